@@ -2,20 +2,36 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/gofiber/fiber/v2"
+
 	"github.com/jagadeesh/grainlify/backend/internal/api"
 	"github.com/jagadeesh/grainlify/backend/internal/bus"
 	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
+	"github.com/jagadeesh/grainlify/backend/internal/cache"
+	"github.com/jagadeesh/grainlify/backend/internal/chaos"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/health"
+	"github.com/jagadeesh/grainlify/backend/internal/jobs"
+	"github.com/jagadeesh/grainlify/backend/internal/loadtest"
 	"github.com/jagadeesh/grainlify/backend/internal/migrate"
-	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
+	"github.com/jagadeesh/grainlify/backend/internal/probe"
+	"github.com/jagadeesh/grainlify/backend/internal/ratelimit"
+	"github.com/jagadeesh/grainlify/backend/internal/realtime"
+	"github.com/jagadeesh/grainlify/backend/internal/tracing"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
 )
 
 func main() {
@@ -26,14 +42,54 @@ func main() {
 	slog.Info("loading configuration", "step", "2", "action", "loading_configuration")
 	cfg := config.Load()
 
+	mode := flag.String("mode", cfg.AppRole, "process mode: api, worker or all")
+	flag.Parse()
+	if !validMode(*mode) {
+		slog.Error("invalid process mode", "mode", *mode)
+		os.Exit(1)
+	}
+
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: cfg.LogLevel(),
 	}))
 	slog.SetDefault(logger)
 
+	// "api migrate [up | down N | status]" migrates from the deployed binary,
+	// e.g. in a release step ahead of the rollout.
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrate(cfg, flag.Args()[1:]))
+	}
+	// "api rekey [status | run]" moves stored GitHub tokens onto the active
+	// token key.
+	if flag.Arg(0) == "rekey" {
+		os.Exit(runRekey(cfg, flag.Args()[1:]))
+	}
+
+	// Stored GitHub tokens are sealed under versioned keys (see rekey.go).
+	if ring, err := cryptox.ParseKeyring(cfg.TokenEncKeyB64, cfg.TokenEncKeys, cfg.TokenEncKeyActive); err == nil {
+		github.SetTokenKeyring(ring)
+	} else if !errors.Is(err, cryptox.ErrNoKeys) {
+		slog.Error("invalid token encryption keys", "error", err)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		slog.Error("tracing disabled", "error", err)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
+	chaos.Configure(cfg.Env, chaos.Settings{
+		LatencyMs:         cfg.ChaosLatencyMs,
+		LatencyPercent:    cfg.ChaosLatencyPercent,
+		GitHubDropPercent: cfg.ChaosGitHubDropPercent,
+		PayoutFailPercent: cfg.ChaosPayoutFailPercent,
+	})
+
 	// Log configuration (mask sensitive values)
 	slog.Info("configuration loaded", "step", "3", "action", "configuration_loaded",
 		"env", cfg.Env,
+		"mode", *mode,
 		"log_level", cfg.Log,
 		"http_addr", cfg.HTTPAddr,
 		"port", os.Getenv("PORT"),
@@ -77,7 +133,18 @@ func main() {
 		database = d
 		defer func() {
 			slog.Info("closing database connection")
-			database.Close()
+			// Close waits for every acquired connection; a handler stuck past
+			// the shutdown deadline shouldn't hold the process up forever.
+			closed := make(chan struct{})
+			go func() {
+				database.Close()
+				close(closed)
+			}()
+			select {
+			case <-closed:
+			case <-time.After(5 * time.Second):
+				slog.Warn("database connections still in use at exit")
+			}
 		}()
 
 		if cfg.AutoMigrate {
@@ -134,34 +201,56 @@ func main() {
 		slog.Info("nats skipped", "step", "6", "action", "nats_skipped", "reason", "NATS_URL not set")
 	}
 
-	slog.Info("initializing api", "step", "7", "action", "initializing_api")
-	app := api.New(cfg, api.Deps{DB: database, Bus: eventBus})
-	slog.Info("api initialized", "step", "7", "action", "api_initialized")
+	wallets := wallet.NewRegistryFromConfig(context.Background(), cfg)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
-	// Background workers (dev convenience). In production we run `cmd/worker` instead.
-	// If NATS is configured, prefer the external worker process.
-	if cfg.NATSURL == "" && database != nil && database.Pool != nil {
-		slog.Info("starting background worker", "step", "8", "action", "starting_background_worker")
-		worker := syncjobs.New(cfg, database.Pool)
-		go func() {
-			slog.Info("background worker started")
-			_ = worker.Run(context.Background())
-		}()
+	var scheduler *jobs.Scheduler
+	if *mode == modeAll && (database == nil || database.Pool == nil) {
+		slog.Warn("background workers skipped", "step", "7", "action", "background_workers_skipped",
+			"reason", "database not available",
+		)
+	} else if *mode != modeAPI {
+		slog.Info("starting background workers", "step", "7", "action", "starting_background_workers")
+		s, err := startWorkers(workerCtx, cfg, database, eventBus, wallets)
+		if err != nil {
+			slog.Error("background workers failed to start", "step", "7", "action", "background_workers_failed",
+				"error", err,
+			)
+			os.Exit(1)
+		}
+		scheduler = s
+		slog.Info("background workers started", "step", "7", "action", "background_workers_started", "jobs", s.Len())
+	}
 
-		// GitHub App cleanup is now handled via webhooks (installation.deleted events)
-		// No need for periodic polling
+	var app *fiber.App
+	if *mode == modeWorker {
+		app = api.NewWorker(cfg, api.Deps{DB: database, Jobs: scheduler})
 	} else {
-		slog.Info("background worker skipped", "step", "8", "action", "background_worker_skipped",
-			"reason", func() string {
-				if cfg.NATSURL != "" {
-					return "NATS configured (use external worker)"
-				}
-				if database == nil {
-					return "database not available"
-				}
-				return "unknown"
-			}(),
-		)
+		slog.Info("initializing api", "step", "8", "action", "initializing_api")
+		prober := newProber(cfg, database, wallets)
+		recorder, closeRecorder := newRecorder(cfg)
+		defer closeRecorder()
+		invalidations := newInvalidationListener(workerCtx, database)
+		broker := newRealtimeBroker(workerCtx, database)
+		a, err := api.New(cfg, api.Deps{DB: database, Bus: eventBus, Wallets: wallets, Probes: prober, Recorder: recorder, Limiter: newRateLimiter(cfg), Jobs: scheduler, Invalidations: invalidations, Realtime: broker})
+		if err != nil {
+			slog.Error("api initialization failed", "step", "8", "action", "api_initialization_failed",
+				"error", err,
+			)
+			os.Exit(1)
+		}
+		app = a
+		slog.Info("api initialized", "step", "8", "action", "api_initialized")
+
+		// Probes exercise this instance's own HTTP API, so every API process runs them.
+		if cfg.ProbeIntervalSeconds > 0 && len(prober.Checks) > 0 {
+			interval := time.Duration(cfg.ProbeIntervalSeconds) * time.Second
+			slog.Info("starting synthetic probes", "interval", interval.String(), "checks", len(prober.Checks))
+			go func() {
+				_ = prober.Run(workerCtx, interval)
+			}()
+		}
 	}
 
 	errCh := make(chan error, 1)
@@ -195,17 +284,127 @@ func main() {
 		os.Exit(1)
 	}
 
-	slog.Info("initiating graceful shutdown", "step", "10", "action", "initiating_graceful_shutdown")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// A second signal skips the drain for operators who need the process gone.
+	go func() {
+		sig := <-sigCh
+		slog.Warn("second shutdown signal received, exiting now", "signal", sig.String())
+		os.Exit(1)
+	}()
+
+	health.StartDraining()
+	if cfg.ShutdownDrainSeconds > 0 {
+		drain := time.Duration(cfg.ShutdownDrainSeconds) * time.Second
+		slog.Info("failing readiness before closing the listener", "drain", drain.String())
+		time.Sleep(drain)
+	}
+
+	timeout := time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+	slog.Info("initiating graceful shutdown", "step", "10", "action", "initiating_graceful_shutdown",
+		"timeout", timeout.String(),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	// In-flight requests and running jobs finish side by side under the same
+	// deadline; workers that aren't jobs (listeners, probes) stop after.
+	jobsDone := make(chan error, 1)
+	go func() {
+		jobsDone <- scheduler.Drain(ctx)
+	}()
 	if err := api.Shutdown(ctx, app); err != nil {
-		slog.Error("graceful shutdown failed",
+		slog.Error("in-flight requests did not finish before the shutdown deadline",
 			"error", err,
 			"error_type", fmt.Sprintf("%T", err),
 		)
-		os.Exit(1)
+	}
+	if err := <-jobsDone; err != nil {
+		slog.Error("background jobs did not finish before the shutdown deadline", "error", err)
+	}
+	stopWorkers()
+
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer flushCancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Warn("flushing traces failed", "error", err)
 	}
 
 	slog.Info("shutdown complete")
 }
+
+// newProber assembles the synthetic probes this configuration can run.
+func newProber(cfg config.Config, database *db.DB, wallets wallet.Registry) *probe.Prober {
+	p := &probe.Prober{}
+	if strings.TrimSpace(cfg.ProbeWalletSeedHex) != "" {
+		check, err := probe.NewAuthCheck(probe.SelfURL(cfg.HTTPAddr), cfg.ProbeWalletSeedHex)
+		if err != nil {
+			slog.Error("login probe disabled", "error", err)
+		} else {
+			p.Checks = append(p.Checks, check)
+		}
+	}
+	if database != nil && database.Pool != nil {
+		p.Checks = append(p.Checks, &probe.MirrorCheck{
+			Pool:   database.Pool,
+			MaxAge: time.Duration(cfg.ProbeMirrorMaxAgeMinutes) * time.Minute,
+		})
+	}
+	if len(wallets) > 0 {
+		p.Checks = append(p.Checks, &probe.PayoutCheck{Wallets: wallets})
+	}
+	return p
+}
+
+// newInvalidationListener starts listening for cache invalidations; without
+// a database there is nothing to cache and it returns nil.
+func newInvalidationListener(ctx context.Context, database *db.DB) *cache.Listener {
+	if database == nil || database.Pool == nil {
+		return nil
+	}
+	l := &cache.Listener{Pool: database.Pool}
+	go func() {
+		_ = l.Run(ctx)
+	}()
+	return l
+}
+
+// newRealtimeBroker starts relaying realtime events to this instance's
+// sockets; without a database there are none and it returns nil.
+func newRealtimeBroker(ctx context.Context, database *db.DB) *realtime.Broker {
+	if database == nil || database.Pool == nil {
+		return nil
+	}
+	b := realtime.NewBroker()
+	l := &realtime.Listener{Pool: database.Pool, Broker: b}
+	go func() {
+		_ = l.Run(ctx)
+	}()
+	return b
+}
+
+// newRateLimiter shares counters through REDIS_URL when set; an invalid URL
+// falls back to per-instance counters.
+func newRateLimiter(cfg config.Config) *ratelimit.Limiter {
+	if cfg.RedisURL == "" {
+		return ratelimit.New(nil)
+	}
+	store, err := ratelimit.NewRedisStore(cfg.RedisURL)
+	if err != nil {
+		slog.Error("rate limits kept in memory", "error", err)
+		return ratelimit.New(nil)
+	}
+	return ratelimit.New(store)
+}
+
+// newRecorder opens LOADTEST_RECORD_PATH for appending, if configured.
+func newRecorder(cfg config.Config) (*loadtest.Recorder, func()) {
+	if strings.TrimSpace(cfg.LoadtestRecordPath) == "" {
+		return nil, func() {}
+	}
+	f, err := os.OpenFile(cfg.LoadtestRecordPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		slog.Error("traffic recording disabled", "error", err)
+		return nil, func() {}
+	}
+	slog.Info("recording anonymized traffic", "path", cfg.LoadtestRecordPath)
+	return loadtest.NewRecorder(f), func() { _ = f.Close() }
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/migrate"
+)
+
+// runMigrate runs a migrate subcommand (see migrate.Usage) and returns the
+// exit code.
+func runMigrate(cfg config.Config, args []string) int {
+	if cfg.DBURL == "" {
+		slog.Error("migrate failed", "error", "DB_URL is not set")
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	d, err := db.Connect(ctx, cfg.DBURL)
+	if err != nil {
+		slog.Error("db connect failed", "error", err)
+		return 1
+	}
+	defer d.Close()
+	if err := migrate.Run(ctx, d.Pool, args, os.Stdout); err != nil {
+		slog.Error("migrate failed", "error", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// rekeyUsage explains the rekey subcommand and the rotation it finishes.
+const rekeyUsage = `usage: api rekey [status | run]
+
+Rotating the key stored GitHub tokens are sealed with, without downtime:
+  1. add the new key to TOKEN_ENC_KEYS ("1:<base64>", next version) with
+     TOKEN_ENC_KEY_ACTIVE still on the current version, and roll out;
+  2. set TOKEN_ENC_KEY_ACTIVE to the new version and roll out;
+  3. run "api rekey run" (or POST /admin/tokens/rekey) until status shows no
+     tokens on the old version;
+  4. drop the old key from TOKEN_ENC_KEYS. Version 0, TOKEN_ENC_KEY_B64,
+     also seals other secrets, so it stays configured.`
+
+// runRekey runs a rekey subcommand and returns the exit code.
+func runRekey(cfg config.Config, args []string) int {
+	cmd := "status"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+	if cmd != "status" && cmd != "run" {
+		fmt.Fprintln(os.Stderr, rekeyUsage)
+		return 2
+	}
+	ring, err := cryptox.ParseKeyring(cfg.TokenEncKeyB64, cfg.TokenEncKeys, cfg.TokenEncKeyActive)
+	if err != nil {
+		slog.Error("rekey failed", "error", err)
+		return 1
+	}
+	if cfg.DBURL == "" {
+		slog.Error("rekey failed", "error", "DB_URL is not set")
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	d, err := db.Connect(ctx, cfg.DBURL)
+	if err != nil {
+		slog.Error("db connect failed", "error", err)
+		return 1
+	}
+	defer d.Close()
+
+	if cmd == "run" {
+		res, err := github.Rekey(ctx, d.Pool, ring)
+		if err != nil {
+			slog.Error("rekey failed", "error", err, "rekeyed", res.Rekeyed)
+			return 1
+		}
+		fmt.Printf("rekeyed %d github tokens onto key %d (%d changed meanwhile, %d unreadable)\n",
+			res.Rekeyed, res.ActiveVersion, res.Skipped, res.Failed)
+	}
+	versions, err := github.KeyVersions(ctx, d.Pool, ring)
+	if err != nil {
+		slog.Error("rekey status failed", "error", err)
+		return 1
+	}
+	for _, v := range versions {
+		state := "held"
+		switch {
+		case v.Active:
+			state = "active"
+		case !v.Held:
+			state = "MISSING"
+		}
+		fmt.Printf("key %d\t%s\t%d tokens\n", v.Version, state, v.Tokens)
+	}
+	return 0
+}
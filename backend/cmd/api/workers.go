@@ -0,0 +1,483 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/jagadeesh/grainlify/backend/internal/advisories"
+	"github.com/jagadeesh/grainlify/backend/internal/attest"
+	"github.com/jagadeesh/grainlify/backend/internal/backup"
+	"github.com/jagadeesh/grainlify/backend/internal/badges"
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
+	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
+	"github.com/jagadeesh/grainlify/backend/internal/cache"
+	"github.com/jagadeesh/grainlify/backend/internal/commitsig"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/deposits"
+	"github.com/jagadeesh/grainlify/backend/internal/escrow"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/idempotency"
+	"github.com/jagadeesh/grainlify/backend/internal/ingest"
+	"github.com/jagadeesh/grainlify/backend/internal/jobs"
+	"github.com/jagadeesh/grainlify/backend/internal/leaderboard"
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/mailer"
+	"github.com/jagadeesh/grainlify/backend/internal/notifications"
+	"github.com/jagadeesh/grainlify/backend/internal/notify"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+	"github.com/jagadeesh/grainlify/backend/internal/payouts"
+	"github.com/jagadeesh/grainlify/backend/internal/profilesync"
+	"github.com/jagadeesh/grainlify/backend/internal/proofs"
+	"github.com/jagadeesh/grainlify/backend/internal/realtime"
+	"github.com/jagadeesh/grainlify/backend/internal/repohealth"
+	"github.com/jagadeesh/grainlify/backend/internal/slack"
+	"github.com/jagadeesh/grainlify/backend/internal/sponsors"
+	"github.com/jagadeesh/grainlify/backend/internal/status"
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
+	"github.com/jagadeesh/grainlify/backend/internal/treasury"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+	"github.com/jagadeesh/grainlify/backend/internal/webhooks"
+	"github.com/jagadeesh/grainlify/backend/internal/worker"
+)
+
+// Process modes selected with --mode (or APP_ROLE).
+const (
+	modeAPI    = "api"
+	modeWorker = "worker"
+	modeAll    = "all"
+)
+
+// webhookQueue is the NATS queue group shared by every worker process, so
+// each GitHub webhook event is ingested once.
+const webhookQueue = "grainlify-workers"
+
+func validMode(mode string) bool {
+	return mode == modeAPI || mode == modeWorker || mode == modeAll
+}
+
+// startWorkers starts the sync job queues, the GitHub webhook consumer and
+// every configured periodic job. Periodic jobs are leased singletons, so
+// worker processes can be scaled out freely.
+func startWorkers(ctx context.Context, cfg config.Config, database *db.DB, eventBus bus.Bus, wallets wallet.Registry) (*jobs.Scheduler, error) {
+	if database == nil || database.Pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	pool := database.Pool
+
+	slog.Info("starting sync job worker")
+	go func() {
+		_ = syncjobs.New(cfg, pool).Run(ctx)
+	}()
+
+	if nb, ok := eventBus.(*natsbus.Bus); ok {
+		ingestor := &ingest.GitHubWebhookIngestor{Pool: pool, Tokens: github.Tokens{
+			Pool:           pool,
+			TokenEncKeyB64: cfg.TokenEncKeyB64,
+			Installations:  github.AppInstallations(cfg.GitHubAppID, cfg.GitHubAppPrivateKey),
+		}}
+		consumer := &worker.GitHubWebhookConsumer{Ingest: ingestor}
+		if err := consumer.Subscribe(ctx, nb.Conn(), webhookQueue); err != nil {
+			return nil, fmt.Errorf("subscribe to github webhooks: %w", err)
+		}
+		slog.Info("consuming github webhooks from nats", "queue", webhookQueue)
+	}
+
+	queue := &jobs.Queue{Pool: pool, Workers: cfg.JobQueueWorkers}
+	if cfg.TokenEncKeyB64 != "" {
+		profiles := &profilesync.Syncer{Pool: pool, GitHub: github.NewClient(), TokenEncKeyB64: cfg.TokenEncKeyB64}
+		queue.Handle(profilesync.Kind, profiles.Handle)
+	}
+	slog.Info("starting job queue", "workers", cfg.JobQueueWorkers)
+	go func() {
+		_ = queue.Run(ctx)
+	}()
+
+	s := &jobs.Scheduler{Pool: pool}
+
+	if cfg.GitHubProfileSyncIntervalMinutes > 0 && cfg.TokenEncKeyB64 != "" {
+		maxAge := time.Duration(cfg.GitHubProfileSyncIntervalMinutes) * time.Minute
+		s.Add(jobs.Job{
+			Name: "github_profile_refresh",
+			// Checking hourly spreads re-syncs out instead of queueing every
+			// user at once each interval.
+			Interval: min(maxAge, time.Hour),
+			Run: func(ctx context.Context) error {
+				n, err := profilesync.EnqueueStale(ctx, pool, maxAge)
+				if n > 0 {
+					slog.Info("queued github profile syncs", "count", n)
+				}
+				return err
+			},
+		})
+	}
+
+	if cfg.CommitSignatureCheckIntervalMinutes > 0 && cfg.TokenEncKeyB64 != "" {
+		checker := &commitsig.Checker{Pool: pool, GitHub: github.NewClient(), TokenEncKeyB64: cfg.TokenEncKeyB64}
+		s.Add(jobs.Job{
+			Name:     "commit_signature_check",
+			Interval: time.Duration(cfg.CommitSignatureCheckIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				n, err := checker.RunOnce(ctx)
+				if n > 0 {
+					slog.Info("checked commit signatures", "count", n)
+				}
+				return err
+			},
+		})
+	}
+
+	if cfg.RepoHealthIntervalMinutes > 0 {
+		refresher := &repohealth.Refresher{Pool: pool, WindowDays: cfg.RepoHealthWindowDays}
+		s.Add(jobs.Job{
+			Name:     "repo_health",
+			Interval: time.Duration(cfg.RepoHealthIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := refresher.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.LeaderboardIntervalMinutes > 0 {
+		refresher := &leaderboard.Refresher{Pool: pool}
+		s.Add(jobs.Job{
+			Name:     "leaderboard",
+			Interval: time.Duration(cfg.LeaderboardIntervalMinutes) * time.Minute,
+			Run:      refresher.RunOnce,
+		})
+	}
+
+	if cfg.GitHubOrgSyncIntervalMinutes > 0 && cfg.GitHubAppID != "" && cfg.GitHubAppPrivateKey != "" {
+		app, err := github.NewGitHubAppClient(cfg.GitHubAppID, cfg.GitHubAppPrivateKey)
+		if err != nil {
+			slog.Error("github org member sync disabled", "error", err)
+		} else {
+			interval := time.Duration(cfg.GitHubOrgSyncIntervalMinutes) * time.Minute
+			syncer := &orgs.Syncer{Pool: pool, App: app, MaxAge: interval}
+			s.Add(jobs.Job{
+				Name:     "github_org_members",
+				Interval: min(interval, 15*time.Minute),
+				Run:      syncer.RunOnce,
+			})
+		}
+	}
+
+	// Invalidations are delivered by NOTIFY as each change commits; outbox
+	// rows are kept a day only for troubleshooting.
+	s.Add(jobs.Job{
+		Name:     "cache_outbox_prune",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			_, err := cache.PruneOutbox(ctx, pool, 24*time.Hour)
+			return err
+		},
+	})
+	s.Add(jobs.Job{
+		Name:     "idempotency_keys_prune",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			_, err := idempotency.Prune(ctx, pool)
+			return err
+		},
+	})
+	s.Add(jobs.Job{
+		Name:     "realtime_events_prune",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			_, err := realtime.Prune(ctx, pool, 24*time.Hour)
+			return err
+		},
+	})
+
+	if cfg.BackupIntervalMinutes > 0 {
+		keys, err := backup.KeysFromB64(cfg.BackupEncKeyB64)
+		if err != nil {
+			slog.Error("ledger backups disabled", "error", err)
+		} else if store, err := backup.NewStoreFromConfig(cfg); err != nil {
+			slog.Error("ledger backups disabled", "error", err)
+		} else {
+			s.Add(jobs.Job{
+				Name:     "ledger_backup",
+				Interval: time.Duration(cfg.BackupIntervalMinutes) * time.Minute,
+				Run: func(ctx context.Context) error {
+					name, m, err := backup.SnapshotTo(ctx, pool, keys, store, cfg.BackupTables)
+					if err != nil {
+						return err
+					}
+					slog.Info("ledger backup written", "name", name, "chain_head", m.ChainHead, "skipped", m.Skipped)
+					return nil
+				},
+			})
+		}
+	}
+
+	if cfg.LedgerAnchorKeyB64 != "" && cfg.LedgerAnchorIntervalMinutes > 0 {
+		signer, err := ledger.NewSignerFromB64(cfg.LedgerAnchorKeyB64)
+		if err != nil {
+			slog.Error("ledger anchoring disabled", "error", err)
+		} else {
+			slog.Info("ledger anchoring enabled", "public_key", signer.PublicKey())
+			s.Add(jobs.Job{
+				Name:     "ledger_anchor",
+				Interval: time.Duration(cfg.LedgerAnchorIntervalMinutes) * time.Minute,
+				Run: func(ctx context.Context) error {
+					a, err := ledger.CreateAnchor(ctx, pool, signer)
+					if err != nil {
+						return err
+					}
+					if a != nil {
+						slog.Info("ledger anchored", "to_seq", a.ToSeq, "root", a.Root)
+					}
+					return nil
+				},
+			})
+		}
+	}
+
+	if cfg.SweepIntervalMinutes > 0 && len(wallets) > 0 {
+		sweeper := &treasury.Sweeper{Pool: pool, Wallets: wallets}
+		s.Add(jobs.Job{
+			Name:     "cold_sweep",
+			Interval: time.Duration(cfg.SweepIntervalMinutes) * time.Minute,
+			Run:      sweeper.RunOnce,
+		})
+	}
+
+	if cfg.PayoutBatchIntervalMinutes > 0 && len(wallets) > 0 {
+		batcher := &payouts.Batcher{Pool: pool, Wallets: wallets, MaxBatch: cfg.PayoutMaxBatch, Router: payouts.NewRouterFromConfig(cfg, wallets), Thresholds: payouts.ParseThresholds(cfg.PayoutThresholds)}
+		batcher.OnWindowRun = func(ctx context.Context, r payouts.WindowRun) {
+			msg := notify.Message{ID: "payout-window-run:" + r.ID.String(), Text: r.Text()}
+			if err := notify.SendToAdmins(ctx, pool, notify.Transports(), cfg.TokenEncKeyB64, msg); err != nil {
+				slog.Warn("failed to deliver payout window summary", "window_run_id", r.ID.String(), "error", err)
+			}
+		}
+		s.Add(jobs.Job{
+			Name:     "payout_batch",
+			Interval: time.Duration(cfg.PayoutBatchIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := batcher.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.PayoutConfirmIntervalMinutes > 0 && len(wallets) > 0 {
+		confirmer := &payouts.Confirmer{Pool: pool, Wallets: wallets}
+		s.Add(jobs.Job{
+			Name:     "payout_confirm",
+			Interval: time.Duration(cfg.PayoutConfirmIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := confirmer.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.ProjectEscrowWatchIntervalMinutes > 0 {
+		watcher := &deposits.EscrowWatcher{Pool: pool, Wallets: wallets}
+		s.Add(jobs.Job{
+			Name:     "project_escrow_watch",
+			Interval: time.Duration(cfg.ProjectEscrowWatchIntervalMinutes) * time.Minute,
+			Run:      watcher.RunOnce,
+		})
+	}
+
+	if cfg.EscrowReleaseIntervalMinutes > 0 && len(escrow.Contracts(cfg)) > 0 {
+		if contracts := escrow.NewRegistryFromConfig(cfg); len(contracts) > 0 {
+			releaser := &payouts.EscrowReleaser{Pool: pool, Contracts: contracts}
+			s.Add(jobs.Job{
+				Name:     "escrow_release",
+				Interval: time.Duration(cfg.EscrowReleaseIntervalMinutes) * time.Minute,
+				Run: func(ctx context.Context) error {
+					_, err := releaser.RunOnce(ctx)
+					return err
+				},
+			})
+		}
+	}
+
+	if cfg.AchievementNFTChain != "" && cfg.AchievementNFTContract != "" {
+		sender, ok := wallets.Get(cfg.AchievementNFTChain)
+		evm, isEVM := sender.(*wallet.EVMSender)
+		if !ok || !isEVM || !common.IsHexAddress(cfg.AchievementNFTContract) {
+			slog.Error("achievement nft minting disabled: needs an evm hot wallet and contract address", "chain", cfg.AchievementNFTChain)
+		} else {
+			minter := &badges.Minter{
+				Pool:     pool,
+				Sender:   evm,
+				Contract: common.HexToAddress(cfg.AchievementNFTContract),
+				BaseURI:  cfg.AchievementNFTBaseURI,
+			}
+			s.Add(jobs.Job{
+				Name:     "achievement_mint",
+				Interval: time.Duration(cfg.AchievementMintIntervalMinutes) * time.Minute,
+				Run: func(ctx context.Context) error {
+					_, err := minter.RunOnce(ctx)
+					return err
+				},
+			})
+		}
+	}
+
+	if cfg.EASChain != "" {
+		attester, err := attest.New(pool, wallets, cfg.EASChain, cfg.EASContract, cfg.EASSchemaUID)
+		if err != nil {
+			slog.Error("eas attestations disabled", "error", err)
+		} else {
+			s.Add(jobs.Job{
+				Name:     "eas_attest",
+				Interval: time.Duration(cfg.AttestIntervalMinutes) * time.Minute,
+				Run:      attester.RunOnce,
+			})
+		}
+	}
+
+	if cfg.SponsorsSyncIntervalMinutes > 0 && cfg.TokenEncKeyB64 != "" {
+		syncer := &sponsors.Syncer{Pool: pool, GitHub: github.NewClient(), TokenEncKeyB64: cfg.TokenEncKeyB64}
+		s.Add(jobs.Job{
+			Name:     "sponsors_sync",
+			Interval: time.Duration(cfg.SponsorsSyncIntervalMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := syncer.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.SlackNotifyIntervalSeconds > 0 && cfg.SlackClientID != "" {
+		notifier := &slack.Notifier{
+			Pool:           pool,
+			Slack:          slack.NewClient(cfg.SlackClientID, cfg.SlackClientSecret, cfg.SlackRedirectURL),
+			TokenEncKeyB64: cfg.TokenEncKeyB64,
+		}
+		s.Add(jobs.Job{
+			Name:     "slack_notify",
+			Interval: time.Duration(cfg.SlackNotifyIntervalSeconds) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := notifier.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.NotifyIntervalSeconds > 0 {
+		dispatcher := &notify.Dispatcher{Pool: pool, Transports: notify.Transports(), TokenEncKeyB64: cfg.TokenEncKeyB64}
+		s.Add(jobs.Job{
+			Name:     "notify_dispatch",
+			Interval: time.Duration(cfg.NotifyIntervalSeconds) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := dispatcher.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.NotificationsIntervalSeconds > 0 {
+		if tmpl, err := notificationTemplates(ctx, cfg); err != nil {
+			slog.Error("notifications disabled: invalid templates", "error", err)
+		} else {
+			providers := []notifications.Provider{&notifications.InApp{Pool: pool}}
+			mail, err := mailer.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+			switch {
+			case err != nil:
+				slog.Error("email notifications disabled: invalid configuration", "error", err)
+			case mail != nil:
+				providers = append(providers, &notifications.Email{Pool: pool, Mailer: mail})
+			}
+			dispatcher := &notifications.Dispatcher{Pool: pool, Templates: tmpl, Providers: providers}
+			s.Add(jobs.Job{
+				Name:     "notifications_dispatch",
+				Interval: time.Duration(cfg.NotificationsIntervalSeconds) * time.Second,
+				Run: func(ctx context.Context) error {
+					_, err := dispatcher.RunOnce(ctx)
+					return err
+				},
+			})
+		}
+	}
+
+	if cfg.AdvisorySyncIntervalSeconds > 0 {
+		syncer := &advisories.Syncer{
+			Pool:           pool,
+			TokenEncKeyB64: cfg.TokenEncKeyB64,
+			Installations:  github.AppInstallations(cfg.GitHubAppID, cfg.GitHubAppPrivateKey),
+		}
+		s.Add(jobs.Job{
+			Name:     "advisory_sync",
+			Interval: time.Duration(cfg.AdvisorySyncIntervalSeconds) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := syncer.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.WebhookDispatchIntervalSeconds > 0 && cfg.TokenEncKeyB64 != "" {
+		dispatcher := &webhooks.Dispatcher{Pool: pool, TokenEncKeyB64: cfg.TokenEncKeyB64}
+		s.Add(jobs.Job{
+			Name:     "webhook_dispatch",
+			Interval: time.Duration(cfg.WebhookDispatchIntervalSeconds) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := dispatcher.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.PaymentProofIntervalSeconds > 0 && cfg.TokenEncKeyB64 != "" {
+		dispatcher := &proofs.Dispatcher{Pool: pool, TokenEncKeyB64: cfg.TokenEncKeyB64}
+		s.Add(jobs.Job{
+			Name:     "payment_proofs",
+			Interval: time.Duration(cfg.PaymentProofIntervalSeconds) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := dispatcher.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if cfg.StatusCheckIntervalSeconds > 0 {
+		checker := &status.Checker{Pool: pool}
+		s.Add(jobs.Job{
+			Name:     "status_checks",
+			Interval: time.Duration(cfg.StatusCheckIntervalSeconds) * time.Second,
+			Run: func(ctx context.Context) error {
+				_, err := checker.RunOnce(ctx)
+				return err
+			},
+		})
+	}
+
+	if err := s.Start(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// notificationTemplates are the built-in templates, or in dev with
+// NotificationTemplatesDir set, that directory's, reloaded on every edit.
+func notificationTemplates(ctx context.Context, cfg config.Config) (notifications.Renderer, error) {
+	if cfg.Env == "dev" && cfg.NotificationTemplatesDir != "" {
+		r, err := notifications.NewReloader(cfg.NotificationTemplatesDir, cfg.FrontendBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		go r.Watch(ctx, time.Second)
+		slog.Info("reloading notification templates on change", "dir", cfg.NotificationTemplatesDir)
+		return r, nil
+	}
+	if cfg.NotificationTemplatesDir != "" {
+		slog.Warn("NOTIFICATION_TEMPLATES_DIR ignored outside dev; using built-in templates", "env", cfg.Env)
+	}
+	t, err := notifications.LoadBuiltin(cfg.FrontendBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("notification templates loaded", "digest", t.Digest)
+	return t, nil
+}
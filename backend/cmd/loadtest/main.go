@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/jagadeesh/grainlify/backend/internal/loadtest"
+	"github.com/jagadeesh/grainlify/backend/internal/probe"
+)
+
+// Usage:
+//
+//	loadtest -in traffic.jsonl [-base http://127.0.0.1:8080] [-seed HEX]
+//	         [-concurrency 8] [-speed 0] [-out report.json]
+//	         [-baseline report.json -tolerance 0.2]
+//	loadtest -verify 2000 [-out report.json]
+//
+// Replays a recording made with LOADTEST_RECORD_PATH against a local
+// instance. With -baseline it exits 1 when a route's p95 regressed.
+//
+// -verify instead times that many wallet signature checks per wallet type in
+// process, fresh and cached, to size instances for login storms.
+func main() {
+	in := flag.String("in", "", "recorded traffic (JSON lines)")
+	base := flag.String("base", "http://127.0.0.1:8080", "instance to replay against")
+	seed := flag.String("seed", os.Getenv("LOADTEST_WALLET_SEED_HEX"), "hex ed25519 seed of a load-test wallet; enables login and authenticated routes")
+	concurrency := flag.Int("concurrency", 8, "max in-flight requests")
+	speed := flag.Float64("speed", 0, "replay speed relative to the recording; 0 = as fast as possible")
+	out := flag.String("out", "", "write the JSON report here")
+	baseline := flag.String("baseline", "", "JSON report to compare against")
+	tolerance := flag.Float64("tolerance", 0.2, "allowed p95 growth over the baseline")
+	minDelta := flag.Float64("min-delta-ms", 5, "ignore p95 growth smaller than this")
+	minCount := flag.Int("min-count", 20, "ignore routes seen fewer times than this")
+	verify := flag.Int("verify", 0, "benchmark this many wallet signature checks per wallet type instead of replaying")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	if *verify > 0 {
+		stats, err := loadtest.VerifyBench(*verify)
+		if err != nil {
+			slog.Error("verify benchmark failed", "error", err)
+			os.Exit(1)
+		}
+		_ = loadtest.WriteVerifyText(os.Stdout, stats)
+		if *out != "" {
+			b, _ := json.MarshalIndent(stats, "", "  ")
+			if err := os.WriteFile(*out, b, 0o644); err != nil {
+				slog.Error("write report failed", "error", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	f, err := os.Open(*in)
+	if err != nil {
+		slog.Error("open recording failed", "error", err)
+		os.Exit(1)
+	}
+	entries, err := loadtest.ReadEntries(f)
+	f.Close()
+	if err != nil {
+		slog.Error("read recording failed", "error", err)
+		os.Exit(1)
+	}
+
+	opts := loadtest.Options{BaseURL: *base, Concurrency: *concurrency, Speed: *speed}
+	if strings.TrimSpace(*seed) != "" {
+		check, err := probe.NewAuthCheck(*base, *seed)
+		if err != nil {
+			slog.Error("invalid load-test wallet", "error", err)
+			os.Exit(1)
+		}
+		opts.Login = func(ctx context.Context) (string, error) {
+			s, err := check.Login(ctx)
+			return s.Token, err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	slog.Info("replaying", "entries", len(entries), "base", *base)
+	report, err := loadtest.Replay(ctx, entries, opts)
+	if err != nil {
+		slog.Error("replay failed", "error", err)
+		os.Exit(1)
+	}
+	_ = report.WriteText(os.Stdout)
+
+	if *out != "" {
+		b, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*out, b, 0o644); err != nil {
+			slog.Error("write report failed", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if *baseline != "" {
+		b, err := os.ReadFile(*baseline)
+		if err != nil {
+			slog.Error("read baseline failed", "error", err)
+			os.Exit(1)
+		}
+		var prev loadtest.Report
+		if err := json.Unmarshal(b, &prev); err != nil {
+			slog.Error("parse baseline failed", "error", err)
+			os.Exit(1)
+		}
+		regs := loadtest.Compare(prev, report, *tolerance, *minDelta, *minCount)
+		for _, r := range regs {
+			fmt.Printf("REGRESSION %s: p95 %.1fms -> %.1fms, errors %d -> %d\n", r.Route, r.BaseP95, r.P95, r.BaseErrs, r.Errs)
+		}
+		if len(regs) > 0 {
+			os.Exit(1)
+		}
+	}
+}
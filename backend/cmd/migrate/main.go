@@ -11,6 +11,12 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/migrate"
 )
 
+// Usage:
+//
+//	migrate            apply pending migrations
+//	migrate up         same
+//	migrate down N     roll back the last N migrations
+//	migrate status     show the applied version and pending migrations
 func main() {
 	config.LoadDotenv()
 	cfg := config.Load()
@@ -30,12 +36,10 @@ func main() {
 	}
 	defer d.Close()
 
-	if err := migrate.Up(ctx, d.Pool); err != nil {
-		slog.Error("migrate up failed", "error", err)
+	if err := migrate.Run(ctx, d.Pool, os.Args[1:], os.Stdout); err != nil {
+		slog.Error("migrate failed", "error", err)
 		os.Exit(1)
 	}
-
-	slog.Info("migrations applied")
 }
 
 
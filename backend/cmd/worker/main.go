@@ -6,9 +6,8 @@ import (
 
 // Worker entrypoint placeholder.
 //
-// This repo currently does not ship a worker binary in this workspace snapshot,
-// but Go tooling expects a valid package in ./cmd/worker.
+// Background workers ship in the API binary; run it with --mode=worker
+// (or APP_ROLE=worker) to start them without the public HTTP API.
 func main() {
-	log.Println("worker is not implemented in this build")
+	log.Println("run ./cmd/api --mode=worker to start the background workers")
 }
-
@@ -0,0 +1,331 @@
+// Package advisories runs security-advisory bounties: vulnerability fixes
+// paid for under embargo. Until its coordinated disclosure date such a
+// bounty is private, its write-up is shown only to insiders (the project's
+// maintainers, the users invited to it and its claimant) and only invited
+// users may claim it. At disclosure the bounty turns public with a redacted
+// write-up, and its GitHub Security Advisory is published (see Syncer).
+package advisories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+)
+
+// Severities, as GitHub grades advisories.
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Severities lists every severity.
+var Severities = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+var (
+	ErrNotFound         = errors.New("advisory_not_found")
+	ErrInvalidSeverity  = errors.New("invalid_advisory_severity")
+	ErrIncomplete       = errors.New("advisory_incomplete")
+	ErrInvalidCVE       = errors.New("invalid_cve_id")
+	ErrInvalidCWE       = errors.New("invalid_cwe_id")
+	ErrInvalidPackage   = errors.New("invalid_advisory_package")
+	ErrDisclosurePassed = errors.New("disclosure_date_passed")
+	ErrPublished        = errors.New("advisory_published")
+)
+
+var (
+	cvePattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+	cwePattern = regexp.MustCompile(`^CWE-\d+$`)
+)
+
+// Package is the package an advisory affects, in GitHub's terms (ecosystem
+// such as npm, go or pip; version ranges such as "< 1.4.2").
+type Package struct {
+	Ecosystem          string `json:"ecosystem"`
+	Name               string `json:"name"`
+	VulnerableVersions string `json:"vulnerable_versions,omitempty"`
+	PatchedVersions    string `json:"patched_versions,omitempty"`
+}
+
+// Advisory is a security-advisory bounty's write-up and disclosure state.
+type Advisory struct {
+	BountyID uuid.UUID `json:"bounty_id"`
+	Severity string    `json:"severity"`
+	Summary  string    `json:"summary"`
+	// Details is the embargoed write-up, left out for outsiders (Redacted).
+	Details string `json:"details,omitempty"`
+	// RedactedDetails is what everyone sees, and what is published.
+	RedactedDetails string     `json:"redacted_details"`
+	CVEID           *string    `json:"cve_id,omitempty"`
+	CWEIDs          []string   `json:"cwe_ids"`
+	Package         *Package   `json:"package,omitempty"`
+	DisclosureAt    time.Time  `json:"disclosure_at"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+	GHSAID          *string    `json:"ghsa_id,omitempty"`
+	GHSAURL         *string    `json:"ghsa_url,omitempty"`
+	GHSAPublishedAt *time.Time `json:"ghsa_published_at,omitempty"`
+	// GitHubError is why the advisory last failed to reach GitHub.
+	GitHubError *string   `json:"github_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Embargoed reports whether a hasn't been disclosed yet.
+func (a Advisory) Embargoed() bool { return a.PublishedAt == nil }
+
+// Redacted is a as shown to outsiders: without the embargoed write-up or
+// GitHub sync errors.
+func (a Advisory) Redacted() Advisory {
+	a.Details = ""
+	a.GitHubError = nil
+	return a
+}
+
+// Draft is a maintainer's write-up of an advisory.
+type Draft struct {
+	Severity        string     `json:"severity"`
+	Summary         string     `json:"summary"`
+	Details         string     `json:"details"`
+	RedactedDetails string     `json:"redacted_details,omitempty"`
+	CVEID           string     `json:"cve_id,omitempty"`
+	CWEIDs          []string   `json:"cwe_ids,omitempty"`
+	Package         *Package   `json:"package,omitempty"`
+	DisclosureAt    *time.Time `json:"disclosure_at"`
+}
+
+// Check normalizes d and reports what is wrong with it, if anything. The
+// redacted write-up defaults to the summary.
+func (d *Draft) Check(now time.Time) error {
+	d.Severity = strings.ToLower(strings.TrimSpace(d.Severity))
+	d.Summary = strings.TrimSpace(d.Summary)
+	d.Details = strings.TrimSpace(d.Details)
+	d.RedactedDetails = strings.TrimSpace(d.RedactedDetails)
+	d.CVEID = strings.ToUpper(strings.TrimSpace(d.CVEID))
+	if !slices.Contains(Severities, d.Severity) {
+		return ErrInvalidSeverity
+	}
+	if d.Summary == "" || d.Details == "" || d.DisclosureAt == nil {
+		return ErrIncomplete
+	}
+	if d.RedactedDetails == "" {
+		d.RedactedDetails = d.Summary
+	}
+	if d.CVEID != "" && !cvePattern.MatchString(d.CVEID) {
+		return ErrInvalidCVE
+	}
+	cwes := make([]string, 0, len(d.CWEIDs))
+	for _, id := range d.CWEIDs {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if !cwePattern.MatchString(id) {
+			return ErrInvalidCWE
+		}
+		if !slices.Contains(cwes, id) {
+			cwes = append(cwes, id)
+		}
+	}
+	d.CWEIDs = cwes
+	if p := d.Package; p != nil {
+		p.Ecosystem = strings.ToLower(strings.TrimSpace(p.Ecosystem))
+		p.Name = strings.TrimSpace(p.Name)
+		p.VulnerableVersions = strings.TrimSpace(p.VulnerableVersions)
+		p.PatchedVersions = strings.TrimSpace(p.PatchedVersions)
+		if p.Ecosystem == "" || p.Name == "" {
+			return ErrInvalidPackage
+		}
+	}
+	if !d.DisclosureAt.After(now) {
+		return ErrDisclosurePassed
+	}
+	return nil
+}
+
+const advisoryColumns = `bounty_id, severity, summary, details, redacted_details, cve_id, cwe_ids,
+package_ecosystem, package_name, vulnerable_versions, patched_versions,
+disclosure_at, published_at, ghsa_id, ghsa_url, ghsa_published_at, github_error, created_at, updated_at`
+
+func scanAdvisory(row pgx.Row) (Advisory, error) {
+	var a Advisory
+	var ecosystem, name, vulnerable, patched *string
+	err := row.Scan(&a.BountyID, &a.Severity, &a.Summary, &a.Details, &a.RedactedDetails, &a.CVEID, &a.CWEIDs,
+		&ecosystem, &name, &vulnerable, &patched,
+		&a.DisclosureAt, &a.PublishedAt, &a.GHSAID, &a.GHSAURL, &a.GHSAPublishedAt, &a.GitHubError, &a.CreatedAt, &a.UpdatedAt)
+	if ecosystem != nil && name != nil {
+		a.Package = &Package{Ecosystem: *ecosystem, Name: *name}
+		if vulnerable != nil {
+			a.Package.VulnerableVersions = *vulnerable
+		}
+		if patched != nil {
+			a.Package.PatchedVersions = *patched
+		}
+	}
+	return a, err
+}
+
+// Get returns a bounty's advisory.
+func Get(ctx context.Context, pool *pgxpool.Pool, bountyID uuid.UUID) (Advisory, error) {
+	if pool == nil {
+		return Advisory{}, fmt.Errorf("db not configured")
+	}
+	a, err := scanAdvisory(pool.QueryRow(ctx, `SELECT `+advisoryColumns+` FROM bounty_advisories WHERE bounty_id = $1`, bountyID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Advisory{}, ErrNotFound
+	}
+	return a, err
+}
+
+// Put writes bounty b's advisory on behalf of actor, turning b into a
+// private security-advisory bounty. A disclosed advisory can't be changed.
+func Put(ctx context.Context, pool *pgxpool.Pool, b bounties.Bounty, actor uuid.UUID, d Draft) (Advisory, error) {
+	if pool == nil {
+		return Advisory{}, fmt.Errorf("db not configured")
+	}
+	if err := d.Check(time.Now()); err != nil {
+		return Advisory{}, err
+	}
+	var ecosystem, name, vulnerable, patched *string
+	if p := d.Package; p != nil {
+		ecosystem, name = &p.Ecosystem, &p.Name
+		vulnerable, patched = nonEmpty(p.VulnerableVersions), nonEmpty(p.PatchedVersions)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Advisory{}, err
+	}
+	defer tx.Rollback(ctx)
+	if err := bounties.SetActor(ctx, tx, actor); err != nil {
+		return Advisory{}, err
+	}
+	a, err := scanAdvisory(tx.QueryRow(ctx, `
+INSERT INTO bounty_advisories (bounty_id, severity, summary, details, redacted_details, cve_id, cwe_ids,
+                               package_ecosystem, package_name, vulnerable_versions, patched_versions, disclosure_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT (bounty_id) DO UPDATE SET
+  severity = EXCLUDED.severity, summary = EXCLUDED.summary, details = EXCLUDED.details,
+  redacted_details = EXCLUDED.redacted_details, cve_id = EXCLUDED.cve_id, cwe_ids = EXCLUDED.cwe_ids,
+  package_ecosystem = EXCLUDED.package_ecosystem, package_name = EXCLUDED.package_name,
+  vulnerable_versions = EXCLUDED.vulnerable_versions, patched_versions = EXCLUDED.patched_versions,
+  disclosure_at = EXCLUDED.disclosure_at, github_retry_at = NULL, updated_at = now()
+WHERE bounty_advisories.published_at IS NULL
+RETURNING `+advisoryColumns,
+		b.ID, d.Severity, d.Summary, d.Details, d.RedactedDetails, nonEmpty(d.CVEID), d.CWEIDs,
+		ecosystem, name, vulnerable, patched, *d.DisclosureAt, actor))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Advisory{}, ErrPublished
+	}
+	if err != nil {
+		return Advisory{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE bounties SET kind = 'security_advisory', visibility = 'private', updated_at = now()
+WHERE id = $1 AND (kind <> 'security_advisory' OR visibility <> 'private')
+`, b.ID); err != nil {
+		return Advisory{}, err
+	}
+	return a, tx.Commit(ctx)
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// Publish discloses a bounty's advisory now, on behalf of actor (uuid.Nil
+// when its disclosure date came): the bounty turns public and outsiders see
+// the redacted write-up. The Syncer publishes it on GitHub.
+func Publish(ctx context.Context, pool *pgxpool.Pool, bountyID, actor uuid.UUID) (Advisory, error) {
+	if pool == nil {
+		return Advisory{}, fmt.Errorf("db not configured")
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Advisory{}, err
+	}
+	defer tx.Rollback(ctx)
+	if actor != uuid.Nil {
+		if err := bounties.SetActor(ctx, tx, actor); err != nil {
+			return Advisory{}, err
+		}
+	}
+	a, err := scanAdvisory(tx.QueryRow(ctx, `
+UPDATE bounty_advisories
+SET published_at = now(), disclosure_at = LEAST(disclosure_at, now()), github_retry_at = NULL, updated_at = now()
+WHERE bounty_id = $1 AND published_at IS NULL
+RETURNING `+advisoryColumns, bountyID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, gerr := Get(ctx, pool, bountyID); gerr != nil {
+			return Advisory{}, gerr
+		}
+		return Advisory{}, ErrPublished
+	}
+	if err != nil {
+		return Advisory{}, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE bounties SET visibility = 'public', updated_at = now() WHERE id = $1`, bountyID); err != nil {
+		return Advisory{}, err
+	}
+	return a, tx.Commit(ctx)
+}
+
+// PublishDue discloses every advisory whose disclosure date has come,
+// returning how many it published.
+func PublishDue(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT bounty_id FROM bounty_advisories WHERE published_at IS NULL AND disclosure_at <= now() ORDER BY disclosure_at LIMIT 100`)
+	if err != nil {
+		return 0, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, id := range ids {
+		_, err := Publish(ctx, pool, id, uuid.Nil)
+		switch {
+		case errors.Is(err, ErrPublished):
+		case err != nil:
+			return n, err
+		default:
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Insider reports whether userID sees b's embargoed write-up as its
+// claimant or an invited user; maintainers are insiders as well, which the
+// caller checks.
+func Insider(ctx context.Context, pool *pgxpool.Pool, b bounties.Bounty, userID uuid.UUID) (bool, error) {
+	if userID == uuid.Nil {
+		return false, nil
+	}
+	if b.Claim != nil && b.Claim.UserID == userID {
+		return true, nil
+	}
+	return bounties.Invited(ctx, pool, b.ID, userID)
+}
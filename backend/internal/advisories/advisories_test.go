@@ -0,0 +1,74 @@
+package advisories
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDraftCheck(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	later := now.Add(30 * 24 * time.Hour)
+	valid := func() Draft {
+		return Draft{
+			Severity:     " High ",
+			Summary:      "SQL injection in search",
+			Details:      "The q parameter is concatenated into the query; see the PoC.",
+			CVEID:        "cve-2026-12345",
+			CWEIDs:       []string{"cwe-89", "CWE-89"},
+			Package:      &Package{Ecosystem: "Go", Name: "github.com/acme/widgets", VulnerableVersions: "< 1.4.2"},
+			DisclosureAt: &later,
+		}
+	}
+
+	d := valid()
+	if err := d.Check(now); err != nil {
+		t.Fatal(err)
+	}
+	if d.Severity != SeverityHigh || d.CVEID != "CVE-2026-12345" || d.Package.Ecosystem != "go" {
+		t.Errorf("not normalized: %+v", d)
+	}
+	if len(d.CWEIDs) != 1 || d.CWEIDs[0] != "CWE-89" {
+		t.Errorf("cwe ids = %v", d.CWEIDs)
+	}
+	if d.RedactedDetails != d.Summary {
+		t.Errorf("redacted details = %q, want the summary", d.RedactedDetails)
+	}
+
+	past := now.Add(-time.Hour)
+	for name, tc := range map[string]struct {
+		edit func(*Draft)
+		want error
+	}{
+		"severity":   {func(d *Draft) { d.Severity = "urgent" }, ErrInvalidSeverity},
+		"summary":    {func(d *Draft) { d.Summary = " " }, ErrIncomplete},
+		"details":    {func(d *Draft) { d.Details = "" }, ErrIncomplete},
+		"no date":    {func(d *Draft) { d.DisclosureAt = nil }, ErrIncomplete},
+		"cve":        {func(d *Draft) { d.CVEID = "CVE-26-1" }, ErrInvalidCVE},
+		"cwe":        {func(d *Draft) { d.CWEIDs = []string{"89"} }, ErrInvalidCWE},
+		"package":    {func(d *Draft) { d.Package.Name = "" }, ErrInvalidPackage},
+		"past date":  {func(d *Draft) { d.DisclosureAt = &past }, ErrDisclosurePassed},
+		"date today": {func(d *Draft) { d.DisclosureAt = &now }, ErrDisclosurePassed},
+	} {
+		d := valid()
+		tc.edit(&d)
+		if err := d.Check(now); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", name, err, tc.want)
+		}
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	msg := "forbidden"
+	a := Advisory{Summary: "s", Details: "exploit", RedactedDetails: "fixed in 1.4.2", GitHubError: &msg}
+	r := a.Redacted()
+	if r.Details != "" || r.GitHubError != nil || r.RedactedDetails != a.RedactedDetails {
+		t.Errorf("redacted = %+v", r)
+	}
+	if a.Details != "exploit" {
+		t.Error("Redacted changed the original")
+	}
+	if !a.Embargoed() {
+		t.Error("unpublished advisory not embargoed")
+	}
+}
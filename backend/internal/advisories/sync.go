@@ -0,0 +1,172 @@
+package advisories
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// githubRetry is how long an advisory GitHub refused waits before it is
+// pushed again.
+const githubRetry = 15 * time.Minute
+
+// syncBatch is how many advisories one RunOnce pushes to GitHub.
+const syncBatch = 50
+
+// Syncer publishes advisories whose disclosure date came, and mirrors every
+// advisory to a GitHub Security Advisory on its project's repository:
+// drafted while embargoed with the full write-up, then published with the
+// redacted one. Calls go through the GitHub App's installation when there
+// is one (it needs the repository_advisories write permission), else the
+// project owner's token.
+type Syncer struct {
+	Pool           *pgxpool.Pool
+	GitHub         *github.Client
+	TokenEncKeyB64 string
+	Installations  *github.InstallationTokens
+}
+
+type pendingAdvisory struct {
+	Advisory
+	repo    string
+	ownerID uuid.UUID
+}
+
+// RunOnce publishes the advisories that are due and pushes a batch of
+// changed ones to GitHub, returning how many it published or pushed.
+func (s *Syncer) RunOnce(ctx context.Context) (int, error) {
+	if s.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	published, err := PublishDue(ctx, s.Pool)
+	if err != nil {
+		return published, err
+	}
+
+	rows, err := s.Pool.Query(ctx, `
+SELECT a.bounty_id, p.github_full_name, p.owner_user_id
+FROM bounty_advisories a
+JOIN bounties b ON b.id = a.bounty_id
+JOIN projects p ON p.id = b.project_id
+WHERE (a.github_synced_at IS NULL OR a.github_synced_at < a.updated_at)
+  AND (a.github_retry_at IS NULL OR a.github_retry_at <= now())
+  AND p.deleted_at IS NULL
+ORDER BY a.updated_at
+LIMIT $1
+`, syncBatch)
+	if err != nil {
+		return published, err
+	}
+	var pending []pendingAdvisory
+	for rows.Next() {
+		var p pendingAdvisory
+		if err := rows.Scan(&p.BountyID, &p.repo, &p.ownerID); err != nil {
+			rows.Close()
+			return published, err
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return published, err
+	}
+	for i := range pending {
+		a, err := Get(ctx, s.Pool, pending[i].BountyID)
+		if err != nil {
+			return published, err
+		}
+		pending[i].Advisory = a
+	}
+
+	pushed := 0
+	for _, p := range pending {
+		if err := s.push(ctx, p); err != nil {
+			slog.Warn("advisory github sync failed", "bounty_id", p.BountyID.String(), "repo", p.repo, "error", err)
+			msg := err.Error()
+			if _, err := s.Pool.Exec(ctx, `
+UPDATE bounty_advisories SET github_error = $2, github_retry_at = $3 WHERE bounty_id = $1
+`, p.BountyID, msg, time.Now().Add(githubRetry)); err != nil {
+				return published + pushed, err
+			}
+			continue
+		}
+		pushed++
+	}
+	if published+pushed > 0 {
+		slog.Info("synced security advisories", "published", published, "pushed", pushed)
+	}
+	return published + pushed, nil
+}
+
+// push brings p's GitHub advisory up to date, drafting it first if needed.
+func (s *Syncer) push(ctx context.Context, p pendingAdvisory) error {
+	gh := s.GitHub
+	if gh == nil {
+		gh = github.NewClient()
+	}
+	tokens := github.Tokens{Pool: s.Pool, TokenEncKeyB64: s.TokenEncKeyB64, Installations: s.Installations}
+	token, err := tokens.Repo(p.repo, p.ownerID).Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	drafted := p.GHSAID != nil
+	if !drafted {
+		draft := github.RepositoryAdvisory{
+			Summary:     p.Summary,
+			Description: p.Details,
+			Severity:    p.Severity,
+			CWEIDs:      p.CWEIDs,
+		}
+		if p.CVEID != nil {
+			draft.CVEID = *p.CVEID
+		}
+		if pkg := p.Package; pkg != nil {
+			var v github.AdvisoryVulnerability
+			v.Package.Ecosystem, v.Package.Name = pkg.Ecosystem, pkg.Name
+			v.VulnerableVersionRange, v.PatchedVersions = pkg.VulnerableVersions, pkg.PatchedVersions
+			draft.Vulnerabilities = []github.AdvisoryVulnerability{v}
+		}
+		out, err := gh.CreateRepositoryAdvisory(ctx, token, p.repo, draft)
+		if err != nil {
+			return err
+		}
+		// Record the draft right away so a failure below can't draft it twice.
+		if _, err := s.Pool.Exec(ctx, `UPDATE bounty_advisories SET ghsa_id = $2, ghsa_url = $3 WHERE bounty_id = $1`,
+			p.BountyID, out.GHSAID, out.HTMLURL); err != nil {
+			return err
+		}
+		p.GHSAID = &out.GHSAID
+	}
+	switch {
+	case p.PublishedAt != nil && p.GHSAPublishedAt == nil:
+		upd := github.AdvisoryUpdate{Description: p.RedactedDetails, State: "published"}
+		if _, err := gh.UpdateRepositoryAdvisory(ctx, token, p.repo, *p.GHSAID, upd); err != nil {
+			return err
+		}
+	case p.PublishedAt == nil && drafted:
+		upd := github.AdvisoryUpdate{Summary: p.Summary, Description: p.Details, Severity: p.Severity}
+		if _, err := gh.UpdateRepositoryAdvisory(ctx, token, p.repo, *p.GHSAID, upd); err != nil {
+			return err
+		}
+	}
+
+	var ghsaPublished *time.Time
+	if p.PublishedAt != nil {
+		now := time.Now()
+		ghsaPublished = &now
+	}
+	_, err = s.Pool.Exec(ctx, `
+UPDATE bounty_advisories
+SET github_synced_at = $2, github_error = NULL, github_retry_at = NULL,
+    ghsa_published_at = COALESCE(ghsa_published_at, $3)
+WHERE bounty_id = $1
+`, p.BountyID, p.UpdatedAt, ghsaPublished)
+	return err
+}
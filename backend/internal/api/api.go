@@ -7,59 +7,94 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/jagadeesh/grainlify/backend/internal/apikeys"
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/authz"
 	"github.com/jagadeesh/grainlify/backend/internal/bus"
+	"github.com/jagadeesh/grainlify/backend/internal/cache"
+	"github.com/jagadeesh/grainlify/backend/internal/chaos"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/handlers"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/idempotency"
+	"github.com/jagadeesh/grainlify/backend/internal/jobs"
+	"github.com/jagadeesh/grainlify/backend/internal/loadtest"
+	"github.com/jagadeesh/grainlify/backend/internal/metrics"
+	"github.com/jagadeesh/grainlify/backend/internal/openapi"
+	"github.com/jagadeesh/grainlify/backend/internal/probe"
+	"github.com/jagadeesh/grainlify/backend/internal/ratelimit"
+	"github.com/jagadeesh/grainlify/backend/internal/realtime"
+	"github.com/jagadeesh/grainlify/backend/internal/shed"
+	"github.com/jagadeesh/grainlify/backend/internal/tracing"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
 )
 
 type Deps struct {
-	DB  *db.DB
-	Bus bus.Bus
+	DB      *db.DB
+	Bus     bus.Bus
+	Wallets wallet.Registry
+	Probes  *probe.Prober
+	// Recorder, when set, captures anonymized traffic for cmd/loadtest.
+	Recorder *loadtest.Recorder
+	// Limiter rate-limits the login endpoints; nil disables it.
+	Limiter *ratelimit.Limiter
+	// Jobs, when set, are the background jobs of an "all" mode process.
+	Jobs *jobs.Scheduler
+	// Invalidations, when set, keeps the public response caches coherent
+	// across instances; without it nothing is cached.
+	Invalidations *cache.Listener
+	// Realtime, when set, carries the events pushed to GET /ws clients;
+	// without it the endpoint answers 503.
+	Realtime *realtime.Broker
 }
 
-func New(cfg config.Config, deps Deps) *fiber.App {
+func New(cfg config.Config, deps Deps) (*fiber.App, error) {
 	slog.Info("initializing Fiber app",
 		"app_name", "grainlify-api",
 	)
+	// Client IPs come from ProxyHeader only on requests from a trusted proxy.
+	proxyHeader := ""
+	if len(cfg.TrustedProxies) > 0 {
+		proxyHeader = cfg.ProxyHeader
+	}
 	app := fiber.New(fiber.Config{
 		AppName:               "grainlify-api",
-		IdleTimeout:           120 * time.Second,  // Increased from 60s
-		ReadTimeout:           30 * time.Second,   // Increased from 10s
-		WriteTimeout:          30 * time.Second,   // Increased from 10s
-		DisableStartupMessage: true,               // Disable Fiber startup message
-		EnablePrintRoutes:     false,              // Disable route logging
+		IdleTimeout:           120 * time.Second, // Increased from 60s
+		ReadTimeout:           30 * time.Second,  // Increased from 10s
+		WriteTimeout:          30 * time.Second,  // Increased from 10s
+		DisableStartupMessage: true,              // Disable Fiber startup message
+		EnablePrintRoutes:     false,             // Disable route logging
 		ServerHeader:          "Grainlify-API",   // Add server header
-		ErrorHandler: func(ctx *fiber.Ctx, err error) error {
-			// Log the error
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-			slog.Error("API error",
-				"method", ctx.Method(),
-				"path", ctx.Path(),
-				"status", code,
-				"error", err,
-			)
-			return fiber.DefaultErrorHandler(ctx, err)
-		},
+		// Errors returned by handlers and middleware, and the router's
+		// own, get the common error envelope.
+		ErrorHandler: httpx.ErrorHandler,
+
+		ProxyHeader:             proxyHeader,
+		EnableTrustedProxyCheck: len(cfg.TrustedProxies) > 0,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 	slog.Info("Fiber app created")
 
-	// Baseline middleware.
-	app.Use(requestid.New())
+	// Baseline middleware. Metrics and tracing go first to time whole
+	// requests and see their final status. RequestLog assigns the request
+	// ID, gives the request its logger and writes the access log line.
+	app.Use(metrics.Middleware())
+	app.Use(tracing.Middleware())
+	app.Use(httpx.RequestLog())
+	app.Use(httpx.SecurityHeaders(time.Duration(cfg.HSTSMaxAgeSeconds) * time.Second))
 
 	// Add request logging middleware BEFORE recover to catch all requests
 	app.Use(func(c *fiber.Ctx) error {
 		// Log all incoming requests for debugging (especially webhooks)
 		if strings.HasPrefix(c.Path(), "/webhooks/") {
-			slog.Info("webhook request received",
+			httpx.Logger(c).Info("webhook request received",
 				"method", c.Method(),
 				"path", c.Path(),
 				"original_url", c.OriginalURL(),
@@ -73,61 +108,35 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	})
 
 	app.Use(recover.New())
-
-	// Configure CORS from environment variables
-	corsConfig := cors.Config{
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Admin-Bootstrap-Token",
-		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowCredentials: true,
+	if deps.Recorder != nil {
+		app.Use(deps.Recorder.Middleware())
 	}
-
-	// Always use AllowOriginsFunc so we can:
-	// - allow localhost for dev
-	// - allow explicit CORS_ORIGINS (comma-separated)
-	// - allow FrontendBaseURL
-	explicitOrigins := map[string]struct{}{}
-	if strings.TrimSpace(cfg.CORSOrigins) != "" {
-		for _, o := range strings.Split(cfg.CORSOrigins, ",") {
-			o = strings.TrimSpace(o)
-			if o == "" {
-				continue
-			}
-			explicitOrigins[o] = struct{}{}
-		}
+	if cfg.PprofToken != "" {
+		app.Use("/debug/pprof", handlers.RequireOpsToken(cfg.PprofToken))
+		app.Use(pprof.New())
 	}
-
-	corsConfig.AllowOriginsFunc = func(origin string) bool {
-		// Always allow localhost origins for development / local frontend testing.
-		if strings.HasPrefix(origin, "http://localhost:") ||
-			strings.HasPrefix(origin, "http://127.0.0.1:") ||
-			strings.HasPrefix(origin, "https://localhost:") ||
-			strings.HasPrefix(origin, "https://127.0.0.1:") {
-			return true
-		}
-
-		// Allow all Vercel preview deployments (*.vercel.app)
-		if strings.HasSuffix(origin, ".vercel.app") {
-			return true
-		}
-
-		// Check explicit CORS origins from config
-		if _, ok := explicitOrigins[origin]; ok {
-			return true
-		}
-
-		// If FrontendBaseURL is set, allow it (exact match or with path)
-		if cfg.FrontendBaseURL != "" {
-			frontendBase := strings.TrimSuffix(cfg.FrontendBaseURL, "/")
-			if origin == frontendBase || strings.HasPrefix(origin, frontendBase+"/") {
-				return true
-			}
-		}
-
-		return false
+	if chaos.Enabled {
+		app.Use(chaos.Latency())
 	}
 
-	app.Use(cors.New(corsConfig))
-	app.Use(logger.New())
+	// Browsers may call the API from the origins in cfg.CORS.
+	app.Use(cors.New(cors.Config{
+		AllowOriginsFunc: cfg.CORS.AllowsOrigin,
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Admin-Bootstrap-Token, X-API-Key, X-Request-ID, Idempotency-Key",
+		ExposeHeaders:    "X-Request-ID, Idempotent-Replayed",
+		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAgeSeconds,
+		// The public API sets its own, open CORS policy.
+		Next: isPublicAPI,
+	}))
+
+	// Every route's permission is declared in routePermissions and checked
+	// against its middleware once all routes are registered.
+	routeAuthz, err := authz.New(routePermissions)
+	if err != nil {
+		return nil, err
+	}
 
 	// Routes.
 	// Root handler - also handle POST requests to catch misconfigured webhooks
@@ -140,123 +149,488 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	})
 	app.Post("/", func(c *fiber.Ctx) error {
 		// Log POST requests to root - this helps identify if webhook URL is misconfigured
-		slog.Warn("POST request received at root path - webhook URL might be misconfigured",
+		httpx.Logger(c).Warn("POST request received at root path - webhook URL might be misconfigured",
 			"user_agent", c.Get("User-Agent"),
 			"x_github_event", c.Get("X-GitHub-Event"),
 			"x_github_delivery", c.Get("X-GitHub-Delivery"),
 			"remote_ip", c.IP(),
 		)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":       "webhook_url_misconfigured",
-			"message":     "Webhook requests should be sent to /webhooks/github, not /",
-			"correct_url": "/webhooks/github",
-		})
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "webhook_url_misconfigured").
+			WithMessage("Webhook requests should be sent to /webhooks/github, not /").
+			With("correct_url", "/webhooks/github"))
 	})
 	app.Get("/health", handlers.Health())
 	app.Get("/ready", handlers.Ready(deps.DB))
+	// Kubernetes probes: liveness checks nothing, readiness the database,
+	// Redis and GitHub.
+	app.Get("/healthz", handlers.Liveness("grainlify-api"))
+	app.Get("/readyz", handlers.Readiness(readinessDeps(deps.DB, deps.Limiter)))
+	if deps.Jobs != nil {
+		app.Get("/health/jobs", handlers.JobsHealth(deps.Jobs))
+	}
+	var pool *pgxpool.Pool
+	if deps.DB != nil {
+		pool = deps.DB.Pool
+	}
+	reportsHandler := handlers.NewReportsHandler(deps.DB)
+	app.Get("/metrics", handlers.Metrics(deps.Probes, cfg.MetricsToken, metrics.Default, metrics.PoolStats{Pool: pool}, deps.Limiter, deps.Jobs, deps.Invalidations, reportsHandler))
+
+	// Load shedding: under pool saturation low-priority routes get 503 +
+	// Retry-After; auth and payouts are tagged critical and never shed.
+	shedder := shed.New(pool, shed.Thresholds{
+		Wait:           time.Duration(cfg.ShedWaitThresholdMs) * time.Millisecond,
+		QueuePerSecond: float64(cfg.ShedQueueThreshold),
+	}, time.Duration(cfg.ShedRetryAfterSeconds)*time.Second)
+	low := shedder.Tag(shed.Low)
+	critical := shedder.Tag(shed.Critical)
+	// Retries of payout, bounty create and pay, wallet sign-in, and project
+	// and email verify requests sent with the same Idempotency-Key replay the
+	// first response instead of running again. Sign-in responses carry the
+	// issued tokens, so they are stored sealed with the token keys; without
+	// those keys sign-in is not idempotent.
+	keys := idempotency.New(pool, time.Duration(cfg.IdempotencyKeyTTLHours)*time.Hour)
+	idempotent := keys.Handler()
+	tokenRing, err := cryptox.ParseKeyring(cfg.TokenEncKeyB64, cfg.TokenEncKeys, cfg.TokenEncKeyActive)
+	if err != nil {
+		slog.Warn("wallet sign-in is not idempotent", "error", err)
+	}
+	idempotentSecret := keys.Sealed(tokenRing).Handler()
+	// Cached routes put the cache ahead of the shedder, so hits skip both.
+	caches := newAPICaches(cfg, deps.Invalidations)
+	mountPublicAPI(app, cfg, deps, caches, low)
+
+	authHandler := handlers.NewAuthHandler(cfg, deps.DB, caches.githubProfiles)
+	authGroup := app.Group("/auth", critical)
+	// Nonce and verify share their buckets, so a login costs two hits.
+	loginWindow := time.Duration(cfg.AuthRateLimitWindowSeconds) * time.Second
+	loginLimit := deps.Limiter.Handler(
+		ratelimit.Rule{Name: "auth_ip", Limit: cfg.AuthRateLimitPerIP, Window: loginWindow, Key: ratelimit.ByIP},
+		ratelimit.Rule{Name: "auth_address", Limit: cfg.AuthRateLimitPerAddress, Window: loginWindow, Key: ratelimit.BodyFields("wallet_type", "address")},
+	)
+	authGroup.Post("/nonce", loginLimit, authHandler.NonceChallenge(), authHandler.Nonce())
+	authGroup.Post("/verify", loginLimit, idempotentSecret, authHandler.Verify())
+	authGroup.Post("/refresh", authHandler.Refresh())
+	authGroup.Post("/logout", authHandler.Logout())
+	authGroup.Get("/wallets", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.ListWallets())
+	authGroup.Post("/wallets/link", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.LinkWallet())
+	authGroup.Put("/wallets/:id/primary", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.SetPrimaryWallet())
+	authGroup.Delete("/wallets/:id", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.UnlinkWallet())
+	authGroup.Get("/sessions", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.ListSessions())
+	authGroup.Delete("/sessions/:id", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.RevokeSession())
+	// Account recovery via verified email, for users who lost their wallets.
+	recoveryWindow := time.Hour
+	recoveryLimit := deps.Limiter.Handler(
+		ratelimit.Rule{Name: "recovery_ip", Limit: cfg.AccountRecoveryLimitPerHour, Window: recoveryWindow, Key: ratelimit.ByIP},
+		ratelimit.Rule{Name: "recovery_email", Limit: cfg.AccountRecoveryLimitPerHour, Window: recoveryWindow, Key: ratelimit.BodyFields("email")},
+	)
+	authGroup.Post("/recovery/start", recoveryLimit, authHandler.StartRecovery())
+	authGroup.Post("/recovery/request", recoveryLimit, authHandler.RequestRecovery())
+	authGroup.Post("/recovery/complete", recoveryLimit, authHandler.CompleteRecovery())
+	app.Post("/me/email", auth.RequireAuth(cfg.JWTSecret, pool), recoveryLimit, authHandler.StartEmailVerification())
+	app.Post("/me/email/verify", auth.RequireAuth(cfg.JWTSecret, pool), idempotent, authHandler.VerifyEmail())
+	app.Get("/me/recovery", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.MyRecovery())
+	app.Delete("/me/recovery", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.CancelMyRecovery())
+	apiKeys := apikeys.Authenticator{DB: deps.DB}
+	// Requests made with an API key also spend from the owner's plan tier bucket.
+	keyLimit := deps.Limiter.Buckets(ratelimit.BucketRule{Name: "api_key", Tiers: apiKeyTiers(cfg), Key: apiKeyOwner, Tier: apiKeyTier})
+	app.Get("/me", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeProfileRead), keyLimit, authHandler.Me())
+	app.Post("/me/github/resync", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.ResyncGitHubProfile())
+	app.Get("/me/github/repos", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.MyGitHubRepos())
+	app.Get("/me/github/contributions", auth.RequireAuth(cfg.JWTSecret, pool), authHandler.MyGitHubContributions())
+	// Linked GitHub organizations: their admins manage the org's projects.
+	githubOrgs := handlers.NewGitHubOrgsHandler(cfg, deps.DB)
+	app.Get("/me/github/orgs", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.Mine())
+	app.Post("/me/github/orgs", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.Link())
+	app.Post("/me/github/orgs/:id/sync", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.Sync())
+	app.Delete("/me/github/orgs/:id", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.Unlink())
+	// Custom roles granting org members permissions over the org's projects.
+	app.Get("/me/github/orgs/:id/roles", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.Roles())
+	app.Post("/me/github/orgs/:id/roles", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.CreateRole())
+	app.Put("/me/github/orgs/:id/roles/:role_id", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.UpdateRole())
+	app.Delete("/me/github/orgs/:id/roles/:role_id", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.DeleteRole())
+	app.Post("/me/github/orgs/:id/roles/:role_id/members", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.AssignRole())
+	app.Delete("/me/github/orgs/:id/roles/:role_id/members/:github_user_id", auth.RequireAuth(cfg.JWTSecret, pool), githubOrgs.UnassignRole())
+
+	geoHandler := handlers.NewGeoHandler(cfg, deps.DB)
+	app.Get("/me/country", auth.RequireAuth(cfg.JWTSecret, pool), geoHandler.MyCountry())
+	app.Put("/me/country", auth.RequireAuth(cfg.JWTSecret, pool), geoHandler.SetMyCountry())
+
+	auditHandler := handlers.NewAuditHandler(deps.DB)
+	app.Get("/users/me/audit", auth.RequireAuth(cfg.JWTSecret, pool), auditHandler.Mine())
+
+	// Abuse reports feed the admin moderation queue.
+	reportLimit := deps.Limiter.Handler(ratelimit.Rule{
+		Name:   "reports_user",
+		Limit:  cfg.ReportRateLimitPerHour,
+		Window: time.Hour,
+		Key: func(c *fiber.Ctx) string {
+			sub, _ := c.Locals(auth.LocalUserID).(string)
+			return sub
+		},
+	})
+	app.Post("/reports", auth.RequireAuth(cfg.JWTSecret, pool), reportLimit, reportsHandler.Create())
 
-	authHandler := handlers.NewAuthHandler(cfg, deps.DB)
-	authGroup := app.Group("/auth")
-	app.Get("/me", auth.RequireAuth(cfg.JWTSecret), authHandler.Me())
-	app.Post("/me/github/resync", auth.RequireAuth(cfg.JWTSecret), authHandler.ResyncGitHubProfile())
+	// Ledger integrity: public signed roots + per-user inclusion proofs.
+	ledgerHandler := handlers.NewLedgerHandler(deps.DB)
+	app.Get("/ledger/anchors", low, ledgerHandler.Anchors())
+	app.Get("/me/ledger/proofs", auth.RequireAuth(cfg.JWTSecret, pool), ledgerHandler.MyProofs())
 
 	// User profile endpoints
 	userProfile := handlers.NewUserProfileHandler(cfg, deps.DB)
-	app.Get("/profile", auth.RequireAuth(cfg.JWTSecret), userProfile.Profile())
-	app.Get("/profile/public", userProfile.PublicProfile()) // Public profile endpoint (no auth required)
-	app.Get("/profile/calendar", auth.RequireAuth(cfg.JWTSecret), userProfile.ContributionCalendar())
-	app.Get("/profile/activity", auth.RequireAuth(cfg.JWTSecret), userProfile.ContributionActivity())
-	app.Get("/profile/projects", auth.RequireAuth(cfg.JWTSecret), userProfile.ProjectsContributed())
-	app.Put("/profile/update", auth.RequireAuth(cfg.JWTSecret), userProfile.UpdateProfile())
-	app.Put("/profile/avatar", auth.RequireAuth(cfg.JWTSecret), userProfile.UpdateAvatar())
-
-	ghOAuth := handlers.NewGitHubOAuthHandler(cfg, deps.DB)
+	app.Get("/profile", auth.RequireAuth(cfg.JWTSecret, pool), userProfile.Profile())
+	app.Get("/profile/public", caches.profiles.Middleware(publicProfileKey, "user_id", "login"), userProfile.PublicProfile()) // Public profile endpoint (no auth required)
+	app.Get("/profile/calendar", auth.RequireAuth(cfg.JWTSecret, pool), userProfile.ContributionCalendar())
+	app.Get("/profile/activity", auth.RequireAuth(cfg.JWTSecret, pool), userProfile.ContributionActivity())
+	app.Get("/profile/projects", auth.RequireAuth(cfg.JWTSecret, pool), userProfile.ProjectsContributed())
+	app.Put("/profile/update", auth.RequireAuth(cfg.JWTSecret, pool), userProfile.UpdateProfile())
+	app.Put("/profile/avatar", auth.RequireAuth(cfg.JWTSecret, pool), userProfile.UpdateAvatar())
+
+	ghOAuth := handlers.NewGitHubOAuthHandler(cfg, deps.DB, caches.githubProfiles)
 	// GitHub-only login/signup:
 	authGroup.Get("/github/login/start", ghOAuth.LoginStart())
 	// Alias to unified callback (for backwards compatibility with older callback URLs).
 	authGroup.Get("/github/login/callback", ghOAuth.CallbackUnified())
 
 	// Legacy "link GitHub to existing account" endpoints (still available).
-	authGroup.Post("/github/start", auth.RequireAuth(cfg.JWTSecret), ghOAuth.Start())
+	authGroup.Post("/github/start", auth.RequireAuth(cfg.JWTSecret, pool), ghOAuth.Start())
 	authGroup.Get("/github/callback", ghOAuth.CallbackUnified())
-	authGroup.Get("/github/status", auth.RequireAuth(cfg.JWTSecret), ghOAuth.Status())
+	authGroup.Get("/github/status", auth.RequireAuth(cfg.JWTSecret, pool), ghOAuth.Status())
+	authGroup.Delete("/github", auth.RequireAuth(cfg.JWTSecret, pool), ghOAuth.Unlink())
+	// Device flow linking for CLI/headless users.
+	app.Post("/github/link/device", critical, auth.RequireAuth(cfg.JWTSecret, pool), ghOAuth.DeviceStart())
+	app.Post("/github/link/device/:id/poll", critical, auth.RequireAuth(cfg.JWTSecret, pool), ghOAuth.DevicePoll())
 
 	// GitHub App installation endpoints
 	ghApp := handlers.NewGitHubAppHandler(cfg, deps.DB)
-	authGroup.Post("/github/app/install/start", auth.RequireAuth(cfg.JWTSecret), ghApp.StartInstallation())
+	authGroup.Post("/github/app/install/start", auth.RequireAuth(cfg.JWTSecret, pool), ghApp.StartInstallation())
 	app.Get("/auth/github/app/install/callback", ghApp.HandleInstallationCallback())
 
 	// KYC verification endpoints
 	kyc := handlers.NewKYCHandler(cfg, deps.DB)
-	authGroup.Post("/kyc/start", auth.RequireAuth(cfg.JWTSecret), kyc.Start())
-	authGroup.Get("/kyc/status", auth.RequireAuth(cfg.JWTSecret), kyc.Status())
+	authGroup.Post("/kyc/start", auth.RequireAuth(cfg.JWTSecret, pool), kyc.Start())
+	authGroup.Get("/kyc/status", auth.RequireAuth(cfg.JWTSecret, pool), kyc.Status())
 
 	// Public ecosystems list (includes computed project_count and user_count).
 	ecosystems := handlers.NewEcosystemsPublicHandler(deps.DB)
-	app.Get("/ecosystems", ecosystems.ListActive())
+	app.Get("/ecosystems", low, ecosystems.ListActive())
+
+	// Public full-text search over projects and bounties.
+	searchHandler := handlers.NewSearchHandler(deps.DB)
+	app.Get("/search", low, searchHandler.Search())
 
 	// Open Source Week (public)
 	osw := handlers.NewOpenSourceWeekHandler(deps.DB)
-	app.Get("/open-source-week/events", osw.ListPublic())
-	app.Get("/open-source-week/events/:id", osw.GetPublic())
+	app.Get("/open-source-week/events", low, osw.ListPublic())
+	app.Get("/open-source-week/events/:id", low, osw.GetPublic())
+
+	// Achievement badges (public) and NFT metadata for minted ones
+	badgesHandler := handlers.NewBadgesHandler(cfg, deps.DB)
+	app.Get("/badges", low, badgesHandler.List())
+	app.Get("/badges/nft/:token_id", badgesHandler.TokenMetadata())
+
+	// EAS attestations of paid bounties (public)
+	attestations := handlers.NewAttestationsHandler(cfg, deps.DB, deps.Wallets)
+	app.Get("/users/:id/attestations", attestations.ForUser())
+	app.Get("/attestations/:uid", attestations.Get())
+	app.Get("/attestations/:uid/verify", attestations.Verify())
+
+	// Signed, verifiable contributor résumés (public)
+	resumes := handlers.NewResumeHandler(cfg, deps.DB)
+	app.Get("/users/:id/resume", low, resumes.Resume())
+	app.Get("/platform/keys", resumes.PlatformKeys())
+	app.Post("/resume/verify", resumes.Verify())
 
 	// Public leaderboard
 	leaderboard := handlers.NewLeaderboardHandler(deps.DB)
-	app.Get("/leaderboard", leaderboard.Leaderboard())
+	app.Get("/leaderboard", low, leaderboard.Leaderboard())
+	app.Get("/users/:id/stats", low, leaderboard.UserStats())
 
 	// Public landing stats
 	landingStats := handlers.NewLandingStatsHandler(deps.DB)
-	app.Get("/stats/landing", landingStats.Get())
+	app.Get("/stats/landing", low, landingStats.Get())
 
 	// Public projects list with filtering
 	projectsPublic := handlers.NewProjectsPublicHandler(cfg, deps.DB)
-	app.Get("/projects", projectsPublic.List())
-	app.Get("/projects/recommended", projectsPublic.Recommended())
-	app.Get("/projects/filters", projectsPublic.FilterOptions())
+	app.Get("/projects", low, projectsPublic.List())
+	app.Get("/projects/recommended", low, projectsPublic.Recommended())
+	app.Get("/projects/filters", low, projectsPublic.FilterOptions())
 
 	projects := handlers.NewProjectsHandler(cfg, deps.DB)
-	app.Post("/projects", auth.RequireAuth(cfg.JWTSecret), projects.Create())
+	app.Post("/projects", auth.RequireAuth(cfg.JWTSecret, pool), projects.Create())
 	// IMPORTANT: /projects/mine must come BEFORE /projects/:id to avoid route conflict
-	app.Get("/projects/mine", auth.RequireAuth(cfg.JWTSecret), projects.Mine())
+	app.Get("/projects/mine", auth.RequireAuth(cfg.JWTSecret, pool), projects.Mine())
 
 	// These routes with :id must come AFTER specific routes like /projects/mine
 	app.Get("/projects/:id", projectsPublic.Get())
-	app.Get("/projects/:id/issues/public", projectsPublic.IssuesPublic())
-	app.Get("/projects/:id/prs/public", projectsPublic.PRsPublic())
-	app.Post("/projects/:id/verify", auth.RequireAuth(cfg.JWTSecret), projects.Verify())
+	app.Patch("/projects/:id", auth.RequireAuth(cfg.JWTSecret, pool), projects.Update())
+	app.Delete("/projects/:id", auth.RequireAuth(cfg.JWTSecret, pool), projects.Delete())
+	app.Get("/projects/:id/issues/public", low, projectsPublic.IssuesPublic())
+	app.Get("/projects/:id/prs/public", low, projectsPublic.PRsPublic())
+	app.Get("/projects/:id/health", low, projectsPublic.Health())
+	app.Post("/projects/:id/verify", auth.RequireAuth(cfg.JWTSecret, pool), idempotent, projects.Verify())
 
 	sync := handlers.NewSyncHandler(deps.DB)
-	app.Post("/projects/:id/sync", auth.RequireAuth(cfg.JWTSecret), sync.EnqueueFullSync())
-	app.Get("/projects/:id/sync/jobs", auth.RequireAuth(cfg.JWTSecret), sync.JobsForProject())
+	app.Post("/projects/:id/sync", auth.RequireAuth(cfg.JWTSecret, pool), sync.EnqueueFullSync())
+	app.Get("/projects/:id/sync/jobs", auth.RequireAuth(cfg.JWTSecret, pool), sync.JobsForProject())
 
 	data := handlers.NewProjectDataHandler(deps.DB)
-	app.Get("/projects/:id/issues", auth.RequireAuth(cfg.JWTSecret), data.Issues())
-	app.Get("/projects/:id/prs", auth.RequireAuth(cfg.JWTSecret), data.PRs())
-	app.Get("/projects/:id/events", auth.RequireAuth(cfg.JWTSecret), data.Events())
+	app.Get("/projects/:id/issues", auth.RequireAuth(cfg.JWTSecret, pool), data.Issues())
+	app.Get("/projects/:id/prs", auth.RequireAuth(cfg.JWTSecret, pool), data.PRs())
+	app.Get("/projects/:id/events", auth.RequireAuth(cfg.JWTSecret, pool), data.Events())
 
 	issueApps := handlers.NewIssueApplicationsHandler(cfg, deps.DB)
-	app.Post("/projects/:id/issues/:number/apply", auth.RequireAuth(cfg.JWTSecret), issueApps.Apply())
+	app.Post("/projects/:id/issues/:number/apply", auth.RequireAuth(cfg.JWTSecret, pool), issueApps.Apply())
+
+	// Bounties attach to GitHub, Jira or Linear issues (issue_provider).
+	bountiesHandler := handlers.NewBountiesHandler(cfg, deps.DB)
+	app.Get("/projects/:id/bounties", caches.bounties.Middleware(projectBountiesKey, handlers.BountyListParams...), low, bountiesHandler.List())
+	// Private and unlisted bounties stay out of the list above; the hidden
+	// list is for maintainers, and a single bounty is only found by users
+	// who may see it (unlisted ones with their link's ?token=).
+	app.Get("/projects/:id/bounties/hidden", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Hidden())
+	app.Get("/projects/:id/bounties/:bounty_id", low, auth.OptionalAuth(cfg.JWTSecret, pool), bountiesHandler.Get())
+	app.Put("/projects/:id/bounties/:bounty_id/visibility", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.SetVisibility())
+	app.Post("/projects/:id/bounties/:bounty_id/link/rotate", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.RotateLink())
+	app.Get("/projects/:id/bounties/:bounty_id/invites", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Invites())
+	app.Post("/projects/:id/bounties/:bounty_id/invites", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Invite())
+	app.Delete("/projects/:id/bounties/:bounty_id/invites/:user_id", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Uninvite())
+	app.Get("/projects/:id/bounties/:bounty_id/advisory", low, auth.OptionalAuth(cfg.JWTSecret, pool), bountiesHandler.Advisory())
+	app.Put("/projects/:id/bounties/:bounty_id/advisory", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.PutAdvisory())
+	app.Post("/projects/:id/bounties/:bounty_id/advisory/publish", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.PublishAdvisory())
+	app.Get("/me/bounties/shared", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Shared())
+	app.Post("/projects/:id/bounties", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeBountiesWrite), keyLimit, idempotent, bountiesHandler.Create())
+	app.Post("/projects/:id/bounties/:bounty_id/cancel", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeBountiesWrite), keyLimit, bountiesHandler.Cancel())
+	// Lifecycle: contributors claim and submit, maintainers approve and pay.
+	app.Post("/projects/:id/bounties/:bounty_id/claim", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Claim())
+	app.Post("/projects/:id/bounties/:bounty_id/unclaim", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Unclaim())
+	app.Post("/projects/:id/bounties/:bounty_id/submit", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Submit())
+	app.Post("/projects/:id/bounties/:bounty_id/approve", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeBountiesWrite), keyLimit, bountiesHandler.Approve())
+	app.Post("/projects/:id/bounties/:bounty_id/pay", auth.RequireAuth(cfg.JWTSecret, pool), idempotent, bountiesHandler.Pay())
+	app.Put("/projects/:id/bounties/:bounty_id/skill-tags", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeBountiesWrite), keyLimit, bountiesHandler.SetSkillTags())
+	app.Delete("/projects/:id/bounties/:bounty_id/skill-tags", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeBountiesWrite), keyLimit, bountiesHandler.ResetSkillTags())
+	app.Put("/projects/:id/bounties/:bounty_id/metadata", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeBountiesWrite), keyLimit, bountiesHandler.SetMetadata())
+	// Splits of team bounties between a pull request's authors: maintainers
+	// suggest, claimants accept or adjust.
+	app.Post("/projects/:id/bounties/:bounty_id/split", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeBountiesWrite), keyLimit, bountiesHandler.SuggestSplit())
+	app.Get("/projects/:id/bounties/:bounty_id/split", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.GetSplit())
+	app.Post("/projects/:id/bounties/:bounty_id/split/accept", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.AcceptSplit())
+	app.Put("/projects/:id/bounties/:bounty_id/split/shares", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.AdjustSplit())
+	app.Post("/projects/:id/bounties/:bounty_id/escrow/lock", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.RecordEscrowLock())
+	app.Get("/projects/:id/bounties/:bounty_id/escrow/approval", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.EscrowApproval())
+	app.Post("/projects/:id/bounties/:bounty_id/escrow/release", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.ReleaseEscrow())
+	// End-to-end encrypted messages between the maintainer and the claimant;
+	// the server only stores ciphertext.
+	app.Get("/projects/:id/bounties/:bounty_id/messages", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Messages())
+	app.Post("/projects/:id/bounties/:bounty_id/messages", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.SendMessage())
+	// Time the claimant logs on their claim, summarized for maintainers and
+	// feeding the project's bounty rate suggestions.
+	app.Get("/projects/:id/bounties/:bounty_id/time", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.TimeLogged())
+	app.Post("/projects/:id/bounties/:bounty_id/time", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.LogTime())
+	app.Post("/projects/:id/bounties/:bounty_id/time/start", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.StartTimer())
+	app.Post("/projects/:id/bounties/:bounty_id/time/stop", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.StopTimer())
+	app.Delete("/projects/:id/bounties/:bounty_id/time/:entry_id", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.DeleteTimeEntry())
+	app.Get("/projects/:id/bounty-rates", auth.RequireAuth(cfg.JWTSecret, pool), bountiesHandler.Rates())
+	messagingKeys := handlers.NewMessagingKeysHandler(deps.DB)
+	app.Put("/me/messaging-key", auth.RequireAuth(cfg.JWTSecret, pool), messagingKeys.Publish())
+	app.Delete("/me/messaging-key", auth.RequireAuth(cfg.JWTSecret, pool), messagingKeys.Delete())
+	app.Get("/users/:id/messaging-key", auth.RequireAuth(cfg.JWTSecret, pool), messagingKeys.Get())
+	// Full timeline of a bounty for disputes and audits.
+	app.Get("/bounties/:id/history", auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopeBountiesRead), keyLimit, bountiesHandler.History())
+
+	issueProviders := handlers.NewIssueProvidersHandler(cfg, deps.DB)
+	authGroup.Post("/issues/:provider/start", auth.RequireAuth(cfg.JWTSecret, pool), issueProviders.Start())
+	authGroup.Get("/issues/:provider/callback", issueProviders.Callback())
+	app.Get("/me/issue-providers", auth.RequireAuth(cfg.JWTSecret, pool), issueProviders.List())
+	app.Delete("/me/issue-providers/:provider", auth.RequireAuth(cfg.JWTSecret, pool), issueProviders.Unlink())
+	app.Post("/me/issue-providers/:provider/webhook-secret", auth.RequireAuth(cfg.JWTSecret, pool), issueProviders.RotateWebhookSecret())
+
+	// Funding deposits: one HD-derived address per intent.
+	depositsHandler := handlers.NewDepositsHandler(cfg, deps.DB)
+	app.Post("/deposit-intents", auth.RequireAuth(cfg.JWTSecret, pool), depositsHandler.Create())
+	app.Get("/deposit-intents", auth.RequireAuth(cfg.JWTSecret, pool), depositsHandler.Mine())
+	app.Get("/deposit-intents/:id", auth.RequireAuth(cfg.JWTSecret, pool), depositsHandler.Get())
+	app.Get("/projects/:id/escrow", auth.RequireAuth(cfg.JWTSecret, pool), depositsHandler.ProjectEscrow())
+	app.Post("/projects/:id/escrow/addresses", auth.RequireAuth(cfg.JWTSecret, pool), depositsHandler.CreateEscrowAddress())
+
+	payoutsHandler := handlers.NewPayoutsHandler(cfg, deps.DB, deps.Wallets)
+	app.Get("/me/payouts", critical, auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopePayoutsRead), keyLimit, payoutsHandler.Mine())
+	app.Get("/me/payouts/preview", critical, auth.RequireAuth(cfg.JWTSecret, pool), payoutsHandler.Preview())
+	app.Get("/me/payouts/:id", critical, auth.RequireAuthOrAPIKey(cfg.JWTSecret, pool, apiKeys, apikeys.ScopePayoutsRead), keyLimit, payoutsHandler.MyPayout())
+	app.Get("/me/payout-methods", auth.RequireAuth(cfg.JWTSecret, pool), payoutsHandler.PayoutMethods())
+	app.Put("/me/payout-methods", auth.RequireAuth(cfg.JWTSecret, pool), payoutsHandler.SetPayoutMethods())
+	app.Get("/me/wallets/:id/balance", auth.RequireAuth(cfg.JWTSecret, pool), payoutsHandler.WalletBalance())
+	// Gasless claims: EIP-2612 permit signed by the owner, relayed by us.
+	app.Get("/relay/permit", critical, auth.RequireAuth(cfg.JWTSecret, pool), payoutsHandler.PermitRequest())
+	app.Post("/relay/permit-transfer", critical, auth.RequireAuth(cfg.JWTSecret, pool), idempotent, payoutsHandler.RelayClaim())
+	app.Get("/me/relayed-transfers", critical, auth.RequireAuth(cfg.JWTSecret, pool), payoutsHandler.MyRelayed())
+
+	// GitHub Sponsors: maintainer-connected listings and combined funding view
+	sponsorsHandler := handlers.NewSponsorsHandler(cfg, deps.DB)
+	app.Get("/me/sponsors", auth.RequireAuth(cfg.JWTSecret, pool), sponsorsHandler.List())
+	app.Post("/me/sponsors", auth.RequireAuth(cfg.JWTSecret, pool), sponsorsHandler.Connect())
+	app.Post("/me/sponsors/:id/sync", auth.RequireAuth(cfg.JWTSecret, pool), sponsorsHandler.Sync())
+	app.Delete("/me/sponsors/:id", auth.RequireAuth(cfg.JWTSecret, pool), sponsorsHandler.Disconnect())
+	app.Get("/me/funding", auth.RequireAuth(cfg.JWTSecret, pool), sponsorsHandler.Funding())
+
+	// No-code integrations (Zapier, Make): scoped API keys and polling triggers.
+	// Bots and CI use the same keys as bearer tokens on routes that take
+	// RequireAuthOrAPIKey. Minting keys always needs a JWT.
+	integrations := handlers.NewIntegrationsHandler(deps.DB)
+	authGroup.Get("/api-keys", auth.RequireAuth(cfg.JWTSecret, pool), integrations.ListKeys())
+	authGroup.Post("/api-keys", auth.RequireAuth(cfg.JWTSecret, pool), integrations.CreateKey())
+	authGroup.Delete("/api-keys/:id", auth.RequireAuth(cfg.JWTSecret, pool), integrations.RevokeKey())
+	app.Get("/me/api-keys", auth.RequireAuth(cfg.JWTSecret, pool), integrations.ListKeys())
+	app.Post("/me/api-keys", auth.RequireAuth(cfg.JWTSecret, pool), integrations.CreateKey())
+	app.Delete("/me/api-keys/:id", auth.RequireAuth(cfg.JWTSecret, pool), integrations.RevokeKey())
+	app.Get("/integrations/v1/me", auth.RequireAPIKey(apiKeys, ""), keyLimit, integrations.Me())
+	app.Get("/integrations/v1/triggers/bounty-events", low, auth.RequireAPIKey(apiKeys, apikeys.ScopeBountiesRead), keyLimit, integrations.BountyEvents())
+
+	// Slack app: workspace installs, /bounty slash command, channel notifications.
+	slackHandler := handlers.NewSlackHandler(cfg, deps.DB)
+	authGroup.Post("/slack/start", auth.RequireAuth(cfg.JWTSecret, pool), slackHandler.Start())
+	authGroup.Get("/slack/callback", slackHandler.Callback())
+	app.Get("/me/slack", auth.RequireAuth(cfg.JWTSecret, pool), slackHandler.List())
+	app.Delete("/me/slack/:id", auth.RequireAuth(cfg.JWTSecret, pool), slackHandler.Uninstall())
+	app.Post("/integrations/slack/commands", slackHandler.Command())
+
+	// Outbound notification channels (Matrix rooms) fed by the notify dispatcher.
+	notificationChannels := handlers.NewNotificationChannelsHandler(cfg, deps.DB)
+	app.Get("/me/notification-channels", auth.RequireAuth(cfg.JWTSecret, pool), notificationChannels.List())
+	app.Post("/me/notification-channels", auth.RequireAuth(cfg.JWTSecret, pool), notificationChannels.Create())
+	app.Delete("/me/notification-channels/:id", auth.RequireAuth(cfg.JWTSecret, pool), notificationChannels.Delete())
+	app.Post("/me/notification-channels/:id/test", auth.RequireAuth(cfg.JWTSecret, pool), notificationChannels.Test())
+
+	notificationsHandler := handlers.NewNotificationsHandler(deps.DB)
+	app.Get("/notifications", auth.RequireAuth(cfg.JWTSecret, pool), notificationsHandler.List())
+	app.Post("/notifications/read-all", auth.RequireAuth(cfg.JWTSecret, pool), notificationsHandler.MarkAllRead())
+	app.Post("/notifications/:id/read", auth.RequireAuth(cfg.JWTSecret, pool), notificationsHandler.MarkRead())
+	app.Get("/me/notification-preferences", auth.RequireAuth(cfg.JWTSecret, pool), notificationsHandler.Preferences())
+	app.Put("/me/notification-preferences", auth.RequireAuth(cfg.JWTSecret, pool), notificationsHandler.SetPreferences())
+
+	// Live bounty, comment and payout events over a WebSocket, instead of polling.
+	realtimeHandler := handlers.NewRealtimeHandler(deps.DB, deps.Realtime)
+	app.Get("/ws", realtimeHandler.Upgrade(), auth.RequireAuth(cfg.JWTSecret, pool), realtimeHandler.Serve())
+
+	// Outbound webhook endpoints fed by the webhooks dispatcher, with their delivery log.
+	webhookEndpoints := handlers.NewWebhooksHandler(cfg, deps.DB)
+	app.Get("/me/webhooks", auth.RequireAuth(cfg.JWTSecret, pool), webhookEndpoints.List())
+	app.Post("/me/webhooks", auth.RequireAuth(cfg.JWTSecret, pool), webhookEndpoints.Create())
+	app.Patch("/me/webhooks/:id", auth.RequireAuth(cfg.JWTSecret, pool), webhookEndpoints.Update())
+	app.Delete("/me/webhooks/:id", auth.RequireAuth(cfg.JWTSecret, pool), webhookEndpoints.Delete())
+	app.Get("/me/webhooks/:id/deliveries", auth.RequireAuth(cfg.JWTSecret, pool), webhookEndpoints.Deliveries())
+	app.Post("/me/webhooks/:id/deliveries/:delivery_id/redeliver", auth.RequireAuth(cfg.JWTSecret, pool), webhookEndpoints.Redeliver())
+
+	// Accounting endpoints receive signed proofs of the payouts a project funds.
+	accounting := handlers.NewAccountingHandler(cfg, deps.DB)
+	app.Get("/projects/:id/accounting-endpoint", auth.RequireAuth(cfg.JWTSecret, pool), accounting.GetEndpoint())
+	app.Put("/projects/:id/accounting-endpoint", auth.RequireAuth(cfg.JWTSecret, pool), accounting.PutEndpoint())
+	app.Delete("/projects/:id/accounting-endpoint", auth.RequireAuth(cfg.JWTSecret, pool), accounting.DeleteEndpoint())
+	app.Get("/projects/:id/payment-proofs", auth.RequireAuth(cfg.JWTSecret, pool), accounting.Proofs())
+	app.Post("/projects/:id/payment-proofs/:proof_id/redeliver", auth.RequireAuth(cfg.JWTSecret, pool), accounting.Redeliver())
+
+	// Custom metadata fields a project defines for itself and its bounties.
+	metadataHandler := handlers.NewMetadataHandler(deps.DB)
+	app.Get("/projects/:id/metadata-fields/:entity", auth.RequireAuth(cfg.JWTSecret, pool), metadataHandler.Fields())
+	app.Put("/projects/:id/metadata-fields/:entity", auth.RequireAuth(cfg.JWTSecret, pool), metadataHandler.PutFields())
+	app.Put("/projects/:id/metadata", auth.RequireAuth(cfg.JWTSecret, pool), metadataHandler.SetProject())
+
+	// Public status page data; components and incidents are managed by admins
+	// and the status checker.
+	statusHandler := handlers.NewStatusHandler(deps.DB)
+	app.Get("/status", statusHandler.Public())
 
 	admin := handlers.NewAdminHandler(cfg, deps.DB)
-	adminGroup := app.Group("/admin", auth.RequireAuth(cfg.JWTSecret))
+	adminGroup := app.Group("/admin", auth.RequireAuth(cfg.JWTSecret, pool), routeAuthz.Require())
 	adminGroup.Post("/bootstrap", admin.BootstrapAdmin())
-	adminGroup.Get("/users", auth.RequireRole("admin"), admin.ListUsers())
-	adminGroup.Put("/users/:id/role", auth.RequireRole("admin"), admin.SetUserRole())
+	adminGroup.Get("/route-permissions", routeAuthz.Handler())
+	adminGroup.Get("/users", admin.ListUsers())
+	adminGroup.Put("/users/:id/role", admin.SetUserRole())
+	adminGroup.Put("/users/:id/plan-tier", admin.SetUserPlanTier())
+	// Rotating the key stored GitHub tokens are sealed with.
+	adminGroup.Get("/tokens/keys", admin.TokenKeys())
+	adminGroup.Post("/tokens/rekey", admin.RekeyTokens())
+
+	adminGroup.Get("/audit", auditHandler.List())
+
+	savedReports := handlers.NewSavedReportsHandler(deps.DB)
+	adminGroup.Get("/saved-reports", savedReports.List())
+	adminGroup.Get("/saved-reports/:name", savedReports.Run())
+
+	adminUsers := handlers.NewAdminUsersHandler(deps.DB)
+	adminGroup.Get("/users/:id", adminUsers.Get())
+	adminGroup.Post("/users/:id/suspend", adminUsers.Suspend())
+	adminGroup.Post("/users/:id/ban", adminUsers.Ban())
+	adminGroup.Post("/users/:id/reinstate", adminUsers.Reinstate())
+	adminGroup.Post("/users/:id/sessions/expire", adminUsers.ExpireSessions())
+
+	adminGroup.Put("/status/components/:id", statusHandler.SetComponent())
+	adminGroup.Post("/status/incidents", statusHandler.CreateIncident())
+	adminGroup.Post("/status/incidents/:id/updates", statusHandler.AddIncidentUpdate())
+
+	moderationAdmin := handlers.NewModerationAdminHandler(deps.DB)
+	adminGroup.Get("/users/:id/moderation", moderationAdmin.History())
+	adminGroup.Post("/users/:id/shadow-ban", moderationAdmin.ShadowBan())
+	adminGroup.Delete("/users/:id/shadow-ban", moderationAdmin.LiftShadowBan())
+	adminGroup.Get("/geo/policies", geoHandler.Policies())
+	adminGroup.Put("/geo/policies/:action", geoHandler.SetPolicy())
+	adminGroup.Get("/reports", reportsHandler.Queue())
+	adminGroup.Get("/reports/:id/attachments/:attachment_id", reportsHandler.Attachment())
+	adminGroup.Post("/reports/:id/close", reportsHandler.Close())
 
 	ecosystemsAdmin := handlers.NewEcosystemsAdminHandler(deps.DB)
-	adminGroup.Get("/ecosystems", auth.RequireRole("admin"), ecosystemsAdmin.List())
-	adminGroup.Post("/ecosystems", auth.RequireRole("admin"), ecosystemsAdmin.Create())
-	adminGroup.Put("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.Update())
-	adminGroup.Delete("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.Delete())
+	adminGroup.Get("/ecosystems", ecosystemsAdmin.List())
+	adminGroup.Post("/ecosystems", ecosystemsAdmin.Create())
+	adminGroup.Put("/ecosystems/:id", ecosystemsAdmin.Update())
+	adminGroup.Delete("/ecosystems/:id", ecosystemsAdmin.Delete())
+
+	fraudAdmin := handlers.NewFraudAdminHandler(deps.DB)
+	adminGroup.Get("/deposits/cursors", depositsHandler.Cursors())
+
+	// Treasury: cold sweeps
+	treasuryAdmin := handlers.NewTreasuryAdminHandler(deps.DB, deps.Wallets)
+	adminGroup.Get("/treasury", treasuryAdmin.Dashboard())
+	adminGroup.Get("/treasury/escrow-reconciliation", treasuryAdmin.EscrowReconciliation())
+	adminGroup.Get("/treasury/sweep-destinations", treasuryAdmin.ListDestinations())
+	adminGroup.Post("/treasury/sweep-destinations", treasuryAdmin.CreateDestination())
+	adminGroup.Post("/treasury/sweep-destinations/:id/approve", treasuryAdmin.ApproveDestination())
+	adminGroup.Delete("/treasury/sweep-destinations/:id", treasuryAdmin.RevokeDestination())
+	adminGroup.Get("/treasury/sweep-policies", treasuryAdmin.ListPolicies())
+	adminGroup.Put("/treasury/sweep-policies", treasuryAdmin.UpsertPolicy())
+	adminGroup.Get("/treasury/sweeps", treasuryAdmin.ListSweeps())
+	adminGroup.Post("/treasury/sweeps/run", treasuryAdmin.RunSweeps())
+
+	adminGroup.Post("/badges", badgesHandler.Create())
+	adminGroup.Post("/badges/:slug/award", badgesHandler.Award())
+
+	// Payouts: queued per user, sent in batches per chain/asset
+	adminGroup.Get("/payouts", critical, payoutsHandler.List())
+	adminGroup.Post("/payouts", critical, idempotent, payoutsHandler.Create())
+	adminGroup.Get("/payouts/batches", critical, payoutsHandler.ListBatches())
+	adminGroup.Post("/payouts/run", critical, payoutsHandler.RunBatches())
+	adminGroup.Get("/payouts/windows", payoutsHandler.ListWindows())
+	adminGroup.Post("/payouts/windows", payoutsHandler.CreateWindow())
+	adminGroup.Delete("/payouts/windows/:id", payoutsHandler.DeleteWindow())
+	adminGroup.Get("/payouts/windows/runs", payoutsHandler.ListWindowRuns())
+	adminGroup.Get("/payouts/:id", critical, payoutsHandler.Get())
+	adminGroup.Post("/payouts/:id/cancel", critical, payoutsHandler.Cancel())
+	adminGroup.Post("/payouts/:id/retry", critical, idempotent, payoutsHandler.Retry())
+
+	adminGroup.Get("/fraud/facts", fraudAdmin.Facts())
+	adminGroup.Get("/fraud/rules", fraudAdmin.ListRules())
+	adminGroup.Post("/fraud/rules", fraudAdmin.CreateRule())
+	adminGroup.Put("/fraud/rules/:id", fraudAdmin.UpdateRule())
+	adminGroup.Delete("/fraud/rules/:id", fraudAdmin.DeleteRule())
+	adminGroup.Get("/fraud/reviews", fraudAdmin.ListReviews())
+	adminGroup.Post("/fraud/reviews/:id/resolve", fraudAdmin.ResolveReview())
 
 	projectsAdmin := handlers.NewProjectsAdminHandler(deps.DB)
-	adminGroup.Delete("/projects/:id", auth.RequireRole("admin"), projectsAdmin.Delete())
+	adminGroup.Delete("/projects/:id", projectsAdmin.Delete())
 
 	// Open Source Week (admin)
 	oswAdmin := handlers.NewOpenSourceWeekAdminHandler(deps.DB)
-	adminGroup.Get("/open-source-week/events", auth.RequireRole("admin"), oswAdmin.List())
-	adminGroup.Post("/open-source-week/events", auth.RequireRole("admin"), oswAdmin.Create())
-	adminGroup.Delete("/open-source-week/events/:id", auth.RequireRole("admin"), oswAdmin.Delete())
+	adminGroup.Get("/open-source-week/events", oswAdmin.List())
+	adminGroup.Post("/open-source-week/events", oswAdmin.Create())
+	adminGroup.Delete("/open-source-week/events/:id", oswAdmin.Delete())
 
 	webhooks := handlers.NewGitHubWebhooksHandler(cfg, deps.DB, deps.Bus)
 	// Register webhook endpoint with explicit OPTIONS support for CORS
@@ -269,32 +643,80 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	})
 	app.Post("/webhooks/github", webhooks.Receive())
 	app.Post("/webhooks/github/", webhooks.Receive())
+	app.Post("/webhooks/github/sponsors/:id", sponsorsHandler.Webhook())
+	app.Post("/webhooks/issues/:provider/:id", issueProviders.Webhook())
 
 	// Didit webhook handler (supports both GET callback redirects and POST webhook events)
 	diditWebhook := handlers.NewDiditWebhookHandler(cfg, deps.DB)
 	app.Get("/webhooks/didit", diditWebhook.Receive())
 	app.Post("/webhooks/didit", diditWebhook.Receive())
 
+	// Chain indexer address-activity webhooks (alchemy|helius|quicknode)
+	chainWebhooks := handlers.NewChainWebhooksHandler(cfg, deps.DB)
+	app.Post("/webhooks/chain/:provider", chainWebhooks.Receive())
+
+	// Machine-generated API reference and changelog, built from the routes
+	// above and their annotations.
+	ops := handlers.OpenAPIOperations()
+	changelog, err := openapi.BuildChangelog(ops, handlers.APIChanges())
+	if err != nil {
+		return nil, err
+	}
+	app.Get("/openapi.json", openapi.SpecHandler(app, openapi.Info{
+		Title:   "Grainlify API",
+		Version: "1.0.0",
+	}, cfg.PublicBaseURL, ops))
+	app.Get("/docs", openapi.UIHandler("Grainlify API", "/openapi.json"))
+	app.Get("/meta/changelog", openapi.ChangelogHandler(changelog))
+	app.Get("/meta/events", handlers.WebhookEventCatalog())
+
 	// Add catch-all 404 handler to log unmatched routes (helps debug routing issues)
 	app.Use(func(c *fiber.Ctx) error {
-		slog.Warn("unmatched route",
+		httpx.Logger(c).Warn("unmatched route",
 			"method", c.Method(),
 			"path", c.Path(),
 			"original_url", c.OriginalURL(),
 			"remote_ip", c.IP(),
 			"user_agent", c.Get("User-Agent"),
 		)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "not_found",
-			"path":  c.Path(),
-		})
+		return httpx.Write(c, httpx.New(fiber.StatusNotFound, "not_found").With("path", c.Path()))
 	})
 
+	if err := routeAuthz.Compile(openapi.Routes(app)); err != nil {
+		return nil, err
+	}
+
 	slog.Info("all routes registered",
 		"total_routes", "~30",
 		"db_configured", deps.DB != nil,
 		"nats_configured", deps.Bus != nil,
 	)
 
-	return app
+	return app, nil
+}
+
+// apiKeyTiers parses API_KEY_RATE_LIMIT_TIERS; a bad value disables the
+// buckets rather than keeping the API from starting.
+func apiKeyTiers(cfg config.Config) ratelimit.Tiers {
+	tiers, err := ratelimit.ParseTiers(cfg.APIKeyRateLimitTiers)
+	if err != nil {
+		slog.Error("api key rate limits disabled", "error", err)
+		return nil
+	}
+	return tiers
+}
+
+// apiKeyOwner keys API key requests on the account owning the key, so all
+// of an account's keys share its bucket. JWT requests aren't limited.
+func apiKeyOwner(c *fiber.Ctx) string {
+	if _, ok := c.Locals(auth.LocalPlanTier).(string); !ok {
+		return ""
+	}
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	return sub
+}
+
+func apiKeyTier(c *fiber.Ctx) string {
+	tier, _ := c.Locals(auth.LocalPlanTier).(string)
+	return tier
 }
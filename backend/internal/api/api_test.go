@@ -0,0 +1,39 @@
+package api
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/openapi"
+)
+
+// TestWalletSignInGuards fails when wallet sign-in loses its rate limit,
+// CAPTCHA/PoW challenge or idempotency middleware.
+func TestWalletSignInGuards(t *testing.T) {
+	app, err := New(config.Config{}, Deps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"/auth/nonce":  {"ratelimit.Limiter.Handler", "handlers.AuthHandler.NonceChallenge", "handlers.AuthHandler.Nonce"},
+		"/auth/verify": {"ratelimit.Limiter.Handler", "idempotency.Keys.Handler", "handlers.AuthHandler.Verify"},
+	}
+	for _, r := range app.GetRoutes() {
+		chain, ok := want[r.Path]
+		if !ok || r.Method != "POST" {
+			continue
+		}
+		delete(want, r.Path)
+		var got []string
+		for _, h := range r.Handlers {
+			got = append(got, openapi.HandlerName(h))
+		}
+		if !slices.Equal(got, chain) {
+			t.Errorf("POST %s runs %v, want %v", r.Path, got, chain)
+		}
+	}
+	for path := range want {
+		t.Errorf("POST %s is not mounted", path)
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"github.com/jagadeesh/grainlify/backend/internal/apikeys"
+	"github.com/jagadeesh/grainlify/backend/internal/authz"
+)
+
+// routePermissions is the permission every route requires. New's routes
+// are checked against it at startup: a route without an entry, or whose
+// middleware doesn't enforce its entry, keeps the API from starting. Admins
+// can review the result at GET /admin/route-permissions.
+var routePermissions = map[string]authz.Permission{
+	// Everything under /admin needs the admin role, enforced by the matrix
+	// for the whole group; bootstrapping is how the first admin gets it.
+	"* /admin/*":            authz.Role("admin"),
+	"POST /admin/bootstrap": authz.User,
+	// The public API never reads credentials.
+	"GET /public/v1/*": authz.Public,
+	// Webhooks check their senders' signatures or tokens.
+	"* /webhooks/*": authz.Verified,
+
+	"GET /":  authz.Public,
+	"POST /": authz.Public,
+
+	"GET /attestations/:uid":        authz.Public,
+	"GET /attestations/:uid/verify": authz.Public,
+
+	"GET /auth/api-keys":                    authz.User,
+	"POST /auth/api-keys":                   authz.User,
+	"DELETE /auth/api-keys/:id":             authz.User,
+	"DELETE /auth/github":                   authz.User,
+	"GET /auth/github/app/install/callback": authz.Verified,
+	"POST /auth/github/app/install/start":   authz.User,
+	"GET /auth/github/callback":             authz.Verified,
+	"GET /auth/github/login/callback":       authz.Verified,
+	"GET /auth/github/login/start":          authz.Public,
+	"POST /auth/github/start":               authz.User,
+	"GET /auth/github/status":               authz.User,
+	"GET /auth/issues/:provider/callback":   authz.Verified,
+	"POST /auth/issues/:provider/start":     authz.User,
+	"POST /auth/kyc/start":                  authz.User,
+	"GET /auth/kyc/status":                  authz.User,
+	"POST /auth/logout":                     authz.Verified,
+	"POST /auth/nonce":                      authz.Public,
+	"POST /auth/recovery/complete":          authz.Verified,
+	"POST /auth/recovery/request":           authz.Public,
+	"POST /auth/recovery/start":             authz.Public,
+	"POST /auth/refresh":                    authz.Verified,
+	"GET /auth/sessions":                    authz.User,
+	"DELETE /auth/sessions/:id":             authz.User,
+	"GET /auth/slack/callback":              authz.Verified,
+	"POST /auth/slack/start":                authz.User,
+	"POST /auth/verify":                     authz.Public,
+	"GET /auth/wallets":                     authz.User,
+	"DELETE /auth/wallets/:id":              authz.User,
+	"PUT /auth/wallets/:id/primary":         authz.User,
+	"POST /auth/wallets/link":               authz.User,
+
+	"GET /badges":               authz.Public,
+	"GET /badges/nft/:token_id": authz.Public,
+
+	"GET /bounties/:id/history": authz.Scope(apikeys.ScopeBountiesRead),
+
+	"GET /deposit-intents":     authz.User,
+	"POST /deposit-intents":    authz.User,
+	"GET /deposit-intents/:id": authz.User,
+
+	"GET /docs": authz.Public,
+
+	"GET /ecosystems": authz.Public,
+
+	"POST /github/link/device":          authz.User,
+	"POST /github/link/device/:id/poll": authz.User,
+
+	"GET /health":      authz.Public,
+	"GET /health/jobs": authz.Public,
+
+	"GET /healthz": authz.Public,
+
+	"POST /integrations/slack/commands":           authz.Verified,
+	"GET /integrations/v1/me":                     authz.Key(""),
+	"GET /integrations/v1/triggers/bounty-events": authz.Key(apikeys.ScopeBountiesRead),
+
+	"GET /leaderboard": authz.Public,
+
+	"GET /ledger/anchors": authz.Public,
+
+	"GET /me":                                         authz.Scope(apikeys.ScopeProfileRead),
+	"GET /me/api-keys":                                authz.User,
+	"GET /me/bounties/shared":                         authz.User,
+	"POST /me/api-keys":                               authz.User,
+	"DELETE /me/api-keys/:id":                         authz.User,
+	"GET /me/country":                                 authz.User,
+	"PUT /me/country":                                 authz.User,
+	"POST /me/email":                                  authz.User,
+	"POST /me/email/verify":                           authz.User,
+	"GET /me/funding":                                 authz.User,
+	"GET /me/github/contributions":                    authz.User,
+	"GET /me/github/orgs":                             authz.User,
+	"POST /me/github/orgs":                            authz.User,
+	"DELETE /me/github/orgs/:id":                      authz.User,
+	"POST /me/github/orgs/:id/sync":                   authz.User,
+	"GET /me/github/orgs/:id/roles":                   authz.User,
+	"POST /me/github/orgs/:id/roles":                  authz.User,
+	"PUT /me/github/orgs/:id/roles/:role_id":          authz.User,
+	"DELETE /me/github/orgs/:id/roles/:role_id":       authz.User,
+	"POST /me/github/orgs/:id/roles/:role_id/members": authz.User,
+	"DELETE /me/github/orgs/:id/roles/:role_id/members/:github_user_id": authz.User,
+	"GET /me/github/repos":                                    authz.User,
+	"POST /me/github/resync":                                  authz.User,
+	"GET /me/issue-providers":                                 authz.User,
+	"DELETE /me/issue-providers/:provider":                    authz.User,
+	"POST /me/issue-providers/:provider/webhook-secret":       authz.User,
+	"GET /me/ledger/proofs":                                   authz.User,
+	"PUT /me/messaging-key":                                   authz.User,
+	"DELETE /me/messaging-key":                                authz.User,
+	"GET /me/notification-channels":                           authz.User,
+	"POST /me/notification-channels":                          authz.User,
+	"DELETE /me/notification-channels/:id":                    authz.User,
+	"POST /me/notification-channels/:id/test":                 authz.User,
+	"GET /me/notification-preferences":                        authz.User,
+	"PUT /me/notification-preferences":                        authz.User,
+	"GET /notifications":                                      authz.User,
+	"POST /notifications/read-all":                            authz.User,
+	"POST /notifications/:id/read":                            authz.User,
+	"GET /ws":                                                 authz.User,
+	"GET /me/payout-methods":                                  authz.User,
+	"PUT /me/payout-methods":                                  authz.User,
+	"GET /me/payouts":                                         authz.Scope(apikeys.ScopePayoutsRead),
+	"GET /me/payouts/preview":                                 authz.User,
+	"GET /me/payouts/:id":                                     authz.Scope(apikeys.ScopePayoutsRead),
+	"GET /me/recovery":                                        authz.User,
+	"DELETE /me/recovery":                                     authz.User,
+	"GET /me/relayed-transfers":                               authz.User,
+	"GET /me/slack":                                           authz.User,
+	"DELETE /me/slack/:id":                                    authz.User,
+	"GET /me/sponsors":                                        authz.User,
+	"POST /me/sponsors":                                       authz.User,
+	"DELETE /me/sponsors/:id":                                 authz.User,
+	"POST /me/sponsors/:id/sync":                              authz.User,
+	"GET /me/wallets/:id/balance":                             authz.User,
+	"GET /me/webhooks":                                        authz.User,
+	"POST /me/webhooks":                                       authz.User,
+	"PATCH /me/webhooks/:id":                                  authz.User,
+	"DELETE /me/webhooks/:id":                                 authz.User,
+	"GET /me/webhooks/:id/deliveries":                         authz.User,
+	"POST /me/webhooks/:id/deliveries/:delivery_id/redeliver": authz.User,
+
+	"GET /meta/changelog": authz.Public,
+	"GET /meta/events":    authz.Public,
+
+	"GET /metrics": authz.Verified,
+
+	"GET /open-source-week/events":     authz.Public,
+	"GET /open-source-week/events/:id": authz.Public,
+
+	"GET /openapi.json": authz.Public,
+
+	"GET /platform/keys": authz.Public,
+
+	"GET /profile":          authz.User,
+	"GET /profile/activity": authz.User,
+	"PUT /profile/avatar":   authz.User,
+	"GET /profile/calendar": authz.User,
+	"GET /profile/projects": authz.User,
+	"GET /profile/public":   authz.Public,
+	"PUT /profile/update":   authz.User,
+
+	"GET /projects":                                             authz.Public,
+	"POST /projects":                                            authz.User,
+	"GET /projects/:id":                                         authz.Public,
+	"PATCH /projects/:id":                                       authz.User,
+	"DELETE /projects/:id":                                      authz.User,
+	"GET /projects/:id/accounting-endpoint":                     authz.User,
+	"PUT /projects/:id/accounting-endpoint":                     authz.User,
+	"DELETE /projects/:id/accounting-endpoint":                  authz.User,
+	"GET /projects/:id/bounty-rates":                            authz.User,
+	"GET /projects/:id/escrow":                                  authz.User,
+	"POST /projects/:id/escrow/addresses":                       authz.User,
+	"GET /projects/:id/bounties":                                authz.Public,
+	"POST /projects/:id/bounties":                               authz.Scope(apikeys.ScopeBountiesWrite),
+	"GET /projects/:id/bounties/hidden":                         authz.User,
+	"GET /projects/:id/bounties/:bounty_id":                     authz.Public,
+	"POST /projects/:id/bounties/:bounty_id/approve":            authz.Scope(apikeys.ScopeBountiesWrite),
+	"POST /projects/:id/bounties/:bounty_id/cancel":             authz.Scope(apikeys.ScopeBountiesWrite),
+	"POST /projects/:id/bounties/:bounty_id/claim":              authz.User,
+	"GET /projects/:id/bounties/:bounty_id/escrow/approval":     authz.User,
+	"GET /projects/:id/bounties/:bounty_id/messages":            authz.User,
+	"POST /projects/:id/bounties/:bounty_id/messages":           authz.User,
+	"POST /projects/:id/bounties/:bounty_id/escrow/lock":        authz.User,
+	"POST /projects/:id/bounties/:bounty_id/escrow/release":     authz.User,
+	"GET /projects/:id/bounties/:bounty_id/invites":             authz.User,
+	"POST /projects/:id/bounties/:bounty_id/invites":            authz.User,
+	"DELETE /projects/:id/bounties/:bounty_id/invites/:user_id": authz.User,
+	"GET /projects/:id/bounties/:bounty_id/advisory":            authz.Public,
+	"PUT /projects/:id/bounties/:bounty_id/advisory":            authz.User,
+	"POST /projects/:id/bounties/:bounty_id/advisory/publish":   authz.User,
+	"POST /projects/:id/bounties/:bounty_id/link/rotate":        authz.User,
+	"PUT /projects/:id/bounties/:bounty_id/metadata":            authz.Scope(apikeys.ScopeBountiesWrite),
+	"POST /projects/:id/bounties/:bounty_id/pay":                authz.User,
+	"PUT /projects/:id/bounties/:bounty_id/skill-tags":          authz.Scope(apikeys.ScopeBountiesWrite),
+	"DELETE /projects/:id/bounties/:bounty_id/skill-tags":       authz.Scope(apikeys.ScopeBountiesWrite),
+	"GET /projects/:id/bounties/:bounty_id/split":               authz.User,
+	"POST /projects/:id/bounties/:bounty_id/split":              authz.Scope(apikeys.ScopeBountiesWrite),
+	"POST /projects/:id/bounties/:bounty_id/split/accept":       authz.User,
+	"PUT /projects/:id/bounties/:bounty_id/split/shares":        authz.User,
+	"POST /projects/:id/bounties/:bounty_id/submit":             authz.User,
+	"GET /projects/:id/bounties/:bounty_id/time":                authz.User,
+	"POST /projects/:id/bounties/:bounty_id/time":               authz.User,
+	"POST /projects/:id/bounties/:bounty_id/time/start":         authz.User,
+	"POST /projects/:id/bounties/:bounty_id/time/stop":          authz.User,
+	"DELETE /projects/:id/bounties/:bounty_id/time/:entry_id":   authz.User,
+	"POST /projects/:id/bounties/:bounty_id/unclaim":            authz.User,
+	"PUT /projects/:id/bounties/:bounty_id/visibility":          authz.User,
+	"GET /projects/:id/events":                                  authz.User,
+	"GET /projects/:id/health":                                  authz.Public,
+	"GET /projects/:id/issues":                                  authz.User,
+	"POST /projects/:id/issues/:number/apply":                   authz.User,
+	"GET /projects/:id/issues/public":                           authz.Public,
+	"PUT /projects/:id/metadata":                                authz.User,
+	"GET /projects/:id/metadata-fields/:entity":                 authz.User,
+	"PUT /projects/:id/metadata-fields/:entity":                 authz.User,
+	"GET /projects/:id/payment-proofs":                          authz.User,
+	"POST /projects/:id/payment-proofs/:proof_id/redeliver":     authz.User,
+	"GET /projects/:id/prs":                                     authz.User,
+	"GET /projects/:id/prs/public":                              authz.Public,
+	"POST /projects/:id/sync":                                   authz.User,
+	"GET /projects/:id/sync/jobs":                               authz.User,
+	"POST /projects/:id/verify":                                 authz.User,
+	"GET /projects/filters":                                     authz.Public,
+	"GET /projects/mine":                                        authz.User,
+	"GET /projects/recommended":                                 authz.Public,
+
+	"GET /ready": authz.Public,
+
+	"GET /readyz": authz.Public,
+
+	"GET /relay/permit":           authz.User,
+	"POST /relay/permit-transfer": authz.User,
+
+	"POST /reports": authz.User,
+
+	"POST /resume/verify": authz.Public,
+
+	"GET /search": authz.Public,
+
+	"GET /stats/landing": authz.Public,
+
+	"GET /status": authz.Public,
+
+	"GET /users/:id/attestations":  authz.Public,
+	"GET /users/:id/messaging-key": authz.User,
+	"GET /users/:id/resume":        authz.Public,
+	"GET /users/:id/stats":         authz.Public,
+	"GET /users/me/audit":          authz.User,
+}
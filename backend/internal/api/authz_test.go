@@ -0,0 +1,15 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+)
+
+// TestRoutePermissions fails when a route is added without an entry in
+// routePermissions, or with middleware that doesn't match its entry.
+func TestRoutePermissions(t *testing.T) {
+	if _, err := New(config.Config{}, Deps{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cache"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+)
+
+// apiCaches are the in-memory caches handlers share. Each is nil, and
+// caches nothing, when disabled or when there is no invalidation listener.
+type apiCaches struct {
+	profiles       *cache.Cache
+	bounties       *cache.Cache
+	githubProfiles *cache.Cache
+	// public holds /public/v1 responses that have no invalidation topic.
+	public *cache.Cache
+}
+
+func newAPICaches(cfg config.Config, l *cache.Listener) apiCaches {
+	if l == nil {
+		return apiCaches{}
+	}
+	var ac apiCaches
+	if cfg.CacheTTLSeconds > 0 {
+		ttl := time.Duration(cfg.CacheTTLSeconds) * time.Second
+		ac.profiles = cache.New(cache.TopicProfile, ttl)
+		ac.bounties = cache.New(cache.TopicBounty, ttl)
+	}
+	if cfg.GitHubProfileCacheSeconds > 0 {
+		// Keyed by user id, which profile invalidations always include.
+		ac.githubProfiles = cache.New(cache.TopicProfile, time.Duration(cfg.GitHubProfileCacheSeconds)*time.Second)
+	}
+	if cfg.PublicCacheSeconds > 0 {
+		ac.public = cache.New(cache.TopicPublic, time.Duration(cfg.PublicCacheSeconds)*time.Second)
+	}
+	l.Register(ac.profiles, ac.bounties, ac.githubProfiles, ac.public)
+	return ac
+}
+
+// publicProfileKey matches the keys the cache_outbox triggers emit: the user
+// id, or the lower-cased GitHub login.
+func publicProfileKey(c *fiber.Ctx) string {
+	if id := strings.ToLower(strings.TrimSpace(c.Query("user_id"))); id != "" {
+		return id
+	}
+	if login := strings.ToLower(strings.TrimSpace(c.Query("login"))); login != "" {
+		return "login:" + login
+	}
+	return ""
+}
+
+func projectBountiesKey(c *fiber.Ctx) string {
+	return strings.ToLower(c.Params("id"))
+}
+
+// publicPathKey caches by path; the query parameters the route reads are
+// the variant.
+func publicPathKey(c *fiber.Ctx) string {
+	return c.Path()
+}
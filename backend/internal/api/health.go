@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/health"
+	"github.com/jagadeesh/grainlify/backend/internal/ratelimit"
+)
+
+// githubCheckTTL spaces out GitHub reachability checks across readiness
+// probes.
+const githubCheckTTL = 30 * time.Second
+
+// readinessDeps are what /readyz probes. Only the database is critical;
+// limiter is nil for processes without rate limits.
+func readinessDeps(d *db.DB, limiter *ratelimit.Limiter) []health.Dependency {
+	deps := []health.Dependency{{Name: "database", Critical: true}}
+	if d != nil && d.Pool != nil {
+		deps[0].Check = func(ctx context.Context) error { return d.Pool.Ping(ctx) }
+	}
+	redis := health.Dependency{Name: "redis"}
+	if limiter.Shared() {
+		redis.Check = limiter.Ping
+	}
+	gh := github.NewClient()
+	return append(deps, redis, health.Dependency{Name: "github", Check: health.Cached(githubCheckTTL, gh.Ping)})
+}
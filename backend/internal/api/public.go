@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/handlers"
+	"github.com/jagadeesh/grainlify/backend/internal/ratelimit"
+)
+
+// publicPrefix is the unauthenticated, read-only API: explore, profiles,
+// badges and feeds. It never reads credentials, so every response is the
+// same for every caller and may be cached by CDNs; it is rate limited per
+// IP in its own tier, apart from the authenticated routes.
+const publicPrefix = "/public/v1"
+
+func isPublicAPI(c *fiber.Ctx) bool {
+	return c.Path() == publicPrefix || strings.HasPrefix(c.Path(), publicPrefix+"/")
+}
+
+// publicCacheControl marks successful responses cacheable for ttl, with a
+// grace period in which CDNs may serve them stale while revalidating.
+func publicCacheControl(ttl time.Duration) fiber.Handler {
+	secs := int(ttl.Seconds())
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if secs > 0 && c.Response().StatusCode() == fiber.StatusOK {
+			c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d", secs, 2*secs))
+		} else {
+			c.Set(fiber.HeaderCacheControl, "no-store")
+		}
+		return nil
+	}
+}
+
+func mountPublicAPI(app *fiber.App, cfg config.Config, deps Deps, caches apiCaches, low fiber.Handler) {
+	pub := app.Group(publicPrefix,
+		// Any origin may read it, but without cookies or auth headers.
+		cors.New(cors.Config{
+			AllowOrigins: "*",
+			AllowMethods: "GET,HEAD,OPTIONS",
+			AllowHeaders: "Origin, Content-Type, Accept",
+		}),
+		deps.Limiter.Handler(ratelimit.Rule{
+			Name:   "public_ip",
+			Limit:  cfg.PublicRateLimitPerIP,
+			Window: time.Duration(cfg.PublicRateLimitWindowSeconds) * time.Second,
+			Key:    ratelimit.ByIP,
+		}),
+		publicCacheControl(time.Duration(cfg.PublicCacheSeconds)*time.Second),
+	)
+	// cached varies on the query parameters the route's handler reads.
+	cached := func(params ...string) fiber.Handler {
+		return caches.public.Middleware(publicPathKey, params...)
+	}
+
+	// Explore
+	projects := handlers.NewProjectsPublicHandler(cfg, deps.DB)
+	pub.Get("/projects", cached(handlers.ProjectsListParams...), low, projects.List())
+	pub.Get("/projects/recommended", cached("limit"), low, projects.Recommended())
+	pub.Get("/projects/filters", cached(), low, projects.FilterOptions())
+	pub.Get("/projects/:id", cached(), low, projects.Get())
+	pub.Get("/projects/:id/issues", cached(), low, projects.IssuesPublic())
+	pub.Get("/projects/:id/prs", cached(), low, projects.PRsPublic())
+	pub.Get("/ecosystems", cached(), low, handlers.NewEcosystemsPublicHandler(deps.DB).ListActive())
+	leaderboard := handlers.NewLeaderboardHandler(deps.DB)
+	pub.Get("/leaderboard", cached("window", "limit", "offset"), low, leaderboard.Leaderboard())
+	pub.Get("/stats", cached(), low, handlers.NewLandingStatsHandler(deps.DB).Get())
+
+	// Profiles
+	pub.Get("/profiles", caches.profiles.Middleware(publicProfileKey, "user_id", "login"), low, handlers.NewUserProfileHandler(cfg, deps.DB).PublicProfile())
+	pub.Get("/users/:id/attestations", cached(), low, handlers.NewAttestationsHandler(cfg, deps.DB, deps.Wallets).ForUser())
+	pub.Get("/users/:id/stats", cached(), low, leaderboard.UserStats())
+
+	// Badges
+	badges := handlers.NewBadgesHandler(cfg, deps.DB)
+	pub.Get("/badges", cached(), low, badges.List())
+	pub.Get("/badges/nft/:token_id", cached(), low, badges.TokenMetadata())
+
+	// Feeds
+	pub.Get("/projects/:id/bounties", caches.bounties.Middleware(projectBountiesKey, handlers.BountyListParams...), low, handlers.NewBountiesHandler(cfg, deps.DB).List())
+	osw := handlers.NewOpenSourceWeekHandler(deps.DB)
+	pub.Get("/open-source-week/events", cached(), low, osw.ListPublic())
+	pub.Get("/open-source-week/events/:id", cached(), low, osw.GetPublic())
+	pub.Get("/ledger/anchors", cached("limit"), low, handlers.NewLedgerHandler(deps.DB).Anchors())
+	pub.Get("/status", cached(), low, handlers.NewStatusHandler(deps.DB).Public())
+}
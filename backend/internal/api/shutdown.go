@@ -6,17 +6,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// Shutdown stops accepting connections and waits, until ctx is done, for
+// in-flight requests to finish.
 func Shutdown(ctx context.Context, app *fiber.App) error {
-	// Fiber's Shutdown doesn't accept a context; we use a goroutine + select to enforce it.
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- app.Shutdown()
-	}()
-
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-errCh:
-		return err
-	}
+	return app.ShutdownWithContext(ctx)
 }
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/handlers"
+	"github.com/jagadeesh/grainlify/backend/internal/metrics"
+)
+
+// NewWorker builds the HTTP surface of a worker-mode process: liveness,
+// readiness, background job health and metrics, without the public API.
+func NewWorker(cfg config.Config, deps Deps) *fiber.App {
+	app := fiber.New(fiber.Config{
+		AppName:               "grainlify-worker",
+		IdleTimeout:           120 * time.Second,
+		ReadTimeout:           10 * time.Second,
+		WriteTimeout:          10 * time.Second,
+		DisableStartupMessage: true,
+	})
+	app.Use(recover.New())
+
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok":      true,
+			"service": "grainlify-worker",
+		})
+	})
+	app.Get("/ready", handlers.Ready(deps.DB))
+	app.Get("/healthz", handlers.Liveness("grainlify-worker"))
+	app.Get("/readyz", handlers.Readiness(readinessDeps(deps.DB, nil)))
+	app.Get("/health/jobs", handlers.JobsHealth(deps.Jobs))
+	var pool *pgxpool.Pool
+	if deps.DB != nil {
+		pool = deps.DB.Pool
+	}
+	app.Get("/metrics", handlers.Metrics(deps.Probes, cfg.MetricsToken, metrics.Default, metrics.PoolStats{Pool: pool}, deps.Jobs))
+	return app
+}
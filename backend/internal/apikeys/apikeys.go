@@ -0,0 +1,236 @@
+// Package apikeys issues scoped personal API keys for no-code automation
+// platforms (Zapier, Make) that cannot run the wallet/GitHub login flow.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+)
+
+// KeyPrefix marks Grainlify API keys so they are recognisable in configs
+// and secret scanners.
+const KeyPrefix = "glf_"
+
+// legacyKeyPrefix was used by keys issued for the no-code integrations;
+// those keys keep working.
+const legacyKeyPrefix = "glk_"
+
+// Scopes an API key can be granted.
+const (
+	ScopeBountiesRead  = "bounties:read"
+	ScopeBountiesWrite = "bounties:write"
+	ScopePayoutsRead   = "payouts:read"
+	ScopeProfileRead   = "profile:read"
+)
+
+// AllScopes lists every grantable scope.
+var AllScopes = []string{ScopeBountiesRead, ScopeBountiesWrite, ScopePayoutsRead, ScopeProfileRead}
+
+// DefaultScopes are granted when a key is created without scopes.
+var DefaultScopes = []string{ScopeBountiesRead, ScopePayoutsRead, ScopeProfileRead}
+
+// maxKeysPerUser bounds how many active keys one account can hold.
+const maxKeysPerUser = 20
+
+var (
+	ErrInvalidKey   = errors.New("invalid_api_key")
+	ErrInvalidScope = errors.New("invalid_scope")
+	ErrMissingName  = errors.New("missing_name")
+	ErrTooManyKeys  = errors.New("too_many_api_keys")
+	ErrNotFound     = errors.New("api_key_not_found")
+)
+
+// Key is an API key as listed to its owner; the secret is never returned
+// after creation.
+type Key struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Tier is the owner's plan tier, set by Authenticate for rate limiting.
+	Tier string `json:"-"`
+}
+
+// NormalizeScopes validates and deduplicates requested scopes. An empty
+// request grants every read scope.
+func NormalizeScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return append([]string(nil), DefaultScopes...), nil
+	}
+	known := map[string]struct{}{}
+	for _, s := range AllScopes {
+		known[s] = struct{}{}
+	}
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(requested))
+	for _, s := range requested {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if _, ok := known[s]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, s)
+		}
+		if _, dup := seen[s]; dup {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// IsKey reports whether raw has the shape of an API key (either prefix).
+func IsKey(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	return strings.HasPrefix(raw, KeyPrefix) || strings.HasPrefix(raw, legacyKeyPrefix)
+}
+
+func hashKey(raw string) []byte {
+	sum := sha256.Sum256([]byte(raw))
+	return sum[:]
+}
+
+// generate returns a new plaintext key and its display prefix.
+func generate() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	raw := KeyPrefix + hex.EncodeToString(b)
+	return raw, raw[:len(KeyPrefix)+8], nil
+}
+
+const keyColumns = `id, user_id, name, prefix, scopes, last_used_at, revoked_at, created_at`
+
+func scanKey(row pgx.Row) (Key, error) {
+	var k Key
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.Scopes, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt)
+	return k, err
+}
+
+// Create issues a key for userID and returns it with the plaintext secret,
+// which is shown exactly once.
+func Create(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, name string, scopes []string) (Key, string, error) {
+	if pool == nil {
+		return Key{}, "", fmt.Errorf("db not configured")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Key{}, "", ErrMissingName
+	}
+	scopes, err := NormalizeScopes(scopes)
+	if err != nil {
+		return Key{}, "", err
+	}
+	var active int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL`, userID).Scan(&active); err != nil {
+		return Key{}, "", err
+	}
+	if active >= maxKeysPerUser {
+		return Key{}, "", ErrTooManyKeys
+	}
+	raw, prefix, err := generate()
+	if err != nil {
+		return Key{}, "", err
+	}
+	k, err := scanKey(pool.QueryRow(ctx, `
+INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING `+keyColumns, userID, name, prefix, hashKey(raw), scopes))
+	if err != nil {
+		return Key{}, "", err
+	}
+	return k, raw, nil
+}
+
+// List returns userID's keys, newest first, including revoked ones.
+func List(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Key, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Key{}
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// Revoke disables one of userID's keys. Revoking twice is not an error.
+func Revoke(ctx context.Context, pool *pgxpool.Pool, userID, id uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `
+UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now())
+WHERE id = $1 AND user_id = $2
+`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext key to its active record and stamps
+// last_used_at (at most once a minute). Keys of banned or suspended users
+// fail with moderation.ErrAccountBanned or ErrAccountSuspended.
+func Authenticate(ctx context.Context, pool *pgxpool.Pool, raw string) (Key, error) {
+	if pool == nil {
+		return Key{}, fmt.Errorf("db not configured")
+	}
+	raw = strings.TrimSpace(raw)
+	if !IsKey(raw) {
+		return Key{}, ErrInvalidKey
+	}
+	var (
+		k                        Key
+		bannedAt, suspendedUntil *time.Time
+	)
+	err := pool.QueryRow(ctx, `
+SELECT `+keyColumns+`, u.plan_tier, u.banned_at, u.suspended_until
+FROM api_keys
+CROSS JOIN LATERAL (SELECT plan_tier, banned_at, suspended_until FROM users WHERE users.id = api_keys.user_id) u
+WHERE key_hash = $1 AND revoked_at IS NULL
+`, hashKey(raw)).Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.Scopes, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt, &k.Tier, &bannedAt, &suspendedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Key{}, ErrInvalidKey
+	}
+	if err != nil {
+		return Key{}, err
+	}
+	if _, err := moderation.AccountAccess(bannedAt, suspendedUntil, time.Now()); err != nil {
+		return Key{}, err
+	}
+	_, _ = pool.Exec(ctx, `
+UPDATE api_keys SET last_used_at = now()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+`, k.ID)
+	return k, nil
+}
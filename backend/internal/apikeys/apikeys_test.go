@@ -0,0 +1,75 @@
+package apikeys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+)
+
+func TestNormalizeScopes(t *testing.T) {
+	got, err := NormalizeScopes(nil)
+	if err != nil || len(got) != len(DefaultScopes) {
+		t.Fatalf("default scopes = %v, %v", got, err)
+	}
+
+	got, err = NormalizeScopes([]string{" Bounties:Read ", "bounties:read"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != ScopeBountiesRead {
+		t.Fatalf("got %v", got)
+	}
+
+	if _, err := NormalizeScopes([]string{"admin"}); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("expected ErrInvalidScope, got %v", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	raw, prefix, err := generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(raw, KeyPrefix) || !strings.HasPrefix(raw, prefix) || len(prefix) >= len(raw) {
+		t.Fatalf("raw=%q prefix=%q", raw, prefix)
+	}
+	other, _, _ := generate()
+	if other == raw {
+		t.Fatal("keys are not random")
+	}
+}
+
+func TestIsKey(t *testing.T) {
+	for raw, want := range map[string]bool{
+		KeyPrefix + "00112233":       true,
+		" glk_00112233 ":             true,
+		"eyJhbGciOiJIUzI1NiJ9.e30.x": false,
+		"":                           false,
+	} {
+		if got := IsKey(raw); got != want {
+			t.Errorf("IsKey(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestRestrictedOwner(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	for name, tc := range map[string]struct {
+		bannedAt, suspendedUntil *time.Time
+		want                     bool
+	}{
+		"active":            {nil, nil, false},
+		"banned":            {&past, nil, true},
+		"suspended":         {nil, &future, true},
+		"suspension lapsed": {nil, &past, false},
+	} {
+		_, err := moderation.AccountAccess(tc.bannedAt, tc.suspendedUntil, now)
+		if got := restricted(err); got != tc.want {
+			t.Errorf("%s: restricted = %v (%v), want %v", name, got, err, tc.want)
+		}
+	}
+}
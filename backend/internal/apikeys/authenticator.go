@@ -0,0 +1,46 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+)
+
+// Authenticator resolves API keys for auth.RequireAPIKey and
+// auth.RequireAuthOrAPIKey.
+type Authenticator struct {
+	DB *db.DB
+}
+
+func (a Authenticator) IsAPIKey(token string) bool { return IsKey(token) }
+
+// AuthenticateAPIKey resolves token to its owner and the owner's role.
+func (a Authenticator) AuthenticateAPIKey(ctx context.Context, token string) (auth.APIKey, error) {
+	if a.DB == nil || a.DB.Pool == nil {
+		return auth.APIKey{}, errors.New("db not configured")
+	}
+	k, err := Authenticate(ctx, a.DB.Pool, token)
+	if errors.Is(err, ErrInvalidKey) {
+		return auth.APIKey{}, auth.ErrInvalidAPIKey
+	}
+	if restricted(err) {
+		return auth.APIKey{}, auth.ErrAccountRestricted
+	}
+	if err != nil {
+		return auth.APIKey{}, err
+	}
+	var role string
+	if err := a.DB.Pool.QueryRow(ctx, `SELECT role FROM users WHERE id = $1`, k.UserID).Scan(&role); err != nil {
+		return auth.APIKey{}, err
+	}
+	return auth.APIKey{ID: k.ID, UserID: k.UserID, Name: k.Name, Role: role, Scopes: k.Scopes, Tier: k.Tier}, nil
+}
+
+// restricted reports whether err is Authenticate rejecting a banned or
+// suspended owner.
+func restricted(err error) bool {
+	return errors.Is(err, moderation.ErrAccountBanned) || errors.Is(err, moderation.ErrAccountSuspended)
+}
@@ -0,0 +1,303 @@
+package attest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusSubmitted = "submitted"
+	StatusAttested  = "attested"
+	StatusFailed    = "failed"
+)
+
+var ErrNotFound = errors.New("attestation_not_found")
+
+// Signer submits contract calls from the platform key (wallet.EVMSender).
+type Signer interface {
+	Chain() string
+	SignerAddress() string
+	Client() *ethclient.Client
+	SendRaw(ctx context.Context, to common.Address, value *big.Int, data []byte) (string, error)
+}
+
+type Attestation struct {
+	ID        uuid.UUID `json:"id"`
+	PayoutID  uuid.UUID `json:"payout_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Recipient string    `json:"recipient"`
+	Repo      string    `json:"repo_full_name"`
+	PRNumber  int       `json:"pr_number"`
+	PRURL     string    `json:"pr_url"`
+	Chain     *string   `json:"chain,omitempty"`
+	SchemaUID *string   `json:"schema_uid,omitempty"`
+	Status    string    `json:"status"`
+	TxHash    *string   `json:"tx_hash,omitempty"`
+	UID       *string   `json:"uid,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (a Attestation) work() Work {
+	return Work{Contributor: a.Recipient, Repo: a.Repo, PRNumber: uint64(a.PRNumber), PRURL: a.PRURL, PayoutID: a.PayoutID}
+}
+
+const attestationColumns = `id, payout_id, user_id, recipient, repo_full_name, pr_number, pr_url, chain, schema_uid, status, tx_hash, uid, created_at`
+
+func scanAttestation(row pgx.Row) (Attestation, error) {
+	var a Attestation
+	err := row.Scan(&a.ID, &a.PayoutID, &a.UserID, &a.Recipient, &a.Repo, &a.PRNumber, &a.PRURL, &a.Chain, &a.SchemaUID, &a.Status, &a.TxHash, &a.UID, &a.CreatedAt)
+	return a, err
+}
+
+// ListForUser returns a user's published attestations, newest first.
+func ListForUser(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Attestation, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+attestationColumns+` FROM attestations WHERE user_id = $1 AND status = 'attested' ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Attestation{}
+	for rows.Next() {
+		a, err := scanAttestation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func GetByUID(ctx context.Context, pool *pgxpool.Pool, uid string) (Attestation, error) {
+	if pool == nil {
+		return Attestation{}, fmt.Errorf("db not configured")
+	}
+	a, err := scanAttestation(pool.QueryRow(ctx, `SELECT `+attestationColumns+` FROM attestations WHERE uid = $1`, strings.ToLower(uid)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Attestation{}, ErrNotFound
+	}
+	return a, err
+}
+
+// Attester publishes pending attestations and confirms submitted ones.
+type Attester struct {
+	Pool     *pgxpool.Pool
+	Signer   Signer
+	Contract common.Address
+	Schema   [32]byte
+}
+
+func (at *Attester) RunOnce(ctx context.Context) error {
+	if at.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	if err := at.confirm(ctx); err != nil {
+		return err
+	}
+	rows, err := at.Pool.Query(ctx, `SELECT `+attestationColumns+` FROM attestations WHERE status = 'pending' ORDER BY created_at LIMIT 25`)
+	if err != nil {
+		return err
+	}
+	var pending []Attestation
+	for rows.Next() {
+		a, err := scanAttestation(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	schemaHex := common.Hash(at.Schema).Hex()
+	for _, a := range pending {
+		data, err := EncodeWork(a.work())
+		if err == nil {
+			data, err = packAttest(at.Schema, common.HexToAddress(a.Recipient), data)
+		}
+		var txHash string
+		if err == nil {
+			txHash, err = at.Signer.SendRaw(ctx, at.Contract, big.NewInt(0), data)
+		}
+		if err != nil {
+			slog.Error("attestation submit failed", "attestation_id", a.ID.String(), "error", err)
+			_, _ = at.Pool.Exec(ctx, `UPDATE attestations SET status = 'failed', error = $2, updated_at = now() WHERE id = $1`, a.ID, err.Error())
+			continue
+		}
+		if _, err := at.Pool.Exec(ctx, `
+UPDATE attestations SET status = 'submitted', chain = $2, schema_uid = $3, tx_hash = $4, updated_at = now()
+WHERE id = $1
+`, a.ID, at.Signer.Chain(), schemaHex, txHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirm reads receipts of submitted attestations and records their UIDs.
+func (at *Attester) confirm(ctx context.Context) error {
+	rows, err := at.Pool.Query(ctx, `SELECT id, tx_hash FROM attestations WHERE status = 'submitted' AND tx_hash IS NOT NULL ORDER BY created_at LIMIT 100`)
+	if err != nil {
+		return err
+	}
+	type sub struct {
+		id   uuid.UUID
+		hash string
+	}
+	var subs []sub
+	for rows.Next() {
+		var s sub
+		if err := rows.Scan(&s.id, &s.hash); err != nil {
+			rows.Close()
+			return err
+		}
+		subs = append(subs, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range subs {
+		rcpt, err := at.Signer.Client().TransactionReceipt(ctx, common.HexToHash(s.hash))
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if rcpt.Status != types.ReceiptStatusSuccessful {
+			_, _ = at.Pool.Exec(ctx, `UPDATE attestations SET status = 'failed', error = 'transaction reverted', updated_at = now() WHERE id = $1`, s.id)
+			continue
+		}
+		uid, ok := attestedUID(rcpt, at.Contract)
+		if !ok {
+			_, _ = at.Pool.Exec(ctx, `UPDATE attestations SET status = 'failed', error = 'no Attested event in receipt', updated_at = now() WHERE id = $1`, s.id)
+			continue
+		}
+		if _, err := at.Pool.Exec(ctx, `UPDATE attestations SET status = 'attested', uid = $2, updated_at = now() WHERE id = $1`, s.id, strings.ToLower(uid.Hex())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func attestedUID(rcpt *types.Receipt, contract common.Address) (common.Hash, bool) {
+	for _, l := range rcpt.Logs {
+		if l.Address == contract && len(l.Topics) > 0 && l.Topics[0] == attestedTopic && len(l.Data) >= 32 {
+			return common.BytesToHash(l.Data[:32]), true
+		}
+	}
+	return common.Hash{}, false
+}
+
+// Verification compares the on-chain attestation with the platform's record.
+type Verification struct {
+	UID    string          `json:"uid"`
+	Valid  bool            `json:"valid"`
+	Checks map[string]bool `json:"checks"`
+	Work   *Work           `json:"work,omitempty"`
+	// Attester is the address third parties should trust for this schema.
+	Attester  string `json:"attester"`
+	Schema    string `json:"schema_uid"`
+	Timestamp uint64 `json:"timestamp,omitempty"`
+}
+
+// Verify reads uid from the EAS contract and checks schema, attester,
+// revocation and that recipient and data match the stored record.
+func (at *Attester) Verify(ctx context.Context, uid string) (Verification, error) {
+	id, err := ParseUID(uid)
+	if err != nil {
+		return Verification{}, err
+	}
+	v := Verification{
+		UID:      strings.ToLower(uid),
+		Checks:   map[string]bool{},
+		Attester: strings.ToLower(at.Signer.SignerAddress()),
+		Schema:   common.Hash(at.Schema).Hex(),
+	}
+
+	call, err := easABI.Pack("getAttestation", id)
+	if err != nil {
+		return Verification{}, err
+	}
+	out, err := at.Signer.Client().CallContract(ctx, ethereum.CallMsg{To: &at.Contract, Data: call}, nil)
+	if err != nil {
+		return Verification{}, fmt.Errorf("getAttestation: %w", err)
+	}
+	oc, err := unpackGetAttestation(out)
+	if err != nil {
+		return Verification{}, err
+	}
+
+	v.Checks["exists"] = oc.UID == id
+	v.Checks["schema"] = oc.Schema == at.Schema
+	v.Checks["attester"] = strings.EqualFold(oc.Attester.Hex(), at.Signer.SignerAddress())
+	v.Checks["not_revoked"] = oc.RevocationTime == 0
+	v.Timestamp = oc.Time
+
+	work, decodeErr := DecodeWork(oc.Data)
+	v.Checks["data_decodes"] = decodeErr == nil
+	if decodeErr == nil {
+		v.Work = &work
+	}
+
+	rec, err := GetByUID(ctx, at.Pool, uid)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		v.Checks["matches_record"] = false
+	case err != nil:
+		return Verification{}, err
+	default:
+		v.Checks["matches_record"] = decodeErr == nil &&
+			strings.EqualFold(oc.Recipient.Hex(), rec.Recipient) &&
+			work == rec.work()
+	}
+
+	v.Valid = true
+	for _, ok := range v.Checks {
+		v.Valid = v.Valid && ok
+	}
+	return v, nil
+}
+
+// New builds an Attester for chain from the hot wallet registry.
+func New(pool *pgxpool.Pool, wallets wallet.Registry, chain, contract, schemaUID string) (*Attester, error) {
+	sender, ok := wallets.Get(chain)
+	if !ok {
+		return nil, fmt.Errorf("no hot wallet for %s", chain)
+	}
+	signer, ok := sender.(Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an evm chain", chain)
+	}
+	if !common.IsHexAddress(contract) {
+		return nil, fmt.Errorf("invalid eas contract %q", contract)
+	}
+	schema, err := ParseUID(schemaUID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid eas schema uid: %w", err)
+	}
+	return &Attester{Pool: pool, Signer: signer, Contract: common.HexToAddress(contract), Schema: schema}, nil
+}
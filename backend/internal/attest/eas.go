@@ -0,0 +1,177 @@
+// Package attest publishes Ethereum Attestation Service (EAS) attestations
+// for paid bounties, giving contributors a portable, verifiable work history.
+package attest
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// SchemaDefinition is the EAS schema to register for SchemaUID.
+const SchemaDefinition = "address contributor,string repo,uint64 prNumber,string prUrl,bytes32 payoutId"
+
+var easABI = func() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(`[
+{"type":"function","name":"attest","inputs":[{"name":"request","type":"tuple","components":[
+	{"name":"schema","type":"bytes32"},
+	{"name":"data","type":"tuple","components":[
+		{"name":"recipient","type":"address"},
+		{"name":"expirationTime","type":"uint64"},
+		{"name":"revocable","type":"bool"},
+		{"name":"refUID","type":"bytes32"},
+		{"name":"data","type":"bytes"},
+		{"name":"value","type":"uint256"}]}]}],
+ "outputs":[{"name":"","type":"bytes32"}]},
+{"type":"function","name":"getAttestation","inputs":[{"name":"uid","type":"bytes32"}],
+ "outputs":[{"name":"","type":"tuple","components":[
+	{"name":"uid","type":"bytes32"},
+	{"name":"schema","type":"bytes32"},
+	{"name":"time","type":"uint64"},
+	{"name":"expirationTime","type":"uint64"},
+	{"name":"revocationTime","type":"uint64"},
+	{"name":"refUID","type":"bytes32"},
+	{"name":"recipient","type":"address"},
+	{"name":"attester","type":"address"},
+	{"name":"revocable","type":"bool"},
+	{"name":"data","type":"bytes"}]}]}
+]`))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+// attestedTopic is Attested(address indexed recipient, address indexed attester, bytes32 uid, bytes32 indexed schemaUID).
+var attestedTopic = crypto.Keccak256Hash([]byte("Attested(address,address,bytes32,bytes32)"))
+
+var schemaArgs = func() abi.Arguments {
+	mk := func(t string) abi.Type {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		return typ
+	}
+	return abi.Arguments{
+		{Name: "contributor", Type: mk("address")},
+		{Name: "repo", Type: mk("string")},
+		{Name: "prNumber", Type: mk("uint64")},
+		{Name: "prUrl", Type: mk("string")},
+		{Name: "payoutId", Type: mk("bytes32")},
+	}
+}()
+
+// Work is the attested claim: contributor address, repo and merged PR, tied
+// to the payout that paid for it.
+type Work struct {
+	Contributor string    `json:"contributor"`
+	Repo        string    `json:"repo"`
+	PRNumber    uint64    `json:"pr_number"`
+	PRURL       string    `json:"pr_url"`
+	PayoutID    uuid.UUID `json:"payout_id"`
+}
+
+// EncodeWork ABI-encodes w per SchemaDefinition.
+func EncodeWork(w Work) ([]byte, error) {
+	if !common.IsHexAddress(w.Contributor) {
+		return nil, fmt.Errorf("invalid contributor address %q", w.Contributor)
+	}
+	var pid [32]byte
+	copy(pid[:], w.PayoutID[:])
+	return schemaArgs.Pack(common.HexToAddress(w.Contributor), w.Repo, w.PRNumber, w.PRURL, pid)
+}
+
+// DecodeWork is the inverse of EncodeWork.
+func DecodeWork(data []byte) (Work, error) {
+	vals, err := schemaArgs.Unpack(data)
+	if err != nil {
+		return Work{}, err
+	}
+	if len(vals) != 5 {
+		return Work{}, fmt.Errorf("unexpected field count %d", len(vals))
+	}
+	addr, _ := vals[0].(common.Address)
+	repo, _ := vals[1].(string)
+	pr, _ := vals[2].(uint64)
+	url, _ := vals[3].(string)
+	pid, _ := vals[4].([32]byte)
+	var id uuid.UUID
+	copy(id[:], pid[:16])
+	return Work{
+		Contributor: strings.ToLower(addr.Hex()),
+		Repo:        repo,
+		PRNumber:    pr,
+		PRURL:       url,
+		PayoutID:    id,
+	}, nil
+}
+
+type attestationRequestData struct {
+	Recipient      common.Address
+	ExpirationTime uint64
+	Revocable      bool
+	RefUID         [32]byte
+	Data           []byte
+	Value          *big.Int
+}
+
+type attestationRequest struct {
+	Schema [32]byte
+	Data   attestationRequestData
+}
+
+func packAttest(schema [32]byte, recipient common.Address, data []byte) ([]byte, error) {
+	return easABI.Pack("attest", attestationRequest{
+		Schema: schema,
+		Data: attestationRequestData{
+			Recipient: recipient,
+			// Revocable so a payout clawed back for fraud can be withdrawn.
+			Revocable: true,
+			Data:      data,
+			Value:     big.NewInt(0),
+		},
+	})
+}
+
+// OnChain is an attestation as stored by the EAS contract.
+type OnChain struct {
+	UID            [32]byte
+	Schema         [32]byte
+	Time           uint64
+	ExpirationTime uint64
+	RevocationTime uint64
+	RefUID         [32]byte
+	Recipient      common.Address
+	Attester       common.Address
+	Revocable      bool
+	Data           []byte
+}
+
+func unpackGetAttestation(out []byte) (OnChain, error) {
+	vals, err := easABI.Unpack("getAttestation", out)
+	if err != nil {
+		return OnChain{}, err
+	}
+	if len(vals) != 1 {
+		return OnChain{}, fmt.Errorf("unexpected getAttestation output")
+	}
+	a := *abi.ConvertType(vals[0], new(OnChain)).(*OnChain)
+	return a, nil
+}
+
+// ParseUID parses a 0x-prefixed 32-byte hex UID.
+func ParseUID(s string) ([32]byte, error) {
+	var uid [32]byte
+	b := common.FromHex(strings.TrimSpace(s))
+	if len(b) != 32 || !strings.HasPrefix(strings.TrimSpace(s), "0x") {
+		return uid, fmt.Errorf("invalid uid")
+	}
+	copy(uid[:], b)
+	return uid, nil
+}
@@ -0,0 +1,54 @@
+package attest
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+func TestWorkRoundTrip(t *testing.T) {
+	w := Work{
+		Contributor: "0x52908400098527886e0f7030069857d2e4169ee7",
+		Repo:        "grainlify/grainlify",
+		PRNumber:    42,
+		PRURL:       "https://github.com/grainlify/grainlify/pull/42",
+		PayoutID:    uuid.MustParse("8f14e45f-ceea-467f-a8b4-2d9c3e1f0a11"),
+	}
+	data, err := EncodeWork(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeWork(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != w {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, w)
+	}
+}
+
+func TestPackAttest(t *testing.T) {
+	data, err := EncodeWork(Work{Contributor: "0x52908400098527886e0f7030069857d2e4169ee7", Repo: "a/b", PRNumber: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, err := packAttest([32]byte{1}, common.HexToAddress("0x52908400098527886e0f7030069857d2e4169ee7"), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := common.Bytes2Hex(call[:4]), common.Bytes2Hex(easABI.Methods["attest"].ID); got != want {
+		t.Fatalf("selector = %s, want %s", got, want)
+	}
+}
+
+func TestParseUID(t *testing.T) {
+	if _, err := ParseUID("0x" + common.Bytes2Hex(make([]byte, 32))); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{"", "0x1234", common.Bytes2Hex(make([]byte, 32))} {
+		if _, err := ParseUID(bad); err == nil {
+			t.Errorf("ParseUID(%q) should fail", bad)
+		}
+	}
+}
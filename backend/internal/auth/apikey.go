@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// LocalAPIKeyScopes holds the scopes of the API key a request was
+// authenticated with; it is unset for JWT requests.
+const LocalAPIKeyScopes = "api_key_scopes"
+
+// LocalAPIKey holds the APIKey a request was authenticated with.
+const LocalAPIKey = "api_key"
+
+// HeaderAPIKey is accepted alongside "Authorization: Bearer glf_..." since
+// some automation tools only support custom headers.
+const HeaderAPIKey = "X-API-Key"
+
+// LocalPlanTier holds the plan tier of the account owning the API key a
+// request was authenticated with; it is unset for JWT requests.
+const LocalPlanTier = "plan_tier"
+
+var ErrInvalidAPIKey = errors.New("invalid_api_key")
+
+// ErrAccountRestricted is returned for keys whose owner is banned or
+// suspended; their keys stop working along with their sessions.
+var ErrAccountRestricted = errors.New("account_restricted")
+
+// APIKey is an authenticated API key as RequireAPIKey and
+// RequireAuthOrAPIKey see it.
+type APIKey struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Name   string
+	// Role is the owner's role; keys never act as admin.
+	Role   string
+	Scopes []string
+	// Tier is the owner's plan tier.
+	Tier string
+}
+
+// APIKeyAuthenticator resolves API keys. It lives outside this package so
+// key storage can depend on auth rather than the other way round.
+type APIKeyAuthenticator interface {
+	// IsAPIKey reports whether a bearer token looks like an API key rather
+	// than a JWT.
+	IsAPIKey(token string) bool
+	// AuthenticateAPIKey returns the active key for token with its owner's
+	// role, or ErrInvalidAPIKey or ErrAccountRestricted.
+	AuthenticateAPIKey(ctx context.Context, token string) (APIKey, error)
+}
+
+// HasScope reports whether the request was made with a JWT (which carries
+// every scope) or with an API key granted scope.
+func HasScope(c *fiber.Ctx, scope string) bool {
+	scopes, ok := c.Locals(LocalAPIKeyScopes).([]string)
+	if !ok {
+		return true
+	}
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAPIKey authenticates the request with an API key granted scope,
+// from "Authorization: Bearer" or X-API-Key; JWTs are refused.
+func RequireAPIKey(keys APIKeyAuthenticator, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if keys == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "api_keys_not_configured")
+		}
+		token := apiKeyToken(c, keys)
+		if token == "" {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "missing_api_key")
+		}
+		return authenticateAPIKey(c, keys, token, scope)
+	}
+}
+
+// RequireAuthOrAPIKey is RequireAuth that also accepts an API key, as
+// RequireAPIKey does. Keys must be granted scope; JWTs are not scoped.
+func RequireAuthOrAPIKey(jwtSecret string, pool *pgxpool.Pool, keys APIKeyAuthenticator, scope string) fiber.Handler {
+	jwtAuth := RequireAuth(jwtSecret, pool)
+	return func(c *fiber.Ctx) error {
+		token := apiKeyToken(c, keys)
+		if token == "" {
+			return jwtAuth(c)
+		}
+		return authenticateAPIKey(c, keys, token, scope)
+	}
+}
+
+// apiKeyToken returns the API key the request carries, or "" if it carries
+// none (e.g. a JWT).
+func apiKeyToken(c *fiber.Ctx, keys APIKeyAuthenticator) string {
+	if keys == nil {
+		return ""
+	}
+	if token := strings.TrimSpace(c.Get(HeaderAPIKey)); token != "" {
+		return token
+	}
+	h := strings.TrimSpace(c.Get("Authorization"))
+	if len(h) <= len("bearer ") || !strings.EqualFold(h[:len("bearer ")], "bearer ") {
+		return ""
+	}
+	if token := strings.TrimSpace(h[len("bearer "):]); keys.IsAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
+// authenticateAPIKey resolves token, checks it was granted scope and sets
+// the request's user from the key's owner.
+func authenticateAPIKey(c *fiber.Ctx, keys APIKeyAuthenticator, token, scope string) error {
+	k, err := keys.AuthenticateAPIKey(c.Context(), token)
+	if errors.Is(err, ErrInvalidAPIKey) {
+		httpx.Logger(c).Warn("auth middleware: api key rejected",
+			"path", c.Path(),
+			"method", c.Method(),
+			"remote_ip", c.IP(),
+		)
+		return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_api_key")
+	}
+	if errors.Is(err, ErrAccountRestricted) {
+		return httpx.Fail(c, fiber.StatusForbidden, "account_restricted")
+	}
+	if err != nil {
+		httpx.Logger(c).Error("auth middleware: api key lookup failed",
+			"path", c.Path(),
+			"error", err,
+		)
+		return httpx.Fail(c, fiber.StatusServiceUnavailable, "api_key_lookup_failed")
+	}
+	if k.Role == "admin" {
+		k.Role = "maintainer"
+	}
+	c.Locals(LocalAPIKey, k)
+	c.Locals(LocalAPIKeyScopes, k.Scopes)
+	c.Locals(LocalPlanTier, k.Tier)
+	if scope != "" && !HasScope(c, scope) {
+		return httpx.Write(c, httpx.New(fiber.StatusForbidden, "insufficient_scope").With("required_scope", scope))
+	}
+	c.Locals(LocalUserID, k.UserID.String())
+	c.Locals(LocalRole, k.Role)
+	httpx.SetUser(c, k.UserID.String(), "")
+	return c.Next()
+}
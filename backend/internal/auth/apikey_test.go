@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type fakeKeys map[string]APIKey
+
+func (f fakeKeys) IsAPIKey(token string) bool { return strings.HasPrefix(token, "glf_") }
+
+func (f fakeKeys) AuthenticateAPIKey(_ context.Context, token string) (APIKey, error) {
+	if token == "glf_banned" {
+		return APIKey{}, ErrAccountRestricted
+	}
+	k, ok := f[token]
+	if !ok {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+	return k, nil
+}
+
+func TestRequireAuthOrAPIKey(t *testing.T) {
+	const secret = "test-secret"
+	owner := uuid.New()
+	keys := fakeKeys{
+		"glf_read":  {ID: uuid.New(), UserID: owner, Role: "contributor", Scopes: []string{"bounties:read"}},
+		"glf_write": {ID: uuid.New(), UserID: owner, Role: "contributor", Scopes: []string{"bounties:write"}},
+	}
+	app := fiber.New()
+	app.Post("/bounties", RequireAuthOrAPIKey(secret, nil, keys, "bounties:write"), func(c *fiber.Ctx) error {
+		sub, _ := c.Locals(LocalUserID).(string)
+		return c.SendString(sub)
+	})
+
+	jwt, err := IssueJWT(secret, owner, uuid.Nil, "contributor", "", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := func(token string) int {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/bounties", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	for token, want := range map[string]int{
+		jwt:          fiber.StatusOK,
+		"glf_write":  fiber.StatusOK,
+		"glf_read":   fiber.StatusForbidden,
+		"glf_banned": fiber.StatusForbidden,
+		"glf_other":  fiber.StatusUnauthorized,
+		"not-a-jwt":  fiber.StatusUnauthorized,
+		"":           fiber.StatusUnauthorized,
+	} {
+		if got := status(token); got != want {
+			t.Errorf("token %q: status %d, want %d", token, got, want)
+		}
+	}
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	const secret = "test-secret"
+	owner := uuid.New()
+	keys := fakeKeys{
+		"glf_read":  {ID: uuid.New(), UserID: owner, Role: "contributor", Scopes: []string{"bounties:read"}},
+		"glf_admin": {ID: uuid.New(), UserID: owner, Role: "admin", Scopes: []string{"bounties:read"}},
+	}
+	app := fiber.New()
+	app.Get("/events", RequireAPIKey(keys, "bounties:read"), func(c *fiber.Ctx) error {
+		role, _ := c.Locals(LocalRole).(string)
+		return c.SendString(role)
+	})
+	app.Get("/me", RequireAuthOrAPIKey(secret, nil, keys, "bounties:read"), func(c *fiber.Ctx) error {
+		role, _ := c.Locals(LocalRole).(string)
+		return c.SendString(role)
+	})
+
+	jwt, err := IssueJWT(secret, owner, uuid.Nil, "contributor", "", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := func(path, header, token string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set(header, token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	for _, tc := range []struct {
+		path, header, token string
+		status              int
+		role                string
+	}{
+		{"/events", HeaderAPIKey, "glf_read", fiber.StatusOK, "contributor"},
+		{"/events", "Authorization", "Bearer glf_read", fiber.StatusOK, "contributor"},
+		{"/events", "Authorization", "Bearer " + jwt, fiber.StatusUnauthorized, ""},
+		{"/events", HeaderAPIKey, "", fiber.StatusUnauthorized, ""},
+		{"/events", HeaderAPIKey, "glf_banned", fiber.StatusForbidden, ""},
+		// Keys never act as admin, on either middleware.
+		{"/events", HeaderAPIKey, "glf_admin", fiber.StatusOK, "maintainer"},
+		{"/me", "Authorization", "Bearer glf_admin", fiber.StatusOK, "maintainer"},
+		{"/me", HeaderAPIKey, "glf_admin", fiber.StatusOK, "maintainer"},
+	} {
+		status, body := do(tc.path, tc.header, tc.token)
+		if status != tc.status || (tc.status == fiber.StatusOK && body != tc.role) {
+			t.Errorf("%s %s %q: %d %q, want %d %q", tc.path, tc.header, tc.token, status, body, tc.status, tc.role)
+		}
+	}
+}
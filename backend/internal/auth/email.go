@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Purposes of a mailed code.
+const (
+	EmailPurposeVerify   = "verify_email"
+	EmailPurposeRecovery = "recovery"
+)
+
+const (
+	// EmailCodeTTL is how long a mailed code stays valid.
+	EmailCodeTTL = 15 * time.Minute
+	// maxEmailCodeAttempts bounds guesses at one six-digit code.
+	maxEmailCodeAttempts = 5
+)
+
+var (
+	ErrInvalidEmail     = errors.New("invalid_email")
+	ErrEmailTaken       = errors.New("email_taken")
+	ErrInvalidEmailCode = errors.New("invalid_or_expired_code")
+	ErrNoVerifiedEmail  = errors.New("no_verified_email")
+)
+
+// NormalizeEmail returns a bare, lower-cased address. Display names
+// ("Name <a@b>") are rejected.
+func NormalizeEmail(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	a, err := mail.ParseAddress(s)
+	if err != nil || a.Name != "" || a.Address != s || len(s) > 254 {
+		return "", ErrInvalidEmail
+	}
+	return strings.ToLower(a.Address), nil
+}
+
+// emailCode returns a random six-digit code.
+func emailCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashEmailCode salts the code with its challenge id, so equal codes never
+// share a hash.
+func hashEmailCode(id uuid.UUID, code string) []byte {
+	sum := sha256.Sum256([]byte(id.String() + ":" + strings.TrimSpace(code)))
+	return sum[:]
+}
+
+// createEmailChallenge stores a code for userID to prove they read email,
+// superseding earlier unconsumed codes for the same purpose.
+func createEmailChallenge(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, purpose, email string) (string, error) {
+	code, err := emailCode()
+	if err != nil {
+		return "", err
+	}
+	id := uuid.New()
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `
+UPDATE email_challenges SET consumed_at = now()
+WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL
+`, userID, purpose); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO email_challenges (id, user_id, purpose, email, code_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`, id, userID, purpose, email, hashEmailCode(id, code), time.Now().UTC().Add(EmailCodeTTL)); err != nil {
+		return "", err
+	}
+	return code, tx.Commit(ctx)
+}
+
+// consumeEmailChallenge checks code against userID's live challenge for
+// purpose and returns the address it was mailed to. Every guess counts
+// toward maxEmailCodeAttempts, including wrong ones.
+func consumeEmailChallenge(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, purpose, code string) (string, error) {
+	var (
+		id    uuid.UUID
+		email string
+		hash  []byte
+	)
+	err := pool.QueryRow(ctx, `
+UPDATE email_challenges SET attempts = attempts + 1
+WHERE id = (
+  SELECT id FROM email_challenges
+  WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > now()
+  ORDER BY created_at DESC
+  LIMIT 1
+) AND attempts < $3
+RETURNING id, email, code_hash
+`, userID, purpose, maxEmailCodeAttempts).Scan(&id, &email, &hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrInvalidEmailCode
+	}
+	if err != nil {
+		return "", err
+	}
+	if subtle.ConstantTimeCompare(hash, hashEmailCode(id, code)) != 1 {
+		return "", ErrInvalidEmailCode
+	}
+	tag, err := pool.Exec(ctx, `UPDATE email_challenges SET consumed_at = now() WHERE id = $1 AND consumed_at IS NULL`, id)
+	if err != nil {
+		return "", err
+	}
+	if tag.RowsAffected() == 0 {
+		return "", ErrInvalidEmailCode
+	}
+	return email, nil
+}
+
+// UserEmail returns the user's email and whether it is verified.
+func UserEmail(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (string, bool, error) {
+	if pool == nil {
+		return "", false, fmt.Errorf("db not configured")
+	}
+	var email string
+	var verified bool
+	err := pool.QueryRow(ctx, `SELECT COALESCE(email, ''), email_verified_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&email, &verified)
+	return email, verified, err
+}
+
+// StartEmailVerification issues a code to prove userID reads email, which
+// stays unverified (and unsaved) until VerifyEmail.
+func StartEmailVerification(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, email string) (string, string, error) {
+	if pool == nil {
+		return "", "", fmt.Errorf("db not configured")
+	}
+	email, err := NormalizeEmail(email)
+	if err != nil {
+		return "", "", err
+	}
+	var taken bool
+	if err := pool.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM users WHERE lower(email) = $1 AND email_verified_at IS NOT NULL AND id <> $2)
+`, email, userID).Scan(&taken); err != nil {
+		return "", "", err
+	}
+	if taken {
+		return "", "", ErrEmailTaken
+	}
+	code, err := createEmailChallenge(ctx, pool, userID, EmailPurposeVerify, email)
+	return email, code, err
+}
+
+// VerifyEmail checks a verification code and saves its address as the
+// user's verified email.
+func VerifyEmail(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, code string) (string, error) {
+	if pool == nil {
+		return "", fmt.Errorf("db not configured")
+	}
+	email, err := consumeEmailChallenge(ctx, pool, userID, EmailPurposeVerify, code)
+	if err != nil {
+		return "", err
+	}
+	_, err = pool.Exec(ctx, `UPDATE users SET email = $2, email_verified_at = now(), updated_at = now() WHERE id = $1`, userID, email)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return "", ErrEmailTaken
+	}
+	return email, err
+}
+
+// userByVerifiedEmail returns the account whose verified email is email.
+func userByVerifiedEmail(ctx context.Context, pool *pgxpool.Pool, email string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := pool.QueryRow(ctx, `SELECT id FROM users WHERE lower(email) = $1 AND email_verified_at IS NOT NULL`, email).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, ErrNoVerifiedEmail
+	}
+	return id, err
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	got, err := NormalizeEmail("  Alice@Example.COM ")
+	if err != nil || got != "alice@example.com" {
+		t.Fatalf("NormalizeEmail = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "alice", "Alice <alice@example.com>", "alice@example.com\r\nBcc: x@example.com"} {
+		if _, err := NormalizeEmail(bad); !errors.Is(err, ErrInvalidEmail) {
+			t.Errorf("NormalizeEmail(%q) error = %v, want ErrInvalidEmail", bad, err)
+		}
+	}
+}
+
+func TestEmailCode(t *testing.T) {
+	code, err := emailCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 6 {
+		t.Fatalf("code %q is not six digits", code)
+	}
+	a, b := uuid.New(), uuid.New()
+	if bytes.Equal(hashEmailCode(a, code), hashEmailCode(b, code)) {
+		t.Fatal("equal codes on different challenges share a hash")
+	}
+	if !bytes.Equal(hashEmailCode(a, code), hashEmailCode(a, " "+code+" ")) {
+		t.Fatal("surrounding whitespace changes the hash")
+	}
+}
@@ -13,9 +13,14 @@ type Claims struct {
 	Role       string `json:"role"`
 	WalletType string `json:"wallet_type,omitempty"`
 	Address    string `json:"address,omitempty"`
+	// SessionID is the sessions row the token belongs to; RequireAuth rejects
+	// tokens whose session was revoked. Empty for sessionless tokens.
+	SessionID string `json:"sid,omitempty"`
 }
 
-func IssueJWT(secret string, userID uuid.UUID, role string, walletType WalletType, address string, ttl time.Duration) (string, error) {
+// IssueJWT signs an access token. Pass uuid.Nil as sessionID for tokens not
+// tied to a session.
+func IssueJWT(secret string, userID, sessionID uuid.UUID, role string, walletType WalletType, address string, ttl time.Duration) (string, error) {
 	if secret == "" {
 		return "", fmt.Errorf("JWT_SECRET is required")
 	}
@@ -34,6 +39,9 @@ func IssueJWT(secret string, userID uuid.UUID, role string, walletType WalletTyp
 		WalletType: string(walletType),
 		Address:    address,
 	}
+	if sessionID != uuid.Nil {
+		claims.SessionID = sessionID.String()
+	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return t.SignedString([]byte(secret))
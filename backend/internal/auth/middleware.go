@@ -1,80 +1,93 @@
 package auth
 
 import (
-	"log/slog"
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
+// Roles are the roles a user can hold.
+var Roles = []string{"contributor", "maintainer", "admin"}
+
 const (
-	LocalUserID = "user_id"
-	LocalRole   = "role"
+	LocalUserID    = "user_id"
+	LocalRole      = "role"
+	LocalSessionID = "session_id"
 )
 
-func RequireAuth(jwtSecret string) fiber.Handler {
+// RequireAuth validates the bearer JWT. With a pool, tokens carrying a
+// session id are rejected once that session is revoked.
+func RequireAuth(jwtSecret string, pool *pgxpool.Pool) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		h := strings.TrimSpace(c.Get("Authorization"))
 		if h == "" || !strings.HasPrefix(strings.ToLower(h), "bearer ") {
-			slog.Warn("auth middleware: missing or invalid Authorization header",
+			httpx.Logger(c).Warn("auth middleware: missing or invalid Authorization header",
 				"path", c.Path(),
 				"method", c.Method(),
 				"header_present", h != "",
 				"header_prefix_ok", h != "" && strings.HasPrefix(strings.ToLower(h), "bearer "),
-				"request_id", c.Locals("requestid"),
 			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "missing_bearer_token",
-			})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "missing_bearer_token")
 		}
 		token := strings.TrimSpace(h[len("bearer "):])
 		if token == "" {
-			slog.Warn("auth middleware: empty token after 'bearer ' prefix",
+			httpx.Logger(c).Warn("auth middleware: empty token after 'bearer ' prefix",
 				"path", c.Path(),
 				"method", c.Method(),
-				"request_id", c.Locals("requestid"),
 			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "missing_bearer_token",
-			})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "missing_bearer_token")
 		}
 		claims, err := ParseJWT(jwtSecret, token)
 		if err != nil {
-			slog.Warn("auth middleware: JWT parse failed",
+			httpx.Logger(c).Warn("auth middleware: JWT parse failed",
 				"path", c.Path(),
 				"method", c.Method(),
 				"error", err,
 				"token_length", len(token),
-				"request_id", c.Locals("requestid"),
 			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "invalid_token",
-			})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_token")
+		}
+
+		if claims.SessionID != "" && pool != nil {
+			sid, err := uuid.Parse(claims.SessionID)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_token")
+			}
+			err = CheckSession(c.Context(), pool, sid, c.IP(), c.Get(fiber.HeaderUserAgent))
+			if errors.Is(err, ErrSessionRevoked) {
+				return httpx.Fail(c, fiber.StatusUnauthorized, "session_revoked")
+			}
+			if err != nil {
+				httpx.Logger(c).Error("auth middleware: session check failed",
+					"path", c.Path(),
+					"error", err,
+				)
+				return httpx.Fail(c, fiber.StatusServiceUnavailable, "session_check_failed")
+			}
+			c.Locals(LocalSessionID, claims.SessionID)
 		}
 
 		c.Locals(LocalUserID, claims.Subject)
 		c.Locals(LocalRole, claims.Role)
+		httpx.SetUser(c, claims.Subject, claims.Address)
 		return c.Next()
 	}
 }
 
-func RequireRole(roles ...string) fiber.Handler {
-	allowed := map[string]struct{}{}
-	for _, r := range roles {
-		allowed[r] = struct{}{}
-	}
+// OptionalAuth is RequireAuth for routes that also serve signed-out
+// callers: a request without an Authorization header goes through
+// anonymously, but a bearer token that is sent must be valid.
+func OptionalAuth(jwtSecret string, pool *pgxpool.Pool) fiber.Handler {
+	required := RequireAuth(jwtSecret, pool)
 	return func(c *fiber.Ctx) error {
-		role, _ := c.Locals(LocalRole).(string)
-		if role == "" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "missing_role",
-			})
-		}
-		if _, ok := allowed[role]; !ok {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "insufficient_role",
-			})
+		if strings.TrimSpace(c.Get("Authorization")) == "" {
+			return c.Next()
 		}
-		return c.Next()
+		return required(c)
 	}
 }
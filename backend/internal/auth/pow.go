@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"math/bits"
+	"strconv"
+)
+
+// MaxPoWDifficulty caps puzzles so a misconfiguration can't lock everyone out.
+const MaxPoWDifficulty = 28
+
+// VerifyPoW checks a hashcash-style solution: SHA-256(challenge + ":" + solution)
+// must start with at least `difficulty` zero bits.
+func VerifyPoW(challenge string, solution string, difficulty int) bool {
+	if difficulty <= 0 {
+		return true
+	}
+	if solution == "" || len(solution) > 64 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(challenge + ":" + solution))
+	return leadingZeroBits(sum[:]) >= difficulty
+}
+
+// SolvePoW brute-forces a solution. Clients do this in the browser; we keep a Go
+// version for tests and CLI tooling.
+func SolvePoW(challenge string, difficulty int) string {
+	for i := uint64(0); ; i++ {
+		s := strconv.FormatUint(i, 36)
+		if VerifyPoW(challenge, s, difficulty) {
+			return s
+		}
+	}
+}
+
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, v := range b {
+		if v == 0 {
+			n += 8
+			continue
+		}
+		return n + bits.LeadingZeros8(v)
+	}
+	return n
+}
@@ -0,0 +1,37 @@
+package auth
+
+import "testing"
+
+func TestPoWRoundTrip(t *testing.T) {
+	challenge := "nonce-abc"
+	sol := SolvePoW(challenge, 12)
+	if !VerifyPoW(challenge, sol, 12) {
+		t.Fatalf("solution %q should satisfy difficulty 12", sol)
+	}
+	if VerifyPoW("other-nonce", sol, 12) && VerifyPoW("other-nonce-2", sol, 12) {
+		t.Errorf("solution unexpectedly valid for unrelated challenges")
+	}
+	if VerifyPoW(challenge, "", 12) {
+		t.Errorf("empty solution must be rejected")
+	}
+	if !VerifyPoW(challenge, "", 0) {
+		t.Errorf("difficulty 0 must always pass")
+	}
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	cases := []struct {
+		in   []byte
+		want int
+	}{
+		{[]byte{0x80}, 0},
+		{[]byte{0x01}, 7},
+		{[]byte{0x00, 0x10}, 11},
+		{[]byte{0x00, 0x00}, 16},
+	}
+	for _, tc := range cases {
+		if got := leadingZeroBits(tc.in); got != tc.want {
+			t.Errorf("leadingZeroBits(%x) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Recovery statuses.
+const (
+	RecoveryPending   = "pending"
+	RecoveryCompleted = "completed"
+	RecoveryCancelled = "cancelled"
+)
+
+var (
+	ErrRecoveryNotFound = errors.New("recovery_not_found")
+	ErrRecoveryPending  = errors.New("recovery_already_pending")
+	ErrRecoveryNotReady = errors.New("recovery_not_ready")
+)
+
+// Recovery binds a new wallet to an account whose owner lost their wallets.
+// It waits until AvailableAt so the owner can cancel it if it isn't theirs.
+type Recovery struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"-"`
+	Status      string     `json:"status"`
+	WalletType  WalletType `json:"wallet_type"`
+	Address     string     `json:"address"`
+	RequestedIP string     `json:"requested_ip,omitempty"`
+	AvailableAt time.Time  `json:"available_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+const recoveryColumns = `id, user_id, status, wallet_type, address, COALESCE(requested_ip, ''), available_at, completed_at, cancelled_at, created_at`
+
+func scanRecovery(row pgx.Row) (Recovery, error) {
+	var r Recovery
+	var wt string
+	err := row.Scan(&r.ID, &r.UserID, &r.Status, &wt, &r.Address, &r.RequestedIP, &r.AvailableAt, &r.CompletedAt, &r.CancelledAt, &r.CreatedAt)
+	r.WalletType = WalletType(wt)
+	return r, err
+}
+
+func hashRecoveryToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// StartRecovery issues a recovery code for the account whose verified email
+// is email, and returns the account and code to mail.
+func StartRecovery(ctx context.Context, pool *pgxpool.Pool, email string) (uuid.UUID, string, error) {
+	if pool == nil {
+		return uuid.Nil, "", fmt.Errorf("db not configured")
+	}
+	email, err := NormalizeEmail(email)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	userID, err := userByVerifiedEmail(ctx, pool, email)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	code, err := createEmailChallenge(ctx, pool, userID, EmailPurposeRecovery, email)
+	return userID, code, err
+}
+
+// NewRecovery is the input to RequestRecovery. The caller has already
+// checked the wallet's signature over Nonce.
+type NewRecovery struct {
+	Email       string
+	Code        string
+	WalletType  WalletType
+	Address     string
+	Nonce       string
+	PublicKey   string
+	PoWSolution string
+	IP          string
+}
+
+// RequestRecovery checks the mailed code and queues in.Address to be bound
+// to the account after delay. It returns the recovery and the one-time token
+// that completes it.
+func RequestRecovery(ctx context.Context, pool *pgxpool.Pool, in NewRecovery, delay time.Duration) (Recovery, string, error) {
+	if pool == nil {
+		return Recovery{}, "", fmt.Errorf("db not configured")
+	}
+	email, err := NormalizeEmail(in.Email)
+	if err != nil {
+		return Recovery{}, "", err
+	}
+	userID, err := userByVerifiedEmail(ctx, pool, email)
+	if errors.Is(err, ErrNoVerifiedEmail) {
+		return Recovery{}, "", ErrInvalidEmailCode
+	}
+	if err != nil {
+		return Recovery{}, "", err
+	}
+	if _, err := consumeEmailChallenge(ctx, pool, userID, EmailPurposeRecovery, in.Code); err != nil {
+		return Recovery{}, "", err
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Recovery{}, "", err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := consumeNonce(ctx, tx, in.WalletType, in.Address, in.Nonce, in.PoWSolution); err != nil {
+		return Recovery{}, "", err
+	}
+	var owner uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT user_id FROM wallets WHERE wallet_type = $1 AND address = $2`, string(in.WalletType), in.Address).Scan(&owner)
+	switch {
+	case err == nil && owner == userID:
+		return Recovery{}, "", ErrWalletAlreadyLinked
+	case err == nil:
+		return Recovery{}, "", ErrWalletLinkedElsewhere
+	case !errors.Is(err, pgx.ErrNoRows):
+		return Recovery{}, "", err
+	}
+
+	token := randomNonce(32)
+	r, err := scanRecovery(tx.QueryRow(ctx, `
+INSERT INTO account_recoveries (user_id, wallet_type, address, public_key, token_hash, requested_ip, available_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING `+recoveryColumns,
+		userID, string(in.WalletType), in.Address, nullIfEmpty(in.PublicKey), hashRecoveryToken(token), nullIfEmpty(in.IP), time.Now().UTC().Add(delay)))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return Recovery{}, "", ErrRecoveryPending
+	}
+	if err != nil {
+		return Recovery{}, "", err
+	}
+	return r, token, tx.Commit(ctx)
+}
+
+// PendingRecovery returns the account's pending recovery, if any.
+func PendingRecovery(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (Recovery, error) {
+	if pool == nil {
+		return Recovery{}, fmt.Errorf("db not configured")
+	}
+	r, err := scanRecovery(pool.QueryRow(ctx, `
+SELECT `+recoveryColumns+`
+FROM account_recoveries
+WHERE user_id = $1 AND status = 'pending'
+`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Recovery{}, ErrRecoveryNotFound
+	}
+	return r, err
+}
+
+// CancelRecovery stops the account's pending recovery on behalf of actorID,
+// a signed-in session of the same account.
+func CancelRecovery(ctx context.Context, pool *pgxpool.Pool, userID, actorID uuid.UUID) (Recovery, error) {
+	if pool == nil {
+		return Recovery{}, fmt.Errorf("db not configured")
+	}
+	r, err := scanRecovery(pool.QueryRow(ctx, `
+UPDATE account_recoveries
+SET status = 'cancelled', cancelled_at = now(), cancelled_by = $2
+WHERE user_id = $1 AND status = 'pending'
+RETURNING `+recoveryColumns, userID, actorID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Recovery{}, ErrRecoveryNotFound
+	}
+	return r, err
+}
+
+// CompleteRecovery binds the recovery's wallet as the account's primary
+// wallet and signs the account out everywhere. Before AvailableAt it
+// returns the recovery with ErrRecoveryNotReady.
+func CompleteRecovery(ctx context.Context, pool *pgxpool.Pool, token string, now time.Time) (Recovery, User, error) {
+	if pool == nil {
+		return Recovery{}, User{}, fmt.Errorf("db not configured")
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Recovery{}, User{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	r, err := scanRecovery(tx.QueryRow(ctx, `
+SELECT `+recoveryColumns+`
+FROM account_recoveries
+WHERE token_hash = $1 AND status = 'pending'
+FOR UPDATE
+`, hashRecoveryToken(token)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Recovery{}, User{}, ErrRecoveryNotFound
+	}
+	if err != nil {
+		return Recovery{}, User{}, err
+	}
+	if now.Before(r.AvailableAt) {
+		return r, User{}, ErrRecoveryNotReady
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET is_primary = false WHERE user_id = $1 AND is_primary`, r.UserID); err != nil {
+		return Recovery{}, User{}, err
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO wallets (user_id, wallet_type, address, public_key, is_primary)
+SELECT user_id, wallet_type, address, public_key, true FROM account_recoveries WHERE id = $1
+`, r.ID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		// Someone signed in with the wallet while the recovery waited.
+		return Recovery{}, User{}, ErrWalletLinkedElsewhere
+	}
+	if err != nil {
+		return Recovery{}, User{}, err
+	}
+	if _, err := RevokeUserSessions(ctx, tx, r.UserID); err != nil {
+		return Recovery{}, User{}, err
+	}
+	r, err = scanRecovery(tx.QueryRow(ctx, `
+UPDATE account_recoveries SET status = 'completed', completed_at = now()
+WHERE id = $1
+RETURNING `+recoveryColumns, r.ID))
+	if err != nil {
+		return Recovery{}, User{}, err
+	}
+	u := User{ID: r.UserID}
+	if err := tx.QueryRow(ctx, `SELECT role FROM users WHERE id = $1`, r.UserID).Scan(&u.Role); err != nil {
+		return Recovery{}, User{}, err
+	}
+	return r, u, tx.Commit(ctx)
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid_refresh_token")
+	// ErrRefreshTokenReused means a rotated-out token was presented again; the
+	// whole family has been revoked and the user must sign in again.
+	ErrRefreshTokenReused = errors.New("refresh_token_reused")
+)
+
+// RefreshToken is a freshly issued opaque refresh token. Only its hash is stored.
+type RefreshToken struct {
+	Token     string    `json:"refresh_token"`
+	ExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// Session is what a refresh token vouches for when minting a new access token.
+type Session struct {
+	ID         uuid.UUID
+	User       User
+	WalletType WalletType
+	Address    string
+}
+
+func hashRefreshToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+type refreshQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// defaultRefreshTTL applies when no refresh token lifetime is configured.
+const defaultRefreshTTL = 30 * 24 * time.Hour
+
+func insertRefreshToken(ctx context.Context, q refreshQuerier, userID, familyID uuid.UUID, walletType WalletType, address string, ttl time.Duration) (uuid.UUID, RefreshToken, error) {
+	if ttl <= 0 {
+		ttl = defaultRefreshTTL
+	}
+	token := randomNonce(32)
+	expiresAt := time.Now().UTC().Add(ttl)
+	var id uuid.UUID
+	err := q.QueryRow(ctx, `
+INSERT INTO refresh_tokens (user_id, family_id, token_hash, wallet_type, address, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`, userID, familyID, hashRefreshToken(token), nullIfEmpty(string(walletType)), nullIfEmpty(address), expiresAt).Scan(&id)
+	if err != nil {
+		return uuid.Nil, RefreshToken{}, err
+	}
+	return id, RefreshToken{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// IssueRefreshToken starts the token family of a new session (see
+// CreateSession); the session id is the family id.
+func IssueRefreshToken(ctx context.Context, pool *pgxpool.Pool, sessionID, userID uuid.UUID, walletType WalletType, address string, ttl time.Duration) (RefreshToken, error) {
+	if pool == nil {
+		return RefreshToken{}, fmt.Errorf("db not configured")
+	}
+	_, rt, err := insertRefreshToken(ctx, pool, userID, sessionID, walletType, address, ttl)
+	return rt, err
+}
+
+// RotateRefreshToken exchanges a refresh token for its successor. A token
+// that was already rotated or revoked revokes its whole family and returns
+// ErrRefreshTokenReused.
+func RotateRefreshToken(ctx context.Context, pool *pgxpool.Pool, token string, ttl time.Duration) (Session, RefreshToken, error) {
+	if pool == nil {
+		return Session{}, RefreshToken{}, fmt.Errorf("db not configured")
+	}
+	return rotateRefreshToken(ctx, pool, token, ttl)
+}
+
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+func rotateRefreshToken(ctx context.Context, db txBeginner, token string, ttl time.Duration) (Session, RefreshToken, error) {
+	if token == "" {
+		return Session{}, RefreshToken{}, ErrInvalidRefreshToken
+	}
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Session{}, RefreshToken{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var (
+		id, familyID      uuid.UUID
+		s                 Session
+		walletType        *string
+		address           *string
+		expiresAt         time.Time
+		usedAt, revokedAt *time.Time
+	)
+	err = tx.QueryRow(ctx, `
+SELECT rt.id, rt.family_id, u.id, u.role, rt.wallet_type, rt.address, rt.expires_at, rt.used_at, rt.revoked_at
+FROM refresh_tokens rt
+JOIN users u ON u.id = rt.user_id
+WHERE rt.token_hash = $1
+FOR UPDATE OF rt
+`, hashRefreshToken(token)).Scan(&id, &familyID, &s.User.ID, &s.User.Role, &walletType, &address, &expiresAt, &usedAt, &revokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Session{}, RefreshToken{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Session{}, RefreshToken{}, err
+	}
+
+	if usedAt != nil || revokedAt != nil {
+		if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL`, familyID); err != nil {
+			return Session{}, RefreshToken{}, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, familyID); err != nil {
+			return Session{}, RefreshToken{}, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return Session{}, RefreshToken{}, err
+		}
+		if revokedAt == nil {
+			slog.Warn("refresh token reuse detected; family revoked",
+				"user_id", s.User.ID.String(),
+				"family_id", familyID.String(),
+			)
+		}
+		return Session{}, RefreshToken{}, ErrRefreshTokenReused
+	}
+	if !expiresAt.After(time.Now()) {
+		return Session{}, RefreshToken{}, ErrInvalidRefreshToken
+	}
+
+	if walletType != nil {
+		s.WalletType = WalletType(*walletType)
+	}
+	if address != nil {
+		s.Address = *address
+	}
+	nextID, next, err := insertRefreshToken(ctx, tx, s.User.ID, familyID, s.WalletType, s.Address, ttl)
+	if err != nil {
+		return Session{}, RefreshToken{}, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = now(), replaced_by = $2 WHERE id = $1`, id, nextID); err != nil {
+		return Session{}, RefreshToken{}, err
+	}
+	// Families issued before sessions existed get their row here.
+	if _, err := tx.Exec(ctx, `
+INSERT INTO sessions (id, user_id, wallet_type, address, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (id) DO UPDATE SET expires_at = EXCLUDED.expires_at, last_seen_at = now()
+`, familyID, s.User.ID, nullIfEmpty(string(s.WalletType)), nullIfEmpty(s.Address), next.ExpiresAt); err != nil {
+		return Session{}, RefreshToken{}, err
+	}
+	s.ID = familyID
+	if err := tx.Commit(ctx); err != nil {
+		return Session{}, RefreshToken{}, err
+	}
+	return s, next, nil
+}
+
+// RevokeRefreshToken revokes the session and family of token (logout). With
+// allSessions every session and refresh token of the token's user is revoked
+// instead. An unknown token is not an error.
+func RevokeRefreshToken(ctx context.Context, pool *pgxpool.Pool, token string, allSessions bool) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	col, sessionCol := "family_id", "id"
+	if allSessions {
+		col, sessionCol = "user_id", "user_id"
+	}
+	if _, err := pool.Exec(ctx, `
+UPDATE sessions
+SET revoked_at = now()
+WHERE revoked_at IS NULL
+  AND `+sessionCol+` = (SELECT `+col+` FROM refresh_tokens WHERE token_hash = $1)
+`, hashRefreshToken(token)); err != nil {
+		return err
+	}
+	_, err := pool.Exec(ctx, `
+UPDATE refresh_tokens
+SET revoked_at = now()
+WHERE revoked_at IS NULL
+  AND `+col+` = (SELECT `+col+` FROM refresh_tokens WHERE token_hash = $1)
+`, hashRefreshToken(token))
+	return err
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type fakeRow func(dest ...any) error
+
+func (f fakeRow) Scan(dest ...any) error { return f(dest...) }
+
+// fakeTx serves the refresh token lookup from row and records statements.
+type fakeTx struct {
+	pgx.Tx
+	row       fakeRow
+	execs     []string
+	args      [][]any
+	committed bool
+}
+
+func (f *fakeTx) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) { return f, nil }
+
+func (f *fakeTx) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	f.execs = append(f.execs, sql)
+	f.args = append(f.args, args)
+	return f.row
+}
+
+func (f *fakeTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	f.execs = append(f.execs, sql)
+	f.args = append(f.args, args)
+	return pgconn.CommandTag{}, nil
+}
+
+func (f *fakeTx) Commit(context.Context) error   { f.committed = true; return nil }
+func (f *fakeTx) Rollback(context.Context) error { return nil }
+
+// storedToken answers the lookup in rotateRefreshToken.
+func storedToken(familyID uuid.UUID, expiresAt time.Time, usedAt *time.Time) fakeRow {
+	return func(dest ...any) error {
+		*dest[0].(*uuid.UUID) = uuid.New()
+		*dest[1].(*uuid.UUID) = familyID
+		*dest[2].(*uuid.UUID) = uuid.New()
+		*dest[3].(*string) = "contributor"
+		*dest[6].(*time.Time) = expiresAt
+		*dest[7].(**time.Time) = usedAt
+		return nil
+	}
+}
+
+func TestRotateRefreshTokenReuse(t *testing.T) {
+	family := uuid.New()
+	used := time.Now().Add(-time.Minute)
+	tx := &fakeTx{row: storedToken(family, time.Now().Add(time.Hour), &used)}
+
+	_, _, err := rotateRefreshToken(context.Background(), tx, "rotated-out", time.Hour)
+	if !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("err = %v, want ErrRefreshTokenReused", err)
+	}
+	if !tx.committed {
+		t.Fatal("family revocation was not committed")
+	}
+	var revokedTokens, revokedSession bool
+	for i, sql := range tx.execs {
+		if len(tx.args[i]) == 0 || tx.args[i][0] != family {
+			continue
+		}
+		revokedTokens = revokedTokens || strings.Contains(sql, "UPDATE refresh_tokens SET revoked_at")
+		revokedSession = revokedSession || strings.Contains(sql, "UPDATE sessions SET revoked_at")
+	}
+	if !revokedTokens || !revokedSession {
+		t.Errorf("family not revoked: tokens %v, session %v", revokedTokens, revokedSession)
+	}
+}
+
+func TestRotateRefreshTokenInvalid(t *testing.T) {
+	if _, _, err := rotateRefreshToken(context.Background(), &fakeTx{}, "", time.Hour); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("empty token: err = %v", err)
+	}
+	unknown := &fakeTx{row: func(...any) error { return pgx.ErrNoRows }}
+	if _, _, err := rotateRefreshToken(context.Background(), unknown, "unknown", time.Hour); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown token: err = %v", err)
+	}
+	expired := &fakeTx{row: storedToken(uuid.New(), time.Now().Add(-time.Second), nil)}
+	if _, _, err := rotateRefreshToken(context.Background(), expired, "expired", time.Hour); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expired token: err = %v", err)
+	}
+	if expired.committed {
+		t.Error("expired token rotated")
+	}
+}
+
+func TestInsertRefreshTokenDefaultTTL(t *testing.T) {
+	for _, ttl := range []time.Duration{0, -time.Hour} {
+		tx := &fakeTx{row: func(dest ...any) error {
+			*dest[0].(*uuid.UUID) = uuid.New()
+			return nil
+		}}
+		_, rt, err := insertRefreshToken(context.Background(), tx, uuid.New(), uuid.New(), WalletTypeEVM, "0xabc", ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Until(rt.ExpiresAt); d < defaultRefreshTTL-time.Minute || d > defaultRefreshTTL {
+			t.Errorf("ttl %v: token expires in %v, want %v", ttl, d, defaultRefreshTTL)
+		}
+		if rt.Token == "" || tx.args[0][5] != rt.ExpiresAt {
+			t.Errorf("ttl %v: stored expiry %v, issued %v", ttl, tx.args[0][5], rt.ExpiresAt)
+		}
+	}
+}
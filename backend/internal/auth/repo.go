@@ -3,9 +3,10 @@ package auth
 import (
 	"context"
 	"crypto/rand"
-	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,11 +26,14 @@ type Wallet struct {
 }
 
 type Nonce struct {
-	Nonce     string    `json:"nonce"`
-	ExpiresAt time.Time `json:"expires_at"`
+	Nonce         string    `json:"nonce"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	PoWDifficulty int       `json:"pow_difficulty,omitempty"`
 }
 
-func CreateNonce(ctx context.Context, pool *pgxpool.Pool, walletType WalletType, address string, ttl time.Duration) (Nonce, error) {
+// CreateNonce issues a login nonce. powDifficulty > 0 attaches a proof-of-work
+// puzzle that must be solved (see VerifyPoW) before the nonce can be consumed.
+func CreateNonce(ctx context.Context, pool *pgxpool.Pool, walletType WalletType, address string, ttl time.Duration, powDifficulty int) (Nonce, error) {
 	if pool == nil {
 		return Nonce{}, fmt.Errorf("db not configured")
 	}
@@ -37,18 +41,25 @@ func CreateNonce(ctx context.Context, pool *pgxpool.Pool, walletType WalletType,
 		ttl = 10 * time.Minute
 	}
 
+	if powDifficulty < 0 {
+		powDifficulty = 0
+	}
+	if powDifficulty > MaxPoWDifficulty {
+		powDifficulty = MaxPoWDifficulty
+	}
+
 	nonce := randomNonce(32)
 	expiresAt := time.Now().UTC().Add(ttl)
 
 	_, err := pool.Exec(ctx, `
-INSERT INTO auth_nonces (wallet_type, address, nonce, expires_at)
-VALUES ($1, $2, $3, $4)
-`, string(walletType), address, nonce, expiresAt)
+INSERT INTO auth_nonces (wallet_type, address, nonce, expires_at, pow_difficulty)
+VALUES ($1, $2, $3, $4, $5)
+`, string(walletType), address, nonce, expiresAt, powDifficulty)
 	if err != nil {
 		return Nonce{}, err
 	}
 
-	return Nonce{Nonce: nonce, ExpiresAt: expiresAt}, nil
+	return Nonce{Nonce: nonce, ExpiresAt: expiresAt, PoWDifficulty: powDifficulty}, nil
 }
 
 type VerifyResult struct {
@@ -56,7 +67,7 @@ type VerifyResult struct {
 	Wallet Wallet `json:"wallet"`
 }
 
-func ConsumeNonceAndUpsertUser(ctx context.Context, pool *pgxpool.Pool, walletType WalletType, address string, nonce string, publicKey string) (VerifyResult, error) {
+func ConsumeNonceAndUpsertUser(ctx context.Context, pool *pgxpool.Pool, walletType WalletType, address string, nonce string, publicKey string, powSolution string) (VerifyResult, error) {
 	if pool == nil {
 		return VerifyResult{}, fmt.Errorf("db not configured")
 	}
@@ -67,25 +78,7 @@ func ConsumeNonceAndUpsertUser(ctx context.Context, pool *pgxpool.Pool, walletTy
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	var nonceID uuid.UUID
-	err = tx.QueryRow(ctx, `
-SELECT id
-FROM auth_nonces
-WHERE wallet_type = $1
-  AND address = $2
-  AND nonce = $3
-  AND used_at IS NULL
-  AND expires_at > now()
-FOR UPDATE
-`, string(walletType), address, nonce).Scan(&nonceID)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return VerifyResult{}, fmt.Errorf("invalid_or_expired_nonce")
-	}
-	if err != nil {
-		return VerifyResult{}, err
-	}
-
-	if _, err := tx.Exec(ctx, `UPDATE auth_nonces SET used_at = now() WHERE id = $1`, nonceID); err != nil {
+	if err := consumeNonce(ctx, tx, walletType, address, nonce, powSolution); err != nil {
 		return VerifyResult{}, err
 	}
 
@@ -105,8 +98,8 @@ WHERE w.wallet_type = $1 AND w.address = $2
 		}
 
 		_, err = tx.Exec(ctx, `
-INSERT INTO wallets (user_id, wallet_type, address, public_key)
-VALUES ($1, $2, $3, $4)
+INSERT INTO wallets (user_id, wallet_type, address, public_key, is_primary)
+VALUES ($1, $2, $3, $4, true)
 `, userID, string(walletType), address, nullIfEmpty(publicKey))
 		if err != nil {
 			return VerifyResult{}, err
@@ -138,13 +131,68 @@ WHERE wallet_type = $1 AND address = $2
 	}, nil
 }
 
+// consumeNonce marks an unexpired login nonce used after checking its
+// proof-of-work. Errors are "invalid_or_expired_nonce" or "invalid_pow".
+func consumeNonce(ctx context.Context, tx pgx.Tx, walletType WalletType, address, nonce, powSolution string) error {
+	var nonceID uuid.UUID
+	var powDifficulty int
+	err := tx.QueryRow(ctx, `
+SELECT id, pow_difficulty
+FROM auth_nonces
+WHERE wallet_type = $1
+  AND address = $2
+  AND nonce = $3
+  AND used_at IS NULL
+  AND expires_at > now()
+FOR UPDATE
+`, string(walletType), address, nonce).Scan(&nonceID, &powDifficulty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("invalid_or_expired_nonce")
+	}
+	if err != nil {
+		return err
+	}
+	// A wrong solution leaves the nonce unused so the client can retry.
+	if !VerifyPoW(nonce, powSolution, powDifficulty) {
+		return fmt.Errorf("invalid_pow")
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE auth_nonces SET used_at = now() WHERE id = $1`, nonceID)
+	return err
+}
+
+// NoncePoWDifficulty returns the proof-of-work difficulty of an unused,
+// unexpired login nonce without consuming it, so an unsolved puzzle can be
+// rejected before the signature is verified. A missing nonce is
+// "invalid_or_expired_nonce".
+func NoncePoWDifficulty(ctx context.Context, pool *pgxpool.Pool, walletType WalletType, address, nonce string) (int, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	var powDifficulty int
+	err := pool.QueryRow(ctx, `
+SELECT pow_difficulty
+FROM auth_nonces
+WHERE wallet_type = $1
+  AND address = $2
+  AND nonce = $3
+  AND used_at IS NULL
+  AND expires_at > now()
+`, string(walletType), address, nonce).Scan(&powDifficulty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("invalid_or_expired_nonce")
+	}
+	return powDifficulty, err
+}
+
+// randomNonce is hex so nonces stay alphanumeric, as EIP-4361 requires.
 func randomNonce(n int) string {
 	b := make([]byte, n)
 	if _, err := rand.Read(b); err != nil {
 		// Should never happen, but keep it deterministic-ish if entropy fails.
-		return uuid.NewString()
+		return strings.ReplaceAll(uuid.NewString(), "-", "")
 	}
-	return base64.RawURLEncoding.EncodeToString(b)
+	return hex.EncodeToString(b)
 }
 
 func nullIfEmpty(s string) any {
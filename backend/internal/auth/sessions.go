@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session_not_found")
+	ErrSessionRevoked  = errors.New("session_revoked")
+)
+
+// sessionTouchInterval throttles last_seen_at writes from the auth middleware.
+const sessionTouchInterval = time.Minute
+
+// DeviceSession is a signed-in device as shown to its owner.
+type DeviceSession struct {
+	ID         uuid.UUID  `json:"id"`
+	WalletType WalletType `json:"wallet_type,omitempty"`
+	Address    string     `json:"address,omitempty"`
+	IP         string     `json:"ip,omitempty"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+// CreateSession records a sign-in that stays listed until expiresAt unless
+// refreshed or revoked.
+func CreateSession(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, walletType WalletType, address, ip, userAgent string, expiresAt time.Time) (uuid.UUID, error) {
+	if pool == nil {
+		return uuid.Nil, fmt.Errorf("db not configured")
+	}
+	var id uuid.UUID
+	err := pool.QueryRow(ctx, `
+INSERT INTO sessions (user_id, wallet_type, address, ip, user_agent, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`, userID, nullIfEmpty(string(walletType)), nullIfEmpty(address), nullIfEmpty(ip), nullIfEmpty(userAgent), expiresAt).Scan(&id)
+	return id, err
+}
+
+// ListSessions returns the user's active sessions, most recently seen first.
+func ListSessions(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]DeviceSession, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, COALESCE(wallet_type, ''), COALESCE(address, ''), COALESCE(ip, ''), COALESCE(user_agent, ''),
+       created_at, last_seen_at, expires_at
+FROM sessions
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+ORDER BY last_seen_at DESC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []DeviceSession{}
+	for rows.Next() {
+		var s DeviceSession
+		var wt string
+		if err := rows.Scan(&s.ID, &wt, &s.Address, &s.IP, &s.UserAgent, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		s.WalletType = WalletType(wt)
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// RevokeSession signs out one of the user's sessions along with its refresh
+// tokens.
+func RevokeSession(ctx context.Context, pool *pgxpool.Pool, userID, sessionID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+UPDATE sessions SET revoked_at = now()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL`, sessionID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// RevokeUserSessions signs the user out everywhere: every session and refresh
+// token is revoked. q may be a pool or a transaction. It returns how many
+// sessions were live.
+func RevokeUserSessions(ctx context.Context, q execer, userID uuid.UUID) (int64, error) {
+	tag, err := q.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := q.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userID); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RevokeWalletSessions signs out the sessions started by signing in with the
+// user's wallet, and revokes their refresh tokens. q may be a pool or a
+// transaction.
+func RevokeWalletSessions(ctx context.Context, q execer, userID uuid.UUID, walletType WalletType, address string) error {
+	if _, err := q.Exec(ctx, `
+UPDATE sessions SET revoked_at = now()
+WHERE user_id = $1 AND wallet_type = $2 AND address = $3 AND revoked_at IS NULL
+`, userID, string(walletType), address); err != nil {
+		return err
+	}
+	_, err := q.Exec(ctx, `
+UPDATE refresh_tokens SET revoked_at = now()
+WHERE user_id = $1 AND wallet_type = $2 AND address = $3 AND revoked_at IS NULL
+`, userID, string(walletType), address)
+	return err
+}
+
+// CheckSession returns ErrSessionRevoked unless the session is live, and
+// records the caller's IP and user agent at most once per minute.
+func CheckSession(ctx context.Context, pool *pgxpool.Pool, sessionID uuid.UUID, ip, userAgent string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	var (
+		revokedAt *time.Time
+		lastSeen  time.Time
+	)
+	err := pool.QueryRow(ctx, `SELECT revoked_at, last_seen_at FROM sessions WHERE id = $1`, sessionID).Scan(&revokedAt, &lastSeen)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && revokedAt != nil) {
+		return ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if time.Since(lastSeen) >= sessionTouchInterval {
+		_, _ = pool.Exec(ctx, `
+UPDATE sessions SET last_seen_at = now(), ip = COALESCE($2, ip), user_agent = COALESCE($3, user_agent)
+WHERE id = $1
+`, sessionID, nullIfEmpty(ip), nullIfEmpty(userAgent))
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SIWE (EIP-4361) login messages for EVM wallets, which wallets such as
+// MetaMask render as a structured sign-in prompt.
+
+const (
+	siweHeaderSuffix = " wants you to sign in with your Ethereum account:"
+	SIWEVersion      = "1"
+)
+
+var (
+	ErrSIWEMalformed = errors.New("siwe_malformed")
+	ErrSIWEDomain    = errors.New("siwe_domain_mismatch")
+	ErrSIWEChain     = errors.New("siwe_chain_mismatch")
+	ErrSIWEAddress   = errors.New("siwe_address_mismatch")
+	ErrSIWENonce     = errors.New("siwe_nonce_mismatch")
+	ErrSIWEExpired   = errors.New("siwe_expired")
+	ErrSIWENotYet    = errors.New("siwe_not_yet_valid")
+)
+
+// SIWEMessage holds the EIP-4361 fields. Optional fields are zero when absent.
+type SIWEMessage struct {
+	Scheme         string
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// String renders the message exactly as the wallet signs it.
+func (m SIWEMessage) String() string {
+	var b strings.Builder
+	if m.Scheme != "" {
+		b.WriteString(m.Scheme + "://")
+	}
+	b.WriteString(m.Domain + siweHeaderSuffix + "\n")
+	b.WriteString(m.Address + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+	version := m.Version
+	if version == "" {
+		version = SIWEVersion
+	}
+	fmt.Fprintf(&b, "URI: %s\nVersion: %s\nChain ID: %d\nNonce: %s\nIssued At: %s",
+		m.URI, version, m.ChainID, m.Nonce, m.IssuedAt.UTC().Format(time.RFC3339))
+	if m.ExpirationTime != nil {
+		b.WriteString("\nExpiration Time: " + m.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	if m.NotBefore != nil {
+		b.WriteString("\nNot Before: " + m.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		b.WriteString("\nRequest ID: " + m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, r := range m.Resources {
+			b.WriteString("\n- " + r)
+		}
+	}
+	return b.String()
+}
+
+// ParseSIWE parses an EIP-4361 message. It checks the grammar only; use
+// Validate for the domain, address, nonce and time bindings.
+func ParseSIWE(s string) (SIWEMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if len(lines) < 8 {
+		return SIWEMessage{}, ErrSIWEMalformed
+	}
+	var m SIWEMessage
+
+	header, ok := strings.CutSuffix(lines[0], siweHeaderSuffix)
+	if !ok || header == "" {
+		return SIWEMessage{}, ErrSIWEMalformed
+	}
+	if scheme, domain, ok := strings.Cut(header, "://"); ok {
+		m.Scheme, header = scheme, domain
+	}
+	m.Domain = header
+	m.Address = lines[1]
+	if !common.IsHexAddress(m.Address) || !strings.HasPrefix(m.Address, "0x") {
+		return SIWEMessage{}, ErrSIWEMalformed
+	}
+	if lines[2] != "" {
+		return SIWEMessage{}, ErrSIWEMalformed
+	}
+
+	// Either "statement", "" or just "" before the fields.
+	i := 3
+	if lines[i] != "" {
+		m.Statement = lines[i]
+		i++
+	}
+	if lines[i] != "" {
+		return SIWEMessage{}, ErrSIWEMalformed
+	}
+	i++
+
+	field := func(name string, required bool) (string, error) {
+		if i < len(lines) {
+			if v, ok := strings.CutPrefix(lines[i], name+": "); ok {
+				i++
+				return v, nil
+			}
+		}
+		if required {
+			return "", fmt.Errorf("%w: missing %s", ErrSIWEMalformed, name)
+		}
+		return "", nil
+	}
+	parseTime := func(v string) (time.Time, error) {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: bad timestamp", ErrSIWEMalformed)
+		}
+		return t, nil
+	}
+
+	var err error
+	if m.URI, err = field("URI", true); err != nil {
+		return SIWEMessage{}, err
+	}
+	if _, err := url.Parse(m.URI); err != nil {
+		return SIWEMessage{}, ErrSIWEMalformed
+	}
+	if m.Version, err = field("Version", true); err != nil {
+		return SIWEMessage{}, err
+	}
+	if m.Version != SIWEVersion {
+		return SIWEMessage{}, fmt.Errorf("%w: unsupported version", ErrSIWEMalformed)
+	}
+	chainID, err := field("Chain ID", true)
+	if err != nil {
+		return SIWEMessage{}, err
+	}
+	if m.ChainID, err = strconv.ParseInt(chainID, 10, 64); err != nil || m.ChainID <= 0 {
+		return SIWEMessage{}, fmt.Errorf("%w: bad chain id", ErrSIWEMalformed)
+	}
+	if m.Nonce, err = field("Nonce", true); err != nil {
+		return SIWEMessage{}, err
+	}
+	if !validSIWENonce(m.Nonce) {
+		return SIWEMessage{}, fmt.Errorf("%w: bad nonce", ErrSIWEMalformed)
+	}
+	issuedAt, err := field("Issued At", true)
+	if err != nil {
+		return SIWEMessage{}, err
+	}
+	if m.IssuedAt, err = parseTime(issuedAt); err != nil {
+		return SIWEMessage{}, err
+	}
+	if v, _ := field("Expiration Time", false); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			return SIWEMessage{}, err
+		}
+		m.ExpirationTime = &t
+	}
+	if v, _ := field("Not Before", false); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			return SIWEMessage{}, err
+		}
+		m.NotBefore = &t
+	}
+	m.RequestID, _ = field("Request ID", false)
+	if i < len(lines) && lines[i] == "Resources:" {
+		i++
+		for ; i < len(lines); i++ {
+			r, ok := strings.CutPrefix(lines[i], "- ")
+			if !ok {
+				break
+			}
+			m.Resources = append(m.Resources, r)
+		}
+	}
+	if i != len(lines) {
+		return SIWEMessage{}, fmt.Errorf("%w: unexpected trailing lines", ErrSIWEMalformed)
+	}
+	return m, nil
+}
+
+// evmChainIDs are the EIP-155 chain IDs of the EVM chains this service
+// knows by name.
+var evmChainIDs = map[string]int64{
+	"ethereum": 1,
+	"sepolia":  11155111,
+	"polygon":  137,
+	"arbitrum": 42161,
+	"optimism": 10,
+	"base":     8453,
+}
+
+// EVMChainID returns the chain ID of a named EVM chain.
+func EVMChainID(chain string) (int64, bool) {
+	id, ok := evmChainIDs[strings.ToLower(strings.TrimSpace(chain))]
+	return id, ok
+}
+
+func validSIWENonce(n string) bool {
+	if len(n) < 8 {
+		return false
+	}
+	for _, r := range n {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate binds the message to this service: the domain, which must be
+// set, one of the chain IDs it serves, the signing address (which must be
+// EIP-55 checksummed), the issued nonce, and the validity window at now.
+func (m SIWEMessage) Validate(domain string, chainIDs []int64, address, nonce string, now time.Time) error {
+	if domain == "" || !strings.EqualFold(m.Domain, domain) {
+		return ErrSIWEDomain
+	}
+	if !slices.Contains(chainIDs, m.ChainID) {
+		return ErrSIWEChain
+	}
+	if m.Address != common.HexToAddress(m.Address).Hex() || !strings.EqualFold(m.Address, address) {
+		return ErrSIWEAddress
+	}
+	if m.Nonce != nonce {
+		return ErrSIWENonce
+	}
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return ErrSIWEExpired
+	}
+	if m.NotBefore != nil && now.Before(*m.NotBefore) {
+		return ErrSIWENotYet
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSIWERoundTrip(t *testing.T) {
+	issued := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	exp := issued.Add(10 * time.Minute)
+	for _, statement := range []string{"Sign in to Grainlify.", ""} {
+		m := SIWEMessage{
+			Domain:         "grainlify.io",
+			Address:        "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			Statement:      statement,
+			URI:            "https://grainlify.io",
+			ChainID:        1,
+			Nonce:          "a1b2c3d4e5f6",
+			IssuedAt:       issued,
+			ExpirationTime: &exp,
+			Resources:      []string{"https://grainlify.io/terms"},
+		}
+		got, err := ParseSIWE(m.String())
+		if err != nil {
+			t.Fatalf("ParseSIWE(%q): %v", m.String(), err)
+		}
+		if got.String() != m.String() {
+			t.Errorf("round trip mismatch:\n%s\n---\n%s", got.String(), m.String())
+		}
+		if got.Statement != statement || got.ChainID != 1 || got.Version != SIWEVersion || len(got.Resources) != 1 {
+			t.Errorf("parsed fields = %+v", got)
+		}
+	}
+}
+
+func TestSIWEParseRejects(t *testing.T) {
+	valid := "grainlify.io wants you to sign in with your Ethereum account:\n" +
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\n\n\n" +
+		"URI: https://grainlify.io\nVersion: 1\nChain ID: 1\nNonce: a1b2c3d4e5f6\nIssued At: 2026-01-02T03:04:05Z"
+	if _, err := ParseSIWE(valid); err != nil {
+		t.Fatalf("valid message rejected: %v", err)
+	}
+	for name, msg := range map[string]string{
+		"version":   "grainlify.io wants you to sign in with your Ethereum account:\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\n\n\nURI: https://grainlify.io\nVersion: 2\nChain ID: 1\nNonce: a1b2c3d4e5f6\nIssued At: 2026-01-02T03:04:05Z",
+		"nonce":     "grainlify.io wants you to sign in with your Ethereum account:\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\n\n\nURI: https://grainlify.io\nVersion: 1\nChain ID: 1\nNonce: ab-cd_ef12\nIssued At: 2026-01-02T03:04:05Z",
+		"address":   "grainlify.io wants you to sign in with your Ethereum account:\nnot-an-address\n\n\nURI: https://grainlify.io\nVersion: 1\nChain ID: 1\nNonce: a1b2c3d4e5f6\nIssued At: 2026-01-02T03:04:05Z",
+		"trailing":  valid + "\nsomething else",
+		"no header": "Patchwork login. Nonce: a1b2c3d4e5f6",
+	} {
+		if _, err := ParseSIWE(msg); !errors.Is(err, ErrSIWEMalformed) {
+			t.Errorf("%s: err = %v, want ErrSIWEMalformed", name, err)
+		}
+	}
+}
+
+func TestSIWEValidate(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC)
+	exp := now.Add(time.Minute)
+	m := SIWEMessage{
+		Domain:         "grainlify.io",
+		Address:        "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		ChainID:        8453,
+		Nonce:          "a1b2c3d4e5f6",
+		ExpirationTime: &exp,
+	}
+	addr := "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	chains := []int64{1, 8453}
+	if err := m.Validate("grainlify.io", chains, addr, "a1b2c3d4e5f6", now); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := m.Validate("evil.example", chains, addr, "a1b2c3d4e5f6", now); !errors.Is(err, ErrSIWEDomain) {
+		t.Errorf("domain: %v", err)
+	}
+	if err := m.Validate("", chains, addr, "a1b2c3d4e5f6", now); !errors.Is(err, ErrSIWEDomain) {
+		t.Errorf("unset domain: %v", err)
+	}
+	if err := m.Validate("grainlify.io", []int64{1}, addr, "a1b2c3d4e5f6", now); !errors.Is(err, ErrSIWEChain) {
+		t.Errorf("chain: %v", err)
+	}
+	if err := m.Validate("grainlify.io", chains, addr, "other0000", now); !errors.Is(err, ErrSIWENonce) {
+		t.Errorf("nonce: %v", err)
+	}
+	if err := m.Validate("grainlify.io", chains, addr, "a1b2c3d4e5f6", exp); !errors.Is(err, ErrSIWEExpired) {
+		t.Errorf("expiry: %v", err)
+	}
+	lower := m
+	lower.Address = addr
+	if err := lower.Validate("grainlify.io", chains, addr, "a1b2c3d4e5f6", now); !errors.Is(err, ErrSIWEAddress) {
+		t.Errorf("checksum: %v", err)
+	}
+}
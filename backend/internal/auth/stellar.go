@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/txnbuild"
+)
+
+// sep53Prefix is prepended to messages signed with SEP-53 (Freighter's signMessage).
+const sep53Prefix = "Stellar Signed Message:\n"
+
+// isStellarAccount reports whether a is a G... account address.
+func isStellarAccount(a string) bool {
+	_, err := strkey.Decode(strkey.VersionByteAccountID, a)
+	return err == nil
+}
+
+// stellarPublicKey returns the ed25519 key for a Stellar login. A G... address
+// is the key itself, and a given public key must match it; other (legacy)
+// addresses are opaque and need the hex public key.
+func stellarPublicKey(address string, publicKey string) (ed25519.PublicKey, error) {
+	pk := strings.TrimSpace(publicKey)
+	if !isStellarAccount(address) {
+		b, err := decodeHex(pk)
+		if err != nil || len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public_key")
+		}
+		return b, nil
+	}
+	key := ed25519.PublicKey(strkey.MustDecode(strkey.VersionByteAccountID, address))
+	if pk == "" {
+		return key, nil
+	}
+	given, err := strkey.Decode(strkey.VersionByteAccountID, strings.ToUpper(pk))
+	if err != nil {
+		given, err = decodeHex(pk)
+	}
+	if err != nil || !key.Equal(ed25519.PublicKey(given)) {
+		return nil, fmt.Errorf("public_key does not match address")
+	}
+	return key, nil
+}
+
+// decodeStellarSignature accepts hex (Albedo) or base64 (Freighter) signatures.
+func decodeStellarSignature(s string) ([]byte, error) {
+	if b, err := decodeHex(s); err == nil && len(b) == ed25519.SignatureSize {
+		return b, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	return b, nil
+}
+
+// stellarSignedPayloads lists what Stellar wallets actually sign when asked to
+// sign message: the raw bytes, the SEP-53 hash (Freighter), and
+// "<address>:<message>" (Albedo).
+func stellarSignedPayloads(address string, message string) [][]byte {
+	sep53 := sha256.Sum256([]byte(sep53Prefix + message))
+	payloads := [][]byte{[]byte(message), sep53[:]}
+	if isStellarAccount(address) {
+		payloads = append(payloads, []byte(address+":"+message))
+	}
+	return payloads
+}
+
+// StellarChallengeName is the ManageData key of a challenge for domain, as in SEP-10.
+func StellarChallengeName(domain string) string {
+	name := domain + " auth"
+	if len(name) > 64 {
+		name = name[len(name)-64:]
+	}
+	return name
+}
+
+// StellarChallenge builds a SEP-10 style challenge for wallets that can only
+// sign transactions: an unsubmittable transaction from address with sequence
+// 0, whose only operation is a ManageData carrying the nonce, valid until the
+// nonce expires. Unlike SEP-10 it is not signed by the server; the nonce row
+// already binds it to this login.
+func StellarChallenge(address, domain, nonce string, now, expiresAt time.Time) (string, error) {
+	if !isStellarAccount(address) {
+		return "", fmt.Errorf("challenge needs a G... address")
+	}
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: address, Sequence: -1},
+		IncrementSequenceNum: true,
+		Operations: []txnbuild.Operation{&txnbuild.ManageData{
+			Name:          StellarChallengeName(domain),
+			Value:         []byte(nonce),
+			SourceAccount: address,
+		}},
+		BaseFee:       txnbuild.MinBaseFee,
+		Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewTimebounds(now.Unix(), expiresAt.Unix())},
+	})
+	if err != nil {
+		return "", err
+	}
+	return tx.Base64()
+}
+
+// VerifyStellarChallenge checks that signedXDR is the challenge for address,
+// domain and nonce, still within its time bounds, and signed by address on
+// the network identified by passphrase.
+func VerifyStellarChallenge(signedXDR, address, domain, nonce, passphrase string, now time.Time) error {
+	kp, err := keypair.ParseAddress(address)
+	if err != nil {
+		return fmt.Errorf("challenge needs a G... address")
+	}
+	gt, err := txnbuild.TransactionFromXDR(strings.TrimSpace(signedXDR))
+	if err != nil {
+		return fmt.Errorf("invalid challenge transaction")
+	}
+	tx, ok := gt.Transaction()
+	if !ok {
+		return fmt.Errorf("invalid challenge transaction")
+	}
+	if tx.SourceAccount().AccountID != address || tx.SequenceNumber() != 0 {
+		return fmt.Errorf("challenge source mismatch")
+	}
+	ops := tx.Operations()
+	if len(ops) != 1 {
+		return fmt.Errorf("invalid challenge operations")
+	}
+	op, ok := ops[0].(*txnbuild.ManageData)
+	if !ok || op.Name != StellarChallengeName(domain) || string(op.Value) != nonce || (op.SourceAccount != "" && op.SourceAccount != address) {
+		return fmt.Errorf("invalid challenge operations")
+	}
+	tb := tx.Timebounds()
+	if now.Unix() < tb.MinTime || (tb.MaxTime != txnbuild.TimeoutInfinite && now.Unix() > tb.MaxTime) {
+		return fmt.Errorf("challenge expired")
+	}
+
+	hash, err := tx.Hash(passphrase)
+	if err != nil {
+		return err
+	}
+	hint := kp.Hint()
+	for _, sig := range tx.Signatures() {
+		if [4]byte(sig.Hint) == hint && kp.Verify(hash[:], sig.Signature) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid signature")
+}
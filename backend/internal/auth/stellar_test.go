@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/txnbuild"
+)
+
+func stellarTestKey(t *testing.T) *keypair.Full {
+	t.Helper()
+	kp, err := keypair.FromRawSeed(sha256.Sum256([]byte("grainlify stellar test wallet")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kp
+}
+
+func TestStellarMessageSignatures(t *testing.T) {
+	kp := stellarTestKey(t)
+	addr, err := NormalizeAddress(WalletTypeStellarEd25519, " "+kp.Address()+" ")
+	if err != nil || addr != kp.Address() {
+		t.Fatalf("NormalizeAddress = %q, %v", addr, err)
+	}
+	msg := LoginMessage("a1b2c3")
+	sign := func(payload []byte) []byte {
+		sig, err := kp.Sign(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	sep53 := sha256.Sum256([]byte(sep53Prefix + msg))
+	raw := sign([]byte(msg))
+	pubHex := hex.EncodeToString(strkey.MustDecode(strkey.VersionByteAccountID, kp.Address()))
+	other, err := keypair.Random()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		sig       string
+		publicKey string
+		ok        bool
+	}{
+		{"raw, hex", hex.EncodeToString(raw), "", true},
+		{"freighter sep-53, base64", base64.StdEncoding.EncodeToString(sign(sep53[:])), "", true},
+		{"albedo, hex", hex.EncodeToString(sign([]byte(addr + ":" + msg))), "", true},
+		{"matching public key", hex.EncodeToString(raw), kp.Address(), true},
+		{"other message", hex.EncodeToString(sign([]byte(LoginMessage("ffff")))), "", false},
+		{"matching hex public key", hex.EncodeToString(raw), pubHex, true},
+		{"mismatched public key", hex.EncodeToString(raw), other.Address(), false},
+	} {
+		err := VerifySignature(WalletTypeStellarEd25519, addr, msg, tc.sig, tc.publicKey)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: VerifySignature = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestStellarChallenge(t *testing.T) {
+	kp := stellarTestKey(t)
+	now := time.Unix(1_700_000_000, 0)
+	nonce := "5f2b8c9e0a1d4e7f5f2b8c9e0a1d4e7f5f2b8c9e0a1d4e7f5f2b8c9e0a1d4e7f"
+	challenge, err := StellarChallenge(kp.Address(), "grainlify.io", nonce, now, now.Add(10*time.Minute))
+	if err != nil {
+		t.Fatalf("StellarChallenge: %v", err)
+	}
+	signFor := func(passphrase string) string {
+		gt, err := txnbuild.TransactionFromXDR(challenge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx, _ := gt.Transaction()
+		tx, err = tx.Sign(passphrase, kp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := tx.Base64()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	signed := signFor(network.TestNetworkPassphrase)
+
+	if err := VerifyStellarChallenge(signed, kp.Address(), "grainlify.io", nonce, network.TestNetworkPassphrase, now.Add(time.Minute)); err != nil {
+		t.Fatalf("VerifyStellarChallenge: %v", err)
+	}
+	for _, tc := range []struct {
+		name, tx, domain, nonce, passphrase string
+		at                                  time.Time
+	}{
+		{"unsigned", challenge, "grainlify.io", nonce, network.TestNetworkPassphrase, now},
+		{"other network", signFor(network.PublicNetworkPassphrase), "grainlify.io", nonce, network.TestNetworkPassphrase, now},
+		{"other domain", signed, "evil.example", nonce, network.TestNetworkPassphrase, now},
+		{"other nonce", signed, "grainlify.io", "0000", network.TestNetworkPassphrase, now},
+		{"expired", signed, "grainlify.io", nonce, network.TestNetworkPassphrase, now.Add(11 * time.Minute)},
+		{"garbage", "AAAA", "grainlify.io", nonce, network.TestNetworkPassphrase, now},
+	} {
+		if err := VerifyStellarChallenge(tc.tx, kp.Address(), tc.domain, tc.nonce, tc.passphrase, tc.at); err == nil {
+			t.Errorf("%s: VerifyStellarChallenge accepted", tc.name)
+		}
+	}
+}
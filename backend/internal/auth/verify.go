@@ -1,17 +1,21 @@
 package auth
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/jagadeesh/grainlify/backend/internal/metrics"
 )
 
 type WalletType string
@@ -20,11 +24,12 @@ const (
 	WalletTypeEVM              WalletType = "evm"
 	WalletTypeStellarEd25519   WalletType = "stellar_ed25519"
 	WalletTypeStellarSecp256k1 WalletType = "stellar_secp256k1"
+	WalletTypeSolana           WalletType = "solana"
 )
 
 func NormalizeWalletType(v string) (WalletType, error) {
 	switch WalletType(strings.ToLower(strings.TrimSpace(v))) {
-	case WalletTypeEVM, WalletTypeStellarEd25519, WalletTypeStellarSecp256k1:
+	case WalletTypeEVM, WalletTypeStellarEd25519, WalletTypeStellarSecp256k1, WalletTypeSolana:
 		return WalletType(strings.ToLower(strings.TrimSpace(v))), nil
 	default:
 		return "", fmt.Errorf("unsupported wallet_type")
@@ -48,8 +53,18 @@ func NormalizeAddress(t WalletType, addr string) (string, error) {
 		}
 		return a, nil
 	case WalletTypeStellarEd25519, WalletTypeStellarSecp256k1:
-		// For now we treat `address` as an opaque identifier (often public key hex or account-hash).
+		if t == WalletTypeStellarEd25519 && isStellarAccount(strings.ToUpper(a)) {
+			return strings.ToUpper(a), nil
+		}
+		// Otherwise `address` is an opaque identifier (often public key hex or account-hash).
 		return strings.ToLower(a), nil
+	case WalletTypeSolana:
+		// The address is the base58 ed25519 public key; base58 is case-sensitive.
+		pub, err := decodeBase58(a)
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			return "", fmt.Errorf("invalid solana address")
+		}
+		return encodeBase58(pub), nil
 	default:
 		return "", fmt.Errorf("unsupported wallet_type")
 	}
@@ -59,15 +74,46 @@ func NormalizeAddress(t WalletType, addr string) (string, error) {
 //
 // Inputs:
 // - signatureHex: hex string (0x prefix optional)
-// - publicKeyHex: required for Stellar; ignored for EVM
+// - publicKeyHex: required for Stellar hex addresses; ignored for EVM; optional otherwise
+//
+// Solana and Stellar G... addresses are their ed25519 public keys, so a given
+// publicKeyHex must match them. Solana signatures may also be base58, as
+// Phantom returns them; Stellar ones base64, as Freighter returns them.
+//
+// Successful checks are remembered for a short while (see signatureCache), so
+// a login retried with the same signature skips the cryptography.
 func VerifySignature(t WalletType, address string, message string, signatureHex string, publicKeyHex string) error {
+	switch t {
+	case WalletTypeEVM, WalletTypeStellarEd25519, WalletTypeStellarSecp256k1, WalletTypeSolana:
+	default:
+		return fmt.Errorf("unsupported wallet_type")
+	}
+	start := time.Now()
+	key := signatureKey(t, address, message, signatureHex, publicKeyHex)
+	if signatures.hit(key, start) {
+		metrics.ObserveSignature(string(t), "cached", time.Since(start))
+		return nil
+	}
+	err := verifySignature(t, address, message, signatureHex, publicKeyHex)
+	result := "invalid"
+	if err == nil {
+		result = "valid"
+		signatures.add(key, time.Now())
+	}
+	metrics.ObserveSignature(string(t), result, time.Since(start))
+	return err
+}
+
+func verifySignature(t WalletType, address string, message string, signatureHex string, publicKeyHex string) error {
 	switch t {
 	case WalletTypeEVM:
 		return verifyEVM(address, message, signatureHex)
 	case WalletTypeStellarEd25519:
-		return verifyStellarEd25519(message, signatureHex, publicKeyHex)
+		return verifyStellarEd25519(address, message, signatureHex, publicKeyHex)
 	case WalletTypeStellarSecp256k1:
 		return verifyStellarSecp256k1(message, signatureHex, publicKeyHex)
+	case WalletTypeSolana:
+		return verifySolana(address, message, signatureHex, publicKeyHex)
 	default:
 		return fmt.Errorf("unsupported wallet_type")
 	}
@@ -99,19 +145,21 @@ func verifyEVM(expectedAddr string, message string, signatureHex string) error {
 	return nil
 }
 
-func verifyStellarEd25519(message string, signatureHex string, publicKeyHex string) error {
-	pubKeyBytes, err := decodeHex(publicKeyHex)
-	if err != nil || len(pubKeyBytes) != ed25519.PublicKeySize {
-		return fmt.Errorf("invalid public_key")
+func verifyStellarEd25519(address string, message string, signature string, publicKey string) error {
+	pubKey, err := stellarPublicKey(address, publicKey)
+	if err != nil {
+		return err
 	}
-	sigBytes, err := decodeHex(signatureHex)
-	if err != nil || len(sigBytes) != ed25519.SignatureSize {
-		return fmt.Errorf("invalid signature")
+	sigBytes, err := decodeStellarSignature(signature)
+	if err != nil {
+		return err
 	}
-	if !ed25519.Verify(ed25519.PublicKey(pubKeyBytes), []byte(message), sigBytes) {
-		return fmt.Errorf("invalid signature")
+	for _, payload := range stellarSignedPayloads(address, message) {
+		if ed25519.Verify(pubKey, payload, sigBytes) {
+			return nil
+		}
 	}
-	return nil
+	return fmt.Errorf("invalid signature")
 }
 
 func verifyStellarSecp256k1(message string, signatureHex string, publicKeyHex string) error {
@@ -142,6 +190,34 @@ func verifyStellarSecp256k1(message string, signatureHex string, publicKeyHex st
 	return nil
 }
 
+func verifySolana(address string, message string, signature string, publicKey string) error {
+	pubKeyBytes, err := decodeBase58(strings.TrimSpace(address))
+	if err != nil || len(pubKeyBytes) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid solana address")
+	}
+	if pk := strings.TrimSpace(publicKey); pk != "" {
+		given, err := decodeBase58(pk)
+		if err != nil || len(given) != ed25519.PublicKeySize {
+			given, err = decodeHex(pk)
+		}
+		if err != nil || !bytes.Equal(given, pubKeyBytes) {
+			return fmt.Errorf("public_key does not match address")
+		}
+	}
+	sigBytes, err := decodeHex(signature)
+	if err != nil || len(sigBytes) != ed25519.SignatureSize {
+		sigBytes, err = decodeBase58(strings.TrimSpace(signature))
+	}
+	if err != nil || len(sigBytes) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature")
+	}
+	// Wallets sign the UTF-8 message bytes directly (Phantom's signMessage).
+	if !ed25519.Verify(ed25519.PublicKey(pubKeyBytes), []byte(message), sigBytes) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
 func decodeHex(s string) ([]byte, error) {
 	v := strings.TrimSpace(s)
 	if v == "" {
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Whether a signature verifies depends only on its inputs, so a success can
+// be reused: clients retrying a login during a storm resend the exact same
+// signature, and each check costs an ed25519 or secp256k1 verification.
+// Callers still refuse reused nonces. Failures aren't cached, so a flood of
+// bad signatures can't evict good ones.
+//
+// crypto/ed25519 already uses assembly field arithmetic on amd64 and arm64,
+// and a login checks a single signature, so there is nothing to batch.
+const (
+	signatureCacheTTL  = 2 * time.Minute
+	signatureCacheSize = 8192
+)
+
+var signatures = newSignatureCache(signatureCacheSize, signatureCacheTTL)
+
+// signatureCache keeps entries in insertion order, which with one TTL for
+// all is also expiry order, so evicting is popping the oldest.
+type signatureCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // of *signatureEntry, oldest first
+	entries map[[sha256.Size]byte]*list.Element
+}
+
+type signatureEntry struct {
+	key     [sha256.Size]byte
+	expires time.Time
+}
+
+func newSignatureCache(size int, ttl time.Duration) *signatureCache {
+	return &signatureCache{size: size, ttl: ttl, order: list.New(), entries: map[[sha256.Size]byte]*list.Element{}}
+}
+
+// signatureKey hashes a verification's inputs, each length-prefixed so that
+// no two input sets share a key.
+func signatureKey(t WalletType, fields ...string) [sha256.Size]byte {
+	h := sha256.New()
+	var n [8]byte
+	for _, f := range append([]string{string(t)}, fields...) {
+		binary.BigEndian.PutUint64(n[:], uint64(len(f)))
+		h.Write(n[:])
+		h.Write([]byte(f))
+	}
+	var out [sha256.Size]byte
+	h.Sum(out[:0])
+	return out
+}
+
+func (c *signatureCache) hit(key [sha256.Size]byte, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if ok && !now.Before(el.Value.(*signatureEntry).expires) {
+		c.remove(el)
+		return false
+	}
+	return ok
+}
+
+func (c *signatureCache) add(key [sha256.Size]byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	// Drop expired entries, then the oldest if still full; a dropped entry
+	// only costs a re-check.
+	for front := c.order.Front(); front != nil; front = c.order.Front() {
+		if now.Before(front.Value.(*signatureEntry).expires) && len(c.entries) < c.size {
+			break
+		}
+		c.remove(front)
+	}
+	c.entries[key] = c.order.PushBack(&signatureEntry{key: key, expires: now.Add(c.ttl)})
+}
+
+func (c *signatureCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*signatureEntry).key)
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// A Phantom-style login: signMessage over the UTF-8 login message, with the
+// wallet's base58 address and the signature as base58 or hex.
+const (
+	solanaAddress   = "2ZutB1R3gN4ZaVvFrRpi9wdasr4jz9Bq7zgt6RWXmZoL"
+	solanaPubKeyHex = "174a112761b860b23381fafac5574d440587da455920f7b84e4e8c6184ebd05f"
+	solanaNonce     = "5f2b8c9e0a1d4e7f"
+	solanaSigB58    = "5U4fb8iEoHLm2Xe5zC8VSo7MpjTKpAdjaQ2QGTREpp9sDVpSJ2q1TmB39QWNUyw82Vc9ZmuuT3hr4176uMz5VVuD"
+	solanaSigHex    = "df6801082f71e07069ff8f83888cc9e715603581de31021bfc27de9f7e211564dcfadb4a57d0d655c41269dfa16cc9fb26ce4570af781bf00885884c57022504"
+)
+
+// Phantom's first account for the BIP-39 test mnemonic "abandon abandon ...
+// about" (m/44'/501'/0'/0'), and what its signMessage returns for
+// LoginMessage(phantomNonce), base58 encoded as dapps send it. Ed25519
+// signatures are deterministic, so importing the mnemonic into Phantom and
+// signing the message reproduces it byte for byte.
+const (
+	phantomAddress = "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk"
+	phantomNonce   = "7c1e4a9b2d6f8e30"
+	phantomSig     = "3RbST3NhQBL36k7fjwbEqWR6GaMmTUmJ4GYEHkP9LwGHPoMYqhJzKkvWP3mJayXTNGcgNp6vvptW7ddNU8g2erat"
+)
+
+func TestPhantomSignMessage(t *testing.T) {
+	msg := LoginMessage(phantomNonce)
+	if err := verifySignature(WalletTypeSolana, phantomAddress, msg, phantomSig, phantomAddress); err != nil {
+		t.Fatalf("Phantom signature rejected: %v", err)
+	}
+	if err := verifySignature(WalletTypeSolana, phantomAddress, LegacyLoginMessage(phantomNonce), phantomSig, ""); err == nil {
+		t.Fatal("Phantom signature accepted for another message")
+	}
+}
+
+func TestBase58(t *testing.T) {
+	for _, tc := range []struct {
+		enc string
+		dec []byte
+	}{
+		{"2NEpo7TZRRrLZSi2U", []byte("Hello World!")},
+		{"11111111111111111111111111111111", make([]byte, 32)},
+		{"1112", []byte{0, 0, 0, 1}},
+	} {
+		got, err := decodeBase58(tc.enc)
+		if err != nil || !bytes.Equal(got, tc.dec) {
+			t.Errorf("decodeBase58(%q) = %x, %v; want %x", tc.enc, got, err, tc.dec)
+		}
+		if enc := encodeBase58(tc.dec); enc != tc.enc {
+			t.Errorf("encodeBase58(%x) = %q, want %q", tc.dec, enc, tc.enc)
+		}
+	}
+	if _, err := decodeBase58("0OIl"); err == nil {
+		t.Error("decodeBase58 accepted characters outside the alphabet")
+	}
+}
+
+func TestSolanaWallet(t *testing.T) {
+	wType, err := NormalizeWalletType(" Solana ")
+	if err != nil || wType != WalletTypeSolana {
+		t.Fatalf("NormalizeWalletType = %q, %v", wType, err)
+	}
+	addr, err := NormalizeAddress(wType, " "+solanaAddress+" ")
+	if err != nil || addr != solanaAddress {
+		t.Fatalf("NormalizeAddress = %q, %v", addr, err)
+	}
+	for _, bad := range []string{"0x174a112761b860b2", "2ZutB1R3gN4ZaVvFrRpi9wdasr4jz9Bq7zgt6RWXmZo", "2zutb1r3gn4zavvfrrpi9wdasr4jz9bq7zgt6rwxmzol"} {
+		if _, err := NormalizeAddress(wType, bad); err == nil {
+			t.Errorf("NormalizeAddress(%q) accepted an invalid address", bad)
+		}
+	}
+
+	msg := LoginMessage(solanaNonce)
+	for _, tc := range []struct {
+		name           string
+		addr, msg      string
+		sig, publicKey string
+		ok             bool
+	}{
+		{"base58 signature", solanaAddress, msg, solanaSigB58, "", true},
+		{"hex signature", solanaAddress, msg, solanaSigHex, "", true},
+		{"0x hex signature", solanaAddress, msg, "0x" + solanaSigHex, "", true},
+		{"matching base58 public key", solanaAddress, msg, solanaSigB58, solanaAddress, true},
+		{"matching hex public key", solanaAddress, msg, solanaSigB58, solanaPubKeyHex, true},
+		{"other nonce", solanaAddress, LoginMessage("0000000000000000"), solanaSigB58, "", false},
+		{"other address", "11111111111111111111111111111111", msg, solanaSigB58, "", false},
+		{"mismatched public key", solanaAddress, msg, solanaSigB58, "11111111111111111111111111111111", false},
+		{"truncated signature", solanaAddress, msg, solanaSigHex[:126], "", false},
+		{"garbage signature", solanaAddress, msg, "not-a-signature", "", false},
+	} {
+		err := VerifySignature(WalletTypeSolana, tc.addr, tc.msg, tc.sig, tc.publicKey)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: VerifySignature = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestSignatureCache(t *testing.T) {
+	now := time.Now()
+	c := newSignatureCache(2, time.Minute)
+	a, b, d := signatureKey(WalletTypeSolana, "a"), signatureKey(WalletTypeSolana, "b"), signatureKey(WalletTypeSolana, "d")
+	if signatureKey(WalletTypeSolana, "ab", "c") == signatureKey(WalletTypeSolana, "a", "bc") {
+		t.Fatal("keys of different inputs collide")
+	}
+	c.add(a, now)
+	if !c.hit(a, now.Add(59*time.Second)) || c.hit(a, now.Add(time.Minute)) {
+		t.Fatal("entry not kept for exactly its ttl")
+	}
+	c.add(a, now)
+	c.add(b, now)
+	c.add(d, now)
+	if len(c.entries) != 2 || !c.hit(d, now) {
+		t.Fatalf("cache over its size: %d entries", len(c.entries))
+	}
+	if c.hit(a, now) || !c.hit(b, now) {
+		t.Fatal("full cache did not evict its oldest entry")
+	}
+}
+
+func TestVerifySignatureCachesSuccessOnly(t *testing.T) {
+	msg := LoginMessage(solanaNonce)
+	for i := 0; i < 2; i++ {
+		if err := VerifySignature(WalletTypeSolana, solanaAddress, msg, solanaSigB58, ""); err != nil {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+	}
+	if !signatures.hit(signatureKey(WalletTypeSolana, solanaAddress, msg, solanaSigB58, ""), time.Now()) {
+		t.Fatal("valid signature not cached")
+	}
+	bad := LoginMessage("0000000000000000")
+	if err := VerifySignature(WalletTypeSolana, solanaAddress, bad, solanaSigB58, ""); err == nil {
+		t.Fatal("signature over another message accepted")
+	}
+	if signatures.hit(signatureKey(WalletTypeSolana, solanaAddress, bad, solanaSigB58, ""), time.Now()) {
+		t.Fatal("invalid signature cached")
+	}
+}
+
+// go test ./internal/auth -bench Verify -benchmem
+func BenchmarkVerifySignature(b *testing.B) {
+	msg := LoginMessage(solanaNonce)
+	b.Run("solana", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := verifySignature(WalletTypeSolana, solanaAddress, msg, solanaSigB58, ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("solana_cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := VerifySignature(WalletTypeSolana, solanaAddress, msg, solanaSigB58, ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
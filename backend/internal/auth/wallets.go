@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrWalletNotFound        = errors.New("wallet_not_found")
+	ErrWalletAlreadyLinked   = errors.New("wallet_already_linked")
+	ErrWalletLinkedElsewhere = errors.New("wallet_linked_to_another_account")
+	ErrLastWallet            = errors.New("cannot_unlink_last_wallet")
+)
+
+// LinkedWallet is a wallet on the caller's account.
+type LinkedWallet struct {
+	ID         uuid.UUID  `json:"id"`
+	WalletType WalletType `json:"wallet_type"`
+	Address    string     `json:"address"`
+	IsPrimary  bool       `json:"is_primary"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+const linkedWalletColumns = `id, wallet_type, address, is_primary, created_at`
+
+func scanLinkedWallet(row pgx.Row) (LinkedWallet, error) {
+	var w LinkedWallet
+	var wt string
+	err := row.Scan(&w.ID, &wt, &w.Address, &w.IsPrimary, &w.CreatedAt)
+	w.WalletType = WalletType(wt)
+	return w, err
+}
+
+func ListWallets(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]LinkedWallet, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+linkedWalletColumns+`
+FROM wallets
+WHERE user_id = $1
+ORDER BY is_primary DESC, created_at
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []LinkedWallet{}
+	for rows.Next() {
+		w, err := scanLinkedWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// GetWallet returns one of the user's wallets.
+func GetWallet(ctx context.Context, pool *pgxpool.Pool, userID, walletID uuid.UUID) (LinkedWallet, error) {
+	if pool == nil {
+		return LinkedWallet{}, fmt.Errorf("db not configured")
+	}
+	w, err := scanLinkedWallet(pool.QueryRow(ctx, `
+SELECT `+linkedWalletColumns+`
+FROM wallets
+WHERE id = $1 AND user_id = $2
+`, walletID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LinkedWallet{}, ErrWalletNotFound
+	}
+	return w, err
+}
+
+// LinkWallet adds a wallet to userID's account after the caller proved
+// ownership by signing the nonce. The first wallet becomes primary, as does
+// any wallet linked with makePrimary.
+func LinkWallet(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, walletType WalletType, address, nonce, publicKey, powSolution string, makePrimary bool) (LinkedWallet, error) {
+	if pool == nil {
+		return LinkedWallet{}, fmt.Errorf("db not configured")
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return LinkedWallet{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := consumeNonce(ctx, tx, walletType, address, nonce, powSolution); err != nil {
+		return LinkedWallet{}, err
+	}
+
+	var owner uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT user_id FROM wallets WHERE wallet_type = $1 AND address = $2`, string(walletType), address).Scan(&owner)
+	switch {
+	case err == nil && owner == userID:
+		return LinkedWallet{}, ErrWalletAlreadyLinked
+	case err == nil:
+		return LinkedWallet{}, ErrWalletLinkedElsewhere
+	case !errors.Is(err, pgx.ErrNoRows):
+		return LinkedWallet{}, err
+	}
+
+	var hasPrimary bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM wallets WHERE user_id = $1 AND is_primary)`, userID).Scan(&hasPrimary); err != nil {
+		return LinkedWallet{}, err
+	}
+	primary := makePrimary || !hasPrimary
+	if primary && hasPrimary {
+		if _, err := tx.Exec(ctx, `UPDATE wallets SET is_primary = false WHERE user_id = $1 AND is_primary`, userID); err != nil {
+			return LinkedWallet{}, err
+		}
+	}
+
+	w, err := scanLinkedWallet(tx.QueryRow(ctx, `
+INSERT INTO wallets (user_id, wallet_type, address, public_key, is_primary)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING `+linkedWalletColumns,
+		userID, string(walletType), address, nullIfEmpty(publicKey), primary))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		// Linked concurrently by someone else.
+		return LinkedWallet{}, ErrWalletLinkedElsewhere
+	}
+	if err != nil {
+		return LinkedWallet{}, err
+	}
+	return w, tx.Commit(ctx)
+}
+
+// SetPrimaryWallet makes walletID the user's primary payout wallet.
+func SetPrimaryWallet(ctx context.Context, pool *pgxpool.Pool, userID, walletID uuid.UUID) (LinkedWallet, error) {
+	if pool == nil {
+		return LinkedWallet{}, fmt.Errorf("db not configured")
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return LinkedWallet{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `UPDATE wallets SET is_primary = false WHERE user_id = $1 AND is_primary AND id <> $2`, userID, walletID); err != nil {
+		return LinkedWallet{}, err
+	}
+	w, err := scanLinkedWallet(tx.QueryRow(ctx, `
+UPDATE wallets SET is_primary = true
+WHERE id = $1 AND user_id = $2
+RETURNING `+linkedWalletColumns, walletID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LinkedWallet{}, ErrWalletNotFound
+	}
+	if err != nil {
+		return LinkedWallet{}, err
+	}
+	return w, tx.Commit(ctx)
+}
+
+// UnlinkWallet removes a wallet from the user's account and signs out the
+// sessions started with it. The last wallet can't be removed; removing the
+// primary promotes the oldest remaining one.
+func UnlinkWallet(ctx context.Context, pool *pgxpool.Pool, userID, walletID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock the user's wallets so concurrent unlinks can't remove them all.
+	rows, err := tx.Query(ctx, `SELECT id, is_primary FROM wallets WHERE user_id = $1 ORDER BY created_at FOR UPDATE`, userID)
+	if err != nil {
+		return err
+	}
+	var (
+		found, wasPrimary bool
+		others            []uuid.UUID
+	)
+	for rows.Next() {
+		var id uuid.UUID
+		var primary bool
+		if err := rows.Scan(&id, &primary); err != nil {
+			rows.Close()
+			return err
+		}
+		if id == walletID {
+			found, wasPrimary = true, primary
+		} else {
+			others = append(others, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if !found {
+		return ErrWalletNotFound
+	}
+	if len(others) == 0 {
+		return ErrLastWallet
+	}
+
+	var walletType, address string
+	if err := tx.QueryRow(ctx, `DELETE FROM wallets WHERE id = $1 RETURNING wallet_type, address`, walletID).Scan(&walletType, &address); err != nil {
+		return err
+	}
+	if err := RevokeWalletSessions(ctx, tx, userID, WalletType(walletType), address); err != nil {
+		return err
+	}
+	if wasPrimary {
+		if _, err := tx.Exec(ctx, `UPDATE wallets SET is_primary = true WHERE id = $1`, others[0]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// PrimaryWallet returns the user's primary wallet among types, falling back
+// to the oldest wallet of those types when the primary is of another type.
+func PrimaryWallet(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, types ...WalletType) (LinkedWallet, error) {
+	if pool == nil {
+		return LinkedWallet{}, fmt.Errorf("db not configured")
+	}
+	ts := make([]string, len(types))
+	for i, t := range types {
+		ts[i] = string(t)
+	}
+	w, err := scanLinkedWallet(pool.QueryRow(ctx, `
+SELECT `+linkedWalletColumns+`
+FROM wallets
+WHERE user_id = $1 AND wallet_type = ANY($2)
+ORDER BY is_primary DESC, created_at
+LIMIT 1
+`, userID, ts))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LinkedWallet{}, ErrWalletNotFound
+	}
+	return w, err
+}
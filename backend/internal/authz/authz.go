@@ -0,0 +1,309 @@
+// Package authz is the API's route authorization matrix: a declarative
+// table of the permission each route requires, checked against the roles
+// and API key scopes that exist and against the auth middleware actually in
+// front of each route when the app starts. A route missing from the table,
+// or whose middleware doesn't enforce what the table says, keeps the API
+// from starting. Role requirements are enforced by the matrix itself (see
+// Matrix.Require) rather than by checks on each route.
+package authz
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/apikeys"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/openapi"
+)
+
+// Permission is what a caller needs to reach a route.
+type Permission string
+
+const (
+	// Public routes are open to anyone.
+	Public Permission = "public"
+	// Verified routes check a credential of their own, such as a webhook
+	// signature, an OAuth state or an ops token.
+	Verified Permission = "verified"
+	// User routes need a signed-in user.
+	User Permission = "user"
+)
+
+// Role is a signed-in user holding role.
+func Role(role string) Permission { return Permission("role:" + role) }
+
+// Scope is a signed-in user, or an API key granted scope.
+func Scope(scope string) Permission { return Permission("scope:" + scope) }
+
+// Key is an API key granted scope; any key if scope is empty.
+func Key(scope string) Permission {
+	if scope == "" {
+		return "key"
+	}
+	return Permission("key:" + scope)
+}
+
+// kind splits p into its kind and argument.
+func (p Permission) kind() (string, string) {
+	k, arg, _ := strings.Cut(string(p), ":")
+	return k, arg
+}
+
+// Validate reports whether p names an existing role or scope.
+func (p Permission) Validate() error {
+	switch k, arg := p.kind(); k {
+	case "public", "verified", "user":
+		if arg == "" {
+			return nil
+		}
+	case "role":
+		if slices.Contains(auth.Roles, arg) {
+			return nil
+		}
+		return fmt.Errorf("unknown role %q", arg)
+	case "scope", "key":
+		if slices.Contains(apikeys.AllScopes, arg) || (k == "key" && arg == "") {
+			return nil
+		}
+		return fmt.Errorf("unknown scope %q", arg)
+	}
+	return fmt.Errorf("unknown permission %q", p)
+}
+
+// Middleware names, as openapi.HandlerName reports them, that enforce each
+// kind of permission. Public and verified routes have none.
+var enforcers = map[string][]string{
+	"user":  {"auth.RequireAuth"},
+	"role":  {"auth.RequireAuth", "authz.Matrix.Require"},
+	"scope": {"auth.RequireAuthOrAPIKey"},
+	"key":   {"auth.RequireAPIKey"},
+}
+
+func isEnforcer(name string) bool {
+	for _, names := range enforcers {
+		if slices.Contains(names, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// rule is one compiled entry of the table.
+type rule struct {
+	key        string
+	method     string
+	segs       []string
+	prefix     bool
+	permission Permission
+}
+
+// parseRule parses a table entry. Entries are keyed like openapi operations,
+// "METHOD /path", where the method may be "*" for any method and a path
+// ending in "/*" covers everything under it. Path parameters (":id") match
+// any one segment.
+func parseRule(key string, p Permission) (rule, error) {
+	method, path, ok := strings.Cut(key, " ")
+	if !ok || !strings.HasPrefix(path, "/") {
+		return rule{}, fmt.Errorf("%s: want \"METHOD /path\"", key)
+	}
+	if err := p.Validate(); err != nil {
+		return rule{}, fmt.Errorf("%s: %w", key, err)
+	}
+	r := rule{key: key, method: method, permission: p}
+	if path == "/*" || strings.HasSuffix(path, "/*") {
+		r.prefix = true
+		path = strings.TrimSuffix(path, "*")
+	}
+	r.segs = splitPath(path)
+	return r, nil
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// match scores how specifically r covers method and path segs; zero means
+// not at all. Exact rules beat prefix rules, literal segments beat
+// parameters and a named method beats "*".
+func (r rule) match(method string, segs []string) int {
+	if r.method != "*" && r.method != method {
+		return 0
+	}
+	if len(segs) < len(r.segs) || (!r.prefix && len(segs) != len(r.segs)) {
+		return 0
+	}
+	score := 1
+	for i, s := range r.segs {
+		switch {
+		case strings.HasPrefix(s, ":"):
+		case strings.EqualFold(s, segs[i]):
+			score += 2
+		default:
+			return 0
+		}
+	}
+	if r.method != "*" {
+		score++
+	}
+	if !r.prefix {
+		score += 1000
+	}
+	return score
+}
+
+// Entry is a route as compiled into the matrix.
+type Entry struct {
+	Method     string     `json:"method"`
+	Path       string     `json:"path"`
+	Permission Permission `json:"permission"`
+	// Rule is the table entry the permission comes from.
+	Rule string `json:"rule"`
+	// Middleware lists the auth middleware in front of the handler.
+	Middleware []string `json:"middleware"`
+	Handler    string   `json:"handler"`
+}
+
+// Matrix is a compiled route authorization table.
+type Matrix struct {
+	rules   []rule
+	entries []Entry
+}
+
+// New checks table against the permission registry.
+func New(table map[string]Permission) (*Matrix, error) {
+	m := &Matrix{}
+	for key, p := range table {
+		r, err := parseRule(key, p)
+		if err != nil {
+			return nil, err
+		}
+		m.rules = append(m.rules, r)
+	}
+	sort.Slice(m.rules, func(i, j int) bool { return m.rules[i].key < m.rules[j].key })
+	return m, nil
+}
+
+func (m *Matrix) lookup(method, path string) (rule, bool) {
+	segs := splitPath(path)
+	var best rule
+	bestScore := 0
+	for _, r := range m.rules {
+		if s := r.match(method, segs); s > bestScore {
+			best, bestScore = r, s
+		}
+	}
+	return best, bestScore > 0
+}
+
+// Compile binds the table to routes, as returned by openapi.Routes. Every
+// route must be covered by a rule whose permission its middleware enforces.
+// HEAD and OPTIONS routes are skipped.
+func (m *Matrix) Compile(routes []fiber.Route) error {
+	var errs []string
+	entries := []Entry{}
+	for _, r := range routes {
+		if r.Method == fiber.MethodHead || r.Method == fiber.MethodOptions || len(r.Handlers) == 0 {
+			continue
+		}
+		route := openapi.Key(r.Method, r.Path)
+		ru, ok := m.lookup(r.Method, r.Path)
+		if !ok {
+			errs = append(errs, route+": no rule")
+			continue
+		}
+
+		var middleware []string
+		for _, h := range r.Handlers[:len(r.Handlers)-1] {
+			name := openapi.HandlerName(h)
+			if isEnforcer(name) && !slices.Contains(middleware, name) {
+				middleware = append(middleware, name)
+			}
+		}
+		kind, _ := ru.permission.kind()
+		want := enforcers[kind]
+		if kind != "role" {
+			// Require only acts on role rules.
+			middleware = slices.DeleteFunc(middleware, func(n string) bool { return n == "authz.Matrix.Require" })
+		}
+		if !sameNames(middleware, want) {
+			errs = append(errs, fmt.Sprintf("%s: %s needs %v, has %v", route, ru.permission, want, middleware))
+		}
+		entries = append(entries, Entry{
+			Method:     r.Method,
+			Path:       r.Path,
+			Permission: ru.permission,
+			Rule:       ru.key,
+			Middleware: middleware,
+			Handler:    openapi.HandlerName(r.Handlers[len(r.Handlers)-1]),
+		})
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("route authorization: %s", strings.Join(errs, "; "))
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Method < entries[j].Method
+	})
+	m.entries = entries
+	return nil
+}
+
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, n := range a {
+		if !slices.Contains(b, n) {
+			return false
+		}
+	}
+	return true
+}
+
+// Entries returns the compiled routes, by path.
+func (m *Matrix) Entries() []Entry { return m.entries }
+
+// Require enforces the role rules of the routes it is mounted in front of,
+// after the caller is authenticated. Other permissions are left to the
+// route's own middleware, which Compile checks.
+func (m *Matrix) Require() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		method := c.Method()
+		if method == fiber.MethodHead {
+			method = fiber.MethodGet
+		}
+		r, ok := m.lookup(method, c.Path())
+		if !ok {
+			return c.Next()
+		}
+		if kind, role := r.permission.kind(); kind == "role" {
+			have, _ := c.Locals(auth.LocalRole).(string)
+			if have == "" {
+				return httpx.Fail(c, fiber.StatusForbidden, "missing_role")
+			}
+			if have != role {
+				return httpx.Fail(c, fiber.StatusForbidden, "insufficient_role")
+			}
+		}
+		return c.Next()
+	}
+}
+
+// Handler lists the compiled matrix for review.
+func (m *Matrix) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"routes": m.entries})
+	}
+}
@@ -0,0 +1,113 @@
+package authz
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/apikeys"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/openapi"
+)
+
+func TestNewValidatesPermissions(t *testing.T) {
+	for _, p := range []Permission{Role("owner"), Scope("bounties:delete"), "anyone", "user:x"} {
+		if _, err := New(map[string]Permission{"GET /x": p}); err == nil {
+			t.Errorf("New accepted %q", p)
+		}
+	}
+	if _, err := New(map[string]Permission{"/x": User}); err == nil {
+		t.Error("New accepted a key without a method")
+	}
+	if _, err := New(map[string]Permission{"GET /x": Key(""), "GET /y": Scope(apikeys.ScopeBountiesRead), "* /z/*": Role("admin")}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	m, err := New(map[string]Permission{
+		"* /admin/*":            Role("admin"),
+		"POST /admin/bootstrap": User,
+		"GET /users/:id":        Public,
+		"GET /users/me":         User,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		method, path string
+		want         Permission
+	}{
+		{"GET", "/admin/users/1", Role("admin")},
+		{"POST", "/admin/bootstrap", User},
+		{"GET", "/admin/bootstrap", Role("admin")},
+		{"GET", "/Admin/Bootstrap/", Role("admin")},
+		{"GET", "/users/42", Public},
+		{"GET", "/users/me", User},
+		{"GET", "/users/:id", Public},
+	} {
+		r, ok := m.lookup(tc.method, tc.path)
+		if !ok || r.permission != tc.want {
+			t.Errorf("lookup(%s %s) = %q, %v; want %q", tc.method, tc.path, r.permission, ok, tc.want)
+		}
+	}
+	if _, ok := m.lookup("GET", "/users"); ok {
+		t.Error("lookup matched a path no rule covers")
+	}
+}
+
+func TestCompileAndRequire(t *testing.T) {
+	m, err := New(map[string]Permission{
+		"* /admin/*":  Role("admin"),
+		"GET /open":   Public,
+		"GET /mine":   User,
+		"GET /tokens": Verified,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noop := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+	asRole := func(c *fiber.Ctx) error {
+		c.Locals(auth.LocalRole, c.Get("X-Role"))
+		return c.Next()
+	}
+
+	app := fiber.New()
+	app.Get("/open", noop)
+	app.Get("/mine", auth.RequireAuth("secret", nil), noop)
+	admin := app.Group("/admin", auth.RequireAuth("secret", nil), m.Require())
+	admin.Get("/users", noop)
+	// GET /tokens has a rule but no route, which is fine.
+	if err := m.Compile(openapi.Routes(app)); err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if len(m.Entries()) != 3 {
+		t.Fatalf("entries = %+v", m.Entries())
+	}
+
+	// A route without a rule, and one whose middleware doesn't match.
+	app.Get("/new", noop)
+	app.Get("/tokens", auth.RequireAuth("secret", nil), noop)
+	err = m.Compile(openapi.Routes(app))
+	if err == nil || !strings.Contains(err.Error(), "GET /new: no rule") || !strings.Contains(err.Error(), "GET /tokens: verified needs") {
+		t.Fatalf("Compile err = %v", err)
+	}
+
+	// Role enforcement, with authentication stubbed out.
+	app = fiber.New()
+	admin = app.Group("/admin", asRole, m.Require())
+	admin.Get("/users", noop)
+	for role, want := range map[string]int{"admin": fiber.StatusNoContent, "maintainer": fiber.StatusForbidden, "": fiber.StatusForbidden} {
+		req := httptest.NewRequest("GET", "/admin/users", nil)
+		req.Header.Set("X-Role", role)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("role %q: status %d, want %d", role, resp.StatusCode, want)
+		}
+	}
+}
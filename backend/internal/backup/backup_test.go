@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+func testKeys(t *testing.T) Keys {
+	t.Helper()
+	k, err := KeysFromB64(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// buildBlob mirrors Snapshot without a database.
+func buildBlob(t *testing.T, keys Keys, rows []string, mutate func([]Record)) []byte {
+	t.Helper()
+	return buildChainedBlob(t, keys, "", rows, mutate)
+}
+
+func buildChainedBlob(t *testing.T, keys Keys, prevHead string, rows []string, mutate func([]Record)) []byte {
+	t.Helper()
+	var recs []Record
+	head := prevHead
+	for i, r := range rows {
+		rec := Record{Table: "ledger_entries", Seq: int64(i + 1), Row: json.RawMessage(r), PrevHash: head}
+		rec.Hash = chainHash(head, rec.Table, rec.Row)
+		head = rec.Hash
+		recs = append(recs, rec)
+	}
+	m := Manifest{
+		Version:   formatVersion,
+		CreatedAt: time.Unix(0, 0).UTC(),
+		Tables:    map[string]TableSummary{"ledger_entries": {Rows: int64(len(rows)), Head: head}},
+		PrevHead:  prevHead,
+		ChainHead: head,
+	}
+	m.Signature = keys.signManifest(m)
+	if mutate != nil {
+		mutate(recs)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for _, r := range recs {
+		_ = enc.Encode(r)
+	}
+	_ = enc.Encode(m)
+	_ = gz.Close()
+	blob, err := cryptox.EncryptAESGCM(keys.enc, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blob
+}
+
+func TestVerify(t *testing.T) {
+	keys := testKeys(t)
+	rows := []string{`{"id":1,"amount":10}`, `{"id":2,"amount":20}`}
+
+	if _, err := Verify(keys, buildBlob(t, keys, rows, nil)); err != nil {
+		t.Fatalf("valid snapshot: %v", err)
+	}
+
+	tampered := buildBlob(t, keys, rows, func(r []Record) { r[0].Row = json.RawMessage(`{"id":1,"amount":99}`) })
+	if _, err := Verify(keys, tampered); !errors.Is(err, ErrTampered) {
+		t.Fatalf("tampered row: got %v", err)
+	}
+
+	dropped := buildBlob(t, keys, rows, func(r []Record) { r[1] = r[0] })
+	if _, err := Verify(keys, dropped); !errors.Is(err, ErrTampered) {
+		t.Fatalf("replayed row: got %v", err)
+	}
+
+	other, _ := KeysFromB64(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{8}, 32)))
+	if _, err := Verify(other, buildBlob(t, keys, rows, nil)); !errors.Is(err, ErrTampered) {
+		t.Fatalf("wrong key: got %v", err)
+	}
+}
+
+func TestVerifyStoreChainsSnapshots(t *testing.T) {
+	ctx := context.Background()
+	keys := testKeys(t)
+	store, err := NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := buildChainedBlob(t, keys, "", []string{`{"id":1}`}, nil)
+	m1, err := Verify(keys, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := buildChainedBlob(t, keys, m1.ChainHead, []string{`{"id":1}`, `{"id":2}`}, nil)
+	_ = store.Put(ctx, "ledger-1"+fileSuffix, first)
+	_ = store.Put(ctx, "ledger-2"+fileSuffix, second)
+
+	check := func() map[string]error {
+		got := map[string]error{}
+		if _, err := VerifyStore(ctx, keys, store, func(name string, _ Manifest, err error) { got[name] = err }); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	for name, err := range check() {
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+
+	// A snapshot replaced by one chained elsewhere no longer follows.
+	_ = store.Put(ctx, "ledger-2"+fileSuffix, buildChainedBlob(t, keys, "", []string{`{"id":1}`, `{"id":2}`}, nil))
+	if err := check()["ledger-2"+fileSuffix]; !errors.Is(err, ErrTampered) {
+		t.Fatalf("replaced snapshot: got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+)
+
+const fileSuffix = ".gbak"
+
+// Store persists encrypted snapshots: DirStore on local disk, S3Store in an
+// S3-compatible bucket off-host.
+type Store interface {
+	Put(ctx context.Context, name string, blob []byte) error
+	Get(ctx context.Context, name string) ([]byte, error)
+	List(ctx context.Context) ([]string, error)
+}
+
+type DirStore struct {
+	Dir string
+}
+
+func NewDirStore(dir string) (*DirStore, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("BACKUP_DIR is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &DirStore{Dir: dir}, nil
+}
+
+// Put writes to a temp file and renames so readers never see a partial snapshot.
+func (s *DirStore) Put(_ context.Context, name string, blob []byte) error {
+	path := filepath.Join(s.Dir, filepath.Base(name))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, blob, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *DirStore) Get(_ context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.Dir, filepath.Base(name)))
+}
+
+// List returns snapshot names, oldest first.
+func (s *DirStore) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), fileSuffix) {
+			out = append(out, e.Name())
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// S3Config locates an S3-compatible bucket.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	// Insecure talks plain HTTP, for local test servers.
+	Insecure bool
+}
+
+// S3Store keeps snapshots as objects under Prefix in an S3-compatible bucket.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if strings.TrimSpace(cfg.Bucket) == "" {
+		return nil, fmt.Errorf("BACKUP_S3_BUCKET is required")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: !cfg.Insecure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup bucket: %w", err)
+	}
+	return &S3Store{client: client, bucket: cfg.Bucket, prefix: strings.Trim(cfg.Prefix, "/")}, nil
+}
+
+func (s *S3Store) key(name string) string {
+	return path.Join(s.prefix, path.Base(name))
+}
+
+// Put uploads in one request, so readers never see a partial snapshot.
+func (s *S3Store) Put(ctx context.Context, name string, blob []byte) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.key(name), bytes.NewReader(blob), int64(len(blob)), minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	return err
+}
+
+func (s *S3Store) Get(ctx context.Context, name string) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.key(name), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return io.ReadAll(obj)
+}
+
+// List returns snapshot names, oldest first.
+func (s *S3Store) List(ctx context.Context) ([]string, error) {
+	prefix := s.prefix
+	if prefix != "" {
+		prefix += "/"
+	}
+	var out []string
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		if name := path.Base(obj.Key); strings.HasSuffix(name, fileSuffix) {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// NewStoreFromConfig returns an S3Store when BACKUP_S3_BUCKET is set and a
+// DirStore on BACKUP_DIR otherwise.
+func NewStoreFromConfig(cfg config.Config) (Store, error) {
+	if strings.TrimSpace(cfg.BackupS3Bucket) != "" {
+		return NewS3Store(S3Config{
+			Endpoint:  cfg.BackupS3Endpoint,
+			Region:    cfg.BackupS3Region,
+			Bucket:    cfg.BackupS3Bucket,
+			Prefix:    cfg.BackupS3Prefix,
+			AccessKey: cfg.BackupS3AccessKey,
+			SecretKey: cfg.BackupS3SecretKey,
+			Insecure:  cfg.BackupS3Insecure,
+		})
+	}
+	return NewDirStore(cfg.BackupDir)
+}
+
+// SnapshotName is sortable by time.
+func SnapshotName(t time.Time) string {
+	return "ledger-" + t.UTC().Format("20060102T150405Z") + fileSuffix
+}
+
+// SnapshotTo takes a snapshot chained to the latest one in store and stores
+// it, returning the object name. It refuses to chain to a latest snapshot
+// that fails verification.
+func SnapshotTo(ctx context.Context, pool *pgxpool.Pool, keys Keys, store Store, tables []string) (string, Manifest, error) {
+	names, err := store.List(ctx)
+	if err != nil {
+		return "", Manifest{}, fmt.Errorf("list snapshots: %w", err)
+	}
+	prevHead := ""
+	if len(names) > 0 {
+		last := names[len(names)-1]
+		blob, err := store.Get(ctx, last)
+		if err != nil {
+			return "", Manifest{}, fmt.Errorf("read snapshot %s: %w", last, err)
+		}
+		prev, err := Verify(keys, blob)
+		if err != nil {
+			return "", Manifest{}, fmt.Errorf("verify snapshot %s: %w", last, err)
+		}
+		prevHead = prev.ChainHead
+	}
+
+	blob, m, err := Snapshot(ctx, pool, keys, tables, prevHead)
+	if err != nil {
+		return "", Manifest{}, err
+	}
+	name := SnapshotName(m.CreatedAt)
+	if err := store.Put(ctx, name, blob); err != nil {
+		return "", Manifest{}, fmt.Errorf("store snapshot: %w", err)
+	}
+	return name, m, nil
+}
+
+// VerifyStore verifies every snapshot in store, oldest first, and that each
+// one's chain starts from the previous one's head. It calls report for each
+// snapshot and returns whether all passed.
+func VerifyStore(ctx context.Context, keys Keys, store Store, report func(name string, m Manifest, err error)) (bool, error) {
+	names, err := store.List(ctx)
+	if err != nil {
+		return false, err
+	}
+	ok := true
+	prevHead := ""
+	for i, name := range names {
+		blob, err := store.Get(ctx, name)
+		if err != nil {
+			report(name, Manifest{}, err)
+			ok = false
+			continue
+		}
+		m, err := Verify(keys, blob)
+		if err == nil && i > 0 && m.PrevHead != prevHead {
+			err = fmt.Errorf("%w: does not follow the previous snapshot", ErrTampered)
+		}
+		if err != nil {
+			ok = false
+		}
+		// Carry on from this snapshot's own head, so a bad link is reported
+		// once.
+		prevHead = m.ChainHead
+		report(name, m, err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,215 @@
+// Package badges awards achievement badges (contributor milestones, campaign
+// wins) and optionally mints them as non-transferable NFTs.
+package badges
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	KindMilestone = "milestone"
+	KindCampaign  = "campaign"
+)
+
+const (
+	NFTNone    = "none"
+	NFTPending = "pending"
+	NFTMinted  = "minted"
+	NFTFailed  = "failed"
+)
+
+var (
+	ErrBadgeNotFound  = errors.New("badge_not_found")
+	ErrAlreadyAwarded = errors.New("already_awarded")
+)
+
+type Badge struct {
+	ID          uuid.UUID `json:"id"`
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Kind        string    `json:"kind"`
+	ImageURL    *string   `json:"image_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NFT is the on-chain token for an awarded badge, once minted.
+type NFT struct {
+	Status    string  `json:"status"`
+	Chain     *string `json:"chain,omitempty"`
+	Contract  *string `json:"contract,omitempty"`
+	TokenID   *string `json:"token_id,omitempty"`
+	Recipient *string `json:"recipient,omitempty"`
+	TxHash    *string `json:"tx_hash,omitempty"`
+}
+
+type Award struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Badge     Badge     `json:"badge"`
+	Reason    *string   `json:"reason,omitempty"`
+	AwardedAt time.Time `json:"awarded_at"`
+	NFT       NFT       `json:"nft"`
+}
+
+func List(ctx context.Context, pool *pgxpool.Pool) ([]Badge, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, slug, name, description, kind, image_url, created_at
+FROM badges
+ORDER BY kind, name
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Badge{}
+	for rows.Next() {
+		var b Badge
+		if err := rows.Scan(&b.ID, &b.Slug, &b.Name, &b.Description, &b.Kind, &b.ImageURL, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+func Create(ctx context.Context, pool *pgxpool.Pool, b Badge) (Badge, error) {
+	if pool == nil {
+		return Badge{}, fmt.Errorf("db not configured")
+	}
+	img := ""
+	if b.ImageURL != nil {
+		img = *b.ImageURL
+	}
+	err := pool.QueryRow(ctx, `
+INSERT INTO badges (slug, name, description, kind, image_url)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+RETURNING id, image_url, created_at
+`, strings.ToLower(strings.TrimSpace(b.Slug)), b.Name, b.Description, b.Kind, img).Scan(&b.ID, &b.ImageURL, &b.CreatedAt)
+	return b, err
+}
+
+// Grant awards the badge to a user. With mint set the award is queued for the
+// NFT minter; otherwise it stays off-chain.
+func Grant(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, slug, reason string, awardedBy *uuid.UUID, mint bool) (Award, error) {
+	if pool == nil {
+		return Award{}, fmt.Errorf("db not configured")
+	}
+	status := NFTNone
+	if mint {
+		status = NFTPending
+	}
+	var id uuid.UUID
+	err := pool.QueryRow(ctx, `
+INSERT INTO user_badges (user_id, badge_id, reason, awarded_by, nft_status)
+SELECT $1, b.id, NULLIF($3, ''), $4, $5
+FROM badges b
+WHERE b.slug = $2
+ON CONFLICT (user_id, badge_id) DO NOTHING
+RETURNING id
+`, userID, strings.ToLower(strings.TrimSpace(slug)), reason, awardedBy, status).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM badges WHERE slug = $1)`, strings.ToLower(strings.TrimSpace(slug))).Scan(&exists); err != nil {
+			return Award{}, err
+		}
+		if !exists {
+			return Award{}, ErrBadgeNotFound
+		}
+		return Award{}, ErrAlreadyAwarded
+	}
+	if err != nil {
+		return Award{}, err
+	}
+	return scanAward(pool.QueryRow(ctx, awardSelect+`WHERE ub.id = $1`, id))
+}
+
+const awardSelect = `
+SELECT ub.id, ub.user_id, b.id, b.slug, b.name, b.description, b.kind, b.image_url, b.created_at,
+       ub.reason, ub.awarded_at,
+       ub.nft_status, ub.nft_chain, ub.nft_contract, ub.nft_token_id, ub.nft_recipient, ub.nft_tx_hash
+FROM user_badges ub
+JOIN badges b ON b.id = ub.badge_id
+`
+
+func scanAward(row pgx.Row) (Award, error) {
+	var a Award
+	err := row.Scan(&a.ID, &a.UserID, &a.Badge.ID, &a.Badge.Slug, &a.Badge.Name, &a.Badge.Description, &a.Badge.Kind, &a.Badge.ImageURL, &a.Badge.CreatedAt,
+		&a.Reason, &a.AwardedAt,
+		&a.NFT.Status, &a.NFT.Chain, &a.NFT.Contract, &a.NFT.TokenID, &a.NFT.Recipient, &a.NFT.TxHash)
+	return a, err
+}
+
+// ForUser lists a user's badges, newest first, for the public profile.
+func ForUser(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Award, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, awardSelect+`WHERE ub.user_id = $1 ORDER BY ub.awarded_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Award{}
+	for rows.Next() {
+		a, err := scanAward(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// TokenMetadata is the ERC-721 metadata JSON served at the token URI.
+type TokenMetadata struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Image       string           `json:"image,omitempty"`
+	Attributes  []map[string]any `json:"attributes"`
+}
+
+// MetadataForToken resolves a minted token id to its metadata.
+func MetadataForToken(ctx context.Context, pool *pgxpool.Pool, tokenID int64) (TokenMetadata, error) {
+	if pool == nil {
+		return TokenMetadata{}, fmt.Errorf("db not configured")
+	}
+	var (
+		m       TokenMetadata
+		kind    string
+		image   *string
+		awarded time.Time
+	)
+	err := pool.QueryRow(ctx, `
+SELECT b.name, b.description, b.kind, b.image_url, ub.awarded_at
+FROM user_badges ub
+JOIN badges b ON b.id = ub.badge_id
+WHERE ub.token_seq = $1
+`, tokenID).Scan(&m.Name, &m.Description, &kind, &image, &awarded)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TokenMetadata{}, ErrBadgeNotFound
+	}
+	if err != nil {
+		return TokenMetadata{}, err
+	}
+	if image != nil {
+		m.Image = *image
+	}
+	m.Attributes = []map[string]any{
+		{"trait_type": "kind", "value": kind},
+		{"trait_type": "awarded_at", "display_type": "date", "value": awarded.Unix()},
+		{"trait_type": "transferable", "value": "no"},
+	}
+	return m, nil
+}
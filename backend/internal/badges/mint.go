@@ -0,0 +1,111 @@
+package badges
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// soulboundABI is the minter entry point expected on the configured contract:
+// an ERC-721 that rejects transfers (ERC-5192 "locked") and lets the platform
+// key mint with an explicit token id and URI.
+var soulboundABI = func() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"safeMint","inputs":[
+		{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"},{"name":"uri","type":"string"}]}]`))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+// RawSender submits a contract call from the platform key (wallet.EVMSender).
+type RawSender interface {
+	Chain() string
+	SendRaw(ctx context.Context, to common.Address, value *big.Int, data []byte) (string, error)
+}
+
+// Minter mints pending badge awards to the recipient's linked EVM wallet.
+// Awards for users without one stay pending until they link a wallet.
+type Minter struct {
+	Pool     *pgxpool.Pool
+	Sender   RawSender
+	Contract common.Address
+	// BaseURI + token id is the token URI, e.g. https://api.example.com/badges/nft/
+	BaseURI string
+}
+
+func (m *Minter) RunOnce(ctx context.Context) (int, error) {
+	if m.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := m.Pool.Query(ctx, `
+SELECT ub.id, ub.token_seq,
+       (SELECT w.address FROM wallets w WHERE w.user_id = ub.user_id AND w.wallet_type = 'evm' ORDER BY w.is_primary DESC, w.created_at LIMIT 1)
+FROM user_badges ub
+WHERE ub.nft_status = 'pending'
+ORDER BY ub.token_seq
+LIMIT 50
+`)
+	if err != nil {
+		return 0, err
+	}
+	type job struct {
+		id      uuid.UUID
+		seq     int64
+		address *string
+	}
+	var jobs []job
+	for rows.Next() {
+		var j job
+		if err := rows.Scan(&j.id, &j.seq, &j.address); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		jobs = append(jobs, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	minted := 0
+	for _, j := range jobs {
+		if j.address == nil || !common.IsHexAddress(*j.address) {
+			continue
+		}
+		if err := m.mintOne(ctx, j.id, j.seq, common.HexToAddress(*j.address)); err != nil {
+			slog.Error("badge nft mint failed", "user_badge_id", j.id.String(), "error", err)
+			continue
+		}
+		minted++
+	}
+	return minted, nil
+}
+
+func (m *Minter) mintOne(ctx context.Context, id uuid.UUID, seq int64, to common.Address) error {
+	tokenID := strconv.FormatInt(seq, 10)
+	data, err := soulboundABI.Pack("safeMint", to, big.NewInt(seq), m.BaseURI+tokenID)
+	if err != nil {
+		return err
+	}
+	txHash, sendErr := m.Sender.SendRaw(ctx, m.Contract, big.NewInt(0), data)
+	if sendErr != nil {
+		_, _ = m.Pool.Exec(ctx, `UPDATE user_badges SET nft_status = 'failed', nft_error = $2 WHERE id = $1`, id, sendErr.Error())
+		return sendErr
+	}
+	_, err = m.Pool.Exec(ctx, `
+UPDATE user_badges
+SET nft_status = 'minted', nft_chain = $2, nft_contract = $3, nft_token_id = $4,
+    nft_recipient = $5, nft_tx_hash = $6, nft_error = NULL
+WHERE id = $1
+`, id, m.Sender.Chain(), strings.ToLower(m.Contract.Hex()), tokenID, strings.ToLower(to.Hex()), txHash)
+	return err
+}
@@ -0,0 +1,405 @@
+// Package bounties attaches rewards to issues from any supported issue
+// provider (see package issues).
+package bounties
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/issues"
+	"github.com/jagadeesh/grainlify/backend/internal/metadata"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// Statuses, in lifecycle order (see lifecycle.go). Completed bounties have
+// been paid.
+const (
+	StatusOpen      = "open"
+	StatusClaimed   = "claimed"
+	StatusSubmitted = "submitted"
+	StatusApproved  = "approved"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+)
+
+// Statuses lists every status in lifecycle order.
+var Statuses = []string{StatusOpen, StatusClaimed, StatusSubmitted, StatusApproved, StatusCompleted, StatusCancelled}
+
+// Kinds. Security-advisory bounties pay for vulnerability fixes under
+// embargo: only invited users may claim them, and they stay private until
+// their advisory is disclosed.
+const (
+	KindStandard         = "standard"
+	KindSecurityAdvisory = "security_advisory"
+)
+
+// How a bounty is funded: from the platform hot wallet, or locked by the
+// maintainer in an on-chain escrow contract.
+const (
+	FundingHotWallet = "hot_wallet"
+	FundingEscrow    = "escrow"
+)
+
+// Escrow statuses.
+const (
+	EscrowAwaitingLock = "awaiting_lock"
+	EscrowLocked       = "locked"
+	EscrowReleasing    = "releasing"
+	EscrowReleased     = "released"
+)
+
+var (
+	ErrNotFound           = errors.New("bounty_not_found")
+	ErrAlreadyOpen        = errors.New("bounty_already_open")
+	ErrInvalidStatus      = errors.New("invalid_bounty_status")
+	ErrInvalidEscrowState = errors.New("invalid_escrow_status")
+)
+
+type Bounty struct {
+	ID        uuid.UUID    `json:"id"`
+	ProjectID uuid.UUID    `json:"project_id"`
+	CreatedBy uuid.UUID    `json:"created_by"`
+	Issue     issues.Issue `json:"issue"`
+	// PathPrefix, when set, scopes the bounty to a directory of the
+	// project's repo (see paths.go).
+	PathPrefix string `json:"path_prefix,omitempty"`
+	Chain      string `json:"chain"`
+	Asset      string `json:"asset"`
+	Amount     string `json:"amount"`
+	Status     string `json:"status"`
+	// SkillTags are detected from the project's repo unless
+	// SkillTagsOverridden, when a maintainer set them.
+	SkillTags           []string `json:"skill_tags"`
+	SkillTagsOverridden bool     `json:"skill_tags_overridden"`
+	Funding             string   `json:"funding"`
+	// Escrow is set for escrow-funded bounties.
+	Escrow *Escrow `json:"escrow,omitempty"`
+	// Metadata holds the custom fields the project defines for bounties
+	// (see package metadata).
+	Metadata map[string]any `json:"metadata"`
+	// Deadline, when set, closes the bounty to claims and submissions.
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// Visibility is public, private or unlisted (see visibility.go).
+	Visibility string `json:"visibility"`
+	// linkVersion is signed into unlisted links; bumping it revokes them.
+	linkVersion int
+	// Kind is standard or security_advisory (see package advisories).
+	Kind string `json:"kind"`
+	// Claim is set once a contributor claims the bounty.
+	Claim *Claimant `json:"claim,omitempty"`
+	// ApprovedBy is the maintainer who accepted the submission.
+	ApprovedBy *uuid.UUID `json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+	PayoutID   *uuid.UUID `json:"payout_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Claimant is the contributor working on a bounty and, once submitted,
+// their pull request.
+type Claimant struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	ClaimedAt   time.Time  `json:"claimed_at"`
+	Repo        *string    `json:"repo_full_name,omitempty"`
+	PRNumber    *int       `json:"pr_number,omitempty"`
+	PRURL       *string    `json:"pr_url,omitempty"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+}
+
+// Escrow is where an escrow-funded bounty's reward is locked. The maintainer
+// locks Amount under Ref in Contract before Deadline, after which the
+// contract lets them refund it.
+type Escrow struct {
+	Contract string    `json:"contract"`
+	Ref      int64     `json:"ref"`
+	Status   string    `json:"status"`
+	Deadline time.Time `json:"deadline"`
+	LockTx   *string   `json:"lock_tx_hash,omitempty"`
+}
+
+const bountyColumns = `id, project_id, created_by, issue_provider, issue_external_id, issue_key,
+COALESCE(issue_title, ''), COALESCE(issue_url, ''), COALESCE(issue_state, ''), issue_closed,
+chain, asset, amount::text, status, skill_tags, skill_tags_overridden,
+funding, escrow_contract, escrow_ref, escrow_status, escrow_deadline, escrow_lock_tx, metadata,
+deadline, claimed_by, claimed_at, pr_repo_full_name, pr_number, pr_url, submitted_at, approved_by, approved_at, payout_id,
+created_at, updated_at, visibility, link_version, kind, COALESCE(path_prefix, '')`
+
+// bountyRow scans bountyColumns.
+type bountyRow struct {
+	Bounty
+	escrowContract, escrowStatus, escrowLockTx *string
+	escrowRef                                  *int64
+	escrowDeadline                             *time.Time
+	claimedBy                                  *uuid.UUID
+	claimedAt                                  *time.Time
+	claim                                      Claimant
+}
+
+func (r *bountyRow) dest() []any {
+	b := &r.Bounty
+	return []any{&b.ID, &b.ProjectID, &b.CreatedBy, &b.Issue.Provider, &b.Issue.ExternalID, &b.Issue.Key,
+		&b.Issue.Title, &b.Issue.URL, &b.Issue.State, &b.Issue.Closed,
+		&b.Chain, &b.Asset, &b.Amount, &b.Status, &b.SkillTags, &b.SkillTagsOverridden,
+		&b.Funding, &r.escrowContract, &r.escrowRef, &r.escrowStatus, &r.escrowDeadline, &r.escrowLockTx, &b.Metadata,
+		&b.Deadline, &r.claimedBy, &r.claimedAt, &r.claim.Repo, &r.claim.PRNumber, &r.claim.PRURL, &r.claim.SubmittedAt, &b.ApprovedBy, &b.ApprovedAt, &b.PayoutID,
+		&b.CreatedAt, &b.UpdatedAt, &b.Visibility, &b.linkVersion, &b.Kind, &b.PathPrefix}
+}
+
+func (r *bountyRow) bounty() Bounty {
+	b := r.Bounty
+	if r.escrowContract != nil && r.escrowRef != nil && r.escrowStatus != nil && r.escrowDeadline != nil {
+		b.Escrow = &Escrow{Contract: *r.escrowContract, Ref: *r.escrowRef, Status: *r.escrowStatus, Deadline: *r.escrowDeadline, LockTx: r.escrowLockTx}
+	}
+	if r.claimedBy != nil && r.claimedAt != nil {
+		claim := r.claim
+		claim.UserID, claim.ClaimedAt = *r.claimedBy, *r.claimedAt
+		b.Claim = &claim
+	}
+	return b
+}
+
+func scanBounty(row pgx.Row) (Bounty, error) {
+	var r bountyRow
+	err := row.Scan(r.dest()...)
+	return r.bounty(), err
+}
+
+// Create opens a bounty on an already resolved issue (issues.Resolve).
+// accountID is the linked tracker account for external providers; deadline
+// is optional, and an empty visibility means public.
+func Create(ctx context.Context, pool *pgxpool.Pool, projectID, createdBy uuid.UUID, iss issues.Issue, accountID *uuid.UUID, chain, asset, amount string, deadline *time.Time, visibility string) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	amt, err := wallet.ParseAmount(amount)
+	if err != nil {
+		return Bounty{}, err
+	}
+	if amt.Sign() <= 0 {
+		return Bounty{}, fmt.Errorf("amount must be positive")
+	}
+	if deadline != nil && !deadline.After(time.Now()) {
+		return Bounty{}, ErrDeadlinePassed
+	}
+	if visibility == "" {
+		visibility = VisibilityPublic
+	}
+	if !ValidVisibility(visibility) {
+		return Bounty{}, ErrInvalidVisibility
+	}
+	b, err := scanBounty(pool.QueryRow(ctx, `
+INSERT INTO bounties (project_id, created_by, issue_provider, issue_provider_account_id, issue_external_id, issue_key,
+                      issue_title, issue_url, issue_state, issue_closed, chain, asset, amount, deadline, visibility)
+VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12, $13::numeric, $14, $15)
+RETURNING `+bountyColumns,
+		projectID, createdBy, iss.Provider, accountID, iss.ExternalID, iss.Key,
+		iss.Title, iss.URL, iss.State, iss.Closed,
+		strings.ToLower(strings.TrimSpace(chain)), strings.TrimSpace(asset), amount, deadline, visibility))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return Bounty{}, ErrAlreadyOpen
+	}
+	return b, err
+}
+
+// ListSpec is what a project's bounty listing sorts and filters on.
+var ListSpec = httpx.ListSpec{
+	Fields: map[string]httpx.Field{
+		"status":     {Column: "status", Filter: true, Values: Statuses},
+		"kind":       {Column: "kind", Filter: true, Values: []string{KindStandard, KindSecurityAdvisory}},
+		"funding":    {Column: "funding", Filter: true, Values: []string{FundingHotWallet, FundingEscrow}},
+		"chain":      {Column: "chain", Filter: true, Fold: true},
+		"asset":      {Column: "lower(asset)", Filter: true, Fold: true},
+		"amount":     {Column: "amount", Type: httpx.FieldNumeric, Sort: true, Filter: true},
+		"deadline":   {Column: "deadline", Type: httpx.FieldTime, Filter: true},
+		"created_at": {Column: "created_at", Type: httpx.FieldTime, Sort: true, Filter: true},
+		"updated_at": {Column: "updated_at", Type: httpx.FieldTime, Sort: true, Filter: true},
+	},
+	DefaultSort:  "-created_at",
+	Key:          "id",
+	KeyType:      httpx.FieldUUID,
+	DefaultLimit: 200,
+	MaxLimit:     200,
+}
+
+// ListForProject lists a project's public bounties, optionally filtered by
+// status and metadata, one page of ListSpec at a time; it also returns the
+// next page's cursor. Private and unlisted ones are left to ListHidden.
+func ListForProject(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, status string, filters metadata.Filters, page httpx.List) ([]Bounty, string, error) {
+	if pool == nil {
+		return nil, "", fmt.Errorf("db not configured")
+	}
+	where := `project_id = $1 AND ($2 = '' OR status = $2) AND ` + Listed("bounties")
+	args := []any{projectID, status}
+	if cond, fargs := filters.SQL("metadata", len(args)+1); cond != "" {
+		where += " AND " + cond
+		args = append(args, fargs...)
+	}
+	if cond, pargs := page.Where(len(args) + 1); cond != "" {
+		where += " AND " + cond
+		args = append(args, pargs...)
+	}
+	args = append(args, page.Limit)
+	rows, err := pool.Query(ctx, `
+SELECT `+bountyColumns+`, `+page.CursorColumn()+`
+FROM bounties
+WHERE `+where+`
+`+page.OrderBy()+`
+LIMIT $`+strconv.Itoa(len(args))+` `+page.OffsetSQL(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	out := []Bounty{}
+	var cursor string
+	for rows.Next() {
+		b, err := scanBounty(httpx.WithCursor(rows, &cursor))
+		if err != nil {
+			return nil, "", err
+		}
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	return out, page.NextCursor(len(out), cursor), nil
+}
+
+// Get returns one of a project's bounties.
+func Get(ctx context.Context, pool *pgxpool.Pool, projectID, id uuid.UUID) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	b, err := scanBounty(pool.QueryRow(ctx, `SELECT `+bountyColumns+` FROM bounties WHERE id = $1 AND project_id = $2`, id, projectID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Bounty{}, ErrNotFound
+	}
+	return b, err
+}
+
+// GetByID returns a bounty by id alone.
+func GetByID(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	b, err := scanBounty(pool.QueryRow(ctx, `SELECT `+bountyColumns+` FROM bounties WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Bounty{}, ErrNotFound
+	}
+	return b, err
+}
+
+// Cancel withdraws a bounty that hasn't been approved on behalf of actor.
+func Cancel(ctx context.Context, pool *pgxpool.Pool, projectID, id, actor uuid.UUID) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	b, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties SET status = 'cancelled', updated_at = now()
+WHERE id = $1 AND project_id = $2 AND status IN ('open', 'claimed', 'submitted')
+RETURNING `+bountyColumns, id, projectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, gerr := Get(ctx, pool, projectID, id); gerr != nil {
+			return Bounty{}, gerr
+		}
+		return Bounty{}, ErrInvalidStatus
+	}
+	return b, err
+}
+
+// SetSkillTags replaces a bounty's skill tags; overridden marks them as set
+// by a maintainer. actor is uuid.Nil when detection sets them.
+func SetSkillTags(ctx context.Context, pool *pgxpool.Pool, projectID, id, actor uuid.UUID, tags []string, overridden bool) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	b, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties SET skill_tags = $3, skill_tags_overridden = $4, updated_at = now()
+WHERE id = $1 AND project_id = $2
+RETURNING `+bountyColumns, id, projectID, tags, overridden)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Bounty{}, ErrNotFound
+	}
+	return b, err
+}
+
+// SetMetadata replaces a bounty's metadata with already validated values.
+func SetMetadata(ctx context.Context, pool *pgxpool.Pool, projectID, id, actor uuid.UUID, values map[string]any) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	b, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties SET metadata = $3, updated_at = now()
+WHERE id = $1 AND project_id = $2
+RETURNING `+bountyColumns, id, projectID, values)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Bounty{}, ErrNotFound
+	}
+	return b, err
+}
+
+// SetPathPrefix scopes a bounty to a directory of the project's repo, or
+// unscopes it with "". prefix must already be normalized (NormalizePath).
+func SetPathPrefix(ctx context.Context, pool *pgxpool.Pool, projectID, id, actor uuid.UUID, prefix string) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	b, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties SET path_prefix = NULLIF($3, ''), updated_at = now()
+WHERE id = $1 AND project_id = $2
+RETURNING `+bountyColumns, id, projectID, prefix)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Bounty{}, ErrNotFound
+	}
+	return b, err
+}
+
+// AttachEscrow switches a new bounty to escrow funding in contract, giving
+// it an escrow ref; the maintainer has until deadline to lock the reward.
+func AttachEscrow(ctx context.Context, pool *pgxpool.Pool, projectID, id, actor uuid.UUID, contract string, deadline time.Time) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	b, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties
+SET funding = 'escrow', escrow_contract = $3, escrow_ref = nextval('bounty_escrow_ref_seq'),
+    escrow_status = 'awaiting_lock', escrow_deadline = $4, updated_at = now()
+WHERE id = $1 AND project_id = $2 AND funding = 'hot_wallet'
+RETURNING `+bountyColumns, id, projectID, contract, deadline)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Bounty{}, ErrNotFound
+	}
+	return b, err
+}
+
+// RecordEscrowLock records the transaction in which the maintainer locked an
+// escrow bounty's reward.
+func RecordEscrowLock(ctx context.Context, pool *pgxpool.Pool, projectID, id, actor uuid.UUID, txHash string) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	b, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties SET escrow_status = 'locked', escrow_lock_tx = $3, updated_at = now()
+WHERE id = $1 AND project_id = $2 AND funding = 'escrow' AND escrow_status = 'awaiting_lock'
+RETURNING `+bountyColumns, id, projectID, txHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, gerr := Get(ctx, pool, projectID, id); gerr != nil {
+			return Bounty{}, gerr
+		}
+		return Bounty{}, ErrInvalidEscrowState
+	}
+	return b, err
+}
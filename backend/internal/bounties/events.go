@@ -0,0 +1,150 @@
+package bounties
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/webhookevents"
+)
+
+// Event types recorded in bounty_events, as sent to webhook endpoints.
+const (
+	EventCreated     = webhookevents.BountyCreated
+	EventClaimed     = webhookevents.BountyClaimed
+	EventSubmitted   = webhookevents.BountySubmitted
+	EventApproved    = webhookevents.BountyApproved
+	EventCompleted   = webhookevents.BountyCompleted
+	EventCancelled   = webhookevents.BountyCancelled
+	EventIssueClosed = webhookevents.BountyIssueClosed
+)
+
+// EventTypes lists every recorded event type.
+var EventTypes = webhookevents.Names()
+
+// MaxEventsPage caps one page of events.
+const MaxEventsPage = 100
+
+// Event is one bounty change, carrying the bounty's current state. ID is
+// stable across polls; Cursor is passed back as `since`.
+type Event struct {
+	ID        uuid.UUID `json:"id"`
+	Cursor    string    `json:"cursor"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Bounty    Bounty    `json:"bounty"`
+}
+
+// EventFilter selects events. Exactly one of ProjectID and OwnerID is set;
+// OwnerID covers every project the user owns. Events on private and
+// unlisted bounties are left out unless Viewer may see them (VisibleTo).
+type EventFilter struct {
+	ProjectID *uuid.UUID
+	OwnerID   *uuid.UUID
+	Viewer    uuid.UUID
+	Types     []string
+	// Since is the last cursor seen; zero returns the latest events.
+	Since int64
+	Limit int
+}
+
+// ParseCursor parses a `since` value. Empty means from the latest events.
+func ParseCursor(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	return n, nil
+}
+
+// ParseEventTypes parses a comma separated type filter.
+func ParseEventTypes(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	known := map[string]struct{}{}
+	for _, t := range EventTypes {
+		known[t] = struct{}{}
+	}
+	var out []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if !strings.HasPrefix(t, "bounty.") {
+			t = "bounty." + t
+		}
+		if _, ok := known[t]; !ok {
+			return nil, fmt.Errorf("unknown event type %q", t)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// ListEvents returns events newest first, as polling triggers expect. With
+// Since set it returns the oldest Limit events after the cursor, so a client
+// that keeps passing the highest cursor it has seen never skips any.
+func ListEvents(ctx context.Context, pool *pgxpool.Pool, f EventFilter) ([]Event, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	if f.Limit <= 0 || f.Limit > MaxEventsPage {
+		f.Limit = MaxEventsPage
+	}
+	order := "DESC"
+	if f.Since > 0 {
+		order = "ASC"
+	}
+	rows, err := pool.Query(ctx, `
+SELECT e.id, e.seq, e.type, e.created_at, b.*
+FROM bounty_events e
+CROSS JOIN LATERAL (SELECT `+bountyColumns+` FROM bounties WHERE bounties.id = e.bounty_id) b
+JOIN projects p ON p.id = b.project_id
+WHERE ($1::uuid IS NULL OR e.project_id = $1)
+  AND ($2::uuid IS NULL OR e.project_id IN (SELECT id FROM projects WHERE owner_user_id = $2))
+  AND (cardinality($3::text[]) = 0 OR e.type = ANY($3))
+  AND e.seq > $4
+  AND `+VisibleTo("b", "p", "$6")+`
+ORDER BY e.seq `+order+`
+LIMIT $5
+`, f.ProjectID, f.OwnerID, f.Types, f.Since, f.Limit, f.Viewer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Event{}
+	for rows.Next() {
+		var (
+			e   Event
+			seq int64
+			b   bountyRow
+		)
+		if err := rows.Scan(append([]any{&e.ID, &seq, &e.Type, &e.CreatedAt}, b.dest()...)...); err != nil {
+			return nil, err
+		}
+		e.Bounty = b.bounty()
+		e.Cursor = strconv.FormatInt(seq, 10)
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if order == "ASC" {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,135 @@
+package bounties
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// History entry types, recorded by triggers on bounties, their splits and
+// their escrow payouts.
+const (
+	HistoryCreated             = "bounty.created"
+	HistoryStatusChanged       = "bounty.status_changed"
+	HistoryEdited              = "bounty.edited"
+	HistoryIssueClosed         = "bounty.issue_closed"
+	HistoryIssueReopened       = "bounty.issue_reopened"
+	HistoryEscrowStatusChanged = "bounty.escrow_status_changed"
+	HistoryClaimChanged        = "bounty.claim_changed"
+	HistorySplitProposed       = "split.proposed"
+	HistorySplitAccepted       = "split.accepted"
+	HistorySplitSuperseded     = "split.superseded"
+	HistorySplitShareChanged   = "split.share_changed"
+	HistorySplitShareAccepted  = "split.share_accepted"
+	HistoryPayoutCreated       = "payout.created"
+	HistoryPayoutStatusChanged = "payout.status_changed"
+)
+
+// MaxHistoryPage caps one page of history.
+const MaxHistoryPage = 500
+
+// HistoryEntry is one change in a bounty's timeline. Actor is nil for
+// changes the system made (webhooks, background jobs).
+type HistoryEntry struct {
+	Seq       int64          `json:"seq"`
+	Type      string         `json:"type"`
+	Actor     *Actor         `json:"actor"`
+	Changes   map[string]any `json:"changes"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Actor is the user a history entry is attributed to.
+type Actor struct {
+	UserID      uuid.UUID `json:"user_id"`
+	GitHubLogin *string   `json:"github_login,omitempty"`
+}
+
+// SetActor attributes the history entries the rest of tx records to actor.
+func SetActor(ctx context.Context, tx pgx.Tx, actor uuid.UUID) error {
+	_, err := tx.Exec(ctx, `SELECT set_config('grainlify.actor_user_id', $1, true)`, actor.String())
+	return err
+}
+
+// queryAsActor runs a statement returning bountyColumns with its history
+// entries attributed to actor; uuid.Nil leaves them to the system.
+func queryAsActor(ctx context.Context, pool *pgxpool.Pool, actor uuid.UUID, sql string, args ...any) (Bounty, error) {
+	if actor == uuid.Nil {
+		return scanBounty(pool.QueryRow(ctx, sql, args...))
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Bounty{}, err
+	}
+	defer tx.Rollback(ctx)
+	if err := SetActor(ctx, tx, actor); err != nil {
+		return Bounty{}, err
+	}
+	b, err := scanBounty(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return Bounty{}, err
+	}
+	return b, tx.Commit(ctx)
+}
+
+// History returns a bounty's timeline oldest first, starting after the
+// since cursor (an earlier entry's Seq).
+func History(ctx context.Context, pool *pgxpool.Pool, bountyID uuid.UUID, since int64, limit int) ([]HistoryEntry, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	if limit <= 0 || limit > MaxHistoryPage {
+		limit = MaxHistoryPage
+	}
+	rows, err := pool.Query(ctx, `
+SELECT h.seq, h.type, h.actor_user_id, ga.login, h.changes, h.created_at
+FROM bounty_history h
+LEFT JOIN LATERAL (
+  SELECT login FROM github_accounts WHERE user_id = h.actor_user_id LIMIT 1
+) ga ON true
+WHERE h.bounty_id = $1 AND h.seq > $2
+ORDER BY h.seq
+LIMIT $3
+`, bountyID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []HistoryEntry{}
+	for rows.Next() {
+		var (
+			e     HistoryEntry
+			actor *uuid.UUID
+			login *string
+		)
+		if err := rows.Scan(&e.Seq, &e.Type, &actor, &login, &e.Changes, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if actor != nil {
+			e.Actor = &Actor{UserID: *actor, GitHubLogin: login}
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// IsParticipant reports whether userID holds a share of one of the bounty's
+// splits or was paid from its escrow.
+func IsParticipant(ctx context.Context, pool *pgxpool.Pool, bountyID, userID uuid.UUID) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM bounty_split_shares s JOIN bounty_splits sp ON sp.id = s.split_id
+  WHERE sp.bounty_id = $1 AND s.user_id = $2
+) OR EXISTS (
+  SELECT 1 FROM payouts WHERE escrow_bounty_id = $1 AND user_id = $2
+)
+`, bountyID, userID).Scan(&ok)
+	return ok, err
+}
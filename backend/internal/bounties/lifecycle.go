@@ -0,0 +1,226 @@
+package bounties
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+)
+
+// A bounty's lifecycle:
+//
+//	open -> claimed -> submitted -> approved -> completed
+//
+// A contributor claims an open bounty and submits a pull request for it; the
+// maintainer approves the submission and pays it (see payouts.PayBounty, or
+// an escrow release). The claimant or the maintainer can drop a claim,
+// reopening the bounty, and the maintainer can cancel it until it is approved.
+// Merging a pull request that closes the bounty's issue approves it directly
+// (see ApproveMerged).
+// Each transition is checked against the bounty as loaded, then applied only
+// if the bounty is still in that state, so concurrent transitions can't both
+// succeed.
+
+var (
+	ErrDeadlinePassed = errors.New("bounty_deadline_passed")
+	ErrIssueClosed    = errors.New("issue_closed")
+	ErrOwnBounty      = errors.New("cannot_claim_own_bounty")
+	ErrNotClaimant    = errors.New("not_bounty_claimant")
+	ErrWrongRepo      = errors.New("pr_not_in_project_repo")
+	ErrEscrowFunded   = errors.New("bounty_escrow_funded")
+	ErrInvalidPR      = errors.New("invalid_pr_url")
+	ErrNotOnClaimList = errors.New("not_on_claim_list")
+	// ErrClaimWithheld refuses shadow-banned users, who never win a bounty;
+	// callers must not tell them why.
+	ErrClaimWithheld = errors.New("claim_withheld")
+)
+
+// PullRequest is a submitted GitHub pull request.
+type PullRequest struct {
+	Repo   string
+	Number int
+	URL    string
+}
+
+// ParsePullRequest parses a GitHub pull request URL such as
+// https://github.com/owner/repo/pull/42 (trailing /files and the like are
+// ignored).
+func ParsePullRequest(raw string) (PullRequest, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" || !strings.EqualFold(u.Host, "github.com") {
+		return PullRequest{}, ErrInvalidPR
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] == "" || parts[1] == "" || parts[2] != "pull" {
+		return PullRequest{}, ErrInvalidPR
+	}
+	n, err := strconv.Atoi(parts[3])
+	if err != nil || n < 1 {
+		return PullRequest{}, ErrInvalidPR
+	}
+	repo := parts[0] + "/" + parts[1]
+	return PullRequest{Repo: repo, Number: n, URL: "https://github.com/" + repo + "/pull/" + strconv.Itoa(n)}, nil
+}
+
+func (b Bounty) expired(now time.Time) bool {
+	return b.Deadline != nil && !now.Before(*b.Deadline)
+}
+
+func (b Bounty) claimedBy(userID uuid.UUID) bool {
+	return b.Claim != nil && b.Claim.UserID == userID
+}
+
+// CheckClaim reports why userID can't claim b, if they can't.
+func (b Bounty) CheckClaim(userID uuid.UUID, now time.Time) error {
+	switch {
+	case b.Status != StatusOpen:
+		return ErrInvalidStatus
+	case b.Issue.Closed:
+		return ErrIssueClosed
+	case b.expired(now):
+		return ErrDeadlinePassed
+	case userID == b.CreatedBy:
+		return ErrOwnBounty
+	}
+	return nil
+}
+
+// CheckUnclaim reports why userID can't drop b's claim, if they can't.
+// Maintainers can drop anyone's claim, e.g. to reject a submission.
+func (b Bounty) CheckUnclaim(userID uuid.UUID, maintainer bool) error {
+	if b.Status != StatusClaimed && b.Status != StatusSubmitted {
+		return ErrInvalidStatus
+	}
+	if !maintainer && !b.claimedBy(userID) {
+		return ErrNotClaimant
+	}
+	return nil
+}
+
+// CheckSubmit reports why userID can't submit pr for b, if they can't. A
+// submitted bounty can be submitted again to replace the pull request.
+// projectRepo is the project's GitHub repository.
+func (b Bounty) CheckSubmit(userID uuid.UUID, pr PullRequest, projectRepo string, now time.Time) error {
+	switch {
+	case b.Status != StatusClaimed && b.Status != StatusSubmitted:
+		return ErrInvalidStatus
+	case !b.claimedBy(userID):
+		return ErrNotClaimant
+	case b.expired(now):
+		return ErrDeadlinePassed
+	case !strings.EqualFold(pr.Repo, projectRepo):
+		return ErrWrongRepo
+	}
+	return nil
+}
+
+// CheckApprove reports why b's submission can't be approved, if it can't.
+func (b Bounty) CheckApprove() error {
+	if b.Status != StatusSubmitted {
+		return ErrInvalidStatus
+	}
+	return nil
+}
+
+// CheckPay reports why b can't be paid from the hot wallet, if it can't.
+// Escrow-funded bounties are paid by releasing the escrow instead.
+func (b Bounty) CheckPay() error {
+	switch {
+	case b.Status != StatusApproved || b.Claim == nil:
+		return ErrInvalidStatus
+	case b.Funding == FundingEscrow:
+		return ErrEscrowFunded
+	}
+	return nil
+}
+
+// transition applies set ($3 onwards are args) to b on behalf of actor,
+// provided b is still in the status and claim it was checked in.
+func transition(ctx context.Context, pool *pgxpool.Pool, b Bounty, actor uuid.UUID, set string, args ...any) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	var claimant *uuid.UUID
+	if b.Claim != nil {
+		claimant = &b.Claim.UserID
+	}
+	out, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties SET `+set+`, updated_at = now()
+WHERE id = $1 AND status = $2 AND claimed_by IS NOT DISTINCT FROM `+fmt.Sprintf("$%d", len(args)+3)+`
+RETURNING `+bountyColumns, append(append([]any{b.ID, b.Status}, args...), claimant)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Bounty{}, ErrInvalidStatus
+	}
+	return out, err
+}
+
+// Claim assigns open bounty b to userID. Security-advisory bounties can
+// only be claimed by the users invited to them, and shadow-banned users
+// claim nothing.
+func Claim(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID uuid.UUID) (Bounty, error) {
+	if err := b.CheckClaim(userID, time.Now()); err != nil {
+		return Bounty{}, err
+	}
+	if err := checkNotShadowBanned(ctx, pool, userID); err != nil {
+		return Bounty{}, err
+	}
+	if b.Kind == KindSecurityAdvisory {
+		invited, err := Invited(ctx, pool, b.ID, userID)
+		if err != nil {
+			return Bounty{}, err
+		}
+		if !invited {
+			return Bounty{}, ErrNotOnClaimList
+		}
+	}
+	return transition(ctx, pool, b, userID, `status = 'claimed', claimed_by = $3, claimed_at = now()`, userID)
+}
+
+// checkNotShadowBanned returns ErrClaimWithheld for shadow-banned users.
+func checkNotShadowBanned(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) error {
+	banned, err := moderation.IsShadowBanned(ctx, pool, userID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrClaimWithheld
+	}
+	return nil
+}
+
+// Unclaim drops b's claim and any submission, reopening it.
+func Unclaim(ctx context.Context, pool *pgxpool.Pool, b Bounty, actor uuid.UUID, maintainer bool) (Bounty, error) {
+	if err := b.CheckUnclaim(actor, maintainer); err != nil {
+		return Bounty{}, err
+	}
+	return transition(ctx, pool, b, actor, `status = 'open', claimed_by = NULL, claimed_at = NULL,
+    pr_repo_full_name = NULL, pr_number = NULL, pr_url = NULL, submitted_at = NULL`)
+}
+
+// Submit records the claimant's pull request for b. projectRepo is the
+// project's GitHub repository, which the pull request must belong to.
+func Submit(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID uuid.UUID, pr PullRequest, projectRepo string) (Bounty, error) {
+	if err := b.CheckSubmit(userID, pr, projectRepo, time.Now()); err != nil {
+		return Bounty{}, err
+	}
+	return transition(ctx, pool, b, userID, `status = 'submitted', pr_repo_full_name = $3, pr_number = $4, pr_url = $5, submitted_at = now()`,
+		pr.Repo, pr.Number, pr.URL)
+}
+
+// Approve accepts b's submission on behalf of maintainer actor, leaving it
+// to be paid.
+func Approve(ctx context.Context, pool *pgxpool.Pool, b Bounty, actor uuid.UUID) (Bounty, error) {
+	if err := b.CheckApprove(); err != nil {
+		return Bounty{}, err
+	}
+	return transition(ctx, pool, b, actor, `status = 'approved', approved_by = $3, approved_at = now()`, actor)
+}
@@ -0,0 +1,152 @@
+package bounties
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParsePullRequest(t *testing.T) {
+	cases := []struct {
+		in   string
+		want PullRequest
+	}{
+		{"https://github.com/acme/widgets/pull/42", PullRequest{Repo: "acme/widgets", Number: 42, URL: "https://github.com/acme/widgets/pull/42"}},
+		{" https://GitHub.com/acme/widgets/pull/7/files#diff ", PullRequest{Repo: "acme/widgets", Number: 7, URL: "https://github.com/acme/widgets/pull/7"}},
+	}
+	for _, tc := range cases {
+		got, err := ParsePullRequest(tc.in)
+		if err != nil || got != tc.want {
+			t.Fatalf("ParsePullRequest(%q) = %+v, %v; want %+v", tc.in, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{
+		"", "http://github.com/acme/widgets/pull/1", "https://gitlab.com/acme/widgets/pull/1",
+		"https://github.com/acme/widgets/issues/1", "https://github.com/acme/widgets/pull/0", "https://github.com/acme/pull/1",
+	} {
+		if _, err := ParsePullRequest(in); !errors.Is(err, ErrInvalidPR) {
+			t.Fatalf("ParsePullRequest(%q) err = %v, want ErrInvalidPR", in, err)
+		}
+	}
+}
+
+func TestLifecycleChecks(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	maintainer, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	pr := PullRequest{Repo: "Acme/Widgets", Number: 3}
+
+	open := Bounty{Status: StatusOpen, CreatedBy: maintainer, Deadline: &future}
+	if err := open.CheckClaim(alice, now); err != nil {
+		t.Fatalf("claim open bounty: %v", err)
+	}
+	if err := open.CheckClaim(maintainer, now); !errors.Is(err, ErrOwnBounty) {
+		t.Fatalf("claim own bounty err = %v", err)
+	}
+	if err := open.CheckClaim(alice, future.Add(time.Second)); !errors.Is(err, ErrDeadlinePassed) {
+		t.Fatalf("claim after deadline err = %v", err)
+	}
+	closed := open
+	closed.Issue.Closed = true
+	if err := closed.CheckClaim(alice, now); !errors.Is(err, ErrIssueClosed) {
+		t.Fatalf("claim on closed issue err = %v", err)
+	}
+	if err := open.CheckSubmit(alice, pr, "acme/widgets", now); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("submit unclaimed bounty err = %v", err)
+	}
+
+	claimed := open
+	claimed.Status = StatusClaimed
+	claimed.Claim = &Claimant{UserID: alice, ClaimedAt: now}
+	if err := claimed.CheckClaim(bob, now); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("claim claimed bounty err = %v", err)
+	}
+	if err := claimed.CheckSubmit(alice, pr, "acme/widgets", now); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if err := claimed.CheckSubmit(bob, pr, "acme/widgets", now); !errors.Is(err, ErrNotClaimant) {
+		t.Fatalf("submit by non-claimant err = %v", err)
+	}
+	if err := claimed.CheckSubmit(alice, pr, "acme/gadgets", now); !errors.Is(err, ErrWrongRepo) {
+		t.Fatalf("submit other repo err = %v", err)
+	}
+	expired := claimed
+	expired.Deadline = &past
+	if err := expired.CheckSubmit(alice, pr, "acme/widgets", now); !errors.Is(err, ErrDeadlinePassed) {
+		t.Fatalf("submit after deadline err = %v", err)
+	}
+	if err := claimed.CheckUnclaim(bob, false); !errors.Is(err, ErrNotClaimant) {
+		t.Fatalf("unclaim by non-claimant err = %v", err)
+	}
+	if err := claimed.CheckUnclaim(bob, true); err != nil {
+		t.Fatalf("unclaim by maintainer: %v", err)
+	}
+	if err := claimed.CheckApprove(); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("approve without submission err = %v", err)
+	}
+
+	submitted := claimed
+	submitted.Status = StatusSubmitted
+	if err := submitted.CheckApprove(); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if err := submitted.CheckPay(); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("pay unapproved bounty err = %v", err)
+	}
+
+	approved := submitted
+	approved.Status = StatusApproved
+	approved.Funding = FundingHotWallet
+	if err := approved.CheckPay(); err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+	if err := approved.CheckUnclaim(alice, false); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("unclaim approved bounty err = %v", err)
+	}
+	approved.Funding = FundingEscrow
+	if err := approved.CheckPay(); !errors.Is(err, ErrEscrowFunded) {
+		t.Fatalf("pay escrow bounty err = %v", err)
+	}
+}
+
+func TestClosingReferences(t *testing.T) {
+	body := "Fixes #12, closes acme/widgets#7 and resolves https://github.com/Acme/Widgets/issues/3.\n" +
+		"Also fixes other/repo#9, see #4, Resolved: eng-42, fixes #12 again."
+	got := ClosingReferences(body, "acme/widgets")
+	want := []string{"#12", "#7", "#3", "ENG-42"}
+	if len(got) != len(want) {
+		t.Fatalf("ClosingReferences = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ClosingReferences = %v, want %v", got, want)
+		}
+	}
+	if got := ClosingReferences("prefix#12 and unfixes #3", "acme/widgets"); len(got) != 0 {
+		t.Fatalf("ClosingReferences matched non-keywords: %v", got)
+	}
+}
+
+func TestCheckMerge(t *testing.T) {
+	maintainer, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	open := Bounty{Status: StatusOpen, CreatedBy: maintainer}
+	if err := open.CheckMerge(alice); err != nil {
+		t.Fatalf("merge on open bounty: %v", err)
+	}
+	if err := open.CheckMerge(maintainer); !errors.Is(err, ErrOwnBounty) {
+		t.Fatalf("maintainer merge err = %v", err)
+	}
+	claimed := open
+	claimed.Status = StatusSubmitted
+	claimed.Claim = &Claimant{UserID: alice}
+	if err := claimed.CheckMerge(bob); !errors.Is(err, ErrNotClaimant) {
+		t.Fatalf("merge by non-claimant err = %v", err)
+	}
+	approved := claimed
+	approved.Status = StatusApproved
+	if err := approved.CheckMerge(alice); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("merge on approved bounty err = %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package bounties
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// closingRef matches GitHub's closing keywords followed by an issue: #12,
+// owner/repo#12, an issue URL, or a Jira/Linear key such as ENG-42.
+var closingRef = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+` +
+	`(#\d+|[\w.-]+/[\w.-]+#\d+|https://github\.com/[\w.-]+/[\w.-]+/issues/\d+|[a-z][a-z0-9]+-\d+)\b`)
+
+// ClosingReferences returns the keys (as in Issue.Key) of the issues that a
+// pull request in repo says it closes. References to other repositories'
+// issues are dropped.
+func ClosingReferences(text, repo string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range closingRef.FindAllStringSubmatch(text, -1) {
+		ref := m[1]
+		var key string
+		switch {
+		case strings.HasPrefix(ref, "#"):
+			key = ref
+		case strings.HasPrefix(strings.ToLower(ref), "https://"):
+			path := strings.TrimPrefix(ref[len("https://github.com/"):], "/")
+			r, n, _ := strings.Cut(path, "/issues/")
+			if strings.EqualFold(r, repo) {
+				key = "#" + n
+			}
+		case strings.Contains(ref, "/"):
+			r, n, _ := strings.Cut(ref, "#")
+			if strings.EqualFold(r, repo) {
+				key = "#" + n
+			}
+		default:
+			key = strings.ToUpper(ref)
+		}
+		if key != "" && !seen[key] {
+			seen[key] = true
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// CheckMerge reports why a merged pull request by userID can't settle b, if
+// it can't. An open bounty goes to the author; a claimed one only to its
+// claimant, and the maintainer's own pull requests never settle it.
+func (b Bounty) CheckMerge(userID uuid.UUID) error {
+	switch {
+	case b.Status != StatusOpen && b.Status != StatusClaimed && b.Status != StatusSubmitted:
+		return ErrInvalidStatus
+	case userID == b.CreatedBy:
+		return ErrOwnBounty
+	case b.Claim != nil && b.Claim.UserID != userID:
+		return ErrNotClaimant
+	}
+	return nil
+}
+
+// ListByIssueKeys returns a project's bounties on the given issues that a
+// merged pull request could settle.
+func ListByIssueKeys(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, keys []string) ([]Bounty, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+bountyColumns+`
+FROM bounties
+WHERE project_id = $1 AND issue_key = ANY($2) AND status IN ('open', 'claimed', 'submitted')
+ORDER BY created_at
+`, projectID, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Bounty{}
+	for rows.Next() {
+		b, err := scanBounty(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// ApproveMerged approves b for pr, a merged pull request by userID, claiming
+// and submitting it on their behalf as needed; it then awaits payment.
+// Shadow-banned authors get ErrClaimWithheld. The
+// system makes the change, so its history has no actor and no approver,
+// which has the project owner notified with a bounty.merged notification.
+func ApproveMerged(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID uuid.UUID, pr PullRequest) (Bounty, error) {
+	if err := b.CheckMerge(userID); err != nil {
+		return Bounty{}, err
+	}
+	if err := checkNotShadowBanned(ctx, pool, userID); err != nil {
+		return Bounty{}, err
+	}
+	return transition(ctx, pool, b, uuid.Nil, `status = 'approved', claimed_by = $3, claimed_at = COALESCE(claimed_at, now()),
+    pr_repo_full_name = $4, pr_number = $5, pr_url = $6, submitted_at = COALESCE(submitted_at, now()), approved_at = now()`,
+		userID, pr.Repo, pr.Number, pr.URL)
+}
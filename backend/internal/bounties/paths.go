@@ -0,0 +1,59 @@
+package bounties
+
+import (
+	"errors"
+	"strings"
+)
+
+// A bounty on a monorepo can be scoped to one of its directories, its path
+// prefix: only merged pull requests changing files under it settle the
+// bounty, and its skill tags come from that subtree.
+
+// MaxPathPrefixLen caps a path prefix.
+const MaxPathPrefixLen = 255
+
+var ErrInvalidPathPrefix = errors.New("invalid_path_prefix")
+
+// NormalizePath cleans a maintainer-supplied path prefix into a repo
+// relative directory such as "packages/api", without leading or trailing
+// slashes. "" and "/" mean the whole repo and normalize to "".
+func NormalizePath(p string) (string, error) {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return "", nil
+	}
+	if len(p) > MaxPathPrefixLen || strings.Contains(p, `\`) {
+		return "", ErrInvalidPathPrefix
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return "", ErrInvalidPathPrefix
+		}
+		for _, r := range seg {
+			if r < 0x20 || r == 0x7f {
+				return "", ErrInvalidPathPrefix
+			}
+		}
+	}
+	return p, nil
+}
+
+// UnderPath reports whether the repo file name is within the directory
+// prefix; every file is within "".
+func UnderPath(prefix, name string) bool {
+	return prefix == "" || name == prefix || strings.HasPrefix(name, prefix+"/")
+}
+
+// ChangesPath reports whether a pull request changing files touches b's
+// path prefix. Unscoped bounties accept any pull request.
+func (b Bounty) ChangesPath(files []string) bool {
+	if b.PathPrefix == "" {
+		return true
+	}
+	for _, f := range files {
+		if UnderPath(b.PathPrefix, f) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package bounties
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                "",
+		" / ":             "",
+		"packages/api":    "packages/api",
+		"/packages/api/ ": "packages/api",
+		"apps/web-ui":     "apps/web-ui",
+	} {
+		got, err := NormalizePath(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizePath(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"a//b", "a/./b", "../secrets", `a\b`, "a/\nb", strings.Repeat("a", MaxPathPrefixLen+1)} {
+		if _, err := NormalizePath(in); !errors.Is(err, ErrInvalidPathPrefix) {
+			t.Errorf("NormalizePath(%q) err = %v", in, err)
+		}
+	}
+}
+
+func TestChangesPath(t *testing.T) {
+	files := []string{"README.md", "packages/api-client/index.ts"}
+	if !(Bounty{}).ChangesPath(files) {
+		t.Error("unscoped bounty rejected a pull request")
+	}
+	scoped := Bounty{PathPrefix: "packages/api"}
+	if scoped.ChangesPath(files) {
+		t.Error("sibling directory with the prefix as its name's start matched")
+	}
+	if !scoped.ChangesPath(append(files, "packages/api/server.go")) {
+		t.Error("file under the prefix did not match")
+	}
+	if scoped.ChangesPath(nil) {
+		t.Error("pull request without files matched a scoped bounty")
+	}
+}
@@ -0,0 +1,345 @@
+package bounties
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Time tracking: a claimant logs time against their active claim, with a
+// start/stop timer or manual entries. Maintainers see the summary alongside
+// the submission, and the logged time of completed bounties feeds the
+// amount suggestions (see SuggestRates).
+
+// Time entry sources.
+const (
+	TimeSourceTimer  = "timer"
+	TimeSourceManual = "manual"
+)
+
+// MaxTimeEntry is the longest single entry accepted; a timer left running
+// longer is capped at it when stopped.
+const MaxTimeEntry = 12 * time.Hour
+
+var (
+	ErrTimerRunning     = errors.New("timer_already_running")
+	ErrNoTimer          = errors.New("no_timer_running")
+	ErrInvalidTimeEntry = errors.New("invalid_time_entry")
+	ErrTimeEntryMissing = errors.New("time_entry_not_found")
+)
+
+// TimeEntry is a span of work logged on a claim. EndedAt is nil while its
+// timer runs.
+type TimeEntry struct {
+	ID        uuid.UUID  `json:"id"`
+	BountyID  uuid.UUID  `json:"bounty_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Source    string     `json:"source"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Seconds is the entry's length, up to now for a running timer.
+func (e TimeEntry) Seconds(now time.Time) int64 {
+	end := now
+	if e.EndedAt != nil {
+		end = *e.EndedAt
+	}
+	if !end.After(e.StartedAt) {
+		return 0
+	}
+	return int64(end.Sub(e.StartedAt) / time.Second)
+}
+
+// TimeSummary is the time a claimant logged on a bounty.
+type TimeSummary struct {
+	BountyID uuid.UUID `json:"bounty_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	// TotalSeconds counts finished entries only.
+	TotalSeconds int64       `json:"total_seconds"`
+	Running      *TimeEntry  `json:"running,omitempty"`
+	Entries      []TimeEntry `json:"entries"`
+	// SubmittedAt and PRURL are the claimant's submission, when there is one.
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+	PRURL       *string    `json:"pr_url,omitempty"`
+}
+
+// Summarize totals entries, oldest first.
+func Summarize(bountyID, userID uuid.UUID, entries []TimeEntry) TimeSummary {
+	s := TimeSummary{BountyID: bountyID, UserID: userID, Entries: entries}
+	for i, e := range entries {
+		if e.EndedAt == nil {
+			s.Running = &entries[i]
+			continue
+		}
+		s.TotalSeconds += e.Seconds(*e.EndedAt)
+	}
+	return s
+}
+
+// CheckLogTime reports why userID can't log time on b, if they can't: only
+// the claimant, while the claim is active.
+func (b Bounty) CheckLogTime(userID uuid.UUID) error {
+	switch {
+	case b.Status != StatusClaimed && b.Status != StatusSubmitted:
+		return ErrInvalidStatus
+	case !b.claimedBy(userID):
+		return ErrNotClaimant
+	}
+	return nil
+}
+
+// CheckManualEntry reports whether [start, end) is an acceptable manual
+// entry: in the past, after the claim and no longer than MaxTimeEntry.
+func (b Bounty) CheckManualEntry(start, end, now time.Time) error {
+	switch {
+	case !end.After(start), end.After(now), end.Sub(start) > MaxTimeEntry:
+		return ErrInvalidTimeEntry
+	case b.Claim != nil && start.Before(b.Claim.ClaimedAt):
+		return ErrInvalidTimeEntry
+	}
+	return nil
+}
+
+const timeEntryColumns = `id, bounty_id, user_id, source, started_at, ended_at, note, created_at`
+
+func scanTimeEntry(row pgx.Row) (TimeEntry, error) {
+	var e TimeEntry
+	err := row.Scan(&e.ID, &e.BountyID, &e.UserID, &e.Source, &e.StartedAt, &e.EndedAt, &e.Note, &e.CreatedAt)
+	return e, err
+}
+
+// StartTimer starts a timer on b for its claimant.
+func StartTimer(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID uuid.UUID, note string) (TimeEntry, error) {
+	if pool == nil {
+		return TimeEntry{}, fmt.Errorf("db not configured")
+	}
+	if err := b.CheckLogTime(userID); err != nil {
+		return TimeEntry{}, err
+	}
+	e, err := scanTimeEntry(pool.QueryRow(ctx, `
+INSERT INTO claim_time_entries (bounty_id, user_id, source, started_at, note)
+VALUES ($1, $2, 'timer', now(), $3)
+RETURNING `+timeEntryColumns, b.ID, userID, strings.TrimSpace(note)))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return TimeEntry{}, ErrTimerRunning
+	}
+	return e, err
+}
+
+// StopTimer stops the claimant's running timer on b, capping it at
+// MaxTimeEntry.
+func StopTimer(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID uuid.UUID) (TimeEntry, error) {
+	if pool == nil {
+		return TimeEntry{}, fmt.Errorf("db not configured")
+	}
+	e, err := scanTimeEntry(pool.QueryRow(ctx, `
+UPDATE claim_time_entries
+SET ended_at = LEAST(now(), started_at + make_interval(secs => $3))
+WHERE bounty_id = $1 AND user_id = $2 AND ended_at IS NULL
+RETURNING `+timeEntryColumns, b.ID, userID, MaxTimeEntry.Seconds()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TimeEntry{}, ErrNoTimer
+	}
+	return e, err
+}
+
+// LogTime records a manual entry on b for its claimant.
+func LogTime(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID uuid.UUID, start, end time.Time, note string) (TimeEntry, error) {
+	if pool == nil {
+		return TimeEntry{}, fmt.Errorf("db not configured")
+	}
+	if err := b.CheckLogTime(userID); err != nil {
+		return TimeEntry{}, err
+	}
+	if err := b.CheckManualEntry(start, end, time.Now()); err != nil {
+		return TimeEntry{}, err
+	}
+	return scanTimeEntry(pool.QueryRow(ctx, `
+INSERT INTO claim_time_entries (bounty_id, user_id, source, started_at, ended_at, note)
+VALUES ($1, $2, 'manual', $3, $4, $5)
+RETURNING `+timeEntryColumns, b.ID, userID, start, end, strings.TrimSpace(note)))
+}
+
+// DeleteTimeEntry removes one of the claimant's entries while the claim is
+// active.
+func DeleteTimeEntry(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID, entryID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	if err := b.CheckLogTime(userID); err != nil {
+		return err
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM claim_time_entries WHERE id = $1 AND bounty_id = $2 AND user_id = $3`, entryID, b.ID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTimeEntryMissing
+	}
+	return nil
+}
+
+// TimeLogged returns the time userID logged on b, with b's submission when
+// userID is its claimant.
+func TimeLogged(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID uuid.UUID) (TimeSummary, error) {
+	if pool == nil {
+		return TimeSummary{}, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+timeEntryColumns+`
+FROM claim_time_entries
+WHERE bounty_id = $1 AND user_id = $2
+ORDER BY started_at, id
+`, b.ID, userID)
+	if err != nil {
+		return TimeSummary{}, err
+	}
+	defer rows.Close()
+	entries := []TimeEntry{}
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			return TimeSummary{}, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return TimeSummary{}, err
+	}
+	s := Summarize(b.ID, userID, entries)
+	if b.claimedBy(userID) {
+		s.SubmittedAt, s.PRURL = b.Claim.SubmittedAt, b.Claim.PRURL
+	}
+	return s, nil
+}
+
+// RateSample is a completed bounty with time logged by its claimant.
+type RateSample struct {
+	Chain   string
+	Asset   string
+	Amount  float64
+	Seconds int64
+	// InProject is set for bounties of the project asking.
+	InProject bool
+}
+
+// Rate is the going rate for bounties paid in one chain/asset: the median
+// amount per logged hour and the median hours logged, over Samples
+// completed bounties of the project, or of the whole platform when the
+// project has fewer than MinRateSamples.
+type Rate struct {
+	Chain         string  `json:"chain"`
+	Asset         string  `json:"asset"`
+	Scope         string  `json:"scope"`
+	Samples       int     `json:"samples"`
+	AmountPerHour string  `json:"amount_per_hour"`
+	MedianHours   float64 `json:"median_hours"`
+}
+
+// MinRateSamples is how many completed bounties a project needs before its
+// own rates are used.
+const MinRateSamples = 5
+
+// SuggestRates computes per chain/asset rates from samples, preferring the
+// project's own.
+func SuggestRates(samples []RateSample) []Rate {
+	type key struct{ chain, asset string }
+	project, platform := map[key][]RateSample{}, map[key][]RateSample{}
+	for _, s := range samples {
+		if s.Seconds <= 0 || s.Amount <= 0 {
+			continue
+		}
+		k := key{s.Chain, s.Asset}
+		platform[k] = append(platform[k], s)
+		if s.InProject {
+			project[k] = append(project[k], s)
+		}
+	}
+	out := []Rate{}
+	for k, all := range platform {
+		scope, use := "platform", all
+		if len(project[k]) >= MinRateSamples {
+			scope, use = "project", project[k]
+		}
+		perHour := make([]float64, len(use))
+		hours := make([]float64, len(use))
+		for i, s := range use {
+			hours[i] = float64(s.Seconds) / 3600
+			perHour[i] = s.Amount / hours[i]
+		}
+		out = append(out, Rate{
+			Chain:         k.chain,
+			Asset:         k.asset,
+			Scope:         scope,
+			Samples:       len(use),
+			AmountPerHour: strconv.FormatFloat(median(perHour), 'f', 2, 64),
+			MedianHours:   float64(int(median(hours)*10+0.5)) / 10,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Chain != out[j].Chain {
+			return out[i].Chain < out[j].Chain
+		}
+		return out[i].Asset < out[j].Asset
+	})
+	return out
+}
+
+func median(xs []float64) float64 {
+	sort.Float64s(xs)
+	n := len(xs)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
+
+// Rates suggests bounty amounts for projectID from the time claimants
+// logged on completed bounties.
+func Rates(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) ([]Rate, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT b.chain, b.asset, b.amount::float8, t.seconds::bigint, b.project_id = $1
+FROM bounties b
+JOIN LATERAL (
+  SELECT SUM(EXTRACT(EPOCH FROM t.ended_at - t.started_at)) AS seconds
+  FROM claim_time_entries t
+  WHERE t.bounty_id = b.id AND t.user_id = b.claimed_by AND t.ended_at IS NOT NULL
+) t ON t.seconds > 0
+WHERE b.status = 'completed'
+`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var samples []RateSample
+	for rows.Next() {
+		var s RateSample
+		if err := rows.Scan(&s.Chain, &s.Asset, &s.Amount, &s.Seconds, &s.InProject); err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return SuggestRates(samples), nil
+}
@@ -0,0 +1,82 @@
+package bounties
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTimeLogChecks(t *testing.T) {
+	now := time.Now()
+	alice, bob := uuid.New(), uuid.New()
+	claimed := Bounty{Status: StatusClaimed, Claim: &Claimant{UserID: alice, ClaimedAt: now.Add(-48 * time.Hour)}}
+
+	if err := claimed.CheckLogTime(alice); err != nil {
+		t.Fatalf("claimant logging time: %v", err)
+	}
+	if err := claimed.CheckLogTime(bob); !errors.Is(err, ErrNotClaimant) {
+		t.Fatalf("other user logging time err = %v", err)
+	}
+	approved := claimed
+	approved.Status = StatusApproved
+	if err := approved.CheckLogTime(alice); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("logging on approved bounty err = %v", err)
+	}
+
+	start := now.Add(-3 * time.Hour)
+	if err := claimed.CheckManualEntry(start, start.Add(2*time.Hour), now); err != nil {
+		t.Fatalf("valid manual entry: %v", err)
+	}
+	for name, span := range map[string][2]time.Time{
+		"empty":        {start, start},
+		"future":       {start, now.Add(time.Minute)},
+		"too long":     {now.Add(-13 * time.Hour), now},
+		"before claim": {now.Add(-49 * time.Hour), now.Add(-47 * time.Hour)},
+	} {
+		if err := claimed.CheckManualEntry(span[0], span[1], now); !errors.Is(err, ErrInvalidTimeEntry) {
+			t.Errorf("%s entry err = %v", name, err)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	end := t0.Add(90 * time.Minute)
+	entries := []TimeEntry{
+		{StartedAt: t0, EndedAt: &end},
+		{StartedAt: t0.Add(2 * time.Hour)},
+	}
+	s := Summarize(uuid.New(), uuid.New(), entries)
+	if s.TotalSeconds != 90*60 {
+		t.Errorf("total = %d, want %d", s.TotalSeconds, 90*60)
+	}
+	if s.Running == nil || !s.Running.StartedAt.Equal(t0.Add(2*time.Hour)) {
+		t.Errorf("running = %+v", s.Running)
+	}
+}
+
+func TestSuggestRates(t *testing.T) {
+	var samples []RateSample
+	// The platform pays 100/h in USDC on stellar; this project 50/h, but
+	// with too few bounties to count until the fifth.
+	for i := 0; i < 3; i++ {
+		samples = append(samples, RateSample{Chain: "stellar", Asset: "USDC", Amount: 200, Seconds: 7200})
+	}
+	for i := 0; i < MinRateSamples-1; i++ {
+		samples = append(samples, RateSample{Chain: "stellar", Asset: "USDC", Amount: 50, Seconds: 3600, InProject: true})
+	}
+	samples = append(samples, RateSample{Chain: "evm", Asset: "ETH", Amount: 1, Seconds: 0})
+
+	got := SuggestRates(samples)
+	if len(got) != 1 || got[0].Scope != "platform" || got[0].Samples != 3+MinRateSamples-1 {
+		t.Fatalf("rates = %+v", got)
+	}
+
+	samples = append(samples, RateSample{Chain: "stellar", Asset: "USDC", Amount: 100, Seconds: 7200, InProject: true})
+	got = SuggestRates(samples)
+	if len(got) != 1 || got[0].Scope != "project" || got[0].AmountPerHour != "50.00" || got[0].MedianHours != 1 {
+		t.Fatalf("rates = %+v", got)
+	}
+}
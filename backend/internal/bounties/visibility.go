@@ -0,0 +1,277 @@
+package bounties
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+// Visibilities. Public bounties are listed in feeds; private ones, such as
+// security fixes, are only seen by members of the project's GitHub
+// organization and invited users; unlisted ones by whoever holds their
+// signed link. The project's maintainers and the claimant see them all.
+const (
+	VisibilityPublic   = "public"
+	VisibilityPrivate  = "private"
+	VisibilityUnlisted = "unlisted"
+)
+
+// Visibilities lists every visibility.
+var Visibilities = []string{VisibilityPublic, VisibilityPrivate, VisibilityUnlisted}
+
+var (
+	ErrInvalidVisibility = errors.New("invalid_visibility")
+	ErrNotUnlisted       = errors.New("bounty_not_unlisted")
+	ErrInviteeNotFound   = errors.New("invitee_not_found")
+	ErrNotInvited        = errors.New("bounty_invite_not_found")
+	ErrEmbargoed         = errors.New("bounty_under_embargo")
+)
+
+// ValidVisibility reports whether v is one of Visibilities.
+func ValidVisibility(v string) bool { return slices.Contains(Visibilities, v) }
+
+// Listed is an SQL condition that holds when the bounty aliased bounty may
+// appear in feeds and search.
+func Listed(bounty string) string {
+	return bounty + `.visibility = 'public'`
+}
+
+// VisibleTo is an SQL condition that holds when the user in userArg may see
+// the bounty aliased bounty, of the project aliased project, without its
+// link: it is public, they maintain the project, claimed the bounty or were
+// invited to it, or it is private and they belong to the project's linked
+// organization.
+func VisibleTo(bounty, project, userArg string) string {
+	return `(` + Listed(bounty) + `
+  OR ` + project + `.owner_user_id = ` + userArg + `
+  OR ` + bounty + `.claimed_by = ` + userArg + `
+  OR EXISTS (SELECT 1 FROM bounty_invites i WHERE i.bounty_id = ` + bounty + `.id AND i.user_id = ` + userArg + `)
+  OR ` + orgs.Can(project, userArg, `'`+string(orgs.PermEditBounties)+`'`) + `
+  OR (` + bounty + `.visibility = 'private' AND ` + orgs.MemberOf(project, userArg) + `))`
+}
+
+// CanView reports whether userID, uuid.Nil when signed out, may see b
+// without its link (see VisibleTo).
+func CanView(ctx context.Context, pool *pgxpool.Pool, b Bounty, userID uuid.UUID) (bool, error) {
+	if b.Visibility == VisibilityPublic {
+		return true, nil
+	}
+	if userID == uuid.Nil {
+		return false, nil
+	}
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `
+SELECT `+VisibleTo("b", "p", "$2")+`
+FROM bounties b JOIN projects p ON p.id = b.project_id
+WHERE b.id = $1
+`, b.ID, userID).Scan(&ok)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// LinkToken is the token in an unlisted bounty's link, signed with secret
+// over the bounty and its link version. It is empty for other bounties.
+func LinkToken(secret string, b Bounty) string {
+	if b.Visibility != VisibilityUnlisted {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "bounty-link:%s:%d", b.ID, b.linkVersion)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidLink reports whether token is the current link token of unlisted b.
+func ValidLink(secret string, b Bounty, token string) bool {
+	want := LinkToken(secret, b)
+	return want != "" && token != "" && hmac.Equal([]byte(token), []byte(want))
+}
+
+// embargoed is an SQL condition that holds while the bounty aliased bounty
+// is a security-advisory bounty whose advisory hasn't been disclosed.
+func embargoed(bounty string) string {
+	return `EXISTS (SELECT 1 FROM bounty_advisories a WHERE a.bounty_id = ` + bounty + `.id AND a.published_at IS NULL)`
+}
+
+// SetVisibility changes who can see a bounty. Security-advisory bounties
+// stay private until their advisory is disclosed.
+func SetVisibility(ctx context.Context, pool *pgxpool.Pool, projectID, id, actor uuid.UUID, visibility string) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	if !ValidVisibility(visibility) {
+		return Bounty{}, ErrInvalidVisibility
+	}
+	b, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties SET visibility = $3, updated_at = now()
+WHERE id = $1 AND project_id = $2 AND ($3 = 'private' OR NOT `+embargoed("bounties")+`)
+RETURNING `+bountyColumns, id, projectID, visibility)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, gerr := Get(ctx, pool, projectID, id); gerr != nil {
+			return Bounty{}, gerr
+		}
+		return Bounty{}, ErrEmbargoed
+	}
+	return b, err
+}
+
+// RotateLink revokes an unlisted bounty's link in favour of a new one.
+func RotateLink(ctx context.Context, pool *pgxpool.Pool, projectID, id, actor uuid.UUID) (Bounty, error) {
+	if pool == nil {
+		return Bounty{}, fmt.Errorf("db not configured")
+	}
+	b, err := queryAsActor(ctx, pool, actor, `
+UPDATE bounties SET link_version = link_version + 1, updated_at = now()
+WHERE id = $1 AND project_id = $2 AND visibility = 'unlisted'
+RETURNING `+bountyColumns, id, projectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, gerr := Get(ctx, pool, projectID, id); gerr != nil {
+			return Bounty{}, gerr
+		}
+		return Bounty{}, ErrNotUnlisted
+	}
+	return b, err
+}
+
+// ListHidden lists a project's private and unlisted bounties.
+func ListHidden(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) ([]Bounty, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	return queryBounties(ctx, pool, `
+SELECT `+bountyColumns+`
+FROM bounties
+WHERE project_id = $1 AND NOT `+Listed("bounties")+`
+ORDER BY created_at DESC
+LIMIT 200
+`, projectID)
+}
+
+// SharedWith lists the private and unlisted bounties userID was invited to
+// or can see as a member of their project's organization, newest first.
+func SharedWith(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Bounty, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	return queryBounties(ctx, pool, `
+SELECT `+bountyColumns+`
+FROM bounties
+WHERE id IN (
+  SELECT b.id FROM bounties b JOIN projects p ON p.id = b.project_id
+  WHERE NOT `+Listed("b")+` AND p.deleted_at IS NULL AND `+VisibleTo("b", "p", "$1")+`
+)
+ORDER BY created_at DESC
+LIMIT 200
+`, userID)
+}
+
+func queryBounties(ctx context.Context, pool *pgxpool.Pool, sql string, args ...any) ([]Bounty, error) {
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Bounty{}
+	for rows.Next() {
+		b, err := scanBounty(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// Invite is a user invited to a private or unlisted bounty.
+type Invite struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	GitHubLogin *string    `json:"github_login,omitempty"`
+	InvitedBy   *uuid.UUID `json:"invited_by,omitempty"`
+	InvitedAt   time.Time  `json:"invited_at"`
+}
+
+// Invites lists the users invited to a bounty.
+func Invites(ctx context.Context, pool *pgxpool.Pool, bountyID uuid.UUID) ([]Invite, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT i.user_id, ga.login, i.invited_by, i.invited_at
+FROM bounty_invites i
+LEFT JOIN github_accounts ga ON ga.user_id = i.user_id
+WHERE i.bounty_id = $1
+ORDER BY i.invited_at
+`, bountyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Invite{}
+	for rows.Next() {
+		var inv Invite
+		if err := rows.Scan(&inv.UserID, &inv.GitHubLogin, &inv.InvitedBy, &inv.InvitedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+// Invited reports whether userID was invited to a bounty.
+func Invited(ctx context.Context, pool *pgxpool.Pool, bountyID, userID uuid.UUID) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM bounty_invites WHERE bounty_id = $1 AND user_id = $2)`, bountyID, userID).Scan(&ok)
+	return ok, err
+}
+
+// AddInvite lets userID see a private or unlisted bounty, and puts them on
+// the claim list of a security-advisory one. Inviting someone twice is a
+// no-op.
+func AddInvite(ctx context.Context, pool *pgxpool.Pool, bountyID, userID, invitedBy uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	_, err := pool.Exec(ctx, `
+INSERT INTO bounty_invites (bounty_id, user_id, invited_by) VALUES ($1, $2, $3)
+ON CONFLICT (bounty_id, user_id) DO NOTHING
+`, bountyID, userID, invitedBy)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrInviteeNotFound
+	}
+	return err
+}
+
+// RemoveInvite withdraws a user's invite.
+func RemoveInvite(ctx context.Context, pool *pgxpool.Pool, bountyID, userID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM bounty_invites WHERE bounty_id = $1 AND user_id = $2`, bountyID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotInvited
+	}
+	return nil
+}
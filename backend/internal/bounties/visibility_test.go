@@ -0,0 +1,44 @@
+package bounties
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestLinkTokens(t *testing.T) {
+	b := Bounty{ID: uuid.New(), Visibility: VisibilityUnlisted, linkVersion: 1}
+	token := LinkToken("secret", b)
+	if !ValidLink("secret", b, token) {
+		t.Fatal("link token rejected")
+	}
+	if ValidLink("other", b, token) || ValidLink("secret", b, "") {
+		t.Fatal("link token accepted with the wrong secret or empty")
+	}
+	rotated := b
+	rotated.linkVersion++
+	if ValidLink("secret", rotated, token) {
+		t.Fatal("link token accepted after rotation")
+	}
+	other := b
+	other.ID = uuid.New()
+	if ValidLink("secret", other, token) {
+		t.Fatal("link token accepted for another bounty")
+	}
+	private := b
+	private.Visibility = VisibilityPrivate
+	if LinkToken("secret", private) != "" || ValidLink("secret", private, token) {
+		t.Fatal("private bounty has a link")
+	}
+}
+
+func TestCanViewWithoutDB(t *testing.T) {
+	ctx := context.Background()
+	if ok, err := CanView(ctx, nil, Bounty{Visibility: VisibilityPublic}, uuid.Nil); !ok || err != nil {
+		t.Fatalf("public bounty signed out = %v, %v", ok, err)
+	}
+	if ok, err := CanView(ctx, nil, Bounty{Visibility: VisibilityPrivate}, uuid.Nil); ok || err != nil {
+		t.Fatalf("private bounty signed out = %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package cache keeps short-lived in-process copies of public API responses.
+// Copies are kept coherent across API instances by a Listener that drops them
+// when the cache_outbox announces a change to the data behind them.
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Topics announced on the cache_invalidation channel. TopicPublic is never
+// announced; its caches rely on TTL alone.
+const (
+	TopicProfile = "profile"
+	TopicBounty  = "bounty"
+	TopicPublic  = "public"
+)
+
+// DefaultMaxEntries bounds a Cache made by New.
+const DefaultMaxEntries = 10000
+
+type entry struct {
+	key, variant string
+	body         []byte
+	contentType  string
+	storedAt     time.Time
+}
+
+// Cache holds responses grouped by key (e.g. a project id); every variant of
+// a key (e.g. different query strings) is dropped together. A Cache serves
+// nothing until a Listener marks it live, so an instance that can't hear
+// invalidations never serves stale copies.
+type Cache struct {
+	Topic string
+	TTL   time.Duration
+	// MaxEntries caps the stored variants; the oldest go first.
+	MaxEntries int
+
+	live atomic.Bool
+
+	mu      sync.Mutex
+	gen     uint64
+	entries map[string]map[string]*list.Element
+	// order holds every *entry, oldest first. All share one TTL, so the
+	// front is always the first to expire.
+	order  *list.List
+	hits   int64
+	misses int64
+}
+
+func New(topic string, ttl time.Duration) *Cache {
+	return &Cache{Topic: topic, TTL: ttl, MaxEntries: DefaultMaxEntries, entries: map[string]map[string]*list.Element{}, order: list.New()}
+}
+
+// Get returns the stored variant of key if it is younger than TTL.
+func (c *Cache) Get(key, variant string) ([]byte, string, bool) {
+	if c == nil || !c.live.Load() {
+		return nil, "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key][variant]
+	if !ok || time.Since(el.Value.(*entry).storedAt) >= c.TTL {
+		c.misses++
+		return nil, "", false
+	}
+	c.hits++
+	e := el.Value.(*entry)
+	return e.body, e.contentType, true
+}
+
+// Generation is read before loading a value and passed to Set, so a value
+// loaded before an invalidation is never stored after it.
+func (c *Cache) Generation() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// Set stores a variant of key unless the cache was invalidated since gen.
+func (c *Cache) Set(gen uint64, key, variant string, body []byte, contentType string) {
+	if c == nil || !c.live.Load() {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	if el, ok := c.entries[key][variant]; ok {
+		c.remove(el)
+	}
+	// Expired entries sit at the front; drop them, then the oldest live
+	// ones if the cache is still full.
+	for front := c.order.Front(); front != nil && now.Sub(front.Value.(*entry).storedAt) >= c.TTL; front = c.order.Front() {
+		c.remove(front)
+	}
+	for c.MaxEntries > 0 && c.order.Len() >= c.MaxEntries {
+		c.remove(c.order.Front())
+	}
+	if c.entries[key] == nil {
+		c.entries[key] = map[string]*list.Element{}
+	}
+	c.entries[key][variant] = c.order.PushBack(&entry{key: key, variant: variant, body: body, contentType: contentType, storedAt: now})
+}
+
+// remove drops one stored variant; c.mu must be held.
+func (c *Cache) remove(el *list.Element) {
+	e := c.order.Remove(el).(*entry)
+	variants := c.entries[e.key]
+	delete(variants, e.variant)
+	if len(variants) == 0 {
+		delete(c.entries, e.key)
+	}
+}
+
+// Invalidate drops every variant of keys.
+func (c *Cache) Invalidate(keys ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for _, k := range keys {
+		for _, el := range c.entries[k] {
+			c.remove(el)
+		}
+	}
+}
+
+// Purge drops everything.
+func (c *Cache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.entries = map[string]map[string]*list.Element{}
+	c.order.Init()
+}
+
+// Stats returns hit and miss counts.
+func (c *Cache) Stats() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// setLive enables or disables serving; either way the contents are purged,
+// since invalidations may have been missed while not listening.
+func (c *Cache) setLive(live bool) {
+	c.Purge()
+	c.live.Store(live)
+}
+
+// Middleware caches 200 responses of the wrapped GET route under key(c),
+// varying on the query parameters named in params: the handler reads no
+// others, so they can't add entries. A name also matches its operators
+// (stars[gte]), and one ending in "." matches that prefix (metadata.). An
+// empty key bypasses the cache, and a nil Cache caches nothing.
+func (c *Cache) Middleware(key func(ctx *fiber.Ctx) string, params ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if c == nil || !c.live.Load() {
+			return ctx.Next()
+		}
+		k := key(ctx)
+		if k == "" {
+			return ctx.Next()
+		}
+		variant := queryVariant(ctx, params)
+		if body, contentType, ok := c.Get(k, variant); ok {
+			ctx.Set(fiber.HeaderContentType, contentType)
+			ctx.Set("X-Cache", "HIT")
+			return ctx.Status(fiber.StatusOK).Send(body)
+		}
+		gen := c.Generation()
+		if err := ctx.Next(); err != nil {
+			return err
+		}
+		if ctx.Response().StatusCode() == fiber.StatusOK {
+			c.Set(gen, k, variant, bytes.Clone(ctx.Response().Body()), string(ctx.Response().Header.ContentType()))
+		}
+		ctx.Set("X-Cache", "MISS")
+		return nil
+	}
+}
+
+// queryVariant encodes the query parameters matching params, sorted by name.
+// Repeated parameters keep their order, since handlers differ in whether
+// they read the first or the last.
+func queryVariant(ctx *fiber.Ctx, params []string) string {
+	type pair struct{ k, v string }
+	var kept []pair
+	ctx.Request().URI().QueryArgs().VisitAll(func(k, v []byte) {
+		if readsParam(params, string(k)) {
+			kept = append(kept, pair{string(k), string(v)})
+		}
+	})
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].k < kept[j].k })
+	var b strings.Builder
+	for i, p := range kept {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(p.k))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(p.v))
+	}
+	return b.String()
+}
+
+func readsParam(params []string, name string) bool {
+	base, _, _ := strings.Cut(name, "[")
+	for _, p := range params {
+		if p == base || (strings.HasSuffix(p, ".") && strings.HasPrefix(name, p)) {
+			return true
+		}
+	}
+	return false
+}
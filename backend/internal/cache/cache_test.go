@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCacheInvalidation(t *testing.T) {
+	c := New(TopicBounty, time.Minute)
+
+	c.Set(c.Generation(), "p1", "", []byte("a"), "application/json")
+	if _, _, ok := c.Get("p1", ""); ok {
+		t.Fatal("cache served before going live")
+	}
+
+	c.setLive(true)
+	c.Set(c.Generation(), "p1", "", []byte("a"), "application/json")
+	c.Set(c.Generation(), "p1", "status=open", []byte("b"), "application/json")
+	c.Set(c.Generation(), "p2", "", []byte("c"), "application/json")
+	if body, ct, ok := c.Get("p1", "status=open"); !ok || string(body) != "b" || ct != "application/json" {
+		t.Fatalf("Get = %q, %q, %v", body, ct, ok)
+	}
+
+	c.Invalidate("p1")
+	if _, _, ok := c.Get("p1", ""); ok {
+		t.Fatal("invalidated key still served")
+	}
+	if _, _, ok := c.Get("p1", "status=open"); ok {
+		t.Fatal("invalidated variant still served")
+	}
+	if _, _, ok := c.Get("p2", ""); !ok {
+		t.Fatal("unrelated key dropped")
+	}
+
+	// A value loaded before an invalidation must not be stored after it.
+	gen := c.Generation()
+	c.Invalidate("p1")
+	c.Set(gen, "p1", "", []byte("stale"), "application/json")
+	if _, _, ok := c.Get("p1", ""); ok {
+		t.Fatal("stale load stored after invalidation")
+	}
+
+	c.setLive(false)
+	if _, _, ok := c.Get("p2", ""); ok {
+		t.Fatal("cache served after listener disconnected")
+	}
+	if hits, misses := c.Stats(); hits != 2 || misses != 3 {
+		t.Fatalf("Stats = %d hits, %d misses", hits, misses)
+	}
+}
+
+func TestListenerApply(t *testing.T) {
+	profiles := New(TopicProfile, time.Minute)
+	bounties := New(TopicBounty, time.Minute)
+	l := &Listener{}
+	l.Register(profiles, bounties)
+	l.setLive(true)
+
+	profiles.Set(profiles.Generation(), "login:octocat", "", []byte("p"), "")
+	bounties.Set(bounties.Generation(), "login:octocat", "", []byte("b"), "")
+
+	l.apply(`{"topic":"profile","keys":["u1","login:octocat"]}`)
+	if _, _, ok := profiles.Get("login:octocat", ""); ok {
+		t.Fatal("profile not invalidated")
+	}
+	if _, _, ok := bounties.Get("login:octocat", ""); !ok {
+		t.Fatal("invalidation crossed topics")
+	}
+
+	l.apply(`not json`)
+	if _, _, ok := bounties.Get("login:octocat", ""); ok {
+		t.Fatal("malformed payload did not purge")
+	}
+
+	// Caches registered while connected go live immediately.
+	late := New(TopicBounty, time.Minute)
+	l.Register(late)
+	late.Set(late.Generation(), "p", "", []byte("x"), "")
+	if _, _, ok := late.Get("p", ""); !ok {
+		t.Fatal("late cache not live")
+	}
+}
+
+func TestCacheEvictsOldest(t *testing.T) {
+	c := New(TopicPublic, time.Minute)
+	c.MaxEntries = 2
+	c.setLive(true)
+
+	c.Set(c.Generation(), "/a", "", []byte("a"), "")
+	c.Set(c.Generation(), "/b", "", []byte("b"), "")
+	c.Set(c.Generation(), "/a", "", []byte("a2"), "") // refreshes /a
+	c.Set(c.Generation(), "/c", "", []byte("c"), "")
+	if _, _, ok := c.Get("/b", ""); ok {
+		t.Error("oldest entry kept past MaxEntries")
+	}
+	if body, _, ok := c.Get("/a", ""); !ok || string(body) != "a2" {
+		t.Errorf("refreshed entry = %q, %v", body, ok)
+	}
+	if _, _, ok := c.Get("/c", ""); !ok {
+		t.Error("newest entry dropped")
+	}
+	if n := c.order.Len(); n != 2 {
+		t.Errorf("%d entries stored, want 2", n)
+	}
+
+	c.Invalidate("/a")
+	if n := c.order.Len(); n != 1 || len(c.entries) != 1 {
+		t.Errorf("after Invalidate: %d entries under %d keys", n, len(c.entries))
+	}
+}
+
+func TestMiddlewareVariant(t *testing.T) {
+	c := New(TopicPublic, time.Minute)
+	c.setLive(true)
+	app := fiber.New()
+	calls := 0
+	app.Get("/list", c.Middleware(func(ctx *fiber.Ctx) string { return ctx.Path() }, "limit", "stars", "metadata."), func(ctx *fiber.Ctx) error {
+		calls++
+		return ctx.SendString(ctx.Query("limit"))
+	})
+
+	for _, tc := range []struct {
+		query string
+		hit   bool
+	}{
+		{"?limit=5&stars[gte]=10", false},
+		// Order and parameters the handler ignores don't make new entries.
+		{"?stars[gte]=10&x=1&limit=5", true},
+		{"?limit=5&stars[gte]=10&x=2", true},
+		{"?limit=6&stars[gte]=10", false},
+		{"?limit=5&stars[gte]=10&metadata.team=core", false},
+		// Repeated values are kept in order: the handler reads the first.
+		{"?limit=7&limit=5&stars[gte]=10", false},
+	} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/list"+tc.query, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hit := resp.Header.Get("X-Cache") == "HIT"; hit != tc.hit {
+			t.Errorf("%s: hit = %v, want %v", tc.query, hit, tc.hit)
+		}
+	}
+	if calls != 4 {
+		t.Errorf("handler ran %d times, want 4", calls)
+	}
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel is the Postgres NOTIFY channel cache_outbox inserts announce on.
+const Channel = "cache_invalidation"
+
+// Message is the payload of a cache_invalidation notification. No keys means
+// everything under the topic.
+type Message struct {
+	Topic string   `json:"topic"`
+	Keys  []string `json:"keys"`
+}
+
+// Listener LISTENs on Channel over a dedicated connection and applies
+// invalidations to its registered caches. Caches are live only while it is
+// connected; on reconnect they start empty, so a missed notification can't
+// leave a stale copy behind.
+type Listener struct {
+	Pool *pgxpool.Pool
+
+	mu        sync.Mutex
+	caches    map[string][]*Cache
+	connected bool
+	events    int64
+	resets    int64
+}
+
+// Register adds caches, which go live at once if the Listener is connected.
+// Nil caches and a nil Listener are ignored.
+func (l *Listener) Register(caches ...*Cache) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.caches == nil {
+		l.caches = map[string][]*Cache{}
+	}
+	for _, c := range caches {
+		if c != nil {
+			l.caches[c.Topic] = append(l.caches[c.Topic], c)
+			c.setLive(l.connected)
+		}
+	}
+}
+
+// Run listens until ctx is done, reconnecting with backoff.
+func (l *Listener) Run(ctx context.Context) error {
+	if l.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	backoff := time.Second
+	for {
+		connected, err := l.listen(ctx)
+		l.setLive(false)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			backoff = time.Second
+		}
+		slog.Warn("cache invalidation listener disconnected; caches disabled", "retry_in", backoff.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	pc, err := l.Pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	// LISTEN state must not leak back into the pool.
+	conn := pc.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return false, err
+	}
+	l.setLive(true)
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		l.apply(n.Payload)
+	}
+}
+
+func (l *Listener) apply(payload string) {
+	var m Message
+	if err := json.Unmarshal([]byte(payload), &m); err != nil {
+		slog.Warn("malformed cache invalidation; purging all caches", "error", err)
+		l.forEach("", func(c *Cache) { c.Purge() })
+		return
+	}
+	l.mu.Lock()
+	l.events++
+	l.mu.Unlock()
+	l.forEach(m.Topic, func(c *Cache) {
+		if len(m.Keys) == 0 {
+			c.Purge()
+		} else {
+			c.Invalidate(m.Keys...)
+		}
+	})
+}
+
+func (l *Listener) setLive(live bool) {
+	l.mu.Lock()
+	l.connected = live
+	if !live {
+		l.resets++
+	}
+	l.mu.Unlock()
+	l.forEach("", func(c *Cache) { c.setLive(live) })
+}
+
+// forEach calls f for every cache under topic, or every cache when topic is
+// empty.
+func (l *Listener) forEach(topic string, f func(*Cache)) {
+	l.mu.Lock()
+	var targets []*Cache
+	for t, cs := range l.caches {
+		if topic == "" || t == topic {
+			targets = append(targets, cs...)
+		}
+	}
+	l.mu.Unlock()
+	for _, c := range targets {
+		f(c)
+	}
+}
+
+// WriteMetrics writes cache and listener counters in Prometheus text format.
+func (l *Listener) WriteMetrics(w io.Writer) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	events, resets := l.events, l.resets
+	l.mu.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP grainlify_cache_invalidations_total Cache invalidation notifications received.\n# TYPE grainlify_cache_invalidations_total counter\n")
+	fmt.Fprintf(&b, "grainlify_cache_invalidations_total %d\n", events)
+	fmt.Fprintf(&b, "# HELP grainlify_cache_listener_resets_total Times the caches were disabled because the listener was not connected.\n# TYPE grainlify_cache_listener_resets_total counter\n")
+	fmt.Fprintf(&b, "grainlify_cache_listener_resets_total %d\n", resets)
+	fmt.Fprintf(&b, "# HELP grainlify_cache_requests_total Cache lookups by topic and result.\n# TYPE grainlify_cache_requests_total counter\n")
+	l.forEach("", func(c *Cache) {
+		hits, misses := c.Stats()
+		fmt.Fprintf(&b, "grainlify_cache_requests_total{topic=%q,result=\"hit\"} %d\n", c.Topic, hits)
+		fmt.Fprintf(&b, "grainlify_cache_requests_total{topic=%q,result=\"miss\"} %d\n", c.Topic, misses)
+	})
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PruneOutbox deletes cache_outbox rows older than keep, returning how many.
+func PruneOutbox(ctx context.Context, pool *pgxpool.Pool, keep time.Duration) (int64, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM cache_outbox WHERE created_at < now() - $1 * interval '1 second'`, int64(keep.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,61 @@
+package captcha
+
+import (
+	"sync"
+	"time"
+)
+
+// Burst is the risk heuristic that decides when a client must solve a challenge:
+// more than Threshold requests from one IP inside Window.
+type Burst struct {
+	Threshold int
+	Window    time.Duration
+
+	mu   sync.Mutex
+	hits map[string][]time.Time
+	seen int
+}
+
+func NewBurst(threshold int, window time.Duration) *Burst {
+	if threshold < 0 {
+		threshold = 0
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &Burst{Threshold: threshold, Window: window, hits: map[string][]time.Time{}}
+}
+
+// Observe records a request from ip and reports whether it is over the threshold.
+// A nil Burst never fires.
+func (b *Burst) Observe(ip string) bool {
+	if b == nil {
+		return false
+	}
+	now := time.Now()
+	cutoff := now.Add(-b.Window)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recent := b.hits[ip][:0]
+	for _, t := range b.hits[ip] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+	b.hits[ip] = recent
+
+	// Opportunistic cleanup so idle IPs don't accumulate forever.
+	b.seen++
+	if b.seen%1024 == 0 {
+		for k, ts := range b.hits {
+			if len(ts) == 0 || !ts[len(ts)-1].After(cutoff) {
+				delete(b.hits, k)
+			}
+		}
+	}
+
+	return len(recent) > b.Threshold
+}
@@ -0,0 +1,254 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderAlchemy   = "alchemy"
+	ProviderHelius    = "helius"
+	ProviderQuickNode = "quicknode"
+)
+
+var (
+	ErrBadSignature       = errors.New("bad webhook signature")
+	ErrProviderDisabled   = errors.New("provider not configured")
+	ErrUnknownProvider    = errors.New("unknown provider")
+	quickNodeMaxClockSkew = 5 * time.Minute
+)
+
+// ProviderSecrets holds the per-provider verification secret. An empty secret
+// disables that provider.
+type ProviderSecrets struct {
+	AlchemySigningKey string
+	HeliusAuthHeader  string
+	QuickNodeSecret   string
+}
+
+// ParseWebhook verifies a provider delivery and returns the normalized transfers.
+func ParseWebhook(provider string, secrets ProviderSecrets, header http.Header, body []byte) ([]Transfer, error) {
+	switch provider {
+	case ProviderAlchemy:
+		if secrets.AlchemySigningKey == "" {
+			return nil, ErrProviderDisabled
+		}
+		if !validHMAC(secrets.AlchemySigningKey, body, header.Get("X-Alchemy-Signature")) {
+			return nil, ErrBadSignature
+		}
+		return parseAlchemy(body)
+
+	case ProviderHelius:
+		if secrets.HeliusAuthHeader == "" {
+			return nil, ErrProviderDisabled
+		}
+		got := header.Get("Authorization")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(secrets.HeliusAuthHeader)) != 1 {
+			return nil, ErrBadSignature
+		}
+		return parseHelius(body)
+
+	case ProviderQuickNode:
+		if secrets.QuickNodeSecret == "" {
+			return nil, ErrProviderDisabled
+		}
+		nonce := header.Get("X-QN-Nonce")
+		ts := header.Get("X-QN-Timestamp")
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return nil, ErrBadSignature
+		}
+		if d := time.Since(time.Unix(sec, 0)); d > quickNodeMaxClockSkew || d < -quickNodeMaxClockSkew {
+			return nil, ErrBadSignature
+		}
+		signed := append([]byte(nonce+ts), body...)
+		if !validHMAC(secrets.QuickNodeSecret, signed, header.Get("X-QN-Signature")) {
+			return nil, ErrBadSignature
+		}
+		return parseQuickNode(body)
+	}
+	return nil, ErrUnknownProvider
+}
+
+func validHMAC(secret string, msg []byte, gotHex string) bool {
+	gotHex = strings.TrimPrefix(strings.TrimSpace(gotHex), "sha256=")
+	if gotHex == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(msg)
+	want := hex.EncodeToString(mac.Sum(nil))
+	return subtle.ConstantTimeCompare([]byte(strings.ToLower(gotHex)), []byte(want)) == 1
+}
+
+// Alchemy "Address Activity" webhook.
+type alchemyPayload struct {
+	Type  string `json:"type"`
+	Event struct {
+		Network  string `json:"network"`
+		Activity []struct {
+			FromAddress string      `json:"fromAddress"`
+			ToAddress   string      `json:"toAddress"`
+			BlockNum    string      `json:"blockNum"`
+			Hash        string      `json:"hash"`
+			Value       json.Number `json:"value"`
+			Asset       string      `json:"asset"`
+			Category    string      `json:"category"`
+			RawContract struct {
+				Address string `json:"address"`
+			} `json:"rawContract"`
+			Log *struct {
+				LogIndex string `json:"logIndex"`
+			} `json:"log"`
+		} `json:"activity"`
+	} `json:"event"`
+}
+
+var alchemyNetworks = map[string]string{
+	"ETH_MAINNET":   "ethereum",
+	"ETH_SEPOLIA":   "sepolia",
+	"MATIC_MAINNET": "polygon",
+	"ARB_MAINNET":   "arbitrum",
+	"OPT_MAINNET":   "optimism",
+	"BASE_MAINNET":  "base",
+}
+
+func parseAlchemy(body []byte) ([]Transfer, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var p alchemyPayload
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("decode alchemy payload: %w", err)
+	}
+	chainName, ok := alchemyNetworks[p.Event.Network]
+	if !ok {
+		chainName = strings.ToLower(p.Event.Network)
+	}
+
+	var out []Transfer
+	internal := map[string]int{} // per-tx count of internal transfers seen
+	for _, a := range p.Event.Activity {
+		if a.Value == "" {
+			continue // NFTs and other valueless activity
+		}
+		asset := a.Asset
+		if a.Category == "token" || a.Category == "erc20" {
+			// Symbols are spoofable; identify tokens by contract.
+			asset = NormalizeAddress(a.RawContract.Address)
+		}
+		t := Transfer{
+			Chain:  chainName,
+			TxHash: strings.ToLower(a.Hash),
+			From:   a.FromAddress,
+			To:     a.ToAddress,
+			Asset:  asset,
+			Amount: a.Value.String(),
+		}
+		if n, err := strconv.ParseInt(strings.TrimPrefix(a.BlockNum, "0x"), 16, 64); err == nil {
+			t.BlockNumber = n
+		}
+		switch {
+		case a.Log != nil:
+			if n, err := strconv.ParseInt(strings.TrimPrefix(a.Log.LogIndex, "0x"), 16, 32); err == nil {
+				t.LogIndex = int(n)
+			}
+		case a.Category == "internal":
+			// Internal transfers have no log; number them within the
+			// transaction after the top-level value transfer at -1.
+			t.LogIndex = -2 - internal[t.TxHash]
+			internal[t.TxHash]++
+		default:
+			t.LogIndex = -1
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// Helius "enhanced transactions" webhook: an array of parsed transactions.
+type heliusTx struct {
+	Signature       string `json:"signature"`
+	Slot            int64  `json:"slot"`
+	NativeTransfers []struct {
+		FromUserAccount string `json:"fromUserAccount"`
+		ToUserAccount   string `json:"toUserAccount"`
+		Amount          int64  `json:"amount"` // lamports
+	} `json:"nativeTransfers"`
+	TokenTransfers []struct {
+		FromUserAccount string      `json:"fromUserAccount"`
+		ToUserAccount   string      `json:"toUserAccount"`
+		Mint            string      `json:"mint"`
+		TokenAmount     json.Number `json:"tokenAmount"`
+	} `json:"tokenTransfers"`
+}
+
+func parseHelius(body []byte) ([]Transfer, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var txs []heliusTx
+	if err := dec.Decode(&txs); err != nil {
+		return nil, fmt.Errorf("decode helius payload: %w", err)
+	}
+
+	var out []Transfer
+	for _, tx := range txs {
+		// Solana has no log index; number transfers within the transaction instead.
+		idx := 0
+		for _, n := range tx.NativeTransfers {
+			out = append(out, Transfer{
+				Chain:       "solana",
+				TxHash:      tx.Signature,
+				LogIndex:    idx,
+				From:        n.FromUserAccount,
+				To:          n.ToUserAccount,
+				Asset:       "SOL",
+				Amount:      lamportsToSOL(n.Amount),
+				BlockNumber: tx.Slot,
+			})
+			idx++
+		}
+		for _, tt := range tx.TokenTransfers {
+			out = append(out, Transfer{
+				Chain:       "solana",
+				TxHash:      tx.Signature,
+				LogIndex:    idx,
+				From:        tt.FromUserAccount,
+				To:          tt.ToUserAccount,
+				Asset:       tt.Mint,
+				Amount:      tt.TokenAmount.String(),
+				BlockNumber: tx.Slot,
+			})
+			idx++
+		}
+	}
+	return out, nil
+}
+
+func lamportsToSOL(lamports int64) string {
+	s := new(big.Rat).SetFrac(big.NewInt(lamports), big.NewInt(1_000_000_000)).FloatString(9)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// QuickNode Streams payloads are shaped by the stream's filter function, which
+// must emit {"transfers": [Transfer, ...]} using this package's field names.
+func parseQuickNode(body []byte) ([]Transfer, error) {
+	var p struct {
+		Transfers []Transfer `json:"transfers"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("decode quicknode payload: %w", err)
+	}
+	return p.Transfers, nil
+}
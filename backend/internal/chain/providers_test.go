@@ -0,0 +1,107 @@
+package chain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(secret string, msg []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(msg)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseAlchemy(t *testing.T) {
+	body := []byte(`{"type":"ADDRESS_ACTIVITY","event":{"network":"ETH_MAINNET","activity":[
+	 {"fromAddress":"0xAbC","toAddress":"0xDeF","blockNum":"0x10","hash":"0xAA","value":1.25,"asset":"ETH","category":"external"},
+	 {"fromAddress":"0x1","toAddress":"0x2","blockNum":"0x11","hash":"0xbb","value":100,"asset":"USDC","category":"token","rawContract":{"address":"0xA0B8"},"log":{"logIndex":"0x3"}}]}}`)
+	secrets := ProviderSecrets{AlchemySigningKey: "k"}
+
+	h := http.Header{}
+	h.Set("X-Alchemy-Signature", "deadbeef")
+	if _, err := ParseWebhook(ProviderAlchemy, secrets, h, body); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("want ErrBadSignature, got %v", err)
+	}
+
+	h.Set("X-Alchemy-Signature", sign("k", body))
+	got, err := ParseWebhook(ProviderAlchemy, secrets, h, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("want 2 transfers, got %d", len(got))
+	}
+	if got[0].Chain != "ethereum" || got[0].Amount != "1.25" || got[0].BlockNumber != 16 || got[0].TxHash != "0xaa" {
+		t.Fatalf("unexpected native transfer: %+v", got[0])
+	}
+	if got[1].Asset != "0xa0b8" || got[1].LogIndex != 3 {
+		t.Fatalf("unexpected token transfer: %+v", got[1])
+	}
+}
+
+func TestParseAlchemyTransfersWithoutLogGetDistinctIndexes(t *testing.T) {
+	body := []byte(`{"type":"ADDRESS_ACTIVITY","event":{"network":"ETH_MAINNET","activity":[
+	 {"fromAddress":"0x1","toAddress":"0x2","blockNum":"0x10","hash":"0xaa","value":1,"asset":"ETH","category":"external"},
+	 {"fromAddress":"0x3","toAddress":"0x2","blockNum":"0x10","hash":"0xaa","value":2,"asset":"ETH","category":"internal"},
+	 {"fromAddress":"0x3","toAddress":"0x2","blockNum":"0x10","hash":"0xaa","value":3,"asset":"ETH","category":"internal"},
+	 {"fromAddress":"0x1","toAddress":"0x2","blockNum":"0x10","hash":"0xaa","value":4,"asset":"USDC","category":"token","rawContract":{"address":"0xA0B8"},"log":{"logIndex":"0x0"}}]}}`)
+	got, err := parseAlchemy(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("want 4 transfers, got %d", len(got))
+	}
+	seen := map[int]bool{}
+	for _, tr := range got {
+		if seen[tr.LogIndex] {
+			t.Fatalf("duplicate log index %d in %+v", tr.LogIndex, got)
+		}
+		seen[tr.LogIndex] = true
+	}
+	if got[0].LogIndex != -1 || got[1].LogIndex != -2 || got[2].LogIndex != -3 || got[3].LogIndex != 0 {
+		t.Fatalf("unexpected indexes: %+v", got)
+	}
+}
+
+func TestParseHelius(t *testing.T) {
+	body := []byte(`[{"signature":"sig1","slot":9,"nativeTransfers":[{"fromUserAccount":"A","toUserAccount":"B","amount":1500000000}],
+	 "tokenTransfers":[{"fromUserAccount":"A","toUserAccount":"B","mint":"M","tokenAmount":2.5}]}]`)
+	h := http.Header{}
+	h.Set("Authorization", "nope")
+	if _, err := ParseWebhook(ProviderHelius, ProviderSecrets{HeliusAuthHeader: "tok"}, h, body); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("want ErrBadSignature, got %v", err)
+	}
+	h.Set("Authorization", "tok")
+	got, err := ParseWebhook(ProviderHelius, ProviderSecrets{HeliusAuthHeader: "tok"}, h, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Amount != "1.5" || got[1].LogIndex != 1 || got[1].Asset != "M" {
+		t.Fatalf("unexpected transfers: %+v", got)
+	}
+}
+
+func TestQuickNodeRejectsStaleTimestamp(t *testing.T) {
+	body := []byte(`{"transfers":[]}`)
+	ts := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	h := http.Header{}
+	h.Set("X-QN-Nonce", "n")
+	h.Set("X-QN-Timestamp", ts)
+	h.Set("X-QN-Signature", sign("s", append([]byte("n"+ts), body...)))
+	if _, err := ParseWebhook(ProviderQuickNode, ProviderSecrets{QuickNodeSecret: "s"}, h, body); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("want ErrBadSignature, got %v", err)
+	}
+}
+
+func TestDisabledProvider(t *testing.T) {
+	if _, err := ParseWebhook(ProviderAlchemy, ProviderSecrets{}, http.Header{}, nil); !errors.Is(err, ErrProviderDisabled) {
+		t.Fatalf("want ErrProviderDisabled, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Package chain is the single entry point for on-chain activity. Pollers and
+// indexer webhooks (Alchemy, Helius, QuickNode) normalize what they see into
+// Transfers and hand them to Ingest; deposit crediting and payout confirmation
+// read from chain_transfers.
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Address purposes.
+const (
+	PurposeDeposit = "deposit"
+	PurposeHot     = "hot"
+	PurposeCold    = "cold"
+)
+
+// Transfer directions relative to our watched addresses.
+const (
+	DirectionIn        = "in"
+	DirectionOut       = "out"
+	DirectionInternal  = "internal"
+	DirectionUnrelated = "unrelated"
+)
+
+type Transfer struct {
+	Chain       string `json:"chain"`
+	TxHash      string `json:"tx_hash"`
+	LogIndex    int    `json:"log_index"` // negative for native/internal EVM transfers, which have no log
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Asset       string `json:"asset"`
+	Amount      string `json:"amount"` // decimal string in whole units
+	BlockNumber int64  `json:"block_number"`
+}
+
+// NormalizeAddress lowercases hex (EVM) addresses; base58/strkey addresses are
+// case-sensitive and left alone.
+func NormalizeAddress(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if strings.HasPrefix(addr, "0x") || strings.HasPrefix(addr, "0X") {
+		return strings.ToLower(addr)
+	}
+	return addr
+}
+
+// IngestResult reports what Ingest did with a transfer.
+type IngestResult struct {
+	ID        uuid.UUID `json:"id"`
+	Inserted  bool      `json:"inserted"`
+	Direction string    `json:"direction"`
+}
+
+// InboundMatcher settles whatever a newly recorded inbound transfer pays for.
+// It runs in the transaction that records the transfer.
+type InboundMatcher func(ctx context.Context, tx pgx.Tx, transferID uuid.UUID, t Transfer) error
+
+// Ingest records a transfer once and classifies it against watched addresses.
+// Redelivery of the same (chain, tx_hash, log_index) is a no-op. A new inbound
+// transfer is handed to match before it commits, so if matching fails the
+// transfer is not recorded and the provider's retry matches it again.
+func Ingest(ctx context.Context, pool *pgxpool.Pool, provider string, t Transfer, match InboundMatcher) (IngestResult, error) {
+	if pool == nil {
+		return IngestResult{}, fmt.Errorf("db not configured")
+	}
+	t.Chain = strings.ToLower(strings.TrimSpace(t.Chain))
+	t.From = NormalizeAddress(t.From)
+	t.To = NormalizeAddress(t.To)
+	if t.Chain == "" || t.TxHash == "" || t.To == "" || t.Amount == "" {
+		return IngestResult{}, fmt.Errorf("incomplete transfer")
+	}
+
+	lookup := func(addr string) (*uuid.UUID, error) {
+		if addr == "" {
+			return nil, nil
+		}
+		var id uuid.UUID
+		err := pool.QueryRow(ctx, `SELECT id FROM watched_addresses WHERE chain = $1 AND address = $2`, t.Chain, addr).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &id, nil
+	}
+	toID, err := lookup(t.To)
+	if err != nil {
+		return IngestResult{}, err
+	}
+	fromID, err := lookup(t.From)
+	if err != nil {
+		return IngestResult{}, err
+	}
+
+	direction, watched := DirectionUnrelated, (*uuid.UUID)(nil)
+	switch {
+	case toID != nil && fromID != nil:
+		direction, watched = DirectionInternal, toID
+	case toID != nil:
+		direction, watched = DirectionIn, toID
+	case fromID != nil:
+		direction, watched = DirectionOut, fromID
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return IngestResult{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	res := IngestResult{Direction: direction}
+	err = tx.QueryRow(ctx, `
+INSERT INTO chain_transfers (chain, tx_hash, log_index, from_address, to_address, asset, amount, block_number, provider, direction, watched_address_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7::numeric, NULLIF($8, 0), $9, $10, $11)
+ON CONFLICT (chain, tx_hash, log_index) DO NOTHING
+RETURNING id
+`, t.Chain, t.TxHash, t.LogIndex, t.From, t.To, t.Asset, t.Amount, t.BlockNumber, provider, direction, watched).Scan(&res.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, nil
+	}
+	if err != nil {
+		return IngestResult{}, err
+	}
+	if direction == DirectionIn && match != nil {
+		if err := match(ctx, tx, res.ID, t); err != nil {
+			return IngestResult{}, fmt.Errorf("match inbound transfer: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return IngestResult{}, err
+	}
+	res.Inserted = true
+	return res, nil
+}
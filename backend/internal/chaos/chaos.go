@@ -0,0 +1,25 @@
+// Package chaos injects faults (request latency, dropped GitHub responses,
+// failed payout broadcasts) for staging drills of retries, circuit breakers
+// and alerting.
+//
+// The injectors only exist in binaries built with `-tags chaos`; other
+// builds compile the no-op stubs in disabled.go and ignore the settings.
+package chaos
+
+import "errors"
+
+// ErrInjected marks a fault produced by this package.
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Settings are the fault rates. Percentages are 0-100.
+type Settings struct {
+	LatencyMs         int
+	LatencyPercent    int
+	GitHubDropPercent int
+	PayoutFailPercent int
+}
+
+// Active reports whether any fault is configured.
+func (s Settings) Active() bool {
+	return (s.LatencyMs > 0 && s.LatencyPercent > 0) || s.GitHubDropPercent > 0 || s.PayoutFailPercent > 0
+}
@@ -0,0 +1,28 @@
+//go:build !chaos
+
+package chaos
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Enabled is false: this binary was built without the chaos tag.
+const Enabled = false
+
+// Configure only warns when faults are configured for a build without them.
+func Configure(env string, s Settings) {
+	if s.Active() {
+		slog.Warn("chaos settings ignored: binary built without -tags chaos")
+	}
+}
+
+func Latency() fiber.Handler {
+	return func(c *fiber.Ctx) error { return c.Next() }
+}
+
+func GitHubTransport(base http.RoundTripper) http.RoundTripper { return base }
+
+func PayoutBroadcast(chain string) error { return nil }
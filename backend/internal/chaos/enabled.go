@@ -0,0 +1,80 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Enabled is true in binaries built with the chaos tag.
+const Enabled = true
+
+var current atomic.Pointer[Settings]
+
+// Configure installs the fault rates. It refuses to arm in production.
+func Configure(env string, s Settings) {
+	if !s.Active() {
+		return
+	}
+	if env == "prod" || env == "production" {
+		slog.Error("chaos faults refused in production", "env", env)
+		return
+	}
+	current.Store(&s)
+	slog.Warn("chaos fault injection armed",
+		"latency_ms", s.LatencyMs,
+		"latency_percent", s.LatencyPercent,
+		"github_drop_percent", s.GitHubDropPercent,
+		"payout_fail_percent", s.PayoutFailPercent,
+	)
+}
+
+func roll(percent int) bool {
+	return percent > 0 && rand.IntN(100) < percent
+}
+
+// Latency delays a share of incoming requests.
+func Latency() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if s := current.Load(); s != nil && s.LatencyMs > 0 && roll(s.LatencyPercent) {
+			time.Sleep(time.Duration(rand.IntN(s.LatencyMs)+1) * time.Millisecond)
+		}
+		return c.Next()
+	}
+}
+
+type dropTransport struct {
+	base http.RoundTripper
+}
+
+func (t dropTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if s := current.Load(); s != nil && roll(s.GitHubDropPercent) {
+		return nil, fmt.Errorf("%w: dropped %s %s", ErrInjected, req.Method, req.URL.Host)
+	}
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// GitHubTransport wraps base (nil means http.DefaultTransport) so a share of
+// GitHub calls fail as if the connection dropped.
+func GitHubTransport(base http.RoundTripper) http.RoundTripper {
+	return dropTransport{base: base}
+}
+
+// PayoutBroadcast fails a share of payout broadcasts before they are sent.
+func PayoutBroadcast(chain string) error {
+	if s := current.Load(); s != nil && roll(s.PayoutFailPercent) {
+		return fmt.Errorf("%w: payout broadcast on %s", ErrInjected, chain)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Package commands parses and runs bounty chat commands for every chat
+// surface (Slack slash commands, `/bounty` GitHub issue comments) so the
+// grammar and replies stay identical; surfaces only supply context such as
+// the project or issue a comment was posted on (see Scope).
+//
+//	create [owner/repo] [#12 | owner/repo#12 | ABC-123] <amount> <asset> on <chain> [via jira|linear]
+//	list [owner/repo] [open|claimed|submitted|approved|completed|cancelled|all]
+//	subscribe [owner/repo] [created,claimed,submitted,approved,completed,cancelled,issue_closed]
+//	unsubscribe [owner/repo]
+//	help
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/issues"
+)
+
+const (
+	ActionHelp        = "help"
+	ActionCreate      = "create"
+	ActionList        = "list"
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+var ErrUsage = errors.New("usage")
+
+// Command is a parsed chat command. Project and IssueRef may be empty when
+// the surface supplies them from context.
+type Command struct {
+	Action     string
+	Project    string
+	IssueRef   string
+	Provider   string
+	Amount     string
+	Asset      string
+	Chain      string
+	Status     string
+	EventTypes []string
+}
+
+var (
+	repoRe        = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	repoIssueRe   = regexp.MustCompile(`^([A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+)#([0-9]+)$`)
+	githubIssueRe = regexp.MustCompile(`^#[0-9]+$`)
+	trackerKeyRe  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*-[0-9]+$`)
+	amountRe      = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+)
+
+var aliases = map[string]string{
+	"":            ActionHelp,
+	"help":        ActionHelp,
+	"create":      ActionCreate,
+	"new":         ActionCreate,
+	"add":         ActionCreate,
+	"list":        ActionList,
+	"ls":          ActionList,
+	"subscribe":   ActionSubscribe,
+	"unsubscribe": ActionUnsubscribe,
+}
+
+func usage(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrUsage, fmt.Sprintf(format, args...))
+}
+
+func isProjectRef(s string) bool {
+	if repoRe.MatchString(s) {
+		return true
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+// Parse parses text as typed after the command prefix. A leading "/bounty"
+// or "bounty" (as written in a GitHub comment) is ignored.
+func Parse(text string) (Command, error) {
+	fields := strings.Fields(text)
+	if len(fields) > 0 {
+		if first := strings.ToLower(fields[0]); first == "/bounty" || first == "bounty" {
+			fields = fields[1:]
+		}
+	}
+	verb := ""
+	if len(fields) > 0 {
+		verb = strings.ToLower(fields[0])
+		fields = fields[1:]
+	}
+	action, ok := aliases[verb]
+	if !ok {
+		return Command{}, usage("unknown command %q", verb)
+	}
+	cmd := Command{Action: action}
+
+	if action != ActionHelp && len(fields) > 0 {
+		if m := repoIssueRe.FindStringSubmatch(fields[0]); m != nil && action == ActionCreate {
+			cmd.Project, cmd.IssueRef, cmd.Provider = m[1], "#"+m[2], issues.ProviderGitHub
+			fields = fields[1:]
+		} else if isProjectRef(fields[0]) {
+			cmd.Project = fields[0]
+			fields = fields[1:]
+		}
+	}
+
+	switch action {
+	case ActionHelp:
+		return cmd, nil
+	case ActionCreate:
+		return parseCreate(cmd, fields)
+	case ActionList:
+		if len(fields) > 1 {
+			return Command{}, usage("list takes at most one status")
+		}
+		if len(fields) == 1 {
+			s := strings.ToLower(fields[0])
+			switch s {
+			case "all":
+			case bounties.StatusOpen, bounties.StatusClaimed, bounties.StatusSubmitted, bounties.StatusApproved,
+				bounties.StatusCompleted, bounties.StatusCancelled:
+				cmd.Status = s
+			default:
+				return Command{}, usage("unknown status %q", fields[0])
+			}
+		} else {
+			cmd.Status = bounties.StatusOpen
+		}
+		return cmd, nil
+	case ActionSubscribe:
+		types, err := bounties.ParseEventTypes(strings.Join(fields, ","))
+		if err != nil {
+			return Command{}, usage("%s", err.Error())
+		}
+		cmd.EventTypes = types
+		return cmd, nil
+	case ActionUnsubscribe:
+		if len(fields) > 0 {
+			return Command{}, usage("unexpected %q", fields[0])
+		}
+		return cmd, nil
+	}
+	return cmd, nil
+}
+
+func parseCreate(cmd Command, fields []string) (Command, error) {
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		lower := strings.ToLower(f)
+		switch {
+		case lower == "on" || lower == "via":
+			if i+1 >= len(fields) {
+				return Command{}, usage("%q needs a value", lower)
+			}
+			i++
+			if lower == "on" {
+				cmd.Chain = strings.ToLower(fields[i])
+			} else {
+				cmd.Provider = strings.ToLower(fields[i])
+			}
+		case cmd.IssueRef == "" && cmd.Amount == "" && githubIssueRe.MatchString(f):
+			cmd.IssueRef, cmd.Provider = f, issues.ProviderGitHub
+		case cmd.IssueRef == "" && cmd.Amount == "" && trackerKeyRe.MatchString(f):
+			cmd.IssueRef = strings.ToUpper(f)
+		case cmd.Amount == "" && amountRe.MatchString(f):
+			cmd.Amount = f
+		case cmd.Amount != "" && cmd.Asset == "":
+			cmd.Asset = f
+		default:
+			return Command{}, usage("unexpected %q", f)
+		}
+	}
+	if cmd.Amount == "" || cmd.Asset == "" {
+		return Command{}, usage("amount and asset are required, e.g. `100 USDC on base`")
+	}
+	if cmd.Chain == "" {
+		return Command{}, usage("chain is required, e.g. `on base`")
+	}
+	return cmd, nil
+}
+
+// Help is the reply to `help` and to usage errors.
+const Help = "Bounty commands:\n" +
+	"• `create [owner/repo] <#12|ABC-123> <amount> <asset> on <chain> [via jira|linear]` opens a bounty\n" +
+	"• `list [owner/repo] [open|claimed|submitted|approved|completed|cancelled|all]` lists bounties\n" +
+	"• `subscribe [owner/repo] [created,claimed,submitted,approved,completed,cancelled,issue_closed]` posts bounty events here\n" +
+	"• `unsubscribe [owner/repo]` stops them\n" +
+	"Without owner/repo, commands use the project in context or, for subscriptions, all of your projects."
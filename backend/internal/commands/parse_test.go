@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCreate(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Command
+	}{
+		{
+			in:   "create acme/widgets #12 100 USDC on Base",
+			want: Command{Action: ActionCreate, Project: "acme/widgets", IssueRef: "#12", Provider: "github", Amount: "100", Asset: "USDC", Chain: "base"},
+		},
+		{
+			in:   "/bounty new acme/widgets#7 2.5 ETH on ethereum",
+			want: Command{Action: ActionCreate, Project: "acme/widgets", IssueRef: "#7", Provider: "github", Amount: "2.5", Asset: "ETH", Chain: "ethereum"},
+		},
+		{
+			in:   "create acme/widgets eng-42 50 USDC on base via Linear",
+			want: Command{Action: ActionCreate, Project: "acme/widgets", IssueRef: "ENG-42", Provider: "linear", Amount: "50", Asset: "USDC", Chain: "base"},
+		},
+		{
+			// Issue and project from context, as in a GitHub comment.
+			in:   "bounty create 100 USDC on base",
+			want: Command{Action: ActionCreate, Amount: "100", Asset: "USDC", Chain: "base"},
+		},
+	}
+	for _, tc := range cases {
+		got, err := Parse(tc.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.in, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("Parse(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	got, err := Parse("list acme/widgets")
+	if err != nil || got.Action != ActionList || got.Project != "acme/widgets" || got.Status != "open" {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+	got, err = Parse("ls all")
+	if err != nil || got.Status != "" || got.Project != "" {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+}
+
+func TestParseSubscribe(t *testing.T) {
+	got, err := Parse("subscribe acme/widgets created closed")
+	if err == nil {
+		t.Fatalf("expected unknown event type error, got %+v", got)
+	}
+	got, err = Parse("subscribe acme/widgets created issue_closed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bounty.created", "bounty.issue_closed"}
+	if !reflect.DeepEqual(got.EventTypes, want) {
+		t.Fatalf("event types = %v, want %v", got.EventTypes, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"frobnicate",
+		"create acme/widgets #12 100",
+		"create acme/widgets #12 100 USDC",
+		"create acme/widgets #12 100 USDC on",
+		"list open extra",
+		"list pending",
+	} {
+		if _, err := Parse(in); !errors.Is(err, ErrUsage) {
+			t.Fatalf("Parse(%q) err = %v, want ErrUsage", in, err)
+		}
+	}
+	if got, err := Parse(""); err != nil || got.Action != ActionHelp {
+		t.Fatalf("empty input = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/issues"
+	"github.com/jagadeesh/grainlify/backend/internal/skills"
+)
+
+var (
+	ErrProjectNotFound = errors.New("project_not_found")
+	ErrProjectRequired = errors.New("project_required")
+)
+
+// Scope is what a surface knows about the caller. Actor is the Grainlify
+// user the command runs as; CanWrite is false for chat users who are not
+// mapped to Actor (e.g. other members of a Slack workspace).
+type Scope struct {
+	Actor    uuid.UUID
+	CanWrite bool
+	// ProjectID and IssueRef are filled by surfaces with context, such as
+	// the repository and issue of a GitHub comment.
+	ProjectID *uuid.UUID
+	IssueRef  string
+}
+
+// Reply is the text to post back. Public replies announce a change and suit
+// the whole channel; the rest are only meant for the caller.
+type Reply struct {
+	Text   string
+	Public bool
+}
+
+func private(text string) Reply { return Reply{Text: text} }
+
+// Runner executes commands against the bounty store.
+type Runner struct {
+	Pool           *pgxpool.Pool
+	Providers      map[string]issues.Provider
+	TokenEncKeyB64 string
+}
+
+type project struct {
+	ID       uuid.UUID
+	FullName string
+	OwnerID  uuid.UUID
+}
+
+// ResolveProject looks up a project by owner/repo or id.
+func ResolveProject(ctx context.Context, pool *pgxpool.Pool, ref string) (uuid.UUID, string, error) {
+	p, err := lookupProject(ctx, pool, ref)
+	return p.ID, p.FullName, err
+}
+
+func lookupProject(ctx context.Context, pool *pgxpool.Pool, ref string) (project, error) {
+	var p project
+	var err error
+	if id, perr := uuid.Parse(ref); perr == nil {
+		err = pool.QueryRow(ctx, `SELECT id, github_full_name, owner_user_id FROM projects WHERE id = $1`, id).Scan(&p.ID, &p.FullName, &p.OwnerID)
+	} else {
+		err = pool.QueryRow(ctx, `SELECT id, github_full_name, owner_user_id FROM projects WHERE lower(github_full_name) = lower($1)`, ref).Scan(&p.ID, &p.FullName, &p.OwnerID)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return project{}, ErrProjectNotFound
+	}
+	return p, err
+}
+
+func (r *Runner) project(ctx context.Context, s Scope, ref string) (project, error) {
+	if ref != "" {
+		return lookupProject(ctx, r.Pool, ref)
+	}
+	if s.ProjectID != nil {
+		return lookupProject(ctx, r.Pool, s.ProjectID.String())
+	}
+	return project{}, ErrProjectRequired
+}
+
+// Run executes a parsed create, list or help command and returns the reply
+// to post. Problems the caller can fix are replies, not errors; the error is
+// only set for internal failures worth logging. Subscriptions are handled by
+// the surface since they depend on where the command was typed.
+func (r *Runner) Run(ctx context.Context, s Scope, cmd Command) (Reply, error) {
+	if r.Pool == nil {
+		return private("Grainlify is not available right now."), fmt.Errorf("db not configured")
+	}
+	switch cmd.Action {
+	case ActionCreate:
+		return r.create(ctx, s, cmd)
+	case ActionList:
+		return r.list(ctx, s, cmd)
+	case ActionHelp:
+		return private(Help), nil
+	}
+	return private("That command is not supported here.\n\n" + Help), nil
+}
+
+func projectReply(err error, ref string) (Reply, error) {
+	switch {
+	case errors.Is(err, ErrProjectNotFound):
+		return private(fmt.Sprintf("No Grainlify project %q.", ref)), nil
+	case errors.Is(err, ErrProjectRequired):
+		return private("Which project? Add `owner/repo` to the command."), nil
+	}
+	return private("Could not look up the project."), err
+}
+
+func (r *Runner) list(ctx context.Context, s Scope, cmd Command) (Reply, error) {
+	p, err := r.project(ctx, s, cmd.Project)
+	if err != nil {
+		return projectReply(err, cmd.Project)
+	}
+	out, _, err := bounties.ListForProject(ctx, r.Pool, p.ID, cmd.Status, nil, bounties.ListSpec.Default())
+	if err != nil {
+		return private("Could not list bounties."), err
+	}
+	label := cmd.Status
+	if label == "" {
+		label = "all"
+	}
+	if len(out) == 0 {
+		return private(fmt.Sprintf("No %s bounties on %s.", label, p.FullName)), nil
+	}
+	const max = 20
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s bounties on %s:\n", strings.ToUpper(label[:1])+label[1:], p.FullName)
+	for i, bounty := range out {
+		if i == max {
+			fmt.Fprintf(&b, "…and %d more\n", len(out)-max)
+			break
+		}
+		fmt.Fprintf(&b, "• %s\n", FormatBounty(bounty))
+	}
+	return private(strings.TrimRight(b.String(), "\n")), nil
+}
+
+func (r *Runner) create(ctx context.Context, s Scope, cmd Command) (Reply, error) {
+	if !s.CanWrite {
+		return private("Only the account that connected Grainlify can create bounties."), nil
+	}
+	p, err := r.project(ctx, s, cmd.Project)
+	if err != nil {
+		return projectReply(err, cmd.Project)
+	}
+	if p.OwnerID != s.Actor {
+		var role string
+		_ = r.Pool.QueryRow(ctx, `SELECT role FROM users WHERE id = $1`, s.Actor).Scan(&role)
+		if role != "admin" {
+			return private(fmt.Sprintf("You are not the owner of %s.", p.FullName)), nil
+		}
+	}
+
+	ref, provider := cmd.IssueRef, cmd.Provider
+	if ref == "" && cmd.Project == "" {
+		ref = s.IssueRef
+	}
+	if ref == "" {
+		return private("Which issue? Add `#12` or a tracker key such as `ABC-123`."), nil
+	}
+	if provider == "" && !strings.HasPrefix(ref, "#") {
+		if provider, err = r.trackerFor(ctx, p.OwnerID); err != nil {
+			return private("Link Jira or Linear, or add `via jira|linear`, to use tracker keys."), nil
+		}
+	}
+
+	iss, accountID, err := issues.Resolve(ctx, r.Pool, r.Providers, r.TokenEncKeyB64, p.ID, provider, ref)
+	switch {
+	case errors.Is(err, issues.ErrIssueNotFound):
+		return private(fmt.Sprintf("Issue %s not found on %s.", ref, p.FullName)), nil
+	case errors.Is(err, issues.ErrUnknownProvider):
+		return private(fmt.Sprintf("Unknown issue provider %q.", provider)), nil
+	case errors.Is(err, issues.ErrProviderDisabled):
+		return private(fmt.Sprintf("%s is not enabled on this Grainlify instance.", provider)), nil
+	case errors.Is(err, issues.ErrNotLinked):
+		return private(fmt.Sprintf("The owner of %s has not linked %s.", p.FullName, provider)), nil
+	case err != nil:
+		return private("Could not look up the issue, try again shortly."), err
+	}
+	if iss.Closed {
+		return private(fmt.Sprintf("%s is closed.", iss.Key)), nil
+	}
+
+	b, err := bounties.Create(ctx, r.Pool, p.ID, s.Actor, iss, accountID, cmd.Chain, cmd.Asset, cmd.Amount, nil, bounties.VisibilityPublic)
+	if errors.Is(err, bounties.ErrAlreadyOpen) {
+		return private(fmt.Sprintf("%s already has an open bounty.", iss.Key)), nil
+	}
+	if err != nil {
+		slog.Warn("chat command bounty create failed", "project_id", p.ID.String(), "issue", iss.Key, "error", err)
+		return private("Could not create the bounty: " + err.Error()), nil
+	}
+	b = (&skills.Detector{Pool: r.Pool, TokenEncKeyB64: r.TokenEncKeyB64}).TagBounty(ctx, b)
+	return Reply{Text: "Bounty created: " + FormatBounty(b), Public: true}, nil
+}
+
+// trackerFor picks the owner's only linked tracker so `via` can be omitted.
+func (r *Runner) trackerFor(ctx context.Context, ownerID uuid.UUID) (string, error) {
+	accounts, err := issues.ListAccounts(ctx, r.Pool, ownerID)
+	if err != nil {
+		return "", err
+	}
+	if len(accounts) != 1 {
+		return "", issues.ErrNotLinked
+	}
+	return accounts[0].Provider, nil
+}
+
+// FormatBounty renders a bounty on one line; URLs are left bare so every
+// surface autolinks them.
+func FormatBounty(b bounties.Bounty) string {
+	line := fmt.Sprintf("%s %s %s on %s", b.Issue.Key, b.Amount, b.Asset, b.Chain)
+	if b.Issue.Title != "" {
+		line += " — " + b.Issue.Title
+	}
+	if b.Status != bounties.StatusOpen {
+		line += " (" + b.Status + ")"
+	}
+	if b.Issue.URL != "" {
+		line += " " + b.Issue.URL
+	}
+	return line
+}
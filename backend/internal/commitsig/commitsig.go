@@ -0,0 +1,215 @@
+// Package commitsig checks that commits credited to contributors are signed
+// with a key on their own GitHub account (the one linked to Grainlify), as
+// verified by GitHub, and turns the results into a trust level.
+package commitsig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// Signature statuses of a checked commit.
+const (
+	// StatusVerified: GitHub verified the signature and the credited author
+	// is also the committer, so the key is on the author's account.
+	StatusVerified = "verified"
+	// StatusOtherSigner: a valid signature by someone else, such as GitHub's
+	// own key on web merges.
+	StatusOtherSigner = "other_signer"
+	StatusUnsigned    = "unsigned"
+	// StatusInvalid: signed, but GitHub couldn't verify it (unknown key,
+	// email mismatch, expired key, ...).
+	StatusInvalid = "invalid"
+	// StatusUnavailable: GitHub no longer serves the commit.
+	StatusUnavailable = "unavailable"
+)
+
+// Trust levels derived from a contributor's checked commits.
+const (
+	TrustNone   = "none"
+	TrustLow    = "low"
+	TrustMedium = "medium"
+	TrustHigh   = "high"
+)
+
+// defaultBatch is how many commits one RunOnce checks.
+const defaultBatch = 100
+
+// Classify returns the status of sig for a commit credited to login.
+func Classify(sig github.CommitSignature, login string) string {
+	switch {
+	case sig.Verified && strings.EqualFold(sig.CommitterLogin, login):
+		return StatusVerified
+	case sig.Verified:
+		return StatusOtherSigner
+	case sig.Reason == "unsigned" || sig.Reason == "":
+		return StatusUnsigned
+	default:
+		return StatusInvalid
+	}
+}
+
+// TrustLevel grades a contributor by how many of their checked commits
+// were verified. High needs at least five verified commits.
+func TrustLevel(verified, checked int) string {
+	switch {
+	case checked == 0:
+		return TrustNone
+	case verified >= 5 && verified*10 >= checked*9:
+		return TrustHigh
+	case verified*2 >= checked:
+		return TrustMedium
+	default:
+		return TrustLow
+	}
+}
+
+// Summary counts a set of commits by status.
+type Summary struct {
+	Commits  int `json:"commits"`
+	Verified int `json:"verified"`
+}
+
+// AllVerified reports whether there is at least one commit and all are verified.
+func (s Summary) AllVerified() bool { return s.Commits > 0 && s.Verified == s.Commits }
+
+// SummarizePR classifies the commits of a pull request authored by login;
+// commits by anyone else are not login's and are skipped.
+func SummarizePR(sigs []github.CommitSignature, login string) Summary {
+	var s Summary
+	for _, sig := range sigs {
+		if !strings.EqualFold(sig.AuthorLogin, login) {
+			continue
+		}
+		s.Commits++
+		if Classify(sig, login) == StatusVerified {
+			s.Verified++
+		}
+	}
+	return s
+}
+
+// Checker checks pushed commits credited to linked contributors, using each
+// contributor's own GitHub token.
+type Checker struct {
+	Pool           *pgxpool.Pool
+	GitHub         *github.Client
+	TokenEncKeyB64 string
+	// Batch defaults to 100 commits per run.
+	Batch int
+}
+
+type pendingCommit struct {
+	projectID uuid.UUID
+	sha       string
+	fullName  string
+	userID    uuid.UUID
+	login     string
+}
+
+// RunOnce checks the oldest unchecked commits and returns how many it
+// recorded. It stops at the first GitHub error other than a missing commit,
+// leaving the rest for the next run.
+func (c *Checker) RunOnce(ctx context.Context) (int, error) {
+	if c.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	gh := c.GitHub
+	if gh == nil {
+		gh = github.NewClient()
+	}
+	batch := c.Batch
+	if batch <= 0 {
+		batch = defaultBatch
+	}
+	rows, err := c.Pool.Query(ctx, `
+SELECT gc.project_id, gc.sha, p.github_full_name, ga.user_id, ga.login
+FROM github_commits gc
+JOIN projects p ON p.id = gc.project_id
+JOIN github_accounts ga ON lower(ga.login) = lower(gc.author_login)
+WHERE gc.signature_checked_at IS NULL
+ORDER BY gc.created_at
+LIMIT $1
+`, batch)
+	if err != nil {
+		return 0, err
+	}
+	var pending []pendingCommit
+	for rows.Next() {
+		var p pendingCommit
+		if err := rows.Scan(&p.projectID, &p.sha, &p.fullName, &p.userID, &p.login); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tokens := map[uuid.UUID]string{}
+	n := 0
+	for _, p := range pending {
+		token, ok := tokens[p.userID]
+		if !ok {
+			linked, err := github.GetLinkedAccount(ctx, c.Pool, p.userID, c.TokenEncKeyB64)
+			if err != nil {
+				slog.Warn("commit signature check: no github token", "user_id", p.userID.String(), "error", err)
+			}
+			token = linked.AccessToken
+			tokens[p.userID] = token
+		}
+
+		status, reason := StatusUnavailable, ""
+		sig, err := gh.GetCommitSignature(ctx, token, p.fullName, p.sha)
+		var apiErr *github.GitHubAPIError
+		switch {
+		case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusUnprocessableEntity):
+			reason = fmt.Sprintf("github status %d", apiErr.StatusCode)
+		case err != nil:
+			return n, err
+		default:
+			status, reason = Classify(sig, p.login), sig.Reason
+		}
+		if _, err := c.Pool.Exec(ctx, `
+UPDATE github_commits
+SET signature_status = $3, signature_reason = NULLIF($4, ''), signature_checked_at = now()
+WHERE project_id = $1 AND sha = $2
+`, p.projectID, p.sha, status, reason); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// CheckPR summarizes the signatures on userID's commits in a pull request,
+// read with their linked GitHub token. linked is false when userID has no
+// GitHub account, so no commit can be credited to them.
+func CheckPR(ctx context.Context, pool *pgxpool.Pool, gh *github.Client, tokenEncKeyB64 string, userID uuid.UUID, fullName string, number int) (s Summary, linked bool, err error) {
+	account, err := github.GetLinkedAccount(ctx, pool, userID, tokenEncKeyB64)
+	if err != nil {
+		if err.Error() == "github_not_linked" {
+			return Summary{}, false, nil
+		}
+		return Summary{}, false, err
+	}
+	if gh == nil {
+		gh = github.NewClient()
+	}
+	sigs, err := gh.ListPRCommitSignatures(ctx, account.AccessToken, fullName, number)
+	if err != nil {
+		return Summary{}, true, err
+	}
+	return SummarizePR(sigs, account.Login), true, nil
+}
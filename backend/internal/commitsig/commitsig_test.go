@@ -0,0 +1,57 @@
+package commitsig
+
+import (
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		sig  github.CommitSignature
+		want string
+	}{
+		{github.CommitSignature{Verified: true, CommitterLogin: "Alice", Reason: "valid"}, StatusVerified},
+		{github.CommitSignature{Verified: true, CommitterLogin: "web-flow", Reason: "valid"}, StatusOtherSigner},
+		{github.CommitSignature{Reason: "unsigned"}, StatusUnsigned},
+		{github.CommitSignature{Reason: "unknown_key"}, StatusInvalid},
+	}
+	for _, tc := range cases {
+		if got := Classify(tc.sig, "alice"); got != tc.want {
+			t.Errorf("Classify(%+v) = %q, want %q", tc.sig, got, tc.want)
+		}
+	}
+}
+
+func TestTrustLevel(t *testing.T) {
+	cases := []struct {
+		verified, checked int
+		want              string
+	}{
+		{0, 0, TrustNone},
+		{4, 4, TrustMedium},
+		{9, 10, TrustHigh},
+		{8, 10, TrustMedium},
+		{1, 3, TrustLow},
+	}
+	for _, tc := range cases {
+		if got := TrustLevel(tc.verified, tc.checked); got != tc.want {
+			t.Errorf("TrustLevel(%d, %d) = %q, want %q", tc.verified, tc.checked, got, tc.want)
+		}
+	}
+}
+
+func TestSummarizePR(t *testing.T) {
+	sigs := []github.CommitSignature{
+		{AuthorLogin: "alice", CommitterLogin: "alice", Verified: true},
+		{AuthorLogin: "alice", CommitterLogin: "alice", Reason: "unsigned"},
+		{AuthorLogin: "bob", CommitterLogin: "bob", Verified: true},
+	}
+	s := SummarizePR(sigs, "Alice")
+	if s.Commits != 2 || s.Verified != 1 || s.AllVerified() {
+		t.Fatalf("SummarizePR = %+v", s)
+	}
+	if (Summary{}).AllVerified() {
+		t.Fatal("empty summary counts as verified")
+	}
+}
@@ -11,11 +11,21 @@ type Config struct {
 	Env      string
 	HTTPAddr string
 	Log      string
+	// AppRole is the default process mode (api, worker or all); the --mode
+	// flag overrides it.
+	AppRole string
+	// On SIGTERM the process fails readiness for ShutdownDrainSeconds so load
+	// balancers stop routing to it, then gives in-flight requests and running
+	// jobs up to ShutdownTimeoutSeconds to finish.
+	ShutdownDrainSeconds   int
+	ShutdownTimeoutSeconds int
 
 	DBURL       string
 	AutoMigrate bool
 
 	JWTSecret string
+	// Lifetime of rotating refresh tokens issued at wallet sign-in.
+	RefreshTokenTTLHours int
 
 	NATSURL string
 
@@ -31,6 +41,98 @@ type Config struct {
 	GitHubAppSlug       string // GitHub App slug (e.g., "grainlify")
 	GitHubAppPrivateKey string // GitHub App private key (PEM format, base64 encoded)
 
+	// OAuth apps for Jira Cloud (3LO) and Linear issue sources. Redirect URLs
+	// point at /auth/issues/{jira,linear}/callback.
+	JiraOAuthClientID       string
+	JiraOAuthClientSecret   string
+	JiraOAuthRedirectURL    string
+	LinearOAuthClientID     string
+	LinearOAuthClientSecret string
+	LinearOAuthRedirectURL  string
+
+	// Slack app: OAuth install per workspace (redirect at /auth/slack/callback),
+	// slash commands verified with the signing secret, and channel
+	// notifications every SlackNotifyIntervalSeconds (0 disables them).
+	SlackClientID              string
+	SlackClientSecret          string
+	SlackSigningSecret         string
+	SlackRedirectURL           string
+	SlackNotifyIntervalSeconds int
+
+	// Notification dispatcher (Matrix and other channels); 0 disables it.
+	NotifyIntervalSeconds int
+
+	// User notifications (in-app, and email when SMTP is configured) every
+	// NotificationsIntervalSeconds; 0 disables them.
+	NotificationsIntervalSeconds int
+	// NotificationTemplatesDir, in dev, is read for notification templates
+	// instead of the built-in ones and reloaded when they change.
+	NotificationTemplatesDir string
+
+	// Security-advisory bounties: disclosing those whose embargo ended and
+	// mirroring them to GitHub Security Advisories; 0 disables it.
+	AdvisorySyncIntervalSeconds int
+
+	// Outbound webhook dispatcher (/me/webhooks); 0 disables it.
+	WebhookDispatchIntervalSeconds int
+
+	// Proof-of-payment deliveries to projects' accounting endpoints; 0
+	// disables them.
+	PaymentProofIntervalSeconds int
+
+	// Automated /status component checks; 0 leaves components to admins.
+	StatusCheckIntervalSeconds int
+
+	// Synthetic probes of the critical path against this process, exported at
+	// /metrics (bearer MetricsToken when set). The login probe only runs with
+	// a dedicated ed25519 probe wallet seed (hex); 0 interval disables probes.
+	ProbeIntervalSeconds     int
+	ProbeWalletSeedHex       string
+	ProbeMirrorMaxAgeMinutes int
+	MetricsToken             string
+
+	// OpenTelemetry tracing, exported over OTLP/HTTP to OTelEndpoint (e.g.
+	// http://tempo:4318); empty disables it. OTelSamplePercent of new traces
+	// are kept; requests arriving with a sampled traceparent always are.
+	OTelEndpoint      string
+	OTelServiceName   string
+	OTelSamplePercent int
+
+	// Profiling and load testing. /debug/pprof is mounted only when
+	// PprofToken is set and requires it as a bearer token. A non-empty
+	// LoadtestRecordPath appends anonymized request records there for
+	// replay with cmd/loadtest.
+	PprofToken         string
+	LoadtestRecordPath string
+
+	// Load shedding on Postgres pool saturation: low-priority routes get 503
+	// when the average acquire wait or waiting acquires per second cross
+	// these thresholds (0 disables each).
+	ShedWaitThresholdMs   int
+	ShedQueueThreshold    int
+	ShedRetryAfterSeconds int
+
+	// How long a response to a request with an Idempotency-Key is replayed
+	// to retries of the key.
+	IdempotencyKeyTTLHours int
+
+	// How long GET /me/wallets/:id/balance reuses an RPC balance lookup.
+	WalletBalanceCacheSeconds int
+
+	// How long public profiles and bounty lists are served from memory.
+	// Copies are dropped cluster-wide as soon as the data changes; 0 disables.
+	CacheTTLSeconds int
+	// How long /auth/me reuses a user's GitHub profile; re-linking or
+	// refreshing drops it at once. 0 disables.
+	GitHubProfileCacheSeconds int
+
+	// Fault injection for staging drills. Only binaries built with
+	// `-tags chaos` act on these; production (APP_ENV=prod) refuses them.
+	ChaosLatencyMs         int
+	ChaosLatencyPercent    int
+	ChaosGitHubDropPercent int
+	ChaosPayoutFailPercent int
+
 	// Used to validate GitHub webhook signatures (X-Hub-Signature-256).
 	GitHubWebhookSecret string
 
@@ -41,16 +143,223 @@ type Config struct {
 	// Used for OAuth redirects and CORS configuration
 	FrontendBaseURL string
 
-	// Allowed CORS origins (comma-separated). If empty, uses FrontendBaseURL
-	// Example: "http://localhost:5173,https://grainlify.figma.site"
-	CORSOrigins string
+	// Browser origins allowed to call the API; OAuth redirects must go to
+	// one of them too.
+	CORS CORS
+
+	// Strict-Transport-Security max-age sent on every response; 0 leaves the
+	// header out. It defaults to two years outside dev.
+	HSTSMaxAgeSeconds int
+
+	// Load balancers in front of the API, as IPs or CIDRs. Only requests
+	// from them take their client IP from ProxyHeader, which must be a header
+	// the proxy overwrites rather than appends to; the rest use the socket
+	// address. Empty trusts no proxy. Rate limits, sessions and audit logs
+	// all key on this IP.
+	TrustedProxies []string
+	ProxyHeader    string
 
 	// Used to encrypt stored OAuth access tokens at rest. Must be 32 bytes base64 (AES-256-GCM key).
 	TokenEncKeyB64 string
+	// Further versioned keys for GitHub tokens, "version:base64" comma
+	// separated, for rotating TokenEncKeyB64 (version 0) without downtime.
+	TokenEncKeys string
+	// Version new GitHub tokens are sealed under; -1 means the highest.
+	TokenEncKeyActive int
 
 	// Dev/admin convenience: allow promoting a logged-in user to admin via a shared token.
 	AdminBootstrapToken string
 
+	// Optional CAPTCHA on nonce issuance ("turnstile" or "recaptcha"; empty disables).
+	// A challenge is only demanded once an IP exceeds CaptchaNonceThreshold nonces per minute.
+	CaptchaProvider       string
+	CaptchaSecret         string
+	CaptchaSiteKey        string
+	CaptchaNonceThreshold int
+
+	// Optional hashcash-style puzzle (leading zero bits) attached to every nonce and
+	// checked on verify. Bursty IPs may opt for the escalated puzzle instead of a CAPTCHA.
+	AuthPoWDifficulty          int
+	AuthPoWEscalatedDifficulty int
+
+	// Rate limits on /auth/nonce and /auth/verify, per client IP and per wallet
+	// address, each per AuthRateLimitWindowSeconds (0 disables a limit).
+	// Counters are shared through RedisURL when set, else kept in memory.
+	AuthRateLimitPerIP         int
+	AuthRateLimitPerAddress    int
+	AuthRateLimitWindowSeconds int
+	RedisURL                   string
+
+	// The unauthenticated /public/v1 API has its own per-IP tier (0
+	// disables) and is cached for PublicCacheSeconds in memory and by CDNs.
+	PublicRateLimitPerIP         int
+	PublicRateLimitWindowSeconds int
+	PublicCacheSeconds           int
+
+	// Token buckets API keys draw from, per plan tier of the key's owner, as
+	// "tier=per_minute/burst,..." (see ratelimit.ParseTiers). Empty disables.
+	APIKeyRateLimitTiers string
+
+	// Abuse reports each user may file per hour (0 disables the limit).
+	ReportRateLimitPerHour int
+
+	// Request header carrying the client's IP country as resolved by the edge
+	// (e.g. Cloudflare's CF-IPCountry). Empty disables IP-based geo checks.
+	// Only set it when every request passes through that edge.
+	GeoIPCountryHeader string
+
+	// Outgoing email (verification codes, recovery notices, notifications).
+	// Unset SMTPHost or MailFrom disables email, and with it account recovery.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
+	// Account recovery lets a user with a verified email bind a new wallet
+	// after AccountRecoveryDelayHours, during which any signed-in session
+	// can cancel it. Requests are limited per IP and per email.
+	AccountRecoveryEnabled      bool
+	AccountRecoveryDelayHours   int
+	AccountRecoveryLimitPerHour int
+
+	// Sign-In with Ethereum (EIP-4361). Domain and URI default to
+	// FrontendBaseURL; AuthRequireSIWE rejects legacy EVM login messages.
+	// SIWEChainIDs defaults to the chains in EVMRPCURLs, or mainnet.
+	SIWEDomain      string
+	SIWEURI         string
+	SIWEStatement   string
+	SIWEChainIDs    []string
+	AuthRequireSIWE bool
+
+	// Signed+encrypted ledger snapshots (see cmd/backup). BackupIntervalMinutes > 0
+	// also schedules them from the API process.
+	BackupEncKeyB64       string
+	BackupDir             string
+	BackupTables          []string
+	BackupIntervalMinutes int
+	// Snapshots go to an S3-compatible bucket instead of BackupDir when
+	// BackupS3Bucket is set.
+	BackupS3Endpoint  string
+	BackupS3Region    string
+	BackupS3Bucket    string
+	BackupS3Prefix    string
+	BackupS3AccessKey string
+	BackupS3SecretKey string
+	BackupS3Insecure  bool
+
+	// Ed25519 seed (base64, 32 bytes) used to sign published ledger Merkle roots.
+	LedgerAnchorKeyB64          string
+	LedgerAnchorIntervalMinutes int
+
+	// Chain indexer webhooks; an empty secret disables that provider's endpoint.
+	AlchemyWebhookSigningKey string
+	HeliusWebhookAuthHeader  string
+	QuickNodeWebhookSecret   string
+
+	// HD deposit addresses: account-level xpub (m/44'/60'/0'/0) for EVM chains and a
+	// SLIP-10 seed for Stellar/Solana. Unused addresses are capped at DepositGapLimit.
+	DepositEVMXPub        string
+	DepositEd25519SeedB64 string
+	DepositGapLimit       int
+	DepositIntentTTLHours int
+
+	// Hot wallets used for sweeps and payouts. EVMRPCURLs is "chain=url,chain=url";
+	// one EVM key is used on every listed chain. EVMSmartAccounts ("chain=0x...")
+	// names an ERC-4337 account owned by that key to hold funds and batch sends.
+	StellarHotWalletSecret string
+	EVMHotWalletKeyHex     string
+	EVMRPCURLs             string
+	EVMSmartAccounts       string
+	SweepIntervalMinutes   int
+
+	// Payout batching: pending payouts per chain/asset are sent together every
+	// interval, at most PayoutMaxBatch per transaction (further capped per chain).
+	// Once admins define payout windows, the interval only checks for a due window.
+	PayoutBatchIntervalMinutes int
+	PayoutMaxBatch             int
+	// Submitted payouts are checked on chain every PayoutConfirmIntervalMinutes
+	// (0 disables) and marked confirmed, or failed if their transaction
+	// reverted. EVM transactions need EVMPayoutConfirmations blocks.
+	PayoutConfirmIntervalMinutes int
+	EVMPayoutConfirmations       int
+	// Deposits into project escrow addresses are credited every
+	// ProjectEscrowWatchIntervalMinutes (0 disables); Stellar addresses are
+	// polled on Horizon, other chains come from indexer webhooks.
+	ProjectEscrowWatchIntervalMinutes int
+	// RelayerFees lists tokens the gasless relayer accepts and its flat fee,
+	// as "chain/token=amount,...". Relaying needs an EVM smart account.
+	RelayerFees string
+	// Payout method fallback: pending payouts go to the first method a user
+	// ranked whose chain has a hot wallet, is under PayoutCongestionPercent
+	// load and whose minimum the amount meets. PayoutAssetGroups lists
+	// interchangeable assets as "chain/asset,chain/asset;..." (e.g. USDC on
+	// each chain); PayoutMinimums is "chain/asset=amount,..."; EVM load is the
+	// gas price against PayoutMaxGasGwei, "chain=gwei,...".
+	PayoutAssetGroups       string
+	PayoutMinimums          string
+	PayoutMaxGasGwei        string
+	PayoutCongestionPercent int
+	// PayoutThresholds, "chain/asset=amount,...", is the smallest payout sent
+	// on its own; smaller ones accrue until the user's balance crosses it or
+	// a new month begins.
+	PayoutThresholds string
+
+	// Achievement NFTs: badges are minted as soulbound ERC-721s on this EVM
+	// chain when both chain and contract are set. The hot wallet key must hold
+	// the contract's minter role. Token URIs are AchievementNFTBaseURI + id.
+	AchievementNFTChain            string
+	AchievementNFTContract         string
+	AchievementNFTBaseURI          string
+	AchievementMintIntervalMinutes int
+
+	// EAS attestations of paid bounties, published from the EVM hot wallet key.
+	// Register attest.SchemaDefinition on the chain and set its UID here.
+	EASChain              string
+	EASContract           string
+	EASSchemaUID          string
+	AttestIntervalMinutes int
+
+	// GitHub Sponsors sync for connected maintainer accounts; 0 disables the
+	// schedule (webhooks still apply).
+	SponsorsSyncIntervalMinutes int
+
+	// Workers per process draining the background job queue, and how stale
+	// a linked user's synced GitHub profile/repos/stats may get before it is
+	// re-queued (0 disables the refresh schedule; linking still syncs).
+	JobQueueWorkers                  int
+	GitHubProfileSyncIntervalMinutes int
+
+	// Commit signature checks: pushed commits credited to linked
+	// contributors are checked every CommitSignatureCheckIntervalMinutes (0
+	// disables), and payouts of at least UnsignedPayoutReviewAmount for a
+	// pull request with unsigned commits get a fraud review (empty disables).
+	CommitSignatureCheckIntervalMinutes int
+	UnsignedPayoutReviewAmount          string
+
+	// Repo health for funders: review, response and CI metrics over the last
+	// RepoHealthWindowDays, recomputed every RepoHealthIntervalMinutes (0
+	// disables the refresh; projects are then computed when first viewed).
+	RepoHealthIntervalMinutes int
+	RepoHealthWindowDays      int
+
+	// The weekly, monthly and all-time contributor leaderboards are
+	// recomputed every LeaderboardIntervalMinutes (0 disables the refresh,
+	// leaving them empty).
+	LeaderboardIntervalMinutes int
+
+	// Members of linked GitHub organizations are re-synced from GitHub once
+	// older than GitHubOrgSyncIntervalMinutes (0 disables the refresh; links
+	// and manual syncs still sync).
+	GitHubOrgSyncIntervalMinutes int
+
+	// Escrow-funded bounties: maintainers have EscrowDeadlineDays to lock the
+	// reward (the contract lets them refund it after), and approved releases
+	// are submitted every EscrowReleaseIntervalMinutes (0 disables). The
+	// contract is ESCROW_CONTRACT_ID, released with SOROBAN_SOURCE_SECRET.
+	EscrowDeadlineDays           int
+	EscrowReleaseIntervalMinutes int
+
 	// Didit KYC verification
 	DiditAPIKey        string
 	DiditWorkflowID    string
@@ -68,6 +377,12 @@ type Config struct {
 
 func Load() Config {
 	env := getEnv("APP_ENV", "dev")
+	// In dev the API runs on localhost, which HSTS would pin to HTTPS for
+	// every local app.
+	hstsDefault := 2 * 365 * 24 * 60 * 60
+	if env == "dev" {
+		hstsDefault = 0
+	}
 	logLevel := getEnv("LOG_LEVEL", "info")
 
 	// Prefer HTTP_ADDR if provided, otherwise build it from PORT.
@@ -81,11 +396,16 @@ func Load() Config {
 		Env:      env,
 		HTTPAddr: httpAddr,
 		Log:      logLevel,
+		AppRole:  getEnv("APP_ROLE", "all"),
+
+		ShutdownDrainSeconds:   getEnvInt("SHUTDOWN_DRAIN_SECONDS", 0),
+		ShutdownTimeoutSeconds: getEnvInt("SHUTDOWN_TIMEOUT_SECONDS", 25),
 
 		DBURL:       getEnv("DB_URL", ""),
 		AutoMigrate: getEnvBool("AUTO_MIGRATE", false),
 
-		JWTSecret: getEnv("JWT_SECRET", ""),
+		JWTSecret:            getEnv("JWT_SECRET", ""),
+		RefreshTokenTTLHours: getEnvInt("REFRESH_TOKEN_TTL_HOURS", 720),
 
 		NATSURL: getEnv("NATS_URL", ""),
 
@@ -100,17 +420,185 @@ func Load() Config {
 		GitHubAppSlug:       getEnv("GITHUB_APP_SLUG", ""),
 		GitHubAppPrivateKey: getEnv("GITHUB_APP_PRIVATE_KEY", ""),
 
+		JiraOAuthClientID:       getEnv("JIRA_OAUTH_CLIENT_ID", ""),
+		JiraOAuthClientSecret:   getEnv("JIRA_OAUTH_CLIENT_SECRET", ""),
+		JiraOAuthRedirectURL:    getEnv("JIRA_OAUTH_REDIRECT_URL", ""),
+		LinearOAuthClientID:     getEnv("LINEAR_OAUTH_CLIENT_ID", ""),
+		LinearOAuthClientSecret: getEnv("LINEAR_OAUTH_CLIENT_SECRET", ""),
+		LinearOAuthRedirectURL:  getEnv("LINEAR_OAUTH_REDIRECT_URL", ""),
+
+		SlackClientID:              getEnv("SLACK_CLIENT_ID", ""),
+		SlackClientSecret:          getEnv("SLACK_CLIENT_SECRET", ""),
+		SlackSigningSecret:         getEnv("SLACK_SIGNING_SECRET", ""),
+		SlackRedirectURL:           getEnv("SLACK_REDIRECT_URL", ""),
+		SlackNotifyIntervalSeconds: getEnvInt("SLACK_NOTIFY_INTERVAL_SECONDS", 60),
+
+		NotifyIntervalSeconds: getEnvInt("NOTIFY_INTERVAL_SECONDS", 60),
+
+		NotificationsIntervalSeconds: getEnvInt("NOTIFICATIONS_INTERVAL_SECONDS", 15),
+		NotificationTemplatesDir:     getEnv("NOTIFICATION_TEMPLATES_DIR", ""),
+
+		AdvisorySyncIntervalSeconds: getEnvInt("ADVISORY_SYNC_INTERVAL_SECONDS", 60),
+
+		WebhookDispatchIntervalSeconds: getEnvInt("WEBHOOK_DISPATCH_INTERVAL_SECONDS", 15),
+		PaymentProofIntervalSeconds:    getEnvInt("PAYMENT_PROOF_INTERVAL_SECONDS", 60),
+
+		StatusCheckIntervalSeconds: getEnvInt("STATUS_CHECK_INTERVAL_SECONDS", 60),
+
+		ProbeIntervalSeconds:     getEnvInt("PROBE_INTERVAL_SECONDS", 60),
+		ProbeWalletSeedHex:       getEnv("PROBE_WALLET_SEED_HEX", ""),
+		ProbeMirrorMaxAgeMinutes: getEnvInt("PROBE_MIRROR_MAX_AGE_MINUTES", 360),
+		MetricsToken:             getEnv("METRICS_TOKEN", ""),
+
+		OTelEndpoint:      strings.TrimSpace(getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "")),
+		OTelServiceName:   getEnv("OTEL_SERVICE_NAME", "grainlify-api"),
+		OTelSamplePercent: getEnvInt("OTEL_TRACES_SAMPLE_PERCENT", 100),
+
+		PprofToken:         getEnv("PPROF_TOKEN", ""),
+		LoadtestRecordPath: getEnv("LOADTEST_RECORD_PATH", ""),
+
+		ShedWaitThresholdMs:   getEnvInt("SHED_WAIT_THRESHOLD_MS", 200),
+		ShedQueueThreshold:    getEnvInt("SHED_QUEUE_THRESHOLD", 50),
+		ShedRetryAfterSeconds: getEnvInt("SHED_RETRY_AFTER_SECONDS", 5),
+
+		IdempotencyKeyTTLHours: getEnvInt("IDEMPOTENCY_KEY_TTL_HOURS", 24),
+
+		WalletBalanceCacheSeconds: getEnvInt("WALLET_BALANCE_CACHE_SECONDS", 30),
+		CacheTTLSeconds:           getEnvInt("CACHE_TTL_SECONDS", 60),
+		GitHubProfileCacheSeconds: getEnvInt("GITHUB_PROFILE_CACHE_SECONDS", 300),
+
+		ChaosLatencyMs:         getEnvInt("CHAOS_LATENCY_MS", 0),
+		ChaosLatencyPercent:    getEnvInt("CHAOS_LATENCY_PERCENT", 0),
+		ChaosGitHubDropPercent: getEnvInt("CHAOS_GITHUB_DROP_PERCENT", 0),
+		ChaosPayoutFailPercent: getEnvInt("CHAOS_PAYOUT_FAIL_PERCENT", 0),
+
 		GitHubWebhookSecret: getEnv("GITHUB_WEBHOOK_SECRET", ""),
 
 		PublicBaseURL: getEnv("PUBLIC_BASE_URL", ""),
 
 		FrontendBaseURL: getEnv("FRONTEND_BASE_URL", ""),
-		CORSOrigins:     getEnv("CORS_ORIGINS", ""),
+		CORS:            loadCORS(env, getEnv("FRONTEND_BASE_URL", "")),
+
+		HSTSMaxAgeSeconds: getEnvInt("HSTS_MAX_AGE_SECONDS", hstsDefault),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+		ProxyHeader:    strings.TrimSpace(getEnv("PROXY_HEADER", "X-Real-IP")),
 
-		TokenEncKeyB64: getEnv("TOKEN_ENC_KEY_B64", ""),
+		TokenEncKeyB64:    getEnv("TOKEN_ENC_KEY_B64", ""),
+		TokenEncKeys:      getEnv("TOKEN_ENC_KEYS", ""),
+		TokenEncKeyActive: getEnvInt("TOKEN_ENC_KEY_ACTIVE", -1),
 
 		AdminBootstrapToken: strings.TrimSpace(getEnv("ADMIN_BOOTSTRAP_TOKEN", "")),
 
+		CaptchaProvider:       strings.ToLower(strings.TrimSpace(getEnv("CAPTCHA_PROVIDER", ""))),
+		CaptchaSecret:         getEnv("CAPTCHA_SECRET", ""),
+		CaptchaSiteKey:        getEnv("CAPTCHA_SITE_KEY", ""),
+		CaptchaNonceThreshold: getEnvInt("CAPTCHA_NONCE_THRESHOLD", 10),
+
+		AuthPoWDifficulty:          getEnvInt("AUTH_POW_DIFFICULTY", 0),
+		AuthPoWEscalatedDifficulty: getEnvInt("AUTH_POW_ESCALATED_DIFFICULTY", 0),
+
+		AuthRateLimitPerIP:         getEnvInt("AUTH_RATE_LIMIT_PER_IP", 30),
+		AuthRateLimitPerAddress:    getEnvInt("AUTH_RATE_LIMIT_PER_ADDRESS", 10),
+		AuthRateLimitWindowSeconds: getEnvInt("AUTH_RATE_LIMIT_WINDOW_SECONDS", 60),
+		RedisURL:                   strings.TrimSpace(getEnv("REDIS_URL", "")),
+
+		PublicRateLimitPerIP:         getEnvInt("PUBLIC_RATE_LIMIT_PER_IP", 120),
+		PublicRateLimitWindowSeconds: getEnvInt("PUBLIC_RATE_LIMIT_WINDOW_SECONDS", 60),
+		PublicCacheSeconds:           getEnvInt("PUBLIC_CACHE_SECONDS", 30),
+
+		APIKeyRateLimitTiers: getEnv("API_KEY_RATE_LIMIT_TIERS", "free=60/300,pro=600/6000,enterprise=3000/30000"),
+
+		ReportRateLimitPerHour: getEnvInt("REPORT_RATE_LIMIT_PER_HOUR", 20),
+
+		GeoIPCountryHeader: strings.TrimSpace(getEnv("GEO_IP_COUNTRY_HEADER", "")),
+
+		SMTPHost:     strings.TrimSpace(getEnv("SMTP_HOST", "")),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     strings.TrimSpace(getEnv("MAIL_FROM", "")),
+
+		AccountRecoveryEnabled:      getEnvBool("ACCOUNT_RECOVERY_ENABLED", true),
+		AccountRecoveryDelayHours:   getEnvInt("ACCOUNT_RECOVERY_DELAY_HOURS", 72),
+		AccountRecoveryLimitPerHour: getEnvInt("ACCOUNT_RECOVERY_LIMIT_PER_HOUR", 5),
+
+		SIWEDomain:      getEnv("SIWE_DOMAIN", ""),
+		SIWEURI:         getEnv("SIWE_URI", ""),
+		SIWEStatement:   getEnv("SIWE_STATEMENT", "Sign in to Grainlify."),
+		SIWEChainIDs:    getEnvList("SIWE_CHAIN_IDS"),
+		AuthRequireSIWE: getEnvBool("AUTH_REQUIRE_SIWE", false),
+
+		BackupEncKeyB64:       getEnv("BACKUP_ENC_KEY_B64", ""),
+		BackupDir:             getEnv("BACKUP_DIR", "./backups"),
+		BackupTables:          getEnvList("BACKUP_TABLES"),
+		BackupIntervalMinutes: getEnvInt("BACKUP_INTERVAL_MINUTES", 0),
+		BackupS3Endpoint:      getEnv("BACKUP_S3_ENDPOINT", "s3.amazonaws.com"),
+		BackupS3Region:        getEnv("BACKUP_S3_REGION", ""),
+		BackupS3Bucket:        getEnv("BACKUP_S3_BUCKET", ""),
+		BackupS3Prefix:        getEnv("BACKUP_S3_PREFIX", ""),
+		BackupS3AccessKey:     getEnv("BACKUP_S3_ACCESS_KEY", ""),
+		BackupS3SecretKey:     getEnv("BACKUP_S3_SECRET_KEY", ""),
+		BackupS3Insecure:      getEnvBool("BACKUP_S3_INSECURE", false),
+
+		LedgerAnchorKeyB64:          getEnv("LEDGER_ANCHOR_KEY_B64", ""),
+		LedgerAnchorIntervalMinutes: getEnvInt("LEDGER_ANCHOR_INTERVAL_MINUTES", 60),
+
+		AlchemyWebhookSigningKey: getEnv("ALCHEMY_WEBHOOK_SIGNING_KEY", ""),
+		HeliusWebhookAuthHeader:  getEnv("HELIUS_WEBHOOK_AUTH_HEADER", ""),
+		QuickNodeWebhookSecret:   getEnv("QUICKNODE_WEBHOOK_SECRET", ""),
+
+		DepositEVMXPub:        getEnv("DEPOSIT_EVM_XPUB", ""),
+		DepositEd25519SeedB64: getEnv("DEPOSIT_ED25519_SEED_B64", ""),
+		DepositGapLimit:       getEnvInt("DEPOSIT_GAP_LIMIT", 20),
+		DepositIntentTTLHours: getEnvInt("DEPOSIT_INTENT_TTL_HOURS", 72),
+
+		StellarHotWalletSecret: getEnv("STELLAR_HOT_WALLET_SECRET", ""),
+		EVMHotWalletKeyHex:     getEnv("EVM_HOT_WALLET_KEY_HEX", ""),
+		EVMRPCURLs:             getEnv("EVM_RPC_URLS", ""),
+		EVMSmartAccounts:       getEnv("EVM_SMART_ACCOUNTS", ""),
+		SweepIntervalMinutes:   getEnvInt("SWEEP_INTERVAL_MINUTES", 0),
+
+		PayoutBatchIntervalMinutes:        getEnvInt("PAYOUT_BATCH_INTERVAL_MINUTES", 0),
+		PayoutMaxBatch:                    getEnvInt("PAYOUT_MAX_BATCH", 50),
+		PayoutConfirmIntervalMinutes:      getEnvInt("PAYOUT_CONFIRM_INTERVAL_MINUTES", 0),
+		EVMPayoutConfirmations:            getEnvInt("EVM_PAYOUT_CONFIRMATIONS", 12),
+		ProjectEscrowWatchIntervalMinutes: getEnvInt("PROJECT_ESCROW_WATCH_INTERVAL_MINUTES", 0),
+		RelayerFees:                       getEnv("RELAYER_FEES", ""),
+		PayoutAssetGroups:                 getEnv("PAYOUT_ASSET_GROUPS", ""),
+		PayoutMinimums:                    getEnv("PAYOUT_MINIMUMS", ""),
+		PayoutMaxGasGwei:                  getEnv("PAYOUT_MAX_GAS_GWEI", ""),
+		PayoutCongestionPercent:           getEnvInt("PAYOUT_CONGESTION_PERCENT", 90),
+		PayoutThresholds:                  getEnv("PAYOUT_THRESHOLDS", ""),
+
+		AchievementNFTChain:            strings.ToLower(getEnv("ACHIEVEMENT_NFT_CHAIN", "")),
+		AchievementNFTContract:         getEnv("ACHIEVEMENT_NFT_CONTRACT", ""),
+		AchievementNFTBaseURI:          getEnv("ACHIEVEMENT_NFT_BASE_URI", ""),
+		AchievementMintIntervalMinutes: getEnvInt("ACHIEVEMENT_MINT_INTERVAL_MINUTES", 10),
+
+		EASChain:              strings.ToLower(getEnv("EAS_CHAIN", "")),
+		EASContract:           getEnv("EAS_CONTRACT", ""),
+		EASSchemaUID:          getEnv("EAS_SCHEMA_UID", ""),
+		AttestIntervalMinutes: getEnvInt("ATTEST_INTERVAL_MINUTES", 5),
+
+		SponsorsSyncIntervalMinutes: getEnvInt("SPONSORS_SYNC_INTERVAL_MINUTES", 360),
+
+		JobQueueWorkers:                  getEnvInt("JOB_QUEUE_WORKERS", 4),
+		GitHubProfileSyncIntervalMinutes: getEnvInt("GITHUB_PROFILE_SYNC_INTERVAL_MINUTES", 360),
+
+		CommitSignatureCheckIntervalMinutes: getEnvInt("COMMIT_SIGNATURE_CHECK_INTERVAL_MINUTES", 0),
+		UnsignedPayoutReviewAmount:          getEnv("UNSIGNED_PAYOUT_REVIEW_AMOUNT", ""),
+
+		RepoHealthIntervalMinutes: getEnvInt("REPO_HEALTH_INTERVAL_MINUTES", 60),
+		RepoHealthWindowDays:      getEnvInt("REPO_HEALTH_WINDOW_DAYS", 90),
+
+		LeaderboardIntervalMinutes: getEnvInt("LEADERBOARD_INTERVAL_MINUTES", 30),
+
+		GitHubOrgSyncIntervalMinutes: getEnvInt("GITHUB_ORG_SYNC_INTERVAL_MINUTES", 60),
+
+		EscrowDeadlineDays:           getEnvInt("ESCROW_DEADLINE_DAYS", 90),
+		EscrowReleaseIntervalMinutes: getEnvInt("ESCROW_RELEASE_INTERVAL_MINUTES", 5),
+
 		DiditAPIKey:        getEnv("DIDIT_API_KEY", ""),
 		DiditWorkflowID:    getEnv("DIDIT_WORKFLOW_ID", ""),
 		DiditWebhookSecret: getEnv("DIDIT_WEBHOOK_SECRET", ""),
@@ -153,6 +641,28 @@ func getEnv(key, fallback string) string {
 	return v
 }
 
+func getEnvInt(key string, fallback int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func getEnvList(key string) []string {
+	var out []string
+	for _, p := range strings.Split(os.Getenv(key), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 func getEnvBool(key string, fallback bool) bool {
 	v := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
 	if v == "" {
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CORS is which browser origins may call the API with credentials. The
+// public API under /public/v1 sets its own policy open to every origin.
+type CORS struct {
+	// Origins are allowed exactly, except that "https://*.example.com"
+	// allows every subdomain of example.com over https.
+	Origins []string
+	// AllowLocalhost allows localhost and 127.0.0.1 on any port, for local
+	// frontends. It defaults to on only in dev.
+	AllowLocalhost   bool
+	AllowCredentials bool
+	// MaxAgeSeconds is how long browsers may cache a preflight response.
+	MaxAgeSeconds int
+}
+
+// defaultCORSOrigins apply when CORS_ORIGINS is unset: frontend preview
+// deployments. Setting CORS_ORIGINS replaces them, so list them again to
+// keep them.
+var defaultCORSOrigins = []string{"https://*.vercel.app"}
+
+func loadCORS(env, frontendBaseURL string) CORS {
+	c := CORS{
+		Origins:          getEnvList("CORS_ORIGINS"),
+		AllowLocalhost:   getEnvBool("CORS_ALLOW_LOCALHOST", env == "dev"),
+		AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", true),
+		MaxAgeSeconds:    getEnvInt("CORS_MAX_AGE_SECONDS", 600),
+	}
+	if len(c.Origins) == 0 {
+		c.Origins = append([]string(nil), defaultCORSOrigins...)
+	}
+	for i, o := range c.Origins {
+		c.Origins[i] = strings.TrimRight(o, "/")
+	}
+	// The frontend is always allowed.
+	if u, err := url.Parse(frontendBaseURL); err == nil && u.Scheme != "" && u.Host != "" {
+		c.Origins = append(c.Origins, u.Scheme+"://"+u.Host)
+	}
+	return c
+}
+
+// AllowsOrigin reports whether origin ("scheme://host[:port]") may call the
+// API.
+func (c CORS) AllowsOrigin(origin string) bool {
+	if c.AllowLocalhost {
+		for _, local := range []string{"http://localhost:", "https://localhost:", "http://127.0.0.1:", "https://127.0.0.1:"} {
+			if strings.HasPrefix(origin, local) {
+				return true
+			}
+		}
+	}
+	for _, o := range c.Origins {
+		if o == origin {
+			return true
+		}
+		if scheme, domain, ok := strings.Cut(o, "://*."); ok &&
+			strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
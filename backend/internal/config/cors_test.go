@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestCORSAllowsOrigin(t *testing.T) {
+	t.Setenv("CORS_ORIGINS", "https://*.vercel.app, https://grainlify.io/")
+	t.Setenv("CORS_ALLOW_LOCALHOST", "false")
+	c := loadCORS("prod", "https://app.grainlify.io/login")
+	for origin, want := range map[string]bool{
+		"https://app.grainlify.io":         true,
+		"https://grainlify.io":             true,
+		"https://preview-42.vercel.app":    true,
+		"https://vercel.app":               false,
+		"http://preview-42.vercel.app":     false,
+		"https://preview.vercel.app.evil":  false,
+		"https://evil.io":                  false,
+		"http://localhost:5173":            false,
+		"https://grainlify.io.example.com": false,
+	} {
+		if got := c.AllowsOrigin(origin); got != want {
+			t.Errorf("AllowsOrigin(%q) = %v, want %v", origin, got, want)
+		}
+	}
+
+	c.AllowLocalhost = true
+	if !c.AllowsOrigin("http://localhost:5173") || c.AllowsOrigin("http://localhost.evil.io") {
+		t.Error("localhost not matched by port")
+	}
+}
+
+func TestCORSLocalhostDefault(t *testing.T) {
+	t.Setenv("CORS_ALLOW_LOCALHOST", "")
+	if !loadCORS("dev", "").AllowLocalhost {
+		t.Error("localhost not allowed in dev")
+	}
+	if loadCORS("prod", "").AllowLocalhost {
+		t.Error("localhost allowed outside dev")
+	}
+}
@@ -0,0 +1,235 @@
+package cryptox
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envelopeMagic starts every envelope; blobs without it predate key versions
+// and were sealed directly with the legacy key (version 0).
+var envelopeMagic = []byte("env1")
+
+const (
+	dekSize = 32
+	// wrappedDEKSize is nonce||ciphertext||tag of a data key.
+	wrappedDEKSize = 12 + dekSize + 16
+	headerSize     = 4 + 2
+)
+
+var (
+	ErrUnknownKeyVersion = errors.New("unknown token key version")
+	ErrNoKeys            = errors.New("TOKEN_ENC_KEY_B64 or TOKEN_ENC_KEYS is required")
+)
+
+// Keyring holds versioned key-encryption keys. Values are sealed in an
+// envelope: a fresh data key encrypts the value, and the active key wraps
+// the data key, so rotating keys only rewraps data keys. Version 0 is the
+// legacy TOKEN_ENC_KEY_B64, which also opens blobs sealed before envelopes.
+type Keyring struct {
+	keys   map[uint16][]byte
+	active uint16
+}
+
+// ParseKeyring builds a keyring from the legacy key (version 0, may be empty)
+// and a spec of further keys, "version:base64" separated by commas. active
+// picks the version new values are sealed under; negative means the highest.
+func ParseKeyring(legacyB64, spec string, active int) (*Keyring, error) {
+	k := &Keyring{keys: map[uint16][]byte{}}
+	if strings.TrimSpace(legacyB64) != "" {
+		key, err := KeyFromB64(legacyB64)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[0] = key
+	}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, b64, ok := strings.Cut(part, ":")
+		n, err := strconv.ParseUint(strings.TrimSpace(v), 10, 16)
+		if !ok || err != nil || n == 0 {
+			return nil, fmt.Errorf("TOKEN_ENC_KEYS: %q is not version:base64 with a version from 1", v)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(b64))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("TOKEN_ENC_KEYS: key %d must be 32 bytes base64", n)
+		}
+		if _, dup := k.keys[uint16(n)]; dup {
+			return nil, fmt.Errorf("TOKEN_ENC_KEYS: key %d given twice", n)
+		}
+		k.keys[uint16(n)] = key
+	}
+	if len(k.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	versions := k.Versions()
+	switch {
+	case active < 0:
+		k.active = versions[len(versions)-1]
+	case active > 0xffff:
+		return nil, fmt.Errorf("TOKEN_ENC_KEY_ACTIVE: %d: %w", active, ErrUnknownKeyVersion)
+	default:
+		if _, ok := k.keys[uint16(active)]; !ok {
+			return nil, fmt.Errorf("TOKEN_ENC_KEY_ACTIVE: %d: %w", active, ErrUnknownKeyVersion)
+		}
+		k.active = uint16(active)
+	}
+	return k, nil
+}
+
+// Active is the version new values are sealed under.
+func (k *Keyring) Active() uint16 { return k.active }
+
+// Versions lists the key versions held, ascending.
+func (k *Keyring) Versions() []uint16 {
+	out := make([]uint16, 0, len(k.keys))
+	for v := range k.keys {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// isEnvelope reports whether blob has an envelope's header.
+func isEnvelope(blob []byte) bool {
+	return len(blob) >= headerSize+wrappedDEKSize && bytes.HasPrefix(blob, envelopeMagic)
+}
+
+// Version reports the key version blob is sealed under: 0 for legacy blobs.
+func Version(blob []byte) uint16 {
+	if !isEnvelope(blob) {
+		return 0
+	}
+	return binary.BigEndian.Uint16(blob[len(envelopeMagic):headerSize])
+}
+
+// Seal encrypts plaintext in an envelope under the active key.
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	dek := make([]byte, dekSize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return nil, err
+	}
+	data, err := EncryptAESGCM(dek, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return k.wrap(dek, data)
+}
+
+// wrap assembles an envelope of data around dek wrapped by the active key.
+// The header is authenticated along with the data key, so a blob can't be
+// relabelled with another version.
+func (k *Keyring) wrap(dek, data []byte) ([]byte, error) {
+	header := make([]byte, headerSize)
+	copy(header, envelopeMagic)
+	binary.BigEndian.PutUint16(header[len(envelopeMagic):], k.active)
+	wrapped, err := sealWithAD(k.keys[k.active], dek, header)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(header)+len(wrapped)+len(data))
+	out = append(out, header...)
+	out = append(out, wrapped...)
+	return append(out, data...), nil
+}
+
+// unwrap returns the data key and sealed data of an envelope.
+func (k *Keyring) unwrap(blob []byte) (dek, data []byte, err error) {
+	v := Version(blob)
+	key, ok := k.keys[v]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, v)
+	}
+	dek, err = openWithAD(key, blob[headerSize:headerSize+wrappedDEKSize], blob[:headerSize])
+	if err != nil {
+		return nil, nil, err
+	}
+	return dek, blob[headerSize+wrappedDEKSize:], nil
+}
+
+// Open decrypts a blob from Seal, or a legacy blob sealed with the version 0
+// key directly.
+func (k *Keyring) Open(blob []byte) ([]byte, error) {
+	legacy, hasLegacy := k.keys[0]
+	if isEnvelope(blob) {
+		dek, data, err := k.unwrap(blob)
+		if err == nil {
+			return DecryptAESGCM(dek, data)
+		}
+		// A legacy blob's nonce can start with the magic by chance.
+		if hasLegacy {
+			if plain, lerr := DecryptAESGCM(legacy, blob); lerr == nil {
+				return plain, nil
+			}
+		}
+		return nil, err
+	}
+	if !hasLegacy {
+		return nil, fmt.Errorf("%w: 0", ErrUnknownKeyVersion)
+	}
+	return DecryptAESGCM(legacy, blob)
+}
+
+// Rekey moves blob under the active key: an envelope keeps its data and only
+// has its data key rewrapped, a legacy blob is sealed afresh. changed is
+// false when blob already is under the active key.
+func (k *Keyring) Rekey(blob []byte) (out []byte, changed bool, err error) {
+	if isEnvelope(blob) {
+		if dek, data, err := k.unwrap(blob); err == nil {
+			if Version(blob) == k.active {
+				return blob, false, nil
+			}
+			out, err := k.wrap(dek, data)
+			return out, err == nil, err
+		}
+	}
+	plain, err := k.Open(blob)
+	if err != nil {
+		return nil, false, err
+	}
+	out, err = k.Seal(plain)
+	return out, err == nil, err
+}
+
+func sealWithAD(key, plaintext, ad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+func openWithAD(key, blob, ad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return gcm.Open(nil, blob[:gcm.NonceSize()], blob[gcm.NonceSize():], ad)
+}
@@ -0,0 +1,91 @@
+package cryptox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func TestKeyringRotation(t *testing.T) {
+	legacy := testKey(1)
+	legacyKey, _ := KeyFromB64(legacy)
+	old, err := EncryptAESGCM(legacyKey, []byte("gho_legacy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k0, err := ParseKeyring(legacy, "", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := k0.Open(old); err != nil || string(got) != "gho_legacy" {
+		t.Fatalf("legacy open = %q, %v", got, err)
+	}
+	sealed, err := k0.Seal([]byte("gho_v0"))
+	if err != nil || Version(sealed) != 0 || Version(old) != 0 {
+		t.Fatalf("seal under v0: version %d, %v", Version(sealed), err)
+	}
+
+	k1, err := ParseKeyring(legacy, "1:"+testKey(2), -1)
+	if err != nil || k1.Active() != 1 {
+		t.Fatalf("active = %d, %v", k1.Active(), err)
+	}
+	rekeyed := map[string][]byte{}
+	for want, blob := range map[string][]byte{"gho_legacy": old, "gho_v0": sealed} {
+		out, changed, err := k1.Rekey(blob)
+		if err != nil || !changed || Version(out) != 1 {
+			t.Fatalf("rekey %s: version %d, changed %v, %v", want, Version(out), changed, err)
+		}
+		if _, changed, _ := k1.Rekey(out); changed {
+			t.Fatalf("rekey %s twice changed it", want)
+		}
+		rekeyed[want] = out
+	}
+
+	// Once everything is rekeyed the legacy key can be retired.
+	retired, err := ParseKeyring("", "1:"+testKey(2), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for want, blob := range rekeyed {
+		if got, err := retired.Open(blob); err != nil || string(got) != want {
+			t.Fatalf("open %s after retiring v0 = %q, %v", want, got, err)
+		}
+	}
+	if _, err := retired.Open(sealed); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Fatalf("open v0 blob without v0 err = %v", err)
+	}
+}
+
+func TestKeyringRejectsRelabelling(t *testing.T) {
+	k, _ := ParseKeyring(testKey(1), "1:"+testKey(1), 1)
+	blob, _ := k.Seal([]byte("token"))
+	blob[len(envelopeMagic)+1] = 0
+	if _, err := k.Open(blob); err == nil {
+		t.Fatal("blob relabelled to v0 opened")
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	for _, tc := range []struct {
+		legacy, spec string
+		active       int
+		err          error
+	}{
+		{"", "", -1, ErrNoKeys},
+		{testKey(1), "", 2, ErrUnknownKeyVersion},
+		{"", "0:" + testKey(1), -1, nil},
+		{"", "1:short", -1, nil},
+		{"", fmt.Sprintf("1:%s,1:%s", testKey(1), testKey(2)), -1, nil},
+	} {
+		if _, err := ParseKeyring(tc.legacy, tc.spec, tc.active); err == nil || (tc.err != nil && !errors.Is(err, tc.err)) {
+			t.Errorf("ParseKeyring(%q, %q, %d) err = %v, want %v", tc.legacy, tc.spec, tc.active, err, tc.err)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/tracing"
 )
 
 type DB struct {
@@ -44,6 +46,7 @@ func Connect(ctx context.Context, dbURL string) (*DB, error) {
 	cfg.MaxConnLifetime = 60 * time.Minute  // Increased from 30 minutes
 	cfg.MaxConnIdleTime = 15 * time.Minute  // Increased from 5 minutes
 	cfg.HealthCheckPeriod = 30 * time.Second
+	cfg.ConnConfig.Tracer = tracing.DBTracer{}
 
 	slog.Info("creating database connection pool",
 		"max_conns", cfg.MaxConns,
@@ -0,0 +1,331 @@
+// Package deposits hands out a unique HD-derived address per funding intent so
+// incoming transfers are matched by address instead of memo.
+package deposits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/hdwallet"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusFunded    = "funded"
+	StatusExpired   = "expired"
+	StatusCancelled = "cancelled"
+)
+
+// An address whose intent expired unfunded is only reissued after this grace
+// period, so a late deposit is not credited to someone else's intent.
+const recycleGrace = 24 * time.Hour
+
+var (
+	ErrUnsupportedChain = errors.New("unsupported_chain")
+	ErrGapLimit         = errors.New("gap_limit_reached")
+	ErrIntentNotFound   = errors.New("intent_not_found")
+)
+
+type Intent struct {
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Chain           string     `json:"chain"`
+	Asset           string     `json:"asset"`
+	ExpectedAmount  *string    `json:"expected_amount,omitempty"`
+	Address         string     `json:"address"`
+	DerivationIndex int64      `json:"derivation_index"`
+	Status          string     `json:"status"`
+	FundedAt        *time.Time `json:"funded_at,omitempty"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type Service struct {
+	Pool     *pgxpool.Pool
+	Deriver  *hdwallet.Deriver
+	GapLimit int
+	TTL      time.Duration
+}
+
+// CreateIntent reserves an address for a new intent. Addresses are recycled
+// from expired, never-funded intents first; otherwise the next index is
+// derived, as long as that keeps unused addresses within the gap limit so
+// standard wallets restoring from the seed still discover every deposit.
+func (s *Service) CreateIntent(ctx context.Context, userID uuid.UUID, chainName, asset, expectedAmount string) (Intent, error) {
+	if s.Pool == nil {
+		return Intent{}, fmt.Errorf("db not configured")
+	}
+	chainName = strings.ToLower(strings.TrimSpace(chainName))
+	family, ok := hdwallet.FamilyOf(chainName)
+	if !ok || !s.Deriver.Supports(family) {
+		return Intent{}, ErrUnsupportedChain
+	}
+
+	tx, err := s.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Intent{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+UPDATE deposit_intents SET status = 'expired'
+WHERE status = 'pending' AND expires_at < now()
+`); err != nil {
+		return Intent{}, err
+	}
+
+	addrID, address, index, err := s.recycle(ctx, tx, family, chainName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		addrID, address, index, err = s.derive(ctx, tx, family, chainName)
+	}
+	if err != nil {
+		return Intent{}, err
+	}
+
+	// Transfers carry tokens by contract, so normalize hex contracts the same way.
+	asset = chain.NormalizeAddress(asset)
+	in := Intent{UserID: userID, Chain: chainName, Asset: asset, Address: address, DerivationIndex: index, Status: StatusPending}
+	err = tx.QueryRow(ctx, `
+INSERT INTO deposit_intents (user_id, chain, asset, expected_amount, deposit_address_id, expires_at)
+VALUES ($1, $2, $3, NULLIF($4, '')::numeric, $5, now() + $6::interval)
+RETURNING id, expected_amount::text, expires_at, created_at
+`, userID, chainName, asset, expectedAmount, addrID, fmt.Sprintf("%d seconds", int64(s.TTL.Seconds()))).
+		Scan(&in.ID, &in.ExpectedAmount, &in.ExpiresAt, &in.CreatedAt)
+	if err != nil {
+		return Intent{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Intent{}, err
+	}
+	return in, nil
+}
+
+func (s *Service) recycle(ctx context.Context, tx pgx.Tx, family, chainName string) (uuid.UUID, string, int64, error) {
+	var (
+		id    uuid.UUID
+		addr  string
+		index int64
+	)
+	err := tx.QueryRow(ctx, `
+SELECT a.id, a.address, a.derivation_index
+FROM deposit_addresses a
+WHERE a.family = $1 AND a.chain = $2 AND a.first_funded_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM project_escrow_accounts pe WHERE pe.deposit_address_id = a.id)
+  AND NOT EXISTS (
+    SELECT 1 FROM deposit_intents i
+    WHERE i.deposit_address_id = a.id
+      AND (i.status IN ('pending', 'funded') OR i.expires_at > now() - $3::interval)
+  )
+ORDER BY a.derivation_index
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`, family, chainName, fmt.Sprintf("%d seconds", int64(recycleGrace.Seconds()))).Scan(&id, &addr, &index)
+	return id, addr, index, err
+}
+
+func (s *Service) derive(ctx context.Context, tx pgx.Tx, family, chainName string) (uuid.UUID, string, int64, error) {
+	if _, err := tx.Exec(ctx, `INSERT INTO hd_derivation_cursors (family) VALUES ($1) ON CONFLICT DO NOTHING`, family); err != nil {
+		return uuid.Nil, "", 0, err
+	}
+	var next int64
+	if err := tx.QueryRow(ctx, `SELECT next_index FROM hd_derivation_cursors WHERE family = $1 FOR UPDATE`, family).Scan(&next); err != nil {
+		return uuid.Nil, "", 0, err
+	}
+
+	var lastFunded int64
+	if err := tx.QueryRow(ctx, `
+SELECT COALESCE(MAX(derivation_index), -1) FROM deposit_addresses
+WHERE family = $1 AND first_funded_at IS NOT NULL
+`, family).Scan(&lastFunded); err != nil {
+		return uuid.Nil, "", 0, err
+	}
+	if s.GapLimit > 0 && next-(lastFunded+1) >= int64(s.GapLimit) {
+		return uuid.Nil, "", 0, ErrGapLimit
+	}
+
+	var (
+		address string
+		err     error
+	)
+	for {
+		if next >= 1<<31 {
+			return uuid.Nil, "", 0, fmt.Errorf("derivation index space exhausted for %s", family)
+		}
+		address, err = s.Deriver.Address(family, uint32(next))
+		if errors.Is(err, hdwallet.ErrInvalidChild) {
+			next++
+			continue
+		}
+		if err != nil {
+			return uuid.Nil, "", 0, err
+		}
+		break
+	}
+	address = chain.NormalizeAddress(address)
+
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, `
+INSERT INTO deposit_addresses (family, derivation_index, chain, address)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`, family, next, chainName, address).Scan(&id); err != nil {
+		return uuid.Nil, "", 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE hd_derivation_cursors SET next_index = $2, updated_at = now() WHERE family = $1`, family, next+1); err != nil {
+		return uuid.Nil, "", 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO watched_addresses (chain, address, purpose)
+VALUES ($1, $2, 'deposit')
+ON CONFLICT (chain, address) DO NOTHING
+`, chainName, address); err != nil {
+		return uuid.Nil, "", 0, err
+	}
+	return id, address, next, nil
+}
+
+const intentSelect = `
+SELECT i.id, i.user_id, i.chain, i.asset, i.expected_amount::text, a.address, a.derivation_index,
+       CASE WHEN i.status = 'pending' AND i.expires_at < now() THEN 'expired' ELSE i.status END,
+       i.funded_at, i.expires_at, i.created_at
+FROM deposit_intents i
+JOIN deposit_addresses a ON a.id = i.deposit_address_id
+`
+
+func scanIntent(row pgx.Row) (Intent, error) {
+	var in Intent
+	err := row.Scan(&in.ID, &in.UserID, &in.Chain, &in.Asset, &in.ExpectedAmount, &in.Address, &in.DerivationIndex,
+		&in.Status, &in.FundedAt, &in.ExpiresAt, &in.CreatedAt)
+	return in, err
+}
+
+// GetIntent returns an intent owned by userID.
+func GetIntent(ctx context.Context, pool *pgxpool.Pool, userID, id uuid.UUID) (Intent, error) {
+	if pool == nil {
+		return Intent{}, fmt.Errorf("db not configured")
+	}
+	in, err := scanIntent(pool.QueryRow(ctx, intentSelect+`WHERE i.id = $1 AND i.user_id = $2`, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Intent{}, ErrIntentNotFound
+	}
+	return in, err
+}
+
+func ListIntents(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Intent, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, intentSelect+`WHERE i.user_id = $1 ORDER BY i.created_at DESC LIMIT 100`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Intent{}
+	for rows.Next() {
+		in, err := scanIntent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, in)
+	}
+	return out, rows.Err()
+}
+
+// MatchTransfer marks the deposit address as used and settles the oldest
+// pending intent the transfer pays for: same asset (tokens by contract) and
+// at least the expected amount. It is passed to chain.Ingest as the
+// InboundMatcher for every newly ingested inbound transfer.
+func MatchTransfer(ctx context.Context, tx pgx.Tx, transferID uuid.UUID, t chain.Transfer) error {
+	var addrID uuid.UUID
+	err := tx.QueryRow(ctx, `
+UPDATE deposit_addresses SET first_funded_at = COALESCE(first_funded_at, now())
+WHERE chain = $1 AND address = $2
+RETURNING id
+`, strings.ToLower(t.Chain), chain.NormalizeAddress(t.To)).Scan(&addrID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil // not a deposit address (hot/cold wallet)
+	}
+	if err != nil {
+		return err
+	}
+
+	ct, err := tx.Exec(ctx, `
+UPDATE deposit_intents
+SET status = 'funded', funded_transfer_id = $2, funded_at = now()
+WHERE id = (
+  SELECT id FROM deposit_intents
+  WHERE deposit_address_id = $1 AND status = 'pending'
+    AND asset = $3
+    AND (expected_amount IS NULL OR expected_amount <= $4::numeric)
+  ORDER BY created_at
+  LIMIT 1
+  FOR UPDATE
+)
+`, addrID, transferID, chain.NormalizeAddress(t.Asset), t.Amount)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 && !isProjectAccount(ctx, tx, addrID) {
+		slog.Warn("deposit to address without matching pending intent",
+			"chain", t.Chain,
+			"address", t.To,
+			"tx_hash", t.TxHash,
+			"asset", t.Asset,
+			"amount", t.Amount,
+		)
+	}
+	return nil
+}
+
+// isProjectAccount reports whether addrID is a project's escrow address,
+// which takes deposits without intents.
+func isProjectAccount(ctx context.Context, tx pgx.Tx, addrID uuid.UUID) bool {
+	var ok bool
+	_ = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM project_escrow_accounts WHERE deposit_address_id = $1)`, addrID).Scan(&ok)
+	return ok
+}
+
+// Cursor summarizes derivation state for one family.
+type Cursor struct {
+	Family          string `json:"family"`
+	NextIndex       int64  `json:"next_index"`
+	LastFundedIndex int64  `json:"last_funded_index"`
+	Unused          int64  `json:"unused"`
+	GapLimit        int    `json:"gap_limit"`
+}
+
+func (s *Service) Cursors(ctx context.Context) ([]Cursor, error) {
+	if s.Pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := s.Pool.Query(ctx, `
+SELECT c.family, c.next_index,
+       COALESCE((SELECT MAX(derivation_index) FROM deposit_addresses a WHERE a.family = c.family AND a.first_funded_at IS NOT NULL), -1)
+FROM hd_derivation_cursors c
+ORDER BY c.family
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Cursor{}
+	for rows.Next() {
+		var c Cursor
+		if err := rows.Scan(&c.Family, &c.NextIndex, &c.LastFundedIndex); err != nil {
+			return nil, err
+		}
+		c.Unused = c.NextIndex - (c.LastFundedIndex + 1)
+		c.GapLimit = s.GapLimit
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,359 @@
+package deposits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/hdwallet"
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// ErrProjectNotFound is returned for projects that don't exist or were deleted.
+var ErrProjectNotFound = errors.New("project_not_found")
+
+// EscrowReferencePrefix starts the ledger reference of every escrow entry
+// of a project, "project_escrow:<project id>:<transfer id>", so the ledger
+// alone yields what each project holds.
+const EscrowReferencePrefix = "project_escrow:"
+
+// EscrowReference is the ledger reference crediting transferID to projectID.
+func EscrowReference(projectID, transferID uuid.UUID) string {
+	return EscrowReferencePrefix + projectID.String() + ":" + transferID.String()
+}
+
+// ProjectAccount is where a project deposits bounty funds on one chain. The
+// address is the project's alone: any asset sent to it is credited to the
+// project, with no memo needed.
+type ProjectAccount struct {
+	ProjectID       uuid.UUID `json:"project_id"`
+	Chain           string    `json:"chain"`
+	Address         string    `json:"address"`
+	DerivationIndex int64     `json:"derivation_index"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ProjectDeposit is an inbound transfer credited to a project's escrow.
+type ProjectDeposit struct {
+	TransferID uuid.UUID `json:"transfer_id"`
+	Chain      string    `json:"chain"`
+	Asset      string    `json:"asset"`
+	Amount     string    `json:"amount"`
+	TxHash     string    `json:"tx_hash"`
+	From       string    `json:"from_address"`
+	CreditedAt time.Time `json:"credited_at"`
+}
+
+// ProjectBalance is what the ledger says a project holds in escrow of one
+// asset.
+type ProjectBalance struct {
+	Chain   string `json:"chain"`
+	Asset   string `json:"asset"`
+	Balance string `json:"balance"`
+}
+
+const projectAccountSelect = `
+SELECT pe.project_id, pe.chain, a.address, a.derivation_index, pe.created_at
+FROM project_escrow_accounts pe
+JOIN deposit_addresses a ON a.id = pe.deposit_address_id
+`
+
+func scanProjectAccount(row pgx.Row) (ProjectAccount, error) {
+	var pa ProjectAccount
+	err := row.Scan(&pa.ProjectID, &pa.Chain, &pa.Address, &pa.DerivationIndex, &pa.CreatedAt)
+	return pa, err
+}
+
+// ProjectAccount returns the project's escrow address on chainName, deriving
+// one the first time. Escrow addresses count towards the gap limit like any
+// other but are never recycled.
+func (s *Service) ProjectAccount(ctx context.Context, projectID uuid.UUID, chainName string) (ProjectAccount, error) {
+	if s.Pool == nil {
+		return ProjectAccount{}, fmt.Errorf("db not configured")
+	}
+	chainName = strings.ToLower(strings.TrimSpace(chainName))
+	family, ok := hdwallet.FamilyOf(chainName)
+	if !ok || !s.Deriver.Supports(family) {
+		return ProjectAccount{}, ErrUnsupportedChain
+	}
+
+	tx, err := s.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ProjectAccount{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// The project row lock serializes concurrent first requests.
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT true FROM projects WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, projectID).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ProjectAccount{}, ErrProjectNotFound
+		}
+		return ProjectAccount{}, err
+	}
+	pa, err := scanProjectAccount(tx.QueryRow(ctx, projectAccountSelect+`WHERE pe.project_id = $1 AND pe.chain = $2`, projectID, chainName))
+	if err == nil {
+		return pa, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return ProjectAccount{}, err
+	}
+
+	addrID, address, index, err := s.derive(ctx, tx, family, chainName)
+	if err != nil {
+		return ProjectAccount{}, err
+	}
+	pa = ProjectAccount{ProjectID: projectID, Chain: chainName, Address: address, DerivationIndex: index}
+	if err := tx.QueryRow(ctx, `
+INSERT INTO project_escrow_accounts (project_id, chain, deposit_address_id)
+VALUES ($1, $2, $3)
+RETURNING created_at
+`, projectID, chainName, addrID).Scan(&pa.CreatedAt); err != nil {
+		return ProjectAccount{}, err
+	}
+	return pa, tx.Commit(ctx)
+}
+
+func ListProjectAccounts(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) ([]ProjectAccount, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, projectAccountSelect+`WHERE pe.project_id = $1 ORDER BY pe.chain`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ProjectAccount{}
+	for rows.Next() {
+		pa, err := scanProjectAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pa)
+	}
+	return out, rows.Err()
+}
+
+func ListProjectDeposits(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, limit int) ([]ProjectDeposit, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT transfer_id, chain, asset, amount::text, tx_hash, from_address, credited_at
+FROM project_escrow_deposits
+WHERE project_id = $1
+ORDER BY credited_at DESC
+LIMIT $2
+`, projectID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ProjectDeposit{}
+	for rows.Next() {
+		var d ProjectDeposit
+		if err := rows.Scan(&d.TransferID, &d.Chain, &d.Asset, &d.Amount, &d.TxHash, &d.From, &d.CreditedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ProjectBalances sums the project's escrow entries in the ledger.
+func ProjectBalances(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) ([]ProjectBalance, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT asset, SUM(amount)::text
+FROM ledger_entries
+WHERE account = $1 AND reference LIKE $2
+GROUP BY asset
+ORDER BY asset
+`, ledger.AccountEscrow, EscrowReferencePrefix+projectID.String()+":%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ProjectBalance{}
+	for rows.Next() {
+		var key string
+		var b ProjectBalance
+		if err := rows.Scan(&key, &b.Balance); err != nil {
+			return nil, err
+		}
+		b.Chain, b.Asset, _ = strings.Cut(key, "/")
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// EscrowWatcher detects deposits into project escrow addresses and credits
+// them. Transfers reach chain_transfers from indexer webhooks; on chains whose
+// sender is a wallet.Watcher (Stellar), the watcher polls for them itself.
+type EscrowWatcher struct {
+	Pool    *pgxpool.Pool
+	Wallets wallet.Registry
+}
+
+// RunOnce polls the watchable chains, then credits every inbound transfer
+// to a project escrow address not credited yet.
+func (w *EscrowWatcher) RunOnce(ctx context.Context) error {
+	if w.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	pollErr := w.poll(ctx)
+	n, err := CreditProjectDeposits(ctx, w.Pool)
+	if n > 0 {
+		slog.Info("project escrow deposits credited", "deposits", n)
+	}
+	return errors.Join(pollErr, err)
+}
+
+func (w *EscrowWatcher) poll(ctx context.Context) error {
+	rows, err := w.Pool.Query(ctx, `
+SELECT pe.project_id, pe.chain, a.address, COALESCE(pe.watch_cursor, '')
+FROM project_escrow_accounts pe
+JOIN deposit_addresses a ON a.id = pe.deposit_address_id
+ORDER BY pe.created_at
+`)
+	if err != nil {
+		return err
+	}
+	type account struct {
+		projectID              uuid.UUID
+		chain, address, cursor string
+	}
+	var accounts []account
+	for rows.Next() {
+		var a account
+		if err := rows.Scan(&a.projectID, &a.chain, &a.address, &a.cursor); err != nil {
+			rows.Close()
+			return err
+		}
+		accounts = append(accounts, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, a := range accounts {
+		sender, _ := w.Wallets.Get(a.chain)
+		watcher, ok := sender.(wallet.Watcher)
+		if !ok {
+			continue
+		}
+		transfers, cursor, err := watcher.InboundTransfers(ctx, a.address, a.cursor)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", a.chain, a.address, err))
+			continue
+		}
+		ingested := true
+		for _, t := range transfers {
+			if _, err := chain.Ingest(ctx, w.Pool, "horizon", t, MatchTransfer); err != nil {
+				errs = append(errs, err)
+				ingested = false
+				break
+			}
+		}
+		// Resume from the same page until all of it is recorded.
+		if ingested && cursor != a.cursor {
+			if _, err := w.Pool.Exec(ctx, `UPDATE project_escrow_accounts SET watch_cursor = $3 WHERE project_id = $1 AND chain = $2`, a.projectID, a.chain, cursor); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CreditProjectDeposits credits inbound transfers to project escrow addresses
+// to their project, posting each to the ledger's escrow account once, and
+// returns how many it credited. The project owner is the ledger entry's user,
+// so they can verify it like their own deposits.
+func CreditProjectDeposits(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT t.id, t.chain, t.asset, t.amount::text, t.tx_hash, t.from_address, pe.project_id, p.owner_user_id
+FROM chain_transfers t
+JOIN deposit_addresses a ON a.chain = t.chain AND a.address = t.to_address
+JOIN project_escrow_accounts pe ON pe.deposit_address_id = a.id
+JOIN projects p ON p.id = pe.project_id
+WHERE t.direction = 'in'
+  AND NOT EXISTS (SELECT 1 FROM project_escrow_deposits d WHERE d.transfer_id = t.id)
+ORDER BY t.created_at
+LIMIT 500
+`)
+	if err != nil {
+		return 0, err
+	}
+	type pending struct {
+		d         ProjectDeposit
+		projectID uuid.UUID
+		owner     *uuid.UUID
+	}
+	var list []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.d.TransferID, &p.d.Chain, &p.d.Asset, &p.d.Amount, &p.d.TxHash, &p.d.From, &p.projectID, &p.owner); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		list = append(list, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, p := range list {
+		ok, err := creditProjectDeposit(ctx, pool, p.projectID, p.owner, p.d)
+		if err != nil {
+			return n, err
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func creditProjectDeposit(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, owner *uuid.UUID, d ProjectDeposit) (bool, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	entry, err := ledger.Append(ctx, tx, owner, ledger.AccountEscrow, ledger.KindDeposit, ledger.Asset(d.Chain, d.Asset), d.Amount, EscrowReference(projectID, d.TransferID))
+	if err != nil {
+		return false, err
+	}
+	// Another instance may have credited it since it was listed; its row
+	// wins and this ledger entry is rolled back.
+	tag, err := tx.Exec(ctx, `
+INSERT INTO project_escrow_deposits (transfer_id, project_id, chain, asset, amount, tx_hash, from_address, ledger_entry_id)
+VALUES ($1, $2, $3, $4, $5::numeric, $6, $7, $8)
+ON CONFLICT (transfer_id) DO NOTHING
+`, d.TransferID, projectID, d.Chain, d.Asset, d.Amount, d.TxHash, d.From, entry.ID)
+	if err != nil || tag.RowsAffected() == 0 {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE chain_transfers SET status = 'credited' WHERE id = $1`, d.TransferID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
@@ -0,0 +1,149 @@
+// Package escrow lets trust-minimized organizations fund bounties into an
+// on-chain escrow contract instead of the platform hot wallet. The
+// maintainer locks the reward themselves; the platform only submits the
+// contract's release call, and only with the maintainer's signed approval
+// of the recipient and amount.
+package escrow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+var (
+	ErrApproverNotOwner = errors.New("approver_not_project_owner")
+	ErrBadApproval      = errors.New("invalid_approval_signature")
+)
+
+// Contracts maps each chain with an escrow contract deployed to its address.
+// Only Stellar (Soroban) has one so far.
+func Contracts(cfg config.Config) map[string]string {
+	out := map[string]string{}
+	if c := strings.TrimSpace(cfg.EscrowContractID); c != "" {
+		out["stellar"] = c
+	}
+	return out
+}
+
+// Contract submits release calls to one chain's escrow contract.
+type Contract interface {
+	// Release pays the reward locked under ref to to, returning the
+	// transaction hash.
+	Release(ctx context.Context, ref int64, to string) (string, error)
+}
+
+// Registry maps chain name to its escrow Contract.
+type Registry map[string]Contract
+
+func (r Registry) Get(chain string) (Contract, bool) {
+	c, ok := r[strings.ToLower(strings.TrimSpace(chain))]
+	return c, ok
+}
+
+// NewRegistryFromConfig connects to every configured escrow contract that
+// has a release key. Misconfigured chains are logged and skipped.
+func NewRegistryFromConfig(cfg config.Config) Registry {
+	r := Registry{}
+	if addr, ok := Contracts(cfg)["stellar"]; ok {
+		c, err := newStellarContract(cfg, addr)
+		if err != nil {
+			slog.Error("stellar escrow releases disabled", "error", err)
+		} else {
+			r["stellar"] = c
+		}
+	}
+	return r
+}
+
+type stellarContract struct {
+	ec *soroban.EscrowContract
+}
+
+func newStellarContract(cfg config.Config, addr string) (*stellarContract, error) {
+	if cfg.SorobanRPCURL == "" || cfg.SorobanSourceSecret == "" {
+		return nil, fmt.Errorf("SOROBAN_RPC_URL and SOROBAN_SOURCE_SECRET are required")
+	}
+	client, err := soroban.NewClient(soroban.Config{
+		RPCURL:            cfg.SorobanRPCURL,
+		NetworkPassphrase: cfg.SorobanNetworkPassphrase,
+		Network:           soroban.Network(cfg.SorobanNetwork),
+	})
+	if err != nil {
+		return nil, err
+	}
+	tb, err := soroban.NewTransactionBuilder(client, cfg.SorobanSourceSecret, soroban.DefaultRetryConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &stellarContract{ec: soroban.NewEscrowContract(client, tb, addr)}, nil
+}
+
+func (c *stellarContract) Release(ctx context.Context, ref int64, to string) (string, error) {
+	if ref < 0 {
+		return "", fmt.Errorf("invalid escrow ref %d", ref)
+	}
+	res, err := c.ec.ReleaseFunds(ctx, uint64(ref), to)
+	if err != nil {
+		return "", err
+	}
+	return res.Hash, nil
+}
+
+// ApprovalMessage is what a maintainer signs to approve releasing an escrow
+// bounty's reward to an address.
+func ApprovalMessage(bountyID uuid.UUID, chain, contract string, ref int64, to, amount, asset string) string {
+	return "Grainlify escrow release\n" +
+		"Bounty: " + bountyID.String() + "\n" +
+		"Chain: " + chain + "\n" +
+		"Contract: " + contract + "\n" +
+		"Escrow ref: " + strconv.FormatInt(ref, 10) + "\n" +
+		"Recipient: " + to + "\n" +
+		"Amount: " + amount + " " + asset
+}
+
+// Approval is a maintainer's wallet signature over an ApprovalMessage.
+type Approval struct {
+	WalletType string `json:"wallet_type"`
+	Address    string `json:"address"`
+	Signature  string `json:"signature"`
+	PublicKey  string `json:"public_key,omitempty"`
+}
+
+// VerifyApproval checks that a is a valid signature over msg by a wallet
+// linked to the owner of projectID.
+func VerifyApproval(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, a Approval, msg string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	wt := auth.WalletType(strings.TrimSpace(a.WalletType))
+	addr, err := auth.NormalizeAddress(wt, a.Address)
+	if err != nil {
+		return ErrBadApproval
+	}
+	var owns bool
+	if err := pool.QueryRow(ctx, `
+SELECT EXISTS(
+  SELECT 1 FROM wallets w JOIN projects p ON p.owner_user_id = w.user_id
+  WHERE p.id = $1 AND w.wallet_type = $2 AND w.address = $3
+)`, projectID, string(wt), addr).Scan(&owns); err != nil {
+		return err
+	}
+	if !owns {
+		return ErrApproverNotOwner
+	}
+	if err := auth.VerifySignature(wt, addr, msg, a.Signature, a.PublicKey); err != nil {
+		return ErrBadApproval
+	}
+	return nil
+}
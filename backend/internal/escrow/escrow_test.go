@@ -0,0 +1,21 @@
+package escrow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestApprovalMessageBindsRecipientAndAmount(t *testing.T) {
+	id := uuid.MustParse("7f1c2d3e-4b5a-6978-8a9b-0c1d2e3f4a5b")
+	msg := ApprovalMessage(id, "stellar", "CCONTRACT", 42, "GRECIPIENT", "150.5", "USDC")
+	for _, want := range []string{id.String(), "Escrow ref: 42", "Recipient: GRECIPIENT", "Amount: 150.5 USDC"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+	if other := ApprovalMessage(id, "stellar", "CCONTRACT", 42, "GOTHER", "150.5", "USDC"); other == msg {
+		t.Error("message doesn't change with the recipient")
+	}
+}
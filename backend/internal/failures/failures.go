@@ -0,0 +1,148 @@
+// Package failures classifies errors from asynchronous work (payout sends,
+// GitHub syncs) into stable codes with remediation hints. Codes are stored
+// next to the raw error and surfaced in API responses and notifications, so
+// integrators can branch on them instead of parsing messages.
+package failures
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chaos"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// Failure codes.
+const (
+	CodeInsufficientFunds  = "insufficient_funds"
+	CodeInvalidRecipient   = "invalid_recipient"
+	CodeAmountPrecision    = "amount_precision"
+	CodeTxReverted         = "tx_reverted"
+	CodeUpstreamTimeout    = "upstream_timeout"
+	CodeUpstreamError      = "upstream_unavailable"
+	CodeGitHubNotLinked    = "github_not_linked"
+	CodeGitHubUnauthorized = "github_unauthorized"
+	CodeGitHubForbidden    = "github_forbidden"
+	CodeGitHubRateLimited  = "github_rate_limited"
+	CodeGitHubNotFound     = "github_not_found"
+	CodeInjectedFault      = "injected_fault"
+	CodeUnknown            = "unknown"
+)
+
+// Failure is the structured form of an error, as integrators see it.
+type Failure struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+	// Retryable says whether trying again unchanged may succeed.
+	Retryable bool `json:"retryable"`
+}
+
+type info struct {
+	remediation string
+	retryable   bool
+}
+
+var catalog = map[string]info{
+	CodeInsufficientFunds:  {"Top up the platform hot wallet for this chain and asset, then retry the payout.", false},
+	CodeInvalidRecipient:   {"Ask the recipient to fix their payout address (it must exist and, for Stellar assets, trust the asset), then retry.", false},
+	CodeAmountPrecision:    {"Re-create the payout with no more decimal places than the asset supports.", false},
+	CodeTxReverted:         {"The transaction was included but reverted, so nothing was paid. Check the hot wallet's balance and the recipient, then retry the payout.", false},
+	CodeUpstreamTimeout:    {"The remote service did not answer in time. Retry later; for payouts, confirm on-chain that nothing landed first.", true},
+	CodeUpstreamError:      {"The remote service could not be reached. Retry later; for payouts, confirm on-chain that nothing landed first.", true},
+	CodeGitHubNotLinked:    {"The project owner must link their GitHub account, then trigger a new sync.", false},
+	CodeGitHubUnauthorized: {"The GitHub token was revoked or expired. The owner must re-link GitHub, then trigger a new sync.", false},
+	CodeGitHubForbidden:    {"The linked GitHub account lacks access to this repository. Grant access or re-link with the required scopes.", false},
+	CodeGitHubRateLimited:  {"GitHub's rate limit was exhausted. Retry after it resets.", true},
+	CodeGitHubNotFound:     {"The repository was not found. Check it was not renamed, deleted or made private.", false},
+	CodeInjectedFault:      {"A fault was injected by chaos testing; retry.", true},
+	CodeUnknown:            {"Retry; contact support with the failure message if it persists.", true},
+}
+
+// New returns the Failure for code with message, filling in its hint.
+func New(code, message string) Failure {
+	in, ok := catalog[code]
+	if !ok {
+		code, in = CodeUnknown, catalog[CodeUnknown]
+	}
+	return Failure{Code: code, Message: message, Remediation: in.remediation, Retryable: in.retryable}
+}
+
+// FromStored rebuilds a Failure from stored columns; nil when nothing failed.
+// Rows from before codes were stored classify as unknown.
+func FromStored(code, message *string) *Failure {
+	if message == nil && code == nil {
+		return nil
+	}
+	var c, m string
+	if code != nil {
+		c = *code
+	}
+	if message != nil {
+		m = *message
+	}
+	f := New(c, m)
+	return &f
+}
+
+// Classify maps err to a Failure.
+func Classify(err error) Failure {
+	if err == nil {
+		return Failure{}
+	}
+	return New(code(err), err.Error())
+}
+
+func code(err error) string {
+	var ghErr *github.GitHubAPIError
+	if errors.As(err, &ghErr) {
+		switch {
+		case ghErr.StatusCode == 429,
+			ghErr.StatusCode == 403 && ghErr.RateLimitRemaining != nil && *ghErr.RateLimitRemaining == 0:
+			return CodeGitHubRateLimited
+		case ghErr.StatusCode == 401:
+			return CodeGitHubUnauthorized
+		case ghErr.StatusCode == 403:
+			return CodeGitHubForbidden
+		case ghErr.StatusCode == 404:
+			return CodeGitHubNotFound
+		case ghErr.StatusCode >= 500:
+			return CodeUpstreamError
+		}
+	}
+	if errors.Is(err, chaos.ErrInjected) {
+		return CodeInjectedFault
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CodeUpstreamTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return CodeUpstreamTimeout
+		}
+		return CodeUpstreamError
+	}
+
+	// Chain and sync errors mostly arrive as text from RPC nodes and Horizon.
+	msg := strings.ToLower(err.Error())
+	for _, m := range []struct{ code, fragment string }{
+		{CodeGitHubNotLinked, "github_not_linked"},
+		{CodeInsufficientFunds, "insufficient funds"},
+		{CodeInsufficientFunds, "tx_insufficient_balance"},
+		{CodeInsufficientFunds, "op_underfunded"},
+		{CodeInvalidRecipient, "op_no_destination"},
+		{CodeInvalidRecipient, "op_no_trust"},
+		{CodeInvalidRecipient, "op_line_full"},
+		{CodeInvalidRecipient, "invalid evm address"},
+		{CodeAmountPrecision, "exceeds asset precision"},
+		{CodeAmountPrecision, "exceeds token precision"},
+	} {
+		if strings.Contains(msg, m.fragment) {
+			return m.code
+		}
+	}
+	return CodeUnknown
+}
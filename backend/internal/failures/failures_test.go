@@ -0,0 +1,58 @@
+package failures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chaos"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+func TestClassify(t *testing.T) {
+	zero := 0
+	cases := []struct {
+		err  error
+		code string
+	}{
+		{fmt.Errorf("send transaction: %w", errors.New("insufficient funds for gas * price + value")), CodeInsufficientFunds},
+		{errors.New("stellar submit failed (tx_failed, op_underfunded): horizon error"), CodeInsufficientFunds},
+		{errors.New("stellar submit failed (tx_failed, op_no_trust): horizon error"), CodeInvalidRecipient},
+		{fmt.Errorf("github_not_linked: %w", errors.New("no rows")), CodeGitHubNotLinked},
+		{&github.GitHubAPIError{StatusCode: 401}, CodeGitHubUnauthorized},
+		{fmt.Errorf("list issues: %w", &github.GitHubAPIError{StatusCode: 403, RateLimitRemaining: &zero}), CodeGitHubRateLimited},
+		{&github.GitHubAPIError{StatusCode: 403}, CodeGitHubForbidden},
+		{&github.GitHubAPIError{StatusCode: 404}, CodeGitHubNotFound},
+		{fmt.Errorf("estimate gas: %w", context.DeadlineExceeded), CodeUpstreamTimeout},
+		{chaos.ErrInjected, CodeInjectedFault},
+		{errors.New("something odd"), CodeUnknown},
+	}
+	for _, tc := range cases {
+		f := Classify(tc.err)
+		if f.Code != tc.code {
+			t.Errorf("Classify(%q).Code = %q, want %q", tc.err, f.Code, tc.code)
+		}
+		if f.Message != tc.err.Error() || f.Remediation == "" {
+			t.Errorf("Classify(%q) = %+v", tc.err, f)
+		}
+	}
+}
+
+func TestFromStored(t *testing.T) {
+	if FromStored(nil, nil) != nil {
+		t.Fatal("FromStored(nil, nil) should be nil")
+	}
+	msg := "boom"
+	if f := FromStored(nil, &msg); f == nil || f.Code != CodeUnknown || f.Message != msg {
+		t.Fatalf("legacy row = %+v", f)
+	}
+	code := "no_longer_defined"
+	if f := FromStored(&code, &msg); f.Code != CodeUnknown {
+		t.Fatalf("unknown code = %+v", f)
+	}
+	code = CodeGitHubRateLimited
+	if f := FromStored(&code, &msg); f.Code != code || !f.Retryable {
+		t.Fatalf("stored code = %+v", f)
+	}
+}
@@ -0,0 +1,242 @@
+package fraud
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Rule expressions are small boolean formulas over numeric facts, e.g.
+//
+//	account_age_days < 7 and (claims_24h >= 5 or ip_accounts_24h > 3)
+//
+// Grammar:
+//
+//	expr  := or
+//	or    := and ("or" | "||") and ...
+//	and   := unary ("and" | "&&") unary ...
+//	unary := ("not" | "!") unary | "(" expr ")" | ident op number
+//	op    := "<" | "<=" | ">" | ">=" | "==" | "!="
+
+// Facts are the numeric inputs a rule is evaluated against.
+type Facts map[string]float64
+
+// Expr is a parsed rule expression.
+type Expr interface {
+	Eval(f Facts) (bool, error)
+	String() string
+}
+
+type cmpExpr struct {
+	field string
+	op    string
+	value float64
+}
+
+func (e cmpExpr) Eval(f Facts) (bool, error) {
+	v, ok := f[e.field]
+	if !ok {
+		return false, fmt.Errorf("unknown fact %q", e.field)
+	}
+	switch e.op {
+	case "<":
+		return v < e.value, nil
+	case "<=":
+		return v <= e.value, nil
+	case ">":
+		return v > e.value, nil
+	case ">=":
+		return v >= e.value, nil
+	case "==":
+		return v == e.value, nil
+	case "!=":
+		return v != e.value, nil
+	}
+	return false, fmt.Errorf("unknown operator %q", e.op)
+}
+
+func (e cmpExpr) String() string {
+	return fmt.Sprintf("%s %s %s", e.field, e.op, strconv.FormatFloat(e.value, 'f', -1, 64))
+}
+
+type boolExpr struct {
+	op          string // "and" | "or"
+	left, right Expr
+}
+
+func (e boolExpr) Eval(f Facts) (bool, error) {
+	l, err := e.left.Eval(f)
+	if err != nil {
+		return false, err
+	}
+	if e.op == "and" && !l {
+		return false, nil
+	}
+	if e.op == "or" && l {
+		return true, nil
+	}
+	return e.right.Eval(f)
+}
+
+func (e boolExpr) String() string {
+	return "(" + e.left.String() + " " + e.op + " " + e.right.String() + ")"
+}
+
+type notExpr struct{ inner Expr }
+
+func (e notExpr) Eval(f Facts) (bool, error) {
+	v, err := e.inner.Eval(f)
+	return !v, err
+}
+
+func (e notExpr) String() string { return "not " + e.inner.String() }
+
+// Parse compiles a rule expression. Every fact it references must be one of KnownFacts.
+func Parse(src string) (Expr, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := &parser{toks: toks}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q", p.toks[p.pos])
+	}
+	return e, nil
+}
+
+type parser struct {
+	toks []string
+	pos  int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.toks) {
+		return ""
+	}
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := strings.ToLower(p.peek()); t == "or" || t == "||"; t = strings.ToLower(p.peek()) {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = boolExpr{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := strings.ToLower(p.peek()); t == "and" || t == "&&"; t = strings.ToLower(p.peek()) {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = boolExpr{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch t := strings.ToLower(p.peek()); t {
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case "not", "!":
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner: inner}, nil
+	case "(":
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		return e, nil
+	}
+
+	field := strings.ToLower(p.next())
+	if _, ok := KnownFacts[field]; !ok {
+		return nil, fmt.Errorf("unknown fact %q", field)
+	}
+	op := p.next()
+	switch op {
+	case "<", "<=", ">", ">=", "==", "!=":
+	default:
+		return nil, fmt.Errorf("expected comparison after %q, got %q", field, op)
+	}
+	raw := p.next()
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("expected number after %q, got %q", op, raw)
+	}
+	return cmpExpr{field: field, op: op, value: v}, nil
+}
+
+func tokenize(src string) ([]string, error) {
+	var out []string
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			out = append(out, string(r))
+			i++
+		case strings.ContainsRune("<>=!&|", r):
+			j := i + 1
+			if j < len(rs) && strings.ContainsRune("=&|", rs[j]) {
+				j++
+			}
+			tok := string(rs[i:j])
+			switch tok {
+			case "<", "<=", ">", ">=", "==", "!=", "!", "&&", "||":
+			default:
+				return nil, fmt.Errorf("invalid operator %q", tok)
+			}
+			out = append(out, tok)
+			i = j
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_' || rs[j] == '.' || rs[j] == '-') {
+				j++
+			}
+			out = append(out, string(rs[i:j]))
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return out, nil
+}
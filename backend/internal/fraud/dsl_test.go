@@ -0,0 +1,54 @@
+package fraud
+
+import "testing"
+
+func TestParseAndEval(t *testing.T) {
+	facts := Facts{
+		"account_age_days": 2,
+		"claims_24h":       6,
+		"ip_accounts_24h":  1,
+		"amount":           0,
+	}
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"account_age_days < 7", true},
+		{"account_age_days < 7 and claims_24h >= 5", true},
+		{"account_age_days >= 7 or ip_accounts_24h > 3", false},
+		{"account_age_days < 7 && (claims_24h > 10 || ip_accounts_24h == 1)", true},
+		{"not (claims_24h > 5)", false},
+		{"!amount != 0", true},
+	}
+	for _, tc := range cases {
+		e, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tc.expr, err)
+		}
+		got, err := e.Eval(facts)
+		if err != nil {
+			t.Fatalf("Eval(%q) failed: %v", tc.expr, err)
+		}
+		if got != tc.want {
+			t.Errorf("Eval(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"account_age_days",
+		"account_age_days < seven",
+		"unknown_fact > 1",
+		"(claims_24h > 1",
+		"claims_24h > 1 and",
+		"claims_24h => 1",
+		"claims_24h > 1 claims_24h > 2",
+	} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) should fail", src)
+		}
+	}
+}
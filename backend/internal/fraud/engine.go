@@ -0,0 +1,193 @@
+package fraud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	EventClaim  = "claim"
+	EventPayout = "payout"
+)
+
+// KnownFacts lists every fact a rule may reference, with a short description
+// that the admin UI shows next to the rule editor.
+var KnownFacts = map[string]string{
+	"account_age_days":  "Days since the user account was created",
+	"amount":            "Amount of the current payout (0 for claims)",
+	"claims_24h":        "Claims made by the user in the last 24h, including this one",
+	"payouts_24h":       "Payouts to the user in the last 24h, including this one",
+	"payout_amount_24h": "Sum of payouts to the user in the last 24h, including this one",
+	"ip_accounts_24h":   "Distinct accounts seen from the request IP in the last 24h",
+	"open_reviews":      "Fraud reviews currently open for the user",
+}
+
+// Subject describes the action being evaluated.
+type Subject struct {
+	Event     string
+	UserID    uuid.UUID
+	SubjectID string // e.g. "<project_id>#<issue_number>" for claims
+	IP        string
+	Amount    float64
+}
+
+// Match is a rule that fired for a subject.
+type Match struct {
+	ReviewID uuid.UUID `json:"review_id"`
+	RuleID   uuid.UUID `json:"rule_id"`
+	RuleName string    `json:"rule_name"`
+}
+
+type Engine struct {
+	Pool *pgxpool.Pool
+}
+
+func NewEngine(pool *pgxpool.Pool) *Engine {
+	return &Engine{Pool: pool}
+}
+
+// Evaluate records the signal, runs all enabled rules for s.Event, and queues
+// a review for every rule that matches. It never blocks the action itself.
+func (e *Engine) Evaluate(ctx context.Context, s Subject) ([]Match, error) {
+	if e == nil || e.Pool == nil {
+		return nil, nil
+	}
+	if s.Event != EventClaim && s.Event != EventPayout {
+		return nil, fmt.Errorf("unsupported fraud event %q", s.Event)
+	}
+
+	_, err := e.Pool.Exec(ctx, `
+INSERT INTO fraud_signals (user_id, event, subject_id, ip, amount)
+VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+`, s.UserID, s.Event, s.SubjectID, s.IP, s.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("record fraud signal: %w", err)
+	}
+
+	facts, err := e.facts(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Pool.Query(ctx, `
+SELECT id, name, expression
+FROM fraud_rules
+WHERE enabled AND $1 = ANY(events)
+ORDER BY name
+`, s.Event)
+	if err != nil {
+		return nil, err
+	}
+	type rule struct {
+		id   uuid.UUID
+		name string
+		expr string
+	}
+	var rules []rule
+	for rows.Next() {
+		var r rule
+		if err := rows.Scan(&r.id, &r.name, &r.expr); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	factsJSON, _ := json.Marshal(facts)
+	var matches []Match
+	for _, r := range rules {
+		expr, err := Parse(r.expr)
+		if err != nil {
+			slog.Warn("skipping invalid fraud rule", "rule", r.name, "error", err)
+			continue
+		}
+		hit, err := expr.Eval(facts)
+		if err != nil {
+			slog.Warn("fraud rule evaluation failed", "rule", r.name, "error", err)
+			continue
+		}
+		if !hit {
+			continue
+		}
+
+		var reviewID uuid.UUID
+		if err := e.Pool.QueryRow(ctx, `
+INSERT INTO fraud_reviews (rule_id, rule_name, user_id, event, subject_id, facts)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6::jsonb)
+RETURNING id
+`, r.id, r.name, s.UserID, s.Event, s.SubjectID, factsJSON).Scan(&reviewID); err != nil {
+			return matches, fmt.Errorf("queue fraud review: %w", err)
+		}
+		slog.Info("fraud rule matched",
+			"rule", r.name,
+			"event", s.Event,
+			"user_id", s.UserID.String(),
+			"review_id", reviewID.String(),
+		)
+		matches = append(matches, Match{ReviewID: reviewID, RuleID: r.id, RuleName: r.name})
+	}
+	return matches, nil
+}
+
+// Flag queues a review for a check made in code rather than by an admin
+// rule, such as unsigned commits behind a large payout. Like rules, it
+// never blocks the action.
+func (e *Engine) Flag(ctx context.Context, name string, s Subject, facts Facts) (uuid.UUID, error) {
+	if e == nil || e.Pool == nil {
+		return uuid.Nil, fmt.Errorf("db not configured")
+	}
+	factsJSON, _ := json.Marshal(facts)
+	var reviewID uuid.UUID
+	err := e.Pool.QueryRow(ctx, `
+INSERT INTO fraud_reviews (rule_name, user_id, event, subject_id, facts)
+VALUES ($1, $2, $3, NULLIF($4, ''), $5::jsonb)
+RETURNING id
+`, name, s.UserID, s.Event, s.SubjectID, factsJSON).Scan(&reviewID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("queue fraud review: %w", err)
+	}
+	slog.Info("fraud review flagged",
+		"rule", name,
+		"event", s.Event,
+		"user_id", s.UserID.String(),
+		"review_id", reviewID.String(),
+	)
+	return reviewID, nil
+}
+
+func (e *Engine) facts(ctx context.Context, s Subject) (Facts, error) {
+	f := Facts{"amount": s.Amount}
+
+	var ageDays, claims, payouts, payoutAmount, ipAccounts, openReviews float64
+	err := e.Pool.QueryRow(ctx, `
+SELECT
+  COALESCE(EXTRACT(EPOCH FROM (now() - u.created_at)) / 86400, 0)::float8,
+  (SELECT COUNT(*) FROM fraud_signals WHERE user_id = u.id AND event = 'claim' AND created_at > now() - interval '24 hours')::float8,
+  (SELECT COUNT(*) FROM fraud_signals WHERE user_id = u.id AND event = 'payout' AND created_at > now() - interval '24 hours')::float8,
+  (SELECT COALESCE(SUM(amount), 0) FROM fraud_signals WHERE user_id = u.id AND event = 'payout' AND created_at > now() - interval '24 hours')::float8,
+  (SELECT COUNT(DISTINCT user_id) FROM fraud_signals WHERE $2 <> '' AND ip = $2 AND created_at > now() - interval '24 hours')::float8,
+  (SELECT COUNT(*) FROM fraud_reviews WHERE user_id = u.id AND status = 'open')::float8
+FROM users u
+WHERE u.id = $1
+`, s.UserID, s.IP).Scan(&ageDays, &claims, &payouts, &payoutAmount, &ipAccounts, &openReviews)
+	if err != nil {
+		return nil, fmt.Errorf("load fraud facts: %w", err)
+	}
+
+	f["account_age_days"] = ageDays
+	f["claims_24h"] = claims
+	f["payouts_24h"] = payouts
+	f["payout_amount_24h"] = payoutAmount
+	f["ip_accounts_24h"] = ipAccounts
+	f["open_reviews"] = openReviews
+	return f, nil
+}
@@ -0,0 +1,262 @@
+// Package geo applies the platform's country restrictions to money movement
+// (funding bounties, receiving payouts). A user's country comes from what they
+// declared and from the IP country reported by the edge; each policy chooses
+// which of the two it trusts.
+package geo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Restricted actions.
+const (
+	ActionBountyFunding = "bounty_funding"
+	ActionPayout        = "payout"
+)
+
+// Actions lists every restricted action.
+var Actions = []string{ActionBountyFunding, ActionPayout}
+
+// Sources a restriction can be based on.
+const (
+	SourceIP       = "ip"
+	SourceDeclared = "declared"
+	SourceUnknown  = "unknown"
+)
+
+var (
+	ErrUnknownAction  = errors.New("unknown_action")
+	ErrInvalidCountry = errors.New("invalid_country")
+	ErrUserNotFound   = errors.New("user_not_found")
+)
+
+// Policy restricts one action. The zero policy allows everything.
+type Policy struct {
+	Action           string     `json:"action"`
+	BlockedCountries []string   `json:"blocked_countries"`
+	CheckIP          bool       `json:"check_ip"`
+	CheckDeclared    bool       `json:"check_declared"`
+	BlockUnknown     bool       `json:"block_unknown"`
+	UpdatedBy        *uuid.UUID `json:"updated_by"`
+	UpdatedAt        *time.Time `json:"updated_at"`
+}
+
+// Restriction says why an action was blocked.
+type Restriction struct {
+	Action  string `json:"action"`
+	Country string `json:"country,omitempty"`
+	Source  string `json:"source"`
+}
+
+func (r *Restriction) Error() string { return "region_restricted" }
+
+// NormalizeCountry returns c as an upper-case ISO 3166-1 alpha-2 code.
+func NormalizeCountry(c string) (string, error) {
+	c = strings.ToUpper(strings.TrimSpace(c))
+	if len(c) != 2 || c[0] < 'A' || c[0] > 'Z' || c[1] < 'A' || c[1] > 'Z' {
+		return "", ErrInvalidCountry
+	}
+	return c, nil
+}
+
+// CountryFromHeader reads an edge geolocation header such as CF-IPCountry.
+// Unknown ("XX") and Tor ("T1") yield "", which only block_unknown rejects.
+func CountryFromHeader(v string) string {
+	c, err := NormalizeCountry(v)
+	if err != nil || c == "XX" {
+		return ""
+	}
+	return c
+}
+
+// Normalize validates p, sorting and de-duplicating its countries.
+func (p *Policy) Normalize() error {
+	valid := false
+	for _, a := range Actions {
+		valid = valid || a == p.Action
+	}
+	if !valid {
+		return ErrUnknownAction
+	}
+	seen := map[string]bool{}
+	out := []string{}
+	for _, c := range p.BlockedCountries {
+		n, err := NormalizeCountry(c)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidCountry, c)
+		}
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	sort.Strings(out)
+	p.BlockedCountries = out
+	return nil
+}
+
+// Check applies p to a user seen from ipCountry who declared declared (either
+// may be empty). It returns nil when the action is allowed.
+func (p Policy) Check(ipCountry, declared string) *Restriction {
+	blocked := func(c string) bool {
+		for _, b := range p.BlockedCountries {
+			if b == c {
+				return true
+			}
+		}
+		return false
+	}
+	known := false
+	if p.CheckIP && ipCountry != "" {
+		known = true
+		if blocked(ipCountry) {
+			return &Restriction{Action: p.Action, Country: ipCountry, Source: SourceIP}
+		}
+	}
+	if p.CheckDeclared && declared != "" {
+		known = true
+		if blocked(declared) {
+			return &Restriction{Action: p.Action, Country: declared, Source: SourceDeclared}
+		}
+	}
+	if p.BlockUnknown && !known {
+		return &Restriction{Action: p.Action, Source: SourceUnknown}
+	}
+	return nil
+}
+
+// GetPolicy returns the policy for action; unset policies allow everything.
+func GetPolicy(ctx context.Context, pool *pgxpool.Pool, action string) (Policy, error) {
+	if pool == nil {
+		return Policy{}, fmt.Errorf("db not configured")
+	}
+	p := Policy{Action: action, BlockedCountries: []string{}, CheckIP: true, CheckDeclared: true}
+	err := pool.QueryRow(ctx, `
+SELECT blocked_countries, check_ip, check_declared, block_unknown, updated_by, updated_at
+FROM geo_policies
+WHERE action = $1
+`, action).Scan(&p.BlockedCountries, &p.CheckIP, &p.CheckDeclared, &p.BlockUnknown, &p.UpdatedBy, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, nil
+	}
+	return p, err
+}
+
+// ListPolicies returns the policy of every action.
+func ListPolicies(ctx context.Context, pool *pgxpool.Pool) ([]Policy, error) {
+	out := make([]Policy, 0, len(Actions))
+	for _, a := range Actions {
+		p, err := GetPolicy(ctx, pool, a)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// SetPolicy replaces the policy for p.Action.
+func SetPolicy(ctx context.Context, pool *pgxpool.Pool, actorID uuid.UUID, p Policy) (Policy, error) {
+	if pool == nil {
+		return Policy{}, fmt.Errorf("db not configured")
+	}
+	if err := p.Normalize(); err != nil {
+		return Policy{}, err
+	}
+	p.UpdatedBy = &actorID
+	var updatedAt time.Time
+	err := pool.QueryRow(ctx, `
+INSERT INTO geo_policies (action, blocked_countries, check_ip, check_declared, block_unknown, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (action) DO UPDATE SET
+  blocked_countries = EXCLUDED.blocked_countries,
+  check_ip = EXCLUDED.check_ip,
+  check_declared = EXCLUDED.check_declared,
+  block_unknown = EXCLUDED.block_unknown,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = now()
+RETURNING updated_at
+`, p.Action, p.BlockedCountries, p.CheckIP, p.CheckDeclared, p.BlockUnknown, actorID).Scan(&updatedAt)
+	p.UpdatedAt = &updatedAt
+	return p, err
+}
+
+// UserCountries returns the user's declared country and the IP country seen
+// at their last sign-in ("" when unknown).
+func UserCountries(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (declared, lastIP string, err error) {
+	if pool == nil {
+		return "", "", fmt.Errorf("db not configured")
+	}
+	err = pool.QueryRow(ctx, `SELECT COALESCE(country, ''), COALESCE(last_ip_country, '') FROM users WHERE id = $1`, userID).Scan(&declared, &lastIP)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrUserNotFound
+	}
+	return declared, lastIP, err
+}
+
+// SetDeclaredCountry stores the user's self-declared country; "" clears it.
+func SetDeclaredCountry(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, country string) (string, error) {
+	if pool == nil {
+		return "", fmt.Errorf("db not configured")
+	}
+	if strings.TrimSpace(country) != "" {
+		c, err := NormalizeCountry(country)
+		if err != nil {
+			return "", err
+		}
+		country = c
+	} else {
+		country = ""
+	}
+	ct, err := pool.Exec(ctx, `UPDATE users SET country = NULLIF($2, ''), updated_at = now() WHERE id = $1`, userID, country)
+	if err != nil {
+		return "", err
+	}
+	if ct.RowsAffected() == 0 {
+		return "", ErrUserNotFound
+	}
+	return country, nil
+}
+
+// RecordIPCountry remembers the IP country a user signed in from, so actions
+// taken on their behalf (such as admin-created payouts) can be checked.
+func RecordIPCountry(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, country string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	if country == "" {
+		return nil
+	}
+	_, err := pool.Exec(ctx, `UPDATE users SET last_ip_country = $2, last_ip_country_at = now() WHERE id = $1`, userID, country)
+	return err
+}
+
+// CheckUser applies the action's policy to userID. ipCountry is the country
+// of the current request when the user is making it, or "" to use the one
+// from their last sign-in.
+func CheckUser(ctx context.Context, pool *pgxpool.Pool, action string, userID uuid.UUID, ipCountry string) (*Restriction, error) {
+	p, err := GetPolicy(ctx, pool, action)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.BlockedCountries) == 0 && !p.BlockUnknown {
+		return nil, nil
+	}
+	declared, lastIP, err := UserCountries(ctx, pool, userID)
+	if err != nil {
+		return nil, err
+	}
+	if ipCountry == "" {
+		ipCountry = lastIP
+	}
+	return p.Check(ipCountry, declared), nil
+}
@@ -0,0 +1,51 @@
+package geo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	p := Policy{Action: ActionPayout, BlockedCountries: []string{"ir", " KP", "IR"}, CheckIP: true, CheckDeclared: true}
+	if err := p.Normalize(); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if !reflect.DeepEqual(p.BlockedCountries, []string{"IR", "KP"}) {
+		t.Fatalf("BlockedCountries = %v", p.BlockedCountries)
+	}
+
+	for _, tc := range []struct {
+		name         string
+		policy       Policy
+		ip, declared string
+		want         *Restriction
+	}{
+		{"allowed", p, "DE", "DE", nil},
+		{"blocked by ip", p, "KP", "DE", &Restriction{Action: ActionPayout, Country: "KP", Source: SourceIP}},
+		{"blocked by declaration", p, "DE", "IR", &Restriction{Action: ActionPayout, Country: "IR", Source: SourceDeclared}},
+		{"ip not checked", Policy{Action: ActionPayout, BlockedCountries: []string{"KP"}, CheckDeclared: true}, "KP", "DE", nil},
+		{"unknown allowed", p, "", "", nil},
+		{"unknown blocked", Policy{Action: ActionPayout, CheckIP: true, BlockUnknown: true}, "", "DE", &Restriction{Action: ActionPayout, Source: SourceUnknown}},
+		{"known via ip", Policy{Action: ActionPayout, CheckIP: true, BlockUnknown: true}, "DE", "", nil},
+	} {
+		if got := tc.policy.Check(tc.ip, tc.declared); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Check = %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+
+	if err := (&Policy{Action: "withdraw"}).Normalize(); !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("unknown action: %v", err)
+	}
+	if err := (&Policy{Action: ActionPayout, BlockedCountries: []string{"IRN"}}).Normalize(); !errors.Is(err, ErrInvalidCountry) {
+		t.Errorf("alpha-3 code: %v", err)
+	}
+}
+
+func TestCountryFromHeader(t *testing.T) {
+	for in, want := range map[string]string{"us": "US", " GB ": "GB", "XX": "", "T1": "", "": "", "USA": ""} {
+		if got := CountryFromHeader(in); got != want {
+			t.Errorf("CountryFromHeader(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
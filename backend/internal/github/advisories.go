@@ -0,0 +1,121 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RepositoryAdvisory is a GitHub Security Advisory drafted on a repository.
+type RepositoryAdvisory struct {
+	Summary         string                  `json:"summary"`
+	Description     string                  `json:"description"`
+	Severity        string                  `json:"severity,omitempty"`
+	CVEID           string                  `json:"cve_id,omitempty"`
+	CWEIDs          []string                `json:"cwe_ids,omitempty"`
+	Vulnerabilities []AdvisoryVulnerability `json:"vulnerabilities"`
+}
+
+// AdvisoryUpdate changes a repository advisory; empty fields are kept.
+type AdvisoryUpdate struct {
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+	// State "published" discloses the advisory.
+	State string `json:"state,omitempty"`
+}
+
+// AdvisoryVulnerability is a package an advisory affects.
+type AdvisoryVulnerability struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name,omitempty"`
+	} `json:"package"`
+	VulnerableVersionRange string `json:"vulnerable_version_range,omitempty"`
+	PatchedVersions        string `json:"patched_versions,omitempty"`
+}
+
+// PublishedAdvisory is GitHub's record of a repository advisory.
+type PublishedAdvisory struct {
+	GHSAID  string `json:"ghsa_id"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+}
+
+func advisoriesURL(fullName string) (string, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return "", err
+	}
+	return "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/security-advisories", nil
+}
+
+// CreateRepositoryAdvisory drafts a security advisory on fullName, visible
+// only to the repository's admins and collaborators until published. The
+// token needs the repository_advisories write permission.
+func (c *Client) CreateRepositoryAdvisory(ctx context.Context, accessToken, fullName string, adv RepositoryAdvisory) (PublishedAdvisory, error) {
+	u, err := advisoriesURL(fullName)
+	if err != nil {
+		return PublishedAdvisory{}, err
+	}
+	if adv.Vulnerabilities == nil {
+		adv.Vulnerabilities = []AdvisoryVulnerability{}
+	}
+	var out PublishedAdvisory
+	if err := c.sendJSON(ctx, http.MethodPost, accessToken, u, adv, &out); err != nil {
+		return PublishedAdvisory{}, err
+	}
+	if out.GHSAID == "" {
+		return PublishedAdvisory{}, fmt.Errorf("invalid github advisory response")
+	}
+	return out, nil
+}
+
+// UpdateRepositoryAdvisory changes a repository advisory.
+func (c *Client) UpdateRepositoryAdvisory(ctx context.Context, accessToken, fullName, ghsaID string, upd AdvisoryUpdate) (PublishedAdvisory, error) {
+	u, err := advisoriesURL(fullName)
+	if err != nil {
+		return PublishedAdvisory{}, err
+	}
+	if strings.TrimSpace(ghsaID) == "" {
+		return PublishedAdvisory{}, fmt.Errorf("ghsa id is required")
+	}
+	var out PublishedAdvisory
+	if err := c.sendJSON(ctx, http.MethodPatch, accessToken, u+"/"+url.PathEscape(ghsaID), upd, &out); err != nil {
+		return PublishedAdvisory{}, err
+	}
+	return out, nil
+}
+
+func (c *Client) sendJSON(ctx context.Context, method, accessToken, u string, body, out any) error {
+	if strings.TrimSpace(accessToken) == "" {
+		return fmt.Errorf("missing github access token")
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chaos"
+	"github.com/jagadeesh/grainlify/backend/internal/metrics"
+	"github.com/jagadeesh/grainlify/backend/internal/tracing"
 )
 
 type Client struct {
@@ -13,9 +18,15 @@ type Client struct {
 	UserAgent string
 }
 
+// transport is what every GitHub client sends through: timed into metrics,
+// traced, and subject to chaos faults in chaos builds.
+func transport() http.RoundTripper {
+	return metrics.GitHubTransport(tracing.GitHubTransport(chaos.GitHubTransport(nil)))
+}
+
 func NewClient() *Client {
 	return &Client{
-		HTTP:      &http.Client{Timeout: 10 * time.Second},
+		HTTP:      &http.Client{Timeout: 10 * time.Second, Transport: transport()},
 		UserAgent: "patchwork-backend",
 	}
 }
@@ -38,6 +49,29 @@ type Email struct {
 	Visibility string `json:"visibility"`
 }
 
+// Ping checks the GitHub API is reachable. /rate_limit does not count
+// against the rate limit.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/rate_limit", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("github /rate_limit failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (c *Client) GetUser(ctx context.Context, accessToken string) (User, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/user", nil)
 	if err != nil {
@@ -40,7 +40,7 @@ func NewGitHubAppClient(appID string, privateKeyPEM string) (*GitHubAppClient, e
 	return &GitHubAppClient{
 		AppID:      appID,
 		PrivateKey: privateKey,
-		HTTP:       &http.Client{Timeout: 10 * time.Second},
+		HTTP:       &http.Client{Timeout: 10 * time.Second, Transport: transport()},
 		UserAgent:  "grainlify-backend",
 	}, nil
 }
@@ -71,15 +71,25 @@ type InstallationTokenResponse struct {
 
 // GetInstallationToken gets an installation access token for a specific installation
 func (c *GitHubAppClient) GetInstallationToken(ctx context.Context, installationID string) (string, error) {
+	tokenResp, err := c.CreateInstallationToken(ctx, installationID)
+	if err != nil {
+		return "", err
+	}
+	return tokenResp.Token, nil
+}
+
+// CreateInstallationToken mints an installation access token along with its
+// expiry, for callers that cache it.
+func (c *GitHubAppClient) CreateInstallationToken(ctx context.Context, installationID string) (InstallationTokenResponse, error) {
 	jwtToken, err := c.GenerateJWT()
 	if err != nil {
-		return "", fmt.Errorf("failed to generate JWT: %w", err)
+		return InstallationTokenResponse{}, fmt.Errorf("failed to generate JWT: %w", err)
 	}
 
 	url := fmt.Sprintf("https://api.github.com/app/installations/%s/access_tokens", installationID)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
 	if err != nil {
-		return "", err
+		return InstallationTokenResponse{}, err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+jwtToken)
@@ -90,22 +100,22 @@ func (c *GitHubAppClient) GetInstallationToken(ctx context.Context, installation
 
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		return "", err
+		return InstallationTokenResponse{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		var errBody map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&errBody)
-		return "", fmt.Errorf("failed to get installation token: status %d, error: %v", resp.StatusCode, errBody)
+		return InstallationTokenResponse{}, fmt.Errorf("failed to get installation token: status %d, error: %v", resp.StatusCode, errBody)
 	}
 
 	var tokenResp InstallationTokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", err
+		return InstallationTokenResponse{}, err
 	}
 
-	return tokenResp.Token, nil
+	return tokenResp, nil
 }
 
 // InstallationRepository represents a repository in a GitHub App installation
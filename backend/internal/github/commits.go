@@ -0,0 +1,193 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxPRCommitPages caps how many commits of a pull request are read (100 per page).
+const maxPRCommitPages = 3
+
+// CommitSignature is GitHub's verdict on a commit's GPG/SSH/S-MIME
+// signature. GitHub only marks a signature verified when the key is
+// registered on the committer's GitHub account.
+type CommitSignature struct {
+	SHA string `json:"sha"`
+	// AuthorLogin and CommitterLogin are the GitHub accounts the commit's
+	// emails resolve to; empty when they match no account.
+	AuthorLogin    string `json:"author_login,omitempty"`
+	CommitterLogin string `json:"committer_login,omitempty"`
+	Verified       bool   `json:"verified"`
+	// Reason is GitHub's verification reason: "valid", "unsigned",
+	// "unknown_key", "bad_email", ...
+	Reason string `json:"reason"`
+}
+
+type commitResponse struct {
+	SHA     string `json:"sha"`
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+	// Stats is only returned when fetching a single commit.
+	Stats struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+	} `json:"stats"`
+	Commit struct {
+		Verification struct {
+			Verified bool   `json:"verified"`
+			Reason   string `json:"reason"`
+		} `json:"verification"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Committer *struct {
+		Login string `json:"login"`
+	} `json:"committer"`
+}
+
+func (r commitResponse) signature() CommitSignature {
+	s := CommitSignature{
+		SHA:      r.SHA,
+		Verified: r.Commit.Verification.Verified,
+		Reason:   r.Commit.Verification.Reason,
+	}
+	if r.Author != nil {
+		s.AuthorLogin = r.Author.Login
+	}
+	if r.Committer != nil {
+		s.CommitterLogin = r.Committer.Login
+	}
+	return s
+}
+
+func (c *Client) getJSON(ctx context.Context, accessToken, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(accessToken) != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// GetCommitSignature returns the signature verdict of one commit.
+func (c *Client) GetCommitSignature(ctx context.Context, accessToken, fullName, sha string) (CommitSignature, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return CommitSignature{}, err
+	}
+	var r commitResponse
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/commits/" + url.PathEscape(sha)
+	if err := c.getJSON(ctx, accessToken, u, &r); err != nil {
+		return CommitSignature{}, err
+	}
+	if r.SHA == "" {
+		return CommitSignature{}, fmt.Errorf("invalid github commit response")
+	}
+	return r.signature(), nil
+}
+
+// ListPRCommitSignatures returns the signature verdicts of a pull request's
+// commits, oldest first.
+func (c *Client) ListPRCommitSignatures(ctx context.Context, accessToken, fullName string, number int) ([]CommitSignature, error) {
+	rs, err := c.listPRCommits(ctx, accessToken, fullName, number)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CommitSignature, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, r.signature())
+	}
+	return out, nil
+}
+
+// maxPRStatCommits caps how many commits of a pull request are fetched one
+// by one for their line counts.
+const maxPRStatCommits = 100
+
+// CommitStats is who authored a commit and how many lines it changed.
+type CommitStats struct {
+	SHA string `json:"sha"`
+	// AuthorLogin is the GitHub account the author email resolves to; empty
+	// when it matches no account.
+	AuthorLogin string `json:"author_login,omitempty"`
+	Additions   int    `json:"additions"`
+	Deletions   int    `json:"deletions"`
+	// Counted is false for commits past maxPRStatCommits, whose lines were
+	// not fetched.
+	Counted bool `json:"counted"`
+}
+
+// ListPRCommitStats returns the authors and line counts of a pull request's
+// commits, oldest first, leaving out merge commits.
+func (c *Client) ListPRCommitStats(ctx context.Context, accessToken, fullName string, number int) ([]CommitStats, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := c.listPRCommits(ctx, accessToken, fullName, number)
+	if err != nil {
+		return nil, err
+	}
+	var out []CommitStats
+	for _, r := range rs {
+		if len(r.Parents) > 1 {
+			continue
+		}
+		s := CommitStats{SHA: r.SHA}
+		if r.Author != nil {
+			s.AuthorLogin = r.Author.Login
+		}
+		if len(out) < maxPRStatCommits {
+			var full commitResponse
+			u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/commits/" + url.PathEscape(r.SHA)
+			if err := c.getJSON(ctx, accessToken, u, &full); err != nil {
+				return nil, err
+			}
+			s.Additions, s.Deletions, s.Counted = full.Stats.Additions, full.Stats.Deletions, true
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (c *Client) listPRCommits(ctx context.Context, accessToken, fullName string, number int) ([]commitResponse, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, err
+	}
+	var out []commitResponse
+	for page := 1; page <= maxPRCommitPages; page++ {
+		var rs []commitResponse
+		u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) +
+			"/pulls/" + strconv.Itoa(number) + "/commits?per_page=100&page=" + strconv.Itoa(page)
+		if err := c.getJSON(ctx, accessToken, u, &rs); err != nil {
+			return nil, err
+		}
+		out = append(out, rs...)
+		if len(rs) < 100 {
+			break
+		}
+	}
+	return out, nil
+}
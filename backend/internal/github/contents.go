@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ContentEntry is one entry of a repository directory listing.
+type ContentEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Type is "file", "dir", "symlink" or "submodule".
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+type fileContent struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+func contentsURL(fullName, path string) (string, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return "", err
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/contents"
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if seg != "" {
+			u += "/" + url.PathEscape(seg)
+		}
+	}
+	return u, nil
+}
+
+// ListDir lists a directory of the default branch; "" is the root.
+func (c *Client) ListDir(ctx context.Context, accessToken, fullName, path string) ([]ContentEntry, error) {
+	u, err := contentsURL(fullName, path)
+	if err != nil {
+		return nil, err
+	}
+	var out []ContentEntry
+	if err := c.getJSON(ctx, accessToken, u, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetFile returns a file of the default branch. GitHub serves files up to
+// 1 MB this way.
+func (c *Client) GetFile(ctx context.Context, accessToken, fullName, path string) ([]byte, error) {
+	u, err := contentsURL(fullName, path)
+	if err != nil {
+		return nil, err
+	}
+	var f fileContent
+	if err := c.getJSON(ctx, accessToken, u, &f); err != nil {
+		return nil, err
+	}
+	if f.Type != "file" {
+		return nil, fmt.Errorf("github contents: %s is a %s", path, f.Type)
+	}
+	if f.Encoding != "base64" {
+		return nil, fmt.Errorf("github contents: unsupported encoding %q", f.Encoding)
+	}
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(f.Content, "\n", ""))
+}
+
+// TreeEntry is one file of a repository tree.
+type TreeEntry struct {
+	Path string `json:"path"`
+	// Type is "blob", "tree" or "commit" (a submodule).
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+type treeResponse struct {
+	Tree      []TreeEntry `json:"tree"`
+	Truncated bool        `json:"truncated"`
+}
+
+// ListFiles lists the files of the default branch under the directory path,
+// recursively; "" is the whole repo. GitHub truncates very large trees, in
+// which case only the files it returned are listed.
+func (c *Client) ListFiles(ctx context.Context, accessToken, fullName, path string) ([]TreeEntry, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, err
+	}
+	var t treeResponse
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/git/trees/HEAD?recursive=1"
+	if err := c.getJSON(ctx, accessToken, u, &t); err != nil {
+		return nil, err
+	}
+	path = strings.Trim(path, "/")
+	var out []TreeEntry
+	for _, e := range t.Tree {
+		if e.Type == "blob" && (path == "" || strings.HasPrefix(e.Path, path+"/")) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
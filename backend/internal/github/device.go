@@ -0,0 +1,132 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Errors returned by PollDeviceToken, mirroring the device flow error codes.
+var (
+	ErrAuthorizationPending = errors.New("authorization_pending")
+	ErrSlowDown             = errors.New("slow_down")
+	ErrDeviceCodeExpired    = errors.New("expired_token")
+	ErrAccessDenied         = errors.New("access_denied")
+)
+
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceCode is GitHub's answer to a device flow start: the user enters
+// UserCode at VerificationURI while the caller polls with the device code.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// RequestDeviceCode starts the OAuth device flow. Device flow must be
+// enabled on the OAuth app; no client secret is involved.
+func RequestDeviceCode(ctx context.Context, clientID string, scopes []string) (DeviceCode, error) {
+	if clientID == "" {
+		return DeviceCode{}, fmt.Errorf("github oauth not configured")
+	}
+	body := map[string]string{"client_id": clientID}
+	if len(scopes) > 0 {
+		body["scope"] = joinScopes(scopes)
+	}
+
+	var dc DeviceCode
+	var oauthErr struct {
+		Error string `json:"error"`
+	}
+	if err := postOAuthJSON(ctx, "https://github.com/login/device/code", body, &dc, &oauthErr); err != nil {
+		return DeviceCode{}, err
+	}
+	if oauthErr.Error != "" {
+		return DeviceCode{}, fmt.Errorf("device code request failed: %s", oauthErr.Error)
+	}
+	if dc.DeviceCode == "" || dc.UserCode == "" {
+		return DeviceCode{}, fmt.Errorf("device code request returned empty code")
+	}
+	return dc, nil
+}
+
+// PollDeviceToken asks once whether the user has approved the device code.
+// Until then it returns ErrAuthorizationPending or ErrSlowDown; on
+// ErrSlowDown the returned interval (seconds) is GitHub's new minimum.
+func PollDeviceToken(ctx context.Context, clientID, deviceCode string) (TokenResponse, int, error) {
+	if clientID == "" {
+		return TokenResponse{}, 0, fmt.Errorf("github oauth not configured")
+	}
+	if deviceCode == "" {
+		return TokenResponse{}, 0, fmt.Errorf("device code is required")
+	}
+	body := map[string]string{
+		"client_id":   clientID,
+		"device_code": deviceCode,
+		"grant_type":  deviceGrantType,
+	}
+
+	var tr TokenResponse
+	var oauthErr struct {
+		Error    string `json:"error"`
+		Interval int    `json:"interval"`
+	}
+	if err := postOAuthJSON(ctx, "https://github.com/login/oauth/access_token", body, &tr, &oauthErr); err != nil {
+		return TokenResponse{}, 0, err
+	}
+	switch oauthErr.Error {
+	case "":
+	case "authorization_pending":
+		return TokenResponse{}, 0, ErrAuthorizationPending
+	case "slow_down":
+		return TokenResponse{}, oauthErr.Interval, ErrSlowDown
+	case "expired_token":
+		return TokenResponse{}, 0, ErrDeviceCodeExpired
+	case "access_denied":
+		return TokenResponse{}, 0, ErrAccessDenied
+	default:
+		return TokenResponse{}, 0, fmt.Errorf("device token poll failed: %s", oauthErr.Error)
+	}
+	if tr.AccessToken == "" {
+		return TokenResponse{}, 0, fmt.Errorf("token exchange returned empty token")
+	}
+	return tr, 0, nil
+}
+
+// postOAuthJSON posts body to a GitHub OAuth endpoint and decodes the reply
+// into both out and errOut, since GitHub reports flow errors with status 200.
+func postOAuthJSON(ctx context.Context, endpoint string, body map[string]string, out, errOut any) error {
+	b, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: transport()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("github oauth request failed: status %d", resp.StatusCode)
+	}
+	raw := new(bytes.Buffer)
+	if _, err := raw.ReadFrom(resp.Body); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw.Bytes(), out); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw.Bytes(), errOut)
+}
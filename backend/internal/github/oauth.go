@@ -73,7 +73,7 @@ func ExchangeCode(ctx context.Context, code string, cfg OAuthConfig) (TokenRespo
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: transport()}
 	resp, err := client.Do(req)
 	if err != nil {
 		return TokenResponse{}, err
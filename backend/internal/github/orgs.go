@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Installation is a GitHub App installation and the account it is on.
+type Installation struct {
+	ID      int64 `json:"id"`
+	Account struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Type  string `json:"type"` // "User" or "Organization"
+	} `json:"account"`
+}
+
+// GetInstallation looks up an installation of the app.
+func (c *GitHubAppClient) GetInstallation(ctx context.Context, installationID string) (Installation, error) {
+	jwtToken, err := c.GenerateJWT()
+	if err != nil {
+		return Installation{}, fmt.Errorf("failed to generate JWT: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/app/installations/"+url.PathEscape(installationID), nil)
+	if err != nil {
+		return Installation{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwtToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return Installation{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Installation{}, fmt.Errorf("failed to get installation: status %d", resp.StatusCode)
+	}
+	var inst Installation
+	if err := json.NewDecoder(resp.Body).Decode(&inst); err != nil {
+		return Installation{}, err
+	}
+	return inst, nil
+}
+
+// OrgMember is a member of a GitHub organization.
+type OrgMember struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+// ListOrgMembers lists an organization's members with the given role ("all",
+// "admin" or "member"), reading with an installation token of the app on
+// that organization. The app needs the members:read permission.
+func (c *GitHubAppClient) ListOrgMembers(ctx context.Context, installationToken, org, role string) ([]OrgMember, error) {
+	var out []OrgMember
+	for page := 1; ; page++ {
+		u, _ := url.Parse("https://api.github.com/orgs/" + url.PathEscape(org) + "/members")
+		q := u.Query()
+		q.Set("role", role)
+		q.Set("per_page", "100")
+		q.Set("page", strconv.Itoa(page))
+		u.RawQuery = q.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+installationToken)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
+
+		resp, err := c.HTTP.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var members []OrgMember
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list org members: status %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&members)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, members...)
+		if len(members) < 100 {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// UserRepo is a repository owned by the authenticated user.
+type UserRepo struct {
+	ID              int64      `json:"id"`
+	FullName        string     `json:"full_name"`
+	HTMLURL         string     `json:"html_url"`
+	Description     string     `json:"description"`
+	Language        string     `json:"language"`
+	Private         bool       `json:"private"`
+	Fork            bool       `json:"fork"`
+	StargazersCount int        `json:"stargazers_count"`
+	ForksCount      int        `json:"forks_count"`
+	PushedAt        *time.Time `json:"pushed_at"`
+}
+
+// ListUserReposPage lists one page (up to 100) of the repositories the
+// token's user owns, most recently updated first.
+func (c *Client) ListUserReposPage(ctx context.Context, accessToken string, page int) ([]UserRepo, error) {
+	u, _ := url.Parse("https://api.github.com/user/repos")
+	q := u.Query()
+	q.Set("affiliation", "owner")
+	q.Set("sort", "updated")
+	q.Set("per_page", "100")
+	q.Set("page", strconv.Itoa(page))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, parseGitHubAPIError(resp)
+	}
+
+	var repos []UserRepo
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// ContributionStats are the token user's contributions over the last year,
+// as counted on their GitHub profile.
+type ContributionStats struct {
+	From               time.Time `json:"from"`
+	To                 time.Time `json:"to"`
+	Total              int       `json:"total"`
+	Commits            int       `json:"commits"`
+	PullRequests       int       `json:"pull_requests"`
+	PullRequestReviews int       `json:"pull_request_reviews"`
+	Issues             int       `json:"issues"`
+	Restricted         int       `json:"restricted"`
+}
+
+const contributionsQuery = `query {
+  viewer {
+    contributionsCollection {
+      startedAt
+      endedAt
+      totalCommitContributions
+      totalPullRequestContributions
+      totalPullRequestReviewContributions
+      totalIssueContributions
+      restrictedContributionsCount
+      contributionCalendar { totalContributions }
+    }
+  }
+}`
+
+// GetContributionStats fetches the viewer's contribution counts via GraphQL.
+func (c *Client) GetContributionStats(ctx context.Context, accessToken string) (ContributionStats, error) {
+	var resp struct {
+		Viewer struct {
+			ContributionsCollection struct {
+				StartedAt                           time.Time `json:"startedAt"`
+				EndedAt                             time.Time `json:"endedAt"`
+				TotalCommitContributions            int       `json:"totalCommitContributions"`
+				TotalPullRequestContributions       int       `json:"totalPullRequestContributions"`
+				TotalPullRequestReviewContributions int       `json:"totalPullRequestReviewContributions"`
+				TotalIssueContributions             int       `json:"totalIssueContributions"`
+				RestrictedContributionsCount        int       `json:"restrictedContributionsCount"`
+				ContributionCalendar                struct {
+					TotalContributions int `json:"totalContributions"`
+				} `json:"contributionCalendar"`
+			} `json:"contributionsCollection"`
+		} `json:"viewer"`
+	}
+	if err := c.graphQL(ctx, accessToken, contributionsQuery, nil, &resp); err != nil {
+		return ContributionStats{}, err
+	}
+	cc := resp.Viewer.ContributionsCollection
+	return ContributionStats{
+		From:               cc.StartedAt,
+		To:                 cc.EndedAt,
+		Total:              cc.ContributionCalendar.TotalContributions,
+		Commits:            cc.TotalCommitContributions,
+		PullRequests:       cc.TotalPullRequestContributions,
+		PullRequestReviews: cc.TotalPullRequestReviewContributions,
+		Issues:             cc.TotalIssueContributions,
+		Restricted:         cc.RestrictedContributionsCount,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// maxPRFilePages caps how many pages of a pull request's files are read
+// (100 per page); GitHub lists at most 3000 files.
+const maxPRFilePages = 30
+
+// PRFile is a file a pull request changes.
+type PRFile struct {
+	Filename string `json:"filename"`
+	// Status is "added", "removed", "modified", "renamed", ...
+	Status string `json:"status"`
+	// PreviousFilename is set for renamed files.
+	PreviousFilename string `json:"previous_filename,omitempty"`
+}
+
+// ListPRFiles returns the files a pull request changes.
+func (c *Client) ListPRFiles(ctx context.Context, accessToken, fullName string, number int) ([]PRFile, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, err
+	}
+	var out []PRFile
+	for page := 1; page <= maxPRFilePages; page++ {
+		var fs []PRFile
+		u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) +
+			"/pulls/" + strconv.Itoa(number) + "/files?per_page=100&page=" + strconv.Itoa(page)
+		if err := c.getJSON(ctx, accessToken, u, &fs); err != nil {
+			return nil, err
+		}
+		out = append(out, fs...)
+		if len(fs) < 100 {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Paths are the repo paths the files touch: their names and, for renames,
+// the names they had.
+func Paths(files []PRFile) []string {
+	out := make([]string, 0, len(files))
+	for _, f := range files {
+		out = append(out, f.Filename)
+		if f.PreviousFilename != "" {
+			out = append(out, f.PreviousFilename)
+		}
+	}
+	return out
+}
@@ -0,0 +1,175 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type SponsorTier struct {
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	MonthlyPriceInCents int    `json:"monthlyPriceInCents"`
+	IsOneTime           bool   `json:"isOneTime"`
+}
+
+type Sponsorship struct {
+	ID               string       `json:"id"`
+	CreatedAt        time.Time    `json:"createdAt"`
+	IsOneTimePayment bool         `json:"isOneTimePayment"`
+	PrivacyLevel     string       `json:"privacyLevel"`
+	Tier             *SponsorTier `json:"tier"`
+	SponsorEntity    *struct {
+		Login string `json:"login"`
+	} `json:"sponsorEntity"`
+}
+
+// SponsorsListing is a sponsorable account's tiers and active sponsorships.
+type SponsorsListing struct {
+	Login        string
+	Tiers        []SponsorTier
+	Sponsorships []Sponsorship
+}
+
+const sponsorsQuery = `
+query($login: String!, $cursor: String) {
+  repositoryOwner(login: $login) {
+    login
+    ... on Sponsorable {
+      sponsorsListing {
+        tiers(first: 100) { nodes { id name monthlyPriceInCents isOneTime } }
+      }
+      sponsorshipsAsMaintainer(first: 100, after: $cursor, includePrivate: true, activeOnly: true) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          id createdAt isOneTimePayment privacyLevel
+          tier { id name monthlyPriceInCents isOneTime }
+          sponsorEntity { ... on User { login } ... on Organization { login } }
+        }
+      }
+    }
+  }
+}`
+
+type sponsorsResponse struct {
+	RepositoryOwner *struct {
+		Login           string `json:"login"`
+		SponsorsListing *struct {
+			Tiers struct {
+				Nodes []SponsorTier `json:"nodes"`
+			} `json:"tiers"`
+		} `json:"sponsorsListing"`
+		SponsorshipsAsMaintainer struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []Sponsorship `json:"nodes"`
+		} `json:"sponsorshipsAsMaintainer"`
+	} `json:"repositoryOwner"`
+}
+
+// GetSponsorsListing fetches tiers and all active sponsorships for login via
+// the GraphQL API. Private sponsors are only visible to a token belonging to
+// the sponsorable user or an admin of the sponsorable org.
+func (c *Client) GetSponsorsListing(ctx context.Context, accessToken string, login string) (SponsorsListing, error) {
+	out := SponsorsListing{Tiers: []SponsorTier{}, Sponsorships: []Sponsorship{}}
+	var cursor *string
+	for page := 0; page < 50; page++ {
+		var resp sponsorsResponse
+		if err := c.graphQL(ctx, accessToken, sponsorsQuery, map[string]any{"login": login, "cursor": cursor}, &resp); err != nil {
+			return SponsorsListing{}, err
+		}
+		owner := resp.RepositoryOwner
+		if owner == nil {
+			return SponsorsListing{}, fmt.Errorf("github account %q not found", login)
+		}
+		if page == 0 {
+			out.Login = owner.Login
+			if owner.SponsorsListing != nil {
+				out.Tiers = append(out.Tiers, owner.SponsorsListing.Tiers.Nodes...)
+			}
+		}
+		out.Sponsorships = append(out.Sponsorships, owner.SponsorshipsAsMaintainer.Nodes...)
+		if !owner.SponsorshipsAsMaintainer.PageInfo.HasNextPage {
+			return out, nil
+		}
+		next := owner.SponsorshipsAsMaintainer.PageInfo.EndCursor
+		cursor = &next
+	}
+	// Partial results would make a sync cancel the unseen sponsorships.
+	return SponsorsListing{}, fmt.Errorf("too many sponsorships for %q", login)
+}
+
+func (c *Client) graphQL(ctx context.Context, accessToken string, query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	if len(envelope.Errors) > 0 {
+		msgs := make([]string, 0, len(envelope.Errors))
+		for _, e := range envelope.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("github graphql: %s", strings.Join(msgs, "; "))
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// CanAdministerSponsorable reports whether the token's user may manage
+// login's Sponsors listing: it is the user themself or an org they administer.
+func (c *Client) CanAdministerSponsorable(ctx context.Context, accessToken string, login string) (bool, error) {
+	var resp struct {
+		RepositoryOwner *struct {
+			IsViewer            bool `json:"isViewer"`
+			ViewerCanAdminister bool `json:"viewerCanAdminister"`
+		} `json:"repositoryOwner"`
+	}
+	err := c.graphQL(ctx, accessToken, `
+query($login: String!) {
+  repositoryOwner(login: $login) {
+    ... on User { isViewer }
+    ... on Organization { viewerCanAdminister }
+  }
+}`, map[string]any{"login": login}, &resp)
+	if err != nil {
+		return false, err
+	}
+	if resp.RepositoryOwner == nil {
+		return false, fmt.Errorf("github account %q not found", login)
+	}
+	return resp.RepositoryOwner.IsViewer || resp.RepositoryOwner.ViewerCanAdminister, nil
+}
@@ -0,0 +1,201 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+var (
+	tokenKeyringMu sync.RWMutex
+	tokenKeyring   *cryptox.Keyring
+)
+
+// SetTokenKeyring installs the keyring stored GitHub tokens are sealed and
+// opened with, built from TOKEN_ENC_KEY_B64, TOKEN_ENC_KEYS and
+// TOKEN_ENC_KEY_ACTIVE at startup.
+func SetTokenKeyring(k *cryptox.Keyring) {
+	tokenKeyringMu.Lock()
+	tokenKeyring = k
+	tokenKeyringMu.Unlock()
+}
+
+// keyring returns the installed keyring, or one holding only the legacy key
+// when none is installed (tests, one-off tools).
+func keyring(tokenEncKeyB64 string) (*cryptox.Keyring, error) {
+	tokenKeyringMu.RLock()
+	k := tokenKeyring
+	tokenKeyringMu.RUnlock()
+	if k != nil {
+		return k, nil
+	}
+	return cryptox.ParseKeyring(tokenEncKeyB64, "", -1)
+}
+
+// SealToken encrypts a GitHub access token for github_accounts under the
+// active key, returning the key version to store alongside it.
+func SealToken(tokenEncKeyB64, token string) ([]byte, uint16, error) {
+	k, err := keyring(tokenEncKeyB64)
+	if err != nil {
+		return nil, 0, err
+	}
+	blob, err := k.Seal([]byte(token))
+	if err != nil {
+		return nil, 0, err
+	}
+	return blob, k.Active(), nil
+}
+
+// OpenToken decrypts a stored GitHub access token under whichever key
+// version sealed it.
+func OpenToken(tokenEncKeyB64 string, blob []byte) (string, error) {
+	k, err := keyring(tokenEncKeyB64)
+	if err != nil {
+		return "", err
+	}
+	out, err := k.Open(blob)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// RekeyBatch is how many tokens Rekey moves per query.
+const RekeyBatch = 200
+
+// RekeyResult counts what a Rekey run did.
+type RekeyResult struct {
+	ActiveVersion int `json:"active_version"`
+	Rekeyed       int `json:"rekeyed"`
+	// Skipped tokens changed under the run, e.g. a user re-linked; they
+	// were sealed under the active key by that write.
+	Skipped int `json:"skipped"`
+	// Failed tokens could not be opened with any key held.
+	Failed int `json:"failed"`
+}
+
+// Rekey moves every stored GitHub token not under the keyring's active key
+// onto it, in batches. Each row is updated only if it still holds the token
+// read, so logins racing the run are never overwritten, and the API keeps
+// serving throughout since every key stays readable until it is retired.
+func Rekey(ctx context.Context, pool *pgxpool.Pool, k *cryptox.Keyring) (RekeyResult, error) {
+	res := RekeyResult{ActiveVersion: int(k.Active())}
+	if pool == nil {
+		return res, fmt.Errorf("db not configured")
+	}
+	after := uuid.Nil
+	for {
+		type row struct {
+			userID uuid.UUID
+			blob   []byte
+		}
+		rows, err := pool.Query(ctx, `
+SELECT user_id, access_token
+FROM github_accounts
+WHERE access_token_key_version <> $1 AND user_id > $2
+ORDER BY user_id
+LIMIT $3
+`, int(k.Active()), after, RekeyBatch)
+		if err != nil {
+			return res, err
+		}
+		var batch []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.userID, &r.blob); err != nil {
+				rows.Close()
+				return res, err
+			}
+			batch = append(batch, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return res, err
+		}
+		if len(batch) == 0 {
+			return res, nil
+		}
+		for _, r := range batch {
+			after = r.userID
+			out, _, err := k.Rekey(r.blob)
+			if err != nil {
+				slog.Warn("github token rekey: unreadable token", "user_id", r.userID.String(), "error", err)
+				res.Failed++
+				continue
+			}
+			tag, err := pool.Exec(ctx, `
+UPDATE github_accounts
+SET access_token = $3, access_token_key_version = $4
+WHERE user_id = $1 AND access_token = $2
+`, r.userID, r.blob, out, int(k.Active()))
+			if err != nil {
+				return res, err
+			}
+			if tag.RowsAffected() == 0 {
+				res.Skipped++
+				continue
+			}
+			res.Rekeyed++
+		}
+	}
+}
+
+// KeyVersionCount is how many stored GitHub tokens a key version seals.
+type KeyVersionCount struct {
+	Version int  `json:"version"`
+	Tokens  int  `json:"tokens"`
+	Held    bool `json:"held"`
+	Active  bool `json:"active"`
+}
+
+// KeyVersions reports how stored GitHub tokens spread over key versions,
+// including held versions no token uses any more, which are safe to retire.
+func KeyVersions(ctx context.Context, pool *pgxpool.Pool, k *cryptox.Keyring) ([]KeyVersionCount, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT access_token_key_version, COUNT(*)
+FROM github_accounts
+GROUP BY access_token_key_version
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[int]int{}
+	for rows.Next() {
+		var v, n int
+		if err := rows.Scan(&v, &n); err != nil {
+			return nil, err
+		}
+		counts[v] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versionCounts(k, counts), nil
+}
+
+func versionCounts(k *cryptox.Keyring, counts map[int]int) []KeyVersionCount {
+	held := map[int]bool{}
+	for _, v := range k.Versions() {
+		held[int(v)] = true
+		if _, ok := counts[int(v)]; !ok {
+			counts[int(v)] = 0
+		}
+	}
+	out := make([]KeyVersionCount, 0, len(counts))
+	for v, n := range counts {
+		out = append(out, KeyVersionCount{Version: v, Tokens: n, Held: held[v], Active: v == int(k.Active())})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
@@ -0,0 +1,141 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource yields the token a GitHub API call is made with.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a fixed token, such as a user's OAuth token.
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) { return string(t), nil }
+
+// ErrNoToken is returned by a source with nothing to offer.
+var ErrNoToken = errors.New("github_no_token")
+
+// installationTokenMargin is how long before GitHub expires an installation
+// token it is minted again, so no call goes out with one about to lapse.
+const installationTokenMargin = 5 * time.Minute
+
+// InstallationTokens mints installation tokens for a GitHub App and caches
+// them until shortly before they expire (GitHub grants an hour).
+type InstallationTokens struct {
+	mint func(ctx context.Context, installationID string) (InstallationTokenResponse, error)
+	now  func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]InstallationTokenResponse
+}
+
+func NewInstallationTokens(app *GitHubAppClient) *InstallationTokens {
+	return &InstallationTokens{
+		mint:  app.CreateInstallationToken,
+		now:   time.Now,
+		cache: map[string]InstallationTokenResponse{},
+	}
+}
+
+// Token returns a live token for installationID, minting one when the cached
+// token is missing or close to expiry.
+func (t *InstallationTokens) Token(ctx context.Context, installationID string) (string, error) {
+	installationID = strings.TrimSpace(installationID)
+	if installationID == "" {
+		return "", ErrNoToken
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cached, ok := t.cache[installationID]; ok && t.now().Add(installationTokenMargin).Before(cached.ExpiresAt) {
+		return cached.Token, nil
+	}
+	resp, err := t.mint(ctx, installationID)
+	if err != nil {
+		delete(t.cache, installationID)
+		return "", err
+	}
+	if resp.ExpiresAt.IsZero() {
+		resp.ExpiresAt = t.now().Add(time.Hour)
+	}
+	t.cache[installationID] = resp
+	return resp.Token, nil
+}
+
+// Forget drops installationID's cached token, e.g. after the app was
+// uninstalled or GitHub rejected the token.
+func (t *InstallationTokens) Forget(installationID string) {
+	t.mu.Lock()
+	delete(t.cache, strings.TrimSpace(installationID))
+	t.mu.Unlock()
+}
+
+// Source is a TokenSource for one installation.
+func (t *InstallationTokens) Source(installationID string) TokenSource {
+	return installationSource{tokens: t, id: installationID}
+}
+
+type installationSource struct {
+	tokens *InstallationTokens
+	id     string
+}
+
+func (s installationSource) Token(ctx context.Context) (string, error) {
+	return s.tokens.Token(ctx, s.id)
+}
+
+var (
+	appInstallationsMu sync.Mutex
+	appInstallations   = map[string]*InstallationTokens{}
+)
+
+// AppInstallations returns the process-wide installation token cache for the
+// configured GitHub App, or nil when no app is configured, so every caller
+// shares minted tokens.
+func AppInstallations(appID, privateKeyPEM string) *InstallationTokens {
+	appID = strings.TrimSpace(appID)
+	if appID == "" || strings.TrimSpace(privateKeyPEM) == "" {
+		return nil
+	}
+	appInstallationsMu.Lock()
+	defer appInstallationsMu.Unlock()
+	if t, ok := appInstallations[appID]; ok {
+		return t
+	}
+	app, err := NewGitHubAppClient(appID, privateKeyPEM)
+	if err != nil {
+		slog.Warn("github app not usable, falling back to user tokens", "error", err)
+		return nil
+	}
+	t := NewInstallationTokens(app)
+	appInstallations[appID] = t
+	return t
+}
+
+// FirstToken tries each source in turn, returning the first token one
+// yields, or the last source's error.
+type FirstToken []TokenSource
+
+func (f FirstToken) Token(ctx context.Context) (string, error) {
+	err := ErrNoToken
+	for _, s := range f {
+		if s == nil {
+			continue
+		}
+		var tok string
+		tok, err = s.Token(ctx)
+		if err == nil && tok != "" {
+			return tok, nil
+		}
+		if err == nil {
+			err = ErrNoToken
+		}
+	}
+	return "", err
+}
@@ -0,0 +1,61 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestInstallationTokensCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	minted := 0
+	tokens := &InstallationTokens{
+		now:   func() time.Time { return now },
+		cache: map[string]InstallationTokenResponse{},
+		mint: func(_ context.Context, id string) (InstallationTokenResponse, error) {
+			minted++
+			return InstallationTokenResponse{Token: fmt.Sprintf("%s-%d", id, minted), ExpiresAt: now.Add(time.Hour)}, nil
+		},
+	}
+	ctx := context.Background()
+
+	first, _ := tokens.Token(ctx, "42")
+	if again, _ := tokens.Token(ctx, "42"); again != first || minted != 1 {
+		t.Fatalf("cached token = %q after %d mints, want %q after 1", again, minted, first)
+	}
+	now = now.Add(time.Hour - installationTokenMargin + time.Second)
+	if renewed, _ := tokens.Token(ctx, "42"); renewed == first {
+		t.Fatal("token close to expiry was not renewed")
+	}
+	tokens.Forget("42")
+	if _, _ = tokens.Token(ctx, "42"); minted != 3 {
+		t.Fatalf("mints after Forget = %d, want 3", minted)
+	}
+	if _, err := tokens.Token(ctx, " "); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("blank installation err = %v", err)
+	}
+}
+
+type failingSource struct{ err error }
+
+func (f failingSource) Token(context.Context) (string, error) { return "", f.err }
+
+func TestFirstToken(t *testing.T) {
+	ctx := context.Background()
+	notLinked := errors.New("github_not_linked")
+
+	if tok, err := (FirstToken{failingSource{errors.New("not installed")}, StaticToken("user")}).Token(ctx); tok != "user" || err != nil {
+		t.Fatalf("fallback = %q, %v", tok, err)
+	}
+	if tok, _ := (FirstToken{StaticToken("app"), StaticToken("user")}).Token(ctx); tok != "app" {
+		t.Fatalf("preferred = %q", tok)
+	}
+	if _, err := (FirstToken{StaticToken(""), failingSource{notLinked}}).Token(ctx); !errors.Is(err, notLinked) {
+		t.Fatalf("all failing err = %v", err)
+	}
+	if _, err := (FirstToken{}).Token(ctx); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("empty err = %v", err)
+	}
+}
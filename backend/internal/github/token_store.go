@@ -8,8 +8,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-
-	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
 )
 
 type LinkedAccount struct {
@@ -38,11 +36,7 @@ WHERE user_id = $1
 		return LinkedAccount{}, err
 	}
 
-	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
-	if err != nil {
-		return LinkedAccount{}, err
-	}
-	tokenBytes, err := cryptox.DecryptAESGCM(key, encToken)
+	token, err := OpenToken(tokenEncKeyB64, encToken)
 	if err != nil {
 		return LinkedAccount{}, fmt.Errorf("decrypt github token failed")
 	}
@@ -50,7 +44,7 @@ WHERE user_id = $1
 	return LinkedAccount{
 		GitHubUserID: githubUserID,
 		Login:        login,
-		AccessToken:  string(tokenBytes),
+		AccessToken:  token,
 	}, nil
 }
 
@@ -74,3 +68,80 @@ WHERE user_id = $1
 
 
 
+
+// Tokens picks the credentials for GitHub calls: repository, issue and
+// webhook operations go through the GitHub App's installation when it is
+// installed on the repo, since installation tokens neither expire with the
+// user's session nor share their rate limit; profile reads and actions taken
+// as a user keep that user's OAuth token.
+type Tokens struct {
+	Pool           *pgxpool.Pool
+	TokenEncKeyB64 string
+	// Installations is nil when no GitHub App is configured, leaving only
+	// user tokens.
+	Installations *InstallationTokens
+}
+
+// User is userID's OAuth token.
+func (t Tokens) User(userID uuid.UUID) TokenSource {
+	return userSource{pool: t.Pool, encKey: t.TokenEncKeyB64, userID: userID}
+}
+
+// Repo is the token for operating on fullName: the installation's when the
+// app is installed on the repo or its linked organization, otherwise the
+// project owner's OAuth token.
+func (t Tokens) Repo(fullName string, ownerID uuid.UUID) TokenSource {
+	user := t.User(ownerID)
+	if t.Installations == nil {
+		return user
+	}
+	return FirstToken{repoInstallationSource{pool: t.Pool, tokens: t.Installations, fullName: fullName}, user}
+}
+
+type userSource struct {
+	pool   *pgxpool.Pool
+	encKey string
+	userID uuid.UUID
+}
+
+func (s userSource) Token(ctx context.Context) (string, error) {
+	linked, err := GetLinkedAccount(ctx, s.pool, s.userID, s.encKey)
+	if err != nil {
+		return "", err
+	}
+	return linked.AccessToken, nil
+}
+
+type repoInstallationSource struct {
+	pool     *pgxpool.Pool
+	tokens   *InstallationTokens
+	fullName string
+}
+
+func (s repoInstallationSource) Token(ctx context.Context) (string, error) {
+	id, err := RepoInstallation(ctx, s.pool, s.fullName)
+	if err != nil {
+		return "", err
+	}
+	return s.tokens.Token(ctx, id)
+}
+
+// RepoInstallation finds the GitHub App installation covering fullName:
+// the one recorded on its project, else its linked organization's. It is ""
+// when the app isn't installed there.
+func RepoInstallation(ctx context.Context, pool *pgxpool.Pool, fullName string) (string, error) {
+	if pool == nil {
+		return "", fmt.Errorf("db not configured")
+	}
+	var id string
+	err := pool.QueryRow(ctx, `
+SELECT COALESCE(
+  (SELECT github_app_installation_id FROM projects
+   WHERE lower(github_full_name) = lower($1) AND github_app_installation_id IS NOT NULL AND deleted_at IS NULL
+   LIMIT 1),
+  (SELECT installation_id FROM github_orgs WHERE lower(login) = lower(split_part($1, '/', 1))),
+  ''
+)
+`, fullName).Scan(&id)
+	return id, err
+}
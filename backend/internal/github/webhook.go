@@ -25,7 +25,7 @@ func (c *Client) CreateWebhook(ctx context.Context, accessToken string, fullName
 		return Webhook{}, fmt.Errorf("webhook url and secret are required")
 	}
 	if len(req.Events) == 0 {
-		req.Events = []string{"issues", "pull_request", "pull_request_review", "push"}
+		req.Events = []string{"issues", "issue_comment", "pull_request", "pull_request_review", "check_suite", "push", "star"}
 	}
 
 	owner, repo, err := splitFullName(fullName)
@@ -0,0 +1,230 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WebhookEvent is a verified webhook delivery, routed by its X-GitHub-Event name.
+type WebhookEvent struct {
+	DeliveryID   string
+	Event        string
+	Action       string
+	RepoFullName string
+	// ProjectID is the registered project the repository maps to, if any.
+	ProjectID string
+	Payload   json.RawMessage
+}
+
+type WebhookHandler func(ctx context.Context, e WebhookEvent) error
+
+// WebhookDispatcher fans deliveries out to handlers registered per event
+// name; handlers registered for "*" see every delivery.
+type WebhookDispatcher struct {
+	mu       sync.RWMutex
+	handlers map[string][]WebhookHandler
+}
+
+func NewWebhookDispatcher() *WebhookDispatcher {
+	return &WebhookDispatcher{handlers: map[string][]WebhookHandler{}}
+}
+
+func (d *WebhookDispatcher) On(event string, h WebhookHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[event] = append(d.handlers[event], h)
+}
+
+// Dispatch runs every matching handler, even after one fails, and returns
+// their errors joined.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, e WebhookEvent) error {
+	d.mu.RLock()
+	hs := append(append([]WebhookHandler{}, d.handlers[e.Event]...), d.handlers["*"]...)
+	d.mu.RUnlock()
+
+	var errs []error
+	for _, h := range hs {
+		if err := h(ctx, e); err != nil {
+			slog.Warn("github webhook handler failed",
+				"delivery_id", e.DeliveryID,
+				"event", e.Event,
+				"error", err,
+			)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type WebhookUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+type PushCommit struct {
+	ID        string    `json:"id"`
+	Message   string    `json:"message"`
+	URL       string    `json:"url"`
+	Distinct  bool      `json:"distinct"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+// PushPayload is the "push" event.
+type PushPayload struct {
+	Ref     string       `json:"ref"`
+	Before  string       `json:"before"`
+	After   string       `json:"after"`
+	Created bool         `json:"created"`
+	Deleted bool         `json:"deleted"`
+	Forced  bool         `json:"forced"`
+	Commits []PushCommit `json:"commits"`
+	Pusher  struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"pusher"`
+	Sender WebhookUser `json:"sender"`
+}
+
+// StarPayload is the "star" event; StarredAt is nil when the star is removed.
+type StarPayload struct {
+	Action    string      `json:"action"`
+	StarredAt *time.Time  `json:"starred_at"`
+	Sender    WebhookUser `json:"sender"`
+}
+
+func ParsePush(e WebhookEvent) (PushPayload, error) {
+	var p PushPayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return PushPayload{}, fmt.Errorf("parse push payload: %w", err)
+	}
+	return p, nil
+}
+
+func ParseStar(e WebhookEvent) (StarPayload, error) {
+	var p StarPayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return StarPayload{}, fmt.Errorf("parse star payload: %w", err)
+	}
+	if p.Sender.ID == 0 {
+		return StarPayload{}, fmt.Errorf("parse star payload: missing sender")
+	}
+	return p, nil
+}
+
+// PullRequestReviewPayload is the "pull_request_review" event. SubmittedAt
+// is nil for pending reviews.
+type PullRequestReviewPayload struct {
+	Action string `json:"action"`
+	Review struct {
+		ID                int64       `json:"id"`
+		User              WebhookUser `json:"user"`
+		State             string      `json:"state"`
+		AuthorAssociation string      `json:"author_association"`
+		SubmittedAt       *time.Time  `json:"submitted_at"`
+	} `json:"review"`
+	PullRequest struct {
+		Number int         `json:"number"`
+		User   WebhookUser `json:"user"`
+	} `json:"pull_request"`
+}
+
+// IssueCommentPayload is the "issue_comment" event, for issues and pull
+// requests alike.
+type IssueCommentPayload struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number int         `json:"number"`
+		User   WebhookUser `json:"user"`
+	} `json:"issue"`
+	Comment struct {
+		ID                int64       `json:"id"`
+		User              WebhookUser `json:"user"`
+		AuthorAssociation string      `json:"author_association"`
+		CreatedAt         time.Time   `json:"created_at"`
+	} `json:"comment"`
+}
+
+// PullRequestPayload is the "pull_request" event. A pull request closed
+// without merging has Merged false.
+type PullRequestPayload struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Number  int         `json:"number"`
+		Title   string      `json:"title"`
+		Body    string      `json:"body"`
+		HTMLURL string      `json:"html_url"`
+		User    WebhookUser `json:"user"`
+		Merged  bool        `json:"merged"`
+	} `json:"pull_request"`
+}
+
+// CheckSuitePayload is the "check_suite" event; repository webhooks only
+// receive it once a suite completes.
+type CheckSuitePayload struct {
+	Action     string `json:"action"`
+	CheckSuite struct {
+		ID         int64     `json:"id"`
+		HeadSHA    string    `json:"head_sha"`
+		HeadBranch string    `json:"head_branch"`
+		Status     string    `json:"status"`
+		Conclusion string    `json:"conclusion"`
+		UpdatedAt  time.Time `json:"updated_at"`
+		App        struct {
+			Slug string `json:"slug"`
+		} `json:"app"`
+	} `json:"check_suite"`
+}
+
+func ParsePullRequestReview(e WebhookEvent) (PullRequestReviewPayload, error) {
+	var p PullRequestReviewPayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return PullRequestReviewPayload{}, fmt.Errorf("parse pull_request_review payload: %w", err)
+	}
+	if p.Review.ID == 0 || p.PullRequest.Number == 0 {
+		return PullRequestReviewPayload{}, fmt.Errorf("parse pull_request_review payload: missing review")
+	}
+	return p, nil
+}
+
+func ParseIssueComment(e WebhookEvent) (IssueCommentPayload, error) {
+	var p IssueCommentPayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return IssueCommentPayload{}, fmt.Errorf("parse issue_comment payload: %w", err)
+	}
+	if p.Comment.ID == 0 || p.Issue.Number == 0 {
+		return IssueCommentPayload{}, fmt.Errorf("parse issue_comment payload: missing comment")
+	}
+	return p, nil
+}
+
+func ParsePullRequest(e WebhookEvent) (PullRequestPayload, error) {
+	var p PullRequestPayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return PullRequestPayload{}, fmt.Errorf("parse pull_request payload: %w", err)
+	}
+	if p.PullRequest.Number == 0 {
+		return PullRequestPayload{}, fmt.Errorf("parse pull_request payload: missing pull request")
+	}
+	return p, nil
+}
+
+func ParseCheckSuite(e WebhookEvent) (CheckSuitePayload, error) {
+	var p CheckSuitePayload
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return CheckSuitePayload{}, fmt.Errorf("parse check_suite payload: %w", err)
+	}
+	if p.CheckSuite.ID == 0 {
+		return CheckSuitePayload{}, fmt.Errorf("parse check_suite payload: missing check suite")
+	}
+	return p, nil
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+	"github.com/jagadeesh/grainlify/backend/internal/proofs"
+	"github.com/jagadeesh/grainlify/backend/internal/webhooks"
+)
+
+// AccountingHandler manages a project's accounting endpoint, which receives
+// a signed proof of every payout the project funds, and its delivery log.
+type AccountingHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewAccountingHandler(cfg config.Config, d *db.DB) *AccountingHandler {
+	return &AccountingHandler{cfg: cfg, db: d}
+}
+
+func (h *AccountingHandler) GetEndpoint() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermViewFinances)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		ep, err := proofs.GetEndpoint(c.Context(), h.db.Pool, projectID)
+		if errors.Is(err, proofs.ErrEndpointNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "accounting_endpoint_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "accounting_endpoint_lookup_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(ep)
+	}
+}
+
+type putAccountingEndpointRequest struct {
+	URL string `json:"url"`
+	// Secret signs proofs; empty keeps the current one, or generates one.
+	Secret string `json:"secret"`
+	// InvoicePrefix starts invoice numbers, e.g. "ACME" for ACME-000042.
+	InvoicePrefix string `json:"invoice_prefix"`
+	Active        *bool  `json:"active"`
+}
+
+// PutEndpoint sets the project's accounting endpoint. When a secret is
+// generated the response carries it, and it is never shown again.
+func (h *AccountingHandler) PutEndpoint() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermManageProjects)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		if strings.TrimSpace(h.cfg.TokenEncKeyB64) == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
+		}
+		var req putAccountingEndpointRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		ep, secret, err := proofs.PutEndpoint(c.Context(), h.db.Pool, projectID, proofs.EndpointConfig{
+			URL:           req.URL,
+			Secret:        req.Secret,
+			InvoicePrefix: req.InvoicePrefix,
+			Active:        req.Active,
+		}, h.cfg.TokenEncKeyB64)
+		switch {
+		case errors.Is(err, webhooks.ErrInvalidURL), errors.Is(err, webhooks.ErrInvalidSecret), errors.Is(err, proofs.ErrInvalidPrefix):
+			return httpx.Fail(c, fiber.StatusBadRequest, err.Error())
+		case err != nil:
+			httpx.Logger(c).Error("accounting endpoint update failed", "project_id", projectID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "accounting_endpoint_update_failed")
+		}
+		out := fiber.Map{"endpoint": ep}
+		if secret != "" {
+			out["secret"] = secret
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+func (h *AccountingHandler) DeleteEndpoint() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermManageProjects)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		err := proofs.DeleteEndpoint(c.Context(), h.db.Pool, projectID)
+		if errors.Is(err, proofs.ErrEndpointNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "accounting_endpoint_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "accounting_endpoint_delete_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// Proofs is the project's proof-of-payment delivery log.
+func (h *AccountingHandler) Proofs() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermViewFinances)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+		switch status {
+		case "", webhooks.StatusPending, webhooks.StatusSucceeded, webhooks.StatusFailed:
+		default:
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		}
+		limit := c.QueryInt("limit", 50)
+		if limit < 1 || limit > 200 {
+			limit = 50
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+		out, err := proofs.ListProofs(c.Context(), h.db.Pool, projectID, status, limit, offset)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payment_proofs_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"proofs": out})
+	}
+}
+
+// Redeliver queues a succeeded or failed proof to be sent again.
+func (h *AccountingHandler) Redeliver() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermManageProjects)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		id, err := uuid.Parse(c.Params("proof_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_proof_id")
+		}
+		p, err := proofs.Redeliver(c.Context(), h.db.Pool, projectID, id)
+		switch {
+		case errors.Is(err, proofs.ErrProofNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "payment_proof_not_found")
+		case errors.Is(err, proofs.ErrDeliveryNotClosed):
+			return httpx.Fail(c, fiber.StatusConflict, "payment_proof_pending")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payment_proof_redeliver_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(p)
+	}
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -9,9 +11,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+	"github.com/jagadeesh/grainlify/backend/internal/ratelimit"
 )
 
 type AdminHandler struct {
@@ -23,42 +29,106 @@ func NewAdminHandler(cfg config.Config, d *db.DB) *AdminHandler {
 	return &AdminHandler{cfg: cfg, db: d}
 }
 
+// usersListSpec is what the admin user listing sorts and filters on.
+var usersListSpec = httpx.ListSpec{
+	Fields: map[string]httpx.Field{
+		"role":       {Column: "u.role", Filter: true, Values: auth.Roles},
+		"status":     {Column: moderation.StatusSQL, Filter: true, Values: []string{moderation.StatusActive, moderation.StatusSuspended, moderation.StatusBanned}},
+		"plan_tier":  {Column: "u.plan_tier", Filter: true},
+		"login":      {Column: "lower(COALESCE(ga.login, ''))", Sort: true, Filter: true, Fold: true},
+		"created_at": {Column: "u.created_at", Type: httpx.FieldTime, Sort: true, Filter: true},
+		"updated_at": {Column: "u.updated_at", Type: httpx.FieldTime, Sort: true, Filter: true},
+	},
+	DefaultSort:  "-created_at",
+	Key:          "u.id",
+	KeyType:      httpx.FieldUUID,
+	DefaultLimit: 50,
+	MaxLimit:     200,
+}
+
 func (h *AdminHandler) ListUsers() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
-		rows, err := h.db.Pool.Query(c.Context(), `
-SELECT id, role, github_user_id, created_at, updated_at
-FROM users
-ORDER BY created_at DESC
-LIMIT 50
-`)
+		// q matches a user id, GitHub login or wallet address; role, status
+		// and the rest are list filters.
+		page, err := httpx.ParseList(c, usersListSpec)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "users_list_failed"})
+			return httpx.Write(c, err)
+		}
+		var where []string
+		var args []any
+		if q := strings.TrimSpace(c.Query("q")); q != "" {
+			args = append(args, q, "%"+q+"%", strings.ToLower(q)+"%")
+			where = append(where, fmt.Sprintf(`(u.id::text = $%d OR ga.login ILIKE $%d OR EXISTS (
+  SELECT 1 FROM wallets w WHERE w.user_id = u.id AND lower(w.address) LIKE $%d))`, len(args)-2, len(args)-1, len(args)))
+		}
+		if cond, pargs := page.Where(len(args) + 1); cond != "" {
+			where = append(where, cond)
+			args = append(args, pargs...)
+		}
+		filter := ""
+		if len(where) > 0 {
+			filter = "WHERE " + strings.Join(where, " AND ")
 		}
-		defer rows.Close()
 
-		var out []fiber.Map
-		for rows.Next() {
+		stream := wantsNDJSON(c)
+		args = append(args, pageLimit(stream, page.Limit))
+		rows, cancel, err := listQuery(c, h.db.Pool, stream, fmt.Sprintf(`
+SELECT u.id, u.role, u.plan_tier, u.github_user_id, ga.login, %s, u.suspended_until, u.created_at, u.updated_at, %s
+FROM users u
+LEFT JOIN github_accounts ga ON ga.user_id = u.id
+%s
+%s
+LIMIT $%d %s
+`, moderation.StatusSQL, page.CursorColumn(), filter, page.OrderBy(), len(args), page.OffsetSQL()), args...)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "users_list_failed")
+		}
+
+		var cursor string
+		scan := func(rows pgx.Rows) (any, error) {
 			var id uuid.UUID
-			var role string
+			var role, tier, status string
 			var ghID *int64
+			var login *string
+			var suspendedUntil *time.Time
 			var createdAt, updatedAt time.Time
-			if err := rows.Scan(&id, &role, &ghID, &createdAt, &updatedAt); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "users_list_failed"})
+			if err := rows.Scan(&id, &role, &tier, &ghID, &login, &status, &suspendedUntil, &createdAt, &updatedAt, &cursor); err != nil {
+				return nil, err
 			}
-			out = append(out, fiber.Map{
-				"id":             id.String(),
-				"role":           role,
-				"github_user_id": ghID,
-				"created_at":     createdAt,
-				"updated_at":     updatedAt,
-			})
+			return fiber.Map{
+				"id":              id.String(),
+				"role":            role,
+				"plan_tier":       tier,
+				"github_user_id":  ghID,
+				"github_login":    login,
+				"status":          status,
+				"suspended_until": suspendedUntil,
+				"created_at":      createdAt,
+				"updated_at":      updatedAt,
+			}, nil
+		}
+		if stream {
+			return streamNDJSON(c, rows, cancel, scan)
+		}
+		defer cancel()
+		out, err := collectRows(rows, scan)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "users_list_failed")
 		}
 
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{"users": out})
+		resp := fiber.Map{"users": out}
+		if next := page.NextCursor(len(out), cursor); next != "" {
+			resp["next_cursor"] = next
+			if c.Query("cursor") == "" {
+				// For clients still paging by offset.
+				resp["next_offset"] = page.Offset + page.Limit
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
 	}
 }
 
@@ -69,34 +139,109 @@ type setRoleRequest struct {
 func (h *AdminHandler) SetUserRole() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		userID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
 		}
 		var req setRoleRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 		role := strings.TrimSpace(req.Role)
-		if role != "contributor" && role != "maintainer" && role != "admin" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_role"})
+		if !slices.Contains(auth.Roles, role) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_role")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		tx, err := h.db.Pool.BeginTx(c.Context(), pgx.TxOptions{})
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "role_update_failed")
 		}
-		ct, err := h.db.Pool.Exec(c.Context(), `
-UPDATE users SET role = $2, updated_at = now()
-WHERE id = $1
-`, userID, role)
-		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		var previous string
+		err = tx.QueryRow(c.Context(), `
+UPDATE users u SET role = $2, updated_at = now()
+FROM (SELECT id, role FROM users WHERE id = $1 FOR UPDATE) old
+WHERE u.id = old.id
+RETURNING old.role
+`, userID, role).Scan(&previous)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "role_update_failed")
+		}
+		if previous != role {
+			if err := moderation.RecordAction(c.Context(), tx, actorID, userID, moderation.ActionRoleChange, previous+" -> "+role); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "role_update_failed")
+			}
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "role_update_failed")
+		}
+		if previous != role {
+			recordAudit(c, h.db.Pool, &userID, audit.ActionRoleChanged, map[string]any{"from": previous, "to": role})
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 	}
 }
 
+type setPlanTierRequest struct {
+	Tier string `json:"plan_tier"`
+}
+
+// SetUserPlanTier moves a user onto a plan tier, which picks the rate limit
+// bucket their API keys draw from. Only configured tiers are accepted
+// while API key rate limits are enabled.
+func (h *AdminHandler) SetUserPlanTier() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		userID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		var req setPlanTierRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		tier := strings.ToLower(strings.TrimSpace(req.Tier))
+		tiers, err := ratelimit.ParseTiers(h.cfg.APIKeyRateLimitTiers)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "rate_limit_tiers_misconfigured")
+		}
+		if _, ok := tiers[tier]; tier == "" || (len(tiers) > 0 && !ok) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_plan_tier")
+		}
+
+		var previous string
+		err = h.db.Pool.QueryRow(c.Context(), `
+UPDATE users u SET plan_tier = $2, updated_at = now()
+FROM (SELECT id, plan_tier FROM users WHERE id = $1 FOR UPDATE) old
+WHERE u.id = old.id
+RETURNING old.plan_tier
+`, userID, tier).Scan(&previous)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "plan_tier_update_failed")
+		}
+		if previous != tier {
+			recordAudit(c, h.db.Pool, &userID, audit.ActionPlanTierChanged, map[string]any{"from": previous, "to": tier})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "plan_tier": tier})
+	}
+}
+
 // BootstrapAdmin promotes the currently authenticated user to admin if they know the bootstrap token.
 // This allows any authenticated user with the correct bootstrap token to become an admin.
 //
@@ -107,38 +252,38 @@ WHERE id = $1
 func (h *AdminHandler) BootstrapAdmin() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		if h.cfg.AdminBootstrapToken == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "bootstrap_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "bootstrap_not_configured")
 		}
 		if h.cfg.JWTSecret == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "jwt_not_configured")
 		}
 		headerToken := strings.TrimSpace(c.Get("X-Admin-Bootstrap-Token"))
 		configToken := strings.TrimSpace(h.cfg.AdminBootstrapToken)
 		if headerToken != configToken {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_bootstrap_token"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_bootstrap_token")
 		}
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		var currentRole string
 		if err := h.db.Pool.QueryRow(c.Context(), `SELECT role FROM users WHERE id = $1`, userID).Scan(&currentRole); err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+				return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
 			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bootstrap_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "bootstrap_failed")
 		}
 
 		// If user is already an admin, no need to update
 		if currentRole == "admin" {
-			jwtToken, err := auth.IssueJWT(h.cfg.JWTSecret, userID, "admin", "", "", 60*time.Minute)
+			jwtToken, err := h.issueBootstrapToken(c, userID)
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
 			}
 			return c.Status(fiber.StatusOK).JSON(fiber.Map{
 				"ok":    true,
@@ -150,12 +295,17 @@ func (h *AdminHandler) BootstrapAdmin() fiber.Handler {
 		// Promote user to admin if they have the correct bootstrap token
 		_, err = h.db.Pool.Exec(c.Context(), `UPDATE users SET role = 'admin', updated_at = now() WHERE id = $1`, userID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bootstrap_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "bootstrap_failed")
 		}
+		recordAudit(c, h.db.Pool, &userID, audit.ActionRoleChanged, map[string]any{
+			"from":   currentRole,
+			"to":     "admin",
+			"method": "bootstrap",
+		})
 
-		jwtToken, err := auth.IssueJWT(h.cfg.JWTSecret, userID, "admin", "", "", 60*time.Minute)
+		jwtToken, err := h.issueBootstrapToken(c, userID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"ok":    true,
@@ -165,6 +315,17 @@ func (h *AdminHandler) BootstrapAdmin() fiber.Handler {
 	}
 }
 
+// issueBootstrapToken issues the admin JWT for BootstrapAdmin, backed by a
+// session so it is listed and can be revoked like a wallet sign-in.
+func (h *AdminHandler) issueBootstrapToken(c *fiber.Ctx, userID uuid.UUID) (string, error) {
+	const ttl = 60 * time.Minute
+	sessionID, err := auth.CreateSession(c.Context(), h.db.Pool, userID, "", "", c.IP(), c.Get(fiber.HeaderUserAgent), time.Now().UTC().Add(ttl))
+	if err != nil {
+		return "", err
+	}
+	return auth.IssueJWT(h.cfg.JWTSecret, userID, sessionID, "admin", "", "", ttl)
+}
+
 
 
 
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
 type EcosystemsAdminHandler struct {
@@ -23,7 +24,7 @@ func NewEcosystemsAdminHandler(d *db.DB) *EcosystemsAdminHandler {
 func (h *EcosystemsAdminHandler) List() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
@@ -45,7 +46,7 @@ ORDER BY e.created_at DESC
 LIMIT 200
 `)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystems_list_failed")
 		}
 		defer rows.Close()
 
@@ -58,7 +59,7 @@ LIMIT 200
 			var projectCnt int64
 			var userCnt int64
 			if err := rows.Scan(&id, &slug, &name, &desc, &website, &status, &createdAt, &updatedAt, &projectCnt, &userCnt); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystems_list_failed")
 			}
 			out = append(out, fiber.Map{
 				"id":          id.String(),
@@ -89,27 +90,27 @@ type ecosystemUpsertRequest struct {
 func (h *EcosystemsAdminHandler) Create() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		var req ecosystemUpsertRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 		name := strings.TrimSpace(req.Name)
 		if name == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_required"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "name_required")
 		}
 		// Auto-generate slug from name (users never see/type slug)
 		slug := normalizeSlug(name)
 		if slug == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_must_contain_valid_characters"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "name_must_contain_valid_characters")
 		}
 		status := strings.TrimSpace(req.Status)
 		if status == "" {
 			status = "active"
 		}
 		if status != "active" && status != "inactive" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
 		}
 
 		var id uuid.UUID
@@ -119,7 +120,7 @@ VALUES ($1, $2, NULLIF($3,''), NULLIF($4,''), $5)
 RETURNING id
 `, slug, name, strings.TrimSpace(req.Description), strings.TrimSpace(req.WebsiteURL), status).Scan(&id)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_create_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystem_create_failed")
 		}
 		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": id.String()})
 	}
@@ -128,22 +129,22 @@ RETURNING id
 func (h *EcosystemsAdminHandler) Update() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		ecoID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_ecosystem_id")
 		}
 		var req ecosystemUpsertRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 
 		name := strings.TrimSpace(req.Name)
 		status := strings.TrimSpace(req.Status)
 
 		if status != "" && status != "active" && status != "inactive" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
 		}
 
 		// Auto-generate slug from name if name is provided
@@ -151,7 +152,7 @@ func (h *EcosystemsAdminHandler) Update() fiber.Handler {
 		if name != "" {
 			slug := normalizeSlug(name)
 			if slug == "" {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_must_contain_valid_characters"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "name_must_contain_valid_characters")
 			}
 			slugVal = &slug
 		}
@@ -167,10 +168,10 @@ SET slug = COALESCE($2, slug),
 WHERE id = $1
 `, ecoID, slugVal, name, strings.TrimSpace(req.Description), strings.TrimSpace(req.WebsiteURL), status)
 		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "ecosystem_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystem_update_failed")
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 	}
@@ -179,28 +180,28 @@ WHERE id = $1
 func (h *EcosystemsAdminHandler) Delete() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		ecoID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_ecosystem_id")
 		}
 
 		// Check if ecosystem has any projects
 		var projectCount int64
 		if err := h.db.Pool.QueryRow(c.Context(), `SELECT COUNT(*) FROM projects WHERE ecosystem_id = $1`, ecoID).Scan(&projectCount); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_check_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystem_delete_check_failed")
 		}
 		if projectCount > 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_has_projects", "message": "Cannot delete ecosystem with existing projects"})
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "ecosystem_has_projects").WithMessage("Cannot delete ecosystem with existing projects"))
 		}
 
 		ct, err := h.db.Pool.Exec(c.Context(), `DELETE FROM ecosystems WHERE id = $1`, ecoID)
 		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "ecosystem_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystem_delete_failed")
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 	}
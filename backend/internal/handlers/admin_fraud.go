@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/fraud"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/payouts"
+)
+
+type FraudAdminHandler struct {
+	db *db.DB
+}
+
+func NewFraudAdminHandler(d *db.DB) *FraudAdminHandler {
+	return &FraudAdminHandler{db: d}
+}
+
+// Facts returns the facts rule expressions may reference.
+func (h *FraudAdminHandler) Facts() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"facts":  fraud.KnownFacts,
+			"events": []string{fraud.EventClaim, fraud.EventPayout},
+		})
+	}
+}
+
+func (h *FraudAdminHandler) ListRules() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT id, name, description, expression, events, enabled, created_at, updated_at
+FROM fraud_rules
+ORDER BY name
+`)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_rules_list_failed")
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id uuid.UUID
+			var name, expression string
+			var desc *string
+			var events []string
+			var enabled bool
+			var createdAt, updatedAt time.Time
+			if err := rows.Scan(&id, &name, &desc, &expression, &events, &enabled, &createdAt, &updatedAt); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_rules_list_failed")
+			}
+			out = append(out, fiber.Map{
+				"id":          id.String(),
+				"name":        name,
+				"description": desc,
+				"expression":  expression,
+				"events":      events,
+				"enabled":     enabled,
+				"created_at":  createdAt,
+				"updated_at":  updatedAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"rules": out})
+	}
+}
+
+type fraudRuleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Expression  string   `json:"expression"`
+	Events      []string `json:"events"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+// validate normalizes the request. For updates, empty fields mean "unchanged".
+func (r *fraudRuleRequest) validate(partial bool) (string, string) {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Expression = strings.TrimSpace(r.Expression)
+	if !partial && r.Name == "" {
+		return "name_required", ""
+	}
+	if !partial && r.Expression == "" {
+		return "expression_required", ""
+	}
+	if r.Expression != "" {
+		if _, err := fraud.Parse(r.Expression); err != nil {
+			return "invalid_expression", err.Error()
+		}
+	}
+	for i, ev := range r.Events {
+		ev = strings.ToLower(strings.TrimSpace(ev))
+		if ev != fraud.EventClaim && ev != fraud.EventPayout {
+			return "invalid_event", ev
+		}
+		r.Events[i] = ev
+	}
+	return "", ""
+}
+
+func (h *FraudAdminHandler) CreateRule() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		adminID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		var req fraudRuleRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if code, msg := req.validate(false); code != "" {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, code).WithMessage(msg))
+		}
+		if len(req.Events) == 0 {
+			req.Events = []string{fraud.EventClaim, fraud.EventPayout}
+		}
+		enabled := true
+		if req.Enabled != nil {
+			enabled = *req.Enabled
+		}
+
+		var id uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO fraud_rules (name, description, expression, events, enabled, created_by)
+VALUES ($1, NULLIF($2,''), $3, $4, $5, $6)
+RETURNING id
+`, req.Name, strings.TrimSpace(req.Description), req.Expression, req.Events, enabled, adminID).Scan(&id)
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				return httpx.Fail(c, fiber.StatusConflict, "fraud_rule_name_taken")
+			}
+			return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_rule_create_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": id.String()})
+	}
+}
+
+func (h *FraudAdminHandler) UpdateRule() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		ruleID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_rule_id")
+		}
+		var req fraudRuleRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if code, msg := req.validate(true); code != "" {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, code).WithMessage(msg))
+		}
+		var events []string
+		if len(req.Events) > 0 {
+			events = req.Events
+		}
+
+		ct, err := h.db.Pool.Exec(c.Context(), `
+UPDATE fraud_rules
+SET name = COALESCE(NULLIF($2,''), name),
+    description = COALESCE(NULLIF($3,''), description),
+    expression = COALESCE(NULLIF($4,''), expression),
+    events = COALESCE($5, events),
+    enabled = COALESCE($6, enabled),
+    updated_at = now()
+WHERE id = $1
+`, ruleID, req.Name, strings.TrimSpace(req.Description), req.Expression, events, req.Enabled)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && ct.RowsAffected() == 0) {
+			return httpx.Fail(c, fiber.StatusNotFound, "fraud_rule_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_rule_update_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+func (h *FraudAdminHandler) DeleteRule() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		ruleID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_rule_id")
+		}
+		ct, err := h.db.Pool.Exec(c.Context(), `DELETE FROM fraud_rules WHERE id = $1`, ruleID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_rule_delete_failed")
+		}
+		if ct.RowsAffected() == 0 {
+			return httpx.Fail(c, fiber.StatusNotFound, "fraud_rule_not_found")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+func (h *FraudAdminHandler) ListReviews() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		status := strings.TrimSpace(c.Query("status", "open"))
+		if status != "open" && status != "cleared" && status != "confirmed" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT id, rule_id, rule_name, user_id, event, subject_id, facts, status, note, resolved_at, created_at
+FROM fraud_reviews
+WHERE status = $1
+ORDER BY created_at DESC
+LIMIT 200
+`, status)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_reviews_list_failed")
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id, userID uuid.UUID
+			var ruleID *uuid.UUID
+			var ruleName, event, st string
+			var subjectID, note *string
+			var facts []byte
+			var resolvedAt *time.Time
+			var createdAt time.Time
+			if err := rows.Scan(&id, &ruleID, &ruleName, &userID, &event, &subjectID, &facts, &st, &note, &resolvedAt, &createdAt); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_reviews_list_failed")
+			}
+			out = append(out, fiber.Map{
+				"id":          id.String(),
+				"rule_id":     ruleID,
+				"rule_name":   ruleName,
+				"user_id":     userID.String(),
+				"event":       event,
+				"subject_id":  subjectID,
+				"facts":       json.RawMessage(facts),
+				"status":      st,
+				"note":        note,
+				"resolved_at": resolvedAt,
+				"created_at":  createdAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"reviews": out})
+	}
+}
+
+type resolveFraudReviewRequest struct {
+	Status string `json:"status"` // cleared|confirmed
+	Note   string `json:"note"`
+}
+
+func (h *FraudAdminHandler) ResolveReview() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		reviewID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_review_id")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		adminID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		var req resolveFraudReviewRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		status := strings.TrimSpace(req.Status)
+		if status != "cleared" && status != "confirmed" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		}
+
+		var event string
+		var subjectID *string
+		err = h.db.Pool.QueryRow(c.Context(), `
+UPDATE fraud_reviews
+SET status = $2, note = NULLIF($3,''), resolved_by = $4, resolved_at = now()
+WHERE id = $1 AND status = 'open'
+RETURNING event, subject_id
+`, reviewID, status, strings.TrimSpace(req.Note), adminID).Scan(&event, &subjectID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "fraud_review_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_review_update_failed")
+		}
+		// A flagged payout is held until its reviews are resolved.
+		if event == fraud.EventPayout && subjectID != nil {
+			if payoutID, err := uuid.Parse(*subjectID); err == nil {
+				if err := payouts.ResolveHold(c.Context(), h.db.Pool, payoutID, status == "confirmed"); err != nil {
+					httpx.Logger(c).Error("failed to release held payout", "payout_id", payoutID.String(), "error", err)
+					return httpx.Fail(c, fiber.StatusInternalServerError, "fraud_review_update_failed")
+				}
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
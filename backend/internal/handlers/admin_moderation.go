@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+)
+
+type ModerationAdminHandler struct {
+	db *db.DB
+}
+
+func NewModerationAdminHandler(d *db.DB) *ModerationAdminHandler {
+	return &ModerationAdminHandler{db: d}
+}
+
+type shadowBanRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *ModerationAdminHandler) ShadowBan() fiber.Handler {
+	return h.setShadowBan(true)
+}
+
+func (h *ModerationAdminHandler) LiftShadowBan() fiber.Handler {
+	return h.setShadowBan(false)
+}
+
+func (h *ModerationAdminHandler) setShadowBan(banned bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		targetID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		if targetID == actorID {
+			return httpx.Fail(c, fiber.StatusBadRequest, "cannot_moderate_self")
+		}
+
+		var req shadowBanRequest
+		if len(c.Body()) > 0 {
+			if err := httpx.DecodeJSON(c, &req); err != nil {
+				return httpx.Write(c, err)
+			}
+		}
+
+		if err := moderation.SetShadowBan(c.Context(), h.db.Pool, actorID, targetID, banned, req.Reason); err != nil {
+			if errors.Is(err, moderation.ErrUserNotFound) {
+				return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+			}
+			httpx.Logger(c).Error("failed to update shadow ban",
+				"target_user_id", targetID.String(),
+				"banned", banned,
+				"error", err,
+			)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "shadow_ban_update_failed")
+		}
+
+		httpx.Logger(c).Info("shadow ban updated",
+			"actor_user_id", actorID.String(),
+			"target_user_id", targetID.String(),
+			"banned", banned,
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "shadow_banned": banned})
+	}
+}
+
+func (h *ModerationAdminHandler) History() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		targetID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		banned, err := moderation.IsShadowBanned(c.Context(), h.db.Pool, targetID)
+		if errors.Is(err, moderation.ErrUserNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "moderation_lookup_failed")
+		}
+		actions, err := moderation.History(c.Context(), h.db.Pool, targetID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "moderation_lookup_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"shadow_banned": banned,
+			"actions":       actions,
+		})
+	}
+}
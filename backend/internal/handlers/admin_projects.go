@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
 type ProjectsAdminHandler struct {
@@ -21,11 +22,11 @@ func NewProjectsAdminHandler(d *db.DB) *ProjectsAdminHandler {
 func (h *ProjectsAdminHandler) Delete() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		projectID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 		}
 
 		ct, err := h.db.Pool.Exec(c.Context(), `
@@ -34,10 +35,10 @@ SET deleted_at = now(), updated_at = now()
 WHERE id = $1 AND deleted_at IS NULL
 `, projectID)
 		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_delete_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_delete_failed")
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 	}
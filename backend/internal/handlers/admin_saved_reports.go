@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/savedreports"
+)
+
+type SavedReportsHandler struct {
+	db *db.DB
+}
+
+func NewSavedReportsHandler(d *db.DB) *SavedReportsHandler {
+	return &SavedReportsHandler{db: d}
+}
+
+// List returns the saved reports and the parameters each takes.
+func (h *SavedReportsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"reports": savedreports.Catalog})
+	}
+}
+
+// Run executes a saved report with its parameters taken from the query
+// string. format=csv downloads the result instead of returning JSON. Every
+// run is audited with its parameters.
+func (h *SavedReportsHandler) Run() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		report, err := savedreports.Find(c.Params("name"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusNotFound, "report_not_found")
+		}
+		format := c.Query("format", "json")
+		if format != "json" && format != "csv" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_format")
+		}
+		params := map[string]string{}
+		for _, p := range report.Params {
+			if v := c.Query(p.Name); v != "" {
+				params[p.Name] = v
+			}
+		}
+
+		res, err := savedreports.Run(c.Context(), h.db.Pool, report, params)
+		var perr *savedreports.ParamError
+		if errors.As(err, &perr) {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_report_param").
+				WithMessage(perr.Reason).With("param", perr.Param))
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "report_failed").With("report", report.Name).Wrap(err))
+		}
+		recordAudit(c, h.db.Pool, nil, audit.ActionSavedReportRun, map[string]any{
+			"report": report.Name,
+			"params": params,
+			"rows":   len(res.Rows),
+			"format": format,
+		})
+
+		if format == "json" {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{
+				"report":    report.Name,
+				"columns":   res.Columns,
+				"rows":      res.Rows,
+				"truncated": res.Truncated,
+			})
+		}
+		filename := fmt.Sprintf("%s-%s.csv", report.Name, time.Now().UTC().Format("20060102-150405"))
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+		if res.Truncated {
+			c.Set("X-Report-Truncated", "true")
+		}
+		w := csv.NewWriter(c.Status(fiber.StatusOK).Response().BodyWriter())
+		_ = w.Write(res.Columns)
+		for _, row := range res.Rows {
+			record := make([]string, len(row))
+			for i, v := range row {
+				record[i] = savedreports.String(v)
+			}
+			_ = w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	}
+}
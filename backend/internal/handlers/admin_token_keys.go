@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// tokenKeyring builds the configured GitHub token keyring, or writes the
+// error response.
+func (h *AdminHandler) tokenKeyring(c *fiber.Ctx) (*cryptox.Keyring, error) {
+	ring, err := cryptox.ParseKeyring(h.cfg.TokenEncKeyB64, h.cfg.TokenEncKeys, h.cfg.TokenEncKeyActive)
+	if errors.Is(err, cryptox.ErrNoKeys) {
+		return nil, httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
+	}
+	if err != nil {
+		return nil, httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "invalid_token_keys").Wrap(err))
+	}
+	return ring, nil
+}
+
+type tokenKeysResponse struct {
+	Versions []github.KeyVersionCount `json:"versions"`
+}
+
+// TokenKeys shows how stored GitHub tokens spread over key versions.
+func (h *AdminHandler) TokenKeys() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		ring, respErr := h.tokenKeyring(c)
+		if ring == nil {
+			return respErr
+		}
+		versions, err := github.KeyVersions(c.Context(), h.db.Pool, ring)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "token_keys_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(tokenKeysResponse{Versions: versions})
+	}
+}
+
+// RekeyTokens moves every stored GitHub token onto the active key.
+func (h *AdminHandler) RekeyTokens() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		ring, respErr := h.tokenKeyring(c)
+		if ring == nil {
+			return respErr
+		}
+		res, err := github.Rekey(c.Context(), h.db.Pool, ring)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "token_rekey_failed").Wrap(err))
+		}
+		recordAudit(c, h.db.Pool, nil, audit.ActionTokensRekeyed, map[string]any{
+			"active_version": res.ActiveVersion,
+			"rekeyed":        res.Rekeyed,
+			"failed":         res.Failed,
+		})
+		return c.Status(fiber.StatusOK).JSON(res)
+	}
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/treasury"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+type TreasuryAdminHandler struct {
+	db      *db.DB
+	wallets wallet.Registry
+}
+
+func NewTreasuryAdminHandler(d *db.DB, wallets wallet.Registry) *TreasuryAdminHandler {
+	return &TreasuryAdminHandler{db: d, wallets: wallets}
+}
+
+// Dashboard summarizes hot/cold balances, ledger positions, obligations and
+// runway per chain and asset. Balances are read live from RPC; a failed read is
+// reported on the asset rather than failing the whole response.
+func (h *TreasuryAdminHandler) Dashboard() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		d, err := treasury.BuildDashboard(c.Context(), h.db.Pool, h.wallets)
+		if err != nil {
+			httpx.Logger(c).Error("treasury dashboard failed", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "treasury_dashboard_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(d)
+	}
+}
+
+func (h *TreasuryAdminHandler) ListDestinations() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		out, err := treasury.ListDestinations(c.Context(), h.db.Pool)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "destinations_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"destinations": out})
+	}
+}
+
+type createDestinationRequest struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+	Label   string `json:"label"`
+}
+
+func (h *TreasuryAdminHandler) CreateDestination() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req createDestinationRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		req.Chain = strings.ToLower(strings.TrimSpace(req.Chain))
+		req.Address = chain.NormalizeAddress(req.Address)
+		if req.Chain == "" || req.Address == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "chain_and_address_required")
+		}
+		d, err := treasury.CreateDestination(c.Context(), h.db.Pool, req.Chain, req.Address, strings.TrimSpace(req.Label), actorID)
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				return httpx.Fail(c, fiber.StatusConflict, "destination_exists")
+			}
+			return httpx.Fail(c, fiber.StatusInternalServerError, "destination_create_failed")
+		}
+		httpx.Logger(c).Info("sweep destination added", "actor_user_id", actorID.String(), "chain", d.Chain, "address", d.Address)
+		return c.Status(fiber.StatusCreated).JSON(d)
+	}
+}
+
+func (h *TreasuryAdminHandler) ApproveDestination() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_destination_id")
+		}
+		err = treasury.ApproveDestination(c.Context(), h.db.Pool, id, actorID)
+		switch {
+		case errors.Is(err, treasury.ErrDestinationNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "destination_not_found")
+		case errors.Is(err, treasury.ErrAlreadyApproved):
+			return httpx.Fail(c, fiber.StatusConflict, "already_approved")
+		case errors.Is(err, treasury.ErrDestinationRevoked):
+			return httpx.Fail(c, fiber.StatusConflict, "destination_revoked")
+		case errors.Is(err, treasury.ErrSelfApproval):
+			return httpx.Fail(c, fiber.StatusForbidden, "approver_must_differ_from_creator")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "destination_approve_failed")
+		}
+		httpx.Logger(c).Info("sweep destination approved", "actor_user_id", actorID.String(), "destination_id", id.String())
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+func (h *TreasuryAdminHandler) RevokeDestination() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_destination_id")
+		}
+		err = treasury.RevokeDestination(c.Context(), h.db.Pool, id, actorID)
+		switch {
+		case errors.Is(err, treasury.ErrDestinationNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "destination_not_found")
+		case errors.Is(err, treasury.ErrDestinationRevoked):
+			return httpx.Fail(c, fiber.StatusConflict, "destination_revoked")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "destination_revoke_failed")
+		}
+		httpx.Logger(c).Info("sweep destination revoked", "actor_user_id", actorID.String(), "destination_id", id.String())
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+func (h *TreasuryAdminHandler) ListPolicies() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		out, err := treasury.ListPolicies(c.Context(), h.db.Pool)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "policies_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"policies": out})
+	}
+}
+
+type upsertPolicyRequest struct {
+	Chain         string `json:"chain"`
+	Asset         string `json:"asset"`
+	DestinationID string `json:"destination_id"`
+	Threshold     string `json:"threshold"`
+	Retain        string `json:"retain"`
+	Enabled       *bool  `json:"enabled"`
+}
+
+func (h *TreasuryAdminHandler) UpsertPolicy() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		var req upsertPolicyRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		destID, err := uuid.Parse(req.DestinationID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_destination_id")
+		}
+		if req.Retain == "" {
+			req.Retain = "0"
+		}
+		threshold, err := wallet.ParseAmount(req.Threshold)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_threshold")
+		}
+		retain, err := wallet.ParseAmount(req.Retain)
+		if err != nil || retain.Cmp(threshold) > 0 {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_retain")
+		}
+		p := treasury.Policy{
+			Chain:         strings.ToLower(strings.TrimSpace(req.Chain)),
+			Asset:         strings.TrimSpace(req.Asset),
+			DestinationID: destID,
+			Threshold:     req.Threshold,
+			Retain:        req.Retain,
+			Enabled:       req.Enabled == nil || *req.Enabled,
+		}
+		if p.Chain == "" || p.Asset == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "chain_and_asset_required")
+		}
+		p, err = treasury.UpsertPolicy(c.Context(), h.db.Pool, p)
+		switch {
+		case errors.Is(err, treasury.ErrDestinationNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "destination_not_found")
+		case errors.Is(err, treasury.ErrDestinationRevoked):
+			return httpx.Fail(c, fiber.StatusConflict, "destination_revoked")
+		case errors.Is(err, treasury.ErrDestinationMismatch):
+			return httpx.Fail(c, fiber.StatusBadRequest, "destination_chain_mismatch")
+		}
+		if err != nil {
+			httpx.Logger(c).Error("failed to save sweep policy", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "policy_save_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(p)
+	}
+}
+
+func (h *TreasuryAdminHandler) ListSweeps() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		limit := c.QueryInt("limit", 50)
+		if limit < 1 || limit > 200 {
+			limit = 50
+		}
+		out, err := treasury.ListSweeps(c.Context(), h.db.Pool, limit)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "sweeps_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"sweeps": out})
+	}
+}
+
+// RunSweeps triggers a sweep pass immediately instead of waiting for the schedule.
+func (h *TreasuryAdminHandler) RunSweeps() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		s := &treasury.Sweeper{Pool: h.db.Pool, Wallets: h.wallets}
+		if err := s.RunOnce(c.Context()); err != nil {
+			httpx.Logger(c).Error("manual sweep run failed", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "sweep_run_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+)
+
+// AdminUsersHandler serves the account management side of the admin API:
+// user detail, suspensions, bans and forced sign-out.
+type AdminUsersHandler struct {
+	db *db.DB
+}
+
+func NewAdminUsersHandler(d *db.DB) *AdminUsersHandler {
+	return &AdminUsersHandler{db: d}
+}
+
+// Get returns a user with their linked wallets, GitHub account and sessions.
+func (h *AdminUsersHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		userID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+
+		var role, status string
+		var suspendedUntil, bannedAt, shadowBannedAt *time.Time
+		var suspensionReason, banReason *string
+		var createdAt, updatedAt time.Time
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT u.role, `+moderation.StatusSQL+`, u.suspended_until, u.suspension_reason, u.banned_at, u.ban_reason,
+       u.shadow_banned_at, u.created_at, u.updated_at
+FROM users u
+WHERE u.id = $1
+`, userID).Scan(&role, &status, &suspendedUntil, &suspensionReason, &bannedAt, &banReason, &shadowBannedAt, &createdAt, &updatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "user_lookup_failed")
+		}
+
+		var github fiber.Map
+		var ghID int64
+		var login string
+		var avatarURL, scope *string
+		var linkedAt time.Time
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT github_user_id, login, avatar_url, scope, created_at
+FROM github_accounts
+WHERE user_id = $1
+`, userID).Scan(&ghID, &login, &avatarURL, &scope, &linkedAt)
+		switch {
+		case err == nil:
+			github = fiber.Map{
+				"github_user_id": ghID,
+				"login":          login,
+				"avatar_url":     avatarURL,
+				"scope":          scope,
+				"linked_at":      linkedAt,
+			}
+		case !errors.Is(err, pgx.ErrNoRows):
+			return httpx.Fail(c, fiber.StatusInternalServerError, "user_lookup_failed")
+		}
+
+		wallets, err := auth.ListWallets(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "user_lookup_failed")
+		}
+		sessions, err := auth.ListSessions(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "user_lookup_failed")
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"id":                userID.String(),
+			"role":              role,
+			"status":            status,
+			"suspended_until":   suspendedUntil,
+			"suspension_reason": suspensionReason,
+			"banned_at":         bannedAt,
+			"ban_reason":        banReason,
+			"shadow_banned":     shadowBannedAt != nil,
+			"created_at":        createdAt,
+			"updated_at":        updatedAt,
+			"github":            github,
+			"wallets":           wallets,
+			"sessions":          sessions,
+		})
+	}
+}
+
+type accountActionRequest struct {
+	Reason string `json:"reason"`
+	// Until ends a suspension; DurationHours is accepted instead of it.
+	Until         *time.Time `json:"until"`
+	DurationHours int        `json:"duration_hours"`
+}
+
+func (h *AdminUsersHandler) Suspend() fiber.Handler {
+	return h.accountAction(moderation.ActionSuspend)
+}
+
+func (h *AdminUsersHandler) Ban() fiber.Handler {
+	return h.accountAction(moderation.ActionBan)
+}
+
+// Reinstate lifts a suspension or a ban.
+func (h *AdminUsersHandler) Reinstate() fiber.Handler {
+	return h.accountAction(moderation.ActionReinstate)
+}
+
+// ExpireSessions signs the user out of every device.
+func (h *AdminUsersHandler) ExpireSessions() fiber.Handler {
+	return h.accountAction(moderation.ActionExpireSessions)
+}
+
+func (h *AdminUsersHandler) accountAction(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		targetID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		if targetID == actorID {
+			return httpx.Fail(c, fiber.StatusBadRequest, "cannot_moderate_self")
+		}
+
+		var req accountActionRequest
+		if len(c.Body()) > 0 {
+			if err := httpx.DecodeJSON(c, &req); err != nil {
+				return httpx.Write(c, err)
+			}
+		}
+
+		ctx := c.Context()
+		resp := fiber.Map{"ok": true, "action": action}
+		switch action {
+		case moderation.ActionSuspend:
+			until := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+			if req.Until != nil {
+				until = *req.Until
+			}
+			if !until.After(time.Now()) {
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_suspension_end")
+			}
+			err = moderation.Suspend(ctx, h.db.Pool, actorID, targetID, until, req.Reason)
+			resp["suspended_until"] = until
+		case moderation.ActionBan:
+			err = moderation.Ban(ctx, h.db.Pool, actorID, targetID, req.Reason)
+		case moderation.ActionReinstate:
+			err = moderation.Reinstate(ctx, h.db.Pool, actorID, targetID, req.Reason)
+		case moderation.ActionExpireSessions:
+			err = moderation.ExpireSessions(ctx, h.db.Pool, actorID, targetID, req.Reason)
+		}
+		if err != nil {
+			if errors.Is(err, moderation.ErrUserNotFound) {
+				return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+			}
+			httpx.Logger(c).Error("failed to apply account action",
+				"action", action,
+				"target_user_id", targetID.String(),
+				"error", err,
+			)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "account_action_failed")
+		}
+
+		httpx.Logger(c).Info("account action applied",
+			"action", action,
+			"actor_user_id", actorID.String(),
+			"target_user_id", targetID.String(),
+		)
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+}
+
+// rejectRestrictedAccount writes a 403 and reports true when a suspended or
+// banned user tries to sign in.
+func rejectRestrictedAccount(c *fiber.Ctx, pool *pgxpool.Pool, userID uuid.UUID) (bool, error) {
+	until, err := moderation.CheckAccountAccess(c.Context(), pool, userID)
+	switch {
+	case errors.Is(err, moderation.ErrAccountBanned):
+		return true, httpx.Fail(c, fiber.StatusForbidden, "account_banned")
+	case errors.Is(err, moderation.ErrAccountSuspended):
+		return true, httpx.Write(c, httpx.New(fiber.StatusForbidden, "account_suspended").With("suspended_until", until))
+	case err != nil:
+		return true, httpx.Fail(c, fiber.StatusInternalServerError, "auth_failed")
+	}
+	return false, nil
+}
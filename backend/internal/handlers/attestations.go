@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/attest"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+type AttestationsHandler struct {
+	db       *db.DB
+	attester *attest.Attester
+}
+
+func NewAttestationsHandler(cfg config.Config, d *db.DB, wallets wallet.Registry) *AttestationsHandler {
+	h := &AttestationsHandler{db: d}
+	if cfg.EASChain != "" && d != nil && d.Pool != nil {
+		a, err := attest.New(d.Pool, wallets, cfg.EASChain, cfg.EASContract, cfg.EASSchemaUID)
+		if err != nil {
+			slog.Error("attestation verification disabled", "error", err)
+		} else {
+			h.attester = a
+		}
+	}
+	return h
+}
+
+// ForUser lists a contributor's published attestations (public).
+func (h *AttestationsHandler) ForUser() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		userID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		out, err := attest.ListForUser(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "attestations_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"attestations": out, "schema": attest.SchemaDefinition})
+	}
+}
+
+func (h *AttestationsHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if _, err := attest.ParseUID(c.Params("uid")); err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_uid")
+		}
+		a, err := attest.GetByUID(c.Context(), h.db.Pool, c.Params("uid"))
+		if errors.Is(err, attest.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "attestation_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "attestation_get_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(a)
+	}
+}
+
+// Verify checks the attestation on-chain against the platform's record.
+func (h *AttestationsHandler) Verify() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.attester == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "attestations_not_configured")
+		}
+		if _, err := attest.ParseUID(c.Params("uid")); err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_uid")
+		}
+		v, err := h.attester.Verify(c.Context(), c.Params("uid"))
+		if err != nil {
+			httpx.Logger(c).Warn("attestation verification failed", "uid", c.Params("uid"), "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "verification_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(v)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// recordAudit logs an event about userID (nil when no account is known),
+// taking the IP and user agent from the request. The caller is recorded as the
+// actor only when it is someone other than userID, i.e. an admin.
+func recordAudit(c *fiber.Ctx, pool *pgxpool.Pool, userID *uuid.UUID, action string, metadata map[string]any) {
+	e := audit.Entry{
+		UserID:    userID,
+		Action:    action,
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Metadata:  metadata,
+	}
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	if actorID, err := uuid.Parse(sub); err == nil && (userID == nil || *userID != actorID) {
+		e.ActorUserID = &actorID
+	}
+	audit.Record(c.Context(), pool, e)
+}
+
+// walletOwner returns the account a wallet belongs to, so pre-login events
+// show up in that user's own audit log. nil when the wallet is unknown.
+func walletOwner(c *fiber.Ctx, pool *pgxpool.Pool, walletType auth.WalletType, address string) *uuid.UUID {
+	var userID uuid.UUID
+	if err := pool.QueryRow(c.Context(), `SELECT user_id FROM wallets WHERE wallet_type = $1 AND address = $2`, string(walletType), address).Scan(&userID); err != nil {
+		return nil
+	}
+	return &userID
+}
+
+type AuditHandler struct {
+	db *db.DB
+}
+
+func NewAuditHandler(d *db.DB) *AuditHandler {
+	return &AuditHandler{db: d}
+}
+
+// Mine lists the caller's own audit trail.
+func (h *AuditHandler) Mine() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		f, err := parseAuditFilter(c)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		f.UserID = &userID
+		return h.list(c, f)
+	}
+}
+
+// List is the admin view over every user, optionally narrowed by ?user_id=.
+func (h *AuditHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		f, err := parseAuditFilter(c)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		if v := strings.TrimSpace(c.Query("user_id")); v != "" {
+			userID, err := uuid.Parse(v)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+			}
+			f.UserID = &userID
+		}
+		return h.list(c, f)
+	}
+}
+
+func (h *AuditHandler) list(c *fiber.Ctx, f audit.Filter) error {
+	entries, next, err := audit.List(c.Context(), h.db.Pool, f)
+	if err != nil {
+		return httpx.Fail(c, fiber.StatusInternalServerError, "audit_list_failed")
+	}
+	resp := fiber.Map{"entries": entries}
+	if next != "" {
+		resp["next_cursor"] = next
+		if c.Query("sort") == "" {
+			// For clients still paging with ?before=.
+			resp["next_before"] = entries[len(entries)-1].CreatedAt
+		}
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// parseAuditFilter reads audit.ListSpec's parameters (?action= takes
+// several, comma-separated) and the older ?since=, ?until= and ?before=
+// (RFC 3339).
+func parseAuditFilter(c *fiber.Ctx) (audit.Filter, error) {
+	page, err := httpx.ParseList(c, audit.ListSpec)
+	if err != nil {
+		return audit.Filter{}, err
+	}
+	f := audit.Filter{Page: page}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}, {"before", &f.Before}} {
+		v := strings.TrimSpace(c.Query(p.name))
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return audit.Filter{}, httpx.New(fiber.StatusBadRequest, "invalid_"+p.name)
+		}
+		*p.dst = &t
+	}
+	return f, nil
+}
@@ -1,151 +1,579 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/stellar/go/network"
 
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/cache"
+	"github.com/jagadeesh/grainlify/backend/internal/captcha"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/mailer"
+	"github.com/jagadeesh/grainlify/backend/internal/metrics"
+	"github.com/jagadeesh/grainlify/backend/internal/profilesync"
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
 )
 
 type AuthHandler struct {
-	cfg config.Config
-	db  *db.DB
+	cfg     config.Config
+	db      *db.DB
+	captcha *captcha.Guard
+	burst   *captcha.Burst
+	// githubProfiles caches the GitHub block of /auth/me per user; nil disables.
+	githubProfiles *cache.Cache
+	// mail sends verification codes and recovery notices; nil when unconfigured.
+	mail *mailer.Mailer
+	// createNonce issues a login nonce.
+	createNonce func(ctx context.Context, walletType auth.WalletType, address string, ttl time.Duration, powDifficulty int) (auth.Nonce, error)
+	// noncePoW looks up a live nonce's proof-of-work difficulty.
+	noncePoW func(ctx context.Context, walletType auth.WalletType, address, nonce string) (int, error)
 }
 
-func NewAuthHandler(cfg config.Config, d *db.DB) *AuthHandler {
-	return &AuthHandler{cfg: cfg, db: d}
+func NewAuthHandler(cfg config.Config, d *db.DB, githubProfiles *cache.Cache) *AuthHandler {
+	guard, err := captcha.NewGuard(cfg.CaptchaProvider, cfg.CaptchaSecret, cfg.CaptchaSiteKey)
+	if err != nil {
+		slog.Error("captcha disabled: invalid configuration", "error", err)
+	}
+	var burst *captcha.Burst
+	if guard != nil || cfg.AuthPoWEscalatedDifficulty > 0 {
+		burst = captcha.NewBurst(cfg.CaptchaNonceThreshold, time.Minute)
+	}
+	mail, err := mailer.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	if err != nil {
+		slog.Error("email disabled: invalid configuration", "error", err)
+	}
+	h := &AuthHandler{cfg: cfg, db: d, captcha: guard, burst: burst, githubProfiles: githubProfiles, mail: mail}
+	h.createNonce = func(ctx context.Context, walletType auth.WalletType, address string, ttl time.Duration, powDifficulty int) (auth.Nonce, error) {
+		return auth.CreateNonce(ctx, d.Pool, walletType, address, ttl, powDifficulty)
+	}
+	h.noncePoW = func(ctx context.Context, walletType auth.WalletType, address, nonce string) (int, error) {
+		return auth.NoncePoWDifficulty(ctx, d.Pool, walletType, address, nonce)
+	}
+	if h.siweDomain() == "" {
+		slog.Error("SIWE and Stellar challenge sign-in disabled: set SIWE_DOMAIN or FRONTEND_BASE_URL")
+	}
+	return h
 }
 
 type nonceRequest struct {
-	WalletType string `json:"wallet_type"`
-	Address    string `json:"address"`
+	WalletType   string `json:"wallet_type"`
+	Address      string `json:"address"`
+	CaptchaToken string `json:"captcha_token,omitempty"`
+	// Challenge lets wallet-native clients pick "pow" over a CAPTCHA when challenged.
+	Challenge string `json:"challenge,omitempty"`
+	// ChainID goes into the SIWE message offered to EVM wallets; it must be
+	// one this service accepts (default the first of them).
+	ChainID int64 `json:"chain_id,omitempty"`
+}
+
+// localNoncePoW carries the puzzle difficulty NonceChallenge settled on to
+// Nonce.
+const localNoncePoW = "nonce_pow_difficulty"
+
+// NonceChallenge guards nonce issuance: only bursty IPs are challenged, with
+// a CAPTCHA or an escalated proof-of-work puzzle, so normal logins never see
+// one.
+func (h *AuthHandler) NonceChallenge() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req nonceRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+
+		powDifficulty := h.cfg.AuthPoWDifficulty
+		if h.burst.Observe(c.IP()) {
+			wantsPoW := strings.EqualFold(strings.TrimSpace(req.Challenge), "pow")
+			switch {
+			case wantsPoW && h.cfg.AuthPoWEscalatedDifficulty > 0:
+				powDifficulty = max(powDifficulty, h.cfg.AuthPoWEscalatedDifficulty)
+			case h.captcha == nil:
+				// PoW is the only challenge configured; escalate regardless of what was asked.
+				powDifficulty = max(powDifficulty, h.cfg.AuthPoWEscalatedDifficulty)
+			case strings.TrimSpace(req.CaptchaToken) == "":
+				herr := httpx.New(fiber.StatusForbidden, "captcha_required").
+					With("provider", h.captcha.Provider).
+					With("site_key", h.captcha.SiteKey)
+				if h.cfg.AuthPoWEscalatedDifficulty > 0 {
+					herr = herr.With("alternatives", []string{"pow"})
+				}
+				return httpx.Write(c, herr)
+			default:
+				if err := h.captcha.Verify(c.Context(), req.CaptchaToken, c.IP()); err != nil {
+					httpx.Logger(c).Warn("nonce captcha verification failed",
+						"remote_ip", c.IP(),
+						"error", err,
+					)
+					return httpx.Fail(c, fiber.StatusForbidden, "captcha_invalid")
+				}
+			}
+		}
+		c.Locals(localNoncePoW, powDifficulty)
+		return c.Next()
+	}
 }
 
 func (h *AuthHandler) Nonce() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		var req nonceRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		powDifficulty, ok := c.Locals(localNoncePoW).(int)
+		if !ok {
+			powDifficulty = h.cfg.AuthPoWDifficulty
 		}
 
 		wType, err := auth.NormalizeWalletType(req.WalletType)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_wallet_type")
 		}
 		addr, err := auth.NormalizeAddress(wType, req.Address)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_address")
 		}
 
-		n, err := auth.CreateNonce(c.Context(), h.db.Pool, wType, addr, 10*time.Minute)
+		// Refuse before a nonce is issued, recorded or audited.
+		chainIDs := h.siweChainIDs()
+		if wType == auth.WalletTypeEVM && req.ChainID > 0 && !slices.Contains(chainIDs, req.ChainID) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "unsupported_chain_id")
+		}
+		if wType == auth.WalletTypeEVM && h.cfg.AuthRequireSIWE && h.siweDomain() == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "siwe_not_configured")
+		}
+
+		n, err := h.createNonce(c.Context(), wType, addr, 10*time.Minute, powDifficulty)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "nonce_create_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "nonce_create_failed")
 		}
+		metrics.NonceIssued(string(wType))
+		recordAudit(c, h.db.Pool, walletOwner(c, h.db.Pool, wType, addr), audit.ActionNonceIssued, map[string]any{
+			"wallet_type":    wType,
+			"address":        addr,
+			"pow_difficulty": n.PoWDifficulty,
+		})
 
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		resp := fiber.Map{
 			"nonce":      n.Nonce,
 			"message":    auth.LoginMessage(n.Nonce),
 			"expires_at": n.ExpiresAt,
-		})
+		}
+		if wType == auth.WalletTypeEVM && h.siweDomain() != "" {
+			chainID := req.ChainID
+			if chainID <= 0 {
+				chainID = chainIDs[0]
+			}
+			expiresAt := n.ExpiresAt
+			resp["siwe_message"] = auth.SIWEMessage{
+				Domain:         h.siweDomain(),
+				Address:        common.HexToAddress(addr).Hex(),
+				Statement:      h.cfg.SIWEStatement,
+				URI:            h.siweURI(),
+				ChainID:        chainID,
+				Nonce:          n.Nonce,
+				IssuedAt:       time.Now().UTC(),
+				ExpirationTime: &expiresAt,
+			}.String()
+		}
+		if wType == auth.WalletTypeStellarEd25519 && strings.HasPrefix(addr, "G") && h.siweDomain() != "" {
+			// Wallets that can only sign transactions sign this instead of the message.
+			if tx, err := auth.StellarChallenge(addr, h.siweDomain(), n.Nonce, time.Now(), n.ExpiresAt); err == nil {
+				resp["stellar_challenge"] = fiber.Map{
+					"transaction":        tx,
+					"network_passphrase": h.stellarPassphrase(),
+				}
+			}
+		}
+		if n.PoWDifficulty > 0 {
+			resp["pow"] = fiber.Map{
+				"algorithm":  "sha256",
+				"challenge":  n.Nonce,
+				"difficulty": n.PoWDifficulty,
+				"format":     "sha256(challenge + \":\" + solution) with `difficulty` leading zero bits",
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
 	}
 }
 
 type verifyRequest struct {
-	WalletType string `json:"wallet_type"`
-	Address    string `json:"address"`
-	Nonce      string `json:"nonce"`
-	Signature  string `json:"signature"`
-	PublicKey  string `json:"public_key,omitempty"`
+	WalletType  string `json:"wallet_type"`
+	Address     string `json:"address"`
+	Nonce       string `json:"nonce"`
+	Signature   string `json:"signature"`
+	PublicKey   string `json:"public_key,omitempty"`
+	PoWSolution string `json:"pow_solution,omitempty"`
+	// Message is the signed EIP-4361 message, for EVM wallets signing SIWE.
+	Message string `json:"message,omitempty"`
+	// Transaction is the signed stellar_challenge, in place of a signature.
+	Transaction string `json:"transaction,omitempty"`
 }
 
 func (h *AuthHandler) Verify() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		if h.cfg.JWTSecret == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "jwt_not_configured")
 		}
 
 		var req verifyRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 
-		wType, err := auth.NormalizeWalletType(req.WalletType)
+		if status, code := h.checkNoncePoW(c, req); status != 0 {
+			if status == fiber.StatusUnauthorized {
+				h.auditLoginFailure(c, req, code)
+			}
+			return httpx.Fail(c, status, code)
+		}
+		wType, addr, status, code := h.checkWalletProof(req)
+		if status != 0 {
+			if status == fiber.StatusUnauthorized {
+				h.auditLoginFailure(c, req, code)
+			}
+			return httpx.Fail(c, status, code)
+		}
+
+		res, err := auth.ConsumeNonceAndUpsertUser(c.Context(), h.db.Pool, wType, addr, req.Nonce, req.PublicKey, req.PoWSolution)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
+			if err.Error() == "invalid_or_expired_nonce" {
+				h.auditLoginFailure(c, req, err.Error())
+				return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_or_expired_nonce")
+			}
+			if err.Error() == "invalid_pow" {
+				h.auditLoginFailure(c, req, err.Error())
+				return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_pow")
+			}
+			return httpx.Fail(c, fiber.StatusInternalServerError, "auth_failed")
 		}
-		addr, err := auth.NormalizeAddress(wType, req.Address)
+
+		if blocked, err := rejectRestrictedAccount(c, h.db.Pool, res.User.ID); blocked {
+			h.auditLoginFailure(c, req, "account_restricted")
+			return err
+		}
+
+		recordIPCountry(c, h.cfg, h.db.Pool, res.User.ID)
+
+		sessionID, err := auth.CreateSession(c.Context(), h.db.Pool, res.User.ID, res.Wallet.WalletType, res.Wallet.Address, c.IP(), c.Get(fiber.HeaderUserAgent), time.Now().UTC().Add(h.refreshTTL()))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
 		}
-		if req.Nonce == "" || req.Signature == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_nonce_or_signature"})
+
+		token, err := auth.IssueJWT(h.cfg.JWTSecret, res.User.ID, sessionID, res.User.Role, res.Wallet.WalletType, res.Wallet.Address, 15*time.Minute)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
+		}
+
+		refresh, err := auth.IssueRefreshToken(c.Context(), h.db.Pool, sessionID, res.User.ID, res.Wallet.WalletType, res.Wallet.Address, h.refreshTTL())
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
+		}
+		metrics.Login("wallet", true)
+		recordAudit(c, h.db.Pool, &res.User.ID, audit.ActionLoginSucceeded, map[string]any{
+			"method":      "wallet",
+			"wallet_type": res.Wallet.WalletType,
+			"address":     res.Wallet.Address,
+			"session_id":  sessionID,
+		})
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"token":              token,
+			"refresh_token":      refresh.Token,
+			"refresh_expires_at": refresh.ExpiresAt,
+			"user":               res.User,
+			"wallet": fiber.Map{
+				"wallet_type": res.Wallet.WalletType,
+				"address":     res.Wallet.Address,
+			},
+		})
+	}
+}
+
+// checkNoncePoW rejects a request whose nonce puzzle is unsolved before
+// checkWalletProof spends CPU on its signature. The nonce is only consumed
+// later, once the signature holds. Malformed wallets are left for
+// checkWalletProof to report.
+func (h *AuthHandler) checkNoncePoW(c *fiber.Ctx, req verifyRequest) (int, string) {
+	wType, err := auth.NormalizeWalletType(req.WalletType)
+	if err != nil {
+		return 0, ""
+	}
+	addr, err := auth.NormalizeAddress(wType, req.Address)
+	if err != nil || req.Nonce == "" {
+		return 0, ""
+	}
+	difficulty, err := h.noncePoW(c.Context(), wType, addr, req.Nonce)
+	switch {
+	case err != nil && err.Error() == "invalid_or_expired_nonce":
+		return fiber.StatusUnauthorized, err.Error()
+	case err != nil:
+		return fiber.StatusInternalServerError, "nonce_lookup_failed"
+	case !auth.VerifyPoW(req.Nonce, req.PoWSolution, difficulty):
+		return fiber.StatusUnauthorized, "invalid_pow"
+	}
+	return 0, ""
+}
+
+// checkWalletProof verifies the signed login message in req and returns the
+// normalized wallet, or a non-zero status with the error code to reply with.
+func (h *AuthHandler) checkWalletProof(req verifyRequest) (auth.WalletType, string, int, string) {
+	wType, err := auth.NormalizeWalletType(req.WalletType)
+	if err != nil {
+		return "", "", fiber.StatusBadRequest, "invalid_wallet_type"
+	}
+	addr, err := auth.NormalizeAddress(wType, req.Address)
+	if err != nil {
+		return "", "", fiber.StatusBadRequest, "invalid_address"
+	}
+	if req.Transaction != "" {
+		if wType != auth.WalletTypeStellarEd25519 || req.Nonce == "" {
+			return "", "", fiber.StatusBadRequest, "stellar_challenge_only"
 		}
+		if h.siweDomain() == "" {
+			return "", "", fiber.StatusServiceUnavailable, "siwe_not_configured"
+		}
+		if err := auth.VerifyStellarChallenge(req.Transaction, addr, h.siweDomain(), req.Nonce, h.stellarPassphrase(), time.Now()); err != nil {
+			return "", "", fiber.StatusUnauthorized, "invalid_signature"
+		}
+		return wType, addr, 0, ""
+	}
+	if req.Nonce == "" || req.Signature == "" {
+		return "", "", fiber.StatusBadRequest, "missing_nonce_or_signature"
+	}
 
+	var msgs []string
+	switch {
+	case req.Message != "":
+		if wType != auth.WalletTypeEVM {
+			return "", "", fiber.StatusBadRequest, "siwe_evm_only"
+		}
+		if h.siweDomain() == "" {
+			return "", "", fiber.StatusServiceUnavailable, "siwe_not_configured"
+		}
+		m, err := auth.ParseSIWE(req.Message)
+		if err != nil {
+			return "", "", fiber.StatusBadRequest, "invalid_siwe_message"
+		}
+		if err := m.Validate(h.siweDomain(), h.siweChainIDs(), addr, req.Nonce, time.Now()); err != nil {
+			return "", "", fiber.StatusUnauthorized, err.Error()
+		}
+		msgs = []string{req.Message}
+	case wType == auth.WalletTypeEVM && h.cfg.AuthRequireSIWE:
+		return "", "", fiber.StatusBadRequest, "siwe_required"
+	default:
 		// Be tolerant during early dev: accept both the current canonical message and the
 		// legacy newline message (so signing tools that copied `\n` vs newline don't block you).
-		msgs := []string{
+		msgs = []string{
 			auth.LoginMessage(req.Nonce),
 			auth.LegacyLoginMessage(req.Nonce),
 		}
-		var sigOK bool
-		for _, msg := range msgs {
-			if err := auth.VerifySignature(wType, addr, msg, req.Signature, req.PublicKey); err == nil {
-				sigOK = true
-				break
-			}
+	}
+	var sigOK bool
+	for _, msg := range msgs {
+		if err := auth.VerifySignature(wType, addr, msg, req.Signature, req.PublicKey); err == nil {
+			sigOK = true
+			break
+		}
+	}
+	if !sigOK {
+		return "", "", fiber.StatusUnauthorized, "invalid_signature"
+	}
+	return wType, addr, 0, ""
+}
+
+// auditLoginFailure records a rejected wallet login against the wallet's owner,
+// when the wallet is linked to an account.
+func (h *AuthHandler) auditLoginFailure(c *fiber.Ctx, req verifyRequest, reason string) {
+	meta := map[string]any{"method": "wallet", "reason": reason}
+	var owner *uuid.UUID
+	if wType, err := auth.NormalizeWalletType(req.WalletType); err == nil {
+		meta["wallet_type"] = wType
+		if addr, err := auth.NormalizeAddress(wType, req.Address); err == nil {
+			meta["address"] = addr
+			owner = walletOwner(c, h.db.Pool, wType, addr)
+		}
+	}
+	recordAudit(c, h.db.Pool, owner, audit.ActionLoginFailed, meta)
+	metrics.Login("wallet", false)
+}
+
+// siweDomain is the domain SIWE messages and Stellar challenges must be bound
+// to: SIWE_DOMAIN, or the host of FRONTEND_BASE_URL. When it is empty both
+// are refused.
+func (h *AuthHandler) siweDomain() string {
+	if h.cfg.SIWEDomain != "" {
+		return h.cfg.SIWEDomain
+	}
+	if u, err := url.Parse(h.cfg.FrontendBaseURL); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+// siweChainIDs are the chain IDs SIWE messages may name: SIWE_CHAIN_IDS, or
+// those of the chains in EVM_RPC_URLS, or mainnet. The first is the default.
+func (h *AuthHandler) siweChainIDs() []int64 {
+	var ids []int64
+	for _, v := range h.cfg.SIWEChainIDs {
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil && id > 0 {
+			ids = append(ids, id)
 		}
-		if !sigOK {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_signature"})
+	}
+	if len(ids) > 0 {
+		return ids
+	}
+	for _, pair := range strings.Split(h.cfg.EVMRPCURLs, ",") {
+		chain, _, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if id, ok := auth.EVMChainID(chain); ok && !slices.Contains(ids, id) {
+			ids = append(ids, id)
 		}
+	}
+	if len(ids) > 0 {
+		return ids
+	}
+	return []int64{1}
+}
+
+func (h *AuthHandler) siweURI() string {
+	if h.cfg.SIWEURI != "" {
+		return h.cfg.SIWEURI
+	}
+	if h.cfg.FrontendBaseURL != "" {
+		return h.cfg.FrontendBaseURL
+	}
+	return "https://" + h.siweDomain()
+}
 
-		res, err := auth.ConsumeNonceAndUpsertUser(c.Context(), h.db.Pool, wType, addr, req.Nonce, req.PublicKey)
+// stellarPassphrase is the network Stellar challenges are signed for.
+func (h *AuthHandler) stellarPassphrase() string {
+	if h.cfg.SorobanNetworkPassphrase != "" {
+		return h.cfg.SorobanNetworkPassphrase
+	}
+	if soroban.Network(h.cfg.SorobanNetwork) == soroban.NetworkMainnet {
+		return network.PublicNetworkPassphrase
+	}
+	return network.TestNetworkPassphrase
+}
+
+func (h *AuthHandler) refreshTTL() time.Duration {
+	return time.Duration(h.cfg.RefreshTokenTTLHours) * time.Hour
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh rotates a refresh token and issues a new access token. Reusing a
+// rotated-out token revokes the whole session.
+func (h *AuthHandler) Refresh() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if h.cfg.JWTSecret == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "jwt_not_configured")
+		}
+
+		var req refreshRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+
+		sess, refresh, err := auth.RotateRefreshToken(c.Context(), h.db.Pool, strings.TrimSpace(req.RefreshToken), h.refreshTTL())
 		if err != nil {
-			if err.Error() == "invalid_or_expired_nonce" {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_or_expired_nonce"})
-			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_failed"})
+			status, code := refreshFailure(err)
+			return httpx.Fail(c, status, code)
 		}
 
-		token, err := auth.IssueJWT(h.cfg.JWTSecret, res.User.ID, res.User.Role, res.Wallet.WalletType, res.Wallet.Address, 15*time.Minute)
+		recordIPCountry(c, h.cfg, h.db.Pool, sess.User.ID)
+
+		token, err := auth.IssueJWT(h.cfg.JWTSecret, sess.User.ID, sess.ID, sess.User.Role, sess.WalletType, sess.Address, 15*time.Minute)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"token": token,
-			"user":  res.User,
-			"wallet": fiber.Map{
-				"wallet_type": res.Wallet.WalletType,
-				"address":     res.Wallet.Address,
-			},
+			"token":              token,
+			"refresh_token":      refresh.Token,
+			"refresh_expires_at": refresh.ExpiresAt,
+			"user":               sess.User,
 		})
 	}
 }
 
+// refreshFailure maps a failed token rotation to its response.
+func refreshFailure(err error) (int, string) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidRefreshToken):
+		return fiber.StatusUnauthorized, "invalid_refresh_token"
+	case errors.Is(err, auth.ErrRefreshTokenReused):
+		return fiber.StatusUnauthorized, "refresh_token_reused"
+	default:
+		return fiber.StatusInternalServerError, "token_refresh_failed"
+	}
+}
+
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	// All signs out every session of the user, not just this one.
+	All bool `json:"all"`
+}
+
+// Logout revokes the session behind a refresh token. The access token stays
+// valid until it expires.
+func (h *AuthHandler) Logout() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+
+		var req logoutRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if strings.TrimSpace(req.RefreshToken) == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_refresh_token")
+		}
+
+		if err := auth.RevokeRefreshToken(c.Context(), h.db.Pool, strings.TrimSpace(req.RefreshToken), req.All); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "logout_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
 func (h *AuthHandler) Me() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
 		role, _ := c.Locals(auth.LocalRole).(string)
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		// Get user profile fields from database
@@ -156,7 +584,7 @@ FROM users
 WHERE id = $1
 `, userID).Scan(&firstName, &lastName, &location, &website, &bio, &avatarURL, &telegram, &linkedin, &whatsapp, &twitter, &discord)
 		if err != nil {
-			slog.Warn("failed to fetch user profile fields", "error", err, "user_id", userID)
+			httpx.Logger(c).Warn("failed to fetch user profile fields", "error", err, "user_id", userID)
 		}
 
 		response := fiber.Map{
@@ -164,116 +592,55 @@ WHERE id = $1
 			"role": role,
 		}
 
-		// Try to get GitHub access token and fetch full profile
-		linkedAccount, err := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
-		if err == nil {
-			// Fetch full GitHub user profile
-			gh := github.NewClient()
-			ghUser, err := gh.GetUser(c.Context(), linkedAccount.AccessToken)
-			if err == nil {
-				githubMap := fiber.Map{
-					"login": ghUser.Login,
-				}
-				// Use database avatar_url if set, otherwise use GitHub avatar
-				if avatarURL != nil && *avatarURL != "" {
-					githubMap["avatar_url"] = *avatarURL
-				} else {
-					githubMap["avatar_url"] = ghUser.AvatarURL
-				}
-				// Add optional fields if available
-				if ghUser.Name != "" {
-					githubMap["name"] = ghUser.Name
-				}
-				// Try to get email from GitHub emails endpoint (more reliable)
-				email, err := gh.GetPrimaryEmail(c.Context(), linkedAccount.AccessToken)
-				if err == nil && email != "" {
-					githubMap["email"] = email
-				} else if ghUser.Email != "" {
-					// Fallback to email from /user endpoint
-					githubMap["email"] = ghUser.Email
-				}
-				// Use database location if set, otherwise use GitHub location
-				if location != nil && *location != "" {
-					githubMap["location"] = *location
-				} else if ghUser.Location != "" {
-					githubMap["location"] = ghUser.Location
-				}
-				// Use database bio if set, otherwise use GitHub bio
-				if bio != nil && *bio != "" {
-					githubMap["bio"] = *bio
-				} else if ghUser.Bio != "" {
-					githubMap["bio"] = ghUser.Bio
-				}
-				// Use database website if set, otherwise use GitHub blog
-				if website != nil && *website != "" {
-					githubMap["website"] = *website
-				} else if ghUser.Blog != "" {
-					githubMap["website"] = ghUser.Blog
-				}
-				response["github"] = githubMap
-			} else {
-				// Fallback to database values if GitHub API fails
-				var githubLogin *string
-				var githubAvatarURL *string
-				_ = h.db.Pool.QueryRow(c.Context(), `
-SELECT login, avatar_url
-FROM github_accounts
-WHERE user_id = $1
-`, userID).Scan(&githubLogin, &githubAvatarURL)
-				if githubLogin != nil {
-					githubMap := fiber.Map{
-						"login": *githubLogin,
-					}
-					// Use database avatar_url if set, otherwise use GitHub account avatar
-					if avatarURL != nil && *avatarURL != "" {
-						githubMap["avatar_url"] = *avatarURL
-					} else if githubAvatarURL != nil && *githubAvatarURL != "" {
-						githubMap["avatar_url"] = *githubAvatarURL
-					}
-					// Add profile fields from database
-					if location != nil && *location != "" {
-						githubMap["location"] = *location
-					}
-					if bio != nil && *bio != "" {
-						githubMap["bio"] = *bio
-					}
-					if website != nil && *website != "" {
-						githubMap["website"] = *website
-					}
-					response["github"] = githubMap
+		// GitHub data comes from the local copy kept by the profile sync
+		// job; a missing copy (e.g. linked before syncing existed) is queued.
+		gh := h.githubProfile(c.Context(), userID)
+		githubLogin, githubAvatarURL, syncedAt := gh.Login, gh.AccountAvatarURL, gh.SyncedAt
+		ghName, ghEmail, ghAvatarURL, ghLocation, ghBio, ghBlog := gh.Name, gh.Email, gh.AvatarURL, gh.Location, gh.Bio, gh.Blog
+		if githubLogin != nil {
+			if syncedAt == nil {
+				if err := profilesync.Enqueue(c.Context(), h.db.Pool, userID); err != nil {
+					httpx.Logger(c).Warn("failed to queue github profile sync", "error", err, "user_id", userID)
 				}
 			}
-		} else {
-			// No GitHub account linked, try to get from database anyway
-			var githubLogin *string
-			var githubAvatarURL *string
-			_ = h.db.Pool.QueryRow(c.Context(), `
-SELECT login, avatar_url
-FROM github_accounts
-WHERE user_id = $1
-`, userID).Scan(&githubLogin, &githubAvatarURL)
-			if githubLogin != nil {
-				githubMap := fiber.Map{
-					"login": *githubLogin,
-				}
-				// Use database avatar_url if set, otherwise use GitHub account avatar
-				if avatarURL != nil && *avatarURL != "" {
-					githubMap["avatar_url"] = *avatarURL
-				} else if githubAvatarURL != nil && *githubAvatarURL != "" {
-					githubMap["avatar_url"] = *githubAvatarURL
-				}
-				// Add profile fields from database
-				if location != nil && *location != "" {
-					githubMap["location"] = *location
-				}
-				if bio != nil && *bio != "" {
-					githubMap["bio"] = *bio
-				}
-				if website != nil && *website != "" {
-					githubMap["website"] = *website
-				}
-				response["github"] = githubMap
+			if ghAvatarURL != nil && *ghAvatarURL != "" {
+				githubAvatarURL = ghAvatarURL
+			}
+			githubMap := fiber.Map{
+				"login": *githubLogin,
+			}
+			// Use database avatar_url if set, otherwise use GitHub avatar
+			if avatarURL != nil && *avatarURL != "" {
+				githubMap["avatar_url"] = *avatarURL
+			} else if githubAvatarURL != nil && *githubAvatarURL != "" {
+				githubMap["avatar_url"] = *githubAvatarURL
+			}
+			if ghName != nil && *ghName != "" {
+				githubMap["name"] = *ghName
 			}
+			if ghEmail != nil && *ghEmail != "" {
+				githubMap["email"] = *ghEmail
+			}
+			// Profile fields set on Grainlify win over GitHub's
+			if location != nil && *location != "" {
+				githubMap["location"] = *location
+			} else if ghLocation != nil && *ghLocation != "" {
+				githubMap["location"] = *ghLocation
+			}
+			if bio != nil && *bio != "" {
+				githubMap["bio"] = *bio
+			} else if ghBio != nil && *ghBio != "" {
+				githubMap["bio"] = *ghBio
+			}
+			if website != nil && *website != "" {
+				githubMap["website"] = *website
+			} else if ghBlog != nil && *ghBlog != "" {
+				githubMap["website"] = *ghBlog
+			}
+			if syncedAt != nil {
+				githubMap["synced_at"] = *syncedAt
+			}
+			response["github"] = githubMap
 		}
 
 		// Add user profile fields to response (for first_name, last_name, social links)
@@ -298,6 +665,14 @@ WHERE user_id = $1
 		if discord != nil && *discord != "" {
 			response["discord"] = *discord
 		}
+		if email, verified, err := auth.UserEmail(c.Context(), h.db.Pool, userID); err == nil && email != "" {
+			response["email"] = email
+			response["email_verified"] = verified
+		}
+		// Surfaced on every session so the owner notices a recovery they didn't start.
+		if r, err := auth.PendingRecovery(c.Context(), h.db.Pool, userID); err == nil {
+			response["pending_recovery"] = r
+		}
 
 		return c.Status(fiber.StatusOK).JSON(response)
 	}
@@ -307,68 +682,46 @@ WHERE user_id = $1
 func (h *AuthHandler) ResyncGitHubProfile() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
-		}
-
-		// Get GitHub access token
-		linkedAccount, err := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
-		if err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "github_not_linked"})
-		}
-
-		// Fetch fresh GitHub user profile
-		gh := github.NewClient()
-		ghUser, err := gh.GetUser(c.Context(), linkedAccount.AccessToken)
-		if err != nil {
-			slog.Error("failed to fetch GitHub user", "error", err, "user_id", userID)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "github_fetch_failed"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
-		// Get primary email from GitHub
-		email, err := gh.GetPrimaryEmail(c.Context(), linkedAccount.AccessToken)
-		if err != nil {
-			slog.Warn("failed to fetch GitHub email", "error", err, "user_id", userID)
-			// Continue without email if email fetch fails
+		// Sync inline (the user asked for fresh data) through the same path
+		// as the background job, then answer from the stored copy.
+		syncer := &profilesync.Syncer{Pool: h.db.Pool, GitHub: github.NewClient(), TokenEncKeyB64: h.cfg.TokenEncKeyB64}
+		if err := syncer.SyncUser(c.Context(), userID); err != nil {
+			if errors.Is(err, profilesync.ErrNotLinked) {
+				return httpx.Fail(c, fiber.StatusNotFound, "github_not_linked")
+			}
+			httpx.Logger(c).Error("failed to sync GitHub profile", "error", err, "user_id", userID)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_fetch_failed")
 		}
+		h.githubProfiles.Invalidate(userID.String())
 
-		// Update github_accounts table with fresh data
-		_, err = h.db.Pool.Exec(c.Context(), `
-UPDATE github_accounts
-SET login = $1, avatar_url = $2, updated_at = now()
-WHERE user_id = $3
-`, ghUser.Login, ghUser.AvatarURL, userID)
-		if err != nil {
-			slog.Error("failed to update github_accounts", "error", err, "user_id", userID)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "update_failed"})
+		var login string
+		var name, email, avatar, location, bio, blog *string
+		if err := h.db.Pool.QueryRow(c.Context(), `
+SELECT login, name, email, avatar_url, location, bio, blog
+FROM github_profiles
+WHERE user_id = $1
+`, userID).Scan(&login, &name, &email, &avatar, &location, &bio, &blog); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "update_failed")
 		}
 
 		// Return fresh GitHub data
 		githubMap := fiber.Map{
-			"login":      ghUser.Login,
-			"avatar_url": ghUser.AvatarURL,
+			"login":      login,
+			"avatar_url": avatar,
 		}
-		if ghUser.Name != "" {
-			githubMap["name"] = ghUser.Name
-		}
-		if email != "" {
-			githubMap["email"] = email
-		} else if ghUser.Email != "" {
-			githubMap["email"] = ghUser.Email
-		}
-		if ghUser.Location != "" {
-			githubMap["location"] = ghUser.Location
-		}
-		if ghUser.Bio != "" {
-			githubMap["bio"] = ghUser.Bio
-		}
-		if ghUser.Blog != "" {
-			githubMap["website"] = ghUser.Blog
+		for key, v := range map[string]*string{"name": name, "email": email, "location": location, "bio": bio, "website": blog} {
+			if v != nil && *v != "" {
+				githubMap[key] = *v
+			}
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// recoveryEnabled reports whether this deployment offers account recovery;
+// it needs email to deliver codes and notices.
+func (h *AuthHandler) recoveryEnabled() bool {
+	return h.cfg.AccountRecoveryEnabled && h.mail != nil
+}
+
+func (h *AuthHandler) recoveryDelay() time.Duration {
+	return time.Duration(max(h.cfg.AccountRecoveryDelayHours, 0)) * time.Hour
+}
+
+// notifyAccount mails every address known for userID: the verified email
+// and the GitHub one, so a recovery started by someone holding only the
+// mailbox is still seen by the owner. Failures only log.
+func (h *AuthHandler) notifyAccount(ctx context.Context, userID uuid.UUID, subject, body string) {
+	rows, err := h.db.Pool.Query(ctx, `
+SELECT lower(u.email) FROM users u WHERE u.id = $1 AND u.email_verified_at IS NOT NULL
+UNION
+SELECT lower(p.email) FROM github_profiles p WHERE p.user_id = $1 AND COALESCE(p.email, '') <> ''
+`, userID)
+	if err != nil {
+		slog.Error("failed to look up account emails", "user_id", userID.String(), "error", err)
+		return
+	}
+	var to []string
+	for rows.Next() {
+		var addr string
+		if err := rows.Scan(&addr); err == nil {
+			to = append(to, addr)
+		}
+	}
+	rows.Close()
+	for _, addr := range to {
+		if err := h.mail.Send(ctx, addr, subject, body); err != nil {
+			slog.Error("failed to send account notice", "user_id", userID.String(), "error", err)
+		}
+	}
+}
+
+type startEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// StartEmailVerification mails a code to the address the caller wants as
+// their account email.
+func (h *AuthHandler) StartEmailVerification() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if h.mail == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "email_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req startEmailRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		email, code, err := auth.StartEmailVerification(c.Context(), h.db.Pool, userID, req.Email)
+		switch {
+		case errors.Is(err, auth.ErrInvalidEmail):
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_email")
+		case errors.Is(err, auth.ErrEmailTaken):
+			return httpx.Fail(c, fiber.StatusConflict, "email_taken")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "email_verification_failed")
+		}
+		body := fmt.Sprintf("Your Grainlify verification code is %s.\n\nIt expires in %d minutes. If you didn't ask for it, ignore this email.\n", code, int(auth.EmailCodeTTL.Minutes()))
+		if err := h.mail.Send(c.Context(), email, "Verify your email for Grainlify", body); err != nil {
+			httpx.Logger(c).Error("failed to send verification email", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "email_send_failed")
+		}
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"email":      email,
+			"expires_in": int(auth.EmailCodeTTL.Seconds()),
+		})
+	}
+}
+
+type emailCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// VerifyEmail checks the mailed code and makes its address the caller's
+// verified email.
+func (h *AuthHandler) VerifyEmail() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req emailCodeRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		email, err := auth.VerifyEmail(c.Context(), h.db.Pool, userID, req.Code)
+		switch {
+		case errors.Is(err, auth.ErrInvalidEmailCode):
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_or_expired_code")
+		case errors.Is(err, auth.ErrEmailTaken):
+			return httpx.Fail(c, fiber.StatusConflict, "email_taken")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "email_verification_failed")
+		}
+		recordAudit(c, h.db.Pool, &userID, audit.ActionEmailVerified, map[string]any{"email": email})
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"email": email, "email_verified": true})
+	}
+}
+
+// StartRecovery mails a recovery code to a verified email. The reply is the
+// same whether or not an account has that email.
+func (h *AuthHandler) StartRecovery() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if !h.recoveryEnabled() {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "recovery_disabled")
+		}
+		var req startEmailRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		email, err := auth.NormalizeEmail(req.Email)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_email")
+		}
+		accepted := fiber.Map{"ok": true, "expires_in": int(auth.EmailCodeTTL.Seconds())}
+
+		userID, code, err := auth.StartRecovery(c.Context(), h.db.Pool, email)
+		if errors.Is(err, auth.ErrNoVerifiedEmail) {
+			httpx.Logger(c).Info("account recovery requested for unknown email", "remote_ip", c.IP())
+			return c.Status(fiber.StatusAccepted).JSON(accepted)
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "recovery_start_failed")
+		}
+		recordAudit(c, h.db.Pool, &userID, audit.ActionRecoveryStarted, map[string]any{"email": email})
+		body := fmt.Sprintf("Someone asked to recover the Grainlify account that uses this email. Your recovery code is %s; it expires in %d minutes.\n\n"+
+			"Recovery adds a new wallet to your account after a %d hour waiting period. If you didn't ask for this, ignore this email; the code is useless without it.\n",
+			code, int(auth.EmailCodeTTL.Minutes()), int(h.recoveryDelay().Hours()))
+		if err := h.mail.Send(c.Context(), email, "Your Grainlify recovery code", body); err != nil {
+			httpx.Logger(c).Error("failed to send recovery email", "user_id", userID.String(), "error", err)
+		}
+		return c.Status(fiber.StatusAccepted).JSON(accepted)
+	}
+}
+
+type requestRecoveryRequest struct {
+	verifyRequest
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+// RequestRecovery queues a new wallet for the account once the mailed code
+// and the wallet's signature (over a /auth/nonce for it) check out. The
+// returned token completes the recovery after the waiting period; the
+// owner is notified and can cancel it until then.
+func (h *AuthHandler) RequestRecovery() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if !h.recoveryEnabled() {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "recovery_disabled")
+		}
+		var req requestRecoveryRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if status, code := h.checkNoncePoW(c, req.verifyRequest); status != 0 {
+			return httpx.Fail(c, status, code)
+		}
+		wType, addr, status, code := h.checkWalletProof(req.verifyRequest)
+		if status != 0 {
+			return httpx.Fail(c, status, code)
+		}
+
+		r, token, err := auth.RequestRecovery(c.Context(), h.db.Pool, auth.NewRecovery{
+			Email:       req.Email,
+			Code:        req.Code,
+			WalletType:  wType,
+			Address:     addr,
+			Nonce:       req.Nonce,
+			PublicKey:   req.PublicKey,
+			PoWSolution: req.PoWSolution,
+			IP:          c.IP(),
+		}, h.recoveryDelay())
+		if err != nil {
+			status, code := fiber.StatusInternalServerError, "recovery_request_failed"
+			switch {
+			case errors.Is(err, auth.ErrInvalidEmail):
+				status, code = fiber.StatusBadRequest, "invalid_email"
+			case errors.Is(err, auth.ErrInvalidEmailCode):
+				status, code = fiber.StatusUnauthorized, "invalid_or_expired_code"
+			case err.Error() == "invalid_or_expired_nonce" || err.Error() == "invalid_pow":
+				status, code = fiber.StatusUnauthorized, err.Error()
+			case errors.Is(err, auth.ErrWalletAlreadyLinked):
+				status, code = fiber.StatusConflict, "wallet_already_linked"
+			case errors.Is(err, auth.ErrWalletLinkedElsewhere):
+				status, code = fiber.StatusConflict, "wallet_linked_to_another_account"
+			case errors.Is(err, auth.ErrRecoveryPending):
+				status, code = fiber.StatusConflict, "recovery_already_pending"
+			}
+			if status != fiber.StatusInternalServerError {
+				recordAudit(c, h.db.Pool, walletOwner(c, h.db.Pool, wType, addr), audit.ActionRecoveryFailed, map[string]any{
+					"reason":      code,
+					"email":       strings.ToLower(strings.TrimSpace(req.Email)),
+					"wallet_type": wType,
+					"address":     addr,
+				})
+			}
+			return httpx.Fail(c, status, code)
+		}
+
+		recordAudit(c, h.db.Pool, &r.UserID, audit.ActionRecoveryRequested, map[string]any{
+			"recovery_id":  r.ID,
+			"wallet_type":  r.WalletType,
+			"address":      r.Address,
+			"available_at": r.AvailableAt,
+		})
+		httpx.Logger(c).Warn("account recovery requested",
+			"user_id", r.UserID.String(),
+			"recovery_id", r.ID.String(),
+			"wallet_type", r.WalletType,
+			"remote_ip", c.IP(),
+		)
+		h.notifyAccount(c.Context(), r.UserID, "A new wallet is being added to your Grainlify account", fmt.Sprintf(
+			"An account recovery was requested from %s to add the %s wallet %s to your Grainlify account.\n\n"+
+				"It completes at %s and signs out every session. If this wasn't you, sign in with one of your wallets or GitHub before then and cancel it in your account security settings.\n",
+			c.IP(), r.WalletType, r.Address, r.AvailableAt.UTC().Format(time.RFC1123)))
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"recovery": r, "recovery_token": token})
+	}
+}
+
+type completeRecoveryRequest struct {
+	RecoveryToken string `json:"recovery_token"`
+}
+
+// CompleteRecovery binds the recovered wallet once the waiting period is
+// over and signs in with it; every earlier session is revoked.
+func (h *AuthHandler) CompleteRecovery() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if h.cfg.JWTSecret == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "jwt_not_configured")
+		}
+		if !h.recoveryEnabled() {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "recovery_disabled")
+		}
+		var req completeRecoveryRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if strings.TrimSpace(req.RecoveryToken) == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_recovery_token")
+		}
+
+		r, user, err := auth.CompleteRecovery(c.Context(), h.db.Pool, strings.TrimSpace(req.RecoveryToken), time.Now())
+		switch {
+		case errors.Is(err, auth.ErrRecoveryNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "recovery_not_found")
+		case errors.Is(err, auth.ErrRecoveryNotReady):
+			return httpx.Write(c, httpx.New(fiber.StatusConflict, "recovery_not_ready").With("available_at", r.AvailableAt))
+		case errors.Is(err, auth.ErrWalletLinkedElsewhere):
+			return httpx.Fail(c, fiber.StatusConflict, "wallet_linked_to_another_account")
+		case err != nil:
+			httpx.Logger(c).Error("failed to complete account recovery", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "recovery_complete_failed")
+		}
+		recordAudit(c, h.db.Pool, &user.ID, audit.ActionRecoveryCompleted, map[string]any{
+			"recovery_id": r.ID,
+			"wallet_type": r.WalletType,
+			"address":     r.Address,
+		})
+		httpx.Logger(c).Warn("account recovered",
+			"user_id", user.ID.String(),
+			"recovery_id", r.ID.String(),
+			"wallet_type", r.WalletType,
+			"remote_ip", c.IP(),
+		)
+		h.notifyAccount(c.Context(), user.ID, "A new wallet was added to your Grainlify account", fmt.Sprintf(
+			"Account recovery completed: the %s wallet %s is now your primary wallet and all other sessions were signed out.\n",
+			r.WalletType, r.Address))
+
+		if blocked, err := rejectRestrictedAccount(c, h.db.Pool, user.ID); blocked {
+			return err
+		}
+		recordIPCountry(c, h.cfg, h.db.Pool, user.ID)
+
+		sessionID, err := auth.CreateSession(c.Context(), h.db.Pool, user.ID, r.WalletType, r.Address, c.IP(), c.Get(fiber.HeaderUserAgent), time.Now().UTC().Add(h.refreshTTL()))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
+		}
+		token, err := auth.IssueJWT(h.cfg.JWTSecret, user.ID, sessionID, user.Role, r.WalletType, r.Address, 15*time.Minute)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
+		}
+		refresh, err := auth.IssueRefreshToken(c.Context(), h.db.Pool, sessionID, user.ID, r.WalletType, r.Address, h.refreshTTL())
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"token":              token,
+			"refresh_token":      refresh.Token,
+			"refresh_expires_at": refresh.ExpiresAt,
+			"user":               user,
+			"wallet": fiber.Map{
+				"wallet_type": r.WalletType,
+				"address":     r.Address,
+			},
+		})
+	}
+}
+
+// MyRecovery shows a pending recovery of the caller's account, so any
+// signed-in session sees it.
+func (h *AuthHandler) MyRecovery() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		r, err := auth.PendingRecovery(c.Context(), h.db.Pool, userID)
+		if errors.Is(err, auth.ErrRecoveryNotFound) {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"recovery": nil})
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "recovery_lookup_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"recovery": r})
+	}
+}
+
+// CancelMyRecovery stops a pending recovery of the caller's account.
+func (h *AuthHandler) CancelMyRecovery() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		r, err := auth.CancelRecovery(c.Context(), h.db.Pool, userID, userID)
+		if errors.Is(err, auth.ErrRecoveryNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "recovery_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "recovery_cancel_failed")
+		}
+		sessionID, _ := c.Locals(auth.LocalSessionID).(string)
+		recordAudit(c, h.db.Pool, &userID, audit.ActionRecoveryCancelled, map[string]any{
+			"recovery_id": r.ID,
+			"wallet_type": r.WalletType,
+			"address":     r.Address,
+			"session_id":  sessionID,
+		})
+		if h.mail != nil {
+			h.notifyAccount(c.Context(), userID, "Grainlify account recovery cancelled", fmt.Sprintf(
+				"The pending recovery that would have added the %s wallet %s to your account was cancelled from a signed-in session.\n",
+				r.WalletType, r.Address))
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"recovery": r})
+	}
+}
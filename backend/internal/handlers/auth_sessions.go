@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// ListSessions shows where the caller is signed in.
+func (h *AuthHandler) ListSessions() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		sessions, err := auth.ListSessions(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "sessions_list_failed")
+		}
+		current, _ := c.Locals(auth.LocalSessionID).(string)
+		for i := range sessions {
+			sessions[i].Current = sessions[i].ID.String() == current
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"sessions": sessions})
+	}
+}
+
+// RevokeSession signs out one of the caller's sessions. Its access tokens
+// stop working immediately and its refresh token is revoked.
+func (h *AuthHandler) RevokeSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		sessionID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_session_id")
+		}
+		err = auth.RevokeSession(c.Context(), h.db.Pool, userID, sessionID)
+		switch {
+		case errors.Is(err, auth.ErrSessionNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "session_not_found")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "session_revoke_failed")
+		}
+		recordAudit(c, h.db.Pool, &userID, audit.ActionSessionRevoked, map[string]any{"session_id": sessionID})
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
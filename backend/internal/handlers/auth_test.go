@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+func TestRefreshFailure(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{auth.ErrInvalidRefreshToken, fiber.StatusUnauthorized, "invalid_refresh_token"},
+		{fmt.Errorf("rotate: %w", auth.ErrRefreshTokenReused), fiber.StatusUnauthorized, "refresh_token_reused"},
+		{errors.New("connection reset"), fiber.StatusInternalServerError, "token_refresh_failed"},
+	} {
+		status, code := refreshFailure(tc.err)
+		if status != tc.status || code != tc.code {
+			t.Errorf("refreshFailure(%v) = %d %s, want %d %s", tc.err, status, code, tc.status, tc.code)
+		}
+	}
+}
+
+func TestVerifyChecksPoWBeforeSignature(t *testing.T) {
+	// The pool never connects: audit writes fail fast and are only logged.
+	pool, err := pgxpool.New(context.Background(), "postgres://test@127.0.0.1:1/test?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, tc := range []struct {
+		difficulty int
+		code       string
+	}{
+		// An unsolved puzzle is refused before the bogus signature is looked at.
+		{20, "invalid_pow"},
+		// Without a puzzle the signature is verified and fails.
+		{0, "invalid_signature"},
+	} {
+		lookups := 0
+		h := &AuthHandler{
+			cfg: config.Config{JWTSecret: "secret"},
+			db:  &db.DB{Pool: pool},
+			noncePoW: func(context.Context, auth.WalletType, string, string) (int, error) {
+				lookups++
+				return tc.difficulty, nil
+			},
+		}
+		app := fiber.New()
+		app.Post("/auth/verify", h.Verify())
+
+		body := `{"wallet_type":"evm","address":"0x52908400098527886E0F7030069857D2E4169EE7","nonce":"abc123","signature":"0xdeadbeef"}`
+		req := httptest.NewRequest(fiber.MethodPost, "/auth/verify", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got httpx.Body
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusUnauthorized || got.Code != tc.code {
+			t.Errorf("difficulty %d: %d %s, want 401 %s", tc.difficulty, resp.StatusCode, got.Code, tc.code)
+		}
+		if lookups != 1 {
+			t.Errorf("difficulty %d: %d nonce lookups, want 1", tc.difficulty, lookups)
+		}
+	}
+}
+
+func TestNonceRejectsChainBeforeIssuing(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://test@127.0.0.1:1/test?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, tc := range []struct {
+		chainID int64
+		status  int
+		issued  int
+	}{
+		{1, fiber.StatusOK, 1},
+		{999999, fiber.StatusBadRequest, 0},
+	} {
+		issued := 0
+		h := &AuthHandler{
+			cfg: config.Config{SIWEDomain: "grainlify.example", SIWEChainIDs: []string{"1"}},
+			db:  &db.DB{Pool: pool},
+			createNonce: func(_ context.Context, _ auth.WalletType, _ string, ttl time.Duration, _ int) (auth.Nonce, error) {
+				issued++
+				return auth.Nonce{Nonce: "abc123", ExpiresAt: time.Now().Add(ttl)}, nil
+			},
+		}
+		app := fiber.New()
+		app.Post("/auth/nonce", h.Nonce())
+
+		body := fmt.Sprintf(`{"wallet_type":"evm","address":"0x52908400098527886E0F7030069857D2E4169EE7","chain_id":%d}`, tc.chainID)
+		req := httptest.NewRequest(fiber.MethodPost, "/auth/nonce", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tc.status || issued != tc.issued {
+			t.Errorf("chain %d: status %d with %d nonces issued, want %d with %d", tc.chainID, resp.StatusCode, issued, tc.status, tc.issued)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+func (h *AuthHandler) ListWallets() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		wallets, err := auth.ListWallets(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "wallets_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"wallets": wallets})
+	}
+}
+
+type linkWalletRequest struct {
+	verifyRequest
+	// Primary makes the new wallet the payout wallet.
+	Primary bool `json:"primary"`
+}
+
+// LinkWallet adds another wallet to the caller's account. Ownership is proven
+// the same way as sign-in: a /auth/nonce for the new wallet, signed.
+func (h *AuthHandler) LinkWallet() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req linkWalletRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if status, code := h.checkNoncePoW(c, req.verifyRequest); status != 0 {
+			return httpx.Fail(c, status, code)
+		}
+		wType, addr, status, code := h.checkWalletProof(req.verifyRequest)
+		if status != 0 {
+			return httpx.Fail(c, status, code)
+		}
+
+		w, err := auth.LinkWallet(c.Context(), h.db.Pool, userID, wType, addr, req.Nonce, req.PublicKey, req.PoWSolution, req.Primary)
+		switch {
+		case errors.Is(err, auth.ErrWalletAlreadyLinked):
+			return httpx.Fail(c, fiber.StatusConflict, "wallet_already_linked")
+		case errors.Is(err, auth.ErrWalletLinkedElsewhere):
+			return httpx.Fail(c, fiber.StatusConflict, "wallet_linked_to_another_account")
+		case err != nil && (err.Error() == "invalid_or_expired_nonce" || err.Error() == "invalid_pow"):
+			return httpx.Fail(c, fiber.StatusUnauthorized, err.Error())
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "wallet_link_failed")
+		}
+		recordAudit(c, h.db.Pool, &userID, audit.ActionWalletLinked, map[string]any{
+			"wallet_id":   w.ID,
+			"wallet_type": w.WalletType,
+			"address":     w.Address,
+		})
+		return c.Status(fiber.StatusCreated).JSON(w)
+	}
+}
+
+// SetPrimaryWallet designates the payout wallet.
+func (h *AuthHandler) SetPrimaryWallet() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		walletID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_wallet_id")
+		}
+		w, err := auth.SetPrimaryWallet(c.Context(), h.db.Pool, userID, walletID)
+		if errors.Is(err, auth.ErrWalletNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "wallet_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "wallet_update_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(w)
+	}
+}
+
+func (h *AuthHandler) UnlinkWallet() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		walletID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_wallet_id")
+		}
+		err = auth.UnlinkWallet(c.Context(), h.db.Pool, userID, walletID)
+		switch {
+		case errors.Is(err, auth.ErrWalletNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "wallet_not_found")
+		case errors.Is(err, auth.ErrLastWallet):
+			return httpx.Fail(c, fiber.StatusConflict, "cannot_unlink_last_wallet")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "wallet_unlink_failed")
+		}
+		recordAudit(c, h.db.Pool, &userID, audit.ActionWalletUnlinked, map[string]any{"wallet_id": walletID})
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
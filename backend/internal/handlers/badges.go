@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/badges"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+type BadgesHandler struct {
+	db *db.DB
+	// mint queues new awards for NFT minting when a contract is configured.
+	mint bool
+}
+
+func NewBadgesHandler(cfg config.Config, d *db.DB) *BadgesHandler {
+	return &BadgesHandler{db: d, mint: cfg.AchievementNFTChain != "" && cfg.AchievementNFTContract != ""}
+}
+
+func (h *BadgesHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		out, err := badges.List(c.Context(), h.db.Pool)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "badges_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"badges": out})
+	}
+}
+
+// TokenMetadata serves ERC-721 metadata; the contract's token URIs point here.
+func (h *BadgesHandler) TokenMetadata() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		tokenID, err := c.ParamsInt("token_id")
+		if err != nil || tokenID < 1 {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_token_id")
+		}
+		m, err := badges.MetadataForToken(c.Context(), h.db.Pool, int64(tokenID))
+		if errors.Is(err, badges.ErrBadgeNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "token_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "metadata_failed")
+		}
+		c.Set("Cache-Control", "public, max-age=3600")
+		return c.Status(fiber.StatusOK).JSON(m)
+	}
+}
+
+type createBadgeRequest struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+	ImageURL    string `json:"image_url"`
+}
+
+func (h *BadgesHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		var req createBadgeRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if strings.TrimSpace(req.Slug) == "" || strings.TrimSpace(req.Name) == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "slug_and_name_required")
+		}
+		if req.Kind != badges.KindMilestone && req.Kind != badges.KindCampaign {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_kind")
+		}
+		b := badges.Badge{
+			Slug:        req.Slug,
+			Name:        strings.TrimSpace(req.Name),
+			Description: strings.TrimSpace(req.Description),
+			Kind:        req.Kind,
+		}
+		if img := strings.TrimSpace(req.ImageURL); img != "" {
+			b.ImageURL = &img
+		}
+		b, err := badges.Create(c.Context(), h.db.Pool, b)
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				return httpx.Fail(c, fiber.StatusConflict, "badge_exists")
+			}
+			return httpx.Fail(c, fiber.StatusInternalServerError, "badge_create_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(b)
+	}
+}
+
+type awardBadgeRequest struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+func (h *BadgesHandler) Award() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req awardBadgeRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		userID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		a, err := badges.Grant(c.Context(), h.db.Pool, userID, c.Params("slug"), strings.TrimSpace(req.Reason), &actorID, h.mint)
+		switch {
+		case errors.Is(err, badges.ErrBadgeNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "badge_not_found")
+		case errors.Is(err, badges.ErrAlreadyAwarded):
+			return httpx.Fail(c, fiber.StatusConflict, "already_awarded")
+		case err != nil:
+			httpx.Logger(c).Error("failed to award badge", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "badge_award_failed")
+		}
+		httpx.Logger(c).Info("badge awarded", "actor_user_id", actorID.String(), "user_id", userID.String(), "badge", a.Badge.Slug, "nft", a.NFT.Status)
+		return c.Status(fiber.StatusCreated).JSON(a)
+	}
+}
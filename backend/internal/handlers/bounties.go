@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/escrow"
+	"github.com/jagadeesh/grainlify/backend/internal/geo"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/issues"
+	"github.com/jagadeesh/grainlify/backend/internal/metadata"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+	"github.com/jagadeesh/grainlify/backend/internal/skills"
+)
+
+type BountiesHandler struct {
+	cfg       config.Config
+	db        *db.DB
+	providers map[string]issues.Provider
+}
+
+func NewBountiesHandler(cfg config.Config, d *db.DB) *BountiesHandler {
+	return &BountiesHandler{cfg: cfg, db: d, providers: issues.Providers(cfg)}
+}
+
+func (h *BountiesHandler) detector() *skills.Detector {
+	return &skills.Detector{
+		Pool:           h.db.Pool,
+		TokenEncKeyB64: h.cfg.TokenEncKeyB64,
+		Installations:  github.AppInstallations(h.cfg.GitHubAppID, h.cfg.GitHubAppPrivateKey),
+	}
+}
+
+func skillTagsError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, skills.ErrTooManyTags) {
+		return httpx.Fail(c, fiber.StatusBadRequest, "too_many_skill_tags")
+	}
+	return httpx.Fail(c, fiber.StatusBadRequest, "invalid_skill_tag")
+}
+
+// ownerCheck returns a non-nil response error unless the caller may do what
+// perm covers on the project: its owner, an admin, an admin of its GitHub
+// organization or a member holding an organization role granting perm.
+func (h *BountiesHandler) ownerCheck(ctx context.Context, c *fiber.Ctx, projectID uuid.UUID, perm orgs.Permission) (uuid.UUID, error) {
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil, httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+	}
+	var owner uuid.UUID
+	err = h.db.Pool.QueryRow(ctx, `SELECT owner_user_id FROM projects WHERE id = $1 AND deleted_at IS NULL`, projectID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
+	}
+	if err != nil {
+		return uuid.Nil, httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+	}
+	role, _ := c.Locals(auth.LocalRole).(string)
+	ok, err := managesProject(ctx, h.db.Pool, projectID, owner, userID, role, perm)
+	if err != nil {
+		return uuid.Nil, httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+	}
+	if !ok {
+		return uuid.Nil, httpx.Fail(c, fiber.StatusForbidden, "forbidden")
+	}
+	return userID, nil
+}
+
+// BountyListParams are the query parameters List reads.
+var BountyListParams = append(bounties.ListSpec.Params(), "metadata.")
+
+// List returns a project's bounties (public), sorted, filtered and paged
+// by bounties.ListSpec and filtered by metadata.<key>=value parameters.
+func (h *BountiesHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		filters, err := metadata.ParseFilters(c.Queries())
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_metadata_filter")
+		}
+		page, err := httpx.ParseList(c, bounties.ListSpec)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		out, next, err := bounties.ListForProject(c.Context(), h.db.Pool, projectID, "", filters, page)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "bounties_list_failed")
+		}
+		resp := fiber.Map{"bounties": out}
+		if next != "" {
+			resp["next_cursor"] = next
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+}
+
+type createBountyRequest struct {
+	// IssueProvider is github (default), jira or linear.
+	IssueProvider string `json:"issue_provider"`
+	// IssueRef is the issue number for GitHub, or the key (ABC-123, ENG-42).
+	IssueRef string `json:"issue_ref"`
+	Chain    string `json:"chain"`
+	Asset    string `json:"asset"`
+	Amount   string `json:"amount"`
+	// SkillTags, when given, replace the tags detected from the repo.
+	SkillTags []string `json:"skill_tags"`
+	// Funding is hot_wallet (default) or escrow, where the maintainer locks
+	// the reward in the chain's escrow contract themselves.
+	Funding string `json:"funding"`
+	// Metadata is checked against the fields the project defines for
+	// bounties.
+	Metadata map[string]any `json:"metadata"`
+	// Deadline, when set, closes the bounty to claims and submissions.
+	Deadline *time.Time `json:"deadline"`
+	// Visibility is public (default), private or unlisted.
+	Visibility string `json:"visibility"`
+	// PathPrefix scopes the bounty to a directory of a monorepo, e.g.
+	// packages/api: merged pull requests only settle it when they change
+	// files under it, and its skill tags are detected from that subtree.
+	PathPrefix string `json:"path_prefix"`
+}
+
+func (h *BountiesHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		userID, respErr := h.ownerCheck(c.Context(), c, projectID, orgs.PermEditBounties)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		if blocked, err := rejectGeoRestricted(c, h.cfg, h.db.Pool, geo.ActionBountyFunding, userID, true); blocked {
+			return err
+		}
+		var req createBountyRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if req.IssueRef == "" || req.Chain == "" || req.Asset == "" || req.Amount == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_fields")
+		}
+		if req.Deadline != nil && !req.Deadline.After(time.Now()) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_deadline")
+		}
+		if req.Visibility = strings.TrimSpace(req.Visibility); req.Visibility != "" && !bounties.ValidVisibility(req.Visibility) {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_visibility").With("visibilities", bounties.Visibilities))
+		}
+		if req.PathPrefix, err = bounties.NormalizePath(req.PathPrefix); err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_path_prefix")
+		}
+		var escrowContract string
+		switch strings.TrimSpace(req.Funding) {
+		case "", bounties.FundingHotWallet:
+		case bounties.FundingEscrow:
+			var ok bool
+			if escrowContract, ok = escrow.Contracts(h.cfg)[strings.ToLower(strings.TrimSpace(req.Chain))]; !ok {
+				return httpx.Fail(c, fiber.StatusBadRequest, "escrow_unsupported_chain")
+			}
+		default:
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_funding")
+		}
+		var tags []string
+		if req.SkillTags != nil {
+			if tags, err = skills.Normalize(req.SkillTags); err != nil {
+				return skillTagsError(c, err)
+			}
+		}
+		md, err := metadata.ValidateFor(c.Context(), h.db.Pool, projectID, metadata.EntityBounty, req.Metadata)
+		if err != nil {
+			return metadataError(c, err)
+		}
+
+		iss, accountID, err := issues.Resolve(c.Context(), h.db.Pool, h.providers, h.cfg.TokenEncKeyB64, projectID, req.IssueProvider, req.IssueRef)
+		switch {
+		case errors.Is(err, issues.ErrIssueNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "issue_not_found")
+		case errors.Is(err, issues.ErrUnknownProvider):
+			return httpx.Fail(c, fiber.StatusBadRequest, "unknown_issue_provider")
+		case errors.Is(err, issues.ErrProviderDisabled), errors.Is(err, issues.ErrNotLinked):
+			return issueProviderError(c, err)
+		case err != nil:
+			httpx.Logger(c).Warn("bounty issue lookup failed", "project_id", projectID.String(), "provider", req.IssueProvider, "ref", req.IssueRef, "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "issue_lookup_failed")
+		}
+		if iss.Closed {
+			return httpx.Fail(c, fiber.StatusConflict, "issue_closed")
+		}
+
+		b, err := bounties.Create(c.Context(), h.db.Pool, projectID, userID, iss, accountID, req.Chain, req.Asset, req.Amount, req.Deadline, req.Visibility)
+		if errors.Is(err, bounties.ErrAlreadyOpen) {
+			return httpx.Fail(c, fiber.StatusConflict, "bounty_already_open")
+		}
+		if errors.Is(err, bounties.ErrDeadlinePassed) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_deadline")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "bounty_create_failed")
+		}
+		if escrowContract != "" {
+			deadline := time.Now().UTC().AddDate(0, 0, h.cfg.EscrowDeadlineDays)
+			escrowed, err := bounties.AttachEscrow(c.Context(), h.db.Pool, projectID, b.ID, userID, escrowContract, deadline)
+			if err != nil {
+				// Don't leave a hot wallet bounty the maintainer didn't ask for.
+				_, _ = bounties.Cancel(c.Context(), h.db.Pool, projectID, b.ID, userID)
+				return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_create_failed").Wrap(err))
+			}
+			b = escrowed
+		}
+		if len(md) > 0 {
+			withMetadata, err := bounties.SetMetadata(c.Context(), h.db.Pool, projectID, b.ID, userID, md)
+			if err != nil {
+				_, _ = bounties.Cancel(c.Context(), h.db.Pool, projectID, b.ID, userID)
+				return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_create_failed").Wrap(err))
+			}
+			b = withMetadata
+		}
+		if req.PathPrefix != "" {
+			scoped, err := bounties.SetPathPrefix(c.Context(), h.db.Pool, projectID, b.ID, userID, req.PathPrefix)
+			if err != nil {
+				_, _ = bounties.Cancel(c.Context(), h.db.Pool, projectID, b.ID, userID)
+				return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_create_failed").Wrap(err))
+			}
+			b = scoped
+		}
+		if tags != nil {
+			if tagged, err := bounties.SetSkillTags(c.Context(), h.db.Pool, projectID, b.ID, userID, tags, true); err == nil {
+				b = tagged
+			}
+		} else {
+			b = h.detector().TagBounty(c.Context(), b)
+		}
+		return c.Status(fiber.StatusCreated).JSON(h.withLink(b))
+	}
+}
+
+func (h *BountiesHandler) Cancel() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		bountyID, err := uuid.Parse(c.Params("bounty_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_bounty_id")
+		}
+		userID, respErr := h.ownerCheck(c.Context(), c, projectID, orgs.PermEditBounties)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		b, err := bounties.Cancel(c.Context(), h.db.Pool, projectID, bountyID, userID)
+		if errors.Is(err, bounties.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "bounty_not_found")
+		}
+		if errors.Is(err, bounties.ErrInvalidStatus) {
+			return httpx.Fail(c, fiber.StatusConflict, "invalid_bounty_status")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "bounty_cancel_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(b)
+	}
+}
+
+type setSkillTagsRequest struct {
+	SkillTags []string `json:"skill_tags"`
+}
+
+// SetSkillTags lets a maintainer replace a bounty's skill tags; detection
+// never overwrites them afterwards.
+func (h *BountiesHandler) SetSkillTags() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		bountyID, err := uuid.Parse(c.Params("bounty_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_bounty_id")
+		}
+		userID, respErr := h.ownerCheck(c.Context(), c, projectID, orgs.PermEditBounties)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req setSkillTagsRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		tags, err := skills.Normalize(req.SkillTags)
+		if err != nil {
+			return skillTagsError(c, err)
+		}
+		b, err := bounties.SetSkillTags(c.Context(), h.db.Pool, projectID, bountyID, userID, tags, true)
+		if errors.Is(err, bounties.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "bounty_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "skill_tags_update_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(b)
+	}
+}
+
+// ResetSkillTags drops a maintainer's override and re-applies the tags
+// detected from the repo, or the directory the bounty is scoped to.
+func (h *BountiesHandler) ResetSkillTags() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		bountyID, err := uuid.Parse(c.Params("bounty_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_bounty_id")
+		}
+		userID, respErr := h.ownerCheck(c.Context(), c, projectID, orgs.PermEditBounties)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		b, err := bounties.Get(c.Context(), h.db.Pool, projectID, bountyID)
+		if errors.Is(err, bounties.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "bounty_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "skill_tags_update_failed")
+		}
+		tags, err := h.detector().ForPath(c.Context(), projectID, b.PathPrefix)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "skill_detection_failed")
+		}
+		b, err = bounties.SetSkillTags(c.Context(), h.db.Pool, projectID, bountyID, userID, tags, false)
+		if errors.Is(err, bounties.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "bounty_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "skill_tags_update_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(b)
+	}
+}
+
+// SetMetadata replaces a bounty's metadata, validated against the fields
+// the project defines for bounties.
+func (h *BountiesHandler) SetMetadata() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		bountyID, err := uuid.Parse(c.Params("bounty_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_bounty_id")
+		}
+		userID, respErr := h.ownerCheck(c.Context(), c, projectID, orgs.PermEditBounties)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req setMetadataRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		md, err := metadata.ValidateFor(c.Context(), h.db.Pool, projectID, metadata.EntityBounty, req.Metadata)
+		if err != nil {
+			return metadataError(c, err)
+		}
+		b, err := bounties.SetMetadata(c.Context(), h.db.Pool, projectID, bountyID, userID, md)
+		if errors.Is(err, bounties.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "bounty_not_found")
+		}
+		if err != nil {
+			return metadataError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(b)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/advisories"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+func advisoryError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, advisories.ErrNotFound):
+		return httpx.Fail(c, fiber.StatusNotFound, "advisory_not_found")
+	case errors.Is(err, advisories.ErrInvalidSeverity):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_advisory_severity").With("severities", advisories.Severities))
+	case errors.Is(err, advisories.ErrIncomplete):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "advisory_incomplete").
+			WithMessage("severity, summary, details and disclosure_at are required"))
+	case errors.Is(err, advisories.ErrInvalidCVE):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_cve_id").WithMessage("use the CVE-YYYY-NNNN form"))
+	case errors.Is(err, advisories.ErrInvalidCWE):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_cwe_id").WithMessage("use the CWE-NNN form"))
+	case errors.Is(err, advisories.ErrInvalidPackage):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_advisory_package").WithMessage("a package needs an ecosystem and a name"))
+	case errors.Is(err, advisories.ErrDisclosurePassed):
+		return httpx.Fail(c, fiber.StatusBadRequest, "disclosure_date_passed")
+	case errors.Is(err, advisories.ErrPublished):
+		return httpx.Fail(c, fiber.StatusConflict, "advisory_published")
+	}
+	return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "advisory_update_failed").Wrap(err))
+}
+
+// advisoryInsider reports whether the caller sees b's embargoed write-up: an
+// admin, a maintainer allowed to edit the project's bounties, the claimant
+// or an invited user.
+func (h *BountiesHandler) advisoryInsider(c *fiber.Ctx, b bounties.Bounty) (bool, error) {
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return false, nil
+	}
+	var owner uuid.UUID
+	if err := h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, b.ProjectID).Scan(&owner); err != nil {
+		return false, err
+	}
+	role, _ := c.Locals(auth.LocalRole).(string)
+	ok, err := managesProject(c.Context(), h.db.Pool, b.ProjectID, owner, userID, role, orgs.PermEditBounties)
+	if err != nil || ok {
+		return ok, err
+	}
+	return advisories.Insider(c.Context(), h.db.Pool, b, userID)
+}
+
+// Advisory returns a security-advisory bounty's advisory. Outsiders get the
+// redacted write-up; the full one stays with insiders even after
+// disclosure.
+func (h *BountiesHandler) Advisory() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, herr := h.splitBounty(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		a, err := advisories.Get(c.Context(), h.db.Pool, b.ID)
+		if err != nil {
+			return advisoryError(c, err)
+		}
+		insider, err := h.advisoryInsider(c, b)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "advisory_lookup_failed").Wrap(err))
+		}
+		if !insider {
+			a = a.Redacted()
+		}
+		return c.Status(fiber.StatusOK).JSON(a)
+	}
+}
+
+// PutAdvisory writes a bounty's advisory, turning it into a private
+// security-advisory bounty that only invited users may claim until its
+// disclosure date.
+func (h *BountiesHandler) PutAdvisory() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.maintainedBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req advisories.Draft
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		a, err := advisories.Put(c.Context(), h.db.Pool, b, userID, req)
+		if err != nil {
+			return advisoryError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(a)
+	}
+}
+
+// PublishAdvisory discloses a bounty's advisory ahead of its disclosure
+// date: the bounty turns public with the redacted write-up, and its GitHub
+// advisory is published on the next sync.
+func (h *BountiesHandler) PublishAdvisory() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.maintainedBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		a, err := advisories.Publish(c.Context(), h.db.Pool, b.ID, userID)
+		if err != nil {
+			return advisoryError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(a)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/escrow"
+	"github.com/jagadeesh/grainlify/backend/internal/geo"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+	"github.com/jagadeesh/grainlify/backend/internal/payouts"
+)
+
+// escrowBounty loads the route's bounty for its owner, and checks that it is
+// escrow-funded.
+func (h *BountiesHandler) escrowBounty(c *fiber.Ctx) (bounties.Bounty, uuid.UUID, error) {
+	b, err := h.splitBounty(c)
+	if err != nil {
+		return bounties.Bounty{}, uuid.Nil, httpx.Write(c, err)
+	}
+	userID, respErr := h.ownerCheck(c.Context(), c, b.ProjectID, orgs.PermApprovePayouts)
+	if userID == uuid.Nil {
+		return bounties.Bounty{}, uuid.Nil, respErr
+	}
+	if b.Escrow == nil {
+		return bounties.Bounty{}, uuid.Nil, httpx.Fail(c, fiber.StatusConflict, "bounty_not_escrowed")
+	}
+	return b, userID, nil
+}
+
+type escrowLockRequest struct {
+	TxHash string `json:"tx_hash"`
+}
+
+// RecordEscrowLock records the transaction in which the maintainer locked an
+// escrow bounty's reward in the contract.
+func (h *BountiesHandler) RecordEscrowLock() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.escrowBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req escrowLockRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if strings.TrimSpace(req.TxHash) == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "tx_hash_required")
+		}
+		b, err := bounties.RecordEscrowLock(c.Context(), h.db.Pool, b.ProjectID, b.ID, userID, strings.TrimSpace(req.TxHash))
+		if errors.Is(err, bounties.ErrInvalidEscrowState) {
+			return httpx.Fail(c, fiber.StatusConflict, "invalid_escrow_status")
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "escrow_lock_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(b)
+	}
+}
+
+type escrowApprovalResponse struct {
+	// Message is what the project owner signs with a linked wallet to
+	// approve the release.
+	Message string `json:"message"`
+}
+
+// EscrowApproval returns the message the project owner signs to approve
+// releasing the reward to ?to_address=.
+func (h *BountiesHandler) EscrowApproval() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.escrowBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		to := chain.NormalizeAddress(c.Query("to_address"))
+		if to == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "to_address_required")
+		}
+		msg := escrow.ApprovalMessage(b.ID, b.Chain, b.Escrow.Contract, b.Escrow.Ref, to, b.Amount, b.Asset)
+		return c.Status(fiber.StatusOK).JSON(escrowApprovalResponse{Message: msg})
+	}
+}
+
+type escrowReleaseRequest struct {
+	UserID    string `json:"user_id"`
+	ToAddress string `json:"to_address"`
+	// Approval is the project owner's signature over the EscrowApproval
+	// message for ToAddress.
+	Approval escrow.Approval `json:"approval"`
+	Repo     string          `json:"repo_full_name"`
+	PRNumber *int            `json:"pr_number"`
+	PRURL    string          `json:"pr_url"`
+}
+
+// ReleaseEscrow queues the release of an escrow bounty's reward to a
+// contributor, once the project owner's approval checks out. The payout
+// worker submits the contract call.
+func (h *BountiesHandler) ReleaseEscrow() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, actorID, respErr := h.escrowBounty(c)
+		if actorID == uuid.Nil {
+			return respErr
+		}
+		var req escrowReleaseRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		recipientID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		to := chain.NormalizeAddress(req.ToAddress)
+		if to == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "to_address_required")
+		}
+		if b.Escrow.Status != bounties.EscrowLocked {
+			return httpx.Write(c, httpx.New(fiber.StatusConflict, "escrow_not_locked").With("escrow_status", b.Escrow.Status))
+		}
+
+		msg := escrow.ApprovalMessage(b.ID, b.Chain, b.Escrow.Contract, b.Escrow.Ref, to, b.Amount, b.Asset)
+		switch err := escrow.VerifyApproval(c.Context(), h.db.Pool, b.ProjectID, req.Approval, msg); {
+		case errors.Is(err, escrow.ErrApproverNotOwner):
+			return httpx.Fail(c, fiber.StatusForbidden, "approver_not_project_owner")
+		case errors.Is(err, escrow.ErrBadApproval):
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_approval_signature")
+		case err != nil:
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "approval_check_failed").Wrap(err))
+		}
+		if blocked, err := rejectGeoRestricted(c, h.cfg, h.db.Pool, geo.ActionPayout, recipientID, false); blocked {
+			return err
+		}
+
+		p := payouts.Payout{UserID: recipientID, To: to}
+		if repo := strings.TrimSpace(req.Repo); repo != "" && req.PRNumber != nil {
+			if *req.PRNumber < 1 || !strings.Contains(repo, "/") {
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_pr_reference")
+			}
+			p.Repo, p.PRNumber = &repo, req.PRNumber
+			if u := strings.TrimSpace(req.PRURL); u != "" {
+				p.PRURL = &u
+			}
+		}
+		p, err = payouts.CreateEscrowRelease(c.Context(), h.db.Pool, b, p, actorID)
+		if errors.Is(err, payouts.ErrEscrowNotLocked) {
+			return httpx.Fail(c, fiber.StatusConflict, "escrow_not_locked")
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "escrow_release_failed").Wrap(err))
+		}
+		httpx.Logger(c).Info("escrow release queued",
+			"actor_user_id", actorID.String(),
+			"bounty_id", b.ID.String(),
+			"payout_id", p.ID.String(),
+			"user_id", recipientID.String(),
+		)
+		return c.Status(fiber.StatusCreated).JSON(p)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+type bountyHistoryResponse struct {
+	Bounty  bounties.Bounty         `json:"bounty"`
+	History []bounties.HistoryEntry `json:"history"`
+}
+
+// History is a bounty's full timeline: state transitions, edits, split and
+// escrow payout changes, each with its actor. It is for the project owner,
+// admins and the contributors the bounty is split with or paid to. Pages
+// continue from ?since=<seq>; the next cursor is in X-Next-Cursor.
+func (h *BountiesHandler) History() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_bounty_id")
+		}
+		since, err := bounties.ParseCursor(c.Query("since"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_cursor")
+		}
+		b, err := bounties.GetByID(c.Context(), h.db.Pool, bountyID)
+		if errors.Is(err, bounties.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "bounty_not_found")
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_lookup_failed").Wrap(err))
+		}
+
+		role, _ := c.Locals(auth.LocalRole).(string)
+		if role != "admin" {
+			var owner uuid.UUID
+			if err := h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, b.ProjectID).Scan(&owner); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+			}
+			manages, err := managesProject(c.Context(), h.db.Pool, b.ProjectID, owner, userID, role, orgs.PermViewFinances)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+			}
+			if !manages {
+				ok, err := bounties.IsParticipant(c.Context(), h.db.Pool, b.ID, userID)
+				if err != nil {
+					return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_history_failed").Wrap(err))
+				}
+				if !ok {
+					return httpx.Fail(c, fiber.StatusForbidden, "forbidden")
+				}
+			}
+		}
+
+		entries, err := bounties.History(c.Context(), h.db.Pool, b.ID, since, c.QueryInt("limit", bounties.MaxHistoryPage))
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_history_failed").Wrap(err))
+		}
+		next := since
+		if len(entries) > 0 {
+			next = entries[len(entries)-1].Seq
+		}
+		c.Set(HeaderNextCursor, strconv.FormatInt(next, 10))
+		return c.Status(fiber.StatusOK).JSON(bountyHistoryResponse{Bounty: b, History: entries})
+	}
+}
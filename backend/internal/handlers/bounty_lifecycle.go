@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/fraud"
+	"github.com/jagadeesh/grainlify/backend/internal/geo"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+	"github.com/jagadeesh/grainlify/backend/internal/payouts"
+)
+
+func lifecycleError(c *fiber.Ctx, b bounties.Bounty, err error) error {
+	switch {
+	case errors.Is(err, bounties.ErrInvalidStatus):
+		return httpx.Write(c, httpx.New(fiber.StatusConflict, "invalid_bounty_status").With("status", b.Status))
+	case errors.Is(err, bounties.ErrDeadlinePassed):
+		return httpx.Fail(c, fiber.StatusConflict, "bounty_deadline_passed")
+	case errors.Is(err, bounties.ErrIssueClosed):
+		return httpx.Fail(c, fiber.StatusConflict, "issue_closed")
+	case errors.Is(err, bounties.ErrOwnBounty):
+		return httpx.Fail(c, fiber.StatusForbidden, "cannot_claim_own_bounty")
+	case errors.Is(err, bounties.ErrNotOnClaimList):
+		return httpx.Write(c, httpx.New(fiber.StatusForbidden, "not_on_claim_list").
+			WithMessage("security-advisory bounties can only be claimed by invited users"))
+	case errors.Is(err, bounties.ErrClaimWithheld):
+		// Deliberately vague: shadow-banned users aren't told.
+		return httpx.Write(c, httpx.New(fiber.StatusConflict, "bounty_unavailable").
+			WithMessage("this bounty can't be claimed right now"))
+	case errors.Is(err, bounties.ErrNotClaimant):
+		return httpx.Fail(c, fiber.StatusForbidden, "not_bounty_claimant")
+	case errors.Is(err, bounties.ErrWrongRepo):
+		return httpx.Fail(c, fiber.StatusBadRequest, "pr_not_in_project_repo")
+	case errors.Is(err, bounties.ErrEscrowFunded):
+		return httpx.Write(c, httpx.New(fiber.StatusConflict, "bounty_escrow_funded").
+			WithMessage("pay escrow-funded bounties by releasing the escrow"))
+	}
+	return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_update_failed").Wrap(err))
+}
+
+// lifecycleBounty loads the route's bounty for a signed-in caller.
+func (h *BountiesHandler) lifecycleBounty(c *fiber.Ctx) (bounties.Bounty, uuid.UUID, error) {
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return bounties.Bounty{}, uuid.Nil, httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+	}
+	b, herr := h.splitBounty(c)
+	if herr != nil {
+		return bounties.Bounty{}, uuid.Nil, httpx.Write(c, herr)
+	}
+	return b, userID, nil
+}
+
+// Claim assigns an open bounty to the caller.
+func (h *BountiesHandler) Claim() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		out, err := bounties.Claim(c.Context(), h.db.Pool, b, userID)
+		if err != nil {
+			return lifecycleError(c, b, err)
+		}
+		// Fraud rules never block the claim; matches land in the admin review queue.
+		if _, err := fraud.NewEngine(h.db.Pool).Evaluate(c.Context(), fraud.Subject{
+			Event:     fraud.EventClaim,
+			UserID:    userID,
+			SubjectID: b.ID.String(),
+			IP:        c.IP(),
+		}); err != nil {
+			httpx.Logger(c).Warn("fraud evaluation failed for bounty claim",
+				"bounty_id", b.ID.String(),
+				"user_id", userID.String(),
+				"error", err,
+			)
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+// Unclaim drops a bounty's claim and reopens it. The claimant can give up a
+// bounty, and the maintainer can take it back from them.
+func (h *BountiesHandler) Unclaim() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		maintainer := false
+		if b.Claim == nil || b.Claim.UserID != userID {
+			if ownerID, respErr := h.ownerCheck(c.Context(), c, b.ProjectID, orgs.PermEditBounties); ownerID == uuid.Nil {
+				return respErr
+			}
+			maintainer = true
+		}
+		out, err := bounties.Unclaim(c.Context(), h.db.Pool, b, userID, maintainer)
+		if err != nil {
+			return lifecycleError(c, b, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+type submitBountyRequest struct {
+	// PRURL is the GitHub pull request resolving the bounty's issue, in the
+	// project's repository.
+	PRURL string `json:"pr_url"`
+}
+
+// Submit links the claimant's pull request to a bounty for the maintainer's
+// review. Submitting again replaces the pull request.
+func (h *BountiesHandler) Submit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req submitBountyRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		pr, err := bounties.ParsePullRequest(req.PRURL)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_pr_url").
+				WithMessage("give the pull request's URL, e.g. https://github.com/owner/repo/pull/42"))
+		}
+		var repo string
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT github_full_name FROM projects WHERE id = $1`, b.ProjectID).Scan(&repo); err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "project_lookup_failed").Wrap(err))
+		}
+		out, err := bounties.Submit(c.Context(), h.db.Pool, b, userID, pr, repo)
+		if err != nil {
+			return lifecycleError(c, b, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+// Approve accepts a bounty's submission, leaving it to be paid.
+func (h *BountiesHandler) Approve() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, err := h.splitBounty(c)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		userID, respErr := h.ownerCheck(c.Context(), c, b.ProjectID, orgs.PermApprovePayouts)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		out, err := bounties.Approve(c.Context(), h.db.Pool, b, userID)
+		if err != nil {
+			return lifecycleError(c, b, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+type payBountyRequest struct {
+	// ToAddress is the claimant's address on the bounty's chain. It defaults
+	// to the wallet the claimant signed in with on that chain.
+	ToAddress string `json:"to_address"`
+}
+
+// Pay queues the hot wallet payout of an approved bounty to its claimant and
+// completes the bounty.
+func (h *BountiesHandler) Pay() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, err := h.splitBounty(c)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		actorID, respErr := h.ownerCheck(c.Context(), c, b.ProjectID, orgs.PermApprovePayouts)
+		if actorID == uuid.Nil {
+			return respErr
+		}
+		var req payBountyRequest
+		if len(c.Body()) > 0 {
+			if err := httpx.DecodeJSON(c, &req); err != nil {
+				return httpx.Write(c, err)
+			}
+		}
+		if err := b.CheckPay(); err != nil {
+			return lifecycleError(c, b, err)
+		}
+		to := chain.NormalizeAddress(strings.TrimSpace(req.ToAddress))
+		if to == "" {
+			addr, err := payouts.ClaimantAddress(c.Context(), h.db.Pool, b)
+			if errors.Is(err, payouts.ErrNoClaimantWallet) {
+				return httpx.Write(c, httpx.New(fiber.StatusUnprocessableEntity, "claimant_wallet_missing").
+					WithMessage("the claimant has no wallet on "+b.Chain+"; pass to_address"))
+			}
+			if err != nil {
+				return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_update_failed").Wrap(err))
+			}
+			to = chain.NormalizeAddress(addr)
+		}
+		if blocked, err := rejectGeoRestricted(c, h.cfg, h.db.Pool, geo.ActionPayout, b.Claim.UserID, false); blocked {
+			return err
+		}
+		p, err := payouts.PayBounty(c.Context(), h.db.Pool, b, to, actorID)
+		if err != nil {
+			return lifecycleError(c, b, err)
+		}
+		httpx.Logger(c).Info("bounty payout queued",
+			"actor_user_id", actorID.String(),
+			"bounty_id", b.ID.String(),
+			"payout_id", p.ID.String(),
+			"user_id", b.Claim.UserID.String(),
+		)
+		if evaluatePayoutRules(c, h.db.Pool, p) {
+			p = holdPayout(c, h.db.Pool, p)
+		}
+		return c.Status(fiber.StatusCreated).JSON(p)
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+	"github.com/jagadeesh/grainlify/backend/internal/splits"
+)
+
+// splitBounty parses the route's project and bounty and loads the bounty,
+// as if it didn't exist when the caller may not see it.
+func (h *BountiesHandler) splitBounty(c *fiber.Ctx) (bounties.Bounty, error) {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return bounties.Bounty{}, httpx.New(fiber.StatusBadRequest, "invalid_project_id")
+	}
+	bountyID, err := uuid.Parse(c.Params("bounty_id"))
+	if err != nil {
+		return bounties.Bounty{}, httpx.New(fiber.StatusBadRequest, "invalid_bounty_id")
+	}
+	b, err := bounties.Get(c.Context(), h.db.Pool, projectID, bountyID)
+	if errors.Is(err, bounties.ErrNotFound) {
+		return bounties.Bounty{}, httpx.New(fiber.StatusNotFound, "bounty_not_found")
+	}
+	if err != nil {
+		return bounties.Bounty{}, httpx.New(fiber.StatusInternalServerError, "bounty_lookup_failed").Wrap(err)
+	}
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	userID, _ := uuid.Parse(sub)
+	ok, err := canViewBounty(c, h.db.Pool, h.cfg.JWTSecret, b, userID)
+	if err != nil {
+		return bounties.Bounty{}, httpx.New(fiber.StatusInternalServerError, "bounty_lookup_failed").Wrap(err)
+	}
+	if !ok {
+		return bounties.Bounty{}, httpx.New(fiber.StatusNotFound, "bounty_not_found")
+	}
+	return b, nil
+}
+
+func splitError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, splits.ErrNotFound):
+		return httpx.Fail(c, fiber.StatusNotFound, "bounty_split_not_found")
+	case errors.Is(err, splits.ErrNotClaimant):
+		return httpx.Fail(c, fiber.StatusForbidden, "not_split_claimant")
+	case errors.Is(err, splits.ErrAccepted):
+		return httpx.Fail(c, fiber.StatusConflict, "split_already_accepted")
+	case errors.Is(err, splits.ErrInvalidShares):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_split_shares").
+			WithMessage("give every author's share in basis points, summing to 10000"))
+	}
+	return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_split_failed").Wrap(err))
+}
+
+type suggestSplitRequest struct {
+	PRNumber int `json:"pr_number"`
+}
+
+// SuggestSplit proposes how a bounty paid for a team pull request is split
+// between its authors, replacing any earlier proposal.
+func (h *BountiesHandler) SuggestSplit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, err := h.splitBounty(c)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		userID, respErr := h.ownerCheck(c.Context(), c, b.ProjectID, orgs.PermEditBounties)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		if b.Status == bounties.StatusCancelled {
+			return httpx.Fail(c, fiber.StatusConflict, "invalid_bounty_status")
+		}
+		var req suggestSplitRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if req.PRNumber < 1 {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_pr_number")
+		}
+		s := &splits.Suggester{
+			Pool:           h.db.Pool,
+			TokenEncKeyB64: h.cfg.TokenEncKeyB64,
+			Installations:  github.AppInstallations(h.cfg.GitHubAppID, h.cfg.GitHubAppPrivateKey),
+		}
+		sp, err := s.Suggest(c.Context(), b, req.PRNumber, userID)
+		if errors.Is(err, splits.ErrNoAuthors) {
+			return httpx.Write(c, httpx.New(fiber.StatusUnprocessableEntity, "no_split_authors").
+				WithMessage("none of the pull request's commits are by a GitHub account"))
+		}
+		if err != nil {
+			httpx.Logger(c).Warn("bounty split suggestion failed", "bounty_id", b.ID.String(), "pr_number", req.PRNumber, "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "pr_commits_lookup_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(sp)
+	}
+}
+
+// GetSplit returns a bounty's current split to its maintainers and
+// claimants.
+func (h *BountiesHandler) GetSplit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, err := h.splitBounty(c)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		sp, err := splits.Current(c.Context(), h.db.Pool, b.ID)
+		if err != nil {
+			return splitError(c, err)
+		}
+		if !sp.IsClaimant(userID) {
+			if ownerID, respErr := h.ownerCheck(c.Context(), c, b.ProjectID, orgs.PermViewFinances); ownerID == uuid.Nil {
+				return respErr
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(sp)
+	}
+}
+
+// AcceptSplit records the caller's agreement to the current split.
+func (h *BountiesHandler) AcceptSplit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, err := h.splitBounty(c)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		sp, err := splits.Current(c.Context(), h.db.Pool, b.ID)
+		if err != nil {
+			return splitError(c, err)
+		}
+		sp, err = splits.Accept(c.Context(), h.db.Pool, sp.ID, userID)
+		if err != nil {
+			return splitError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(sp)
+	}
+}
+
+type adjustSplitRequest struct {
+	// Shares maps every author's GitHub login to their share in basis
+	// points; they must sum to 10000.
+	Shares map[string]int `json:"shares"`
+}
+
+// AdjustSplit lets a claimant change the shares. The other claimants have
+// to accept the new shares.
+func (h *BountiesHandler) AdjustSplit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, err := h.splitBounty(c)
+		if err != nil {
+			return httpx.Write(c, err)
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req adjustSplitRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		sp, err := splits.Current(c.Context(), h.db.Pool, b.ID)
+		if err != nil {
+			return splitError(c, err)
+		}
+		sp, err = splits.Adjust(c.Context(), h.db.Pool, sp.ID, userID, b.Amount, req.Shares)
+		if err != nil {
+			return splitError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(sp)
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+func timeLogError(c *fiber.Ctx, b bounties.Bounty, err error) error {
+	switch {
+	case errors.Is(err, bounties.ErrTimerRunning):
+		return httpx.Fail(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, bounties.ErrNoTimer), errors.Is(err, bounties.ErrTimeEntryMissing):
+		return httpx.Fail(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, bounties.ErrInvalidTimeEntry):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, err.Error()).
+			WithMessage("entries must end after they start, in the past, after the claim and within 12 hours"))
+	}
+	return lifecycleError(c, b, err)
+}
+
+type timerRequest struct {
+	Note string `json:"note"`
+}
+
+// StartTimer starts a timer on the caller's claim.
+func (h *BountiesHandler) StartTimer() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req timerRequest
+		if len(c.Body()) > 0 {
+			if err := httpx.DecodeJSON(c, &req); err != nil {
+				return httpx.Write(c, err)
+			}
+		}
+		e, err := bounties.StartTimer(c.Context(), h.db.Pool, b, userID, req.Note)
+		if err != nil {
+			return timeLogError(c, b, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(e)
+	}
+}
+
+// StopTimer stops the caller's running timer.
+func (h *BountiesHandler) StopTimer() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		e, err := bounties.StopTimer(c.Context(), h.db.Pool, b, userID)
+		if err != nil {
+			return timeLogError(c, b, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(e)
+	}
+}
+
+type logTimeRequest struct {
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Note      string    `json:"note"`
+}
+
+// LogTime records a manual time entry on the caller's claim.
+func (h *BountiesHandler) LogTime() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req logTimeRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		e, err := bounties.LogTime(c.Context(), h.db.Pool, b, userID, req.StartedAt, req.EndedAt, req.Note)
+		if err != nil {
+			return timeLogError(c, b, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(e)
+	}
+}
+
+// DeleteTimeEntry removes one of the caller's time entries.
+func (h *BountiesHandler) DeleteTimeEntry() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		entryID, err := uuid.Parse(c.Params("entry_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_entry_id")
+		}
+		if err := bounties.DeleteTimeEntry(c.Context(), h.db.Pool, b, userID, entryID); err != nil {
+			return timeLogError(c, b, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// TimeLogged shows the time logged on a bounty: the claimant's own, or for
+// the project's maintainers the current claimant's (another claimant's with
+// ?user_id=), next to their submission.
+func (h *BountiesHandler) TimeLogged() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		subject := userID
+		if b.Claim == nil || b.Claim.UserID != userID || c.Query("user_id") != "" {
+			if managerID, respErr := h.ownerCheck(c.Context(), c, b.ProjectID, orgs.PermViewFinances); managerID == uuid.Nil {
+				return respErr
+			}
+			switch {
+			case c.Query("user_id") != "":
+				id, err := uuid.Parse(c.Query("user_id"))
+				if err != nil {
+					return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+				}
+				subject = id
+			case b.Claim != nil:
+				subject = b.Claim.UserID
+			default:
+				return httpx.Write(c, httpx.New(fiber.StatusConflict, "invalid_bounty_status").With("status", b.Status))
+			}
+		}
+		s, err := bounties.TimeLogged(c.Context(), h.db.Pool, b, subject)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "time_log_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(s)
+	}
+}
+
+type bountyRatesResponse struct {
+	Rates []bounties.Rate `json:"rates"`
+}
+
+// Rates suggests bounty amounts from the time claimants logged on completed
+// bounties, the project's own once it has enough.
+func (h *BountiesHandler) Rates() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		if userID, respErr := h.ownerCheck(c.Context(), c, projectID, orgs.PermEditBounties); userID == uuid.Nil {
+			return respErr
+		}
+		rates, err := bounties.Rates(c.Context(), h.db.Pool, projectID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_rates_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(bountyRatesResponse{Rates: rates})
+	}
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+// linkedBounty is a bounty as its maintainers see it, with the token of its
+// link when it is unlisted.
+type linkedBounty struct {
+	bounties.Bounty
+	LinkToken string `json:"link_token,omitempty"`
+}
+
+func (h *BountiesHandler) withLink(b bounties.Bounty) linkedBounty {
+	return linkedBounty{Bounty: b, LinkToken: bounties.LinkToken(h.cfg.JWTSecret, b)}
+}
+
+// Get returns one bounty. Unlisted bounties need their link's ?token=;
+// private ones are only found by the users who may see them.
+func (h *BountiesHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, herr := h.splitBounty(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		return c.Status(fiber.StatusOK).JSON(b)
+	}
+}
+
+// maintainedBounty loads the route's bounty for a maintainer allowed to
+// edit the project's bounties.
+func (h *BountiesHandler) maintainedBounty(c *fiber.Ctx) (bounties.Bounty, uuid.UUID, error) {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return bounties.Bounty{}, uuid.Nil, httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+	}
+	bountyID, err := uuid.Parse(c.Params("bounty_id"))
+	if err != nil {
+		return bounties.Bounty{}, uuid.Nil, httpx.Fail(c, fiber.StatusBadRequest, "invalid_bounty_id")
+	}
+	userID, respErr := h.ownerCheck(c.Context(), c, projectID, orgs.PermEditBounties)
+	if userID == uuid.Nil {
+		return bounties.Bounty{}, uuid.Nil, respErr
+	}
+	b, err := bounties.Get(c.Context(), h.db.Pool, projectID, bountyID)
+	if errors.Is(err, bounties.ErrNotFound) {
+		return bounties.Bounty{}, uuid.Nil, httpx.Fail(c, fiber.StatusNotFound, "bounty_not_found")
+	}
+	if err != nil {
+		return bounties.Bounty{}, uuid.Nil, httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_lookup_failed").Wrap(err))
+	}
+	return b, userID, nil
+}
+
+type hiddenBountiesResponse struct {
+	Bounties []linkedBounty `json:"bounties"`
+}
+
+// Hidden lists a project's private and unlisted bounties, which the public
+// list leaves out, with the links of unlisted ones.
+func (h *BountiesHandler) Hidden() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		if userID, respErr := h.ownerCheck(c.Context(), c, projectID, orgs.PermEditBounties); userID == uuid.Nil {
+			return respErr
+		}
+		list, err := bounties.ListHidden(c.Context(), h.db.Pool, projectID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounties_list_failed").Wrap(err))
+		}
+		out := hiddenBountiesResponse{Bounties: make([]linkedBounty, 0, len(list))}
+		for _, b := range list {
+			out.Bounties = append(out.Bounties, h.withLink(b))
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+type sharedBountiesResponse struct {
+	Bounties []bounties.Bounty `json:"bounties"`
+}
+
+// Shared lists the private and unlisted bounties the caller can see,
+// through an invite or their GitHub organization.
+func (h *BountiesHandler) Shared() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		list, err := bounties.SharedWith(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounties_list_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(sharedBountiesResponse{Bounties: list})
+	}
+}
+
+type setVisibilityRequest struct {
+	Visibility string `json:"visibility"`
+}
+
+// SetVisibility makes a bounty public, private or unlisted. Private and
+// unlisted bounties drop out of feeds; the response carries the link of an
+// unlisted one.
+func (h *BountiesHandler) SetVisibility() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.maintainedBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req setVisibilityRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		out, err := bounties.SetVisibility(c.Context(), h.db.Pool, b.ProjectID, b.ID, userID, strings.TrimSpace(req.Visibility))
+		if errors.Is(err, bounties.ErrInvalidVisibility) {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_visibility").With("visibilities", bounties.Visibilities))
+		}
+		if errors.Is(err, bounties.ErrEmbargoed) {
+			return httpx.Write(c, httpx.New(fiber.StatusConflict, "bounty_under_embargo").
+				WithMessage("security-advisory bounties stay private until their advisory is disclosed"))
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_update_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(h.withLink(out))
+	}
+}
+
+// RotateLink revokes an unlisted bounty's link and returns a new one.
+func (h *BountiesHandler) RotateLink() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.maintainedBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		out, err := bounties.RotateLink(c.Context(), h.db.Pool, b.ProjectID, b.ID, userID)
+		if errors.Is(err, bounties.ErrNotUnlisted) {
+			return httpx.Write(c, httpx.New(fiber.StatusConflict, err.Error()).With("visibility", b.Visibility))
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_update_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(h.withLink(out))
+	}
+}
+
+type bountyInvitesResponse struct {
+	Invites []bounties.Invite `json:"invites"`
+}
+
+// Invites lists the users invited to a bounty.
+func (h *BountiesHandler) Invites() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.maintainedBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		invites, err := bounties.Invites(c.Context(), h.db.Pool, b.ID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_invites_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(bountyInvitesResponse{Invites: invites})
+	}
+}
+
+type inviteRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// Invite lets a user outside the project's organization, such as a
+// security researcher, see and claim a private or unlisted bounty.
+func (h *BountiesHandler) Invite() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.maintainedBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req inviteRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if req.UserID == uuid.Nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_fields")
+		}
+		err := bounties.AddInvite(c.Context(), h.db.Pool, b.ID, req.UserID, userID)
+		if errors.Is(err, bounties.ErrInviteeNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_invites_failed").Wrap(err))
+		}
+		invites, err := bounties.Invites(c.Context(), h.db.Pool, b.ID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_invites_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusCreated).JSON(bountyInvitesResponse{Invites: invites})
+	}
+}
+
+// Uninvite withdraws a user's invite to a bounty. It doesn't take back a
+// claim they already made.
+func (h *BountiesHandler) Uninvite() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.maintainedBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		invitee, err := uuid.Parse(c.Params("user_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		err = bounties.RemoveInvite(c.Context(), h.db.Pool, b.ID, invitee)
+		if errors.Is(err, bounties.ErrNotInvited) {
+			return httpx.Fail(c, fiber.StatusNotFound, err.Error())
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "bounty_invites_failed").Wrap(err))
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/deposits"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// ChainWebhooksHandler receives address-activity webhooks from indexer
+// providers and feeds them into chain.Ingest.
+type ChainWebhooksHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewChainWebhooksHandler(cfg config.Config, d *db.DB) *ChainWebhooksHandler {
+	return &ChainWebhooksHandler{cfg: cfg, db: d}
+}
+
+func (h *ChainWebhooksHandler) Receive() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		provider := c.Params("provider")
+
+		header := http.Header{}
+		c.Request().Header.VisitAll(func(k, v []byte) {
+			header.Add(string(k), string(v))
+		})
+
+		transfers, err := chain.ParseWebhook(provider, chain.ProviderSecrets{
+			AlchemySigningKey: h.cfg.AlchemyWebhookSigningKey,
+			HeliusAuthHeader:  h.cfg.HeliusWebhookAuthHeader,
+			QuickNodeSecret:   h.cfg.QuickNodeWebhookSecret,
+		}, header, c.Body())
+		switch {
+		case errors.Is(err, chain.ErrUnknownProvider), errors.Is(err, chain.ErrProviderDisabled):
+			return httpx.Fail(c, fiber.StatusNotFound, "provider_not_configured")
+		case errors.Is(err, chain.ErrBadSignature):
+			httpx.Logger(c).Warn("chain webhook signature rejected", "provider", provider, "remote_ip", c.IP())
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_signature")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_payload")
+		}
+
+		inserted := 0
+		for _, t := range transfers {
+			res, err := chain.Ingest(c.Context(), h.db.Pool, provider, t, deposits.MatchTransfer)
+			if err != nil {
+				// Fail the delivery so the provider retries; ingestion is idempotent.
+				httpx.Logger(c).Error("chain transfer ingest failed",
+					"provider", provider,
+					"chain", t.Chain,
+					"tx_hash", t.TxHash,
+					"error", err,
+				)
+				return httpx.Fail(c, fiber.StatusInternalServerError, "ingest_failed")
+			}
+			if !res.Inserted {
+				continue
+			}
+			inserted++
+		}
+
+		httpx.Logger(c).Info("chain webhook processed",
+			"provider", provider,
+			"transfers", len(transfers),
+			"inserted", inserted,
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "received": len(transfers), "inserted": inserted})
+	}
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/messaging"
+)
+
+func messagingError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, messaging.ErrInvalidKey),
+		errors.Is(err, messaging.ErrInvalidNonce),
+		errors.Is(err, messaging.ErrInvalidCiphertext),
+		errors.Is(err, messaging.ErrBadKeySignature):
+		return httpx.Fail(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, auth.ErrWalletNotFound):
+		return httpx.Fail(c, fiber.StatusBadRequest, "wallet_not_linked")
+	case errors.Is(err, messaging.ErrNoKey):
+		return httpx.Fail(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, messaging.ErrNotParticipant):
+		return httpx.Fail(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, messaging.ErrNotClaimed),
+		errors.Is(err, messaging.ErrNoRecipientKey):
+		return httpx.Fail(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, messaging.ErrKeyChanged):
+		return httpx.Write(c, httpx.New(fiber.StatusConflict, err.Error()).
+			WithMessage("a messaging key changed; fetch both keys again and re-encrypt"))
+	}
+	return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "messaging_failed").Wrap(err))
+}
+
+// MessagingKeysHandler publishes the keys claim messages are encrypted to.
+type MessagingKeysHandler struct {
+	db *db.DB
+}
+
+func NewMessagingKeysHandler(d *db.DB) *MessagingKeysHandler {
+	return &MessagingKeysHandler{db: d}
+}
+
+// Publish sets the caller's messaging key, signed by one of their wallets.
+func (h *MessagingKeysHandler) Publish() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req messaging.Publication
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		k, err := messaging.Publish(c.Context(), h.db.Pool, userID, req)
+		if err != nil {
+			return messagingError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(k)
+	}
+}
+
+// Delete withdraws the caller's messaging key.
+func (h *MessagingKeysHandler) Delete() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		if err := messaging.DeleteKey(c.Context(), h.db.Pool, userID); err != nil {
+			return messagingError(c, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// Get returns a user's messaging key with the wallet signature vouching for
+// it.
+func (h *MessagingKeysHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		userID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		k, err := messaging.GetKey(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return messagingError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(k)
+	}
+}
+
+type claimMessagesResponse struct {
+	Messages []messaging.Message `json:"messages"`
+}
+
+// Messages lists a claim's encrypted thread. The project owner reads the
+// current claimant's thread, or an earlier claimant's with ?claimant_id=;
+// claimants read their own.
+func (h *BountiesHandler) Messages() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var since *time.Time
+		if s := c.Query("since"); s != "" {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_since")
+			}
+			since = &t
+		}
+		var owner uuid.UUID
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, b.ProjectID).Scan(&owner); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+		}
+
+		claimant := userID
+		if userID == owner {
+			switch {
+			case c.Query("claimant_id") != "":
+				id, err := uuid.Parse(c.Query("claimant_id"))
+				if err != nil {
+					return httpx.Fail(c, fiber.StatusBadRequest, "invalid_claimant_id")
+				}
+				claimant = id
+			case b.Claim != nil:
+				claimant = b.Claim.UserID
+			default:
+				return messagingError(c, messaging.ErrNotClaimed)
+			}
+		} else if b.Claim == nil || b.Claim.UserID != userID {
+			ok, err := messaging.HasThread(c.Context(), h.db.Pool, b.ID, userID)
+			if err != nil {
+				return messagingError(c, err)
+			}
+			if !ok {
+				return messagingError(c, messaging.ErrNotParticipant)
+			}
+		}
+
+		out, err := messaging.List(c.Context(), h.db.Pool, b.ID, claimant, since, c.QueryInt("limit", messaging.MaxPage))
+		if err != nil {
+			return messagingError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(claimMessagesResponse{Messages: out})
+	}
+}
+
+// SendMessage posts an encrypted message to the other side of a bounty's
+// current claim.
+func (h *BountiesHandler) SendMessage() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		b, userID, respErr := h.lifecycleBounty(c)
+		if userID == uuid.Nil {
+			return respErr
+		}
+		var req messaging.Draft
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		var owner uuid.UUID
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, b.ProjectID).Scan(&owner); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+		}
+		m, err := messaging.Send(c.Context(), h.db.Pool, b, owner, userID, req)
+		if err != nil {
+			return messagingError(c, err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(m)
+	}
+}
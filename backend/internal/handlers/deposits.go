@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/deposits"
+	"github.com/jagadeesh/grainlify/backend/internal/hdwallet"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+type DepositsHandler struct {
+	db  *db.DB
+	svc *deposits.Service
+}
+
+func NewDepositsHandler(cfg config.Config, d *db.DB) *DepositsHandler {
+	var seed []byte
+	if cfg.DepositEd25519SeedB64 != "" {
+		b, err := base64.StdEncoding.DecodeString(cfg.DepositEd25519SeedB64)
+		if err != nil {
+			slog.Error("invalid DEPOSIT_ED25519_SEED_B64; stellar/solana deposits disabled", "error", err)
+		} else {
+			seed = b
+		}
+	}
+	deriver, err := hdwallet.NewDeriver(cfg.DepositEVMXPub, seed)
+	if err != nil {
+		slog.Error("invalid deposit derivation config; deposit intents disabled", "error", err)
+		deriver = nil
+	}
+	h := &DepositsHandler{db: d}
+	if d != nil && d.Pool != nil {
+		h.svc = &deposits.Service{
+			Pool:     d.Pool,
+			Deriver:  deriver,
+			GapLimit: cfg.DepositGapLimit,
+			TTL:      time.Duration(cfg.DepositIntentTTLHours) * time.Hour,
+		}
+	}
+	return h
+}
+
+type createDepositIntentRequest struct {
+	Chain          string `json:"chain"`
+	Asset          string `json:"asset"`
+	ExpectedAmount string `json:"expected_amount"`
+}
+
+func (h *DepositsHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.svc == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		var req createDepositIntentRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		req.Asset = strings.TrimSpace(req.Asset)
+		if req.Asset == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "asset_required")
+		}
+
+		in, err := h.svc.CreateIntent(c.Context(), userID, req.Chain, req.Asset, strings.TrimSpace(req.ExpectedAmount))
+		switch {
+		case errors.Is(err, deposits.ErrUnsupportedChain):
+			return httpx.Fail(c, fiber.StatusBadRequest, "unsupported_chain")
+		case errors.Is(err, deposits.ErrGapLimit):
+			// Too many handed-out addresses are still unfunded; try again once some expire.
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "deposit_addresses_exhausted")
+		case err != nil:
+			httpx.Logger(c).Error("failed to create deposit intent", "user_id", userID.String(), "chain", req.Chain, "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "deposit_intent_create_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(in)
+	}
+}
+
+func (h *DepositsHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_intent_id")
+		}
+		in, err := deposits.GetIntent(c.Context(), h.db.Pool, userID, id)
+		if errors.Is(err, deposits.ErrIntentNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "intent_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "deposit_intent_lookup_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(in)
+	}
+}
+
+func (h *DepositsHandler) Mine() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		intents, err := deposits.ListIntents(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "deposit_intents_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"intents": intents})
+	}
+}
+
+// Cursors exposes derivation index tracking for operators (admin).
+func (h *DepositsHandler) Cursors() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.svc == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		cursors, err := h.svc.Cursors(c.Context())
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "cursors_lookup_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"cursors": cursors})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/didit"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
 type DiditWebhookHandler struct {
@@ -46,7 +47,7 @@ type WebhookEvent struct {
 func (h *DiditWebhookHandler) Receive() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		var sessionID string
@@ -63,16 +64,18 @@ func (h *DiditWebhookHandler) Receive() fiber.Handler {
 			}
 		} else {
 			// Handle POST request (webhook event from Didit)
+			// Didit's payload is not ours to pin down, so unknown fields are
+			// tolerated here, unlike in request bodies of our own API.
 			var event WebhookEvent
 			if err := c.BodyParser(&event); err != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_json")
 			}
 			sessionID = event.SessionID
 			status = event.Status
 		}
 
 		if sessionID == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_session_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_session_id")
 		}
 
 		// Find user by session ID
@@ -84,7 +87,7 @@ WHERE kyc_session_id = $1
 `, sessionID).Scan(&userID)
 		if err != nil {
 			// Session not found - might be from another system or invalid
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "session_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "session_not_found")
 		}
 
 		// Process status update
@@ -124,7 +127,7 @@ SET kyc_status = $1,
 WHERE id = $3
 `, kycStatus, decisionJSON, userID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_update_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "kyc_update_failed")
 		}
 
 		// For GET requests (callback redirect), redirect to success page
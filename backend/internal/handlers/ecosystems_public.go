@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
 type EcosystemsPublicHandler struct {
@@ -23,7 +24,7 @@ func NewEcosystemsPublicHandler(d *db.DB) *EcosystemsPublicHandler {
 func (h *EcosystemsPublicHandler) ListActive() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
@@ -46,7 +47,7 @@ ORDER BY e.created_at DESC
 LIMIT 200
 `)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystems_list_failed")
 		}
 		defer rows.Close()
 
@@ -65,7 +66,7 @@ LIMIT 200
 				userCnt    int64
 			)
 			if err := rows.Scan(&id, &slug, &name, &desc, &website, &status, &createdAt, &updatedAt, &projectCnt, &userCnt); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystems_list_failed")
 			}
 			out = append(out, fiber.Map{
 				"id":            id.String(),
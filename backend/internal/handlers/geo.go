@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/geo"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// requestCountry is the caller's IP country as reported by the edge, or "".
+func requestCountry(c *fiber.Ctx, cfg config.Config) string {
+	if cfg.GeoIPCountryHeader == "" {
+		return ""
+	}
+	return geo.CountryFromHeader(c.Get(cfg.GeoIPCountryHeader))
+}
+
+// recordIPCountry remembers where userID signed in from. Failures only log.
+func recordIPCountry(c *fiber.Ctx, cfg config.Config, pool *pgxpool.Pool, userID uuid.UUID) {
+	if err := geo.RecordIPCountry(c.Context(), pool, userID, requestCountry(c, cfg)); err != nil {
+		httpx.Logger(c).Warn("failed to record ip country", "user_id", userID.String(), "error", err)
+	}
+}
+
+// rejectGeoRestricted answers 451 and records an audit entry when action is
+// restricted for userID. fromRequest uses the caller's IP country; otherwise
+// (userID is not the caller) the one from their last sign-in.
+func rejectGeoRestricted(c *fiber.Ctx, cfg config.Config, pool *pgxpool.Pool, action string, userID uuid.UUID, fromRequest bool) (bool, error) {
+	ipCountry := ""
+	if fromRequest {
+		ipCountry = requestCountry(c, cfg)
+	}
+	r, err := geo.CheckUser(c.Context(), pool, action, userID, ipCountry)
+	if errors.Is(err, geo.ErrUserNotFound) {
+		return true, httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+	}
+	if err != nil {
+		httpx.Logger(c).Error("geo restriction check failed", "action", action, "user_id", userID.String(), "error", err)
+		return true, httpx.Fail(c, fiber.StatusInternalServerError, "geo_check_failed")
+	}
+	if r == nil {
+		return false, nil
+	}
+	recordAudit(c, pool, &userID, audit.ActionRegionBlocked, map[string]any{
+		"action":  r.Action,
+		"country": r.Country,
+		"source":  r.Source,
+		"path":    c.Path(),
+	})
+	httpx.Logger(c).Info("action blocked by geo restriction",
+		"action", r.Action,
+		"user_id", userID.String(),
+		"country", r.Country,
+		"source", r.Source,
+	)
+	return true, httpx.Write(c, httpx.New(fiber.StatusUnavailableForLegalReasons, "region_restricted").With("restriction", r))
+}
+
+type GeoHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewGeoHandler(cfg config.Config, d *db.DB) *GeoHandler {
+	return &GeoHandler{cfg: cfg, db: d}
+}
+
+// MyCountry returns the caller's declared country and the country of this request.
+func (h *GeoHandler) MyCountry() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		declared, _, err := geo.UserCountries(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "country_lookup_failed")
+		}
+		resp := fiber.Map{"country": nil, "ip_country": nil}
+		if declared != "" {
+			resp["country"] = declared
+		}
+		if ip := requestCountry(c, h.cfg); ip != "" {
+			resp["ip_country"] = ip
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+}
+
+type setCountryRequest struct {
+	Country string `json:"country"`
+}
+
+// SetMyCountry sets (or, with an empty country, clears) the caller's declared country.
+func (h *GeoHandler) SetMyCountry() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req setCountryRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		country, err := geo.SetDeclaredCountry(c.Context(), h.db.Pool, userID, req.Country)
+		switch {
+		case errors.Is(err, geo.ErrInvalidCountry):
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_country")
+		case errors.Is(err, geo.ErrUserNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "country_update_failed")
+		}
+		recordAudit(c, h.db.Pool, &userID, audit.ActionCountryDeclared, map[string]any{
+			"country":    country,
+			"ip_country": requestCountry(c, h.cfg),
+		})
+		if country == "" {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"country": nil})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"country": country})
+	}
+}
+
+// Policies lists the restriction policy of every action.
+func (h *GeoHandler) Policies() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		out, err := geo.ListPolicies(c.Context(), h.db.Pool)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "geo_policies_lookup_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"policies":          out,
+			"ip_country_header": h.cfg.GeoIPCountryHeader,
+		})
+	}
+}
+
+type setGeoPolicyRequest struct {
+	BlockedCountries []string `json:"blocked_countries"`
+	CheckIP          *bool    `json:"check_ip"`
+	CheckDeclared    *bool    `json:"check_declared"`
+	BlockUnknown     bool     `json:"block_unknown"`
+}
+
+// SetPolicy replaces the restriction policy of the :action in the path.
+func (h *GeoHandler) SetPolicy() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req setGeoPolicyRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		p := geo.Policy{
+			Action:           c.Params("action"),
+			BlockedCountries: req.BlockedCountries,
+			CheckIP:          req.CheckIP == nil || *req.CheckIP,
+			CheckDeclared:    req.CheckDeclared == nil || *req.CheckDeclared,
+			BlockUnknown:     req.BlockUnknown,
+		}
+		p, err = geo.SetPolicy(c.Context(), h.db.Pool, actorID, p)
+		switch {
+		case errors.Is(err, geo.ErrUnknownAction):
+			return httpx.Fail(c, fiber.StatusNotFound, "unknown_action")
+		case errors.Is(err, geo.ErrInvalidCountry):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_country").WithMessage(err.Error()))
+		case err != nil:
+			httpx.Logger(c).Error("failed to update geo policy", "action", c.Params("action"), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "geo_policy_update_failed")
+		}
+		recordAudit(c, h.db.Pool, nil, audit.ActionGeoPolicyUpdated, map[string]any{
+			"action":            p.Action,
+			"blocked_countries": p.BlockedCountries,
+			"check_ip":          p.CheckIP,
+			"check_declared":    p.CheckDeclared,
+			"block_unknown":     p.BlockUnknown,
+		})
+		return c.Status(fiber.StatusOK).JSON(p)
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
 )
 
 type GitHubAppHandler struct {
@@ -32,20 +34,17 @@ func NewGitHubAppHandler(cfg config.Config, d *db.DB) *GitHubAppHandler {
 func (h *GitHubAppHandler) StartInstallation() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		if h.cfg.GitHubAppID == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-				"error":   "github_app_not_configured",
-				"message": "GitHub App is not configured. Please contact support.",
-			})
+			return httpx.Write(c, httpx.New(fiber.StatusServiceUnavailable, "github_app_not_configured").WithMessage("GitHub App is not configured. Please contact support."))
 		}
 
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		// Generate state for installation callback
@@ -57,7 +56,7 @@ INSERT INTO oauth_states (state, user_id, kind, expires_at)
 VALUES ($1, $2, 'github_app_install', $3)
 `, state, userID, expiresAt)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_create_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "state_create_failed")
 		}
 
 		// Build GitHub App installation URL
@@ -94,7 +93,7 @@ VALUES ($1, $2, 'github_app_install', $3)
 		}
 
 		// Log installation start for debugging
-		slog.Info("GitHub App installation started",
+		httpx.Logger(c).Info("GitHub App installation started",
 			"user_id", userID,
 			"app_slug", appSlug,
 			"app_id", h.cfg.GitHubAppID,
@@ -114,7 +113,7 @@ VALUES ($1, $2, 'github_app_install', $3)
 func (h *GitHubAppHandler) HandleInstallationCallback() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Log immediately when callback is hit (even before DB check)
-		slog.Info("=== GitHub App callback endpoint hit ===",
+		httpx.Logger(c).Info("=== GitHub App callback endpoint hit ===",
 			"method", c.Method(),
 			"path", c.Path(),
 			"full_url", c.OriginalURL(),
@@ -123,13 +122,13 @@ func (h *GitHubAppHandler) HandleInstallationCallback() fiber.Handler {
 		)
 
 		if h.db == nil || h.db.Pool == nil {
-			slog.Error("callback received but DB not configured")
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			httpx.Logger(c).Error("callback received but DB not configured")
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		// Log all query parameters for debugging
 		allParams := c.Queries()
-		slog.Info("GitHub App installation callback received",
+		httpx.Logger(c).Info("GitHub App installation callback received",
 			"method", c.Method(),
 			"path", c.Path(),
 			"query_params", allParams,
@@ -143,7 +142,7 @@ func (h *GitHubAppHandler) HandleInstallationCallback() fiber.Handler {
 
 		// If installation_id is missing, user might have cancelled or accessed URL directly
 		if installationID == "" {
-			slog.Warn("GitHub App callback missing installation_id - user may have cancelled installation",
+			httpx.Logger(c).Warn("GitHub App callback missing installation_id - user may have cancelled installation",
 				"state", state,
 				"setup_action", setupAction,
 				"all_params", allParams,
@@ -163,11 +162,9 @@ func (h *GitHubAppHandler) HandleInstallationCallback() fiber.Handler {
 				return c.Redirect(u.String(), fiber.StatusFound)
 			}
 
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error":   "missing_installation_id",
-				"message": "Installation ID is missing. You may have cancelled the installation or accessed this URL directly.",
-				"hint":    "Please try installing the GitHub App again from the dashboard.",
-			})
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "missing_installation_id").
+				WithMessage("Installation ID is missing. You may have cancelled the installation or accessed this URL directly.").
+				With("hint", "Please try installing the GitHub App again from the dashboard."))
 		}
 
 		// Verify state and get user ID
@@ -183,10 +180,10 @@ WHERE state = $1
   AND kind = 'github_app_install'
 `, state).Scan(&storedUserID, &storedKind)
 			if errors.Is(err, pgx.ErrNoRows) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_or_expired_state"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_or_expired_state")
 			}
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_lookup_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "state_lookup_failed")
 			}
 
 			if storedUserID != nil {
@@ -199,7 +196,7 @@ WHERE state = $1
 
 		// If we don't have userID, we can't create projects - just redirect
 		if userID == (uuid.UUID{}) {
-			slog.Warn("GitHub App installation callback: no user ID found, skipping repository sync",
+			httpx.Logger(c).Warn("GitHub App installation callback: no user ID found, skipping repository sync",
 				"installation_id", installationID,
 				"state", state,
 			)
@@ -218,7 +215,7 @@ WHERE state = $1
 		// Build redirect URL with query parameters
 		u, err := url.Parse(strings.TrimSuffix(redirectURL, "/") + "/dashboard")
 		if err != nil {
-			slog.Error("failed to parse redirect URL", "error", err, "url", redirectURL)
+			httpx.Logger(c).Error("failed to parse redirect URL", "error", err, "url", redirectURL)
 			// Fallback: return JSON response
 			return c.Status(fiber.StatusOK).JSON(fiber.Map{
 				"ok":              true,
@@ -237,7 +234,7 @@ WHERE state = $1
 		}
 		u.RawQuery = q.Encode()
 
-		slog.Info("redirecting after GitHub App installation",
+		httpx.Logger(c).Info("redirecting after GitHub App installation",
 			"installation_id", installationID,
 			"redirect_url", u.String(),
 			"frontend_base_url", redirectURL,
@@ -273,6 +270,14 @@ func (h *GitHubAppHandler) syncInstallationRepositories(ctx context.Context, use
 		return
 	}
 
+	// Installations on organizations link the organization, so its admins
+	// manage its projects. The installer must be one of them.
+	if o, err := orgs.Link(ctx, h.db.Pool, appClient, installationID, userID); err == nil {
+		slog.Info("linked github org from app installation", "org", o.Login, "installation_id", installationID)
+	} else if !errors.Is(err, orgs.ErrNotOrganization) {
+		slog.Warn("github org not linked", "error", err, "installation_id", installationID)
+	}
+
 	// Get installation token
 	installationToken, err := appClient.GetInstallationToken(ctx, installationID)
 	if err != nil {
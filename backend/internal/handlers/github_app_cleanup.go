@@ -108,6 +108,9 @@ func (h *GitHubAppCleanupHandler) checkSingleInstallation(ctx context.Context, a
 			slog.Info("installation no longer exists, marking projects as deleted",
 				"installation_id", installationID,
 			)
+			if t := github.AppInstallations(h.cfg.GitHubAppID, h.cfg.GitHubAppPrivateKey); t != nil {
+				t.Forget(installationID)
+			}
 
 			// Mark all projects from this installation as deleted
 			result, err := h.pool.Exec(ctx, `
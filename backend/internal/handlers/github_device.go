@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// DeviceStart begins linking GitHub through the OAuth device flow, for CLI
+// and headless users: they enter the returned user_code at verification_uri
+// while the client polls DevicePoll with the returned id.
+func (h *GitHubOAuthHandler) DeviceStart() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if h.cfg.GitHubOAuthClientID == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "github_oauth_not_configured")
+		}
+		encKey, err := cryptox.KeyFromB64(h.cfg.TokenEncKeyB64)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		dc, err := github.RequestDeviceCode(c.Context(), h.cfg.GitHubOAuthClientID, githubLinkScopes)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadGateway, "device_code_request_failed")
+		}
+		encCode, err := cryptox.EncryptAESGCM(encKey, []byte(dc.DeviceCode))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_encrypt_failed")
+		}
+		interval := dc.Interval
+		if interval <= 0 {
+			interval = 5
+		}
+		expiresAt := time.Now().UTC().Add(time.Duration(dc.ExpiresIn) * time.Second)
+
+		_, _ = h.db.Pool.Exec(c.Context(), `DELETE FROM github_device_links WHERE expires_at < now()`)
+		var id uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO github_device_links (user_id, device_code, user_code, verification_uri, interval_seconds, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`, userID, encCode, dc.UserCode, dc.VerificationURI, interval, expiresAt).Scan(&id)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "device_link_create_failed")
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"id":               id.String(),
+			"user_code":        dc.UserCode,
+			"verification_uri": dc.VerificationURI,
+			"interval":         interval,
+			"expires_at":       expiresAt,
+		})
+	}
+}
+
+// DevicePoll checks a pending device flow link once. It answers 202 with
+// status pending or slow_down until the user approves, then links the account.
+func (h *GitHubOAuthHandler) DevicePoll() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if h.cfg.GitHubOAuthClientID == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "github_oauth_not_configured")
+		}
+		encKey, err := cryptox.KeyFromB64(h.cfg.TokenEncKeyB64)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		linkID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_device_link_id")
+		}
+
+		var encCode []byte
+		var interval int
+		var nextPollAt, expiresAt time.Time
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT device_code, interval_seconds, next_poll_at, expires_at
+FROM github_device_links
+WHERE id = $1 AND user_id = $2
+`, linkID, userID).Scan(&encCode, &interval, &nextPollAt, &expiresAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "device_link_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "device_link_lookup_failed")
+		}
+
+		remove := func() {
+			_, _ = h.db.Pool.Exec(c.Context(), `DELETE FROM github_device_links WHERE id = $1`, linkID)
+		}
+		now := time.Now()
+		if !now.Before(expiresAt) {
+			remove()
+			return httpx.Fail(c, fiber.StatusGone, "device_code_expired")
+		}
+		// Polling faster than GitHub allows gets the device code revoked, so
+		// early polls are answered here without asking GitHub.
+		if now.Before(nextPollAt) {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"status": "slow_down", "interval": interval})
+		}
+
+		deviceCode, err := cryptox.DecryptAESGCM(encKey, encCode)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_decrypt_failed")
+		}
+		tr, newInterval, err := github.PollDeviceToken(c.Context(), h.cfg.GitHubOAuthClientID, string(deviceCode))
+		switch {
+		case errors.Is(err, github.ErrAuthorizationPending), errors.Is(err, github.ErrSlowDown):
+			status := "pending"
+			if errors.Is(err, github.ErrSlowDown) {
+				status = "slow_down"
+				interval = max(newInterval, interval+5)
+			}
+			_, _ = h.db.Pool.Exec(c.Context(), `
+UPDATE github_device_links
+SET interval_seconds = $2, next_poll_at = now() + $2 * interval '1 second'
+WHERE id = $1
+`, linkID, interval)
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"status": status, "interval": interval})
+		case errors.Is(err, github.ErrDeviceCodeExpired):
+			remove()
+			return httpx.Fail(c, fiber.StatusGone, "device_code_expired")
+		case errors.Is(err, github.ErrAccessDenied):
+			remove()
+			return httpx.Fail(c, fiber.StatusForbidden, "access_denied")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusBadGateway, "device_poll_failed")
+		}
+		remove()
+
+		encToken, keyVersion, err := github.SealToken(h.cfg.TokenEncKeyB64, tr.AccessToken)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_encrypt_failed")
+		}
+		u, err := github.NewClient().GetUser(c.Context(), tr.AccessToken)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "github_user_fetch_failed")
+		}
+		if err := h.upsertGitHubAccount(c.Context(), userID, u, encToken, keyVersion, tr); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_account_upsert_failed")
+		}
+		recordAudit(c, h.db.Pool, &userID, audit.ActionGitHubLinked, map[string]any{
+			"github_user_id": u.ID,
+			"login":          u.Login,
+			"method":         "device",
+		})
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok":     true,
+			"status": "linked",
+			"github": fiber.Map{
+				"id":         u.ID,
+				"login":      u.Login,
+				"avatar_url": u.AvatarURL,
+			},
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
@@ -14,86 +15,61 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/cache"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/metrics"
+	"github.com/jagadeesh/grainlify/backend/internal/profilesync"
 )
 
-// isAllowedRedirectURI validates that a redirect URI is from an allowed origin.
-// This prevents open redirect vulnerabilities by only allowing:
-// - localhost origins (for development)
-// - *.vercel.app domains (for preview deployments)
-// - Explicit origins from CORS_ORIGINS config
-// - FrontendBaseURL (if configured)
+// isAllowedRedirectURI validates that a redirect URI is on an origin allowed
+// to call the API (see config.CORS), so OAuth can't be used as an open
+// redirect.
 func isAllowedRedirectURI(redirectURI string, cfg config.Config) bool {
 	parsedURL, err := url.Parse(redirectURI)
 	if err != nil {
 		return false
 	}
-
-	// Extract origin (scheme + host)
-	origin := parsedURL.Scheme + "://" + parsedURL.Host
-
-	// Always allow localhost origins for development
-	if strings.HasPrefix(origin, "http://localhost:") ||
-		strings.HasPrefix(origin, "http://127.0.0.1:") ||
-		strings.HasPrefix(origin, "https://localhost:") ||
-		strings.HasPrefix(origin, "https://127.0.0.1:") {
-		return true
-	}
-
-	// Allow all Vercel preview deployments (*.vercel.app)
-	if strings.HasSuffix(origin, ".vercel.app") {
-		return true
-	}
-
-	// Check explicit CORS origins
-	if strings.TrimSpace(cfg.CORSOrigins) != "" {
-		for _, o := range strings.Split(cfg.CORSOrigins, ",") {
-			o = strings.TrimSpace(o)
-			if o == "" {
-				continue
-			}
-			if origin == o || strings.HasPrefix(origin, o+"/") {
-				return true
-			}
-		}
-	}
-
-	// If FrontendBaseURL is set, allow it
-	if cfg.FrontendBaseURL != "" {
-		if origin == cfg.FrontendBaseURL || strings.HasPrefix(origin, cfg.FrontendBaseURL+"/") {
-			return true
-		}
-	}
-
-	return false
+	return cfg.CORS.AllowsOrigin(parsedURL.Scheme + "://" + parsedURL.Host)
 }
 
 type GitHubOAuthHandler struct {
 	cfg config.Config
 	db  *db.DB
+	// githubProfiles is the /auth/me GitHub profile cache, dropped on re-link.
+	githubProfiles *cache.Cache
 }
 
-func NewGitHubOAuthHandler(cfg config.Config, d *db.DB) *GitHubOAuthHandler {
-	return &GitHubOAuthHandler{cfg: cfg, db: d}
+// githubLinkScopes are requested when linking GitHub to an existing account:
+// - read:user: link identity
+// - user:email: access user email addresses
+// - repo: access private repos + read repo metadata
+// - admin:repo_hook: create webhooks
+// - read:org: helps when dealing with org-owned repos
+var githubLinkScopes = []string{"read:user", "user:email", "repo", "admin:repo_hook", "read:org"}
+
+func NewGitHubOAuthHandler(cfg config.Config, d *db.DB, githubProfiles *cache.Cache) *GitHubOAuthHandler {
+	return &GitHubOAuthHandler{cfg: cfg, db: d, githubProfiles: githubProfiles}
 }
 
 func (h *GitHubOAuthHandler) Start() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		if h.cfg.GitHubOAuthClientID == "" || effectiveGitHubRedirect(h.cfg) == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "github_oauth_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "github_oauth_not_configured")
 		}
 
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		state := randomState(32)
@@ -104,18 +80,12 @@ INSERT INTO oauth_states (state, user_id, kind, expires_at)
 VALUES ($1, $2, 'github_link', $3)
 `, state, userID, expiresAt)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_create_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "state_create_failed")
 		}
 
-		// Scopes:
-		// - read:user: link identity
-		// - user:email: access user email addresses
-		// - repo: access private repos + read repo metadata
-		// - admin:repo_hook: create webhooks
-		// - read:org: helps when dealing with org-owned repos
-		authURL, err := github.AuthorizeURL(h.cfg.GitHubOAuthClientID, effectiveGitHubRedirect(h.cfg), state, []string{"read:user", "user:email", "repo", "admin:repo_hook", "read:org"})
+		authURL, err := github.AuthorizeURL(h.cfg.GitHubOAuthClientID, effectiveGitHubRedirect(h.cfg), state, githubLinkScopes)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_url_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "auth_url_failed")
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"url": authURL})
@@ -128,35 +98,32 @@ VALUES ($1, $2, 'github_link', $3)
 func (h *GitHubOAuthHandler) LoginStart() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		if h.cfg.GitHubOAuthClientID == "" || effectiveGitHubRedirect(h.cfg) == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "github_login_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "github_login_not_configured")
 		}
 
 		// Get redirect_uri from query parameter (frontend origin)
 		redirectURI := c.Query("redirect")
-		slog.Info("OAuth login start - received redirect parameter", "redirect", redirectURI)
+		httpx.Logger(c).Info("OAuth login start - received redirect parameter", "redirect", redirectURI)
 
 		// Validate redirect_uri is a valid URL and from an allowed origin
 		if redirectURI != "" {
 			parsedURL, err := url.Parse(redirectURI)
 			if err != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_redirect_uri"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_redirect_uri")
 			}
 
 			// Security: Only allow redirects to whitelisted origins
 			// This prevents open redirect vulnerabilities
 			if !isAllowedRedirectURI(redirectURI, h.cfg) {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error":   "redirect_uri_not_allowed",
-					"message": "Redirect URI must be from an allowed origin (localhost, *.vercel.app, or configured CORS origins)",
-				})
+				return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "redirect_uri_not_allowed").WithMessage("Redirect URI must be from an allowed origin (localhost, *.vercel.app, or configured CORS origins)"))
 			}
 
 			// Ensure redirect URI uses http or https scheme
 			if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_redirect_uri_scheme"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_redirect_uri_scheme")
 			}
 		}
 
@@ -170,15 +137,15 @@ INSERT INTO oauth_states (state, user_id, kind, expires_at, redirect_uri)
 VALUES ($1, NULL, 'github_login', $2, $3)
 `, csrfToken, expiresAt, redirectURI)
 		if err != nil {
-			slog.Error("OAuth login start - failed to store state", "error", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_create_failed"})
+			httpx.Logger(c).Error("OAuth login start - failed to store state", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "state_create_failed")
 		}
 
 		// Encode redirect_uri in state parameter (OAuth 2.0 spec recommendation)
 		// Format: base64(csrf_token|redirect_uri)
 		// This allows dynamic redirection while maintaining CSRF protection
 		state := encodeStateWithRedirect(csrfToken, redirectURI)
-		slog.Info("OAuth login start - encoded state with redirect",
+		httpx.Logger(c).Info("OAuth login start - encoded state with redirect",
 			"csrf_token", csrfToken,
 			"redirect_uri", redirectURI,
 			"encoded_state", state,
@@ -187,7 +154,7 @@ VALUES ($1, NULL, 'github_login', $2, $3)
 		// Login scopes: identity + email + repo access for later project verification.
 		authURL, err := github.AuthorizeURL(h.cfg.GitHubOAuthClientID, effectiveGitHubRedirect(h.cfg), state, []string{"read:user", "user:email", "repo", "admin:repo_hook", "read:org"})
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_url_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "auth_url_failed")
 		}
 
 		// Redirect user to GitHub OAuth page
@@ -203,32 +170,32 @@ VALUES ($1, NULL, 'github_login', $2, $3)
 func (h *GitHubOAuthHandler) CallbackUnified() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		if h.cfg.GitHubOAuthClientID == "" || h.cfg.GitHubOAuthClientSecret == "" || effectiveGitHubRedirect(h.cfg) == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "github_oauth_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "github_oauth_not_configured")
 		}
 		if h.cfg.JWTSecret == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "jwt_not_configured")
 		}
 
 		code := c.Query("code")
 		encodedState := c.Query("state")
 		if code == "" || encodedState == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_code_or_state"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_code_or_state")
 		}
 
 		// Decode state parameter to extract CSRF token and redirect_uri (OAuth 2.0 spec)
 		csrfToken, redirectURIFromState, err := decodeStateWithRedirect(encodedState)
 		if err != nil {
-			slog.Error("OAuth callback - failed to decode state",
+			httpx.Logger(c).Error("OAuth callback - failed to decode state",
 				"error", err,
 				"encoded_state", encodedState,
 			)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_state_format"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_state_format")
 		}
 
-		slog.Info("OAuth callback - decoded state",
+		httpx.Logger(c).Info("OAuth callback - decoded state",
 			"csrf_token", csrfToken,
 			"redirect_uri_from_state", redirectURIFromState,
 			"encoded_state_length", len(encodedState),
@@ -245,19 +212,19 @@ WHERE state = $1
   AND expires_at > now()
 `, csrfToken).Scan(&storedKind, &stateUserID, &storedRedirectURI)
 		if errors.Is(err, pgx.ErrNoRows) {
-			slog.Warn("OAuth callback - state not found or expired",
+			httpx.Logger(c).Warn("OAuth callback - state not found or expired",
 				"csrf_token", csrfToken,
 				"encoded_state", encodedState,
 			)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_or_expired_state"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_or_expired_state")
 		}
 		if err != nil {
-			slog.Error("OAuth callback - database error during state lookup",
+			httpx.Logger(c).Error("OAuth callback - database error during state lookup",
 				"error", err,
 				"csrf_token", csrfToken,
 				"encoded_state", encodedState,
 			)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_lookup_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "state_lookup_failed")
 		}
 
 		// Use redirect_uri from state parameter (OAuth 2.0 spec), fallback to database if not in state
@@ -267,31 +234,28 @@ WHERE state = $1
 		if redirectURIFromState != "" {
 			// Security: Validate redirect_uri from state parameter against allowed origins
 			if !isAllowedRedirectURI(redirectURIFromState, h.cfg) {
-				slog.Warn("OAuth callback - redirect_uri from state not allowed, rejecting",
+				httpx.Logger(c).Warn("OAuth callback - redirect_uri from state not allowed, rejecting",
 					"redirect_uri", redirectURIFromState,
-					"allowed_origins", h.cfg.CORSOrigins,
+					"allowed_origins", h.cfg.CORS.Origins,
 					"frontend_base_url", h.cfg.FrontendBaseURL,
 				)
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error":   "redirect_uri_not_allowed",
-					"message": "Redirect URI from state parameter is not from an allowed origin",
-				})
+				return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "redirect_uri_not_allowed").WithMessage("Redirect URI from state parameter is not from an allowed origin"))
 			}
 			finalRedirectURI = redirectURIFromState
-			slog.Info("OAuth callback - using redirect_uri from state parameter",
+			httpx.Logger(c).Info("OAuth callback - using redirect_uri from state parameter",
 				"redirect_uri", finalRedirectURI,
 				"kind", storedKind,
 			)
 		} else if storedRedirectURI != nil && *storedRedirectURI != "" {
 			// Validate redirect_uri from database as well
 			if !isAllowedRedirectURI(*storedRedirectURI, h.cfg) {
-				slog.Warn("OAuth callback - redirect_uri from database not allowed, rejecting",
+				httpx.Logger(c).Warn("OAuth callback - redirect_uri from database not allowed, rejecting",
 					"redirect_uri", *storedRedirectURI,
 				)
 				// Don't reject, just log and fall through to config
 			} else {
 				finalRedirectURI = *storedRedirectURI
-				slog.Info("OAuth callback - using redirect_uri from database (fallback)",
+				httpx.Logger(c).Info("OAuth callback - using redirect_uri from database (fallback)",
 					"redirect_uri", finalRedirectURI,
 					"kind", storedKind,
 				)
@@ -299,7 +263,7 @@ WHERE state = $1
 		}
 
 		if finalRedirectURI == "" {
-			slog.Info("OAuth callback - no redirect_uri in state or database, will use config fallback",
+			httpx.Logger(c).Info("OAuth callback - no redirect_uri in state or database, will use config fallback",
 				"kind", storedKind,
 				"redirect_uri_from_state", redirectURIFromState,
 				"stored_redirect_uri", storedRedirectURI,
@@ -317,26 +281,26 @@ WHERE state = $1
 			RedirectURL:  effectiveGitHubRedirect(h.cfg),
 		})
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token_exchange_failed"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "token_exchange_failed")
 		}
 
-		encKey, err := cryptox.KeyFromB64(h.cfg.TokenEncKeyB64)
-		if err != nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "token_encryption_not_configured"})
+		encToken, keyVersion, err := github.SealToken(h.cfg.TokenEncKeyB64, tr.AccessToken)
+		if errors.Is(err, cryptox.ErrNoKeys) {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
 		}
-		encToken, err := cryptox.EncryptAESGCM(encKey, []byte(tr.AccessToken))
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_encrypt_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "token_encrypt_failed")
 		}
 
 		gh := github.NewClient()
 		u, err := gh.GetUser(c.Context(), tr.AccessToken)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "github_user_fetch_failed"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "github_user_fetch_failed")
 		}
 
 		var userID uuid.UUID
 		var role string
+		newlyLinked := storedKind == "github_link"
 		switch storedKind {
 		case "github_login":
 			// Create-or-find user by github_user_id.
@@ -350,50 +314,59 @@ WHERE github_user_id = $1
 INSERT INTO users (github_user_id) VALUES ($1)
 RETURNING id, role
 `, u.ID).Scan(&userID, &role)
+				newlyLinked = err == nil
 			}
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user_upsert_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "user_upsert_failed")
 			}
 		case "github_link":
 			if stateUserID == nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_state_user"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_state_user")
 			}
 			userID = *stateUserID
 			// Fetch role for JWT issuance.
 			if err := h.db.Pool.QueryRow(c.Context(), `SELECT role FROM users WHERE id = $1`, userID).Scan(&role); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user_lookup_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "user_lookup_failed")
 			}
 		default:
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "wrong_state_kind"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "wrong_state_kind")
 		}
 
-		_, err = h.db.Pool.Exec(c.Context(), `
-INSERT INTO github_accounts (user_id, github_user_id, login, avatar_url, access_token, token_type, scope)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-ON CONFLICT (user_id) DO UPDATE SET
-  github_user_id = EXCLUDED.github_user_id,
-  login = EXCLUDED.login,
-  avatar_url = EXCLUDED.avatar_url,
-  access_token = EXCLUDED.access_token,
-  token_type = EXCLUDED.token_type,
-  scope = EXCLUDED.scope,
-  updated_at = now()
-`, userID, u.ID, u.Login, u.AvatarURL, encToken, tr.TokenType, tr.Scope)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "github_account_upsert_failed"})
+		if err := h.upsertGitHubAccount(c.Context(), userID, u, encToken, keyVersion, tr); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_account_upsert_failed")
+		}
+		if newlyLinked {
+			recordAudit(c, h.db.Pool, &userID, audit.ActionGitHubLinked, map[string]any{
+				"github_user_id": u.ID,
+				"login":          u.Login,
+			})
 		}
-
-		// Ensure users.github_user_id is set (idempotent).
-		_, _ = h.db.Pool.Exec(c.Context(), `
-UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
-`, userID, u.ID)
 
 		// For login: issue JWT. For link: we can optionally redirect without token.
 		if storedKind == "github_login" {
-			jwtToken, err := auth.IssueJWT(h.cfg.JWTSecret, userID, role, "", "", 60*time.Minute)
+			if blocked, err := rejectRestrictedAccount(c, h.db.Pool, userID); blocked {
+				metrics.Login("github", false)
+				recordAudit(c, h.db.Pool, &userID, audit.ActionLoginFailed, map[string]any{
+					"method": "github",
+					"reason": "account_restricted",
+				})
+				return err
+			}
+			recordIPCountry(c, h.cfg, h.db.Pool, userID)
+			sessionID, err := auth.CreateSession(c.Context(), h.db.Pool, userID, "", "", c.IP(), c.Get(fiber.HeaderUserAgent), time.Now().UTC().Add(60*time.Minute))
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
+			}
+			jwtToken, err := auth.IssueJWT(h.cfg.JWTSecret, userID, sessionID, role, "", "", 60*time.Minute)
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "token_issue_failed")
 			}
+			metrics.Login("github", true)
+			recordAudit(c, h.db.Pool, &userID, audit.ActionLoginSucceeded, map[string]any{
+				"method":     "github",
+				"login":      u.Login,
+				"session_id": sessionID,
+			})
 
 			// Determine redirect URL priority (OAuth 2.0 spec: use state parameter):
 			// 1. redirect_uri from state parameter (OAuth 2.0 recommended approach) - ALWAYS PRIORITIZE
@@ -407,7 +380,7 @@ UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
 				// Use the redirect_uri from state parameter (OAuth 2.0 spec)
 				// This is the primary source and should always be used when available
 				redirectURL = strings.TrimSuffix(finalRedirectURI, "/") + "/auth/callback"
-				slog.Info("OAuth redirect - using redirect_uri from state parameter",
+				httpx.Logger(c).Info("OAuth redirect - using redirect_uri from state parameter",
 					"redirect_url", redirectURL,
 					"final_redirect_uri", finalRedirectURI,
 				)
@@ -425,14 +398,14 @@ UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
 					if !strings.HasSuffix(redirectURL, "/auth/callback") {
 						redirectURL = redirectURL + "/auth/callback"
 					}
-					slog.Warn("OAuth redirect - using GitHubLoginSuccessRedirectURL (fallback - redirect_uri from state was empty)",
+					httpx.Logger(c).Warn("OAuth redirect - using GitHubLoginSuccessRedirectURL (fallback - redirect_uri from state was empty)",
 						"redirect_url", redirectURL,
 						"redirect_uri_from_state", redirectURIFromState,
 						"stored_redirect_uri", storedRedirectURI,
 					)
 				} else if h.cfg.FrontendBaseURL != "" && !isLocalhost(h.cfg.FrontendBaseURL) {
 					redirectURL = strings.TrimSuffix(h.cfg.FrontendBaseURL, "/") + "/auth/callback"
-					slog.Warn("OAuth redirect - using FrontendBaseURL (fallback - redirect_uri from state was empty)",
+					httpx.Logger(c).Warn("OAuth redirect - using FrontendBaseURL (fallback - redirect_uri from state was empty)",
 						"redirect_url", redirectURL,
 						"frontend_base_url", h.cfg.FrontendBaseURL,
 						"redirect_uri_from_state", redirectURIFromState,
@@ -443,7 +416,7 @@ UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
 					// But log a warning that redirect_uri should have been provided
 					if h.cfg.FrontendBaseURL != "" {
 						redirectURL = strings.TrimSuffix(h.cfg.FrontendBaseURL, "/") + "/auth/callback"
-						slog.Error("OAuth redirect - WARNING: Using localhost fallback (redirect_uri from state was empty)",
+						httpx.Logger(c).Error("OAuth redirect - WARNING: Using localhost fallback (redirect_uri from state was empty)",
 							"redirect_url", redirectURL,
 							"redirect_uri_from_state", redirectURIFromState,
 							"stored_redirect_uri", storedRedirectURI,
@@ -451,7 +424,7 @@ UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
 							"message", "Frontend should always pass redirect parameter. This fallback should not be used in production.",
 						)
 					} else {
-						slog.Error("OAuth redirect - no redirect URL configured, cannot redirect user",
+						httpx.Logger(c).Error("OAuth redirect - no redirect URL configured, cannot redirect user",
 							"redirect_uri_from_state", redirectURIFromState,
 							"stored_redirect_uri", storedRedirectURI,
 							"github_login_success_redirect_url", h.cfg.GitHubLoginSuccessRedirectURL,
@@ -465,7 +438,7 @@ UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
 			if redirectURL != "" {
 				ru, err := url.Parse(redirectURL)
 				if err != nil {
-					slog.Error("OAuth redirect - failed to parse redirect URL", "error", err, "redirect_url", redirectURL)
+					httpx.Logger(c).Error("OAuth redirect - failed to parse redirect URL", "error", err, "redirect_url", redirectURL)
 					// Fall through to JSON response
 				} else {
 					// Ensure the path is set correctly (should be /auth/callback)
@@ -477,7 +450,7 @@ UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
 					q.Set("github", u.Login)
 					ru.RawQuery = q.Encode()
 					finalRedirectURL := ru.String()
-					slog.Info("OAuth redirect - redirecting user",
+					httpx.Logger(c).Info("OAuth redirect - redirecting user",
 						"final_redirect_url", finalRedirectURL,
 						"path", ru.Path,
 						"host", ru.Host,
@@ -545,13 +518,13 @@ func effectiveGitHubRedirect(cfg config.Config) string {
 func (h *GitHubOAuthHandler) Status() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		var githubUserID int64
@@ -568,7 +541,7 @@ WHERE user_id = $1
 			})
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "status_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "status_failed")
 		}
 
 		githubMap := fiber.Map{
@@ -585,6 +558,96 @@ WHERE user_id = $1
 	}
 }
 
+// Unlink disconnects the caller's GitHub account. Accounts without a linked
+// wallet keep it, since GitHub would be their only way to sign in.
+func (h *GitHubOAuthHandler) Unlink() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		tx, err := h.db.Pool.BeginTx(c.Context(), pgx.TxOptions{})
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_unlink_failed")
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		var hasWallet bool
+		if err := tx.QueryRow(c.Context(), `SELECT EXISTS (SELECT 1 FROM wallets WHERE user_id = $1)`, userID).Scan(&hasWallet); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_unlink_failed")
+		}
+		if !hasWallet {
+			return httpx.Fail(c, fiber.StatusConflict, "cannot_unlink_last_login_method")
+		}
+
+		var githubUserID int64
+		var login string
+		err = tx.QueryRow(c.Context(), `
+DELETE FROM github_accounts WHERE user_id = $1
+RETURNING github_user_id, login
+`, userID).Scan(&githubUserID, &login)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "github_not_linked")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_unlink_failed")
+		}
+		if _, err := tx.Exec(c.Context(), `UPDATE users SET github_user_id = NULL, updated_at = now() WHERE id = $1`, userID); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_unlink_failed")
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_unlink_failed")
+		}
+
+		recordAudit(c, h.db.Pool, &userID, audit.ActionGitHubUnlinked, map[string]any{
+			"github_user_id": githubUserID,
+			"login":          login,
+		})
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// upsertGitHubAccount stores the (encrypted) token for the user's GitHub
+// account, mirrors the GitHub id onto users and queues a profile sync.
+func (h *GitHubOAuthHandler) upsertGitHubAccount(ctx context.Context, userID uuid.UUID, u github.User, encToken []byte, keyVersion uint16, tr github.TokenResponse) error {
+	pool := h.db.Pool
+	_, err := pool.Exec(ctx, `
+INSERT INTO github_accounts (user_id, github_user_id, login, avatar_url, access_token, access_token_key_version, token_type, scope)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (user_id) DO UPDATE SET
+  github_user_id = EXCLUDED.github_user_id,
+  login = EXCLUDED.login,
+  avatar_url = EXCLUDED.avatar_url,
+  access_token = EXCLUDED.access_token,
+  access_token_key_version = EXCLUDED.access_token_key_version,
+  token_type = EXCLUDED.token_type,
+  scope = EXCLUDED.scope,
+  updated_at = now()
+`, userID, u.ID, u.Login, u.AvatarURL, encToken, int(keyVersion), tr.TokenType, tr.Scope)
+	if err != nil {
+		return err
+	}
+
+	// Ensure users.github_user_id is set (idempotent).
+	_, _ = pool.Exec(ctx, `
+UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
+`, userID, u.ID)
+
+	if err := profilesync.Enqueue(ctx, pool, userID); err != nil {
+		slog.Warn("failed to queue github profile sync", "error", err, "user_id", userID)
+	}
+	// Other instances hear of the change through cache_outbox; drop ours
+	// now so the client's next /auth/me sees the new account.
+	h.githubProfiles.Invalidate(userID.String())
+	return nil
+}
+
 func randomState(n int) string {
 	b := make([]byte, n)
 	_, _ = rand.Read(b)
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+// GitHubOrgsHandler links GitHub organizations, whose admins then manage
+// the organization's projects.
+type GitHubOrgsHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewGitHubOrgsHandler(cfg config.Config, d *db.DB) *GitHubOrgsHandler {
+	return &GitHubOrgsHandler{cfg: cfg, db: d}
+}
+
+func orgsError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, orgs.ErrNotFound):
+		return httpx.Fail(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, orgs.ErrNotOrganization):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, err.Error()).
+			WithMessage("the installation is on a personal account; install the app on the organization"))
+	case errors.Is(err, orgs.ErrNotOrgAdmin):
+		return httpx.Fail(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, orgs.ErrNoGitHubAccount):
+		return httpx.Fail(c, fiber.StatusBadRequest, err.Error())
+	}
+	return httpx.Write(c, httpx.New(fiber.StatusBadGateway, "github_org_sync_failed").Wrap(err))
+}
+
+// app returns the GitHub App client, or a response error when the app isn't
+// configured.
+func (h *GitHubOrgsHandler) app(c *fiber.Ctx) (*github.GitHubAppClient, error) {
+	if h.cfg.GitHubAppID == "" || h.cfg.GitHubAppPrivateKey == "" {
+		return nil, httpx.Fail(c, fiber.StatusServiceUnavailable, "github_app_not_configured")
+	}
+	app, err := github.NewGitHubAppClient(h.cfg.GitHubAppID, h.cfg.GitHubAppPrivateKey)
+	if err != nil {
+		return nil, httpx.Write(c, httpx.New(fiber.StatusServiceUnavailable, "github_app_not_configured").Wrap(err))
+	}
+	return app, nil
+}
+
+type linkGitHubOrgRequest struct {
+	// InstallationID is the GitHub App installation on the organization.
+	InstallationID string `json:"installation_id"`
+}
+
+// Link links the organization of a GitHub App installation. The caller must
+// be one of its admins on GitHub.
+func (h *GitHubOrgsHandler) Link() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req linkGitHubOrgRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if req.InstallationID == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "installation_id_required")
+		}
+		app, respErr := h.app(c)
+		if app == nil {
+			return respErr
+		}
+		o, err := orgs.Link(c.Context(), h.db.Pool, app, req.InstallationID, userID)
+		if err != nil {
+			return orgsError(c, err)
+		}
+		httpx.Logger(c).Info("github org linked",
+			"org", o.Login,
+			"installation_id", o.InstallationID,
+			"user_id", userID.String(),
+		)
+		return c.Status(fiber.StatusOK).JSON(o)
+	}
+}
+
+// Mine lists the linked organizations the caller is an admin of.
+func (h *GitHubOrgsHandler) Mine() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		out, err := orgs.ForUser(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "github_orgs_list_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"orgs": out})
+	}
+}
+
+// adminOrg loads the route's organization for one of its admins.
+func (h *GitHubOrgsHandler) adminOrg(c *fiber.Ctx) (orgs.Org, error) {
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return orgs.Org{}, httpx.New(fiber.StatusUnauthorized, "invalid_user")
+	}
+	orgID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return orgs.Org{}, httpx.New(fiber.StatusBadRequest, "invalid_org_id")
+	}
+	o, err := orgs.Get(c.Context(), h.db.Pool, orgID)
+	if errors.Is(err, orgs.ErrNotFound) {
+		return orgs.Org{}, httpx.New(fiber.StatusNotFound, "github_org_not_found")
+	}
+	if err != nil {
+		return orgs.Org{}, httpx.New(fiber.StatusInternalServerError, "github_org_lookup_failed").Wrap(err)
+	}
+	ok, err := orgs.IsAdmin(c.Context(), h.db.Pool, orgID, userID)
+	if err != nil {
+		return orgs.Org{}, httpx.New(fiber.StatusInternalServerError, "github_org_lookup_failed").Wrap(err)
+	}
+	if role, _ := c.Locals(auth.LocalRole).(string); !ok && role != "admin" {
+		return orgs.Org{}, httpx.New(fiber.StatusForbidden, "not_github_org_admin")
+	}
+	return o, nil
+}
+
+// Sync refreshes an organization's memberships from GitHub now.
+func (h *GitHubOrgsHandler) Sync() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		o, herr := h.adminOrg(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		app, respErr := h.app(c)
+		if app == nil {
+			return respErr
+		}
+		if err := orgs.SyncMembers(c.Context(), h.db.Pool, app, o); err != nil {
+			return orgsError(c, err)
+		}
+		o, err := orgs.Get(c.Context(), h.db.Pool, o.ID)
+		if err != nil {
+			return orgsError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(o)
+	}
+}
+
+// Unlink drops a linked organization; its projects stay with their owners.
+func (h *GitHubOrgsHandler) Unlink() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		o, herr := h.adminOrg(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		if err := orgs.Unlink(c.Context(), h.db.Pool, o.ID); err != nil {
+			return orgsError(c, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+func orgRolesError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, orgs.ErrRoleNotFound):
+		return httpx.Fail(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, orgs.ErrRoleExists):
+		return httpx.Fail(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, orgs.ErrInvalidRole):
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, orgs.ErrInvalidRole.Error()).WithMessage(err.Error()))
+	case errors.Is(err, orgs.ErrNotMember), errors.Is(err, orgs.ErrNotAssigned):
+		return httpx.Fail(c, fiber.StatusNotFound, err.Error())
+	}
+	return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "github_org_roles_failed").Wrap(err))
+}
+
+// roleParam parses the route's role id, or writes the error response.
+func roleParam(c *fiber.Ctx) (uuid.UUID, error) {
+	roleID, err := uuid.Parse(c.Params("role_id"))
+	if err != nil {
+		return uuid.Nil, httpx.Fail(c, fiber.StatusBadRequest, "invalid_role_id")
+	}
+	return roleID, nil
+}
+
+type orgRolesResponse struct {
+	Roles []orgs.Role `json:"roles"`
+	// Permissions are those a role can grant.
+	Permissions []orgs.Permission `json:"permissions"`
+}
+
+// Roles lists an organization's custom roles and who holds them.
+func (h *GitHubOrgsHandler) Roles() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		o, herr := h.adminOrg(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		roles, err := orgs.ListRoles(c.Context(), h.db.Pool, o.ID)
+		if err != nil {
+			return orgRolesError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(orgRolesResponse{Roles: roles, Permissions: orgs.Permissions})
+	}
+}
+
+// CreateRole defines a custom role in an organization.
+func (h *GitHubOrgsHandler) CreateRole() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		o, herr := h.adminOrg(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		var req orgs.RoleDraft
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, _ := uuid.Parse(sub)
+		r, err := orgs.CreateRole(c.Context(), h.db.Pool, o.ID, req, userID)
+		if err != nil {
+			return orgRolesError(c, err)
+		}
+		httpx.Logger(c).Info("github org role created",
+			"org", o.Login,
+			"role", r.Name,
+			"user_id", userID.String(),
+		)
+		return c.Status(fiber.StatusCreated).JSON(r)
+	}
+}
+
+// UpdateRole redefines a custom role; members keep it.
+func (h *GitHubOrgsHandler) UpdateRole() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		o, herr := h.adminOrg(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		roleID, respErr := roleParam(c)
+		if roleID == uuid.Nil {
+			return respErr
+		}
+		var req orgs.RoleDraft
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		r, err := orgs.UpdateRole(c.Context(), h.db.Pool, o.ID, roleID, req)
+		if err != nil {
+			return orgRolesError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(r)
+	}
+}
+
+// DeleteRole removes a custom role from everyone holding it.
+func (h *GitHubOrgsHandler) DeleteRole() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		o, herr := h.adminOrg(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		roleID, respErr := roleParam(c)
+		if roleID == uuid.Nil {
+			return respErr
+		}
+		if err := orgs.DeleteRole(c.Context(), h.db.Pool, o.ID, roleID); err != nil {
+			return orgRolesError(c, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+type assignOrgRoleRequest struct {
+	// Login is the member's GitHub login.
+	Login string `json:"login"`
+}
+
+// AssignRole gives a custom role to a member of the organization.
+func (h *GitHubOrgsHandler) AssignRole() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		o, herr := h.adminOrg(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		roleID, respErr := roleParam(c)
+		if roleID == uuid.Nil {
+			return respErr
+		}
+		var req assignOrgRoleRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if req.Login == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "login_required")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, _ := uuid.Parse(sub)
+		r, err := orgs.Assign(c.Context(), h.db.Pool, o.ID, roleID, req.Login, userID)
+		if err != nil {
+			return orgRolesError(c, err)
+		}
+		httpx.Logger(c).Info("github org role assigned",
+			"org", o.Login,
+			"role", r.Name,
+			"login", req.Login,
+			"user_id", userID.String(),
+		)
+		return c.Status(fiber.StatusOK).JSON(r)
+	}
+}
+
+// UnassignRole takes a custom role back from a GitHub user.
+func (h *GitHubOrgsHandler) UnassignRole() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		o, herr := h.adminOrg(c)
+		if herr != nil {
+			return httpx.Write(c, herr)
+		}
+		roleID, respErr := roleParam(c)
+		if roleID == uuid.Nil {
+			return respErr
+		}
+		ghUserID, err := strconv.ParseInt(c.Params("github_user_id"), 10, 64)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_github_user_id")
+		}
+		r, err := orgs.Unassign(c.Context(), h.db.Pool, o.ID, roleID, ghUserID)
+		if err != nil {
+			return orgRolesError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(r)
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// MyGitHubRepos lists the caller's GitHub repositories as last synced.
+func (h *AuthHandler) MyGitHubRepos() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT github_repo_id, full_name, html_url, description, language, private, fork, stars, forks, pushed_at, synced_at
+FROM github_user_repos
+WHERE user_id = $1
+ORDER BY pushed_at DESC NULLS LAST, full_name
+`, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_repos_list_failed")
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id int64
+			var fullName, htmlURL string
+			var description, language *string
+			var private, fork bool
+			var stars, forks int
+			var pushedAt *time.Time
+			var syncedAt time.Time
+			if err := rows.Scan(&id, &fullName, &htmlURL, &description, &language, &private, &fork, &stars, &forks, &pushedAt, &syncedAt); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "github_repos_list_failed")
+			}
+			out = append(out, fiber.Map{
+				"id":          id,
+				"full_name":   fullName,
+				"html_url":    htmlURL,
+				"description": description,
+				"language":    language,
+				"private":     private,
+				"fork":        fork,
+				"stars":       stars,
+				"forks":       forks,
+				"pushed_at":   pushedAt,
+				"synced_at":   syncedAt,
+			})
+		}
+		if rows.Err() != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_repos_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"repos": out})
+	}
+}
+
+// MyGitHubContributions returns the caller's last-year GitHub contribution
+// counts as last synced.
+func (h *AuthHandler) MyGitHubContributions() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		var from, to, syncedAt time.Time
+		var total, commits, prs, reviews, issues, restricted int
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT period_start, period_end, total, commits, pull_requests, pull_request_reviews, issues, restricted, synced_at
+FROM github_contribution_stats
+WHERE user_id = $1
+`, userID).Scan(&from, &to, &total, &commits, &prs, &reviews, &issues, &restricted, &syncedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "github_not_synced")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "github_contributions_lookup_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"from":                 from,
+			"to":                   to,
+			"total":                total,
+			"commits":              commits,
+			"pull_requests":        prs,
+			"pull_request_reviews": reviews,
+			"issues":               issues,
+			"restricted":           restricted,
+			"synced_at":            syncedAt,
+		})
+	}
+}
+
+// githubProfile is the linked account and its synced profile behind the
+// "github" block of /auth/me. Login is nil when no account is linked.
+type githubProfile struct {
+	Login            *string    `json:"login"`
+	AccountAvatarURL *string    `json:"account_avatar_url"`
+	Name             *string    `json:"name"`
+	Email            *string    `json:"email"`
+	AvatarURL        *string    `json:"avatar_url"`
+	Location         *string    `json:"location"`
+	Bio              *string    `json:"bio"`
+	Blog             *string    `json:"blog"`
+	SyncedAt         *time.Time `json:"synced_at"`
+}
+
+// githubProfile loads userID's GitHub data through the per-user cache,
+// which re-linking, refreshing and background syncs all invalidate.
+func (h *AuthHandler) githubProfile(ctx context.Context, userID uuid.UUID) githubProfile {
+	key := userID.String()
+	var gp githubProfile
+	if b, _, ok := h.githubProfiles.Get(key, ""); ok && json.Unmarshal(b, &gp) == nil {
+		return gp
+	}
+	gen := h.githubProfiles.Generation()
+	err := h.db.Pool.QueryRow(ctx, `
+SELECT ga.login, ga.avatar_url, gp.name, gp.email, gp.avatar_url, gp.location, gp.bio, gp.blog, gp.synced_at
+FROM github_accounts ga
+LEFT JOIN github_profiles gp ON gp.user_id = ga.user_id
+WHERE ga.user_id = $1
+`, userID).Scan(&gp.Login, &gp.AccountAvatarURL, &gp.Name, &gp.Email, &gp.AvatarURL, &gp.Location, &gp.Bio, &gp.Blog, &gp.SyncedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		slog.Warn("failed to fetch github profile", "error", err, "user_id", userID)
+		return githubProfile{}
+	}
+	// "Not linked" is cached too; linking invalidates it.
+	if b, err := json.Marshal(gp); err == nil {
+		h.githubProfiles.Set(gen, key, "", b, fiber.MIMEApplicationJSON)
+	}
+	return gp
+}
@@ -5,7 +5,6 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/json"
-	"log/slog"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,6 +13,8 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 	"github.com/jagadeesh/grainlify/backend/internal/ingest"
 )
 
@@ -27,7 +28,11 @@ type GitHubWebhooksHandler struct {
 func NewGitHubWebhooksHandler(cfg config.Config, d *db.DB, b bus.Bus) *GitHubWebhooksHandler {
 	var ingestor *ingest.GitHubWebhookIngestor
 	if d != nil && d.Pool != nil {
-		ingestor = &ingest.GitHubWebhookIngestor{Pool: d.Pool}
+		ingestor = &ingest.GitHubWebhookIngestor{Pool: d.Pool, Tokens: github.Tokens{
+			Pool:           d.Pool,
+			TokenEncKeyB64: cfg.TokenEncKeyB64,
+			Installations:  github.AppInstallations(cfg.GitHubAppID, cfg.GitHubAppPrivateKey),
+		}}
 	}
 	return &GitHubWebhooksHandler{cfg: cfg, db: d, bus: b, ing: ingestor}
 }
@@ -36,7 +41,7 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Handle CORS preflight requests
 		if c.Method() == "OPTIONS" {
-			slog.Info("GitHub webhook OPTIONS preflight request",
+			httpx.Logger(c).Info("GitHub webhook OPTIONS preflight request",
 				"path", c.Path(),
 				"remote_ip", c.IP(),
 			)
@@ -55,7 +60,7 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 		hookInstallationTargetType := strings.TrimSpace(c.Get("X-GitHub-Hook-Installation-Target-Type"))
 
 		// Detailed logging of incoming webhook request
-		slog.Info("=== GitHub Webhook POST Request Received ===",
+		httpx.Logger(c).Info("=== GitHub Webhook POST Request Received ===",
 			"method", c.Method(),
 			"path", c.Path(),
 			"original_url", c.OriginalURL(),
@@ -79,21 +84,21 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 		if len(bodyPreview) > 500 {
 			bodyPreview = bodyPreview[:500] + "... (truncated)"
 		}
-		slog.Info("GitHub webhook request body preview",
+		httpx.Logger(c).Info("GitHub webhook request body preview",
 			"delivery_id", delivery,
 			"body_preview", bodyPreview,
 			"body_size", bodySize,
 		)
 
 		if h.cfg.GitHubWebhookSecret == "" {
-			slog.Error("GitHub webhook secret not configured - rejecting request",
+			httpx.Logger(c).Error("GitHub webhook secret not configured - rejecting request",
 				"delivery_id", delivery,
 				"event", event,
 			)
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "webhook_secret_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "webhook_secret_not_configured")
 		}
 
-		slog.Info("GitHub webhook secret configured, proceeding with signature verification",
+		httpx.Logger(c).Info("GitHub webhook secret configured, proceeding with signature verification",
 			"delivery_id", delivery,
 			"event", event,
 		)
@@ -105,17 +110,17 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 		}
 
 		if !verifyGitHubSignature(h.cfg.GitHubWebhookSecret, body, sig) {
-			slog.Warn("GitHub webhook signature verification FAILED",
+			httpx.Logger(c).Warn("GitHub webhook signature verification FAILED",
 				"delivery_id", delivery,
 				"event", event,
 				"has_signature_256", sig != "",
 				"signature_256_preview", sigPreview,
 				"body_size", bodySize,
 			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_signature"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_signature")
 		}
 
-		slog.Info("GitHub webhook signature verification SUCCESS",
+		httpx.Logger(c).Info("GitHub webhook signature verification SUCCESS",
 			"delivery_id", delivery,
 			"event", event,
 		)
@@ -139,7 +144,7 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 			Payload:      body,
 		}
 
-		slog.Info("GitHub webhook event parsed",
+		httpx.Logger(c).Info("GitHub webhook event parsed",
 			"delivery_id", delivery,
 			"event", event,
 			"action", action,
@@ -148,31 +153,31 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 
 		// Preferred path: publish to NATS and return immediately (no heavy work in request path).
 		if h.bus != nil {
-			slog.Info("Publishing GitHub webhook to NATS event bus",
+			httpx.Logger(c).Info("Publishing GitHub webhook to NATS event bus",
 				"delivery_id", delivery,
 				"event", event,
 				"subject", events.SubjectGitHubWebhookReceived,
 			)
 			b, err := json.Marshal(ev)
 			if err != nil {
-				slog.Error("Failed to marshal webhook event for NATS",
+				httpx.Logger(c).Error("Failed to marshal webhook event for NATS",
 					"delivery_id", delivery,
 					"error", err,
 				)
 			} else {
 				if pubErr := h.bus.Publish(c.Context(), events.SubjectGitHubWebhookReceived, b); pubErr != nil {
-					slog.Error("Failed to publish webhook event to NATS",
+					httpx.Logger(c).Error("Failed to publish webhook event to NATS",
 						"delivery_id", delivery,
 						"error", pubErr,
 					)
 				} else {
-					slog.Info("Successfully published GitHub webhook to NATS",
+					httpx.Logger(c).Info("Successfully published GitHub webhook to NATS",
 						"delivery_id", delivery,
 						"event", event,
 					)
 				}
 			}
-			slog.Info("=== GitHub Webhook Request Completed (NATS) ===",
+			httpx.Logger(c).Info("=== GitHub Webhook Request Completed (NATS) ===",
 				"delivery_id", delivery,
 				"event", event,
 				"status", "200 OK",
@@ -182,30 +187,30 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 
 		// Fallback path (no NATS): ingest inline (still no external calls).
 		if h.ing != nil {
-			slog.Info("Processing GitHub webhook inline (no NATS configured)",
+			httpx.Logger(c).Info("Processing GitHub webhook inline (no NATS configured)",
 				"delivery_id", delivery,
 				"event", event,
 			)
 			if err := h.ing.Ingest(c.Context(), ev); err != nil {
-				slog.Error("Failed to ingest GitHub webhook",
+				httpx.Logger(c).Error("Failed to ingest GitHub webhook",
 					"delivery_id", delivery,
 					"event", event,
 					"error", err,
 				)
 			} else {
-				slog.Info("Successfully ingested GitHub webhook",
+				httpx.Logger(c).Info("Successfully ingested GitHub webhook",
 					"delivery_id", delivery,
 					"event", event,
 				)
 			}
 		} else {
-			slog.Warn("No webhook ingestor configured - webhook received but not processed",
+			httpx.Logger(c).Warn("No webhook ingestor configured - webhook received but not processed",
 				"delivery_id", delivery,
 				"event", event,
 			)
 		}
 
-		slog.Info("=== GitHub Webhook Request Completed (Inline) ===",
+		httpx.Logger(c).Info("=== GitHub Webhook Request Completed (Inline) ===",
 			"delivery_id", delivery,
 			"event", event,
 			"status", "200 OK",
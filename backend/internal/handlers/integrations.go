@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/apikeys"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// HeaderNextCursor carries the cursor to pass as `since` on the next poll.
+const HeaderNextCursor = "X-Next-Cursor"
+
+// IntegrationsHandler manages API keys and serves polling triggers shaped
+// for Zapier and Make: plain JSON arrays, newest first, with stable ids.
+type IntegrationsHandler struct {
+	db *db.DB
+}
+
+func NewIntegrationsHandler(d *db.DB) *IntegrationsHandler {
+	return &IntegrationsHandler{db: d}
+}
+
+func (h *IntegrationsHandler) ListKeys() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		keys, err := apikeys.List(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "api_keys_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"api_keys": keys, "available_scopes": apikeys.AllScopes})
+	}
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name"`
+	// Scopes defaults to every scope when empty.
+	Scopes []string `json:"scopes"`
+}
+
+// CreateKey issues a key; the plaintext is only returned here.
+func (h *IntegrationsHandler) CreateKey() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req createAPIKeyRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		key, raw, err := apikeys.Create(c.Context(), h.db.Pool, userID, req.Name, req.Scopes)
+		switch {
+		case errors.Is(err, apikeys.ErrMissingName):
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_name")
+		case errors.Is(err, apikeys.ErrInvalidScope):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_scope").With("available_scopes", apikeys.AllScopes))
+		case errors.Is(err, apikeys.ErrTooManyKeys):
+			return httpx.Fail(c, fiber.StatusConflict, "too_many_api_keys")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "api_key_create_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"api_key": key, "key": raw})
+	}
+}
+
+func (h *IntegrationsHandler) RevokeKey() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_api_key_id")
+		}
+		err = apikeys.Revoke(c.Context(), h.db.Pool, userID, id)
+		if errors.Is(err, apikeys.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "api_key_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "api_key_revoke_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// Me identifies the calling key; automation platforms use it to test a
+// connection and label it.
+func (h *IntegrationsHandler) Me() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key, _ := c.Locals(auth.LocalAPIKey).(auth.APIKey)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"user_id": key.UserID,
+			"key_id":  key.ID,
+			"name":    key.Name,
+			"scopes":  key.Scopes,
+		})
+	}
+}
+
+// BountyEvents is a polling trigger over bounty_events. Without project_id it
+// covers every project the key's owner owns; events on private and unlisted
+// bounties are only included if the owner may see them. The response is a bare array;
+// the cursor for the next poll is in X-Next-Cursor and on each item.
+func (h *IntegrationsHandler) BountyEvents() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		f := bounties.EventFilter{Limit: c.QueryInt("limit", bounties.MaxEventsPage), Viewer: userID}
+		if f.Since, err = bounties.ParseCursor(c.Query("since")); err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_cursor")
+		}
+		if f.Types, err = bounties.ParseEventTypes(c.Query("types")); err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_event_type").With("event_types", bounties.EventTypes))
+		}
+		if p := c.Query("project_id"); p != "" {
+			projectID, err := uuid.Parse(p)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+			}
+			f.ProjectID = &projectID
+		} else {
+			f.OwnerID = &userID
+		}
+
+		events, err := bounties.ListEvents(c.Context(), h.db.Pool, f)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "bounty_events_list_failed")
+		}
+		next := strconv.FormatInt(f.Since, 10)
+		if len(events) > 0 {
+			next = events[0].Cursor
+		}
+		c.Set(HeaderNextCursor, next)
+		return c.Status(fiber.StatusOK).JSON(events)
+	}
+}
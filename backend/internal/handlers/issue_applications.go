@@ -2,8 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
-	"log/slog"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -11,18 +12,26 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/fraud"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
 )
 
 const grainlifyApplicationPrefix = "[grainlify application]"
 
 type IssueApplicationsHandler struct {
-	cfg config.Config
-	db  *db.DB
+	cfg   config.Config
+	db    *db.DB
+	fraud *fraud.Engine
 }
 
 func NewIssueApplicationsHandler(cfg config.Config, d *db.DB) *IssueApplicationsHandler {
-	return &IssueApplicationsHandler{cfg: cfg, db: d}
+	var engine *fraud.Engine
+	if d != nil && d.Pool != nil {
+		engine = fraud.NewEngine(d.Pool)
+	}
+	return &IssueApplicationsHandler{cfg: cfg, db: d, fraud: engine}
 }
 
 type applyToIssueRequest struct {
@@ -32,42 +41,42 @@ type applyToIssueRequest struct {
 func (h *IssueApplicationsHandler) Apply() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		if strings.TrimSpace(h.cfg.TokenEncKeyB64) == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "token_encryption_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
 		}
 
 		projectID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 		}
 		issueNumber, err := c.ParamsInt("number")
 		if err != nil || issueNumber <= 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_issue_number"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_issue_number")
 		}
 
 		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(userIDStr)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		var req applyToIssueRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 		req.Message = strings.TrimSpace(req.Message)
 		if req.Message == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "message_required"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "message_required")
 		}
 		if len(req.Message) > 5000 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "message_too_long"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "message_too_long")
 		}
 
 		linked, err := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "github_not_linked"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "github_not_linked")
 		}
 
 		// Load repo + issue state from DB.
@@ -83,29 +92,50 @@ WHERE p.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
   AND gi.number = $2
 LIMIT 1
 `, projectID, issueNumber).Scan(&fullName, &state, &authorLogin, &assigneesJSON); err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "issue_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "issue_not_found")
 		}
 
 		if strings.ToLower(strings.TrimSpace(state)) != "open" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "issue_not_open"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "issue_not_open")
 		}
 		if strings.EqualFold(strings.TrimSpace(authorLogin), strings.TrimSpace(linked.Login)) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot_apply_to_own_issue"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "cannot_apply_to_own_issue")
 		}
 
 		// "yet to be assigned" => no assignees.
 		var assignees []any
 		_ = json.Unmarshal(assigneesJSON, &assignees)
 		if len(assignees) > 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "issue_already_assigned"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "issue_already_assigned")
 		}
 
-		// Create GitHub comment as the applicant (OAuth token).
 		commentBody := grainlifyApplicationPrefix + "\n\n" + req.Message
+
+		// Shadow-banned applicants get the normal response, but nothing reaches GitHub
+		// or the maintainer's view, so they can never be picked.
+		if banned, err := moderation.IsShadowBanned(c.Context(), h.db.Pool, userID); err == nil && banned {
+			createdAt, err := moderation.RecordShadowedContent(c.Context(), h.db.Pool, userID, "issue_application", projectID, issueNumber, commentBody)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusBadGateway, "github_comment_create_failed")
+			}
+			ts := createdAt.UTC().Format(time.RFC3339)
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{
+				"ok": true,
+				"comment": fiber.Map{
+					"id": createdAt.UnixNano(),
+					"body": commentBody,
+					"user": fiber.Map{"login": linked.Login},
+					"created_at": ts,
+					"updated_at": ts,
+				},
+			})
+		}
+
+		// Create GitHub comment as the applicant (OAuth token).
 		gh := github.NewClient()
 		ghComment, err := gh.CreateIssueComment(c.Context(), linked.AccessToken, fullName, issueNumber, commentBody)
 		if err != nil {
-			slog.Warn("failed to create github issue comment for application",
+			httpx.Logger(c).Warn("failed to create github issue comment for application",
 				"project_id", projectID.String(),
 				"issue_number", issueNumber,
 				"github_full_name", fullName,
@@ -113,7 +143,7 @@ LIMIT 1
 				"github_login", linked.Login,
 				"error", err,
 			)
-			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "github_comment_create_failed"})
+			return httpx.Fail(c, fiber.StatusBadGateway, "github_comment_create_failed")
 		}
 
 		// Persist the comment into our DB so maintainers see it immediately.
@@ -127,6 +157,21 @@ SET comments = COALESCE(comments, '[]'::jsonb) || $3::jsonb,
 WHERE project_id = $1 AND number = $2
 `, projectID, issueNumber, commentJSON, ghComment.UpdatedAt)
 
+		// Fraud rules never block the claim; matches land in the admin review queue.
+		if _, err := h.fraud.Evaluate(c.Context(), fraud.Subject{
+			Event:     fraud.EventClaim,
+			UserID:    userID,
+			SubjectID: fmt.Sprintf("%s#%d", projectID, issueNumber),
+			IP:        c.IP(),
+		}); err != nil {
+			httpx.Logger(c).Warn("fraud evaluation failed for application",
+				"project_id", projectID.String(),
+				"issue_number", issueNumber,
+				"user_id", userID.String(),
+				"error", err,
+			)
+		}
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"ok": true,
 			"comment": fiber.Map{
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/issues"
+)
+
+// IssueProvidersHandler links Jira/Linear accounts over OAuth and receives
+// their issue webhooks.
+type IssueProvidersHandler struct {
+	cfg       config.Config
+	db        *db.DB
+	providers map[string]issues.Provider
+}
+
+func NewIssueProvidersHandler(cfg config.Config, d *db.DB) *IssueProvidersHandler {
+	return &IssueProvidersHandler{cfg: cfg, db: d, providers: issues.Providers(cfg)}
+}
+
+func issueProviderError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, issues.ErrUnknownProvider):
+		return httpx.Fail(c, fiber.StatusNotFound, "unknown_issue_provider")
+	case errors.Is(err, issues.ErrProviderDisabled):
+		return httpx.Fail(c, fiber.StatusServiceUnavailable, "issue_provider_not_configured")
+	case errors.Is(err, issues.ErrNotLinked):
+		return httpx.Fail(c, fiber.StatusNotFound, "issue_provider_not_linked")
+	}
+	return httpx.Fail(c, fiber.StatusInternalServerError, "issue_provider_failed")
+}
+
+func (h *IssueProvidersHandler) webhookURL(a issues.Account) string {
+	return strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/webhooks/issues/" + a.Provider + "/" + a.ID.String()
+}
+
+// Start returns the provider's OAuth consent URL.
+func (h *IssueProvidersHandler) Start() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		p, err := issues.Lookup(h.providers, c.Params("provider"))
+		if err != nil {
+			return issueProviderError(c, err)
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		state := randomState(32)
+		_, err = h.db.Pool.Exec(c.Context(), `
+INSERT INTO oauth_states (state, user_id, kind, expires_at)
+VALUES ($1, $2, $3, $4)
+`, state, userID, p.Name()+"_link", time.Now().UTC().Add(10*time.Minute))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "state_create_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"url": p.AuthorizeURL(state)})
+	}
+}
+
+// Callback completes the OAuth flow and stores the link, then redirects to
+// the frontend when one is configured.
+func (h *IssueProvidersHandler) Callback() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if strings.TrimSpace(h.cfg.TokenEncKeyB64) == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
+		}
+		p, err := issues.Lookup(h.providers, c.Params("provider"))
+		if err != nil {
+			return issueProviderError(c, err)
+		}
+		code := strings.TrimSpace(c.Query("code"))
+		state := strings.TrimSpace(c.Query("state"))
+		if code == "" || state == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_code_or_state")
+		}
+
+		var userID uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `
+DELETE FROM oauth_states
+WHERE state = $1 AND kind = $2 AND expires_at > now()
+RETURNING user_id
+`, state, p.Name()+"_link").Scan(&userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_or_expired_state")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "state_lookup_failed")
+		}
+
+		token, err := p.Exchange(c.Context(), code)
+		if err != nil {
+			httpx.Logger(c).Warn("issue provider token exchange failed", "provider", p.Name(), "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "token_exchange_failed")
+		}
+		account, err := issues.SaveAccount(c.Context(), h.db.Pool, userID, p.Name(), token, h.cfg.TokenEncKeyB64)
+		if err != nil {
+			httpx.Logger(c).Error("failed to save issue provider account", "provider", p.Name(), "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "account_save_failed")
+		}
+
+		if h.cfg.FrontendBaseURL != "" {
+			q := url.Values{"issue_provider": {p.Name()}, "status": {"linked"}}
+			return c.Redirect(strings.TrimRight(h.cfg.FrontendBaseURL, "/")+"/settings?"+q.Encode(), fiber.StatusFound)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"account": account})
+	}
+}
+
+func (h *IssueProvidersHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		accounts, err := issues.ListAccounts(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "issue_providers_list_failed")
+		}
+		out := make([]fiber.Map, 0, len(accounts))
+		for _, a := range accounts {
+			out = append(out, fiber.Map{"account": a, "webhook_url": h.webhookURL(a)})
+		}
+		available := make([]string, 0, len(h.providers)+1)
+		available = append(available, issues.ProviderGitHub)
+		for _, name := range []string{issues.ProviderJira, issues.ProviderLinear} {
+			if _, ok := h.providers[name]; ok {
+				available = append(available, name)
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"accounts": out, "available_providers": available})
+	}
+}
+
+func (h *IssueProvidersHandler) Unlink() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		if err := issues.DeleteAccount(c.Context(), h.db.Pool, userID, strings.ToLower(c.Params("provider"))); err != nil {
+			return issueProviderError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// RotateWebhookSecret returns a new signing secret to paste into the
+// tracker's webhook settings along with the webhook URL.
+func (h *IssueProvidersHandler) RotateWebhookSecret() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		account, secret, err := issues.RotateWebhookSecret(c.Context(), h.db.Pool, userID, strings.ToLower(c.Params("provider")), h.cfg.TokenEncKeyB64)
+		if err != nil {
+			return issueProviderError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"account":        account,
+			"webhook_url":    h.webhookURL(account),
+			"webhook_secret": secret,
+		})
+	}
+}
+
+// Webhook receives issue events from a linked Jira site or Linear workspace.
+func (h *IssueProvidersHandler) Webhook() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		p, err := issues.Lookup(h.providers, c.Params("provider"))
+		if err != nil {
+			return issueProviderError(c, err)
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusNotFound, "issue_provider_not_linked")
+		}
+		account, secret, err := issues.WebhookAccount(c.Context(), h.db.Pool, id, p.Name(), h.cfg.TokenEncKeyB64)
+		if errors.Is(err, issues.ErrWebhookNotConfigured) {
+			return httpx.Fail(c, fiber.StatusPreconditionFailed, "webhook_secret_not_configured")
+		}
+		if err != nil {
+			return issueProviderError(c, err)
+		}
+
+		header := http.Header{}
+		c.Request().Header.VisitAll(func(k, v []byte) {
+			header.Add(string(k), string(v))
+		})
+		updates, err := p.ParseWebhook(account, header, c.Body(), secret)
+		if errors.Is(err, issues.ErrBadSignature) {
+			httpx.Logger(c).Warn("issue provider webhook rejected", "provider", p.Name(), "account_id", id.String(), "remote_ip", c.IP())
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_signature")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_payload")
+		}
+
+		updated := int64(0)
+		for _, iss := range updates {
+			n, err := issues.ApplyUpdate(c.Context(), h.db.Pool, iss, &account.ID)
+			if err != nil {
+				// Fail the delivery so the tracker retries; updates are idempotent.
+				httpx.Logger(c).Error("issue provider webhook apply failed", "provider", p.Name(), "issue", iss.Key, "error", err)
+				return httpx.Fail(c, fiber.StatusInternalServerError, "apply_failed")
+			}
+			updated += n
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"bounties_updated": updated})
+	}
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/jobs"
+)
+
+// JobsHealth reports the background jobs of this process; it answers 503
+// when any job has stopped checking in.
+func JobsHealth(s *jobs.Scheduler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if s == nil {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "jobs": []jobs.Status{}})
+		}
+		now := time.Now()
+		code := fiber.StatusOK
+		ok := s.Healthy(now)
+		if !ok {
+			code = fiber.StatusServiceUnavailable
+		}
+		return c.Status(code).JSON(fiber.Map{"ok": ok, "jobs": s.Statuses(now)})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/didit"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
 // extractKYCInfo extracts structured information from Didit response data
@@ -116,19 +117,19 @@ func NewKYCHandler(cfg config.Config, d *db.DB) *KYCHandler {
 func (h *KYCHandler) Start() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		if h.didit == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "kyc_not_configured", "message": "DIDIT_API_KEY and DIDIT_WORKFLOW_ID must be set"})
+			return httpx.Write(c, httpx.New(fiber.StatusServiceUnavailable, "kyc_not_configured").WithMessage("DIDIT_API_KEY and DIDIT_WORKFLOW_ID must be set"))
 		}
 		if h.cfg.DiditWorkflowID == "" {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "kyc_not_configured", "message": "DIDIT_WORKFLOW_ID must be set"})
+			return httpx.Write(c, httpx.New(fiber.StatusServiceUnavailable, "kyc_not_configured").WithMessage("DIDIT_WORKFLOW_ID must be set"))
 		}
 
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		// Check if user already has an active KYC session
@@ -140,7 +141,7 @@ FROM users
 WHERE id = $1
 `, userID).Scan(&existingSessionID, &existingStatus)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user_lookup_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "user_lookup_failed")
 		}
 
 		// Only allow new session if:
@@ -195,20 +196,18 @@ SET kyc_status = 'expired',
     updated_at = now()
 WHERE id = $1
 `, userID)
-						slog.Info("session deleted in didit dashboard, marked as expired", "session_id", *existingSessionID, "user_id", userID)
+						httpx.Logger(c).Info("session deleted in didit dashboard, marked as expired", "session_id", *existingSessionID, "user_id", userID)
 						// Continue to create new session
 					} else {
 						// Session exists in Didit - don't allow new session, but return URL if we have it
-						response := fiber.Map{
-							"error":      "kyc_session_exists",
-							"message":    fmt.Sprintf("You already have a KYC verification session (status: %s). Please complete it or contact admin to delete it.", *existingStatus),
-							"session_id": *existingSessionID,
-							"status":     *existingStatus,
-						}
+						herr := httpx.New(fiber.StatusConflict, "kyc_session_exists").
+							WithMessage(fmt.Sprintf("You already have a KYC verification session (status: %s). Please complete it or contact admin to delete it.", *existingStatus)).
+							With("session_id", *existingSessionID).
+							With("status", *existingStatus)
 						if sessionURL != "" {
-							response["url"] = sessionURL
+							herr = herr.With("url", sessionURL)
 						}
-						return c.Status(fiber.StatusConflict).JSON(response)
+						return httpx.Write(c, herr)
 					}
 				} else {
 					// Session exists in Didit - extract session_url from response if available
@@ -218,31 +217,27 @@ WHERE id = $1
 						}
 					}
 					// Don't allow new session
-					response := fiber.Map{
-						"error":      "kyc_session_exists",
-						"message":    fmt.Sprintf("You already have an active KYC verification session (status: %s). Please complete it or contact admin to delete it.", *existingStatus),
-						"session_id": *existingSessionID,
-						"status":     *existingStatus,
-					}
+					herr := httpx.New(fiber.StatusConflict, "kyc_session_exists").
+						WithMessage(fmt.Sprintf("You already have an active KYC verification session (status: %s). Please complete it or contact admin to delete it.", *existingStatus)).
+						With("session_id", *existingSessionID).
+						With("status", *existingStatus)
 					if sessionURL != "" {
-						response["url"] = sessionURL
+						herr = herr.With("url", sessionURL)
 					}
-					return c.Status(fiber.StatusConflict).JSON(response)
+					return httpx.Write(c, herr)
 				}
 			} else {
 				// No Didit client - check status directly
 				// Only allow new session if status is expired (session was deleted)
 				if *existingStatus != "expired" {
-					response := fiber.Map{
-						"error":      "kyc_session_exists",
-						"message":    fmt.Sprintf("You already have a KYC verification session (status: %s). Please complete it or contact admin to delete it.", *existingStatus),
-						"session_id": *existingSessionID,
-						"status":     *existingStatus,
-					}
+					herr := httpx.New(fiber.StatusConflict, "kyc_session_exists").
+						WithMessage(fmt.Sprintf("You already have a KYC verification session (status: %s). Please complete it or contact admin to delete it.", *existingStatus)).
+						With("session_id", *existingSessionID).
+						With("status", *existingStatus)
 					if sessionURL != "" {
-						response["url"] = sessionURL
+						herr = herr.With("url", sessionURL)
 					}
-					return c.Status(fiber.StatusConflict).JSON(response)
+					return httpx.Write(c, herr)
 				}
 			}
 		}
@@ -260,20 +255,17 @@ WHERE id = $1
 		}
 
 		// Create Didit session
-		slog.Info("creating didit session", "user_id", userID, "workflow_id", h.cfg.DiditWorkflowID, "callback", callbackURL)
+		httpx.Logger(c).Info("creating didit session", "user_id", userID, "workflow_id", h.cfg.DiditWorkflowID, "callback", callbackURL)
 		sessionResp, err := h.didit.CreateSession(c.Context(), didit.CreateSessionRequest{
 			WorkflowID: h.cfg.DiditWorkflowID,
 			VendorData: userID.String(),
 			Callback:   callbackURL,
 		})
 		if err != nil {
-			slog.Error("didit create session failed", "error", err, "user_id", userID, "workflow_id", h.cfg.DiditWorkflowID)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "kyc_session_create_failed",
-				"message": err.Error(),
-			})
+			httpx.Logger(c).Error("didit create session failed", "error", err, "user_id", userID, "workflow_id", h.cfg.DiditWorkflowID)
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "kyc_session_create_failed").Wrap(err))
 		}
-		slog.Info("didit session created", "session_id", sessionResp.SessionID, "url", sessionResp.URL, "user_id", userID)
+		httpx.Logger(c).Info("didit session created", "session_id", sessionResp.SessionID, "url", sessionResp.URL, "user_id", userID)
 
 		// Store session ID and URL in database (replaces any existing session)
 		// Store the URL in kyc_data so we can retrieve it later
@@ -283,7 +275,7 @@ WHERE id = $1
 			"session_url": sessionResp.URL,
 		})
 
-		slog.Info("storing kyc session in database", "user_id", userID, "session_id", sessionResp.SessionID, "status", "not_started")
+		httpx.Logger(c).Info("storing kyc session in database", "user_id", userID, "session_id", sessionResp.SessionID, "status", "not_started")
 		result, err := h.db.Pool.Exec(c.Context(), `
 UPDATE users
 SET kyc_session_id = $1,
@@ -293,20 +285,17 @@ SET kyc_session_id = $1,
 WHERE id = $3
 `, sessionResp.SessionID, sessionDataJSON, userID)
 		if err != nil {
-			slog.Error("failed to store kyc session in database",
+			httpx.Logger(c).Error("failed to store kyc session in database",
 				"error", err,
 				"user_id", userID,
 				"session_id", sessionResp.SessionID,
 				"kyc_data_size", len(sessionDataJSON),
 				"error_type", fmt.Sprintf("%T", err))
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "kyc_session_store_failed",
-				"message": err.Error(),
-			})
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "kyc_session_store_failed").Wrap(err))
 		}
 
 		rowsAffected := result.RowsAffected()
-		slog.Info("stored new kyc session", "user_id", userID, "session_id", sessionResp.SessionID, "rows_affected", rowsAffected)
+		httpx.Logger(c).Info("stored new kyc session", "user_id", userID, "session_id", sessionResp.SessionID, "rows_affected", rowsAffected)
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"session_id": sessionResp.SessionID,
@@ -319,26 +308,26 @@ WHERE id = $3
 // If status is pending and we have a session_id, fetches latest status from Didit API
 func (h *KYCHandler) Status() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		slog.Info("kyc status request started", "path", c.Path(), "method", c.Method())
+		httpx.Logger(c).Info("kyc status request started", "path", c.Path(), "method", c.Method())
 
 		if h.db == nil || h.db.Pool == nil {
-			slog.Error("db not configured in kyc status handler")
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			httpx.Logger(c).Error("db not configured in kyc status handler")
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		if sub == "" {
-			slog.Error("no user id in context")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			httpx.Logger(c).Error("no user id in context")
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			slog.Error("failed to parse user id", "sub", sub, "error", err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			httpx.Logger(c).Error("failed to parse user id", "sub", sub, "error", err)
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
-		slog.Info("fetching kyc status from database", "user_id", userID)
+		httpx.Logger(c).Info("fetching kyc status from database", "user_id", userID)
 
 		var kycStatus *string
 		var kycSessionID *string
@@ -351,11 +340,8 @@ FROM users
 WHERE id = $1
 `, userID).Scan(&kycStatus, &kycSessionID, &kycVerifiedAt, &kycData)
 		if err != nil {
-			slog.Error("failed to fetch kyc status from database", "user_id", userID, "error", err, "error_type", fmt.Sprintf("%T", err))
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "kyc_status_fetch_failed",
-				"message": err.Error(),
-			})
+			httpx.Logger(c).Error("failed to fetch kyc status from database", "user_id", userID, "error", err, "error_type", fmt.Sprintf("%T", err))
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "kyc_status_fetch_failed").Wrap(err))
 		}
 
 		// Log actual values, not pointers
@@ -372,7 +358,7 @@ WHERE id = $1
 			verifiedAtLogStr = kycVerifiedAt.Format(time.RFC3339)
 		}
 
-		slog.Info("fetched kyc status from database",
+		httpx.Logger(c).Info("fetched kyc status from database",
 			"user_id", userID,
 			"kyc_status", statusStr,
 			"kyc_session_id", sessionIDStr,
@@ -387,7 +373,7 @@ WHERE id = $1
 			if kycStatus != nil {
 				currentStatusStr = *kycStatus
 			}
-			slog.Info("checking session with didit api", "session_id", *kycSessionID, "current_status", currentStatusStr)
+			httpx.Logger(c).Info("checking session with didit api", "session_id", *kycSessionID, "current_status", currentStatusStr)
 			// Always fetch to check if session still exists (especially for pending status)
 			decision, err := h.didit.GetSessionDecision(c.Context(), *kycSessionID)
 			if err != nil {
@@ -397,7 +383,7 @@ WHERE id = $1
 				if kycStatus != nil {
 					currentStatusStr = *kycStatus
 				}
-				slog.Warn("didit api call failed",
+				httpx.Logger(c).Warn("didit api call failed",
 					"session_id", *kycSessionID,
 					"error", err.Error(),
 					"current_status", currentStatusStr,
@@ -423,7 +409,7 @@ WHERE id = $1
 					if kycStatus != nil {
 						previousStatusStr = *kycStatus
 					}
-					slog.Info("session deleted in didit - marking as expired",
+					httpx.Logger(c).Info("session deleted in didit - marking as expired",
 						"session_id", *kycSessionID,
 						"user_id", userID,
 						"previous_status", previousStatusStr)
@@ -439,7 +425,7 @@ SET kyc_status = $1,
 WHERE id = $2
 `, expiredStatus, userID)
 					if updateErr != nil {
-						slog.Error("failed to mark session as expired in database",
+						httpx.Logger(c).Error("failed to mark session as expired in database",
 							"error", updateErr,
 							"user_id", userID,
 							"session_id", deletedSessionID,
@@ -452,7 +438,7 @@ WHERE id = $2
 						if kycStatus != nil {
 							previousStatusStr = *kycStatus
 						}
-						slog.Info("marked session as expired - deleted in didit dashboard",
+						httpx.Logger(c).Info("marked session as expired - deleted in didit dashboard",
 							"session_id", deletedSessionID,
 							"user_id", userID,
 							"previous_status", previousStatusStr,
@@ -464,7 +450,7 @@ WHERE id = $2
 					if kycStatus != nil {
 						currentStatusStr = *kycStatus
 					}
-					slog.Warn("didit api error but session may still exist",
+					httpx.Logger(c).Warn("didit api error but session may still exist",
 						"session_id", *kycSessionID,
 						"error", err.Error(),
 						"current_status", currentStatusStr)
@@ -481,7 +467,7 @@ WHERE id = $2
 				if kycStatus != nil {
 					currentStatusStr = *kycStatus
 				}
-				slog.Info("fetched didit status",
+				httpx.Logger(c).Info("fetched didit status",
 					"session_id", *kycSessionID,
 					"didit_status", decision.Status,
 					"mapped_status", newStatus,
@@ -525,13 +511,13 @@ SET kyc_status = $1,
 WHERE id = $3
 `, newStatus, decisionJSON, userID)
 					if updateErr != nil {
-						slog.Error("failed to update kyc status", "error", updateErr, "user_id", userID, "old_status", oldStatusStr, "new_status", newStatus)
+						httpx.Logger(c).Error("failed to update kyc status", "error", updateErr, "user_id", userID, "old_status", oldStatusStr, "new_status", newStatus)
 					} else {
 						kycStatus = &newStatus
 						// Update kycData with latest decision data
 						kycData = decisionJSON
 						if statusChanged {
-							slog.Info("kyc status changed", "user_id", userID, "old_status", oldStatusStr, "new_status", newStatus, "didit_status", decision.Status)
+							httpx.Logger(c).Info("kyc status changed", "user_id", userID, "old_status", oldStatusStr, "new_status", newStatus, "didit_status", decision.Status)
 						}
 					}
 				} else {
@@ -670,7 +656,7 @@ WHERE id = $2
 			responseVerifiedAtLogStr = *verifiedAtStr
 		}
 
-		slog.Info("returning kyc status response",
+		httpx.Logger(c).Info("returning kyc status response",
 			"user_id", userID,
 			"status", responseStatusStr,
 			"session_id", responseSessionIDStr,
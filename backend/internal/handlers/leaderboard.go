@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
-	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 
+	"github.com/jagadeesh/grainlify/backend/internal/commitsig"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/leaderboard"
 )
 
 type LeaderboardHandler struct {
@@ -17,11 +21,17 @@ func NewLeaderboardHandler(d *db.DB) *LeaderboardHandler {
 	return &LeaderboardHandler{db: d}
 }
 
-// Leaderboard returns top contributors ranked by contributions in verified projects
+// Leaderboard returns top contributors ranked by contributions in verified projects.
+// With ?window=week|month|all it serves the precomputed ranking of merged
+// pull requests and completed bounties instead (see Windowed).
 func (h *LeaderboardHandler) Leaderboard() fiber.Handler {
+	windowed := h.Windowed()
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if c.Query("window") != "" {
+			return windowed(c)
 		}
 
 		// Get limit and offset from query params (default 10, max 100)
@@ -97,7 +107,17 @@ SELECT
       WHERE e.status = 'active'
     ),
     ARRAY[]::TEXT[]
-  ) as ecosystems
+  ) as ecosystems,
+  (
+    SELECT COUNT(*)
+    FROM github_commits gc
+    WHERE LOWER(gc.author_login) = LOWER(ac.login) AND gc.signature_status = 'verified'
+  ) as signed_commits,
+  (
+    SELECT COUNT(*)
+    FROM github_commits gc
+    WHERE LOWER(gc.author_login) = LOWER(ac.login) AND gc.signature_status <> 'unavailable'
+  ) as checked_commits
 FROM all_contributors ac
 LEFT JOIN github_accounts ga ON LOWER(ga.login) = LOWER(ac.login)
 LEFT JOIN users u ON ga.user_id = u.id
@@ -117,10 +137,10 @@ ORDER BY contribution_count DESC, ac.login ASC
 LIMIT $1 OFFSET $2
 `, limit, offset)
 		if err != nil {
-			slog.Error("failed to fetch leaderboard",
+			httpx.Logger(c).Error("failed to fetch leaderboard",
 				"error", err,
 			)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_fetch_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "leaderboard_fetch_failed")
 		}
 		defer rows.Close()
 
@@ -132,9 +152,10 @@ LIMIT $1 OFFSET $2
 			var userID string
 			var contributionCount int
 			var ecosystems []string
+			var signedCommits, checkedCommits int
 
-			if err := rows.Scan(&username, &avatarURL, &userID, &contributionCount, &ecosystems); err != nil {
-				slog.Error("failed to scan leaderboard row",
+			if err := rows.Scan(&username, &avatarURL, &userID, &contributionCount, &ecosystems, &signedCommits, &checkedCommits); err != nil {
+				httpx.Logger(c).Error("failed to scan leaderboard row",
 					"error", err,
 				)
 				continue
@@ -166,6 +187,10 @@ LIMIT $1 OFFSET $2
 				"user_id":        userID,
 				"contributions":  contributionCount,
 				"ecosystems":     ecosystems,
+				// Trust in the score grows with the share of the contributor's
+				// commits signed with their own key.
+				"signed_commits": signedCommits,
+				"trust_level":    commitsig.TrustLevel(signedCommits, checkedCommits),
 				// For now, set trend to 'same' and score to contribution count
 				// These can be enhanced later with historical data
 				"score":      contributionCount,
@@ -183,3 +208,81 @@ LIMIT $1 OFFSET $2
 		return c.Status(fiber.StatusOK).JSON(leaderboard)
 	}
 }
+
+// Windowed serves a page of the contributor ranking the leaderboard job
+// computed for ?window (all-time by default). Entries carry the display
+// fields of the live leaderboard as well.
+func (h *LeaderboardHandler) Windowed() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		window, err := leaderboard.ParseWindow(c.Query("window"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_window")
+		}
+		limit := c.QueryInt("limit", 10)
+		if limit < 1 {
+			limit = 10
+		}
+		if limit > 100 {
+			limit = 100
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+		entries, err := leaderboard.List(c.Context(), h.db.Pool, window, limit, offset)
+		if err != nil {
+			httpx.Logger(c).Error("failed to fetch leaderboard", "window", window, "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "leaderboard_fetch_failed")
+		}
+		out := make([]fiber.Map, 0, len(entries))
+		for _, e := range entries {
+			tier := GetRankTier(e.Rank)
+			userID := ""
+			if e.UserID != nil {
+				userID = e.UserID.String()
+			}
+			out = append(out, fiber.Map{
+				"rank":               e.Rank,
+				"rank_tier":          string(tier),
+				"rank_tier_name":     GetRankTierDisplayName(tier),
+				"username":           e.Login,
+				"avatar":             fmt.Sprintf("https://github.com/%s.png?size=200", e.Login),
+				"user_id":            userID,
+				"window":             e.Window,
+				"merged_prs":         e.MergedPRs,
+				"completed_bounties": e.CompletedBounties,
+				"projects":           e.Projects,
+				"earnings":           e.Earnings,
+				"score":              e.Score,
+				"computed_at":        e.ComputedAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+// UserStats returns a user's merged pull requests, completed bounties and
+// earnings for every window, with their rank in each.
+func (h *LeaderboardHandler) UserStats() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		userID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		st, err := leaderboard.ForUser(c.Context(), h.db.Pool, userID)
+		if errors.Is(err, leaderboard.ErrUserNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+		}
+		if err != nil {
+			httpx.Logger(c).Error("failed to fetch user stats", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "user_stats_fetch_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(st)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+)
+
+type LedgerHandler struct {
+	db *db.DB
+}
+
+func NewLedgerHandler(d *db.DB) *LedgerHandler {
+	return &LedgerHandler{db: d}
+}
+
+// Anchors lists the published, signed ledger roots (public).
+func (h *LedgerHandler) Anchors() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		limit := c.QueryInt("limit", 20)
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+		anchors, err := ledger.ListAnchors(c.Context(), h.db.Pool, limit)
+		if err != nil {
+			httpx.Logger(c).Error("failed to list ledger anchors", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "anchors_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"anchors": anchors})
+	}
+}
+
+// MyProofs returns Merkle inclusion proofs for the caller's ledger entries
+// against the latest anchor.
+func (h *LedgerHandler) MyProofs() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		anchor, proofs, err := ledger.UserProofs(c.Context(), h.db.Pool, userID)
+		if errors.Is(err, ledger.ErrNoAnchor) {
+			return httpx.Fail(c, fiber.StatusNotFound, "no_anchor_yet")
+		}
+		if err != nil {
+			httpx.Logger(c).Error("failed to build ledger proofs", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "proofs_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"anchor": anchor,
+			"proofs": proofs,
+			"scheme": fiber.Map{
+				"leaf":      "sha256(0x00 || leaf_data)",
+				"node":      "sha256(0x01 || left || right); a node without a sibling is promoted unchanged",
+				"leaf_data": "seq|id|user_id|account|kind|asset|amount|reference|created_at(RFC3339Nano, UTC)",
+				"signature": "ed25519 over \"grainlify-ledger-anchor:v1:<to_seq>:<leaf_count>:<root>\"",
+			},
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/metadata"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+// MetadataHandler manages the custom metadata fields a project defines for
+// itself and its bounties, and the project's own metadata values.
+type MetadataHandler struct {
+	db *db.DB
+}
+
+func NewMetadataHandler(d *db.DB) *MetadataHandler {
+	return &MetadataHandler{db: d}
+}
+
+// metadataError writes the response for a rejected metadata write.
+func metadataError(c *fiber.Ctx, err error) error {
+	var verr *metadata.ValidationError
+	if errors.As(err, &verr) {
+		return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_metadata").
+			WithMessage(verr.Error()).With("key", verr.Key))
+	}
+	return httpx.Fail(c, fiber.StatusInternalServerError, "metadata_update_failed")
+}
+
+// Fields lists the fields the project defines for :entity (project or
+// bounty).
+func (h *MetadataHandler) Fields() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermManageProjects)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		entity := c.Params("entity")
+		if !metadata.ValidEntity(entity) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_metadata_entity")
+		}
+		fields, err := metadata.ListFields(c.Context(), h.db.Pool, projectID, entity)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "metadata_fields_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"fields": fields})
+	}
+}
+
+type putMetadataFieldsRequest struct {
+	Fields []metadata.Field `json:"fields"`
+}
+
+// PutFields replaces the fields the project defines for :entity.
+func (h *MetadataHandler) PutFields() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermManageProjects)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		var req putMetadataFieldsRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		fields, err := metadata.PutFields(c.Context(), h.db.Pool, projectID, c.Params("entity"), req.Fields)
+		switch {
+		case errors.Is(err, metadata.ErrInvalidEntity), errors.Is(err, metadata.ErrTooManyFields):
+			return httpx.Fail(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, metadata.ErrInvalidField):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_metadata_field").WithMessage(err.Error()))
+		case err != nil:
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "metadata_fields_update_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"fields": fields})
+	}
+}
+
+type setMetadataRequest struct {
+	Metadata map[string]any `json:"metadata"`
+}
+
+// SetProject replaces the project's metadata, validated against the fields
+// it defines for projects.
+func (h *MetadataHandler) SetProject() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermManageProjects)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		var req setMetadataRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		md, err := metadata.ValidateFor(c.Context(), h.db.Pool, projectID, metadata.EntityProject, req.Metadata)
+		if err != nil {
+			return metadataError(c, err)
+		}
+		if err := metadata.SetProject(c.Context(), h.db.Pool, projectID, md); err != nil {
+			return metadataError(c, err)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"metadata": md})
+	}
+}
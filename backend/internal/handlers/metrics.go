@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/probe"
+)
+
+// MetricsWriter is any component that exports its own Prometheus metrics.
+type MetricsWriter interface {
+	WriteMetrics(w io.Writer) error
+}
+
+// Metrics serves probe results, followed by any extra metrics, in the
+// Prometheus text format. A non-empty token must be presented as a bearer token.
+func Metrics(p *probe.Prober, token string, extra ...MetricsWriter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token != "" && !bearerMatches(c, token) {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "unauthorized")
+		}
+		var buf bytes.Buffer
+		if p != nil {
+			if err := p.WriteMetrics(&buf); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "metrics_failed")
+			}
+		}
+		for _, m := range extra {
+			if err := m.WriteMetrics(&buf); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "metrics_failed")
+			}
+		}
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4")
+		return c.Status(fiber.StatusOK).Send(buf.Bytes())
+	}
+}
+
+// RequireOpsToken guards operational endpoints such as /debug/pprof. Unlike
+// Metrics, an empty token rejects every request.
+func RequireOpsToken(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" || !bearerMatches(c, token) {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "unauthorized")
+		}
+		return c.Next()
+	}
+}
+
+func bearerMatches(c *fiber.Ctx, token string) bool {
+	got := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
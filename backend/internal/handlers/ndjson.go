@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// List endpoints that accept `Accept: application/x-ndjson` drop their page
+// limit and stream one JSON object per row straight off the DB cursor, so
+// full exports don't buffer the whole result in memory.
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	// ndjsonTimeout bounds a streamed export; the stream outlives the handler
+	// so it can't rely on the request context.
+	ndjsonTimeout = 10 * time.Minute
+	// ndjsonFlushEvery is how many rows are buffered between flushes.
+	ndjsonFlushEvery = 100
+)
+
+type rowScanner func(rows pgx.Rows) (any, error)
+
+func wantsNDJSON(c *fiber.Ctx) bool {
+	return strings.Contains(c.Get(fiber.HeaderAccept), ndjsonContentType)
+}
+
+// pageLimit is the LIMIT for a list query: limit for JSON, nil (no limit) when
+// streaming.
+func pageLimit(stream bool, limit int) *int {
+	if stream {
+		return nil
+	}
+	return &limit
+}
+
+// listQuery runs a list query. Streamed queries get their own deadline; the
+// returned cancel must be called once rows are done.
+func listQuery(c *fiber.Ctx, pool *pgxpool.Pool, stream bool, sql string, args ...any) (pgx.Rows, context.CancelFunc, error) {
+	var ctx context.Context = c.Context()
+	cancel := func() {}
+	if stream {
+		ctx, cancel = context.WithTimeout(context.Background(), ndjsonTimeout)
+	}
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return rows, cancel, nil
+}
+
+// collectRows scans every row for a buffered JSON response and closes rows.
+func collectRows(rows pgx.Rows, scan rowScanner) ([]any, error) {
+	defer rows.Close()
+	var out []any
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// streamNDJSON answers 200 and writes rows as NDJSON as they are read, then
+// closes rows and calls cancel. The status is already sent when a row fails,
+// so failures end the stream with a final {"error": ...} line.
+func streamNDJSON(c *fiber.Ctx, rows pgx.Rows, cancel context.CancelFunc, scan rowScanner) error {
+	path := c.Path()
+	c.Set(fiber.HeaderContentType, ndjsonContentType)
+	c.Status(fiber.StatusOK).Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		defer rows.Close()
+		enc := json.NewEncoder(w)
+		n := 0
+		for rows.Next() {
+			v, err := scan(rows)
+			if err != nil {
+				slog.Warn("ndjson stream scan failed", "path", path, "rows", n, "error", err)
+				_ = enc.Encode(fiber.Map{"error": "stream_failed"})
+				_ = w.Flush()
+				return
+			}
+			if err := enc.Encode(v); err != nil {
+				return
+			}
+			n++
+			if n%ndjsonFlushEvery == 0 {
+				if err := w.Flush(); err != nil {
+					// Client went away.
+					return
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			slog.Warn("ndjson stream failed", "path", path, "rows", n, "error", err)
+			_ = enc.Encode(fiber.Map{"error": "stream_failed"})
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/commands"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/notify"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+// NotificationChannelsHandler manages outbound chat channels (Matrix rooms)
+// that receive bounty events from the notify dispatcher.
+type NotificationChannelsHandler struct {
+	cfg        config.Config
+	db         *db.DB
+	transports map[string]notify.Transport
+}
+
+func NewNotificationChannelsHandler(cfg config.Config, d *db.DB) *NotificationChannelsHandler {
+	return &NotificationChannelsHandler{cfg: cfg, db: d, transports: notify.Transports()}
+}
+
+func (h *NotificationChannelsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		channels, err := notify.ListChannels(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "notification_channels_list_failed")
+		}
+		transports := make([]string, 0, len(h.transports))
+		for name := range h.transports {
+			transports = append(transports, name)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"channels": channels, "transports": transports, "event_types": bounties.EventTypes})
+	}
+}
+
+type createNotificationChannelRequest struct {
+	Transport string `json:"transport"`
+	Name      string `json:"name"`
+	// Project is owner/repo or a project id; empty follows all owned projects.
+	Project string          `json:"project"`
+	Config  json.RawMessage `json:"config"`
+	// AccessToken is the transport credential (the Matrix bot's token).
+	AccessToken string   `json:"access_token"`
+	EventTypes  []string `json:"event_types"`
+}
+
+func (h *NotificationChannelsHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if strings.TrimSpace(h.cfg.TokenEncKeyB64) == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req createNotificationChannelRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		eventTypes, err := bounties.ParseEventTypes(strings.Join(req.EventTypes, ","))
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_event_type").With("event_types", bounties.EventTypes))
+		}
+
+		var projectID *uuid.UUID
+		if req.Project != "" {
+			id, _, err := commands.ResolveProject(c.Context(), h.db.Pool, strings.TrimSpace(req.Project))
+			if errors.Is(err, commands.ErrProjectNotFound) {
+				return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
+			}
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+			}
+			var owner uuid.UUID
+			if err := h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, id).Scan(&owner); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+			}
+			role, _ := c.Locals(auth.LocalRole).(string)
+			ok, err := managesProject(c.Context(), h.db.Pool, id, owner, userID, role, orgs.PermManageProjects)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+			}
+			if !ok {
+				return httpx.Fail(c, fiber.StatusForbidden, "forbidden")
+			}
+			projectID = &id
+		}
+
+		ch, err := notify.CreateChannel(c.Context(), h.db.Pool, h.transports, userID, notify.NewChannel{
+			ProjectID:  projectID,
+			Transport:  req.Transport,
+			Name:       req.Name,
+			Config:     req.Config,
+			Secret:     req.AccessToken,
+			EventTypes: eventTypes,
+		}, h.cfg.TokenEncKeyB64)
+		switch {
+		case errors.Is(err, notify.ErrUnknownTransport):
+			return httpx.Fail(c, fiber.StatusBadRequest, "unknown_transport")
+		case errors.Is(err, notify.ErrInvalidConfig):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_channel_config").WithMessage(err.Error()))
+		case err != nil:
+			httpx.Logger(c).Warn("notification channel create failed", "user_id", userID.String(), "transport", req.Transport, "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "notification_channel_create_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(ch)
+	}
+}
+
+func (h *NotificationChannelsHandler) Delete() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_channel_id")
+		}
+		err = notify.DeleteChannel(c.Context(), h.db.Pool, userID, id)
+		if errors.Is(err, notify.ErrChannelNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "notification_channel_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "notification_channel_delete_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// Test sends a test message through the channel's transport.
+func (h *NotificationChannelsHandler) Test() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_channel_id")
+		}
+		err = notify.SendTest(c.Context(), h.db.Pool, h.transports, userID, id, h.cfg.TokenEncKeyB64)
+		if errors.Is(err, notify.ErrChannelNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "notification_channel_not_found")
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusBadGateway, "notification_send_failed").WithMessage(err.Error()))
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
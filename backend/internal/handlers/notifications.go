@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/notifications"
+)
+
+// NotificationsHandler serves a user's in-app notifications and their
+// notification preferences.
+type NotificationsHandler struct {
+	db *db.DB
+}
+
+func NewNotificationsHandler(d *db.DB) *NotificationsHandler {
+	return &NotificationsHandler{db: d}
+}
+
+type notificationsResponse struct {
+	Notifications []notifications.Notification `json:"notifications"`
+	Unread        int                          `json:"unread"`
+}
+
+// List returns the caller's notifications newest first, only unread ones
+// with ?unread=true. Pages continue with ?before=<created_at of the last>.
+func (h *NotificationsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		f := notifications.ListFilter{UnreadOnly: c.QueryBool("unread"), Limit: c.QueryInt("limit", notifications.MaxPage)}
+		if s := c.Query("before"); s != "" {
+			before, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_before")
+			}
+			f.Before = &before
+		}
+		list, err := notifications.List(c.Context(), h.db.Pool, userID, f)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "notifications_list_failed").Wrap(err))
+		}
+		unread, err := notifications.UnreadCount(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "notifications_list_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(notificationsResponse{Notifications: list, Unread: unread})
+	}
+}
+
+// MarkRead marks one of the caller's notifications read.
+func (h *NotificationsHandler) MarkRead() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_notification_id")
+		}
+		err = notifications.MarkRead(c.Context(), h.db.Pool, userID, id)
+		if errors.Is(err, notifications.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, err.Error())
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "notification_update_failed").Wrap(err))
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+type markAllReadResponse struct {
+	Marked int64 `json:"marked"`
+}
+
+// MarkAllRead marks every notification of the caller read.
+func (h *NotificationsHandler) MarkAllRead() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		n, err := notifications.MarkAllRead(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "notification_update_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(markAllReadResponse{Marked: n})
+	}
+}
+
+type notificationPreferencesResponse struct {
+	Preferences []notifications.Preference `json:"preferences"`
+	Types       []string                   `json:"types"`
+	Channels    []string                   `json:"channels"`
+}
+
+func preferencesResponse(prefs []notifications.Preference) notificationPreferencesResponse {
+	return notificationPreferencesResponse{Preferences: prefs, Types: notifications.Types, Channels: notifications.Channels}
+}
+
+// Preferences returns which channels the caller gets each notification on.
+func (h *NotificationsHandler) Preferences() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		prefs, err := notifications.Preferences(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "notification_preferences_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(preferencesResponse(prefs))
+	}
+}
+
+type setNotificationPreferencesRequest struct {
+	Preferences []notifications.Preference `json:"preferences"`
+}
+
+// SetPreferences turns channels on or off per notification type; settings
+// not given are kept.
+func (h *NotificationsHandler) SetPreferences() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req setNotificationPreferencesRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		prefs, err := notifications.SetPreferences(c.Context(), h.db.Pool, userID, req.Preferences)
+		switch {
+		case errors.Is(err, notifications.ErrUnknownType):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "unknown_notification_type").With("types", notifications.Types))
+		case errors.Is(err, notifications.ErrUnknownChannel):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "unknown_notification_channel").With("channels", notifications.Channels))
+		case err != nil:
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "notification_preferences_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(preferencesResponse(prefs))
+	}
+}
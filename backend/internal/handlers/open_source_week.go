@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
 type OpenSourceWeekHandler struct {
@@ -24,7 +25,7 @@ func NewOpenSourceWeekHandler(d *db.DB) *OpenSourceWeekHandler {
 func (h *OpenSourceWeekHandler) ListPublic() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
@@ -35,7 +36,7 @@ ORDER BY start_at DESC
 LIMIT 100
 `)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "osw_events_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "osw_events_list_failed")
 		}
 		defer rows.Close()
 
@@ -46,7 +47,7 @@ LIMIT 100
 			var desc, location *string
 			var startAt, endAt, createdAt, updatedAt time.Time
 			if err := rows.Scan(&id, &title, &desc, &location, &status, &startAt, &endAt, &createdAt, &updatedAt); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "osw_events_list_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "osw_events_list_failed")
 			}
 			out = append(out, fiber.Map{
 				"id":          id.String(),
@@ -68,11 +69,11 @@ LIMIT 100
 func (h *OpenSourceWeekHandler) GetPublic() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		evID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_event_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_event_id")
 		}
 
 		var title, status string
@@ -84,10 +85,10 @@ FROM open_source_week_events
 WHERE id = $1 AND status <> 'draft'
 `, evID).Scan(&title, &desc, &location, &status, &startAt, &endAt, &createdAt, &updatedAt)
 		if errors.Is(err, pgx.ErrNoRows) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "event_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "event_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "osw_event_get_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "osw_event_get_failed")
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -117,7 +118,7 @@ func NewOpenSourceWeekAdminHandler(d *db.DB) *OpenSourceWeekAdminHandler {
 func (h *OpenSourceWeekAdminHandler) List() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		rows, err := h.db.Pool.Query(c.Context(), `
 SELECT id, title, description, location, status, start_at, end_at, created_at, updated_at
@@ -126,7 +127,7 @@ ORDER BY start_at DESC
 LIMIT 200
 `)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "osw_events_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "osw_events_list_failed")
 		}
 		defer rows.Close()
 
@@ -137,7 +138,7 @@ LIMIT 200
 			var desc, location *string
 			var startAt, endAt, createdAt, updatedAt time.Time
 			if err := rows.Scan(&id, &title, &desc, &location, &status, &startAt, &endAt, &createdAt, &updatedAt); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "osw_events_list_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "osw_events_list_failed")
 			}
 			out = append(out, fiber.Map{
 				"id":          id.String(),
@@ -168,35 +169,35 @@ type oswCreateRequest struct {
 func (h *OpenSourceWeekAdminHandler) Create() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		var req oswCreateRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 
 		title := strings.TrimSpace(req.Title)
 		if title == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "title_required"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "title_required")
 		}
 		status := strings.TrimSpace(req.Status)
 		if status == "" {
 			status = "upcoming"
 		}
 		if status != "upcoming" && status != "running" && status != "completed" && status != "draft" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
 		}
 
 		startAt, err := time.Parse(time.RFC3339, strings.TrimSpace(req.StartAt))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_start_at"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_start_at")
 		}
 		endAt, err := time.Parse(time.RFC3339, strings.TrimSpace(req.EndAt))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_end_at"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_end_at")
 		}
 		if !endAt.After(startAt) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "end_at_must_be_after_start_at"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "end_at_must_be_after_start_at")
 		}
 
 		var id uuid.UUID
@@ -206,7 +207,7 @@ VALUES ($1, NULLIF($2,''), NULLIF($3,''), $4, $5, $6)
 RETURNING id
 `, title, strings.TrimSpace(req.Description), strings.TrimSpace(req.Location), status, startAt, endAt).Scan(&id)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "osw_event_create_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "osw_event_create_failed")
 		}
 
 		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": id.String()})
@@ -216,18 +217,18 @@ RETURNING id
 func (h *OpenSourceWeekAdminHandler) Delete() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		evID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_event_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_event_id")
 		}
 		ct, err := h.db.Pool.Exec(c.Context(), `DELETE FROM open_source_week_events WHERE id = $1`, evID)
 		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "event_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "event_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "osw_event_delete_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "osw_event_delete_failed")
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 	}
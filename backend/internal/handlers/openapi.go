@@ -0,0 +1,727 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/advisories"
+	"github.com/jagadeesh/grainlify/backend/internal/apikeys"
+	"github.com/jagadeesh/grainlify/backend/internal/audit"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/authz"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/deposits"
+	"github.com/jagadeesh/grainlify/backend/internal/geo"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/leaderboard"
+	"github.com/jagadeesh/grainlify/backend/internal/messaging"
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+	"github.com/jagadeesh/grainlify/backend/internal/openapi"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+	"github.com/jagadeesh/grainlify/backend/internal/payouts"
+	"github.com/jagadeesh/grainlify/backend/internal/proofs"
+	"github.com/jagadeesh/grainlify/backend/internal/repohealth"
+	"github.com/jagadeesh/grainlify/backend/internal/splits"
+	"github.com/jagadeesh/grainlify/backend/internal/treasury"
+	"github.com/jagadeesh/grainlify/backend/internal/webhooks"
+)
+
+// Response bodies that handlers build as fiber.Map, described for the
+// OpenAPI document. Keep them in step with the handlers.
+
+type okResponse struct {
+	OK bool `json:"ok"`
+}
+
+type nonceResponse struct {
+	Nonce     string    `json:"nonce"`
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// SIWEMessage is offered to EVM wallets.
+	SIWEMessage string `json:"siwe_message,omitempty"`
+	// StellarChallenge is offered to Stellar wallets that only sign transactions.
+	StellarChallenge *struct {
+		Transaction       string `json:"transaction"`
+		NetworkPassphrase string `json:"network_passphrase"`
+	} `json:"stellar_challenge,omitempty"`
+	// PoW is set when the login must carry a proof of work.
+	PoW *struct {
+		Algorithm  string `json:"algorithm"`
+		Challenge  string `json:"challenge"`
+		Difficulty int    `json:"difficulty"`
+		Format     string `json:"format"`
+	} `json:"pow,omitempty"`
+}
+
+type tokenResponse struct {
+	Token            string       `json:"token"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
+	User             auth.User    `json:"user"`
+	Wallet           *auth.Wallet `json:"wallet,omitempty"`
+}
+
+type urlResponse struct {
+	URL string `json:"url"`
+}
+
+type walletsResponse struct {
+	Wallets []auth.LinkedWallet `json:"wallets"`
+}
+
+type routePermissionsResponse struct {
+	Routes []authz.Entry `json:"routes"`
+}
+
+type sessionsResponse struct {
+	Sessions []auth.DeviceSession `json:"sessions"`
+}
+
+type githubDeviceResponse struct {
+	ID              string    `json:"id"`
+	UserCode        string    `json:"user_code"`
+	VerificationURI string    `json:"verification_uri"`
+	Interval        int       `json:"interval"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+type githubAppInstallResponse struct {
+	InstallURL string `json:"install_url"`
+	State      string `json:"state"`
+}
+
+type createAPIKeyResponse struct {
+	APIKey apikeys.Key `json:"api_key"`
+	// Key is the plaintext key, shown only in this response.
+	Key string `json:"key"`
+}
+
+type createWebhookResponse struct {
+	Endpoint webhooks.Endpoint `json:"endpoint"`
+	// Secret signs deliveries and is shown only in this response.
+	Secret string `json:"secret"`
+}
+
+type webhookDeliveriesResponse struct {
+	Deliveries []webhooks.Delivery `json:"deliveries"`
+}
+
+type countryResponse struct {
+	Country   *string `json:"country"`
+	IPCountry *string `json:"ip_country,omitempty"`
+}
+
+// listParams documents the query parameters httpx.ParseList reads for spec,
+// followed by extra.
+func listParams(spec httpx.ListSpec, extra ...openapi.Param) []openapi.Param {
+	var sortable []string
+	names := make([]string, 0, len(spec.Fields))
+	for name, f := range spec.Fields {
+		names = append(names, name)
+		if f.Sort {
+			sortable = append(sortable, name)
+		}
+	}
+	sort.Strings(names)
+	sort.Strings(sortable)
+	out := []openapi.Param{
+		{Name: "sort", Description: "comma-separated fields of " + strings.Join(sortable, ", ") + ", each prefixed with - for descending; default " + spec.DefaultSort},
+		{Name: "limit", Type: "integer", Description: fmt.Sprintf("default %d, at most %d", spec.DefaultLimit, spec.MaxLimit)},
+		{Name: "cursor", Description: "next_cursor of the previous page, with the same sort"},
+	}
+	for _, name := range names {
+		f := spec.Fields[name]
+		if !f.Filter {
+			continue
+		}
+		desc := f.Type.String() + "; comma-separated for any of several, or " + name + "[ne]= for none of them"
+		if len(f.Values) > 0 {
+			desc = "one of " + strings.Join(f.Values, ", ") + "; comma-separated for any of several, or " + name + "[ne]= for none of them"
+		}
+		if f.Type == httpx.FieldTime || f.Type == httpx.FieldInt || f.Type == httpx.FieldNumeric {
+			desc += "; " + name + "[gt], [gte], [lt] and [lte] bound a range"
+		}
+		out = append(out, openapi.Param{Name: name, Description: desc})
+	}
+	return append(out, extra...)
+}
+
+// OpenAPIOperations annotates routes for the generated OpenAPI document,
+// keyed by method and registered path. Routes without an entry are still
+// listed, with their path parameters and security.
+func OpenAPIOperations() map[string]openapi.Operation {
+	bearer := []string{openapi.SchemeBearer}
+	return map[string]openapi.Operation{
+		// Meta
+		openapi.Key(http.MethodGet, "/meta/changelog"): {
+			Summary:     "Machine-readable changelog of API behavior changes",
+			Description: "Entries are newest first; latest is the newest entry's date. since=YYYY-MM-DD keeps entries after that day. Deprecated routes are also flagged in this document.",
+			Query:       []openapi.Param{{Name: "since", Description: "Only entries after this date (YYYY-MM-DD)."}},
+			Response:    openapi.Changelog{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Lists API behavior changes for SDKs and integrators."}},
+		},
+		openapi.Key(http.MethodGet, "/meta/events"): {
+			Summary:     "Catalog of webhook events",
+			Description: "Every event type sent to webhook endpoints, with the JSON Schema of its data (refs point into $defs) and an example delivery body in the current version. The Go package github.com/jagadeesh/grainlify/backend/webhookevents has the same types.",
+			Response:    webhooks.Catalog{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Publishes webhook event types, schemas and examples."}},
+		},
+
+		// Auth
+		openapi.Key(http.MethodPost, "/auth/nonce"): {
+			Summary:  "Start a wallet login",
+			Request:  nonceRequest{},
+			Response: nonceResponse{},
+		},
+		openapi.Key(http.MethodPost, "/auth/verify"): {
+			Summary:  "Finish a wallet login with the signed message",
+			Request:  verifyRequest{},
+			Response: tokenResponse{},
+		},
+		openapi.Key(http.MethodPost, "/auth/refresh"): {
+			Summary:     "Rotate a refresh token",
+			Description: "Reusing a rotated-out token revokes the whole session.",
+			Request:     refreshRequest{},
+			Response:    tokenResponse{},
+		},
+		openapi.Key(http.MethodPost, "/auth/logout"): {
+			Summary:  "Revoke the session behind a refresh token",
+			Request:  logoutRequest{},
+			Response: okResponse{},
+		},
+		openapi.Key(http.MethodGet, "/auth/wallets"): {Summary: "List linked wallets", Response: walletsResponse{}},
+		openapi.Key(http.MethodPost, "/auth/wallets/link"): {
+			Summary:  "Link another wallet",
+			Request:  linkWalletRequest{},
+			Response: auth.LinkedWallet{},
+			Status:   http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/auth/sessions"):           {Summary: "List signed-in devices", Response: sessionsResponse{}},
+		openapi.Key(http.MethodDelete, "/auth/sessions/:id"):    {Summary: "Sign out a device", Response: okResponse{}},
+		openapi.Key(http.MethodPost, "/auth/recovery/start"):    {Summary: "Email a recovery code", Request: startEmailRequest{}},
+		openapi.Key(http.MethodPost, "/auth/recovery/request"):  {Summary: "Request recovery to a new wallet", Request: requestRecoveryRequest{}},
+		openapi.Key(http.MethodPost, "/auth/recovery/complete"): {Summary: "Complete a recovery after its waiting period", Request: completeRecoveryRequest{}, Response: tokenResponse{}},
+		openapi.Key(http.MethodPost, "/me/email"):               {Summary: "Email a verification code", Request: startEmailRequest{}},
+		openapi.Key(http.MethodPost, "/me/email/verify"):        {Summary: "Verify the caller's email", Request: emailCodeRequest{}},
+		openapi.Key(http.MethodGet, "/me/recovery"):             {Summary: "Show a pending recovery of the caller's account"},
+		openapi.Key(http.MethodDelete, "/me/recovery"):          {Summary: "Cancel a pending recovery"},
+		openapi.Key(http.MethodGet, "/me"):                      {Summary: "The signed-in user", Description: "Accepts API keys with the profile:read scope."},
+		openapi.Key(http.MethodGet, "/me/country"):              {Summary: "Declared and request country", Response: countryResponse{}},
+		openapi.Key(http.MethodPut, "/me/country"):              {Summary: "Declare a country", Request: setCountryRequest{}, Response: countryResponse{}},
+		openapi.Key(http.MethodGet, "/auth/api-keys"):           {Summary: "List API keys"},
+		openapi.Key(http.MethodPost, "/auth/api-keys"):          {Summary: "Create an API key", Request: createAPIKeyRequest{}, Response: createAPIKeyResponse{}, Status: http.StatusCreated},
+		openapi.Key(http.MethodDelete, "/auth/api-keys/:id"):    {Summary: "Revoke an API key"},
+		openapi.Key(http.MethodGet, "/me/api-keys"):             {Summary: "List API keys"},
+		openapi.Key(http.MethodPost, "/me/api-keys"):            {Summary: "Create an API key", Request: createAPIKeyRequest{}, Response: createAPIKeyResponse{}, Status: http.StatusCreated},
+		openapi.Key(http.MethodDelete, "/me/api-keys/:id"):      {Summary: "Revoke an API key"},
+		openapi.Key(http.MethodPost, "/admin/bootstrap"):        {Summary: "Promote the first admin", Security: bearer},
+		openapi.Key(http.MethodPut, "/admin/users/:id/role"):    {Summary: "Set a user's role", Request: setRoleRequest{}},
+		openapi.Key(http.MethodGet, "/admin/users"): {
+			Summary:     "List users",
+			Description: "Streams every match as NDJSON with Accept: application/x-ndjson. A full page comes with next_cursor.",
+			Query: listParams(usersListSpec,
+				openapi.Param{Name: "q", Description: "user id, GitHub login or wallet address"},
+				openapi.Param{Name: "offset", Type: "integer", Description: "deprecated; use cursor"}),
+			Changes: []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Sorting, typed filters and cursor pagination; an invalid status is rejected with invalid_filter. offset and next_offset still work but are superseded by cursor.", Fields: []string{"next_cursor"}}},
+		},
+		openapi.Key(http.MethodGet, "/admin/audit"): {
+			Summary:     "Audit trail of every user",
+			Description: "A full page comes with next_cursor.",
+			Query:       listParams(audit.ListSpec, openapi.Param{Name: "user_id", Description: "only this user's entries"}),
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Sorting, created_at ranges and cursor pagination; since, until, before and next_before still work.", Fields: []string{"next_cursor"}}},
+		},
+		openapi.Key(http.MethodGet, "/users/me/audit"): {
+			Summary:     "Your audit trail",
+			Description: "A full page comes with next_cursor.",
+			Query:       listParams(audit.ListSpec),
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Sorting, created_at ranges and cursor pagination; since, until, before and next_before still work.", Fields: []string{"next_cursor"}}},
+		},
+		openapi.Key(http.MethodGet, "/admin/route-permissions"): {
+			Summary:     "Review the route authorization matrix",
+			Description: "Lists every route with the permission it requires, the rule that grants it and the auth middleware enforcing it.",
+			Response:    routePermissionsResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Lists the permission every route requires."}},
+		},
+		openapi.Key(http.MethodGet, "/admin/saved-reports"):       {Summary: "List saved reports and their parameters"},
+		openapi.Key(http.MethodGet, "/admin/saved-reports/:name"): {Summary: "Run a saved report (parameters in the query string; format=csv downloads it)"},
+		openapi.Key(http.MethodPut, "/admin/users/:id/plan-tier"): {Summary: "Set a user's plan tier (API key rate limits)", Request: setPlanTierRequest{}},
+		openapi.Key(http.MethodGet, "/admin/tokens/keys"): {
+			Summary:     "GitHub token key versions",
+			Description: "How many stored GitHub tokens each key version seals, and which versions are held and active. A held version with no tokens can be retired from TOKEN_ENC_KEYS.",
+			Response:    tokenKeysResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "GitHub token key rotation."}},
+		},
+		openapi.Key(http.MethodPost, "/admin/tokens/rekey"): {
+			Summary:     "Re-encrypt stored GitHub tokens under the active key",
+			Description: "Rewraps each token's data key under TOKEN_ENC_KEY_ACTIVE while the API keeps serving; tokens rewritten meanwhile by a login are left as they are. Same as `api rekey run`.",
+			Response:    github.RekeyResult{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "GitHub token key rotation."}},
+		},
+		openapi.Key(http.MethodPost, "/admin/users/:id/suspend"):         {Summary: "Suspend a user", Request: accountActionRequest{}},
+		openapi.Key(http.MethodPost, "/admin/users/:id/ban"):             {Summary: "Ban a user", Request: accountActionRequest{}},
+		openapi.Key(http.MethodPost, "/admin/users/:id/reinstate"):       {Summary: "Reinstate a user", Request: accountActionRequest{}},
+		openapi.Key(http.MethodPost, "/admin/users/:id/shadow-ban"):      {Summary: "Shadow-ban a user", Request: shadowBanRequest{}},
+		openapi.Key(http.MethodPut, "/admin/geo/policies/:action"):       {Summary: "Set a country restriction policy", Request: setGeoPolicyRequest{}, Response: geo.Policy{}},
+		openapi.Key(http.MethodPost, "/admin/reports/:id/close"):         {Summary: "Resolve or dismiss a report", Request: closeReportRequest{}, Response: moderation.Report{}},
+		openapi.Key(http.MethodPost, "/admin/fraud/rules"):               {Summary: "Create a fraud rule", Request: fraudRuleRequest{}, Status: http.StatusCreated},
+		openapi.Key(http.MethodPut, "/admin/fraud/rules/:id"):            {Summary: "Update a fraud rule", Request: fraudRuleRequest{}},
+		openapi.Key(http.MethodPost, "/admin/fraud/reviews/:id/resolve"): {Summary: "Resolve a fraud review", Request: resolveFraudReviewRequest{}},
+		openapi.Key(http.MethodPost, "/admin/payouts"):                   {Summary: "Queue a payout", Request: createPayoutRequest{}, Response: payouts.Payout{}, Status: http.StatusCreated},
+		openapi.Key(http.MethodGet, "/admin/payouts/:id"): {
+			Summary:  "A payout and its on-chain status",
+			Response: payouts.Payout{},
+			Changes:  []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Shows one payout, including its confirmation block."}},
+		},
+		openapi.Key(http.MethodGet, "/admin/treasury/escrow-reconciliation"): {
+			Summary:     "Compare project escrow balances on chain with the ledger",
+			Description: "One line per project escrow address and asset: surplus means more on chain than credited (deposits not yet credited), shortfall means less. Unreadable balances are reported per line as unknown.",
+			Response:    treasury.EscrowReconciliation{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Reconciles project escrow addresses against the ledger."}},
+		},
+
+		// GitHub
+		openapi.Key(http.MethodGet, "/auth/github/login/start"): {
+			Summary: "Sign in with GitHub",
+			Query:   []openapi.Param{{Name: "redirect", Description: "Frontend origin to return to."}},
+			Status:  http.StatusFound,
+		},
+		openapi.Key(http.MethodGet, "/auth/github/callback"):           {Summary: "GitHub OAuth callback", Status: http.StatusFound},
+		openapi.Key(http.MethodGet, "/auth/github/login/callback"):     {Summary: "GitHub OAuth callback (legacy path)", Status: http.StatusFound},
+		openapi.Key(http.MethodPost, "/auth/github/start"):             {Summary: "Link GitHub to the signed-in account", Response: urlResponse{}},
+		openapi.Key(http.MethodGet, "/auth/github/status"):             {Summary: "Linked GitHub account"},
+		openapi.Key(http.MethodDelete, "/auth/github"):                 {Summary: "Unlink GitHub"},
+		openapi.Key(http.MethodPost, "/github/link/device"):            {Summary: "Link GitHub with the device flow", Response: githubDeviceResponse{}},
+		openapi.Key(http.MethodPost, "/github/link/device/:id/poll"):   {Summary: "Poll a device flow link"},
+		openapi.Key(http.MethodPost, "/auth/github/app/install/start"): {Summary: "Start a GitHub App installation", Response: githubAppInstallResponse{}},
+		openapi.Key(http.MethodPost, "/me/github/resync"):              {Summary: "Re-sync the GitHub profile"},
+		openapi.Key(http.MethodGet, "/me/github/repos"):                {Summary: "Repositories of the linked GitHub account"},
+		openapi.Key(http.MethodGet, "/me/github/contributions"):        {Summary: "GitHub contribution stats"},
+		openapi.Key(http.MethodGet, "/me/github/orgs"): {
+			Summary: "Linked GitHub organizations the caller is an admin of",
+			Changes: []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Lists linked GitHub organizations."}},
+		},
+		openapi.Key(http.MethodPost, "/me/github/orgs"): {
+			Summary:     "Link the GitHub organization of an app installation",
+			Description: "The caller's linked GitHub account must be an admin of the organization. Its admins then manage every project whose repository the organization owns. Installing the app on an organization links it too.",
+			Request:     linkGitHubOrgRequest{},
+			Response:    orgs.Org{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Links GitHub organizations for team-based project ownership."}},
+		},
+		openapi.Key(http.MethodPost, "/me/github/orgs/:id/sync"): {Summary: "Re-sync a linked organization's members from GitHub", Response: orgs.Org{}},
+		openapi.Key(http.MethodDelete, "/me/github/orgs/:id"):    {Summary: "Unlink a GitHub organization", Status: http.StatusNoContent},
+		openapi.Key(http.MethodGet, "/me/github/orgs/:id/roles"): {
+			Summary:     "A linked organization's custom roles",
+			Description: "For the organization's admins. Each role grants its members a set of permissions over the organization's projects; permissions lists those available. A member who leaves the organization on GitHub keeps the assignment, inactive, until they rejoin or are unassigned.",
+			Response:    orgRolesResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Custom organization roles."}},
+		},
+		openapi.Key(http.MethodPost, "/me/github/orgs/:id/roles"): {
+			Summary:     "Define a custom organization role",
+			Description: "Names are unique per organization and can't be admin, member or owner. projects:manage covers project settings; bounties:edit creating, editing and cancelling bounties; payouts:approve approving submissions and releasing payouts; finances:view escrow, accounting, histories, splits and logged time.",
+			Request:     orgs.RoleDraft{},
+			Response:    orgs.Role{},
+			Status:      http.StatusCreated,
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Custom organization roles."}},
+		},
+		openapi.Key(http.MethodPut, "/me/github/orgs/:id/roles/:role_id"):    {Summary: "Redefine a custom organization role", Request: orgs.RoleDraft{}, Response: orgs.Role{}},
+		openapi.Key(http.MethodDelete, "/me/github/orgs/:id/roles/:role_id"): {Summary: "Delete a custom organization role", Status: http.StatusNoContent},
+		openapi.Key(http.MethodPost, "/me/github/orgs/:id/roles/:role_id/members"): {
+			Summary:     "Assign a custom role to an organization member",
+			Description: "The login must be a member in the organization's last sync.",
+			Request:     assignOrgRoleRequest{},
+			Response:    orgs.Role{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Custom organization roles."}},
+		},
+		openapi.Key(http.MethodDelete, "/me/github/orgs/:id/roles/:role_id/members/:github_user_id"): {Summary: "Take a custom role from a GitHub user", Response: orgs.Role{}},
+		openapi.Key(http.MethodPost, "/webhooks/github"):                                             {Summary: "GitHub webhook receiver", Description: "Signed with the app's webhook secret (X-Hub-Signature-256)."},
+
+		// Community
+		openapi.Key(http.MethodGet, "/leaderboard"): {
+			Summary:     "Top contributors",
+			Description: "Without window, contributions (issues and pull requests) in verified projects, counted live. With window=week|month|all, the ranking the leaderboard job last computed: merged pull requests plus completed bounties, with earnings per chain/asset.",
+			Query: []openapi.Param{
+				{Name: "window", Description: "week, month (trailing 7 and 30 days) or all."},
+				{Name: "limit", Type: "integer", Description: "Page size, at most 100 (default 10)."},
+				{Name: "offset", Type: "integer"},
+			},
+			Changes: []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "window selects a precomputed weekly, monthly or all-time ranking.", Fields: []string{"window", "merged_prs", "completed_bounties", "projects", "earnings", "computed_at"}}},
+		},
+		openapi.Key(http.MethodGet, "/users/:id/stats"): {
+			Summary:     "A contributor's merged pull requests, completed bounties, earnings and rank",
+			Description: "One entry per window (week, month, all) the user is ranked in, as of the leaderboard job's last run.",
+			Response:    leaderboard.Stats{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Per-window contribution stats of a user."}},
+		},
+
+		// Bounties, funding and payouts
+		openapi.Key(http.MethodGet, "/projects/:id/bounties"): {
+			Summary:     "List a project's bounties",
+			Description: "Public bounties only, also filtered by metadata.<key>=value parameters. A full page comes with next_cursor.",
+			Query:       listParams(bounties.ListSpec, openapi.Param{Name: "metadata.<key>", Description: "value of a project-defined metadata field"}),
+			Changes: []openapi.Change{
+				{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Sorting, typed filters and cursor pagination; status takes several, comma-separated.", Fields: []string{"next_cursor"}},
+				{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "Private and unlisted bounties are left out."},
+			},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties"): {
+			Summary:     "Create a bounty",
+			Description: "Accepts API keys with the bounties:write scope. Unlisted bounties come back with the token of their link.",
+			Request:     createBountyRequest{},
+			Response:    linkedBounty{},
+			Status:      http.StatusCreated,
+			Changes: []openapi.Change{
+				{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Bounty visibility.", Fields: []string{"visibility", "link_token"}},
+				{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Security-advisory bounties.", Fields: []string{"kind"}},
+				{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "path_prefix scopes a bounty to a directory of a monorepo; an invalid one is rejected with invalid_path_prefix.", Fields: []string{"path_prefix"}},
+			},
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/bounties/hidden"): {
+			Summary:     "List a project's private and unlisted bounties",
+			Description: "For maintainers allowed to edit the project's bounties; unlisted ones carry the token of their link.",
+			Response:    hiddenBountiesResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty visibility."}},
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/bounties/:bounty_id"): {
+			Summary:     "Get a bounty",
+			Description: "Private bounties are found only by signed-in callers among the project's maintainers, members of its GitHub organization, invited users and the claimant; unlisted ones also by anyone with their link.",
+			Query:       []openapi.Param{{Name: "token", Description: "An unlisted bounty's link token."}},
+			Response:    bounties.Bounty{},
+			Changes: []openapi.Change{
+				{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty visibility."},
+				{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Security-advisory bounties.", Fields: []string{"kind"}},
+			},
+		},
+		openapi.Key(http.MethodPut, "/projects/:id/bounties/:bounty_id/visibility"): {
+			Summary:     "Make a bounty public, private or unlisted",
+			Description: "Private and unlisted bounties are left out of lists, event feeds and notifications to anyone who may not see them.",
+			Request:     setVisibilityRequest{},
+			Response:    linkedBounty{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty visibility."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/link/rotate"): {
+			Summary:     "Replace an unlisted bounty's link",
+			Description: "Every link handed out before stops working.",
+			Response:    linkedBounty{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty visibility."}},
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/bounties/:bounty_id/invites"): {
+			Summary:  "List the users invited to a bounty",
+			Response: bountyInvitesResponse{},
+			Changes:  []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty visibility."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/invites"): {
+			Summary:     "Invite a user to a private or unlisted bounty",
+			Description: "Invited users can see and claim the bounty without belonging to the project's organization or holding its link.",
+			Request:     inviteRequest{},
+			Response:    bountyInvitesResponse{},
+			Status:      http.StatusCreated,
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty visibility."}},
+		},
+		openapi.Key(http.MethodDelete, "/projects/:id/bounties/:bounty_id/invites/:user_id"): {
+			Summary: "Withdraw a user's invite to a bounty",
+			Status:  http.StatusNoContent,
+			Changes: []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty visibility."}},
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/bounties/:bounty_id/advisory"): {
+			Summary:     "A security-advisory bounty's advisory",
+			Description: "The embargoed details are only returned to the project's maintainers, the claimant and invited users; everyone else gets the redacted write-up.",
+			Response:    advisories.Advisory{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Security-advisory bounties."}},
+		},
+		openapi.Key(http.MethodPut, "/projects/:id/bounties/:bounty_id/advisory"): {
+			Summary:     "Write a bounty's security advisory",
+			Description: "Turns the bounty into a private security-advisory bounty under embargo until disclosure_at: only invited users may claim it, and a draft GitHub Security Advisory is opened on the project's repository. Disclosed advisories can't be changed.",
+			Request:     advisories.Draft{},
+			Response:    advisories.Advisory{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Security-advisory bounties."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/advisory/publish"): {
+			Summary:     "Disclose a security advisory now",
+			Description: "Ends the embargo ahead of disclosure_at, which otherwise ends it automatically: the bounty turns public with the redacted write-up and the GitHub advisory is published.",
+			Response:    advisories.Advisory{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Security-advisory bounties."}},
+		},
+		openapi.Key(http.MethodGet, "/me/bounties/shared"): {
+			Summary:     "Private and unlisted bounties shared with the caller",
+			Description: "Through an invite or membership of the project's GitHub organization.",
+			Response:    sharedBountiesResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty visibility."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/cancel"):  {Summary: "Cancel a bounty", Description: "Open, claimed and submitted bounties can be cancelled.", Response: bounties.Bounty{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/claim"):   {Summary: "Claim an open bounty", Response: bounties.Bounty{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/unclaim"): {Summary: "Drop a bounty's claim", Description: "By the claimant, or by the maintainer to reject their work. Reopens the bounty.", Response: bounties.Bounty{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/submit"):  {Summary: "Submit a pull request for a claimed bounty", Request: submitBountyRequest{}, Response: bounties.Bounty{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/approve"): {Summary: "Approve a bounty's submission", Description: "Accepts API keys with the bounties:write scope.", Response: bounties.Bounty{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/pay"): {
+			Summary:     "Pay an approved bounty",
+			Description: "Queues a hot wallet payout to the claimant, at to_address or else the wallet they signed in with on the bounty's chain (422 claimant_wallet_missing when they have none), and completes the bounty. Escrow-funded bounties are paid by releasing the escrow.",
+			Request:     payBountyRequest{},
+			Response:    payouts.Payout{},
+			Status:      http.StatusCreated,
+		},
+		openapi.Key(http.MethodPut, "/projects/:id/bounties/:bounty_id/skill-tags"):    {Summary: "Override a bounty's skill tags", Request: setSkillTagsRequest{}, Response: bounties.Bounty{}},
+		openapi.Key(http.MethodDelete, "/projects/:id/bounties/:bounty_id/skill-tags"): {Summary: "Restore a bounty's detected skill tags", Response: bounties.Bounty{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/split"): {
+			Summary:     "Suggest a split of a team bounty",
+			Description: "Weights each author of the pull request by commits and lines changed. Replaces any earlier suggestion.",
+			Request:     suggestSplitRequest{},
+			Response:    splits.Split{},
+			Status:      http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/bounties/:bounty_id/split"):           {Summary: "Get a bounty's current split", Response: splits.Split{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/split/accept"):   {Summary: "Accept a bounty split", Response: splits.Split{}},
+		openapi.Key(http.MethodPut, "/projects/:id/bounties/:bounty_id/split/shares"):    {Summary: "Adjust a bounty split's shares", Request: adjustSplitRequest{}, Response: splits.Split{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/escrow/lock"):    {Summary: "Record the escrow lock transaction of a bounty", Request: escrowLockRequest{}, Response: bounties.Bounty{}},
+		openapi.Key(http.MethodGet, "/projects/:id/bounties/:bounty_id/escrow/approval"): {Summary: "The message a maintainer signs to release an escrow bounty", Response: escrowApprovalResponse{}},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/escrow/release"): {
+			Summary:     "Release an escrow bounty to a contributor",
+			Description: "Needs the project owner's wallet signature over the escrow approval message. The contract call is submitted in the background, and the bounty is completed.",
+			Request:     escrowReleaseRequest{},
+			Response:    payouts.Payout{},
+			Status:      http.StatusCreated,
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/accounting-endpoint"): {Summary: "A project's accounting endpoint", Response: proofs.Endpoint{}},
+		openapi.Key(http.MethodPut, "/projects/:id/accounting-endpoint"): {
+			Summary:     "Set a project's accounting endpoint",
+			Description: "Every payout the project funds is then POSTed there as a signed proof of payment with an invoice number. A generated secret is returned once.",
+			Request:     putAccountingEndpointRequest{},
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/payment-proofs"):                      {Summary: "Proof-of-payment delivery log", Description: "Filter with ?status=pending|succeeded|failed."},
+		openapi.Key(http.MethodPost, "/projects/:id/payment-proofs/:proof_id/redeliver"): {Summary: "Send a proof of payment again", Response: proofs.Proof{}},
+		openapi.Key(http.MethodGet, "/projects/:id/metadata-fields/:entity"):             {Summary: "Custom metadata fields a project defines", Description: "entity is project or bounty."},
+		openapi.Key(http.MethodPut, "/projects/:id/metadata-fields/:entity"): {
+			Summary:     "Define a project's custom metadata fields",
+			Description: "Replaces the fields for the entity (project or bounty). Fields are string, number, boolean or enum, optionally required. Metadata is then validated against them and list endpoints filter on it with metadata.<key>=value.",
+			Request:     putMetadataFieldsRequest{},
+		},
+		openapi.Key(http.MethodPut, "/projects/:id/metadata"):                     {Summary: "Set a project's custom metadata", Request: setMetadataRequest{}},
+		openapi.Key(http.MethodPut, "/projects/:id/bounties/:bounty_id/metadata"): {Summary: "Set a bounty's custom metadata", Description: "Accepts API keys with the bounties:write scope.", Request: setMetadataRequest{}, Response: bounties.Bounty{}},
+		openapi.Key(http.MethodGet, "/projects/:id/bounties/:bounty_id/messages"): {
+			Summary:     "A claim's end-to-end encrypted messages",
+			Description: "Between the project owner and the claimant, oldest first. The owner reads the current claimant's thread, or an earlier claimant's with claimant_id. Each message records the sender and recipient keys it was encrypted between (NaCl box).",
+			Query: []openapi.Param{
+				{Name: "since", Description: "Only messages after this time (RFC 3339)."},
+				{Name: "claimant_id", Description: "For the owner: whose thread to read."},
+				{Name: "limit", Type: "integer", Description: "At most 200 (the default)."},
+			},
+			Response: claimMessagesResponse{},
+			Changes:  []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Encrypted messaging on claims."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/messages"): {
+			Summary:     "Send an encrypted message on a claim",
+			Description: "Encrypt to the other side's key from /users/{id}/messaging-key after checking its wallet signature. sender_key and recipient_key must still be both sides' published keys, or the message is refused with 409 messaging_key_changed.",
+			Request:     messaging.Draft{},
+			Response:    messaging.Message{},
+			Status:      http.StatusCreated,
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Encrypted messaging on claims."}},
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/bounties/:bounty_id/time"): {
+			Summary:     "Time logged on a claim",
+			Description: "The claimant sees their own entries; the project's maintainers see the current claimant's, or another claimant's with user_id, next to the submission.",
+			Query:       []openapi.Param{{Name: "user_id", Description: "For maintainers: whose time to show."}},
+			Response:    bounties.TimeSummary{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Time tracking on claims."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/time"): {
+			Summary:     "Log time on a claim",
+			Description: "A manual entry by the claimant while the bounty is claimed or submitted. It must end after it starts, in the past, start after the claim and last at most 12 hours.",
+			Request:     logTimeRequest{},
+			Response:    bounties.TimeEntry{},
+			Status:      http.StatusCreated,
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Time tracking on claims."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/time/start"): {
+			Summary:     "Start a timer on a claim",
+			Description: "One timer runs at a time; starting a second is refused with 409.",
+			Request:     timerRequest{},
+			Response:    bounties.TimeEntry{},
+			Status:      http.StatusCreated,
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Time tracking on claims."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/bounties/:bounty_id/time/stop"): {
+			Summary:     "Stop the running timer on a claim",
+			Description: "Entries are capped at 12 hours.",
+			Response:    bounties.TimeEntry{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Time tracking on claims."}},
+		},
+		openapi.Key(http.MethodDelete, "/projects/:id/bounties/:bounty_id/time/:entry_id"): {Summary: "Delete one of the caller's time entries", Status: http.StatusNoContent},
+		openapi.Key(http.MethodGet, "/projects/:id/bounty-rates"): {
+			Summary:     "Suggested bounty amounts per hour",
+			Description: "The median amount per hour of completed bounties with logged time, per chain and asset: the project's own once it has five, otherwise the platform's.",
+			Response:    bountyRatesResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounty amount suggestions from logged time."}},
+		},
+		openapi.Key(http.MethodPut, "/me/messaging-key"): {
+			Summary:     "Publish the caller's messaging key",
+			Description: "An X25519 public key (base64), signed by one of the caller's linked wallets over \"Grainlify messaging key\\nUser: <user id>\\nKey: <key>\". Replaces any earlier key; unlinking the wallet withdraws it.",
+			Request:     messaging.Publication{},
+			Response:    messaging.Key{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Publishes a key for encrypted claim messages."}},
+		},
+		openapi.Key(http.MethodDelete, "/me/messaging-key"): {Summary: "Withdraw the caller's messaging key", Status: http.StatusNoContent},
+		openapi.Key(http.MethodGet, "/users/:id/messaging-key"): {
+			Summary:  "A user's messaging key and the wallet signature vouching for it",
+			Response: messaging.Key{},
+			Changes:  []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Serves keys for encrypted claim messages."}},
+		},
+		openapi.Key(http.MethodGet, "/bounties/:id/history"): {
+			Summary:     "A bounty's full history",
+			Description: "Every state transition, edit, split and escrow payout change with its actor and time, oldest first. For the project owner, admins and the bounty's split claimants and payees; accepts API keys with the bounties:read scope. Continue with ?since=<X-Next-Cursor>.",
+			Response:    bountyHistoryResponse{},
+		},
+		openapi.Key(http.MethodGet, "/projects"): {
+			Summary:     "List verified projects",
+			Description: "total counts every match. A full page comes with next_cursor.",
+			Query: listParams(projectsListSpec,
+				openapi.Param{Name: "tags", Description: "comma-separated; projects must have all of them"},
+				openapi.Param{Name: "offset", Type: "integer", Description: "deprecated; use cursor"}),
+			Changes: []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Sorting, star, fork and date ranges and cursor pagination; ecosystem, language and category take several, comma-separated.", Fields: []string{"next_cursor"}}},
+		},
+		openapi.Key(http.MethodGet, "/search"): {
+			Summary:     "Search projects and bounties",
+			Description: "Full-text search over verified projects (name, tags, description) and public bounties (title, issue, skills), best matches first. Snippets are HTML-escaped with matches in <mark>. A full page comes with next_offset.",
+			Query: []openapi.Param{
+				{Name: "q", Required: true, Description: "web search syntax: quoted phrases, or, -word; at most 200 characters"},
+				{Name: "type", Description: "project or bounty, comma-separated; both by default"},
+				{Name: "status", Description: "only bounties in this status"},
+				{Name: "limit", Type: "integer", Description: "at most 50; default 20"},
+				{Name: "offset", Type: "integer"},
+			},
+			Response: searchResponse{},
+			Changes:  []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Full-text search."}},
+		},
+		openapi.Key(http.MethodPost, "/projects"): {
+			Summary:     "Register a project",
+			Description: "Requires a linked GitHub account with admin rights on the repository.",
+			Request:     createProjectRequest{},
+			Status:      http.StatusCreated,
+		},
+		openapi.Key(http.MethodPatch, "/projects/:id"):  {Summary: "Edit a project's details", Request: projectDetails{}},
+		openapi.Key(http.MethodDelete, "/projects/:id"): {Summary: "Unregister a project", Description: "Refused while the project has open bounties.", Status: http.StatusNoContent},
+		openapi.Key(http.MethodPost, "/projects/:id/issues/:number/apply"): {
+			Summary: "Apply to work on an issue",
+			Request: applyToIssueRequest{},
+			Changes: []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "A malformed body is rejected with error code invalid_json instead of invalid_body."}},
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/health"): {Summary: "Review, response and CI metrics of a project", Response: repohealth.Health{}},
+		openapi.Key(http.MethodPost, "/deposit-intents"):    {Summary: "Create a deposit address", Request: createDepositIntentRequest{}, Response: deposits.Intent{}, Status: http.StatusCreated},
+		openapi.Key(http.MethodGet, "/deposit-intents/:id"): {Summary: "A deposit intent", Response: deposits.Intent{}},
+		openapi.Key(http.MethodGet, "/me/payout-methods"): {
+			Summary:  "The caller's ranked payout methods",
+			Response: payoutMethodsResponse{},
+			Changes:  []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Lists the chains and addresses the caller wants to be paid on, most preferred first."}},
+		},
+		openapi.Key(http.MethodPut, "/me/payout-methods"): {
+			Summary:     "Rank the caller's payout methods",
+			Description: "Methods are ranked in the order given, one per chain. Pending payouts go to the first method that has an equivalent asset, a hot wallet, no congestion and a minimum the amount meets; otherwise they keep their original destination. Payouts record the method used in method_rank and routed_from, and why better methods were skipped in routing_notes.",
+			Request:     setPayoutMethodsRequest{},
+			Response:    payoutMethodsResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Lets users rank payout methods that payouts fall through when a chain is congested or below its minimum."}},
+		},
+		openapi.Key(http.MethodGet, "/me/payouts"): {
+			Summary:     "The caller's payouts",
+			Description: "Payouts below their asset's threshold are accruing: they add up in accrued until the balance crosses the threshold or pays_by passes, then go out as one payout (settles_accrual) that each of them is merged_into. Accepts API keys with the payouts:read scope.",
+			Changes: []openapi.Change{
+				{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "Balances accrued below per-asset payout thresholds, and the payout an accrued one was merged into.", Fields: []string{"accrued", "merged_into", "settles_accrual"}},
+				{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "Payouts below their asset's threshold move to status accruing, then merged once paid out together."},
+				{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "The payout method a payout was routed to and why better-ranked ones were skipped.", Fields: []string{"method_rank", "routed_from", "routing_notes"}},
+				{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "Payouts final on chain move from status submitted to confirmed; reverted ones fail with error_code tx_reverted."},
+				{Date: "2026-10-16", Kind: openapi.ChangeFieldsAdded, Summary: "The block and time a payout was confirmed.", Fields: []string{"block_number", "confirmed_at"}},
+			},
+		},
+		openapi.Key(http.MethodGet, "/me/payouts/:id"): {
+			Summary:     "One of the caller's payouts",
+			Description: "Status moves pending (accruing then merged when below its asset's threshold), batched, submitted, then confirmed once the transaction is final on chain (block_number is the including block or ledger); a reverted transaction fails the payout with failure code tx_reverted. Accepts API keys with the payouts:read scope.",
+			Response:    payouts.Payout{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Shows one of the caller's payouts, to follow its confirmation."}},
+		},
+		openapi.Key(http.MethodGet, "/projects/:id/escrow"): {
+			Summary: "A project's escrow addresses, ledgered balances and recent deposits",
+			Changes: []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Shows a project's escrow funding."}},
+		},
+		openapi.Key(http.MethodPost, "/projects/:id/escrow/addresses"): {
+			Summary:     "Get a project's escrow address on a chain",
+			Description: "Derived on first use and stable after. Anything sent to it is credited to the project's escrow by the chain watcher; no memo is needed.",
+			Request:     createEscrowAddressRequest{},
+			Response:    deposits.ProjectAccount{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Hands out a project's escrow deposit address."}},
+		},
+		openapi.Key(http.MethodPost, "/relay/permit-transfer"): {Summary: "Relay a gasless claim", Request: relayClaimRequest{}, Status: http.StatusCreated},
+
+		// Integrations
+		openapi.Key(http.MethodPost, "/reports"):                  {Summary: "Report abuse", Request: createReportRequest{}, Response: moderation.Report{}, Status: http.StatusCreated},
+		openapi.Key(http.MethodPost, "/me/notification-channels"): {Summary: "Add a notification channel", Request: createNotificationChannelRequest{}, Status: http.StatusCreated},
+		openapi.Key(http.MethodGet, "/ws"): {
+			Summary:     "Real-time events over a WebSocket",
+			Description: "Upgrade with subprotocol grainlify; browsers, which cannot send an Authorization header, also offer bearer.<access token> as a subprotocol. Each message is a JSON event {seq, type, data, created_at} of type bounty.status_changed, bounty.comment_created or payout.status_changed, for public bounties, other bounties the caller can see and the caller's own payouts. Reconnect with after set to the last seq seen to replay what was missed; a message of type resync means more was missed than can be replayed. The socket is closed with code 4401 once the session it was opened with is revoked.",
+			Query:       []openapi.Param{{Name: "after", Type: "integer", Description: "replay events after this seq"}},
+			Status:      http.StatusSwitchingProtocols,
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Pushes bounty state changes, new comments and payout progress to connected clients."}},
+		},
+		openapi.Key(http.MethodGet, "/notifications"): {
+			Summary:     "Your notifications",
+			Description: "Newest first, with the count of unread ones. Continue a listing with before set to the created_at of its last item.",
+			Query:       []openapi.Param{{Name: "unread", Type: "boolean", Description: "only unread notifications"}, {Name: "before", Description: "RFC 3339 timestamp"}, {Name: "limit", Type: "integer"}},
+			Response:    notificationsResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Lists in-app notifications for claimed bounties, requested reviews and sent payouts."}},
+		},
+		openapi.Key(http.MethodPost, "/notifications/:id/read"): {
+			Summary: "Mark a notification read",
+			Status:  http.StatusNoContent,
+			Changes: []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Marks an in-app notification read."}},
+		},
+		openapi.Key(http.MethodPost, "/notifications/read-all"): {
+			Summary:  "Mark all notifications read",
+			Response: markAllReadResponse{},
+			Changes:  []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Marks every unread notification read."}},
+		},
+		openapi.Key(http.MethodGet, "/me/notification-preferences"): {
+			Summary:     "Your notification preferences",
+			Description: "Which channels each notification type is sent on; everything is on until turned off.",
+			Response:    notificationPreferencesResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Shows per-type, per-channel notification preferences."}},
+		},
+		openapi.Key(http.MethodPut, "/me/notification-preferences"): {
+			Summary:     "Change notification preferences",
+			Description: "Settings not listed are kept. Unknown types or channels are rejected with 400.",
+			Request:     setNotificationPreferencesRequest{},
+			Response:    notificationPreferencesResponse{},
+			Changes:     []openapi.Change{{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Turns notification channels on or off per type."}},
+		},
+		openapi.Key(http.MethodPost, "/me/webhooks"): {
+			Summary:     "Register a webhook endpoint",
+			Description: "Deliveries are signed: X-Grainlify-Signature is t=<unix>,v1=<hex HMAC-SHA256 of \"<t>.<body>\">.",
+			Request:     createWebhookRequest{},
+			Response:    createWebhookResponse{},
+			Status:      http.StatusCreated,
+		},
+		openapi.Key(http.MethodPatch, "/me/webhooks/:id"): {Summary: "Pause or resume a webhook endpoint", Request: updateWebhookRequest{}, Response: webhooks.Endpoint{}},
+		openapi.Key(http.MethodGet, "/me/webhooks/:id/deliveries"): {
+			Summary:  "Delivery log of a webhook endpoint",
+			Query:    []openapi.Param{{Name: "status", Description: "pending, succeeded or failed"}, {Name: "limit", Type: "integer"}, {Name: "offset", Type: "integer"}},
+			Response: webhookDeliveriesResponse{},
+		},
+		openapi.Key(http.MethodPost, "/me/webhooks/:id/deliveries/:delivery_id/redeliver"): {Summary: "Send a delivery again", Response: webhooks.Delivery{}},
+	}
+}
+
+// APIChanges are the changelog entries that apply to the whole API rather
+// than a route; route changes are annotated on OpenAPIOperations.
+func APIChanges() []openapi.Change {
+	return []openapi.Change{
+		{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "POST /auth/verify accepts an Idempotency-Key header when token encryption keys are configured; a retry with the same key and request replays the first response, including its token pair."},
+		{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "Browser origins on localhost are only allowed by default when APP_ENV is dev; set CORS_ALLOW_LOCALHOST=true to allow them elsewhere. Setting CORS_ORIGINS replaces the default https://*.vercel.app allowance rather than adding to it."},
+		{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "Payouts queued through POST /payouts or a bounty payment run the admin fraud rules for the payout event; a matching payout is flagged for review."},
+		{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "API keys of banned or suspended users are rejected with 403 account_restricted."},
+		{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "Admins of a linked GitHub organization can manage the organization's projects wherever the project owner can, and see them in /projects/mine."},
+		{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "List endpoints for users, projects, bounties and the audit trail share one query syntax: sort=-field,field, field=a,b and field[ne|gt|gte|lt|lte]=v filters, limit, and cursor from the previous page's next_cursor. Bad parameters are rejected with 400 invalid_sort, invalid_filter or invalid_cursor."},
+		{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "Responses carry X-Content-Type-Options, X-Frame-Options, Referrer-Policy and Content-Security-Policy headers, plus Strict-Transport-Security in production. CORS preflight responses are cached for CORS_MAX_AGE_SECONDS."},
+		{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Payout, bounty create and pay, project verify and email verify requests accept an Idempotency-Key header (up to 255 printable characters). A retry with the same key and request replays the first response with Idempotent-Replayed: true; the same key with a different request gets 422 idempotency_key_reused, and while the first is still running 409 idempotency_key_in_use. Keys expire after 24 hours by default."},
+		{Date: "2026-10-16", Kind: openapi.ChangeAdded, Summary: "Bounties can be scoped to a directory of a monorepo with path_prefix. A merged pull request only settles a scoped bounty when it changes files under that directory, and the bounty's skill tags are detected from that subtree rather than the whole repo. Bounty payloads, including webhook data, carry path_prefix when set."},
+		{Date: "2026-10-16", Kind: openapi.ChangeChanged, Summary: "JSON request bodies are decoded strictly: unknown fields, mistyped values and trailing data are rejected with 400 invalid_json naming the field in details.field, and non-JSON content types with 415 unsupported_content_type."},
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/payouts"
+)
+
+type payoutMethodsResponse struct {
+	Methods []payouts.PayoutMethod `json:"methods"`
+}
+
+// PayoutMethods lists the caller's payout methods, most preferred first.
+func (h *PayoutsHandler) PayoutMethods() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		methods, err := payouts.Methods(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "payout_methods_lookup_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(payoutMethodsResponse{Methods: methods})
+	}
+}
+
+type setPayoutMethodsRequest struct {
+	// Methods are ranked in the order given; ranks sent are ignored.
+	Methods []payouts.PayoutMethod `json:"methods"`
+}
+
+// SetPayoutMethods replaces the caller's ranked payout methods. Pending
+// payouts fall through them in order when a chain is congested, has no hot
+// wallet or the amount is below its minimum.
+func (h *PayoutsHandler) SetPayoutMethods() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req setPayoutMethodsRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		methods, err := payouts.SetMethods(c.Context(), h.db.Pool, userID, req.Methods)
+		for _, e := range []error{payouts.ErrTooManyMethods, payouts.ErrMethodChain, payouts.ErrMethodAddress, payouts.ErrDuplicateMethodChain} {
+			if errors.Is(err, e) {
+				return httpx.Write(c, httpx.New(fiber.StatusBadRequest, e.Error()).WithMessage(err.Error()))
+			}
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "payout_methods_update_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(payoutMethodsResponse{Methods: methods})
+	}
+}
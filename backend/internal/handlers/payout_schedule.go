@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/payouts"
+)
+
+// ListWindows shows the payout windows and when the next one opens.
+func (h *PayoutsHandler) ListWindows() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		windows, err := payouts.ListWindows(c.Context(), h.db.Pool)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payout_windows_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"windows":     windows,
+			"next_window": payouts.NextWindow(windows, time.Now()),
+		})
+	}
+}
+
+type createWindowRequest struct {
+	// Weekday is a day name ("friday" or "fri"); At is "HH:MM" in UTC.
+	Weekday string `json:"weekday"`
+	At      string `json:"at"`
+}
+
+func (h *PayoutsHandler) CreateWindow() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req createWindowRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		spec, err := payouts.ParseWindow(req.Weekday, req.At)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, err.Error())
+		}
+		w, err := payouts.CreateWindow(c.Context(), h.db.Pool, spec.Weekday, spec.Hour, spec.Minute, actorID)
+		switch {
+		case errors.Is(err, payouts.ErrWindowExists):
+			return httpx.Fail(c, fiber.StatusConflict, err.Error())
+		case errors.Is(err, payouts.ErrInvalidWindow):
+			return httpx.Fail(c, fiber.StatusBadRequest, err.Error())
+		case err != nil:
+			httpx.Logger(c).Error("failed to create payout window", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payout_window_create_failed")
+		}
+		httpx.Logger(c).Info("payout window created",
+			"window_id", w.ID.String(),
+			"window", w.String(),
+			"actor_user_id", actorID.String(),
+		)
+		return c.Status(fiber.StatusCreated).JSON(w)
+	}
+}
+
+func (h *PayoutsHandler) DeleteWindow() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_window_id")
+		}
+		err = payouts.DeleteWindow(c.Context(), h.db.Pool, id)
+		if errors.Is(err, payouts.ErrWindowNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, err.Error())
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payout_window_delete_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// ListWindowRuns returns the per-window summaries, newest first.
+func (h *PayoutsHandler) ListWindowRuns() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		runs, err := payouts.ListWindowRuns(c.Context(), h.db.Pool, 50)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payout_window_runs_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"runs": runs})
+	}
+}
@@ -0,0 +1,500 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/commitsig"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/fraud"
+	"github.com/jagadeesh/grainlify/backend/internal/geo"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/payouts"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+type PayoutsHandler struct {
+	cfg     config.Config
+	db      *db.DB
+	wallets wallet.Registry
+	fees    payouts.RelayerFees
+	// thresholds are shown with the caller's accrued balances.
+	thresholds payouts.Thresholds
+	batcher    *payouts.Batcher
+	// balances caches wallet balance previews.
+	balances *wallet.BalanceCache
+}
+
+func NewPayoutsHandler(cfg config.Config, d *db.DB, wallets wallet.Registry) *PayoutsHandler {
+	h := &PayoutsHandler{
+		cfg:        cfg,
+		db:         d,
+		wallets:    wallets,
+		fees:       payouts.ParseRelayerFees(cfg.RelayerFees),
+		thresholds: payouts.ParseThresholds(cfg.PayoutThresholds),
+		balances:   wallet.NewBalanceCache(time.Duration(cfg.WalletBalanceCacheSeconds) * time.Second),
+	}
+	if d != nil && d.Pool != nil {
+		h.batcher = &payouts.Batcher{Pool: d.Pool, Wallets: wallets, MaxBatch: cfg.PayoutMaxBatch, Router: payouts.NewRouterFromConfig(cfg, wallets), Thresholds: h.thresholds}
+	}
+	return h
+}
+
+func (h *PayoutsHandler) Mine() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		out, err := payouts.ListForUser(c.Context(), h.db.Pool, userID, 100)
+		if err != nil {
+			httpx.Logger(c).Error("failed to list payouts", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payouts_list_failed")
+		}
+		// Pending payouts go out in the next window, when windows are defined.
+		windows, err := payouts.ListWindows(c.Context(), h.db.Pool)
+		if err != nil {
+			httpx.Logger(c).Error("failed to list payout windows", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payouts_list_failed")
+		}
+		accrued, err := payouts.Accrued(c.Context(), h.db.Pool, userID, h.thresholds)
+		if err != nil {
+			httpx.Logger(c).Error("failed to list accrued payouts", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payouts_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"payouts":     out,
+			"next_window": payouts.NextWindow(windows, time.Now()),
+			"accrued":     accrued,
+		})
+	}
+}
+
+// MyPayout reports where one of the caller's payouts stands, including its
+// transaction and, once final, the block that included it.
+func (h *PayoutsHandler) MyPayout() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		return h.get(c, &userID)
+	}
+}
+
+// Get reports where any payout stands.
+func (h *PayoutsHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return h.get(c, nil)
+	}
+}
+
+func (h *PayoutsHandler) get(c *fiber.Ctx, userID *uuid.UUID) error {
+	if h.db == nil || h.db.Pool == nil {
+		return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return httpx.Fail(c, fiber.StatusBadRequest, "invalid_payout_id")
+	}
+	p, err := payouts.Get(c.Context(), h.db.Pool, id, userID)
+	if errors.Is(err, payouts.ErrNotFound) {
+		return httpx.Fail(c, fiber.StatusNotFound, "payout_not_found")
+	}
+	if err != nil {
+		httpx.Logger(c).Error("failed to load payout", "payout_id", id.String(), "error", err)
+		return httpx.Fail(c, fiber.StatusInternalServerError, "payout_lookup_failed")
+	}
+	return c.Status(fiber.StatusOK).JSON(p)
+}
+
+// relayErrorCode maps request-level relay/preview errors to 400 codes.
+func relayErrorCode(err error) (string, bool) {
+	for _, e := range []error{payouts.ErrRelayNotOffered, payouts.ErrBelowRelayerFee, payouts.ErrUnknownMethod} {
+		if errors.Is(err, e) {
+			return e.Error(), true
+		}
+	}
+	return "", false
+}
+
+// Preview shows the recipient's net amount for a payout method, including the
+// relayer fee when claiming gaslessly with a permit.
+func (h *PayoutsHandler) Preview() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, err := wallet.ParseAmount(c.Query("amount")); err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_amount")
+		}
+		p, err := payouts.BuildPreview(h.wallets, h.fees, c.Query("chain"), c.Query("asset"), c.Query("amount"), c.Query("method"))
+		if code, ok := relayErrorCode(err); ok {
+			return httpx.Fail(c, fiber.StatusBadRequest, code)
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "preview_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(p)
+	}
+}
+
+// PermitRequest returns the EIP-2612 typed data the token owner signs to have
+// the relayer move their tokens without holding native gas.
+func (h *PayoutsHandler) PermitRequest() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, err := wallet.ParseAmount(c.Query("amount")); err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_amount")
+		}
+		req, err := payouts.NewPermitRequest(c.Context(), h.wallets, h.fees, c.Query("chain"), c.Query("token"), c.Query("owner"), c.Query("amount"))
+		if code, ok := relayErrorCode(err); ok {
+			return httpx.Fail(c, fiber.StatusBadRequest, code)
+		}
+		if err != nil {
+			httpx.Logger(c).Warn("permit request failed", "chain", c.Query("chain"), "token", c.Query("token"), "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "permit_request_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(req)
+	}
+}
+
+type relayClaimRequest struct {
+	Chain     string `json:"chain"`
+	Token     string `json:"token"`
+	Owner     string `json:"owner"`
+	To        string `json:"to"`
+	Amount    string `json:"amount"`
+	Deadline  int64  `json:"deadline"`
+	Signature string `json:"signature"`
+}
+
+// RelayClaim submits a signed permit; the owner receives amount minus the
+// relayer fee at the destination.
+func (h *PayoutsHandler) RelayClaim() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req relayClaimRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if _, err := wallet.ParseAmount(req.Amount); err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_amount")
+		}
+		if req.Signature == "" || req.Owner == "" || req.To == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "owner_to_and_signature_required")
+		}
+		if blocked, err := rejectGeoRestricted(c, h.cfg, h.db.Pool, geo.ActionPayout, userID, true); blocked {
+			return err
+		}
+		rt, err := payouts.Relay(c.Context(), h.db.Pool, h.wallets, h.fees, userID, payouts.RelayClaim{
+			Chain:     req.Chain,
+			Token:     req.Token,
+			Owner:     req.Owner,
+			To:        req.To,
+			Amount:    req.Amount,
+			Deadline:  req.Deadline,
+			Signature: req.Signature,
+		})
+		if code, ok := relayErrorCode(err); ok {
+			return httpx.Fail(c, fiber.StatusBadRequest, code)
+		}
+		if err != nil {
+			httpx.Logger(c).Warn("permit relay failed", "user_id", userID.String(), "error", err)
+			if rt.ID != uuid.Nil {
+				return httpx.Write(c, httpx.New(fiber.StatusUnprocessableEntity, "relay_failed").With("transfer", rt))
+			}
+			return httpx.Fail(c, fiber.StatusInternalServerError, "relay_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(rt)
+	}
+}
+
+func (h *PayoutsHandler) MyRelayed() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		out, err := payouts.ListRelayed(c.Context(), h.db.Pool, userID, 100)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "relayed_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"transfers": out})
+	}
+}
+
+func (h *PayoutsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		limit := c.QueryInt("limit", 50)
+		if limit < 1 || limit > 200 {
+			limit = 50
+		}
+		out, err := payouts.List(c.Context(), h.db.Pool, strings.TrimSpace(c.Query("status")), limit)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payouts_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"payouts": out})
+	}
+}
+
+type createPayoutRequest struct {
+	UserID    string `json:"user_id"`
+	Chain     string `json:"chain"`
+	Asset     string `json:"asset"`
+	ToAddress string `json:"to_address"`
+	Amount    string `json:"amount"`
+	Reference string `json:"reference"`
+	Repo      string `json:"repo_full_name"`
+	PRNumber  *int   `json:"pr_number"`
+	PRURL     string `json:"pr_url"`
+}
+
+func (h *PayoutsHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req createPayoutRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		userID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		// EVM payouts default to the user's primary linked EVM wallet. Linked
+		// Stellar wallets are stored by public key, not account address.
+		if strings.TrimSpace(req.ToAddress) == "" && req.Chain != "" && !strings.EqualFold(strings.TrimSpace(req.Chain), "stellar") {
+			w, err := auth.PrimaryWallet(c.Context(), h.db.Pool, userID, auth.WalletTypeEVM)
+			if err != nil && !errors.Is(err, auth.ErrWalletNotFound) {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "wallet_lookup_failed")
+			}
+			if err == nil {
+				req.ToAddress = w.Address
+			}
+		}
+		if strings.TrimSpace(req.Chain) == "" || strings.TrimSpace(req.Asset) == "" || strings.TrimSpace(req.ToAddress) == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "chain_asset_and_address_required")
+		}
+		if amt, err := wallet.ParseAmount(req.Amount); err != nil || amt.Sign() == 0 {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_amount")
+		}
+		// The recipient isn't the caller, so their last sign-in country is used.
+		if blocked, err := rejectGeoRestricted(c, h.cfg, h.db.Pool, geo.ActionPayout, userID, false); blocked {
+			return err
+		}
+		p := payouts.Payout{
+			UserID: userID,
+			Chain:  req.Chain,
+			Asset:  req.Asset,
+			To:     req.ToAddress,
+			Amount: req.Amount,
+		}
+		if ref := strings.TrimSpace(req.Reference); ref != "" {
+			p.Reference = &ref
+		}
+		if repo := strings.TrimSpace(req.Repo); repo != "" && req.PRNumber != nil {
+			if *req.PRNumber < 1 || !strings.Contains(repo, "/") {
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_pr_reference")
+			}
+			p.Repo, p.PRNumber = &repo, req.PRNumber
+			if u := strings.TrimSpace(req.PRURL); u != "" {
+				p.PRURL = &u
+			}
+		}
+		p, err = payouts.Create(c.Context(), h.db.Pool, p)
+		if err != nil {
+			httpx.Logger(c).Error("failed to create payout", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payout_create_failed")
+		}
+		httpx.Logger(c).Info("payout queued",
+			"actor_user_id", actorID.String(),
+			"payout_id", p.ID.String(),
+			"user_id", p.UserID.String(),
+			"chain", p.Chain,
+			"asset", p.Asset,
+			"amount", p.Amount,
+		)
+		flagged := evaluatePayoutRules(c, h.db.Pool, p)
+		if h.flagUnsignedPayout(c.Context(), p) {
+			flagged = true
+		}
+		if flagged {
+			p = holdPayout(c, h.db.Pool, p)
+		}
+		return c.Status(fiber.StatusCreated).JSON(p)
+	}
+}
+
+// evaluatePayoutRules runs the admin fraud rules for the payout event on a
+// newly queued payout and reports whether any matched, queueing a review
+// for each; failures only log.
+func evaluatePayoutRules(c *fiber.Ctx, pool *pgxpool.Pool, p payouts.Payout) bool {
+	amount, _ := strconv.ParseFloat(p.Amount, 64)
+	matches, err := fraud.NewEngine(pool).Evaluate(c.Context(), fraud.Subject{
+		Event:     fraud.EventPayout,
+		UserID:    p.UserID,
+		SubjectID: p.ID.String(),
+		IP:        c.IP(),
+		Amount:    amount,
+	})
+	if err != nil {
+		httpx.Logger(c).Warn("fraud evaluation failed for payout", "payout_id", p.ID.String(), "error", err)
+	}
+	return len(matches) > 0
+}
+
+// holdPayout holds a flagged payout until its fraud reviews are resolved
+// (see payouts.ResolveHold). It returns the payout as it now stands.
+func holdPayout(c *fiber.Ctx, pool *pgxpool.Pool, p payouts.Payout) payouts.Payout {
+	held, err := payouts.Hold(c.Context(), pool, p.ID)
+	if err != nil {
+		// Already picked up by a batch, or gone: nothing left to hold.
+		httpx.Logger(c).Warn("failed to hold flagged payout", "payout_id", p.ID.String(), "error", err)
+		return p
+	}
+	httpx.Logger(c).Info("flagged payout held for review", "payout_id", p.ID.String())
+	return held
+}
+
+// flagUnsignedPayout queues a fraud review when a payout of at least
+// UnsignedPayoutReviewAmount pays for a pull request whose commits by the
+// recipient aren't all signed with a key on their linked GitHub account, and
+// reports whether it did; failures only log.
+func (h *PayoutsHandler) flagUnsignedPayout(ctx context.Context, p payouts.Payout) bool {
+	threshold, err := strconv.ParseFloat(h.cfg.UnsignedPayoutReviewAmount, 64)
+	if err != nil || threshold <= 0 || p.Repo == nil || p.PRNumber == nil {
+		return false
+	}
+	amount, err := strconv.ParseFloat(p.Amount, 64)
+	if err != nil || amount < threshold {
+		return false
+	}
+	s, linked, err := commitsig.CheckPR(ctx, h.db.Pool, nil, h.cfg.TokenEncKeyB64, p.UserID, *p.Repo, *p.PRNumber)
+	if err != nil {
+		slog.Warn("commit signature check failed for payout", "payout_id", p.ID.String(), "error", err)
+		return false
+	}
+	if linked && s.AllVerified() {
+		return false
+	}
+	facts := fraud.Facts{
+		"amount":            amount,
+		"pr_commits":        float64(s.Commits),
+		"pr_signed_commits": float64(s.Verified),
+		"github_linked":     0,
+	}
+	if linked {
+		facts["github_linked"] = 1
+	}
+	if _, err := fraud.NewEngine(h.db.Pool).Flag(ctx, "unsigned_commits", fraud.Subject{
+		Event:     fraud.EventPayout,
+		UserID:    p.UserID,
+		SubjectID: p.ID.String(),
+		Amount:    amount,
+	}, facts); err != nil {
+		slog.Warn("failed to flag unsigned payout", "payout_id", p.ID.String(), "error", err)
+		return false
+	}
+	return true
+}
+
+func (h *PayoutsHandler) Cancel() fiber.Handler {
+	return h.transition("cancel", payouts.Cancel)
+}
+
+func (h *PayoutsHandler) Retry() fiber.Handler {
+	return h.transition("retry", payouts.Retry)
+}
+
+func (h *PayoutsHandler) transition(action string, fn func(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (payouts.Payout, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_payout_id")
+		}
+		p, err := fn(c.Context(), h.db.Pool, id)
+		switch {
+		case errors.Is(err, payouts.ErrNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "payout_not_found")
+		case errors.Is(err, payouts.ErrInvalidStatus):
+			return httpx.Fail(c, fiber.StatusConflict, "invalid_payout_status")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payout_"+action+"_failed")
+		}
+		httpx.Logger(c).Info("payout "+action, "actor_user_id", actorID.String(), "payout_id", id.String())
+		return c.Status(fiber.StatusOK).JSON(p)
+	}
+}
+
+func (h *PayoutsHandler) ListBatches() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		limit := c.QueryInt("limit", 50)
+		if limit < 1 || limit > 200 {
+			limit = 50
+		}
+		out, err := payouts.ListBatches(c.Context(), h.db.Pool, limit)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "batches_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"batches": out})
+	}
+}
+
+// RunBatches sends pending payouts now instead of waiting for the schedule or
+// the next payout window.
+func (h *PayoutsHandler) RunBatches() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.batcher == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		res, err := h.batcher.RunNow(c.Context())
+		if err != nil {
+			httpx.Logger(c).Error("payout batch run failed", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "payout_run_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(res)
+	}
+}
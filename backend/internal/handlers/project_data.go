@@ -11,6 +11,9 @@ import (
 
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
 )
 
 type ProjectDataHandler struct {
@@ -28,23 +31,39 @@ func (h *ProjectDataHandler) Issues() fiber.Handler {
 			return err
 		}
 		if !ownerOK {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+			return httpx.Fail(c, fiber.StatusForbidden, "forbidden")
 		}
 
-		rows, err := h.db.Pool.Query(c.Context(), `
+		stream := wantsNDJSON(c)
+		rows, cancel, err := listQuery(c, h.db.Pool, stream, `
 SELECT github_issue_id, number, state, title, body, author_login, url, assignees, labels, comments_count, comments, updated_at_github, last_seen_at
 FROM github_issues
 WHERE project_id = $1
 ORDER BY COALESCE(updated_at_github, last_seen_at) DESC
-LIMIT 50
-`, projectID)
+LIMIT $2
+`, projectID, pageLimit(stream, 50))
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "issues_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "issues_list_failed")
 		}
-		defer rows.Close()
 
-		var out []fiber.Map
-		for rows.Next() {
+		// Comments from shadow-banned users stay hidden from everyone but their
+		// author, who also sees what they posted that never reached GitHub.
+		hidden, err := moderation.HiddenLogins(c.Context(), h.db.Pool)
+		if err != nil {
+			httpx.Logger(c).Warn("failed to load shadow-banned logins", "error", err)
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		viewerID, _ := uuid.Parse(sub)
+		var viewerLogin string
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT login FROM github_accounts WHERE user_id = $1`, viewerID).Scan(&viewerLogin); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			httpx.Logger(c).Warn("failed to load viewer github login", "error", err)
+		}
+		own, err := moderation.OwnShadowedComments(c.Context(), h.db.Pool, viewerID, viewerLogin, projectID)
+		if err != nil {
+			httpx.Logger(c).Warn("failed to load viewer's shadowed comments", "error", err)
+		}
+
+		scan := func(rows pgx.Rows) (any, error) {
 			var gid int64
 			var number int
 			var state, title, author, url string
@@ -54,9 +73,9 @@ LIMIT 50
 			var updated *time.Time
 			var lastSeen time.Time
 			if err := rows.Scan(&gid, &number, &state, &title, &body, &author, &url, &assigneesJSON, &labelsJSON, &commentsCount, &commentsJSON, &updated, &lastSeen); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "issues_list_failed"})
+				return nil, err
 			}
-			
+
 			// Parse JSONB fields
 			var assignees []any
 			var labels []any
@@ -69,9 +88,14 @@ LIMIT 50
 			}
 			if len(commentsJSON) > 0 {
 				_ = json.Unmarshal(commentsJSON, &comments)
+				comments = moderation.FilterComments(comments, hidden, viewerLogin)
 			}
-			
-			out = append(out, fiber.Map{
+			if mine := own[number]; len(mine) > 0 {
+				comments = append(comments, mine...)
+				commentsCount += len(mine)
+			}
+
+			return fiber.Map{
 				"github_issue_id": gid,
 				"number":          number,
 				"state":           state,
@@ -80,12 +104,20 @@ LIMIT 50
 				"author_login":    author,
 				"assignees":       assignees,
 				"labels":          labels,
-				"comments_count": commentsCount,
+				"comments_count":  commentsCount,
 				"comments":        comments, // Actual comments array
 				"url":             url,
 				"updated_at":      updated,
 				"last_seen_at":    lastSeen,
-			})
+			}, nil
+		}
+		if stream {
+			return streamNDJSON(c, rows, cancel, scan)
+		}
+		defer cancel()
+		out, err := collectRows(rows, scan)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "issues_list_failed")
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"issues": out})
 	}
@@ -98,24 +130,23 @@ func (h *ProjectDataHandler) PRs() fiber.Handler {
 			return err
 		}
 		if !ownerOK {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+			return httpx.Fail(c, fiber.StatusForbidden, "forbidden")
 		}
 
-		rows, err := h.db.Pool.Query(c.Context(), `
+		stream := wantsNDJSON(c)
+		rows, cancel, err := listQuery(c, h.db.Pool, stream, `
 SELECT github_pr_id, number, state, title, author_login, url, merged, 
        created_at_github, updated_at_github, closed_at_github, merged_at_github, last_seen_at
 FROM github_pull_requests
 WHERE project_id = $1
 ORDER BY COALESCE(updated_at_github, last_seen_at) DESC
-LIMIT 50
-`, projectID)
+LIMIT $2
+`, projectID, pageLimit(stream, 50))
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "prs_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "prs_list_failed")
 		}
-		defer rows.Close()
 
-		var out []fiber.Map
-		for rows.Next() {
+		scan := func(rows pgx.Rows) (any, error) {
 			var gid int64
 			var number int
 			var state, title, author, url string
@@ -123,22 +154,30 @@ LIMIT 50
 			var createdAt, updated, closedAt, mergedAt *time.Time
 			var lastSeen time.Time
 			if err := rows.Scan(&gid, &number, &state, &title, &author, &url, &merged, &createdAt, &updated, &closedAt, &mergedAt, &lastSeen); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "prs_list_failed"})
+				return nil, err
 			}
-			out = append(out, fiber.Map{
-				"github_pr_id":    gid,
-				"number":          number,
-				"state":           state,
-				"title":           title,
-				"author_login":    author,
-				"url":             url,
-				"merged":          merged,
-				"created_at":       createdAt,
-				"updated_at":      updated,
-				"closed_at":       closedAt,
-				"merged_at":       mergedAt,
-				"last_seen_at":    lastSeen,
-			})
+			return fiber.Map{
+				"github_pr_id": gid,
+				"number":       number,
+				"state":        state,
+				"title":        title,
+				"author_login": author,
+				"url":          url,
+				"merged":       merged,
+				"created_at":   createdAt,
+				"updated_at":   updated,
+				"closed_at":    closedAt,
+				"merged_at":    mergedAt,
+				"last_seen_at": lastSeen,
+			}, nil
+		}
+		if stream {
+			return streamNDJSON(c, rows, cancel, scan)
+		}
+		defer cancel()
+		out, err := collectRows(rows, scan)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "prs_list_failed")
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"prs": out})
 	}
@@ -151,36 +190,43 @@ func (h *ProjectDataHandler) Events() fiber.Handler {
 			return err
 		}
 		if !ownerOK {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+			return httpx.Fail(c, fiber.StatusForbidden, "forbidden")
 		}
 
-		rows, err := h.db.Pool.Query(c.Context(), `
+		stream := wantsNDJSON(c)
+		rows, cancel, err := listQuery(c, h.db.Pool, stream, `
 SELECT delivery_id, event, action, received_at
 FROM github_events
 WHERE project_id = $1
 ORDER BY received_at DESC
-LIMIT 50
-`, projectID)
+LIMIT $2
+`, projectID, pageLimit(stream, 50))
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "events_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "events_list_failed")
 		}
-		defer rows.Close()
 
-		var out []fiber.Map
-		for rows.Next() {
+		scan := func(rows pgx.Rows) (any, error) {
 			var deliveryID string
 			var event string
 			var action *string
 			var receivedAt time.Time
 			if err := rows.Scan(&deliveryID, &event, &action, &receivedAt); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "events_list_failed"})
+				return nil, err
 			}
-			out = append(out, fiber.Map{
-				"delivery_id":  deliveryID,
-				"event":        event,
-				"action":       action,
-				"received_at":  receivedAt,
-			})
+			return fiber.Map{
+				"delivery_id": deliveryID,
+				"event":       event,
+				"action":      action,
+				"received_at": receivedAt,
+			}, nil
+		}
+		if stream {
+			return streamNDJSON(c, rows, cancel, scan)
+		}
+		defer cancel()
+		out, err := collectRows(rows, scan)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "events_list_failed")
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"events": out})
 	}
@@ -188,29 +234,32 @@ LIMIT 50
 
 func (h *ProjectDataHandler) authorizeProject(c *fiber.Ctx) (uuid.UUID, bool, error) {
 	if h.db == nil || h.db.Pool == nil {
-		return uuid.Nil, false, c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		return uuid.Nil, false, httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 	}
 	sub, _ := c.Locals(auth.LocalUserID).(string)
 	userID, err := uuid.Parse(sub)
 	if err != nil {
-		return uuid.Nil, false, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		return uuid.Nil, false, httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 	}
 	projectID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return uuid.Nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+		return uuid.Nil, false, httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 	}
 
 	var owner uuid.UUID
 	err = h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, projectID).Scan(&owner)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return uuid.Nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+		return uuid.Nil, false, httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
 	}
 	if err != nil {
-		return uuid.Nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		return uuid.Nil, false, httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
 	}
 
 	role, _ := c.Locals(auth.LocalRole).(string)
-	ownerOK := owner == userID || role == "admin"
+	ownerOK, err := managesProject(c.Context(), h.db.Pool, projectID, owner, userID, role, orgs.PermManageProjects)
+	if err != nil {
+		return uuid.Nil, false, httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+	}
 	return projectID, ownerOK, nil
 }
 
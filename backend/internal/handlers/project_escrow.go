@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/deposits"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+	"github.com/jagadeesh/grainlify/backend/internal/treasury"
+)
+
+// ProjectEscrow shows where the project funds bounties, what the ledger
+// holds for it and the latest deposits credited.
+func (h *DepositsHandler) ProjectEscrow() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermViewFinances)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		accounts, err := deposits.ListProjectAccounts(c.Context(), h.db.Pool, projectID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "escrow_lookup_failed")
+		}
+		balances, err := deposits.ProjectBalances(c.Context(), h.db.Pool, projectID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "escrow_lookup_failed")
+		}
+		recent, err := deposits.ListProjectDeposits(c.Context(), h.db.Pool, projectID, 50)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "escrow_lookup_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"accounts": accounts,
+			"balances": balances,
+			"deposits": recent,
+		})
+	}
+}
+
+type createEscrowAddressRequest struct {
+	Chain string `json:"chain"`
+}
+
+// CreateEscrowAddress returns the project's escrow address on a chain,
+// deriving it on first use; asking again returns the same address.
+func (h *DepositsHandler) CreateEscrowAddress() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		projectID, respErr := managedProject(c, h.db, orgs.PermManageProjects)
+		if projectID == uuid.Nil {
+			return respErr
+		}
+		if h.svc == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		var req createEscrowAddressRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		pa, err := h.svc.ProjectAccount(c.Context(), projectID, req.Chain)
+		switch {
+		case errors.Is(err, deposits.ErrUnsupportedChain):
+			return httpx.Fail(c, fiber.StatusBadRequest, "unsupported_chain")
+		case errors.Is(err, deposits.ErrProjectNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
+		case errors.Is(err, deposits.ErrGapLimit):
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "deposit_addresses_exhausted")
+		case err != nil:
+			httpx.Logger(c).Error("failed to create escrow address", "project_id", projectID.String(), "chain", req.Chain, "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "escrow_address_create_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(pa)
+	}
+}
+
+// EscrowReconciliation compares each project escrow address's live balance
+// with the deposits ledgered for it (admin).
+func (h *TreasuryAdminHandler) EscrowReconciliation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		r, err := treasury.ReconcileEscrow(c.Context(), h.db.Pool, h.wallets)
+		if err != nil {
+			httpx.Logger(c).Error("escrow reconciliation failed", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "escrow_reconciliation_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(r)
+	}
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
+)
+
+const (
+	maxProjectDescription = 500
+	maxProjectTags        = 20
+	maxProjectTagLength   = 32
+)
+
+// projectDetails are the maintainer-editable fields of a project, shared by
+// registration and PATCH. Nil fields are left alone.
+type projectDetails struct {
+	Description       *string   `json:"description,omitempty"`
+	Tags              *[]string `json:"tags,omitempty"`
+	Language          *string   `json:"language,omitempty"`
+	Category          *string   `json:"category,omitempty"`
+	EcosystemName     *string   `json:"ecosystem_name,omitempty"`
+	FundingWalletType *string   `json:"funding_wallet_type,omitempty"`
+	FundingAddress    *string   `json:"funding_address,omitempty"`
+}
+
+// normalize trims the fields and validates them, returning the tags as
+// JSONB and the normalized funding address. An empty funding address clears
+// it; the wallet type is required alongside a non-empty one.
+func (d *projectDetails) normalize() (tagsJSON []byte, funding *string, err *httpx.Error) {
+	if d.Description != nil {
+		v := strings.TrimSpace(*d.Description)
+		if len(v) > maxProjectDescription {
+			return nil, nil, httpx.New(fiber.StatusBadRequest, "invalid_description").
+				WithMessage(fmt.Sprintf("Description is limited to %d characters", maxProjectDescription))
+		}
+		d.Description = &v
+	}
+	if d.Tags != nil {
+		seen := map[string]bool{}
+		tags := []string{}
+		for _, t := range *d.Tags {
+			t = strings.TrimSpace(t)
+			if t == "" || seen[strings.ToLower(t)] {
+				continue
+			}
+			if len(t) > maxProjectTagLength {
+				return nil, nil, httpx.New(fiber.StatusBadRequest, "invalid_tags").With("tag", t)
+			}
+			seen[strings.ToLower(t)] = true
+			tags = append(tags, t)
+		}
+		if len(tags) > maxProjectTags {
+			return nil, nil, httpx.New(fiber.StatusBadRequest, "invalid_tags").
+				WithMessage(fmt.Sprintf("At most %d tags", maxProjectTags))
+		}
+		tagsJSON, _ = json.Marshal(tags)
+	}
+	for _, p := range []*string{d.Language, d.Category, d.EcosystemName} {
+		if p != nil {
+			*p = strings.TrimSpace(*p)
+		}
+	}
+	if d.FundingAddress != nil {
+		addr := strings.TrimSpace(*d.FundingAddress)
+		if addr == "" {
+			d.FundingWalletType = nil
+			return tagsJSON, &addr, nil
+		}
+		if d.FundingWalletType == nil {
+			return nil, nil, httpx.New(fiber.StatusBadRequest, "funding_wallet_type_required")
+		}
+		wt, werr := auth.NormalizeWalletType(*d.FundingWalletType)
+		if werr != nil {
+			return nil, nil, httpx.New(fiber.StatusBadRequest, "invalid_funding_wallet_type")
+		}
+		addr, aerr := auth.NormalizeAddress(wt, addr)
+		if aerr != nil {
+			return nil, nil, httpx.New(fiber.StatusBadRequest, "invalid_funding_address").WithMessage(aerr.Error())
+		}
+		t := string(wt)
+		d.FundingWalletType = &t
+		return tagsJSON, &addr, nil
+	}
+	if d.FundingWalletType != nil {
+		return nil, nil, httpx.New(fiber.StatusBadRequest, "funding_address_required")
+	}
+	return tagsJSON, nil, nil
+}
+
+// activeEcosystemID resolves an ecosystem by name; registration requires an
+// active one.
+func (h *ProjectsHandler) activeEcosystemID(ctx context.Context, name string) (uuid.UUID, *httpx.Error) {
+	if name == "" {
+		return uuid.Nil, httpx.New(fiber.StatusBadRequest, "ecosystem_required").WithMessage("Ecosystem name is required")
+	}
+	var id uuid.UUID
+	err := h.db.Pool.QueryRow(ctx, `
+SELECT id
+FROM ecosystems
+WHERE LOWER(TRIM(name)) = LOWER(TRIM($1))
+  AND status = 'active'
+`, name).Scan(&id)
+	if err != nil {
+		return uuid.Nil, httpx.New(fiber.StatusBadRequest, "ecosystem_not_found").WithMessage("No active ecosystem found with that name. Please select from available ecosystems.")
+	}
+	return id, nil
+}
+
+// adminRepo fetches fullName with userID's GitHub token and checks they
+// administer it, so only a repo's admins can register it.
+func (h *ProjectsHandler) adminRepo(ctx context.Context, userID uuid.UUID, fullName string) (github.Repo, *httpx.Error) {
+	linked, err := github.GetLinkedAccount(ctx, h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+	if err != nil {
+		return github.Repo{}, httpx.New(fiber.StatusBadRequest, "github_not_linked").WithMessage("Link your GitHub account to register a repository")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	repo, err := github.NewClient().GetRepo(ctx, linked.AccessToken, fullName)
+	var apiErr *github.GitHubAPIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusForbidden) {
+		return github.Repo{}, httpx.New(fiber.StatusNotFound, "repo_not_found").With("github_full_name", fullName)
+	}
+	if err != nil {
+		return github.Repo{}, httpx.New(fiber.StatusBadGateway, "github_repo_lookup_failed").Wrap(err)
+	}
+	if !repo.Permissions.Admin {
+		return github.Repo{}, httpx.New(fiber.StatusForbidden, "repo_admin_required").WithMessage("Only admins of the repository can register it")
+	}
+	return repo, nil
+}
+
+// Update edits a project's details. Only the fields present change.
+func (h *ProjectsHandler) Update() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		if respErr := checkManagesProject(c, h.db.Pool, projectID, orgs.PermManageProjects); respErr != nil {
+			return httpx.Write(c, respErr)
+		}
+		var req projectDetails
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		tagsJSON, funding, respErr := req.normalize()
+		if respErr != nil {
+			return httpx.Write(c, respErr)
+		}
+
+		var sets []string
+		args := []any{projectID}
+		set := func(column string, v any) {
+			args = append(args, v)
+			sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+		if req.Description != nil {
+			set("description", nullIfEmpty(*req.Description))
+		}
+		if tagsJSON != nil {
+			set("tags", tagsJSON)
+		}
+		if req.Language != nil {
+			set("language", nullIfEmpty(*req.Language))
+		}
+		if req.Category != nil {
+			set("category", nullIfEmpty(*req.Category))
+		}
+		if req.EcosystemName != nil {
+			ecosystemID, respErr := h.activeEcosystemID(c.Context(), *req.EcosystemName)
+			if respErr != nil {
+				return httpx.Write(c, respErr)
+			}
+			set("ecosystem_id", ecosystemID)
+		}
+		if funding != nil {
+			set("funding_wallet_type", req.FundingWalletType)
+			set("funding_address", nullIfEmpty(*funding))
+		}
+		if len(sets) == 0 {
+			return httpx.Fail(c, fiber.StatusBadRequest, "nothing_to_update")
+		}
+
+		var out projectRegistryResponse
+		err = h.db.Pool.QueryRow(c.Context(), `
+UPDATE projects SET `+strings.Join(sets, ", ")+`, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING `+projectRegistryColumns, args...).Scan(out.dest()...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
+		}
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "project_update_failed").Wrap(err))
+		}
+		return c.Status(fiber.StatusOK).JSON(out.render())
+	}
+}
+
+// Delete unregisters a project. Projects with open bounties can't be
+// removed until those are completed or cancelled.
+func (h *ProjectsHandler) Delete() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+		if respErr := checkManagesProject(c, h.db.Pool, projectID, orgs.PermManageProjects); respErr != nil {
+			return httpx.Write(c, respErr)
+		}
+		var openBounties int
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT COUNT(*) FROM bounties WHERE project_id = $1 AND status IN ('open', 'claimed', 'submitted', 'approved')`, projectID).Scan(&openBounties); err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "project_delete_failed").Wrap(err))
+		}
+		if openBounties > 0 {
+			return httpx.Write(c, httpx.New(fiber.StatusConflict, "project_has_open_bounties").With("open_bounties", openBounties))
+		}
+		ct, err := h.db.Pool.Exec(c.Context(), `
+UPDATE projects
+SET deleted_at = now(), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+`, projectID)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "project_delete_failed").Wrap(err))
+		}
+		if ct.RowsAffected() == 0 {
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+const projectRegistryColumns = `id, github_full_name, status, description, tags, language, category, funding_wallet_type, funding_address, created_at, updated_at`
+
+// projectRegistryResponse is a project as its maintainer registered it.
+type projectRegistryResponse struct {
+	ID                uuid.UUID
+	GitHubFullName    string
+	Status            string
+	Description       *string
+	TagsJSON          []byte
+	Language          *string
+	Category          *string
+	FundingWalletType *string
+	FundingAddress    *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (p *projectRegistryResponse) dest() []any {
+	return []any{&p.ID, &p.GitHubFullName, &p.Status, &p.Description, &p.TagsJSON, &p.Language, &p.Category, &p.FundingWalletType, &p.FundingAddress, &p.CreatedAt, &p.UpdatedAt}
+}
+
+func (p projectRegistryResponse) render() fiber.Map {
+	tags := []string{}
+	if len(p.TagsJSON) > 0 {
+		_ = json.Unmarshal(p.TagsJSON, &tags)
+	}
+	return fiber.Map{
+		"id":                  p.ID.String(),
+		"github_full_name":    p.GitHubFullName,
+		"status":              p.Status,
+		"description":         p.Description,
+		"tags":                tags,
+		"language":            p.Language,
+		"category":            p.Category,
+		"funding_wallet_type": p.FundingWalletType,
+		"funding_address":     p.FundingAddress,
+		"created_at":          p.CreatedAt,
+		"updated_at":          p.UpdatedAt,
+	}
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
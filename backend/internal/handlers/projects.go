@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
 	"strings"
 	"time"
 
@@ -17,6 +16,9 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/metadata"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
 )
 
 type ProjectsHandler struct {
@@ -29,125 +31,147 @@ func NewProjectsHandler(cfg config.Config, d *db.DB) *ProjectsHandler {
 }
 
 type createProjectRequest struct {
-	GitHubFullName string   `json:"github_full_name"`
-	EcosystemName  string   `json:"ecosystem_name"` // Users provide name, not slug
-	Language       *string  `json:"language,omitempty"`
-	Tags           []string `json:"tags,omitempty"`
-	Category       *string  `json:"category,omitempty"`
+	GitHubFullName string `json:"github_full_name"`
+	projectDetails
 }
 
+// Create registers a GitHub repository as a project. The caller must have
+// linked GitHub and administer the repo. Registering a repo again restores
+// it if it was deleted; a repo registered by someone else is a conflict.
 func (h *ProjectsHandler) Create() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		var req createProjectRequest
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 
 		fullName := normalizeRepoFullName(req.GitHubFullName)
 		if fullName == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_github_full_name"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_github_full_name")
+		}
+		tagsJSON, funding, respErr := req.normalize()
+		if respErr != nil {
+			return httpx.Write(c, respErr)
+		}
+		if tagsJSON == nil {
+			tagsJSON = []byte("[]")
+		}
+		if funding != nil && *funding == "" {
+			funding = nil
 		}
 
 		// Ecosystem is required (must be an active ecosystem from DB)
-		ecosystemName := strings.TrimSpace(req.EcosystemName)
-		if ecosystemName == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_required", "message": "Ecosystem name is required"})
+		ecosystemName := ""
+		if req.EcosystemName != nil {
+			ecosystemName = *req.EcosystemName
 		}
-
-		var ecosystemID uuid.UUID
-		// Search by name (case-insensitive, trimmed) - must be active
-		err = h.db.Pool.QueryRow(c.Context(), `
-SELECT id
-FROM ecosystems
-WHERE LOWER(TRIM(name)) = LOWER(TRIM($1))
-  AND status = 'active'
-`, ecosystemName).Scan(&ecosystemID)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_not_found", "message": "No active ecosystem found with that name. Please select from available ecosystems."})
+		ecosystemID, respErr := h.activeEcosystemID(c.Context(), ecosystemName)
+		if respErr != nil {
+			return httpx.Write(c, respErr)
 		}
 
-		// Prepare tags as JSONB
-		var tagsJSON []byte = []byte("[]")
-		if len(req.Tags) > 0 {
-			tagsJSON, _ = json.Marshal(req.Tags)
+		repo, respErr := h.adminRepo(c.Context(), userID, fullName)
+		if respErr != nil {
+			return httpx.Write(c, respErr)
 		}
+		// GitHub's casing, so the same repo can't be registered twice.
+		fullName = repo.FullName
 
-		var projectID uuid.UUID
-		var status string
+		var description *string
+		if req.Description != nil {
+			description = nullIfEmpty(*req.Description)
+		}
+		var out projectRegistryResponse
 		err = h.db.Pool.QueryRow(c.Context(), `
-INSERT INTO projects (owner_user_id, github_full_name, ecosystem_id, language, tags, category, status)
-VALUES ($1, $2, $3, $4, $5, $6, 'pending_verification')
+INSERT INTO projects (owner_user_id, github_full_name, ecosystem_id, language, tags, category, description,
+  funding_wallet_type, funding_address, github_repo_id, stars_count, forks_count, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 'pending_verification')
 ON CONFLICT (github_full_name) DO UPDATE SET
   owner_user_id = EXCLUDED.owner_user_id,
   ecosystem_id = EXCLUDED.ecosystem_id,
   language = EXCLUDED.language,
   tags = EXCLUDED.tags,
   category = EXCLUDED.category,
+  description = EXCLUDED.description,
+  funding_wallet_type = EXCLUDED.funding_wallet_type,
+  funding_address = EXCLUDED.funding_address,
+  github_repo_id = EXCLUDED.github_repo_id,
+  deleted_at = NULL,
   updated_at = now()
-RETURNING id, status
-`, userID, fullName, ecosystemID, req.Language, tagsJSON, req.Category).Scan(&projectID, &status)
+WHERE projects.owner_user_id = EXCLUDED.owner_user_id OR projects.deleted_at IS NOT NULL
+RETURNING `+projectRegistryColumns,
+			userID, fullName, ecosystemID, req.Language, tagsJSON, req.Category, description,
+			req.FundingWalletType, funding, repo.ID, repo.StargazersCount, repo.ForksCount,
+		).Scan(out.dest()...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Write(c, httpx.New(fiber.StatusConflict, "project_already_registered").With("github_full_name", fullName))
+		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_create_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_create_failed")
 		}
 
-		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-			"id":               projectID.String(),
-			"github_full_name": fullName,
-			"ecosystem_name":   ecosystemName,
-			"status":           status,
-		})
+		resp := out.render()
+		resp["ecosystem_name"] = ecosystemName
+		return c.Status(fiber.StatusCreated).JSON(resp)
 	}
 }
 
 func (h *ProjectsHandler) Mine() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		slog.Info("projects/mine: handler called",
+		httpx.Logger(c).Info("projects/mine: handler called",
 			"method", c.Method(),
 			"path", c.Path(),
-			"request_id", c.Locals("requestid"),
 		)
 
 		if h.db == nil || h.db.Pool == nil {
-			slog.Error("projects/mine: database not configured",
-				"request_id", c.Locals("requestid"),
-			)
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			httpx.Logger(c).Error("projects/mine: database not configured")
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		sub, ok := c.Locals(auth.LocalUserID).(string)
 		if !ok || sub == "" {
-			requestID := c.Locals("requestid")
-			slog.Warn("projects/mine: missing or invalid user_id in context",
+			httpx.Logger(c).Warn("projects/mine: missing or invalid user_id in context",
 				"user_id_type", fmt.Sprintf("%T", c.Locals(auth.LocalUserID)),
 				"user_id_value", c.Locals(auth.LocalUserID),
-				"request_id", requestID,
 			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 		
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			slog.Warn("projects/mine: failed to parse user_id as UUID",
+			httpx.Logger(c).Warn("projects/mine: failed to parse user_id as UUID",
 				"user_id", sub,
 				"error", err,
-				"request_id", c.Locals("requestid"),
 			)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
-		slog.Info("projects/mine: querying projects",
+		// metadata.<key>=value parameters filter on the project's metadata.
+		filters, err := metadata.ParseFilters(c.Queries())
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_metadata_filter")
+		}
+		// Projects of linked GitHub organizations the caller administers,
+		// or holds a custom role in, are theirs to manage too.
+		where := "(p.owner_user_id = $1 OR " + orgs.AdminOf("p", "$1") + " OR " + orgs.HoldsRoleOn("p", "$1") + ")\n  AND p.deleted_at IS NULL"
+		args := []any{userID}
+		if cond, fargs := filters.SQL("p.metadata", 2); cond != "" {
+			where += "\n  AND " + cond
+			args = append(args, fargs...)
+		}
+
+		httpx.Logger(c).Info("projects/mine: querying projects",
 			"user_id", userID.String(),
-			"request_id", c.Locals("requestid"),
 		)
 
 		rows, err := h.db.Pool.Query(c.Context(), `
@@ -166,20 +190,22 @@ SELECT
   e.name AS ecosystem_name,
   p.language,
   p.tags,
-  p.category
+  p.category,
+  p.metadata,
+  p.description,
+  p.funding_wallet_type,
+  p.funding_address
 FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
-WHERE p.owner_user_id = $1
-  AND p.deleted_at IS NULL
+WHERE `+where+`
 ORDER BY p.created_at DESC
-`, userID)
+`, args...)
 		if err != nil {
-			slog.Error("projects/mine: database query failed",
+			httpx.Logger(c).Error("projects/mine: database query failed",
 				"user_id", userID.String(),
 				"error", err,
-				"request_id", c.Locals("requestid"),
 			)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "projects_list_failed")
 		}
 		defer rows.Close()
 
@@ -206,9 +232,11 @@ ORDER BY p.created_at DESC
 			var language *string
 			var tagsJSON []byte
 			var category *string
+			var md map[string]any
+			var description, fundingWalletType, fundingAddress *string
 
-			if err := rows.Scan(&id, &fullName, &status, &repoID, &verifiedAt, &verErr, &webhookID, &webhookURL, &webhookCreatedAt, &createdAt, &updatedAt, &ecosystemName, &language, &tagsJSON, &category); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed"})
+			if err := rows.Scan(&id, &fullName, &status, &repoID, &verifiedAt, &verErr, &webhookID, &webhookURL, &webhookCreatedAt, &createdAt, &updatedAt, &ecosystemName, &language, &tagsJSON, &category, &md, &description, &fundingWalletType, &fundingAddress); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "projects_list_failed")
 			}
 
 			// Fetch repo data from GitHub to check if it's private and get owner avatar
@@ -245,21 +273,25 @@ WHERE id = $1
 			}
 
 			projectMap := fiber.Map{
-				"id":                 id.String(),
-				"github_full_name":   fullName,
-				"status":             status,
-				"github_repo_id":     repoID,
-				"verified_at":        verifiedAt,
-				"verification_error": verErr,
-				"webhook_id":         webhookID,
-				"webhook_url":        webhookURL,
-				"webhook_created_at": webhookCreatedAt,
-				"created_at":         createdAt,
-				"updated_at":         updatedAt,
-				"ecosystem_name":     ecosystemName,
-				"language":           language,
-				"tags":               tags,
-				"category":           category,
+				"id":                  id.String(),
+				"github_full_name":    fullName,
+				"status":              status,
+				"github_repo_id":      repoID,
+				"verified_at":         verifiedAt,
+				"verification_error":  verErr,
+				"webhook_id":          webhookID,
+				"webhook_url":         webhookURL,
+				"webhook_created_at":  webhookCreatedAt,
+				"created_at":          createdAt,
+				"updated_at":          updatedAt,
+				"ecosystem_name":      ecosystemName,
+				"language":            language,
+				"tags":                tags,
+				"category":            category,
+				"metadata":            md,
+				"description":         description,
+				"funding_wallet_type": fundingWalletType,
+				"funding_address":     fundingAddress,
 			}
 
 			// Add owner avatar if available
@@ -275,10 +307,9 @@ WHERE id = $1
 			out = []fiber.Map{}
 		}
 
-		slog.Info("projects/mine: returning projects",
+		httpx.Logger(c).Info("projects/mine: returning projects",
 			"user_id", userID.String(),
 			"count", len(out),
-			"request_id", c.Locals("requestid"),
 		)
 
 		return c.Status(fiber.StatusOK).JSON(out)
@@ -288,20 +319,20 @@ WHERE id = $1
 func (h *ProjectsHandler) Verify() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		role, _ := c.Locals(auth.LocalRole).(string)
 
 		projectID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 		}
 
 		var ownerUserID uuid.UUID
@@ -313,14 +344,18 @@ FROM projects
 WHERE id = $1
 `, projectID).Scan(&ownerUserID, &fullName, &webhookID)
 		if errors.Is(err, pgx.ErrNoRows) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
 		}
 
-		if ownerUserID != userID && role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+		ok, err := managesProject(c.Context(), h.db.Pool, projectID, ownerUserID, userID, role, orgs.PermManageProjects)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+		}
+		if !ok {
+			return httpx.Fail(c, fiber.StatusForbidden, "forbidden")
 		}
 
 		_, _ = h.db.Pool.Exec(c.Context(), `
@@ -387,7 +422,18 @@ WHERE id = $1
 
 	webhookURL := strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/webhooks/github"
 
-	wh, err := gh.CreateWebhook(ctx, linked.AccessToken, fullName, github.CreateWebhookRequest{
+	// The permission check above is the owner's; the hook itself is created
+	// through the app's installation when there is one.
+	tokens := github.Tokens{
+		Pool:           h.db.Pool,
+		TokenEncKeyB64: h.cfg.TokenEncKeyB64,
+		Installations:  github.AppInstallations(h.cfg.GitHubAppID, h.cfg.GitHubAppPrivateKey),
+	}
+	hookToken, err := tokens.Repo(fullName, ownerUserID).Token(ctx)
+	if err != nil {
+		hookToken = linked.AccessToken
+	}
+	wh, err := gh.CreateWebhook(ctx, hookToken, fullName, github.CreateWebhookRequest{
 		URL:    webhookURL,
 		Secret: h.cfg.GitHubWebhookSecret,
 		Events: []string{"issues", "pull_request", "pull_request_review", "push"},
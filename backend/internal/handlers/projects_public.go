@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,57 +16,32 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/repohealth"
 )
 
 type ProjectsPublicHandler struct {
 	db  *db.DB
 	cfg config.Config
 
-	// GitHub App enrichment helpers (best-effort).
-	appClient  *github.GitHubAppClient
-	tokenMu    sync.Mutex
-	tokenCache map[string]struct {
-		token     string
-		expiresAt time.Time
-	}
+	// GitHub App installation tokens for enrichment (best-effort; nil when
+	// the app isn't configured).
+	installations *github.InstallationTokens
 }
 
 func NewProjectsPublicHandler(cfg config.Config, d *db.DB) *ProjectsPublicHandler {
-	h := &ProjectsPublicHandler{
-		db:  d,
-		cfg: cfg,
-		tokenCache: map[string]struct {
-			token     string
-			expiresAt time.Time
-		}{},
+	return &ProjectsPublicHandler{
+		db:            d,
+		cfg:           cfg,
+		installations: github.AppInstallations(cfg.GitHubAppID, cfg.GitHubAppPrivateKey),
 	}
-
-	// Initialize GitHub App client if configured.
-	if strings.TrimSpace(cfg.GitHubAppID) != "" && strings.TrimSpace(cfg.GitHubAppPrivateKey) != "" {
-		appClient, err := github.NewGitHubAppClient(cfg.GitHubAppID, cfg.GitHubAppPrivateKey)
-		if err != nil {
-			slog.Warn("failed to init github app client (will skip github enrichment auth)", "error", err)
-		} else {
-			h.appClient = appClient
-		}
-	}
-	return h
 }
 
 func (h *ProjectsPublicHandler) installationToken(ctx context.Context, installationID string) string {
-	if h.appClient == nil || strings.TrimSpace(installationID) == "" {
+	if h.installations == nil || strings.TrimSpace(installationID) == "" {
 		return ""
 	}
-
-	h.tokenMu.Lock()
-	defer h.tokenMu.Unlock()
-
-	if cached, ok := h.tokenCache[installationID]; ok && time.Now().Before(cached.expiresAt) {
-		return cached.token
-	}
-
-	// Installation tokens typically last 1 hour; refresh proactively.
-	tok, err := h.appClient.GetInstallationToken(ctx, installationID)
+	tok, err := h.installations.Token(ctx, installationID)
 	if err != nil {
 		slog.Warn("failed to get github app installation token (continuing without auth)",
 			"installation_id", installationID,
@@ -74,14 +49,6 @@ func (h *ProjectsPublicHandler) installationToken(ctx context.Context, installat
 		)
 		return ""
 	}
-
-	h.tokenCache[installationID] = struct {
-		token     string
-		expiresAt time.Time
-	}{
-		token:     tok,
-		expiresAt: time.Now().Add(50 * time.Minute),
-	}
 	return tok
 }
 
@@ -89,25 +56,23 @@ func (h *ProjectsPublicHandler) installationToken(ctx context.Context, installat
 func (h *ProjectsPublicHandler) Get() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		projectIDParam := c.Params("id")
-		slog.Info("projects/:id: handler called",
+		httpx.Logger(c).Info("projects/:id: handler called",
 			"method", c.Method(),
 			"path", c.Path(),
 			"id_param", projectIDParam,
-			"request_id", c.Locals("requestid"),
 		)
 
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		projectID, err := uuid.Parse(projectIDParam)
 		if err != nil {
-			slog.Warn("projects/:id: invalid project ID format",
+			httpx.Logger(c).Warn("projects/:id: invalid project ID format",
 				"id_param", projectIDParam,
 				"error", err,
-				"request_id", c.Locals("requestid"),
 			)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 		}
 
 		// Load project from DB (verified + not deleted)
@@ -120,6 +85,7 @@ func (h *ProjectsPublicHandler) Get() fiber.Handler {
 		var openIssuesCount, openPRsCount, contributorsCount int
 		var createdAt, updatedAt time.Time
 		var ecosystemName, ecosystemSlug *string
+		var description, fundingWalletType, fundingAddress *string
 
 		err = h.db.Pool.QueryRow(c.Context(), `
 SELECT 
@@ -152,7 +118,10 @@ SELECT
   p.created_at,
   p.updated_at,
   e.name AS ecosystem_name,
-  e.slug AS ecosystem_slug
+  e.slug AS ecosystem_slug,
+  p.description,
+  p.funding_wallet_type,
+  p.funding_address
 FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
 WHERE p.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
@@ -160,12 +129,13 @@ WHERE p.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
 			&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount,
 			&openIssuesCount, &openPRsCount, &contributorsCount,
 			&createdAt, &updatedAt, &ecosystemName, &ecosystemSlug,
+			&description, &fundingWalletType, &fundingAddress,
 		)
 		if err == pgx.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
 		}
 
 		// Parse tags JSONB
@@ -200,14 +170,14 @@ WHERE p.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
 			// If GitHub fetch fails (404/403), it's likely a private repo
 			errStr := repoErr.Error()
 			if strings.Contains(errStr, "404") || strings.Contains(errStr, "403") || strings.Contains(errStr, "Not Found") {
-				slog.Info("project is private or inaccessible",
+				httpx.Logger(c).Info("project is private or inaccessible",
 					"project_id", projectID,
 					"github_full_name", fullName,
 					"error", repoErr,
 				)
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_accessible"})
+				return httpx.Fail(c, fiber.StatusNotFound, "project_not_accessible")
 			}
-			slog.Warn("failed to fetch repo metadata from GitHub",
+			httpx.Logger(c).Warn("failed to fetch repo metadata from GitHub",
 				"project_id", projectID,
 				"github_full_name", fullName,
 				"error", repoErr,
@@ -215,11 +185,11 @@ WHERE p.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
 		} else {
 			// Check if repo is private
 			if r.Private {
-				slog.Info("project is private",
+				httpx.Logger(c).Info("project is private",
 					"project_id", projectID,
 					"github_full_name", fullName,
 				)
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_accessible"})
+				return httpx.Fail(c, fiber.StatusNotFound, "project_not_accessible")
 			}
 			repo = r
 			repoOK = true
@@ -256,7 +226,7 @@ WHERE id=$1
 		if readme, err := gh.GetReadme(ctx, token, fullName); err == nil {
 			readmeContent = readme
 		} else {
-			slog.Warn("failed to fetch README for project",
+			httpx.Logger(c).Warn("failed to fetch README for project",
 				"project_id", projectID,
 				"github_full_name", fullName,
 				"error", err,
@@ -264,22 +234,30 @@ WHERE id=$1
 		}
 
 		resp := fiber.Map{
-			"id":                 id.String(),
-			"github_full_name":   fullName,
-			"language":           language,
-			"tags":               tags,
-			"category":           category,
-			"stars_count":        stars,
-			"forks_count":        forks,
-			"contributors_count": contributorsCount,
-			"open_issues_count":  openIssuesCount,
-			"open_prs_count":     openPRsCount,
-			"ecosystem_name":     ecosystemName,
-			"ecosystem_slug":     ecosystemSlug,
-			"created_at":         createdAt,
-			"updated_at":         updatedAt,
-			"languages":          langsOut,
-			"readme":             readmeContent,
+			"id":                  id.String(),
+			"github_full_name":    fullName,
+			"language":            language,
+			"tags":                tags,
+			"category":            category,
+			"stars_count":         stars,
+			"forks_count":         forks,
+			"contributors_count":  contributorsCount,
+			"open_issues_count":   openIssuesCount,
+			"open_prs_count":      openPRsCount,
+			"ecosystem_name":      ecosystemName,
+			"ecosystem_slug":      ecosystemSlug,
+			"description":         description,
+			"funding_wallet_type": fundingWalletType,
+			"funding_address":     fundingAddress,
+			"created_at":          createdAt,
+			"updated_at":          updatedAt,
+			"languages":           langsOut,
+			"readme":              readmeContent,
+		}
+
+		// Stored by the repo health job; absent until it has run.
+		if health, err := repohealth.Get(c.Context(), h.db.Pool, projectID); err == nil {
+			resp["health"] = health
 		}
 
 		if repoOK {
@@ -302,11 +280,11 @@ WHERE id=$1
 func (h *ProjectsPublicHandler) IssuesPublic() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		projectID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 		}
 
 		// Ensure project is verified and not deleted
@@ -316,7 +294,7 @@ SELECT EXISTS(
   SELECT 1 FROM projects WHERE id=$1 AND status='verified' AND deleted_at IS NULL
 )
 `, projectID).Scan(&ok); err != nil || !ok {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
 		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
@@ -327,7 +305,7 @@ ORDER BY COALESCE(updated_at_github, last_seen_at) DESC
 LIMIT 50
 `, projectID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "issues_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "issues_list_failed")
 		}
 		defer rows.Close()
 
@@ -341,7 +319,7 @@ LIMIT 50
 			var updated *time.Time
 			var lastSeen time.Time
 			if err := rows.Scan(&gid, &number, &state, &title, &body, &author, &url, &labelsJSON, &updated, &lastSeen); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "issues_list_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "issues_list_failed")
 			}
 
 			// labels JSONB (stored as array of objects) -> surface as-is
@@ -372,11 +350,11 @@ LIMIT 50
 func (h *ProjectsPublicHandler) PRsPublic() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		projectID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 		}
 
 		var ok bool
@@ -385,7 +363,7 @@ SELECT EXISTS(
   SELECT 1 FROM projects WHERE id=$1 AND status='verified' AND deleted_at IS NULL
 )
 `, projectID).Scan(&ok); err != nil || !ok {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
 		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
@@ -397,7 +375,7 @@ ORDER BY COALESCE(updated_at_github, last_seen_at) DESC
 LIMIT 50
 `, projectID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "prs_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "prs_list_failed")
 		}
 		defer rows.Close()
 
@@ -410,7 +388,7 @@ LIMIT 50
 			var createdAt, updated, closedAt, mergedAt *time.Time
 			var lastSeen time.Time
 			if err := rows.Scan(&gid, &number, &state, &title, &author, &url, &merged, &createdAt, &updated, &closedAt, &mergedAt, &lastSeen); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "prs_list_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "prs_list_failed")
 			}
 			out = append(out, fiber.Map{
 				"github_pr_id": gid,
@@ -432,68 +410,59 @@ LIMIT 50
 	}
 }
 
+// projectsListSpec is what the public project listing sorts and filters on.
+var projectsListSpec = httpx.ListSpec{
+	Fields: map[string]httpx.Field{
+		"ecosystem":  {Column: "LOWER(TRIM(e.name))", Filter: true, Fold: true},
+		"language":   {Column: "LOWER(TRIM(p.language))", Filter: true, Fold: true},
+		"category":   {Column: "LOWER(TRIM(p.category))", Filter: true, Fold: true},
+		"name":       {Column: "lower(p.github_full_name)", Sort: true},
+		"stars":      {Column: "COALESCE(p.stars_count, 0)", Type: httpx.FieldInt, Sort: true, Filter: true},
+		"forks":      {Column: "COALESCE(p.forks_count, 0)", Type: httpx.FieldInt, Sort: true, Filter: true},
+		"created_at": {Column: "p.created_at", Type: httpx.FieldTime, Sort: true, Filter: true},
+		"updated_at": {Column: "p.updated_at", Type: httpx.FieldTime, Sort: true, Filter: true},
+	},
+	DefaultSort:  "-created_at",
+	Key:          "p.id",
+	KeyType:      httpx.FieldUUID,
+	DefaultLimit: 50,
+	MaxLimit:     200,
+}
+
+// ProjectsListParams are the query parameters List reads.
+var ProjectsListParams = append(projectsListSpec.Params(), "tags")
+
 // List returns a filtered list of verified projects.
 // Query parameters:
-//   - ecosystem: filter by ecosystem name (case-insensitive)
-//   - language: filter by programming language
-//   - category: filter by category
+//   - ecosystem, language, category: case-insensitive, comma-separated for any of several
+//   - stars, forks, created_at, updated_at: with [gt], [gte], [lt] or [lte] for ranges
 //   - tags: comma-separated list of tags (project must have ALL tags)
+//   - sort: name, stars, forks, created_at or updated_at, "-" for descending (default -created_at)
 //   - limit: max results (default 50, max 200)
-//   - offset: pagination offset (default 0)
+//   - cursor: next_cursor of the previous page
 func (h *ProjectsPublicHandler) List() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
-		// Parse query parameters
-		ecosystem := strings.TrimSpace(c.Query("ecosystem"))
-		language := strings.TrimSpace(c.Query("language"))
-		category := strings.TrimSpace(c.Query("category"))
-		tagsParam := strings.TrimSpace(c.Query("tags"))
-
-		limit := 50
-		if l := c.QueryInt("limit", 50); l > 0 && l <= 200 {
-			limit = l
-		}
-		offset := c.QueryInt("offset", 0)
-		if offset < 0 {
-			offset = 0
+		page, err := httpx.ParseList(c, projectsListSpec)
+		if err != nil {
+			return httpx.Write(c, err)
 		}
+		tagsParam := strings.TrimSpace(c.Query("tags"))
 
 		// Build WHERE clause and args
 		var conditions []string
 		var args []any
 		argPos := 1
 
-		// Only show verified projects
-		conditions = append(conditions, "p.status = 'verified'")
+		// Only show verified projects that haven't been deleted
+		conditions = append(conditions, "p.status = 'verified'", "p.deleted_at IS NULL")
 
 		// Exclude special GitHub repositories (owner/.github)
 		conditions = append(conditions, "split_part(p.github_full_name, '/', 2) != '.github'")
 
-
-		// Filter by ecosystem
-		if ecosystem != "" {
-			conditions = append(conditions, fmt.Sprintf("LOWER(TRIM(e.name)) = LOWER($%d)", argPos))
-			args = append(args, ecosystem)
-			argPos++
-		}
-
-		// Filter by language
-		if language != "" {
-			conditions = append(conditions, fmt.Sprintf("LOWER(TRIM(p.language)) = LOWER($%d)", argPos))
-			args = append(args, language)
-			argPos++
-		}
-
-		// Filter by category
-		if category != "" {
-			conditions = append(conditions, fmt.Sprintf("LOWER(TRIM(p.category)) = LOWER($%d)", argPos))
-			args = append(args, category)
-			argPos++
-		}
-
 		// Filter by tags (must have ALL specified tags)
 		var tags []string
 		if tagsParam != "" {
@@ -512,6 +481,18 @@ func (h *ProjectsPublicHandler) List() fiber.Handler {
 			argPos++
 		}
 
+		// total counts every match, not only those past the cursor.
+		countConditions, countArgs := slices.Clone(conditions), slices.Clone(args)
+		if cond, pargs := page.WithoutCursor().Where(argPos); cond != "" {
+			countConditions = append(countConditions, cond)
+			countArgs = append(countArgs, pargs...)
+		}
+		if cond, pargs := page.Where(argPos); cond != "" {
+			conditions = append(conditions, cond)
+			args = append(args, pargs...)
+			argPos += len(pargs)
+		}
+
 		whereClause := strings.Join(conditions, " AND ")
 
 		// Build query
@@ -546,18 +527,22 @@ SELECT
   p.created_at,
   p.updated_at,
   e.name AS ecosystem_name,
-  e.slug AS ecosystem_slug
+  e.slug AS ecosystem_slug,
+  p.description,
+  p.funding_wallet_type,
+  p.funding_address,
+  %s
 FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
 WHERE %s
-ORDER BY p.created_at DESC
-LIMIT $%d OFFSET $%d
-`, whereClause, argPos, argPos+1)
-		args = append(args, limit, offset)
+%s
+LIMIT $%d %s
+`, page.CursorColumn(), whereClause, page.OrderBy(), argPos, page.OffsetSQL())
+		args = append(args, page.Limit)
 
 		rows, err := h.db.Pool.Query(c.Context(), query, args...)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "projects_list_failed")
 		}
 		defer rows.Close()
 
@@ -567,7 +552,12 @@ LIMIT $%d OFFSET $%d
 		gh := github.NewClient()
 
 		var out []fiber.Map
+		// Private repositories are dropped from the page, so whether there
+		// is another one goes by the rows read.
+		var read int
+		var cursor string
 		for rows.Next() {
+			read++
 			var id uuid.UUID
 			var fullName string
 			var installationID *string
@@ -577,9 +567,10 @@ LIMIT $%d OFFSET $%d
 			var openIssuesCount, openPRsCount, contributorsCount int
 			var createdAt, updatedAt time.Time
 			var ecosystemName, ecosystemSlug *string
+			var ownDescription, fundingWalletType, fundingAddress *string
 
-			if err := rows.Scan(&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount, &openIssuesCount, &openPRsCount, &contributorsCount, &createdAt, &updatedAt, &ecosystemName, &ecosystemSlug); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed", "details": err.Error()})
+			if err := rows.Scan(&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount, &openIssuesCount, &openPRsCount, &contributorsCount, &createdAt, &updatedAt, &ecosystemName, &ecosystemSlug, &ownDescription, &fundingWalletType, &fundingAddress, &cursor); err != nil {
+				return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "projects_list_failed").Wrap(err))
 			}
 
 			// Parse tags JSONB
@@ -607,7 +598,7 @@ LIMIT $%d OFFSET $%d
 			}
 			repo, repoErr := gh.GetRepo(ctx, token, fullName)
 			if repoErr != nil {
-				slog.Warn("github repo enrichment failed (continuing without github metadata)",
+				httpx.Logger(c).Warn("github repo enrichment failed (continuing without github metadata)",
 					"project_id", id,
 					"github_full_name", fullName,
 					"error", repoErr,
@@ -615,7 +606,7 @@ LIMIT $%d OFFSET $%d
 			} else {
 				// Check if repo is private
 				if repo.Private {
-					slog.Info("skipping private repository",
+					httpx.Logger(c).Info("skipping private repository",
 						"project_id", id,
 						"github_full_name", fullName,
 					)
@@ -640,22 +631,29 @@ WHERE id=$1
 				}
 			}
 
+			// The maintainer's own description wins over GitHub's.
+			if ownDescription != nil {
+				description = *ownDescription
+			}
+
 			out = append(out, fiber.Map{
-				"id":                 id.String(),
-				"github_full_name":   fullName,
-				"language":           language,
-				"tags":               tags,
-				"category":           category,
-				"stars_count":        stars,
-				"forks_count":        forks,
-				"contributors_count": contributorsCount,
-				"open_issues_count":  openIssuesCount,
-				"open_prs_count":     openPRsCount,
-				"ecosystem_name":     ecosystemName,
-				"ecosystem_slug":     ecosystemSlug,
-				"description":        description,
-				"created_at":         createdAt,
-				"updated_at":         updatedAt,
+				"id":                  id.String(),
+				"github_full_name":    fullName,
+				"language":            language,
+				"tags":                tags,
+				"category":            category,
+				"stars_count":         stars,
+				"forks_count":         forks,
+				"contributors_count":  contributorsCount,
+				"open_issues_count":   openIssuesCount,
+				"open_prs_count":      openPRsCount,
+				"ecosystem_name":      ecosystemName,
+				"ecosystem_slug":      ecosystemSlug,
+				"description":         description,
+				"funding_wallet_type": fundingWalletType,
+				"funding_address":     fundingAddress,
+				"created_at":          createdAt,
+				"updated_at":          updatedAt,
 			})
 		}
 
@@ -665,8 +663,7 @@ SELECT COUNT(*)
 FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
 WHERE %s
-`, whereClause)
-		countArgs := args[:len(args)-2] // Remove limit and offset
+`, strings.Join(countConditions, " AND "))
 
 		var total int
 		if err := h.db.Pool.QueryRow(c.Context(), countQuery, countArgs...).Scan(&total); err != nil {
@@ -675,10 +672,11 @@ WHERE %s
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"projects": out,
-			"total":    total,
-			"limit":    limit,
-			"offset":   offset,
+			"projects":    out,
+			"total":       total,
+			"limit":       page.Limit,
+			"offset":      page.Offset,
+			"next_cursor": page.NextCursor(read, cursor),
 		})
 	}
 }
@@ -689,7 +687,7 @@ WHERE %s
 func (h *ProjectsPublicHandler) Recommended() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		limit := 8
@@ -738,7 +736,7 @@ LIMIT $1
 `
 		rows, err := h.db.Pool.Query(c.Context(), query, limit)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "recommended_projects_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "recommended_projects_failed")
 		}
 		defer rows.Close()
 
@@ -760,7 +758,7 @@ LIMIT $1
 			var ecosystemName, ecosystemSlug *string
 
 			if err := rows.Scan(&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount, &openIssuesCount, &openPRsCount, &contributorsCount, &createdAt, &updatedAt, &ecosystemName, &ecosystemSlug); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "recommended_projects_scan_failed"})
+				return httpx.Fail(c, fiber.StatusInternalServerError, "recommended_projects_scan_failed")
 			}
 
 			// Parse tags JSONB
@@ -788,7 +786,7 @@ LIMIT $1
 			}
 			repo, repoErr := gh.GetRepo(ctx, token, fullName)
 			if repoErr != nil {
-				slog.Warn("github repo enrichment failed in recommended (continuing without github metadata)",
+				httpx.Logger(c).Warn("github repo enrichment failed in recommended (continuing without github metadata)",
 					"project_id", id,
 					"github_full_name", fullName,
 					"error", repoErr,
@@ -796,7 +794,7 @@ LIMIT $1
 			} else {
 				// Check if repo is private
 				if repo.Private {
-					slog.Info("skipping private repository in recommended",
+					httpx.Logger(c).Info("skipping private repository in recommended",
 						"project_id", id,
 						"github_full_name", fullName,
 					)
@@ -848,7 +846,7 @@ WHERE id=$1
 func (h *ProjectsPublicHandler) FilterOptions() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		// Get distinct languages
@@ -859,7 +857,7 @@ WHERE status = 'verified' AND language IS NOT NULL AND language != ''
 ORDER BY language
 `)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "filter_options_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "filter_options_failed")
 		}
 		defer langRows.Close()
 
@@ -879,7 +877,7 @@ WHERE status = 'verified' AND category IS NOT NULL AND category != ''
 ORDER BY category
 `)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "filter_options_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "filter_options_failed")
 		}
 		defer catRows.Close()
 
@@ -899,7 +897,7 @@ WHERE status = 'verified' AND tags IS NOT NULL AND jsonb_array_length(tags) > 0
 ORDER BY tag
 `)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "filter_options_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "filter_options_failed")
 		}
 		defer tagRows.Close()
 
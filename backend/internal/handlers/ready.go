@@ -7,10 +7,17 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/health"
 )
 
 func Ready(d *db.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if health.Draining() {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"ok":     false,
+				"reason": "draining",
+			})
+		}
 		if d == nil || d.Pool == nil {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
 				"ok":     false,
@@ -54,3 +61,35 @@ func Ready(d *db.DB) fiber.Handler {
 
 
 
+
+// Liveness answers as long as the process serves HTTP. It checks no
+// dependencies, so an outage elsewhere doesn't get every instance restarted.
+func Liveness(service string) fiber.Handler {
+	started := time.Now()
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok":             true,
+			"service":        service,
+			"uptime_seconds": int64(time.Since(started).Seconds()),
+		})
+	}
+}
+
+// Readiness probes deps and answers 503 when a critical one is down or the
+// process is shutting down, with every dependency's status and latency
+// either way.
+func Readiness(deps []health.Dependency) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ready, results := health.Check(c.Context(), deps, 2*time.Second)
+		draining := health.Draining()
+		status := fiber.StatusOK
+		if !ready || draining {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"ok":           ready && !draining,
+			"draining":     draining,
+			"dependencies": results,
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/realtime"
+)
+
+const (
+	// realtimeSubprotocol is the WebSocket subprotocol clients ask for.
+	// Browsers cannot set an Authorization header on a WebSocket, so they
+	// also offer "bearer.<token>", which is never echoed back.
+	realtimeSubprotocol = "grainlify"
+	// realtimeReplayLimit caps the events replayed to a reconnecting client;
+	// past it the client is told to resync instead.
+	realtimeReplayLimit = 500
+	realtimeWriteWait   = 10 * time.Second
+	realtimePongWait    = 60 * time.Second
+	realtimePingPeriod  = 50 * time.Second
+	// realtimeCloseRevoked closes a socket whose session was revoked, in the
+	// 4000-4999 range left to applications; it mirrors HTTP 401.
+	realtimeCloseRevoked = 4401
+)
+
+type RealtimeHandler struct {
+	db     *db.DB
+	broker *realtime.Broker
+}
+
+func NewRealtimeHandler(d *db.DB, b *realtime.Broker) *RealtimeHandler {
+	return &RealtimeHandler{db: d, broker: b}
+}
+
+// Upgrade admits WebSocket handshakes, lifting a bearer token offered as a
+// subprotocol into the Authorization header for auth.RequireAuth.
+func (h *RealtimeHandler) Upgrade() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.broker == nil || h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "realtime_not_configured")
+		}
+		if !websocket.IsWebSocketUpgrade(c) {
+			return httpx.Fail(c, fiber.StatusUpgradeRequired, "websocket_upgrade_required")
+		}
+		if strings.TrimSpace(c.Get(fiber.HeaderAuthorization)) == "" {
+			for _, p := range strings.Split(c.Get(fiber.HeaderSecWebSocketProtocol), ",") {
+				if token, ok := strings.CutPrefix(strings.TrimSpace(p), "bearer."); ok {
+					c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+					break
+				}
+			}
+		}
+		return c.Next()
+	}
+}
+
+// Serve pushes the caller's events over the socket until either side
+// closes it. With ?after=<seq> it first replays the events the caller
+// missed since the last one it saw.
+func (h *RealtimeHandler) Serve() fiber.Handler {
+	return websocket.New(h.serve, websocket.Config{Subprotocols: []string{realtimeSubprotocol}})
+}
+
+func (h *RealtimeHandler) serve(conn *websocket.Conn) {
+	sub, _ := conn.Locals(auth.LocalUserID).(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return
+	}
+	// RequireAuth only checked the session at the handshake; it is checked
+	// again on every ping so signing out elsewhere closes the socket.
+	var sessionID uuid.UUID
+	if sid, _ := conn.Locals(auth.LocalSessionID).(string); sid != "" {
+		sessionID, _ = uuid.Parse(sid)
+	}
+	// Subscribe before replaying so nothing falls in between; replayed
+	// events that also arrive live are skipped.
+	s := h.broker.Subscribe(userID)
+	defer h.broker.Unsubscribe(s)
+
+	write := func(v any) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(realtimeWriteWait))
+		return conn.WriteJSON(v)
+	}
+	replayed := map[int64]bool{}
+	if after, err := strconv.ParseInt(conn.Query("after"), 10, 64); err == nil && after >= 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), realtimeWriteWait)
+		missed, err := realtime.Since(ctx, h.db.Pool, userID, after, realtimeReplayLimit+1)
+		cancel()
+		if err != nil {
+			slog.Error("failed to replay realtime events", "user_id", userID.String(), "error", err)
+			missed = nil
+		}
+		if err != nil || len(missed) > realtimeReplayLimit {
+			missed = nil
+			if write(fiber.Map{"type": realtime.TypeResync}) != nil {
+				return
+			}
+		}
+		for _, e := range missed {
+			replayed[e.Seq] = true
+			if write(e) != nil {
+				return
+			}
+		}
+	}
+
+	// Clients only answer pings; reading is how a close is noticed.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		_ = conn.SetReadDeadline(time.Now().Add(realtimePongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(realtimePongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(realtimePingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case e, ok := <-s.Events:
+			if !ok {
+				// Fell behind; the client reconnects with the last seq it saw.
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"), time.Now().Add(realtimeWriteWait))
+				return
+			}
+			if replayed[e.Seq] {
+				continue
+			}
+			if write(e) != nil {
+				return
+			}
+		case <-ping.C:
+			if h.sessionRevoked(sessionID, userID) {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(realtimeCloseRevoked, "session_revoked"), time.Now().Add(realtimeWriteWait))
+				return
+			}
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(realtimeWriteWait)) != nil {
+				return
+			}
+		}
+	}
+}
+
+// sessionRevoked reports whether the socket's session has been revoked.
+// A failed check keeps the socket open; the next ping tries again.
+func (h *RealtimeHandler) sessionRevoked(sessionID, userID uuid.UUID) bool {
+	if sessionID == uuid.Nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), realtimeWriteWait)
+	defer cancel()
+	err := auth.CheckSession(ctx, h.db.Pool, sessionID, "", "")
+	if err != nil && !errors.Is(err, auth.ErrSessionRevoked) {
+		slog.Warn("failed to check realtime session", "user_id", userID.String(), "error", err)
+		return false
+	}
+	return err != nil
+}
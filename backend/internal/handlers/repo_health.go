@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/repohealth"
+)
+
+// Health returns a verified project's review, response and CI metrics.
+// Projects the refresh job hasn't reached yet are computed on the spot.
+func (h *ProjectsPublicHandler) Health() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
+		}
+
+		var one int
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT 1 FROM projects WHERE id = $1 AND status = 'verified' AND deleted_at IS NULL
+`, projectID).Scan(&one)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+		}
+
+		health, err := repohealth.Get(c.Context(), h.db.Pool, projectID)
+		if errors.Is(err, repohealth.ErrNotFound) {
+			health, err = repohealth.Compute(c.Context(), h.db.Pool, projectID, h.cfg.RepoHealthWindowDays)
+			if err == nil {
+				_ = repohealth.Save(c.Context(), h.db.Pool, health)
+			}
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_health_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(health)
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/moderation"
+)
+
+// ReportsHandler takes abuse reports from users and serves them to admins as
+// the moderation queue.
+type ReportsHandler struct {
+	db *db.DB
+
+	mu        sync.Mutex
+	submitted map[[2]string]int64
+}
+
+func NewReportsHandler(d *db.DB) *ReportsHandler {
+	return &ReportsHandler{db: d, submitted: map[[2]string]int64{}}
+}
+
+type reportAttachment struct {
+	Filename string `json:"filename"`
+	// Data is the file, base64-encoded.
+	Data string `json:"data"`
+}
+
+type createReportRequest struct {
+	TargetType  string             `json:"target_type"`
+	TargetID    string             `json:"target_id"`
+	Category    string             `json:"category"`
+	Details     string             `json:"details"`
+	Attachments []reportAttachment `json:"attachments"`
+}
+
+var reportErrorStatus = map[error]int{
+	moderation.ErrInvalidReportTarget:  fiber.StatusBadRequest,
+	moderation.ErrInvalidReportReason:  fiber.StatusBadRequest,
+	moderation.ErrReportTooLong:        fiber.StatusBadRequest,
+	moderation.ErrTooManyAttachments:   fiber.StatusBadRequest,
+	moderation.ErrInvalidAttachment:    fiber.StatusBadRequest,
+	moderation.ErrSelfReport:           fiber.StatusBadRequest,
+	moderation.ErrAttachmentTooLarge:   fiber.StatusRequestEntityTooLarge,
+	moderation.ErrReportTargetNotFound: fiber.StatusNotFound,
+	moderation.ErrReportExists:         fiber.StatusConflict,
+}
+
+// Create files a report against a bounty, user or comment.
+func (h *ReportsHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+
+		var req createReportRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if len(req.Attachments) > moderation.MaxReportAttachments {
+			return httpx.Fail(c, fiber.StatusBadRequest, moderation.ErrTooManyAttachments.Error())
+		}
+		r := moderation.NewReport{TargetType: req.TargetType, TargetID: req.TargetID, Category: req.Category, Details: req.Details}
+		for _, a := range req.Attachments {
+			data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(a.Data))
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusBadRequest, moderation.ErrInvalidAttachment.Error())
+			}
+			r.Attachments = append(r.Attachments, moderation.Attachment{Filename: a.Filename, Data: data})
+		}
+
+		rep, err := moderation.CreateReport(c.Context(), h.db.Pool, userID, r)
+		if err != nil {
+			for sentinel, status := range reportErrorStatus {
+				if errors.Is(err, sentinel) {
+					return httpx.Fail(c, status, sentinel.Error())
+				}
+			}
+			httpx.Logger(c).Error("failed to create abuse report",
+				"reporter_user_id", userID.String(),
+				"target_type", r.TargetType,
+				"error", err,
+			)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "report_create_failed")
+		}
+
+		h.mu.Lock()
+		h.submitted[[2]string{rep.Category, rep.TargetType}]++
+		h.mu.Unlock()
+		httpx.Logger(c).Info("abuse report filed",
+			"report_id", rep.ID.String(),
+			"target_type", rep.TargetType,
+			"target_id", rep.TargetID,
+			"category", rep.Category,
+			"attachments", len(rep.Attachments),
+		)
+		return c.Status(fiber.StatusCreated).JSON(rep)
+	}
+}
+
+// Queue lists reports for moderators, open ones by default.
+func (h *ReportsHandler) Queue() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		status := strings.TrimSpace(c.Query("status", moderation.ReportOpen))
+		if status == "all" {
+			status = ""
+		}
+		limit := c.QueryInt("limit", 50)
+		if limit < 1 || limit > 200 {
+			limit = 50
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+		out, err := moderation.ListReports(c.Context(), h.db.Pool, status, limit, offset)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "reports_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"reports": out})
+	}
+}
+
+// Attachment downloads a piece of evidence. It is always served as a
+// download so uploaded files never render in the admin's browser.
+func (h *ReportsHandler) Attachment() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		reportID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_report_id")
+		}
+		attachmentID, err := uuid.Parse(c.Params("attachment_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_attachment_id")
+		}
+		a, err := moderation.ReportAttachment(c.Context(), h.db.Pool, reportID, attachmentID)
+		if errors.Is(err, moderation.ErrReportNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "attachment_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "attachment_lookup_failed")
+		}
+		c.Set(fiber.HeaderContentType, a.ContentType)
+		c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		return c.Status(fiber.StatusOK).Send(a.Data)
+	}
+}
+
+type closeReportRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// Close resolves or dismisses an open report.
+func (h *ReportsHandler) Close() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		actorID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		reportID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_report_id")
+		}
+		var req closeReportRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		status := strings.ToLower(strings.TrimSpace(req.Status))
+		if status != moderation.ReportResolved && status != moderation.ReportDismissed {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		}
+
+		rep, err := moderation.CloseReport(c.Context(), h.db.Pool, actorID, reportID, status, req.Note)
+		switch {
+		case errors.Is(err, moderation.ErrReportNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "report_not_found")
+		case errors.Is(err, moderation.ErrReportClosed):
+			return httpx.Fail(c, fiber.StatusConflict, "report_already_closed")
+		case err != nil:
+			httpx.Logger(c).Error("failed to close abuse report", "report_id", reportID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "report_close_failed")
+		}
+		httpx.Logger(c).Info("abuse report closed",
+			"actor_user_id", actorID.String(),
+			"report_id", reportID.String(),
+			"status", status,
+		)
+		return c.Status(fiber.StatusOK).JSON(rep)
+	}
+}
+
+// WriteMetrics writes report volume by category and target type.
+func (h *ReportsHandler) WriteMetrics(w io.Writer) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	keys := make([][2]string, 0, len(h.submitted))
+	for k := range h.submitted {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1]
+	})
+	var b strings.Builder
+	b.WriteString("# HELP grainlify_abuse_reports_total Abuse reports filed, by category and target type.\n# TYPE grainlify_abuse_reports_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "grainlify_abuse_reports_total{category=%q,target_type=%q} %d\n", k[0], k[1], h.submitted[k])
+	}
+	h.mu.Unlock()
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/resume"
+)
+
+type ResumeHandler struct {
+	db     *db.DB
+	signer *ledger.Signer
+	issuer string
+}
+
+// NewResumeHandler signs résumés with the ledger anchor key; without one
+// configured the résumé endpoints return 503.
+func NewResumeHandler(cfg config.Config, d *db.DB) *ResumeHandler {
+	h := &ResumeHandler{db: d, issuer: strings.TrimRight(cfg.PublicBaseURL, "/")}
+	if cfg.LedgerAnchorKeyB64 != "" {
+		s, err := ledger.NewSignerFromB64(cfg.LedgerAnchorKeyB64)
+		if err != nil {
+			slog.Error("resume signing disabled", "error", err)
+		} else {
+			h.signer = s
+		}
+	}
+	return h
+}
+
+// Resume returns the user's signed JSON-LD résumé (public).
+func (h *ResumeHandler) Resume() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if h.signer == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "resume_signing_not_configured")
+		}
+		userID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
+		}
+		now := time.Now()
+		doc, err := resume.Build(c.Context(), h.db.Pool, userID, h.issuer, now)
+		if errors.Is(err, resume.ErrUserNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
+		}
+		if err != nil {
+			httpx.Logger(c).Error("failed to build resume", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "resume_build_failed")
+		}
+		signed, err := resume.Sign(doc, h.signer, h.issuer+"/platform/keys", now)
+		if err != nil {
+			httpx.Logger(c).Error("failed to sign resume", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "resume_sign_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(signed, "application/ld+json")
+	}
+}
+
+// PlatformKeys publishes the public key résumés and ledger anchors are signed
+// with, so third parties can pin it.
+func (h *ResumeHandler) PlatformKeys() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.signer == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "resume_signing_not_configured")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"algorithm":      "Ed25519",
+			"public_key_hex": h.signer.PublicKey(),
+			"proof_type":     resume.ProofType,
+		})
+	}
+}
+
+// Verify checks a résumé posted as the request body against the platform key.
+// It is a convenience; resume.Verify documents the scheme for offline checks.
+func (h *ResumeHandler) Verify() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.signer == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "resume_signing_not_configured")
+		}
+		err := resume.Verify(c.Body(), h.signer.PublicKey())
+		if err != nil && !errors.Is(err, resume.ErrInvalidSignature) && !errors.Is(err, resume.ErrMissingProof) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_json")
+		}
+		out := fiber.Map{"valid": err == nil}
+		if err != nil {
+			out["reason"] = err.Error()
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
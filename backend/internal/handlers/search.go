@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/search"
+)
+
+type SearchHandler struct {
+	db *db.DB
+}
+
+func NewSearchHandler(d *db.DB) *SearchHandler {
+	return &SearchHandler{db: d}
+}
+
+type searchResponse struct {
+	Results    []search.Result `json:"results"`
+	NextOffset *int            `json:"next_offset,omitempty"`
+}
+
+// Search finds verified projects and public bounties matching ?q=,
+// optionally only ?type=project or bounty and bounties in ?status=.
+func (h *SearchHandler) Search() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		types, err := search.ParseTypes(c.Query("type"))
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, err.Error()).WithMessage("type must be project or bounty"))
+		}
+		status := c.Query("status")
+		if status != "" && !slices.Contains(bounties.Statuses, status) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		}
+		limit := c.QueryInt("limit", 20)
+		if limit < 1 || limit > search.MaxPage {
+			limit = 20
+		}
+		q := search.Query{
+			Q:            c.Query("q"),
+			Types:        types,
+			BountyStatus: status,
+			Limit:        limit,
+			Offset:       max(c.QueryInt("offset", 0), 0),
+		}
+		out, err := search.Search(c.Context(), h.db.Pool, q)
+		switch {
+		case errors.Is(err, search.ErrEmptyQuery):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "empty_query").WithMessage("q is required"))
+		case errors.Is(err, search.ErrQueryTooLong):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "query_too_long").With("max_length", search.MaxQueryLen))
+		case err != nil:
+			httpx.Logger(c).Error("search failed", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "search_failed")
+		}
+		resp := searchResponse{Results: out}
+		if len(out) == q.Limit {
+			next := q.Offset + q.Limit
+			resp.NextOffset = &next
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/commands"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/issues"
+	"github.com/jagadeesh/grainlify/backend/internal/slack"
+)
+
+// slackInlineReplyWindow is how long a slash command may run before we ack
+// and deliver the reply through response_url (Slack allows 3s).
+const slackInlineReplyWindow = 2500 * time.Millisecond
+
+type SlackHandler struct {
+	cfg    config.Config
+	db     *db.DB
+	client *slack.Client
+}
+
+func NewSlackHandler(cfg config.Config, d *db.DB) *SlackHandler {
+	return &SlackHandler{cfg: cfg, db: d, client: slack.NewClient(cfg.SlackClientID, cfg.SlackClientSecret, cfg.SlackRedirectURL)}
+}
+
+func (h *SlackHandler) oauthConfigured() bool {
+	return h.cfg.SlackClientID != "" && h.cfg.SlackClientSecret != "" && h.cfg.SlackRedirectURL != ""
+}
+
+// Start returns the Slack consent URL for installing the app in a workspace.
+func (h *SlackHandler) Start() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if !h.oauthConfigured() {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "slack_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		state := randomState(32)
+		_, err = h.db.Pool.Exec(c.Context(), `
+INSERT INTO oauth_states (state, user_id, kind, expires_at)
+VALUES ($1, $2, 'slack_install', $3)
+`, state, userID, time.Now().UTC().Add(10*time.Minute))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "state_create_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"url": h.client.AuthorizeURL(state)})
+	}
+}
+
+func (h *SlackHandler) Callback() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if !h.oauthConfigured() {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "slack_not_configured")
+		}
+		if strings.TrimSpace(h.cfg.TokenEncKeyB64) == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
+		}
+		code := strings.TrimSpace(c.Query("code"))
+		state := strings.TrimSpace(c.Query("state"))
+		if code == "" || state == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_code_or_state")
+		}
+
+		var userID uuid.UUID
+		err := h.db.Pool.QueryRow(c.Context(), `
+DELETE FROM oauth_states
+WHERE state = $1 AND kind = 'slack_install' AND expires_at > now()
+RETURNING user_id
+`, state).Scan(&userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_or_expired_state")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "state_lookup_failed")
+		}
+
+		install, err := h.client.Exchange(c.Context(), code)
+		if err != nil {
+			httpx.Logger(c).Warn("slack install exchange failed", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "token_exchange_failed")
+		}
+		in, err := slack.SaveInstallation(c.Context(), h.db.Pool, userID, install, h.cfg.TokenEncKeyB64)
+		if err != nil {
+			httpx.Logger(c).Error("failed to save slack installation", "user_id", userID.String(), "team_id", install.TeamID, "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "installation_save_failed")
+		}
+
+		if h.cfg.FrontendBaseURL != "" {
+			q := url.Values{"slack": {"installed"}, "team": {in.TeamName}}
+			return c.Redirect(strings.TrimRight(h.cfg.FrontendBaseURL, "/")+"/settings?"+q.Encode(), fiber.StatusFound)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"installation": in})
+	}
+}
+
+// List returns the caller's workspace installs with their channel subscriptions.
+func (h *SlackHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		installs, err := slack.ListInstallations(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "slack_list_failed")
+		}
+		out := make([]fiber.Map, 0, len(installs))
+		for _, in := range installs {
+			subs, err := slack.ListSubscriptions(c.Context(), h.db.Pool, in.ID)
+			if err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "slack_list_failed")
+			}
+			out = append(out, fiber.Map{"installation": in, "subscriptions": subs})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"installations": out, "configured": h.oauthConfigured()})
+	}
+}
+
+func (h *SlackHandler) Uninstall() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_installation_id")
+		}
+		err = slack.DeleteInstallation(c.Context(), h.db.Pool, userID, id)
+		if errors.Is(err, slack.ErrNotInstalled) {
+			return httpx.Fail(c, fiber.StatusNotFound, "slack_installation_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "slack_uninstall_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// slashCommand is a slash command invocation, copied out of the request so it
+// outlives the handler.
+type slashCommand struct {
+	teamID, userID, channelID, text, responseURL string
+}
+
+// Command receives the /bounty slash command, parsed and run by package
+// commands.
+func (h *SlackHandler) Command() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if h.cfg.SlackSigningSecret == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "slack_not_configured")
+		}
+		header := http.Header{}
+		c.Request().Header.VisitAll(func(k, v []byte) {
+			header.Add(string(k), string(v))
+		})
+		if err := slack.VerifyRequest(h.cfg.SlackSigningSecret, header, c.Body(), time.Now()); err != nil {
+			httpx.Logger(c).Warn("slack command rejected", "remote_ip", c.IP())
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_signature")
+		}
+		form, err := url.ParseQuery(string(c.Body()))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_payload")
+		}
+		sc := slashCommand{
+			teamID:      form.Get("team_id"),
+			userID:      form.Get("user_id"),
+			channelID:   form.Get("channel_id"),
+			text:        form.Get("text"),
+			responseURL: form.Get("response_url"),
+		}
+
+		in, err := slack.ForTeam(c.Context(), h.db.Pool, sc.teamID, h.cfg.TokenEncKeyB64)
+		if errors.Is(err, slack.ErrNotInstalled) {
+			return c.JSON(slack.Ephemeral("Grainlify is not connected to this workspace. Install it from your Grainlify settings."))
+		}
+		if err != nil {
+			httpx.Logger(c).Error("slack installation lookup failed", "team_id", sc.teamID, "error", err)
+			return c.JSON(slack.Ephemeral("Grainlify is not available right now."))
+		}
+
+		cmd, err := commands.Parse(sc.text)
+		if err != nil {
+			return c.JSON(slack.Ephemeral(strings.TrimPrefix(err.Error(), commands.ErrUsage.Error()+": ") + "\n\n" + commands.Help))
+		}
+
+		done := make(chan slack.Message, 1)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			done <- h.run(ctx, in, sc, cmd)
+		}()
+		select {
+		case msg := <-done:
+			return c.JSON(msg)
+		case <-time.After(slackInlineReplyWindow):
+			go func() {
+				msg := <-done
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				if err := h.client.Respond(ctx, sc.responseURL, msg); err != nil {
+					slog.Warn("slack delayed reply failed", "team_id", sc.teamID, "error", err)
+				}
+			}()
+			return c.JSON(slack.Ephemeral("Working on it…"))
+		}
+	}
+}
+
+func (h *SlackHandler) run(ctx context.Context, in slack.Installation, sc slashCommand, cmd commands.Command) slack.Message {
+	isInstaller := sc.userID == in.InstallerSlackUserID
+	switch cmd.Action {
+	case commands.ActionSubscribe, commands.ActionUnsubscribe:
+		if !isInstaller {
+			return slack.Ephemeral("Only the account that connected Grainlify can manage notifications.")
+		}
+		var projectID *uuid.UUID
+		label := "your projects"
+		if cmd.Project != "" {
+			id, name, err := commands.ResolveProject(ctx, h.db.Pool, cmd.Project)
+			if errors.Is(err, commands.ErrProjectNotFound) {
+				return slack.Ephemeral("No Grainlify project " + cmd.Project + ".")
+			}
+			if err != nil {
+				return slack.Ephemeral("Could not look up the project.")
+			}
+			projectID, label = &id, name
+		}
+		if cmd.Action == commands.ActionUnsubscribe {
+			err := slack.Unsubscribe(ctx, h.db.Pool, in.ID, sc.channelID, projectID)
+			if errors.Is(err, slack.ErrSubscriptionNotFound) {
+				return slack.Ephemeral("This channel is not subscribed to " + label + ".")
+			}
+			if err != nil {
+				return slack.Ephemeral("Could not unsubscribe, try again shortly.")
+			}
+			return slack.InChannel("Stopped bounty notifications for " + label + ".")
+		}
+		if _, err := slack.Subscribe(ctx, h.db.Pool, in.ID, sc.channelID, projectID, cmd.EventTypes); err != nil {
+			slog.Error("slack subscribe failed", "team_id", sc.teamID, "channel_id", sc.channelID, "error", err)
+			return slack.Ephemeral("Could not subscribe, try again shortly.")
+		}
+		return slack.InChannel("Bounty notifications for " + label + " will be posted here.")
+	}
+
+	runner := &commands.Runner{Pool: h.db.Pool, Providers: issues.Providers(h.cfg), TokenEncKeyB64: h.cfg.TokenEncKeyB64}
+	reply, err := runner.Run(ctx, commands.Scope{Actor: in.InstalledBy, CanWrite: isInstaller}, cmd)
+	if err != nil {
+		slog.Warn("slack command failed", "team_id", sc.teamID, "action", cmd.Action, "error", err)
+	}
+	if reply.Public {
+		return slack.InChannel(reply.Text)
+	}
+	return slack.Ephemeral(reply.Text)
+}
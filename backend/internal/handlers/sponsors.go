@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/sponsors"
+)
+
+// SponsorsHandler lets maintainers connect GitHub Sponsors listings and see
+// them alongside their bounty budgets.
+type SponsorsHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewSponsorsHandler(cfg config.Config, d *db.DB) *SponsorsHandler {
+	return &SponsorsHandler{cfg: cfg, db: d}
+}
+
+func (h *SponsorsHandler) syncer() *sponsors.Syncer {
+	return &sponsors.Syncer{Pool: h.db.Pool, GitHub: github.NewClient(), TokenEncKeyB64: h.cfg.TokenEncKeyB64}
+}
+
+func (h *SponsorsHandler) webhookURL(id uuid.UUID) string {
+	return strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/webhooks/github/sponsors/" + id.String()
+}
+
+func (h *SponsorsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		accounts, err := sponsors.ListAccounts(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "sponsor_accounts_list_failed")
+		}
+		out := make([]fiber.Map, 0, len(accounts))
+		for _, a := range accounts {
+			out = append(out, fiber.Map{"account": a, "webhook_url": h.webhookURL(a.ID)})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"accounts": out})
+	}
+}
+
+// Connect links a Sponsors listing (the caller's own login or an org they
+// administer) and returns the webhook URL and secret to configure in the
+// Sponsors dashboard. The secret is only shown here.
+func (h *SponsorsHandler) Connect() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req struct {
+			Login string `json:"login"`
+		}
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		linked, err := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "github_not_linked")
+		}
+		login := strings.TrimSpace(req.Login)
+		if login == "" {
+			login = linked.Login
+		}
+
+		ok, err := github.NewClient().CanAdministerSponsorable(c.Context(), linked.AccessToken, login)
+		if err != nil {
+			httpx.Logger(c).Warn("github sponsors ownership check failed", "user_id", userID.String(), "login", login, "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "github_check_failed")
+		}
+		if !ok {
+			return httpx.Fail(c, fiber.StatusForbidden, "not_sponsorable_admin")
+		}
+
+		account, secret, err := sponsors.Connect(c.Context(), h.db.Pool, userID, login, h.cfg.TokenEncKeyB64)
+		if errors.Is(err, sponsors.ErrLoginTaken) {
+			return httpx.Fail(c, fiber.StatusConflict, "sponsor_login_taken")
+		}
+		if err != nil {
+			httpx.Logger(c).Error("failed to connect sponsors account", "user_id", userID.String(), "login", login, "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "sponsor_connect_failed")
+		}
+
+		// Initial sync is best-effort; the scheduled sync retries.
+		if err := h.syncer().SyncAccount(c.Context(), account); err != nil {
+			httpx.Logger(c).Warn("initial github sponsors sync failed", "account_id", account.ID.String(), "error", err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"account":        account,
+			"webhook_url":    h.webhookURL(account.ID),
+			"webhook_secret": secret,
+		})
+	}
+}
+
+func (h *SponsorsHandler) Sync() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_account_id")
+		}
+		account, err := sponsors.GetAccount(c.Context(), h.db.Pool, userID, id)
+		if errors.Is(err, sponsors.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "sponsor_account_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "sponsor_account_get_failed")
+		}
+		if err := h.syncer().SyncAccount(c.Context(), account); err != nil {
+			httpx.Logger(c).Warn("github sponsors sync failed", "account_id", id.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusBadGateway, "sponsors_sync_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+func (h *SponsorsHandler) Disconnect() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_account_id")
+		}
+		err = sponsors.Disconnect(c.Context(), h.db.Pool, userID, id)
+		if errors.Is(err, sponsors.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "sponsor_account_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "sponsor_disconnect_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// Funding is the maintainer's combined funding dashboard: Sponsors income
+// next to bounty budgets.
+func (h *SponsorsHandler) Funding() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		f, err := sponsors.BuildFunding(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			httpx.Logger(c).Error("failed to build funding dashboard", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "funding_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(f)
+	}
+}
+
+// Webhook receives "sponsorship" deliveries configured in a maintainer's
+// Sponsors dashboard, signed with that account's secret.
+func (h *SponsorsHandler) Webhook() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusNotFound, "sponsor_account_not_found")
+		}
+		login, secret, err := sponsors.WebhookSecret(c.Context(), h.db.Pool, id, h.cfg.TokenEncKeyB64)
+		if errors.Is(err, sponsors.ErrNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "sponsor_account_not_found")
+		}
+		if err != nil {
+			httpx.Logger(c).Error("failed to load sponsors webhook secret", "account_id", id.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "webhook_secret_unavailable")
+		}
+		if !verifyGitHubSignature(secret, c.Body(), strings.TrimSpace(c.Get("X-Hub-Signature-256"))) {
+			httpx.Logger(c).Warn("sponsors webhook signature rejected", "account_id", id.String(), "remote_ip", c.IP())
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_signature")
+		}
+
+		event := strings.TrimSpace(c.Get("X-GitHub-Event"))
+		if event == "ping" {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		if event != "sponsorship" {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"ignored": event})
+		}
+		delivery := strings.TrimSpace(c.Get("X-GitHub-Delivery"))
+		if delivery == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_delivery_id")
+		}
+
+		err = sponsors.ApplyWebhook(c.Context(), h.db.Pool, id, login, delivery, c.Body())
+		if errors.Is(err, sponsors.ErrAccountMismatch) {
+			return httpx.Fail(c, fiber.StatusUnprocessableEntity, "sponsorable_mismatch")
+		}
+		if err != nil {
+			httpx.Logger(c).Error("failed to apply sponsorship webhook", "account_id", id.String(), "delivery_id", delivery, "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "sponsorship_apply_failed")
+		}
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
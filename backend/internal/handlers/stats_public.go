@@ -1,11 +1,10 @@
 package handlers
 
 import (
-	"log/slog"
-
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
 type LandingStatsHandler struct {
@@ -31,7 +30,7 @@ type LandingStatsResponse struct {
 func (h *LandingStatsHandler) Get() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		var resp LandingStatsResponse
@@ -57,8 +56,8 @@ SELECT
   (SELECT COUNT(DISTINCT LOWER(login)) FROM all_contributors) AS contributors
 `).Scan(&resp.ActiveProjects, &resp.Contributors)
 		if err != nil {
-			slog.Error("failed to fetch landing stats", "error", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "stats_fetch_failed"})
+			httpx.Logger(c).Error("failed to fetch landing stats", "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "stats_fetch_failed")
 		}
 
 		// No payouts/grants table exists yet in the schema.
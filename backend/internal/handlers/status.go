@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/status"
+)
+
+// statusHistoryDays is how far back /status lists resolved incidents.
+const statusHistoryDays = 90
+
+type StatusHandler struct {
+	db *db.DB
+}
+
+func NewStatusHandler(d *db.DB) *StatusHandler {
+	return &StatusHandler{db: d}
+}
+
+// Public returns the rolled-up status, components and incident history for
+// the status page.
+func (h *StatusHandler) Public() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			// The status page should still say something useful.
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status":     status.MajorOutage,
+				"components": []fiber.Map{{"id": "database", "name": "Database", "status": status.MajorOutage}},
+				"incidents":  []fiber.Map{},
+			})
+		}
+		components, err := status.ListComponents(c.Context(), h.db.Pool)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "status_unavailable")
+		}
+		incidents, err := status.ListIncidents(c.Context(), h.db.Pool, time.Now().UTC().AddDate(0, 0, -statusHistoryDays))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "status_unavailable")
+		}
+		c.Set(fiber.HeaderCacheControl, "public, max-age=30")
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"status":       status.Rollup(components),
+			"components":   components,
+			"incidents":    incidents,
+			"history_days": statusHistoryDays,
+			"generated_at": time.Now().UTC(),
+		})
+	}
+}
+
+func adminUserID(c *fiber.Ctx) *uuid.UUID {
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	id, err := uuid.Parse(sub)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+type setComponentStatusRequest struct {
+	// Status pins the component; empty with Automated hands it back to checks.
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	Automated bool   `json:"automated"`
+}
+
+func (h *StatusHandler) SetComponent() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		var req setComponentStatusRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		var err error
+		if req.Automated {
+			err = status.ReleaseManual(c.Context(), h.db.Pool, c.Params("id"))
+		} else {
+			err = status.SetManual(c.Context(), h.db.Pool, c.Params("id"), strings.TrimSpace(req.Status), req.Message)
+		}
+		switch {
+		case errors.Is(err, status.ErrComponentNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "status_component_not_found")
+		case errors.Is(err, status.ErrInvalidStatus):
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "status_update_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+type createIncidentRequest struct {
+	Title        string   `json:"title"`
+	Impact       string   `json:"impact"`
+	Status       string   `json:"status"`
+	ComponentIDs []string `json:"component_ids"`
+	Message      string   `json:"message"`
+}
+
+func (h *StatusHandler) CreateIncident() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		var req createIncidentRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if strings.TrimSpace(req.Title) == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_title")
+		}
+		inc, err := status.CreateIncident(c.Context(), h.db.Pool, adminUserID(c), status.NewIncident{
+			Title:        req.Title,
+			Impact:       req.Impact,
+			Status:       req.Status,
+			ComponentIDs: req.ComponentIDs,
+			Message:      req.Message,
+		})
+		switch {
+		case errors.Is(err, status.ErrInvalidImpact):
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_impact")
+		case errors.Is(err, status.ErrInvalidStatus):
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "incident_create_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(inc)
+	}
+}
+
+type incidentUpdateRequest struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func (h *StatusHandler) AddIncidentUpdate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		incidentID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_incident_id")
+		}
+		var req incidentUpdateRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if strings.TrimSpace(req.Message) == "" {
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_message")
+		}
+		u, err := status.AddUpdate(c.Context(), h.db.Pool, incidentID, adminUserID(c), strings.TrimSpace(req.Status), req.Message)
+		switch {
+		case errors.Is(err, status.ErrIncidentNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "status_incident_not_found")
+		case errors.Is(err, status.ErrInvalidStatus):
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "incident_update_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(u)
+	}
+}
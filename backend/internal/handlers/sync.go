@@ -10,6 +10,9 @@ import (
 
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/failures"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/orgs"
 )
 
 type SyncHandler struct {
@@ -23,31 +26,35 @@ func NewSyncHandler(d *db.DB) *SyncHandler {
 func (h *SyncHandler) EnqueueFullSync() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		projectID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 		}
 
 		var owner uuid.UUID
 		err = h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, projectID).Scan(&owner)
 		if errors.Is(err, pgx.ErrNoRows) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
 		}
 
 		role, _ := c.Locals(auth.LocalRole).(string)
-		if owner != userID && role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+		ok, err := managesProject(c.Context(), h.db.Pool, projectID, owner, userID, role, orgs.PermManageProjects)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+		}
+		if !ok {
+			return httpx.Fail(c, fiber.StatusForbidden, "forbidden")
 		}
 
 		_, _ = h.db.Pool.Exec(c.Context(), `
@@ -63,42 +70,46 @@ VALUES ($1, 'sync_issues', 'pending', now()),
 func (h *SyncHandler) JobsForProject() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		projectID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_project_id")
 		}
 
 		var owner uuid.UUID
 		err = h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, projectID).Scan(&owner)
 		if errors.Is(err, pgx.ErrNoRows) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "project_not_found")
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
 		}
 
 		role, _ := c.Locals(auth.LocalRole).(string)
-		if owner != userID && role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+		ok, err := managesProject(c.Context(), h.db.Pool, projectID, owner, userID, role, orgs.PermManageProjects)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "project_lookup_failed")
+		}
+		if !ok {
+			return httpx.Fail(c, fiber.StatusForbidden, "forbidden")
 		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
-SELECT id, job_type, status, run_at, attempts, last_error, created_at, updated_at
+SELECT id, job_type, status, run_at, attempts, last_error, last_error_code, created_at, updated_at
 FROM sync_jobs
 WHERE project_id = $1
 ORDER BY created_at DESC
 LIMIT 50
 `, projectID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_list_failed"})
+			return httpx.Fail(c, fiber.StatusInternalServerError, "jobs_list_failed")
 		}
 		defer rows.Close()
 
@@ -108,9 +119,9 @@ LIMIT 50
 			var jobType, status string
 			var runAt, createdAt, updatedAt time.Time
 			var attempts int
-			var lastErr *string
-			if err := rows.Scan(&id, &jobType, &status, &runAt, &attempts, &lastErr, &createdAt, &updatedAt); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_list_failed"})
+			var lastErr, lastErrCode *string
+			if err := rows.Scan(&id, &jobType, &status, &runAt, &attempts, &lastErr, &lastErrCode, &createdAt, &updatedAt); err != nil {
+				return httpx.Fail(c, fiber.StatusInternalServerError, "jobs_list_failed")
 			}
 			out = append(out, fiber.Map{
 				"id":         id.String(),
@@ -119,6 +130,7 @@ LIMIT 50
 				"run_at":     runAt,
 				"attempts":   attempts,
 				"last_error": lastErr,
+				"failure":    failures.FromStored(lastErrCode, lastErr),
 				"created_at": createdAt,
 				"updated_at": updatedAt,
 			})
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"fmt"
-	"log/slog"
 	"strings"
 	"time"
 
@@ -10,9 +9,11 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/badges"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
 )
 
 type UserProfileHandler struct {
@@ -31,14 +32,14 @@ func NewUserProfileHandler(cfg config.Config, d *db.DB) *UserProfileHandler {
 func (h *UserProfileHandler) Profile() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		// Get user ID from JWT
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		// Get user's GitHub login from github_accounts
@@ -86,8 +87,8 @@ SELECT
    WHERE pr.author_login = $1 AND p.status = 'verified')
 `, *githubLogin).Scan(&contributionsCount)
 		if err != nil {
-			slog.Error("failed to count contributions", "error", err, "user_id", userID, "github_login", *githubLogin)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contribution_count_failed"})
+			httpx.Logger(c).Error("failed to count contributions", "error", err, "user_id", userID, "github_login", *githubLogin)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "contribution_count_failed")
 		}
 
 		// Get most active languages (top 10)
@@ -108,8 +109,8 @@ ORDER BY contribution_count DESC, p.language ASC
 LIMIT 10
 `, *githubLogin)
 		if err != nil {
-			slog.Error("failed to fetch languages", "error", err, "user_id", userID, "github_login", *githubLogin)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "languages_fetch_failed"})
+			httpx.Logger(c).Error("failed to fetch languages", "error", err, "user_id", userID, "github_login", *githubLogin)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "languages_fetch_failed")
 		}
 		defer langRows.Close()
 
@@ -118,7 +119,7 @@ LIMIT 10
 			var lang string
 			var count int
 			if err := langRows.Scan(&lang, &count); err != nil {
-				slog.Error("failed to scan language row", "error", err)
+				httpx.Logger(c).Error("failed to scan language row", "error", err)
 				continue
 			}
 			languages = append(languages, fiber.Map{
@@ -146,8 +147,8 @@ ORDER BY contribution_count DESC, e.name ASC
 LIMIT 10
 `, *githubLogin)
 		if err != nil {
-			slog.Error("failed to fetch ecosystems", "error", err, "user_id", userID, "github_login", *githubLogin)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_fetch_failed"})
+			httpx.Logger(c).Error("failed to fetch ecosystems", "error", err, "user_id", userID, "github_login", *githubLogin)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "ecosystems_fetch_failed")
 		}
 		defer ecoRows.Close()
 
@@ -156,7 +157,7 @@ LIMIT 10
 			var ecoName string
 			var count int
 			if err := ecoRows.Scan(&ecoName, &count); err != nil {
-				slog.Error("failed to scan ecosystem row", "error", err)
+				httpx.Logger(c).Error("failed to scan ecosystem row", "error", err)
 				continue
 			}
 			ecosystems = append(ecosystems, fiber.Map{
@@ -240,7 +241,7 @@ INNER JOIN projects p ON contributions.project_id = p.id
 WHERE p.status = 'verified'
 `, *githubLogin).Scan(&projectsContributedToCount)
 		if err != nil {
-			slog.Warn("failed to count projects contributed to", "error", err, "user_id", userID, "github_login", *githubLogin)
+			httpx.Logger(c).Warn("failed to count projects contributed to", "error", err, "user_id", userID, "github_login", *githubLogin)
 			projectsContributedToCount = 0
 		}
 
@@ -255,7 +256,7 @@ WHERE p.status = 'verified'
   AND SPLIT_PART(p.github_full_name, '/', 1) = $1
 `, *githubLogin).Scan(&projectsLedCount)
 		if err != nil {
-			slog.Warn("failed to count projects led", "error", err, "user_id", userID, "github_login", *githubLogin)
+			httpx.Logger(c).Warn("failed to count projects led", "error", err, "user_id", userID, "github_login", *githubLogin)
 			projectsLedCount = 0
 		}
 
@@ -309,7 +310,7 @@ WHERE p.status = 'verified'
 func (h *UserProfileHandler) ContributionCalendar() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		var githubLogin *string
@@ -323,7 +324,7 @@ func (h *UserProfileHandler) ContributionCalendar() fiber.Handler {
 			// Fetch by user_id
 			parsedUserID, err := uuid.Parse(userIDParam)
 			if err != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
 			}
 			err = h.db.Pool.QueryRow(c.Context(), `
 SELECT login
@@ -338,7 +339,7 @@ WHERE user_id = $1
 			sub, _ := c.Locals(auth.LocalUserID).(string)
 			userID, err := uuid.Parse(sub)
 			if err != nil {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+				return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 			}
 			err = h.db.Pool.QueryRow(c.Context(), `
 SELECT login
@@ -388,8 +389,8 @@ GROUP BY DATE(contribution_date)
 ORDER BY date ASC
 `, *githubLogin, startDate, now)
 		if err != nil {
-			slog.Error("failed to fetch contribution calendar", "error", err, "github_login", *githubLogin)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "calendar_fetch_failed"})
+			httpx.Logger(c).Error("failed to fetch contribution calendar", "error", err, "github_login", *githubLogin)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "calendar_fetch_failed")
 		}
 		defer rows.Close()
 
@@ -400,7 +401,7 @@ ORDER BY date ASC
 			var date time.Time
 			var count int
 			if err := rows.Scan(&date, &count); err != nil {
-				slog.Error("failed to scan calendar row", "error", err)
+				httpx.Logger(c).Error("failed to scan calendar row", "error", err)
 				continue
 			}
 			dateStr := date.Format("2006-01-02")
@@ -450,7 +451,7 @@ ORDER BY date ASC
 func (h *UserProfileHandler) ContributionActivity() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		// Get pagination parameters
@@ -471,7 +472,7 @@ func (h *UserProfileHandler) ContributionActivity() fiber.Handler {
 			// Fetch by user_id
 			parsedUserID, err := uuid.Parse(userIDParam)
 			if err != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
 			}
 			err = h.db.Pool.QueryRow(c.Context(), `
 SELECT login
@@ -486,7 +487,7 @@ WHERE user_id = $1
 			sub, _ := c.Locals(auth.LocalUserID).(string)
 			userID, err := uuid.Parse(sub)
 			if err != nil {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+				return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 			}
 			err = h.db.Pool.QueryRow(c.Context(), `
 SELECT login
@@ -541,8 +542,8 @@ ORDER BY created_at_github DESC
 LIMIT $2 OFFSET $3
 `, *githubLogin, limit, offset)
 		if err != nil {
-			slog.Error("failed to fetch contribution activity", "error", err, "github_login", *githubLogin)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "activity_fetch_failed"})
+			httpx.Logger(c).Error("failed to fetch contribution activity", "error", err, "github_login", *githubLogin)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "activity_fetch_failed")
 		}
 		defer rows.Close()
 
@@ -556,7 +557,7 @@ LIMIT $2 OFFSET $3
 			var createdAt *time.Time
 
 			if err := rows.Scan(&contribType, &id, &number, &title, &url, &createdAt, &state, &projectName, &projectID); err != nil {
-				slog.Error("failed to scan activity row", "error", err)
+				httpx.Logger(c).Error("failed to scan activity row", "error", err)
 				continue
 			}
 
@@ -595,7 +596,7 @@ SELECT
    WHERE pr.author_login = $1 AND p.status = 'verified' AND pr.created_at_github IS NOT NULL)
 `, *githubLogin).Scan(&total)
 		if err != nil {
-			slog.Error("failed to count total activities", "error", err)
+			httpx.Logger(c).Error("failed to count total activities", "error", err)
 			total = len(activities) // Fallback
 		}
 
@@ -613,7 +614,7 @@ SELECT
 func (h *UserProfileHandler) ProjectsContributed() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		var githubLogin *string
@@ -627,7 +628,7 @@ func (h *UserProfileHandler) ProjectsContributed() fiber.Handler {
 			// Fetch by user_id
 			parsedUserID, parseErr := uuid.Parse(userIDParam)
 			if parseErr != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
 			}
 			err = h.db.Pool.QueryRow(c.Context(), `
 SELECT login
@@ -643,7 +644,7 @@ WHERE user_id = $1
 			sub, _ := c.Locals(auth.LocalUserID).(string)
 			userID, parseErr := uuid.Parse(sub)
 			if parseErr != nil {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+				return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 			}
 			err = h.db.Pool.QueryRow(c.Context(), `
 SELECT login
@@ -653,14 +654,14 @@ WHERE user_id = $1
 		}
 
 		if err != nil || githubLogin == nil || *githubLogin == "" {
-			slog.Warn("no github login found for user",
+			httpx.Logger(c).Warn("no github login found for user",
 				"err", err,
 				"user_id_param", userIDParam,
 				"login_param", loginParam,
 			)
 			return c.Status(fiber.StatusOK).JSON([]fiber.Map{})
 		}
-		slog.Warn("ProjectsContributed: resolved github login",
+		httpx.Logger(c).Warn("ProjectsContributed: resolved github login",
 			"github_login", githubLogin,
 			"query_user_id", userIDParam,
 			"query_login", loginParam,
@@ -695,8 +696,8 @@ ORDER BY p.github_full_name ASC
 LIMIT 10
 `, *githubLogin)
 		if err != nil {
-			slog.Error("failed to fetch contributed projects", "error", err, "github_login", *githubLogin)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_fetch_failed"})
+			httpx.Logger(c).Error("failed to fetch contributed projects", "error", err, "github_login", *githubLogin)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "projects_fetch_failed")
 		}
 		defer rows.Close()
 
@@ -730,11 +731,11 @@ LIMIT 10
 			var ownerUserID *uuid.UUID
 
 			if err := rows.Scan(&id, &fullName, &status, &ecosystemName, &language, &ownerUserID); err != nil {
-				slog.Error("failed to scan project row", "error", err)
+				httpx.Logger(c).Error("failed to scan project row", "error", err)
 				continue
 			}
 
-			slog.Warn("ProjectsContributed: no github login resolved",
+			httpx.Logger(c).Warn("ProjectsContributed: no github login resolved",
 				"err", err,
 				"githubLogin", githubLogin,
 				"query_user_id", userIDParam,
@@ -768,7 +769,7 @@ LIMIT 10
 func (h *UserProfileHandler) PublicProfile() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		// Get identifier from query params (user_id or login)
@@ -776,7 +777,7 @@ func (h *UserProfileHandler) PublicProfile() fiber.Handler {
 		loginParam := c.Query("login")
 
 		if userIDParam == "" && loginParam == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_identifier"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "missing_identifier")
 		}
 
 		var githubLogin *string
@@ -787,7 +788,7 @@ func (h *UserProfileHandler) PublicProfile() fiber.Handler {
 		if userIDParam != "" {
 			parsedUserID, err := uuid.Parse(userIDParam)
 			if err != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_user_id")
 			}
 			userID = &parsedUserID
 
@@ -798,7 +799,7 @@ WHERE user_id = $1
 `, parsedUserID).Scan(&githubLogin)
 			if err != nil {
 				// User doesn't have GitHub account linked
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+				return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
 			}
 
 			// Get profile fields
@@ -847,7 +848,7 @@ WHERE id = $1
 		}
 
 		if githubLogin == nil || *githubLogin == "" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+			return httpx.Fail(c, fiber.StatusNotFound, "user_not_found")
 		}
 
 		// Count total contributions (issues + PRs) for verified projects only
@@ -863,7 +864,7 @@ SELECT
    WHERE pr.author_login = $1 AND p.status = 'verified')
 `, *githubLogin).Scan(&contributionsCount)
 		if err != nil {
-			slog.Error("failed to count contributions", "error", err, "github_login", *githubLogin)
+			httpx.Logger(c).Error("failed to count contributions", "error", err, "github_login", *githubLogin)
 			contributionsCount = 0
 		}
 
@@ -890,7 +891,7 @@ ORDER BY contribution_count DESC
 LIMIT 10
 `, *githubLogin)
 		if err != nil {
-			slog.Error("failed to fetch languages", "error", err, "github_login", *githubLogin)
+			httpx.Logger(c).Error("failed to fetch languages", "error", err, "github_login", *githubLogin)
 		}
 		defer langRows.Close()
 
@@ -932,7 +933,7 @@ ORDER BY contribution_count DESC
 LIMIT 10
 `, *githubLogin)
 		if err != nil {
-			slog.Error("failed to fetch ecosystems", "error", err, "github_login", *githubLogin)
+			httpx.Logger(c).Error("failed to fetch ecosystems", "error", err, "github_login", *githubLogin)
 		}
 		defer ecoRows.Close()
 
@@ -1092,6 +1093,17 @@ WHERE u.id = $1
 			response["discord"] = *discord
 		}
 
+		// Achievement badges, with NFT token ids once minted
+		response["badges"] = []badges.Award{}
+		if userID != nil {
+			awards, err := badges.ForUser(c.Context(), h.db.Pool, *userID)
+			if err != nil {
+				httpx.Logger(c).Warn("failed to load badges", "error", err, "user_id", userID)
+			} else {
+				response["badges"] = awards
+			}
+		}
+
 		return c.Status(fiber.StatusOK).JSON(response)
 	}
 }
@@ -1126,14 +1138,14 @@ func calculateContributionLevel(count int, maxCount int) int {
 func (h *UserProfileHandler) UpdateProfile() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		// Get user ID from JWT
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		var req struct {
@@ -1149,8 +1161,8 @@ func (h *UserProfileHandler) UpdateProfile() fiber.Handler {
 			Discord   *string `json:"discord,omitempty"`
 		}
 
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 
 		// Build update query dynamically based on provided fields
@@ -1210,7 +1222,7 @@ func (h *UserProfileHandler) UpdateProfile() fiber.Handler {
 		}
 
 		if len(updates) == 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "no_fields_to_update"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "no_fields_to_update")
 		}
 
 		// Always update updated_at
@@ -1225,8 +1237,8 @@ WHERE id = $%d
 
 		_, err = h.db.Pool.Exec(c.Context(), query, args...)
 		if err != nil {
-			slog.Error("failed to update user profile", "error", err, "user_id", userID)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "profile_update_failed"})
+			httpx.Logger(c).Error("failed to update user profile", "error", err, "user_id", userID)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "profile_update_failed")
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "profile_updated"})
@@ -1237,34 +1249,34 @@ WHERE id = $%d
 func (h *UserProfileHandler) UpdateAvatar() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
 		}
 
 		// Get user ID from JWT
 		sub, _ := c.Locals(auth.LocalUserID).(string)
 		userID, err := uuid.Parse(sub)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
 		}
 
 		var req struct {
 			AvatarURL string `json:"avatar_url"`
 		}
 
-		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
 		}
 
 		avatarURL := strings.TrimSpace(req.AvatarURL)
 		if avatarURL == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "avatar_url_required"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "avatar_url_required")
 		}
 
 		// Validate URL format (either http/https URL or data URL)
 		if !strings.HasPrefix(avatarURL, "http://") &&
 			!strings.HasPrefix(avatarURL, "https://") &&
 			!strings.HasPrefix(avatarURL, "data:image/") {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_avatar_url_format"})
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_avatar_url_format")
 		}
 
 		_, err = h.db.Pool.Exec(c.Context(), `
@@ -1273,8 +1285,8 @@ SET avatar_url = $1, updated_at = now()
 WHERE id = $2
 `, avatarURL, userID)
 		if err != nil {
-			slog.Error("failed to update user avatar", "error", err, "user_id", userID)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "avatar_update_failed"})
+			httpx.Logger(c).Error("failed to update user avatar", "error", err, "user_id", userID)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "avatar_update_failed")
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+type walletBalance struct {
+	wallet.AddressBalance
+	// Sufficient compares the balance to the ?amount= the caller plans to move.
+	Sufficient *bool  `json:"sufficient,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// WalletBalance previews the on-chain balance of one of the caller's wallets
+// so the UI can warn before a transfer: ?amount= flags an underfunded wallet,
+// and fresh marks addresses with no on-chain history. EVM wallets are checked
+// on every configured EVM chain unless ?chain= narrows it; ?asset= defaults
+// to the native asset.
+func (h *PayoutsHandler) WalletBalance() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		walletID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_wallet_id")
+		}
+		w, err := auth.GetWallet(c.Context(), h.db.Pool, userID, walletID)
+		if errors.Is(err, auth.ErrWalletNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "wallet_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "wallet_lookup_failed")
+		}
+
+		asset := strings.TrimSpace(c.Query("asset", "native"))
+		var need *big.Rat
+		if a := strings.TrimSpace(c.Query("amount")); a != "" {
+			if need, err = wallet.ParseAmount(a); err != nil {
+				return httpx.Fail(c, fiber.StatusBadRequest, "invalid_amount")
+			}
+		}
+
+		address := w.Address
+		isStellar := w.WalletType != auth.WalletTypeEVM
+		if isStellar {
+			id, ok := wallet.StellarAccountID(w.Address)
+			if !ok {
+				return httpx.Fail(c, fiber.StatusUnprocessableEntity, "unsupported_wallet_address")
+			}
+			address = id
+		}
+
+		var chains []string
+		if ch := strings.ToLower(strings.TrimSpace(c.Query("chain"))); ch != "" {
+			if (ch == "stellar") != isStellar {
+				return httpx.Fail(c, fiber.StatusBadRequest, "chain_mismatch")
+			}
+			if _, ok := h.wallets.Get(ch); !ok {
+				return httpx.Fail(c, fiber.StatusBadRequest, "chain_not_configured")
+			}
+			chains = []string{ch}
+		} else {
+			for _, ch := range h.wallets.Chains() {
+				if (ch == "stellar") == isStellar {
+					chains = append(chains, ch)
+				}
+			}
+			sort.Strings(chains)
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+		defer cancel()
+		out := make([]walletBalance, 0, len(chains))
+		for _, ch := range chains {
+			sender, _ := h.wallets.Get(ch)
+			b, err := h.balances.Lookup(ctx, sender, address, asset)
+			if err != nil {
+				httpx.Logger(c).Warn("wallet balance lookup failed",
+					"chain", ch,
+					"wallet_id", walletID.String(),
+					"error", err,
+				)
+				out = append(out, walletBalance{
+					AddressBalance: wallet.AddressBalance{Chain: ch, Asset: asset, Address: address},
+					Error:          "balance_lookup_failed",
+				})
+				continue
+			}
+			entry := walletBalance{AddressBalance: b}
+			if need != nil {
+				have, err := wallet.ParseAmount(b.Balance)
+				ok := err == nil && have.Cmp(need) >= 0
+				entry.Sufficient = &ok
+			}
+			out = append(out, entry)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"wallet": w, "balances": out})
+	}
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+	"github.com/jagadeesh/grainlify/backend/internal/webhooks"
+)
+
+// WebhooksHandler manages outbound webhook endpoints, which receive the
+// bounty events of the owner's projects from the webhooks dispatcher.
+type WebhooksHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewWebhooksHandler(cfg config.Config, d *db.DB) *WebhooksHandler {
+	return &WebhooksHandler{cfg: cfg, db: d}
+}
+
+func (h *WebhooksHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		endpoints, err := webhooks.ListEndpoints(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "webhooks_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"endpoints":       endpoints,
+			"event_types":     bounties.EventTypes,
+			"current_version": webhooks.Versions.Current(),
+		})
+	}
+}
+
+type createWebhookRequest struct {
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	// Secret signs deliveries; empty generates one.
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	// Version pins the payload schema ("1" or "v1"); empty is the current one.
+	Version string `json:"version"`
+}
+
+// Create registers an endpoint. The response carries the signing secret,
+// which is never shown again.
+func (h *WebhooksHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		if strings.TrimSpace(h.cfg.TokenEncKeyB64) == "" {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "token_encryption_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		var req createWebhookRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		events, err := bounties.ParseEventTypes(strings.Join(req.Events, ","))
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_event_type").With("event_types", bounties.EventTypes))
+		}
+		version, err := webhooks.ParseVersion(req.Version)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "unsupported_event_version").With("current_version", webhooks.Versions.Current()))
+		}
+
+		ep, secret, err := webhooks.CreateEndpoint(c.Context(), h.db.Pool, userID, webhooks.NewEndpoint{
+			URL:          req.URL,
+			Description:  req.Description,
+			Secret:       req.Secret,
+			Events:       events,
+			EventVersion: version,
+		}, h.cfg.TokenEncKeyB64)
+		switch {
+		case errors.Is(err, webhooks.ErrInvalidURL), errors.Is(err, webhooks.ErrInvalidSecret):
+			return httpx.Fail(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, webhooks.ErrUnsupportedVersion):
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "unsupported_event_version").With("current_version", webhooks.Versions.Current()))
+		case errors.Is(err, webhooks.ErrTooManyEndpoints):
+			return httpx.Write(c, httpx.New(fiber.StatusConflict, "too_many_webhook_endpoints").With("max", webhooks.MaxEndpointsPerUser))
+		case err != nil:
+			httpx.Logger(c).Error("webhook endpoint create failed", "user_id", userID.String(), "error", err)
+			return httpx.Fail(c, fiber.StatusInternalServerError, "webhook_create_failed")
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"endpoint": ep, "secret": secret})
+	}
+}
+
+type updateWebhookRequest struct {
+	Active *bool `json:"active"`
+}
+
+// Update pauses or resumes an endpoint.
+func (h *WebhooksHandler) Update() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_webhook_id")
+		}
+		var req updateWebhookRequest
+		if err := httpx.DecodeJSON(c, &req); err != nil {
+			return httpx.Write(c, err)
+		}
+		if req.Active == nil {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, httpx.CodeInvalidJSON).WithMessage("active is required").With("field", "active"))
+		}
+		ep, err := webhooks.SetEndpointActive(c.Context(), h.db.Pool, userID, id, *req.Active)
+		if errors.Is(err, webhooks.ErrEndpointNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "webhook_endpoint_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "webhook_update_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(ep)
+	}
+}
+
+func (h *WebhooksHandler) Delete() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_webhook_id")
+		}
+		err = webhooks.DeleteEndpoint(c.Context(), h.db.Pool, userID, id)
+		if errors.Is(err, webhooks.ErrEndpointNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "webhook_endpoint_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "webhook_delete_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// Deliveries is the delivery log of an endpoint, for debugging receivers.
+func (h *WebhooksHandler) Deliveries() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_webhook_id")
+		}
+		status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+		switch status {
+		case "", webhooks.StatusPending, webhooks.StatusSucceeded, webhooks.StatusFailed:
+		default:
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_status")
+		}
+		limit := c.QueryInt("limit", 50)
+		if limit < 1 || limit > 200 {
+			limit = 50
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+		out, err := webhooks.ListDeliveries(c.Context(), h.db.Pool, userID, id, status, limit, offset)
+		if errors.Is(err, webhooks.ErrEndpointNotFound) {
+			return httpx.Fail(c, fiber.StatusNotFound, "webhook_endpoint_not_found")
+		}
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "webhook_deliveries_list_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"deliveries": out})
+	}
+}
+
+// Redeliver queues a succeeded or failed delivery to be sent again.
+func (h *WebhooksHandler) Redeliver() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return httpx.Fail(c, fiber.StatusServiceUnavailable, "db_not_configured")
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusUnauthorized, "invalid_user")
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_webhook_id")
+		}
+		deliveryID, err := uuid.Parse(c.Params("delivery_id"))
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusBadRequest, "invalid_delivery_id")
+		}
+		d, err := webhooks.Redeliver(c.Context(), h.db.Pool, userID, id, deliveryID)
+		switch {
+		case errors.Is(err, webhooks.ErrDeliveryNotFound):
+			return httpx.Fail(c, fiber.StatusNotFound, "webhook_delivery_not_found")
+		case errors.Is(err, webhooks.ErrDeliveryNotClosed):
+			return httpx.Fail(c, fiber.StatusConflict, "webhook_delivery_pending")
+		case err != nil:
+			return httpx.Fail(c, fiber.StatusInternalServerError, "webhook_redeliver_failed")
+		}
+		return c.Status(fiber.StatusOK).JSON(d)
+	}
+}
+
+// WebhookEventCatalog serves every webhook event type with the JSON Schema
+// of its data and an example delivery.
+func WebhookEventCatalog() fiber.Handler {
+	cat, err := webhooks.BuildCatalog()
+	var body []byte
+	if err == nil {
+		body, err = json.Marshal(cat)
+	}
+	return func(c *fiber.Ctx) error {
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "event_catalog_unavailable")
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.Status(fiber.StatusOK).Send(body)
+	}
+}
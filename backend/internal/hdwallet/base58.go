@@ -0,0 +1,79 @@
+package hdwallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const b58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	bigRadix = big.NewInt(58)
+	b58Index [256]int
+)
+
+func init() {
+	for i := range b58Index {
+		b58Index[i] = -1
+	}
+	for i, c := range b58Alphabet {
+		b58Index[c] = i
+	}
+}
+
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	var out []byte
+	for x.Sign() > 0 {
+		x.DivMod(x, bigRadix, mod)
+		out = append(out, b58Alphabet[mod.Int64()])
+	}
+	for _, v := range b {
+		if v != 0 {
+			break
+		}
+		out = append(out, b58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		v := b58Index[s[i]]
+		if v < 0 {
+			return nil, errors.New("invalid base58 character")
+		}
+		x.Mul(x, bigRadix)
+		x.Add(x, big.NewInt(int64(v)))
+	}
+	out := x.Bytes()
+	zeros := 0
+	for zeros < len(s) && s[zeros] == b58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), out...), nil
+}
+
+func base58CheckDecode(s string) ([]byte, error) {
+	b, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 4 {
+		return nil, errors.New("base58check payload too short")
+	}
+	payload, sum := b[:len(b)-4], b[len(b)-4:]
+	h1 := sha256.Sum256(payload)
+	h2 := sha256.Sum256(h1[:])
+	if !bytes.Equal(h2[:4], sum) {
+		return nil, errors.New("base58check checksum mismatch")
+	}
+	return payload, nil
+}
@@ -0,0 +1,76 @@
+package hdwallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// ErrInvalidChild is returned for the ~1 in 2^127 indexes that BIP-32 says to skip.
+var ErrInvalidChild = errors.New("invalid child key, use the next index")
+
+// ExtendedPublicKey is a BIP-32 secp256k1 public node. Only non-hardened
+// children can be derived from it, which is the point: the API never holds
+// EVM private keys.
+type ExtendedPublicKey struct {
+	Key       *secp256k1.PublicKey
+	ChainCode []byte
+	Depth     byte
+}
+
+// ParseXPub decodes a base58check "xpub..." string (mainnet or testnet).
+func ParseXPub(s string) (*ExtendedPublicKey, error) {
+	b, err := base58CheckDecode(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse xpub: %w", err)
+	}
+	if len(b) != 78 {
+		return nil, fmt.Errorf("parse xpub: unexpected length %d", len(b))
+	}
+	version := binary.BigEndian.Uint32(b[0:4])
+	if version != 0x0488B21E && version != 0x043587CF {
+		return nil, fmt.Errorf("parse xpub: not a public key (version %08x)", version)
+	}
+	pub, err := secp256k1.ParsePubKey(b[45:78])
+	if err != nil {
+		return nil, fmt.Errorf("parse xpub: %w", err)
+	}
+	return &ExtendedPublicKey{Key: pub, ChainCode: append([]byte(nil), b[13:45]...), Depth: b[4]}, nil
+}
+
+// Child derives the non-hardened child at index (CKDpub).
+func (k *ExtendedPublicKey) Child(index uint32) (*ExtendedPublicKey, error) {
+	if index >= 0x80000000 {
+		return nil, fmt.Errorf("cannot derive hardened child from a public key")
+	}
+	data := make([]byte, 0, 37)
+	data = append(data, k.Key.SerializeCompressed()...)
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(data)
+	I := mac.Sum(nil)
+
+	var il secp256k1.ModNScalar
+	if overflow := il.SetByteSlice(I[:32]); overflow || il.IsZero() {
+		return nil, ErrInvalidChild
+	}
+	var tweak, parent, child secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&il, &tweak)
+	k.Key.AsJacobian(&parent)
+	secp256k1.AddNonConst(&tweak, &parent, &child)
+	if (child.X.IsZero() && child.Y.IsZero()) || child.Z.IsZero() {
+		return nil, ErrInvalidChild
+	}
+	child.ToAffine()
+
+	return &ExtendedPublicKey{
+		Key:       secp256k1.NewPublicKey(&child.X, &child.Y),
+		ChainCode: I[32:],
+		Depth:     k.Depth + 1,
+	}, nil
+}
@@ -0,0 +1,109 @@
+// Package hdwallet derives per-intent deposit addresses: BIP-32 public
+// derivation from an xpub for EVM chains, SLIP-10 ed25519 for Stellar (SEP-5)
+// and Solana.
+package hdwallet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stellar/go/strkey"
+)
+
+// Families share one derivation index space.
+const (
+	FamilyEVM     = "evm"
+	FamilyStellar = "stellar"
+	FamilySolana  = "solana"
+)
+
+var chainFamilies = map[string]string{
+	"ethereum": FamilyEVM,
+	"sepolia":  FamilyEVM,
+	"polygon":  FamilyEVM,
+	"arbitrum": FamilyEVM,
+	"optimism": FamilyEVM,
+	"base":     FamilyEVM,
+	"stellar":  FamilyStellar,
+	"solana":   FamilySolana,
+}
+
+// FamilyOf returns the derivation family for a chain name.
+func FamilyOf(chain string) (string, bool) {
+	f, ok := chainFamilies[strings.ToLower(strings.TrimSpace(chain))]
+	return f, ok
+}
+
+// Deriver turns (family, index) into an address. A family whose key material
+// is not configured is unavailable.
+type Deriver struct {
+	evm     *ExtendedPublicKey // account-level xpub, e.g. m/44'/60'/0'/0
+	ed25519 *Ed25519Node       // SLIP-10 master
+}
+
+func NewDeriver(evmXPub string, ed25519Seed []byte) (*Deriver, error) {
+	d := &Deriver{}
+	if strings.TrimSpace(evmXPub) != "" {
+		k, err := ParseXPub(strings.TrimSpace(evmXPub))
+		if err != nil {
+			return nil, err
+		}
+		d.evm = k
+	}
+	if len(ed25519Seed) > 0 {
+		m, err := NewEd25519Master(ed25519Seed)
+		if err != nil {
+			return nil, err
+		}
+		d.ed25519 = m
+	}
+	return d, nil
+}
+
+func (d *Deriver) Supports(family string) bool {
+	if d == nil {
+		return false
+	}
+	switch family {
+	case FamilyEVM:
+		return d.evm != nil
+	case FamilyStellar, FamilySolana:
+		return d.ed25519 != nil
+	}
+	return false
+}
+
+// Address derives the address at index for family.
+func (d *Deriver) Address(family string, index uint32) (string, error) {
+	if !d.Supports(family) {
+		return "", fmt.Errorf("deposit derivation for %q is not configured", family)
+	}
+	switch family {
+	case FamilyEVM:
+		child, err := d.evm.Child(index)
+		if err != nil {
+			return "", err
+		}
+		hash := crypto.Keccak256(child.Key.SerializeUncompressed()[1:])
+		return common.BytesToAddress(hash[12:]).Hex(), nil
+
+	case FamilyStellar:
+		// SEP-5: m/44'/148'/index'
+		n, err := d.ed25519.Path(44, 148, index)
+		if err != nil {
+			return "", err
+		}
+		return strkey.Encode(strkey.VersionByteAccountID, n.PublicKey())
+
+	case FamilySolana:
+		// Phantom/Solflare layout: m/44'/501'/index'/0'
+		n, err := d.ed25519.Path(44, 501, index, 0)
+		if err != nil {
+			return "", err
+		}
+		return base58Encode(n.PublicKey()), nil
+	}
+	return "", fmt.Errorf("unknown derivation family %q", family)
+}
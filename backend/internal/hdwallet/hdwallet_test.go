@@ -0,0 +1,78 @@
+package hdwallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// BIP-32 test vector 1: m/0H -> m/0H/1.
+func TestXPubChild(t *testing.T) {
+	parent, err := ParseXPub("xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ParseXPub("xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parent.Child(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Key.SerializeCompressed(), want.Key.SerializeCompressed()) || !bytes.Equal(got.ChainCode, want.ChainCode) {
+		t.Fatalf("child mismatch")
+	}
+	if _, err := parent.Child(0x80000000); err == nil {
+		t.Fatal("hardened derivation from xpub should fail")
+	}
+}
+
+// SLIP-10 ed25519 test vector 1.
+func TestSLIP10(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	m, err := NewEd25519Master(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(m.Key); got != "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7" {
+		t.Fatalf("master key = %s", got)
+	}
+	c, err := m.Child(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(c.Key); got != "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3" {
+		t.Fatalf("m/0H key = %s", got)
+	}
+}
+
+func TestBase58RoundTrip(t *testing.T) {
+	for _, in := range [][]byte{{0, 0, 1, 2}, {255, 254}, {}} {
+		out, err := base58Decode(base58Encode(in))
+		if err != nil || !bytes.Equal(out, in) {
+			t.Fatalf("round trip %x -> %x (%v)", in, out, err)
+		}
+	}
+}
+
+func TestDeriverAddresses(t *testing.T) {
+	seed := bytes.Repeat([]byte{1}, 32)
+	d, err := NewDeriver("xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, fam := range []string{FamilyEVM, FamilyStellar, FamilySolana} {
+		for i := uint32(0); i < 3; i++ {
+			a, err := d.Address(fam, i)
+			if err != nil {
+				t.Fatalf("%s/%d: %v", fam, i, err)
+			}
+			if seen[a] {
+				t.Fatalf("duplicate address %s", a)
+			}
+			seen[a] = true
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package hdwallet
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+)
+
+const hardened = 0x80000000
+
+// Ed25519Node is a SLIP-10 ed25519 node. ed25519 only supports hardened
+// derivation, so deriving deposit addresses for Stellar/Solana needs the seed.
+type Ed25519Node struct {
+	Key       []byte // 32-byte private seed for ed25519.NewKeyFromSeed
+	ChainCode []byte
+}
+
+func NewEd25519Master(seed []byte) (*Ed25519Node, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("seed must be 16..64 bytes")
+	}
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	return &Ed25519Node{Key: I[:32], ChainCode: I[32:]}, nil
+}
+
+// Child derives the hardened child index' (index must not include the hardened bit).
+func (n *Ed25519Node) Child(index uint32) (*Ed25519Node, error) {
+	if index >= hardened {
+		return nil, fmt.Errorf("index out of range")
+	}
+	data := make([]byte, 0, 37)
+	data = append(data, 0x00)
+	data = append(data, n.Key...)
+	data = binary.BigEndian.AppendUint32(data, index|hardened)
+
+	mac := hmac.New(sha512.New, n.ChainCode)
+	mac.Write(data)
+	I := mac.Sum(nil)
+	return &Ed25519Node{Key: I[:32], ChainCode: I[32:]}, nil
+}
+
+// Path derives a sequence of hardened children.
+func (n *Ed25519Node) Path(indexes ...uint32) (*Ed25519Node, error) {
+	cur := n
+	for _, i := range indexes {
+		next, err := cur.Child(i)
+		if err != nil {
+			return nil, err
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func (n *Ed25519Node) PublicKey() ed25519.PublicKey {
+	return ed25519.NewKeyFromSeed(n.Key).Public().(ed25519.PublicKey)
+}
+
+func (n *Ed25519Node) PrivateKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(n.Key)
+}
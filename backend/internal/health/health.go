@@ -0,0 +1,104 @@
+// Package health probes the dependencies a process needs to serve traffic,
+// for readiness checks.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Dependency statuses.
+const (
+	StatusOK   = "ok"
+	StatusDown = "down"
+	// StatusDisabled is reported for a dependency that isn't configured.
+	StatusDisabled = "disabled"
+)
+
+// Dependency is one thing a process talks to. Only a Critical dependency
+// being down makes the process unready: the API degrades without Redis
+// (rate limits fall back to memory) or GitHub, and failing every instance's
+// readiness on a GitHub outage would take the whole API out of service.
+type Dependency struct {
+	Name     string
+	Critical bool
+	// Check returns nil when the dependency is reachable. A nil Check
+	// reports the dependency as disabled.
+	Check func(ctx context.Context) error
+}
+
+// Result is a dependency's status and how long its check took.
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Check probes every dependency concurrently, each bounded by timeout, and
+// reports whether all critical ones are up.
+func Check(ctx context.Context, deps []Dependency, timeout time.Duration) (bool, []Result) {
+	out := make([]Result, len(deps))
+	var wg sync.WaitGroup
+	for i, d := range deps {
+		out[i] = Result{Name: d.Name, Status: StatusDisabled, Critical: d.Critical}
+		if d.Check == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(r *Result, check func(context.Context) error) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			err := check(checkCtx)
+			r.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+			if err != nil {
+				r.Status, r.Error = StatusDown, err.Error()
+				return
+			}
+			r.Status = StatusOK
+		}(&out[i], d.Check)
+	}
+	wg.Wait()
+	ready := true
+	for _, r := range out {
+		if r.Critical && r.Status != StatusOK {
+			ready = false
+		}
+	}
+	return ready, out
+}
+
+var draining atomic.Bool
+
+// StartDraining makes the process report unready from now on, so load
+// balancers stop sending it traffic while it shuts down.
+func StartDraining() { draining.Store(true) }
+
+// Draining reports whether StartDraining was called.
+func Draining() bool { return draining.Load() }
+
+// Cached wraps check so it runs at most once per ttl, returning the last
+// result in between. Probes hit readiness every few seconds; external APIs
+// shouldn't be.
+func Cached(ttl time.Duration, check func(ctx context.Context) error) func(ctx context.Context) error {
+	var (
+		mu   sync.Mutex
+		last time.Time
+		err  error
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !last.IsZero() && time.Since(last) < ttl {
+			return err
+		}
+		err = check(ctx)
+		last = time.Now()
+		return err
+	}
+}
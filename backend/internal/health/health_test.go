@@ -0,0 +1,56 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ready, results := Check(context.Background(), []Dependency{
+		{Name: "database", Critical: true, Check: up},
+		{Name: "redis"},
+		{Name: "github", Check: down},
+	}, time.Second)
+	if !ready {
+		t.Fatal("a non-critical dependency made the process unready")
+	}
+	if results[0].Status != StatusOK || results[1].Status != StatusDisabled || results[2].Status != StatusDown {
+		t.Fatalf("got %+v", results)
+	}
+	if results[2].Error != "connection refused" {
+		t.Fatalf("got %+v", results[2])
+	}
+
+	ready, results = Check(context.Background(), []Dependency{{Name: "database", Critical: true, Check: slow}}, 10*time.Millisecond)
+	if ready || results[0].Status != StatusDown {
+		t.Fatalf("got %v %+v", ready, results)
+	}
+	if ready, _ := Check(context.Background(), []Dependency{{Name: "database", Critical: true}}, time.Second); ready {
+		t.Fatal("an unconfigured critical dependency should make the process unready")
+	}
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	check := Cached(time.Hour, func(context.Context) error {
+		calls++
+		return errors.New("down")
+	})
+	for i := 0; i < 3; i++ {
+		if err := check(context.Background()); err == nil {
+			t.Fatal("cached error dropped")
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("check ran %d times", calls)
+	}
+}
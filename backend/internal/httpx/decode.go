@@ -0,0 +1,234 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeInvalidJSON is sent for request bodies DecodeJSON rejects.
+const CodeInvalidJSON = "invalid_json"
+
+// DecodeJSON decodes the request body, a single JSON value, into out. Unlike
+// fiber's BodyParser it refuses what would otherwise be silently dropped or
+// zeroed: fields out doesn't have, values of the wrong type (a number for a
+// string, a string for a bool) and anything after the value. The returned
+// *Error names the offending field by its JSON path, e.g. "splits[1].share",
+// in its message and in details.field.
+func DecodeJSON(c *fiber.Ctx, out any) error {
+	if ct := c.Get(fiber.HeaderContentType); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mt != fiber.MIMEApplicationJSON && !strings.HasSuffix(mt, "+json")) {
+			return New(fiber.StatusUnsupportedMediaType, "unsupported_content_type").
+				WithMessage("request body must be application/json")
+		}
+	}
+	return decodeJSON(c.Body(), out)
+}
+
+func decodeJSON(body []byte, out any) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(out)
+	if err == nil {
+		if _, terr := dec.Token(); terr != io.EOF {
+			return New(fiber.StatusBadRequest, CodeInvalidJSON).
+				WithMessage("request body must hold a single JSON value")
+		}
+		return nil
+	}
+
+	base := New(fiber.StatusBadRequest, CodeInvalidJSON).Wrap(err)
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return base.WithMessage("request body is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return base.WithMessage("request body is truncated")
+	case errors.As(err, &syntaxErr):
+		return base.WithMessage(fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset))
+	case errors.As(err, &typeErr):
+		field := indexPath(typeErr.Field)
+		if field == "" {
+			return base.WithMessage(fmt.Sprintf("request body must be %s, got %s", jsonKind(typeErr.Type), typeErr.Value))
+		}
+		return base.WithMessage(fmt.Sprintf("%s must be %s, got %s", field, jsonKind(typeErr.Type), typeErr.Value)).
+			With("field", field)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		// The decoder only names the key; find where it is.
+		var raw any
+		if json.Unmarshal(body, &raw) == nil {
+			if path := unknownField(raw, reflect.TypeOf(out), field, ""); path != "" {
+				field = path
+			}
+		}
+		return base.WithMessage(fmt.Sprintf("unknown field %s", field)).With("field", field)
+	}
+	return base.WithMessage(err.Error())
+}
+
+// jsonKind names the JSON type t decodes from.
+func jsonKind(t reflect.Type) string {
+	if t == nil {
+		return "a value"
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return "a string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	}
+	return "a " + t.String()
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// unknownField returns the path of a key name in v, decoded generically, that
+// type t has no field for; "" if there is none or t decodes itself.
+func unknownField(v any, t reflect.Type, name, path string) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshaler) {
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return ""
+		}
+		fields := jsonFields(t)
+		for key, val := range obj {
+			f, ok := lookupField(fields, key)
+			if !ok {
+				if key == name {
+					return joinPath(path, key)
+				}
+				continue
+			}
+			if p := unknownField(val, f.Type, name, joinPath(path, key)); p != "" {
+				return p
+			}
+		}
+	case reflect.Map:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return ""
+		}
+		for key, val := range obj {
+			if p := unknownField(val, t.Elem(), name, joinPath(path, key)); p != "" {
+				return p
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		arr, ok := v.([]any)
+		if !ok {
+			return ""
+		}
+		for i, val := range arr {
+			if p := unknownField(val, t.Elem(), name, fmt.Sprintf("%s[%d]", path, i)); p != "" {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// jsonFields maps the JSON names of t's fields, promoted ones included, to
+// their fields.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	out := map[string]reflect.StructField{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// Its fields are promoted and listed on their own.
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, dup := out[name]; !dup {
+			out[name] = f
+		}
+	}
+	return out
+}
+
+// lookupField matches key like encoding/json: exactly, else ignoring case.
+func lookupField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if f, ok := fields[key]; ok {
+		return f, true
+	}
+	for name, f := range fields {
+		if strings.EqualFold(name, key) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// indexPath writes the array indexes newer decoders put in type error paths
+// ("splits.1.share") the way unknownField does ("splits[1].share").
+func indexPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	segs := strings.Split(path, ".")
+	out := segs[0]
+	for _, s := range segs[1:] {
+		if _, err := strconv.Atoi(s); err == nil {
+			out += "[" + s + "]"
+		} else {
+			out += "." + s
+		}
+	}
+	return out
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,104 @@
+package httpx
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type decodeSplit struct {
+	UserID string  `json:"user_id"`
+	Share  float64 `json:"share"`
+}
+
+type decodeBase struct {
+	Note string `json:"note"`
+}
+
+type decodeRequest struct {
+	decodeBase
+	Title    string            `json:"title"`
+	Draft    *bool             `json:"draft"`
+	Count    int               `json:"count"`
+	Splits   []decodeSplit     `json:"splits"`
+	Lead     *decodeSplit      `json:"lead"`
+	Labels   map[string]string `json:"labels"`
+	Due      *time.Time        `json:"due"`
+	Internal string            `json:"-"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var ok decodeRequest
+	if err := decodeJSON([]byte(`{"title":"x","Draft":true,"note":"n","splits":[{"user_id":"u","share":0.5}],"labels":{"a":"b"}}`), &ok); err != nil {
+		t.Fatalf("valid body: %v", err)
+	}
+	if ok.Title != "x" || ok.Draft == nil || !*ok.Draft || ok.Note != "n" || len(ok.Splits) != 1 {
+		t.Fatalf("decoded %+v", ok)
+	}
+
+	cases := []struct {
+		body, field, message string
+	}{
+		{`{"title": 5}`, "title", "title must be a string, got number"},
+		{`{"draft": "yes"}`, "draft", "draft must be a boolean, got string"},
+		{`{"count": 1.5}`, "count", "count must be an integer, got number 1.5"},
+		{`{"lead": {"share": "half"}}`, "lead.share", "lead.share must be a number, got string"},
+		{`{"due": 12}`, "due", "due must be a string, got number"},
+		{`{"titel": "x"}`, "titel", "unknown field titel"},
+		{`{"splits": [{"user_id": "u"}, {"user": "v"}]}`, "splits[1].user", "unknown field splits[1].user"},
+		{`{"Internal": "x"}`, "Internal", "unknown field Internal"},
+		{`[1]`, "", "request body must be an object, got array"},
+		{``, "", "request body is empty"},
+		{`{"title": "x"`, "", "request body is truncated"},
+		{`{"title": x}`, "", "malformed JSON at offset 11"},
+		{`{"title": "x"} {}`, "", "request body must hold a single JSON value"},
+	}
+	for _, tc := range cases {
+		var req decodeRequest
+		err := decodeJSON([]byte(tc.body), &req)
+		var e *Error
+		if !errors.As(err, &e) || e.Status != fiber.StatusBadRequest || e.Code != CodeInvalidJSON {
+			t.Errorf("decode %q: err = %v", tc.body, err)
+			continue
+		}
+		if e.Message != tc.message {
+			t.Errorf("decode %q: message = %q, want %q", tc.body, e.Message, tc.message)
+		}
+		if field, _ := e.Details["field"].(string); field != tc.field {
+			t.Errorf("decode %q: field = %q, want %q", tc.body, field, tc.field)
+		}
+	}
+}
+
+func TestDecodeJSONContentType(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/", func(c *fiber.Ctx) error {
+		var req decodeRequest
+		if err := DecodeJSON(c, &req); err != nil {
+			return Write(c, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	for ct, want := range map[string]int{
+		"application/json; charset=utf-8":   fiber.StatusNoContent,
+		"application/merge-patch+json":      fiber.StatusNoContent,
+		"":                                  fiber.StatusNoContent,
+		"application/x-www-form-urlencoded": fiber.StatusUnsupportedMediaType,
+	} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"title":"x"}`))
+		if ct != "" {
+			req.Header.Set("Content-Type", ct)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("content type %q: status %d, want %d", ct, resp.StatusCode, want)
+		}
+	}
+}
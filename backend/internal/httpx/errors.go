@@ -0,0 +1,171 @@
+// Package httpx is the HTTP plumbing shared by handlers: request IDs and
+// request-scoped logging, and the error envelope. Every error response has
+// the same shape, with a stable snake_case code clients can program against:
+//
+//	{"error": "bounty_not_found", "code": "bounty_not_found",
+//	 "message": "...", "details": {...}, "request_id": "..."}
+//
+// "error" repeats the code for clients written against the older
+// {"error": "<code>"} bodies.
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CodeInternal is sent for errors that carry no code of their own.
+const CodeInternal = "internal_error"
+
+// Error is an API error: the status and code to respond with, plus an
+// optional message, details and the underlying cause. The cause is logged,
+// never sent.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]any
+	Err     error
+}
+
+// New returns an error responding with status and code.
+func New(status int, code string) *Error {
+	return &Error{Status: status, Code: code}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// WithMessage returns a copy of e with a human-readable message.
+func (e *Error) WithMessage(msg string) *Error {
+	out := e.clone()
+	out.Message = msg
+	return out
+}
+
+// With returns a copy of e with one more detail.
+func (e *Error) With(key string, value any) *Error {
+	out := e.clone()
+	out.Details = make(map[string]any, len(e.Details)+1)
+	for k, v := range e.Details {
+		out.Details[k] = v
+	}
+	out.Details[key] = value
+	return out
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	out := e.clone()
+	out.Err = err
+	return out
+}
+
+func (e *Error) clone() *Error {
+	out := *e
+	return &out
+}
+
+// Body is the JSON error envelope.
+type Body struct {
+	// Error repeats Code for older clients.
+	Error     string         `json:"error"`
+	Code      string         `json:"code"`
+	Message   string         `json:"message,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+// Fail writes an error response with status and code.
+func Fail(c *fiber.Ctx, status int, code string) error {
+	return Write(c, New(status, code))
+}
+
+// Write writes err as an error response. Errors other than *Error are
+// converted by From.
+func Write(c *fiber.Ctx, err error) error {
+	e := From(err)
+	if e.Status >= http.StatusInternalServerError && e.Err != nil {
+		Logger(c).Error("request failed",
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", e.Status,
+			"code", e.Code,
+			"error", e.Err,
+		)
+	}
+	return c.Status(e.Status).JSON(Body{
+		Error:     e.Code,
+		Code:      e.Code,
+		Message:   e.Message,
+		Details:   e.Details,
+		RequestID: requestID(c),
+	})
+}
+
+// From converts err to an *Error: *Error as is, *fiber.Error by its status
+// (so routing and body-limit errors get codes too), anything else as an
+// internal error caused by err.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		out := New(fe.Code, codeForStatus(fe.Code))
+		if msg := fe.Message; msg != "" && msg != http.StatusText(fe.Code) {
+			out.Message = msg
+		}
+		if fe.Code >= http.StatusInternalServerError {
+			out.Err = err
+		}
+		return out
+	}
+	return New(http.StatusInternalServerError, CodeInternal).Wrap(err)
+}
+
+// codeForStatus derives a code from a status: 404 is "not_found", 413
+// "request_entity_too_large".
+func codeForStatus(status int) string {
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return "request_failed"
+	}
+	text = strings.ToLower(strings.NewReplacer("-", " ", "'", "").Replace(text))
+	return strings.Join(strings.Fields(text), "_")
+}
+
+// ErrorHandler is the app's fiber.Config.ErrorHandler: errors returned by
+// handlers and middleware, and the router's own, get the envelope.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	e := From(err)
+	if e.Status < http.StatusInternalServerError {
+		Logger(c).Warn("request error",
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", e.Status,
+			"code", e.Code,
+		)
+	}
+	return Write(c, e)
+}
+
+func requestID(c *fiber.Ctx) string {
+	if id, ok := c.Locals(localRequestID).(string); ok && id != "" {
+		return id
+	}
+	return c.GetRespHeader(fiber.HeaderXRequestID)
+}
@@ -0,0 +1,74 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestFrom(t *testing.T) {
+	base := New(fiber.StatusConflict, "bounty_already_open")
+	if got := From(fmt.Errorf("create: %w", base)); got != base {
+		t.Errorf("From(wrapped *Error) = %+v", got)
+	}
+	if got := From(fiber.ErrRequestEntityTooLarge); got.Status != 413 || got.Code != "request_entity_too_large" || got.Message != "" {
+		t.Errorf("From(fiber 413) = %+v", got)
+	}
+	if got := From(fiber.NewError(fiber.StatusBadRequest, "bad cursor")); got.Code != "bad_request" || got.Message != "bad cursor" {
+		t.Errorf("From(fiber 400) = %+v", got)
+	}
+	cause := errors.New("connection refused")
+	if got := From(cause); got.Status != 500 || got.Code != CodeInternal || !errors.Is(got, cause) {
+		t.Errorf("From(plain) = %+v", got)
+	}
+}
+
+func TestWithCopies(t *testing.T) {
+	base := New(fiber.StatusBadRequest, "invalid_event_type")
+	a := base.With("event_types", []string{"bounty.created"})
+	b := a.With("hint", "x")
+	if base.Details != nil || len(a.Details) != 1 || len(b.Details) != 2 {
+		t.Fatalf("details shared: base=%v a=%v b=%v", base.Details, a.Details, b.Details)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "req-1")
+		return c.Next()
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return Write(c, New(fiber.StatusConflict, "recovery_not_ready").WithMessage("not yet").With("available_at", "later"))
+	})
+	app.Get("/returned", func(c *fiber.Ctx) error {
+		return New(fiber.StatusForbidden, "forbidden")
+	})
+
+	cases := []struct {
+		path   string
+		status int
+		want   Body
+	}{
+		{"/fail", 409, Body{Error: "recovery_not_ready", Code: "recovery_not_ready", Message: "not yet", Details: map[string]any{"available_at": "later"}, RequestID: "req-1"}},
+		{"/returned", 403, Body{Error: "forbidden", Code: "forbidden", RequestID: "req-1"}},
+		{"/missing", 404, Body{Error: "not_found", Code: "not_found", Message: "Cannot GET /missing", RequestID: "req-1"}},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", tc.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Body
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: decode: %v", tc.path, err)
+		}
+		if resp.StatusCode != tc.status || fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%s: %d %+v, want %d %+v", tc.path, resp.StatusCode, got, tc.status, tc.want)
+		}
+	}
+}
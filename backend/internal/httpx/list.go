@@ -0,0 +1,495 @@
+package httpx
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Codes sent for list query parameters ParseList rejects.
+const (
+	CodeInvalidSort   = "invalid_sort"
+	CodeInvalidFilter = "invalid_filter"
+	CodeInvalidCursor = "invalid_cursor"
+)
+
+// MaxSortFields caps the fields one ?sort= may name.
+const MaxSortFields = 3
+
+// FieldType says how a list field's values are parsed and compared.
+type FieldType int
+
+const (
+	FieldText FieldType = iota
+	FieldInt
+	FieldNumeric
+	FieldTime
+	FieldBool
+	FieldUUID
+)
+
+// String is the type's name in the API docs.
+func (t FieldType) String() string {
+	switch t {
+	case FieldInt:
+		return "integer"
+	case FieldNumeric:
+		return "number"
+	case FieldTime:
+		return "RFC 3339 timestamp"
+	case FieldBool:
+		return "boolean"
+	case FieldUUID:
+		return "uuid"
+	}
+	return "string"
+}
+
+// pgType is the SQL type values are cast to; they are always sent as text.
+func (t FieldType) pgType() string {
+	switch t {
+	case FieldInt:
+		return "bigint"
+	case FieldNumeric:
+		return "numeric"
+	case FieldTime:
+		return "timestamptz"
+	case FieldBool:
+		return "boolean"
+	case FieldUUID:
+		return "uuid"
+	}
+	return "text"
+}
+
+// casts are what a value and a list of values, sent as text, are cast to
+// for comparison.
+func (t FieldType) casts() (one, many string) {
+	if pg := t.pgType(); pg != "text" {
+		return "text::" + pg, "text[]::" + pg + "[]"
+	}
+	return "text", "text[]"
+}
+
+// ordered reports whether values of the type can be compared by range.
+func (t FieldType) ordered() bool {
+	return t == FieldInt || t == FieldNumeric || t == FieldTime
+}
+
+// parse checks v and returns it in the form sent to the database.
+func (t FieldType) parse(v string) (string, error) {
+	switch t {
+	case FieldInt:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not an integer", v)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case FieldNumeric:
+		if _, ok := new(big.Rat).SetString(v); !ok || strings.Contains(v, "/") {
+			return "", fmt.Errorf("%q is not a number", v)
+		}
+	case FieldTime:
+		// Kept as given: cursors carry microseconds Go would round away.
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return "", fmt.Errorf("%q is not an RFC 3339 timestamp", v)
+		}
+	case FieldBool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a boolean", v)
+		}
+		return strconv.FormatBool(b), nil
+	case FieldUUID:
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a uuid", v)
+		}
+		return id.String(), nil
+	}
+	return v, nil
+}
+
+// Field is a column clients may sort or filter a list on.
+type Field struct {
+	// Column is the SQL expression, e.g. "p.created_at". Sorted columns
+	// must not be NULL; wrap them in COALESCE otherwise.
+	Column string
+	Type   FieldType
+	Sort   bool
+	Filter bool
+	// Values, when set, are the only values a filter accepts.
+	Values []string
+	// Fold lowercases filter values; Column should be lowercased to match.
+	Fold bool
+}
+
+// ListSpec is what a list endpoint lets clients sort and filter on.
+type ListSpec struct {
+	Fields map[string]Field
+	// DefaultSort applies without ?sort=, e.g. "-created_at".
+	DefaultSort string
+	// Key is a unique, non-NULL column of KeyType that ends every order, so
+	// rows with equal sort values still page in a stable order.
+	Key     string
+	KeyType FieldType
+	// DefaultLimit applies when ?limit= is missing or above MaxLimit.
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// Filter operators, written field[op]=value. Without an operator a filter
+// is eq; eq and ne take comma-separated values.
+const (
+	OpEq  = "eq"
+	OpNe  = "ne"
+	OpGt  = "gt"
+	OpGte = "gte"
+	OpLt  = "lt"
+	OpLte = "lte"
+)
+
+var filterOps = map[string]string{OpGt: ">", OpGte: ">=", OpLt: "<", OpLte: "<="}
+
+// SortKey is one field of a list's order.
+type SortKey struct {
+	Name string
+	Desc bool
+}
+
+// Filter is one parsed filter.
+type Filter struct {
+	Name   string
+	Op     string
+	Values []string
+}
+
+// List is a parsed list request: ?sort=-a,b, typed filters, ?limit= and an
+// opaque ?cursor= from the previous page's next_cursor.
+type List struct {
+	Sort    []SortKey
+	Filters []Filter
+	Limit   int
+	// Offset pages the older way; it is ignored with a cursor.
+	Offset int
+
+	spec  ListSpec
+	after []string
+}
+
+// ParseList reads a list request's query parameters. Parameters that are
+// not fields of spec are left to the handler.
+func ParseList(c *fiber.Ctx, spec ListSpec) (List, error) {
+	return parseList(c.Queries(), spec)
+}
+
+// Default is the list a request without parameters gets.
+func (s ListSpec) Default() List {
+	l, err := parseList(nil, s)
+	if err != nil {
+		panic("httpx: bad default sort " + s.DefaultSort)
+	}
+	return l
+}
+
+// Params names the query parameters ParseList reads for s, for callers
+// that vary on them (e.g. response caches).
+func (s ListSpec) Params() []string {
+	params := []string{"cursor", "limit", "offset", "sort"}
+	for name, f := range s.Fields {
+		if f.Filter {
+			params = append(params, name)
+		}
+	}
+	sort.Strings(params)
+	return params
+}
+
+func parseList(q map[string]string, spec ListSpec) (List, error) {
+	l := List{spec: spec, Limit: spec.DefaultLimit}
+	if n, err := strconv.Atoi(strings.TrimSpace(q["limit"])); err == nil && n > 0 && n <= spec.MaxLimit {
+		l.Limit = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(q["offset"])); err == nil && n > 0 {
+		l.Offset = n
+	}
+
+	order := strings.TrimSpace(q["sort"])
+	if order == "" {
+		order = spec.DefaultSort
+	}
+	for _, s := range strings.Split(order, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		k := SortKey{Name: strings.TrimPrefix(s, "-"), Desc: strings.HasPrefix(s, "-")}
+		if f, ok := spec.Fields[k.Name]; !ok || !f.Sort {
+			return List{}, New(http.StatusBadRequest, CodeInvalidSort).WithMessage("cannot sort by "+k.Name).With("field", k.Name)
+		}
+		if slices.ContainsFunc(l.Sort, func(o SortKey) bool { return o.Name == k.Name }) {
+			return List{}, New(http.StatusBadRequest, CodeInvalidSort).WithMessage(k.Name+" is sorted on twice").With("field", k.Name)
+		}
+		l.Sort = append(l.Sort, k)
+	}
+	if len(l.Sort) > MaxSortFields {
+		return List{}, New(http.StatusBadRequest, CodeInvalidSort).WithMessage(fmt.Sprintf("sort by at most %d fields", MaxSortFields))
+	}
+
+	// Sorted for stable SQL text.
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name, op := key, OpEq
+		if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+			name, op = key[:i], key[i+1:len(key)-1]
+		}
+		field, ok := spec.Fields[name]
+		if !ok || !field.Filter {
+			continue
+		}
+		f, err := parseFilter(name, op, q[key], field)
+		if err != nil {
+			return List{}, New(http.StatusBadRequest, CodeInvalidFilter).WithMessage(err.Error()).With("field", key)
+		}
+		if f.Values != nil {
+			l.Filters = append(l.Filters, f)
+		}
+	}
+
+	if c := strings.TrimSpace(q["cursor"]); c != "" {
+		after, err := l.decodeCursor(c)
+		if err != nil {
+			return List{}, New(http.StatusBadRequest, CodeInvalidCursor).WithMessage(err.Error())
+		}
+		l.after = after
+	}
+	return l, nil
+}
+
+func parseFilter(name, op, raw string, field Field) (Filter, error) {
+	f := Filter{Name: name, Op: op}
+	var values []string
+	switch {
+	case op == OpEq || op == OpNe:
+		values = strings.Split(raw, ",")
+	case filterOps[op] != "" && field.Type.ordered():
+		values = []string{raw}
+	case filterOps[op] != "":
+		return Filter{}, fmt.Errorf("%s cannot be compared with %s", name, op)
+	default:
+		return Filter{}, fmt.Errorf("unknown operator %s", op)
+	}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if field.Fold {
+			v = strings.ToLower(v)
+		}
+		if len(field.Values) > 0 && !slices.Contains(field.Values, v) {
+			return Filter{}, fmt.Errorf("%s must be one of %s", name, strings.Join(field.Values, ", "))
+		}
+		v, err := field.Type.parse(v)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%s: %w", name, err)
+		}
+		f.Values = append(f.Values, v)
+	}
+	return f, nil
+}
+
+// columns are the sort columns followed by the key, with their directions
+// and types.
+func (l List) columns() (cols []string, desc []bool, types []FieldType) {
+	for _, k := range l.Sort {
+		f := l.spec.Fields[k.Name]
+		cols, desc, types = append(cols, f.Column), append(desc, k.Desc), append(types, f.Type)
+	}
+	return append(cols, l.spec.Key), append(desc, false), append(types, l.spec.KeyType)
+}
+
+// Where returns the filters and cursor as one condition, with placeholders
+// numbered from argPos, and its arguments. It is empty when there are
+// neither.
+func (l List) Where(argPos int) (string, []any) {
+	var conds []string
+	var args []any
+	param := func(v any, cast string) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d::%s", argPos+len(args)-1, cast)
+	}
+	for _, f := range l.Filters {
+		field := l.spec.Fields[f.Name]
+		one, many := field.Type.casts()
+		switch {
+		case f.Op == OpEq && len(f.Values) == 1:
+			conds = append(conds, field.Column+" = "+param(f.Values[0], one))
+		case f.Op == OpEq:
+			conds = append(conds, field.Column+" = ANY("+param(f.Values, many)+")")
+		case f.Op == OpNe:
+			conds = append(conds, field.Column+" <> ALL("+param(f.Values, many)+")")
+		default:
+			conds = append(conds, field.Column+" "+filterOps[f.Op]+" "+param(f.Values[0], one))
+		}
+	}
+	if l.after != nil {
+		// Rows after the cursor: greater on the first column that differs,
+		// or less where that column sorts descending.
+		cols, desc, types := l.columns()
+		vals := make([]string, len(cols))
+		for i, v := range l.after {
+			one, _ := types[i].casts()
+			vals[i] = param(v, one)
+		}
+		ors := make([]string, len(cols))
+		for i := range cols {
+			ands := make([]string, 0, i+1)
+			for j := 0; j < i; j++ {
+				ands = append(ands, cols[j]+" = "+vals[j])
+			}
+			op := ">"
+			if desc[i] {
+				op = "<"
+			}
+			ands = append(ands, cols[i]+" "+op+" "+vals[i])
+			ors[i] = "(" + strings.Join(ands, " AND ") + ")"
+		}
+		conds = append(conds, "("+strings.Join(ors, " OR ")+")")
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// WithoutCursor is l from its first page, e.g. to count every match.
+func (l List) WithoutCursor() List {
+	l.after = nil
+	return l
+}
+
+// OrderBy is the list's ORDER BY clause.
+func (l List) OrderBy() string {
+	cols, desc, _ := l.columns()
+	terms := make([]string, len(cols))
+	for i, c := range cols {
+		terms[i] = c
+		if desc[i] {
+			terms[i] += " DESC"
+		}
+	}
+	return "ORDER BY " + strings.Join(terms, ", ")
+}
+
+// OffsetSQL is the OFFSET clause for clients still paging by offset; empty
+// with a cursor.
+func (l List) OffsetSQL() string {
+	if l.after != nil || l.Offset == 0 {
+		return ""
+	}
+	return fmt.Sprintf("OFFSET %d", l.Offset)
+}
+
+// CursorColumn is an SQL expression to select after the row's own columns;
+// the value from a page's last row makes its next cursor.
+func (l List) CursorColumn() string {
+	cols, _, _ := l.columns()
+	return "jsonb_build_array(" + strings.Join(cols, ", ") + ")::text"
+}
+
+// WithCursor scans row's CursorColumn into cursor after dest.
+func WithCursor(row pgx.Row, cursor *string) pgx.Row {
+	return cursorRow{row, cursor}
+}
+
+type cursorRow struct {
+	pgx.Row
+	cursor *string
+}
+
+func (r cursorRow) Scan(dest ...any) error {
+	return r.Row.Scan(append(dest, r.cursor)...)
+}
+
+type cursor struct {
+	Sort string          `json:"s"`
+	Keys json.RawMessage `json:"k"`
+}
+
+// sortString is the list's order as ?sort= spells it.
+func (l List) sortString() string {
+	parts := make([]string, len(l.Sort))
+	for i, k := range l.Sort {
+		parts[i] = k.Name
+		if k.Desc {
+			parts[i] = "-" + k.Name
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// NextCursor returns the cursor for the page after one of n rows ending in
+// the row whose CursorColumn was last; empty when it was the last page.
+func (l List) NextCursor(n int, last string) string {
+	if n < l.Limit || last == "" {
+		return ""
+	}
+	b, err := json.Marshal(cursor{Sort: l.sortString(), Keys: json.RawMessage(last)})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (l List) decodeCursor(s string) ([]string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	if c.Sort != l.sortString() {
+		return nil, fmt.Errorf("cursor is for sort %q; pass the same sort with it", c.Sort)
+	}
+	dec := json.NewDecoder(strings.NewReader(string(c.Keys)))
+	dec.UseNumber()
+	var keys []any
+	if err := dec.Decode(&keys); err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	_, _, types := l.columns()
+	if len(keys) != len(types) {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		var v string
+		switch k := k.(type) {
+		case string:
+			v = k
+		case json.Number:
+			v = k.String()
+		case bool:
+			v = strconv.FormatBool(k)
+		default:
+			return nil, fmt.Errorf("malformed cursor")
+		}
+		if out[i], err = types[i].parse(v); err != nil {
+			return nil, fmt.Errorf("malformed cursor")
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,107 @@
+package httpx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var testListSpec = ListSpec{
+	Fields: map[string]Field{
+		"created_at": {Column: "t.created_at", Type: FieldTime, Sort: true, Filter: true},
+		"amount":     {Column: "t.amount", Type: FieldNumeric, Sort: true, Filter: true},
+		"status":     {Column: "t.status", Filter: true, Values: []string{"open", "closed"}},
+		"language":   {Column: "lower(t.language)", Filter: true, Fold: true},
+		"stars":      {Column: "t.stars", Type: FieldInt, Filter: true},
+	},
+	DefaultSort:  "-created_at",
+	Key:          "t.id",
+	KeyType:      FieldUUID,
+	DefaultLimit: 50,
+	MaxLimit:     200,
+}
+
+func TestParseList(t *testing.T) {
+	l, err := parseList(map[string]string{
+		"sort":           "amount,-created_at",
+		"limit":          "500",
+		"status":         "open,closed",
+		"language":       "Go",
+		"stars[gte]":     "10",
+		"created_at[lt]": "2026-10-01T00:00:00Z",
+		"q":              "ignored",
+	}, testListSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Limit != 50 {
+		t.Errorf("limit = %d, want the default for one over the max", l.Limit)
+	}
+	if got := l.OrderBy(); got != "ORDER BY t.amount, t.created_at DESC, t.id" {
+		t.Errorf("order = %q", got)
+	}
+	where, args := l.Where(3)
+	want := "t.created_at < $3::text::timestamptz AND lower(t.language) = $4::text AND t.stars >= $5::text::bigint AND t.status = ANY($6::text[])"
+	if where != want {
+		t.Errorf("where = %q", where)
+	}
+	if !reflect.DeepEqual(args, []any{"2026-10-01T00:00:00Z", "go", "10", []string{"open", "closed"}}) {
+		t.Errorf("args = %v", args)
+	}
+
+	for name, tc := range map[string]struct {
+		q    map[string]string
+		code string
+	}{
+		"unknown sort":   {map[string]string{"sort": "status"}, CodeInvalidSort},
+		"duplicate sort": {map[string]string{"sort": "amount,-amount"}, CodeInvalidSort},
+		"enum":           {map[string]string{"status": "pending"}, CodeInvalidFilter},
+		"type":           {map[string]string{"stars": "many"}, CodeInvalidFilter},
+		"range on text":  {map[string]string{"status[gt]": "open"}, CodeInvalidFilter},
+		"operator":       {map[string]string{"stars[like]": "1"}, CodeInvalidFilter},
+		"cursor":         {map[string]string{"cursor": "nope"}, CodeInvalidCursor},
+	} {
+		_, err := parseList(tc.q, testListSpec)
+		var e *Error
+		if !errors.As(err, &e) || e.Code != tc.code {
+			t.Errorf("%s: err = %v, want %s", name, err, tc.code)
+		}
+	}
+}
+
+func TestListSpecParams(t *testing.T) {
+	want := []string{"amount", "created_at", "cursor", "language", "limit", "offset", "sort", "stars", "status"}
+	if got := testListSpec.Params(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Params = %v", got)
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	l, err := parseList(map[string]string{"sort": "-amount", "limit": "2"}, testListSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.NextCursor(1, `[1.5, "7f3c2a4e-8d1b-4c9a-9e2f-0b6d5a1c3e7f"]`) != "" {
+		t.Error("short page has a next cursor")
+	}
+	next := l.NextCursor(2, `[1.5, "7f3c2a4e-8d1b-4c9a-9e2f-0b6d5a1c3e7f"]`)
+	if next == "" {
+		t.Fatal("full page has no next cursor")
+	}
+
+	l, err = parseList(map[string]string{"sort": "-amount", "cursor": next}, testListSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	where, args := l.Where(1)
+	if want := "((t.amount < $1::text::numeric) OR (t.amount = $1::text::numeric AND t.id > $2::text::uuid))"; where != want {
+		t.Errorf("where = %q", where)
+	}
+	if !reflect.DeepEqual(args, []any{"1.5", "7f3c2a4e-8d1b-4c9a-9e2f-0b6d5a1c3e7f"}) {
+		t.Errorf("args = %v", args)
+	}
+
+	if _, err := parseList(map[string]string{"cursor": next}, testListSpec); err == nil {
+		t.Error("cursor accepted with a different sort")
+	}
+}
@@ -0,0 +1,113 @@
+package httpx
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Locals keys set by RequestLog and SetUser. "requestid" is the key fiber's
+// requestid middleware uses.
+const (
+	localRequestID = "requestid"
+	localLogger    = "httpx_logger"
+	localUserID    = "httpx_user_id"
+	localWallet    = "httpx_wallet_address"
+)
+
+// maxRequestIDLen bounds an X-Request-ID accepted from the caller.
+const maxRequestIDLen = 128
+
+type loggerKey struct{}
+
+// RequestLog gives every request an ID and a logger, and logs the request
+// once it completes. The ID is the caller's X-Request-ID when it is sane,
+// otherwise a new UUID, and is echoed in the response. Handlers log through
+// Logger(c) so every line carries it.
+func RequestLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		id := c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(fiber.HeaderXRequestID, id)
+		c.Locals(localRequestID, id)
+
+		l := slog.Default().With("request_id", id)
+		c.Locals(localLogger, l)
+		c.SetUserContext(context.WithValue(c.UserContext(), loggerKey{}, l))
+
+		// Errors are rendered here rather than by the app so the logged
+		// status is the one sent.
+		if err := c.Next(); err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		attrs := []any{
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"remote_ip", c.IP(),
+		}
+		if uid, ok := c.Locals(localUserID).(string); ok && uid != "" {
+			attrs = append(attrs, "user_id", uid)
+		}
+		if w, ok := c.Locals(localWallet).(string); ok && w != "" {
+			attrs = append(attrs, "wallet_address", w)
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		l.Log(c.UserContext(), level, "request", attrs...)
+		return nil
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		b := id[i]
+		if !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '-' || b == '_' || b == '.' || b == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// SetUser records the authenticated user, and the wallet they signed in
+// with if any, for the request's access log line. Auth middleware calls it.
+func SetUser(c *fiber.Ctx, userID, walletAddress string) {
+	c.Locals(localUserID, userID)
+	c.Locals(localWallet, walletAddress)
+}
+
+// Logger returns the request's logger: slog's default with the request ID.
+// The user and wallet are on the request's access log line, so handlers
+// keep logging whose records they touch under their own keys.
+func Logger(c *fiber.Ctx) *slog.Logger {
+	if l, ok := c.Locals(localLogger).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// LoggerFrom returns the logger RequestLog put in a request's user context,
+// for code below the handlers that only has a context.Context.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,49 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequestLogID(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(RequestLog())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return New(fiber.StatusNotFound, "widget_not_found")
+	})
+
+	for _, tc := range []struct {
+		in   string
+		keep bool
+	}{
+		{"trace-abc.123:4", true},
+		{"", false},
+		{"bad id\n", false},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.in != "" {
+			req.Header.Set(fiber.HeaderXRequestID, tc.in)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := resp.Header.Get(fiber.HeaderXRequestID)
+		if tc.keep && id != tc.in || !tc.keep && (id == "" || id == tc.in) {
+			t.Errorf("X-Request-ID %q -> %q", tc.in, id)
+		}
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("status = %d", resp.StatusCode)
+		}
+		var body Body
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.RequestID != id {
+			t.Errorf("body request_id = %q, header %q", body.RequestID, id)
+		}
+	}
+}
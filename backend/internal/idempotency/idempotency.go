@@ -0,0 +1,226 @@
+// Package idempotency lets clients retry mutating requests safely. A request
+// sent with an Idempotency-Key header runs once; retries of the same key
+// with the same request get the first response replayed, so a payout or
+// bounty submitted twice over a flaky connection is only made once.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+const (
+	// Header carries the client's key.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on replayed responses.
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLen caps the key's length.
+	MaxKeyLen = 255
+)
+
+// Codes sent when a key can't be used.
+const (
+	CodeInvalidKey = "invalid_idempotency_key"
+	CodeKeyReused  = "idempotency_key_reused"
+	CodeKeyInUse   = "idempotency_key_in_use"
+)
+
+// stale is how long a request may hold its key without responding before a
+// retry takes the key over, e.g. after the instance running it died. It is
+// longer than the server's write timeout.
+const stale = time.Minute
+
+// Keys stores responses by key. A nil Keys lets every request through.
+type Keys struct {
+	pool *pgxpool.Pool
+	ttl  time.Duration
+	// ring, when set, seals stored response bodies.
+	ring *cryptox.Keyring
+}
+
+// New keeps responses for ttl; it returns nil without a pool.
+func New(pool *pgxpool.Pool, ttl time.Duration) *Keys {
+	if pool == nil {
+		return nil
+	}
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &Keys{pool: pool, ttl: ttl}
+}
+
+// Sealed returns Keys that store response bodies sealed with ring, for
+// routes whose responses carry secrets such as issued tokens. It returns nil,
+// letting every request through, without a ring.
+func (k *Keys) Sealed(ring *cryptox.Keyring) *Keys {
+	if k == nil || ring == nil {
+		return nil
+	}
+	sealed := *k
+	sealed.ring = ring
+	return &sealed
+}
+
+// Handler runs requests carrying a new key and stores their response, and
+// replays it for retries. A key reused with a different request is rejected
+// with 422, and one whose first request is still running with 409. Server
+// errors are not stored, so the retry runs again. Put it after the route's
+// auth middleware: keys belong to the signed-in user, or to the IP address
+// of anonymous callers.
+func (k *Keys) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(Header)
+		if k == nil || key == "" {
+			return c.Next()
+		}
+		if !validKey(key) {
+			return httpx.Write(c, httpx.New(fiber.StatusBadRequest, CodeInvalidKey).
+				WithMessage(fmt.Sprintf("%s must be 1 to %d printable ASCII characters", Header, MaxKeyLen)))
+		}
+		scope := "ip:" + c.IP()
+		if sub, _ := c.Locals(auth.LocalUserID).(string); sub != "" {
+			scope = sub
+		}
+		hash := requestHash(c.Method(), c.Path(), c.Body())
+
+		ctx := c.Context()
+		claimed, err := k.claim(ctx, scope, key, hash)
+		if err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "idempotency_failed").Wrap(err))
+		}
+		if !claimed {
+			return k.replay(c, scope, key, hash)
+		}
+
+		if err := c.Next(); err != nil {
+			k.release(ctx, scope, key)
+			return err
+		}
+		res := c.Response()
+		if res.StatusCode() >= fiber.StatusInternalServerError {
+			k.release(ctx, scope, key)
+			return nil
+		}
+		body := res.Body()
+		if k.ring != nil {
+			if body, err = k.ring.Seal(body); err != nil {
+				// Unsealed secrets are never stored; the retry runs again.
+				httpx.Logger(c).Error("failed to seal idempotent response", "error", err)
+				k.release(ctx, scope, key)
+				return nil
+			}
+		}
+		if _, err := k.pool.Exec(ctx, `
+UPDATE idempotency_keys SET status = $3, content_type = $4, body = $5
+WHERE scope = $1 AND key = $2
+`, scope, key, res.StatusCode(), string(res.Header.ContentType()), body); err != nil {
+			// The request went through; a retry will find the key held
+			// until it goes stale.
+			httpx.Logger(c).Error("failed to store idempotent response", "error", err)
+		}
+		return nil
+	}
+}
+
+// claim takes the key for a request, reporting false when an unexpired
+// request already holds it.
+func (k *Keys) claim(ctx context.Context, scope, key, hash string) (bool, error) {
+	var ok bool
+	err := k.pool.QueryRow(ctx, `
+INSERT INTO idempotency_keys (scope, key, request_hash, expires_at)
+VALUES ($1, $2, $3, now() + $4 * interval '1 second')
+ON CONFLICT (scope, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, status = NULL, content_type = NULL, body = NULL,
+    created_at = now(), expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+   OR (idempotency_keys.status IS NULL AND idempotency_keys.created_at < now() - $5 * interval '1 second')
+RETURNING true
+`, scope, key, hash, int64(k.ttl.Seconds()), int64(stale.Seconds())).Scan(&ok)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// replay answers a retry of a key another request holds.
+func (k *Keys) replay(c *fiber.Ctx, scope, key, hash string) error {
+	var storedHash string
+	var status *int
+	var contentType *string
+	var body []byte
+	err := k.pool.QueryRow(c.Context(), `
+SELECT request_hash, status, content_type, body FROM idempotency_keys WHERE scope = $1 AND key = $2
+`, scope, key).Scan(&storedHash, &status, &contentType, &body)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "idempotency_failed").Wrap(err))
+	}
+	switch {
+	case err == nil && storedHash != hash:
+		return httpx.Write(c, httpx.New(fiber.StatusUnprocessableEntity, CodeKeyReused).
+			WithMessage(Header+" was already used for a different request"))
+	case err != nil || status == nil:
+		// Released between claim and here, or still running.
+		c.Set(fiber.HeaderRetryAfter, "1")
+		return httpx.Write(c, httpx.New(fiber.StatusConflict, CodeKeyInUse).
+			WithMessage("a request with this "+Header+" is in progress"))
+	}
+	if k.ring != nil {
+		if body, err = k.ring.Open(body); err != nil {
+			return httpx.Write(c, httpx.New(fiber.StatusInternalServerError, "idempotency_failed").Wrap(err))
+		}
+	}
+	if contentType != nil && *contentType != "" {
+		c.Set(fiber.HeaderContentType, *contentType)
+	}
+	c.Set(ReplayedHeader, "true")
+	return c.Status(*status).Send(body)
+}
+
+// release frees the key of a request that failed, so its retry runs again.
+func (k *Keys) release(ctx context.Context, scope, key string) {
+	_, _ = k.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status IS NULL`, scope, key)
+}
+
+// Prune deletes expired keys, returning how many.
+func Prune(ctx context.Context, pool *pgxpool.Pool) (int64, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func validKey(key string) bool {
+	if len(key) == 0 || len(key) > MaxKeyLen {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestHash identifies a request by method, path and body.
+func requestHash(method, path string, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", method, path)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,74 @@
+package idempotency
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestValidKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"":                                     false,
+		"7f3c2a4e-8d1b-4c9a-9e2f-0b6d5a1c3e7f": true,
+		"retry 1":                              true,
+		"tab\tkey":                             false,
+		"clé":                                  false,
+		strings.Repeat("k", MaxKeyLen):         true,
+		strings.Repeat("k", MaxKeyLen+1):       false,
+	} {
+		if got := validKey(key); got != want {
+			t.Errorf("validKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	base := requestHash("POST", "/admin/payouts", []byte(`{"amount":"10"}`))
+	if requestHash("POST", "/admin/payouts", []byte(`{"amount":"10"}`)) != base {
+		t.Error("same request hashed differently")
+	}
+	if requestHash("POST", "/admin/payouts", []byte(`{"amount":"11"}`)) == base {
+		t.Error("different body hashed the same")
+	}
+	if requestHash("POST", "/admin/payouts/x/retry", []byte(`{"amount":"10"}`)) == base {
+		t.Error("different path hashed the same")
+	}
+}
+
+func TestHandlerWithoutStore(t *testing.T) {
+	app := fiber.New()
+	var keys *Keys
+	app.Post("/", keys.Handler(), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set(Header, "k1")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusCreated {
+		t.Errorf("status = %d, want the handler's 201", res.StatusCode)
+	}
+
+	app = fiber.New()
+	app.Post("/", (&Keys{}).Handler(), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+	req = httptest.NewRequest("POST", "/", nil)
+	req.Header.Set(Header, strings.Repeat("k", MaxKeyLen+1))
+	if res, err = app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("long key status = %d, want 400", res.StatusCode)
+	}
+}
+
+func TestSealedNeedsKeysAndRing(t *testing.T) {
+	var keys *Keys
+	if keys.Sealed(nil) != nil {
+		t.Error("sealed keys without a store")
+	}
+	if (&Keys{}).Sealed(nil) != nil {
+		t.Error("sealed keys without a key ring would store secrets in the clear")
+	}
+}
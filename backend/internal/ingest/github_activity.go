@@ -0,0 +1,72 @@
+package ingest
+
+import (
+	"context"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// Reviews, comments and CI results are mirrored for repo health metrics.
+
+func (i *GitHubWebhookIngestor) handlePullRequestReview(ctx context.Context, e github.WebhookEvent) error {
+	if e.ProjectID == "" {
+		return nil
+	}
+	p, err := github.ParsePullRequestReview(e)
+	if err != nil {
+		return err
+	}
+	if p.Review.SubmittedAt == nil {
+		// Pending review; nothing the author can see yet.
+		return nil
+	}
+	_, err = i.Pool.Exec(ctx, `
+INSERT INTO github_pr_reviews (project_id, github_review_id, pr_number, reviewer_login, author_association, state, submitted_at)
+VALUES ($1::uuid, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (project_id, github_review_id) DO UPDATE SET
+  state = EXCLUDED.state,
+  author_association = EXCLUDED.author_association
+`, e.ProjectID, p.Review.ID, p.PullRequest.Number, nullIfEmpty(p.Review.User.Login), nullIfEmpty(p.Review.AuthorAssociation), p.Review.State, *p.Review.SubmittedAt)
+	return err
+}
+
+func (i *GitHubWebhookIngestor) handleIssueComment(ctx context.Context, e github.WebhookEvent) error {
+	if e.ProjectID == "" {
+		return nil
+	}
+	p, err := github.ParseIssueComment(e)
+	if err != nil {
+		return err
+	}
+	if p.Action == "deleted" {
+		_, err = i.Pool.Exec(ctx, `DELETE FROM github_issue_comment_events WHERE project_id = $1::uuid AND github_comment_id = $2`, e.ProjectID, p.Comment.ID)
+		return err
+	}
+	_, err = i.Pool.Exec(ctx, `
+INSERT INTO github_issue_comment_events (project_id, github_comment_id, issue_number, author_login, author_association, created_at_github)
+VALUES ($1::uuid, $2, $3, $4, $5, $6)
+ON CONFLICT (project_id, github_comment_id) DO NOTHING
+`, e.ProjectID, p.Comment.ID, p.Issue.Number, nullIfEmpty(p.Comment.User.Login), nullIfEmpty(p.Comment.AuthorAssociation), p.Comment.CreatedAt)
+	return err
+}
+
+func (i *GitHubWebhookIngestor) handleCheckSuite(ctx context.Context, e github.WebhookEvent) error {
+	if e.ProjectID == "" {
+		return nil
+	}
+	p, err := github.ParseCheckSuite(e)
+	if err != nil {
+		return err
+	}
+	if p.Action != "completed" || p.CheckSuite.Conclusion == "" {
+		return nil
+	}
+	_, err = i.Pool.Exec(ctx, `
+INSERT INTO github_check_suites (project_id, github_suite_id, head_sha, head_branch, app_slug, conclusion, completed_at)
+VALUES ($1::uuid, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (project_id, github_suite_id) DO UPDATE SET
+  conclusion = EXCLUDED.conclusion,
+  completed_at = EXCLUDED.completed_at
+`, e.ProjectID, p.CheckSuite.ID, nullIfEmpty(p.CheckSuite.HeadSHA), nullIfEmpty(p.CheckSuite.HeadBranch), nullIfEmpty(p.CheckSuite.App.Slug), p.CheckSuite.Conclusion, p.CheckSuite.UpdatedAt)
+	return err
+}
@@ -0,0 +1,141 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/fraud"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// handleMergedPullRequest approves the bounties on the issues a merged pull
+// request closes, for its author to be paid; the approval notifies the
+// maintainer. Bounties scoped to a directory of a monorepo are only
+// approved when the pull request changes files under it. Approved bounties
+// are no longer matched, so redeliveries are no-ops.
+func (i *GitHubWebhookIngestor) handleMergedPullRequest(ctx context.Context, e github.WebhookEvent) error {
+	if e.ProjectID == "" || e.Action != "closed" {
+		return nil
+	}
+	p, err := github.ParsePullRequest(e)
+	if err != nil {
+		return err
+	}
+	if !p.PullRequest.Merged || p.PullRequest.User.ID == 0 {
+		return nil
+	}
+	keys := bounties.ClosingReferences(p.PullRequest.Title+"\n"+p.PullRequest.Body, e.RepoFullName)
+	if len(keys) == 0 {
+		return nil
+	}
+	projectID, err := uuid.Parse(e.ProjectID)
+	if err != nil {
+		return nil
+	}
+	list, err := bounties.ListByIssueKeys(ctx, i.Pool, projectID, keys)
+	if err != nil || len(list) == 0 {
+		return err
+	}
+
+	// Only authors who signed in with GitHub can be paid.
+	var userID uuid.UUID
+	err = i.Pool.QueryRow(ctx, `SELECT user_id FROM github_accounts WHERE github_user_id = $1`, p.PullRequest.User.ID).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		slog.Info("merged pull request author has no account; bounties left open",
+			"project_id", e.ProjectID,
+			"pr_number", p.PullRequest.Number,
+			"author_login", p.PullRequest.User.Login,
+		)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pr, err := bounties.ParsePullRequest(p.PullRequest.HTMLURL)
+	if err != nil {
+		return err
+	}
+	var (
+		errs     []error
+		files    []string
+		filesErr error
+	)
+	for _, b := range list {
+		if b.PathPrefix != "" {
+			if files == nil && filesErr == nil {
+				if files, filesErr = i.changedFiles(ctx, projectID, e.RepoFullName, pr.Number); filesErr != nil {
+					errs = append(errs, filesErr)
+				}
+			}
+			if filesErr != nil {
+				// Scoped bounties wait for a redelivery or the claimant.
+				continue
+			}
+			if !b.ChangesPath(files) {
+				slog.Info("merged pull request did not settle bounty",
+					"bounty_id", b.ID.String(),
+					"pr_number", pr.Number,
+					"user_id", userID.String(),
+					"reason", "no changes under "+b.PathPrefix,
+				)
+				continue
+			}
+		}
+		if _, err := bounties.ApproveMerged(ctx, i.Pool, b, userID, pr); err != nil {
+			if errors.Is(err, bounties.ErrInvalidStatus) || errors.Is(err, bounties.ErrOwnBounty) || errors.Is(err, bounties.ErrNotClaimant) ||
+				errors.Is(err, bounties.ErrClaimWithheld) {
+				slog.Info("merged pull request did not settle bounty",
+					"bounty_id", b.ID.String(),
+					"pr_number", pr.Number,
+					"user_id", userID.String(),
+					"reason", err.Error(),
+				)
+				continue
+			}
+			errs = append(errs, err)
+			continue
+		}
+		slog.Info("bounty approved on merge",
+			"bounty_id", b.ID.String(),
+			"pr_number", pr.Number,
+			"user_id", userID.String(),
+		)
+		// A merge claims the bounty for its author, so claim rules see it too.
+		if _, err := fraud.NewEngine(i.Pool).Evaluate(ctx, fraud.Subject{
+			Event:     fraud.EventClaim,
+			UserID:    userID,
+			SubjectID: b.ID.String(),
+		}); err != nil {
+			slog.Warn("fraud evaluation failed for merge approval", "bounty_id", b.ID.String(), "user_id", userID.String(), "error", err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// changedFiles lists the repo paths a pull request touches, read with the
+// project's repo token.
+func (i *GitHubWebhookIngestor) changedFiles(ctx context.Context, projectID uuid.UUID, fullName string, number int) ([]string, error) {
+	var ownerID uuid.UUID
+	if err := i.Pool.QueryRow(ctx, `SELECT owner_user_id FROM projects WHERE id = $1`, projectID).Scan(&ownerID); err != nil {
+		return nil, err
+	}
+	tokens := i.Tokens
+	tokens.Pool = i.Pool
+	// Public repos can still be read anonymously.
+	token, _ := tokens.Repo(fullName, ownerID).Token(ctx)
+	gh := i.GitHub
+	if gh == nil {
+		gh = github.NewClient()
+	}
+	fs, err := gh.ListPRFiles(ctx, token, fullName, number)
+	if err != nil {
+		return nil, err
+	}
+	return github.Paths(fs), nil
+}
@@ -0,0 +1,87 @@
+package ingest
+
+import (
+	"context"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// registerWebhookHandlers wires the typed event handlers into d.
+func (i *GitHubWebhookIngestor) registerWebhookHandlers(d *github.WebhookDispatcher) {
+	d.On("push", i.handlePush)
+	d.On("star", i.handleStar)
+	d.On("pull_request_review", i.handlePullRequestReview)
+	d.On("issue_comment", i.handleIssueComment)
+	d.On("check_suite", i.handleCheckSuite)
+	d.On("pull_request", i.handleMergedPullRequest)
+}
+
+func (i *GitHubWebhookIngestor) handlePush(ctx context.Context, e github.WebhookEvent) error {
+	if e.ProjectID == "" || e.DeliveryID == "" {
+		return nil
+	}
+	p, err := github.ParsePush(e)
+	if err != nil {
+		return err
+	}
+	tx, err := i.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+INSERT INTO github_pushes (delivery_id, project_id, ref, before_sha, after_sha, forced, deleted, pusher_login, sender_github_id, commit_count)
+VALUES ($1, $2::uuid, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (delivery_id) DO NOTHING
+`, e.DeliveryID, e.ProjectID, p.Ref, nullIfEmpty(p.Before), nullIfEmpty(p.After), p.Forced, p.Deleted, nullIfEmpty(p.Pusher.Name), p.Sender.ID, len(p.Commits))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		// Redelivery.
+		return nil
+	}
+	for _, c := range p.Commits {
+		if !c.Distinct || c.ID == "" {
+			continue
+		}
+		var committedAt *time.Time
+		if !c.Timestamp.IsZero() {
+			committedAt = &c.Timestamp
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO github_commits (project_id, sha, ref, message, url, author_name, author_email, author_login, committed_at, delivery_id)
+VALUES ($1::uuid, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (project_id, sha) DO NOTHING
+`, e.ProjectID, c.ID, p.Ref, c.Message, nullIfEmpty(c.URL), nullIfEmpty(c.Author.Name), nullIfEmpty(c.Author.Email), nullIfEmpty(c.Author.Username), committedAt, e.DeliveryID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (i *GitHubWebhookIngestor) handleStar(ctx context.Context, e github.WebhookEvent) error {
+	if e.ProjectID == "" {
+		return nil
+	}
+	p, err := github.ParseStar(e)
+	if err != nil {
+		return err
+	}
+	if p.Action == "deleted" {
+		_, err = i.Pool.Exec(ctx, `DELETE FROM github_stars WHERE project_id = $1::uuid AND github_user_id = $2`, e.ProjectID, p.Sender.ID)
+		return err
+	}
+	starredAt := time.Now().UTC()
+	if p.StarredAt != nil {
+		starredAt = *p.StarredAt
+	}
+	_, err = i.Pool.Exec(ctx, `
+INSERT INTO github_stars (project_id, github_user_id, login, starred_at)
+VALUES ($1::uuid, $2, $3, $4)
+ON CONFLICT (project_id, github_user_id) DO UPDATE SET login = EXCLUDED.login
+`, e.ProjectID, p.Sender.ID, p.Sender.Login, starredAt)
+	return err
+}
@@ -4,16 +4,38 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/issues"
 )
 
 type GitHubWebhookIngestor struct {
 	Pool *pgxpool.Pool
+	// GitHub and Tokens read pull requests' changed files for bounties
+	// scoped to a directory; GitHub defaults to a new client, and without
+	// tokens only public repos can be read.
+	GitHub *github.Client
+	Tokens github.Tokens
+
+	dispatchOnce sync.Once
+	dispatch     *github.WebhookDispatcher
+}
+
+// Dispatcher returns the typed event dispatcher; callers may register more
+// handlers for downstream features.
+func (i *GitHubWebhookIngestor) Dispatcher() *github.WebhookDispatcher {
+	i.dispatchOnce.Do(func() {
+		i.dispatch = github.NewWebhookDispatcher()
+		i.registerWebhookHandlers(i.dispatch)
+	})
+	return i.dispatch
 }
 
 func (i *GitHubWebhookIngestor) Ingest(ctx context.Context, e events.GitHubWebhookReceived) error {
@@ -70,6 +92,16 @@ ON CONFLICT (project_id, github_issue_id) DO UPDATE SET
   closed_at_github = EXCLUDED.closed_at_github,
   last_seen_at = now()
 `, *projectID, issue.ID, issue.Number, issue.State, issue.Title, issue.Body, issue.User.Login, issue.HTMLURL, issue.CreatedAt, issue.UpdatedAt, issue.ClosedAt)
+
+			// Keep bounties on this issue in step with its state.
+			_, _ = issues.ApplyUpdate(ctx, i.Pool, issues.Issue{
+				Provider:   issues.ProviderGitHub,
+				ExternalID: strconv.FormatInt(issue.ID, 10),
+				Title:      issue.Title,
+				URL:        issue.HTMLURL,
+				State:      issue.State,
+				Closed:     issue.State == "closed",
+			}, nil)
 		}
 
 		if (e.Event == "pull_request" || e.Event == "pull_request_review") && env.PullRequest != nil {
@@ -94,6 +126,20 @@ ON CONFLICT (project_id, github_pr_id) DO UPDATE SET
 		}
 	}
 
+	// Typed handlers (push, star, ...); failures are logged by the dispatcher.
+	var pid string
+	if projectID != nil {
+		pid = *projectID
+	}
+	_ = i.Dispatcher().Dispatch(ctx, github.WebhookEvent{
+		DeliveryID:   e.DeliveryID,
+		Event:        e.Event,
+		Action:       action,
+		RepoFullName: repoFullName,
+		ProjectID:    pid,
+		Payload:      e.Payload,
+	})
+
 	// Enqueue follow-up sync jobs (best-effort).
 	if projectID != nil && (e.Event == "issues" || e.Event == "pull_request" || e.Event == "push") {
 		_, _ = i.Pool.Exec(ctx, `
@@ -0,0 +1,247 @@
+package issues
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+// Account is a user's link to an external tracker. AccessToken is only
+// populated by AccountFor and never serialized.
+type Account struct {
+	ID                uuid.UUID  `json:"id"`
+	UserID            uuid.UUID  `json:"user_id"`
+	Provider          string     `json:"provider"`
+	SiteID            string     `json:"site_id"`
+	SiteURL           string     `json:"site_url"`
+	TokenExpiresAt    *time.Time `json:"token_expires_at,omitempty"`
+	WebhookConfigured bool       `json:"webhook_configured"`
+	CreatedAt         time.Time  `json:"created_at"`
+	AccessToken       string     `json:"-"`
+}
+
+const accountColumns = `id, user_id, provider, site_id, site_url, token_expires_at, webhook_secret IS NOT NULL, created_at`
+
+func scanAccount(row pgx.Row) (Account, error) {
+	var a Account
+	err := row.Scan(&a.ID, &a.UserID, &a.Provider, &a.SiteID, &a.SiteURL, &a.TokenExpiresAt, &a.WebhookConfigured, &a.CreatedAt)
+	return a, err
+}
+
+func encrypt(key []byte, s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return cryptox.EncryptAESGCM(key, []byte(s))
+}
+
+func decrypt(key []byte, b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", nil
+	}
+	out, err := cryptox.DecryptAESGCM(key, b)
+	if err != nil {
+		return "", fmt.Errorf("decrypt issue provider secret failed")
+	}
+	return string(out), nil
+}
+
+// SaveAccount stores the OAuth link, replacing any previous link to the same
+// provider. The webhook secret survives relinking.
+func SaveAccount(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, provider string, t Token, tokenEncKeyB64 string) (Account, error) {
+	if pool == nil {
+		return Account{}, fmt.Errorf("db not configured")
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return Account{}, err
+	}
+	access, err := encrypt(key, t.AccessToken)
+	if err != nil {
+		return Account{}, err
+	}
+	refresh, err := encrypt(key, t.RefreshToken)
+	if err != nil {
+		return Account{}, err
+	}
+	return scanAccount(pool.QueryRow(ctx, `
+INSERT INTO issue_provider_accounts (user_id, provider, site_id, site_url, access_token, refresh_token, token_expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id, provider) DO UPDATE SET
+  site_id = EXCLUDED.site_id,
+  site_url = EXCLUDED.site_url,
+  access_token = EXCLUDED.access_token,
+  refresh_token = EXCLUDED.refresh_token,
+  token_expires_at = EXCLUDED.token_expires_at,
+  updated_at = now()
+RETURNING `+accountColumns, userID, provider, t.SiteID, t.SiteURL, access, refresh, t.ExpiresAt))
+}
+
+// AccountFor loads userID's link to p with a usable access token, refreshing
+// it first when it is about to expire.
+func AccountFor(ctx context.Context, pool *pgxpool.Pool, p Provider, userID uuid.UUID, tokenEncKeyB64 string) (Account, error) {
+	if pool == nil {
+		return Account{}, fmt.Errorf("db not configured")
+	}
+	var (
+		a                 Account
+		access, refreshEn []byte
+	)
+	err := pool.QueryRow(ctx, `
+SELECT `+accountColumns+`, access_token, refresh_token
+FROM issue_provider_accounts
+WHERE user_id = $1 AND provider = $2
+`, userID, p.Name()).Scan(&a.ID, &a.UserID, &a.Provider, &a.SiteID, &a.SiteURL, &a.TokenExpiresAt, &a.WebhookConfigured, &a.CreatedAt, &access, &refreshEn)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Account{}, ErrNotLinked
+	}
+	if err != nil {
+		return Account{}, err
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return Account{}, err
+	}
+	if a.AccessToken, err = decrypt(key, access); err != nil {
+		return Account{}, err
+	}
+	if a.TokenExpiresAt == nil || time.Until(*a.TokenExpiresAt) > time.Minute {
+		return a, nil
+	}
+
+	refresh, err := decrypt(key, refreshEn)
+	if err != nil {
+		return Account{}, err
+	}
+	if refresh == "" {
+		return Account{}, fmt.Errorf("%s token expired; relink the account", p.Name())
+	}
+	t, err := p.Refresh(ctx, refresh)
+	if err != nil {
+		return Account{}, fmt.Errorf("refresh %s token: %w", p.Name(), err)
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = refresh
+	}
+	newAccess, err := encrypt(key, t.AccessToken)
+	if err != nil {
+		return Account{}, err
+	}
+	newRefresh, err := encrypt(key, t.RefreshToken)
+	if err != nil {
+		return Account{}, err
+	}
+	if _, err := pool.Exec(ctx, `
+UPDATE issue_provider_accounts
+SET access_token = $2, refresh_token = $3, token_expires_at = $4, updated_at = now()
+WHERE id = $1
+`, a.ID, newAccess, newRefresh, t.ExpiresAt); err != nil {
+		return Account{}, err
+	}
+	a.AccessToken = t.AccessToken
+	a.TokenExpiresAt = t.ExpiresAt
+	return a, nil
+}
+
+func ListAccounts(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Account, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+accountColumns+` FROM issue_provider_accounts WHERE user_id = $1 ORDER BY provider`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Account{}
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func DeleteAccount(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, provider string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM issue_provider_accounts WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotLinked
+	}
+	return nil
+}
+
+// RotateWebhookSecret issues a new secret for the tracker's webhook settings.
+func RotateWebhookSecret(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, provider, tokenEncKeyB64 string) (Account, string, error) {
+	if pool == nil {
+		return Account{}, "", fmt.Errorf("db not configured")
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return Account{}, "", err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return Account{}, "", err
+	}
+	secret := hex.EncodeToString(raw)
+	enc, err := encrypt(key, secret)
+	if err != nil {
+		return Account{}, "", err
+	}
+	a, err := scanAccount(pool.QueryRow(ctx, `
+UPDATE issue_provider_accounts SET webhook_secret = $3, updated_at = now()
+WHERE user_id = $1 AND provider = $2
+RETURNING `+accountColumns, userID, provider, enc))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Account{}, "", ErrNotLinked
+	}
+	if err != nil {
+		return Account{}, "", err
+	}
+	return a, secret, nil
+}
+
+// WebhookAccount loads the account a webhook URL points at, with its secret.
+func WebhookAccount(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID, provider, tokenEncKeyB64 string) (Account, string, error) {
+	if pool == nil {
+		return Account{}, "", fmt.Errorf("db not configured")
+	}
+	var enc []byte
+	var a Account
+	err := pool.QueryRow(ctx, `SELECT `+accountColumns+`, webhook_secret FROM issue_provider_accounts WHERE id = $1 AND provider = $2`, id, provider).
+		Scan(&a.ID, &a.UserID, &a.Provider, &a.SiteID, &a.SiteURL, &a.TokenExpiresAt, &a.WebhookConfigured, &a.CreatedAt, &enc)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Account{}, "", ErrNotLinked
+	}
+	if err != nil {
+		return Account{}, "", err
+	}
+	if len(enc) == 0 {
+		return Account{}, "", ErrWebhookNotConfigured
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return Account{}, "", err
+	}
+	secret, err := decrypt(key, enc)
+	if err != nil {
+		return Account{}, "", err
+	}
+	return a, secret, nil
+}
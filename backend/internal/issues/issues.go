@@ -0,0 +1,191 @@
+// Package issues abstracts the issue a bounty is attached to so it can come
+// from GitHub (synced per project) or from an external tracker linked over
+// OAuth (Jira Cloud, Linear), with state kept current by webhooks.
+package issues
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+)
+
+const (
+	ProviderGitHub = "github"
+	ProviderJira   = "jira"
+	ProviderLinear = "linear"
+)
+
+var (
+	ErrUnknownProvider      = errors.New("unknown_issue_provider")
+	ErrProviderDisabled     = errors.New("issue_provider_not_configured")
+	ErrNotLinked            = errors.New("issue_provider_not_linked")
+	ErrIssueNotFound        = errors.New("issue_not_found")
+	ErrBadSignature         = errors.New("invalid_signature")
+	ErrWebhookNotConfigured = errors.New("webhook_secret_not_configured")
+)
+
+// Issue is a provider-neutral view of an issue.
+type Issue struct {
+	Provider   string `json:"provider"`
+	ExternalID string `json:"external_id"`
+	Key        string `json:"key"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	State      string `json:"state"`
+	Closed     bool   `json:"closed"`
+}
+
+// Token is the result of an OAuth code exchange or refresh. SiteID/SiteURL
+// identify the tracker workspace the token is scoped to.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    *time.Time
+	SiteID       string
+	SiteURL      string
+}
+
+// Provider is an external issue tracker linked over OAuth.
+type Provider interface {
+	Name() string
+	AuthorizeURL(state string) string
+	Exchange(ctx context.Context, code string) (Token, error)
+	Refresh(ctx context.Context, refreshToken string) (Token, error)
+	// FetchIssue resolves a human reference (ABC-123, ENG-42) in the linked site.
+	FetchIssue(ctx context.Context, acct Account, ref string) (Issue, error)
+	// ParseWebhook verifies a delivery with the account's secret and returns
+	// the issues it updates (none for unrelated events).
+	ParseWebhook(acct Account, header http.Header, body []byte, secret string) ([]Issue, error)
+}
+
+// Providers returns the external trackers with OAuth configured.
+func Providers(cfg config.Config) map[string]Provider {
+	out := map[string]Provider{}
+	if cfg.JiraOAuthClientID != "" && cfg.JiraOAuthClientSecret != "" && cfg.JiraOAuthRedirectURL != "" {
+		out[ProviderJira] = &Jira{ClientID: cfg.JiraOAuthClientID, ClientSecret: cfg.JiraOAuthClientSecret, RedirectURL: cfg.JiraOAuthRedirectURL, HTTP: httpClient()}
+	}
+	if cfg.LinearOAuthClientID != "" && cfg.LinearOAuthClientSecret != "" && cfg.LinearOAuthRedirectURL != "" {
+		out[ProviderLinear] = &Linear{ClientID: cfg.LinearOAuthClientID, ClientSecret: cfg.LinearOAuthClientSecret, RedirectURL: cfg.LinearOAuthRedirectURL, HTTP: httpClient()}
+	}
+	return out
+}
+
+// Lookup returns the provider for name, distinguishing unknown names from
+// known but unconfigured ones.
+func Lookup(providers map[string]Provider, name string) (Provider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if p, ok := providers[name]; ok {
+		return p, nil
+	}
+	if name == ProviderJira || name == ProviderLinear {
+		return nil, ErrProviderDisabled
+	}
+	return nil, ErrUnknownProvider
+}
+
+func httpClient() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// Resolve looks up ref for a bounty on projectID. GitHub refs are issue
+// numbers in the project's repo, read from the synced snapshot; external refs
+// are fetched with the project owner's linked account, whose id is returned.
+func Resolve(ctx context.Context, pool *pgxpool.Pool, providers map[string]Provider, tokenEncKeyB64 string, projectID uuid.UUID, provider, ref string) (Issue, *uuid.UUID, error) {
+	if pool == nil {
+		return Issue{}, nil, fmt.Errorf("db not configured")
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" {
+		provider = ProviderGitHub
+	}
+	ref = strings.TrimSpace(ref)
+	if provider == ProviderGitHub {
+		iss, err := githubIssue(ctx, pool, projectID, ref)
+		return iss, nil, err
+	}
+
+	p, err := Lookup(providers, provider)
+	if err != nil {
+		return Issue{}, nil, err
+	}
+	var ownerID uuid.UUID
+	if err := pool.QueryRow(ctx, `SELECT owner_user_id FROM projects WHERE id = $1`, projectID).Scan(&ownerID); err != nil {
+		return Issue{}, nil, err
+	}
+	acct, err := AccountFor(ctx, pool, p, ownerID, tokenEncKeyB64)
+	if err != nil {
+		return Issue{}, nil, err
+	}
+	iss, err := p.FetchIssue(ctx, acct, ref)
+	if err != nil {
+		return Issue{}, nil, err
+	}
+	return iss, &acct.ID, nil
+}
+
+func githubIssue(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, ref string) (Issue, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(ref, "#"))
+	if err != nil || number <= 0 {
+		return Issue{}, ErrIssueNotFound
+	}
+	var (
+		id         int64
+		title, url *string
+		state      *string
+	)
+	err = pool.QueryRow(ctx, `
+SELECT github_issue_id, title, url, state
+FROM github_issues
+WHERE project_id = $1 AND number = $2
+`, projectID, number).Scan(&id, &title, &url, &state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Issue{}, ErrIssueNotFound
+	}
+	if err != nil {
+		return Issue{}, err
+	}
+	iss := Issue{Provider: ProviderGitHub, ExternalID: strconv.FormatInt(id, 10), Key: "#" + strconv.Itoa(number)}
+	if title != nil {
+		iss.Title = *title
+	}
+	if url != nil {
+		iss.URL = *url
+	}
+	if state != nil {
+		iss.State = *state
+		iss.Closed = *state == "closed"
+	}
+	return iss, nil
+}
+
+// ApplyUpdate refreshes the issue snapshot on bounties attached to iss. For
+// external providers accountID scopes the update to the delivering account.
+func ApplyUpdate(ctx context.Context, pool *pgxpool.Pool, iss Issue, accountID *uuid.UUID) (int64, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `
+UPDATE bounties
+SET issue_title = COALESCE(NULLIF($3, ''), issue_title),
+    issue_url = COALESCE(NULLIF($4, ''), issue_url),
+    issue_state = $5,
+    issue_closed = $6,
+    updated_at = now()
+WHERE issue_provider = $1 AND issue_external_id = $2
+  AND ($7::uuid IS NULL OR issue_provider_account_id = $7)
+`, iss.Provider, iss.ExternalID, iss.Title, iss.URL, iss.State, iss.Closed, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
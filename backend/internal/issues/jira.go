@@ -0,0 +1,166 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Jira is a Jira Cloud site linked with OAuth 2.0 (3LO).
+type Jira struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	HTTP         *http.Client
+}
+
+var jiraKeyRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-[0-9]+$`)
+
+func (j *Jira) Name() string { return ProviderJira }
+
+func (j *Jira) AuthorizeURL(state string) string {
+	q := url.Values{}
+	q.Set("audience", "api.atlassian.com")
+	q.Set("client_id", j.ClientID)
+	q.Set("scope", "read:jira-work offline_access")
+	q.Set("redirect_uri", j.RedirectURL)
+	q.Set("state", state)
+	q.Set("response_type", "code")
+	q.Set("prompt", "consent")
+	return "https://auth.atlassian.com/authorize?" + q.Encode()
+}
+
+type atlassianToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (j *Jira) token(ctx context.Context, body map[string]string) (atlassianToken, error) {
+	body["client_id"] = j.ClientID
+	body["client_secret"] = j.ClientSecret
+	b, _ := json.Marshal(body)
+	req, err := newRequest(ctx, http.MethodPost, "https://auth.atlassian.com/oauth/token", bytes.NewReader(b), "application/json", "")
+	if err != nil {
+		return atlassianToken{}, err
+	}
+	var t atlassianToken
+	if err := doJSON(j.HTTP, req, &t); err != nil {
+		return atlassianToken{}, err
+	}
+	if t.AccessToken == "" {
+		return atlassianToken{}, fmt.Errorf("jira token exchange returned empty token")
+	}
+	return t, nil
+}
+
+// Exchange completes the OAuth flow and binds the link to the first Jira site
+// the user granted access to.
+func (j *Jira) Exchange(ctx context.Context, code string) (Token, error) {
+	t, err := j.token(ctx, map[string]string{"grant_type": "authorization_code", "code": code, "redirect_uri": j.RedirectURL})
+	if err != nil {
+		return Token{}, err
+	}
+	req, err := newRequest(ctx, http.MethodGet, "https://api.atlassian.com/oauth/token/accessible-resources", nil, "", t.AccessToken)
+	if err != nil {
+		return Token{}, err
+	}
+	var sites []struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	if err := doJSON(j.HTTP, req, &sites); err != nil {
+		return Token{}, err
+	}
+	if len(sites) == 0 {
+		return Token{}, fmt.Errorf("no jira site granted")
+	}
+	return Token{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		ExpiresAt:    expiresAt(t.ExpiresIn),
+		SiteID:       sites[0].ID,
+		SiteURL:      strings.TrimRight(sites[0].URL, "/"),
+	}, nil
+}
+
+func (j *Jira) Refresh(ctx context.Context, refreshToken string) (Token, error) {
+	t, err := j.token(ctx, map[string]string{"grant_type": "refresh_token", "refresh_token": refreshToken})
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken, ExpiresAt: expiresAt(t.ExpiresIn)}, nil
+}
+
+type jiraIssue struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string `json:"summary"`
+		Status  struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+	} `json:"fields"`
+}
+
+func (ji jiraIssue) toIssue(siteURL string) Issue {
+	return Issue{
+		Provider:   ProviderJira,
+		ExternalID: ji.ID,
+		Key:        ji.Key,
+		Title:      ji.Fields.Summary,
+		URL:        siteURL + "/browse/" + ji.Key,
+		State:      ji.Fields.Status.Name,
+		Closed:     ji.Fields.Status.StatusCategory.Key == "done",
+	}
+}
+
+func (j *Jira) FetchIssue(ctx context.Context, acct Account, ref string) (Issue, error) {
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	if !jiraKeyRe.MatchString(ref) {
+		return Issue{}, ErrIssueNotFound
+	}
+	u := "https://api.atlassian.com/ex/jira/" + url.PathEscape(acct.SiteID) + "/rest/api/3/issue/" + url.PathEscape(ref) + "?fields=summary,status"
+	req, err := newRequest(ctx, http.MethodGet, u, nil, "", acct.AccessToken)
+	if err != nil {
+		return Issue{}, err
+	}
+	var ji jiraIssue
+	if err := doJSON(j.HTTP, req, &ji); err != nil {
+		return Issue{}, err
+	}
+	return ji.toIssue(acct.SiteURL), nil
+}
+
+// ParseWebhook handles Jira admin webhooks configured with a secret, which
+// sign the body as X-Hub-Signature: sha256=<hex>.
+func (j *Jira) ParseWebhook(acct Account, header http.Header, body []byte, secret string) ([]Issue, error) {
+	sig := header.Get("X-Hub-Signature")
+	if !strings.HasPrefix(sig, "sha256=") || !validHMAC(secret, body, strings.TrimPrefix(sig, "sha256=")) {
+		return nil, ErrBadSignature
+	}
+	var ev struct {
+		WebhookEvent string     `json:"webhookEvent"`
+		Issue        *jiraIssue `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return nil, err
+	}
+	if ev.Issue == nil || !strings.HasPrefix(ev.WebhookEvent, "jira:issue_") {
+		return nil, nil
+	}
+	iss := ev.Issue.toIssue(acct.SiteURL)
+	if ev.WebhookEvent == "jira:issue_deleted" {
+		iss.State = "deleted"
+		iss.Closed = true
+	}
+	return []Issue{iss}, nil
+}
@@ -0,0 +1,187 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Linear is a Linear workspace linked with OAuth.
+type Linear struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	HTTP         *http.Client
+}
+
+var linearKeyRe = regexp.MustCompile(`^[A-Z][A-Z0-9]*-[0-9]+$`)
+
+// linearMaxSkew bounds webhookTimestamp to reject replayed deliveries.
+const linearMaxSkew = 5 * time.Minute
+
+func (l *Linear) Name() string { return ProviderLinear }
+
+func (l *Linear) AuthorizeURL(state string) string {
+	q := url.Values{}
+	q.Set("client_id", l.ClientID)
+	q.Set("redirect_uri", l.RedirectURL)
+	q.Set("response_type", "code")
+	q.Set("scope", "read")
+	q.Set("state", state)
+	q.Set("prompt", "consent")
+	return "https://linear.app/oauth/authorize?" + q.Encode()
+}
+
+func (l *Linear) token(ctx context.Context, form url.Values) (Token, error) {
+	form.Set("client_id", l.ClientID)
+	form.Set("client_secret", l.ClientSecret)
+	req, err := newRequest(ctx, http.MethodPost, "https://api.linear.app/oauth/token", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded", "")
+	if err != nil {
+		return Token{}, err
+	}
+	var t struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := doJSON(l.HTTP, req, &t); err != nil {
+		return Token{}, err
+	}
+	if t.AccessToken == "" {
+		return Token{}, fmt.Errorf("linear token exchange returned empty token")
+	}
+	return Token{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken, ExpiresAt: expiresAt(t.ExpiresIn)}, nil
+}
+
+func (l *Linear) Exchange(ctx context.Context, code string) (Token, error) {
+	t, err := l.token(ctx, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {l.RedirectURL}})
+	if err != nil {
+		return Token{}, err
+	}
+	var resp struct {
+		Organization struct {
+			ID     string `json:"id"`
+			URLKey string `json:"urlKey"`
+		} `json:"organization"`
+	}
+	if err := l.graphQL(ctx, t.AccessToken, `query { organization { id urlKey } }`, nil, &resp); err != nil {
+		return Token{}, err
+	}
+	t.SiteID = resp.Organization.ID
+	t.SiteURL = "https://linear.app/" + resp.Organization.URLKey
+	return t, nil
+}
+
+func (l *Linear) Refresh(ctx context.Context, refreshToken string) (Token, error) {
+	return l.token(ctx, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+}
+
+type linearIssue struct {
+	ID         string `json:"id"`
+	Identifier string `json:"identifier"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	State      *struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"state"`
+}
+
+func (li linearIssue) toIssue() Issue {
+	iss := Issue{Provider: ProviderLinear, ExternalID: li.ID, Key: li.Identifier, Title: li.Title, URL: li.URL}
+	if li.State != nil {
+		iss.State = li.State.Name
+		// Workflow state types: triage, backlog, unstarted, started, completed, canceled.
+		iss.Closed = li.State.Type == "completed" || li.State.Type == "canceled"
+	}
+	return iss
+}
+
+func (l *Linear) FetchIssue(ctx context.Context, acct Account, ref string) (Issue, error) {
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	if !linearKeyRe.MatchString(ref) {
+		return Issue{}, ErrIssueNotFound
+	}
+	var resp struct {
+		Issue *linearIssue `json:"issue"`
+	}
+	err := l.graphQL(ctx, acct.AccessToken, `
+query($id: String!) {
+  issue(id: $id) { id identifier title url state { name type } }
+}`, map[string]any{"id": ref}, &resp)
+	if err != nil {
+		return Issue{}, err
+	}
+	if resp.Issue == nil {
+		return Issue{}, ErrIssueNotFound
+	}
+	return resp.Issue.toIssue(), nil
+}
+
+func (l *Linear) graphQL(ctx context.Context, accessToken, query string, variables map[string]any, out any) error {
+	b, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := newRequest(ctx, http.MethodPost, "https://api.linear.app/graphql", bytes.NewReader(b), "application/json", accessToken)
+	if err != nil {
+		return err
+	}
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := doJSON(l.HTTP, req, &envelope); err != nil {
+		return err
+	}
+	if len(envelope.Errors) > 0 {
+		msg := envelope.Errors[0].Message
+		if strings.Contains(strings.ToLower(msg), "not found") {
+			return ErrIssueNotFound
+		}
+		return fmt.Errorf("linear graphql: %s", msg)
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// ParseWebhook handles Linear webhooks, signed as a hex HMAC-SHA256 of the
+// body in Linear-Signature. Deliveries for other organizations or outside
+// linearMaxSkew are rejected.
+func (l *Linear) ParseWebhook(acct Account, header http.Header, body []byte, secret string) ([]Issue, error) {
+	if !validHMAC(secret, body, header.Get("Linear-Signature")) {
+		return nil, ErrBadSignature
+	}
+	var ev struct {
+		Action           string      `json:"action"`
+		Type             string      `json:"type"`
+		OrganizationID   string      `json:"organizationId"`
+		WebhookTimestamp int64       `json:"webhookTimestamp"`
+		Data             linearIssue `json:"data"`
+	}
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return nil, err
+	}
+	if skew := time.Since(time.UnixMilli(ev.WebhookTimestamp)); skew > linearMaxSkew || skew < -linearMaxSkew {
+		return nil, ErrBadSignature
+	}
+	if ev.OrganizationID != acct.SiteID {
+		return nil, ErrBadSignature
+	}
+	if ev.Type != "Issue" || ev.Data.ID == "" {
+		return nil, nil
+	}
+	iss := ev.Data.toIssue()
+	if ev.Action == "remove" {
+		iss.State = "deleted"
+		iss.Closed = true
+	}
+	return []Issue{iss}, nil
+}
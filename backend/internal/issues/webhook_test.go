@@ -0,0 +1,66 @@
+package issues
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestJiraParseWebhook(t *testing.T) {
+	acct := Account{Provider: ProviderJira, SiteID: "cloud-1", SiteURL: "https://acme.atlassian.net"}
+	body := []byte(`{"webhookEvent":"jira:issue_updated","issue":{"id":"10001","key":"ABC-12","fields":{"summary":"Fix login","status":{"name":"Done","statusCategory":{"key":"done"}}}}}`)
+	h := http.Header{}
+	h.Set("X-Hub-Signature", "sha256="+sign("s3cret", body))
+
+	got, err := (&Jira{}).ParseWebhook(acct, h, body, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Issue{Provider: ProviderJira, ExternalID: "10001", Key: "ABC-12", Title: "Fix login", URL: "https://acme.atlassian.net/browse/ABC-12", State: "Done", Closed: true}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := (&Jira{}).ParseWebhook(acct, h, body, "other"); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("wrong secret: got %v, want ErrBadSignature", err)
+	}
+}
+
+func TestLinearParseWebhook(t *testing.T) {
+	acct := Account{Provider: ProviderLinear, SiteID: "org-1"}
+	body := []byte(fmt.Sprintf(`{"action":"update","type":"Issue","organizationId":"org-1","webhookTimestamp":%d,
+"data":{"id":"uuid-1","identifier":"ENG-42","title":"Add export","url":"https://linear.app/acme/issue/ENG-42","state":{"name":"In Progress","type":"started"}}}`, time.Now().UnixMilli()))
+	h := http.Header{}
+	h.Set("Linear-Signature", sign("s3cret", body))
+
+	got, err := (&Linear{}).ParseWebhook(acct, h, body, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Key != "ENG-42" || got[0].ExternalID != "uuid-1" || got[0].Closed {
+		t.Fatalf("unexpected issues: %+v", got)
+	}
+
+	stale := []byte(`{"action":"update","type":"Issue","organizationId":"org-1","webhookTimestamp":1000,"data":{"id":"uuid-1"}}`)
+	h.Set("Linear-Signature", sign("s3cret", stale))
+	if _, err := (&Linear{}).ParseWebhook(acct, h, stale, "s3cret"); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("stale delivery: got %v, want ErrBadSignature", err)
+	}
+
+	acct.SiteID = "org-2"
+	h.Set("Linear-Signature", sign("s3cret", body))
+	if _, err := (&Linear{}).ParseWebhook(acct, h, body, "s3cret"); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("other org: got %v, want ErrBadSignature", err)
+	}
+}
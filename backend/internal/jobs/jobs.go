@@ -0,0 +1,365 @@
+// Package jobs runs the periodic background work of worker processes. Each
+// job is a singleton across the deployment: a replica runs it only while
+// holding the job's lease in job_leases, at most once per interval, and a
+// crashed holder's lease expires so another replica takes over.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// staleIntervals is how many intervals a job may go without checking in
+// (running, or finding another replica holding or having just run it)
+// before it counts as unhealthy.
+const staleIntervals = 3
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Status is a job's health as seen by this process.
+type Status struct {
+	Name          string     `json:"name"`
+	Interval      string     `json:"interval"`
+	Runs          int64      `json:"runs"`
+	Failures      int64      `json:"failures"`
+	Skipped       int64      `json:"skipped"`
+	Takeovers     int64      `json:"takeovers"`
+	LeasesLost    int64      `json:"leases_lost"`
+	LeaseHeld     bool       `json:"lease_held"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	Healthy       bool       `json:"healthy"`
+}
+
+type entry struct {
+	job Job
+
+	startedAt   time.Time
+	runs        int64
+	failures    int64
+	skipped     int64
+	takeovers   int64
+	leasesLost  int64
+	leaseHeld   bool
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastSkip    time.Time
+	lastErr     string
+}
+
+// healthy reports whether the job checked in recently: it succeeded, or
+// another replica held its lease.
+func (e *entry) healthy(now time.Time) bool {
+	seen := e.startedAt
+	if e.lastSuccess.After(seen) {
+		seen = e.lastSuccess
+	}
+	if e.lastSkip.After(seen) {
+		seen = e.lastSkip
+	}
+	return now.Sub(seen) <= staleIntervals*e.job.Interval
+}
+
+type Scheduler struct {
+	Pool *pgxpool.Pool
+	// Holder names this process in job_leases; defaults to host:pid:random.
+	Holder string
+	// LeaseTTL defaults to DefaultLeaseTTL; leases are renewed every third of it.
+	LeaseTTL time.Duration
+
+	mu      sync.Mutex
+	entries []*entry
+	started bool
+
+	// loops counts running job loops; drain stops them starting new runs,
+	// and cancelRuns cancels the runs in progress.
+	loops      sync.WaitGroup
+	drain      chan struct{}
+	drainOnce  sync.Once
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+}
+
+// Add registers a job; jobs added after Start are ignored.
+func (s *Scheduler) Add(j Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || j.Run == nil || j.Interval <= 0 {
+		return
+	}
+	s.entries = append(s.entries, &entry{job: j})
+}
+
+// Len returns the number of registered jobs.
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Start runs every registered job on its own ticker until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) error {
+	if s.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return nil
+	}
+	s.started = true
+	if s.Holder == "" {
+		s.Holder = defaultHolder()
+	}
+	if s.LeaseTTL <= 0 {
+		s.LeaseTTL = DefaultLeaseTTL
+	}
+	s.drain = make(chan struct{})
+	s.runCtx, s.cancelRuns = context.WithCancel(ctx)
+	now := time.Now()
+	for _, e := range s.entries {
+		e.startedAt = now
+		slog.Info("starting background job", "job", e.job.Name, "interval", e.job.Interval.String(), "holder", s.Holder)
+		s.loops.Add(1)
+		go s.loop(ctx, e)
+	}
+	return nil
+}
+
+// Drain stops starting job runs and waits for the runs in progress to
+// finish. Runs still going when ctx is done are cancelled, and Drain waits
+// for them to return before reporting ctx's error.
+func (s *Scheduler) Drain(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	if !started {
+		return nil
+	}
+	s.drainOnce.Do(func() { close(s.drain) })
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		slog.Warn("cancelling background jobs still running at shutdown")
+		s.cancelRuns()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer s.loops.Done()
+	t := time.NewTicker(e.job.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.drain:
+			return
+		case <-t.C:
+			s.tick(s.runCtx, e)
+		}
+	}
+}
+
+// tick runs the job once if it is due and this process wins its lease. The
+// lease is renewed while the job runs; if it is lost the run is cancelled.
+func (s *Scheduler) tick(ctx context.Context, e *entry) {
+	ok, prev, err := acquireLease(ctx, s.Pool, e.job.Name, s.Holder, s.LeaseTTL, dueGap(e.job.Interval))
+	if err != nil {
+		slog.Error("job lease acquire failed", "job", e.job.Name, "error", err)
+		s.finish(e, false, err)
+		return
+	}
+	if !ok {
+		s.finish(e, true, nil)
+		return
+	}
+	s.mu.Lock()
+	e.leaseHeld = true
+	if prev != "" {
+		e.takeovers++
+	}
+	s.mu.Unlock()
+	if prev != "" {
+		slog.Warn("took over expired job lease", "job", e.job.Name, "previous_holder", prev)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.heartbeat(runCtx, e, cancel)
+	}()
+
+	err = e.job.Run(runCtx)
+	cancel()
+	<-done
+	if err != nil {
+		slog.Error("background job failed", "job", e.job.Name, "error", err)
+	}
+
+	relCtx, relCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := releaseLease(relCtx, s.Pool, e.job.Name, s.Holder); err != nil {
+		slog.Warn("job lease release failed; it will expire", "job", e.job.Name, "error", err)
+	}
+	relCancel()
+
+	s.mu.Lock()
+	e.leaseHeld = false
+	s.mu.Unlock()
+	s.finish(e, false, err)
+}
+
+// heartbeat renews the lease until ctx is done, cancelling the run when the
+// lease is lost.
+func (s *Scheduler) heartbeat(ctx context.Context, e *entry, cancel context.CancelFunc) {
+	t := time.NewTicker(s.LeaseTTL / 3)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			held, err := renewLease(ctx, s.Pool, e.job.Name, s.Holder, s.LeaseTTL)
+			if err != nil {
+				// Keep going; the lease survives a missed renewal or two.
+				slog.Warn("job lease renewal failed", "job", e.job.Name, "error", err)
+				continue
+			}
+			if !held {
+				slog.Error("job lease lost; cancelling run", "job", e.job.Name)
+				s.mu.Lock()
+				e.leasesLost++
+				s.mu.Unlock()
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (s *Scheduler) finish(e *entry, skipped bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	switch {
+	case skipped:
+		e.skipped++
+		e.lastSkip = now
+	case err != nil:
+		e.runs++
+		e.failures++
+		e.lastRun = now
+		e.lastErr = err.Error()
+	default:
+		e.runs++
+		e.lastRun = now
+		e.lastSuccess = now
+		e.lastErr = ""
+	}
+}
+
+// Statuses returns every job's health, sorted by name.
+func (s *Scheduler) Statuses(now time.Time) []Status {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		st := Status{
+			Name:       e.job.Name,
+			Interval:   e.job.Interval.String(),
+			Runs:       e.runs,
+			Failures:   e.failures,
+			Skipped:    e.skipped,
+			Takeovers:  e.takeovers,
+			LeasesLost: e.leasesLost,
+			LeaseHeld:  e.leaseHeld,
+			LastError:  e.lastErr,
+			Healthy:    !s.started || e.healthy(now),
+		}
+		if !e.lastRun.IsZero() {
+			t := e.lastRun
+			st.LastRunAt = &t
+		}
+		if !e.lastSuccess.IsZero() {
+			t := e.lastSuccess
+			st.LastSuccessAt = &t
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Healthy reports whether every job has checked in recently.
+func (s *Scheduler) Healthy(now time.Time) bool {
+	for _, st := range s.Statuses(now) {
+		if !st.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteMetrics exports job runs and lease activity in the Prometheus text
+// format.
+func (s *Scheduler) WriteMetrics(w io.Writer) error {
+	sts := s.Statuses(time.Now())
+	if len(sts) == 0 {
+		return nil
+	}
+	b01 := func(v bool) string {
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	metrics := []struct {
+		name, help, kind string
+		value            func(Status) string
+	}{
+		{"grainlify_job_runs_total", "Runs of the job by this process.", "counter", func(st Status) string { return fmt.Sprint(st.Runs) }},
+		{"grainlify_job_failures_total", "Failed runs of the job by this process.", "counter", func(st Status) string { return fmt.Sprint(st.Failures) }},
+		{"grainlify_job_lease_skips_total", "Ticks where the job was not due or another replica held its lease.", "counter", func(st Status) string { return fmt.Sprint(st.Skipped) }},
+		{"grainlify_job_lease_takeovers_total", "Expired leases this process took over from a crashed holder.", "counter", func(st Status) string { return fmt.Sprint(st.Takeovers) }},
+		{"grainlify_job_lease_lost_total", "Runs cancelled because the lease was lost mid-run.", "counter", func(st Status) string { return fmt.Sprint(st.LeasesLost) }},
+		{"grainlify_job_lease_held", "Whether this process currently holds the job's lease.", "gauge", func(st Status) string { return b01(st.LeaseHeld) }},
+		{"grainlify_job_healthy", "Whether the job checked in within three intervals.", "gauge", func(st Status) string { return b01(st.Healthy) }},
+	}
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, st := range sts {
+			fmt.Fprintf(&b, "%s{job=%q} %s\n", m.name, st.Name, m.value(st))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
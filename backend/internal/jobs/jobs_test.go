@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusesTrackHealth(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+	s := &Scheduler{started: true}
+	s.Add(Job{Name: "b", Interval: time.Minute, Run: noop})
+	s.Add(Job{Name: "a", Interval: time.Minute, Run: noop})
+	s.Add(Job{Name: "disabled", Interval: 0, Run: noop})
+	if s.Len() != 0 {
+		t.Fatalf("jobs added after start should be ignored, got %d", s.Len())
+	}
+
+	s = &Scheduler{}
+	s.Add(Job{Name: "b", Interval: time.Minute, Run: noop})
+	s.Add(Job{Name: "a", Interval: time.Minute, Run: noop})
+	s.Add(Job{Name: "disabled", Interval: 0, Run: noop})
+	if s.Len() != 2 {
+		t.Fatalf("want 2 jobs, got %d", s.Len())
+	}
+	start := time.Now()
+	s.started = true
+	for _, e := range s.entries {
+		e.startedAt = start
+	}
+
+	if !s.Healthy(start.Add(2 * time.Minute)) {
+		t.Fatal("jobs should be healthy within three intervals of starting")
+	}
+	if s.Healthy(start.Add(4 * time.Minute)) {
+		t.Fatal("jobs that never ran should go stale")
+	}
+
+	a, b := s.entries[1], s.entries[0]
+	s.finish(a, false, nil)
+	s.finish(b, true, nil)
+	s.finish(b, false, errors.New("boom"))
+	now := time.Now().Add(2 * time.Minute)
+	sts := s.Statuses(now)
+	if sts[0].Name != "a" || sts[1].Name != "b" {
+		t.Fatalf("statuses not sorted: %+v", sts)
+	}
+	if sts[0].Runs != 1 || sts[0].LastSuccessAt == nil || !sts[0].Healthy {
+		t.Fatalf("unexpected status for a: %+v", sts[0])
+	}
+	if sts[1].Skipped != 1 || sts[1].Failures != 1 || sts[1].LastError != "boom" || !sts[1].Healthy {
+		t.Fatalf("unexpected status for b: %+v", sts[1])
+	}
+	if s.Healthy(now.Add(2 * time.Minute)) {
+		t.Fatal("a failing job should go stale after three intervals")
+	}
+}
+
+func TestDueGap(t *testing.T) {
+	cases := map[time.Duration]time.Duration{
+		10 * time.Second: 9 * time.Second,
+		time.Minute:      55 * time.Second,
+		time.Hour:        time.Hour - 5*time.Second,
+	}
+	for interval, want := range cases {
+		if got := dueGap(interval); got != want {
+			t.Errorf("dueGap(%s) = %s, want %s", interval, got, want)
+		}
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	var nilScheduler *Scheduler
+	var buf strings.Builder
+	if err := nilScheduler.WriteMetrics(&buf); err != nil || buf.Len() != 0 {
+		t.Fatalf("nil scheduler should write nothing, got %q, %v", buf.String(), err)
+	}
+
+	s := &Scheduler{}
+	s.Add(Job{Name: "sweep", Interval: time.Minute, Run: func(context.Context) error { return nil }})
+	s.entries[0].takeovers = 2
+	s.entries[0].leaseHeld = true
+	if err := s.WriteMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`grainlify_job_lease_takeovers_total{job="sweep"} 2`,
+		`grainlify_job_lease_held{job="sweep"} 1`,
+		`grainlify_job_runs_total{job="sweep"} 0`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("metrics missing %q:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		7:  32 * time.Minute,
+		8:  time.Hour,
+		20: time.Hour,
+	}
+	for attempt, want := range cases {
+		if got := Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestDrainCancelsRunsPastDeadline(t *testing.T) {
+	if err := (&Scheduler{}).Drain(context.Background()); err != nil {
+		t.Fatalf("draining an unstarted scheduler: %v", err)
+	}
+
+	s := &Scheduler{started: true, drain: make(chan struct{})}
+	s.runCtx, s.cancelRuns = context.WithCancel(context.Background())
+	// A run that only returns once cancelled, as a loop mid-tick would.
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		<-s.runCtx.Done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v", err)
+	}
+	select {
+	case <-s.drain:
+	default:
+		t.Fatal("drain channel left open")
+	}
+}
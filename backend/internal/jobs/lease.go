@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultLeaseTTL is how long a lease outlives its last renewal, and so how
+// long a crashed holder blocks a job before another replica takes over.
+const DefaultLeaseTTL = 30 * time.Second
+
+// dueGap is how long after the last start a job may run again. It is a
+// little under the interval so that replicas ticking in step don't skip a
+// cycle over clock and scheduling jitter.
+func dueGap(interval time.Duration) time.Duration {
+	slack := interval / 10
+	if slack > 5*time.Second {
+		slack = 5 * time.Second
+	}
+	return interval - slack
+}
+
+// acquireLease takes the job's lease if it is free (released or expired) and
+// the job is due. prevHolder is set when an expired lease was taken over from
+// a holder that never released it.
+func acquireLease(ctx context.Context, pool *pgxpool.Pool, name, holder string, ttl, gap time.Duration) (ok bool, prevHolder string, err error) {
+	if pool == nil {
+		return false, "", fmt.Errorf("db not configured")
+	}
+	err = pool.QueryRow(ctx, `
+WITH prev AS (SELECT holder FROM job_leases WHERE name = $1)
+INSERT INTO job_leases (name, holder, acquired_at, expires_at, last_started_at)
+VALUES ($1, $2, now(), now() + $3 * interval '1 millisecond', now())
+ON CONFLICT (name) DO UPDATE SET
+  holder = EXCLUDED.holder,
+  acquired_at = EXCLUDED.acquired_at,
+  expires_at = EXCLUDED.expires_at,
+  last_started_at = EXCLUDED.last_started_at,
+  takeovers = job_leases.takeovers + CASE WHEN job_leases.holder IS NULL THEN 0 ELSE 1 END
+WHERE (job_leases.holder IS NULL OR job_leases.expires_at <= now())
+  AND (job_leases.last_started_at IS NULL OR job_leases.last_started_at <= now() - $4 * interval '1 millisecond')
+RETURNING COALESCE((SELECT holder FROM prev), '')
+`, name, holder, ttl.Milliseconds(), gap.Milliseconds()).Scan(&prevHolder)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, prevHolder, nil
+}
+
+// renewLease extends a held lease; false means it was lost to another holder.
+func renewLease(ctx context.Context, pool *pgxpool.Pool, name, holder string, ttl time.Duration) (bool, error) {
+	tag, err := pool.Exec(ctx, `
+UPDATE job_leases SET expires_at = now() + $3 * interval '1 millisecond'
+WHERE name = $1 AND holder = $2
+`, name, holder, ttl.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// releaseLease frees a held lease so the next due tick anywhere can take it.
+func releaseLease(ctx context.Context, pool *pgxpool.Pool, name, holder string) error {
+	_, err := pool.Exec(ctx, `
+UPDATE job_leases SET holder = NULL, expires_at = now(), last_finished_at = now()
+WHERE name = $1 AND holder = $2
+`, name, holder)
+	return err
+}
+
+// defaultHolder identifies this process in job_leases. The random suffix
+// keeps restarted containers (often PID 1 on the same host name) distinct.
+func defaultHolder() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b))
+}
@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// staleClaim is how long a running job may go without finishing before
+// another worker assumes its claimant died and runs it again.
+const staleClaim = 10 * time.Minute
+
+// Handler processes one queued job; returning an error schedules a retry.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Queue is a pool of workers draining job_queue. Any number of processes
+// may run one; rows are claimed with SKIP LOCKED.
+type Queue struct {
+	Pool *pgxpool.Pool
+	// Workers defaults to 2.
+	Workers int
+	// Poll is how often an idle worker looks for due jobs; defaults to 1s.
+	Poll time.Duration
+	// Holder names this process in locked_by; defaults to host:pid:random.
+	Holder string
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+}
+
+// Handle routes jobs of kind to h. Register handlers before Run.
+func (q *Queue) Handle(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.handlers == nil {
+		q.handlers = map[string]Handler{}
+	}
+	q.handlers[kind] = h
+}
+
+// Enqueue adds a job unless one of the same kind and key is already
+// pending; it reports whether a job was added.
+func Enqueue(ctx context.Context, pool *pgxpool.Pool, kind, key string, payload any) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+	tag, err := pool.Exec(ctx, `
+INSERT INTO job_queue (kind, dedupe_key, payload)
+VALUES ($1, $2, $3)
+ON CONFLICT (kind, dedupe_key) WHERE status = 'pending' DO NOTHING
+`, kind, key, b)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Backoff is the delay before retry number attempt (1-based): 30s doubling
+// up to an hour.
+func Backoff(attempt int) time.Duration {
+	d := 30 * time.Second
+	for i := 1; i < attempt && d < time.Hour; i++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
+
+// Run drains the queue with Workers workers until ctx is done.
+func (q *Queue) Run(ctx context.Context) error {
+	if q.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	q.mu.Lock()
+	kinds := make([]string, 0, len(q.handlers))
+	for k := range q.handlers {
+		kinds = append(kinds, k)
+	}
+	q.mu.Unlock()
+	if len(kinds) == 0 {
+		return nil
+	}
+	if q.Holder == "" {
+		q.Holder = defaultHolder()
+	}
+	workers := q.Workers
+	if workers <= 0 {
+		workers = 2
+	}
+	poll := q.Poll
+	if poll <= 0 {
+		poll = time.Second
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := time.NewTicker(poll)
+			defer t.Stop()
+			for {
+				// Drain everything due before waiting for the next poll.
+				for {
+					ran, err := q.runOne(ctx, kinds)
+					if err != nil && ctx.Err() == nil {
+						slog.Error("job queue error", "error", err)
+					}
+					if !ran || ctx.Err() != nil {
+						break
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// runOne claims and runs a single due job, reporting whether there was one.
+func (q *Queue) runOne(ctx context.Context, kinds []string) (bool, error) {
+	var id uuid.UUID
+	var kind string
+	var payload []byte
+	var attempts, maxAttempts int
+	err := q.Pool.QueryRow(ctx, `
+UPDATE job_queue
+SET status = 'running', attempts = attempts + 1, locked_by = $2, locked_at = now(), updated_at = now()
+WHERE id = (
+  SELECT id FROM job_queue
+  WHERE kind = ANY($1)
+    AND ((status = 'pending' AND run_after <= now())
+      OR (status = 'running' AND attempts < max_attempts AND locked_at < now() - $3 * interval '1 second'))
+  ORDER BY run_after
+  FOR UPDATE SKIP LOCKED
+  LIMIT 1
+)
+RETURNING id, kind, payload, attempts, max_attempts
+`, kinds, q.Holder, int(staleClaim.Seconds())).Scan(&id, &kind, &payload, &attempts, &maxAttempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	q.mu.Lock()
+	h := q.handlers[kind]
+	q.mu.Unlock()
+	runErr := h(ctx, payload)
+
+	// Record the outcome even if ctx was cancelled mid-run.
+	done, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if runErr == nil {
+		_, err = q.Pool.Exec(done, `DELETE FROM job_queue WHERE id = $1`, id)
+		return true, err
+	}
+	if attempts >= maxAttempts {
+		slog.Error("queued job failed permanently", "job_id", id.String(), "kind", kind, "attempts", attempts, "error", runErr)
+		_, err = q.Pool.Exec(done, `
+UPDATE job_queue SET status = 'failed', last_error = $2, locked_by = NULL, updated_at = now()
+WHERE id = $1
+`, id, runErr.Error())
+		return true, err
+	}
+	retryIn := Backoff(attempts)
+	slog.Warn("queued job failed; retrying", "job_id", id.String(), "kind", kind, "attempts", attempts, "retry_in", retryIn.String(), "error", runErr)
+	_, err = q.Pool.Exec(done, `
+UPDATE job_queue
+SET status = 'pending', last_error = $2, locked_by = NULL, run_after = now() + $3 * interval '1 second', updated_at = now()
+WHERE id = $1
+`, id, runErr.Error(), int(retryIn.Seconds()))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		// A fresh job with the same key was enqueued meanwhile; it supersedes this retry.
+		_, err = q.Pool.Exec(done, `DELETE FROM job_queue WHERE id = $1`, id)
+	}
+	return true, err
+}
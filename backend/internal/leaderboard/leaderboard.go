@@ -0,0 +1,258 @@
+// Package leaderboard ranks contributors by their merged pull requests and
+// completed bounties across verified projects, and totals what they earned.
+// A background job recomputes each time window into contributor_stats, so
+// reads never aggregate the GitHub mirror themselves.
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Time windows.
+const (
+	WindowWeek  = "week"
+	WindowMonth = "month"
+	WindowAll   = "all"
+)
+
+// Windows lists every window, shortest first.
+var Windows = []string{WindowWeek, WindowMonth, WindowAll}
+
+var (
+	ErrInvalidWindow = errors.New("invalid_window")
+	ErrUserNotFound  = errors.New("user_not_found")
+)
+
+// ParseWindow validates a window name; "" is all-time.
+func ParseWindow(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "":
+		return WindowAll, nil
+	case WindowWeek, WindowMonth, WindowAll:
+		return s, nil
+	}
+	return "", ErrInvalidWindow
+}
+
+// Since returns where window starts relative to now; all-time has no start.
+func Since(window string, now time.Time) *time.Time {
+	var d time.Duration
+	switch window {
+	case WindowWeek:
+		d = 7 * 24 * time.Hour
+	case WindowMonth:
+		d = 30 * 24 * time.Hour
+	default:
+		return nil
+	}
+	t := now.Add(-d)
+	return &t
+}
+
+// Entry is one contributor's standing in a window. Score is merged pull
+// requests plus completed bounties; earnings can't rank contributors since
+// they are in different assets.
+type Entry struct {
+	Window            string            `json:"window"`
+	Rank              int               `json:"rank"`
+	Login             string            `json:"login"`
+	UserID            *uuid.UUID        `json:"user_id,omitempty"`
+	MergedPRs         int               `json:"merged_prs"`
+	CompletedBounties int               `json:"completed_bounties"`
+	Projects          int               `json:"projects"`
+	Earnings          map[string]string `json:"earnings"`
+	Score             int               `json:"score"`
+	ComputedAt        time.Time         `json:"computed_at"`
+}
+
+// Stats is a user's standing in every window, keyed by window; windows they
+// have no activity in are left out.
+type Stats struct {
+	UserID  uuid.UUID        `json:"user_id"`
+	Login   string           `json:"login"`
+	Windows map[string]Entry `json:"windows"`
+}
+
+// Refresh recomputes window, replacing its rows in one transaction so
+// readers see either the old ranking or the new one, and returns how many
+// contributors it ranked.
+func Refresh(ctx context.Context, pool *pgxpool.Pool, window string, now time.Time) (int, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `DELETE FROM contributor_stats WHERE time_window = $1`, window); err != nil {
+		return 0, err
+	}
+	// Bounties and earnings belong to users; they count for the GitHub
+	// account the user linked.
+	tag, err := tx.Exec(ctx, `
+WITH prs AS (
+  SELECT lower(pr.author_login) AS login_key, MIN(pr.author_login) AS login,
+         COUNT(*) AS merged_prs, COUNT(DISTINCT pr.project_id) AS projects
+  FROM github_pull_requests pr
+  JOIN projects p ON p.id = pr.project_id
+  WHERE pr.merged = true AND p.status = 'verified' AND p.deleted_at IS NULL
+    AND COALESCE(pr.author_login, '') <> ''
+    AND ($2::timestamptz IS NULL OR pr.merged_at_github >= $2)
+  GROUP BY lower(pr.author_login)
+),
+bounties_done AS (
+  SELECT lower(ga.login) AS login_key, COUNT(*) AS completed_bounties
+  FROM bounties b
+  JOIN projects p ON p.id = b.project_id
+  JOIN github_accounts ga ON ga.user_id = b.claimed_by
+  WHERE b.status = 'completed' AND p.deleted_at IS NULL
+    AND ($2::timestamptz IS NULL OR COALESCE(b.approved_at, b.updated_at) >= $2)
+  GROUP BY lower(ga.login)
+),
+earned AS (
+  SELECT login_key, jsonb_object_agg(asset, total) AS earnings
+  FROM (
+    SELECT lower(ga.login) AS login_key, py.chain || '/' || py.asset AS asset, SUM(py.amount)::text AS total
+    FROM payouts py
+    JOIN github_accounts ga ON ga.user_id = py.user_id
+    WHERE py.status IN ('submitted', 'confirmed')
+      AND ($2::timestamptz IS NULL OR py.updated_at >= $2)
+    GROUP BY lower(ga.login), py.chain, py.asset
+  ) t
+  GROUP BY login_key
+),
+merged AS (
+  SELECT COALESCE(prs.login_key, bd.login_key) AS login_key,
+         COALESCE(prs.merged_prs, 0) AS merged_prs,
+         COALESCE(bd.completed_bounties, 0) AS completed_bounties,
+         COALESCE(prs.projects, 0) AS projects,
+         prs.login AS pr_login
+  FROM prs
+  FULL JOIN bounties_done bd ON bd.login_key = prs.login_key
+)
+INSERT INTO contributor_stats (time_window, login_key, login, user_id, merged_prs, completed_bounties, projects, earnings, score, rank, computed_at)
+SELECT $1, m.login_key, COALESCE(ga.login, m.pr_login), ga.user_id,
+       m.merged_prs, m.completed_bounties, m.projects, COALESCE(e.earnings, '{}'::jsonb),
+       m.merged_prs + m.completed_bounties,
+       ROW_NUMBER() OVER (ORDER BY m.merged_prs + m.completed_bounties DESC, m.completed_bounties DESC, m.login_key),
+       $3
+FROM merged m
+LEFT JOIN LATERAL (
+  SELECT login, user_id FROM github_accounts
+  WHERE lower(login) = m.login_key
+  ORDER BY updated_at DESC
+  LIMIT 1
+) ga ON true
+LEFT JOIN earned e ON e.login_key = m.login_key
+`, window, Since(window, now), now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), tx.Commit(ctx)
+}
+
+const entrySelect = `
+SELECT time_window, rank, login, user_id, merged_prs, completed_bounties, projects, earnings, score, computed_at
+FROM contributor_stats
+`
+
+func scanEntry(row pgx.Row) (Entry, error) {
+	var e Entry
+	err := row.Scan(&e.Window, &e.Rank, &e.Login, &e.UserID, &e.MergedPRs, &e.CompletedBounties, &e.Projects, &e.Earnings, &e.Score, &e.ComputedAt)
+	if e.Earnings == nil {
+		e.Earnings = map[string]string{}
+	}
+	return e, err
+}
+
+// List returns a page of window's ranking.
+func List(ctx context.Context, pool *pgxpool.Pool, window string, limit, offset int) ([]Entry, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, entrySelect+`WHERE time_window = $1 ORDER BY rank LIMIT $2 OFFSET $3`, window, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Entry{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// ForUser returns userID's standing in every window. Users without a linked
+// GitHub account, or without activity, have no windows.
+func ForUser(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (Stats, error) {
+	if pool == nil {
+		return Stats{}, fmt.Errorf("db not configured")
+	}
+	st := Stats{UserID: userID, Windows: map[string]Entry{}}
+	var login *string
+	err := pool.QueryRow(ctx, `
+SELECT ga.login FROM users u LEFT JOIN github_accounts ga ON ga.user_id = u.id WHERE u.id = $1
+`, userID).Scan(&login)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Stats{}, ErrUserNotFound
+	}
+	if err != nil {
+		return Stats{}, err
+	}
+	if login == nil {
+		return st, nil
+	}
+	st.Login = *login
+	rows, err := pool.Query(ctx, entrySelect+`WHERE login_key = lower($1)`, st.Login)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return Stats{}, err
+		}
+		st.Windows[e.Window] = e
+	}
+	return st, rows.Err()
+}
+
+// Refresher recomputes every window.
+type Refresher struct {
+	Pool *pgxpool.Pool
+}
+
+// RunOnce refreshes each window; a failing window is logged and the others
+// still refresh.
+func (r *Refresher) RunOnce(ctx context.Context) error {
+	if r.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	now := time.Now().UTC()
+	var errs []error
+	for _, w := range Windows {
+		n, err := Refresh(ctx, r.Pool, w, now)
+		if err != nil {
+			slog.Warn("leaderboard refresh failed", "window", w, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", w, err))
+			continue
+		}
+		slog.Debug("leaderboard refreshed", "window", w, "contributors", n)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,29 @@
+package leaderboard
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWindows(t *testing.T) {
+	for in, want := range map[string]string{"": WindowAll, "Week": WindowWeek, " month ": WindowMonth, "all": WindowAll} {
+		if got, err := ParseWindow(in); err != nil || got != want {
+			t.Errorf("ParseWindow(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseWindow("year"); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("ParseWindow(year) err = %v", err)
+	}
+
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	if s := Since(WindowWeek, now); s == nil || !s.Equal(time.Date(2026, 3, 24, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("week since = %v", s)
+	}
+	if s := Since(WindowMonth, now); s == nil || !s.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("month since = %v", s)
+	}
+	if s := Since(WindowAll, now); s != nil {
+		t.Errorf("all-time since = %v", s)
+	}
+}
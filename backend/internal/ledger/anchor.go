@@ -0,0 +1,254 @@
+package ledger
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNoAnchor = errors.New("no ledger anchor yet")
+
+// Anchor is a signed Merkle root over ledger_entries with seq <= ToSeq.
+type Anchor struct {
+	ID        uuid.UUID `json:"id"`
+	ToSeq     int64     `json:"to_seq"`
+	LeafCount int64     `json:"leaf_count"`
+	Root      string    `json:"root"`
+	Signature string    `json:"signature"`
+	PublicKey string    `json:"public_key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AnchorMessage is what the anchor signature covers.
+func AnchorMessage(toSeq, leafCount int64, root string) []byte {
+	return []byte(fmt.Sprintf("grainlify-ledger-anchor:v1:%d:%d:%s", toSeq, leafCount, root))
+}
+
+// Signer holds the Ed25519 key used to publish anchors.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSignerFromB64 takes a base64 32-byte Ed25519 seed.
+func NewSignerFromB64(b64 string) (*Signer, error) {
+	if b64 == "" {
+		return nil, fmt.Errorf("LEDGER_ANCHOR_KEY_B64 is required")
+	}
+	seed, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("decode LEDGER_ANCHOR_KEY_B64: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("LEDGER_ANCHOR_KEY_B64 must decode to %d bytes", ed25519.SeedSize)
+	}
+	return &Signer{key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+func (s *Signer) PublicKey() string {
+	return hex.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// Sign returns the hex Ed25519 signature of msg. The same platform key signs
+// anchors and other published documents, so callers must domain-separate msg.
+func (s *Signer) Sign(msg []byte) string {
+	return hex.EncodeToString(ed25519.Sign(s.key, msg))
+}
+
+// VerifyAnchor checks the anchor signature against its embedded public key.
+// Callers should also pin the expected public key out of band.
+func VerifyAnchor(a Anchor) bool {
+	pub, err := hex.DecodeString(a.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	sig, err := hex.DecodeString(a.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(pub, AnchorMessage(a.ToSeq, a.LeafCount, a.Root), sig)
+}
+
+const anchorColumns = `id, to_seq, leaf_count, root, signature, public_key, created_at`
+
+func scanAnchor(row pgx.Row) (Anchor, error) {
+	var a Anchor
+	err := row.Scan(&a.ID, &a.ToSeq, &a.LeafCount, &a.Root, &a.Signature, &a.PublicKey, &a.CreatedAt)
+	return a, err
+}
+
+// committedHead returns the highest seq such that every entry at or below it
+// is committed or rolled back. Taking appendLock exclusively waits out open
+// appends; later ones draw higher seqs from the sequence.
+func committedHead(ctx context.Context, pool *pgxpool.Pool) (int64, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLock); err != nil {
+		return 0, err
+	}
+	var head int64
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(seq), 0) FROM ledger_entries`).Scan(&head); err != nil {
+		return 0, err
+	}
+	return head, tx.Commit(ctx)
+}
+
+// CreateAnchor signs the current ledger head. It returns (nil, nil) if nothing
+// was appended since the last anchor.
+func CreateAnchor(ctx context.Context, pool *pgxpool.Pool, signer *Signer) (*Anchor, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	head, err := committedHead(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	last, err := LatestAnchor(ctx, pool)
+	if err != nil && !errors.Is(err, ErrNoAnchor) {
+		return nil, err
+	}
+	if head == 0 || (err == nil && last.ToSeq >= head) {
+		return nil, nil
+	}
+
+	leaves, _, err := leafHashes(ctx, pool, head)
+	if err != nil {
+		return nil, err
+	}
+	root := NewTree(leaves).Root()
+	sig := ed25519.Sign(signer.key, AnchorMessage(head, int64(len(leaves)), root))
+
+	a, err := scanAnchor(pool.QueryRow(ctx, `
+INSERT INTO ledger_anchors (to_seq, leaf_count, root, signature, public_key)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING `+anchorColumns, head, len(leaves), root, hex.EncodeToString(sig), signer.PublicKey()))
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func LatestAnchor(ctx context.Context, pool *pgxpool.Pool) (Anchor, error) {
+	if pool == nil {
+		return Anchor{}, fmt.Errorf("db not configured")
+	}
+	a, err := scanAnchor(pool.QueryRow(ctx, `SELECT `+anchorColumns+` FROM ledger_anchors ORDER BY to_seq DESC, created_at DESC LIMIT 1`))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Anchor{}, ErrNoAnchor
+	}
+	return a, err
+}
+
+func ListAnchors(ctx context.Context, pool *pgxpool.Pool, limit int) ([]Anchor, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+anchorColumns+` FROM ledger_anchors ORDER BY to_seq DESC, created_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Anchor{}
+	for rows.Next() {
+		a, err := scanAnchor(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// InclusionProof ties one of the user's entries to an anchor root.
+type InclusionProof struct {
+	Entry    Entry       `json:"entry"`
+	LeafData string      `json:"leaf_data"`
+	LeafHash string      `json:"leaf_hash"`
+	Index    int         `json:"index"`
+	Path     []ProofStep `json:"path"`
+}
+
+// UserProofs returns inclusion proofs for every entry of userID covered by the
+// latest anchor. Entries appended after the anchor are not provable yet.
+func UserProofs(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (Anchor, []InclusionProof, error) {
+	a, err := LatestAnchor(ctx, pool)
+	if err != nil {
+		return Anchor{}, nil, err
+	}
+	entries, err := UserEntries(ctx, pool, userID, a.ToSeq)
+	if err != nil {
+		return Anchor{}, nil, err
+	}
+	out := []InclusionProof{}
+	if len(entries) == 0 {
+		return a, out, nil
+	}
+
+	tree, index, err := anchoredTree(ctx, pool, a)
+	if err != nil {
+		return Anchor{}, nil, err
+	}
+
+	for _, e := range entries {
+		i := index[e.Seq]
+		path, err := tree.Proof(i)
+		if err != nil {
+			return Anchor{}, nil, err
+		}
+		data := e.LeafData()
+		out = append(out, InclusionProof{
+			Entry:    e,
+			LeafData: string(data),
+			LeafHash: hex.EncodeToString(HashLeaf(data)),
+			Index:    i,
+			Path:     path,
+		})
+	}
+	return a, out, nil
+}
+
+// treeCache holds the Merkle tree of the most recently proven anchor. Anchored
+// rows cannot change, so it is only rebuilt when a newer anchor is published.
+var treeCache struct {
+	mu    sync.Mutex
+	id    uuid.UUID
+	tree  *Tree
+	index map[int64]int
+}
+
+// anchoredTree returns the tree over a's entries, checking it against the
+// signed root when it is built.
+func anchoredTree(ctx context.Context, pool *pgxpool.Pool, a Anchor) (*Tree, map[int64]int, error) {
+	treeCache.mu.Lock()
+	defer treeCache.mu.Unlock()
+	if treeCache.tree != nil && treeCache.id == a.ID {
+		return treeCache.tree, treeCache.index, nil
+	}
+
+	leaves, index, err := leafHashes(ctx, pool, a.ToSeq)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree := NewTree(leaves)
+	if tree.Root() != a.Root {
+		// The ledger no longer matches what we signed: surface it loudly.
+		slog.Error("ledger does not match anchored root", "anchor_id", a.ID.String(), "to_seq", a.ToSeq)
+		return nil, nil, fmt.Errorf("ledger root mismatch for anchor %s", a.ID)
+	}
+	treeCache.id, treeCache.tree, treeCache.index = a.ID, tree, index
+	return tree, index, nil
+}
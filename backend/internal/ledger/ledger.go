@@ -0,0 +1,158 @@
+// Package ledger is the append-only record of money movements. Rows are never
+// updated; corrections are new entries. Periodic Merkle anchors over the whole
+// history make later edits detectable, and users can check their own entries.
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Accounts.
+const (
+	AccountUser     = "user"
+	AccountTreasury = "treasury"
+	AccountEscrow   = "escrow"
+	AccountFees     = "fees"
+	AccountCold     = "cold"
+)
+
+// Kinds.
+const (
+	KindDeposit    = "deposit"
+	KindPayout     = "payout"
+	KindFee        = "fee"
+	KindAdjustment = "adjustment"
+	KindSweep      = "sweep"
+	// KindAccrual moves payouts below their threshold onto the user's
+	// account and back off when the accrued balance is paid out.
+	KindAccrual = "accrual"
+)
+
+// Asset qualifies an asset with its chain, e.g. "stellar/XLM" or
+// "ethereum/0xa0b8...", which is how assets are keyed in the ledger.
+func Asset(chain, asset string) string {
+	return chain + "/" + asset
+}
+
+type Entry struct {
+	ID        uuid.UUID  `json:"id"`
+	Seq       int64      `json:"seq"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Account   string     `json:"account"`
+	Kind      string     `json:"kind"`
+	Asset     string     `json:"asset"`
+	Amount    string     `json:"amount"` // decimal string, signed
+	Reference *string    `json:"reference,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// LeafData is the canonical encoding hashed into the Merkle tree. It is
+// documented in the proofs response so users can recompute it themselves.
+func (e Entry) LeafData() []byte {
+	user, ref := "", ""
+	if e.UserID != nil {
+		user = e.UserID.String()
+	}
+	if e.Reference != nil {
+		ref = *e.Reference
+	}
+	return []byte(strings.Join([]string{
+		fmt.Sprint(e.Seq),
+		e.ID.String(),
+		user,
+		e.Account,
+		e.Kind,
+		e.Asset,
+		e.Amount,
+		ref,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}, "|"))
+}
+
+const entryColumns = `id, seq, user_id, account, kind, asset, amount::text, reference, created_at`
+
+func scanEntry(row pgx.Row) (Entry, error) {
+	var e Entry
+	err := row.Scan(&e.ID, &e.Seq, &e.UserID, &e.Account, &e.Kind, &e.Asset, &e.Amount, &e.Reference, &e.CreatedAt)
+	return e, err
+}
+
+// appendLock orders appends against anchoring. Appends hold it shared until
+// their transaction ends; CreateAnchor takes it exclusively before reading the
+// head, so no seq below the head can still be uncommitted.
+const appendLock int64 = 0x6c6564676572 // "ledger"
+
+// Append writes one entry inside tx. amount is a signed decimal string.
+func Append(ctx context.Context, tx pgx.Tx, userID *uuid.UUID, account, kind, asset, amount, reference string) (Entry, error) {
+	if tx == nil {
+		return Entry{}, fmt.Errorf("db not configured")
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock_shared($1)`, appendLock); err != nil {
+		return Entry{}, err
+	}
+	return scanEntry(tx.QueryRow(ctx, `
+INSERT INTO ledger_entries (user_id, account, kind, asset, amount, reference)
+VALUES ($1, $2, $3, $4, $5::numeric, NULLIF($6, ''))
+RETURNING `+entryColumns, userID, account, kind, asset, amount, reference))
+}
+
+// UserEntries returns a user's entries with seq <= maxSeq, oldest first.
+func UserEntries(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, maxSeq int64) ([]Entry, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+entryColumns+`
+FROM ledger_entries
+WHERE user_id = $1 AND seq <= $2
+ORDER BY seq
+`, userID, maxSeq)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// leafHashes loads every entry with seq <= maxSeq in order and returns their
+// leaf hashes plus a seq -> index map.
+func leafHashes(ctx context.Context, pool *pgxpool.Pool, maxSeq int64) ([][]byte, map[int64]int, error) {
+	rows, err := pool.Query(ctx, `
+SELECT `+entryColumns+`
+FROM ledger_entries
+WHERE seq <= $1
+ORDER BY seq
+`, maxSeq)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var leaves [][]byte
+	index := map[int64]int{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		index[e.Seq] = len(leaves)
+		leaves = append(leaves, HashLeaf(e.LeafData()))
+	}
+	return leaves, index, rows.Err()
+}
@@ -0,0 +1,104 @@
+package ledger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Domain-separated hashing (as in RFC 6962) so a leaf can never be passed off
+// as an inner node.
+const (
+	leafPrefix byte = 0x00
+	nodePrefix byte = 0x01
+)
+
+func HashLeaf(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func hashNode(l, r []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(l)
+	h.Write(r)
+	return h.Sum(nil)
+}
+
+// ProofStep is one sibling on the path from a leaf to the root.
+// Left reports whether the sibling sits to the left of the running hash.
+type ProofStep struct {
+	Hash string `json:"hash"`
+	Left bool   `json:"left"`
+}
+
+// Tree is a binary Merkle tree over leaf hashes. A node without a sibling is
+// promoted to the next level unchanged.
+type Tree struct {
+	levels [][][]byte
+}
+
+func NewTree(leaves [][]byte) *Tree {
+	t := &Tree{levels: [][][]byte{leaves}}
+	for cur := leaves; len(cur) > 1; {
+		next := make([][]byte, 0, (len(cur)+1)/2)
+		for i := 0; i < len(cur); i += 2 {
+			if i+1 == len(cur) {
+				next = append(next, cur[i])
+				continue
+			}
+			next = append(next, hashNode(cur[i], cur[i+1]))
+		}
+		t.levels = append(t.levels, next)
+		cur = next
+	}
+	return t
+}
+
+// Root returns the hex root; an empty tree has the hash of no data.
+func (t *Tree) Root() string {
+	top := t.levels[len(t.levels)-1]
+	if len(top) == 0 {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:])
+	}
+	return hex.EncodeToString(top[0])
+}
+
+func (t *Tree) Len() int { return len(t.levels[0]) }
+
+// Proof returns the inclusion path for leaf i.
+func (t *Tree) Proof(i int) ([]ProofStep, error) {
+	if i < 0 || i >= t.Len() {
+		return nil, fmt.Errorf("leaf index %d out of range", i)
+	}
+	var steps []ProofStep
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sib := i ^ 1
+		if sib < len(level) {
+			steps = append(steps, ProofStep{Hash: hex.EncodeToString(level[sib]), Left: sib < i})
+		}
+		i /= 2
+	}
+	return steps, nil
+}
+
+// VerifyProof recomputes the root from a leaf hash and its path.
+func VerifyProof(leaf []byte, steps []ProofStep, root string) bool {
+	cur := leaf
+	for _, s := range steps {
+		sib, err := hex.DecodeString(s.Hash)
+		if err != nil || len(sib) != sha256.Size {
+			return false
+		}
+		if s.Left {
+			cur = hashNode(sib, cur)
+		} else {
+			cur = hashNode(cur, sib)
+		}
+	}
+	return hex.EncodeToString(cur) == root
+}
@@ -0,0 +1,37 @@
+package ledger
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProofsVerifyForAllSizes(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		var leaves [][]byte
+		for i := 0; i < n; i++ {
+			leaves = append(leaves, HashLeaf([]byte(fmt.Sprintf("entry-%d", i))))
+		}
+		tree := NewTree(leaves)
+		root := tree.Root()
+		for i := range leaves {
+			path, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("n=%d i=%d: %v", n, i, err)
+			}
+			if !VerifyProof(leaves[i], path, root) {
+				t.Fatalf("n=%d i=%d: proof did not verify", n, i)
+			}
+			if VerifyProof(HashLeaf([]byte("forged")), path, root) {
+				t.Fatalf("n=%d i=%d: forged leaf verified", n, i)
+			}
+		}
+	}
+}
+
+func TestRootChangesWhenEntryChanges(t *testing.T) {
+	a := NewTree([][]byte{HashLeaf([]byte("a")), HashLeaf([]byte("b")), HashLeaf([]byte("c"))}).Root()
+	b := NewTree([][]byte{HashLeaf([]byte("a")), HashLeaf([]byte("B")), HashLeaf([]byte("c"))}).Root()
+	if a == b {
+		t.Fatal("root did not change")
+	}
+}
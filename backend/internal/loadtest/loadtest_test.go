@@ -0,0 +1,73 @@
+package loadtest
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAnonymizePath(t *testing.T) {
+	cases := map[string]string{
+		"/projects/3f2504e0-4f89-11d3-9a0c-0305e82c3301":                    "/projects/{uuid}",
+		"/users/0x52908400098527886E0F7030069857D2E4169EEE/x":               "/users/{address}/x",
+		"/wallets/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H": "/wallets/{stellar}",
+		"/me":        "/me",
+		"/issues/42": "/issues/42",
+	}
+	for in, want := range cases {
+		if got := AnonymizePath(in); got != want {
+			t.Errorf("AnonymizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAnonymizeQuery(t *testing.T) {
+	got, _ := url.ParseQuery(AnonymizeQuery("q=alice&limit=20&status=open&owner=3f2504e0-4f89-11d3-9a0c-0305e82c3301"))
+	if got.Get("q") != PlaceholderValue || got.Get("owner") != PlaceholderValue {
+		t.Fatalf("identifying values kept: %v", got)
+	}
+	if got.Get("limit") != "20" || got.Get("status") != "open" {
+		t.Fatalf("allowed values dropped: %v", got)
+	}
+}
+
+func TestSummarizeAndCompare(t *testing.T) {
+	var ds []time.Duration
+	for i := 1; i <= 100; i++ {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+	s := summarize(ds, 0)
+	if s.P50Ms != 50 || s.P95Ms != 95 || s.P99Ms != 99 || s.MaxMs != 100 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	base := Report{Routes: map[string]RouteStats{
+		"GET /me":           {Count: 100, P95Ms: 10},
+		"POST /auth/verify": {Count: 100, P95Ms: 40},
+		"GET /rare":         {Count: 2, P95Ms: 1},
+	}}
+	cur := Report{Routes: map[string]RouteStats{
+		"GET /me":           {Count: 100, P95Ms: 20},
+		"POST /auth/verify": {Count: 100, P95Ms: 42},
+		"GET /rare":         {Count: 2, P95Ms: 500},
+	}}
+	regs := Compare(base, cur, 0.2, 5, 20)
+	if len(regs) != 1 || regs[0].Route != "GET /me" {
+		t.Fatalf("regressions = %+v", regs)
+	}
+}
+
+func TestVerifyBench(t *testing.T) {
+	stats, err := VerifyBench(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2*len(signers) {
+		t.Fatalf("got %d stats, want fresh and cached for %d wallet types", len(stats), len(signers))
+	}
+	for _, s := range stats {
+		if s.Count != 3 || s.OpsPerSec <= 0 {
+			t.Fatalf("bad stats %+v", s)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package loadtest records anonymized API traffic and replays it against a
+// local instance to measure per-route latency.
+package loadtest
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Entry is one recorded request. Identifiers in Path and Query are replaced
+// with placeholders such as {uuid}; bodies and credentials are never kept.
+type Entry struct {
+	// OffsetMs is the time since recording started.
+	OffsetMs   int64  `json:"offset_ms"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Query      string `json:"query,omitempty"`
+	Auth       bool   `json:"auth,omitempty"`
+	Status     int    `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Placeholders substituted for identifiers, in match order.
+const (
+	PlaceholderUUID    = "{uuid}"
+	PlaceholderAddress = "{address}"
+	PlaceholderStellar = "{stellar}"
+	PlaceholderHex     = "{hex}"
+	PlaceholderValue   = "{value}"
+)
+
+var (
+	uuidRe    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	addressRe = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	stellarRe = regexp.MustCompile(`^[GC][A-Z2-7]{55}$`)
+	hexRe     = regexp.MustCompile(`^(0x)?[0-9a-fA-F]{32,}$`)
+)
+
+// Query parameters whose values are kept verbatim; every other value is
+// replaced with {value}.
+var keptQueryParams = map[string]bool{
+	"page": true, "limit": true, "offset": true, "per_page": true,
+	"sort": true, "order": true, "status": true, "state": true,
+	"chain": true, "ecosystem": true, "language": true,
+}
+
+func anonymizeSegment(s string) string {
+	switch {
+	case uuidRe.MatchString(s):
+		return PlaceholderUUID
+	case addressRe.MatchString(s):
+		return PlaceholderAddress
+	case stellarRe.MatchString(s):
+		return PlaceholderStellar
+	case hexRe.MatchString(s):
+		return PlaceholderHex
+	}
+	return s
+}
+
+// AnonymizePath replaces identifier-looking path segments with placeholders.
+func AnonymizePath(path string) string {
+	segs := strings.Split(path, "/")
+	for i, s := range segs {
+		segs[i] = anonymizeSegment(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+// AnonymizeQuery keeps the keys of a raw query string but drops any value
+// that isn't a number or on the allow list.
+func AnonymizeQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		return ""
+	}
+	for k, vs := range q {
+		for i, v := range vs {
+			if keptQueryParams[k] && anonymizeSegment(v) == v {
+				continue
+			}
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				continue
+			}
+			vs[i] = PlaceholderValue
+		}
+	}
+	return q.Encode()
+}
+
+// Recorder appends anonymized entries as JSON lines to W.
+type Recorder struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, start: time.Now()}
+}
+
+// Record writes one entry; write errors are logged, never surfaced.
+func (r *Recorder) Record(e Entry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(append(b, '\n')); err != nil {
+		slog.Warn("loadtest record failed", "error", err)
+	}
+}
+
+// Middleware records every request except webhooks, metrics and profiling.
+func (r *Recorder) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+		if strings.HasPrefix(path, "/webhooks/") || strings.HasPrefix(path, "/debug/") || path == "/metrics" {
+			return c.Next()
+		}
+		started := time.Now()
+		err := c.Next()
+		status := c.Response().StatusCode()
+		if err != nil {
+			if fe, ok := err.(*fiber.Error); ok {
+				status = fe.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+		r.Record(Entry{
+			OffsetMs:   started.Sub(r.start).Milliseconds(),
+			Method:     c.Method(),
+			Path:       AnonymizePath(path),
+			Query:      AnonymizeQuery(string(c.Request().URI().QueryString())),
+			Auth:       c.Get(fiber.HeaderAuthorization) != "",
+			Status:     status,
+			DurationMs: time.Since(started).Milliseconds(),
+		})
+		return err
+	}
+}
+
+// ReadEntries decodes a JSON-lines recording.
+func ReadEntries(r io.Reader) ([]Entry, error) {
+	dec := json.NewDecoder(r)
+	var out []Entry
+	for {
+		var e Entry
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, e)
+	}
+}
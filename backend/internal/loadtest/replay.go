@@ -0,0 +1,247 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultFixtures fill placeholders on replay. They rarely exist locally, so
+// those requests exercise the not-found paths unless overridden.
+var DefaultFixtures = map[string]string{
+	PlaceholderUUID:    "00000000-0000-0000-0000-000000000000",
+	PlaceholderAddress: "0x0000000000000000000000000000000000000000",
+	PlaceholderStellar: "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
+	PlaceholderHex:     "00000000000000000000000000000000",
+	PlaceholderValue:   "x",
+}
+
+type Options struct {
+	BaseURL string
+	// Concurrency caps in-flight requests (default 8).
+	Concurrency int
+	// Speed scales the recorded pacing (2 = twice as fast); 0 sends as fast
+	// as Concurrency allows.
+	Speed float64
+	// Fixtures override DefaultFixtures.
+	Fixtures map[string]string
+	// Login signs in a load-test wallet. When set, recorded logins replay as
+	// live nonce+verify round trips timed under "POST /auth/verify", and
+	// authenticated requests carry the token from an initial login. Without
+	// it both are skipped.
+	Login  func(ctx context.Context) (string, error)
+	Client *http.Client
+}
+
+// RouteStats are latencies for one "METHOD path" key.
+type RouteStats struct {
+	Count  int     `json:"count"`
+	Errors int     `json:"errors"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+type Report struct {
+	Routes  map[string]RouteStats `json:"routes"`
+	Skipped int                   `json:"skipped"`
+	Elapsed time.Duration         `json:"elapsed"`
+}
+
+// Replay sends entries to opts.BaseURL and reports per-route latency.
+// Requests answered with 5xx, or not answered, count as errors.
+func Replay(ctx context.Context, entries []Entry, opts Options) (Report, error) {
+	base := strings.TrimRight(opts.BaseURL, "/")
+	if base == "" {
+		return Report{}, fmt.Errorf("base url is required")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	fixtures := map[string]string{}
+	for k, v := range DefaultFixtures {
+		fixtures[k] = v
+	}
+	for k, v := range opts.Fixtures {
+		fixtures[k] = v
+	}
+
+	var token string
+	if opts.Login != nil {
+		t, err := opts.Login(ctx)
+		if err != nil {
+			return Report{}, fmt.Errorf("initial login: %w", err)
+		}
+		token = t
+	}
+
+	var (
+		mu        sync.Mutex
+		durations = map[string][]time.Duration{}
+		errs      = map[string]int{}
+		skipped   int
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, opts.Concurrency)
+	)
+	observe := func(key string, d time.Duration, failed bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		durations[key] = append(durations[key], d)
+		if failed {
+			errs[key]++
+		}
+	}
+
+	start := time.Now()
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		login := e.Method == http.MethodPost && e.Path == "/auth/verify"
+		switch {
+		case e.Method == http.MethodPost && e.Path == "/auth/nonce":
+			// Part of the live login replayed for /auth/verify.
+			skipped++
+			continue
+		case (login || e.Auth) && opts.Login == nil:
+			skipped++
+			continue
+		}
+		if opts.Speed > 0 {
+			at := start.Add(time.Duration(float64(e.OffsetMs)/opts.Speed) * time.Millisecond)
+			select {
+			case <-time.After(time.Until(at)):
+			case <-ctx.Done():
+			}
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(e Entry) {
+			defer func() { <-sem; wg.Done() }()
+			key := e.Method + " " + e.Path
+			started := time.Now()
+			if login {
+				_, err := opts.Login(ctx)
+				observe(key, time.Since(started), err != nil)
+				return
+			}
+			url := base + fill(e.Path, fixtures)
+			if e.Query != "" {
+				url += "?" + fill(e.Query, fixtures)
+			}
+			req, err := http.NewRequestWithContext(ctx, e.Method, url, nil)
+			if err != nil {
+				observe(key, 0, true)
+				return
+			}
+			if e.Auth {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				observe(key, time.Since(started), true)
+				return
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			observe(key, time.Since(started), resp.StatusCode >= 500)
+		}(e)
+	}
+	wg.Wait()
+
+	r := Report{Routes: map[string]RouteStats{}, Skipped: skipped, Elapsed: time.Since(start)}
+	for key, ds := range durations {
+		r.Routes[key] = summarize(ds, errs[key])
+	}
+	return r, ctx.Err()
+}
+
+func fill(s string, fixtures map[string]string) string {
+	for k, v := range fixtures {
+		s = strings.ReplaceAll(s, k, v)
+	}
+	return s
+}
+
+func summarize(ds []time.Duration, errors int) RouteStats {
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	return RouteStats{
+		Count:  len(ds),
+		Errors: errors,
+		P50Ms:  ms(percentile(ds, 50)),
+		P95Ms:  ms(percentile(ds, 95)),
+		P99Ms:  ms(percentile(ds, 99)),
+		MaxMs:  ms(ds[len(ds)-1]),
+	}
+}
+
+// percentile uses the nearest-rank method on sorted ds.
+func percentile(ds []time.Duration, p int) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	rank := (p*len(ds) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return ds[rank-1]
+}
+
+// Regression is a route whose p95 grew past the allowed tolerance.
+type Regression struct {
+	Route    string  `json:"route"`
+	BaseP95  float64 `json:"baseline_p95_ms"`
+	P95      float64 `json:"p95_ms"`
+	BaseErrs int     `json:"baseline_errors"`
+	Errs     int     `json:"errors"`
+}
+
+// Compare flags routes seen at least minCount times in both reports whose
+// p95 exceeds the baseline by more than tolerance (0.2 = 20%) and minDeltaMs,
+// or that now fail where the baseline didn't.
+func Compare(baseline, current Report, tolerance, minDeltaMs float64, minCount int) []Regression {
+	var out []Regression
+	for route, cur := range current.Routes {
+		base, ok := baseline.Routes[route]
+		if !ok || base.Count < minCount || cur.Count < minCount {
+			continue
+		}
+		slower := cur.P95Ms > base.P95Ms*(1+tolerance) && cur.P95Ms-base.P95Ms > minDeltaMs
+		failing := cur.Errors > 0 && base.Errors == 0
+		if slower || failing {
+			out = append(out, Regression{Route: route, BaseP95: base.P95Ms, P95: cur.P95Ms, BaseErrs: base.Errors, Errs: cur.Errors})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Route < out[j].Route })
+	return out
+}
+
+// WriteText prints the report as a table sorted by route.
+func (r Report) WriteText(w io.Writer) error {
+	routes := make([]string, 0, len(r.Routes))
+	for route := range r.Routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROUTE\tCOUNT\tERRORS\tP50_MS\tP95_MS\tP99_MS\tMAX_MS")
+	for _, route := range routes {
+		s := r.Routes[route]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.1f\t%.1f\t%.1f\n", route, s.Count, s.Errors, s.P50Ms, s.P95Ms, s.P99Ms, s.MaxMs)
+	}
+	fmt.Fprintf(tw, "\nskipped %d entries, elapsed %s\n", r.Skipped, r.Elapsed.Round(time.Millisecond))
+	return tw.Flush()
+}
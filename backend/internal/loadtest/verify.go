@@ -0,0 +1,158 @@
+package loadtest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stellar/go/strkey"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+// VerifyStats is the latency of wallet signature checks of one wallet type.
+// Mode is "fresh" for signatures seen for the first time and "cached" for a
+// repeated one.
+type VerifyStats struct {
+	WalletType string  `json:"wallet_type"`
+	Mode       string  `json:"mode"`
+	Count      int     `json:"count"`
+	P50Us      float64 `json:"p50_us"`
+	P99Us      float64 `json:"p99_us"`
+	OpsPerSec  float64 `json:"ops_per_sec"`
+}
+
+type signedLogin struct {
+	address, message, signature, publicKey string
+}
+
+// signer produces a login signed by a new key of its wallet type. Solana
+// signatures are the same ed25519 check as Stellar's.
+type signer func(nonce string) (signedLogin, error)
+
+var signers = map[auth.WalletType]signer{
+	auth.WalletTypeEVM: func(nonce string) (signedLogin, error) {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return signedLogin{}, err
+		}
+		msg := auth.LoginMessage(nonce)
+		sig, err := crypto.Sign(accounts.TextHash([]byte(msg)), key)
+		if err != nil {
+			return signedLogin{}, err
+		}
+		sig[64] += 27
+		addr := strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+		return signedLogin{address: addr, message: msg, signature: "0x" + hex.EncodeToString(sig)}, nil
+	},
+	auth.WalletTypeStellarEd25519: func(nonce string) (signedLogin, error) {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return signedLogin{}, err
+		}
+		addr, err := strkey.Encode(strkey.VersionByteAccountID, pub)
+		if err != nil {
+			return signedLogin{}, err
+		}
+		msg := auth.LoginMessage(nonce)
+		return signedLogin{address: addr, message: msg, signature: hex.EncodeToString(ed25519.Sign(priv, []byte(msg)))}, nil
+	},
+	auth.WalletTypeStellarSecp256k1: func(nonce string) (signedLogin, error) {
+		key, err := secp256k1.GeneratePrivateKey()
+		if err != nil {
+			return signedLogin{}, err
+		}
+		msg := auth.LoginMessage(nonce)
+		h := sha256.Sum256([]byte(msg))
+		pub := hex.EncodeToString(key.PubKey().SerializeCompressed())
+		return signedLogin{address: pub, message: msg, signature: hex.EncodeToString(ecdsa.Sign(key, h[:]).Serialize()), publicKey: pub}, nil
+	},
+}
+
+// VerifyBench checks n freshly signed logins per wallet type in process, then
+// one of them n more times, which the verification cache answers. It measures
+// the CPU cost of a login storm without a database or network in the way.
+func VerifyBench(n int) ([]VerifyStats, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("n must be positive")
+	}
+	types := make([]string, 0, len(signers))
+	for t := range signers {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+
+	out := []VerifyStats{}
+	for _, name := range types {
+		t := auth.WalletType(name)
+		logins := make([]signedLogin, n)
+		for i := range logins {
+			l, err := signers[t](fmt.Sprintf("bench%011d", i))
+			if err != nil {
+				return nil, err
+			}
+			logins[i] = l
+		}
+		fresh, err := timeVerify(t, logins)
+		if err != nil {
+			return nil, err
+		}
+		repeated := make([]signedLogin, n)
+		for i := range repeated {
+			repeated[i] = logins[0]
+		}
+		cached, err := timeVerify(t, repeated)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, verifyStats(name, "fresh", fresh), verifyStats(name, "cached", cached))
+	}
+	return out, nil
+}
+
+func timeVerify(t auth.WalletType, logins []signedLogin) ([]time.Duration, error) {
+	ds := make([]time.Duration, 0, len(logins))
+	for _, l := range logins {
+		start := time.Now()
+		if err := auth.VerifySignature(t, l.address, l.message, l.signature, l.publicKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		ds = append(ds, time.Since(start))
+	}
+	return ds, nil
+}
+
+func verifyStats(walletType, mode string, ds []time.Duration) VerifyStats {
+	var total time.Duration
+	for _, d := range ds {
+		total += d
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	us := func(d time.Duration) float64 { return float64(d.Nanoseconds()) / 1000 }
+	s := VerifyStats{WalletType: walletType, Mode: mode, Count: len(ds), P50Us: us(percentile(ds, 50)), P99Us: us(percentile(ds, 99))}
+	if total > 0 {
+		s.OpsPerSec = float64(len(ds)) / total.Seconds()
+	}
+	return s
+}
+
+// WriteVerifyText writes stats as a table.
+func WriteVerifyText(w io.Writer, stats []VerifyStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "WALLET_TYPE\tMODE\tCOUNT\tP50_US\tP99_US\tOPS_PER_SEC")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.1f\t%.1f\t%.0f\n", s.WalletType, s.Mode, s.Count, s.P50Us, s.P99Us, s.OpsPerSec)
+	}
+	return tw.Flush()
+}
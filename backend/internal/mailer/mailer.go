@@ -0,0 +1,86 @@
+// Package mailer sends plain-text transactional email (verification codes,
+// account recovery notices, user notifications) over SMTP.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidHeader = errors.New("invalid_mail_header")
+
+// Mailer delivers through one SMTP relay. A nil *Mailer is "not configured".
+type Mailer struct {
+	Addr     string
+	Username string
+	Password string
+	From     mail.Address
+}
+
+// New returns a Mailer for host:port, or nil when host or from is empty.
+func New(host string, port int, username, password, from string) (*Mailer, error) {
+	host, from = strings.TrimSpace(host), strings.TrimSpace(from)
+	if host == "" || from == "" {
+		return nil, nil
+	}
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	if port <= 0 {
+		port = 587
+	}
+	return &Mailer{
+		Addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Username: username,
+		Password: password,
+		From:     *addr,
+	}, nil
+}
+
+// Message renders a plain-text message. Header values may not contain line
+// breaks, so user input can't inject headers.
+func Message(from mail.Address, to, subject, body string, now time.Time) ([]byte, error) {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return nil, ErrInvalidHeader
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
+
+// Send delivers one message. SMTP has no context support, so ctx is only
+// checked before dialing.
+func (m *Mailer) Send(ctx context.Context, to, subject, body string) error {
+	if m == nil {
+		return fmt.Errorf("mailer not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	msg, err := Message(m.From, to, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+	var a smtp.Auth
+	if m.Username != "" {
+		host, _, _ := net.SplitHostPort(m.Addr)
+		a = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	return smtp.SendMail(m.Addr, a, m.From.Address, []string{to}, msg)
+}
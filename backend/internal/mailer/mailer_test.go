@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessage(t *testing.T) {
+	from := mail.Address{Name: "Grainlify", Address: "no-reply@grainlify.test"}
+	msg, err := Message(from, "user@example.com", "Your code", "line one\nline two", time.Unix(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(msg)
+	for _, want := range []string{
+		"From: \"Grainlify\" <no-reply@grainlify.test>\r\n",
+		"To: user@example.com\r\n",
+		"Subject: Your code\r\n",
+		"\r\n\r\nline one\r\nline two",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("message missing %q:\n%s", want, s)
+		}
+	}
+
+	if _, err := Message(from, "user@example.com\r\nBcc: x@example.com", "hi", "", time.Now()); !errors.Is(err, ErrInvalidHeader) {
+		t.Fatalf("expected ErrInvalidHeader, got %v", err)
+	}
+}
+
+func TestNewUnconfigured(t *testing.T) {
+	m, err := New("", 0, "", "", "no-reply@grainlify.test")
+	if m != nil || err != nil {
+		t.Fatalf("New without host = %v, %v", m, err)
+	}
+	if _, err := New("smtp.example.com", 0, "", "", "not an address"); err == nil {
+		t.Fatal("expected invalid from address error")
+	}
+}
@@ -0,0 +1,331 @@
+// Package messaging carries end-to-end encrypted messages between a bounty's
+// maintainer and its claimant, for repro details or credentials that
+// shouldn't sit in a public issue.
+//
+// Each user publishes an X25519 public key, vouched for by a signature from
+// one of their linked wallets over KeyMessage. Clients fetch the other side's
+// key, check that signature themselves and encrypt with NaCl box
+// (X25519-XSalsa20-Poly1305); the server only ever sees and stores the nonce
+// and ciphertext.
+package messaging
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+)
+
+// Sizes of NaCl box keys, nonces and authenticators, and the largest
+// ciphertext accepted.
+const (
+	KeySize       = 32
+	NonceSize     = 24
+	overhead      = 16
+	MaxCiphertext = 64 << 10
+	// MaxPage is the most messages returned at once.
+	MaxPage = 200
+)
+
+var (
+	ErrInvalidKey        = errors.New("invalid_messaging_key")
+	ErrBadKeySignature   = errors.New("invalid_messaging_key_signature")
+	ErrNoKey             = errors.New("messaging_key_not_found")
+	ErrNoRecipientKey    = errors.New("recipient_has_no_messaging_key")
+	ErrKeyChanged        = errors.New("messaging_key_changed")
+	ErrNotClaimed        = errors.New("bounty_not_claimed")
+	ErrNotParticipant    = errors.New("not_thread_participant")
+	ErrInvalidNonce      = errors.New("invalid_nonce")
+	ErrInvalidCiphertext = errors.New("invalid_ciphertext")
+)
+
+// KeyMessage is what a wallet signs to vouch for a user's messaging key.
+func KeyMessage(userID uuid.UUID, key string) string {
+	return "Grainlify messaging key\n" +
+		"User: " + userID.String() + "\n" +
+		"Key: " + key
+}
+
+// ParseKey checks that key is a base64 X25519 public key and returns it in
+// standard padded base64.
+func ParseKey(key string) (string, error) {
+	raw, err := decodeBase64(key)
+	if err != nil || len(raw) != KeySize {
+		return "", ErrInvalidKey
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if raw, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return raw, nil
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// Key is a user's published messaging key and the wallet signature vouching
+// for it, which readers should verify against KeyMessage before encrypting.
+type Key struct {
+	UserID          uuid.UUID       `json:"user_id"`
+	Key             string          `json:"key"`
+	WalletType      auth.WalletType `json:"wallet_type"`
+	Address         string          `json:"address"`
+	Signature       string          `json:"signature"`
+	WalletPublicKey string          `json:"wallet_public_key,omitempty"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// Publication is a request to publish a messaging key: the key and a
+// signature over its KeyMessage by one of the user's wallets.
+type Publication struct {
+	Key        string `json:"key"`
+	WalletType string `json:"wallet_type"`
+	Address    string `json:"address"`
+	Signature  string `json:"signature"`
+	// PublicKey is the wallet's public key, for wallet types that need it
+	// to verify signatures.
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// Publish sets userID's messaging key, replacing any earlier one. Messages
+// already sent stay readable with the keys they record.
+func Publish(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, p Publication) (Key, error) {
+	if pool == nil {
+		return Key{}, fmt.Errorf("db not configured")
+	}
+	key, err := ParseKey(p.Key)
+	if err != nil {
+		return Key{}, err
+	}
+	wt, err := auth.NormalizeWalletType(p.WalletType)
+	if err != nil {
+		return Key{}, ErrBadKeySignature
+	}
+	addr, err := auth.NormalizeAddress(wt, p.Address)
+	if err != nil {
+		return Key{}, ErrBadKeySignature
+	}
+	var walletID uuid.UUID
+	err = pool.QueryRow(ctx, `
+SELECT id FROM wallets WHERE user_id = $1 AND wallet_type = $2 AND address = $3
+`, userID, string(wt), addr).Scan(&walletID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Key{}, auth.ErrWalletNotFound
+	}
+	if err != nil {
+		return Key{}, err
+	}
+	// Clients may have signed the key as they had it, padded or not.
+	if auth.VerifySignature(wt, addr, KeyMessage(userID, key), p.Signature, p.PublicKey) != nil &&
+		auth.VerifySignature(wt, addr, KeyMessage(userID, strings.TrimSpace(p.Key)), p.Signature, p.PublicKey) != nil {
+		return Key{}, ErrBadKeySignature
+	}
+
+	if _, err := pool.Exec(ctx, `
+INSERT INTO messaging_keys (user_id, wallet_id, public_key, signature, wallet_public_key)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE SET
+  wallet_id = EXCLUDED.wallet_id,
+  public_key = EXCLUDED.public_key,
+  signature = EXCLUDED.signature,
+  wallet_public_key = EXCLUDED.wallet_public_key,
+  updated_at = now()
+`, userID, walletID, key, strings.TrimSpace(p.Signature), strings.TrimSpace(p.PublicKey)); err != nil {
+		return Key{}, err
+	}
+	return GetKey(ctx, pool, userID)
+}
+
+// GetKey returns userID's published messaging key.
+func GetKey(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (Key, error) {
+	if pool == nil {
+		return Key{}, fmt.Errorf("db not configured")
+	}
+	k := Key{UserID: userID}
+	var wt string
+	err := pool.QueryRow(ctx, `
+SELECT k.public_key, w.wallet_type, w.address, k.signature, k.wallet_public_key, k.updated_at
+FROM messaging_keys k
+JOIN wallets w ON w.id = k.wallet_id
+WHERE k.user_id = $1
+`, userID).Scan(&k.Key, &wt, &k.Address, &k.Signature, &k.WalletPublicKey, &k.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Key{}, ErrNoKey
+	}
+	k.WalletType = auth.WalletType(wt)
+	return k, err
+}
+
+// DeleteKey withdraws userID's messaging key, so nobody can send them new
+// messages until they publish another.
+func DeleteKey(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM messaging_keys WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoKey
+	}
+	return nil
+}
+
+// Message is one encrypted message in a claim's thread. It records the keys
+// it was encrypted between, so both sides can still open it after rotating.
+type Message struct {
+	ID           uuid.UUID `json:"id"`
+	BountyID     uuid.UUID `json:"bounty_id"`
+	ClaimantID   uuid.UUID `json:"claimant_id"`
+	SenderID     uuid.UUID `json:"sender_id"`
+	RecipientID  uuid.UUID `json:"recipient_id"`
+	SenderKey    string    `json:"sender_key"`
+	RecipientKey string    `json:"recipient_key"`
+	Nonce        string    `json:"nonce"`
+	Ciphertext   string    `json:"ciphertext"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Draft is a message as sent by a client. SenderKey and RecipientKey are the
+// keys the client encrypted between; they must still be the published ones.
+type Draft struct {
+	SenderKey    string `json:"sender_key"`
+	RecipientKey string `json:"recipient_key"`
+	Nonce        string `json:"nonce"`
+	Ciphertext   string `json:"ciphertext"`
+}
+
+// Check validates the shape of d; the server can't check its contents.
+func (d Draft) Check() error {
+	if _, err := ParseKey(d.SenderKey); err != nil {
+		return err
+	}
+	if _, err := ParseKey(d.RecipientKey); err != nil {
+		return err
+	}
+	if nonce, err := decodeBase64(d.Nonce); err != nil || len(nonce) != NonceSize {
+		return ErrInvalidNonce
+	}
+	if ct, err := decodeBase64(d.Ciphertext); err != nil || len(ct) <= overhead || len(ct) > MaxCiphertext {
+		return ErrInvalidCiphertext
+	}
+	return nil
+}
+
+// Counterpart returns who userID talks to about b's current claim: the
+// claimant for the project's owner, and the owner for the claimant.
+func Counterpart(b bounties.Bounty, owner, userID uuid.UUID) (uuid.UUID, error) {
+	if b.Claim == nil {
+		return uuid.Nil, ErrNotClaimed
+	}
+	switch userID {
+	case owner:
+		return b.Claim.UserID, nil
+	case b.Claim.UserID:
+		return owner, nil
+	}
+	return uuid.Nil, ErrNotParticipant
+}
+
+// Send stores a message from sender about b's current claim, after checking
+// it was encrypted between both sides' current keys.
+func Send(ctx context.Context, pool *pgxpool.Pool, b bounties.Bounty, owner, sender uuid.UUID, d Draft) (Message, error) {
+	if pool == nil {
+		return Message{}, fmt.Errorf("db not configured")
+	}
+	recipient, err := Counterpart(b, owner, sender)
+	if err != nil {
+		return Message{}, err
+	}
+	if err := d.Check(); err != nil {
+		return Message{}, err
+	}
+	senderKey, err := GetKey(ctx, pool, sender)
+	if err != nil {
+		return Message{}, err
+	}
+	recipientKey, err := GetKey(ctx, pool, recipient)
+	if errors.Is(err, ErrNoKey) {
+		return Message{}, ErrNoRecipientKey
+	}
+	if err != nil {
+		return Message{}, err
+	}
+	sk, _ := ParseKey(d.SenderKey)
+	rk, _ := ParseKey(d.RecipientKey)
+	if sk != senderKey.Key || rk != recipientKey.Key {
+		return Message{}, ErrKeyChanged
+	}
+
+	nonce, _ := decodeBase64(d.Nonce)
+	ct, _ := decodeBase64(d.Ciphertext)
+	m := Message{
+		BountyID:     b.ID,
+		ClaimantID:   b.Claim.UserID,
+		SenderID:     sender,
+		RecipientID:  recipient,
+		SenderKey:    sk,
+		RecipientKey: rk,
+		Nonce:        base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext:   base64.StdEncoding.EncodeToString(ct),
+	}
+	err = pool.QueryRow(ctx, `
+INSERT INTO claim_messages (bounty_id, claimant_id, sender_id, recipient_id, sender_key, recipient_key, nonce, ciphertext)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at
+`, m.BountyID, m.ClaimantID, m.SenderID, m.RecipientID, m.SenderKey, m.RecipientKey, m.Nonce, m.Ciphertext).Scan(&m.ID, &m.CreatedAt)
+	return m, err
+}
+
+// List returns the messages of the thread between a bounty's maintainer and
+// claimantID, oldest first, after since when it is set.
+func List(ctx context.Context, pool *pgxpool.Pool, bountyID, claimantID uuid.UUID, since *time.Time, limit int) ([]Message, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	if limit <= 0 || limit > MaxPage {
+		limit = MaxPage
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, bounty_id, claimant_id, sender_id, recipient_id, sender_key, recipient_key, nonce, ciphertext, created_at
+FROM claim_messages
+WHERE bounty_id = $1 AND claimant_id = $2 AND ($3::timestamptz IS NULL OR created_at > $3)
+ORDER BY created_at, id
+LIMIT $4
+`, bountyID, claimantID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Message{}
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.ID, &m.BountyID, &m.ClaimantID, &m.SenderID, &m.RecipientID, &m.SenderKey, &m.RecipientKey, &m.Nonce, &m.Ciphertext, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// HasThread reports whether userID has a thread on bountyID as its claimant,
+// which lets an earlier claimant keep reading theirs.
+func HasThread(ctx context.Context, pool *pgxpool.Pool, bountyID, userID uuid.UUID) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM claim_messages WHERE bounty_id = $1 AND claimant_id = $2)`, bountyID, userID).Scan(&ok)
+	return ok, err
+}
@@ -0,0 +1,81 @@
+package messaging
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+)
+
+func b64(n int) string {
+	return base64.StdEncoding.EncodeToString(make([]byte, n))
+}
+
+func TestParseKey(t *testing.T) {
+	raw := base64.RawStdEncoding.EncodeToString(make([]byte, KeySize))
+	got, err := ParseKey(raw)
+	if err != nil || got != b64(KeySize) {
+		t.Fatalf("ParseKey(unpadded) = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "not base64!", b64(31), b64(33)} {
+		if _, err := ParseKey(bad); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ParseKey(%q) = %v, want ErrInvalidKey", bad, err)
+		}
+	}
+}
+
+func TestKeyMessageBindsUserAndKey(t *testing.T) {
+	u := uuid.New()
+	msg := KeyMessage(u, "KEY")
+	if !strings.Contains(msg, u.String()) || !strings.Contains(msg, "Key: KEY") {
+		t.Fatalf("message missing user or key:\n%s", msg)
+	}
+	if KeyMessage(uuid.New(), "KEY") == msg {
+		t.Error("message doesn't change with the user")
+	}
+}
+
+func TestDraftCheck(t *testing.T) {
+	ok := Draft{SenderKey: b64(KeySize), RecipientKey: b64(KeySize), Nonce: b64(NonceSize), Ciphertext: b64(overhead + 1)}
+	if err := ok.Check(); err != nil {
+		t.Fatalf("valid draft: %v", err)
+	}
+	cases := []struct {
+		edit func(*Draft)
+		want error
+	}{
+		{func(d *Draft) { d.RecipientKey = b64(16) }, ErrInvalidKey},
+		{func(d *Draft) { d.Nonce = b64(12) }, ErrInvalidNonce},
+		{func(d *Draft) { d.Ciphertext = b64(overhead) }, ErrInvalidCiphertext},
+		{func(d *Draft) { d.Ciphertext = b64(MaxCiphertext + 1) }, ErrInvalidCiphertext},
+	}
+	for i, tc := range cases {
+		d := ok
+		tc.edit(&d)
+		if err := d.Check(); !errors.Is(err, tc.want) {
+			t.Errorf("case %d: got %v, want %v", i, err, tc.want)
+		}
+	}
+}
+
+func TestCounterpart(t *testing.T) {
+	owner, claimant, other := uuid.New(), uuid.New(), uuid.New()
+	b := bounties.Bounty{}
+	if _, err := Counterpart(b, owner, owner); !errors.Is(err, ErrNotClaimed) {
+		t.Fatalf("unclaimed: %v", err)
+	}
+	b.Claim = &bounties.Claimant{UserID: claimant}
+	if got, _ := Counterpart(b, owner, owner); got != claimant {
+		t.Errorf("owner's counterpart = %s, want claimant", got)
+	}
+	if got, _ := Counterpart(b, owner, claimant); got != owner {
+		t.Errorf("claimant's counterpart = %s, want owner", got)
+	}
+	if _, err := Counterpart(b, owner, other); !errors.Is(err, ErrNotParticipant) {
+		t.Errorf("stranger: %v", err)
+	}
+}
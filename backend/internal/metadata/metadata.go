@@ -0,0 +1,334 @@
+// Package metadata lets a project define custom fields (internal ticket
+// ids, cost centers, ...) that integrators attach to the project and its
+// bounties. Values are validated against the project's definitions when
+// they are written, and list endpoints filter on them.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Entities fields are defined for.
+const (
+	EntityProject = "project"
+	EntityBounty  = "bounty"
+)
+
+// Field types.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeEnum    = "enum"
+)
+
+const (
+	// MaxFields is how many fields a project may define per entity.
+	MaxFields      = 50
+	maxOptions     = 100
+	maxValueLength = 500
+	// maxFilters is how many metadata filters one list request may use.
+	maxFilters = 10
+)
+
+var (
+	ErrInvalidEntity   = errors.New("invalid_metadata_entity")
+	ErrInvalidField    = errors.New("invalid_metadata_field")
+	ErrTooManyFields   = errors.New("too_many_metadata_fields")
+	ErrInvalidMetadata = errors.New("invalid_metadata")
+	ErrInvalidFilter   = errors.New("invalid_metadata_filter")
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// Field is one custom field a project accepts on an entity.
+type Field struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Options     []string `json:"options,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// ValidationError says which metadata key was rejected and why.
+type ValidationError struct {
+	Key    string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("metadata %q: %s", e.Key, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error { return ErrInvalidMetadata }
+
+// ValidEntity reports whether fields can be defined for entity.
+func ValidEntity(entity string) bool {
+	return entity == EntityProject || entity == EntityBounty
+}
+
+// NormalizeFields checks field definitions and returns them cleaned up:
+// trimmed, with options only on enum fields.
+func NormalizeFields(fields []Field) ([]Field, error) {
+	if len(fields) > MaxFields {
+		return nil, ErrTooManyFields
+	}
+	out := make([]Field, 0, len(fields))
+	seen := map[string]bool{}
+	for _, f := range fields {
+		f.Key = strings.TrimSpace(f.Key)
+		f.Type = strings.ToLower(strings.TrimSpace(f.Type))
+		f.Description = strings.TrimSpace(f.Description)
+		if !keyPattern.MatchString(f.Key) || seen[f.Key] {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidField, f.Key)
+		}
+		seen[f.Key] = true
+		switch f.Type {
+		case TypeString, TypeNumber, TypeBoolean:
+			f.Options = nil
+		case TypeEnum:
+			opts := make([]string, 0, len(f.Options))
+			have := map[string]bool{}
+			for _, o := range f.Options {
+				o = strings.TrimSpace(o)
+				if o == "" || len(o) > maxValueLength || have[o] {
+					return nil, fmt.Errorf("%w: %q options", ErrInvalidField, f.Key)
+				}
+				have[o] = true
+				opts = append(opts, o)
+			}
+			if len(opts) == 0 || len(opts) > maxOptions {
+				return nil, fmt.Errorf("%w: %q options", ErrInvalidField, f.Key)
+			}
+			f.Options = opts
+		default:
+			return nil, fmt.Errorf("%w: %q type", ErrInvalidField, f.Key)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// Validate checks values against fields and returns them normalized.
+// Keys without a definition are rejected, as are missing required fields.
+// values is decoded JSON, so numbers are float64.
+func Validate(fields []Field, values map[string]any) (map[string]any, error) {
+	out := map[string]any{}
+	byKey := make(map[string]Field, len(fields))
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+	for k, v := range values {
+		f, ok := byKey[k]
+		if !ok {
+			return nil, &ValidationError{Key: k, Reason: "unknown field"}
+		}
+		if v == nil {
+			continue
+		}
+		nv, err := validateValue(f, v)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = nv
+	}
+	for _, f := range fields {
+		if _, ok := out[f.Key]; f.Required && !ok {
+			return nil, &ValidationError{Key: f.Key, Reason: "required"}
+		}
+	}
+	return out, nil
+}
+
+func validateValue(f Field, v any) (any, error) {
+	switch f.Type {
+	case TypeString, TypeEnum:
+		s, ok := v.(string)
+		if !ok {
+			return nil, &ValidationError{Key: f.Key, Reason: "must be a string"}
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil, &ValidationError{Key: f.Key, Reason: "must not be empty"}
+		}
+		if len(s) > maxValueLength {
+			return nil, &ValidationError{Key: f.Key, Reason: "too long"}
+		}
+		if f.Type == TypeEnum {
+			for _, o := range f.Options {
+				if o == s {
+					return s, nil
+				}
+			}
+			return nil, &ValidationError{Key: f.Key, Reason: "not one of the options"}
+		}
+		return s, nil
+	case TypeNumber:
+		n, ok := v.(float64)
+		if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, &ValidationError{Key: f.Key, Reason: "must be a number"}
+		}
+		return n, nil
+	case TypeBoolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, &ValidationError{Key: f.Key, Reason: "must be a boolean"}
+		}
+		return b, nil
+	}
+	return nil, &ValidationError{Key: f.Key, Reason: "unknown type"}
+}
+
+// Filters match metadata values by key. Values compare against the value's
+// text form, so 42, true and "CC-1" are matched by "42", "true" and "CC-1".
+type Filters map[string]string
+
+// ParseFilters picks the metadata.<key>=value parameters out of a query.
+func ParseFilters(query map[string]string) (Filters, error) {
+	f := Filters{}
+	for k, v := range query {
+		key, ok := strings.CutPrefix(k, "metadata.")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if !keyPattern.MatchString(key) || v == "" || len(v) > maxValueLength {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidFilter, k)
+		}
+		f[key] = v
+	}
+	if len(f) > maxFilters {
+		return nil, fmt.Errorf("%w: at most %d filters", ErrInvalidFilter, maxFilters)
+	}
+	return f, nil
+}
+
+// SQL returns a condition matching column against the filters, with
+// placeholders numbered from argPos, and its arguments. It is empty when
+// there are no filters.
+func (f Filters) SQL(column string, argPos int) (string, []any) {
+	if len(f) == 0 {
+		return "", nil
+	}
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	// Stable SQL text for the same filters.
+	sort.Strings(keys)
+	conds := make([]string, 0, len(keys))
+	args := make([]any, 0, 2*len(keys))
+	for _, k := range keys {
+		conds = append(conds, fmt.Sprintf("%s->>$%d = $%d", column, argPos, argPos+1))
+		args = append(args, k, f[k])
+		argPos += 2
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// ListFields returns the fields a project defines for entity.
+func ListFields(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, entity string) ([]Field, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT key, type, required, options, COALESCE(description, '')
+FROM metadata_fields
+WHERE project_id = $1 AND entity = $2
+ORDER BY position, key
+`, projectID, entity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Field{}
+	for rows.Next() {
+		var f Field
+		if err := rows.Scan(&f.Key, &f.Type, &f.Required, &f.Options, &f.Description); err != nil {
+			return nil, err
+		}
+		if len(f.Options) == 0 {
+			f.Options = nil
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// PutFields replaces the fields a project defines for entity. Values already
+// stored are kept; they are checked against the new definitions the next
+// time they are written.
+func PutFields(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, entity string, fields []Field) ([]Field, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	if !ValidEntity(entity) {
+		return nil, ErrInvalidEntity
+	}
+	fields, err := NormalizeFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM metadata_fields WHERE project_id = $1 AND entity = $2`, projectID, entity); err != nil {
+		return nil, err
+	}
+	for i, f := range fields {
+		opts := f.Options
+		if opts == nil {
+			opts = []string{}
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO metadata_fields (project_id, entity, key, type, required, options, description, position)
+VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
+`, projectID, entity, f.Key, f.Type, f.Required, opts, f.Description, i); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// ValidateFor validates values against the fields projectID defines for
+// entity.
+func ValidateFor(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, entity string, values map[string]any) (map[string]any, error) {
+	fields, err := ListFields(ctx, pool, projectID, entity)
+	if err != nil {
+		return nil, err
+	}
+	return Validate(fields, values)
+}
+
+// Project returns a project's metadata.
+func Project(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) (map[string]any, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	var md map[string]any
+	err := pool.QueryRow(ctx, `SELECT metadata FROM projects WHERE id = $1`, projectID).Scan(&md)
+	return md, err
+}
+
+// SetProject replaces a project's metadata with already validated values.
+func SetProject(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, values map[string]any) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	_, err := pool.Exec(ctx, `UPDATE projects SET metadata = $2, updated_at = now() WHERE id = $1`, projectID, values)
+	return err
+}
@@ -0,0 +1,97 @@
+package metadata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeFields(t *testing.T) {
+	fields, err := NormalizeFields([]Field{
+		{Key: "ticket_id", Type: " String ", Options: []string{"dropped"}},
+		{Key: "cost_center", Type: "enum", Options: []string{" CC-1 ", "CC-2"}, Required: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields[0].Type != TypeString || fields[0].Options != nil {
+		t.Fatalf("got %+v", fields[0])
+	}
+	if fields[1].Options[0] != "CC-1" {
+		t.Fatalf("got %+v", fields[1])
+	}
+
+	for _, bad := range [][]Field{
+		{{Key: "Ticket", Type: "string"}},
+		{{Key: "a", Type: "string"}, {Key: "a", Type: "number"}},
+		{{Key: "a", Type: "date"}},
+		{{Key: "a", Type: "enum"}},
+		{{Key: "a", Type: "enum", Options: []string{"x", "x"}}},
+	} {
+		if _, err := NormalizeFields(bad); !errors.Is(err, ErrInvalidField) {
+			t.Errorf("%+v: got %v", bad, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	fields := []Field{
+		{Key: "ticket_id", Type: TypeString},
+		{Key: "cost_center", Type: TypeEnum, Options: []string{"CC-1", "CC-2"}, Required: true},
+		{Key: "hours", Type: TypeNumber},
+		{Key: "billable", Type: TypeBoolean},
+	}
+	got, err := Validate(fields, map[string]any{
+		"ticket_id":   " OPS-12 ",
+		"cost_center": "CC-2",
+		"hours":       float64(3),
+		"billable":    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["ticket_id"] != "OPS-12" || got["hours"] != float64(3) {
+		t.Fatalf("got %v", got)
+	}
+
+	for name, values := range map[string]map[string]any{
+		"unknown":  {"cost_center": "CC-1", "owner": "x"},
+		"required": {"ticket_id": "OPS-12"},
+		"enum":     {"cost_center": "CC-3"},
+		"number":   {"cost_center": "CC-1", "hours": "3"},
+		"boolean":  {"cost_center": "CC-1", "billable": "yes"},
+		"empty":    {"cost_center": "CC-1", "ticket_id": " "},
+	} {
+		var verr *ValidationError
+		if _, err := Validate(fields, values); !errors.As(err, &verr) || !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+}
+
+func TestFilters(t *testing.T) {
+	f, err := ParseFilters(map[string]string{
+		"status":               "open",
+		"metadata.ticket_id":   "OPS-12",
+		"metadata.cost_center": "CC-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cond, args := f.SQL("metadata", 3)
+	if cond != "metadata->>$3 = $4 AND metadata->>$5 = $6" {
+		t.Fatalf("got %q", cond)
+	}
+	if len(args) != 4 || args[0] != "cost_center" || args[3] != "OPS-12" {
+		t.Fatalf("got %v", args)
+	}
+	if cond, args := (Filters{}).SQL("metadata", 1); cond != "" || args != nil {
+		t.Fatalf("got %q %v", cond, args)
+	}
+
+	if _, err := ParseFilters(map[string]string{"metadata.Bad-Key": "x"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("got %v", err)
+	}
+	if _, err := ParseFilters(map[string]string{"metadata.a": " "}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("got %v", err)
+	}
+}
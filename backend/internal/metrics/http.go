@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware times every request into Default. Mount it first so the
+// status it sees is the one the error handler wrote.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+		status := c.Response().StatusCode()
+		if err != nil {
+			// Not written yet; the error handler will.
+			status = fiber.StatusInternalServerError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				status = fe.Code
+			}
+		}
+		Default.ObserveRequest(c.Method(), routeLabel(c), status, time.Since(start))
+		return err
+	}
+}
+
+// routeLabel is the route template that answered, so paths with IDs share
+// a series. When no route matched, the last route seen is a global
+// middleware's, mounted at "/".
+func routeLabel(c *fiber.Ctx) string {
+	r := c.Route()
+	if r.Path == "/" && c.Path() != "/" {
+		return "unmatched"
+	}
+	return r.Path
+}
+
+// GitHubTransport wraps base (nil means http.DefaultTransport) to time every
+// GitHub call into Default and track the rate limit GitHub reports.
+func GitHubTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return githubTransport{base: base}
+}
+
+type githubTransport struct {
+	base http.RoundTripper
+}
+
+func (t githubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	resource := githubResource(req)
+	if err != nil {
+		Default.ObserveGitHub(resource, "error", time.Since(start))
+		return resp, err
+	}
+	Default.ObserveGitHub(resource, statusClass(resp.StatusCode), time.Since(start))
+	if rl := resp.Header.Get("X-RateLimit-Resource"); rl != "" {
+		limit, errL := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+		remaining, errR := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+		if errL == nil && errR == nil {
+			Default.SetGitHubRateLimit(rl, limit, remaining)
+		}
+	}
+	return resp, nil
+}
+
+// githubResource names the API a call went to by the first segment of its
+// path (repos, user, graphql...); OAuth calls go to github.com itself.
+func githubResource(req *http.Request) string {
+	if req.URL.Host != "api.github.com" {
+		return "oauth"
+	}
+	first, _, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	if first == "" {
+		return "root"
+	}
+	return first
+}
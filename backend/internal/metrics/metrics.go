@@ -0,0 +1,229 @@
+// Package metrics records the API's own request, login and GitHub client
+// metrics and writes them, with the database pool's, in the Prometheus text
+// format. Instrumented code records into Default; /metrics serves it.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the Prometheus client's default buckets, in seconds.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// signatureBuckets suit wallet signature checks, which take tens of
+// microseconds to a millisecond or so.
+var signatureBuckets = []float64{0.00001, 0.000025, 0.00005, 0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005}
+
+// Default is the registry the instrumented packages record into.
+var Default = New()
+
+// Registry holds what this process has recorded since it started.
+type Registry struct {
+	mu       sync.Mutex
+	requests map[requestKey]*histogram
+	logins   map[loginKey]uint64
+	nonces   map[string]uint64
+	github   map[githubKey]*histogram
+	// signatures times wallet signature checks; cached ones skip the
+	// cryptography.
+	signatures map[signatureKey]*histogram
+	// rateLimits is the last rate limit GitHub reported per resource (core,
+	// graphql, search...), whichever token the call was made with.
+	rateLimits map[string]rateLimit
+}
+
+type requestKey struct{ method, route, code string }
+
+type loginKey struct{ method, result string }
+
+type githubKey struct{ resource, code string }
+
+type signatureKey struct{ walletType, result string }
+
+type rateLimit struct{ limit, remaining int }
+
+func New() *Registry {
+	return &Registry{
+		requests:   map[requestKey]*histogram{},
+		logins:     map[loginKey]uint64{},
+		nonces:     map[string]uint64{},
+		github:     map[githubKey]*histogram{},
+		signatures: map[signatureKey]*histogram{},
+		rateLimits: map[string]rateLimit{},
+	}
+}
+
+type histogram struct {
+	buckets []float64 // upper bounds; latencyBuckets when nil
+	counts  []uint64  // per bucket, not cumulative
+	sum     float64
+	n       uint64
+}
+
+func (h *histogram) bounds() []float64 {
+	if h.buckets == nil {
+		return latencyBuckets
+	}
+	return h.buckets
+}
+
+func (h *histogram) observe(v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(h.bounds()))
+	}
+	for i, le := range h.bounds() {
+		if v <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += v
+	h.n++
+}
+
+func observe[K comparable](m map[K]*histogram, k K, d time.Duration) {
+	observeIn(m, k, d, nil)
+}
+
+func observeIn[K comparable](m map[K]*histogram, k K, d time.Duration, buckets []float64) {
+	h, ok := m[k]
+	if !ok {
+		h = &histogram{buckets: buckets}
+		m[k] = h
+	}
+	h.observe(d.Seconds())
+}
+
+// statusClass buckets an HTTP status as 2xx, 4xx... to keep label sets small.
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// ObserveRequest records how long the API took to answer a request to route.
+func (r *Registry) ObserveRequest(method, route string, status int, d time.Duration) {
+	r.mu.Lock()
+	observe(r.requests, requestKey{method, route, statusClass(status)}, d)
+	r.mu.Unlock()
+}
+
+// Login counts a login attempt by method (wallet, github).
+func (r *Registry) Login(method string, ok bool) {
+	result := "failure"
+	if ok {
+		result = "success"
+	}
+	r.mu.Lock()
+	r.logins[loginKey{method, result}]++
+	r.mu.Unlock()
+}
+
+// NonceIssued counts a login nonce handed out to a wallet of walletType.
+func (r *Registry) NonceIssued(walletType string) {
+	r.mu.Lock()
+	r.nonces[walletType]++
+	r.mu.Unlock()
+}
+
+// ObserveGitHub records a GitHub API call. code is the status class, or
+// "error" when no response came back.
+func (r *Registry) ObserveGitHub(resource, code string, d time.Duration) {
+	r.mu.Lock()
+	observe(r.github, githubKey{resource, code}, d)
+	r.mu.Unlock()
+}
+
+// ObserveSignature records a wallet signature check. result is valid,
+// invalid or cached.
+func (r *Registry) ObserveSignature(walletType, result string, d time.Duration) {
+	r.mu.Lock()
+	observeIn(r.signatures, signatureKey{walletType, result}, d, signatureBuckets)
+	r.mu.Unlock()
+}
+
+// SetGitHubRateLimit records the rate limit a GitHub response reported.
+func (r *Registry) SetGitHubRateLimit(resource string, limit, remaining int) {
+	r.mu.Lock()
+	r.rateLimits[resource] = rateLimit{limit, remaining}
+	r.mu.Unlock()
+}
+
+// Login counts a login attempt in Default.
+func Login(method string, ok bool) { Default.Login(method, ok) }
+
+// NonceIssued counts an issued login nonce in Default.
+func NonceIssued(walletType string) { Default.NonceIssued(walletType) }
+
+// ObserveSignature records a wallet signature check in Default.
+func ObserveSignature(walletType, result string, d time.Duration) {
+	Default.ObserveSignature(walletType, result, d)
+}
+
+// WriteMetrics writes everything recorded in the Prometheus text format.
+func (r *Registry) WriteMetrics(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b strings.Builder
+
+	writeHistograms(&b, "grainlify_http_request_duration_seconds", "Time taken to answer API requests, by route template.", r.requests,
+		func(k requestKey) string { return fmt.Sprintf("method=%q,route=%q,code=%q", k.method, k.route, k.code) })
+
+	b.WriteString("# HELP grainlify_auth_logins_total Login attempts by method and result.\n# TYPE grainlify_auth_logins_total counter\n")
+	for _, k := range sortedKeys(r.logins, func(k loginKey) string { return k.method + "\x00" + k.result }) {
+		fmt.Fprintf(&b, "grainlify_auth_logins_total{method=%q,result=%q} %d\n", k.method, k.result, r.logins[k])
+	}
+	b.WriteString("# HELP grainlify_auth_nonces_issued_total Login nonces issued, by wallet type.\n# TYPE grainlify_auth_nonces_issued_total counter\n")
+	for _, k := range sortedKeys(r.nonces, func(k string) string { return k }) {
+		fmt.Fprintf(&b, "grainlify_auth_nonces_issued_total{wallet_type=%q} %d\n", k, r.nonces[k])
+	}
+	writeHistograms(&b, "grainlify_auth_signature_verify_duration_seconds", "Time taken to check wallet signatures, by wallet type and result.", r.signatures,
+		func(k signatureKey) string { return fmt.Sprintf("wallet_type=%q,result=%q", k.walletType, k.result) })
+
+	writeHistograms(&b, "grainlify_github_request_duration_seconds", "Latency of GitHub API calls, by API resource.", r.github,
+		func(k githubKey) string { return fmt.Sprintf("resource=%q,code=%q", k.resource, k.code) })
+
+	limits := sortedKeys(r.rateLimits, func(k string) string { return k })
+	b.WriteString("# HELP grainlify_github_rate_limit_remaining Requests left in the GitHub rate limit window, as last reported.\n# TYPE grainlify_github_rate_limit_remaining gauge\n")
+	for _, k := range limits {
+		fmt.Fprintf(&b, "grainlify_github_rate_limit_remaining{resource=%q} %d\n", k, r.rateLimits[k].remaining)
+	}
+	b.WriteString("# HELP grainlify_github_rate_limit GitHub rate limit per window, as last reported.\n# TYPE grainlify_github_rate_limit gauge\n")
+	for _, k := range limits {
+		fmt.Fprintf(&b, "grainlify_github_rate_limit{resource=%q} %d\n", k, r.rateLimits[k].limit)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHistograms[K comparable](b *strings.Builder, name, help string, m map[K]*histogram, labels func(K) string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, k := range sortedKeys(m, labels) {
+		h, l := m[k], labels(k)
+		var cum uint64
+		for i, le := range h.bounds() {
+			cum += h.counts[i]
+			fmt.Fprintf(b, "%s_bucket{%s,le=%q} %d\n", name, l, strconv.FormatFloat(le, 'g', -1, 64), cum)
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l, h.n)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, l, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, l, h.n)
+	}
+}
+
+func sortedKeys[K comparable, V any](m map[K]V, by func(K) string) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return by(keys[i]) < by(keys[j]) })
+	return keys
+}
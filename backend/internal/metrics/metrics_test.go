@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWriteMetricsHistogram(t *testing.T) {
+	r := New()
+	r.ObserveRequest("GET", "/projects/:id", 200, 30*time.Millisecond)
+	r.ObserveRequest("GET", "/projects/:id", 204, 2*time.Second)
+	r.Login("wallet", false)
+	r.SetGitHubRateLimit("core", 5000, 4999)
+	r.ObserveSignature("evm", "valid", 80*time.Microsecond)
+
+	var b strings.Builder
+	if err := r.WriteMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`grainlify_http_request_duration_seconds_bucket{method="GET",route="/projects/:id",code="2xx",le="0.025"} 0`,
+		`grainlify_http_request_duration_seconds_bucket{method="GET",route="/projects/:id",code="2xx",le="0.05"} 1`,
+		`grainlify_http_request_duration_seconds_bucket{method="GET",route="/projects/:id",code="2xx",le="2.5"} 2`,
+		`grainlify_http_request_duration_seconds_bucket{method="GET",route="/projects/:id",code="2xx",le="+Inf"} 2`,
+		`grainlify_http_request_duration_seconds_count{method="GET",route="/projects/:id",code="2xx"} 2`,
+		`grainlify_auth_logins_total{method="wallet",result="failure"} 1`,
+		`grainlify_github_rate_limit_remaining{resource="core"} 4999`,
+		`grainlify_auth_signature_verify_duration_seconds_bucket{wallet_type="evm",result="valid",le="5e-05"} 0`,
+		`grainlify_auth_signature_verify_duration_seconds_bucket{wallet_type="evm",result="valid",le="0.0001"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+}
+
+func TestMiddlewareLabelsRouteTemplate(t *testing.T) {
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/items/:id", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+	before := New()
+	Default, before = before, Default
+	defer func() { Default = before }()
+
+	for _, path := range []string{"/items/1", "/items/2", "/nope"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h := Default.requests[requestKey{"GET", "/items/:id", "2xx"}]; h == nil || h.n != 2 {
+		t.Errorf("route template not shared: %+v", Default.requests)
+	}
+	if h := Default.requests[requestKey{"GET", "unmatched", "4xx"}]; h == nil || h.n != 1 {
+		t.Errorf("unmatched request not labelled: %+v", Default.requests)
+	}
+}
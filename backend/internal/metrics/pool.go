@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolStats exports a database pool's connection stats.
+type PoolStats struct {
+	Pool *pgxpool.Pool
+}
+
+// WriteMetrics writes the pool's stats in the Prometheus text format.
+func (p PoolStats) WriteMetrics(w io.Writer) error {
+	if p.Pool == nil {
+		return nil
+	}
+	st := p.Pool.Stat()
+	metrics := []struct {
+		name, help, kind string
+		value            any
+	}{
+		{"grainlify_db_pool_max_conns", "Most connections the pool opens.", "gauge", st.MaxConns()},
+		{"grainlify_db_pool_total_conns", "Connections open, idle or in use.", "gauge", st.TotalConns()},
+		{"grainlify_db_pool_acquired_conns", "Connections in use.", "gauge", st.AcquiredConns()},
+		{"grainlify_db_pool_idle_conns", "Idle connections.", "gauge", st.IdleConns()},
+		{"grainlify_db_pool_acquires_total", "Connections acquired from the pool.", "counter", st.AcquireCount()},
+		{"grainlify_db_pool_empty_acquires_total", "Acquires that waited because no connection was idle.", "counter", st.EmptyAcquireCount()},
+		{"grainlify_db_pool_canceled_acquires_total", "Acquires cancelled before a connection was free.", "counter", st.CanceledAcquireCount()},
+		{"grainlify_db_pool_acquire_seconds_total", "Time spent waiting for connections.", "counter", st.AcquireDuration().Seconds()},
+	}
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
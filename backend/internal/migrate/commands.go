@@ -0,0 +1,189 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/jagadeesh/grainlify/backend/migrations"
+)
+
+// Usage describes the migrate subcommands.
+const Usage = `usage: migrate [up | down N | status]
+  up       apply every pending migration (default)
+  down N   roll back the last N migrations
+  status   show the applied version and pending migrations`
+
+// Status is where the database stands against the embedded migrations.
+type Status struct {
+	// Version is the last applied migration, 0 when none is.
+	Version uint
+	// Dirty is set when a migration failed half way; it has to be fixed by
+	// hand before migrating further.
+	Dirty   bool
+	Latest  uint
+	Pending []uint
+}
+
+// Run runs the subcommand in args (see Usage), writing what it did to w.
+func Run(ctx context.Context, pool *pgxpool.Pool, args []string, w io.Writer) error {
+	cmd, steps, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	switch cmd {
+	case "up":
+		if err := Up(ctx, pool); err != nil {
+			return err
+		}
+	case "down":
+		if err := Down(ctx, pool, steps); err != nil {
+			return err
+		}
+	}
+	st, err := GetStatus(ctx, pool)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "version: %d (latest %d)\n", st.Version, st.Latest)
+	if st.Dirty {
+		fmt.Fprintf(w, "dirty: migration %d failed part way; fix the schema by hand before migrating\n", st.Version)
+	}
+	fmt.Fprintf(w, "pending: %d\n", len(st.Pending))
+	for _, v := range st.Pending {
+		fmt.Fprintf(w, "  %06d\n", v)
+	}
+	return nil
+}
+
+func parseArgs(args []string) (string, int, error) {
+	if len(args) == 0 {
+		return "up", 0, nil
+	}
+	switch args[0] {
+	case "up", "status":
+		if len(args) == 1 {
+			return args[0], 0, nil
+		}
+	case "down":
+		// Rolling back drops data, so the number of steps is never implied.
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err == nil && n > 0 {
+				return "down", n, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("%s", Usage)
+}
+
+// Down rolls back the last steps migrations.
+func Down(ctx context.Context, pool *pgxpool.Pool, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive")
+	}
+	m, closeFn, err := open(pool)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+	// migrate.Steps() is not context-aware either.
+	_ = ctx
+	if err := m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrate down: %w", err)
+	}
+	return nil
+}
+
+// GetStatus compares the database's migration version with the embedded
+// migrations.
+func GetStatus(ctx context.Context, pool *pgxpool.Pool) (Status, error) {
+	m, closeFn, err := open(pool)
+	if err != nil {
+		return Status{}, err
+	}
+	defer closeFn()
+	_ = ctx
+	var st Status
+	st.Version, st.Dirty, err = m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, fmt.Errorf("read migration version: %w", err)
+	}
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return Status{}, fmt.Errorf("open embedded migrations: %w", err)
+	}
+	all, err := versions(src)
+	if err != nil {
+		return Status{}, err
+	}
+	if len(all) > 0 {
+		st.Latest = all[len(all)-1]
+	}
+	st.Pending = pending(all, st.Version)
+	return st, nil
+}
+
+// open builds a migrator over the embedded migrations for one-off commands;
+// Up sets up its own with retries for instances starting together.
+func open(pool *pgxpool.Pool) (*migrate.Migrate, func(), error) {
+	if pool == nil {
+		return nil, nil, fmt.Errorf("db pool is nil")
+	}
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+	sqlDB := stdlib.OpenDB(*pool.Config().ConnConfig)
+	drv, err := postgres.WithInstance(sqlDB, &postgres.Config{MigrationsTable: "schema_migrations"})
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, nil, fmt.Errorf("create postgres migration driver: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", drv)
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, nil, fmt.Errorf("create migrator: %w", err)
+	}
+	return m, func() {
+		_, _ = m.Close()
+		_ = sqlDB.Close()
+	}, nil
+}
+
+// versions lists the source's migration versions in order.
+func versions(src source.Driver) ([]uint, error) {
+	v, err := src.First()
+	if err != nil {
+		return nil, fmt.Errorf("get first migration: %w", err)
+	}
+	out := []uint{v}
+	for {
+		next, err := src.Next(v)
+		if err != nil {
+			return out, nil
+		}
+		out = append(out, next)
+		v = next
+	}
+}
+
+// pending returns the versions after current.
+func pending(all []uint, current uint) []uint {
+	out := []uint{}
+	for _, v := range all {
+		if v > current {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args  []string
+		cmd   string
+		steps int
+	}{
+		{nil, "up", 0},
+		{[]string{"up"}, "up", 0},
+		{[]string{"status"}, "status", 0},
+		{[]string{"down", "2"}, "down", 2},
+	} {
+		cmd, steps, err := parseArgs(tc.args)
+		if err != nil || cmd != tc.cmd || steps != tc.steps {
+			t.Errorf("%v: got %q %d %v", tc.args, cmd, steps, err)
+		}
+	}
+	for _, bad := range [][]string{{"down"}, {"down", "0"}, {"down", "all"}, {"up", "3"}, {"redo"}} {
+		if _, _, err := parseArgs(bad); err == nil {
+			t.Errorf("%v: accepted", bad)
+		}
+	}
+}
+
+func TestPending(t *testing.T) {
+	all := []uint{1, 2, 3, 69, 70}
+	if got := pending(all, 3); !reflect.DeepEqual(got, []uint{69, 70}) {
+		t.Fatalf("got %v", got)
+	}
+	if got := pending(all, 70); len(got) != 0 {
+		t.Fatalf("got %v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+const (
+	ActionSuspend        = "suspend"
+	ActionBan            = "ban"
+	ActionReinstate      = "reinstate"
+	ActionExpireSessions = "expire_sessions"
+	ActionRoleChange     = "role_change"
+)
+
+// Account statuses as reported to admins.
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
+)
+
+// StatusSQL computes the account status of the users row aliased u.
+const StatusSQL = `CASE WHEN u.banned_at IS NOT NULL THEN 'banned' WHEN u.suspended_until > now() THEN 'suspended' ELSE 'active' END`
+
+var (
+	ErrAccountSuspended = errors.New("account_suspended")
+	ErrAccountBanned    = errors.New("account_banned")
+)
+
+// CheckAccountAccess returns ErrAccountBanned or ErrAccountSuspended (with
+// the end of the suspension) when the user may not sign in.
+func CheckAccountAccess(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (*time.Time, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	var bannedAt, suspendedUntil *time.Time
+	err := pool.QueryRow(ctx, `SELECT banned_at, suspended_until FROM users WHERE id = $1`, userID).Scan(&bannedAt, &suspendedUntil)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, ErrUserNotFound
+	case err != nil:
+		return nil, err
+	}
+	return AccountAccess(bannedAt, suspendedUntil, time.Now())
+}
+
+// AccountAccess is CheckAccountAccess for a user's banned_at and
+// suspended_until as of now.
+func AccountAccess(bannedAt, suspendedUntil *time.Time, now time.Time) (*time.Time, error) {
+	switch {
+	case bannedAt != nil:
+		return nil, ErrAccountBanned
+	case suspendedUntil != nil && suspendedUntil.After(now):
+		return suspendedUntil, ErrAccountSuspended
+	}
+	return nil, nil
+}
+
+// RecordAction appends to the moderation audit trail; q may be a transaction.
+func RecordAction(ctx context.Context, q interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}, actorID, targetID uuid.UUID, action, reason string) error {
+	_, err := q.Exec(ctx, `
+INSERT INTO moderation_actions (actor_user_id, target_user_id, action, reason)
+VALUES ($1, $2, $3, NULLIF($4, ''))
+`, actorID, targetID, action, strings.TrimSpace(reason))
+	return err
+}
+
+// Suspend blocks sign-in until until and signs the user out everywhere.
+func Suspend(ctx context.Context, pool *pgxpool.Pool, actorID, targetID uuid.UUID, until time.Time, reason string) error {
+	return applyAccountAction(ctx, pool, actorID, targetID, ActionSuspend, reason, true,
+		`UPDATE users SET suspended_until = $2, suspension_reason = NULLIF($3, ''), updated_at = now() WHERE id = $1`,
+		until, strings.TrimSpace(reason))
+}
+
+// Ban blocks sign-in permanently and signs the user out everywhere.
+func Ban(ctx context.Context, pool *pgxpool.Pool, actorID, targetID uuid.UUID, reason string) error {
+	return applyAccountAction(ctx, pool, actorID, targetID, ActionBan, reason, true,
+		`UPDATE users SET banned_at = COALESCE(banned_at, now()), ban_reason = NULLIF($2, ''), updated_at = now() WHERE id = $1`,
+		strings.TrimSpace(reason))
+}
+
+// Reinstate lifts both a suspension and a ban.
+func Reinstate(ctx context.Context, pool *pgxpool.Pool, actorID, targetID uuid.UUID, reason string) error {
+	return applyAccountAction(ctx, pool, actorID, targetID, ActionReinstate, reason, false,
+		`UPDATE users SET suspended_until = NULL, suspension_reason = NULL, banned_at = NULL, ban_reason = NULL, updated_at = now() WHERE id = $1`)
+}
+
+// ExpireSessions signs the user out everywhere without restricting the account.
+func ExpireSessions(ctx context.Context, pool *pgxpool.Pool, actorID, targetID uuid.UUID, reason string) error {
+	return applyAccountAction(ctx, pool, actorID, targetID, ActionExpireSessions, reason, true,
+		`UPDATE users SET updated_at = now() WHERE id = $1`)
+}
+
+// applyAccountAction runs update (with $1 = targetID, then args), optionally
+// revokes every session, and records the action, all in one transaction.
+func applyAccountAction(ctx context.Context, pool *pgxpool.Pool, actorID, targetID uuid.UUID, action, reason string, revokeSessions bool, update string, args ...any) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	ct, err := tx.Exec(ctx, update, append([]any{targetID}, args...)...)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	if revokeSessions {
+		if _, err := auth.RevokeUserSessions(ctx, tx, targetID); err != nil {
+			return err
+		}
+	}
+	if err := RecordAction(ctx, tx, actorID, targetID, action, reason); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
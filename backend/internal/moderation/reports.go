@@ -0,0 +1,356 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Things that can be reported. Comment targets are GitHub comment ids as
+// stored in github_issues.comments.
+const (
+	ReportTargetBounty  = "bounty"
+	ReportTargetUser    = "user"
+	ReportTargetComment = "comment"
+)
+
+// Report statuses. Open reports are the moderation queue.
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// ReportCategories are the reasons a reporter can pick from.
+var ReportCategories = []string{"spam", "harassment", "scam", "plagiarism", "inappropriate", "other"}
+
+// Limits on what a single report may carry.
+const (
+	MaxReportAttachments = 3
+	MaxAttachmentBytes   = 768 << 10
+	maxReportDetails     = 4000
+	maxAttachmentName    = 200
+)
+
+// Evidence types accepted, by sniffed content type.
+var attachmentTypes = map[string]bool{
+	"image/png":                 true,
+	"image/jpeg":                true,
+	"image/gif":                 true,
+	"image/webp":                true,
+	"application/pdf":           true,
+	"text/plain; charset=utf-8": true,
+}
+
+var (
+	ErrInvalidReportTarget  = errors.New("invalid_target")
+	ErrReportTargetNotFound = errors.New("target_not_found")
+	ErrInvalidReportReason  = errors.New("invalid_category")
+	ErrReportTooLong        = errors.New("details_too_long")
+	ErrTooManyAttachments   = errors.New("too_many_attachments")
+	ErrAttachmentTooLarge   = errors.New("attachment_too_large")
+	ErrInvalidAttachment    = errors.New("invalid_attachment")
+	ErrSelfReport           = errors.New("cannot_report_self")
+	ErrReportExists         = errors.New("report_exists")
+	ErrReportNotFound       = errors.New("report_not_found")
+	ErrReportClosed         = errors.New("report_already_closed")
+)
+
+// Attachment is a piece of evidence. Data is only loaded when downloading.
+type Attachment struct {
+	ID          uuid.UUID `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int       `json:"size_bytes"`
+	CreatedAt   time.Time `json:"created_at"`
+	Data        []byte    `json:"-"`
+}
+
+type Report struct {
+	ID             uuid.UUID    `json:"id"`
+	ReporterUserID *uuid.UUID   `json:"reporter_user_id"`
+	TargetType     string       `json:"target_type"`
+	TargetID       string       `json:"target_id"`
+	Category       string       `json:"category"`
+	Details        *string      `json:"details"`
+	Status         string       `json:"status"`
+	ResolvedBy     *uuid.UUID   `json:"resolved_by"`
+	ResolutionNote *string      `json:"resolution_note"`
+	ResolvedAt     *time.Time   `json:"resolved_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	Attachments    []Attachment `json:"attachments"`
+	// OpenOnTarget counts open reports against the same target, so the queue
+	// can surface heavily reported targets first.
+	OpenOnTarget int `json:"open_reports_on_target"`
+}
+
+// NewReport is a report as submitted.
+type NewReport struct {
+	TargetType  string
+	TargetID    string
+	Category    string
+	Details     string
+	Attachments []Attachment
+}
+
+// Normalize validates r in place, canonicalizing ids and setting each
+// attachment's content type from its bytes rather than the client's claim.
+func (r *NewReport) Normalize() error {
+	r.TargetType = strings.ToLower(strings.TrimSpace(r.TargetType))
+	r.TargetID = strings.TrimSpace(r.TargetID)
+	switch r.TargetType {
+	case ReportTargetBounty, ReportTargetUser:
+		id, err := uuid.Parse(r.TargetID)
+		if err != nil {
+			return ErrInvalidReportTarget
+		}
+		r.TargetID = id.String()
+	case ReportTargetComment:
+		id, err := strconv.ParseInt(r.TargetID, 10, 64)
+		if err != nil || id <= 0 {
+			return ErrInvalidReportTarget
+		}
+		r.TargetID = strconv.FormatInt(id, 10)
+	default:
+		return ErrInvalidReportTarget
+	}
+
+	r.Category = strings.ToLower(strings.TrimSpace(r.Category))
+	valid := false
+	for _, c := range ReportCategories {
+		valid = valid || c == r.Category
+	}
+	if !valid {
+		return ErrInvalidReportReason
+	}
+	r.Details = strings.TrimSpace(r.Details)
+	if utf8.RuneCountInString(r.Details) > maxReportDetails {
+		return ErrReportTooLong
+	}
+
+	if len(r.Attachments) > MaxReportAttachments {
+		return ErrTooManyAttachments
+	}
+	for i := range r.Attachments {
+		a := &r.Attachments[i]
+		if len(a.Data) == 0 {
+			return ErrInvalidAttachment
+		}
+		if len(a.Data) > MaxAttachmentBytes {
+			return ErrAttachmentTooLarge
+		}
+		a.ContentType = http.DetectContentType(a.Data)
+		if !attachmentTypes[a.ContentType] {
+			return ErrInvalidAttachment
+		}
+		a.SizeBytes = len(a.Data)
+		a.Filename = path.Base(strings.ReplaceAll(strings.TrimSpace(a.Filename), `\`, "/"))
+		if a.Filename == "." || a.Filename == "/" {
+			a.Filename = fmt.Sprintf("attachment-%d", i+1)
+		}
+		if utf8.RuneCountInString(a.Filename) > maxAttachmentName {
+			a.Filename = string([]rune(a.Filename)[:maxAttachmentName])
+		}
+	}
+	return nil
+}
+
+// CreateReport files a report with its attachments into the moderation queue.
+func CreateReport(ctx context.Context, pool *pgxpool.Pool, reporterID uuid.UUID, r NewReport) (Report, error) {
+	if pool == nil {
+		return Report{}, fmt.Errorf("db not configured")
+	}
+	if err := r.Normalize(); err != nil {
+		return Report{}, err
+	}
+	if r.TargetType == ReportTargetUser && r.TargetID == reporterID.String() {
+		return Report{}, ErrSelfReport
+	}
+	var exists bool
+	var err error
+	switch r.TargetType {
+	case ReportTargetBounty:
+		err = pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM bounties WHERE id = $1)`, r.TargetID).Scan(&exists)
+	case ReportTargetUser:
+		err = pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, r.TargetID).Scan(&exists)
+	case ReportTargetComment:
+		err = pool.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM github_issues gi, jsonb_array_elements(gi.comments) c
+  WHERE c->>'id' = $1
+)`, r.TargetID).Scan(&exists)
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	if !exists {
+		return Report{}, ErrReportTargetNotFound
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Report{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rep := Report{ReporterUserID: &reporterID, TargetType: r.TargetType, TargetID: r.TargetID, Category: r.Category, Status: ReportOpen, Attachments: []Attachment{}}
+	if r.Details != "" {
+		rep.Details = &r.Details
+	}
+	err = tx.QueryRow(ctx, `
+INSERT INTO abuse_reports (reporter_user_id, target_type, target_id, category, details)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+RETURNING id, created_at
+`, reporterID, r.TargetType, r.TargetID, r.Category, r.Details).Scan(&rep.ID, &rep.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return Report{}, ErrReportExists
+		}
+		return Report{}, err
+	}
+	for _, a := range r.Attachments {
+		if err := tx.QueryRow(ctx, `
+INSERT INTO abuse_report_attachments (report_id, filename, content_type, size_bytes, data)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at
+`, rep.ID, a.Filename, a.ContentType, a.SizeBytes, a.Data).Scan(&a.ID, &a.CreatedAt); err != nil {
+			return Report{}, err
+		}
+		a.Data = nil
+		rep.Attachments = append(rep.Attachments, a)
+	}
+	return rep, tx.Commit(ctx)
+}
+
+const reportColumns = `r.id, r.reporter_user_id, r.target_type, r.target_id, r.category, r.details, r.status,
+       r.resolved_by, r.resolution_note, r.resolved_at, r.created_at,
+       (SELECT COUNT(*) FROM abuse_reports o WHERE o.target_type = r.target_type AND o.target_id = r.target_id AND o.status = 'open')`
+
+func scanReport(row pgx.Row) (Report, error) {
+	var r Report
+	err := row.Scan(&r.ID, &r.ReporterUserID, &r.TargetType, &r.TargetID, &r.Category, &r.Details, &r.Status,
+		&r.ResolvedBy, &r.ResolutionNote, &r.ResolvedAt, &r.CreatedAt, &r.OpenOnTarget)
+	r.Attachments = []Attachment{}
+	return r, err
+}
+
+// ListReports returns reports in status (all when empty), oldest first so the
+// queue is worked in order.
+func ListReports(ctx context.Context, pool *pgxpool.Pool, status string, limit, offset int) ([]Report, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+reportColumns+`
+FROM abuse_reports r
+WHERE ($1 = '' OR r.status = $1)
+ORDER BY r.created_at, r.id
+LIMIT $2 OFFSET $3
+`, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Report{}
+	index := map[uuid.UUID]int{}
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		index[r.ID] = len(out)
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(out))
+	for _, r := range out {
+		ids = append(ids, r.ID)
+	}
+	arows, err := pool.Query(ctx, `
+SELECT report_id, id, filename, content_type, size_bytes, created_at
+FROM abuse_report_attachments
+WHERE report_id = ANY($1)
+ORDER BY created_at, id
+`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer arows.Close()
+	for arows.Next() {
+		var reportID uuid.UUID
+		var a Attachment
+		if err := arows.Scan(&reportID, &a.ID, &a.Filename, &a.ContentType, &a.SizeBytes, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		i := index[reportID]
+		out[i].Attachments = append(out[i].Attachments, a)
+	}
+	return out, arows.Err()
+}
+
+// ReportAttachment loads one attachment of a report, including its data.
+func ReportAttachment(ctx context.Context, pool *pgxpool.Pool, reportID, attachmentID uuid.UUID) (Attachment, error) {
+	if pool == nil {
+		return Attachment{}, fmt.Errorf("db not configured")
+	}
+	var a Attachment
+	err := pool.QueryRow(ctx, `
+SELECT id, filename, content_type, size_bytes, created_at, data
+FROM abuse_report_attachments
+WHERE id = $1 AND report_id = $2
+`, attachmentID, reportID).Scan(&a.ID, &a.Filename, &a.ContentType, &a.SizeBytes, &a.CreatedAt, &a.Data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Attachment{}, ErrReportNotFound
+	}
+	return a, err
+}
+
+// CloseReport takes an open report off the queue as resolved or dismissed.
+func CloseReport(ctx context.Context, pool *pgxpool.Pool, actorID, reportID uuid.UUID, status, note string) (Report, error) {
+	if pool == nil {
+		return Report{}, fmt.Errorf("db not configured")
+	}
+	if status != ReportResolved && status != ReportDismissed {
+		return Report{}, fmt.Errorf("invalid report status %q", status)
+	}
+	r, err := scanReport(pool.QueryRow(ctx, `
+WITH r AS (
+  UPDATE abuse_reports
+  SET status = $3, resolved_by = $2, resolution_note = NULLIF($4, ''), resolved_at = now()
+  WHERE id = $1 AND status = 'open'
+  RETURNING *
+)
+SELECT `+reportColumns+`
+FROM r
+`, reportID, actorID, status, strings.TrimSpace(note)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM abuse_reports WHERE id = $1)`, reportID).Scan(&exists); err != nil {
+			return Report{}, err
+		}
+		if exists {
+			return Report{}, ErrReportClosed
+		}
+		return Report{}, ErrReportNotFound
+	}
+	return r, err
+}
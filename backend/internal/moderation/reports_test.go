@@ -0,0 +1,56 @@
+package moderation
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestNewReportNormalize(t *testing.T) {
+	r := NewReport{
+		TargetType:  " Bounty ",
+		TargetID:    "6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+		Category:    "SPAM",
+		Details:     "  copies another bounty  ",
+		Attachments: []Attachment{{Filename: `C:\Users\me\shot.png`, Data: pngHeader}, {Data: []byte("log line\n")}},
+	}
+	if err := r.Normalize(); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if r.TargetType != ReportTargetBounty || r.TargetID != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" || r.Category != "spam" || r.Details != "copies another bounty" {
+		t.Errorf("normalized report = %+v", r)
+	}
+	if a := r.Attachments[0]; a.Filename != "shot.png" || a.ContentType != "image/png" || a.SizeBytes != len(pngHeader) {
+		t.Errorf("attachment 0 = %+v", a)
+	}
+	if a := r.Attachments[1]; a.Filename != "attachment-2" || a.ContentType != "text/plain; charset=utf-8" {
+		t.Errorf("attachment 1 = %+v", a)
+	}
+
+	comment := NewReport{TargetType: "comment", TargetID: "0042", Category: "harassment"}
+	if err := comment.Normalize(); err != nil || comment.TargetID != "42" {
+		t.Errorf("comment target = %q, %v", comment.TargetID, err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		r    NewReport
+		want error
+	}{
+		{"unknown target", NewReport{TargetType: "project", TargetID: "x", Category: "spam"}, ErrInvalidReportTarget},
+		{"user id not a uuid", NewReport{TargetType: "user", TargetID: "octocat", Category: "spam"}, ErrInvalidReportTarget},
+		{"comment id not numeric", NewReport{TargetType: "comment", TargetID: "-1", Category: "spam"}, ErrInvalidReportTarget},
+		{"unknown category", NewReport{TargetType: "comment", TargetID: "1", Category: "rude"}, ErrInvalidReportReason},
+		{"details too long", NewReport{TargetType: "comment", TargetID: "1", Category: "other", Details: string(bytes.Repeat([]byte("a"), maxReportDetails+1))}, ErrReportTooLong},
+		{"too many attachments", NewReport{TargetType: "comment", TargetID: "1", Category: "spam", Attachments: make([]Attachment, MaxReportAttachments+1)}, ErrTooManyAttachments},
+		{"empty attachment", NewReport{TargetType: "comment", TargetID: "1", Category: "spam", Attachments: []Attachment{{Filename: "a"}}}, ErrInvalidAttachment},
+		{"attachment too large", NewReport{TargetType: "comment", TargetID: "1", Category: "spam", Attachments: []Attachment{{Data: make([]byte, MaxAttachmentBytes+1)}}}, ErrAttachmentTooLarge},
+		{"disallowed type", NewReport{TargetType: "comment", TargetID: "1", Category: "spam", Attachments: []Attachment{{Data: []byte("<html><script>alert(1)</script>")}}}, ErrInvalidAttachment},
+	} {
+		if err := tc.r.Normalize(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Normalize = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ActionShadowBan   = "shadow_ban"
+	ActionUnshadowBan = "unshadow_ban"
+)
+
+var ErrUserNotFound = errors.New("user_not_found")
+
+// Action is one entry of the moderation audit trail.
+type Action struct {
+	ID           uuid.UUID  `json:"id"`
+	ActorUserID  *uuid.UUID `json:"actor_user_id"`
+	TargetUserID uuid.UUID  `json:"target_user_id"`
+	Action       string     `json:"action"`
+	Reason       *string    `json:"reason"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// IsShadowBanned reports whether the user's content must be hidden from others.
+// Anything that picks a winner among claimants must also skip shadow-banned users.
+func IsShadowBanned(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var banned bool
+	err := pool.QueryRow(ctx, `SELECT shadow_banned_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&banned)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrUserNotFound
+	}
+	return banned, err
+}
+
+// SetShadowBan bans or unbans a user and records the action in the same transaction.
+func SetShadowBan(ctx context.Context, pool *pgxpool.Pool, actorID, targetID uuid.UUID, banned bool, reason string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	reason = strings.TrimSpace(reason)
+	action := ActionUnshadowBan
+	query := `UPDATE users SET shadow_banned_at = NULL, shadow_ban_reason = NULL, updated_at = now() WHERE id = $1`
+	args := []any{targetID}
+	if banned {
+		action = ActionShadowBan
+		query = `UPDATE users SET shadow_banned_at = COALESCE(shadow_banned_at, now()), shadow_ban_reason = NULLIF($2, ''), updated_at = now() WHERE id = $1`
+		args = append(args, reason)
+	}
+	ct, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO moderation_actions (actor_user_id, target_user_id, action, reason)
+VALUES ($1, $2, $3, NULLIF($4, ''))
+`, actorID, targetID, action, reason); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// History returns the moderation trail for a user, newest first.
+func History(ctx context.Context, pool *pgxpool.Pool, targetID uuid.UUID) ([]Action, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, actor_user_id, target_user_id, action, reason, created_at
+FROM moderation_actions
+WHERE target_user_id = $1
+ORDER BY created_at DESC
+LIMIT 200
+`, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Action{}
+	for rows.Next() {
+		var a Action
+		if err := rows.Scan(&a.ID, &a.ActorUserID, &a.TargetUserID, &a.Action, &a.Reason, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// HiddenLogins returns the lowercased GitHub logins of shadow-banned users.
+func HiddenLogins(ctx context.Context, pool *pgxpool.Pool) (map[string]struct{}, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT LOWER(ga.login)
+FROM users u
+JOIN github_accounts ga ON ga.user_id = u.id
+WHERE u.shadow_banned_at IS NOT NULL
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]struct{}{}
+	for rows.Next() {
+		var login string
+		if err := rows.Scan(&login); err != nil {
+			return nil, err
+		}
+		out[login] = struct{}{}
+	}
+	return out, rows.Err()
+}
+
+// FilterComments drops GitHub comments (as stored in github_issues.comments)
+// authored by hidden logins, unless the viewer is the author.
+func FilterComments(comments []any, hidden map[string]struct{}, viewerLogin string) []any {
+	if len(hidden) == 0 || len(comments) == 0 {
+		return comments
+	}
+	viewer := strings.ToLower(strings.TrimSpace(viewerLogin))
+	out := make([]any, 0, len(comments))
+	for _, c := range comments {
+		m, ok := c.(map[string]any)
+		if !ok {
+			out = append(out, c)
+			continue
+		}
+		user, _ := m["user"].(map[string]any)
+		login, _ := user["login"].(string)
+		login = strings.ToLower(login)
+		if _, isHidden := hidden[login]; isHidden && login != viewer {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// RecordShadowedContent stores content from a shadow-banned user instead of publishing it.
+func RecordShadowedContent(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, kind string, projectID uuid.UUID, issueNumber int, body string) (time.Time, error) {
+	if pool == nil {
+		return time.Time{}, fmt.Errorf("db not configured")
+	}
+	var createdAt time.Time
+	err := pool.QueryRow(ctx, `
+INSERT INTO shadowed_content (user_id, kind, project_id, issue_number, body)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING created_at
+`, userID, kind, projectID, issueNumber, body).Scan(&createdAt)
+	return createdAt, err
+}
+
+// OwnShadowedComments returns the user's shadowed content on a project's
+// issues, keyed by issue number and shaped like the comments stored in
+// github_issues.comments, so its author keeps seeing what they posted.
+func OwnShadowedComments(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, login string, projectID uuid.UUID) (map[int][]any, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT issue_number, body, created_at
+FROM shadowed_content
+WHERE user_id = $1 AND project_id = $2 AND issue_number IS NOT NULL
+ORDER BY created_at
+`, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int][]any{}
+	for rows.Next() {
+		var number int
+		var body string
+		var createdAt time.Time
+		if err := rows.Scan(&number, &body, &createdAt); err != nil {
+			return nil, err
+		}
+		ts := createdAt.UTC().Format(time.RFC3339)
+		out[number] = append(out[number], map[string]any{
+			"id":         createdAt.UnixNano(),
+			"body":       body,
+			"user":       map[string]any{"login": login},
+			"created_at": ts,
+			"updated_at": ts,
+		})
+	}
+	return out, rows.Err()
+}
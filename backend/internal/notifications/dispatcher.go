@@ -0,0 +1,139 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxAttempts bounds how often an event is retried on a channel that keeps
+// failing before it is given up.
+const MaxAttempts = 5
+
+// dispatchBatch is how many events one RunOnce handles.
+const dispatchBatch = 100
+
+// Renderer renders events: *Templates, or a *Reloader in development.
+type Renderer interface {
+	Render(e Event) (Rendered, error)
+}
+
+// Dispatcher delivers pending events from notification_outbox.
+type Dispatcher struct {
+	Pool      *pgxpool.Pool
+	Templates Renderer
+	Providers []Provider
+}
+
+type pendingEvent struct {
+	Event
+	delivered []string
+	attempts  int
+}
+
+// RunOnce delivers a batch of pending events oldest first, returning how
+// many deliveries were made.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	if d.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := d.Pool.Query(ctx, `
+SELECT id, user_id, type, data, delivered, attempts, created_at
+FROM notification_outbox
+WHERE done_at IS NULL
+ORDER BY seq
+LIMIT $1
+`, dispatchBatch)
+	if err != nil {
+		return 0, err
+	}
+	var pending []pendingEvent
+	for rows.Next() {
+		var p pendingEvent
+		if err := rows.Scan(&p.ID, &p.UserID, &p.Type, &p.Data, &p.delivered, &p.attempts, &p.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, p := range pending {
+		n, err := d.dispatch(ctx, p)
+		sent += n
+		if err != nil {
+			return sent, err
+		}
+	}
+	if sent > 0 {
+		slog.Info("delivered notifications", "count", sent)
+	}
+	return sent, nil
+}
+
+// dispatch hands one event to every enabled channel that hasn't taken it
+// yet. Delivery failures are recorded on the event, not returned.
+func (d *Dispatcher) dispatch(ctx context.Context, p pendingEvent) (int, error) {
+	channels, err := enabledChannels(ctx, d.Pool, p.UserID, p.Type)
+	if err != nil {
+		return 0, err
+	}
+	r, err := d.Templates.Render(p.Event)
+	if err != nil {
+		// A template that can't render won't on a retry either.
+		slog.Error("notification render failed", "notification_id", p.ID.String(), "type", p.Type, "error", err)
+		return 0, d.finish(ctx, p.ID, p.delivered, p.attempts+1, err, true)
+	}
+
+	sent := 0
+	var errs []error
+	for _, prov := range d.Providers {
+		ch := prov.Channel()
+		if !channels[ch] || slices.Contains(p.delivered, ch) {
+			continue
+		}
+		err := prov.Deliver(ctx, p.Event, r)
+		switch {
+		case errors.Is(err, ErrNoAddress):
+		case err != nil:
+			slog.Warn("notification delivery failed", "notification_id", p.ID.String(), "channel", ch, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", ch, err))
+		default:
+			p.delivered = append(p.delivered, ch)
+			sent++
+		}
+	}
+	attempts := p.attempts + 1
+	err = errors.Join(errs...)
+	return sent, d.finish(ctx, p.ID, p.delivered, attempts, err, err == nil || attempts >= MaxAttempts)
+}
+
+func (d *Dispatcher) finish(ctx context.Context, id uuid.UUID, delivered []string, attempts int, failure error, done bool) error {
+	var lastError *string
+	if failure != nil {
+		msg := failure.Error()
+		lastError = &msg
+	}
+	var doneAt *time.Time
+	if done {
+		now := time.Now()
+		doneAt = &now
+	}
+	if delivered == nil {
+		delivered = []string{}
+	}
+	_, err := d.Pool.Exec(ctx, `
+UPDATE notification_outbox SET delivered = $2, attempts = $3, last_error = $4, done_at = $5 WHERE id = $1
+`, id, delivered, attempts, lastError, doneAt)
+	return err
+}
@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/mailer"
+)
+
+// Email sends notifications to users' verified email addresses.
+type Email struct {
+	Pool   *pgxpool.Pool
+	Mailer *mailer.Mailer
+}
+
+func (p *Email) Channel() string { return ChannelEmail }
+
+// Deliver mails e to its recipient, or returns ErrNoAddress when they have
+// no verified address.
+func (p *Email) Deliver(ctx context.Context, e Event, r Rendered) error {
+	to, verified, err := auth.UserEmail(ctx, p.Pool, e.UserID)
+	if err != nil {
+		return err
+	}
+	if to == "" || !verified {
+		return ErrNoAddress
+	}
+	return p.Mailer.Send(ctx, to, r.Title, r.Body)
+}
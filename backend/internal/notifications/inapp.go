@@ -0,0 +1,119 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// InApp stores notifications for the app to show (GET /notifications).
+type InApp struct {
+	Pool *pgxpool.Pool
+}
+
+func (p *InApp) Channel() string { return ChannelInApp }
+
+// Deliver stores e under its outbox id, so delivering it twice is a no-op.
+func (p *InApp) Deliver(ctx context.Context, e Event, r Rendered) error {
+	_, err := p.Pool.Exec(ctx, `
+INSERT INTO notifications (id, user_id, type, title, body, link, created_at)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+ON CONFLICT (id) DO NOTHING
+`, e.ID, e.UserID, e.Type, r.Title, r.Summary, r.Link, e.CreatedAt)
+	return err
+}
+
+// Notification is an in-app notification.
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      *string    `json:"link,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MaxPage caps one page of notifications.
+const MaxPage = 100
+
+// ListFilter selects a user's notifications.
+type ListFilter struct {
+	UnreadOnly bool
+	// Before continues a listing from the created_at of its last item.
+	Before *time.Time
+	Limit  int
+}
+
+// List returns a user's notifications, newest first.
+func List(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, f ListFilter) ([]Notification, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	if f.Limit <= 0 || f.Limit > MaxPage {
+		f.Limit = MaxPage
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, type, title, body, link, read_at, created_at
+FROM notifications
+WHERE user_id = $1
+  AND (NOT $2 OR read_at IS NULL)
+  AND ($3::timestamptz IS NULL OR created_at < $3)
+ORDER BY created_at DESC
+LIMIT $4
+`, userID, f.UnreadOnly, f.Before, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.Type, &n.Title, &n.Body, &n.Link, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// UnreadCount counts a user's unread notifications.
+func UnreadCount(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (int, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	var n int
+	err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+// MarkRead marks one of a user's notifications read.
+func MarkRead(ctx context.Context, pool *pgxpool.Pool, userID, id uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `UPDATE notifications SET read_at = COALESCE(read_at, now()) WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of a user read, returning how
+// many there were.
+func MarkAllRead(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (int64, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,81 @@
+// Package notifications tells users about what happens to them: a bounty of
+// theirs was claimed, a submission awaits their review, a merged pull
+// request settled a bounty, a payout was sent.
+// Database triggers record each event for its recipient in
+// notification_outbox; the Dispatcher renders it from templates and fans it
+// out to the channel providers (in-app, email) the recipient's preferences
+// enable.
+//
+// Unlike package notify, which posts project-wide bounty events to chat
+// rooms, these are personal.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types.
+const (
+	TypeBountyClaimed   = "bounty.claimed"
+	TypeReviewRequested = "review.requested"
+	TypeBountyMerged    = "bounty.merged"
+	TypePayoutSent      = "payout.sent"
+)
+
+// Types lists every event type.
+var Types = []string{TypeBountyClaimed, TypeReviewRequested, TypeBountyMerged, TypePayoutSent}
+
+// Channels.
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+// Channels lists every channel.
+var Channels = []string{ChannelInApp, ChannelEmail}
+
+var (
+	ErrUnknownType    = errors.New("unknown_notification_type")
+	ErrUnknownChannel = errors.New("unknown_notification_channel")
+	ErrNotFound       = errors.New("notification_not_found")
+	// ErrNoAddress is returned by providers that have nowhere to deliver
+	// to for a user, such as email without a verified address. Such events
+	// are skipped for the provider rather than retried.
+	ErrNoAddress = errors.New("no_delivery_address")
+)
+
+// Event is one notification owed to a user, as recorded in
+// notification_outbox. Data holds the fields its template uses.
+type Event struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Type      string
+	Data      map[string]any
+	CreatedAt time.Time
+}
+
+// Rendered is an event rendered for delivery: Title and Summary for short
+// channels such as in-app, Body for email. Link is a frontend path.
+type Rendered struct {
+	Title   string
+	Summary string
+	Body    string
+	Link    string
+}
+
+// Provider delivers rendered events on one channel.
+type Provider interface {
+	Channel() string
+	Deliver(ctx context.Context, e Event, r Rendered) error
+}
+
+// ValidType reports whether t is one of Types.
+func ValidType(t string) bool { return slices.Contains(Types, t) }
+
+// ValidChannel reports whether ch is one of Channels.
+func ValidChannel(ch string) bool { return slices.Contains(Channels, ch) }
@@ -0,0 +1,147 @@
+package notifications
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// sampleData is what the outbox triggers record for each type.
+var sampleData = map[string]map[string]any{
+	TypeBountyClaimed: {
+		"bounty_id": "b1", "project_id": "p1", "project": "acme/widgets",
+		"issue_key": "#42", "issue_title": "Fix the widget", "issue_url": "https://github.com/acme/widgets/issues/42",
+		"amount": "150", "asset": "USDC", "chain": "stellar", "claimant": "octocat", "pr_url": "",
+	},
+	TypePayoutSent: {
+		"payout_id": "po1", "amount": "150", "asset": "USDC", "chain": "stellar",
+		"to_address": "GABC", "tx_hash": "0xfeed", "reference": "",
+	},
+}
+
+func init() {
+	review := map[string]any{}
+	for k, v := range sampleData[TypeBountyClaimed] {
+		review[k] = v
+	}
+	review["pr_url"] = "https://github.com/acme/widgets/pull/43"
+	sampleData[TypeReviewRequested] = review
+	sampleData[TypeBountyMerged] = review
+}
+
+func TestBuiltinTemplatesRender(t *testing.T) {
+	tmpl, err := ParseTemplates(BuiltinTemplates(), "https://app.grainlify.test/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range Types {
+		r, err := tmpl.Render(Event{Type: typ, Data: sampleData[typ]})
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if r.Title == "" || r.Summary == "" || !strings.HasPrefix(r.Link, "/") {
+			t.Errorf("%s rendered %+v", typ, r)
+		}
+		if strings.Contains(r.Title+r.Summary+r.Body, "<no value>") {
+			t.Errorf("%s rendered a missing value: %+v", typ, r)
+		}
+		if !strings.Contains(r.Body, "https://app.grainlify.test"+r.Link) {
+			t.Errorf("%s body lacks the link:\n%s", typ, r.Body)
+		}
+	}
+
+	claimed, _ := tmpl.Render(Event{Type: TypeBountyClaimed, Data: sampleData[TypeBountyClaimed]})
+	if claimed.Title != "octocat claimed your bounty on acme/widgets" {
+		t.Errorf("claimed title = %q", claimed.Title)
+	}
+	if _, err := tmpl.Render(Event{Type: "bounty.unknown"}); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("unknown type err = %v", err)
+	}
+	if _, err := tmpl.Render(Event{Type: TypePayoutSent, Data: map[string]any{}}); err == nil {
+		t.Error("rendered a payout without its data")
+	}
+}
+
+func TestParseTemplatesNeedsEveryType(t *testing.T) {
+	fsys := fstest.MapFS{
+		TypeBountyClaimed + ".tmpl": {Data: []byte(`{{define "title"}}t{{end}}{{define "summary"}}s{{end}}{{define "link"}}/{{end}}`)},
+	}
+	if _, err := ParseTemplates(fsys, ""); err == nil {
+		t.Fatal("parsed templates missing a body and other types")
+	}
+}
+
+func TestLoadBuiltinVerifiesSums(t *testing.T) {
+	if _, err := LoadBuiltin(""); err != nil {
+		t.Fatalf("built-in templates: %v", err)
+	}
+	fsys := fstest.MapFS{
+		"payout.sent.tmpl": {Data: []byte("edited")},
+		sumsFile:           {Data: []byte("fcddc0dadf18f99e61e95fedcd175d3aa8ca591d53f0514dc04e7704ddf91a35  payout.sent.tmpl\n")},
+	}
+	if err := verifySums(fsys); err == nil {
+		t.Error("verified an edited template")
+	}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	write := func(typ, title string) {
+		body := `{{define "title"}}` + title + `{{end}}{{define "summary"}}s{{end}}{{define "link"}}/{{end}}{{define "body"}}b{{end}}`
+		if err := os.WriteFile(filepath.Join(dir, typ+".tmpl"), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, typ := range Types {
+		write(typ, "v1")
+	}
+	r, err := NewReloader(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	title := func() string {
+		out, err := r.Render(Event{Type: TypePayoutSent})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out.Title
+	}
+
+	if changed, err := r.reload(); changed || err != nil {
+		t.Errorf("unchanged reload = %v, %v", changed, err)
+	}
+	write(TypePayoutSent, "v2")
+	if changed, err := r.reload(); !changed || err != nil || title() != "v2" {
+		t.Errorf("reload = %v, %v; title %q", changed, err, title())
+	}
+	write(TypePayoutSent, "{{broken")
+	if _, err := r.reload(); err == nil {
+		t.Error("reloaded a broken template")
+	}
+	if _, err := r.reload(); err != nil {
+		t.Errorf("broken template reported twice: %v", err)
+	}
+	if title() != "v2" {
+		t.Errorf("title = %q, want the last good v2", title())
+	}
+}
+
+func TestPreferenceCheck(t *testing.T) {
+	if len(Defaults()) != len(Types)*len(Channels) {
+		t.Fatalf("defaults = %v", Defaults())
+	}
+	for _, p := range Defaults() {
+		if err := p.Check(); err != nil || !p.Enabled {
+			t.Errorf("default %+v: %v", p, err)
+		}
+	}
+	if err := (Preference{Type: "bounty.nope", Channel: ChannelEmail}).Check(); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("unknown type err = %v", err)
+	}
+	if err := (Preference{Type: TypePayoutSent, Channel: "sms"}).Check(); !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("unknown channel err = %v", err)
+	}
+}
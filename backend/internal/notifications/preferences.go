@@ -0,0 +1,116 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Preference turns one channel on or off for one event type.
+type Preference struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Defaults are every channel's setting for users who never changed it: all
+// of them on.
+func Defaults() []Preference {
+	out := make([]Preference, 0, len(Types)*len(Channels))
+	for _, t := range Types {
+		for _, ch := range Channels {
+			out = append(out, Preference{Type: t, Channel: ch, Enabled: true})
+		}
+	}
+	return out
+}
+
+// Check validates a preference's type and channel.
+func (p Preference) Check() error {
+	if !ValidType(p.Type) {
+		return fmt.Errorf("%w: %q", ErrUnknownType, p.Type)
+	}
+	if !ValidChannel(p.Channel) {
+		return fmt.Errorf("%w: %q", ErrUnknownChannel, p.Channel)
+	}
+	return nil
+}
+
+// Preferences returns every type and channel's setting for userID, stored
+// or default.
+func Preferences(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Preference, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT type, channel, enabled FROM notification_preferences WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stored := map[[2]string]bool{}
+	for rows.Next() {
+		var p Preference
+		if err := rows.Scan(&p.Type, &p.Channel, &p.Enabled); err != nil {
+			return nil, err
+		}
+		stored[[2]string{p.Type, p.Channel}] = p.Enabled
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := Defaults()
+	for i, p := range out {
+		if enabled, ok := stored[[2]string{p.Type, p.Channel}]; ok {
+			out[i].Enabled = enabled
+		}
+	}
+	return out, nil
+}
+
+// SetPreferences stores the given settings, leaving the others as they are,
+// and returns them all.
+func SetPreferences(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, prefs []Preference) ([]Preference, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	for _, p := range prefs {
+		if err := p.Check(); err != nil {
+			return nil, err
+		}
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	for _, p := range prefs {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO notification_preferences (user_id, type, channel, enabled) VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, type, channel) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = now()
+`, userID, p.Type, p.Channel, p.Enabled); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return Preferences(ctx, pool, userID)
+}
+
+// enabledChannels returns the channels userID receives events of type typ
+// on.
+func enabledChannels(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, typ string) (map[string]bool, error) {
+	prefs, err := Preferences(ctx, pool, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]bool{}
+	for _, p := range prefs {
+		if p.Type == typ && p.Enabled {
+			out[p.Channel] = true
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Reloader renders with templates read from a directory on disk, reparsing
+// them when they change so they can be edited without a restart. It is for
+// development; other environments use LoadBuiltin.
+type Reloader struct {
+	Dir     string
+	BaseURL string
+	cur     atomic.Pointer[Templates]
+	// failed is the digest of files that last failed to parse, so a broken
+	// edit is reported once.
+	failed string
+}
+
+// NewReloader parses the templates in dir.
+func NewReloader(dir, baseURL string) (*Reloader, error) {
+	t, err := ParseTemplates(os.DirFS(dir), baseURL)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reloader{Dir: dir, BaseURL: baseURL}
+	r.cur.Store(t)
+	return r, nil
+}
+
+// Render renders e with the latest templates that parsed.
+func (r *Reloader) Render(e Event) (Rendered, error) {
+	return r.cur.Load().Render(e)
+}
+
+// Watch checks Dir every interval until ctx is done. Templates that fail to
+// parse are logged once and the previous ones kept until the next edit.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		changed, err := r.reload()
+		switch {
+		case err != nil:
+			slog.Error("notification templates not reloaded", "dir", r.Dir, "error", err)
+		case changed:
+			slog.Info("notification templates reloaded", "dir", r.Dir, "digest", r.cur.Load().Digest)
+		}
+	}
+}
+
+// reload reparses the templates if their files changed, reporting whether
+// new ones are in use.
+func (r *Reloader) reload() (bool, error) {
+	fsys := os.DirFS(r.Dir)
+	digest, err := templatesDigest(fsys)
+	if err != nil {
+		return false, err
+	}
+	if digest == r.cur.Load().Digest || digest == r.failed {
+		return false, nil
+	}
+	t, err := ParseTemplates(fsys, r.BaseURL)
+	if err != nil {
+		r.failed = digest
+		return false, err
+	}
+	r.cur.Store(t)
+	return true, nil
+}
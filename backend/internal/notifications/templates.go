@@ -0,0 +1,151 @@
+package notifications
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+)
+
+//go:generate sh -c "cd templates && sha256sum *.tmpl > SHA256SUMS"
+
+//go:embed templates/*.tmpl templates/SHA256SUMS
+var templateFS embed.FS
+
+// BuiltinTemplates is the templates shipped with the binary.
+func BuiltinTemplates() fs.FS {
+	sub, _ := fs.Sub(templateFS, "templates")
+	return sub
+}
+
+// sumsFile lists the SHA-256 of each built-in template, as sha256sum writes
+// it. Regenerate it with go generate after editing a template.
+const sumsFile = "SHA256SUMS"
+
+// LoadBuiltin parses the built-in templates once they match sumsFile, so a
+// binary never ships a template edited without its sum being updated.
+func LoadBuiltin(baseURL string) (*Templates, error) {
+	fsys := BuiltinTemplates()
+	if err := verifySums(fsys); err != nil {
+		return nil, err
+	}
+	return ParseTemplates(fsys, baseURL)
+}
+
+func verifySums(fsys fs.FS) error {
+	sums, err := fs.ReadFile(fsys, sumsFile)
+	if err != nil {
+		return fmt.Errorf("notification templates: %w", err)
+	}
+	want := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		sum, name, ok := strings.Cut(sc.Text(), "  ")
+		if !ok {
+			continue
+		}
+		want[name] = sum
+	}
+	files, err := fs.Glob(fsys, "*.tmpl")
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		if hex.EncodeToString(sum[:]) != want[name] {
+			return fmt.Errorf("notification template %s does not match %s; run go generate", name, sumsFile)
+		}
+		delete(want, name)
+	}
+	for name := range want {
+		return fmt.Errorf("notification template %s is in %s but missing", name, sumsFile)
+	}
+	return nil
+}
+
+// Templates renders events. Each type has a file <type>.tmpl defining the
+// templates title, summary, link and body, executed over the event's Data
+// plus base_url, the frontend origin links are relative to.
+type Templates struct {
+	BaseURL string
+	// Digest is the SHA-256 of the template files, logged to tell which
+	// templates are in use.
+	Digest string
+	byType map[string]*template.Template
+}
+
+// templateNames are the templates every file must define.
+var templateNames = []string{"title", "summary", "link", "body"}
+
+// ParseTemplates parses a template file for every event type from fsys.
+func ParseTemplates(fsys fs.FS, baseURL string) (*Templates, error) {
+	digest, err := templatesDigest(fsys)
+	if err != nil {
+		return nil, err
+	}
+	t := &Templates{BaseURL: strings.TrimRight(baseURL, "/"), Digest: digest, byType: map[string]*template.Template{}}
+	for _, typ := range Types {
+		tmpl, err := template.New(typ).Option("missingkey=error").ParseFS(fsys, typ+".tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("notification template %s: %w", typ, err)
+		}
+		for _, name := range templateNames {
+			if tmpl.Lookup(name) == nil {
+				return nil, fmt.Errorf("notification template %s: no %q template", typ, name)
+			}
+		}
+		t.byType[typ] = tmpl
+	}
+	return t, nil
+}
+
+// templatesDigest hashes the file of every event type in fsys.
+func templatesDigest(fsys fs.FS) (string, error) {
+	h := sha256.New()
+	for _, typ := range Types {
+		name := typ + ".tmpl"
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return "", fmt.Errorf("notification template %s: %w", typ, err)
+		}
+		fmt.Fprintf(h, "%s %d\n", name, len(b))
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Render renders e with its type's templates.
+func (t *Templates) Render(e Event) (Rendered, error) {
+	tmpl, ok := t.byType[e.Type]
+	if !ok {
+		return Rendered{}, fmt.Errorf("%w: %s", ErrUnknownType, e.Type)
+	}
+	data := make(map[string]any, len(e.Data)+1)
+	for k, v := range e.Data {
+		data[k] = v
+	}
+	data["base_url"] = t.BaseURL
+	out := make(map[string]string, len(templateNames))
+	for _, name := range templateNames {
+		var b strings.Builder
+		if err := tmpl.ExecuteTemplate(&b, name, data); err != nil {
+			return Rendered{}, fmt.Errorf("notification template %s: %w", e.Type, err)
+		}
+		out[name] = b.String()
+	}
+	return Rendered{
+		Title:   strings.TrimSpace(out["title"]),
+		Summary: strings.TrimSpace(out["summary"]),
+		Link:    strings.TrimSpace(out["link"]),
+		Body:    strings.TrimLeft(out["body"], "\n"),
+	}, nil
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SendToAdmins posts an operational message to every account-wide channel
+// (no project) owned by an admin. It tries every channel and returns the
+// failures joined.
+func SendToAdmins(ctx context.Context, pool *pgxpool.Pool, transports map[string]Transport, tokenEncKeyB64 string, msg Message) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT c.id, c.owner_user_id
+FROM notification_channels c
+JOIN users u ON u.id = c.owner_user_id
+WHERE u.role = 'admin' AND c.project_id IS NULL
+ORDER BY c.created_at
+`)
+	if err != nil {
+		return err
+	}
+	type ref struct{ id, ownerID uuid.UUID }
+	var refs []ref
+	for rows.Next() {
+		var r ref
+		if err := rows.Scan(&r.id, &r.ownerID); err != nil {
+			rows.Close()
+			return err
+		}
+		refs = append(refs, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, r := range refs {
+		c, target, err := channelTarget(ctx, pool, r.ownerID, r.id, tokenEncKeyB64)
+		if err == nil {
+			var t Transport
+			if t, err = Lookup(transports, c.Transport); err == nil {
+				err = t.Send(ctx, target, msg)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", r.id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
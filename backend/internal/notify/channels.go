@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+// Channel is a configured notification destination. The secret is never
+// serialized.
+type Channel struct {
+	ID              uuid.UUID       `json:"id"`
+	OwnerUserID     uuid.UUID       `json:"owner_user_id"`
+	ProjectID       *uuid.UUID      `json:"project_id,omitempty"`
+	Transport       string          `json:"transport"`
+	Name            string          `json:"name"`
+	Config          json.RawMessage `json:"config"`
+	EventTypes      []string        `json:"event_types"`
+	LastDeliveredAt *time.Time      `json:"last_delivered_at,omitempty"`
+	LastError       *string         `json:"last_error,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+const channelColumns = `id, owner_user_id, project_id, transport, name, config, event_types, last_delivered_at, last_error, created_at`
+
+func scanChannel(row pgx.Row) (Channel, error) {
+	var c Channel
+	err := row.Scan(&c.ID, &c.OwnerUserID, &c.ProjectID, &c.Transport, &c.Name, &c.Config, &c.EventTypes, &c.LastDeliveredAt, &c.LastError, &c.CreatedAt)
+	return c, err
+}
+
+// NewChannel is the input to CreateChannel.
+type NewChannel struct {
+	ProjectID  *uuid.UUID
+	Transport  string
+	Name       string
+	Config     json.RawMessage
+	Secret     string
+	EventTypes []string
+}
+
+// CreateChannel validates the channel with its transport and stores it.
+// Delivery starts from the current end of the feed.
+func CreateChannel(ctx context.Context, pool *pgxpool.Pool, transports map[string]Transport, ownerID uuid.UUID, in NewChannel, tokenEncKeyB64 string) (Channel, error) {
+	if pool == nil {
+		return Channel{}, fmt.Errorf("db not configured")
+	}
+	t, err := Lookup(transports, in.Transport)
+	if err != nil {
+		return Channel{}, err
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		name = t.Name()
+	}
+	cfg, err := t.Validate(ctx, Target{Config: in.Config, Secret: in.Secret})
+	if err != nil {
+		return Channel{}, err
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return Channel{}, err
+	}
+	secret, err := cryptox.EncryptAESGCM(key, []byte(in.Secret))
+	if err != nil {
+		return Channel{}, err
+	}
+	eventTypes := in.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return scanChannel(pool.QueryRow(ctx, `
+INSERT INTO notification_channels (owner_user_id, project_id, transport, name, config, secret, event_types, cursor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT COALESCE(max(seq), 0) FROM bounty_events))
+RETURNING `+channelColumns, ownerID, in.ProjectID, t.Name(), name, cfg, secret, eventTypes))
+}
+
+func ListChannels(ctx context.Context, pool *pgxpool.Pool, ownerID uuid.UUID) ([]Channel, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+channelColumns+` FROM notification_channels WHERE owner_user_id = $1 ORDER BY created_at`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Channel{}
+	for rows.Next() {
+		c, err := scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func DeleteChannel(ctx context.Context, pool *pgxpool.Pool, ownerID, id uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM notification_channels WHERE id = $1 AND owner_user_id = $2`, id, ownerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrChannelNotFound
+	}
+	return nil
+}
+
+// channelTarget loads a channel with its decrypted secret.
+func channelTarget(ctx context.Context, pool *pgxpool.Pool, ownerID, id uuid.UUID, tokenEncKeyB64 string) (Channel, Target, error) {
+	var (
+		c      Channel
+		secret []byte
+	)
+	err := pool.QueryRow(ctx, `
+SELECT `+channelColumns+`, secret
+FROM notification_channels
+WHERE id = $1 AND owner_user_id = $2
+`, id, ownerID).Scan(&c.ID, &c.OwnerUserID, &c.ProjectID, &c.Transport, &c.Name, &c.Config, &c.EventTypes, &c.LastDeliveredAt, &c.LastError, &c.CreatedAt, &secret)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Channel{}, Target{}, ErrChannelNotFound
+	}
+	if err != nil {
+		return Channel{}, Target{}, err
+	}
+	s, err := decryptSecret(tokenEncKeyB64, secret)
+	if err != nil {
+		return Channel{}, Target{}, err
+	}
+	return c, Target{Config: c.Config, Secret: s}, nil
+}
+
+func decryptSecret(tokenEncKeyB64 string, b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", nil
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return "", err
+	}
+	out, err := cryptox.DecryptAESGCM(key, b)
+	if err != nil {
+		return "", fmt.Errorf("decrypt channel secret failed")
+	}
+	return string(out), nil
+}
+
+// SendTest posts a test message so owners can check a channel end to end.
+func SendTest(ctx context.Context, pool *pgxpool.Pool, transports map[string]Transport, ownerID, id uuid.UUID, tokenEncKeyB64 string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	c, target, err := channelTarget(ctx, pool, ownerID, id, tokenEncKeyB64)
+	if err != nil {
+		return err
+	}
+	t, err := Lookup(transports, c.Transport)
+	if err != nil {
+		return err
+	}
+	return t.Send(ctx, target, Message{ID: "test-" + uuid.NewString(), Text: "Grainlify notifications are set up for " + c.Name + "."})
+}
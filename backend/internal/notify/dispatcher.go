@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+)
+
+// Dispatcher delivers new bounty events to every notification channel,
+// advancing a channel's cursor only after its transport accepts a message.
+type Dispatcher struct {
+	Pool           *pgxpool.Pool
+	Transports     map[string]Transport
+	TokenEncKeyB64 string
+}
+
+type pendingChannel struct {
+	id         uuid.UUID
+	ownerID    uuid.UUID
+	projectID  *uuid.UUID
+	transport  string
+	config     json.RawMessage
+	secret     []byte
+	eventTypes []string
+	cursor     int64
+}
+
+// RunOnce delivers pending events and returns how many were sent.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	if d.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := d.Pool.Query(ctx, `
+SELECT id, owner_user_id, project_id, transport, config, secret, event_types, cursor
+FROM notification_channels
+ORDER BY created_at
+`)
+	if err != nil {
+		return 0, err
+	}
+	var channels []pendingChannel
+	for rows.Next() {
+		var p pendingChannel
+		if err := rows.Scan(&p.id, &p.ownerID, &p.projectID, &p.transport, &p.config, &p.secret, &p.eventTypes, &p.cursor); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		channels = append(channels, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, ch := range channels {
+		count, err := d.deliver(ctx, ch)
+		sent += count
+		if err != nil {
+			slog.Warn("notification delivery failed", "channel_id", ch.id.String(), "transport", ch.transport, "error", err)
+			_, _ = d.Pool.Exec(ctx, `UPDATE notification_channels SET last_error = $2, updated_at = now() WHERE id = $1`, ch.id, err.Error())
+		}
+	}
+	return sent, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, ch pendingChannel) (int, error) {
+	t, err := Lookup(d.Transports, ch.transport)
+	if err != nil {
+		return 0, err
+	}
+	f := bounties.EventFilter{Types: ch.eventTypes, Since: ch.cursor, Limit: bounties.MaxEventsPage, Viewer: ch.ownerID}
+	if ch.projectID != nil {
+		f.ProjectID = ch.projectID
+	} else {
+		f.OwnerID = &ch.ownerID
+	}
+	events, err := bounties.ListEvents(ctx, d.Pool, f)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	secret, err := decryptSecret(d.TokenEncKeyB64, ch.secret)
+	if err != nil {
+		return 0, err
+	}
+	target := Target{Config: ch.config, Secret: secret}
+
+	sent := 0
+	// ListEvents returns newest first; send oldest first.
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if err := t.Send(ctx, target, EventMessage(e)); err != nil {
+			return sent, err
+		}
+		seq, _ := strconv.ParseInt(e.Cursor, 10, 64)
+		if _, err := d.Pool.Exec(ctx, `
+UPDATE notification_channels
+SET cursor = GREATEST(cursor, $2), last_delivered_at = now(), last_error = NULL, updated_at = now()
+WHERE id = $1
+`, ch.id, seq); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jagadeesh/grainlify/backend/internal/safehttp"
+)
+
+// Matrix posts m.notice messages into a room through the client-server API,
+// authenticated as a bot account the community has invited to the room.
+type Matrix struct {
+	HTTP *http.Client
+}
+
+// MatrixConfig is the stored, non-secret part of a Matrix channel. The
+// bot's access token is the channel secret.
+type MatrixConfig struct {
+	Homeserver string `json:"homeserver"`
+	RoomID     string `json:"room_id"`
+	// UserID is the bot's Matrix id, filled in by Validate.
+	UserID string `json:"user_id,omitempty"`
+}
+
+func NewMatrix() *Matrix {
+	// Homeserver URLs are user supplied.
+	return &Matrix{HTTP: safehttp.NewClient()}
+}
+
+func (m *Matrix) Name() string { return TransportMatrix }
+
+// ParseMatrixConfig normalizes and checks a Matrix channel config.
+func ParseMatrixConfig(raw json.RawMessage) (MatrixConfig, error) {
+	var c MatrixConfig
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return MatrixConfig{}, invalidConfig("config must be an object")
+	}
+	c.Homeserver = strings.TrimRight(strings.TrimSpace(c.Homeserver), "/")
+	if c.Homeserver != "" && !strings.Contains(c.Homeserver, "://") {
+		c.Homeserver = "https://" + c.Homeserver
+	}
+	u, err := url.Parse(c.Homeserver)
+	if err != nil || u.Scheme != "https" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return MatrixConfig{}, invalidConfig("homeserver must be an https base URL")
+	}
+	c.Homeserver = u.Scheme + "://" + u.Host
+	c.RoomID = strings.TrimSpace(c.RoomID)
+	if !strings.HasPrefix(c.RoomID, "!") || !strings.Contains(c.RoomID, ":") {
+		return MatrixConfig{}, invalidConfig("room_id must be a room id like !abc123:example.org")
+	}
+	return c, nil
+}
+
+func (m *Matrix) do(ctx context.Context, method, endpoint, token string, body any, out any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := m.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var merr struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		_ = json.Unmarshal(raw, &merr)
+		if merr.ErrCode != "" {
+			return fmt.Errorf("matrix %s: %s", merr.ErrCode, merr.Error)
+		}
+		return fmt.Errorf("matrix: status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// Validate checks the token with whoami and that the bot has joined the room.
+func (m *Matrix) Validate(ctx context.Context, t Target) (json.RawMessage, error) {
+	c, err := ParseMatrixConfig(t.Config)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(t.Secret) == "" {
+		return nil, invalidConfig("access_token is required")
+	}
+	var who struct {
+		UserID string `json:"user_id"`
+	}
+	if err := m.do(ctx, http.MethodGet, c.Homeserver+"/_matrix/client/v3/account/whoami", t.Secret, nil, &who); err != nil {
+		return nil, invalidConfig("homeserver rejected the access token: %v", err)
+	}
+	var joined struct {
+		JoinedRooms []string `json:"joined_rooms"`
+	}
+	if err := m.do(ctx, http.MethodGet, c.Homeserver+"/_matrix/client/v3/joined_rooms", t.Secret, nil, &joined); err != nil {
+		return nil, err
+	}
+	found := false
+	for _, r := range joined.JoinedRooms {
+		if r == c.RoomID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, invalidConfig("%s has not joined %s; invite it and accept the invite first", who.UserID, c.RoomID)
+	}
+	c.UserID = who.UserID
+	return json.Marshal(c)
+}
+
+// Send posts msg as an m.notice. The transaction id is derived from msg.ID,
+// so a retried send is deduplicated by the homeserver.
+func (m *Matrix) Send(ctx context.Context, t Target, msg Message) error {
+	c, err := ParseMatrixConfig(t.Config)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		c.Homeserver, url.PathEscape(c.RoomID), url.PathEscape("grainlify-"+msg.ID))
+	return m.do(ctx, http.MethodPut, endpoint, t.Secret, map[string]string{
+		"msgtype": "m.notice",
+		"body":    msg.Text,
+	}, nil)
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseMatrixConfig(t *testing.T) {
+	c, err := ParseMatrixConfig(json.RawMessage(`{"homeserver":"matrix.example.org/","room_id":" !abc:example.org "}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Homeserver != "https://matrix.example.org" || c.RoomID != "!abc:example.org" {
+		t.Fatalf("got %+v", c)
+	}
+	for _, raw := range []string{
+		`{"homeserver":"http://matrix.example.org","room_id":"!abc:example.org"}`,
+		`{"homeserver":"https://matrix.example.org/path","room_id":"!abc:example.org"}`,
+		`{"homeserver":"https://matrix.example.org","room_id":"#general:example.org"}`,
+		`[]`,
+	} {
+		if _, err := ParseMatrixConfig(json.RawMessage(raw)); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%s: expected ErrInvalidConfig, got %v", raw, err)
+		}
+	}
+}
+
+func TestMatrixValidateAndSend(t *testing.T) {
+	var sent struct {
+		path, auth string
+		body       map[string]string
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/_matrix/client/v3/account/whoami":
+			_, _ = w.Write([]byte(`{"user_id":"@bot:example.org"}`))
+		case r.URL.Path == "/_matrix/client/v3/joined_rooms":
+			_, _ = w.Write([]byte(`{"joined_rooms":["!abc:example.org"]}`))
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/_matrix/client/v3/rooms/"):
+			sent.path, sent.auth = r.URL.EscapedPath(), r.Header.Get("Authorization")
+			_ = json.NewDecoder(r.Body).Decode(&sent.body)
+			_, _ = w.Write([]byte(`{"event_id":"$1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	m := &Matrix{HTTP: srv.Client()}
+	cfg, err := m.Validate(context.Background(), Target{
+		Config: json.RawMessage(`{"homeserver":"` + srv.URL + `","room_id":"!abc:example.org"}`),
+		Secret: "tok",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(cfg), `"user_id":"@bot:example.org"`) {
+		t.Fatalf("config = %s", cfg)
+	}
+
+	if err := m.Send(context.Background(), Target{Config: cfg, Secret: "tok"}, Message{ID: "e1", Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if sent.path != "/_matrix/client/v3/rooms/%21abc:example.org/send/m.room.message/grainlify-e1" {
+		t.Fatalf("path = %s", sent.path)
+	}
+	if sent.auth != "Bearer tok" || sent.body["msgtype"] != "m.notice" || sent.body["body"] != "hello" {
+		t.Fatalf("sent = %+v", sent)
+	}
+
+	_, err = m.Validate(context.Background(), Target{
+		Config: json.RawMessage(`{"homeserver":"` + srv.URL + `","room_id":"!other:example.org"}`),
+		Secret: "tok",
+	})
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected not-joined error, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package notify delivers bounty events to outbound chat channels. The
+// Dispatcher walks bounty_events for every row in notification_channels
+// and hands messages to the Transport named by the channel.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/commands"
+)
+
+const TransportMatrix = "matrix"
+
+var (
+	ErrUnknownTransport = errors.New("unknown_transport")
+	ErrInvalidConfig    = errors.New("invalid_channel_config")
+	ErrChannelNotFound  = errors.New("notification_channel_not_found")
+)
+
+// Message is one notification. ID is stable per event so transports with
+// idempotent sends (Matrix transaction ids) never post it twice.
+type Message struct {
+	ID   string
+	Text string
+}
+
+// Target is what a transport needs to deliver to one channel.
+type Target struct {
+	Config json.RawMessage
+	Secret string
+}
+
+// Transport sends messages to one kind of chat network.
+type Transport interface {
+	Name() string
+	// Validate checks config and credentials against the remote service and
+	// returns the normalized config to store.
+	Validate(ctx context.Context, t Target) (json.RawMessage, error)
+	Send(ctx context.Context, t Target, msg Message) error
+}
+
+// Transports returns the available transports keyed by name.
+func Transports() map[string]Transport {
+	return map[string]Transport{
+		TransportMatrix: NewMatrix(),
+	}
+}
+
+// Lookup returns the transport for name.
+func Lookup(transports map[string]Transport, name string) (Transport, error) {
+	if t, ok := transports[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return t, nil
+	}
+	return nil, ErrUnknownTransport
+}
+
+// FormatEvent renders a bounty event as a one-line chat message.
+func FormatEvent(e bounties.Event) string {
+	verb := map[string]string{
+		bounties.EventCreated:     "New bounty",
+		bounties.EventClaimed:     "Bounty claimed",
+		bounties.EventSubmitted:   "Work submitted for bounty",
+		bounties.EventApproved:    "Bounty approved",
+		bounties.EventCompleted:   "Bounty completed",
+		bounties.EventCancelled:   "Bounty cancelled",
+		bounties.EventIssueClosed: "Issue closed on bounty",
+	}[e.Type]
+	if verb == "" {
+		verb = e.Type
+	}
+	return verb + ": " + commands.FormatBounty(e.Bounty)
+}
+
+// EventMessage wraps FormatEvent with the event's stable id.
+func EventMessage(e bounties.Event) Message {
+	return Message{ID: e.ID.String(), Text: FormatEvent(e)}
+}
+
+func invalidConfig(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidConfig, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// Change kinds.
+const (
+	// ChangeAdded is a new route.
+	ChangeAdded = "added"
+	// ChangeFieldsAdded adds Fields to a route's request or response.
+	ChangeFieldsAdded = "fields_added"
+	// ChangeChanged alters existing behavior; clients may need updating.
+	ChangeChanged = "changed"
+	// ChangeDeprecated marks a route for removal; it is flagged deprecated
+	// in the spec from then on.
+	ChangeDeprecated = "deprecated"
+	// ChangeRemoved records a route that is gone. Its annotation stays in
+	// the operations map, keeping the entry, after the route is deleted.
+	ChangeRemoved = "removed"
+)
+
+var changeKinds = map[string]bool{
+	ChangeAdded:       true,
+	ChangeFieldsAdded: true,
+	ChangeChanged:     true,
+	ChangeDeprecated:  true,
+	ChangeRemoved:     true,
+}
+
+// DateLayout is the format of Change dates.
+const DateLayout = "2006-01-02"
+
+// Change is one behavior change of the API, as integrators see it.
+type Change struct {
+	// Date is the day the change shipped, as YYYY-MM-DD.
+	Date string
+	Kind string
+	// Summary says what changed and what callers should do about it.
+	Summary string
+	// Fields lists the JSON fields a fields_added change introduces.
+	Fields []string
+}
+
+// ChangelogEntry is a Change with the route it applies to; API-wide changes
+// have no method or path.
+type ChangelogEntry struct {
+	Date    string   `json:"date"`
+	Kind    string   `json:"kind"`
+	Method  string   `json:"method,omitempty"`
+	Path    string   `json:"path,omitempty"`
+	Summary string   `json:"summary"`
+	Fields  []string `json:"fields,omitempty"`
+}
+
+type Changelog struct {
+	// Latest is the date of the newest entry; poll it to detect changes.
+	Latest  string           `json:"latest,omitempty"`
+	Entries []ChangelogEntry `json:"entries"`
+}
+
+// BuildChangelog collects the changes annotated on ops and the API-wide
+// ones, newest first. Malformed annotations are an error so they are caught
+// at startup rather than served.
+func BuildChangelog(ops map[string]Operation, general []Change) (Changelog, error) {
+	out := Changelog{Entries: []ChangelogEntry{}}
+	add := func(method, path string, c Change) error {
+		where := "API-wide change"
+		if path != "" {
+			where = Key(method, path)
+		}
+		if _, err := time.Parse(DateLayout, c.Date); err != nil {
+			return fmt.Errorf("changelog: %s: date %q is not YYYY-MM-DD", where, c.Date)
+		}
+		if !changeKinds[c.Kind] {
+			return fmt.Errorf("changelog: %s: unknown kind %q", where, c.Kind)
+		}
+		if strings.TrimSpace(c.Summary) == "" {
+			return fmt.Errorf("changelog: %s: summary required", where)
+		}
+		if (c.Kind == ChangeFieldsAdded) != (len(c.Fields) > 0) {
+			return fmt.Errorf("changelog: %s: fields belong to %s changes, which need them", where, ChangeFieldsAdded)
+		}
+		out.Entries = append(out.Entries, ChangelogEntry{Date: c.Date, Kind: c.Kind, Method: method, Path: path, Summary: c.Summary, Fields: c.Fields})
+		return nil
+	}
+	for _, c := range general {
+		if err := add("", "", c); err != nil {
+			return Changelog{}, err
+		}
+	}
+	for key, op := range ops {
+		method, path, ok := strings.Cut(key, " ")
+		if !ok && len(op.Changes) > 0 {
+			return Changelog{}, fmt.Errorf("changelog: bad operation key %q", key)
+		}
+		for _, c := range op.Changes {
+			if err := add(method, path, c); err != nil {
+				return Changelog{}, err
+			}
+		}
+	}
+	sort.SliceStable(out.Entries, func(i, j int) bool {
+		a, b := out.Entries[i], out.Entries[j]
+		if a.Date != b.Date {
+			return a.Date > b.Date
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Kind < b.Kind
+	})
+	if len(out.Entries) > 0 {
+		out.Latest = out.Entries[0].Date
+	}
+	return out, nil
+}
+
+// deprecated reports whether changes include a deprecation.
+func deprecated(changes []Change) bool {
+	for _, c := range changes {
+		if c.Kind == ChangeDeprecated {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangelogHandler serves log. ?since=YYYY-MM-DD keeps only entries after
+// that day, so clients can ask for what changed since they last looked.
+func ChangelogHandler(log Changelog) fiber.Handler {
+	body, err := json.Marshal(log)
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	return func(c *fiber.Ctx) error {
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "changelog_unavailable")
+		}
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		if since := c.Query("since"); since != "" {
+			if _, err := time.Parse(DateLayout, since); err != nil {
+				return httpx.Write(c, httpx.New(fiber.StatusBadRequest, "invalid_since").WithMessage("since must be a date, YYYY-MM-DD"))
+			}
+			filtered := Changelog{Latest: log.Latest, Entries: []ChangelogEntry{}}
+			for _, e := range log.Entries {
+				if e.Date > since {
+					filtered.Entries = append(filtered.Entries, e)
+				}
+			}
+			return c.Status(fiber.StatusOK).JSON(filtered)
+		}
+		c.Set(fiber.HeaderETag, etag)
+		if c.Get(fiber.HeaderIfNoneMatch) == etag {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Status(fiber.StatusOK).Send(body)
+	}
+}
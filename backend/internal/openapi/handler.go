@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// SpecHandler serves the document for app's routes. It is generated on the
+// first request, once every route has been registered.
+func SpecHandler(app *fiber.App, info Info, serverURL string, ops map[string]Operation) fiber.Handler {
+	var (
+		once sync.Once
+		body []byte
+		err  error
+	)
+	return func(c *fiber.Ctx) error {
+		once.Do(func() {
+			body, err = json.Marshal(Generate(info, serverURL, Routes(app), ops))
+		})
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "openapi_generation_failed")
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.Status(fiber.StatusOK).Send(body)
+	}
+}
+
+// swaggerUIVersion pins the swagger-ui-dist release loaded by the docs page.
+const swaggerUIVersion = "5.17.14"
+
+var uiPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+<script>{{.Init}}</script>
+</body>
+</html>
+`))
+
+// uiInit starts Swagger UI with the spec URL as a JSON string. It is allowed
+// by its hash in the page's Content-Security-Policy, so the page runs no
+// other inline script.
+const uiInit = `
+window.onload = function () {
+  window.ui = SwaggerUIBundle({ url: %s, dom_id: "#swagger-ui", deepLinking: true, persistAuthorization: true });
+};
+`
+
+// UIHandler serves a Swagger UI page for the document at specURL.
+func UIHandler(title, specURL string) fiber.Handler {
+	url, err := json.Marshal(specURL)
+	script := fmt.Sprintf(uiInit, url)
+	sum := sha256.Sum256([]byte(script))
+	csp := "default-src 'none'; " +
+		"script-src https://unpkg.com 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'; " +
+		"style-src https://unpkg.com 'unsafe-inline'; img-src 'self' data: https://unpkg.com; connect-src 'self'; frame-ancestors 'none'"
+	var b strings.Builder
+	if err == nil {
+		err = uiPage.Execute(&b, struct {
+			Title, Version string
+			Init           template.JS
+		}{title, swaggerUIVersion, template.JS(script)})
+	}
+	page := b.String()
+	return func(c *fiber.Ctx) error {
+		if err != nil {
+			return httpx.Fail(c, fiber.StatusInternalServerError, "docs_unavailable")
+		}
+		c.Set(fiber.HeaderContentSecurityPolicy, csp)
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Status(fiber.StatusOK).SendString(page)
+	}
+}
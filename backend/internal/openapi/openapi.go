@@ -0,0 +1,339 @@
+// Package openapi generates the API's OpenAPI 3.1 document from the routes
+// registered on the Fiber app, so every handler is listed without keeping a
+// spec in sync by hand. Paths, path parameters, tags, operation ids and
+// security come from the router itself; request and response bodies come from
+// Operation annotations, whose Go types are turned into JSON Schemas.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Security scheme names.
+const (
+	// SchemeBearer is a JWT access token or an API key in Authorization.
+	SchemeBearer = "bearerAuth"
+	// SchemeAPIKey is an API key in X-API-Key.
+	SchemeAPIKey = "apiKeyHeader"
+)
+
+// Operation annotates one route. Everything is optional.
+type Operation struct {
+	Summary     string
+	Description string
+	// Tags replace the tag derived from the path.
+	Tags []string
+	// Request is a value of the JSON request body's type.
+	Request any
+	// Response is a value of the success response body's type.
+	Response any
+	// Status is the success status; it defaults to 200.
+	Status int
+	Query  []Param
+	// Security replaces the schemes inferred from the route's middleware;
+	// use it where auth is applied to a whole group.
+	Security []string
+	// Changes is the route's history for the changelog; a deprecation
+	// marks it deprecated in the document.
+	Changes []Change
+}
+
+// Param is a query parameter.
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+	// Type is a JSON Schema type; it defaults to "string".
+	Type string
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Servers    []Server                        `json:"servers,omitempty"`
+	Paths      map[string]map[string]*PathItem `json:"paths"`
+	Components Components                      `json:"components"`
+	Tags       []Tag                           `json:"tags,omitempty"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem is one operation (named for the map it lives in).
+type PathItem struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// ErrorBody is the shape of every error response.
+type ErrorBody httpx.Body
+
+// Key returns the annotation key of a route: "GET /projects/:id".
+func Key(method, path string) string { return method + " " + path }
+
+var (
+	pathParam   = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+	closureName = regexp.MustCompile(`(\.func\d+)+$`)
+)
+
+// HandlerName returns the package-qualified name of the function h was
+// built by, such as "handlers.AuthHandler.Me" or "auth.RequireAuth".
+func HandlerName(h fiber.Handler) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = closureName.ReplaceAllString(name, "")
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
+
+// inferSecurity maps the auth middleware in front of a handler to schemes.
+func inferSecurity(middleware []fiber.Handler) []string {
+	for _, h := range middleware {
+		switch name := HandlerName(h); {
+		case name == "auth.RequireAPIKey":
+			return []string{SchemeBearer, SchemeAPIKey}
+		case name == "auth.RequireAuth", name == "auth.RequireAuthOrAPIKey":
+			return []string{SchemeBearer}
+		}
+	}
+	return nil
+}
+
+// operationID turns a handler name into an id, falling back to the route.
+func operationID(name, method, path string) string {
+	parts := strings.Split(name, ".")
+	if len(parts) >= 2 && parts[0] == "handlers" {
+		return strings.Join(parts[1:], ".")
+	}
+	id := strings.ToLower(method)
+	for _, seg := range strings.Split(path, "/") {
+		seg = strings.Trim(seg, ":?")
+		if seg != "" {
+			id += "_" + strings.NewReplacer("-", "_", ".", "_").Replace(seg)
+		}
+	}
+	if id == strings.ToLower(method) {
+		id += "_root"
+	}
+	return id
+}
+
+func defaultTag(path string) string {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case segs[0] == "":
+		return "meta"
+	case segs[0] == "public" && len(segs) > 2:
+		return "public"
+	case strings.Contains(path, "/github"):
+		return "github"
+	}
+	return segs[0]
+}
+
+// Routes returns app's routes like app.GetRoutes(true), with the app and
+// group middleware registered ahead of each route prepended to its handlers.
+func Routes(app *fiber.App) []fiber.Route {
+	all, endpoints := app.GetRoutes(), app.GetRoutes(true)
+	out := make([]fiber.Route, 0, len(endpoints))
+	var uses []fiber.Route
+	for _, r := range all {
+		if len(out) < len(endpoints) && sameRoute(r, endpoints[len(out)]) {
+			var chain []fiber.Handler
+			for _, u := range uses {
+				if u.Method == r.Method && (u.Path == "/" || u.Path == r.Path || strings.HasPrefix(r.Path, u.Path+"/")) {
+					chain = append(chain, u.Handlers...)
+				}
+			}
+			r.Handlers = append(chain, r.Handlers...)
+			out = append(out, r)
+			continue
+		}
+		uses = append(uses, r)
+	}
+	return out
+}
+
+func sameRoute(a, b fiber.Route) bool {
+	if a.Method != b.Method || a.Path != b.Path || len(a.Handlers) != len(b.Handlers) {
+		return false
+	}
+	for i := range a.Handlers {
+		if reflect.ValueOf(a.Handlers[i]).Pointer() != reflect.ValueOf(b.Handlers[i]).Pointer() {
+			return false
+		}
+	}
+	return true
+}
+
+// Generate builds the document for routes, as returned by Routes or
+// app.GetRoutes(true).
+// HEAD and OPTIONS routes, wildcards and repeated registrations are skipped.
+func Generate(info Info, serverURL string, routes []fiber.Route, ops map[string]Operation) *Document {
+	s := newSchemas()
+	errSchema := s.of(ErrorBody{})
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]map[string]*PathItem{},
+		Components: Components{
+			Schemas: s.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				SchemeBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Access token from /auth/verify, or an API key where the route accepts one."},
+				SchemeAPIKey: {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "API key, for the integrations routes."},
+			},
+		},
+	}
+	if serverURL != "" {
+		doc.Servers = []Server{{URL: strings.TrimRight(serverURL, "/")}}
+	}
+
+	seenIDs := map[string]int{}
+	tags := map[string]bool{}
+	for _, r := range routes {
+		if r.Method == fiber.MethodHead || r.Method == fiber.MethodOptions || strings.Contains(r.Path, "*") || len(r.Handlers) == 0 {
+			continue
+		}
+		path := pathParam.ReplaceAllString(r.Path, "{$1}")
+		method := strings.ToLower(r.Method)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*PathItem{}
+		}
+		if doc.Paths[path][method] != nil {
+			continue
+		}
+
+		op := ops[Key(r.Method, r.Path)]
+		handler := HandlerName(r.Handlers[len(r.Handlers)-1])
+		id := operationID(handler, r.Method, r.Path)
+		if seenIDs[id]++; seenIDs[id] > 1 {
+			id += "_" + strconv.Itoa(seenIDs[id])
+		}
+		item := &PathItem{
+			OperationID: id,
+			Summary:     op.Summary,
+			Description: op.Description,
+			Tags:        op.Tags,
+			Responses:   map[string]Response{},
+			Deprecated:  deprecated(op.Changes),
+		}
+		if len(item.Tags) == 0 {
+			item.Tags = []string{defaultTag(r.Path)}
+		}
+		for _, t := range item.Tags {
+			tags[t] = true
+		}
+
+		for _, p := range r.Params {
+			item.Parameters = append(item.Parameters, Parameter{Name: p, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, q := range op.Query {
+			typ := q.Type
+			if typ == "" {
+				typ = "string"
+			}
+			item.Parameters = append(item.Parameters, Parameter{Name: q.Name, In: "query", Description: q.Description, Required: q.Required, Schema: &Schema{Type: typ}})
+		}
+
+		if op.Request != nil {
+			item.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: s.of(op.Request)}}}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ok := Response{Description: http.StatusText(status)}
+		if op.Response != nil {
+			ok.Content = map[string]MediaType{"application/json": {Schema: s.of(op.Response)}}
+		}
+		item.Responses[strconv.Itoa(status)] = ok
+		item.Responses["default"] = Response{Description: "Error", Content: map[string]MediaType{"application/json": {Schema: errSchema}}}
+
+		security := op.Security
+		if security == nil {
+			security = inferSecurity(r.Handlers[:len(r.Handlers)-1])
+		}
+		for _, name := range security {
+			item.Security = append(item.Security, map[string][]string{name: {}})
+		}
+		doc.Paths[path][method] = item
+	}
+
+	for t := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: t})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	return doc
+}
@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+type widget struct {
+	ID        string     `json:"id"`
+	Note      *string    `json:"note"`
+	Tags      []string   `json:"tags,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	secret    string
+}
+
+func TestGenerate(t *testing.T) {
+	app := fiber.New()
+	noop := func(c *fiber.Ctx) error { return nil }
+	app.Get("/", noop)
+	app.Get("/widgets/:id", auth.RequireAuth("secret", nil), noop)
+	app.Post("/widgets", noop)
+	app.Use(noop)
+
+	doc := Generate(Info{Title: "t", Version: "1"}, "https://api.example.com/", app.GetRoutes(true), map[string]Operation{
+		Key(http.MethodGet, "/widgets/:id"): {Response: widget{}},
+		Key(http.MethodPost, "/widgets"):    {Request: widget{}, Response: widget{}, Status: http.StatusCreated},
+	})
+
+	if doc.Servers[0].URL != "https://api.example.com" {
+		t.Errorf("server = %q", doc.Servers[0].URL)
+	}
+	get := doc.Paths["/widgets/{id}"]["get"]
+	if get == nil {
+		t.Fatalf("missing GET /widgets/{id}: %v", doc.Paths)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "id" || get.Parameters[0].In != "path" {
+		t.Errorf("parameters = %+v", get.Parameters)
+	}
+	if len(get.Security) != 1 || get.Security[0][SchemeBearer] == nil {
+		t.Errorf("security = %v", get.Security)
+	}
+	if get.Tags[0] != "widgets" {
+		t.Errorf("tags = %v", get.Tags)
+	}
+	if ref := get.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/Widget" {
+		t.Errorf("response ref = %q", ref)
+	}
+
+	post := doc.Paths["/widgets"]["post"]
+	if post == nil || post.Security != nil || post.RequestBody == nil {
+		t.Fatalf("POST /widgets = %+v", post)
+	}
+	if _, ok := post.Responses["201"]; !ok {
+		t.Errorf("responses = %v", post.Responses)
+	}
+	if doc.Paths["/"]["get"].Tags[0] != "meta" {
+		t.Errorf("root tags = %v", doc.Paths["/"]["get"].Tags)
+	}
+
+	w := doc.Components.Schemas["Widget"]
+	if w == nil {
+		t.Fatalf("Widget not registered: %v", doc.Components.Schemas)
+	}
+	if _, ok := w.Properties["secret"]; ok {
+		t.Error("unexported field listed")
+	}
+	if typ, ok := w.Properties["note"].Type.([]string); !ok || typ[1] != "null" {
+		t.Errorf("note type = %v", w.Properties["note"].Type)
+	}
+	if f := w.Properties["created_at"]; f.Format != "date-time" {
+		t.Errorf("created_at = %+v", f)
+	}
+	required := map[string]bool{}
+	for _, r := range w.Required {
+		required[r] = true
+	}
+	if !required["id"] || !required["created_at"] || required["note"] || required["tags"] {
+		t.Errorf("required = %v", w.Required)
+	}
+}
+
+func TestRoutesGroupMiddleware(t *testing.T) {
+	app := fiber.New()
+	noop := func(c *fiber.Ctx) error { return nil }
+	app.Get("/open", noop)
+	admin := app.Group("/admin", auth.RequireAuth("secret", nil))
+	admin.Get("/users", noop)
+	app.Get("/administrators", noop)
+
+	routes := Routes(app)
+	got := map[string]int{}
+	for _, r := range routes {
+		got[Key(r.Method, r.Path)] = len(r.Handlers)
+	}
+	if len(got) != 6 || got["GET /admin/users"] != 2 || got["GET /open"] != 1 || got["GET /administrators"] != 1 {
+		t.Fatalf("routes = %v", got)
+	}
+	doc := Generate(Info{Title: "t", Version: "1"}, "", routes, nil)
+	if s := doc.Paths["/admin/users"]["get"].Security; len(s) != 1 || s[0][SchemeBearer] == nil {
+		t.Errorf("group security = %v", s)
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	ops := map[string]Operation{
+		Key(http.MethodGet, "/widgets"): {Changes: []Change{
+			{Date: "2026-01-02", Kind: ChangeAdded, Summary: "Lists widgets."},
+			{Date: "2026-03-01", Kind: ChangeDeprecated, Summary: "Use /gadgets."},
+		}},
+		Key(http.MethodGet, "/widgets/:id"): {Changes: []Change{
+			{Date: "2026-02-01", Kind: ChangeFieldsAdded, Summary: "Colors.", Fields: []string{"color"}},
+		}},
+		Key(http.MethodDelete, "/widgets/:id"): {Changes: []Change{{Date: "2026-03-01", Kind: ChangeRemoved, Summary: "Gone."}}},
+	}
+	log, err := BuildChangelog(ops, []Change{{Date: "2026-02-15", Kind: ChangeChanged, Summary: "Stricter bodies."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range log.Entries {
+		got = append(got, e.Date+" "+e.Kind+" "+e.Method+" "+e.Path)
+	}
+	want := []string{
+		"2026-03-01 deprecated GET /widgets",
+		"2026-03-01 removed DELETE /widgets/:id",
+		"2026-02-15 changed  ",
+		"2026-02-01 fields_added GET /widgets/:id",
+		"2026-01-02 added GET /widgets",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") || log.Latest != "2026-03-01" {
+		t.Errorf("changelog (latest %s):\n%s", log.Latest, strings.Join(got, "\n"))
+	}
+
+	for _, bad := range []Change{
+		{Date: "03/01/2026", Kind: ChangeAdded, Summary: "x"},
+		{Date: "2026-03-01", Kind: "renamed", Summary: "x"},
+		{Date: "2026-03-01", Kind: ChangeAdded},
+		{Date: "2026-03-01", Kind: ChangeFieldsAdded, Summary: "x"},
+	} {
+		if _, err := BuildChangelog(nil, []Change{bad}); err == nil {
+			t.Errorf("accepted %+v", bad)
+		}
+	}
+
+	app := fiber.New()
+	noop := func(c *fiber.Ctx) error { return nil }
+	app.Get("/widgets", noop)
+	app.Get("/widgets/:id", noop)
+	doc := Generate(Info{Title: "t", Version: "1"}, "", app.GetRoutes(true), ops)
+	if !doc.Paths["/widgets"]["get"].Deprecated || doc.Paths["/widgets/{id}"]["get"].Deprecated {
+		t.Error("deprecation not reflected in the document")
+	}
+}
@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schema is a JSON Schema (2020-12, as used by OpenAPI 3.1).
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas builds component schemas from Go types, following encoding/json's
+// rules for field names, omitempty and embedded structs.
+type schemas struct {
+	// prefix is prepended to component names in refs.
+	prefix     string
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{prefix: "#/components/schemas/", components: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// Definitions builds JSON Schemas of Go types outside an OpenAPI document:
+// named structs are referenced as #/$defs/<name>, so Defs belongs under
+// "$defs" at the root of the document the schemas are served in.
+type Definitions struct {
+	s *schemas
+}
+
+func NewDefinitions() *Definitions {
+	return &Definitions{s: &schemas{prefix: "#/$defs/", components: map[string]*Schema{}, names: map[reflect.Type]string{}}}
+}
+
+// Of returns the schema of v's type.
+func (d *Definitions) Of(v any) *Schema { return d.s.of(v) }
+
+// Defs are the structs schemas returned so far refer to.
+func (d *Definitions) Defs() map[string]*Schema { return d.s.components }
+
+// of returns the schema of v's type, registering named structs as components.
+func (s *schemas) of(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+	return s.forType(reflect.TypeOf(v))
+}
+
+func (s *schemas) forType(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	sc := s.forValueType(t)
+	if nullable && sc.Ref == "" {
+		if typ, ok := sc.Type.(string); ok {
+			sc.Type = []string{typ, "null"}
+		}
+	}
+	return sc
+}
+
+func (s *schemas) forValueType(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.forType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.forType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: s.prefix + s.register(t)}
+	default:
+		return &Schema{}
+	}
+}
+
+// register adds a named struct to the components, keyed by its type name
+// (prefixed with its package when two packages share a name).
+func (s *schemas) register(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := exportedName(t.Name())
+	if _, taken := s.components[name]; taken {
+		pkg := t.PkgPath()
+		name = exportedName(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+	}
+	s.names[t] = name
+	// Reserve the name before recursing so self-references terminate.
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+	return name
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(obj, t)
+	return obj
+}
+
+func (s *schemas) addFields(obj *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(obj, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		obj.Properties[name] = s.forType(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
+
+func exportedName(n string) string {
+	if n == "" {
+		return n
+	}
+	return strings.ToUpper(n[:1]) + n[1:]
+}
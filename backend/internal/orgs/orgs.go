@@ -0,0 +1,373 @@
+// Package orgs links GitHub organizations through GitHub App installations,
+// so that any admin of a linked organization can manage the organization's
+// projects, not only the user who registered each one. Admins can also
+// define custom roles granting other members a subset of that, such as
+// approving payouts without editing bounties.
+//
+// A project belongs to the organization whose login owns its repository.
+// Memberships are mirrored from GitHub when an organization is linked and
+// then refreshed by Syncer; ownership checks only read the mirror.
+package orgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// Member roles, as GitHub reports them.
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+var (
+	ErrNotFound        = errors.New("github_org_not_found")
+	ErrNotOrganization = errors.New("installation_not_on_organization")
+	ErrNotOrgAdmin     = errors.New("not_github_org_admin")
+	ErrNoGitHubAccount = errors.New("github_not_linked")
+)
+
+// App is the part of the GitHub App client linking needs.
+type App interface {
+	GetInstallation(ctx context.Context, installationID string) (github.Installation, error)
+	GetInstallationToken(ctx context.Context, installationID string) (string, error)
+	ListOrgMembers(ctx context.Context, installationToken, org, role string) ([]github.OrgMember, error)
+}
+
+// Org is a linked GitHub organization.
+type Org struct {
+	ID              uuid.UUID  `json:"id"`
+	GitHubOrgID     int64      `json:"github_org_id"`
+	Login           string     `json:"login"`
+	InstallationID  string     `json:"installation_id"`
+	LinkedBy        *uuid.UUID `json:"linked_by,omitempty"`
+	Admins          int        `json:"admins"`
+	Members         int        `json:"members"`
+	MembersSyncedAt *time.Time `json:"members_synced_at,omitempty"`
+	SyncError       *string    `json:"sync_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Member is a mirrored membership.
+type Member struct {
+	GitHubUserID int64  `json:"github_user_id"`
+	Login        string `json:"login"`
+	Role         string `json:"role"`
+}
+
+// Roles merges GitHub's member list with its admin list into one membership
+// per user, sorted by login.
+func Roles(all, admins []github.OrgMember) []Member {
+	byID := map[int64]Member{}
+	for _, m := range all {
+		byID[m.ID] = Member{GitHubUserID: m.ID, Login: m.Login, Role: RoleMember}
+	}
+	for _, m := range admins {
+		byID[m.ID] = Member{GitHubUserID: m.ID, Login: m.Login, Role: RoleAdmin}
+	}
+	out := make([]Member, 0, len(byID))
+	for _, m := range byID {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Login) < strings.ToLower(out[j].Login) })
+	return out
+}
+
+const orgColumns = `o.id, o.github_org_id, o.login, o.installation_id, o.linked_by,
+(SELECT COUNT(*) FROM github_org_members m WHERE m.org_id = o.id AND m.role = 'admin'),
+(SELECT COUNT(*) FROM github_org_members m WHERE m.org_id = o.id),
+o.members_synced_at, o.sync_error, o.created_at`
+
+func scanOrg(row pgx.Row) (Org, error) {
+	var o Org
+	err := row.Scan(&o.ID, &o.GitHubOrgID, &o.Login, &o.InstallationID, &o.LinkedBy,
+		&o.Admins, &o.Members, &o.MembersSyncedAt, &o.SyncError, &o.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Org{}, ErrNotFound
+	}
+	return o, err
+}
+
+// Link links the organization an installation of the app is on, and syncs
+// its members. userID must be one of its admins.
+func Link(ctx context.Context, pool *pgxpool.Pool, app App, installationID string, userID uuid.UUID) (Org, error) {
+	if pool == nil {
+		return Org{}, fmt.Errorf("db not configured")
+	}
+	inst, err := app.GetInstallation(ctx, installationID)
+	if err != nil {
+		return Org{}, err
+	}
+	if inst.Account.Type != "Organization" {
+		return Org{}, ErrNotOrganization
+	}
+	token, err := app.GetInstallationToken(ctx, installationID)
+	if err != nil {
+		return Org{}, err
+	}
+	members, err := fetchMembers(ctx, app, token, inst.Account.Login)
+	if err != nil {
+		return Org{}, err
+	}
+	ghUserID, err := githubUserID(ctx, pool, userID)
+	if err != nil {
+		return Org{}, err
+	}
+	if !hasAdmin(members, ghUserID) {
+		return Org{}, ErrNotOrgAdmin
+	}
+
+	var id uuid.UUID
+	if err := pool.QueryRow(ctx, `
+INSERT INTO github_orgs (github_org_id, login, installation_id, linked_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (github_org_id) DO UPDATE SET
+  login = EXCLUDED.login,
+  installation_id = EXCLUDED.installation_id,
+  linked_by = EXCLUDED.linked_by,
+  updated_at = now()
+RETURNING id
+`, inst.Account.ID, inst.Account.Login, installationID, userID).Scan(&id); err != nil {
+		return Org{}, err
+	}
+	if err := storeMembers(ctx, pool, id, members); err != nil {
+		return Org{}, err
+	}
+	return Get(ctx, pool, id)
+}
+
+func fetchMembers(ctx context.Context, app App, token, login string) ([]Member, error) {
+	all, err := app.ListOrgMembers(ctx, token, login, "all")
+	if err != nil {
+		return nil, err
+	}
+	admins, err := app.ListOrgMembers(ctx, token, login, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return Roles(all, admins), nil
+}
+
+func hasAdmin(members []Member, ghUserID int64) bool {
+	for _, m := range members {
+		if m.GitHubUserID == ghUserID && m.Role == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func githubUserID(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (int64, error) {
+	var id int64
+	err := pool.QueryRow(ctx, `SELECT github_user_id FROM github_accounts WHERE user_id = $1`, userID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNoGitHubAccount
+	}
+	return id, err
+}
+
+// storeMembers replaces an organization's mirrored memberships.
+func storeMembers(ctx context.Context, pool *pgxpool.Pool, orgID uuid.UUID, members []Member) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM github_org_members WHERE org_id = $1`, orgID); err != nil {
+		return err
+	}
+	for _, m := range members {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO github_org_members (org_id, github_user_id, login, role) VALUES ($1, $2, $3, $4)
+`, orgID, m.GitHubUserID, m.Login, m.Role); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE github_orgs SET members_synced_at = now(), sync_error = NULL, updated_at = now() WHERE id = $1
+`, orgID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SyncMembers refreshes an organization's mirrored memberships from GitHub.
+// A failure is recorded on the organization and the old mirror kept.
+func SyncMembers(ctx context.Context, pool *pgxpool.Pool, app App, o Org) error {
+	token, err := app.GetInstallationToken(ctx, o.InstallationID)
+	var members []Member
+	if err == nil {
+		members, err = fetchMembers(ctx, app, token, o.Login)
+	}
+	if err != nil {
+		_, _ = pool.Exec(ctx, `UPDATE github_orgs SET sync_error = $2, updated_at = now() WHERE id = $1`, o.ID, err.Error())
+		return err
+	}
+	return storeMembers(ctx, pool, o.ID, members)
+}
+
+// Get returns a linked organization.
+func Get(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (Org, error) {
+	if pool == nil {
+		return Org{}, fmt.Errorf("db not configured")
+	}
+	return scanOrg(pool.QueryRow(ctx, `SELECT `+orgColumns+` FROM github_orgs o WHERE o.id = $1`, id))
+}
+
+// ForUser returns the linked organizations userID is an admin of.
+func ForUser(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Org, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+orgColumns+`
+FROM github_orgs o
+JOIN github_org_members m ON m.org_id = o.id AND m.role = 'admin'
+JOIN github_accounts ga ON ga.github_user_id = m.github_user_id
+WHERE ga.user_id = $1
+ORDER BY lower(o.login)
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Org{}
+	for rows.Next() {
+		o, err := scanOrg(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+// IsAdmin reports whether userID is an admin of the linked organization.
+func IsAdmin(ctx context.Context, pool *pgxpool.Pool, orgID, userID uuid.UUID) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM github_org_members m
+  JOIN github_accounts ga ON ga.github_user_id = m.github_user_id
+  WHERE m.org_id = $1 AND m.role = 'admin' AND ga.user_id = $2
+)`, orgID, userID).Scan(&ok)
+	return ok, err
+}
+
+// Unlink drops a linked organization. Its projects go back to being managed
+// by their owners alone.
+func Unlink(ctx context.Context, pool *pgxpool.Pool, orgID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM github_orgs WHERE id = $1`, orgID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AdminOf is an SQL condition that holds when the user in userArg is an
+// admin of the linked organization owning the repository of the project
+// aliased project.
+func AdminOf(project, userArg string) string {
+	return `EXISTS (
+  SELECT 1 FROM github_orgs o
+  JOIN github_org_members m ON m.org_id = o.id AND m.role = 'admin'
+  JOIN github_accounts ga ON ga.github_user_id = m.github_user_id
+  WHERE lower(o.login) = lower(split_part(` + project + `.github_full_name, '/', 1))
+    AND ga.user_id = ` + userArg + `
+)`
+}
+
+// MemberOf is an SQL condition that holds when the user in userArg is a
+// member, admin or not, of the linked organization owning the repository
+// of the project aliased project.
+func MemberOf(project, userArg string) string {
+	return `EXISTS (
+  SELECT 1 FROM github_orgs o
+  JOIN github_org_members m ON m.org_id = o.id
+  JOIN github_accounts ga ON ga.github_user_id = m.github_user_id
+  WHERE lower(o.login) = lower(split_part(` + project + `.github_full_name, '/', 1))
+    AND ga.user_id = ` + userArg + `
+)`
+}
+
+// IsProjectAdmin reports whether userID is an admin of the linked
+// organization owning projectID's repository.
+func IsProjectAdmin(ctx context.Context, pool *pgxpool.Pool, projectID, userID uuid.UUID) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `SELECT `+AdminOf("p", "$2")+` FROM projects p WHERE p.id = $1`, projectID, userID).Scan(&ok)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// Syncer refreshes the memberships of linked organizations not synced for
+// MaxAge.
+type Syncer struct {
+	Pool   *pgxpool.Pool
+	App    App
+	MaxAge time.Duration
+}
+
+// RunOnce syncs every stale organization, carrying on past failures.
+func (s *Syncer) RunOnce(ctx context.Context) error {
+	rows, err := s.Pool.Query(ctx, `
+SELECT `+orgColumns+`
+FROM github_orgs o
+WHERE o.members_synced_at IS NULL OR o.members_synced_at < now() - make_interval(secs => $1)
+ORDER BY o.members_synced_at NULLS FIRST
+`, s.MaxAge.Seconds())
+	if err != nil {
+		return err
+	}
+	var stale []Org
+	for rows.Next() {
+		o, err := scanOrg(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		stale = append(stale, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, o := range stale {
+		if err := SyncMembers(ctx, s.Pool, s.App, o); err != nil {
+			slog.Warn("github org member sync failed", "org", o.Login, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if len(stale) > 0 {
+		slog.Info("synced github org members", "count", len(stale))
+	}
+	return firstErr
+}
@@ -0,0 +1,51 @@
+package orgs
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+func TestRolesMergesAdmins(t *testing.T) {
+	all := []github.OrgMember{{ID: 1, Login: "zed"}, {ID: 2, Login: "Amy"}, {ID: 3, Login: "bob"}}
+	admins := []github.OrgMember{{ID: 3, Login: "bob"}}
+	got := Roles(all, admins)
+	want := []Member{
+		{GitHubUserID: 2, Login: "Amy", Role: RoleMember},
+		{GitHubUserID: 3, Login: "bob", Role: RoleAdmin},
+		{GitHubUserID: 1, Login: "zed", Role: RoleMember},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d members, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("member %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if !hasAdmin(got, 3) || hasAdmin(got, 1) {
+		t.Error("hasAdmin disagrees with the merged roles")
+	}
+}
+
+func TestRoleDraftCheck(t *testing.T) {
+	d := RoleDraft{Name: "  Treasurer ", Permissions: []Permission{PermViewFinances, PermApprovePayouts, PermViewFinances}}
+	if err := d.Check(); err != nil {
+		t.Fatalf("valid role: %v", err)
+	}
+	if d.Name != "Treasurer" || !slices.Equal(d.Permissions, []Permission{PermApprovePayouts, PermViewFinances}) {
+		t.Errorf("normalized role = %+v", d)
+	}
+	for name, bad := range map[string]RoleDraft{
+		"no name":            {Permissions: []Permission{PermEditBounties}},
+		"built-in name":      {Name: "Admin", Permissions: []Permission{PermEditBounties}},
+		"no permissions":     {Name: "reviewer"},
+		"unknown permission": {Name: "reviewer", Permissions: []Permission{"bounties:delete"}},
+	} {
+		if err := bad.Check(); !errors.Is(err, ErrInvalidRole) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+}
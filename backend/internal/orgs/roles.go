@@ -0,0 +1,357 @@
+package orgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Permission is something an organization role can allow over the
+// organization's projects. Org admins hold every permission.
+type Permission string
+
+const (
+	// PermManageProjects covers project settings: registry details,
+	// verification, syncs, custom metadata fields, escrow addresses,
+	// accounting endpoints and notification channels.
+	PermManageProjects Permission = "projects:manage"
+	// PermEditBounties covers creating, editing and cancelling bounties,
+	// releasing claims and proposing splits.
+	PermEditBounties Permission = "bounties:edit"
+	// PermApprovePayouts covers approving submissions and paying out,
+	// escrow releases included.
+	PermApprovePayouts Permission = "payouts:approve"
+	// PermViewFinances covers escrow balances, accounting proofs, bounty
+	// histories, splits and logged time.
+	PermViewFinances Permission = "finances:view"
+)
+
+// Permissions are all the permissions a role can grant.
+var Permissions = []Permission{PermManageProjects, PermEditBounties, PermApprovePayouts, PermViewFinances}
+
+// MaxRoleName bounds a role's name.
+const MaxRoleName = 50
+
+var (
+	ErrRoleNotFound = errors.New("github_org_role_not_found")
+	ErrRoleExists   = errors.New("github_org_role_exists")
+	ErrInvalidRole  = errors.New("invalid_github_org_role")
+	ErrNotMember    = errors.New("not_github_org_member")
+	ErrNotAssigned  = errors.New("github_org_role_not_assigned")
+)
+
+// Role is a custom role of a linked organization.
+type Role struct {
+	ID          uuid.UUID      `json:"id"`
+	OrgID       uuid.UUID      `json:"org_id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Permissions []Permission   `json:"permissions"`
+	Members     []RoleAssignee `json:"members"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// RoleAssignee is a member assigned a role. Active is false once they have
+// left the organization on GitHub, which suspends the role for them.
+type RoleAssignee struct {
+	GitHubUserID int64     `json:"github_user_id"`
+	Login        string    `json:"login,omitempty"`
+	Active       bool      `json:"active"`
+	AssignedAt   time.Time `json:"assigned_at"`
+}
+
+// RoleDraft is a role as its organization's admins define it.
+type RoleDraft struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Check normalizes d: the name is trimmed and may not shadow GitHub's roles,
+// and the permissions must be known, at least one, and are deduplicated in
+// the order of Permissions.
+func (d *RoleDraft) Check() error {
+	d.Name = strings.TrimSpace(d.Name)
+	d.Description = strings.TrimSpace(d.Description)
+	if d.Name == "" || len(d.Name) > MaxRoleName {
+		return fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidRole, MaxRoleName)
+	}
+	switch strings.ToLower(d.Name) {
+	case RoleAdmin, RoleMember, "owner":
+		return fmt.Errorf("%w: %q is a built-in role", ErrInvalidRole, d.Name)
+	}
+	for _, p := range d.Permissions {
+		if !slices.Contains(Permissions, p) {
+			return fmt.Errorf("%w: unknown permission %q", ErrInvalidRole, p)
+		}
+	}
+	var perms []Permission
+	for _, p := range Permissions {
+		if slices.Contains(d.Permissions, p) {
+			perms = append(perms, p)
+		}
+	}
+	if len(perms) == 0 {
+		return fmt.Errorf("%w: at least one permission is required", ErrInvalidRole)
+	}
+	d.Permissions = perms
+	return nil
+}
+
+func roleWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrRoleExists
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrRoleNotFound
+	}
+	return err
+}
+
+func permStrings(perms []Permission) []string {
+	out := make([]string, len(perms))
+	for i, p := range perms {
+		out[i] = string(p)
+	}
+	return out
+}
+
+// CreateRole defines a role in orgID.
+func CreateRole(ctx context.Context, pool *pgxpool.Pool, orgID uuid.UUID, d RoleDraft, createdBy uuid.UUID) (Role, error) {
+	if pool == nil {
+		return Role{}, fmt.Errorf("db not configured")
+	}
+	if err := d.Check(); err != nil {
+		return Role{}, err
+	}
+	var id uuid.UUID
+	err := pool.QueryRow(ctx, `
+INSERT INTO github_org_roles (org_id, name, description, permissions, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id
+`, orgID, d.Name, d.Description, permStrings(d.Permissions), createdBy).Scan(&id)
+	if err != nil {
+		return Role{}, roleWriteError(err)
+	}
+	return GetRole(ctx, pool, orgID, id)
+}
+
+// UpdateRole redefines one of orgID's roles; its assignments are kept.
+func UpdateRole(ctx context.Context, pool *pgxpool.Pool, orgID, roleID uuid.UUID, d RoleDraft) (Role, error) {
+	if pool == nil {
+		return Role{}, fmt.Errorf("db not configured")
+	}
+	if err := d.Check(); err != nil {
+		return Role{}, err
+	}
+	var id uuid.UUID
+	err := pool.QueryRow(ctx, `
+UPDATE github_org_roles
+SET name = $3, description = $4, permissions = $5, updated_at = now()
+WHERE org_id = $1 AND id = $2
+RETURNING id
+`, orgID, roleID, d.Name, d.Description, permStrings(d.Permissions)).Scan(&id)
+	if err != nil {
+		return Role{}, roleWriteError(err)
+	}
+	return GetRole(ctx, pool, orgID, id)
+}
+
+// DeleteRole removes one of orgID's roles along with its assignments.
+func DeleteRole(ctx context.Context, pool *pgxpool.Pool, orgID, roleID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM github_org_roles WHERE org_id = $1 AND id = $2`, orgID, roleID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRoleNotFound
+	}
+	return nil
+}
+
+const roleQuery = `
+SELECT r.id, r.org_id, r.name, r.description, r.permissions, r.created_at, r.updated_at,
+  a.github_user_id, m.login, m.github_user_id IS NOT NULL, a.assigned_at
+FROM github_org_roles r
+LEFT JOIN github_org_role_assignments a ON a.role_id = r.id
+LEFT JOIN github_org_members m ON m.org_id = r.org_id AND m.github_user_id = a.github_user_id
+`
+
+// scanRoles folds the rows of roleQuery into roles, in row order.
+func scanRoles(rows pgx.Rows) ([]Role, error) {
+	defer rows.Close()
+	out := []Role{}
+	for rows.Next() {
+		var r Role
+		var perms []string
+		var ghUserID *int64
+		var login *string
+		var active bool
+		var assignedAt *time.Time
+		if err := rows.Scan(&r.ID, &r.OrgID, &r.Name, &r.Description, &perms, &r.CreatedAt, &r.UpdatedAt,
+			&ghUserID, &login, &active, &assignedAt); err != nil {
+			return nil, err
+		}
+		if n := len(out); n == 0 || out[n-1].ID != r.ID {
+			for _, p := range perms {
+				r.Permissions = append(r.Permissions, Permission(p))
+			}
+			r.Members = []RoleAssignee{}
+			out = append(out, r)
+		}
+		if ghUserID != nil {
+			a := RoleAssignee{GitHubUserID: *ghUserID, Active: active}
+			if login != nil {
+				a.Login = *login
+			}
+			if assignedAt != nil {
+				a.AssignedAt = *assignedAt
+			}
+			out[len(out)-1].Members = append(out[len(out)-1].Members, a)
+		}
+	}
+	return out, rows.Err()
+}
+
+// ListRoles returns orgID's roles by name, with their members.
+func ListRoles(ctx context.Context, pool *pgxpool.Pool, orgID uuid.UUID) ([]Role, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, roleQuery+`
+WHERE r.org_id = $1
+ORDER BY lower(r.name), r.id, lower(m.login), a.github_user_id
+`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return scanRoles(rows)
+}
+
+// GetRole returns one of orgID's roles.
+func GetRole(ctx context.Context, pool *pgxpool.Pool, orgID, roleID uuid.UUID) (Role, error) {
+	if pool == nil {
+		return Role{}, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, roleQuery+`
+WHERE r.org_id = $1 AND r.id = $2
+ORDER BY lower(m.login), a.github_user_id
+`, orgID, roleID)
+	if err != nil {
+		return Role{}, err
+	}
+	roles, err := scanRoles(rows)
+	if err != nil {
+		return Role{}, err
+	}
+	if len(roles) == 0 {
+		return Role{}, ErrRoleNotFound
+	}
+	return roles[0], nil
+}
+
+// Assign gives one of orgID's roles to the member with GitHub login.
+func Assign(ctx context.Context, pool *pgxpool.Pool, orgID, roleID uuid.UUID, login string, assignedBy uuid.UUID) (Role, error) {
+	if pool == nil {
+		return Role{}, fmt.Errorf("db not configured")
+	}
+	if _, err := GetRole(ctx, pool, orgID, roleID); err != nil {
+		return Role{}, err
+	}
+	var ghUserID int64
+	err := pool.QueryRow(ctx, `
+SELECT github_user_id FROM github_org_members WHERE org_id = $1 AND lower(login) = lower($2)
+`, orgID, strings.TrimSpace(login)).Scan(&ghUserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Role{}, ErrNotMember
+	}
+	if err != nil {
+		return Role{}, err
+	}
+	if _, err := pool.Exec(ctx, `
+INSERT INTO github_org_role_assignments (role_id, github_user_id, assigned_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (role_id, github_user_id) DO NOTHING
+`, roleID, ghUserID, assignedBy); err != nil {
+		return Role{}, err
+	}
+	return GetRole(ctx, pool, orgID, roleID)
+}
+
+// Unassign takes one of orgID's roles from a GitHub user.
+func Unassign(ctx context.Context, pool *pgxpool.Pool, orgID, roleID uuid.UUID, githubUserID int64) (Role, error) {
+	if pool == nil {
+		return Role{}, fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `
+DELETE FROM github_org_role_assignments a
+USING github_org_roles r
+WHERE a.role_id = r.id AND r.org_id = $1 AND r.id = $2 AND a.github_user_id = $3
+`, orgID, roleID, githubUserID)
+	if err != nil {
+		return Role{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return Role{}, ErrNotAssigned
+	}
+	return GetRole(ctx, pool, orgID, roleID)
+}
+
+// HoldsRoleOn is an SQL condition that holds when the user in userArg is a
+// current member holding any custom role of the linked organization owning
+// the repository of the project aliased project.
+func HoldsRoleOn(project, userArg string) string {
+	return `EXISTS (
+  SELECT 1 FROM github_orgs o
+  JOIN github_org_roles r ON r.org_id = o.id
+  JOIN github_org_role_assignments a ON a.role_id = r.id
+  JOIN github_org_members m ON m.org_id = o.id AND m.github_user_id = a.github_user_id
+  JOIN github_accounts ga ON ga.github_user_id = a.github_user_id
+  WHERE lower(o.login) = lower(split_part(` + project + `.github_full_name, '/', 1))
+    AND ga.user_id = ` + userArg + `
+)`
+}
+
+// Can is an SQL condition that holds when the user in userArg may exercise
+// the permission in permArg over the project aliased project: as an admin
+// of the linked organization owning its repository, or as a current member
+// holding one of the organization's roles that grants it.
+func Can(project, userArg, permArg string) string {
+	return `(` + AdminOf(project, userArg) + ` OR EXISTS (
+  SELECT 1 FROM github_orgs o
+  JOIN github_org_roles r ON r.org_id = o.id
+  JOIN github_org_role_assignments a ON a.role_id = r.id
+  JOIN github_org_members m ON m.org_id = o.id AND m.github_user_id = a.github_user_id
+  JOIN github_accounts ga ON ga.github_user_id = a.github_user_id
+  WHERE lower(o.login) = lower(split_part(` + project + `.github_full_name, '/', 1))
+    AND ga.user_id = ` + userArg + `
+    AND ` + permArg + ` = ANY(r.permissions)
+))`
+}
+
+// HasProjectPermission reports whether userID holds perm over projectID
+// through the linked organization owning its repository.
+func HasProjectPermission(ctx context.Context, pool *pgxpool.Pool, projectID, userID uuid.UUID, perm Permission) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, `SELECT `+Can("p", "$2", "$3")+` FROM projects p WHERE p.id = $1`, projectID, userID, string(perm)).Scan(&ok)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return ok, err
+}
@@ -0,0 +1,292 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// Thresholds is the smallest payout sent on its own per chain/asset, keyed
+// by assetKey. Smaller payouts accrue on the user's ledger account until the
+// balance crosses the threshold or a new month begins, so dust is paid in one
+// transaction instead of many.
+type Thresholds map[string]*big.Rat
+
+// ParseThresholds parses "chain/asset=amount,...". Malformed entries are
+// skipped.
+func ParseThresholds(s string) Thresholds {
+	return Thresholds(parseAssetAmounts(s))
+}
+
+// Holds reports whether amount of the asset is below its threshold.
+func (t Thresholds) Holds(chainName, asset string, amount *big.Rat) bool {
+	threshold, ok := t[assetKey(chainName, asset)]
+	return ok && amount.Cmp(threshold) < 0
+}
+
+// due reports whether an accrued balance is paid out: once it reaches the
+// threshold (or the threshold is removed), and in any case at the first run
+// of the month after its oldest payout accrued.
+func (t Thresholds) due(chainName, asset string, total *big.Rat, oldest, now time.Time) bool {
+	return !t.Holds(chainName, asset, total) || !now.Before(nextMonth(oldest))
+}
+
+// nextMonth is the start of the UTC month after t.
+func nextMonth(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Accrual is a user's balance of payouts waiting on a threshold.
+type Accrual struct {
+	Chain     string `json:"chain"`
+	Asset     string `json:"asset"`
+	Amount    string `json:"amount"`
+	Threshold string `json:"threshold,omitempty"`
+	Payouts   int    `json:"payouts"`
+	// PaysBy is when the balance goes out even if it stays below threshold.
+	PaysBy time.Time `json:"pays_by"`
+}
+
+// Accrued returns userID's accrued balances per chain/asset.
+func Accrued(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, t Thresholds) ([]Accrual, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT chain, (array_agg(asset ORDER BY created_at DESC))[1], SUM(amount)::text, COUNT(*), MIN(created_at)
+FROM payouts
+WHERE user_id = $1 AND status = 'accruing'
+GROUP BY chain, lower(asset)
+ORDER BY chain, lower(asset)
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Accrual{}
+	for rows.Next() {
+		var a Accrual
+		var oldest time.Time
+		if err := rows.Scan(&a.Chain, &a.Asset, &a.Amount, &a.Payouts, &oldest); err != nil {
+			return nil, err
+		}
+		if threshold, ok := t[assetKey(a.Chain, a.Asset)]; ok {
+			a.Threshold = wallet.FormatAmount(threshold, 18)
+		}
+		a.PaysBy = nextMonth(oldest)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// holdBelowThreshold moves pending payouts below their threshold to
+// accruing, crediting each to the user's account. Escrow releases and
+// payouts of accrued balances are never held.
+func holdBelowThreshold(ctx context.Context, pool *pgxpool.Pool, t Thresholds) (int, error) {
+	if len(t) == 0 {
+		return 0, nil
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, chain, asset, amount::text
+FROM payouts
+WHERE status = 'pending' AND escrow_bounty_id IS NULL AND NOT settles_accrual
+`)
+	if err != nil {
+		return 0, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		var chainName, asset, amount string
+		if err := rows.Scan(&id, &chainName, &asset, &amount); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if amt, err := wallet.ParseAmount(amount); err == nil && t.Holds(chainName, asset, amt) {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	held := 0
+	for _, id := range ids {
+		ok, err := holdPayout(ctx, pool, id)
+		if err != nil {
+			return held, err
+		}
+		if ok {
+			held++
+		}
+	}
+	return held, nil
+}
+
+func holdPayout(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (bool, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var userID uuid.UUID
+	var chainName, asset, amount string
+	err = tx.QueryRow(ctx, `
+UPDATE payouts SET status = 'accruing', updated_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING user_id, chain, asset, amount::text
+`, id).Scan(&userID, &chainName, &asset, &amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := ledger.Append(ctx, tx, &userID, ledger.AccountUser, ledger.KindAccrual, ledger.Asset(chainName, asset), amount, "payout:"+id.String()); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// settleAccruals merges each accrued balance that is due into one pending
+// payout, returning how many it created.
+func settleAccruals(ctx context.Context, pool *pgxpool.Pool, t Thresholds, now time.Time) (int, error) {
+	rows, err := pool.Query(ctx, `
+SELECT user_id, chain, lower(asset), SUM(amount)::text, MIN(created_at)
+FROM payouts
+WHERE status = 'accruing'
+GROUP BY user_id, chain, lower(asset)
+`)
+	if err != nil {
+		return 0, err
+	}
+	type balance struct {
+		userID       uuid.UUID
+		chain, asset string
+	}
+	var due []balance
+	for rows.Next() {
+		var b balance
+		var total string
+		var oldest time.Time
+		if err := rows.Scan(&b.userID, &b.chain, &b.asset, &total, &oldest); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		amt, err := wallet.ParseAmount(total)
+		if err != nil || t.due(b.chain, b.asset, amt, oldest, now) {
+			due = append(due, b)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, b := range due {
+		id, err := settleAccrual(ctx, pool, b.userID, b.chain, b.asset)
+		if err != nil {
+			slog.Error("failed to pay out accrued balance", "user_id", b.userID.String(), "chain", b.chain, "asset", b.asset, "error", err)
+			continue
+		}
+		if id != nil {
+			settled++
+		}
+	}
+	return settled, nil
+}
+
+// settleAccrual pays out one accrued balance to the address of its most
+// recent payout, moving it back off the user's account.
+func settleAccrual(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, chainName, lowerAsset string) (*uuid.UUID, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	rows, err := tx.Query(ctx, `
+SELECT id, asset, to_address, amount::text
+FROM payouts
+WHERE status = 'accruing' AND user_id = $1 AND chain = $2 AND lower(asset) = $3
+ORDER BY created_at
+FOR UPDATE
+`, userID, chainName, lowerAsset)
+	if err != nil {
+		return nil, err
+	}
+	type accrued struct {
+		id                uuid.UUID
+		asset, to, amount string
+	}
+	var held []accrued
+	var ids []uuid.UUID
+	for rows.Next() {
+		var a accrued
+		if err := rows.Scan(&a.id, &a.asset, &a.to, &a.amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		held = append(held, a)
+		ids = append(ids, a.id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(held) == 0 {
+		return nil, nil
+	}
+
+	latest := held[len(held)-1]
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, `
+INSERT INTO payouts (user_id, chain, asset, to_address, amount, reference, settles_accrual)
+SELECT $1, $2, $3, $4, SUM(amount), $5, true FROM payouts WHERE id = ANY($6)
+RETURNING id
+`, userID, chainName, latest.asset, latest.to, fmt.Sprintf("accrued balance of %d payouts", len(held)), ids).Scan(&id); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE payouts SET status = 'merged', merged_into = $1, updated_at = now() WHERE id = ANY($2)`, id, ids); err != nil {
+		return nil, err
+	}
+	for _, a := range held {
+		if _, err := ledger.Append(ctx, tx, &userID, ledger.AccountUser, ledger.KindAccrual, ledger.Asset(chainName, a.asset), "-"+a.amount, "payout:"+id.String()); err != nil {
+			return nil, err
+		}
+	}
+	return &id, tx.Commit(ctx)
+}
+
+// cancelAccruing cancels an accruing payout and takes it off the user's
+// account; pgx.ErrNoRows means the payout is not accruing.
+func cancelAccruing(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (Payout, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Payout{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	p, err := scanPayout(tx.QueryRow(ctx, `
+UPDATE payouts SET status = 'cancelled', updated_at = now()
+WHERE id = $1 AND status = 'accruing'
+RETURNING `+payoutColumns, id))
+	if err != nil {
+		return Payout{}, err
+	}
+	if _, err := ledger.Append(ctx, tx, &p.UserID, ledger.AccountUser, ledger.KindAccrual, ledger.Asset(p.Chain, p.Asset), "-"+p.Amount, "payout_cancelled:"+p.ID.String()); err != nil {
+		return Payout{}, err
+	}
+	return p, tx.Commit(ctx)
+}
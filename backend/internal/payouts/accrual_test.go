@@ -0,0 +1,40 @@
+package payouts
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThresholds(t *testing.T) {
+	th := ParseThresholds("base/" + baseUSDC + "=5, stellar/xlm=oops")
+	if !th.Holds("Base", strings.ToUpper(baseUSDC), big.NewRat(49, 10)) {
+		t.Error("4.9 should be held")
+	}
+	if th.Holds("base", baseUSDC, big.NewRat(5, 1)) {
+		t.Error("5 meets the threshold")
+	}
+	if th.Holds("stellar", "XLM", big.NewRat(1, 100)) {
+		t.Error("malformed threshold should not hold")
+	}
+
+	oldest := time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)
+	march := time.Date(2026, 3, 31, 23, 59, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	if th.due("base", baseUSDC, big.NewRat(4, 1), oldest, march) {
+		t.Error("balance below threshold paid out within the month")
+	}
+	if !th.due("base", baseUSDC, big.NewRat(6, 1), oldest, march) {
+		t.Error("balance over threshold not paid out")
+	}
+	if !th.due("base", baseUSDC, big.NewRat(1, 1), oldest, april) {
+		t.Error("balance not paid out once the month turned")
+	}
+	if !th.due("ethereum", "native", big.NewRat(1, 1000), oldest, march) {
+		t.Error("balance without a threshold not paid out")
+	}
+	if got := nextMonth(time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("next month after december = %v", got)
+	}
+}
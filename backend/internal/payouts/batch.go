@@ -0,0 +1,492 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chaos"
+	"github.com/jagadeesh/grainlify/backend/internal/failures"
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// batchLockKey keeps concurrent instances from sending the same payouts.
+const batchLockKey = 0x6772_6e5f_706f // "grn_po"
+
+// Batcher sends pending payouts. Each run groups them by chain/asset and
+// sends up to min(MaxBatch, sender.MaxBatch()) per transaction: Stellar packs
+// them as payment operations, EVM chains with a smart account use
+// executeBatch, and chains without batching send one payout per transaction.
+//
+// When payout windows are defined, runs outside a due window send nothing and
+// pending payouts stay queued for the next one.
+type Batcher struct {
+	Pool     *pgxpool.Pool
+	Wallets  wallet.Registry
+	MaxBatch int
+	// Router, if set, sends payouts through the payout methods their users
+	// rank before they are batched.
+	Router *Router
+	// Thresholds hold back payouts too small to send on their own until the
+	// user's accrued balance crosses them or a new month begins.
+	Thresholds Thresholds
+	// OnWindowRun, if set, receives the summary of every finished window.
+	OnWindowRun func(ctx context.Context, r WindowRun)
+}
+
+type RunResult struct {
+	Batches int `json:"batches"`
+	Payouts int `json:"payouts"`
+	Failed  int `json:"failed"`
+}
+
+// RunOnce sends pending payouts if no windows are defined or one is due.
+func (b *Batcher) RunOnce(ctx context.Context) (RunResult, error) {
+	return b.locked(ctx, func() (RunResult, error) {
+		windows, err := ListWindows(ctx, b.Pool)
+		if err != nil {
+			return RunResult{}, err
+		}
+		if len(windows) == 0 {
+			return b.drain(ctx, nil)
+		}
+		runs, err := claimDueWindows(ctx, b.Pool, windows, time.Now())
+		var res RunResult
+		for _, run := range runs {
+			r, derr := b.drain(ctx, &run.ID)
+			res.Batches += r.Batches
+			res.Payouts += r.Payouts
+			res.Failed += r.Failed
+			if derr != nil {
+				slog.Error("payout window run failed", "window_run_id", run.ID.String(), "error", derr)
+			}
+			summary, ferr := finishWindowRun(ctx, b.Pool, run, r)
+			if ferr != nil {
+				slog.Error("failed to record payout window run", "window_run_id", run.ID.String(), "error", ferr)
+				continue
+			}
+			slog.Info("payout window run finished",
+				"window_run_id", run.ID.String(),
+				"scheduled_for", run.ScheduledFor,
+				"batches", r.Batches,
+				"payouts", r.Payouts,
+				"failed", r.Failed,
+			)
+			if b.OnWindowRun != nil {
+				b.OnWindowRun(ctx, summary)
+			}
+		}
+		return res, err
+	})
+}
+
+// RunNow sends every pending payout immediately, ignoring payout windows.
+func (b *Batcher) RunNow(ctx context.Context) (RunResult, error) {
+	return b.locked(ctx, func() (RunResult, error) {
+		return b.drain(ctx, nil)
+	})
+}
+
+// locked runs fn while holding the batch lock; it does nothing when another
+// instance holds it.
+func (b *Batcher) locked(ctx context.Context, fn func() (RunResult, error)) (RunResult, error) {
+	if b.Pool == nil {
+		return RunResult{}, fmt.Errorf("db not configured")
+	}
+	conn, err := b.Pool.Acquire(ctx)
+	if err != nil {
+		return RunResult{}, err
+	}
+	defer conn.Release()
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, batchLockKey).Scan(&locked); err != nil {
+		return RunResult{}, err
+	}
+	if !locked {
+		return RunResult{}, nil
+	}
+	defer func() { _, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, batchLockKey) }()
+	return fn()
+}
+
+// drain sends every pending payout, tagging batches with the window run.
+func (b *Batcher) drain(ctx context.Context, windowRunID *uuid.UUID) (RunResult, error) {
+	var res RunResult
+	if err := b.resumeSending(ctx); err != nil {
+		slog.Error("failed to resume interrupted payout batches", "error", err)
+	}
+	// Hold first so a payout that lifts a balance over its threshold is paid
+	// out with it in this run; accruals are merged before routing so the
+	// merged payout is routed like any other.
+	if n, err := holdBelowThreshold(ctx, b.Pool, b.Thresholds); err != nil {
+		slog.Error("failed to hold payouts below threshold", "error", err)
+	} else if n > 0 {
+		slog.Info("payouts held below threshold", "payouts", n)
+	}
+	if n, err := settleAccruals(ctx, b.Pool, b.Thresholds, time.Now()); err != nil {
+		slog.Error("failed to pay out accrued balances", "error", err)
+	} else if n > 0 {
+		slog.Info("accrued balances paid out", "payouts", n)
+	}
+	if b.Router != nil {
+		// Unrouted payouts still go out to where they were headed.
+		if _, err := b.Router.RouteAll(ctx, b.Pool); err != nil {
+			slog.Error("payout routing failed", "error", err)
+		}
+	}
+	rows, err := b.Pool.Query(ctx, `SELECT DISTINCT chain, asset FROM payouts WHERE status = 'pending' AND escrow_bounty_id IS NULL ORDER BY chain, asset`)
+	if err != nil {
+		return res, err
+	}
+	type group struct{ chain, asset string }
+	var groups []group
+	for rows.Next() {
+		var g group
+		if err := rows.Scan(&g.chain, &g.asset); err != nil {
+			rows.Close()
+			return res, err
+		}
+		groups = append(groups, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	for _, g := range groups {
+		sender, ok := b.Wallets.Get(g.chain)
+		if !ok {
+			slog.Warn("pending payouts on chain without hot wallet", "chain", g.chain, "asset", g.asset)
+			continue
+		}
+		limit := sender.MaxBatch()
+		if b.MaxBatch > 0 && b.MaxBatch < limit {
+			limit = b.MaxBatch
+		}
+		// Drain the group; a short batch means nothing is left.
+		for {
+			n, err := b.sendBatch(ctx, sender, g.asset, limit, windowRunID)
+			if err != nil {
+				res.Failed += n
+				slog.Error("payout batch failed", "chain", g.chain, "asset", g.asset, "error", err)
+				break
+			}
+			if n == 0 {
+				break
+			}
+			res.Batches++
+			res.Payouts += n
+			if n < limit {
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+type claimed struct {
+	id     uuid.UUID
+	userID uuid.UUID
+	to     string
+	amount string
+}
+
+// sendBatch claims up to limit pending payouts, sends them in one transaction
+// and posts the outflow to the ledger. It returns how many payouts it handled.
+func (b *Batcher) sendBatch(ctx context.Context, sender wallet.Sender, asset string, limit int, windowRunID *uuid.UUID) (int, error) {
+	chainName := sender.Chain()
+	dec, err := sender.Decimals(ctx, asset)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := b.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+SELECT id, user_id, to_address, amount::text
+FROM payouts
+WHERE chain = $1 AND asset = $2 AND status = 'pending' AND escrow_bounty_id IS NULL
+ORDER BY created_at
+LIMIT $3
+FOR UPDATE SKIP LOCKED
+`, chainName, asset, limit)
+	if err != nil {
+		return 0, err
+	}
+	var items []claimed
+	var unpayable []uuid.UUID
+	total := new(big.Rat)
+	for rows.Next() {
+		var c claimed
+		if err := rows.Scan(&c.id, &c.userID, &c.to, &c.amount); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		amt, err := wallet.ParseAmount(c.amount)
+		if err != nil || !fitsDecimals(amt, dec) {
+			// Sending would silently truncate the amount.
+			unpayable = append(unpayable, c.id)
+			continue
+		}
+		total.Add(total, amt)
+		items = append(items, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(unpayable) > 0 {
+		if _, err := tx.Exec(ctx, `
+UPDATE payouts SET status = 'failed', error = 'amount exceeds asset precision', error_code = $2, updated_at = now()
+WHERE id = ANY($1)
+`, unpayable, failures.CodeAmountPrecision); err != nil {
+			return 0, err
+		}
+	}
+	if len(items) == 0 {
+		return 0, tx.Commit(ctx)
+	}
+
+	ids := make([]uuid.UUID, len(items))
+	payments := make([]wallet.Payment, len(items))
+	for i, c := range items {
+		ids[i] = c.id
+		payments[i] = wallet.Payment{To: c.to, Amount: c.amount}
+	}
+	var batchID uuid.UUID
+	if err := tx.QueryRow(ctx, `
+INSERT INTO payout_batches (chain, asset, payout_count, total_amount, status, window_run_id)
+VALUES ($1, $2, $3, $4::numeric, 'sending', $5)
+RETURNING id
+`, chainName, asset, len(items), wallet.FormatAmount(total, dec), windowRunID).Scan(&batchID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE payouts SET status = 'batched', batch_id = $2, updated_at = now()
+WHERE id = ANY($1)
+`, ids, batchID); err != nil {
+		return 0, err
+	}
+
+	// Record the signed transaction before broadcasting it, so a send cut
+	// short after this commit is resumed by resumeSending, not lost.
+	signed, signErr := sender.Sign(ctx, asset, payments)
+	if signErr != nil {
+		// Nothing was broadcast, but failed payouts wait for an operator
+		// like any other failed send.
+		if err := failBatch(ctx, tx, batchID, failures.Classify(signErr)); err != nil {
+			return 0, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return 0, err
+		}
+		return len(items), signErr
+	}
+	if _, err := tx.Exec(ctx, `UPDATE payout_batches SET tx_hash = $2, signed_tx = $3 WHERE id = $1`, batchID, signed.Hash, signed.Raw); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE payouts SET tx_hash = $2 WHERE batch_id = $1`, batchID, signed.Hash); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	sendErr := chaos.PayoutBroadcast(chainName)
+	if sendErr == nil {
+		sendErr = sender.Broadcast(ctx, signed)
+	}
+	if sendErr != nil {
+		// Failed payouts stay out of the queue until an operator retries them,
+		// since a timed-out send may still have landed; their tx_hash shows
+		// where to look.
+		if err := failBatch(ctx, b.Pool, batchID, failures.Classify(sendErr)); err != nil {
+			slog.Error("failed to record failed payout batch", "batch_id", batchID.String(), "error", err)
+		}
+		return len(items), sendErr
+	}
+	if err := markSubmitted(ctx, b.Pool, batchID); err != nil {
+		// resumeSending finishes recording it on a later run.
+		return len(items), err
+	}
+
+	slog.Info("payout batch submitted",
+		"chain", chainName,
+		"asset", asset,
+		"payouts", len(items),
+		"total", wallet.FormatAmount(total, dec),
+		"tx_hash", signed.Hash,
+	)
+	return len(items), nil
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// failBatch fails a batch that is still sending, and its payouts.
+func failBatch(ctx context.Context, db execer, batchID uuid.UUID, f failures.Failure) error {
+	if _, err := db.Exec(ctx, `UPDATE payout_batches SET status = 'failed', error = $2, error_code = $3, updated_at = now() WHERE id = $1 AND status = 'sending'`, batchID, f.Message, f.Code); err != nil {
+		return err
+	}
+	_, err := db.Exec(ctx, `UPDATE payouts SET status = 'failed', error = $2, error_code = $3, updated_at = now() WHERE batch_id = $1 AND status = 'batched'`, batchID, f.Message, f.Code)
+	return err
+}
+
+// markSubmitted records a broadcast batch as submitted and posts its outflow
+// to the ledger. It does nothing if the batch is no longer sending.
+func markSubmitted(ctx context.Context, pool *pgxpool.Pool, batchID uuid.UUID) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var chainName, asset string
+	err = tx.QueryRow(ctx, `
+UPDATE payout_batches SET status = 'submitted', updated_at = now()
+WHERE id = $1 AND status = 'sending'
+RETURNING chain, asset
+`, batchID).Scan(&chainName, &asset)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rows, err := tx.Query(ctx, `
+UPDATE payouts SET status = 'submitted', updated_at = now()
+WHERE batch_id = $1 AND status = 'batched'
+RETURNING id, user_id, amount::text
+`, batchID)
+	if err != nil {
+		return err
+	}
+	var items []claimed
+	for rows.Next() {
+		var c claimed
+		if err := rows.Scan(&c.id, &c.userID, &c.amount); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	ledgerAsset := ledger.Asset(chainName, asset)
+	for _, c := range items {
+		userID := c.userID
+		if _, err := ledger.Append(ctx, tx, &userID, ledger.AccountTreasury, ledger.KindPayout, ledgerAsset, "-"+c.amount, "payout:"+c.id.String()); err != nil {
+			return err
+		}
+	}
+	// Bounty payouts get an on-chain attestation of the work, addressed to the
+	// payout address when it is an EVM address, else the user's linked wallet.
+	if _, err := tx.Exec(ctx, `
+INSERT INTO attestations (payout_id, user_id, recipient, repo_full_name, pr_number, pr_url)
+SELECT p.id, p.user_id, r.recipient, p.repo_full_name, p.pr_number, COALESCE(p.pr_url, '')
+FROM payouts p
+CROSS JOIN LATERAL (
+  SELECT COALESCE(
+    CASE WHEN p.to_address ~ '^0x[0-9a-f]{40}$' THEN p.to_address END,
+    (SELECT lower(w.address) FROM wallets w WHERE w.user_id = p.user_id AND w.wallet_type = 'evm' ORDER BY w.is_primary DESC, w.created_at LIMIT 1)
+  ) AS recipient
+) r
+WHERE p.batch_id = $1 AND p.repo_full_name IS NOT NULL AND p.pr_number IS NOT NULL AND r.recipient IS NOT NULL
+ON CONFLICT (payout_id) DO NOTHING
+`, batchID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// staleSending is how long a batch may stay sending before resumeSending
+// takes it to be interrupted.
+const staleSending = 2 * time.Minute
+
+// resumeSending finishes batches whose send was interrupted after signing:
+// a transaction the chain already has, or that broadcasts again, is recorded
+// as submitted; one the chain now rejects fails for an operator. Batches from
+// before transactions were recorded unsent have no envelope to resume, so
+// they fail too.
+func (b *Batcher) resumeSending(ctx context.Context) error {
+	rows, err := b.Pool.Query(ctx, `
+SELECT id, chain, tx_hash, signed_tx
+FROM payout_batches
+WHERE status = 'sending' AND updated_at < now() - $1::interval
+ORDER BY updated_at
+LIMIT 100
+`, fmt.Sprintf("%d seconds", int64(staleSending.Seconds())))
+	if err != nil {
+		return err
+	}
+	type stale struct {
+		id        uuid.UUID
+		chain     string
+		hash, raw *string
+	}
+	var list []stale
+	for rows.Next() {
+		var s stale
+		if err := rows.Scan(&s.id, &s.chain, &s.hash, &s.raw); err != nil {
+			rows.Close()
+			return err
+		}
+		list = append(list, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, s := range list {
+		if s.hash == nil || s.raw == nil {
+			f := failures.New(failures.CodeUnknown, "send interrupted; check the hot wallet's transactions before retrying")
+			errs = append(errs, failBatch(ctx, b.Pool, s.id, f))
+			continue
+		}
+		sender, ok := b.Wallets.Get(s.chain)
+		if !ok {
+			continue
+		}
+		landed, err := wallet.Landed(ctx, sender, *s.hash)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !landed {
+			if err := sender.Broadcast(ctx, wallet.SignedTx{Hash: *s.hash, Raw: *s.raw}); err != nil {
+				slog.Error("interrupted payout batch could not be resent", "batch_id", s.id.String(), "tx_hash", *s.hash, "error", err)
+				errs = append(errs, failBatch(ctx, b.Pool, s.id, failures.Classify(err)))
+				continue
+			}
+		}
+		if err := markSubmitted(ctx, b.Pool, s.id); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		slog.Info("interrupted payout batch resumed", "batch_id", s.id.String(), "chain", s.chain, "tx_hash", *s.hash, "resent", !landed)
+	}
+	return errors.Join(errs...)
+}
+
+func fitsDecimals(r *big.Rat, decimals int) bool {
+	t, err := wallet.ParseAmount(wallet.FormatAmount(r, decimals))
+	return err == nil && t.Cmp(r) == 0
+}
@@ -0,0 +1,93 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/hdwallet"
+)
+
+var ErrNoClaimantWallet = errors.New("claimant_wallet_missing")
+
+// walletTypeByFamily is the sign-in wallet type whose address can receive a
+// payout on a chain family.
+var walletTypeByFamily = map[string]string{
+	hdwallet.FamilyEVM:     "evm",
+	hdwallet.FamilyStellar: "stellar_ed25519",
+	hdwallet.FamilySolana:  "solana",
+}
+
+// ClaimantAddress returns the address b's claimant signed in with on b's
+// chain, preferring their primary wallet.
+func ClaimantAddress(ctx context.Context, pool *pgxpool.Pool, b bounties.Bounty) (string, error) {
+	if pool == nil {
+		return "", fmt.Errorf("db not configured")
+	}
+	if b.Claim == nil {
+		return "", bounties.ErrInvalidStatus
+	}
+	family, _ := hdwallet.FamilyOf(b.Chain)
+	walletType, ok := walletTypeByFamily[family]
+	if !ok {
+		return "", ErrNoClaimantWallet
+	}
+	var addr string
+	err := pool.QueryRow(ctx, `
+SELECT address FROM wallets
+WHERE user_id = $1 AND wallet_type = $2
+ORDER BY is_primary DESC, created_at
+LIMIT 1
+`, b.Claim.UserID, walletType).Scan(&addr)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNoClaimantWallet
+	}
+	return addr, err
+}
+
+// PayBounty queues the hot wallet payout of approved bounty b to its
+// claimant at address to and completes the bounty, on behalf of maintainer
+// actor. Escrow-funded bounties are paid by CreateEscrowRelease instead.
+func PayBounty(ctx context.Context, pool *pgxpool.Pool, b bounties.Bounty, to string, actor uuid.UUID) (Payout, error) {
+	if pool == nil {
+		return Payout{}, fmt.Errorf("db not configured")
+	}
+	if err := b.CheckPay(); err != nil {
+		return Payout{}, err
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Payout{}, err
+	}
+	defer tx.Rollback(ctx)
+	if err := bounties.SetActor(ctx, tx, actor); err != nil {
+		return Payout{}, err
+	}
+
+	out, err := scanPayout(tx.QueryRow(ctx, `
+INSERT INTO payouts (user_id, chain, asset, to_address, amount, reference, repo_full_name, pr_number, pr_url)
+VALUES ($1, $2, $3, $4, $5::numeric, $6, $7, $8, $9)
+RETURNING `+payoutColumns,
+		b.Claim.UserID, b.Chain, b.Asset, chain.NormalizeAddress(to), b.Amount, "bounty:"+b.ID.String(),
+		b.Claim.Repo, b.Claim.PRNumber, b.Claim.PRURL))
+	if err != nil {
+		return Payout{}, err
+	}
+	tag, err := tx.Exec(ctx, `
+UPDATE bounties SET status = 'completed', payout_id = $2, updated_at = now()
+WHERE id = $1 AND status = 'approved' AND claimed_by = $3
+`, b.ID, out.ID, b.Claim.UserID)
+	if err != nil {
+		return Payout{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return Payout{}, bounties.ErrInvalidStatus
+	}
+	return out, tx.Commit(ctx)
+}
@@ -0,0 +1,190 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/failures"
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// Confirmer follows submitted payouts, hot wallet batches and escrow releases
+// alike, until their transactions are final. Confirmed payouts record the
+// block that included them. Reverted ones fail with their ledger outflow
+// reversed and their escrow release reopened, so an operator can retry or
+// cancel them like any failed send.
+type Confirmer struct {
+	Pool    *pgxpool.Pool
+	Wallets wallet.Registry
+}
+
+type ConfirmResult struct {
+	Confirmed int `json:"confirmed"`
+	Failed    int `json:"failed"`
+}
+
+type submittedTx struct {
+	chain string
+	hash  string
+}
+
+// RunOnce checks the oldest submitted transactions once. Chains without a
+// sender that can look transactions up are left alone.
+func (c *Confirmer) RunOnce(ctx context.Context) (ConfirmResult, error) {
+	var res ConfirmResult
+	if c.Pool == nil {
+		return res, fmt.Errorf("db not configured")
+	}
+	rows, err := c.Pool.Query(ctx, `
+SELECT chain, tx_hash
+FROM payouts
+WHERE status = 'submitted' AND tx_hash IS NOT NULL
+GROUP BY chain, tx_hash
+ORDER BY MIN(updated_at)
+LIMIT 200
+`)
+	if err != nil {
+		return res, err
+	}
+	var txs []submittedTx
+	for rows.Next() {
+		var t submittedTx
+		if err := rows.Scan(&t.chain, &t.hash); err != nil {
+			rows.Close()
+			return res, err
+		}
+		txs = append(txs, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	var errs []error
+	for _, t := range txs {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		sender, _ := c.Wallets.Get(t.chain)
+		tracker, ok := sender.(wallet.Tracker)
+		if !ok {
+			continue
+		}
+		st, err := tracker.TxStatus(ctx, t.hash)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		switch st.State {
+		case wallet.TxConfirmed:
+			n, err := c.confirm(ctx, t, st)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			res.Confirmed += n
+			slog.Info("payout transaction confirmed", "chain", t.chain, "tx_hash", t.hash, "block", st.Block, "payouts", n)
+		case wallet.TxFailed:
+			n, err := c.revert(ctx, t, st)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			res.Failed += n
+			slog.Error("payout transaction reverted", "chain", t.chain, "tx_hash", t.hash, "block", st.Block, "payouts", n)
+		}
+	}
+	return res, errors.Join(errs...)
+}
+
+func (c *Confirmer) confirm(ctx context.Context, t submittedTx, st wallet.TxStatus) (int, error) {
+	tx, err := c.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	tag, err := tx.Exec(ctx, `
+UPDATE payouts SET status = 'confirmed', block_number = $3, confirmed_at = now(), updated_at = now()
+WHERE chain = $1 AND tx_hash = $2 AND status = 'submitted'
+`, t.chain, t.hash, int64(st.Block))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE payout_batches SET status = 'confirmed', block_number = $3, confirmed_at = now(), updated_at = now()
+WHERE chain = $1 AND tx_hash = $2 AND status = 'submitted'
+`, t.chain, t.hash, int64(st.Block)); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), tx.Commit(ctx)
+}
+
+func (c *Confirmer) revert(ctx context.Context, t submittedTx, st wallet.TxStatus) (int, error) {
+	f := failures.New(failures.CodeTxReverted, "transaction reverted")
+	tx, err := c.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	rows, err := tx.Query(ctx, `
+UPDATE payouts SET status = 'failed', block_number = $3, error = $4, error_code = $5, updated_at = now()
+WHERE chain = $1 AND tx_hash = $2 AND status = 'submitted'
+RETURNING id, user_id, asset, amount::text, escrow_bounty_id
+`, t.chain, t.hash, int64(st.Block), f.Message, f.Code)
+	if err != nil {
+		return 0, err
+	}
+	type reverted struct {
+		id, userID     uuid.UUID
+		asset, amount  string
+		escrowBountyID *uuid.UUID
+	}
+	var list []reverted
+	for rows.Next() {
+		var r reverted
+		if err := rows.Scan(&r.id, &r.userID, &r.asset, &r.amount, &r.escrowBountyID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		list = append(list, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE payout_batches SET status = 'failed', block_number = $3, error = $4, error_code = $5, updated_at = now()
+WHERE chain = $1 AND tx_hash = $2 AND status = 'submitted'
+`, t.chain, t.hash, int64(st.Block), f.Message, f.Code); err != nil {
+		return 0, err
+	}
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, r := range list {
+		ids = append(ids, r.id)
+		if r.escrowBountyID != nil {
+			// The bounty's reward is still in escrow, waiting to be released.
+			if _, err := tx.Exec(ctx, `UPDATE bounties SET escrow_status = 'releasing', updated_at = now() WHERE id = $1 AND escrow_status = 'released'`, *r.escrowBountyID); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		userID := r.userID
+		if _, err := ledger.Append(ctx, tx, &userID, ledger.AccountTreasury, ledger.KindPayout, ledger.Asset(t.chain, r.asset), r.amount, "payout_reverted:"+r.id.String()); err != nil {
+			return 0, err
+		}
+	}
+	// Nothing was paid, so there is no work to attest yet; a retried send
+	// queues the attestation again.
+	if _, err := tx.Exec(ctx, `DELETE FROM attestations WHERE payout_id = ANY($1) AND status = 'pending'`, ids); err != nil {
+		return 0, err
+	}
+	return len(list), tx.Commit(ctx)
+}
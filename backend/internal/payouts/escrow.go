@@ -0,0 +1,169 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/escrow"
+	"github.com/jagadeesh/grainlify/backend/internal/failures"
+)
+
+// ErrEscrowNotLocked is returned when releasing a bounty whose reward isn't
+// (or is no longer) locked in escrow.
+var ErrEscrowNotLocked = errors.New("escrow_not_locked")
+
+// CreateEscrowRelease queues the release of escrow bounty b's whole reward to
+// p's recipient. The caller has checked the approval of the maintainer,
+// approvedBy.
+func CreateEscrowRelease(ctx context.Context, pool *pgxpool.Pool, b bounties.Bounty, p Payout, approvedBy uuid.UUID) (Payout, error) {
+	if pool == nil {
+		return Payout{}, fmt.Errorf("db not configured")
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Payout{}, err
+	}
+	defer tx.Rollback(ctx)
+	if err := bounties.SetActor(ctx, tx, approvedBy); err != nil {
+		return Payout{}, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+UPDATE bounties SET escrow_status = 'releasing', updated_at = now()
+WHERE id = $1 AND funding = 'escrow' AND escrow_status = 'locked'
+`, b.ID)
+	if err != nil {
+		return Payout{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return Payout{}, ErrEscrowNotLocked
+	}
+	out, err := scanPayout(tx.QueryRow(ctx, `
+INSERT INTO payouts (user_id, chain, asset, to_address, amount, repo_full_name, pr_number, pr_url, escrow_bounty_id)
+VALUES ($1, $2, $3, $4, $5::numeric, $6, $7, $8, $9)
+RETURNING `+payoutColumns,
+		p.UserID, b.Chain, b.Asset, chain.NormalizeAddress(p.To), b.Amount, p.Repo, p.PRNumber, p.PRURL, b.ID))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return Payout{}, ErrEscrowNotLocked
+	}
+	if err != nil {
+		return Payout{}, err
+	}
+	// Releasing the reward settles the bounty, whether or not it went
+	// through claim and approval first.
+	if _, err := tx.Exec(ctx, `
+UPDATE bounties SET status = 'completed', payout_id = $2, updated_at = now()
+WHERE id = $1 AND status IN ('open', 'claimed', 'submitted', 'approved')
+`, b.ID, out.ID); err != nil {
+		return Payout{}, err
+	}
+	return out, tx.Commit(ctx)
+}
+
+// EscrowReleaser submits the release calls of pending escrow payouts. The
+// funds never pass through the platform, so nothing is posted to the ledger.
+type EscrowReleaser struct {
+	Pool      *pgxpool.Pool
+	Contracts escrow.Registry
+}
+
+type pendingRelease struct {
+	id       uuid.UUID
+	bountyID uuid.UUID
+	chain    string
+	to       string
+	ref      int64
+}
+
+// RunOnce releases every pending escrow payout and returns how many were
+// submitted.
+func (r *EscrowReleaser) RunOnce(ctx context.Context) (int, error) {
+	if r.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := r.Pool.Query(ctx, `
+SELECT p.id, p.escrow_bounty_id, p.chain, p.to_address, b.escrow_ref
+FROM payouts p
+JOIN bounties b ON b.id = p.escrow_bounty_id
+WHERE p.status = 'pending' AND b.escrow_ref IS NOT NULL
+ORDER BY p.created_at
+LIMIT 50
+`)
+	if err != nil {
+		return 0, err
+	}
+	var pending []pendingRelease
+	for rows.Next() {
+		var pr pendingRelease
+		if err := rows.Scan(&pr.id, &pr.bountyID, &pr.chain, &pr.to, &pr.ref); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, pr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, pr := range pending {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		contract, ok := r.Contracts.Get(pr.chain)
+		if !ok {
+			slog.Warn("pending escrow release on chain without escrow contract", "chain", pr.chain, "payout_id", pr.id.String())
+			continue
+		}
+		if err := r.release(ctx, contract, pr); err != nil {
+			slog.Error("escrow release failed", "payout_id", pr.id.String(), "bounty_id", pr.bountyID.String(), "error", err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (r *EscrowReleaser) release(ctx context.Context, contract escrow.Contract, pr pendingRelease) error {
+	// Claim the payout so another instance doesn't release it too.
+	tag, err := r.Pool.Exec(ctx, `UPDATE payouts SET status = 'batched', updated_at = now() WHERE id = $1 AND status = 'pending'`, pr.id)
+	if err != nil || tag.RowsAffected() == 0 {
+		return err
+	}
+	txHash, relErr := contract.Release(ctx, pr.ref, pr.to)
+	if relErr != nil {
+		// Like hot wallet sends, a failed release waits for an operator to
+		// retry it, since a timed-out call may still have landed.
+		f := failures.Classify(relErr)
+		_, _ = r.Pool.Exec(ctx, `UPDATE payouts SET status = 'failed', error = $2, error_code = $3, updated_at = now() WHERE id = $1`, pr.id, f.Message, f.Code)
+		return relErr
+	}
+
+	tx, err := r.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `UPDATE payouts SET status = 'submitted', tx_hash = $2, updated_at = now() WHERE id = $1`, pr.id, txHash); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE bounties SET escrow_status = 'released', updated_at = now() WHERE id = $1`, pr.bountyID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	slog.Info("escrow release submitted", "payout_id", pr.id.String(), "bounty_id", pr.bountyID.String(), "chain", pr.chain, "tx_hash", txHash)
+	return nil
+}
@@ -0,0 +1,110 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/hdwallet"
+)
+
+// MaxMethods caps how many payout methods a user may rank.
+const MaxMethods = 8
+
+var (
+	ErrTooManyMethods       = errors.New("too_many_payout_methods")
+	ErrMethodChain          = errors.New("unsupported_payout_method_chain")
+	ErrMethodAddress        = errors.New("invalid_payout_method_address")
+	ErrDuplicateMethodChain = errors.New("duplicate_payout_method_chain")
+)
+
+// PayoutMethod is a chain and address a user wants to be paid on. Rank 1 is
+// the most preferred.
+type PayoutMethod struct {
+	Rank    int    `json:"rank"`
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+}
+
+// CheckMethods normalizes methods, ranking them in the order given. Each
+// chain may appear once, with an address valid on that chain.
+func CheckMethods(methods []PayoutMethod) ([]PayoutMethod, error) {
+	if len(methods) > MaxMethods {
+		return nil, ErrTooManyMethods
+	}
+	out := make([]PayoutMethod, 0, len(methods))
+	seen := map[string]bool{}
+	for i, m := range methods {
+		m.Rank = i + 1
+		m.Chain = strings.ToLower(strings.TrimSpace(m.Chain))
+		family, ok := hdwallet.FamilyOf(m.Chain)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrMethodChain, m.Chain)
+		}
+		if seen[m.Chain] {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateMethodChain, m.Chain)
+		}
+		seen[m.Chain] = true
+		addr, err := auth.NormalizeAddress(auth.WalletType(walletTypeByFamily[family]), m.Address)
+		// Stellar payouts need a G... account, not a legacy opaque id.
+		if err != nil || (family == hdwallet.FamilyStellar && !strings.HasPrefix(addr, "G")) {
+			return nil, fmt.Errorf("%w for %s", ErrMethodAddress, m.Chain)
+		}
+		m.Address = addr
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// Methods returns userID's payout methods, most preferred first.
+func Methods(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]PayoutMethod, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT rank, chain, address FROM payout_methods WHERE user_id = $1 ORDER BY rank`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []PayoutMethod{}
+	for rows.Next() {
+		var m PayoutMethod
+		if err := rows.Scan(&m.Rank, &m.Chain, &m.Address); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// SetMethods replaces userID's payout methods with methods, ranked in the
+// order given. Payouts already routed keep their destination.
+func SetMethods(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, methods []PayoutMethod) ([]PayoutMethod, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	methods, err := CheckMethods(methods)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM payout_methods WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+	for _, m := range methods {
+		if _, err := tx.Exec(ctx, `INSERT INTO payout_methods (user_id, rank, chain, address) VALUES ($1, $2, $3, $4)`,
+			userID, m.Rank, m.Chain, m.Address); err != nil {
+			return nil, err
+		}
+	}
+	return methods, tx.Commit(ctx)
+}
@@ -0,0 +1,285 @@
+// Package payouts queues money owed to users and sends it from the hot
+// wallets, batching pending payouts per chain/asset into as few transactions
+// as each chain allows, then follows each transaction until it is final.
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/failures"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusHeld      = "held"
+	StatusAccruing  = "accruing"
+	StatusMerged    = "merged"
+	StatusBatched   = "batched"
+	StatusSubmitted = "submitted"
+	StatusConfirmed = "confirmed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+var (
+	ErrNotFound      = errors.New("payout_not_found")
+	ErrInvalidStatus = errors.New("invalid_payout_status")
+)
+
+type Payout struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Chain     string    `json:"chain"`
+	Asset     string    `json:"asset"`
+	To        string    `json:"to_address"`
+	Amount    string    `json:"amount"`
+	Reference *string   `json:"reference,omitempty"`
+	// Work being paid for; set for bounties so the payout can be attested.
+	Repo     *string `json:"repo_full_name,omitempty"`
+	PRNumber *int    `json:"pr_number,omitempty"`
+	PRURL    *string `json:"pr_url,omitempty"`
+	// EscrowBountyID is set for releases from an escrow-funded bounty, which
+	// are sent as contract release calls rather than from the hot wallet.
+	EscrowBountyID *uuid.UUID `json:"escrow_bounty_id,omitempty"`
+	Status         string     `json:"status"`
+	BatchID        *uuid.UUID `json:"batch_id,omitempty"`
+	TxHash         *string    `json:"tx_hash,omitempty"`
+	// BlockNumber is the block (ledger on Stellar) that included TxHash, once
+	// it is confirmed or reverted.
+	BlockNumber *int64     `json:"block_number,omitempty"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	// Failure is Error classified for integrators; set when status is failed.
+	Failure *failures.Failure `json:"failure,omitempty"`
+	// MethodRank is the rank of the user's payout method the payout goes out
+	// with; unset when none was usable or the user ranks none.
+	MethodRank *int `json:"method_rank,omitempty"`
+	// RoutedFrom is where the payout was headed before it was routed to one
+	// of the user's payout methods.
+	RoutedFrom *Destination `json:"routed_from,omitempty"`
+	// RoutingNotes say why higher-ranked methods were passed over.
+	RoutingNotes []string `json:"routing_notes,omitempty"`
+	// MergedInto is the payout that paid this one out with the rest of the
+	// user's accrued balance; set when status is merged.
+	MergedInto *uuid.UUID `json:"merged_into,omitempty"`
+	// SettlesAccrual marks a payout of an accrued balance.
+	SettlesAccrual bool      `json:"settles_accrual,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Destination is a chain, asset and address a payout can be sent to.
+type Destination struct {
+	Chain string `json:"chain"`
+	Asset string `json:"asset"`
+	To    string `json:"to_address"`
+}
+
+type Batch struct {
+	ID          uuid.UUID         `json:"id"`
+	Chain       string            `json:"chain"`
+	Asset       string            `json:"asset"`
+	PayoutCount int               `json:"payout_count"`
+	TotalAmount string            `json:"total_amount"`
+	Status      string            `json:"status"`
+	TxHash      *string           `json:"tx_hash,omitempty"`
+	BlockNumber *int64            `json:"block_number,omitempty"`
+	ConfirmedAt *time.Time        `json:"confirmed_at,omitempty"`
+	Error       *string           `json:"error,omitempty"`
+	Failure     *failures.Failure `json:"failure,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+const payoutColumns = `id, user_id, chain, asset, to_address, amount::text, reference, repo_full_name, pr_number, pr_url, escrow_bounty_id, status, batch_id, tx_hash, block_number, confirmed_at, error, error_code, method_rank, original_chain, original_asset, original_to_address, routing_notes, merged_into, settles_accrual, created_at, updated_at`
+
+func scanPayout(row pgx.Row) (Payout, error) {
+	var p Payout
+	var code, fromChain, fromAsset, fromTo *string
+	err := row.Scan(&p.ID, &p.UserID, &p.Chain, &p.Asset, &p.To, &p.Amount, &p.Reference, &p.Repo, &p.PRNumber, &p.PRURL, &p.EscrowBountyID, &p.Status, &p.BatchID, &p.TxHash, &p.BlockNumber, &p.ConfirmedAt, &p.Error, &code,
+		&p.MethodRank, &fromChain, &fromAsset, &fromTo, &p.RoutingNotes, &p.MergedInto, &p.SettlesAccrual, &p.CreatedAt, &p.UpdatedAt)
+	p.Failure = failures.FromStored(code, p.Error)
+	if fromChain != nil && fromAsset != nil && fromTo != nil {
+		p.RoutedFrom = &Destination{Chain: *fromChain, Asset: *fromAsset, To: *fromTo}
+	}
+	return p, err
+}
+
+// Create queues a payout for the next batch run.
+func Create(ctx context.Context, pool *pgxpool.Pool, p Payout) (Payout, error) {
+	if pool == nil {
+		return Payout{}, fmt.Errorf("db not configured")
+	}
+	amt, err := wallet.ParseAmount(p.Amount)
+	if err != nil {
+		return Payout{}, err
+	}
+	if amt.Sign() == 0 {
+		return Payout{}, fmt.Errorf("amount must be positive")
+	}
+	ref := ""
+	if p.Reference != nil {
+		ref = *p.Reference
+	}
+	return scanPayout(pool.QueryRow(ctx, `
+INSERT INTO payouts (user_id, chain, asset, to_address, amount, reference, repo_full_name, pr_number, pr_url)
+VALUES ($1, $2, $3, $4, $5::numeric, NULLIF($6, ''), $7, $8, $9)
+RETURNING `+payoutColumns,
+		p.UserID, strings.ToLower(strings.TrimSpace(p.Chain)), strings.TrimSpace(p.Asset), chain.NormalizeAddress(p.To), p.Amount, ref,
+		p.Repo, p.PRNumber, p.PRURL))
+}
+
+func listPayouts(ctx context.Context, pool *pgxpool.Pool, where string, args ...any) ([]Payout, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+payoutColumns+` FROM payouts `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Payout{}
+	for rows.Next() {
+		p, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// Get returns a payout; when userID is set, only if it is owed to them.
+func Get(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID, userID *uuid.UUID) (Payout, error) {
+	if pool == nil {
+		return Payout{}, fmt.Errorf("db not configured")
+	}
+	p, err := scanPayout(pool.QueryRow(ctx, `SELECT `+payoutColumns+` FROM payouts WHERE id = $1 AND ($2::uuid IS NULL OR user_id = $2)`, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Payout{}, ErrNotFound
+	}
+	return p, err
+}
+
+func ListForUser(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, limit int) ([]Payout, error) {
+	return listPayouts(ctx, pool, `WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`, userID, limit)
+}
+
+// List returns payouts, optionally filtered by status ("" for all).
+func List(ctx context.Context, pool *pgxpool.Pool, status string, limit int) ([]Payout, error) {
+	return listPayouts(ctx, pool, `WHERE ($1 = '' OR status = $1) ORDER BY created_at DESC LIMIT $2`, status, limit)
+}
+
+func ListBatches(ctx context.Context, pool *pgxpool.Pool, limit int) ([]Batch, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, chain, asset, payout_count, total_amount::text, status, tx_hash, block_number, confirmed_at, error, error_code, created_at
+FROM payout_batches
+ORDER BY created_at DESC
+LIMIT $1
+`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Batch{}
+	for rows.Next() {
+		var b Batch
+		var code *string
+		if err := rows.Scan(&b.ID, &b.Chain, &b.Asset, &b.PayoutCount, &b.TotalAmount, &b.Status, &b.TxHash, &b.BlockNumber, &b.ConfirmedAt, &b.Error, &code, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		b.Failure = failures.FromStored(code, b.Error)
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// transition moves a payout from one of the allowed statuses to next.
+func transition(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID, next string, from ...string) (Payout, error) {
+	if pool == nil {
+		return Payout{}, fmt.Errorf("db not configured")
+	}
+	p, err := scanPayout(pool.QueryRow(ctx, `
+UPDATE payouts SET status = $2, batch_id = NULL, error = NULL, error_code = NULL,
+  routed_at = CASE WHEN $2 = 'pending' THEN NULL ELSE routed_at END, updated_at = now()
+WHERE id = $1 AND status = ANY($3)
+RETURNING `+payoutColumns, id, next, from))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM payouts WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return Payout{}, err
+		}
+		if !exists {
+			return Payout{}, ErrNotFound
+		}
+		return Payout{}, ErrInvalidStatus
+	}
+	return p, err
+}
+
+// Hold keeps a pending payout out of batches while a fraud review of it is
+// open.
+func Hold(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (Payout, error) {
+	return transition(ctx, pool, id, StatusHeld, StatusPending)
+}
+
+// ResolveHold acts on a resolved fraud review of a held payout: confirmed
+// fraud cancels it, and once no review of it is open it is queued again.
+// Payouts that are not held are left alone.
+func ResolveHold(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID, fraudConfirmed bool) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	if fraudConfirmed {
+		_, err := Cancel(ctx, pool, id)
+		if errors.Is(err, ErrInvalidStatus) {
+			return nil
+		}
+		return err
+	}
+	_, err := pool.Exec(ctx, `
+UPDATE payouts SET status = 'pending', updated_at = now()
+WHERE id = $1 AND status = 'held'
+  AND NOT EXISTS (
+    SELECT 1 FROM fraud_reviews r
+    WHERE r.event = 'payout' AND r.subject_id = $1::text AND r.status = 'open'
+  )
+`, id)
+	return err
+}
+
+// Cancel withdraws a payout that has not been sent. A cancelled escrow
+// release leaves the bounty's reward locked for another; a cancelled
+// accruing payout comes off the user's accrued balance.
+func Cancel(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (Payout, error) {
+	if pool == nil {
+		return Payout{}, fmt.Errorf("db not configured")
+	}
+	if p, err := cancelAccruing(ctx, pool, id); !errors.Is(err, pgx.ErrNoRows) {
+		return p, err
+	}
+	p, err := transition(ctx, pool, id, StatusCancelled, StatusPending, StatusHeld, StatusFailed)
+	if err != nil || p.EscrowBountyID == nil {
+		return p, err
+	}
+	_, err = pool.Exec(ctx, `UPDATE bounties SET escrow_status = 'locked', updated_at = now() WHERE id = $1 AND escrow_status = 'releasing'`, *p.EscrowBountyID)
+	return p, err
+}
+
+// Retry requeues a failed payout, to be routed again. Operators should
+// confirm on-chain that the failed batch did not land before retrying.
+func Retry(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (Payout, error) {
+	return transition(ctx, pool, id, StatusPending, StatusFailed)
+}
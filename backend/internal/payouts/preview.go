@@ -0,0 +1,125 @@
+package payouts
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+const (
+	// MethodPush sends from the hot wallet; the platform pays network fees.
+	MethodPush = "push"
+	// MethodPermit relays an EIP-2612 permit signed by a recipient who holds
+	// tokens but no native gas; the relayer fee is taken from the tokens.
+	MethodPermit = "permit"
+)
+
+var (
+	ErrRelayNotOffered = errors.New("relay_not_offered")
+	ErrBelowRelayerFee = errors.New("amount_below_relayer_fee")
+	ErrUnknownMethod   = errors.New("unknown_method")
+)
+
+// RelayerFees is the flat fee charged per relayed transfer, keyed by
+// ledger.Asset(chain, token). Only listed assets may be relayed, so the
+// relayer never pays gas for arbitrary tokens.
+type RelayerFees map[string]*big.Rat
+
+// ParseRelayerFees parses "chain/token=amount,chain/token=amount". Malformed
+// entries are skipped.
+func ParseRelayerFees(s string) RelayerFees {
+	return RelayerFees(parseAssetAmounts(s))
+}
+
+// parseAssetAmounts parses "chain/asset=amount,..." keyed by
+// ledger.Asset(chain, asset), lowercased. Malformed entries are skipped.
+func parseAssetAmounts(s string) map[string]*big.Rat {
+	out := map[string]*big.Rat{}
+	for _, pair := range strings.Split(s, ",") {
+		key, amount, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		chainName, token, ok := strings.Cut(strings.TrimSpace(key), "/")
+		if !ok {
+			continue
+		}
+		v, err := wallet.ParseAmount(amount)
+		if err != nil {
+			continue
+		}
+		out[assetKey(chainName, token)] = v
+	}
+	return out
+}
+
+func assetKey(chainName, asset string) string {
+	return ledger.Asset(strings.ToLower(strings.TrimSpace(chainName)), strings.ToLower(strings.TrimSpace(asset)))
+}
+
+// Fee returns the relayer fee for the asset, if it can be relayed at all.
+func (f RelayerFees) Fee(chainName, token string) (*big.Rat, bool) {
+	fee, ok := f[assetKey(chainName, token)]
+	return fee, ok
+}
+
+type Preview struct {
+	Chain            string `json:"chain"`
+	Asset            string `json:"asset"`
+	Method           string `json:"method"`
+	Amount           string `json:"amount"`
+	RelayerFee       string `json:"relayer_fee"`
+	NetAmount        string `json:"net_amount"`
+	NetworkFeePaidBy string `json:"network_fee_paid_by"`
+}
+
+// BuildPreview shows what the recipient ends up with for a given method.
+func BuildPreview(wallets wallet.Registry, fees RelayerFees, chainName, asset, amount, method string) (Preview, error) {
+	amt, err := wallet.ParseAmount(amount)
+	if err != nil {
+		return Preview{}, err
+	}
+	if method == "" {
+		method = MethodPush
+	}
+	p := Preview{
+		Chain:  strings.ToLower(strings.TrimSpace(chainName)),
+		Asset:  strings.TrimSpace(asset),
+		Method: method,
+	}
+
+	fee := new(big.Rat)
+	switch method {
+	case MethodPush:
+		p.NetworkFeePaidBy = "platform"
+	case MethodPermit:
+		sender, ok := wallets.Get(p.Chain)
+		if !ok {
+			return Preview{}, ErrRelayNotOffered
+		}
+		r, ok := sender.(wallet.PermitRelayer)
+		if !ok {
+			return Preview{}, ErrRelayNotOffered
+		}
+		if _, ok := r.PermitSpender(); !ok {
+			return Preview{}, ErrRelayNotOffered
+		}
+		if fee, ok = fees.Fee(p.Chain, p.Asset); !ok {
+			return Preview{}, ErrRelayNotOffered
+		}
+		if amt.Cmp(fee) <= 0 {
+			return Preview{}, ErrBelowRelayerFee
+		}
+		p.NetworkFeePaidBy = "relayer"
+	default:
+		return Preview{}, ErrUnknownMethod
+	}
+
+	p.Amount = wallet.FormatAmount(amt, 18)
+	p.RelayerFee = wallet.FormatAmount(fee, 18)
+	p.NetAmount = wallet.FormatAmount(new(big.Rat).Sub(amt, fee), 18)
+	return p, nil
+}
@@ -0,0 +1,39 @@
+package payouts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+func TestParseRelayerFees(t *testing.T) {
+	fees := ParseRelayerFees("Base/0xABC=0.5, ethereum/0xdef=2,bad,polygon=1")
+	if len(fees) != 2 {
+		t.Fatalf("got %d fees, want 2", len(fees))
+	}
+	fee, ok := fees.Fee("base", "0xabc")
+	if !ok || fee.FloatString(1) != "0.5" {
+		t.Fatalf("base fee = %v, %v", fee, ok)
+	}
+	if _, ok := fees.Fee("polygon", ""); ok {
+		t.Fatal("malformed entry should be skipped")
+	}
+}
+
+func TestBuildPreview(t *testing.T) {
+	p, err := BuildPreview(wallet.Registry{}, nil, "Stellar", "XLM", "12.5", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Method != MethodPush || p.NetAmount != "12.5" || p.RelayerFee != "0" || p.NetworkFeePaidBy != "platform" {
+		t.Fatalf("unexpected push preview %+v", p)
+	}
+
+	if _, err := BuildPreview(wallet.Registry{}, ParseRelayerFees("base/0xabc=1"), "base", "0xabc", "5", MethodPermit); !errors.Is(err, ErrRelayNotOffered) {
+		t.Fatalf("permit without relayer: got %v", err)
+	}
+	if _, err := BuildPreview(wallet.Registry{}, nil, "base", "0xabc", "5", "carrier-pigeon"); !errors.Is(err, ErrUnknownMethod) {
+		t.Fatalf("unknown method: got %v", err)
+	}
+}
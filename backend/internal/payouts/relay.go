@@ -0,0 +1,198 @@
+package payouts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// permitTTL is how long a permit signature requested now stays valid.
+const permitTTL = 30 * time.Minute
+
+type RelayedTransfer struct {
+	ID         uuid.UUID `json:"id"`
+	Chain      string    `json:"chain"`
+	Token      string    `json:"token"`
+	Owner      string    `json:"owner_address"`
+	To         string    `json:"to_address"`
+	Amount     string    `json:"amount"`
+	RelayerFee string    `json:"relayer_fee"`
+	Status     string    `json:"status"`
+	TxHash     *string   `json:"tx_hash,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PermitRequest is what a client needs to ask the owner for a signature.
+type PermitRequest struct {
+	Preview   Preview        `json:"preview"`
+	Deadline  int64          `json:"deadline"`
+	TypedData map[string]any `json:"typed_data"`
+}
+
+func relayerFor(wallets wallet.Registry, chainName string) (wallet.Sender, wallet.PermitRelayer, error) {
+	sender, ok := wallets.Get(chainName)
+	if !ok {
+		return nil, nil, ErrRelayNotOffered
+	}
+	r, ok := sender.(wallet.PermitRelayer)
+	if !ok {
+		return nil, nil, ErrRelayNotOffered
+	}
+	return sender, r, nil
+}
+
+// NewPermitRequest quotes the relayed transfer and returns the EIP-712 permit
+// for the owner to sign.
+func NewPermitRequest(ctx context.Context, wallets wallet.Registry, fees RelayerFees, chainName, token, owner, amount string) (PermitRequest, error) {
+	preview, err := BuildPreview(wallets, fees, chainName, token, amount, MethodPermit)
+	if err != nil {
+		return PermitRequest{}, err
+	}
+	sender, r, err := relayerFor(wallets, preview.Chain)
+	if err != nil {
+		return PermitRequest{}, err
+	}
+	dec, err := sender.Decimals(ctx, token)
+	if err != nil {
+		return PermitRequest{}, err
+	}
+	amt, _ := wallet.ParseAmount(preview.Amount)
+	deadline := time.Now().Add(permitTTL).Unix()
+	td, err := r.PermitTypedData(ctx, token, owner, wallet.ToBaseUnits(amt, dec), deadline)
+	if err != nil {
+		return PermitRequest{}, err
+	}
+	return PermitRequest{Preview: preview, Deadline: deadline, TypedData: td}, nil
+}
+
+type RelayClaim struct {
+	Chain     string
+	Token     string
+	Owner     string
+	To        string
+	Amount    string
+	Deadline  int64
+	Signature string
+}
+
+// Relay submits a signed permit transfer and records it. The fee is booked to
+// the fees account against the user so it shows up in their ledger proofs.
+func Relay(ctx context.Context, pool *pgxpool.Pool, wallets wallet.Registry, fees RelayerFees, userID uuid.UUID, c RelayClaim) (RelayedTransfer, error) {
+	if pool == nil {
+		return RelayedTransfer{}, fmt.Errorf("db not configured")
+	}
+	preview, err := BuildPreview(wallets, fees, c.Chain, c.Token, c.Amount, MethodPermit)
+	if err != nil {
+		return RelayedTransfer{}, err
+	}
+	sender, r, err := relayerFor(wallets, preview.Chain)
+	if err != nil {
+		return RelayedTransfer{}, err
+	}
+	dec, err := sender.Decimals(ctx, c.Token)
+	if err != nil {
+		return RelayedTransfer{}, err
+	}
+	amt, _ := wallet.ParseAmount(preview.Amount)
+	fee, _ := wallet.ParseAmount(preview.RelayerFee)
+	if !fitsDecimals(amt, dec) || !fitsDecimals(fee, dec) {
+		return RelayedTransfer{}, fmt.Errorf("amount exceeds token precision")
+	}
+
+	rt := RelayedTransfer{
+		Chain:      preview.Chain,
+		Token:      chain.NormalizeAddress(c.Token),
+		Owner:      chain.NormalizeAddress(c.Owner),
+		To:         chain.NormalizeAddress(c.To),
+		Amount:     preview.Amount,
+		RelayerFee: preview.RelayerFee,
+		Status:     "submitted",
+	}
+	txHash, sendErr := r.RelayPermitTransfer(ctx, wallet.PermitTransfer{
+		Token:     c.Token,
+		Owner:     c.Owner,
+		To:        c.To,
+		Value:     wallet.ToBaseUnits(amt, dec),
+		Fee:       wallet.ToBaseUnits(fee, dec),
+		Deadline:  c.Deadline,
+		Signature: c.Signature,
+	})
+	var errMsg string
+	if sendErr != nil {
+		rt.Status = "failed"
+		errMsg = sendErr.Error()
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return RelayedTransfer{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	err = tx.QueryRow(ctx, `
+INSERT INTO relayed_transfers (user_id, chain, token, owner_address, to_address, amount, relayer_fee, status, tx_hash, error)
+VALUES ($1, $2, $3, $4, $5, $6::numeric, $7::numeric, $8, NULLIF($9, ''), NULLIF($10, ''))
+RETURNING id, tx_hash, error, created_at
+`, userID, rt.Chain, rt.Token, rt.Owner, rt.To, rt.Amount, rt.RelayerFee, rt.Status, txHash, errMsg).
+		Scan(&rt.ID, &rt.TxHash, &rt.Error, &rt.CreatedAt)
+	if err != nil {
+		return RelayedTransfer{}, err
+	}
+	if sendErr == nil && fee.Sign() > 0 {
+		if _, err := ledger.Append(ctx, tx, &userID, ledger.AccountFees, ledger.KindFee,
+			ledger.Asset(rt.Chain, rt.Token), rt.RelayerFee, "relay:"+rt.ID.String()); err != nil {
+			return RelayedTransfer{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return RelayedTransfer{}, err
+	}
+
+	if sendErr != nil {
+		return rt, sendErr
+	}
+	slog.Info("permit transfer relayed",
+		"user_id", userID.String(),
+		"chain", rt.Chain,
+		"token", rt.Token,
+		"amount", rt.Amount,
+		"relayer_fee", rt.RelayerFee,
+		"tx_hash", txHash,
+	)
+	return rt, nil
+}
+
+func ListRelayed(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, limit int) ([]RelayedTransfer, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, chain, token, owner_address, to_address, amount::text, relayer_fee::text, status, tx_hash, error, created_at
+FROM relayed_transfers
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []RelayedTransfer{}
+	for rows.Next() {
+		var rt RelayedTransfer
+		if err := rows.Scan(&rt.ID, &rt.Chain, &rt.Token, &rt.Owner, &rt.To, &rt.Amount, &rt.RelayerFee, &rt.Status, &rt.TxHash, &rt.Error, &rt.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, rt)
+	}
+	return out, rows.Err()
+}
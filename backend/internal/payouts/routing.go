@@ -0,0 +1,236 @@
+package payouts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// AssetGroups says which assets stand in for each other across chains, such
+// as USDC on Base, Solana and Stellar. It maps each member, keyed by
+// assetKey, to its group's asset per chain.
+type AssetGroups map[string]map[string]string
+
+// ParseAssetGroups parses "chain/asset,chain/asset;chain/asset,...", one
+// group per ";". Malformed members are skipped.
+func ParseAssetGroups(s string) AssetGroups {
+	out := AssetGroups{}
+	for _, group := range strings.Split(s, ";") {
+		byChain := map[string]string{}
+		var keys []string
+		for _, member := range strings.Split(group, ",") {
+			chainName, asset, ok := strings.Cut(strings.TrimSpace(member), "/")
+			chainName, asset = strings.ToLower(strings.TrimSpace(chainName)), strings.TrimSpace(asset)
+			if !ok || chainName == "" || asset == "" {
+				continue
+			}
+			byChain[chainName] = asset
+			keys = append(keys, assetKey(chainName, asset))
+		}
+		for _, k := range keys {
+			out[k] = byChain
+		}
+	}
+	return out
+}
+
+// Equivalent returns the asset on toChain worth the same as asset on
+// fromChain: the asset itself on the same chain, else its group's member.
+func (g AssetGroups) Equivalent(fromChain, asset, toChain string) (string, bool) {
+	if strings.EqualFold(fromChain, toChain) {
+		return asset, true
+	}
+	a, ok := g[assetKey(fromChain, asset)][strings.ToLower(toChain)]
+	return a, ok
+}
+
+// Minimums is the smallest payout worth sending per chain/asset, keyed by
+// assetKey.
+type Minimums map[string]*big.Rat
+
+// ParseMinimums parses "chain/asset=amount,...". Malformed entries are
+// skipped.
+func ParseMinimums(s string) Minimums {
+	return Minimums(parseAssetAmounts(s))
+}
+
+// Router picks where pending payouts go: the first of the user's payout
+// methods whose chain has a hot wallet, is not congested, carries an
+// equivalent asset and whose minimum the amount meets. When none does the
+// payout keeps its original destination.
+type Router struct {
+	Wallets  wallet.Registry
+	Groups   AssetGroups
+	Minimums Minimums
+	// MaxLoad is the chain load (see wallet.LoadReader) above which a method
+	// is passed over; zero never passes over.
+	MaxLoad float64
+}
+
+// Route is where a payout goes and why better-ranked methods were skipped.
+// Rank is zero when the payout keeps its original destination.
+type Route struct {
+	Destination
+	Rank  int
+	Notes []string
+}
+
+// loads reads each chain's load at most once per routing pass. Chains whose
+// sender cannot tell report zero.
+func (r *Router) loads(ctx context.Context) func(chainName string) (float64, error) {
+	type reading struct {
+		load float64
+		err  error
+	}
+	seen := map[string]reading{}
+	return func(chainName string) (float64, error) {
+		if v, ok := seen[chainName]; ok {
+			return v.load, v.err
+		}
+		var v reading
+		if lr, ok := r.Wallets[chainName].(wallet.LoadReader); ok {
+			v.load, v.err = lr.Load(ctx)
+		}
+		seen[chainName] = v
+		return v.load, v.err
+	}
+}
+
+// choose routes amount of the asset at orig through methods, best first.
+func (r *Router) choose(orig Destination, amount *big.Rat, methods []PayoutMethod, load func(chainName string) (float64, error)) Route {
+	var notes []string
+	skip := func(m PayoutMethod, why string) {
+		notes = append(notes, fmt.Sprintf("#%d %s: %s", m.Rank, m.Chain, why))
+	}
+	for _, m := range methods {
+		asset, ok := r.Groups.Equivalent(orig.Chain, orig.Asset, m.Chain)
+		if !ok {
+			skip(m, "no equivalent of "+orig.Asset)
+			continue
+		}
+		if _, ok := r.Wallets.Get(m.Chain); !ok {
+			skip(m, "no hot wallet")
+			continue
+		}
+		if floor, ok := r.Minimums[assetKey(m.Chain, asset)]; ok && amount.Cmp(floor) < 0 {
+			skip(m, "below minimum of "+wallet.FormatAmount(floor, 18))
+			continue
+		}
+		if r.MaxLoad > 0 {
+			l, err := load(m.Chain)
+			if err != nil {
+				skip(m, "load unknown: "+err.Error())
+				continue
+			}
+			if l > r.MaxLoad {
+				skip(m, fmt.Sprintf("congested at %.0f%% load", l*100))
+				continue
+			}
+		}
+		return Route{Destination: Destination{Chain: m.Chain, Asset: asset, To: m.Address}, Rank: m.Rank, Notes: notes}
+	}
+	if len(methods) > 0 {
+		notes = append(notes, "no payout method usable; kept the original destination")
+	}
+	return Route{Destination: orig, Notes: notes}
+}
+
+// RouteAll routes every pending hot wallet payout not yet routed whose user
+// ranks payout methods. Payouts are routed from where they were first
+// headed, so a retried payout may fall back to a better method.
+func (r *Router) RouteAll(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	rows, err := pool.Query(ctx, `
+SELECT id, user_id, COALESCE(original_chain, chain), COALESCE(original_asset, asset), COALESCE(original_to_address, to_address), amount::text
+FROM payouts p
+WHERE status = 'pending' AND escrow_bounty_id IS NULL AND routed_at IS NULL
+  AND EXISTS (SELECT 1 FROM payout_methods m WHERE m.user_id = p.user_id)
+ORDER BY created_at
+`)
+	if err != nil {
+		return 0, err
+	}
+	type unrouted struct {
+		id, userID uuid.UUID
+		orig       Destination
+		amount     string
+	}
+	var todo []unrouted
+	for rows.Next() {
+		var u unrouted
+		if err := rows.Scan(&u.id, &u.userID, &u.orig.Chain, &u.orig.Asset, &u.orig.To, &u.amount); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		todo = append(todo, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	methods := map[uuid.UUID][]PayoutMethod{}
+	load := r.loads(ctx)
+	routed := 0
+	for _, u := range todo {
+		ms, ok := methods[u.userID]
+		if !ok {
+			if ms, err = Methods(ctx, pool, u.userID); err != nil {
+				return routed, err
+			}
+			methods[u.userID] = ms
+		}
+		amt, err := wallet.ParseAmount(u.amount)
+		if err != nil {
+			return routed, err
+		}
+		route := r.choose(u.orig, amt, ms, load)
+		var rank *int
+		if route.Rank > 0 {
+			rank = &route.Rank
+		}
+		// original_* stay NULL unless the payout goes somewhere else.
+		var fromChain, fromAsset, fromTo *string
+		moved := route.Destination != u.orig
+		if moved {
+			fromChain, fromAsset, fromTo = &u.orig.Chain, &u.orig.Asset, &u.orig.To
+		}
+		if _, err := pool.Exec(ctx, `
+UPDATE payouts SET chain = $2, asset = $3, to_address = $4,
+  original_chain = $5, original_asset = $6, original_to_address = $7,
+  method_rank = $8, routing_notes = $9, routed_at = now(), updated_at = now()
+WHERE id = $1 AND status = 'pending' AND routed_at IS NULL
+`, u.id, route.Chain, route.Asset, route.To, fromChain, fromAsset, fromTo,
+			rank, append([]string{}, route.Notes...)); err != nil {
+			return routed, err
+		}
+		if moved {
+			routed++
+			slog.Info("payout routed to payout method",
+				"payout_id", u.id.String(),
+				"rank", route.Rank,
+				"chain", route.Chain,
+				"from_chain", u.orig.Chain,
+			)
+		}
+	}
+	return routed, nil
+}
+
+// NewRouterFromConfig builds the router for the configured asset groups,
+// minimums and congestion threshold.
+func NewRouterFromConfig(cfg config.Config, wallets wallet.Registry) *Router {
+	return &Router{
+		Wallets:  wallets,
+		Groups:   ParseAssetGroups(cfg.PayoutAssetGroups),
+		Minimums: ParseMinimums(cfg.PayoutMinimums),
+		MaxLoad:  float64(cfg.PayoutCongestionPercent) / 100,
+	}
+}
@@ -0,0 +1,113 @@
+package payouts
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+const (
+	baseUSDC    = "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913"
+	solanaUSDC  = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	stellarUSDC = "USDC:GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN"
+)
+
+// hotWallet stands in for a chain's sender in the registry.
+type hotWallet struct{ wallet.Sender }
+
+func TestAssetGroups(t *testing.T) {
+	g := ParseAssetGroups("base/" + baseUSDC + ", solana/" + solanaUSDC + ",stellar/" + stellarUSDC + ";bad;ethereum/native,polygon/native")
+	if a, ok := g.Equivalent("Base", strings.ToUpper(baseUSDC), "stellar"); !ok || a != stellarUSDC {
+		t.Errorf("base usdc on stellar = %q, %v", a, ok)
+	}
+	if a, ok := g.Equivalent("stellar", stellarUSDC, "base"); !ok || a != baseUSDC {
+		t.Errorf("stellar usdc on base = %q, %v", a, ok)
+	}
+	if a, ok := g.Equivalent("base", "native", "base"); !ok || a != "native" {
+		t.Errorf("same chain = %q, %v", a, ok)
+	}
+	if _, ok := g.Equivalent("base", baseUSDC, "polygon"); ok {
+		t.Error("usdc has no polygon member")
+	}
+}
+
+func TestChoose(t *testing.T) {
+	methods := []PayoutMethod{
+		{Rank: 1, Chain: "base", Address: "0x1111111111111111111111111111111111111111"},
+		{Rank: 2, Chain: "solana", Address: "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"},
+		{Rank: 3, Chain: "stellar", Address: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"},
+	}
+	orig := Destination{Chain: "base", Asset: baseUSDC, To: "0x2222222222222222222222222222222222222222"}
+	loads := map[string]float64{"base": 0.97, "stellar": 0.4}
+	load := func(chainName string) (float64, error) { return loads[chainName], nil }
+	r := &Router{
+		Wallets:  wallet.Registry{"base": hotWallet{}, "stellar": hotWallet{}},
+		Groups:   ParseAssetGroups("base/" + baseUSDC + ",solana/" + solanaUSDC + ",stellar/" + stellarUSDC),
+		Minimums: ParseMinimums("stellar/" + stellarUSDC + "=5"),
+		MaxLoad:  0.9,
+	}
+
+	// Base is congested and there is no Solana hot wallet, so Stellar it is.
+	got := r.choose(orig, big.NewRat(10, 1), methods, load)
+	want := Destination{Chain: "stellar", Asset: stellarUSDC, To: methods[2].Address}
+	if got.Rank != 3 || got.Destination != want {
+		t.Fatalf("route = %+v", got)
+	}
+	if len(got.Notes) != 2 || !strings.Contains(got.Notes[0], "congested at 97% load") || !strings.Contains(got.Notes[1], "no hot wallet") {
+		t.Errorf("notes = %q", got.Notes)
+	}
+
+	// Below Stellar's minimum nothing is usable; the payout stays put.
+	got = r.choose(orig, big.NewRat(1, 1), methods, load)
+	if got.Rank != 0 || got.Destination != orig || len(got.Notes) != 4 || !strings.Contains(got.Notes[2], "below minimum of 5") {
+		t.Errorf("route below minimum = %+v", got)
+	}
+
+	// Once Base clears, the preferred method wins outright.
+	loads["base"] = 0.2
+	got = r.choose(orig, big.NewRat(10, 1), methods, load)
+	if got.Rank != 1 || got.To != methods[0].Address || got.Asset != baseUSDC || len(got.Notes) != 0 {
+		t.Errorf("route with base clear = %+v", got)
+	}
+
+	// Without ranked methods the payout is left alone, without notes.
+	got = r.choose(orig, big.NewRat(10, 1), nil, load)
+	if got.Rank != 0 || got.Destination != orig || got.Notes != nil {
+		t.Errorf("route without methods = %+v", got)
+	}
+}
+
+func TestCheckMethods(t *testing.T) {
+	got, err := CheckMethods([]PayoutMethod{
+		{Rank: 7, Chain: " Base ", Address: "0xAbCd000000000000000000000000000000000001"},
+		{Chain: "stellar", Address: "gbrpyhil2ci3fnq4bxlfmndlfjunpu2hy3zmfshonuceoasw7qc7ox2h"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Rank != 1 || got[0].Chain != "base" || got[0].Address != "0xabcd000000000000000000000000000000000001" {
+		t.Errorf("first = %+v", got[0])
+	}
+	if got[1].Rank != 2 || got[1].Address != "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H" {
+		t.Errorf("second = %+v", got[1])
+	}
+
+	for name, tc := range map[string]struct {
+		methods []PayoutMethod
+		want    error
+	}{
+		"chain":     {[]PayoutMethod{{Chain: "dogecoin", Address: "D8vF"}}, ErrMethodChain},
+		"evm":       {[]PayoutMethod{{Chain: "base", Address: "0x12"}}, ErrMethodAddress},
+		"stellar":   {[]PayoutMethod{{Chain: "stellar", Address: "deadbeef"}}, ErrMethodAddress},
+		"solana":    {[]PayoutMethod{{Chain: "solana", Address: "0OIl"}}, ErrMethodAddress},
+		"duplicate": {[]PayoutMethod{{Chain: "base", Address: "0x1111111111111111111111111111111111111111"}, {Chain: "BASE", Address: "0x1111111111111111111111111111111111111111"}}, ErrDuplicateMethodChain},
+		"too many":  {make([]PayoutMethod, MaxMethods+1), ErrTooManyMethods},
+	} {
+		if _, err := CheckMethods(tc.methods); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/failures"
+)
+
+var (
+	ErrWindowNotFound = errors.New("payout_window_not_found")
+	ErrWindowExists   = errors.New("payout_window_exists")
+	ErrInvalidWindow  = errors.New("invalid_payout_window")
+)
+
+// Window is a weekly slot, in UTC, in which queued payouts are sent. With no
+// windows defined the batcher sends on every run.
+type Window struct {
+	ID        uuid.UUID    `json:"id"`
+	Weekday   time.Weekday `json:"weekday"`
+	Hour      int          `json:"hour"`
+	Minute    int          `json:"minute"`
+	CreatedBy *uuid.UUID   `json:"created_by,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// LastOccurrence is the latest start of w at or before now.
+func (w Window) LastOccurrence(now time.Time) time.Time {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), w.Hour, w.Minute, 0, 0, time.UTC)
+	back := (int(now.Weekday()) - int(w.Weekday) + 7) % 7
+	occ := day.AddDate(0, 0, -back)
+	if occ.After(now) {
+		occ = occ.AddDate(0, 0, -7)
+	}
+	return occ
+}
+
+// NextOccurrence is the earliest start of w after now.
+func (w Window) NextOccurrence(now time.Time) time.Time {
+	return w.LastOccurrence(now).AddDate(0, 0, 7)
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("%s %02d:%02d UTC", w.Weekday, w.Hour, w.Minute)
+}
+
+// WindowRun summarises the payouts sent in one window occurrence.
+type WindowRun struct {
+	ID           uuid.UUID     `json:"id"`
+	WindowID     *uuid.UUID    `json:"window_id,omitempty"`
+	ScheduledFor time.Time     `json:"scheduled_for"`
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   *time.Time    `json:"finished_at,omitempty"`
+	Batches      int           `json:"batches"`
+	Payouts      int           `json:"payouts"`
+	Failed       int           `json:"failed"`
+	Totals       []WindowTotal `json:"totals"`
+	// Failures groups the run's failed payouts by failure code.
+	Failures []WindowFailure `json:"failures,omitempty"`
+}
+
+// WindowFailure counts a run's failed payouts sharing one failure code.
+type WindowFailure struct {
+	failures.Failure
+	Payouts int `json:"payouts"`
+}
+
+// WindowTotal is what a run sent on one chain/asset.
+type WindowTotal struct {
+	Chain   string `json:"chain"`
+	Asset   string `json:"asset"`
+	Payouts int    `json:"payouts"`
+	Amount  string `json:"amount"`
+}
+
+// Text renders the run as a short chat message for admins.
+func (r WindowRun) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Payout window %s: %d payouts in %d batches", r.ScheduledFor.UTC().Format("Mon 2006-01-02 15:04 UTC"), r.Payouts, r.Batches)
+	if r.Failed > 0 {
+		fmt.Fprintf(&b, ", %d failed", r.Failed)
+	}
+	for _, t := range r.Totals {
+		fmt.Fprintf(&b, "\n- %s %s on %s (%d payouts)", t.Amount, t.Asset, t.Chain, t.Payouts)
+	}
+	for _, f := range r.Failures {
+		fmt.Fprintf(&b, "\n! %d failed with %s: %s", f.Payouts, f.Code, f.Remediation)
+	}
+	return b.String()
+}
+
+// ParseWindow reads a weekday name ("friday", "fri") and a 24h UTC time
+// ("15:00") into an unsaved Window.
+func ParseWindow(weekday, at string) (Window, error) {
+	day := strings.ToLower(strings.TrimSpace(weekday))
+	w := Window{Weekday: -1}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if day == name || (len(day) >= 3 && strings.HasPrefix(name, day)) {
+			w.Weekday = d
+			break
+		}
+	}
+	if w.Weekday < 0 {
+		return Window{}, ErrInvalidWindow
+	}
+	t, err := time.Parse("15:04", strings.TrimSpace(at))
+	if err != nil {
+		return Window{}, ErrInvalidWindow
+	}
+	w.Hour, w.Minute = t.Hour(), t.Minute()
+	return w, nil
+}
+
+const windowColumns = `id, weekday, hour, minute, created_by, created_at`
+
+func scanWindow(row pgx.Row) (Window, error) {
+	var w Window
+	var weekday int16
+	var hour, minute int16
+	if err := row.Scan(&w.ID, &weekday, &hour, &minute, &w.CreatedBy, &w.CreatedAt); err != nil {
+		return Window{}, err
+	}
+	w.Weekday, w.Hour, w.Minute = time.Weekday(weekday), int(hour), int(minute)
+	return w, nil
+}
+
+func CreateWindow(ctx context.Context, pool *pgxpool.Pool, weekday time.Weekday, hour, minute int, createdBy uuid.UUID) (Window, error) {
+	if pool == nil {
+		return Window{}, fmt.Errorf("db not configured")
+	}
+	if weekday < time.Sunday || weekday > time.Saturday || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return Window{}, ErrInvalidWindow
+	}
+	w, err := scanWindow(pool.QueryRow(ctx, `
+INSERT INTO payout_windows (weekday, hour, minute, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING `+windowColumns, int16(weekday), int16(hour), int16(minute), createdBy))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return Window{}, ErrWindowExists
+	}
+	return w, err
+}
+
+func ListWindows(ctx context.Context, pool *pgxpool.Pool) ([]Window, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+windowColumns+` FROM payout_windows ORDER BY weekday, hour, minute`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Window{}
+	for rows.Next() {
+		w, err := scanWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// DeleteWindow removes a window; its past runs are kept.
+func DeleteWindow(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM payout_windows WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWindowNotFound
+	}
+	return nil
+}
+
+// NextWindow is when queued payouts will next be sent, or nil when no
+// windows are defined and they go out on the next batch run.
+func NextWindow(windows []Window, now time.Time) *time.Time {
+	var next *time.Time
+	for _, w := range windows {
+		t := w.NextOccurrence(now)
+		if next == nil || t.Before(*next) {
+			next = &t
+		}
+	}
+	return next
+}
+
+// claimDueWindows records a run for every window whose latest occurrence has
+// passed without one. Occurrences before a window was created don't count.
+func claimDueWindows(ctx context.Context, pool *pgxpool.Pool, windows []Window, now time.Time) ([]WindowRun, error) {
+	var runs []WindowRun
+	for _, w := range windows {
+		occ := w.LastOccurrence(now)
+		if occ.Before(w.CreatedAt) {
+			continue
+		}
+		r := WindowRun{WindowID: &w.ID, ScheduledFor: occ}
+		err := pool.QueryRow(ctx, `
+INSERT INTO payout_window_runs (window_id, scheduled_for)
+VALUES ($1, $2)
+ON CONFLICT (window_id, scheduled_for) DO NOTHING
+RETURNING id, started_at
+`, w.ID, occ).Scan(&r.ID, &r.StartedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, nil
+}
+
+// finishWindowRun stores the run's counts and loads its per-asset totals.
+func finishWindowRun(ctx context.Context, pool *pgxpool.Pool, r WindowRun, res RunResult) (WindowRun, error) {
+	if err := pool.QueryRow(ctx, `
+UPDATE payout_window_runs SET finished_at = now(), batches = $2, payouts = $3, failed = $4
+WHERE id = $1
+RETURNING finished_at
+`, r.ID, res.Batches, res.Payouts, res.Failed).Scan(&r.FinishedAt); err != nil {
+		return r, err
+	}
+	r.Batches, r.Payouts, r.Failed = res.Batches, res.Payouts, res.Failed
+	return loadWindowDetail(ctx, pool, r)
+}
+
+func loadWindowDetail(ctx context.Context, pool *pgxpool.Pool, r WindowRun) (WindowRun, error) {
+	totals, err := windowTotals(ctx, pool, r.ID)
+	if err != nil {
+		return r, err
+	}
+	r.Totals = totals
+	r.Failures, err = windowFailures(ctx, pool, r.ID)
+	return r, err
+}
+
+// windowFailures groups the run's failed payouts by code; the message shown
+// is one example of the group's raw errors.
+func windowFailures(ctx context.Context, pool *pgxpool.Pool, runID uuid.UUID) ([]WindowFailure, error) {
+	rows, err := pool.Query(ctx, `
+SELECT COALESCE(p.error_code, ''), MIN(COALESCE(p.error, '')), COUNT(*)::int
+FROM payouts p
+JOIN payout_batches b ON b.id = p.batch_id
+WHERE b.window_run_id = $1 AND p.status = 'failed'
+GROUP BY 1
+ORDER BY 3 DESC, 1
+`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []WindowFailure
+	for rows.Next() {
+		var code, msg string
+		var n int
+		if err := rows.Scan(&code, &msg, &n); err != nil {
+			return nil, err
+		}
+		out = append(out, WindowFailure{Failure: failures.New(code, msg), Payouts: n})
+	}
+	return out, rows.Err()
+}
+
+func windowTotals(ctx context.Context, pool *pgxpool.Pool, runID uuid.UUID) ([]WindowTotal, error) {
+	rows, err := pool.Query(ctx, `
+SELECT chain, asset, SUM(payout_count)::int, SUM(total_amount)::text
+FROM payout_batches
+WHERE window_run_id = $1 AND status <> 'failed'
+GROUP BY chain, asset
+ORDER BY chain, asset
+`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []WindowTotal{}
+	for rows.Next() {
+		var t WindowTotal
+		if err := rows.Scan(&t.Chain, &t.Asset, &t.Payouts, &t.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func ListWindowRuns(ctx context.Context, pool *pgxpool.Pool, limit int) ([]WindowRun, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, window_id, scheduled_for, started_at, finished_at, batches, payouts, failed
+FROM payout_window_runs
+ORDER BY started_at DESC
+LIMIT $1
+`, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := []WindowRun{}
+	for rows.Next() {
+		var r WindowRun
+		if err := rows.Scan(&r.ID, &r.WindowID, &r.ScheduledFor, &r.StartedAt, &r.FinishedAt, &r.Batches, &r.Payouts, &r.Failed); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		if out[i], err = loadWindowDetail(ctx, pool, out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,73 @@
+package payouts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/failures"
+)
+
+func TestWindowOccurrences(t *testing.T) {
+	w := Window{Weekday: time.Friday, Hour: 15, Minute: 0}
+	cases := []struct {
+		now, last string
+	}{
+		{"2026-10-16T15:00:00Z", "2026-10-16T15:00:00Z"}, // Friday, exactly at the window
+		{"2026-10-16T14:59:00Z", "2026-10-09T15:00:00Z"}, // Friday, just before
+		{"2026-10-18T09:00:00Z", "2026-10-16T15:00:00Z"}, // Sunday after
+		{"2026-10-15T23:00:00Z", "2026-10-09T15:00:00Z"}, // Thursday before
+	}
+	for _, c := range cases {
+		now, _ := time.Parse(time.RFC3339, c.now)
+		want, _ := time.Parse(time.RFC3339, c.last)
+		if got := w.LastOccurrence(now); !got.Equal(want) {
+			t.Errorf("LastOccurrence(%s) = %s, want %s", c.now, got, want)
+		}
+		if got := w.NextOccurrence(now); !got.Equal(want.AddDate(0, 0, 7)) || !got.After(now) {
+			t.Errorf("NextOccurrence(%s) = %s", c.now, got)
+		}
+	}
+}
+
+func TestNextWindow(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2026-10-14T12:00:00Z") // Wednesday
+	if NextWindow(nil, now) != nil {
+		t.Fatal("no windows should mean no next window")
+	}
+	windows := []Window{
+		{Weekday: time.Friday, Hour: 15},
+		{Weekday: time.Wednesday, Hour: 18, Minute: 30},
+	}
+	want, _ := time.Parse(time.RFC3339, "2026-10-14T18:30:00Z")
+	if got := NextWindow(windows, now); got == nil || !got.Equal(want) {
+		t.Fatalf("NextWindow = %v, want %s", got, want)
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	w, err := ParseWindow("Fri", "15:00")
+	if err != nil || w.Weekday != time.Friday || w.Hour != 15 || w.Minute != 0 {
+		t.Fatalf("ParseWindow = %+v, %v", w, err)
+	}
+	if w, err := ParseWindow("sunday", "07:45"); err != nil || w.Weekday != time.Sunday || w.Minute != 45 {
+		t.Fatalf("ParseWindow(sunday) = %+v, %v", w, err)
+	}
+	for _, c := range [][2]string{{"fr", "15:00"}, {"funday", "15:00"}, {"friday", "25:00"}, {"friday", "3pm"}} {
+		if _, err := ParseWindow(c[0], c[1]); err == nil {
+			t.Errorf("ParseWindow(%q, %q) should fail", c[0], c[1])
+		}
+	}
+}
+
+func TestWindowRunText(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2026-10-16T15:00:00Z")
+	r := WindowRun{ScheduledFor: at, Batches: 2, Payouts: 7, Failed: 1, Totals: []WindowTotal{{Chain: "stellar", Asset: "native", Payouts: 6, Amount: "120.5"}},
+		Failures: []WindowFailure{{Failure: failures.New(failures.CodeInsufficientFunds, "op_underfunded"), Payouts: 1}}}
+	text := r.Text()
+	for _, want := range []string{"Fri 2026-10-16 15:00 UTC", "7 payouts in 2 batches", "1 failed", "120.5 native on stellar", "1 failed with insufficient_funds: Top up"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() = %q, missing %q", text, want)
+		}
+	}
+}
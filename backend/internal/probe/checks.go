@@ -0,0 +1,206 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// AuthCheck signs in over HTTP with a dedicated Stellar ed25519 probe wallet:
+// nonce, verify, /me with the issued token, then logout.
+type AuthCheck struct {
+	BaseURL string
+	Key     ed25519.PrivateKey
+	Client  *http.Client
+}
+
+// NewAuthCheck builds the check from a hex-encoded 32-byte ed25519 seed.
+func NewAuthCheck(baseURL, seedHex string) (*AuthCheck, error) {
+	seed, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(seedHex), "0x"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("probe wallet seed must be %d bytes of hex", ed25519.SeedSize)
+	}
+	return &AuthCheck{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Key:     ed25519.NewKeyFromSeed(seed),
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+func (a *AuthCheck) Name() string { return "auth_login" }
+
+func (a *AuthCheck) Run(ctx context.Context) error {
+	session, err := a.Login(ctx)
+	if err != nil {
+		return err
+	}
+	if err := a.call(ctx, http.MethodGet, "/me", session.Token, nil, nil); err != nil {
+		return fmt.Errorf("me: %w", err)
+	}
+	if session.RefreshToken != "" {
+		if err := a.call(ctx, http.MethodPost, "/auth/logout", "", map[string]string{"refresh_token": session.RefreshToken}, nil); err != nil {
+			return fmt.Errorf("logout: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoginSession is what a successful probe login returns.
+type LoginSession struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Login runs the nonce and verify steps and returns the issued session.
+func (a *AuthCheck) Login(ctx context.Context) (LoginSession, error) {
+	pub := hex.EncodeToString(a.Key.Public().(ed25519.PublicKey))
+
+	var nonce struct {
+		Nonce   string `json:"nonce"`
+		Message string `json:"message"`
+		PoW     *struct {
+			Difficulty int `json:"difficulty"`
+		} `json:"pow"`
+	}
+	if err := a.call(ctx, http.MethodPost, "/auth/nonce", "", map[string]string{
+		"wallet_type": string(auth.WalletTypeStellarEd25519),
+		"address":     pub,
+		"challenge":   "pow",
+	}, &nonce); err != nil {
+		return LoginSession{}, fmt.Errorf("nonce: %w", err)
+	}
+	solution := ""
+	if nonce.PoW != nil && nonce.PoW.Difficulty > 0 {
+		solution = auth.SolvePoW(nonce.Nonce, nonce.PoW.Difficulty)
+	}
+
+	var session LoginSession
+	if err := a.call(ctx, http.MethodPost, "/auth/verify", "", map[string]string{
+		"wallet_type":  string(auth.WalletTypeStellarEd25519),
+		"address":      pub,
+		"nonce":        nonce.Nonce,
+		"signature":    hex.EncodeToString(ed25519.Sign(a.Key, []byte(nonce.Message))),
+		"public_key":   pub,
+		"pow_solution": solution,
+	}, &session); err != nil {
+		return LoginSession{}, fmt.Errorf("verify: %w", err)
+	}
+	if session.Token == "" {
+		return LoginSession{}, errors.New("verify: no token issued")
+	}
+	return session, nil
+}
+
+func (a *AuthCheck) call(ctx context.Context, method, path, token string, body any, out any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.BaseURL+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+// MirrorCheck fails when the GitHub mirror has not seen an issue or pull
+// request for longer than MaxAge. An empty mirror passes.
+type MirrorCheck struct {
+	Pool   *pgxpool.Pool
+	MaxAge time.Duration
+}
+
+func (m *MirrorCheck) Name() string { return "github_mirror_freshness" }
+
+func (m *MirrorCheck) Run(ctx context.Context) error {
+	if m.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	var last *time.Time
+	if err := m.Pool.QueryRow(ctx, `
+SELECT GREATEST(
+  (SELECT max(last_seen_at) FROM github_issues),
+  (SELECT max(last_seen_at) FROM github_pull_requests)
+)
+`).Scan(&last); err != nil {
+		return err
+	}
+	if last == nil {
+		return nil
+	}
+	if age := time.Since(*last); age > m.MaxAge {
+		return fmt.Errorf("mirror last updated %s ago", age.Round(time.Minute))
+	}
+	return nil
+}
+
+// PayoutCheck dry-runs payouts: every configured hot wallet must answer
+// decimals and balance queries for its native asset. Nothing is sent.
+type PayoutCheck struct {
+	Wallets wallet.Registry
+}
+
+func (p *PayoutCheck) Name() string { return "payout_dry_run" }
+
+func (p *PayoutCheck) Run(ctx context.Context) error {
+	var errs []error
+	for _, chain := range p.Wallets.Chains() {
+		sender, _ := p.Wallets.Get(chain)
+		if _, err := sender.Decimals(ctx, "native"); err != nil {
+			errs = append(errs, fmt.Errorf("%s decimals: %w", chain, err))
+			continue
+		}
+		if _, err := sender.Balance(ctx, "native"); err != nil {
+			errs = append(errs, fmt.Errorf("%s balance: %w", chain, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SelfURL turns a listen address such as ":8080" into a loopback base URL.
+func SelfURL(listenAddr string) string {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(listenAddr))
+	if err != nil {
+		return "http://" + listenAddr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
@@ -0,0 +1,152 @@
+// Package probe runs synthetic checks of the critical paths against this
+// process and keeps pass/fail metrics for scraping.
+package probe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Check is one synthetic probe. Run returns nil on success.
+type Check interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// Result is the latest outcome of a check plus its run counters.
+type Result struct {
+	Name      string        `json:"name"`
+	OK        bool          `json:"ok"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration_ns"`
+	LastRun   time.Time     `json:"last_run"`
+	Successes int64         `json:"successes"`
+	Failures  int64         `json:"failures"`
+}
+
+// Prober runs its checks on an interval and records the results.
+type Prober struct {
+	Checks  []Check
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	results map[string]*Result
+}
+
+// RunOnce runs every check and returns how many failed.
+func (p *Prober) RunOnce(ctx context.Context) (int, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	failed := 0
+	for _, c := range p.Checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		err := c.Run(checkCtx)
+		cancel()
+		if err != nil {
+			failed++
+			slog.Warn("probe failed", "probe", c.Name(), "error", err)
+		}
+		p.record(c.Name(), start, time.Since(start), err)
+	}
+	return failed, nil
+}
+
+func (p *Prober) record(name string, start time.Time, d time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.results == nil {
+		p.results = map[string]*Result{}
+	}
+	r, ok := p.results[name]
+	if !ok {
+		r = &Result{Name: name}
+		p.results[name] = r
+	}
+	r.OK = err == nil
+	r.Error = ""
+	if err != nil {
+		r.Error = err.Error()
+		r.Failures++
+	} else {
+		r.Successes++
+	}
+	r.Duration = d
+	r.LastRun = start
+}
+
+// Results returns a snapshot of the latest results, sorted by name.
+func (p *Prober) Results() []Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]Result, 0, len(p.results))
+	for _, r := range p.results {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// WriteMetrics writes the results in the Prometheus text exposition format.
+func (p *Prober) WriteMetrics(w io.Writer) error {
+	results := p.Results()
+	metrics := []struct {
+		name, help, kind string
+		value            func(Result) string
+	}{
+		{"grainlify_probe_success", "Whether the last run of the probe passed (1) or failed (0).", "gauge", func(r Result) string {
+			if r.OK {
+				return "1"
+			}
+			return "0"
+		}},
+		{"grainlify_probe_duration_seconds", "Duration of the last run of the probe.", "gauge", func(r Result) string {
+			return fmt.Sprintf("%g", r.Duration.Seconds())
+		}},
+		{"grainlify_probe_last_run_timestamp_seconds", "Unix time the probe last ran.", "gauge", func(r Result) string {
+			return fmt.Sprintf("%d", r.LastRun.Unix())
+		}},
+	}
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind); err != nil {
+			return err
+		}
+		for _, r := range results {
+			if _, err := fmt.Fprintf(w, "%s{probe=%q} %s\n", m.name, r.Name, m.value(r)); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := fmt.Fprintf(w, "# HELP grainlify_probe_runs_total Probe runs by result.\n# TYPE grainlify_probe_runs_total counter\n"); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if _, err := fmt.Fprintf(w, "grainlify_probe_runs_total{probe=%q,result=\"success\"} %d\ngrainlify_probe_runs_total{probe=%q,result=\"failure\"} %d\n", r.Name, r.Successes, r.Name, r.Failures); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run probes every interval until ctx is done.
+func (p *Prober) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			if _, err := p.RunOnce(ctx); err != nil {
+				slog.Error("probe run failed", "error", err)
+			}
+		}
+	}
+}
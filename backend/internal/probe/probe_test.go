@@ -0,0 +1,55 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeCheck struct {
+	name string
+	err  error
+}
+
+func (f fakeCheck) Name() string                  { return f.name }
+func (f fakeCheck) Run(ctx context.Context) error { return f.err }
+
+func TestProberMetrics(t *testing.T) {
+	p := &Prober{Checks: []Check{fakeCheck{name: "ok"}, fakeCheck{name: "broken", err: errors.New("boom")}}}
+	failed, err := p.RunOnce(context.Background())
+	if err != nil || failed != 1 {
+		t.Fatalf("RunOnce = %d, %v", failed, err)
+	}
+	_, _ = p.RunOnce(context.Background())
+
+	var b strings.Builder
+	if err := p.WriteMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`grainlify_probe_success{probe="ok"} 1`,
+		`grainlify_probe_success{probe="broken"} 0`,
+		`grainlify_probe_runs_total{probe="broken",result="failure"} 2`,
+		`grainlify_probe_runs_total{probe="ok",result="success"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSelfURL(t *testing.T) {
+	cases := map[string]string{
+		":8080":         "http://127.0.0.1:8080",
+		"0.0.0.0:9000":  "http://127.0.0.1:9000",
+		"10.0.0.5:8080": "http://10.0.0.5:8080",
+		"[::]:8080":     "http://127.0.0.1:8080",
+	}
+	for in, want := range cases {
+		if got := SelfURL(in); got != want {
+			t.Errorf("SelfURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package profilesync copies linked users' GitHub profile, owned repos and
+// contribution stats into local tables through the job queue, so request
+// handlers read them from the database instead of calling GitHub.
+package profilesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/jobs"
+)
+
+// Kind is the job_queue kind of a profile sync.
+const Kind = "github_profile_sync"
+
+// maxRepoPages caps the repos kept per user (100 per page).
+const maxRepoPages = 5
+
+type payload struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// Enqueue schedules a sync of userID's GitHub data.
+func Enqueue(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) error {
+	_, err := jobs.Enqueue(ctx, pool, Kind, userID.String(), payload{UserID: userID})
+	return err
+}
+
+// EnqueueStale schedules a sync for every linked user whose data is missing
+// or older than maxAge, returning how many were queued.
+func EnqueueStale(ctx context.Context, pool *pgxpool.Pool, maxAge time.Duration) (int, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT ga.user_id
+FROM github_accounts ga
+LEFT JOIN github_profiles gp ON gp.user_id = ga.user_id
+WHERE gp.synced_at IS NULL OR gp.synced_at < now() - $1 * interval '1 second'
+`, int64(maxAge.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, id := range ids {
+		added, err := jobs.Enqueue(ctx, pool, Kind, id.String(), payload{UserID: id})
+		if err != nil {
+			return n, err
+		}
+		if added {
+			n++
+		}
+	}
+	return n, nil
+}
+
+type Syncer struct {
+	Pool           *pgxpool.Pool
+	GitHub         *github.Client
+	TokenEncKeyB64 string
+}
+
+// Handle is the job_queue handler for Kind.
+func (s *Syncer) Handle(ctx context.Context, raw json.RawMessage) error {
+	var p payload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return err
+	}
+	err := s.SyncUser(ctx, p.UserID)
+	if errors.Is(err, ErrNotLinked) {
+		// Unlinked since it was queued; nothing to retry.
+		return nil
+	}
+	return err
+}
+
+// ErrNotLinked is returned by SyncUser when the user has no GitHub account.
+var ErrNotLinked = errors.New("github_not_linked")
+
+// SyncUser fetches the user's GitHub profile, owned repos and contribution
+// stats and replaces the local copies.
+func (s *Syncer) SyncUser(ctx context.Context, userID uuid.UUID) error {
+	if s.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	linked, err := github.GetLinkedAccount(ctx, s.Pool, userID, s.TokenEncKeyB64)
+	if err != nil {
+		if err.Error() == "github_not_linked" {
+			return ErrNotLinked
+		}
+		return err
+	}
+	gh := s.GitHub
+	if gh == nil {
+		gh = github.NewClient()
+	}
+
+	u, err := gh.GetUser(ctx, linked.AccessToken)
+	if err != nil {
+		return err
+	}
+	// The /user email is only the public one; the emails endpoint needs
+	// user:email, which older links may lack.
+	email := u.Email
+	if primary, err := gh.GetPrimaryEmail(ctx, linked.AccessToken); err == nil && primary != "" {
+		email = primary
+	}
+	var repos []github.UserRepo
+	for page := 1; page <= maxRepoPages; page++ {
+		batch, err := gh.ListUserReposPage(ctx, linked.AccessToken, page)
+		if err != nil {
+			return err
+		}
+		repos = append(repos, batch...)
+		if len(batch) < 100 {
+			break
+		}
+	}
+	stats, err := gh.GetContributionStats(ctx, linked.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO github_profiles (user_id, github_user_id, login, name, email, avatar_url, location, bio, blog, synced_at)
+VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), now())
+ON CONFLICT (user_id) DO UPDATE SET
+  github_user_id = EXCLUDED.github_user_id,
+  login = EXCLUDED.login,
+  name = EXCLUDED.name,
+  email = EXCLUDED.email,
+  avatar_url = EXCLUDED.avatar_url,
+  location = EXCLUDED.location,
+  bio = EXCLUDED.bio,
+  blog = EXCLUDED.blog,
+  synced_at = now()
+`, userID, u.ID, u.Login, u.Name, email, u.AvatarURL, u.Location, u.Bio, u.Blog); err != nil {
+		return err
+	}
+	// Keep the linked account's cached login/avatar in step with GitHub.
+	if _, err := tx.Exec(ctx, `
+UPDATE github_accounts SET login = $2, avatar_url = $3, updated_at = now()
+WHERE user_id = $1 AND (login <> $2 OR avatar_url IS DISTINCT FROM $3)
+`, userID, u.Login, u.AvatarURL); err != nil {
+		return err
+	}
+
+	seen := make([]int64, 0, len(repos))
+	for _, r := range repos {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO github_user_repos (user_id, github_repo_id, full_name, html_url, description, language, private, fork, stars, forks, pushed_at, synced_at)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10, $11, now())
+ON CONFLICT (user_id, github_repo_id) DO UPDATE SET
+  full_name = EXCLUDED.full_name,
+  html_url = EXCLUDED.html_url,
+  description = EXCLUDED.description,
+  language = EXCLUDED.language,
+  private = EXCLUDED.private,
+  fork = EXCLUDED.fork,
+  stars = EXCLUDED.stars,
+  forks = EXCLUDED.forks,
+  pushed_at = EXCLUDED.pushed_at,
+  synced_at = now()
+`, userID, r.ID, r.FullName, r.HTMLURL, r.Description, r.Language, r.Private, r.Fork, r.StargazersCount, r.ForksCount, r.PushedAt); err != nil {
+			return err
+		}
+		seen = append(seen, r.ID)
+	}
+	if _, err := tx.Exec(ctx, `
+DELETE FROM github_user_repos WHERE user_id = $1 AND NOT (github_repo_id = ANY($2))
+`, userID, seen); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO github_contribution_stats (user_id, period_start, period_end, total, commits, pull_requests, pull_request_reviews, issues, restricted, synced_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, now())
+ON CONFLICT (user_id) DO UPDATE SET
+  period_start = EXCLUDED.period_start,
+  period_end = EXCLUDED.period_end,
+  total = EXCLUDED.total,
+  commits = EXCLUDED.commits,
+  pull_requests = EXCLUDED.pull_requests,
+  pull_request_reviews = EXCLUDED.pull_request_reviews,
+  issues = EXCLUDED.issues,
+  restricted = EXCLUDED.restricted,
+  synced_at = now()
+`, userID, stats.From, stats.To, stats.Total, stats.Commits, stats.PullRequests, stats.PullRequestReviews, stats.Issues, stats.Restricted); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,306 @@
+package proofs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+	"github.com/jagadeesh/grainlify/backend/internal/safehttp"
+	"github.com/jagadeesh/grainlify/backend/internal/webhooks"
+)
+
+const (
+	claimLease      = 5 * time.Minute
+	maxResponseBody = 1024
+	defaultBatch    = 50
+	concurrency     = 8
+)
+
+// Dispatcher issues a proof for each payout sent for a project with an
+// active accounting endpoint, and POSTs due proofs signed with the
+// endpoint's secret. Retries follow webhooks.Backoff up to
+// webhooks.MaxAttempts.
+type Dispatcher struct {
+	Pool           *pgxpool.Pool
+	TokenEncKeyB64 string
+	// HTTP defaults to safehttp.NewClient, which only connects to
+	// public addresses.
+	HTTP *http.Client
+	// Batch caps proofs issued, and sent, per run; it defaults to 50.
+	Batch int
+}
+
+// RunOnce issues new proofs and sends due ones, returning how many were
+// accepted by their endpoints.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	if d.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	if err := d.issue(ctx); err != nil {
+		return 0, err
+	}
+	return d.deliverDue(ctx)
+}
+
+func (d *Dispatcher) batch() int {
+	if d.Batch > 0 {
+		return d.Batch
+	}
+	return defaultBatch
+}
+
+type paidPayout struct {
+	projectID   uuid.UUID
+	projectName string
+	doc         Document
+}
+
+// issue numbers each newly sent payout as the project's next invoice and
+// queues its proof. A payout pays for a project when its repo is the
+// project's, or it releases one of the project's escrow bounties.
+func (d *Dispatcher) issue(ctx context.Context) error {
+	rows, err := d.Pool.Query(ctx, `
+SELECT pr.id, pr.github_full_name, p.id, p.user_id, ga.login, p.to_address, p.amount::text, p.asset, p.chain,
+       p.tx_hash, p.updated_at, COALESCE(p.repo_full_name, pr.github_full_name), p.pr_number, p.pr_url
+FROM payouts p
+LEFT JOIN bounties b ON b.id = p.escrow_bounty_id
+JOIN projects pr ON pr.id = b.project_id
+  OR (p.escrow_bounty_id IS NULL AND lower(pr.github_full_name) = lower(p.repo_full_name))
+JOIN accounting_endpoints ae ON ae.project_id = pr.id AND ae.active
+LEFT JOIN github_accounts ga ON ga.user_id = p.user_id
+WHERE p.status IN ('submitted', 'confirmed') AND p.tx_hash IS NOT NULL
+  AND p.updated_at >= ae.created_at
+  AND NOT EXISTS (SELECT 1 FROM payment_proofs pp WHERE pp.payout_id = p.id)
+ORDER BY p.updated_at
+LIMIT $1
+`, d.batch())
+	if err != nil {
+		return err
+	}
+	var paid []paidPayout
+	for rows.Next() {
+		var pp paidPayout
+		doc := &pp.doc
+		if err := rows.Scan(&pp.projectID, &pp.projectName, &doc.PayoutID, &doc.Counterparty.UserID, &doc.Counterparty.GitHubLogin,
+			&doc.Counterparty.Address, &doc.Amount, &doc.Asset, &doc.Chain, &doc.TxHash, &doc.PaidAt,
+			&doc.Work.Repo, &doc.Work.PRNumber, &doc.Work.PRURL); err != nil {
+			rows.Close()
+			return err
+		}
+		paid = append(paid, pp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, pp := range paid {
+		if err := d.issueOne(ctx, pp); err != nil {
+			slog.Warn("payment proof issue failed", "payout_id", pp.doc.PayoutID.String(), "project_id", pp.projectID.String(), "error", err)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) issueOne(ctx context.Context, pp paidPayout) error {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var prefix string
+	var n int64
+	err = tx.QueryRow(ctx, `
+UPDATE accounting_endpoints SET next_invoice = next_invoice + 1
+WHERE project_id = $1 AND active
+RETURNING invoice_prefix, next_invoice - 1
+`, pp.projectID).Scan(&prefix, &n)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Paused or removed since the query above.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	doc := pp.doc
+	doc.Type = EventType
+	doc.InvoiceNumber = InvoiceNumber(prefix, n)
+	doc.IssuedAt = time.Now().UTC()
+	doc.Payer = Party{ProjectID: pp.projectID, Name: pp.projectName}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `
+INSERT INTO payment_proofs (project_id, payout_id, invoice_number, payload)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (payout_id) DO NOTHING
+`, pp.projectID, doc.PayoutID, doc.InvoiceNumber, body)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		// Another instance issued it; don't use up the invoice number.
+		return nil
+	}
+	return tx.Commit(ctx)
+}
+
+type dueProof struct {
+	id       uuid.UUID
+	payoutID uuid.UUID
+	payload  []byte
+	attempts int
+	url      string
+	secret   []byte
+}
+
+// deliverDue claims due proofs and sends them, a few at a time.
+func (d *Dispatcher) deliverDue(ctx context.Context) (int, error) {
+	rows, err := d.Pool.Query(ctx, `
+UPDATE payment_proofs pp
+SET next_attempt_at = now() + make_interval(secs => $2)
+FROM accounting_endpoints ae
+WHERE ae.project_id = pp.project_id AND pp.id IN (
+  SELECT p2.id
+  FROM payment_proofs p2
+  JOIN accounting_endpoints a2 ON a2.project_id = p2.project_id
+  WHERE p2.status = 'pending' AND p2.next_attempt_at <= now() AND a2.active
+  ORDER BY p2.next_attempt_at
+  LIMIT $1
+  FOR UPDATE OF p2 SKIP LOCKED
+)
+RETURNING pp.id, pp.payout_id, pp.payload, pp.attempts, ae.url, ae.secret
+`, d.batch(), claimLease.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	var due []dueProof
+	for rows.Next() {
+		var dp dueProof
+		if err := rows.Scan(&dp.id, &dp.payoutID, &dp.payload, &dp.attempts, &dp.url, &dp.secret); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, dp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	client := d.HTTP
+	if client == nil {
+		client = safehttp.NewClient()
+	}
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+		sem       = make(chan struct{}, concurrency)
+	)
+	for _, dp := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(dp dueProof) {
+			defer func() { <-sem; wg.Done() }()
+			ok, err := d.attempt(ctx, client, dp)
+			if err != nil {
+				slog.Error("failed to record payment proof attempt", "proof_id", dp.id.String(), "error", err)
+			}
+			if ok {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}(dp)
+	}
+	wg.Wait()
+	return delivered, nil
+}
+
+// send POSTs one proof, returning the response status and the start of its
+// body when the endpoint answered.
+func (d *Dispatcher) send(ctx context.Context, client *http.Client, dp dueProof) (*int, *string, error) {
+	key, err := cryptox.KeyFromB64(d.TokenEncKeyB64)
+	if err != nil {
+		return nil, nil, err
+	}
+	secret, err := cryptox.DecryptAESGCM(key, dp.secret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt accounting endpoint secret failed")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dp.url, bytes.NewReader(dp.payload))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Grainlify-Proofs/1")
+	req.Header.Set(webhooks.HeaderEvent, EventType)
+	req.Header.Set(webhooks.HeaderDelivery, dp.id.String())
+	req.Header.Set(webhooks.HeaderSignature, webhooks.Sign(string(secret), time.Now(), dp.payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	code, body := resp.StatusCode, strings.ToValidUTF8(string(b), "")
+	if code < 200 || code > 299 {
+		return &code, &body, fmt.Errorf("endpoint responded %d", code)
+	}
+	return &code, &body, nil
+}
+
+// attempt sends one proof and records the outcome, scheduling a retry or
+// failing the proof after webhooks.MaxAttempts.
+func (d *Dispatcher) attempt(ctx context.Context, client *http.Client, dp dueProof) (bool, error) {
+	start := time.Now()
+	code, body, sendErr := d.send(ctx, client, dp)
+	duration := int(time.Since(start).Milliseconds())
+
+	attempts := dp.attempts + 1
+	status, next, errMsg := webhooks.StatusSucceeded, time.Duration(0), ""
+	if sendErr != nil {
+		status, next, errMsg = webhooks.StatusPending, webhooks.Backoff(attempts), sendErr.Error()
+		if attempts >= webhooks.MaxAttempts {
+			status = webhooks.StatusFailed
+		}
+		slog.Info("payment proof delivery failed",
+			"proof_id", dp.id.String(),
+			"payout_id", dp.payoutID.String(),
+			"attempts", attempts,
+			"status", status,
+			"error", errMsg,
+		)
+	}
+	_, err := d.Pool.Exec(ctx, `
+UPDATE payment_proofs
+SET status = $2,
+    attempts = $3,
+    next_attempt_at = now() + make_interval(secs => $4),
+    last_attempt_at = now(),
+    response_status = $5,
+    response_body = $6,
+    error = NULLIF($7, ''),
+    duration_ms = $8,
+    delivered_at = CASE WHEN $2 = 'succeeded' THEN now() END
+WHERE id = $1
+`, dp.id, status, attempts, next.Seconds(), code, body, errMsg, duration)
+	return sendErr == nil, err
+}
@@ -0,0 +1,291 @@
+// Package proofs sends funding projects' accounting systems a signed proof
+// of every payout made for work on the project: the amount, the transaction
+// it was sent in, an invoice number and who was paid. Deliveries are
+// signed and retried like outbound webhooks.
+package proofs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+	"github.com/jagadeesh/grainlify/backend/internal/webhooks"
+)
+
+// EventType is sent in the event header of every proof delivery.
+const EventType = "payment.proof"
+
+const (
+	minSecretLength = 16
+	maxSecretLength = 256
+	// DefaultInvoicePrefix starts invoice numbers when the project sets none.
+	DefaultInvoicePrefix = "GRL"
+)
+
+var (
+	ErrInvalidPrefix     = errors.New("invalid_invoice_prefix")
+	ErrEndpointNotFound  = errors.New("accounting_endpoint_not_found")
+	ErrProofNotFound     = errors.New("payment_proof_not_found")
+	ErrDeliveryNotClosed = errors.New("payment_proof_pending")
+)
+
+var prefixPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,15}$`)
+
+// Endpoint is a project's accounting endpoint. The secret is never
+// serialized after it is set.
+type Endpoint struct {
+	ProjectID     uuid.UUID `json:"project_id"`
+	URL           string    `json:"url"`
+	InvoicePrefix string    `json:"invoice_prefix"`
+	// NextInvoice is the number the next proof is invoiced under.
+	NextInvoice int64     `json:"next_invoice"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+const endpointColumns = `project_id, url, invoice_prefix, next_invoice, active, created_at, updated_at`
+
+func scanEndpoint(row pgx.Row) (Endpoint, error) {
+	var e Endpoint
+	err := row.Scan(&e.ProjectID, &e.URL, &e.InvoicePrefix, &e.NextInvoice, &e.Active, &e.CreatedAt, &e.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Endpoint{}, ErrEndpointNotFound
+	}
+	return e, err
+}
+
+// EndpointConfig is the input to PutEndpoint. An empty Secret keeps the
+// current one, or generates one for a new endpoint; an empty InvoicePrefix
+// keeps the current one, or uses DefaultInvoicePrefix.
+type EndpointConfig struct {
+	URL           string
+	Secret        string
+	InvoicePrefix string
+	Active        *bool
+}
+
+// InvoiceNumber formats the n-th invoice under prefix.
+func InvoiceNumber(prefix string, n int64) string {
+	return fmt.Sprintf("%s-%06d", prefix, n)
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhooks.SecretPrefix + hex.EncodeToString(b), nil
+}
+
+// GetEndpoint returns projectID's accounting endpoint.
+func GetEndpoint(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) (Endpoint, error) {
+	if pool == nil {
+		return Endpoint{}, fmt.Errorf("db not configured")
+	}
+	return scanEndpoint(pool.QueryRow(ctx, `SELECT `+endpointColumns+` FROM accounting_endpoints WHERE project_id = $1`, projectID))
+}
+
+// PutEndpoint sets projectID's accounting endpoint, returning it with the
+// plaintext secret when one was generated (shown exactly once). Only payouts
+// sent after the endpoint is first set get proofs.
+func PutEndpoint(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, in EndpointConfig, tokenEncKeyB64 string) (Endpoint, string, error) {
+	if pool == nil {
+		return Endpoint{}, "", fmt.Errorf("db not configured")
+	}
+	u, err := webhooks.NormalizeURL(in.URL)
+	if err != nil {
+		return Endpoint{}, "", err
+	}
+	prefix := strings.TrimSpace(in.InvoicePrefix)
+	if prefix != "" && !prefixPattern.MatchString(prefix) {
+		return Endpoint{}, "", ErrInvalidPrefix
+	}
+
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM accounting_endpoints WHERE project_id = $1)`, projectID).Scan(&exists); err != nil {
+		return Endpoint{}, "", err
+	}
+	secret, generated := strings.TrimSpace(in.Secret), ""
+	switch {
+	case secret == "" && !exists:
+		if secret, err = generateSecret(); err != nil {
+			return Endpoint{}, "", err
+		}
+		generated = secret
+	case secret != "" && (len(secret) < minSecretLength || len(secret) > maxSecretLength):
+		return Endpoint{}, "", webhooks.ErrInvalidSecret
+	}
+	var enc []byte
+	if secret != "" {
+		key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+		if err != nil {
+			return Endpoint{}, "", err
+		}
+		if enc, err = cryptox.EncryptAESGCM(key, []byte(secret)); err != nil {
+			return Endpoint{}, "", err
+		}
+	}
+
+	e, err := scanEndpoint(pool.QueryRow(ctx, `
+INSERT INTO accounting_endpoints (project_id, url, secret, invoice_prefix, active)
+VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), $6), COALESCE($5, TRUE))
+ON CONFLICT (project_id) DO UPDATE
+SET url = EXCLUDED.url,
+    secret = COALESCE($3, accounting_endpoints.secret),
+    invoice_prefix = COALESCE(NULLIF($4, ''), accounting_endpoints.invoice_prefix),
+    active = COALESCE($5, accounting_endpoints.active),
+    updated_at = now()
+RETURNING `+endpointColumns, projectID, u, enc, prefix, in.Active, DefaultInvoicePrefix))
+	if err != nil {
+		return Endpoint{}, "", err
+	}
+	return e, generated, nil
+}
+
+// DeleteEndpoint stops proofs for projectID. Its delivery log is kept.
+func DeleteEndpoint(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM accounting_endpoints WHERE project_id = $1`, projectID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrEndpointNotFound
+	}
+	return nil
+}
+
+// Party is the payer of a proof: the funding project.
+type Party struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+}
+
+// Counterparty is who was paid.
+type Counterparty struct {
+	UserID      uuid.UUID `json:"user_id"`
+	GitHubLogin *string   `json:"github_login"`
+	Address     string    `json:"address"`
+}
+
+// Work is what the payout paid for.
+type Work struct {
+	Repo     string  `json:"repo_full_name"`
+	PRNumber *int    `json:"pr_number,omitempty"`
+	PRURL    *string `json:"pr_url,omitempty"`
+}
+
+// Document is the proof of payment POSTed to the accounting endpoint.
+type Document struct {
+	Type          string       `json:"type"`
+	InvoiceNumber string       `json:"invoice_number"`
+	PayoutID      uuid.UUID    `json:"payout_id"`
+	Amount        string       `json:"amount"`
+	Asset         string       `json:"asset"`
+	Chain         string       `json:"chain"`
+	TxHash        string       `json:"tx_hash"`
+	PaidAt        time.Time    `json:"paid_at"`
+	IssuedAt      time.Time    `json:"issued_at"`
+	Payer         Party        `json:"payer"`
+	Counterparty  Counterparty `json:"counterparty"`
+	Work          Work         `json:"work"`
+}
+
+// Proof is one document sent (or being sent) to a project's endpoint, with
+// the outcome of its last attempt.
+type Proof struct {
+	ID             uuid.UUID       `json:"id"`
+	ProjectID      uuid.UUID       `json:"project_id"`
+	PayoutID       uuid.UUID       `json:"payout_id"`
+	InvoiceNumber  string          `json:"invoice_number"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   *string         `json:"response_body,omitempty"`
+	Error          *string         `json:"error,omitempty"`
+	DurationMS     *int            `json:"duration_ms,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+const proofColumns = `id, project_id, payout_id, invoice_number, payload, status, attempts,
+  CASE WHEN status = 'pending' THEN next_attempt_at END, last_attempt_at, response_status, response_body,
+  error, duration_ms, delivered_at, created_at`
+
+func scanProof(row pgx.Row) (Proof, error) {
+	var p Proof
+	err := row.Scan(&p.ID, &p.ProjectID, &p.PayoutID, &p.InvoiceNumber, &p.Payload, &p.Status, &p.Attempts,
+		&p.NextAttemptAt, &p.LastAttemptAt, &p.ResponseStatus, &p.ResponseBody, &p.Error, &p.DurationMS, &p.DeliveredAt, &p.CreatedAt)
+	return p, err
+}
+
+// ListProofs returns projectID's proofs, newest first, optionally only those
+// with status.
+func ListProofs(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, status string, limit, offset int) ([]Proof, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+proofColumns+`
+FROM payment_proofs
+WHERE project_id = $1 AND ($2 = '' OR status = $2)
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4
+`, projectID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Proof{}
+	for rows.Next() {
+		p, err := scanProof(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// Redeliver queues a finished proof to be sent again right away, with a
+// fresh set of attempts. The invoice number doesn't change.
+func Redeliver(ctx context.Context, pool *pgxpool.Pool, projectID, id uuid.UUID) (Proof, error) {
+	if pool == nil {
+		return Proof{}, fmt.Errorf("db not configured")
+	}
+	p, err := scanProof(pool.QueryRow(ctx, `
+UPDATE payment_proofs
+SET status = 'pending', attempts = 0, next_attempt_at = now()
+WHERE id = $1 AND project_id = $2 AND status <> 'pending'
+RETURNING `+proofColumns, id, projectID))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return p, err
+	}
+	var pending bool
+	err = pool.QueryRow(ctx, `SELECT status = 'pending' FROM payment_proofs WHERE id = $1 AND project_id = $2`, id, projectID).Scan(&pending)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return Proof{}, ErrProofNotFound
+	case err != nil:
+		return Proof{}, err
+	default:
+		return Proof{}, ErrDeliveryNotClosed
+	}
+}
@@ -0,0 +1,62 @@
+package proofs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+	"github.com/jagadeesh/grainlify/backend/internal/webhooks"
+)
+
+func TestInvoiceNumber(t *testing.T) {
+	if got := InvoiceNumber("ACME", 42); got != "ACME-000042" {
+		t.Errorf("InvoiceNumber = %q", got)
+	}
+	for prefix, ok := range map[string]bool{"ACME": true, "grl-2026": true, "": false, "-X": false, "has space": false, "ABCDEFGHIJKLMNOPQ": false} {
+		if prefixPattern.MatchString(prefix) != ok {
+			t.Errorf("prefix %q valid = %v, want %v", prefix, !ok, ok)
+		}
+	}
+}
+
+func TestSendSignsProof(t *testing.T) {
+	raw := make([]byte, 32)
+	_, _ = rand.Read(raw)
+	keyB64 := base64.StdEncoding.EncodeToString(raw)
+	key, err := cryptox.KeyFromB64(keyB64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const secret = "whsec_0123456789abcdef"
+	enc, err := cryptox.EncryptAESGCM(key, []byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte(`{"type":"payment.proof","invoice_number":"ACME-000001"}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhooks.HeaderEvent) != EventType {
+			t.Errorf("event header = %q", r.Header.Get(webhooks.HeaderEvent))
+		}
+		if err := webhooks.Verify(secret, r.Header.Get(webhooks.HeaderSignature), body, time.Now(), webhooks.SignatureTolerance); err != nil {
+			t.Errorf("signature: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	d := &Dispatcher{TokenEncKeyB64: keyB64}
+	code, _, err := d.send(context.Background(), srv.Client(), dueProof{id: uuid.New(), payload: payload, url: srv.URL, secret: enc})
+	if err != nil || code == nil || *code != http.StatusAccepted {
+		t.Fatalf("send = %v, %v", code, err)
+	}
+}
@@ -0,0 +1,225 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// DefaultTier is the plan tier of accounts without one, and the tier used for
+// tiers that aren't configured.
+const DefaultTier = "free"
+
+// Tier is a token bucket: it refills at PerMinute tokens a minute and holds
+// up to Burst, so a client that stays under its rate banks credits for a
+// spike (a CI run fanning out) while sustained traffic is held to PerMinute.
+type Tier struct {
+	Name      string
+	PerMinute int
+	Burst     int
+}
+
+func (t Tier) perSecond() float64 { return float64(t.PerMinute) / 60 }
+
+// Tiers maps plan tier names to their buckets.
+type Tiers map[string]Tier
+
+// ParseTiers reads "name=per_minute/burst" pairs separated by commas, e.g.
+// "free=60/300,pro=600/6000". An empty spec configures no tiers; otherwise
+// DefaultTier must be among them.
+func ParseTiers(spec string) (Tiers, error) {
+	out := Tiers{}
+	if strings.TrimSpace(spec) == "" {
+		return out, nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		name, rate, ok := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		perMinute, burst, ok2 := strings.Cut(rate, "/")
+		if !ok || !ok2 || name == "" {
+			return nil, fmt.Errorf("invalid rate limit tier %q: want name=per_minute/burst", part)
+		}
+		pm, err1 := strconv.Atoi(strings.TrimSpace(perMinute))
+		b, err2 := strconv.Atoi(strings.TrimSpace(burst))
+		if err1 != nil || err2 != nil || pm <= 0 || b < 1 {
+			return nil, fmt.Errorf("invalid rate limit tier %q: want name=per_minute/burst", part)
+		}
+		if _, dup := out[name]; dup {
+			return nil, fmt.Errorf("rate limit tier %q listed twice", name)
+		}
+		out[name] = Tier{Name: name, PerMinute: pm, Burst: b}
+	}
+	if _, ok := out[DefaultTier]; !ok {
+		return nil, fmt.Errorf("rate limit tiers must include %q", DefaultTier)
+	}
+	return out, nil
+}
+
+// For returns the named tier, falling back to DefaultTier.
+func (t Tiers) For(name string) (Tier, bool) {
+	if tier, ok := t[strings.ToLower(name)]; ok {
+		return tier, true
+	}
+	tier, ok := t[DefaultTier]
+	return tier, ok
+}
+
+// BucketStore takes tokens from token buckets.
+type BucketStore interface {
+	// Take spends one token from key's bucket, refilling it first, and
+	// returns whether there was one, the whole tokens left and, when there
+	// wasn't, how long until there will be.
+	Take(ctx context.Context, key string, tier Tier) (bool, int64, time.Duration, error)
+}
+
+// BucketRule limits requests sharing a key with the token bucket of the
+// key's tier.
+type BucketRule struct {
+	Name  string
+	Tiers Tiers
+	// Key picks the bucket; an empty key skips the rule for that request.
+	Key func(c *fiber.Ctx) string
+	// Tier names the request's plan tier.
+	Tier func(c *fiber.Ctx) string
+}
+
+// Buckets enforces r, answering requests that find the bucket empty with 429
+// and a Retry-After header. Every admitted request reports the tokens left in
+// X-RateLimit-Remaining.
+func (l *Limiter) Buckets(r BucketRule) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if l == nil || len(r.Tiers) == 0 || r.Key == nil {
+			return c.Next()
+		}
+		key := r.Key(c)
+		if key == "" {
+			return c.Next()
+		}
+		name := DefaultTier
+		if r.Tier != nil {
+			name = r.Tier(c)
+		}
+		tier, ok := r.Tiers.For(name)
+		if !ok {
+			return c.Next()
+		}
+		ok, remaining, wait := l.take(c.Context(), r.Name+":"+key, tier)
+		c.Set("X-RateLimit-Limit", strconv.Itoa(tier.Burst))
+		c.Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		if !ok {
+			l.mu.Lock()
+			l.rejected[r.Name]++
+			l.mu.Unlock()
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds(wait)))
+			return httpx.Write(c, httpx.New(fiber.StatusTooManyRequests, "rate_limited").With("tier", tier.Name))
+		}
+		return c.Next()
+	}
+}
+
+func (l *Limiter) take(ctx context.Context, key string, tier Tier) (bool, int64, time.Duration) {
+	if s, ok := l.store.(BucketStore); ok {
+		ok, remaining, wait, err := s.Take(ctx, key, tier)
+		if err == nil {
+			return ok, remaining, wait
+		}
+		l.storeFailed(err)
+	}
+	ok, remaining, wait, _ := l.fallback.Take(ctx, key, tier)
+	return ok, remaining, wait
+}
+
+type tokenBucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// Take implements BucketStore in process.
+func (m *MemoryStore) Take(_ context.Context, key string, tier Tier) (bool, int64, time.Duration, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b := m.tokens[key]
+	if b == nil {
+		b = &tokenBucket{tokens: float64(tier.Burst), at: now}
+		m.tokens[key] = b
+	}
+	ok, wait := b.take(now, tier)
+
+	// Buckets refilled to the brim carry no state worth keeping.
+	m.takes++
+	if m.takes%1024 == 0 {
+		for k, v := range m.tokens {
+			if v.full(now, tier) {
+				delete(m.tokens, k)
+			}
+		}
+	}
+	return ok, int64(b.tokens), wait, nil
+}
+
+func (b *tokenBucket) take(now time.Time, tier Tier) (bool, time.Duration) {
+	b.tokens = math.Min(float64(tier.Burst), b.tokens+now.Sub(b.at).Seconds()*tier.perSecond())
+	b.at = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / tier.perSecond() * float64(time.Second))
+}
+
+func (b *tokenBucket) full(now time.Time, tier Tier) bool {
+	return b.tokens+now.Sub(b.at).Seconds()*tier.perSecond() >= float64(tier.Burst)
+}
+
+// takeScript refills and spends from a bucket stored as a hash of tokens and
+// the millisecond it was last updated, using Redis' clock so instances with
+// skewed clocks agree (Redis 5+ replicates the writes that follow TIME). ARGV: tokens per millisecond, burst. It returns
+// whether a token was spent, the whole tokens left and the milliseconds
+// until the next one.
+var takeScript = redis.NewScript(`local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'at')
+local tokens = tonumber(b[1]) or burst
+local at = tonumber(b[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - at) * rate)
+local ok, wait = 0, 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  ok = 1
+else
+  wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'at', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate))
+return {ok, math.floor(tokens), wait}`)
+
+// Take implements BucketStore in Redis.
+func (s *RedisStore) Take(ctx context.Context, key string, tier Tier) (bool, int64, time.Duration, error) {
+	perMS := strconv.FormatFloat(tier.perSecond()/1000, 'g', -1, 64)
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + "tb:" + key}, perMS, strconv.Itoa(tier.Burst)).Result()
+	if err != nil {
+		return false, 0, 0, err
+	}
+	vals, ok := reply.([]any)
+	if !ok || len(vals) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	took, ok1 := vals[0].(int64)
+	left, ok2 := vals[1].(int64)
+	wait, ok3 := vals[2].(int64)
+	if !ok1 || !ok2 || !ok3 {
+		return false, 0, 0, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	return took == 1, left, time.Duration(wait) * time.Millisecond, nil
+}
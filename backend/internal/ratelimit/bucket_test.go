@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParseTiers(t *testing.T) {
+	tiers, err := ParseTiers(" free=60/300, Pro=600/6000 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tiers["pro"] != (Tier{Name: "pro", PerMinute: 600, Burst: 6000}) {
+		t.Fatalf("parsed %+v", tiers)
+	}
+	if tier, _ := tiers.For("unknown"); tier.Name != DefaultTier {
+		t.Fatalf("unknown tier got %+v", tier)
+	}
+	if tiers, err := ParseTiers(""); err != nil || len(tiers) != 0 {
+		t.Fatalf("empty spec = %v, %v", tiers, err)
+	}
+	for _, bad := range []string{"pro=600/6000", "free=60", "free=0/10", "free=60/300,free=1/1", "=1/1"} {
+		if _, err := ParseTiers(bad); err == nil {
+			t.Errorf("ParseTiers(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBucketBanksBurstCredits(t *testing.T) {
+	m := NewMemoryStore()
+	// One token a second, banking up to three.
+	tier := Tier{Name: "free", PerMinute: 60, Burst: 3}
+	b := &tokenBucket{tokens: 0, at: time.Now()}
+	m.tokens["k"] = b
+
+	// Idle long enough to refill past the burst: only three are banked.
+	b.at = b.at.Add(-10 * time.Second)
+	for i := 0; i < 3; i++ {
+		if ok, _, _, _ := m.Take(t.Context(), "k", tier); !ok {
+			t.Fatalf("take %d rejected with credits banked", i+1)
+		}
+	}
+	ok, left, wait, _ := m.Take(t.Context(), "k", tier)
+	if ok || left != 0 || wait <= 0 || wait > time.Second {
+		t.Fatalf("empty bucket: ok %v left %d wait %s", ok, left, wait)
+	}
+
+	// A second later one token has come back: sustained traffic gets through
+	// at the refill rate.
+	b.at = b.at.Add(-time.Second)
+	if ok, _, _, _ := m.Take(t.Context(), "k", tier); !ok {
+		t.Fatal("refilled token rejected")
+	}
+}
+
+func TestBucketsHandler(t *testing.T) {
+	l := New(nil)
+	tiers := Tiers{
+		"free": {Name: "free", PerMinute: 1, Burst: 1},
+		"pro":  {Name: "pro", PerMinute: 1, Burst: 3},
+	}
+	app := fiber.New()
+	app.Get("/", l.Buckets(BucketRule{
+		Name:  "api_key",
+		Tiers: tiers,
+		Key:   func(c *fiber.Ctx) string { return c.Get("X-Account") },
+		Tier:  func(c *fiber.Ctx) string { return c.Get("X-Tier") },
+	}), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	status := func(account, tier string) int {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Account", account)
+		req.Header.Set("X-Tier", tier)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := status("paying", "pro"); code != 200 {
+			t.Fatalf("pro request %d = %d", i+1, code)
+		}
+	}
+	if code := status("paying", "pro"); code != fiber.StatusTooManyRequests {
+		t.Fatalf("pro over burst = %d", code)
+	}
+	if code := status("other", "gold"); code != 200 {
+		t.Fatalf("unknown tier = %d", code)
+	}
+	if code := status("other", "gold"); code != fiber.StatusTooManyRequests {
+		t.Fatalf("unknown tier should get the free bucket, got %d", code)
+	}
+	if code := status("", ""); code != 200 {
+		t.Fatalf("unkeyed request = %d", code)
+	}
+}
@@ -0,0 +1,233 @@
+// Package ratelimit counts requests per key in fixed windows, or spends them
+// from per-tier token buckets, and rejects clients over their limit. Counters live in Redis when configured so every
+// instance shares them, with an in-memory fallback while Redis is unreachable.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// Store counts hits per key.
+type Store interface {
+	// Hit counts one request against key and returns the count in the current
+	// window and the time until that window resets.
+	Hit(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error)
+}
+
+// Rule limits requests sharing a key to Limit per Window. Rules with the same
+// Name share buckets across routes. Limit <= 0 disables the rule.
+type Rule struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	// Key picks the bucket; an empty key skips the rule for that request.
+	Key func(c *fiber.Ctx) string
+}
+
+// Limiter applies rules. A nil Limiter admits everything.
+type Limiter struct {
+	store    Store
+	fallback *MemoryStore
+
+	mu          sync.Mutex
+	rejected    map[string]int64
+	storeErrors int64
+	lastWarn    time.Time
+}
+
+// New limits with store, or in memory only when store is nil.
+func New(store Store) *Limiter {
+	mem := NewMemoryStore()
+	if store == nil {
+		store = mem
+	}
+	return &Limiter{store: store, fallback: mem, rejected: map[string]int64{}}
+}
+
+// Shared reports whether counters live in a store shared between
+// instances rather than in memory.
+func (l *Limiter) Shared() bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.store.(*RedisStore)
+	return ok
+}
+
+// Ping checks the shared store is reachable; it is nil when counters are
+// kept in memory.
+func (l *Limiter) Ping(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if s, ok := l.store.(*RedisStore); ok {
+		return s.Ping(ctx)
+	}
+	return nil
+}
+
+// Handler enforces rules in order and answers the first one exceeded with 429
+// and a Retry-After header.
+func (l *Limiter) Handler(rules ...Rule) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if l == nil {
+			return c.Next()
+		}
+		for _, r := range rules {
+			if r.Limit <= 0 || r.Key == nil {
+				continue
+			}
+			key := r.Key(c)
+			if key == "" {
+				continue
+			}
+			count, reset := l.hit(c.Context(), r.Name+":"+key, r.Window)
+			if count > int64(r.Limit) {
+				l.mu.Lock()
+				l.rejected[r.Name]++
+				l.mu.Unlock()
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds(reset)))
+				return httpx.Fail(c, fiber.StatusTooManyRequests, "rate_limited")
+			}
+		}
+		return c.Next()
+	}
+}
+
+func (l *Limiter) hit(ctx context.Context, key string, window time.Duration) (int64, time.Duration) {
+	if window <= 0 {
+		window = time.Minute
+	}
+	count, reset, err := l.store.Hit(ctx, key, window)
+	if err == nil {
+		return count, reset
+	}
+	l.storeFailed(err)
+	count, reset, _ = l.fallback.Hit(ctx, key, window)
+	return count, reset
+}
+
+// storeFailed counts a store error, logging at most once a minute; the
+// caller falls back to memory.
+func (l *Limiter) storeFailed(err error) {
+	l.mu.Lock()
+	l.storeErrors++
+	warn := time.Since(l.lastWarn) > time.Minute
+	if warn {
+		l.lastWarn = time.Now()
+	}
+	l.mu.Unlock()
+	if warn {
+		slog.Warn("rate limit store failed, counting in memory", "error", err)
+	}
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	return max(1, int(math.Ceil(d.Seconds())))
+}
+
+// WriteMetrics writes reject and store-error counters in the Prometheus text
+// format.
+func (l *Limiter) WriteMetrics(w io.Writer) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	names := make([]string, 0, len(l.rejected))
+	for name := range l.rejected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("# HELP grainlify_rate_limit_rejected_total Requests rejected by a rate limit rule.\n# TYPE grainlify_rate_limit_rejected_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "grainlify_rate_limit_rejected_total{rule=%q} %d\n", name, l.rejected[name])
+	}
+	fmt.Fprintf(&b, "# HELP grainlify_rate_limit_store_errors_total Rate limit lookups that fell back to memory.\n# TYPE grainlify_rate_limit_store_errors_total counter\ngrainlify_rate_limit_store_errors_total %d\n", l.storeErrors)
+	l.mu.Unlock()
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ByIP keys on the client IP, which the app resolves through its trusted
+// proxies (config.TrustedProxies).
+func ByIP(c *fiber.Ctx) string {
+	return c.IP()
+}
+
+// BodyFields keys on the named fields of a JSON request body, lowercased.
+// Requests missing any of them are not limited by the rule.
+func BodyFields(names ...string) func(c *fiber.Ctx) string {
+	return func(c *fiber.Ctx) string {
+		var body map[string]any
+		if err := json.Unmarshal(c.Body(), &body); err != nil {
+			return ""
+		}
+		parts := make([]string, 0, len(names))
+		for _, n := range names {
+			v, _ := body[n].(string)
+			v = strings.ToLower(strings.TrimSpace(v))
+			if v == "" {
+				return ""
+			}
+			parts = append(parts, v)
+		}
+		return strings.Join(parts, ":")
+	}
+}
+
+// MemoryStore keeps counters and token buckets in process.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	hits    int
+	tokens  map[string]*tokenBucket
+	takes   int
+}
+
+type bucket struct {
+	count int64
+	reset time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, tokens: map[string]*tokenBucket{}}
+}
+
+func (m *MemoryStore) Hit(_ context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := m.buckets[key]
+	if b == nil || !now.Before(b.reset) {
+		b = &bucket{reset: now.Add(window)}
+		m.buckets[key] = b
+	}
+	b.count++
+
+	// Opportunistic cleanup so idle keys don't accumulate forever.
+	m.hits++
+	if m.hits%1024 == 0 {
+		for k, v := range m.buckets {
+			if !now.Before(v.reset) {
+				delete(m.buckets, k)
+			}
+		}
+	}
+	return b.count, b.reset.Sub(now), nil
+}
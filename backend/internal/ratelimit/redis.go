@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// hitScript increments the counter and starts its window on the first hit,
+// returning the count and the milliseconds left in the window.
+var hitScript = redis.NewScript(`local n = redis.call('INCR', KEYS[1])
+if n == 1 then redis.call('PEXPIRE', KEYS[1], ARGV[1]) end
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then redis.call('PEXPIRE', KEYS[1], ARGV[1]); ttl = tonumber(ARGV[1]) end
+return {n, ttl}`)
+
+// RedisStore keeps counters in Redis.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore parses a redis:// or rediss:// (TLS) URL,
+// [user:password@]host:port[/db]. Connections are opened on first use.
+func NewRedisStore(rawURL string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	// Fail fast and count in memory rather than stall the request.
+	opts.DialTimeout = 500 * time.Millisecond
+	opts.ReadTimeout = 500 * time.Millisecond
+	opts.WriteTimeout = 500 * time.Millisecond
+	opts.MaxRetries = -1
+	opts.PoolSize = 8
+	return &RedisStore{client: redis.NewClient(opts), prefix: "grainlify:rl:"}, nil
+}
+
+func (s *RedisStore) Hit(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	reply, err := hitScript.Run(ctx, s.client, []string{s.prefix + key}, strconv.FormatInt(window.Milliseconds(), 10)).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	vals, ok := reply.([]any)
+	if !ok || len(vals) != 2 {
+		return 0, 0, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	count, ok1 := vals[0].(int64)
+	ttl, ok2 := vals[1].(int64)
+	if !ok1 || !ok2 {
+		return 0, 0, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	return count, time.Duration(ttl) * time.Millisecond, nil
+}
+
+// Ping checks Redis answers.
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
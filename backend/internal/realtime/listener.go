@@ -0,0 +1,81 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Listener LISTENs on Channel over a dedicated connection and publishes each
+// announced event to Broker. Events announced while it is disconnected are
+// not replayed; clients catch up with Since when they reconnect.
+type Listener struct {
+	Pool   *pgxpool.Pool
+	Broker *Broker
+}
+
+// Run listens until ctx is done, reconnecting with backoff.
+func (l *Listener) Run(ctx context.Context) error {
+	if l.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	backoff := time.Second
+	for {
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			backoff = time.Second
+		}
+		slog.Warn("realtime listener disconnected", "retry_in", backoff.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	pc, err := l.Pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	// LISTEN state must not leak back into the pool.
+	conn := pc.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return false, err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		l.apply(ctx, n.Payload)
+	}
+}
+
+func (l *Listener) apply(ctx context.Context, payload string) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		slog.Warn("malformed realtime event", "error", err)
+		return
+	}
+	e := Event{Seq: n.Seq, Type: n.Type, Public: n.Public, UserIDs: n.UserIDs, Data: n.Data, CreatedAt: n.CreatedAt}
+	if e.Type == "" {
+		var err error
+		if e, err = get(ctx, l.Pool, n.Seq); err != nil {
+			slog.Warn("failed to load realtime event", "seq", n.Seq, "error", err)
+			return
+		}
+	}
+	l.Broker.Publish(e)
+}
@@ -0,0 +1,176 @@
+// Package realtime pushes events to connected clients. Database triggers
+// append them to realtime_events and announce them over Postgres NOTIFY; a
+// Listener on each API instance hands them to its Broker, which fans them
+// out to the sockets of the users allowed to see them.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel is the Postgres NOTIFY channel realtime_events inserts announce on.
+const Channel = "realtime"
+
+// Event types.
+const (
+	TypeBountyStatusChanged = "bounty.status_changed"
+	TypeBountyComment       = "bounty.comment_created"
+	TypePayoutStatusChanged = "payout.status_changed"
+	// TypeResync tells a client it missed more than can be replayed and
+	// should reload what it shows.
+	TypeResync = "resync"
+)
+
+// Event is one change pushed to clients. Public events go to every client,
+// others only to UserIDs.
+type Event struct {
+	Seq       int64           `json:"seq"`
+	Type      string          `json:"type"`
+	Public    bool            `json:"-"`
+	UserIDs   []uuid.UUID     `json:"-"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// For reports whether userID may receive e.
+func (e Event) For(userID uuid.UUID) bool {
+	return e.Public || slices.Contains(e.UserIDs, userID)
+}
+
+// notification is the NOTIFY payload: the whole event, or just its seq when
+// it was too large to announce.
+type notification struct {
+	Seq       int64           `json:"seq"`
+	Type      string          `json:"type"`
+	Public    bool            `json:"public"`
+	UserIDs   []uuid.UUID     `json:"user_ids"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// subscriberBuffer is how many events a socket may fall behind before it is
+// dropped; the client catches up by reconnecting with the last seq it saw.
+const subscriberBuffer = 64
+
+// Subscription receives the events meant for one user until it is closed,
+// by the subscriber or by the Broker when the subscriber falls behind.
+type Subscription struct {
+	UserID uuid.UUID
+	Events <-chan Event
+
+	events chan Event
+	once   sync.Once
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.events) })
+}
+
+// Broker fans events out to subscriptions in process.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: map[*Subscription]struct{}{}}
+}
+
+// Subscribe starts delivering userID's events.
+func (b *Broker) Subscribe(userID uuid.UUID) *Subscription {
+	ch := make(chan Event, subscriberBuffer)
+	s := &Subscription{UserID: userID, Events: ch, events: ch}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Unsubscribe stops delivery to s and closes its channel.
+func (b *Broker) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	delete(b.subs, s)
+	b.mu.Unlock()
+	s.close()
+}
+
+// Publish hands e to every subscription allowed to see it. A subscription
+// whose buffer is full is dropped rather than blocking everyone else.
+func (b *Broker) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		if !e.For(s.UserID) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			delete(b.subs, s)
+			s.close()
+		}
+	}
+}
+
+// Len is the number of open subscriptions.
+func (b *Broker) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Since returns up to limit of userID's events after seq, oldest first, so a
+// reconnecting client can catch up.
+func Since(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, seq int64, limit int) ([]Event, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT seq, type, public, user_ids, data, created_at
+FROM realtime_events
+WHERE seq > $2 AND (public OR user_ids @> ARRAY[$1::uuid])
+ORDER BY seq
+LIMIT $3
+`, userID, seq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Event{}
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.Seq, &e.Type, &e.Public, &e.UserIDs, &e.Data, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// get reads one event back, for announcements too large to carry it.
+func get(ctx context.Context, pool *pgxpool.Pool, seq int64) (Event, error) {
+	var e Event
+	err := pool.QueryRow(ctx, `SELECT seq, type, public, user_ids, data, created_at FROM realtime_events WHERE seq = $1`, seq).
+		Scan(&e.Seq, &e.Type, &e.Public, &e.UserIDs, &e.Data, &e.CreatedAt)
+	return e, err
+}
+
+// Prune deletes events older than keep, returning how many.
+func Prune(ctx context.Context, pool *pgxpool.Pool, keep time.Duration) (int64, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM realtime_events WHERE created_at < now() - $1 * interval '1 second'`, int64(keep.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,58 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBrokerPublish(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	b := NewBroker()
+	sa, sb := b.Subscribe(alice), b.Subscribe(bob)
+
+	b.Publish(Event{Seq: 1, Type: TypePayoutStatusChanged, UserIDs: []uuid.UUID{alice}})
+	b.Publish(Event{Seq: 2, Type: TypeBountyStatusChanged, Public: true})
+
+	if e := <-sa.Events; e.Seq != 1 {
+		t.Errorf("alice got seq %d first, want 1", e.Seq)
+	}
+	if e := <-sa.Events; e.Seq != 2 {
+		t.Errorf("alice got seq %d second, want 2", e.Seq)
+	}
+	if e := <-sb.Events; e.Seq != 2 {
+		t.Errorf("bob got seq %d, want only the public 2", e.Seq)
+	}
+	select {
+	case e := <-sb.Events:
+		t.Errorf("bob got extra event %d", e.Seq)
+	default:
+	}
+
+	b.Unsubscribe(sa)
+	if _, ok := <-sa.Events; ok {
+		t.Error("unsubscribed channel still open")
+	}
+	b.Unsubscribe(sa)
+	if b.Len() != 1 {
+		t.Errorf("len = %d, want 1", b.Len())
+	}
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	b := NewBroker()
+	s := b.Subscribe(uuid.New())
+	for i := 0; i <= subscriberBuffer; i++ {
+		b.Publish(Event{Seq: int64(i), Public: true})
+	}
+	if b.Len() != 0 {
+		t.Fatal("slow subscriber kept")
+	}
+	n := 0
+	for range s.Events {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("drained %d events, want the %d buffered", n, subscriberBuffer)
+	}
+}
@@ -0,0 +1,283 @@
+// Package repohealth computes how promptly a project's maintainers review,
+// respond to and merge contributions, and how often its CI passes, from the
+// GitHub activity mirrored by webhooks. Funders use it to judge whether a
+// bounty on the repo is likely to be merged promptly.
+package repohealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Ratings summarising a Health.
+const (
+	RatingGood = "good"
+	RatingFair = "fair"
+	RatingPoor = "poor"
+	// RatingInsufficientData: too little activity in the window to judge.
+	RatingInsufficientData = "insufficient_data"
+)
+
+const (
+	// DefaultWindowDays is how far back activity counts.
+	DefaultWindowDays = 90
+	// MinSample is the fewest pull requests (or CI runs) worth rating.
+	MinSample = 5
+	// slowHours is the median wait past which reviews and responses count
+	// against a repo.
+	slowHours = 72
+	// minPassRate and minResponseRate are the lowest acceptable CI pass rate
+	// and share of issues and pull requests that get a maintainer response.
+	minPassRate     = 0.7
+	minResponseRate = 0.5
+	// responseGrace keeps items younger than this out of the response rate,
+	// so a burst of new issues doesn't count as ignored.
+	responseGrace = 48 * time.Hour
+)
+
+var ErrNotFound = errors.New("project_health_not_found")
+
+// Health is a project's activity over the last WindowDays. Nil metrics mean
+// there was nothing to measure.
+type Health struct {
+	ProjectID  uuid.UUID `json:"project_id"`
+	WindowDays int       `json:"window_days"`
+	PRsOpened  int       `json:"prs_opened"`
+	PRsMerged  int       `json:"prs_merged"`
+	// MedianReviewHours is from a pull request opening to its first review
+	// by someone other than its author.
+	MedianReviewHours *float64 `json:"median_review_hours"`
+	MedianMergeHours  *float64 `json:"median_merge_hours"`
+	// CIRuns counts completed check suites with a pass/fail conclusion.
+	CIRuns     int      `json:"ci_runs"`
+	CIPassRate *float64 `json:"ci_pass_rate"`
+	// MedianResponseHours is from an issue or pull request opening to the
+	// first comment or review by a maintainer (owner, member or
+	// collaborator) other than its author.
+	MedianResponseHours *float64  `json:"median_response_hours"`
+	ResponseRate        *float64  `json:"response_rate"`
+	Rating              string    `json:"rating"`
+	ComputedAt          time.Time `json:"computed_at"`
+}
+
+// Rate summarises h: each slow or failing metric lowers the rating, and
+// metrics without data don't count.
+func Rate(h Health) string {
+	if h.PRsOpened < MinSample && h.CIRuns < MinSample {
+		return RatingInsufficientData
+	}
+	bad := 0
+	if h.MedianReviewHours != nil && *h.MedianReviewHours > slowHours {
+		bad++
+	}
+	if h.MedianResponseHours != nil && *h.MedianResponseHours > slowHours {
+		bad++
+	}
+	if h.CIRuns >= MinSample && h.CIPassRate != nil && *h.CIPassRate < minPassRate {
+		bad++
+	}
+	if h.ResponseRate != nil && *h.ResponseRate < minResponseRate {
+		bad++
+	}
+	switch {
+	case bad == 0:
+		return RatingGood
+	case bad == 1:
+		return RatingFair
+	default:
+		return RatingPoor
+	}
+}
+
+// maintainerAssociations are the author_association values GitHub gives
+// people with write access or ownership.
+const maintainerAssociations = `('OWNER', 'MEMBER', 'COLLABORATOR')`
+
+// Compute measures projectID's activity over the last windowDays.
+func Compute(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, windowDays int) (Health, error) {
+	if pool == nil {
+		return Health{}, fmt.Errorf("db not configured")
+	}
+	if windowDays <= 0 {
+		windowDays = DefaultWindowDays
+	}
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -windowDays)
+	h := Health{ProjectID: projectID, WindowDays: windowDays, ComputedAt: now}
+
+	if err := pool.QueryRow(ctx, `
+WITH prs AS (
+  SELECT pr.created_at_github AS opened_at, pr.merged_at_github AS merged_at,
+    (
+      SELECT MIN(r.submitted_at) FROM github_pr_reviews r
+      WHERE r.project_id = pr.project_id AND r.pr_number = pr.number
+        AND r.reviewer_login IS DISTINCT FROM pr.author_login
+        AND r.reviewer_login NOT LIKE '%[bot]'
+    ) AS reviewed_at
+  FROM github_pull_requests pr
+  WHERE pr.project_id = $1 AND pr.created_at_github >= $2
+)
+SELECT
+  COUNT(*),
+  COUNT(merged_at),
+  percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM reviewed_at - opened_at) / 3600),
+  percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM merged_at - opened_at) / 3600)
+FROM prs
+`, projectID, since).Scan(&h.PRsOpened, &h.PRsMerged, &h.MedianReviewHours, &h.MedianMergeHours); err != nil {
+		return Health{}, err
+	}
+
+	var passed int
+	if err := pool.QueryRow(ctx, `
+SELECT
+  COUNT(*) FILTER (WHERE conclusion = 'success'),
+  COUNT(*)
+FROM github_check_suites
+WHERE project_id = $1 AND completed_at >= $2
+  AND conclusion IN ('success', 'failure', 'timed_out', 'startup_failure')
+`, projectID, since).Scan(&passed, &h.CIRuns); err != nil {
+		return Health{}, err
+	}
+	if h.CIRuns > 0 {
+		rate := float64(passed) / float64(h.CIRuns)
+		h.CIPassRate = &rate
+	}
+
+	var settled, responded int
+	if err := pool.QueryRow(ctx, `
+WITH items AS (
+  SELECT number, author_login, created_at_github AS opened_at FROM github_issues
+  WHERE project_id = $1 AND created_at_github >= $2
+  UNION
+  SELECT number, author_login, created_at_github FROM github_pull_requests
+  WHERE project_id = $1 AND created_at_github >= $2
+), responses AS (
+  SELECT i.opened_at, (
+    SELECT MIN(t) FROM (
+      SELECT c.created_at_github AS t FROM github_issue_comment_events c
+      WHERE c.project_id = $1 AND c.issue_number = i.number
+        AND c.author_association IN `+maintainerAssociations+`
+        AND c.author_login IS DISTINCT FROM i.author_login
+      UNION ALL
+      SELECT r.submitted_at FROM github_pr_reviews r
+      WHERE r.project_id = $1 AND r.pr_number = i.number
+        AND r.author_association IN `+maintainerAssociations+`
+        AND r.reviewer_login IS DISTINCT FROM i.author_login
+    ) firsts
+  ) AS responded_at
+  FROM items i
+)
+SELECT
+  percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM responded_at - opened_at) / 3600),
+  COUNT(*) FILTER (WHERE opened_at < $3),
+  COUNT(*) FILTER (WHERE opened_at < $3 AND responded_at IS NOT NULL)
+FROM responses
+`, projectID, since, now.Add(-responseGrace)).Scan(&h.MedianResponseHours, &settled, &responded); err != nil {
+		return Health{}, err
+	}
+	if settled > 0 {
+		rate := float64(responded) / float64(settled)
+		h.ResponseRate = &rate
+	}
+
+	h.Rating = Rate(h)
+	return h, nil
+}
+
+// Save stores h as its project's latest health.
+func Save(ctx context.Context, pool *pgxpool.Pool, h Health) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	_, err := pool.Exec(ctx, `
+INSERT INTO project_health (project_id, window_days, prs_opened, prs_merged, median_review_hours, median_merge_hours, ci_runs, ci_pass_rate, median_response_hours, response_rate, rating, computed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (project_id) DO UPDATE SET
+  window_days = EXCLUDED.window_days,
+  prs_opened = EXCLUDED.prs_opened,
+  prs_merged = EXCLUDED.prs_merged,
+  median_review_hours = EXCLUDED.median_review_hours,
+  median_merge_hours = EXCLUDED.median_merge_hours,
+  ci_runs = EXCLUDED.ci_runs,
+  ci_pass_rate = EXCLUDED.ci_pass_rate,
+  median_response_hours = EXCLUDED.median_response_hours,
+  response_rate = EXCLUDED.response_rate,
+  rating = EXCLUDED.rating,
+  computed_at = EXCLUDED.computed_at
+`, h.ProjectID, h.WindowDays, h.PRsOpened, h.PRsMerged, h.MedianReviewHours, h.MedianMergeHours, h.CIRuns, h.CIPassRate, h.MedianResponseHours, h.ResponseRate, h.Rating, h.ComputedAt)
+	return err
+}
+
+// Get returns projectID's latest stored health.
+func Get(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) (Health, error) {
+	if pool == nil {
+		return Health{}, fmt.Errorf("db not configured")
+	}
+	var h Health
+	err := pool.QueryRow(ctx, `
+SELECT project_id, window_days, prs_opened, prs_merged, median_review_hours, median_merge_hours, ci_runs, ci_pass_rate, median_response_hours, response_rate, rating, computed_at
+FROM project_health
+WHERE project_id = $1
+`, projectID).Scan(&h.ProjectID, &h.WindowDays, &h.PRsOpened, &h.PRsMerged, &h.MedianReviewHours, &h.MedianMergeHours, &h.CIRuns, &h.CIPassRate, &h.MedianResponseHours, &h.ResponseRate, &h.Rating, &h.ComputedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Health{}, ErrNotFound
+	}
+	if err != nil {
+		return Health{}, err
+	}
+	return h, nil
+}
+
+// Refresher recomputes the health of every verified project.
+type Refresher struct {
+	Pool       *pgxpool.Pool
+	WindowDays int
+}
+
+// RunOnce refreshes every verified project and returns how many it updated.
+// A failing project is logged and skipped.
+func (r *Refresher) RunOnce(ctx context.Context) (int, error) {
+	if r.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := r.Pool.Query(ctx, `SELECT id FROM projects WHERE status = 'verified' AND deleted_at IS NULL`)
+	if err != nil {
+		return 0, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		h, err := Compute(ctx, r.Pool, id, r.WindowDays)
+		if err == nil {
+			err = Save(ctx, r.Pool, h)
+		}
+		if err != nil {
+			slog.Warn("repo health refresh failed", "project_id", id, "error", err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
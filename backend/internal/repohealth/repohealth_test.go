@@ -0,0 +1,25 @@
+package repohealth
+
+import "testing"
+
+func ptr(f float64) *float64 { return &f }
+
+func TestRate(t *testing.T) {
+	cases := []struct {
+		name string
+		h    Health
+		want string
+	}{
+		{"no activity", Health{PRsOpened: 2, CIRuns: 1}, RatingInsufficientData},
+		{"prompt", Health{PRsOpened: 10, MedianReviewHours: ptr(6), MedianResponseHours: ptr(3), CIRuns: 20, CIPassRate: ptr(0.95), ResponseRate: ptr(0.9)}, RatingGood},
+		{"ci only", Health{CIRuns: 8, CIPassRate: ptr(0.9)}, RatingGood},
+		{"slow reviews", Health{PRsOpened: 10, MedianReviewHours: ptr(200), ResponseRate: ptr(0.8)}, RatingFair},
+		{"flaky ci ignored below sample", Health{PRsOpened: 10, CIRuns: 3, CIPassRate: ptr(0.1)}, RatingGood},
+		{"ignored and failing", Health{PRsOpened: 10, MedianResponseHours: ptr(100), CIRuns: 10, CIPassRate: ptr(0.4), ResponseRate: ptr(0.2)}, RatingPoor},
+	}
+	for _, tc := range cases {
+		if got := Rate(tc.h); got != tc.want {
+			t.Errorf("%s: Rate = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Package resume builds a contributor's verifiable résumé: a JSON-LD document
+// of merged work and payouts, signed with the platform key so third parties
+// can check it offline.
+package resume
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user_not_found")
+	ErrMissingProof     = errors.New("missing_proof")
+	ErrInvalidSignature = errors.New("invalid_signature")
+)
+
+// ProofType names the signature scheme: Ed25519 over the domain prefix
+// followed by the RFC 8785 (JCS) form of the document without "proof".
+const ProofType = "GrainlifyEd25519Signature"
+
+const signingPrefix = "grainlify-resume:v1:"
+
+// Contribution is a merged pull request in a verified project.
+type Contribution struct {
+	Type     string     `json:"type"`
+	Repo     string     `json:"repo"`
+	Number   int        `json:"number"`
+	Title    string     `json:"title,omitempty"`
+	URL      string     `json:"url,omitempty"`
+	MergedAt *time.Time `json:"mergedAt,omitempty"`
+}
+
+// AttestationRef points at the on-chain EAS attestation for a payout.
+type AttestationRef struct {
+	UID    string `json:"uid"`
+	Chain  string `json:"chain"`
+	Schema string `json:"schema"`
+	TxHash string `json:"txHash,omitempty"`
+}
+
+// Payment is a payout sent to the contributor.
+type Payment struct {
+	Type        string          `json:"type"`
+	ID          uuid.UUID       `json:"id"`
+	Chain       string          `json:"chain"`
+	Asset       string          `json:"asset"`
+	Amount      string          `json:"amount"`
+	TxHash      string          `json:"txHash,omitempty"`
+	Repo        string          `json:"repo,omitempty"`
+	PRNumber    *int            `json:"prNumber,omitempty"`
+	PaidAt      time.Time       `json:"paidAt"`
+	Attestation *AttestationRef `json:"attestation,omitempty"`
+}
+
+type Subject struct {
+	ID            string         `json:"id"`
+	GitHubLogin   string         `json:"githubLogin,omitempty"`
+	Contributions []Contribution `json:"contributions"`
+	Payments      []Payment      `json:"payments"`
+}
+
+type Issuer struct {
+	ID        string `json:"id"`
+	PublicKey string `json:"publicKeyHex"`
+}
+
+type Proof struct {
+	Type               string    `json:"type"`
+	Created            time.Time `json:"created"`
+	VerificationMethod string    `json:"verificationMethod"`
+	ProofPurpose       string    `json:"proofPurpose"`
+	SignatureValue     string    `json:"signatureValue"`
+}
+
+// Document is the résumé. Proof is nil until Sign is called.
+type Document struct {
+	Context           []any     `json:"@context"`
+	ID                string    `json:"id"`
+	Type              []string  `json:"type"`
+	Issuer            Issuer    `json:"issuer"`
+	IssuanceDate      time.Time `json:"issuanceDate"`
+	CredentialSubject Subject   `json:"credentialSubject"`
+	Proof             *Proof    `json:"proof,omitempty"`
+}
+
+var jsonLDContext = []any{
+	"https://www.w3.org/2018/credentials/v1",
+	map[string]any{
+		"@vocab":        "https://grainlify.io/vocab#",
+		"contributions": map[string]any{"@container": "@list"},
+		"payments":      map[string]any{"@container": "@list"},
+	},
+}
+
+// Build collects a user's merged PRs in verified projects and sent payouts,
+// with attestation references where one has landed on-chain. issuer is the
+// public base URL of this backend.
+func Build(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, issuer string, now time.Time) (Document, error) {
+	if pool == nil {
+		return Document{}, fmt.Errorf("db not configured")
+	}
+	var login *string
+	err := pool.QueryRow(ctx, `
+SELECT ga.login
+FROM users u
+LEFT JOIN github_accounts ga ON ga.user_id = u.id
+WHERE u.id = $1
+`, userID).Scan(&login)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Document{}, ErrUserNotFound
+	}
+	if err != nil {
+		return Document{}, err
+	}
+
+	subject := Subject{
+		ID:            "urn:uuid:" + userID.String(),
+		Contributions: []Contribution{},
+		Payments:      []Payment{},
+	}
+	if login != nil {
+		subject.GitHubLogin = *login
+		if subject.Contributions, err = contributions(ctx, pool, *login); err != nil {
+			return Document{}, err
+		}
+	}
+	if subject.Payments, err = payments(ctx, pool, userID); err != nil {
+		return Document{}, err
+	}
+
+	return Document{
+		Context:           jsonLDContext,
+		ID:                issuer + "/users/" + userID.String() + "/resume",
+		Type:              []string{"VerifiableCredential", "ContributorResume"},
+		Issuer:            Issuer{ID: issuer},
+		IssuanceDate:      now.UTC().Truncate(time.Second),
+		CredentialSubject: subject,
+	}, nil
+}
+
+func contributions(ctx context.Context, pool *pgxpool.Pool, login string) ([]Contribution, error) {
+	rows, err := pool.Query(ctx, `
+SELECT p.github_full_name, pr.number, COALESCE(pr.title, ''), COALESCE(pr.url, ''), pr.merged_at_github
+FROM github_pull_requests pr
+JOIN projects p ON p.id = pr.project_id
+WHERE lower(pr.author_login) = lower($1)
+  AND pr.merged = true
+  AND p.status = 'verified'
+ORDER BY pr.merged_at_github DESC NULLS LAST, p.github_full_name, pr.number
+LIMIT 500
+`, login)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Contribution{}
+	for rows.Next() {
+		c := Contribution{Type: "MergedPullRequest"}
+		if err := rows.Scan(&c.Repo, &c.Number, &c.Title, &c.URL, &c.MergedAt); err != nil {
+			return nil, err
+		}
+		if c.MergedAt != nil {
+			t := c.MergedAt.UTC()
+			c.MergedAt = &t
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func payments(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Payment, error) {
+	rows, err := pool.Query(ctx, `
+SELECT p.id, p.chain, p.asset, p.amount::text, COALESCE(p.tx_hash, ''), COALESCE(p.repo_full_name, ''), p.pr_number, p.updated_at,
+       a.uid, a.chain, a.schema_uid, a.tx_hash
+FROM payouts p
+LEFT JOIN attestations a ON a.payout_id = p.id AND a.status = 'attested'
+WHERE p.user_id = $1 AND p.status IN ('submitted', 'confirmed')
+ORDER BY p.updated_at DESC
+LIMIT 500
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Payment{}
+	for rows.Next() {
+		p := Payment{Type: "Payout"}
+		var uid, chain, schema, txHash *string
+		if err := rows.Scan(&p.ID, &p.Chain, &p.Asset, &p.Amount, &p.TxHash, &p.Repo, &p.PRNumber, &p.PaidAt, &uid, &chain, &schema, &txHash); err != nil {
+			return nil, err
+		}
+		p.PaidAt = p.PaidAt.UTC()
+		if uid != nil && chain != nil && schema != nil {
+			p.Attestation = &AttestationRef{UID: *uid, Chain: *chain, Schema: *schema}
+			if txHash != nil {
+				p.Attestation.TxHash = *txHash
+			}
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// Sign attaches a proof made with the platform key. keyURL is where verifiers
+// fetch the public key.
+func Sign(doc Document, signer *ledger.Signer, keyURL string, now time.Time) (Document, error) {
+	doc.Proof = nil
+	doc.Issuer.PublicKey = signer.PublicKey()
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return Document{}, err
+	}
+	msg, err := signingMessage(raw)
+	if err != nil {
+		return Document{}, err
+	}
+	doc.Proof = &Proof{
+		Type:               ProofType,
+		Created:            now.UTC().Truncate(time.Second),
+		VerificationMethod: keyURL,
+		ProofPurpose:       "assertionMethod",
+		SignatureValue:     signer.Sign(msg),
+	}
+	return doc, nil
+}
+
+// Verify checks a signed résumé as received (raw JSON) against the platform
+// public key (hex). Verifiers should pin that key rather than trust the one
+// embedded in the document.
+func Verify(raw []byte, publicKeyHex string) error {
+	pub, err := hex.DecodeString(publicKeyHex)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key")
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+	proof, ok := doc["proof"].(map[string]any)
+	if !ok {
+		return ErrMissingProof
+	}
+	sigHex, _ := proof["signatureValue"].(string)
+	sig, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	delete(doc, "proof")
+	unsigned, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	msg, err := signingMessage(unsigned)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, msg, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signingMessage(raw []byte) ([]byte, error) {
+	canon, err := Canonicalize(raw)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(signingPrefix), canon...), nil
+}
+
+// Canonicalize re-encodes JSON with sorted object keys, no insignificant
+// whitespace and unescaped HTML characters. For the strings and integers a
+// résumé contains this matches RFC 8785.
+func Canonicalize(raw []byte) ([]byte, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,82 @@
+package resume
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+)
+
+func testSigner(t *testing.T) *ledger.Signer {
+	t.Helper()
+	s, err := ledger.NewSignerFromB64(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testDocument() Document {
+	n := 42
+	return Document{
+		Context:      jsonLDContext,
+		ID:           "https://api.example.com/users/x/resume",
+		Type:         []string{"VerifiableCredential", "ContributorResume"},
+		Issuer:       Issuer{ID: "https://api.example.com"},
+		IssuanceDate: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		CredentialSubject: Subject{
+			ID:          "urn:uuid:" + uuid.Nil.String(),
+			GitHubLogin: "octocat",
+			Contributions: []Contribution{
+				{Type: "MergedPullRequest", Repo: "acme/<widgets>", Number: n, URL: "https://github.com/acme/widgets/pull/42?a=1&b=2"},
+			},
+			Payments: []Payment{
+				{Type: "Payout", ID: uuid.Nil, Chain: "base", Asset: "USDC", Amount: "12.5", PRNumber: &n, PaidAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+					Attestation: &AttestationRef{UID: "0x01", Chain: "base", Schema: "0x02"}},
+			},
+		},
+	}
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	s := testSigner(t)
+	doc, err := Sign(testDocument(), s, "https://api.example.com/platform/keys", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(raw, s.PublicKey()); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	tampered := strings.Replace(string(raw), `"12.5"`, `"125"`, 1)
+	if err := Verify([]byte(tampered), s.PublicKey()); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("tampered document: got %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyMissingProof(t *testing.T) {
+	raw, _ := json.Marshal(testDocument())
+	if err := Verify(raw, testSigner(t).PublicKey()); !errors.Is(err, ErrMissingProof) {
+		t.Fatalf("got %v, want ErrMissingProof", err)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	got, err := Canonicalize([]byte(`{ "b": 1, "a": {"d": "<&>", "c": [2, 1]} }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":{"c":[2,1],"d":"<&>"},"b":1}`; string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+package safehttp
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"127.0.0.1":   false,
+		"10.1.2.3":    false,
+		"169.254.1.1": false,
+		"::1":         false,
+		"8.8.8.8":     true,
+	} {
+		if got := IsPublicIP(net.ParseIP(ip)); got != want {
+			t.Fatalf("IsPublicIP(%s) = %v", ip, got)
+		}
+	}
+}
+
+func TestNewClientRefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+	resp, err := NewClient().Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("connected to a loopback server")
+	}
+}
@@ -0,0 +1,105 @@
+package savedreports
+
+// Catalog lists every saved report. Add new ones here; each binds its Params
+// as $1, $2, ... in order and casts ids and amounts to text.
+var Catalog = []Report{
+	{
+		Name:        "user_lookup",
+		Title:       "Find a user",
+		Description: "Accounts matching a user id, GitHub login or wallet address, with their wallets and status.",
+		Params: []Param{
+			{Name: "q", Type: TypeText, Required: true, Description: "User id, GitHub login or wallet address"},
+		},
+		SQL: `
+SELECT u.id::text AS user_id, ga.login AS github_login, u.role, u.plan_tier,
+  CASE WHEN u.banned_at IS NOT NULL THEN 'banned' WHEN u.suspended_until > now() THEN 'suspended' ELSE 'active' END AS status,
+  (SELECT string_agg(w.wallet_type || ':' || w.address, ' ' ORDER BY w.created_at) FROM wallets w WHERE w.user_id = u.id) AS wallets,
+  u.created_at
+FROM users u
+LEFT JOIN github_accounts ga ON ga.user_id = u.id
+WHERE u.id::text = $1 OR lower(ga.login) = lower($1)
+  OR EXISTS (SELECT 1 FROM wallets w WHERE w.user_id = u.id AND lower(w.address) = lower($1))
+ORDER BY u.created_at
+`,
+	},
+	{
+		Name:        "user_payouts",
+		Title:       "A user's payouts",
+		Description: "Every payout to a user, newest first, with its transaction and any error. Answers \"where is my payment?\".",
+		Params: []Param{
+			{Name: "user_id", Type: TypeUUID, Required: true, Description: "User id"},
+			{Name: "since", Type: TypeTimestamp, Description: "Only payouts created on or after this date"},
+		},
+		SQL: `
+SELECT p.id::text AS payout_id, p.status, p.chain, p.asset, p.amount::text AS amount, p.to_address,
+  p.tx_hash, p.error_code, p.error, p.reference, p.repo_full_name, p.pr_number, p.escrow_bounty_id::text AS escrow_bounty_id,
+  p.created_at, p.updated_at
+FROM payouts p
+WHERE p.user_id = $1 AND ($2::timestamptz IS NULL OR p.created_at >= $2)
+ORDER BY p.created_at DESC
+`,
+	},
+	{
+		Name:        "stuck_payouts",
+		Title:       "Stuck payouts",
+		Description: "Payouts still pending or batched after the given number of hours.",
+		Params: []Param{
+			{Name: "older_than_hours", Type: TypeInt, Description: "Minimum age in hours", Default: "24"},
+		},
+		SQL: `
+SELECT p.id::text AS payout_id, p.user_id::text AS user_id, ga.login AS github_login, p.status, p.chain, p.asset,
+  p.amount::text AS amount, p.batch_id::text AS batch_id, p.error, p.created_at
+FROM payouts p
+LEFT JOIN github_accounts ga ON ga.user_id = p.user_id
+WHERE p.status IN ('pending', 'batched') AND p.created_at < now() - make_interval(hours => $1::int)
+ORDER BY p.created_at
+`,
+	},
+	{
+		Name:        "project_bounties",
+		Title:       "A project's bounties",
+		Description: "Bounties of a project with their escrow state, optionally filtered by status.",
+		Params: []Param{
+			{Name: "project_id", Type: TypeUUID, Required: true, Description: "Project id"},
+			{Name: "status", Type: TypeText, Description: "open, completed or cancelled"},
+		},
+		SQL: `
+SELECT b.id::text AS bounty_id, b.issue_key, b.issue_title, b.status, b.chain, b.asset, b.amount::text AS amount,
+  b.funding, b.escrow_status, b.escrow_deadline, b.created_at, b.updated_at
+FROM bounties b
+WHERE b.project_id = $1 AND ($2::text IS NULL OR b.status = $2)
+ORDER BY b.created_at DESC
+`,
+	},
+	{
+		Name:        "failing_webhooks",
+		Title:       "Failing webhook deliveries",
+		Description: "Webhook deliveries that failed since the given date, optionally for one endpoint owner.",
+		Params: []Param{
+			{Name: "since", Type: TypeTimestamp, Required: true, Description: "Deliveries created on or after this date"},
+			{Name: "owner_user_id", Type: TypeUUID, Description: "Endpoint owner"},
+		},
+		SQL: `
+SELECT d.id::text AS delivery_id, e.id::text AS endpoint_id, e.owner_user_id::text AS owner_user_id, e.url,
+  d.event_type, d.attempts, d.response_status, d.error, d.last_attempt_at, d.created_at
+FROM webhook_deliveries d
+JOIN webhook_endpoints e ON e.id = d.endpoint_id
+WHERE d.status = 'failed' AND d.created_at >= $1 AND ($2::uuid IS NULL OR e.owner_user_id = $2)
+ORDER BY d.created_at DESC
+`,
+	},
+	{
+		Name:        "failed_jobs",
+		Title:       "Failed queued jobs",
+		Description: "Queued jobs that exhausted their attempts, optionally of one kind.",
+		Params: []Param{
+			{Name: "kind", Type: TypeText, Description: "Job kind"},
+		},
+		SQL: `
+SELECT j.id::text AS job_id, j.kind, j.dedupe_key, j.attempts, j.last_error, j.created_at, j.updated_at
+FROM job_queue j
+WHERE j.status = 'failed' AND ($1::text IS NULL OR j.kind = $1)
+ORDER BY j.updated_at DESC
+`,
+	},
+}
@@ -0,0 +1,213 @@
+// Package savedreports runs the predefined, parameterized SQL reports admins
+// use to answer support questions without database access. Reports are
+// defined in code; callers only choose a report and supply its parameters,
+// which are always bound, never spliced into the SQL.
+package savedreports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Parameter types.
+const (
+	TypeText      = "text"
+	TypeUUID      = "uuid"
+	TypeInt       = "int"
+	TypeTimestamp = "timestamp"
+)
+
+const (
+	// MaxRows caps a report's result; larger results are truncated.
+	MaxRows = 5000
+	// statementTimeout bounds a report so a heavy one can't hog the database.
+	statementTimeout = "30s"
+)
+
+var (
+	ErrUnknownReport = errors.New("unknown_report")
+	ErrInvalidParam  = errors.New("invalid_report_param")
+)
+
+// ParamError names the parameter that failed to parse.
+type ParamError struct {
+	Param  string
+	Reason string
+}
+
+func (e *ParamError) Error() string { return e.Param + ": " + e.Reason }
+
+func (e *ParamError) Unwrap() error { return ErrInvalidParam }
+
+// Param is one input to a report, bound as $n in the order listed.
+type Param struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+	// Default is used when an optional parameter is omitted; empty binds NULL.
+	Default string `json:"default,omitempty"`
+}
+
+// Report is a saved query.
+type Report struct {
+	Name        string  `json:"name"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Params      []Param `json:"params"`
+	SQL         string  `json:"-"`
+}
+
+// Result is a report's output, rows in column order.
+type Result struct {
+	Columns   []string `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	Truncated bool     `json:"truncated"`
+}
+
+// Find returns the named report.
+func Find(name string) (Report, error) {
+	for _, r := range Catalog {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return Report{}, ErrUnknownReport
+}
+
+// Args parses values (by parameter name) into r's bind arguments.
+func (r Report) Args(values map[string]string) ([]any, error) {
+	args := make([]any, 0, len(r.Params))
+	for _, p := range r.Params {
+		raw := strings.TrimSpace(values[p.Name])
+		if raw == "" {
+			if p.Required {
+				return nil, &ParamError{Param: p.Name, Reason: "required"}
+			}
+			raw = p.Default
+		}
+		if raw == "" {
+			args = append(args, nil)
+			continue
+		}
+		v, err := parse(p.Type, raw)
+		if err != nil {
+			return nil, &ParamError{Param: p.Name, Reason: err.Error()}
+		}
+		args = append(args, v)
+	}
+	return args, nil
+}
+
+func parse(typ, raw string) (any, error) {
+	switch typ {
+	case TypeUUID:
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("not a uuid")
+		}
+		return id, nil
+	case TypeInt:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("not an integer")
+		}
+		return n, nil
+	case TypeTimestamp:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse("2006-01-02", raw); err == nil {
+			return t, nil
+		}
+		return nil, fmt.Errorf("not a date (YYYY-MM-DD) or RFC 3339 time")
+	default:
+		if len(raw) > 256 {
+			return nil, fmt.Errorf("too long")
+		}
+		return raw, nil
+	}
+}
+
+// Run executes r with values in a read-only transaction.
+func Run(ctx context.Context, pool *pgxpool.Pool, r Report, values map[string]string) (Result, error) {
+	if pool == nil {
+		return Result{}, fmt.Errorf("db not configured")
+	}
+	args, err := r.Args(values)
+	if err != nil {
+		return Result{}, err
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `SET LOCAL statement_timeout = '`+statementTimeout+`'`); err != nil {
+		return Result{}, err
+	}
+	rows, err := tx.Query(ctx, r.SQL, args...)
+	if err != nil {
+		return Result{}, err
+	}
+	defer rows.Close()
+	out := Result{Columns: []string{}, Rows: [][]any{}}
+	for _, f := range rows.FieldDescriptions() {
+		out.Columns = append(out.Columns, f.Name)
+	}
+	for rows.Next() {
+		if len(out.Rows) == MaxRows {
+			out.Truncated = true
+			break
+		}
+		vals, err := rows.Values()
+		if err != nil {
+			return Result{}, err
+		}
+		for i, v := range vals {
+			vals[i] = cell(v)
+		}
+		out.Rows = append(out.Rows, vals)
+	}
+	return out, rows.Err()
+}
+
+// cell turns driver values into ones that read well as JSON and CSV.
+// Reports cast numerics to text so amounts keep their precision.
+func cell(v any) any {
+	switch v := v.(type) {
+	case [16]byte:
+		return uuid.UUID(v).String()
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	return v
+}
+
+// String formats a cell for CSV. Text that a spreadsheet would read as a
+// formula (user-supplied titles, logins) is quoted with a leading apostrophe.
+func String(v any) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		s = v
+	case []string:
+		s = strings.Join(v, ";")
+	default:
+		return fmt.Sprint(v)
+	}
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package savedreports
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCatalogBindsEveryParam(t *testing.T) {
+	placeholder := regexp.MustCompile(`\$(\d+)`)
+	seen := map[string]bool{}
+	for _, r := range Catalog {
+		if seen[r.Name] {
+			t.Errorf("report %q defined twice", r.Name)
+		}
+		seen[r.Name] = true
+		highest := 0
+		for _, m := range placeholder.FindAllStringSubmatch(r.SQL, -1) {
+			n, _ := strconv.Atoi(m[1])
+			highest = max(highest, n)
+		}
+		if highest != len(r.Params) {
+			t.Errorf("%s: SQL binds $%d but takes %d params", r.Name, highest, len(r.Params))
+		}
+		for _, p := range r.Params {
+			switch p.Type {
+			case TypeText, TypeUUID, TypeInt, TypeTimestamp:
+			default:
+				t.Errorf("%s: param %s has unknown type %q", r.Name, p.Name, p.Type)
+			}
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	r, err := Find("user_payouts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := r.Args(map[string]string{"user_id": "8a0f5b8e-1c1c-4b8e-9d6e-0c1d2e3f4a5b", "since": "2026-01-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if since, ok := args[1].(time.Time); !ok || since.Day() != 31 {
+		t.Fatalf("since = %#v", args[1])
+	}
+	if args, _ := r.Args(map[string]string{"user_id": "8a0f5b8e-1c1c-4b8e-9d6e-0c1d2e3f4a5b"}); args[1] != nil {
+		t.Fatalf("omitted optional param bound %#v", args[1])
+	}
+
+	var perr *ParamError
+	if _, err := r.Args(map[string]string{}); !errors.As(err, &perr) || perr.Param != "user_id" || !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("missing required param: %v", err)
+	}
+	if _, err := r.Args(map[string]string{"user_id": "x"}); !errors.As(err, &perr) || perr.Param != "user_id" {
+		t.Fatalf("bad uuid: %v", err)
+	}
+
+	stuck, _ := Find("stuck_payouts")
+	if args, err := stuck.Args(nil); err != nil || args[0] != int64(24) {
+		t.Fatalf("default = %v, %v", args, err)
+	}
+	if _, err := Find("drop_tables"); !errors.Is(err, ErrUnknownReport) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	for v, want := range map[any]string{
+		nil:             "",
+		"plain":         "plain",
+		"=HYPERLINK(1)": "'=HYPERLINK(1)",
+		"@sum":          "'@sum",
+		int64(-3):       "-3",
+	} {
+		if got := String(v); got != want {
+			t.Errorf("String(%#v) = %q, want %q", v, got, want)
+		}
+	}
+}
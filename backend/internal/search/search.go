@@ -0,0 +1,162 @@
+// Package search finds public projects and bounties by Postgres full-text
+// search over the weighted search_vector columns triggers keep current.
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+)
+
+// Result types.
+const (
+	TypeProject = "project"
+	TypeBounty  = "bounty"
+)
+
+// Types are the result types, in the order searched by default.
+var Types = []string{TypeProject, TypeBounty}
+
+const (
+	MaxQueryLen = 200
+	MaxPage     = 50
+)
+
+var (
+	ErrEmptyQuery   = errors.New("empty_query")
+	ErrQueryTooLong = errors.New("query_too_long")
+	ErrInvalidType  = errors.New("invalid_type")
+)
+
+// Query is one search request. Q uses web search syntax: quoted phrases,
+// "or" and -excluded words.
+type Query struct {
+	Q     string
+	Types []string
+	// BountyStatus, when set, keeps only bounties in that status.
+	BountyStatus string
+	Limit        int
+	Offset       int
+}
+
+// Result is a project or bounty matching a query, best matches first.
+type Result struct {
+	Type      string    `json:"type"`
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	// Project is the project's owner/repo name.
+	Project string `json:"project"`
+	Title   string `json:"title"`
+	// Snippet is HTML-escaped text around the matches, which are wrapped in
+	// <mark>.
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+	// Bounty fields.
+	Status    *string   `json:"status,omitempty"`
+	Chain     *string   `json:"chain,omitempty"`
+	Asset     *string   `json:"asset,omitempty"`
+	Amount    *string   `json:"amount,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// plurals are also accepted in ?type=.
+var plurals = map[string]string{"projects": TypeProject, "bounties": TypeBounty}
+
+// ParseTypes parses a comma-separated ?type=; empty means all of Types.
+func ParseTypes(s string) ([]string, error) {
+	var out []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if one, ok := plurals[t]; ok {
+			t = one
+		}
+		if t == "" {
+			continue
+		}
+		if !slices.Contains(Types, t) {
+			return nil, ErrInvalidType
+		}
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	if len(out) == 0 {
+		return Types, nil
+	}
+	return out, nil
+}
+
+// headlineOptions mark matches in snippets and keep them short.
+const headlineOptions = `StartSel=<mark>, StopSel=</mark>, MinWords=8, MaxWords=24, MaxFragments=2, FragmentDelimiter=" … "`
+
+// escapeHTML escapes the SQL text expr before ts_headline adds its marks.
+func escapeHTML(expr string) string {
+	return `replace(replace(replace(` + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+}
+
+// Search runs q over verified projects and public bounties.
+func Search(ctx context.Context, pool *pgxpool.Pool, q Query) ([]Result, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	q.Q = strings.TrimSpace(q.Q)
+	if q.Q == "" {
+		return nil, ErrEmptyQuery
+	}
+	if len(q.Q) > MaxQueryLen {
+		return nil, ErrQueryTooLong
+	}
+	if len(q.Types) == 0 {
+		q.Types = Types
+	}
+	if q.Limit <= 0 || q.Limit > MaxPage {
+		q.Limit = 20
+	}
+	rows, err := pool.Query(ctx, `
+WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query)
+SELECT type, id, project_id, project, title, snippet, rank, status, chain, asset, amount, created_at
+FROM (
+  SELECT 'project' AS type, p.id, p.id AS project_id, p.github_full_name AS project, p.github_full_name AS title,
+         ts_headline('english', `+escapeHTML(`COALESCE(NULLIF(p.description, ''), p.github_full_name)`)+`, q.query, $6) AS snippet,
+         ts_rank(p.search_vector, q.query)::float8 AS rank,
+         NULL::text AS status, NULL::text AS chain, NULL::text AS asset, NULL::text AS amount, p.created_at
+  FROM projects p, q
+  WHERE 'project' = ANY($2) AND p.search_vector @@ q.query
+    AND p.status = 'verified' AND p.deleted_at IS NULL
+  UNION ALL
+  SELECT 'bounty', b.id, b.project_id, p.github_full_name, COALESCE(b.issue_title, b.issue_key),
+         ts_headline('english', `+escapeHTML(`COALESCE(b.issue_title, b.issue_key)`)+`, q.query, $6),
+         ts_rank(b.search_vector, q.query)::float8,
+         b.status, b.chain, b.asset, b.amount::text, b.created_at
+  FROM bounties b
+  JOIN projects p ON p.id = b.project_id, q
+  WHERE 'bounty' = ANY($2) AND b.search_vector @@ q.query
+    AND `+bounties.Listed("b")+` AND p.deleted_at IS NULL
+    AND ($3 = '' OR b.status = $3)
+) r
+ORDER BY rank DESC, created_at DESC, id
+LIMIT $4 OFFSET $5
+`, q.Q, q.Types, q.BountyStatus, q.Limit, q.Offset, headlineOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Result{}
+	for rows.Next() {
+		var r Result
+		if err := rows.Scan(&r.Type, &r.ID, &r.ProjectID, &r.Project, &r.Title, &r.Snippet, &r.Rank,
+			&r.Status, &r.Chain, &r.Asset, &r.Amount, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,24 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTypes(t *testing.T) {
+	for in, want := range map[string][]string{
+		"":                        Types,
+		"bounty":                  {TypeBounty},
+		" Bounties , project ":    {TypeBounty, TypeProject},
+		"project,projects,bounty": {TypeProject, TypeBounty},
+	} {
+		got, err := ParseTypes(in)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseTypes(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseTypes("project,user"); !errors.Is(err, ErrInvalidType) {
+		t.Errorf("unknown type: err = %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Package shed rejects low-priority requests with 503 + Retry-After while
+// the Postgres pool is saturated, so auth and payouts keep their connections.
+package shed
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/httpx"
+)
+
+// Priority is the tag a route carries.
+type Priority int
+
+const (
+	// Low routes (public lists, exports) are shed first.
+	Low Priority = iota
+	// Normal routes are shed only under severe saturation.
+	Normal
+	// Critical routes (auth, payouts) are never shed.
+	Critical
+)
+
+// Level is the pool saturation the shedder last observed.
+type Level int
+
+const (
+	OK Level = iota
+	Elevated
+	Severe
+)
+
+// sampleEvery is how often pool stats are re-read.
+const sampleEvery = time.Second
+
+// Thresholds mark Elevated; twice either threshold is Severe.
+type Thresholds struct {
+	// Wait is the average time to acquire a connection.
+	Wait time.Duration
+	// QueuePerSecond is how many acquires per second had to wait for a connection.
+	QueuePerSecond float64
+}
+
+// Sample is a reading of the pool's cumulative acquire counters.
+type Sample struct {
+	At                time.Time
+	AcquireCount      int64
+	AcquireDuration   time.Duration
+	EmptyAcquireCount int64
+}
+
+// Evaluate grades the pool between two samples.
+func Evaluate(prev, cur Sample, th Thresholds) Level {
+	elapsed := cur.At.Sub(prev.At).Seconds()
+	if elapsed <= 0 {
+		return OK
+	}
+	var wait time.Duration
+	if n := cur.AcquireCount - prev.AcquireCount; n > 0 {
+		wait = (cur.AcquireDuration - prev.AcquireDuration) / time.Duration(n)
+	}
+	queue := float64(cur.EmptyAcquireCount-prev.EmptyAcquireCount) / elapsed
+
+	over := func(factor float64) bool {
+		return (th.Wait > 0 && float64(wait) >= factor*float64(th.Wait)) ||
+			(th.QueuePerSecond > 0 && queue >= factor*th.QueuePerSecond)
+	}
+	switch {
+	case over(2):
+		return Severe
+	case over(1):
+		return Elevated
+	}
+	return OK
+}
+
+// Shedder tracks pool saturation and guards tagged routes. A nil Shedder
+// admits everything.
+type Shedder struct {
+	pool       *pgxpool.Pool
+	th         Thresholds
+	retryAfter string
+
+	mu    sync.Mutex
+	last  Sample
+	level Level
+}
+
+// New returns nil (shedding disabled) without a pool or thresholds.
+func New(pool *pgxpool.Pool, th Thresholds, retryAfter time.Duration) *Shedder {
+	if pool == nil || (th.Wait <= 0 && th.QueuePerSecond <= 0) {
+		return nil
+	}
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	s := &Shedder{pool: pool, th: th, retryAfter: strconv.Itoa(int(retryAfter.Seconds()))}
+	s.last = s.read(time.Now())
+	return s
+}
+
+func (s *Shedder) read(now time.Time) Sample {
+	st := s.pool.Stat()
+	return Sample{
+		At:                now,
+		AcquireCount:      st.AcquireCount(),
+		AcquireDuration:   st.AcquireDuration(),
+		EmptyAcquireCount: st.EmptyAcquireCount(),
+	}
+}
+
+// Level re-samples the pool at most once per sampleEvery.
+func (s *Shedder) Level() Level {
+	if s == nil {
+		return OK
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.last.At) >= sampleEvery {
+		cur := s.read(now)
+		s.level = Evaluate(s.last, cur, s.th)
+		s.last = cur
+	}
+	return s.level
+}
+
+func admits(p Priority, l Level) bool {
+	switch p {
+	case Critical:
+		return true
+	case Normal:
+		return l < Severe
+	}
+	return l == OK
+}
+
+// Tag marks a route with its priority and sheds it when the pool is too busy.
+func (s *Shedder) Tag(p Priority) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if p == Critical || s == nil || admits(p, s.Level()) {
+			return c.Next()
+		}
+		c.Set(fiber.HeaderRetryAfter, s.retryAfter)
+		return httpx.Fail(c, fiber.StatusServiceUnavailable, "overloaded")
+	}
+}
@@ -0,0 +1,41 @@
+package shed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	th := Thresholds{Wait: 50 * time.Millisecond, QueuePerSecond: 10}
+	t0 := time.Unix(0, 0)
+	prev := Sample{At: t0, AcquireCount: 100, AcquireDuration: time.Second, EmptyAcquireCount: 5}
+	cases := []struct {
+		name string
+		cur  Sample
+		want Level
+	}{
+		{"idle", Sample{At: t0.Add(time.Second), AcquireCount: 100, AcquireDuration: time.Second, EmptyAcquireCount: 5}, OK},
+		{"fast", Sample{At: t0.Add(time.Second), AcquireCount: 200, AcquireDuration: 2 * time.Second, EmptyAcquireCount: 8}, OK},
+		{"slow waits", Sample{At: t0.Add(time.Second), AcquireCount: 110, AcquireDuration: time.Second + 600*time.Millisecond, EmptyAcquireCount: 5}, Elevated},
+		{"very slow waits", Sample{At: t0.Add(time.Second), AcquireCount: 110, AcquireDuration: 3 * time.Second, EmptyAcquireCount: 5}, Severe},
+		{"queueing", Sample{At: t0.Add(2 * time.Second), AcquireCount: 150, AcquireDuration: 2 * time.Second, EmptyAcquireCount: 30}, Elevated},
+		{"queue normalised by time", Sample{At: t0.Add(10 * time.Second), AcquireCount: 150, AcquireDuration: 2 * time.Second, EmptyAcquireCount: 30}, OK},
+	}
+	for _, tc := range cases {
+		if got := Evaluate(prev, tc.cur, th); got != tc.want {
+			t.Errorf("%s: Evaluate = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAdmits(t *testing.T) {
+	if !admits(Low, OK) || admits(Low, Elevated) {
+		t.Error("low priority should only be admitted when OK")
+	}
+	if !admits(Normal, Elevated) || admits(Normal, Severe) {
+		t.Error("normal priority should be shed only when severe")
+	}
+	if !admits(Critical, Severe) {
+		t.Error("critical priority must never be shed")
+	}
+}
@@ -0,0 +1,176 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// DetectTTL is how long a project's detected skills are reused.
+const DetectTTL = 24 * time.Hour
+
+// Detector detects and caches the skills of registered projects, reading
+// GitHub with the project owner's linked token (or anonymously).
+type Detector struct {
+	Pool           *pgxpool.Pool
+	GitHub         *github.Client
+	TokenEncKeyB64 string
+	// Installations, when set, reads repos through the GitHub App.
+	Installations *github.InstallationTokens
+}
+
+// ForProject returns projectID's skill tags, detecting them again once the
+// cached ones are older than DetectTTL. When GitHub can't be read it falls
+// back to the project's recorded language.
+func (d *Detector) ForProject(ctx context.Context, projectID uuid.UUID) ([]string, error) {
+	if d.Pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	var (
+		fullName   string
+		ownerID    uuid.UUID
+		language   *string
+		cached     []string
+		detectedAt *time.Time
+	)
+	if err := d.Pool.QueryRow(ctx, `
+SELECT github_full_name, owner_user_id, language, detected_skills, skills_detected_at
+FROM projects
+WHERE id = $1
+`, projectID).Scan(&fullName, &ownerID, &language, &cached, &detectedAt); err != nil {
+		return nil, err
+	}
+	if detectedAt != nil && time.Since(*detectedAt) < DetectTTL {
+		return cached, nil
+	}
+
+	tags, err := d.detect(ctx, fullName, ownerID, "")
+	if err != nil {
+		slog.Warn("skill detection failed", "project_id", projectID, "github_full_name", fullName, "error", err)
+		if cached != nil {
+			return cached, nil
+		}
+		if language != nil && *language != "" {
+			return []string{LanguageTag(*language)}, nil
+		}
+		return []string{}, nil
+	}
+	if _, err := d.Pool.Exec(ctx, `
+UPDATE projects SET detected_skills = $2, skills_detected_at = now() WHERE id = $1
+`, projectID, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ForPath returns the skill tags of the directory prefix of projectID's
+// repo, as for a bounty scoped to it (see bounties.NormalizePath), from the
+// languages of the files under it and the manifests at its top. Subtrees
+// aren't cached. When GitHub can't be read it falls back to the whole
+// project's tags; "" is the whole project.
+func (d *Detector) ForPath(ctx context.Context, projectID uuid.UUID, prefix string) ([]string, error) {
+	if prefix == "" {
+		return d.ForProject(ctx, projectID)
+	}
+	if d.Pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	var (
+		fullName string
+		ownerID  uuid.UUID
+	)
+	if err := d.Pool.QueryRow(ctx, `SELECT github_full_name, owner_user_id FROM projects WHERE id = $1`, projectID).Scan(&fullName, &ownerID); err != nil {
+		return nil, err
+	}
+	tags, err := d.detect(ctx, fullName, ownerID, prefix)
+	if err != nil {
+		slog.Warn("skill detection failed", "project_id", projectID, "github_full_name", fullName, "path_prefix", prefix, "error", err)
+		return d.ForProject(ctx, projectID)
+	}
+	return tags, nil
+}
+
+// detect reads the skills of fullName's directory prefix, "" being the
+// whole repo.
+func (d *Detector) detect(ctx context.Context, fullName string, ownerID uuid.UUID, prefix string) ([]string, error) {
+	gh := d.GitHub
+	if gh == nil {
+		gh = github.NewClient()
+	}
+	// Public repos can still be read anonymously.
+	tokens := github.Tokens{Pool: d.Pool, TokenEncKeyB64: d.TokenEncKeyB64, Installations: d.Installations}
+	token, _ := tokens.Repo(fullName, ownerID).Token(ctx)
+
+	var languages map[string]int64
+	if prefix == "" {
+		var err error
+		if languages, err = gh.GetRepoLanguages(ctx, token, fullName); err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := gh.ListFiles(ctx, token, fullName, prefix)
+		if err != nil {
+			return nil, err
+		}
+		sizes := make(map[string]int64, len(entries))
+		for _, e := range entries {
+			sizes[e.Path] = e.Size
+		}
+		languages = FileLanguages(sizes)
+	}
+	root, err := gh.ListDir(ctx, token, fullName, prefix)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, e := range root {
+		if e.Type != "file" {
+			continue
+		}
+		if _, ok := manifests[e.Name]; !ok {
+			continue
+		}
+		content, err := gh.GetFile(ctx, token, fullName, e.Path)
+		if err != nil {
+			// A manifest we can't read only costs its framework tags.
+			slog.Warn("skill detection: manifest unreadable", "github_full_name", fullName, "path", e.Path, "error", err)
+			continue
+		}
+		files[e.Name] = content
+	}
+	tags := Detect(languages, files)
+	if tags == nil {
+		tags = []string{}
+	}
+	return tags, nil
+}
+
+// detectTimeout bounds detection while a bounty is being created.
+const detectTimeout = 5 * time.Second
+
+// TagBounty applies the detected skills of the project, or of the directory
+// the bounty is scoped to, to a new bounty. It is best-effort: on failure b
+// is returned untagged.
+func (d *Detector) TagBounty(ctx context.Context, b bounties.Bounty) bounties.Bounty {
+	if b.SkillTagsOverridden {
+		return b
+	}
+	ctx, cancel := context.WithTimeout(ctx, detectTimeout)
+	defer cancel()
+	tags, err := d.ForPath(ctx, b.ProjectID, b.PathPrefix)
+	if err == nil {
+		var tagged bounties.Bounty
+		if tagged, err = bounties.SetSkillTags(ctx, d.Pool, b.ProjectID, b.ID, uuid.Nil, tags, false); err == nil {
+			return tagged
+		}
+	}
+	slog.Warn("bounty skill tagging failed", "bounty_id", b.ID, "project_id", b.ProjectID, "error", err)
+	return b
+}
@@ -0,0 +1,36 @@
+package skills
+
+import (
+	"path"
+	"strings"
+)
+
+// extensionLanguages maps file extensions to GitHub linguist names, for
+// breaking down a part of a repo GitHub's language stats don't cover, such
+// as one package of a monorepo. It only knows the common languages; other
+// files are left out.
+var extensionLanguages = map[string]string{
+	".go": "Go", ".rs": "Rust", ".py": "Python", ".rb": "Ruby", ".php": "PHP",
+	".js": "JavaScript", ".jsx": "JavaScript", ".mjs": "JavaScript", ".cjs": "JavaScript",
+	".ts": "TypeScript", ".tsx": "TypeScript", ".mts": "TypeScript", ".cts": "TypeScript",
+	".vue": "Vue", ".svelte": "Svelte", ".html": "HTML", ".css": "CSS", ".scss": "SCSS",
+	".java": "Java", ".kt": "Kotlin", ".kts": "Kotlin", ".scala": "Scala", ".swift": "Swift",
+	".c": "C", ".h": "C", ".cc": "C++", ".cpp": "C++", ".cxx": "C++", ".hpp": "C++",
+	".cs": "C#", ".fs": "F#", ".dart": "Dart", ".ex": "Elixir", ".exs": "Elixir",
+	".erl": "Erlang", ".hs": "Haskell", ".ml": "OCaml", ".clj": "Clojure", ".lua": "Lua",
+	".zig": "Zig", ".nim": "Nim", ".r": "R", ".jl": "Julia", ".ipynb": "Jupyter Notebook",
+	".sol": "Solidity", ".move": "Move", ".cairo": "Cairo", ".vy": "Vyper",
+	".sh": "Shell", ".bash": "Shell", ".ps1": "PowerShell",
+}
+
+// FileLanguages estimates a bytes-per-language breakdown, like GitHub's,
+// from a listing of file paths and their sizes.
+func FileLanguages(files map[string]int64) map[string]int64 {
+	out := map[string]int64{}
+	for name, size := range files {
+		if lang, ok := extensionLanguages[strings.ToLower(path.Ext(name))]; ok && size > 0 {
+			out[lang] += size
+		}
+	}
+	return out
+}
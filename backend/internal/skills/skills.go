@@ -0,0 +1,227 @@
+// Package skills detects the languages and frameworks a repository, or one
+// directory of a monorepo, uses from its language breakdown and the manifest
+// files at its top, and turns them into the skill tags applied to bounties.
+package skills
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxTags caps the tags on one bounty.
+	MaxTags = 10
+	// maxTagLen caps a single tag.
+	maxTagLen = 32
+	// maxLanguages is how many languages detection keeps, and minLanguageShare
+	// the share of the repo's code below which a language is left out (the
+	// top language is always kept).
+	maxLanguages     = 3
+	minLanguageShare = 0.1
+)
+
+var (
+	ErrInvalidTag  = errors.New("invalid_skill_tag")
+	ErrTooManyTags = errors.New("too_many_skill_tags")
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.+#-]*$`)
+
+// Normalize validates and deduplicates maintainer-supplied tags, keeping
+// their order.
+func Normalize(tags []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if len(t) > maxTagLen || !tagPattern.MatchString(t) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTag, t)
+		}
+		if _, dup := seen[t]; dup {
+			continue
+		}
+		seen[t] = struct{}{}
+		out = append(out, t)
+	}
+	if len(out) > MaxTags {
+		return nil, ErrTooManyTags
+	}
+	return out, nil
+}
+
+// ignoredLanguages are build and config languages that say little about the
+// skills a bounty needs.
+var ignoredLanguages = map[string]bool{
+	"Makefile": true, "Dockerfile": true, "Shell": true, "Batchfile": true,
+	"PowerShell": true, "Procfile": true, "CMake": true, "M4": true,
+	"Roff": true, "Nix": true, "Just": true, "Starlark": true,
+}
+
+// LanguageTag turns a GitHub linguist name into a tag: "C++" is "c++",
+// "Jupyter Notebook" is "jupyter-notebook".
+func LanguageTag(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
+
+// manifest describes one root file: the tags its presence implies, and
+// the tags implied by dependencies named in it.
+type manifest struct {
+	tags []string
+	deps map[string]string
+	// packageJSON manifests are parsed; others are searched as text.
+	packageJSON bool
+}
+
+var manifests = map[string]manifest{
+	"package.json": {packageJSON: true, deps: map[string]string{
+		"react": "react", "next": "nextjs", "vue": "vue", "nuxt": "nuxt",
+		"svelte": "svelte", "@angular/core": "angular", "react-native": "react-native",
+		"electron": "electron", "express": "express", "@nestjs/core": "nestjs",
+		"tailwindcss": "tailwind", "typescript": "typescript",
+		"hardhat": "hardhat", "ethers": "ethers", "viem": "viem", "wagmi": "wagmi",
+		"@solana/web3.js": "solana", "@coral-xyz/anchor": "anchor",
+		"@stellar/stellar-sdk": "stellar", "stellar-sdk": "stellar",
+	}},
+	"go.mod": {tags: []string{"go"}, deps: map[string]string{
+		"github.com/gin-gonic/gin": "gin", "github.com/gofiber/fiber": "fiber",
+		"github.com/labstack/echo": "echo", "github.com/cosmos/cosmos-sdk": "cosmos-sdk",
+		"github.com/ethereum/go-ethereum": "go-ethereum",
+	}},
+	"Cargo.toml": {tags: []string{"rust"}, deps: map[string]string{
+		"soroban-sdk": "soroban", "anchor-lang": "anchor", "solana-program": "solana",
+		"ink": "ink", "frame-support": "substrate", "tokio": "tokio",
+		"actix-web": "actix", "axum": "axum", "bevy": "bevy", "tauri": "tauri",
+	}},
+	"requirements.txt":  {tags: []string{"python"}, deps: pythonDeps},
+	"pyproject.toml":    {tags: []string{"python"}, deps: pythonDeps},
+	"Gemfile":           {tags: []string{"ruby"}, deps: map[string]string{"rails": "rails"}},
+	"composer.json":     {tags: []string{"php"}, deps: map[string]string{"laravel/framework": "laravel", "symfony/framework-bundle": "symfony"}},
+	"pom.xml":           {tags: []string{"java"}, deps: map[string]string{"spring-boot": "spring"}},
+	"build.gradle":      {deps: map[string]string{"spring-boot": "spring", "com.android": "android"}},
+	"build.gradle.kts":  {tags: []string{"kotlin"}, deps: map[string]string{"spring-boot": "spring", "com.android": "android"}},
+	"pubspec.yaml":      {tags: []string{"dart"}, deps: map[string]string{"flutter": "flutter"}},
+	"foundry.toml":      {tags: []string{"solidity", "foundry"}},
+	"hardhat.config.js": {tags: []string{"solidity", "hardhat"}},
+	"hardhat.config.ts": {tags: []string{"solidity", "hardhat"}},
+	"Move.toml":         {tags: []string{"move"}},
+	"Scarb.toml":        {tags: []string{"cairo"}},
+	"Dockerfile":        {tags: []string{"docker"}},
+}
+
+var pythonDeps = map[string]string{
+	"django": "django", "flask": "flask", "fastapi": "fastapi",
+	"torch": "pytorch", "tensorflow": "tensorflow", "web3": "web3py",
+}
+
+// Detect returns the tags for a repo: its main languages, largest first,
+// then the frameworks its manifests name, alphabetically. languages is
+// GitHub's bytes-per-language breakdown and files maps manifest names to
+// their contents.
+func Detect(languages map[string]int64, files map[string][]byte) []string {
+	var out []string
+	seen := map[string]bool{}
+	add := func(tag string) {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+
+	type lang struct {
+		name  string
+		bytes int64
+	}
+	var langs []lang
+	var total int64
+	for name, n := range languages {
+		if n > 0 && !ignoredLanguages[name] {
+			langs = append(langs, lang{name, n})
+			total += n
+		}
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if langs[i].bytes != langs[j].bytes {
+			return langs[i].bytes > langs[j].bytes
+		}
+		return langs[i].name < langs[j].name
+	})
+	for i, l := range langs {
+		if i >= maxLanguages || (i > 0 && float64(l.bytes) < minLanguageShare*float64(total)) {
+			break
+		}
+		add(LanguageTag(l.name))
+	}
+
+	var frameworks []string
+	for name, content := range files {
+		m, ok := manifests[name]
+		if !ok {
+			continue
+		}
+		frameworks = append(frameworks, m.tags...)
+		if m.packageJSON {
+			frameworks = append(frameworks, packageJSONTags(content, m.deps)...)
+			continue
+		}
+		text := strings.ToLower(string(content))
+		for dep, tag := range m.deps {
+			if mentions(text, dep) {
+				frameworks = append(frameworks, tag)
+			}
+		}
+	}
+	sort.Strings(frameworks)
+	for _, t := range frameworks {
+		add(t)
+	}
+
+	if len(out) > MaxTags {
+		out = out[:MaxTags]
+	}
+	return out
+}
+
+func packageJSONTags(content []byte, deps map[string]string) []string {
+	var pkg struct {
+		Dependencies     map[string]string `json:"dependencies"`
+		DevDependencies  map[string]string `json:"devDependencies"`
+		PeerDependencies map[string]string `json:"peerDependencies"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil
+	}
+	var out []string
+	for dep, tag := range deps {
+		_, a := pkg.Dependencies[dep]
+		_, b := pkg.DevDependencies[dep]
+		_, c := pkg.PeerDependencies[dep]
+		if a || b || c {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// mentions reports whether dep appears in text as a whole name, so "ink"
+// doesn't match "link".
+func mentions(text, dep string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], dep)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(dep)
+		if (start == 0 || !nameByte(text[start-1])) && (end == len(text) || !nameByte(text[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func nameByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '_' || b == '-'
+}
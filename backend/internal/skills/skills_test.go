@@ -0,0 +1,77 @@
+package skills
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	languages := map[string]int64{
+		"TypeScript": 70_000,
+		"Rust":       25_000,
+		"Shell":      40_000,
+		"CSS":        3_000,
+		"HTML":       2_000,
+	}
+	files := map[string][]byte{
+		"package.json": []byte(`{"dependencies":{"react":"^18","next":"14"},"devDependencies":{"typescript":"5"}}`),
+		"Cargo.toml":   []byte("[dependencies]\nsoroban-sdk = \"21\"\nink_env = \"5\"\n"),
+		"README.md":    []byte("react"),
+	}
+	got := Detect(languages, files)
+	want := []string{"typescript", "rust", "nextjs", "react", "soroban"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Detect = %v, want %v", got, want)
+	}
+}
+
+func TestDetectEmpty(t *testing.T) {
+	if got := Detect(nil, map[string][]byte{"package.json": []byte("not json")}); len(got) != 0 {
+		t.Fatalf("Detect = %v, want none", got)
+	}
+}
+
+func TestFileLanguages(t *testing.T) {
+	got := FileLanguages(map[string]int64{
+		"packages/api/main.go":       900,
+		"packages/api/db/store.go":   600,
+		"packages/api/schema.SQL":    300,
+		"packages/api/web/index.TSX": 400,
+		"packages/api/empty.ts":      0,
+		"packages/api/Makefile":      100,
+	})
+	want := map[string]int64{"Go": 1500, "TypeScript": 400}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FileLanguages = %v, want %v", got, want)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	if !mentions("require github.com/gofiber/fiber/v2 v2.52.0", "github.com/gofiber/fiber") {
+		t.Error("missed go module")
+	}
+	if mentions("link = \"1\"", "ink") {
+		t.Error("matched inside another name")
+	}
+	if mentions("ink-wrapper = \"1\"", "ink") {
+		t.Error("matched a prefix")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize([]string{" Go ", "c++", "go", "Next.js"})
+	if err != nil || !reflect.DeepEqual(got, []string{"go", "c++", "next.js"}) {
+		t.Fatalf("Normalize = %v, %v", got, err)
+	}
+	if _, err := Normalize([]string{"two words"}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("Normalize(two words) err = %v", err)
+	}
+	many := make([]string, MaxTags+1)
+	for i := range many {
+		many[i] = string(rune('a' + i))
+	}
+	if _, err := Normalize(many); !errors.Is(err, ErrTooManyTags) {
+		t.Errorf("Normalize(%d tags) err = %v", len(many), err)
+	}
+}
@@ -0,0 +1,202 @@
+// Package slack is the Grainlify Slack app: per-workspace OAuth installs,
+// bounty slash commands (parsed by package commands) and channel
+// notifications fed from bounty_events.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BotScopes are the bot token scopes requested at install.
+const BotScopes = "commands,chat:write,chat:write.public"
+
+// maxRequestSkew bounds X-Slack-Request-Timestamp to reject replays.
+const maxRequestSkew = 5 * time.Minute
+
+var (
+	ErrBadSignature = errors.New("invalid_signature")
+	ErrNotInstalled = errors.New("slack_not_installed")
+)
+
+// Client talks to the Slack Web API for one app.
+type Client struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	HTTP         *http.Client
+}
+
+func NewClient(clientID, clientSecret, redirectURL string) *Client {
+	return &Client{ClientID: clientID, ClientSecret: clientSecret, RedirectURL: redirectURL, HTTP: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *Client) AuthorizeURL(state string) string {
+	q := url.Values{}
+	q.Set("client_id", c.ClientID)
+	q.Set("scope", BotScopes)
+	q.Set("redirect_uri", c.RedirectURL)
+	q.Set("state", state)
+	return "https://slack.com/oauth/v2/authorize?" + q.Encode()
+}
+
+// Install is the result of oauth.v2.access.
+type Install struct {
+	TeamID        string
+	TeamName      string
+	BotUserID     string
+	BotToken      string
+	InstallerUser string
+}
+
+// apiResponse is the envelope shared by every Web API method.
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+func (c *Client) call(ctx context.Context, method string, body []byte, contentType, token string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://slack.com/api/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack %s: status %d", method, resp.StatusCode)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	var env apiResponse
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return err
+	}
+	if !env.OK {
+		return fmt.Errorf("slack %s: %s", method, env.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// Exchange completes the OAuth install.
+func (c *Client) Exchange(ctx context.Context, code string) (Install, error) {
+	form := url.Values{
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {c.RedirectURL},
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		BotUserID   string `json:"bot_user_id"`
+		Team        struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"team"`
+		AuthedUser struct {
+			ID string `json:"id"`
+		} `json:"authed_user"`
+	}
+	if err := c.call(ctx, "oauth.v2.access", []byte(form.Encode()), "application/x-www-form-urlencoded", "", &resp); err != nil {
+		return Install{}, err
+	}
+	if resp.AccessToken == "" || resp.Team.ID == "" {
+		return Install{}, fmt.Errorf("slack install returned no bot token")
+	}
+	return Install{
+		TeamID:        resp.Team.ID,
+		TeamName:      resp.Team.Name,
+		BotUserID:     resp.BotUserID,
+		BotToken:      resp.AccessToken,
+		InstallerUser: resp.AuthedUser.ID,
+	}, nil
+}
+
+// PostMessage posts text to a channel as the bot.
+func (c *Client) PostMessage(ctx context.Context, botToken, channel, text string) error {
+	body, err := json.Marshal(map[string]any{"channel": channel, "text": text, "unfurl_links": false})
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, "chat.postMessage", body, "application/json; charset=utf-8", botToken, nil)
+}
+
+// Respond posts a delayed reply to a slash command's response_url.
+func (c *Client) Respond(ctx context.Context, responseURL string, msg Message) error {
+	if !strings.HasPrefix(responseURL, "https://hooks.slack.com/") {
+		return fmt.Errorf("unexpected response_url")
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack response_url: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Message is a slash command reply.
+type Message struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// Ephemeral replies are only shown to the user who ran the command.
+func Ephemeral(text string) Message { return Message{ResponseType: "ephemeral", Text: text} }
+
+// InChannel replies are shown to everyone in the channel.
+func InChannel(text string) Message { return Message{ResponseType: "in_channel", Text: text} }
+
+// VerifyRequest checks Slack's v0 request signature over the raw body.
+func VerifyRequest(signingSecret string, header http.Header, body []byte, now time.Time) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrBadSignature
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > maxRequestSkew || skew < -maxRequestSkew {
+		return ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	_, _ = mac.Write([]byte("v0:" + ts + ":"))
+	_, _ = mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(want)) {
+		return ErrBadSignature
+	}
+	return nil
+}
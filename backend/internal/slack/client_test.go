@@ -0,0 +1,41 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyRequest(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte("team_id=T1&text=list")
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	h := http.Header{}
+	h.Set("X-Slack-Request-Timestamp", ts)
+	h.Set("X-Slack-Signature", sign("s3cret", ts, body))
+	if err := VerifyRequest("s3cret", h, body, now); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+	if err := VerifyRequest("other", h, body, now); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("wrong secret: got %v", err)
+	}
+	if err := VerifyRequest("s3cret", h, []byte("team_id=T1&text=create"), now); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered body: got %v", err)
+	}
+	if err := VerifyRequest("s3cret", h, body, now.Add(10*time.Minute)); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("stale timestamp: got %v", err)
+	}
+}
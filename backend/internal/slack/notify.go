@@ -0,0 +1,100 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/notify"
+)
+
+// Notifier posts new bounty_events to subscribed channels, advancing each
+// subscription's cursor only after Slack accepts the message.
+type Notifier struct {
+	Pool           *pgxpool.Pool
+	Slack          *Client
+	TokenEncKeyB64 string
+}
+
+type pendingSubscription struct {
+	Subscription
+	cursor      int64
+	installedBy uuid.UUID
+	token       []byte
+}
+
+// RunOnce delivers pending events and returns how many were posted.
+func (n *Notifier) RunOnce(ctx context.Context) (int, error) {
+	if n.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := n.Pool.Query(ctx, `
+SELECT s.id, s.installation_id, s.channel_id, s.project_id, s.event_types, s.created_at, s.cursor, i.installed_by, i.bot_token
+FROM slack_subscriptions s
+JOIN slack_installations i ON i.id = s.installation_id
+ORDER BY s.created_at
+`)
+	if err != nil {
+		return 0, err
+	}
+	var subs []pendingSubscription
+	for rows.Next() {
+		var p pendingSubscription
+		if err := rows.Scan(&p.ID, &p.InstallationID, &p.ChannelID, &p.ProjectID, &p.EventTypes, &p.CreatedAt, &p.cursor, &p.installedBy, &p.token); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		subs = append(subs, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, s := range subs {
+		count, err := n.deliver(ctx, s)
+		posted += count
+		if err != nil {
+			slog.Warn("slack notification delivery failed", "subscription_id", s.ID.String(), "channel_id", s.ChannelID, "error", err)
+		}
+	}
+	return posted, nil
+}
+
+func (n *Notifier) deliver(ctx context.Context, s pendingSubscription) (int, error) {
+	f := bounties.EventFilter{Types: s.EventTypes, Since: s.cursor, Limit: bounties.MaxEventsPage, Viewer: s.installedBy}
+	if s.ProjectID != nil {
+		f.ProjectID = s.ProjectID
+	} else {
+		f.OwnerID = &s.installedBy
+	}
+	events, err := bounties.ListEvents(ctx, n.Pool, f)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	token, err := decryptToken(n.TokenEncKeyB64, s.token)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	// ListEvents returns newest first; post oldest first.
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if err := n.Slack.PostMessage(ctx, token, s.ChannelID, notify.FormatEvent(e)); err != nil {
+			return posted, err
+		}
+		seq, _ := strconv.ParseInt(e.Cursor, 10, 64)
+		if _, err := n.Pool.Exec(ctx, `UPDATE slack_subscriptions SET cursor = $2 WHERE id = $1 AND cursor < $2`, s.ID, seq); err != nil {
+			return posted, err
+		}
+		posted++
+	}
+	return posted, nil
+}
@@ -0,0 +1,209 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+var ErrSubscriptionNotFound = errors.New("slack_subscription_not_found")
+
+// Installation is the app installed in one workspace. BotToken is only
+// populated by Installation lookups used to post, never serialized.
+type Installation struct {
+	ID                   uuid.UUID `json:"id"`
+	TeamID               string    `json:"team_id"`
+	TeamName             string    `json:"team_name"`
+	InstalledBy          uuid.UUID `json:"installed_by"`
+	InstallerSlackUserID string    `json:"installer_slack_user_id"`
+	CreatedAt            time.Time `json:"created_at"`
+	BotToken             string    `json:"-"`
+}
+
+const installationColumns = `id, team_id, team_name, installed_by, installer_slack_user_id, created_at`
+
+func scanInstallation(row pgx.Row) (Installation, error) {
+	var in Installation
+	err := row.Scan(&in.ID, &in.TeamID, &in.TeamName, &in.InstalledBy, &in.InstallerSlackUserID, &in.CreatedAt)
+	return in, err
+}
+
+// SaveInstallation stores an install, moving an already installed workspace
+// to the new installer.
+func SaveInstallation(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, in Install, tokenEncKeyB64 string) (Installation, error) {
+	if pool == nil {
+		return Installation{}, fmt.Errorf("db not configured")
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return Installation{}, err
+	}
+	token, err := cryptox.EncryptAESGCM(key, []byte(in.BotToken))
+	if err != nil {
+		return Installation{}, err
+	}
+	return scanInstallation(pool.QueryRow(ctx, `
+INSERT INTO slack_installations (team_id, team_name, bot_user_id, bot_token, installed_by, installer_slack_user_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (team_id) DO UPDATE SET
+  team_name = EXCLUDED.team_name,
+  bot_user_id = EXCLUDED.bot_user_id,
+  bot_token = EXCLUDED.bot_token,
+  installed_by = EXCLUDED.installed_by,
+  installer_slack_user_id = EXCLUDED.installer_slack_user_id,
+  updated_at = now()
+RETURNING `+installationColumns, in.TeamID, in.TeamName, in.BotUserID, token, userID, in.InstallerUser))
+}
+
+// ForTeam loads the installation for a workspace with its decrypted token.
+func ForTeam(ctx context.Context, pool *pgxpool.Pool, teamID, tokenEncKeyB64 string) (Installation, error) {
+	if pool == nil {
+		return Installation{}, fmt.Errorf("db not configured")
+	}
+	var (
+		in    Installation
+		token []byte
+	)
+	err := pool.QueryRow(ctx, `
+SELECT `+installationColumns+`, bot_token
+FROM slack_installations
+WHERE team_id = $1
+`, teamID).Scan(&in.ID, &in.TeamID, &in.TeamName, &in.InstalledBy, &in.InstallerSlackUserID, &in.CreatedAt, &token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Installation{}, ErrNotInstalled
+	}
+	if err != nil {
+		return Installation{}, err
+	}
+	if in.BotToken, err = decryptToken(tokenEncKeyB64, token); err != nil {
+		return Installation{}, err
+	}
+	return in, nil
+}
+
+func decryptToken(tokenEncKeyB64 string, b []byte) (string, error) {
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return "", err
+	}
+	out, err := cryptox.DecryptAESGCM(key, b)
+	if err != nil {
+		return "", fmt.Errorf("decrypt slack token failed")
+	}
+	return string(out), nil
+}
+
+func ListInstallations(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Installation, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+installationColumns+` FROM slack_installations WHERE installed_by = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Installation{}
+	for rows.Next() {
+		in, err := scanInstallation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, in)
+	}
+	return out, rows.Err()
+}
+
+// DeleteInstallation forgets a workspace; its subscriptions go with it.
+// The app stays installed in Slack until removed there.
+func DeleteInstallation(ctx context.Context, pool *pgxpool.Pool, userID, id uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM slack_installations WHERE id = $1 AND installed_by = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotInstalled
+	}
+	return nil
+}
+
+// Subscription routes bounty events to a channel.
+type Subscription struct {
+	ID             uuid.UUID  `json:"id"`
+	InstallationID uuid.UUID  `json:"installation_id"`
+	ChannelID      string     `json:"channel_id"`
+	ProjectID      *uuid.UUID `json:"project_id,omitempty"`
+	EventTypes     []string   `json:"event_types"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+const subscriptionColumns = `id, installation_id, channel_id, project_id, event_types, created_at`
+
+func scanSubscription(row pgx.Row) (Subscription, error) {
+	var s Subscription
+	err := row.Scan(&s.ID, &s.InstallationID, &s.ChannelID, &s.ProjectID, &s.EventTypes, &s.CreatedAt)
+	return s, err
+}
+
+// Subscribe starts (or updates) delivery to channelID. Delivery starts from
+// the current end of the feed; past events are not replayed.
+func Subscribe(ctx context.Context, pool *pgxpool.Pool, installationID uuid.UUID, channelID string, projectID *uuid.UUID, eventTypes []string) (Subscription, error) {
+	if pool == nil {
+		return Subscription{}, fmt.Errorf("db not configured")
+	}
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return scanSubscription(pool.QueryRow(ctx, `
+INSERT INTO slack_subscriptions (installation_id, channel_id, project_id, event_types, cursor)
+VALUES ($1, $2, $3, $4, (SELECT COALESCE(max(seq), 0) FROM bounty_events))
+ON CONFLICT (installation_id, channel_id, COALESCE(project_id, '00000000-0000-0000-0000-000000000000'::uuid))
+DO UPDATE SET event_types = EXCLUDED.event_types
+RETURNING `+subscriptionColumns, installationID, channelID, projectID, eventTypes))
+}
+
+func Unsubscribe(ctx context.Context, pool *pgxpool.Pool, installationID uuid.UUID, channelID string, projectID *uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `
+DELETE FROM slack_subscriptions
+WHERE installation_id = $1 AND channel_id = $2 AND project_id IS NOT DISTINCT FROM $3
+`, installationID, channelID, projectID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func ListSubscriptions(ctx context.Context, pool *pgxpool.Pool, installationID uuid.UUID) ([]Subscription, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+subscriptionColumns+` FROM slack_subscriptions WHERE installation_id = $1 ORDER BY created_at`, installationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Subscription{}
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -36,6 +36,17 @@ func NewTransactionBuilder(client *Client, sourceSecret string, retryConfig Retr
 
 // BuildAndSubmit builds a transaction, signs it, and submits it to the network
 func (tb *TransactionBuilder) BuildAndSubmit(ctx context.Context, operations []txnbuild.Operation) (*TransactionResult, error) {
+	tx, err := tb.Build(ctx, operations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Submit with retry
+	return tb.submitWithRetry(ctx, tx)
+}
+
+// Build builds and signs a transaction without submitting it.
+func (tb *TransactionBuilder) Build(ctx context.Context, operations []txnbuild.Operation) (*txnbuild.Transaction, error) {
 	// Get account details
 	accountRequest := horizonclient.AccountRequest{AccountID: tb.sourceKP.Address()}
 	accountDetail, err := tb.client.GetHorizonClient().AccountDetail(accountRequest)
@@ -61,8 +72,11 @@ func (tb *TransactionBuilder) BuildAndSubmit(ctx context.Context, operations []t
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
+	return tx, nil
+}
 
-	// Submit with retry
+// Submit submits a transaction from Build, retrying transient failures.
+func (tb *TransactionBuilder) Submit(ctx context.Context, tx *txnbuild.Transaction) (*TransactionResult, error) {
 	return tb.submitWithRetry(ctx, tx)
 }
 
@@ -0,0 +1,219 @@
+// Package splits suggests how a bounty paid for a team pull request is
+// divided between its authors, weighting each by the commits and lines they
+// contributed. Claimants accept the suggestion or adjust it before payout.
+package splits
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+const (
+	StatusProposed   = "proposed"
+	StatusAccepted   = "accepted"
+	StatusSuperseded = "superseded"
+)
+
+const (
+	// TotalBps is a whole bounty in basis points.
+	TotalBps = 10000
+	// lineWeight is how much lines changed count against commits when
+	// weighting authors; commits take the rest.
+	lineWeight = 0.7
+	// shareDecimals is the precision share amounts are cut to: no coarser
+	// than any asset payouts are sent in, so each share can be paid as is.
+	shareDecimals = 6
+)
+
+var (
+	ErrNotFound      = errors.New("bounty_split_not_found")
+	ErrNoAuthors     = errors.New("no_split_authors")
+	ErrNotClaimant   = errors.New("not_split_claimant")
+	ErrAccepted      = errors.New("split_already_accepted")
+	ErrInvalidShares = errors.New("invalid_split_shares")
+)
+
+// Contribution is what one author contributed to the pull request.
+type Contribution struct {
+	Login   string
+	Commits int
+	Lines   int
+}
+
+// Share is one claimant's part of a split.
+type Share struct {
+	GitHubLogin string     `json:"github_login"`
+	UserID      *uuid.UUID `json:"user_id"`
+	Commits     int        `json:"commits"`
+	Lines       int        `json:"lines_changed"`
+	// SuggestedBps is the share computed from authorship; ShareBps is the
+	// agreed one, which claimants may have adjusted.
+	SuggestedBps int        `json:"suggested_bps"`
+	ShareBps     int        `json:"share_bps"`
+	Amount       string     `json:"amount"`
+	AcceptedAt   *time.Time `json:"accepted_at"`
+}
+
+// Split divides a bounty between the authors of one pull request.
+type Split struct {
+	ID        uuid.UUID  `json:"id"`
+	BountyID  uuid.UUID  `json:"bounty_id"`
+	Repo      string     `json:"repo_full_name"`
+	PRNumber  int        `json:"pr_number"`
+	Status    string     `json:"status"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+	Shares    []Share    `json:"shares"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Contributions totals commits by author. Commits with no linked GitHub
+// account, and those by bots, can't be paid and are left out.
+func Contributions(commits []github.CommitStats) []Contribution {
+	byLogin := map[string]*Contribution{}
+	var out []Contribution
+	var order []string
+	for _, c := range commits {
+		login := strings.TrimSpace(c.AuthorLogin)
+		if login == "" || strings.HasSuffix(login, "[bot]") {
+			continue
+		}
+		key := strings.ToLower(login)
+		con, ok := byLogin[key]
+		if !ok {
+			con = &Contribution{Login: login}
+			byLogin[key] = con
+			order = append(order, key)
+		}
+		con.Commits++
+		con.Lines += c.Additions + c.Deletions
+	}
+	for _, k := range order {
+		out = append(out, *byLogin[k])
+	}
+	return out
+}
+
+// Suggest weights each author by lineWeight of their share of lines changed
+// plus the rest of their share of commits (commits alone when no lines were
+// counted), returning basis points that sum to TotalBps. Shares are ordered
+// largest first.
+func Suggest(contribs []Contribution) []Share {
+	var commits, lines int
+	for _, c := range contribs {
+		commits += c.Commits
+		lines += c.Lines
+	}
+	if commits == 0 {
+		return nil
+	}
+	weights := make([]float64, len(contribs))
+	for i, c := range contribs {
+		w := float64(c.Commits) / float64(commits)
+		if lines > 0 {
+			w = lineWeight*float64(c.Lines)/float64(lines) + (1-lineWeight)*w
+		}
+		weights[i] = w
+	}
+	bps := apportion(weights, TotalBps)
+	out := make([]Share, len(contribs))
+	for i, c := range contribs {
+		out[i] = Share{GitHubLogin: c.Login, Commits: c.Commits, Lines: c.Lines, SuggestedBps: bps[i], ShareBps: bps[i]}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].ShareBps != out[j].ShareBps {
+			return out[i].ShareBps > out[j].ShareBps
+		}
+		return strings.ToLower(out[i].GitHubLogin) < strings.ToLower(out[j].GitHubLogin)
+	})
+	return out
+}
+
+// apportion turns weights summing to 1 into integers summing to total by
+// largest remainder.
+func apportion(weights []float64, total int) []int {
+	out := make([]int, len(weights))
+	type rem struct {
+		i int
+		r float64
+	}
+	rems := make([]rem, len(weights))
+	left := total
+	for i, w := range weights {
+		exact := w * float64(total)
+		out[i] = int(exact)
+		left -= out[i]
+		rems[i] = rem{i, exact - float64(out[i])}
+	}
+	sort.SliceStable(rems, func(a, b int) bool { return rems[a].r > rems[b].r })
+	for k := 0; left > 0 && len(rems) > 0; k = (k + 1) % len(rems) {
+		out[rems[k].i]++
+		left--
+	}
+	return out
+}
+
+// Allocate sets each share's Amount from its ShareBps. Amounts are cut to
+// shareDecimals and whatever that leaves over goes to the first share, so
+// they add up to amount exactly.
+func Allocate(amount string, shares []Share) error {
+	total, err := wallet.ParseAmount(amount)
+	if err != nil {
+		return err
+	}
+	if len(shares) == 0 {
+		return nil
+	}
+	units := wallet.ToBaseUnits(total, shareDecimals)
+	rest := new(big.Rat)
+	for i := 1; i < len(shares); i++ {
+		p := new(big.Int).Mul(units, big.NewInt(int64(shares[i].ShareBps)))
+		p.Quo(p, big.NewInt(TotalBps))
+		shares[i].Amount = wallet.FromBaseUnits(p, shareDecimals)
+		r, _ := wallet.ParseAmount(shares[i].Amount)
+		rest.Add(rest, r)
+	}
+	// amount may be more precise than shareDecimals; the first share keeps
+	// its precision.
+	decimals := shareDecimals
+	if _, frac, ok := strings.Cut(strings.TrimSpace(amount), "."); ok && len(frac) > decimals {
+		decimals = len(frac)
+	}
+	first := new(big.Rat).Sub(total, rest)
+	shares[0].Amount = wallet.FormatAmount(first, decimals)
+	return nil
+}
+
+// AdjustShares applies claimant-chosen basis points, keyed by GitHub login, to
+// shares. Every share must be given, none negative, and they must sum to
+// TotalBps.
+func AdjustShares(shares []Share, bps map[string]int) error {
+	if len(bps) != len(shares) {
+		return ErrInvalidShares
+	}
+	byLogin := map[string]int{}
+	for login, v := range bps {
+		byLogin[strings.ToLower(login)] = v
+	}
+	sum := 0
+	for i := range shares {
+		v, ok := byLogin[strings.ToLower(shares[i].GitHubLogin)]
+		if !ok || v < 0 {
+			return ErrInvalidShares
+		}
+		shares[i].ShareBps = v
+		sum += v
+	}
+	if sum != TotalBps {
+		return ErrInvalidShares
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package splits
+
+import (
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+func TestSuggest(t *testing.T) {
+	contribs := Contributions([]github.CommitStats{
+		{AuthorLogin: "alice", Additions: 80, Deletions: 10},
+		{AuthorLogin: "Alice", Additions: 0, Deletions: 0},
+		{AuthorLogin: "bob", Additions: 10, Deletions: 0},
+		{AuthorLogin: "dependabot[bot]", Additions: 500},
+		{AuthorLogin: "", Additions: 500},
+	})
+	if len(contribs) != 2 || contribs[0].Commits != 2 || contribs[0].Lines != 90 {
+		t.Fatalf("contributions = %+v", contribs)
+	}
+	shares := Suggest(contribs)
+	// alice: 0.7*0.9 + 0.3*2/3 = 0.83; bob: 0.7*0.1 + 0.3*1/3 = 0.17.
+	if len(shares) != 2 || shares[0].GitHubLogin != "alice" || shares[0].ShareBps != 8300 || shares[1].ShareBps != 1700 {
+		t.Fatalf("shares = %+v", shares)
+	}
+
+	// Thirds leave a basis point over, which must not be lost.
+	thirds := Suggest([]Contribution{{Login: "a", Commits: 1}, {Login: "b", Commits: 1}, {Login: "c", Commits: 1}})
+	sum := 0
+	for _, s := range thirds {
+		sum += s.ShareBps
+	}
+	if sum != TotalBps {
+		t.Errorf("thirds sum to %d: %+v", sum, thirds)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	shares := []Share{{ShareBps: 3334}, {ShareBps: 3333}, {ShareBps: 3333}}
+	if err := Allocate("100.00000001", shares); err != nil {
+		t.Fatal(err)
+	}
+	total, _ := wallet.ParseAmount("0")
+	for _, s := range shares {
+		r, err := wallet.ParseAmount(s.Amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total.Add(total, r)
+	}
+	if total.FloatString(8) != "100.00000001" {
+		t.Errorf("amounts %v sum to %s", shares, total.FloatString(8))
+	}
+	if shares[1].Amount != "33.33" {
+		t.Errorf("second share = %q", shares[1].Amount)
+	}
+}
+
+func TestAdjustShares(t *testing.T) {
+	shares := []Share{{GitHubLogin: "Alice", ShareBps: 8300}, {GitHubLogin: "bob", ShareBps: 1700}}
+	if err := AdjustShares(shares, map[string]int{"alice": 5000, "bob": 4000}); err != ErrInvalidShares {
+		t.Errorf("short sum: %v", err)
+	}
+	if err := AdjustShares(shares, map[string]int{"alice": 10000}); err != ErrInvalidShares {
+		t.Errorf("missing author: %v", err)
+	}
+	if err := AdjustShares(shares, map[string]int{"alice": 6000, "bob": 4000}); err != nil || shares[0].ShareBps != 6000 {
+		t.Errorf("adjust: %v %+v", err, shares)
+	}
+}
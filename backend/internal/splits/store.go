@@ -0,0 +1,247 @@
+package splits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// Suggester computes splits from GitHub, reading with the project owner's
+// linked token (or anonymously).
+type Suggester struct {
+	Pool           *pgxpool.Pool
+	GitHub         *github.Client
+	TokenEncKeyB64 string
+	// Installations, when set, reads repos through the GitHub App.
+	Installations *github.InstallationTokens
+}
+
+// Suggest proposes a split of b between the authors of prNumber in the
+// project's repo, superseding any split proposed before.
+func (s *Suggester) Suggest(ctx context.Context, b bounties.Bounty, prNumber int, createdBy uuid.UUID) (Split, error) {
+	if s.Pool == nil {
+		return Split{}, fmt.Errorf("db not configured")
+	}
+	var fullName string
+	var ownerID uuid.UUID
+	if err := s.Pool.QueryRow(ctx, `SELECT github_full_name, owner_user_id FROM projects WHERE id = $1`, b.ProjectID).Scan(&fullName, &ownerID); err != nil {
+		return Split{}, err
+	}
+	gh := s.GitHub
+	if gh == nil {
+		gh = github.NewClient()
+	}
+	tokens := github.Tokens{Pool: s.Pool, TokenEncKeyB64: s.TokenEncKeyB64, Installations: s.Installations}
+	token, _ := tokens.Repo(fullName, ownerID).Token(ctx)
+	commits, err := gh.ListPRCommitStats(ctx, token, fullName, prNumber)
+	if err != nil {
+		return Split{}, err
+	}
+	shares := Suggest(Contributions(commits))
+	if len(shares) == 0 {
+		return Split{}, ErrNoAuthors
+	}
+	if err := Allocate(b.Amount, shares); err != nil {
+		return Split{}, err
+	}
+	return create(ctx, s.Pool, Split{BountyID: b.ID, Repo: fullName, PRNumber: prNumber, CreatedBy: &createdBy, Shares: shares})
+}
+
+func create(ctx context.Context, pool *pgxpool.Pool, sp Split) (Split, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Split{}, err
+	}
+	defer tx.Rollback(ctx)
+	if sp.CreatedBy != nil {
+		if err := bounties.SetActor(ctx, tx, *sp.CreatedBy); err != nil {
+			return Split{}, err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE bounty_splits SET status = 'superseded', updated_at = now()
+WHERE bounty_id = $1 AND status <> 'superseded'
+`, sp.BountyID); err != nil {
+		return Split{}, err
+	}
+	if err := tx.QueryRow(ctx, `
+INSERT INTO bounty_splits (bounty_id, repo_full_name, pr_number, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`, sp.BountyID, sp.Repo, sp.PRNumber, sp.CreatedBy).Scan(&sp.ID); err != nil {
+		return Split{}, err
+	}
+	for _, sh := range sp.Shares {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO bounty_split_shares (split_id, github_login, user_id, commits, lines_changed, suggested_bps, share_bps, amount)
+VALUES ($1, $2, (SELECT user_id FROM github_accounts WHERE lower(login) = lower($2) LIMIT 1), $3, $4, $5, $6, $7::numeric)
+`, sp.ID, sh.GitHubLogin, sh.Commits, sh.Lines, sh.SuggestedBps, sh.ShareBps, sh.Amount); err != nil {
+			return Split{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Split{}, err
+	}
+	return Get(ctx, pool, sp.ID)
+}
+
+// Get returns a split with its shares, largest first.
+func Get(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (Split, error) {
+	return get(ctx, pool, `id = $1`, id)
+}
+
+// Current returns the bounty's split that hasn't been superseded.
+func Current(ctx context.Context, pool *pgxpool.Pool, bountyID uuid.UUID) (Split, error) {
+	return get(ctx, pool, `bounty_id = $1 AND status <> 'superseded'`, bountyID)
+}
+
+func get(ctx context.Context, pool *pgxpool.Pool, where string, arg any) (Split, error) {
+	if pool == nil {
+		return Split{}, fmt.Errorf("db not configured")
+	}
+	var sp Split
+	err := pool.QueryRow(ctx, `
+SELECT id, bounty_id, repo_full_name, pr_number, status, created_by, created_at, updated_at
+FROM bounty_splits
+WHERE `+where, arg).Scan(&sp.ID, &sp.BountyID, &sp.Repo, &sp.PRNumber, &sp.Status, &sp.CreatedBy, &sp.CreatedAt, &sp.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Split{}, ErrNotFound
+	}
+	if err != nil {
+		return Split{}, err
+	}
+	rows, err := pool.Query(ctx, `
+SELECT github_login, user_id, commits, lines_changed, suggested_bps, share_bps, amount::text, accepted_at
+FROM bounty_split_shares
+WHERE split_id = $1
+ORDER BY suggested_bps DESC, lower(github_login)
+`, sp.ID)
+	if err != nil {
+		return Split{}, err
+	}
+	defer rows.Close()
+	sp.Shares = []Share{}
+	for rows.Next() {
+		var sh Share
+		if err := rows.Scan(&sh.GitHubLogin, &sh.UserID, &sh.Commits, &sh.Lines, &sh.SuggestedBps, &sh.ShareBps, &sh.Amount, &sh.AcceptedAt); err != nil {
+			return Split{}, err
+		}
+		sp.Shares = append(sp.Shares, sh)
+	}
+	return sp, rows.Err()
+}
+
+// IsClaimant reports whether userID holds a share of sp.
+func (sp Split) IsClaimant(userID uuid.UUID) bool {
+	for _, sh := range sp.Shares {
+		if sh.UserID != nil && *sh.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Accept records userID's agreement to the split's shares. Once every
+// claimant has agreed the split is accepted.
+func Accept(ctx context.Context, pool *pgxpool.Pool, id, userID uuid.UUID) (Split, error) {
+	sp, err := Get(ctx, pool, id)
+	if err != nil {
+		return Split{}, err
+	}
+	if sp.Status != StatusProposed {
+		return Split{}, ErrAccepted
+	}
+	if !sp.IsClaimant(userID) {
+		return Split{}, ErrNotClaimant
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Split{}, err
+	}
+	defer tx.Rollback(ctx)
+	if err := bounties.SetActor(ctx, tx, userID); err != nil {
+		return Split{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE bounty_split_shares SET accepted_at = now()
+WHERE split_id = $1 AND user_id = $2 AND accepted_at IS NULL
+`, id, userID); err != nil {
+		return Split{}, err
+	}
+	if err := settle(ctx, tx, id); err != nil {
+		return Split{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Split{}, err
+	}
+	return Get(ctx, pool, id)
+}
+
+// Adjust changes the split's shares to bps (basis points by GitHub login)
+// on behalf of userID, a claimant. Adjusting counts as userID accepting the
+// new shares; everyone else has to accept them again.
+func Adjust(ctx context.Context, pool *pgxpool.Pool, id, userID uuid.UUID, amount string, bps map[string]int) (Split, error) {
+	sp, err := Get(ctx, pool, id)
+	if err != nil {
+		return Split{}, err
+	}
+	if sp.Status != StatusProposed {
+		return Split{}, ErrAccepted
+	}
+	if !sp.IsClaimant(userID) {
+		return Split{}, ErrNotClaimant
+	}
+	if err := AdjustShares(sp.Shares, bps); err != nil {
+		return Split{}, err
+	}
+	if err := Allocate(amount, sp.Shares); err != nil {
+		return Split{}, err
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Split{}, err
+	}
+	defer tx.Rollback(ctx)
+	if err := bounties.SetActor(ctx, tx, userID); err != nil {
+		return Split{}, err
+	}
+	for _, sh := range sp.Shares {
+		if _, err := tx.Exec(ctx, `
+UPDATE bounty_split_shares
+SET share_bps = $3, amount = $4::numeric,
+    accepted_at = CASE WHEN user_id = $5 THEN now() ELSE NULL END
+WHERE split_id = $1 AND github_login = $2
+`, id, sh.GitHubLogin, sh.ShareBps, sh.Amount, userID); err != nil {
+			return Split{}, err
+		}
+	}
+	if err := settle(ctx, tx, id); err != nil {
+		return Split{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Split{}, err
+	}
+	return Get(ctx, pool, id)
+}
+
+// settle marks the split accepted once no claimant's acceptance is missing.
+// Authors with no Grainlify account can't accept, and don't hold it up.
+func settle(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+UPDATE bounty_splits SET status = 'accepted', updated_at = now()
+WHERE id = $1 AND status = 'proposed'
+  AND NOT EXISTS (
+    SELECT 1 FROM bounty_split_shares
+    WHERE split_id = $1 AND user_id IS NOT NULL AND accepted_at IS NULL
+  )
+`, id)
+	return err
+}
@@ -0,0 +1,112 @@
+package sponsors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+)
+
+type TierSummary struct {
+	Name              string `json:"name"`
+	MonthlyPriceCents int    `json:"monthly_price_cents"`
+	IsOneTime         bool   `json:"is_one_time"`
+	ActiveSponsors    int    `json:"active_sponsors"`
+}
+
+type AccountFunding struct {
+	Account
+	ActiveSponsors        int           `json:"active_sponsors"`
+	MonthlyRecurringCents int64         `json:"monthly_recurring_cents"`
+	OneTimeCents30d       int64         `json:"one_time_cents_30d"`
+	Tiers                 []TierSummary `json:"tiers"`
+}
+
+// BudgetBalance is the maintainer's platform balance for one asset, which
+// funds their bounties.
+type BudgetBalance struct {
+	Asset     string `json:"asset"`
+	Available string `json:"available"`
+	Deposited string `json:"deposited"`
+}
+
+// Funding combines GitHub Sponsors income (USD cents) with on-platform bounty
+// budgets (per asset). The two are not converted into a common currency.
+type Funding struct {
+	Sponsors                  []AccountFunding `json:"sponsors"`
+	SponsorsMonthlyTotalCents int64            `json:"sponsors_monthly_total_cents"`
+	BountyBudgets             []BudgetBalance  `json:"bounty_budgets"`
+	GeneratedAt               time.Time        `json:"generated_at"`
+}
+
+func BuildFunding(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (Funding, error) {
+	if pool == nil {
+		return Funding{}, fmt.Errorf("db not configured")
+	}
+	accounts, err := ListAccounts(ctx, pool, userID)
+	if err != nil {
+		return Funding{}, err
+	}
+	out := Funding{Sponsors: []AccountFunding{}, BountyBudgets: []BudgetBalance{}, GeneratedAt: time.Now().UTC()}
+	for _, a := range accounts {
+		af := AccountFunding{Account: a, Tiers: []TierSummary{}}
+		err := pool.QueryRow(ctx, `
+SELECT COUNT(*) FILTER (WHERE status = 'active'),
+       COALESCE(SUM(monthly_price_cents) FILTER (WHERE status = 'active' AND NOT is_one_time), 0),
+       COALESCE(SUM(monthly_price_cents) FILTER (WHERE is_one_time AND started_at > now() - interval '30 days'), 0)
+FROM sponsorships
+WHERE account_id = $1
+`, a.ID).Scan(&af.ActiveSponsors, &af.MonthlyRecurringCents, &af.OneTimeCents30d)
+		if err != nil {
+			return Funding{}, err
+		}
+		rows, err := pool.Query(ctx, `
+SELECT t.name, t.monthly_price_cents, t.is_one_time,
+       (SELECT COUNT(*) FROM sponsorships s WHERE s.tier_github_id = t.github_id AND s.status = 'active')
+FROM sponsor_tiers t
+WHERE t.account_id = $1
+ORDER BY t.is_one_time, t.monthly_price_cents
+`, a.ID)
+		if err != nil {
+			return Funding{}, err
+		}
+		for rows.Next() {
+			var t TierSummary
+			if err := rows.Scan(&t.Name, &t.MonthlyPriceCents, &t.IsOneTime, &t.ActiveSponsors); err != nil {
+				rows.Close()
+				return Funding{}, err
+			}
+			af.Tiers = append(af.Tiers, t)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return Funding{}, err
+		}
+		out.SponsorsMonthlyTotalCents += af.MonthlyRecurringCents
+		out.Sponsors = append(out.Sponsors, af)
+	}
+
+	rows, err := pool.Query(ctx, `
+SELECT asset, SUM(amount)::text, COALESCE(SUM(amount) FILTER (WHERE kind = $3 AND amount > 0), 0)::text
+FROM ledger_entries
+WHERE user_id = $1 AND account = $2
+GROUP BY asset
+ORDER BY asset
+`, userID, ledger.AccountUser, ledger.KindDeposit)
+	if err != nil {
+		return Funding{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b BudgetBalance
+		if err := rows.Scan(&b.Asset, &b.Available, &b.Deposited); err != nil {
+			return Funding{}, err
+		}
+		out.BountyBudgets = append(out.BountyBudgets, b)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,154 @@
+// Package sponsors mirrors maintainers' GitHub Sponsors tiers and
+// sponsorships, kept current by a periodic GraphQL sync plus sponsorship
+// webhooks, so funding dashboards can show them next to bounty budgets.
+package sponsors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+const (
+	StatusActive    = "active"
+	StatusCancelled = "cancelled"
+)
+
+var (
+	ErrNotFound   = errors.New("sponsor_account_not_found")
+	ErrLoginTaken = errors.New("sponsor_login_taken")
+)
+
+// Account is a Sponsors listing (user or org login) connected by a maintainer.
+type Account struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Login        string     `json:"login"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    *string    `json:"last_error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+const accountColumns = `id, user_id, login, last_synced_at, last_error, created_at`
+
+func scanAccount(row pgx.Row) (Account, error) {
+	var a Account
+	err := row.Scan(&a.ID, &a.UserID, &a.Login, &a.LastSyncedAt, &a.LastError, &a.CreatedAt)
+	return a, err
+}
+
+// Connect registers login for userID and returns a fresh webhook secret for
+// the Sponsors dashboard. Reconnecting the same login rotates the secret.
+func Connect(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, login, tokenEncKeyB64 string) (Account, string, error) {
+	if pool == nil {
+		return Account{}, "", fmt.Errorf("db not configured")
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return Account{}, "", err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return Account{}, "", err
+	}
+	secret := hex.EncodeToString(raw)
+	enc, err := cryptox.EncryptAESGCM(key, []byte(secret))
+	if err != nil {
+		return Account{}, "", err
+	}
+	a, err := scanAccount(pool.QueryRow(ctx, `
+INSERT INTO sponsor_accounts (user_id, login, webhook_secret)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, login) DO UPDATE SET webhook_secret = EXCLUDED.webhook_secret
+RETURNING `+accountColumns, userID, strings.TrimSpace(login), enc))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return Account{}, "", ErrLoginTaken
+	}
+	if err != nil {
+		return Account{}, "", err
+	}
+	return a, secret, nil
+}
+
+func ListAccounts(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Account, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+accountColumns+` FROM sponsor_accounts WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Account{}
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// GetAccount loads an account owned by userID.
+func GetAccount(ctx context.Context, pool *pgxpool.Pool, userID, id uuid.UUID) (Account, error) {
+	if pool == nil {
+		return Account{}, fmt.Errorf("db not configured")
+	}
+	a, err := scanAccount(pool.QueryRow(ctx, `SELECT `+accountColumns+` FROM sponsor_accounts WHERE id = $1 AND user_id = $2`, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Account{}, ErrNotFound
+	}
+	return a, err
+}
+
+func Disconnect(ctx context.Context, pool *pgxpool.Pool, userID, id uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM sponsor_accounts WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// WebhookSecret returns the account's login and decrypted webhook secret.
+func WebhookSecret(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID, tokenEncKeyB64 string) (string, string, error) {
+	if pool == nil {
+		return "", "", fmt.Errorf("db not configured")
+	}
+	var login string
+	var enc []byte
+	err := pool.QueryRow(ctx, `SELECT login, webhook_secret FROM sponsor_accounts WHERE id = $1`, id).Scan(&login, &enc)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := cryptox.DecryptAESGCM(key, enc)
+	if err != nil {
+		return "", "", fmt.Errorf("decrypt webhook secret failed")
+	}
+	return login, string(secret), nil
+}
@@ -0,0 +1,140 @@
+package sponsors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// Syncer refreshes connected accounts from the GraphQL API using the owning
+// maintainer's linked GitHub token. Webhooks keep the data current between
+// runs; the sync repairs missed deliveries.
+type Syncer struct {
+	Pool           *pgxpool.Pool
+	GitHub         *github.Client
+	TokenEncKeyB64 string
+}
+
+// SyncAccount replaces the account's tiers and active sponsorships with what
+// GitHub reports. Sponsorships no longer listed are marked cancelled.
+func (s *Syncer) SyncAccount(ctx context.Context, a Account) error {
+	if s.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	err := s.syncAccount(ctx, a)
+	if err != nil {
+		_, _ = s.Pool.Exec(ctx, `UPDATE sponsor_accounts SET last_error = $2 WHERE id = $1`, a.ID, err.Error())
+	}
+	return err
+}
+
+func (s *Syncer) syncAccount(ctx context.Context, a Account) error {
+	linked, err := github.GetLinkedAccount(ctx, s.Pool, a.UserID, s.TokenEncKeyB64)
+	if err != nil {
+		return err
+	}
+	listing, err := s.GitHub.GetSponsorsListing(ctx, linked.AccessToken, a.Login)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, t := range listing.Tiers {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO sponsor_tiers (account_id, github_id, name, monthly_price_cents, is_one_time)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (github_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  monthly_price_cents = EXCLUDED.monthly_price_cents,
+  is_one_time = EXCLUDED.is_one_time,
+  updated_at = now()
+`, a.ID, t.ID, t.Name, t.MonthlyPriceInCents, t.IsOneTime); err != nil {
+			return err
+		}
+	}
+
+	seen := make([]string, 0, len(listing.Sponsorships))
+	for _, sp := range listing.Sponsorships {
+		var sponsor, tierID *string
+		price, oneTime := 0, sp.IsOneTimePayment
+		if sp.SponsorEntity != nil && sp.SponsorEntity.Login != "" {
+			sponsor = &sp.SponsorEntity.Login
+		}
+		if sp.Tier != nil {
+			tierID = &sp.Tier.ID
+			price = sp.Tier.MonthlyPriceInCents
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO sponsorships (account_id, github_id, sponsor_login, tier_github_id, monthly_price_cents, is_one_time, privacy_level, status, started_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, 'active', $8)
+ON CONFLICT (github_id) DO UPDATE SET
+  sponsor_login = EXCLUDED.sponsor_login,
+  tier_github_id = EXCLUDED.tier_github_id,
+  monthly_price_cents = EXCLUDED.monthly_price_cents,
+  is_one_time = EXCLUDED.is_one_time,
+  privacy_level = EXCLUDED.privacy_level,
+  status = 'active',
+  cancelled_at = NULL,
+  updated_at = now()
+`, a.ID, sp.ID, sponsor, tierID, price, oneTime, strings.ToLower(sp.PrivacyLevel), sp.CreatedAt); err != nil {
+			return err
+		}
+		seen = append(seen, sp.ID)
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE sponsorships
+SET status = 'cancelled', cancelled_at = now(), updated_at = now()
+WHERE account_id = $1 AND status = 'active' AND NOT (github_id = ANY($2))
+`, a.ID, seen); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE sponsor_accounts SET last_synced_at = now(), last_error = NULL WHERE id = $1`, a.ID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RunOnce syncs every connected account, returning how many succeeded.
+func (s *Syncer) RunOnce(ctx context.Context) (int, error) {
+	if s.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	rows, err := s.Pool.Query(ctx, `SELECT `+accountColumns+` FROM sponsor_accounts ORDER BY last_synced_at NULLS FIRST`)
+	if err != nil {
+		return 0, err
+	}
+	var accounts []Account
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		accounts = append(accounts, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for _, a := range accounts {
+		if err := s.SyncAccount(ctx, a); err != nil {
+			slog.Warn("github sponsors sync failed", "account_id", a.ID.String(), "login", a.Login, "error", err)
+			continue
+		}
+		synced++
+	}
+	return synced, nil
+}
@@ -0,0 +1,124 @@
+package sponsors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrAccountMismatch = errors.New("sponsorable_mismatch")
+
+// WebhookEvent is the subset of GitHub's "sponsorship" webhook payload we use.
+type WebhookEvent struct {
+	Action      string `json:"action"`
+	Sponsorship struct {
+		NodeID       string    `json:"node_id"`
+		CreatedAt    time.Time `json:"created_at"`
+		PrivacyLevel string    `json:"privacy_level"`
+		Sponsorable  struct {
+			Login string `json:"login"`
+		} `json:"sponsorable"`
+		Sponsor struct {
+			Login string `json:"login"`
+		} `json:"sponsor"`
+		Tier struct {
+			NodeID              string `json:"node_id"`
+			Name                string `json:"name"`
+			MonthlyPriceInCents int    `json:"monthly_price_in_cents"`
+			IsOneTime           bool   `json:"is_one_time"`
+		} `json:"tier"`
+	} `json:"sponsorship"`
+}
+
+// ParseWebhook decodes a sponsorship delivery.
+func ParseWebhook(payload []byte) (WebhookEvent, error) {
+	var ev WebhookEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return WebhookEvent{}, err
+	}
+	if ev.Action == "" || ev.Sponsorship.NodeID == "" {
+		return WebhookEvent{}, fmt.Errorf("not a sponsorship event")
+	}
+	return ev, nil
+}
+
+// ApplyWebhook records a verified sponsorship delivery for account and updates
+// the mirrored sponsorship. Redeliveries are ignored. pending_* actions are
+// recorded only; GitHub follows them with cancelled/tier_changed when they
+// take effect.
+func ApplyWebhook(ctx context.Context, pool *pgxpool.Pool, accountID uuid.UUID, login, deliveryID string, payload []byte) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	ev, err := ParseWebhook(payload)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(ev.Sponsorship.Sponsorable.Login, login) {
+		return ErrAccountMismatch
+	}
+	sp := ev.Sponsorship
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+INSERT INTO sponsorship_events (delivery_id, account_id, action, sponsor_login, monthly_price_cents, payload)
+VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6::jsonb)
+ON CONFLICT (delivery_id) DO NOTHING
+`, deliveryID, accountID, ev.Action, sp.Sponsor.Login, sp.Tier.MonthlyPriceInCents, string(payload))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	if sp.Tier.NodeID != "" {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO sponsor_tiers (account_id, github_id, name, monthly_price_cents, is_one_time)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (github_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  monthly_price_cents = EXCLUDED.monthly_price_cents,
+  is_one_time = EXCLUDED.is_one_time,
+  updated_at = now()
+`, accountID, sp.Tier.NodeID, sp.Tier.Name, sp.Tier.MonthlyPriceInCents, sp.Tier.IsOneTime); err != nil {
+			return err
+		}
+	}
+
+	switch ev.Action {
+	case "created", "edited", "tier_changed":
+		_, err = tx.Exec(ctx, `
+INSERT INTO sponsorships (account_id, github_id, sponsor_login, tier_github_id, monthly_price_cents, is_one_time, privacy_level, status, started_at)
+VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, 'active', $8)
+ON CONFLICT (github_id) DO UPDATE SET
+  sponsor_login = EXCLUDED.sponsor_login,
+  tier_github_id = EXCLUDED.tier_github_id,
+  monthly_price_cents = EXCLUDED.monthly_price_cents,
+  is_one_time = EXCLUDED.is_one_time,
+  privacy_level = EXCLUDED.privacy_level,
+  updated_at = now()
+`, accountID, sp.NodeID, sp.Sponsor.Login, sp.Tier.NodeID, sp.Tier.MonthlyPriceInCents, sp.Tier.IsOneTime, strings.ToLower(sp.PrivacyLevel), sp.CreatedAt)
+	case "cancelled":
+		_, err = tx.Exec(ctx, `
+UPDATE sponsorships
+SET status = 'cancelled', cancelled_at = now(), updated_at = now()
+WHERE github_id = $1 AND account_id = $2
+`, sp.NodeID, accountID)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,32 @@
+package sponsors
+
+import "testing"
+
+func TestParseWebhook(t *testing.T) {
+	payload := []byte(`{
+  "action": "tier_changed",
+  "sponsorship": {
+    "node_id": "MDExOlNwb25zb3JzaGlwMQ==",
+    "created_at": "2019-12-20T19:24:46+00:00",
+    "sponsorable": {"login": "octocat"},
+    "sponsor": {"login": "monalisa"},
+    "privacy_level": "public",
+    "tier": {"node_id": "MDEyOlNwb25zb3JzVGllcjE=", "name": "$10 a month", "monthly_price_in_cents": 1000, "is_one_time": false}
+  },
+  "changes": {"tier": {"from": {"node_id": "MDEyOlNwb25zb3JzVGllcjI=", "monthly_price_in_cents": 500}}}
+}`)
+	ev, err := ParseWebhook(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Action != "tier_changed" || ev.Sponsorship.Sponsorable.Login != "octocat" || ev.Sponsorship.Sponsor.Login != "monalisa" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if ev.Sponsorship.Tier.MonthlyPriceInCents != 1000 || ev.Sponsorship.Tier.NodeID == "" {
+		t.Fatalf("unexpected tier: %+v", ev.Sponsorship.Tier)
+	}
+
+	if _, err := ParseWebhook([]byte(`{"action": "opened", "issue": {}}`)); err == nil {
+		t.Fatal("expected error for non-sponsorship payload")
+	}
+}
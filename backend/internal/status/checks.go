@@ -0,0 +1,104 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// slowPing is the database round trip above which it is reported degraded.
+const slowPing = 500 * time.Millisecond
+
+// Checker runs the automated component checks.
+type Checker struct {
+	Pool *pgxpool.Pool
+}
+
+// RunOnce runs every check, records results on components that are not
+// pinned by an admin and returns how many checks ran.
+func (c *Checker) RunOnce(ctx context.Context) (int, error) {
+	if c.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	start := time.Now()
+	err := c.Pool.Ping(pingCtx)
+	cancel()
+	if err != nil {
+		// Nothing can be recorded without the database.
+		return 0, fmt.Errorf("database ping: %w", err)
+	}
+	dbStatus, dbMsg := Operational, ""
+	if elapsed := time.Since(start); elapsed > slowPing {
+		dbStatus, dbMsg = Degraded, fmt.Sprintf("slow responses (%dms)", elapsed.Milliseconds())
+	}
+
+	results := map[string][2]string{
+		"api":      {Operational, ""},
+		"database": {dbStatus, dbMsg},
+	}
+
+	var failed, completed, stale int
+	if err := c.Pool.QueryRow(ctx, `
+SELECT
+  count(*) FILTER (WHERE status = 'failed' AND updated_at > now() - interval '1 hour'),
+  count(*) FILTER (WHERE status = 'completed' AND updated_at > now() - interval '1 hour'),
+  count(*) FILTER (WHERE status = 'pending' AND run_at < now() - interval '30 minutes')
+FROM sync_jobs
+`).Scan(&failed, &completed, &stale); err != nil {
+		slog.Warn("status check failed", "component", "github_sync", "error", err)
+	} else {
+		st, msg := SyncStatus(failed, completed, stale)
+		results["github_sync"] = [2]string{st, msg}
+	}
+
+	var failedBatches, stuckBatches int
+	if err := c.Pool.QueryRow(ctx, `
+SELECT
+  count(*) FILTER (WHERE status = 'failed' AND updated_at > now() - interval '1 hour'),
+  count(*) FILTER (WHERE status = 'sending' AND updated_at < now() - interval '30 minutes')
+FROM payout_batches
+`).Scan(&failedBatches, &stuckBatches); err != nil {
+		slog.Warn("status check failed", "component", "payouts", "error", err)
+	} else {
+		st, msg := PayoutStatus(failedBatches, stuckBatches)
+		results["payouts"] = [2]string{st, msg}
+	}
+
+	for id, r := range results {
+		if err := setAutomated(ctx, c.Pool, id, r[0], r[1]); err != nil {
+			return 0, err
+		}
+	}
+	return len(results), nil
+}
+
+// SyncStatus grades GitHub sync from the last hour of jobs: mostly failing
+// is a partial outage, any failures or a backlog is degraded.
+func SyncStatus(failed, completed, stale int) (string, string) {
+	switch {
+	case failed >= 5 && failed > completed:
+		return PartialOutage, fmt.Sprintf("%d sync jobs failed in the last hour", failed)
+	case failed > 0:
+		return Degraded, fmt.Sprintf("%d sync jobs failed in the last hour", failed)
+	case stale > 0:
+		return Degraded, fmt.Sprintf("%d sync jobs delayed", stale)
+	}
+	return Operational, ""
+}
+
+// PayoutStatus grades payouts from recently failed and stuck batches.
+func PayoutStatus(failed, stuck int) (string, string) {
+	switch {
+	case failed > 0 && stuck > 0:
+		return PartialOutage, fmt.Sprintf("%d payout batches failed and %d are stuck", failed, stuck)
+	case failed > 0:
+		return Degraded, fmt.Sprintf("%d payout batches failed in the last hour", failed)
+	case stuck > 0:
+		return Degraded, fmt.Sprintf("%d payout batches delayed", stuck)
+	}
+	return Operational, ""
+}
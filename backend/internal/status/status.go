@@ -0,0 +1,356 @@
+// Package status backs the public status page: component health set by
+// automated checks or admins, and an incident history with timeline updates.
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Component statuses, ordered from best to worst for Rollup.
+const (
+	Operational   = "operational"
+	Maintenance   = "maintenance"
+	Degraded      = "degraded"
+	PartialOutage = "partial_outage"
+	MajorOutage   = "major_outage"
+)
+
+var severity = map[string]int{
+	Operational:   0,
+	Maintenance:   1,
+	Degraded:      2,
+	PartialOutage: 3,
+	MajorOutage:   4,
+}
+
+// Incident statuses.
+const (
+	IncidentInvestigating = "investigating"
+	IncidentIdentified    = "identified"
+	IncidentMonitoring    = "monitoring"
+	IncidentResolved      = "resolved"
+)
+
+const (
+	SourceAutomated = "automated"
+	SourceManual    = "manual"
+)
+
+var (
+	ErrComponentNotFound = errors.New("status_component_not_found")
+	ErrIncidentNotFound  = errors.New("status_incident_not_found")
+	ErrInvalidStatus     = errors.New("invalid_status")
+	ErrInvalidImpact     = errors.New("invalid_impact")
+)
+
+// ValidStatus reports whether s is a component status.
+func ValidStatus(s string) bool {
+	_, ok := severity[s]
+	return ok
+}
+
+// ValidIncidentStatus reports whether s is an incident status.
+func ValidIncidentStatus(s string) bool {
+	switch s {
+	case IncidentInvestigating, IncidentIdentified, IncidentMonitoring, IncidentResolved:
+		return true
+	}
+	return false
+}
+
+// ValidImpact reports whether s is an incident impact.
+func ValidImpact(s string) bool {
+	switch s {
+	case "none", "minor", "major", "critical":
+		return true
+	}
+	return false
+}
+
+type Component struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Source      string     `json:"source"`
+	Message     *string    `json:"message,omitempty"`
+	CheckedAt   *time.Time `json:"checked_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Rollup is the overall status: the worst component status, or operational
+// when there are no components.
+func Rollup(components []Component) string {
+	overall := Operational
+	for _, c := range components {
+		if severity[c.Status] > severity[overall] {
+			overall = c.Status
+		}
+	}
+	return overall
+}
+
+func ListComponents(ctx context.Context, pool *pgxpool.Pool) ([]Component, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, name, description, status, source, message, checked_at, updated_at
+FROM status_components
+ORDER BY position, id
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Component{}
+	for rows.Next() {
+		var c Component
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.Status, &c.Source, &c.Message, &c.CheckedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// SetManual pins a component to status until ReleaseManual.
+func SetManual(ctx context.Context, pool *pgxpool.Pool, id, st, message string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	if !ValidStatus(st) {
+		return ErrInvalidStatus
+	}
+	tag, err := pool.Exec(ctx, `
+UPDATE status_components
+SET status = $2, source = 'manual', message = NULLIF($3, ''), updated_at = now()
+WHERE id = $1
+`, id, st, strings.TrimSpace(message))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrComponentNotFound
+	}
+	return nil
+}
+
+// ReleaseManual hands a component back to the automated checks.
+func ReleaseManual(ctx context.Context, pool *pgxpool.Pool, id string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `UPDATE status_components SET source = 'automated', updated_at = now() WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrComponentNotFound
+	}
+	return nil
+}
+
+// setAutomated records a check result unless an admin has pinned the component.
+func setAutomated(ctx context.Context, pool *pgxpool.Pool, id, st, message string) error {
+	_, err := pool.Exec(ctx, `
+UPDATE status_components
+SET status = $2, message = NULLIF($3, ''), checked_at = now(),
+    updated_at = CASE WHEN status IS DISTINCT FROM $2 THEN now() ELSE updated_at END
+WHERE id = $1 AND source = 'automated'
+`, id, st, message)
+	return err
+}
+
+type IncidentUpdate struct {
+	ID        uuid.UUID `json:"id"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Incident struct {
+	ID           uuid.UUID        `json:"id"`
+	Title        string           `json:"title"`
+	Impact       string           `json:"impact"`
+	Status       string           `json:"status"`
+	ComponentIDs []string         `json:"component_ids"`
+	StartedAt    time.Time        `json:"started_at"`
+	ResolvedAt   *time.Time       `json:"resolved_at,omitempty"`
+	Updates      []IncidentUpdate `json:"updates"`
+}
+
+const incidentColumns = `id, title, impact, status, component_ids, started_at, resolved_at`
+
+func scanIncident(row pgx.Row) (Incident, error) {
+	var in Incident
+	err := row.Scan(&in.ID, &in.Title, &in.Impact, &in.Status, &in.ComponentIDs, &in.StartedAt, &in.ResolvedAt)
+	in.Updates = []IncidentUpdate{}
+	return in, err
+}
+
+// ListIncidents returns unresolved incidents plus those started since
+// `since`, newest first, each with its update timeline (oldest first).
+func ListIncidents(ctx context.Context, pool *pgxpool.Pool, since time.Time) ([]Incident, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+incidentColumns+`
+FROM status_incidents
+WHERE resolved_at IS NULL OR started_at >= $1
+ORDER BY started_at DESC
+LIMIT 200
+`, since)
+	if err != nil {
+		return nil, err
+	}
+	out := []Incident{}
+	index := map[uuid.UUID]int{}
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		in, err := scanIncident(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		index[in.ID] = len(out)
+		ids = append(ids, in.ID)
+		out = append(out, in)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	rows, err = pool.Query(ctx, `
+SELECT incident_id, id, status, message, created_at
+FROM status_incident_updates
+WHERE incident_id = ANY($1)
+ORDER BY created_at
+`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			incidentID uuid.UUID
+			u          IncidentUpdate
+		)
+		if err := rows.Scan(&incidentID, &u.ID, &u.Status, &u.Message, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		i := index[incidentID]
+		out[i].Updates = append(out[i].Updates, u)
+	}
+	return out, rows.Err()
+}
+
+// NewIncident is the input to CreateIncident.
+type NewIncident struct {
+	Title        string
+	Impact       string
+	Status       string
+	ComponentIDs []string
+	Message      string
+}
+
+// CreateIncident opens an incident with its first timeline update.
+func CreateIncident(ctx context.Context, pool *pgxpool.Pool, createdBy *uuid.UUID, in NewIncident) (Incident, error) {
+	if pool == nil {
+		return Incident{}, fmt.Errorf("db not configured")
+	}
+	if in.Impact == "" {
+		in.Impact = "minor"
+	}
+	if in.Status == "" {
+		in.Status = IncidentInvestigating
+	}
+	if !ValidImpact(in.Impact) {
+		return Incident{}, ErrInvalidImpact
+	}
+	if !ValidIncidentStatus(in.Status) {
+		return Incident{}, ErrInvalidStatus
+	}
+	if in.ComponentIDs == nil {
+		in.ComponentIDs = []string{}
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Incident{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	inc, err := scanIncident(tx.QueryRow(ctx, `
+INSERT INTO status_incidents (title, impact, status, component_ids, created_by, resolved_at)
+VALUES ($1, $2, $3, $4, $5, CASE WHEN $3 = 'resolved' THEN now() END)
+RETURNING `+incidentColumns, strings.TrimSpace(in.Title), in.Impact, in.Status, in.ComponentIDs, createdBy))
+	if err != nil {
+		return Incident{}, err
+	}
+	message := strings.TrimSpace(in.Message)
+	if message == "" {
+		message = inc.Title
+	}
+	var u IncidentUpdate
+	if err := tx.QueryRow(ctx, `
+INSERT INTO status_incident_updates (incident_id, status, message, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING id, status, message, created_at
+`, inc.ID, inc.Status, message, createdBy).Scan(&u.ID, &u.Status, &u.Message, &u.CreatedAt); err != nil {
+		return Incident{}, err
+	}
+	inc.Updates = append(inc.Updates, u)
+	return inc, tx.Commit(ctx)
+}
+
+// AddUpdate appends a timeline update and moves the incident to its status;
+// a resolved status closes the incident.
+func AddUpdate(ctx context.Context, pool *pgxpool.Pool, incidentID uuid.UUID, createdBy *uuid.UUID, st, message string) (IncidentUpdate, error) {
+	if pool == nil {
+		return IncidentUpdate{}, fmt.Errorf("db not configured")
+	}
+	if !ValidIncidentStatus(st) {
+		return IncidentUpdate{}, ErrInvalidStatus
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return IncidentUpdate{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+UPDATE status_incidents
+SET status = $2,
+    resolved_at = CASE WHEN $2 = 'resolved' THEN COALESCE(resolved_at, now()) ELSE NULL END,
+    updated_at = now()
+WHERE id = $1
+`, incidentID, st)
+	if err != nil {
+		return IncidentUpdate{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return IncidentUpdate{}, ErrIncidentNotFound
+	}
+	var u IncidentUpdate
+	if err := tx.QueryRow(ctx, `
+INSERT INTO status_incident_updates (incident_id, status, message, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING id, status, message, created_at
+`, incidentID, st, strings.TrimSpace(message), createdBy).Scan(&u.ID, &u.Status, &u.Message, &u.CreatedAt); err != nil {
+		return IncidentUpdate{}, err
+	}
+	return u, tx.Commit(ctx)
+}
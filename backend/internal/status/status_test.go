@@ -0,0 +1,55 @@
+package status
+
+import "testing"
+
+func TestRollup(t *testing.T) {
+	cases := []struct {
+		statuses []string
+		want     string
+	}{
+		{nil, Operational},
+		{[]string{Operational, Operational}, Operational},
+		{[]string{Operational, Maintenance}, Maintenance},
+		{[]string{Degraded, Maintenance, Operational}, Degraded},
+		{[]string{PartialOutage, MajorOutage, Degraded}, MajorOutage},
+	}
+	for _, tc := range cases {
+		var components []Component
+		for _, s := range tc.statuses {
+			components = append(components, Component{Status: s})
+		}
+		if got := Rollup(components); got != tc.want {
+			t.Errorf("Rollup(%v) = %q, want %q", tc.statuses, got, tc.want)
+		}
+	}
+}
+
+func TestSyncStatus(t *testing.T) {
+	cases := []struct {
+		failed, completed, stale int
+		want                     string
+	}{
+		{0, 10, 0, Operational},
+		{1, 10, 0, Degraded},
+		{0, 0, 3, Degraded},
+		{6, 2, 0, PartialOutage},
+		{6, 20, 0, Degraded},
+	}
+	for _, tc := range cases {
+		if got, _ := SyncStatus(tc.failed, tc.completed, tc.stale); got != tc.want {
+			t.Errorf("SyncStatus(%d, %d, %d) = %q, want %q", tc.failed, tc.completed, tc.stale, got, tc.want)
+		}
+	}
+}
+
+func TestPayoutStatus(t *testing.T) {
+	if got, _ := PayoutStatus(0, 0); got != Operational {
+		t.Errorf("PayoutStatus(0, 0) = %q", got)
+	}
+	if got, _ := PayoutStatus(1, 0); got != Degraded {
+		t.Errorf("PayoutStatus(1, 0) = %q", got)
+	}
+	if got, _ := PayoutStatus(1, 1); got != PartialOutage {
+		t.Errorf("PayoutStatus(1, 1) = %q", got)
+	}
+}
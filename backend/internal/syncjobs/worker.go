@@ -15,23 +15,30 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/failures"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
 )
 
 type Worker struct {
-	cfg     config.Config
-	pool    *pgxpool.Pool
-	limiter *rate.Limiter
-	gh      *github.Client
+	cfg      config.Config
+	pool     *pgxpool.Pool
+	limiter  *rate.Limiter
+	gh       *github.Client
+	tokens   github.Tokens
 	workerID string
 }
 
 func New(cfg config.Config, pool *pgxpool.Pool) *Worker {
 	return &Worker{
-		cfg:      cfg,
-		pool:     pool,
-		limiter:  rate.NewLimiter(rate.Every(250*time.Millisecond), 2), // ~4 req/s, burst 2
-		gh:       github.NewClient(),
+		cfg:     cfg,
+		pool:    pool,
+		limiter: rate.NewLimiter(rate.Every(250*time.Millisecond), 2), // ~4 req/s, burst 2
+		gh:      github.NewClient(),
+		tokens: github.Tokens{
+			Pool:           pool,
+			TokenEncKeyB64: cfg.TokenEncKeyB64,
+			Installations:  github.AppInstallations(cfg.GitHubAppID, cfg.GitHubAppPrivateKey),
+		},
 		workerID: fmt.Sprintf("%s:%d", hostname(), os.Getpid()),
 	}
 }
@@ -94,17 +101,17 @@ WHERE id = $1
 	runErr := w.runJob(ctx, jobID, projectID, jobType)
 
 	status := "completed"
-	lastErr := ""
+	var f failures.Failure
 	if runErr != nil {
 		status = "failed"
-		lastErr = runErr.Error()
+		f = failures.Classify(runErr)
 	}
 
 	_, _ = w.pool.Exec(ctx, `
 UPDATE sync_jobs
-SET status = $2, attempts = attempts + 1, last_error = NULLIF($3, ''), updated_at = now()
+SET status = $2, attempts = attempts + 1, last_error = NULLIF($3, ''), last_error_code = NULLIF($4, ''), updated_at = now()
 WHERE id = $1
-`, jobID, status, lastErr)
+`, jobID, status, f.Message, f.Code)
 
 	return nil
 }
@@ -127,7 +134,8 @@ WHERE id = $1
 		return err
 	}
 
-	linked, err := github.GetLinkedAccount(ctx, w.pool, ownerUserID, w.cfg.TokenEncKeyB64)
+	// Installation token when the app is on the repo, else the owner's.
+	token, err := w.tokens.Repo(fullName, ownerUserID).Token(ctx)
 	if err != nil {
 		slog.Error("sync job failed: GitHub account not linked",
 			"job_id", jobID,
@@ -135,7 +143,7 @@ WHERE id = $1
 			"user_id", ownerUserID,
 			"repo", fullName,
 			"error", err,
-			"hint", "User needs to link their GitHub account via OAuth",
+			"hint", "Install the GitHub App on the repository, or link the owner's GitHub account via OAuth",
 		)
 		return fmt.Errorf("github_not_linked: %w", err)
 	}
@@ -151,9 +159,9 @@ WHERE id = $1
 	var syncErr error
 	switch jobType {
 	case "sync_issues":
-		syncErr = w.syncIssues(ctx, projectID, fullName, linked.AccessToken)
+		syncErr = w.syncIssues(ctx, projectID, fullName, token)
 	case "sync_prs":
-		syncErr = w.syncPRs(ctx, projectID, fullName, linked.AccessToken)
+		syncErr = w.syncPRs(ctx, projectID, fullName, token)
 	default:
 		syncErr = fmt.Errorf("unknown job_type: %s", jobType)
 	}
@@ -202,7 +210,7 @@ func (w *Worker) syncIssues(ctx context.Context, projectID uuid.UUID, fullName s
 			assigneesJSON, _ := json.Marshal(it.Assignees)
 			// Convert labels to JSONB (array of {name, color} objects)
 			labelsJSON, _ := json.Marshal(it.Labels)
-			
+
 			// Parse date strings from GitHub API
 			var createdAt, updatedAt, closedAt *time.Time
 			if it.CreatedAt != nil && *it.CreatedAt != "" {
@@ -244,7 +252,7 @@ func (w *Worker) syncIssues(ctx context.Context, projectID uuid.UUID, fullName s
 					)
 				}
 			}
-			
+
 			// Fetch comments for this issue (if comments_count > 0)
 			var commentsJSON []byte = []byte("[]")
 			if it.Comments > 0 {
@@ -255,7 +263,7 @@ func (w *Worker) syncIssues(ctx context.Context, projectID uuid.UUID, fullName s
 					}
 				}
 			}
-			
+
 			_, _ = w.pool.Exec(ctx, `
 INSERT INTO github_issues (project_id, github_issue_id, number, state, title, body, author_login, url, assignees, labels, comments_count, comments, created_at_github, updated_at_github, closed_at_github, last_seen_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, now())
@@ -277,7 +285,7 @@ ON CONFLICT (project_id, github_issue_id) DO UPDATE SET
 `, projectID, it.ID, it.Number, it.State, it.Title, it.Body, it.User.Login, it.HTMLURL, assigneesJSON, labelsJSON, it.Comments, commentsJSON, createdAt, updatedAt, closedAt)
 		}
 	}
-	
+
 	slog.Info("sync issues completed",
 		"project_id", projectID,
 		"repo", fullName,
@@ -313,7 +321,7 @@ func (w *Worker) syncPRs(ctx context.Context, projectID uuid.UUID, fullName stri
 
 		for _, it := range items {
 			totalPRs++
-			
+
 			// Parse date strings from GitHub API
 			var createdAt, updatedAt, closedAt, mergedAt *time.Time
 			if it.CreatedAt != nil && *it.CreatedAt != "" {
@@ -336,7 +344,7 @@ func (w *Worker) syncPRs(ctx context.Context, projectID uuid.UUID, fullName stri
 					mergedAt = &t
 				}
 			}
-			
+
 			_, _ = w.pool.Exec(ctx, `
 INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, title, body, author_login, url, merged, created_at_github, updated_at_github, closed_at_github, merged_at_github, last_seen_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, now())
@@ -366,7 +374,3 @@ func hostname() string {
 	}
 	return h
 }
-
-
-
-
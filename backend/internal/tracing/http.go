@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span per request, continuing the caller's
+// trace when it sends a traceparent header. Mount it ahead of the request
+// logger so the span covers the whole request and sees the final status.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		ctx, span := tracer().Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method()),
+				attribute.String("url.path", c.Path()),
+				attribute.String("client.address", c.IP()),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+		c.Locals(localSpan{}, span)
+
+		err := c.Next()
+
+		// Named after the route template so requests for different IDs
+		// group together.
+		route := c.Route().Path
+		if route == "/" && c.Path() != "/" {
+			route = "unmatched"
+		}
+		span.SetName(c.Method() + " " + route)
+		status := c.Response().StatusCode()
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if id, ok := c.Locals("requestid").(string); ok {
+			span.SetAttributes(attribute.String("request_id", id))
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		if err != nil || status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return err
+	}
+}
+
+// headerCarrier reads trace context from fiber's request headers.
+type headerCarrier struct{ c *fiber.Ctx }
+
+func (h headerCarrier) Get(key string) string { return h.c.Get(key) }
+
+func (h headerCarrier) Set(key, value string) { h.c.Request().Header.Set(key, value) }
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.c.Request().Header.VisitAll(func(k, _ []byte) { keys = append(keys, string(k)) })
+	return keys
+}
+
+// GitHubTransport wraps base (nil means http.DefaultTransport) in a client
+// span per GitHub call. Trace context isn't sent to GitHub.
+func GitHubTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return githubTransport{base: base}
+}
+
+type githubTransport struct {
+	base http.RoundTripper
+}
+
+func (t githubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, ok := withParent(req.Context())
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	ctx, span := tracer().Start(ctx, "GitHub "+req.Method+" "+req.URL.Host,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+	defer span.End()
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if rem := resp.Header.Get("X-RateLimit-Remaining"); rem != "" {
+		span.SetAttributes(attribute.String("github.rate_limit.remaining", rem))
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatementLen bounds the SQL recorded on a query span.
+const maxStatementLen = 2048
+
+// DBTracer spans each query, and each wait for a pool connection. Set it as
+// the pool's ConnConfig.Tracer. Only the SQL is recorded, never the
+// arguments.
+type DBTracer struct{}
+
+var (
+	_ pgx.QueryTracer       = DBTracer{}
+	_ pgxpool.AcquireTracer = DBTracer{}
+)
+
+func (DBTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, ok := withParent(ctx)
+	if !ok {
+		return ctx
+	}
+	stmt := strings.TrimSpace(data.SQL)
+	ctx, _ = tracer().Start(ctx, queryName(stmt),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", truncate(stmt, maxStatementLen)),
+		),
+	)
+	return ctx
+}
+
+func (DBTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	} else {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	span.End()
+}
+
+func (DBTracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	ctx, ok := withParent(ctx)
+	if !ok {
+		return ctx
+	}
+	ctx, _ = tracer().Start(ctx, "db.acquire", trace.WithSpanKind(trace.SpanKindInternal))
+	return ctx
+}
+
+func (DBTracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
+
+// queryName names a query span after its leading keyword (SELECT, UPDATE,
+// WITH...), which keeps span names few.
+func queryName(stmt string) string {
+	kw, _, _ := strings.Cut(stmt, " ")
+	kw, _, _ = strings.Cut(kw, "\n")
+	if kw == "" {
+		return "db.query"
+	}
+	return "db." + strings.ToUpper(kw)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
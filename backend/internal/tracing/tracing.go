@@ -0,0 +1,80 @@
+// Package tracing exports OpenTelemetry traces of API requests, with spans
+// for the database queries and GitHub calls made while serving them.
+//
+// Handlers pass fiber's request context (c.Context()) down rather than a
+// context.Context carrying the span, so Middleware also stores the span in
+// the request's locals, and spans started below a handler find their
+// parent there. Queries and calls made outside a traced request, by
+// background jobs, aren't spanned, so they don't flood the exporter with
+// tiny traces.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+)
+
+const instrumentation = "github.com/jagadeesh/grainlify/backend/internal/tracing"
+
+// Setup installs the global tracer provider exporting to cfg.OTelEndpoint,
+// and returns the function that flushes it on shutdown. Without an endpoint
+// the no-op provider stays and spans cost next to nothing.
+func Setup(ctx context.Context, cfg config.Config) (func(context.Context) error, error) {
+	if cfg.OTelEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exp, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTelEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("otlp exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.OTelServiceName),
+		attribute.String("deployment.environment", cfg.Env),
+	))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio(cfg.OTelSamplePercent)))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
+
+func sampleRatio(percent int) float64 {
+	return float64(min(max(percent, 0), 100)) / 100
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// localSpan is the request locals key Middleware stores the request's span
+// under. fasthttp's request context answers Value with its locals.
+type localSpan struct{}
+
+// withParent returns ctx carrying the span to start children of: ctx's own,
+// or else the request span Middleware left in fiber's request context. ok
+// is false when there is neither.
+func withParent(ctx context.Context) (context.Context, bool) {
+	if trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx, true
+	}
+	if span, ok := ctx.Value(localSpan{}).(trace.Span); ok {
+		return trace.ContextWithSpan(ctx, span), true
+	}
+	return ctx, false
+}
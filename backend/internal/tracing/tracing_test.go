@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddlewareParentsSpansStartedFromRequestContext(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/items/:id", func(c *fiber.Ctx) error {
+		// Handlers hand fiber's request context to the DB and GitHub code.
+		ctx, ok := withParent(c.Context())
+		if !ok {
+			t.Error("no parent span in the request context")
+		}
+		_, span := tracer().Start(ctx, "child")
+		span.End()
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	if _, err := app.Test(httptest.NewRequest("GET", "/items/7", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	child, server := spans[0], spans[1]
+	if server.Name() != "GET /items/:id" || server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("server span = %q (%v)", server.Name(), server.SpanKind())
+	}
+	if child.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("child span isn't parented to the request span")
+	}
+}
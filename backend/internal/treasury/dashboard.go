@@ -0,0 +1,277 @@
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// rpcTimeout bounds each live balance read so one slow node cannot stall the dashboard.
+const rpcTimeout = 10 * time.Second
+
+// runwayWindow is the lookback used to estimate the daily outflow.
+const runwayWindow = 30 * 24 * time.Hour
+
+type ColdBalance struct {
+	Address string `json:"address"`
+	Label   string `json:"label,omitempty"`
+	Balance string `json:"balance,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type AssetSummary struct {
+	Asset string `json:"asset"`
+
+	// Live on-chain balances.
+	HotBalance string        `json:"hot_balance,omitempty"`
+	HotError   string        `json:"hot_error,omitempty"`
+	Cold       []ColdBalance `json:"cold"`
+	OnChain    string        `json:"on_chain_total"`
+
+	// Ledger view: net balance per ledger account.
+	Ledger map[string]string `json:"ledger"`
+
+	// Obligations are funds owed to users or locked for bounties, including
+	// payouts that are queued but not yet sent.
+	PendingPayouts string `json:"pending_payouts,omitempty"`
+	Obligations    string `json:"obligations"`
+	// Surplus = on-chain total - obligations; negative means insolvent.
+	Surplus string `json:"surplus"`
+
+	Outflow30d string   `json:"outflow_30d"`
+	RunwayDays *float64 `json:"runway_days"`
+}
+
+type ChainSummary struct {
+	Chain      string         `json:"chain"`
+	HotAddress string         `json:"hot_address,omitempty"`
+	Assets     []AssetSummary `json:"assets"`
+}
+
+type Dashboard struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Chains      []ChainSummary `json:"chains"`
+}
+
+// obligationAccounts hold money the platform owes rather than owns.
+var obligationAccounts = []string{ledger.AccountUser, ledger.AccountEscrow}
+
+// BuildDashboard combines live RPC balances with the ledger. Chains appear if
+// they have a hot wallet or any ledger activity.
+func BuildDashboard(ctx context.Context, pool *pgxpool.Pool, wallets wallet.Registry) (Dashboard, error) {
+	if pool == nil {
+		return Dashboard{}, fmt.Errorf("db not configured")
+	}
+
+	// ledger balances keyed by "chain/asset" -> account -> amount
+	ledgerBal := map[string]map[string]*big.Rat{}
+	outflow := map[string]*big.Rat{}
+	rows, err := pool.Query(ctx, `
+SELECT asset, account, SUM(amount)::text,
+       COALESCE(SUM(-amount) FILTER (WHERE account = 'treasury' AND kind = 'payout' AND created_at > now() - $1::interval), 0)::text
+FROM ledger_entries
+GROUP BY asset, account
+`, fmt.Sprintf("%d seconds", int64(runwayWindow.Seconds())))
+	if err != nil {
+		return Dashboard{}, err
+	}
+	for rows.Next() {
+		var asset, account, sum, out string
+		if err := rows.Scan(&asset, &account, &sum, &out); err != nil {
+			rows.Close()
+			return Dashboard{}, err
+		}
+		if ledgerBal[asset] == nil {
+			ledgerBal[asset] = map[string]*big.Rat{}
+		}
+		ledgerBal[asset][account] = ratOrZero(sum)
+		if outflow[asset] == nil {
+			outflow[asset] = new(big.Rat)
+		}
+		outflow[asset].Add(outflow[asset], ratOrZero(out))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Dashboard{}, err
+	}
+
+	// Payouts queued but not yet sent are owed as well. Accruing ones are
+	// already on the user account.
+	unsent := map[string]*big.Rat{}
+	rows, err = pool.Query(ctx, `
+SELECT chain, asset, SUM(amount)::text
+FROM payouts
+WHERE status IN ('pending', 'batched', 'failed')
+GROUP BY chain, asset
+`)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	for rows.Next() {
+		var chainName, asset, sum string
+		if err := rows.Scan(&chainName, &asset, &sum); err != nil {
+			rows.Close()
+			return Dashboard{}, err
+		}
+		unsent[ledger.Asset(chainName, asset)] = ratOrZero(sum)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Dashboard{}, err
+	}
+
+	cold := map[string][]ColdBalance{}
+	rows, err = pool.Query(ctx, `SELECT chain, address, COALESCE(label, '') FROM sweep_destinations WHERE approved_at IS NOT NULL ORDER BY created_at`)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	for rows.Next() {
+		var chainName string
+		var cb ColdBalance
+		if err := rows.Scan(&chainName, &cb.Address, &cb.Label); err != nil {
+			rows.Close()
+			return Dashboard{}, err
+		}
+		cold[chainName] = append(cold[chainName], cb)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Dashboard{}, err
+	}
+
+	// Collect assets per chain from the ledger and sweep policies.
+	assets := map[string]map[string]struct{}{}
+	add := func(chainName, asset string) {
+		if assets[chainName] == nil {
+			assets[chainName] = map[string]struct{}{}
+		}
+		assets[chainName][asset] = struct{}{}
+	}
+	for key := range ledgerBal {
+		if chainName, asset, ok := strings.Cut(key, "/"); ok {
+			add(chainName, asset)
+		}
+	}
+	for key := range unsent {
+		if chainName, asset, ok := strings.Cut(key, "/"); ok {
+			add(chainName, asset)
+		}
+	}
+	policies, err := ListPolicies(ctx, pool)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	for _, p := range policies {
+		add(p.Chain, p.Asset)
+	}
+	for _, chainName := range wallets.Chains() {
+		if assets[chainName] == nil {
+			assets[chainName] = map[string]struct{}{}
+		}
+	}
+
+	d := Dashboard{GeneratedAt: time.Now().UTC(), Chains: []ChainSummary{}}
+	for _, chainName := range sortedKeys(assets) {
+		cs := ChainSummary{Chain: chainName, Assets: []AssetSummary{}}
+		sender, hasWallet := wallets.Get(chainName)
+		if hasWallet {
+			cs.HotAddress = sender.Address()
+		}
+
+		for _, asset := range sortedKeys(assets[chainName]) {
+			key := ledger.Asset(chainName, asset)
+			as := AssetSummary{Asset: asset, Ledger: map[string]string{}, Cold: []ColdBalance{}}
+			onChain := new(big.Rat)
+
+			if hasWallet {
+				if bal, err := balanceWithTimeout(ctx, func(ctx context.Context) (string, error) { return sender.Balance(ctx, asset) }); err != nil {
+					as.HotError = err.Error()
+				} else {
+					as.HotBalance = bal
+					onChain.Add(onChain, ratOrZero(bal))
+				}
+				for _, cb := range cold[chainName] {
+					addr := cb.Address
+					if bal, err := balanceWithTimeout(ctx, func(ctx context.Context) (string, error) { return sender.BalanceOf(ctx, addr, asset) }); err != nil {
+						cb.Error = err.Error()
+					} else {
+						cb.Balance = bal
+						onChain.Add(onChain, ratOrZero(bal))
+					}
+					as.Cold = append(as.Cold, cb)
+				}
+			}
+			as.OnChain = ratString(onChain)
+
+			obligations := new(big.Rat)
+			for account, v := range ledgerBal[key] {
+				as.Ledger[account] = ratString(v)
+			}
+			for _, account := range obligationAccounts {
+				if v, ok := ledgerBal[key][account]; ok {
+					obligations.Add(obligations, v)
+				}
+			}
+			if v, ok := unsent[key]; ok {
+				obligations.Add(obligations, v)
+				as.PendingPayouts = ratString(v)
+			}
+			as.Obligations = ratString(obligations)
+			as.Surplus = ratString(new(big.Rat).Sub(onChain, obligations))
+
+			out := outflow[key]
+			if out == nil {
+				out = new(big.Rat)
+			}
+			as.Outflow30d = ratString(out)
+			if out.Sign() > 0 {
+				perDay := new(big.Rat).Quo(out, big.NewRat(int64(runwayWindow/(24*time.Hour)), 1))
+				days, _ := new(big.Rat).Quo(onChain, perDay).Float64()
+				as.RunwayDays = &days
+			}
+			cs.Assets = append(cs.Assets, as)
+		}
+		d.Chains = append(d.Chains, cs)
+	}
+	return d, nil
+}
+
+func balanceWithTimeout(ctx context.Context, fn func(context.Context) (string, error)) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+func ratOrZero(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+func ratString(r *big.Rat) string {
+	neg := r.Sign() < 0
+	s := wallet.FormatAmount(new(big.Rat).Abs(r), 18)
+	if neg && s != "0" {
+		return "-" + s
+	}
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,168 @@
+package treasury
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/deposits"
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+// Escrow reconciliation statuses.
+const (
+	EscrowBalanced  = "balanced"
+	EscrowSurplus   = "surplus"
+	EscrowShortfall = "shortfall"
+	EscrowUnknown   = "unknown"
+)
+
+// EscrowLine compares what one project escrow address holds of an asset with
+// what the ledger says the project is owed.
+type EscrowLine struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Project   string    `json:"project"`
+	Chain     string    `json:"chain"`
+	Address   string    `json:"address"`
+	Asset     string    `json:"asset"`
+	OnChain   string    `json:"on_chain,omitempty"`
+	Ledgered  string    `json:"ledgered"`
+	// Difference = on-chain - ledgered; negative means funds are missing.
+	Difference string `json:"difference,omitempty"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// EscrowTotal sums the lines of one asset. OnChain only counts the lines
+// whose balance could be read.
+type EscrowTotal struct {
+	Chain    string `json:"chain"`
+	Asset    string `json:"asset"`
+	OnChain  string `json:"on_chain"`
+	Ledgered string `json:"ledgered"`
+	Status   string `json:"status"`
+}
+
+type EscrowReconciliation struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Lines       []EscrowLine  `json:"lines"`
+	Totals      []EscrowTotal `json:"totals"`
+}
+
+// ReconcileEscrow reads the live balance of every project escrow address for
+// each asset the ledger credited to it and compares the two. Assets sent to
+// an address but never credited don't appear; the chain watcher credits
+// every inbound transfer, so a persistent surplus means it is behind.
+func ReconcileEscrow(ctx context.Context, pool *pgxpool.Pool, wallets wallet.Registry) (EscrowReconciliation, error) {
+	if pool == nil {
+		return EscrowReconciliation{}, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT pe.project_id, p.github_full_name, pe.chain, a.address, l.asset, SUM(l.amount)::text
+FROM project_escrow_accounts pe
+JOIN projects p ON p.id = pe.project_id
+JOIN deposit_addresses a ON a.id = pe.deposit_address_id
+JOIN ledger_entries l ON l.account = $1
+  AND l.reference LIKE $2 || pe.project_id::text || ':%'
+  AND l.asset LIKE pe.chain || '/%'
+GROUP BY pe.project_id, p.github_full_name, pe.chain, a.address, l.asset
+ORDER BY pe.chain, l.asset, p.github_full_name
+`, ledger.AccountEscrow, deposits.EscrowReferencePrefix)
+	if err != nil {
+		return EscrowReconciliation{}, err
+	}
+	lines := []EscrowLine{}
+	for rows.Next() {
+		var l EscrowLine
+		if err := rows.Scan(&l.ProjectID, &l.Project, &l.Chain, &l.Address, &l.Asset, &l.Ledgered); err != nil {
+			rows.Close()
+			return EscrowReconciliation{}, err
+		}
+		l.Asset = l.Asset[len(l.Chain)+1:]
+		l.Ledgered = ratString(ratOrZero(l.Ledgered))
+		lines = append(lines, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return EscrowReconciliation{}, err
+	}
+
+	type total struct {
+		onChain, ledgered *big.Rat
+		unknown           bool
+		surplus, short    bool
+	}
+	totals := map[string]*total{}
+	for i := range lines {
+		l := &lines[i]
+		key := ledger.Asset(l.Chain, l.Asset)
+		t := totals[key]
+		if t == nil {
+			t = &total{onChain: new(big.Rat), ledgered: new(big.Rat)}
+			totals[key] = t
+		}
+		ledgered := ratOrZero(l.Ledgered)
+		t.ledgered.Add(t.ledgered, ledgered)
+
+		sender, ok := wallets.Get(l.Chain)
+		if !ok {
+			l.Status, l.Error = EscrowUnknown, "no wallet configured for chain"
+			t.unknown = true
+			continue
+		}
+		bal, err := balanceWithTimeout(ctx, func(ctx context.Context) (string, error) { return sender.BalanceOf(ctx, l.Address, l.Asset) })
+		if errors.Is(err, wallet.ErrAccountNotFound) {
+			bal, err = "0", nil
+		}
+		if err != nil {
+			l.Status, l.Error = EscrowUnknown, err.Error()
+			t.unknown = true
+			continue
+		}
+		onChain := ratOrZero(bal)
+		t.onChain.Add(t.onChain, onChain)
+		diff := new(big.Rat).Sub(onChain, ledgered)
+		l.OnChain, l.Difference, l.Status = ratString(onChain), ratString(diff), escrowStatus(diff)
+		switch l.Status {
+		case EscrowSurplus:
+			t.surplus = true
+		case EscrowShortfall:
+			t.short = true
+		}
+	}
+
+	out := EscrowReconciliation{GeneratedAt: time.Now().UTC(), Lines: lines, Totals: []EscrowTotal{}}
+	for _, key := range sortedKeys(totals) {
+		t := totals[key]
+		et := EscrowTotal{OnChain: ratString(t.onChain), Ledgered: ratString(t.ledgered), Status: EscrowBalanced}
+		et.Chain, et.Asset, _ = strings.Cut(key, "/")
+		// The worst line decides: one short address isn't offset by another's surplus.
+		switch {
+		case t.short:
+			et.Status = EscrowShortfall
+		case t.unknown:
+			et.Status = EscrowUnknown
+		case t.surplus:
+			et.Status = EscrowSurplus
+		}
+		out.Totals = append(out.Totals, et)
+	}
+	return out, nil
+}
+
+func escrowStatus(diff *big.Rat) string {
+	switch diff.Sign() {
+	case 1:
+		return EscrowSurplus
+	case -1:
+		return EscrowShortfall
+	}
+	return EscrowBalanced
+}
@@ -0,0 +1,552 @@
+// Package treasury manages platform funds held in hot and cold wallets.
+package treasury
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/ledger"
+	"github.com/jagadeesh/grainlify/backend/internal/wallet"
+)
+
+const (
+	SweepAwaitingApproval = "awaiting_approval"
+	SweepPending          = "pending"
+	SweepSending          = "sending"
+	SweepSubmitted        = "submitted"
+	SweepFailed           = "failed"
+	SweepCancelled        = "cancelled"
+)
+
+// sweepLockKey is the advisory lock that keeps concurrent instances from
+// sweeping the same balance twice.
+const sweepLockKey = 0x6772_6e5f_7377 // "grn_sw"
+
+// Address book statuses of a Destination.
+const (
+	DestinationPendingApproval = "pending_approval"
+	DestinationVerified        = "verified"
+	DestinationRevoked         = "revoked"
+)
+
+var (
+	ErrDestinationNotFound = errors.New("destination_not_found")
+	ErrAlreadyApproved     = errors.New("already_approved")
+	// ErrSelfApproval: adding an address takes two admins.
+	ErrSelfApproval        = errors.New("approver_must_differ_from_creator")
+	ErrDestinationRevoked  = errors.New("destination_revoked")
+	ErrDestinationMismatch = errors.New("destination_chain_mismatch")
+)
+
+type Destination struct {
+	ID         uuid.UUID  `json:"id"`
+	Chain      string     `json:"chain"`
+	Address    string     `json:"address"`
+	Label      *string    `json:"label,omitempty"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	ApprovedBy *uuid.UUID `json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+	RevokedBy  *uuid.UUID `json:"revoked_by,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+const destinationColumns = `id, chain, address, label, created_by, approved_by, approved_at, revoked_by, revoked_at, created_at`
+
+func scanDestination(row pgx.Row) (Destination, error) {
+	var d Destination
+	err := row.Scan(&d.ID, &d.Chain, &d.Address, &d.Label, &d.CreatedBy, &d.ApprovedBy, &d.ApprovedAt, &d.RevokedBy, &d.RevokedAt, &d.CreatedAt)
+	switch {
+	case d.RevokedAt != nil:
+		d.Status = DestinationRevoked
+	case d.ApprovedAt != nil:
+		d.Status = DestinationVerified
+	default:
+		d.Status = DestinationPendingApproval
+	}
+	return d, err
+}
+
+type Policy struct {
+	ID            uuid.UUID `json:"id"`
+	Chain         string    `json:"chain"`
+	Asset         string    `json:"asset"`
+	DestinationID uuid.UUID `json:"destination_id"`
+	Threshold     string    `json:"threshold"`
+	Retain        string    `json:"retain"`
+	Enabled       bool      `json:"enabled"`
+}
+
+type Sweep struct {
+	ID        uuid.UUID  `json:"id"`
+	PolicyID  *uuid.UUID `json:"policy_id,omitempty"`
+	Chain     string     `json:"chain"`
+	Asset     string     `json:"asset"`
+	From      string     `json:"from_address"`
+	To        string     `json:"to_address"`
+	Amount    string     `json:"amount"`
+	Status    string     `json:"status"`
+	TxHash    *string    `json:"tx_hash,omitempty"`
+	Error     *string    `json:"error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type Sweeper struct {
+	Pool    *pgxpool.Pool
+	Wallets wallet.Registry
+}
+
+// RunOnce evaluates every enabled policy. Sweeps to a destination that has
+// not been approved yet are parked as awaiting_approval instead of sent;
+// policies pointing at a revoked or other-chain destination are skipped.
+func (s *Sweeper) RunOnce(ctx context.Context) error {
+	if s.Pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	conn, err := s.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, sweepLockKey).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer func() { _, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, sweepLockKey) }()
+
+	// Settle interrupted sweeps before measuring balances again.
+	if err := s.resumeSending(ctx); err != nil {
+		slog.Error("failed to resume interrupted cold sweeps", "error", err)
+	}
+
+	rows, err := s.Pool.Query(ctx, `
+SELECT p.id, p.chain, p.asset, p.threshold::text, p.retain::text, d.address, d.approved_at IS NOT NULL
+FROM sweep_policies p
+JOIN sweep_destinations d ON d.id = p.destination_id
+WHERE p.enabled AND d.revoked_at IS NULL AND d.chain = p.chain
+ORDER BY p.chain, p.asset
+`)
+	if err != nil {
+		return err
+	}
+	type job struct {
+		policy   Policy
+		to       string
+		approved bool
+	}
+	var jobs []job
+	for rows.Next() {
+		var j job
+		if err := rows.Scan(&j.policy.ID, &j.policy.Chain, &j.policy.Asset, &j.policy.Threshold, &j.policy.Retain, &j.to, &j.approved); err != nil {
+			rows.Close()
+			return err
+		}
+		jobs = append(jobs, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, j := range jobs {
+		if err := s.sweepOne(ctx, j.policy, j.to, j.approved); err != nil {
+			slog.Error("cold sweep failed", "chain", j.policy.Chain, "asset", j.policy.Asset, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *Sweeper) sweepOne(ctx context.Context, p Policy, to string, approved bool) error {
+	sender, ok := s.Wallets.Get(p.Chain)
+	if !ok {
+		slog.Warn("no hot wallet for sweep policy", "chain", p.Chain)
+		return nil
+	}
+
+	balStr, err := sender.Balance(ctx, p.Asset)
+	if err != nil {
+		return fmt.Errorf("hot balance: %w", err)
+	}
+	bal, err := wallet.ParseAmount(balStr)
+	if err != nil {
+		return err
+	}
+	threshold, err := wallet.ParseAmount(p.Threshold)
+	if err != nil {
+		return err
+	}
+	retain, err := wallet.ParseAmount(p.Retain)
+	if err != nil {
+		return err
+	}
+	if bal.Cmp(threshold) <= 0 {
+		return nil
+	}
+	dec, err := sender.Decimals(ctx, p.Asset)
+	if err != nil {
+		return err
+	}
+	amount := wallet.FormatAmount(new(big.Rat).Sub(bal, retain), dec)
+
+	// A sweep still in flight has not left the hot balance yet.
+	var inFlight bool
+	if err := s.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM sweeps WHERE policy_id = $1 AND status IN ('pending', 'sending'))`, p.ID).Scan(&inFlight); err != nil {
+		return err
+	}
+	if inFlight {
+		return nil
+	}
+
+	// One parked sweep per policy at a time; it is refreshed with the current amount.
+	var parked *uuid.UUID
+	var id uuid.UUID
+	err = s.Pool.QueryRow(ctx, `
+SELECT id FROM sweeps WHERE policy_id = $1 AND status = 'awaiting_approval' ORDER BY created_at LIMIT 1
+`, p.ID).Scan(&id)
+	if err == nil {
+		parked = &id
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if !approved {
+		if parked != nil {
+			_, err = s.Pool.Exec(ctx, `UPDATE sweeps SET amount = $2::numeric, updated_at = now() WHERE id = $1`, *parked, amount)
+			return err
+		}
+		_, err = s.Pool.Exec(ctx, `
+INSERT INTO sweeps (policy_id, chain, asset, from_address, to_address, amount, status)
+VALUES ($1, $2, $3, $4, $5, $6::numeric, 'awaiting_approval')
+`, p.ID, p.Chain, p.Asset, sender.Address(), to, amount)
+		if err == nil {
+			slog.Info("cold sweep awaiting destination approval", "chain", p.Chain, "asset", p.Asset, "to", to, "amount", amount)
+		}
+		return err
+	}
+
+	if parked != nil {
+		_, err = s.Pool.Exec(ctx, `UPDATE sweeps SET status = 'pending', amount = $2::numeric, updated_at = now() WHERE id = $1`, *parked, amount)
+		id = *parked
+	} else {
+		err = s.Pool.QueryRow(ctx, `
+INSERT INTO sweeps (policy_id, chain, asset, from_address, to_address, amount, status)
+VALUES ($1, $2, $3, $4, $5, $6::numeric, 'pending')
+RETURNING id
+`, p.ID, p.Chain, p.Asset, sender.Address(), to, amount).Scan(&id)
+	}
+	if err != nil {
+		return err
+	}
+
+	signed, err := sender.Sign(ctx, p.Asset, []wallet.Payment{{To: to, Amount: amount}})
+	if err != nil {
+		_, _ = s.Pool.Exec(ctx, `UPDATE sweeps SET status = 'failed', error = $2, updated_at = now() WHERE id = $1`, id, err.Error())
+		return err
+	}
+	// Record the signed transaction before broadcasting it, so a sweep cut
+	// short is resumed by resumeSending rather than lost.
+	if _, err := s.Pool.Exec(ctx, `UPDATE sweeps SET status = 'sending', tx_hash = $2, signed_tx = $3, updated_at = now() WHERE id = $1`, id, signed.Hash, signed.Raw); err != nil {
+		return err
+	}
+	if sendErr := sender.Broadcast(ctx, signed); sendErr != nil {
+		_, _ = s.Pool.Exec(ctx, `UPDATE sweeps SET status = 'failed', error = $2, updated_at = now() WHERE id = $1 AND status = 'sending'`, id, sendErr.Error())
+		return sendErr
+	}
+	if err := markSweepSubmitted(ctx, s.Pool, id); err != nil {
+		return err
+	}
+
+	slog.Info("cold sweep submitted", "chain", p.Chain, "asset", p.Asset, "amount", amount, "tx_hash", signed.Hash)
+	return nil
+}
+
+// markSweepSubmitted records a broadcast sweep as submitted and posts it to
+// the ledger, atomically. It does nothing if the sweep is no longer sending.
+func markSweepSubmitted(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var chainName, asset, amount string
+	err = tx.QueryRow(ctx, `
+UPDATE sweeps SET status = 'submitted', updated_at = now()
+WHERE id = $1 AND status = 'sending'
+RETURNING chain, asset, amount::text
+`, id).Scan(&chainName, &asset, &amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ledgerAsset := ledger.Asset(chainName, asset)
+	ref := "sweep:" + id.String()
+	if _, err := ledger.Append(ctx, tx, nil, ledger.AccountTreasury, ledger.KindSweep, ledgerAsset, "-"+amount, ref); err != nil {
+		return err
+	}
+	if _, err := ledger.Append(ctx, tx, nil, ledger.AccountCold, ledger.KindSweep, ledgerAsset, amount, ref); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// staleSending is how long a sweep may stay pending or sending before
+// resumeSending takes it to be interrupted.
+const staleSending = 2 * time.Minute
+
+// resumeSending finishes sweeps interrupted mid-send: a signed transaction
+// the chain already has, or that broadcasts again, is recorded as submitted;
+// anything else fails, and the next run sweeps the balance afresh.
+func (s *Sweeper) resumeSending(ctx context.Context) error {
+	rows, err := s.Pool.Query(ctx, `
+SELECT id, chain, status, tx_hash, signed_tx
+FROM sweeps
+WHERE status IN ('pending', 'sending') AND updated_at < now() - $1::interval
+ORDER BY updated_at
+`, fmt.Sprintf("%d seconds", int64(staleSending.Seconds())))
+	if err != nil {
+		return err
+	}
+	type stale struct {
+		id            uuid.UUID
+		chain, status string
+		hash, raw     *string
+	}
+	var list []stale
+	for rows.Next() {
+		var st stale
+		if err := rows.Scan(&st.id, &st.chain, &st.status, &st.hash, &st.raw); err != nil {
+			rows.Close()
+			return err
+		}
+		list = append(list, st)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	fail := func(id uuid.UUID, msg string) error {
+		_, err := s.Pool.Exec(ctx, `UPDATE sweeps SET status = 'failed', error = $2, updated_at = now() WHERE id = $1 AND status IN ('pending', 'sending')`, id, msg)
+		return err
+	}
+	var errs []error
+	for _, st := range list {
+		if st.hash == nil || st.raw == nil {
+			errs = append(errs, fail(st.id, "sweep interrupted; check the hot wallet's transactions"))
+			continue
+		}
+		sender, ok := s.Wallets.Get(st.chain)
+		if !ok {
+			continue
+		}
+		landed, err := wallet.Landed(ctx, sender, *st.hash)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !landed {
+			if err := sender.Broadcast(ctx, wallet.SignedTx{Hash: *st.hash, Raw: *st.raw}); err != nil {
+				slog.Error("interrupted cold sweep could not be resent", "sweep_id", st.id.String(), "tx_hash", *st.hash, "error", err)
+				errs = append(errs, fail(st.id, err.Error()))
+				continue
+			}
+		}
+		if err := markSweepSubmitted(ctx, s.Pool, st.id); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		slog.Info("interrupted cold sweep resumed", "sweep_id", st.id.String(), "chain", st.chain, "tx_hash", *st.hash, "resent", !landed)
+	}
+	return errors.Join(errs...)
+}
+
+func ListDestinations(ctx context.Context, pool *pgxpool.Pool) ([]Destination, error) {
+	rows, err := pool.Query(ctx, `
+SELECT `+destinationColumns+`
+FROM sweep_destinations
+ORDER BY chain, created_at
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Destination{}
+	for rows.Next() {
+		d, err := scanDestination(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ApproveDestination verifies an address book entry, unlocking sweeps to it
+// including any parked ones. The approver must not be the admin who added it.
+func ApproveDestination(ctx context.Context, pool *pgxpool.Pool, id, approver uuid.UUID) error {
+	ct, err := pool.Exec(ctx, `
+UPDATE sweep_destinations SET approved_by = $2, approved_at = now()
+WHERE id = $1 AND approved_at IS NULL AND revoked_at IS NULL
+  AND created_by IS DISTINCT FROM $2
+`, id, approver)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		d, err := scanDestination(pool.QueryRow(ctx, `SELECT `+destinationColumns+` FROM sweep_destinations WHERE id = $1`, id))
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDestinationNotFound
+		case err != nil:
+			return err
+		case d.RevokedAt != nil:
+			return ErrDestinationRevoked
+		case d.ApprovedAt != nil:
+			return ErrAlreadyApproved
+		}
+		return ErrSelfApproval
+	}
+	return nil
+}
+
+// RevokeDestination removes an entry from the address book. Sweeps to it stop,
+// and parked ones are cancelled.
+func RevokeDestination(ctx context.Context, pool *pgxpool.Pool, id, actor uuid.UUID) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var address, chainName string
+	err = tx.QueryRow(ctx, `
+UPDATE sweep_destinations SET revoked_by = $2, revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING chain, address
+`, id, actor).Scan(&chainName, &address)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM sweep_destinations WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrDestinationNotFound
+		}
+		return ErrDestinationRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE sweeps SET status = 'cancelled', updated_at = now()
+WHERE status = 'awaiting_approval' AND chain = $1 AND to_address = $2
+`, chainName, address); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func ListSweeps(ctx context.Context, pool *pgxpool.Pool, limit int) ([]Sweep, error) {
+	rows, err := pool.Query(ctx, `
+SELECT id, policy_id, chain, asset, from_address, to_address, amount::text, status, tx_hash, error, created_at
+FROM sweeps
+ORDER BY created_at DESC
+LIMIT $1
+`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Sweep{}
+	for rows.Next() {
+		var sw Sweep
+		if err := rows.Scan(&sw.ID, &sw.PolicyID, &sw.Chain, &sw.Asset, &sw.From, &sw.To, &sw.Amount, &sw.Status, &sw.TxHash, &sw.Error, &sw.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, sw)
+	}
+	return out, rows.Err()
+}
+
+func CreateDestination(ctx context.Context, pool *pgxpool.Pool, chain, address, label string, createdBy uuid.UUID) (Destination, error) {
+	d, err := scanDestination(pool.QueryRow(ctx, `
+INSERT INTO sweep_destinations (chain, address, label, created_by)
+VALUES ($1, $2, NULLIF($3, ''), $4)
+RETURNING `+destinationColumns,
+		chain, address, label, createdBy))
+	if err != nil {
+		return Destination{}, err
+	}
+	// Watch it so sweeps show up as internal transfers in chain_transfers.
+	_, err = pool.Exec(ctx, `
+INSERT INTO watched_addresses (chain, address, purpose, label)
+VALUES ($1, $2, 'cold', NULLIF($3, ''))
+ON CONFLICT (chain, address) DO NOTHING
+`, chain, address, label)
+	return d, err
+}
+
+func ListPolicies(ctx context.Context, pool *pgxpool.Pool) ([]Policy, error) {
+	rows, err := pool.Query(ctx, `
+SELECT id, chain, asset, destination_id, threshold::text, retain::text, enabled
+FROM sweep_policies
+ORDER BY chain, asset
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Policy{}
+	for rows.Next() {
+		var p Policy
+		if err := rows.Scan(&p.ID, &p.Chain, &p.Asset, &p.DestinationID, &p.Threshold, &p.Retain, &p.Enabled); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// UpsertPolicy creates or replaces the policy for (chain, asset). The
+// destination must be a live address book entry on the same chain.
+func UpsertPolicy(ctx context.Context, pool *pgxpool.Pool, p Policy) (Policy, error) {
+	var destChain string
+	var revokedAt *time.Time
+	err := pool.QueryRow(ctx, `SELECT chain, revoked_at FROM sweep_destinations WHERE id = $1`, p.DestinationID).Scan(&destChain, &revokedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return Policy{}, ErrDestinationNotFound
+	case err != nil:
+		return Policy{}, err
+	case revokedAt != nil:
+		return Policy{}, ErrDestinationRevoked
+	case destChain != p.Chain:
+		return Policy{}, ErrDestinationMismatch
+	}
+	err = pool.QueryRow(ctx, `
+INSERT INTO sweep_policies (chain, asset, destination_id, threshold, retain, enabled)
+VALUES ($1, $2, $3, $4::numeric, $5::numeric, $6)
+ON CONFLICT (chain, asset) DO UPDATE SET
+  destination_id = EXCLUDED.destination_id,
+  threshold = EXCLUDED.threshold,
+  retain = EXCLUDED.retain,
+  enabled = EXCLUDED.enabled,
+  updated_at = now()
+RETURNING id, threshold::text, retain::text
+`, p.Chain, p.Asset, p.DestinationID, p.Threshold, p.Retain, p.Enabled).Scan(&p.ID, &p.Threshold, &p.Retain)
+	return p, err
+}
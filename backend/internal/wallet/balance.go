@@ -0,0 +1,110 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/strkey"
+)
+
+// ErrAccountNotFound means the address has never been funded on chain
+// (Stellar accounts only exist once they hold the base reserve).
+var ErrAccountNotFound = errors.New("account_not_found")
+
+// nonceReader is implemented by account-based chains that expose how many
+// transactions an address has sent.
+type nonceReader interface {
+	NonceOf(ctx context.Context, address string) (uint64, error)
+}
+
+// AddressBalance is a point-in-time balance of any address on one chain.
+type AddressBalance struct {
+	Chain   string `json:"chain"`
+	Asset   string `json:"asset"`
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+	// Fresh is true for addresses with no on-chain history: unfunded Stellar
+	// accounts, or EVM addresses that never sent a transaction and hold
+	// nothing. Transfers to them are often typos.
+	Fresh     bool      `json:"fresh"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// StellarAccountID accepts a G... account id or a hex ed25519 public key,
+// which is how Stellar wallets are stored at sign-in.
+func StellarAccountID(address string) (string, bool) {
+	address = strings.TrimSpace(address)
+	if strkey.IsValidEd25519PublicKey(address) {
+		return address, true
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil || len(raw) != 32 {
+		return "", false
+	}
+	id, err := strkey.Encode(strkey.VersionByteAccountID, raw)
+	return id, err == nil
+}
+
+// BalanceCache memoizes address balance lookups for TTL so repeated UI
+// previews don't hit RPC providers on every render.
+type BalanceCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]AddressBalance
+}
+
+func NewBalanceCache(ttl time.Duration) *BalanceCache {
+	return &BalanceCache{TTL: ttl, entries: map[string]AddressBalance{}}
+}
+
+// Lookup returns the cached balance of address on s's chain, refreshing it
+// once older than TTL. Errors are not cached.
+func (c *BalanceCache) Lookup(ctx context.Context, s Sender, address, asset string) (AddressBalance, error) {
+	key := s.Chain() + "|" + strings.ToLower(asset) + "|" + strings.ToLower(address)
+	now := time.Now().UTC()
+	c.mu.Lock()
+	if b, ok := c.entries[key]; ok && now.Sub(b.CheckedAt) < c.TTL {
+		c.mu.Unlock()
+		return b, nil
+	}
+	c.mu.Unlock()
+
+	b := AddressBalance{Chain: s.Chain(), Asset: asset, Address: address, CheckedAt: now}
+	bal, err := s.BalanceOf(ctx, address, asset)
+	switch {
+	case errors.Is(err, ErrAccountNotFound):
+		b.Balance, b.Fresh = "0", true
+	case err != nil:
+		return AddressBalance{}, err
+	default:
+		b.Balance = bal
+		if nr, ok := s.(nonceReader); ok && isZeroAmount(bal) {
+			nonce, err := nr.NonceOf(ctx, address)
+			if err != nil {
+				return AddressBalance{}, err
+			}
+			b.Fresh = nonce == 0
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Drop stale entries as we go so the map can't grow without bound.
+	for k, e := range c.entries {
+		if now.Sub(e.CheckedAt) >= c.TTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = b
+	return b, nil
+}
+
+func isZeroAmount(s string) bool {
+	r, err := ParseAmount(s)
+	return err == nil && r.Sign() == 0
+}
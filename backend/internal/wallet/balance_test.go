@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeSender struct {
+	Sender
+	balance string
+	err     error
+	nonce   uint64
+	calls   int
+}
+
+func (f *fakeSender) Chain() string { return "base" }
+
+func (f *fakeSender) BalanceOf(context.Context, string, string) (string, error) {
+	f.calls++
+	return f.balance, f.err
+}
+
+func (f *fakeSender) NonceOf(context.Context, string) (uint64, error) { return f.nonce, nil }
+
+func TestBalanceCache(t *testing.T) {
+	ctx := context.Background()
+	s := &fakeSender{balance: "0"}
+	c := NewBalanceCache(time.Minute)
+
+	b, err := c.Lookup(ctx, s, "0xAbC", "native")
+	if err != nil || !b.Fresh || b.Balance != "0" {
+		t.Fatalf("empty address: %+v, %v", b, err)
+	}
+	if _, err := c.Lookup(ctx, s, "0xabc", "NATIVE"); err != nil || s.calls != 1 {
+		t.Fatalf("expected cached lookup, calls = %d, err = %v", s.calls, err)
+	}
+
+	s2 := &fakeSender{balance: "0", nonce: 3}
+	if b, _ := c.Lookup(ctx, s2, "0xdef", "native"); b.Fresh {
+		t.Fatal("address with sent transactions reported fresh")
+	}
+
+	s3 := &fakeSender{err: ErrAccountNotFound}
+	if b, err := c.Lookup(ctx, s3, "0x123", "native"); err != nil || !b.Fresh {
+		t.Fatalf("unfunded account: %+v, %v", b, err)
+	}
+}
+
+func TestStellarAccountID(t *testing.T) {
+	const g = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+	if id, ok := StellarAccountID(g); !ok || id != g {
+		t.Fatalf("account id rejected: %q %v", id, ok)
+	}
+	hexKey := "62fc1d0bd091b2b61c0dd6563468ad2b47d347c6f2c2c8ee6d0447024b7e05f7"
+	if id, ok := StellarAccountID(hexKey); !ok || id[0] != 'G' {
+		t.Fatalf("hex key not converted: %q %v", id, ok)
+	}
+	if _, ok := StellarAccountID("not-a-key"); ok {
+		t.Fatal("garbage accepted")
+	}
+}
@@ -0,0 +1,281 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ERC-20 selectors.
+var (
+	selTransfer  = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	selBalanceOf = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+	selDecimals  = crypto.Keccak256([]byte("decimals()"))[:4]
+)
+
+// smartAccountABI is the batch entry point of ERC-4337 accounts such as
+// SimpleAccount v0.7, which the owner key may call directly.
+var smartAccountABI = mustABI(`[{"type":"function","name":"executeBatch","inputs":[
+	{"name":"dest","type":"address[]"},{"name":"value","type":"uint256[]"},{"name":"func","type":"bytes[]"}]}]`)
+
+func mustABI(js string) abi.ABI {
+	a, err := abi.JSON(strings.NewReader(js))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// maxSmartAccountBatch caps executeBatch calls well below the block gas limit.
+const maxSmartAccountBatch = 50
+
+// EVMSender sends native coin (asset "native" or the chain's symbol) or ERC-20
+// tokens (asset = contract address). From a plain key each transfer is its own
+// transaction; with a smart account configured, funds are held by the account
+// and up to maxSmartAccountBatch transfers go out in one executeBatch call.
+type EVMSender struct {
+	chain   string
+	rpc     *ethclient.Client
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	chainID *big.Int
+	account *common.Address
+	// confirmations is the block depth at which TxStatus reports success.
+	confirmations uint64
+	// maxGasPrice is the gas price at which Load reports the chain full.
+	maxGasPrice *big.Int
+}
+
+func NewEVMSender(ctx context.Context, chain, rpcURL, keyHex string) (*EVMSender, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(keyHex), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid evm hot wallet key: %w", err)
+	}
+	rpc, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s rpc: %w", chain, err)
+	}
+	chainID, err := rpc.ChainID(ctx)
+	if err != nil {
+		rpc.Close()
+		return nil, fmt.Errorf("%s chain id: %w", chain, err)
+	}
+	return &EVMSender{
+		chain:         chain,
+		rpc:           rpc,
+		key:           key,
+		from:          crypto.PubkeyToAddress(key.PublicKey),
+		chainID:       chainID,
+		confirmations: DefaultEVMConfirmations,
+	}, nil
+}
+
+// UseSmartAccount makes the sender hold funds in, and pay from, an ERC-4337
+// account owned by the hot wallet key.
+func (s *EVMSender) UseSmartAccount(address string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid smart account address %q", address)
+	}
+	a := common.HexToAddress(address)
+	s.account = &a
+	return nil
+}
+
+func (s *EVMSender) Chain() string { return s.chain }
+
+func (s *EVMSender) Address() string {
+	if s.account != nil {
+		return s.account.Hex()
+	}
+	return s.from.Hex()
+}
+
+func (s *EVMSender) MaxBatch() int {
+	if s.account != nil {
+		return maxSmartAccountBatch
+	}
+	return 1
+}
+
+// SignerAddress is the hot wallet key's own address, which is msg.sender for
+// SendRaw even when funds are held in a smart account.
+func (s *EVMSender) SignerAddress() string { return s.from.Hex() }
+
+// Client exposes the RPC client for chain-specific extensions (e.g. relayers).
+func (s *EVMSender) Client() *ethclient.Client { return s.rpc }
+
+func isToken(asset string) bool {
+	return common.IsHexAddress(asset)
+}
+
+func (s *EVMSender) Decimals(ctx context.Context, asset string) (int, error) {
+	if !isToken(asset) {
+		return 18, nil
+	}
+	token := common.HexToAddress(asset)
+	out, err := s.rpc.CallContract(ctx, ethereum.CallMsg{To: &token, Data: selDecimals}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("token decimals: %w", err)
+	}
+	return int(new(big.Int).SetBytes(out).Int64()), nil
+}
+
+func (s *EVMSender) Balance(ctx context.Context, asset string) (string, error) {
+	return s.BalanceOf(ctx, s.Address(), asset)
+}
+
+func (s *EVMSender) BalanceOf(ctx context.Context, address, asset string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("invalid evm address %q", address)
+	}
+	owner := common.HexToAddress(address)
+	dec, err := s.Decimals(ctx, asset)
+	if err != nil {
+		return "", err
+	}
+	if !isToken(asset) {
+		bal, err := s.rpc.BalanceAt(ctx, owner, nil)
+		if err != nil {
+			return "", err
+		}
+		return FromBaseUnits(bal, dec), nil
+	}
+	token := common.HexToAddress(asset)
+	data := append(append([]byte{}, selBalanceOf...), common.LeftPadBytes(owner.Bytes(), 32)...)
+	out, err := s.rpc.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return "", fmt.Errorf("token balance: %w", err)
+	}
+	return FromBaseUnits(new(big.Int).SetBytes(out), dec), nil
+}
+
+// NonceOf is the number of transactions address has sent.
+func (s *EVMSender) NonceOf(ctx context.Context, address string) (uint64, error) {
+	if !common.IsHexAddress(address) {
+		return 0, fmt.Errorf("invalid evm address %q", address)
+	}
+	return s.rpc.NonceAt(ctx, common.HexToAddress(address), nil)
+}
+
+func (s *EVMSender) Sign(ctx context.Context, asset string, payments []Payment) (SignedTx, error) {
+	if len(payments) == 0 {
+		return SignedTx{}, fmt.Errorf("no payments")
+	}
+	if len(payments) > s.MaxBatch() {
+		return SignedTx{}, fmt.Errorf("evm send takes at most %d payments, got %d", s.MaxBatch(), len(payments))
+	}
+	dec, err := s.Decimals(ctx, asset)
+	if err != nil {
+		return SignedTx{}, err
+	}
+
+	dests := make([]common.Address, len(payments))
+	values := make([]*big.Int, len(payments))
+	calls := make([][]byte, len(payments))
+	for i, p := range payments {
+		if !common.IsHexAddress(p.To) {
+			return SignedTx{}, fmt.Errorf("invalid evm address %q", p.To)
+		}
+		amt, err := ParseAmount(p.Amount)
+		if err != nil {
+			return SignedTx{}, err
+		}
+		units := ToBaseUnits(amt, dec)
+		dests[i], values[i], calls[i] = common.HexToAddress(p.To), units, []byte{}
+		if isToken(asset) {
+			dests[i], values[i] = common.HexToAddress(asset), big.NewInt(0)
+			calls[i] = append(append(append([]byte{}, selTransfer...),
+				common.LeftPadBytes(common.HexToAddress(p.To).Bytes(), 32)...),
+				common.LeftPadBytes(units.Bytes(), 32)...)
+		}
+	}
+
+	to, value, data := dests[0], values[0], []byte(nil)
+	if len(calls[0]) > 0 {
+		data = calls[0]
+	}
+	if s.account != nil {
+		to, value = *s.account, big.NewInt(0)
+		if data, err = smartAccountABI.Pack("executeBatch", dests, values, calls); err != nil {
+			return SignedTx{}, fmt.Errorf("encode executeBatch: %w", err)
+		}
+	}
+	tx, err := s.signRaw(ctx, to, value, data)
+	if err != nil {
+		return SignedTx{}, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return SignedTx{}, err
+	}
+	return SignedTx{Hash: tx.Hash().Hex(), Raw: hexutil.Encode(raw)}, nil
+}
+
+func (s *EVMSender) Broadcast(ctx context.Context, signed SignedTx) error {
+	raw, err := hexutil.Decode(signed.Raw)
+	if err != nil {
+		return fmt.Errorf("decode signed transaction: %w", err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return fmt.Errorf("decode signed transaction: %w", err)
+	}
+	if err := s.rpc.SendTransaction(ctx, tx); err != nil && !strings.Contains(err.Error(), "already known") {
+		return fmt.Errorf("send transaction: %w", err)
+	}
+	return nil
+}
+
+// SendRaw signs and submits an EIP-1559 transaction from the hot wallet key,
+// which also pays gas when a smart account is in use.
+func (s *EVMSender) SendRaw(ctx context.Context, to common.Address, value *big.Int, data []byte) (string, error) {
+	tx, err := s.signRaw(ctx, to, value, data)
+	if err != nil {
+		return "", err
+	}
+	if err := s.rpc.SendTransaction(ctx, tx); err != nil {
+		return "", fmt.Errorf("send transaction: %w", err)
+	}
+	return tx.Hash().Hex(), nil
+}
+
+func (s *EVMSender) signRaw(ctx context.Context, to common.Address, value *big.Int, data []byte) (*types.Transaction, error) {
+	nonce, err := s.rpc.PendingNonceAt(ctx, s.from)
+	if err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+	tip, err := s.rpc.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gas tip: %w", err)
+	}
+	head, err := s.rpc.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("latest header: %w", err)
+	}
+	feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	gas, err := s.rpc.EstimateGas(ctx, ethereum.CallMsg{From: s.from, To: &to, Value: value, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("estimate gas: %w", err)
+	}
+
+	return types.SignNewTx(s.key, types.LatestSignerForChainID(s.chainID), &types.DynamicFeeTx{
+		ChainID:   s.chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        &to,
+		Value:     value,
+		Data:      data,
+	})
+}
@@ -0,0 +1,41 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// LoadReader is implemented by senders that can tell how busy their chain
+// is, as a fraction of capacity where 1 is full.
+type LoadReader interface {
+	Load(ctx context.Context) (float64, error)
+}
+
+// SetMaxGasPrice sets the gas price, in wei, at which Load reports the chain
+// full. Without one Load always reports zero.
+func (s *EVMSender) SetMaxGasPrice(wei *big.Int) {
+	s.maxGasPrice = wei
+}
+
+// Load is the suggested gas price as a fraction of the configured maximum.
+func (s *EVMSender) Load(ctx context.Context) (float64, error) {
+	if s.maxGasPrice == nil || s.maxGasPrice.Sign() <= 0 {
+		return 0, nil
+	}
+	price, err := s.rpc.SuggestGasPrice(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s gas price: %w", s.chain, err)
+	}
+	f, _ := new(big.Rat).SetFrac(price, s.maxGasPrice).Float64()
+	return f, nil
+}
+
+// Load is how full recent ledgers were, as reported by Horizon.
+func (s *StellarSender) Load(ctx context.Context) (float64, error) {
+	stats, err := s.client.GetHorizonClient().FeeStats()
+	if err != nil {
+		return 0, fmt.Errorf("stellar fee stats: %w", err)
+	}
+	return stats.LedgerCapacityUsage, nil
+}
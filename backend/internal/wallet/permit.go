@@ -0,0 +1,180 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrRelayUnsupported is returned when a chain cannot relay permit transfers;
+// the relayer needs a smart account so permit and both transferFroms land in
+// one executeBatch call.
+var ErrRelayUnsupported = errors.New("permit relay unsupported on this chain")
+
+var erc2612ABI = mustABI(`[
+{"type":"function","name":"permit","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"},{"name":"value","type":"uint256"},{"name":"deadline","type":"uint256"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}]},
+{"type":"function","name":"transferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}]},
+{"type":"function","name":"nonces","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+{"type":"function","name":"name","inputs":[],"outputs":[{"name":"","type":"string"}]},
+{"type":"function","name":"version","inputs":[],"outputs":[{"name":"","type":"string"}]}
+]`)
+
+// PermitRelayer is implemented by senders that can relay EIP-2612 transfers.
+type PermitRelayer interface {
+	PermitSpender() (string, bool)
+	PermitTypedData(ctx context.Context, token, owner string, value *big.Int, deadline int64) (map[string]any, error)
+	RelayPermitTransfer(ctx context.Context, p PermitTransfer) (string, error)
+}
+
+// PermitTransfer moves Value of Token out of Owner's wallet using an EIP-2612
+// signature: Value-Fee goes to To and Fee to the relayer. Owner never pays gas.
+type PermitTransfer struct {
+	Token     string
+	Owner     string
+	To        string
+	Value     *big.Int
+	Fee       *big.Int
+	Deadline  int64
+	Signature string // 65-byte hex r||s||v
+}
+
+// PermitSpender is the address users authorize in their permit signature.
+func (s *EVMSender) PermitSpender() (string, bool) {
+	if s.account == nil {
+		return "", false
+	}
+	return s.account.Hex(), true
+}
+
+func (s *EVMSender) callToken(ctx context.Context, token common.Address, method string, args ...any) ([]any, error) {
+	data, err := erc2612ABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.rpc.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return erc2612ABI.Unpack(method, out)
+}
+
+// PermitTypedData returns the EIP-712 payload the owner signs with their
+// wallet (eth_signTypedData_v4) to authorize a relayed transfer of value.
+func (s *EVMSender) PermitTypedData(ctx context.Context, token, owner string, value *big.Int, deadline int64) (map[string]any, error) {
+	spender, ok := s.PermitSpender()
+	if !ok {
+		return nil, ErrRelayUnsupported
+	}
+	if !common.IsHexAddress(token) || !common.IsHexAddress(owner) {
+		return nil, fmt.Errorf("invalid token or owner address")
+	}
+	tokenAddr := common.HexToAddress(token)
+
+	name, err := s.callToken(ctx, tokenAddr, "name")
+	if err != nil || len(name) == 0 {
+		return nil, fmt.Errorf("token name: %w", err)
+	}
+	nonce, err := s.callToken(ctx, tokenAddr, "nonces", common.HexToAddress(owner))
+	if err != nil || len(nonce) == 0 {
+		return nil, fmt.Errorf("token does not support EIP-2612 permit")
+	}
+	// version() is optional; OpenZeppelin's default is "1".
+	version := "1"
+	if v, err := s.callToken(ctx, tokenAddr, "version"); err == nil && len(v) == 1 {
+		if str, ok := v[0].(string); ok && str != "" {
+			version = str
+		}
+	}
+
+	return map[string]any{
+		"types": map[string]any{
+			"EIP712Domain": []map[string]string{
+				{"name": "name", "type": "string"},
+				{"name": "version", "type": "string"},
+				{"name": "chainId", "type": "uint256"},
+				{"name": "verifyingContract", "type": "address"},
+			},
+			"Permit": []map[string]string{
+				{"name": "owner", "type": "address"},
+				{"name": "spender", "type": "address"},
+				{"name": "value", "type": "uint256"},
+				{"name": "nonce", "type": "uint256"},
+				{"name": "deadline", "type": "uint256"},
+			},
+		},
+		"primaryType": "Permit",
+		"domain": map[string]any{
+			"name":              name[0],
+			"version":           version,
+			"chainId":           s.chainID.String(),
+			"verifyingContract": tokenAddr.Hex(),
+		},
+		"message": map[string]any{
+			"owner":    common.HexToAddress(owner).Hex(),
+			"spender":  spender,
+			"value":    value.String(),
+			"nonce":    nonce[0].(*big.Int).String(),
+			"deadline": fmt.Sprint(deadline),
+		},
+	}, nil
+}
+
+// RelayPermitTransfer submits permit + transferFrom(owner→to) +
+// transferFrom(owner→relayer fee) as one smart-account batch, paying gas from
+// the hot wallet key.
+func (s *EVMSender) RelayPermitTransfer(ctx context.Context, p PermitTransfer) (string, error) {
+	if s.account == nil {
+		return "", ErrRelayUnsupported
+	}
+	if !common.IsHexAddress(p.Token) || !common.IsHexAddress(p.Owner) || !common.IsHexAddress(p.To) {
+		return "", fmt.Errorf("invalid address")
+	}
+	if p.Fee.Sign() < 0 || p.Value.Cmp(p.Fee) <= 0 {
+		return "", fmt.Errorf("value must exceed relayer fee")
+	}
+	sig, err := hexutil.Decode(strings.TrimSpace(p.Signature))
+	if err != nil || len(sig) != 65 {
+		return "", fmt.Errorf("signature must be 65 bytes of hex")
+	}
+	var r, sv [32]byte
+	copy(r[:], sig[:32])
+	copy(sv[:], sig[32:64])
+	v := sig[64]
+	if v < 27 {
+		v += 27
+	}
+
+	token := common.HexToAddress(p.Token)
+	owner := common.HexToAddress(p.Owner)
+	permit, err := erc2612ABI.Pack("permit", owner, *s.account, p.Value, big.NewInt(p.Deadline), v, r, sv)
+	if err != nil {
+		return "", err
+	}
+	toRecipient, err := erc2612ABI.Pack("transferFrom", owner, common.HexToAddress(p.To), new(big.Int).Sub(p.Value, p.Fee))
+	if err != nil {
+		return "", err
+	}
+	dests := []common.Address{token, token}
+	values := []*big.Int{big.NewInt(0), big.NewInt(0)}
+	calls := [][]byte{permit, toRecipient}
+	if p.Fee.Sign() > 0 {
+		toRelayer, err := erc2612ABI.Pack("transferFrom", owner, *s.account, p.Fee)
+		if err != nil {
+			return "", err
+		}
+		dests = append(dests, token)
+		values = append(values, big.NewInt(0))
+		calls = append(calls, toRelayer)
+	}
+	data, err := smartAccountABI.Pack("executeBatch", dests, values, calls)
+	if err != nil {
+		return "", fmt.Errorf("encode executeBatch: %w", err)
+	}
+	return s.SendRaw(ctx, *s.account, big.NewInt(0), data)
+}
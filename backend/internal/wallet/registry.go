@@ -0,0 +1,76 @@
+package wallet
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+// NewRegistryFromConfig builds senders for every chain with key material
+// configured. Misconfigured chains are logged and skipped.
+func NewRegistryFromConfig(ctx context.Context, cfg config.Config) Registry {
+	r := Registry{}
+
+	if cfg.StellarHotWalletSecret != "" && cfg.SorobanRPCURL != "" {
+		client, err := soroban.NewClient(soroban.Config{
+			RPCURL:            cfg.SorobanRPCURL,
+			NetworkPassphrase: cfg.SorobanNetworkPassphrase,
+			Network:           soroban.Network(cfg.SorobanNetwork),
+		})
+		if err == nil {
+			var s *StellarSender
+			if s, err = NewStellarSender(client, cfg.StellarHotWalletSecret); err == nil {
+				r["stellar"] = s
+			}
+		}
+		if err != nil {
+			slog.Error("stellar hot wallet disabled", "error", err)
+		}
+	}
+
+	if cfg.EVMHotWalletKeyHex != "" {
+		accounts := map[string]string{}
+		for _, pair := range strings.Split(cfg.EVMSmartAccounts, ",") {
+			if chain, addr, ok := strings.Cut(strings.TrimSpace(pair), "="); ok {
+				accounts[strings.ToLower(chain)] = addr
+			}
+		}
+		maxGas := map[string]*big.Int{}
+		for _, pair := range strings.Split(cfg.PayoutMaxGasGwei, ",") {
+			chain, gwei, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+			if g, err := ParseAmount(gwei); err == nil {
+				maxGas[strings.ToLower(chain)] = ToBaseUnits(g, 9)
+			}
+		}
+		for _, pair := range strings.Split(cfg.EVMRPCURLs, ",") {
+			chain, url, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || chain == "" || url == "" {
+				continue
+			}
+			chain = strings.ToLower(chain)
+			s, err := NewEVMSender(ctx, chain, url, cfg.EVMHotWalletKeyHex)
+			if err != nil {
+				slog.Error("evm hot wallet disabled", "chain", chain, "error", err)
+				continue
+			}
+			s.SetConfirmations(cfg.EVMPayoutConfirmations)
+			if wei, ok := maxGas[chain]; ok {
+				s.SetMaxGasPrice(wei)
+			}
+			if addr, ok := accounts[chain]; ok {
+				if err := s.UseSmartAccount(addr); err != nil {
+					slog.Error("evm smart account ignored", "chain", chain, "error", err)
+				}
+			}
+			r[chain] = s
+		}
+	}
+	return r
+}
@@ -0,0 +1,100 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stellar/go/clients/horizonclient"
+)
+
+// TxState is where a submitted transaction stands on chain.
+type TxState string
+
+const (
+	// TxPending transactions are not yet included, or not yet deep enough
+	// to be considered final.
+	TxPending   TxState = "pending"
+	TxConfirmed TxState = "confirmed"
+	// TxFailed transactions were included but reverted.
+	TxFailed TxState = "failed"
+)
+
+// TxStatus is a transaction's state and, once included, its block (ledger on
+// Stellar) and how many blocks deep it is.
+type TxStatus struct {
+	State         TxState
+	Block         uint64
+	Confirmations uint64
+}
+
+// Tracker is implemented by senders that can look up a transaction by hash,
+// including ones sent by other signers on the same chain.
+type Tracker interface {
+	TxStatus(ctx context.Context, hash string) (TxStatus, error)
+}
+
+// DefaultEVMConfirmations is how deep an EVM transaction must be before it
+// counts as confirmed, unless SetConfirmations says otherwise.
+const DefaultEVMConfirmations = 12
+
+// SetConfirmations sets how many blocks (including its own) a transaction
+// needs before TxStatus reports it confirmed; n < 1 means one.
+func (s *EVMSender) SetConfirmations(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.confirmations = uint64(n)
+}
+
+func (s *EVMSender) TxStatus(ctx context.Context, hash string) (TxStatus, error) {
+	rcpt, err := s.rpc.TransactionReceipt(ctx, common.HexToHash(hash))
+	if errors.Is(err, ethereum.NotFound) {
+		return TxStatus{State: TxPending}, nil
+	}
+	if err != nil {
+		return TxStatus{}, fmt.Errorf("%s receipt: %w", s.chain, err)
+	}
+	head, err := s.rpc.BlockNumber(ctx)
+	if err != nil {
+		return TxStatus{}, fmt.Errorf("%s block number: %w", s.chain, err)
+	}
+	return evmTxStatus(rcpt.Status, rcpt.BlockNumber.Uint64(), head, s.confirmations), nil
+}
+
+// evmTxStatus evaluates a receipt included in block against the chain head.
+// A reverted transaction fails at once; a successful one is confirmed once
+// depth blocks deep.
+func evmTxStatus(status, block, head, depth uint64) TxStatus {
+	st := TxStatus{State: TxPending, Block: block}
+	if head >= block {
+		st.Confirmations = head - block + 1
+	}
+	switch {
+	case status != types.ReceiptStatusSuccessful:
+		st.State = TxFailed
+	case st.Confirmations >= max(depth, 1):
+		st.State = TxConfirmed
+	}
+	return st
+}
+
+// TxStatus looks the transaction up on Horizon. Stellar ledgers are final
+// once closed, so any transaction Horizon knows of is settled.
+func (s *StellarSender) TxStatus(ctx context.Context, hash string) (TxStatus, error) {
+	tx, err := s.client.GetHorizonClient().TransactionDetail(hash)
+	if horizonclient.IsNotFoundError(err) {
+		return TxStatus{State: TxPending}, nil
+	}
+	if err != nil {
+		return TxStatus{}, fmt.Errorf("stellar transaction: %w", err)
+	}
+	st := TxStatus{State: TxConfirmed, Block: uint64(tx.Ledger), Confirmations: 1}
+	if !tx.Successful {
+		st.State = TxFailed
+	}
+	return st, nil
+}
@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+// Stellar allows up to 100 operations per transaction, so payouts batch natively.
+const stellarMaxOps = 100
+
+type StellarSender struct {
+	client *soroban.Client
+	tb     *soroban.TransactionBuilder
+	kp     *keypair.Full
+}
+
+func NewStellarSender(client *soroban.Client, secret string) (*StellarSender, error) {
+	kp, err := keypair.ParseFull(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stellar hot wallet secret: %w", err)
+	}
+	tb, err := soroban.NewTransactionBuilder(client, secret, soroban.DefaultRetryConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &StellarSender{client: client, tb: tb, kp: kp}, nil
+}
+
+func (s *StellarSender) Chain() string   { return "stellar" }
+func (s *StellarSender) Address() string { return s.kp.Address() }
+func (s *StellarSender) MaxBatch() int   { return stellarMaxOps }
+
+// Decimals is fixed at 7 for every Stellar asset.
+func (s *StellarSender) Decimals(context.Context, string) (int, error) { return 7, nil }
+
+// ParseStellarAsset accepts "XLM"/"native" or "CODE:ISSUER".
+func ParseStellarAsset(asset string) (txnbuild.Asset, error) {
+	asset = strings.TrimSpace(asset)
+	if strings.EqualFold(asset, "XLM") || strings.EqualFold(asset, "native") {
+		return txnbuild.NativeAsset{}, nil
+	}
+	code, issuer, ok := strings.Cut(asset, ":")
+	if !ok || code == "" || issuer == "" {
+		return nil, fmt.Errorf("stellar asset must be XLM or CODE:ISSUER, got %q", asset)
+	}
+	return txnbuild.CreditAsset{Code: code, Issuer: issuer}, nil
+}
+
+func (s *StellarSender) Balance(ctx context.Context, asset string) (string, error) {
+	return s.BalanceOf(ctx, s.kp.Address(), asset)
+}
+
+func (s *StellarSender) BalanceOf(ctx context.Context, address, asset string) (string, error) {
+	a, err := ParseStellarAsset(asset)
+	if err != nil {
+		return "", err
+	}
+	acct, err := s.client.GetHorizonClient().AccountDetail(horizonclient.AccountRequest{AccountID: address})
+	if horizonclient.IsNotFoundError(err) {
+		return "", ErrAccountNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("load stellar account: %w", err)
+	}
+	for _, b := range acct.Balances {
+		if a.IsNative() && b.Asset.Type == "native" {
+			return b.Balance, nil
+		}
+		if !a.IsNative() && b.Asset.Code == a.GetCode() && b.Asset.Issuer == a.GetIssuer() {
+			return b.Balance, nil
+		}
+	}
+	return "0", nil
+}
+
+func (s *StellarSender) Sign(ctx context.Context, asset string, payments []Payment) (SignedTx, error) {
+	if len(payments) == 0 || len(payments) > stellarMaxOps {
+		return SignedTx{}, fmt.Errorf("stellar send needs 1..%d payments, got %d", stellarMaxOps, len(payments))
+	}
+	a, err := ParseStellarAsset(asset)
+	if err != nil {
+		return SignedTx{}, err
+	}
+	ops := make([]txnbuild.Operation, 0, len(payments))
+	for _, p := range payments {
+		amt, err := ParseAmount(p.Amount)
+		if err != nil {
+			return SignedTx{}, err
+		}
+		ops = append(ops, &txnbuild.Payment{
+			Destination: p.To,
+			Amount:      FormatAmount(amt, 7),
+			Asset:       a,
+		})
+	}
+	tx, err := s.tb.Build(ctx, ops)
+	if err != nil {
+		return SignedTx{}, err
+	}
+	hash, err := tx.HashHex(s.client.GetNetworkPassphrase())
+	if err != nil {
+		return SignedTx{}, err
+	}
+	raw, err := tx.Base64()
+	if err != nil {
+		return SignedTx{}, err
+	}
+	return SignedTx{Hash: hash, Raw: raw}, nil
+}
+
+// Broadcast submits the envelope. A transaction Horizon already has is
+// reported by TxStatus, so a resubmission rejected for its sequence number
+// after landing is not an error.
+func (s *StellarSender) Broadcast(ctx context.Context, signed SignedTx) error {
+	generic, err := txnbuild.TransactionFromXDR(signed.Raw)
+	if err != nil {
+		return fmt.Errorf("decode signed transaction: %w", err)
+	}
+	tx, ok := generic.Transaction()
+	if !ok {
+		return fmt.Errorf("decode signed transaction: not a simple transaction")
+	}
+	if _, err := s.tb.Submit(ctx, tx); err != nil {
+		if st, serr := s.TxStatus(ctx, signed.Hash); serr == nil && st.State != TxPending {
+			return nil
+		}
+		return withResultCodes(err)
+	}
+	return nil
+}
+
+// withResultCodes adds Horizon's transaction/operation result codes (e.g.
+// op_underfunded) to a failed submission, since its message omits them.
+func withResultCodes(err error) error {
+	var herr *horizonclient.Error
+	if !errors.As(err, &herr) {
+		return err
+	}
+	rc, rcErr := herr.ResultCodes()
+	if rcErr != nil || rc == nil {
+		return err
+	}
+	codes := append([]string{rc.TransactionCode}, rc.OperationCodes...)
+	return fmt.Errorf("stellar submit failed (%s): %w", strings.Join(codes, ", "), err)
+}
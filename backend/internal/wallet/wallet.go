@@ -0,0 +1,112 @@
+// Package wallet signs and submits transfers from the platform's hot wallets.
+// Each supported chain has one Sender; chains without key material configured
+// are simply absent from the Registry.
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Payment is one recipient in a (possibly batched) send. Amount is a decimal
+// string in whole units of the asset.
+type Payment struct {
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+}
+
+type Sender interface {
+	Chain() string
+	// Address is the hot wallet address.
+	Address() string
+	// Decimals is the precision of asset on this chain; amounts are truncated to it.
+	Decimals(ctx context.Context, asset string) (int, error)
+	// Balance returns the hot wallet balance of asset as a decimal string.
+	Balance(ctx context.Context, asset string) (string, error)
+	// BalanceOf reads any address's balance (e.g. cold storage) on this chain.
+	BalanceOf(ctx context.Context, address, asset string) (string, error)
+	// Sign builds and signs one transaction carrying all payments without
+	// broadcasting it, so callers can record its hash first.
+	// len(payments) must not exceed MaxBatch.
+	Sign(ctx context.Context, asset string, payments []Payment) (SignedTx, error)
+	// Broadcast submits a transaction from Sign. Submitting one the network
+	// already has is not an error, so an interrupted send can be resumed.
+	Broadcast(ctx context.Context, tx SignedTx) error
+	// MaxBatch is the largest number of payments Sign accepts at once.
+	MaxBatch() int
+}
+
+// SignedTx is a signed transaction ready to broadcast. Raw is its chain's
+// wire encoding (hex RLP on EVM chains, base64 XDR on Stellar).
+type SignedTx struct {
+	Hash string
+	Raw  string
+}
+
+// Landed reports whether the chain already knows the transaction, included
+// or not. It is always false for senders that cannot look transactions up.
+func Landed(ctx context.Context, s Sender, hash string) (bool, error) {
+	t, ok := s.(Tracker)
+	if !ok {
+		return false, nil
+	}
+	st, err := t.TxStatus(ctx, hash)
+	if err != nil {
+		return false, err
+	}
+	return st.State != TxPending || st.Block > 0, nil
+}
+
+// Registry maps chain name to its Sender.
+type Registry map[string]Sender
+
+func (r Registry) Get(chain string) (Sender, bool) {
+	s, ok := r[strings.ToLower(strings.TrimSpace(chain))]
+	return s, ok
+}
+
+func (r Registry) Chains() []string {
+	out := make([]string, 0, len(r))
+	for c := range r {
+		out = append(out, c)
+	}
+	return out
+}
+
+// ParseAmount parses a non-negative decimal string.
+func ParseAmount(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	if r.Sign() < 0 {
+		return nil, fmt.Errorf("negative amount %q", s)
+	}
+	return r, nil
+}
+
+// FormatAmount renders r with at most decimals fractional digits, truncating
+// (never rounding up, so we never send more than we computed).
+func FormatAmount(r *big.Rat, decimals int) string {
+	units := ToBaseUnits(r, decimals)
+	return FromBaseUnits(units, decimals)
+}
+
+// ToBaseUnits converts r to integer base units, truncating extra precision.
+func ToBaseUnits(r *big.Rat, decimals int) *big.Int {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	n := new(big.Int).Mul(r.Num(), scale)
+	return n.Quo(n, r.Denom())
+}
+
+// FromBaseUnits renders integer base units as a trimmed decimal string.
+func FromBaseUnits(n *big.Int, decimals int) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	s := new(big.Rat).SetFrac(n, scale).FloatString(decimals)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
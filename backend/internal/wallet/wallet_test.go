@@ -0,0 +1,57 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAmountConversions(t *testing.T) {
+	cases := []struct {
+		in       string
+		decimals int
+		units    string
+		out      string
+	}{
+		{"1.5", 18, "1500000000000000000", "1.5"},
+		{"0.12345678", 7, "1234567", "0.1234567"},
+		{"100", 6, "100000000", "100"},
+		{"0", 7, "0", "0"},
+	}
+	for _, c := range cases {
+		r, err := ParseAmount(c.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ToBaseUnits(r, c.decimals).String(); got != c.units {
+			t.Errorf("ToBaseUnits(%s, %d) = %s, want %s", c.in, c.decimals, got, c.units)
+		}
+		if got := FormatAmount(r, c.decimals); got != c.out {
+			t.Errorf("FormatAmount(%s, %d) = %s, want %s", c.in, c.decimals, got, c.out)
+		}
+	}
+	if _, err := ParseAmount("-1"); err == nil {
+		t.Error("negative amount accepted")
+	}
+	if got := FromBaseUnits(big.NewInt(5), 2); got != "0.05" {
+		t.Errorf("FromBaseUnits = %s", got)
+	}
+}
+
+func TestEVMTxStatus(t *testing.T) {
+	cases := []struct {
+		status, block, head, depth uint64
+		want                       TxStatus
+	}{
+		{1, 100, 105, 12, TxStatus{State: TxPending, Block: 100, Confirmations: 6}},
+		{1, 100, 111, 12, TxStatus{State: TxConfirmed, Block: 100, Confirmations: 12}},
+		{1, 100, 100, 0, TxStatus{State: TxConfirmed, Block: 100, Confirmations: 1}},
+		{0, 100, 100, 12, TxStatus{State: TxFailed, Block: 100, Confirmations: 1}},
+		// A lagging node may not have seen the receipt's block as head yet.
+		{1, 100, 99, 1, TxStatus{State: TxPending, Block: 100}},
+	}
+	for _, c := range cases {
+		if got := evmTxStatus(c.status, c.block, c.head, c.depth); got != c.want {
+			t.Errorf("evmTxStatus(%d, %d, %d, %d) = %+v, want %+v", c.status, c.block, c.head, c.depth, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon/operations"
+
+	"github.com/jagadeesh/grainlify/backend/internal/chain"
+)
+
+// Watcher is implemented by senders that can list transfers into any address
+// themselves, for chains without an indexer webhook feeding chain_transfers.
+type Watcher interface {
+	// InboundTransfers returns transfers into address after cursor ("" for
+	// the beginning), oldest first, and the cursor to resume from.
+	InboundTransfers(ctx context.Context, address, cursor string) ([]chain.Transfer, string, error)
+}
+
+// stellarPageSize is Horizon's largest page.
+const stellarPageSize = 200
+
+// InboundTransfers reads one page of address's payments from Horizon. An
+// address's first deposit creates the account, so account creations count
+// too. Native lumens are reported as "XLM" and other assets as CODE:ISSUER.
+func (s *StellarSender) InboundTransfers(ctx context.Context, address, cursor string) ([]chain.Transfer, string, error) {
+	page, err := s.client.GetHorizonClient().Payments(horizonclient.OperationRequest{
+		ForAccount: address,
+		Cursor:     cursor,
+		Order:      horizonclient.OrderAsc,
+		Limit:      stellarPageSize,
+	})
+	if horizonclient.IsNotFoundError(err) {
+		// Not created yet: nothing has been deposited.
+		return nil, cursor, nil
+	}
+	if err != nil {
+		return nil, cursor, fmt.Errorf("stellar payments: %w", err)
+	}
+	var out []chain.Transfer
+	for _, rec := range page.Embedded.Records {
+		cursor = rec.PagingToken()
+		var t chain.Transfer
+		switch op := rec.(type) {
+		case operations.Payment:
+			if op.To != address || !op.TransactionSuccessful {
+				continue
+			}
+			asset := "XLM"
+			if op.Asset.Type != "native" {
+				asset = op.Asset.Code + ":" + op.Asset.Issuer
+			}
+			t = chain.Transfer{TxHash: op.TransactionHash, From: op.From, To: op.To, Asset: asset, Amount: op.Amount}
+			t.LogIndex, t.BlockNumber = stellarOpPosition(op.ID)
+		case operations.CreateAccount:
+			if op.Account != address || !op.TransactionSuccessful {
+				continue
+			}
+			t = chain.Transfer{TxHash: op.TransactionHash, From: op.Funder, To: op.Account, Asset: "XLM", Amount: op.StartingBalance}
+			t.LogIndex, t.BlockNumber = stellarOpPosition(op.ID)
+		default:
+			continue
+		}
+		t.Chain = "stellar"
+		out = append(out, t)
+	}
+	return out, cursor, nil
+}
+
+// stellarOpPosition splits an operation ID into its index within the
+// transaction and its ledger.
+func stellarOpPosition(id string) (int, int64) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+	return int(n & 0xfff), n >> 32
+}
@@ -0,0 +1,326 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/safehttp"
+)
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// MaxAttempts is how many times a delivery is tried before it is failed.
+// With Backoff that spans roughly eight hours.
+const MaxAttempts = 10
+
+const (
+	backoffBase = time.Minute
+	backoffMax  = 6 * time.Hour
+	// claimLease keeps a claimed delivery from being picked up again while
+	// it is in flight.
+	claimLease = 5 * time.Minute
+	// maxResponseBody is how much of an endpoint's response is logged.
+	maxResponseBody = 1024
+	defaultBatch    = 50
+	concurrency     = 8
+)
+
+// Backoff is the wait after the attempt-th failed attempt (1-based): one
+// minute, doubling up to six hours.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := backoffBase
+	for i := 1; i < attempt && d < backoffMax; i++ {
+		d *= 2
+	}
+	return min(d, backoffMax)
+}
+
+// Dispatcher turns bounty events into deliveries for every active endpoint
+// and POSTs due deliveries, signed with the endpoint's secret.
+type Dispatcher struct {
+	Pool           *pgxpool.Pool
+	TokenEncKeyB64 string
+	// HTTP defaults to safehttp.NewClient, which only connects to public
+	// addresses.
+	HTTP *http.Client
+	// Registry defaults to Versions.
+	Registry *Registry
+	// Batch caps deliveries sent per run; it defaults to 50.
+	Batch int
+}
+
+// RunOnce queues new events and sends due deliveries, returning how many
+// were accepted by their endpoints.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	if d.Pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	if err := d.enqueue(ctx); err != nil {
+		return 0, err
+	}
+	return d.deliverDue(ctx)
+}
+
+type activeEndpoint struct {
+	id      uuid.UUID
+	ownerID uuid.UUID
+	events  []string
+	version int
+	cursor  int64
+}
+
+func (d *Dispatcher) registry() *Registry {
+	if d.Registry != nil {
+		return d.Registry
+	}
+	return Versions
+}
+
+// enqueue records a delivery for each event after every active endpoint's
+// cursor, advancing the cursor in the same transaction.
+func (d *Dispatcher) enqueue(ctx context.Context) error {
+	rows, err := d.Pool.Query(ctx, `
+SELECT id, owner_user_id, events, event_version, cursor
+FROM webhook_endpoints
+WHERE active
+ORDER BY created_at
+`)
+	if err != nil {
+		return err
+	}
+	var endpoints []activeEndpoint
+	for rows.Next() {
+		var e activeEndpoint
+		if err := rows.Scan(&e.id, &e.ownerID, &e.events, &e.version, &e.cursor); err != nil {
+			rows.Close()
+			return err
+		}
+		endpoints = append(endpoints, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, ep := range endpoints {
+		if err := d.enqueueEndpoint(ctx, ep); err != nil {
+			slog.Warn("webhook enqueue failed", "endpoint_id", ep.id.String(), "error", err)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) enqueueEndpoint(ctx context.Context, ep activeEndpoint) error {
+	events, err := bounties.ListEvents(ctx, d.Pool, bounties.EventFilter{
+		OwnerID: &ep.ownerID,
+		Viewer:  ep.ownerID,
+		Types:   ep.events,
+		Since:   ep.cursor,
+		Limit:   bounties.MaxEventsPage,
+	})
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	cursor := ep.cursor
+	// ListEvents returns newest first; queue oldest first.
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		data, err := json.Marshal(bountyEvent(e.Bounty))
+		if err != nil {
+			return err
+		}
+		body, version, err := d.registry().Render(Event{ID: e.ID.String(), Type: e.Type, CreatedAt: e.CreatedAt, Data: data}, ep.version)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, event_version, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (endpoint_id, event_id) DO NOTHING
+`, ep.id, e.ID.String(), e.Type, version, body); err != nil {
+			return err
+		}
+		if seq, _ := strconv.ParseInt(e.Cursor, 10, 64); seq > cursor {
+			cursor = seq
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE webhook_endpoints SET cursor = GREATEST(cursor, $2) WHERE id = $1`, ep.id, cursor); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+type dueDelivery struct {
+	id        uuid.UUID
+	eventID   string
+	eventType string
+	version   int
+	payload   []byte
+	attempts  int
+	url       string
+	secret    []byte
+}
+
+// deliverDue claims due deliveries and sends them, a few at a time.
+func (d *Dispatcher) deliverDue(ctx context.Context) (int, error) {
+	batch := d.Batch
+	if batch <= 0 {
+		batch = defaultBatch
+	}
+	rows, err := d.Pool.Query(ctx, `
+UPDATE webhook_deliveries d
+SET next_attempt_at = now() + make_interval(secs => $2)
+FROM webhook_endpoints e
+WHERE e.id = d.endpoint_id AND d.id IN (
+  SELECT d2.id
+  FROM webhook_deliveries d2
+  JOIN webhook_endpoints e2 ON e2.id = d2.endpoint_id
+  WHERE d2.status = 'pending' AND d2.next_attempt_at <= now() AND e2.active
+  ORDER BY d2.next_attempt_at
+  LIMIT $1
+  FOR UPDATE OF d2 SKIP LOCKED
+)
+RETURNING d.id, d.event_id, d.event_type, d.event_version, d.payload, d.attempts, e.url, e.secret
+`, batch, claimLease.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	var due []dueDelivery
+	for rows.Next() {
+		var dd dueDelivery
+		if err := rows.Scan(&dd.id, &dd.eventID, &dd.eventType, &dd.version, &dd.payload, &dd.attempts, &dd.url, &dd.secret); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, dd)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	client := d.HTTP
+	if client == nil {
+		client = safehttp.NewClient()
+	}
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+		sem       = make(chan struct{}, concurrency)
+	)
+	for _, dd := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(dd dueDelivery) {
+			defer func() { <-sem; wg.Done() }()
+			ok, err := d.attempt(ctx, client, dd)
+			if err != nil {
+				slog.Error("failed to record webhook attempt", "delivery_id", dd.id.String(), "error", err)
+			}
+			if ok {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}(dd)
+	}
+	wg.Wait()
+	return delivered, nil
+}
+
+// send POSTs one delivery, returning the response status and the start of
+// its body when the endpoint answered.
+func (d *Dispatcher) send(ctx context.Context, client *http.Client, dd dueDelivery) (*int, *string, error) {
+	secret, err := decryptSecret(d.TokenEncKeyB64, dd.secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dd.url, bytes.NewReader(dd.payload))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Grainlify-Webhooks/1")
+	req.Header.Set(HeaderEvent, dd.eventType)
+	req.Header.Set(HeaderDelivery, dd.id.String())
+	req.Header.Set(HeaderSignature, Sign(secret, time.Now(), dd.payload))
+	SetVersionHeader(req.Header, dd.version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	code, body := resp.StatusCode, strings.ToValidUTF8(string(b), "")
+	if code < 200 || code > 299 {
+		return &code, &body, fmt.Errorf("endpoint responded %d", code)
+	}
+	return &code, &body, nil
+}
+
+// attempt sends one delivery and records the outcome, scheduling a retry
+// or failing the delivery after MaxAttempts.
+func (d *Dispatcher) attempt(ctx context.Context, client *http.Client, dd dueDelivery) (bool, error) {
+	start := time.Now()
+	code, body, sendErr := d.send(ctx, client, dd)
+	duration := int(time.Since(start).Milliseconds())
+
+	attempts := dd.attempts + 1
+	status, next, errMsg := StatusSucceeded, time.Duration(0), ""
+	if sendErr != nil {
+		status, next, errMsg = StatusPending, Backoff(attempts), sendErr.Error()
+		if attempts >= MaxAttempts {
+			status = StatusFailed
+		}
+		slog.Info("webhook delivery failed",
+			"delivery_id", dd.id.String(),
+			"event_id", dd.eventID,
+			"attempts", attempts,
+			"status", status,
+			"error", errMsg,
+		)
+	}
+	_, err := d.Pool.Exec(ctx, `
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = $3,
+    next_attempt_at = now() + make_interval(secs => $4),
+    last_attempt_at = now(),
+    response_status = $5,
+    response_body = $6,
+    error = NULLIF($7, ''),
+    duration_ms = $8,
+    delivered_at = CASE WHEN $2 = 'succeeded' THEN now() END
+WHERE id = $1
+`, dd.id, status, attempts, next.Seconds(), code, body, errMsg, duration)
+	return sendErr == nil, err
+}
@@ -0,0 +1,312 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+// SecretPrefix marks secrets generated by Grainlify.
+const SecretPrefix = "whsec_"
+
+// MaxEndpointsPerUser caps how many endpoints one user registers.
+const MaxEndpointsPerUser = 10
+
+const (
+	minSecretLength = 16
+	maxSecretLength = 256
+)
+
+var (
+	ErrInvalidURL        = errors.New("invalid_webhook_url")
+	ErrInvalidSecret     = errors.New("invalid_webhook_secret")
+	ErrTooManyEndpoints  = errors.New("too_many_webhook_endpoints")
+	ErrEndpointNotFound  = errors.New("webhook_endpoint_not_found")
+	ErrDeliveryNotFound  = errors.New("webhook_delivery_not_found")
+	ErrDeliveryNotClosed = errors.New("webhook_delivery_pending")
+)
+
+// Endpoint is a registered URL. The secret is never serialized after creation.
+type Endpoint struct {
+	ID           uuid.UUID `json:"id"`
+	OwnerUserID  uuid.UUID `json:"owner_user_id"`
+	URL          string    `json:"url"`
+	Description  string    `json:"description"`
+	Events       []string  `json:"events"`
+	EventVersion int       `json:"event_version"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+const endpointColumns = `id, owner_user_id, url, description, events, event_version, active, created_at, updated_at`
+
+func scanEndpoint(row pgx.Row) (Endpoint, error) {
+	var e Endpoint
+	err := row.Scan(&e.ID, &e.OwnerUserID, &e.URL, &e.Description, &e.Events, &e.EventVersion, &e.Active, &e.CreatedAt, &e.UpdatedAt)
+	return e, err
+}
+
+// NewEndpoint is the input to CreateEndpoint. An empty Secret is generated;
+// empty Events subscribes to every event; zero EventVersion pins the current one.
+type NewEndpoint struct {
+	URL          string
+	Description  string
+	Secret       string
+	Events       []string
+	EventVersion int
+}
+
+// NormalizeURL checks that raw is an absolute https URL without credentials.
+// Whether it resolves to a public address is checked on every delivery.
+func NormalizeURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil || u.Fragment != "" {
+		return "", ErrInvalidURL
+	}
+	if h := strings.ToLower(u.Hostname()); h == "localhost" || strings.HasSuffix(h, ".localhost") {
+		return "", ErrInvalidURL
+	}
+	return u.String(), nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return SecretPrefix + hex.EncodeToString(b), nil
+}
+
+func encryptSecret(tokenEncKeyB64, secret string) ([]byte, error) {
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return nil, err
+	}
+	return cryptox.EncryptAESGCM(key, []byte(secret))
+}
+
+func decryptSecret(tokenEncKeyB64 string, b []byte) (string, error) {
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return "", err
+	}
+	out, err := cryptox.DecryptAESGCM(key, b)
+	if err != nil {
+		return "", fmt.Errorf("decrypt webhook secret failed")
+	}
+	return string(out), nil
+}
+
+// CreateEndpoint registers an endpoint for ownerID and returns it with its
+// plaintext secret, shown exactly once. Delivery starts from the current end
+// of the event feed.
+func CreateEndpoint(ctx context.Context, pool *pgxpool.Pool, ownerID uuid.UUID, in NewEndpoint, tokenEncKeyB64 string) (Endpoint, string, error) {
+	if pool == nil {
+		return Endpoint{}, "", fmt.Errorf("db not configured")
+	}
+	u, err := NormalizeURL(in.URL)
+	if err != nil {
+		return Endpoint{}, "", err
+	}
+	version, err := Versions.Resolve(in.EventVersion)
+	if err != nil {
+		return Endpoint{}, "", err
+	}
+	secret := strings.TrimSpace(in.Secret)
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return Endpoint{}, "", err
+		}
+	} else if len(secret) < minSecretLength || len(secret) > maxSecretLength {
+		return Endpoint{}, "", ErrInvalidSecret
+	}
+	enc, err := encryptSecret(tokenEncKeyB64, secret)
+	if err != nil {
+		return Endpoint{}, "", err
+	}
+	events := in.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	var n int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM webhook_endpoints WHERE owner_user_id = $1`, ownerID).Scan(&n); err != nil {
+		return Endpoint{}, "", err
+	}
+	if n >= MaxEndpointsPerUser {
+		return Endpoint{}, "", ErrTooManyEndpoints
+	}
+	e, err := scanEndpoint(pool.QueryRow(ctx, `
+INSERT INTO webhook_endpoints (owner_user_id, url, description, secret, events, event_version, cursor)
+VALUES ($1, $2, $3, $4, $5, $6, (SELECT COALESCE(max(seq), 0) FROM bounty_events))
+RETURNING `+endpointColumns, ownerID, u, strings.TrimSpace(in.Description), enc, events, version))
+	if err != nil {
+		return Endpoint{}, "", err
+	}
+	return e, secret, nil
+}
+
+func ListEndpoints(ctx context.Context, pool *pgxpool.Pool, ownerID uuid.UUID) ([]Endpoint, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `SELECT `+endpointColumns+` FROM webhook_endpoints WHERE owner_user_id = $1 ORDER BY created_at`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Endpoint{}
+	for rows.Next() {
+		e, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// SetEndpointActive pauses or resumes deliveries to an endpoint. Resuming
+// does not replay events that happened while it was paused.
+func SetEndpointActive(ctx context.Context, pool *pgxpool.Pool, ownerID, id uuid.UUID, active bool) (Endpoint, error) {
+	if pool == nil {
+		return Endpoint{}, fmt.Errorf("db not configured")
+	}
+	e, err := scanEndpoint(pool.QueryRow(ctx, `
+UPDATE webhook_endpoints
+SET active = $3,
+    cursor = CASE WHEN $3 AND NOT active THEN (SELECT COALESCE(max(seq), 0) FROM bounty_events) ELSE cursor END,
+    updated_at = now()
+WHERE id = $1 AND owner_user_id = $2
+RETURNING `+endpointColumns, id, ownerID, active))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Endpoint{}, ErrEndpointNotFound
+	}
+	return e, err
+}
+
+func DeleteEndpoint(ctx context.Context, pool *pgxpool.Pool, ownerID, id uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	tag, err := pool.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND owner_user_id = $2`, id, ownerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrEndpointNotFound
+	}
+	return nil
+}
+
+// Delivery is one event sent (or being sent) to an endpoint.
+type Delivery struct {
+	ID             uuid.UUID       `json:"id"`
+	EndpointID     uuid.UUID       `json:"endpoint_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	EventVersion   int             `json:"event_version"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   *string         `json:"response_body,omitempty"`
+	Error          *string         `json:"error,omitempty"`
+	DurationMS     *int            `json:"duration_ms,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+const deliveryColumns = `d.id, d.endpoint_id, d.event_id, d.event_type, d.event_version, d.payload, d.status, d.attempts,
+  CASE WHEN d.status = 'pending' THEN d.next_attempt_at END, d.last_attempt_at, d.response_status, d.response_body,
+  d.error, d.duration_ms, d.delivered_at, d.created_at`
+
+func scanDelivery(row pgx.Row) (Delivery, error) {
+	var d Delivery
+	err := row.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.EventVersion, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.ResponseBody, &d.Error, &d.DurationMS, &d.DeliveredAt, &d.CreatedAt)
+	return d, err
+}
+
+// ListDeliveries returns an endpoint's deliveries, newest first, optionally
+// only those with status.
+func ListDeliveries(ctx context.Context, pool *pgxpool.Pool, ownerID, endpointID uuid.UUID, status string, limit, offset int) ([]Delivery, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	var owned bool
+	if err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM webhook_endpoints WHERE id = $1 AND owner_user_id = $2)`, endpointID, ownerID).Scan(&owned); err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, ErrEndpointNotFound
+	}
+	rows, err := pool.Query(ctx, `
+SELECT `+deliveryColumns+`
+FROM webhook_deliveries d
+WHERE d.endpoint_id = $1 AND ($2 = '' OR d.status = $2)
+ORDER BY d.created_at DESC
+LIMIT $3 OFFSET $4
+`, endpointID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// Redeliver queues a finished delivery to be sent again right away, with a
+// fresh set of attempts.
+func Redeliver(ctx context.Context, pool *pgxpool.Pool, ownerID, endpointID, deliveryID uuid.UUID) (Delivery, error) {
+	if pool == nil {
+		return Delivery{}, fmt.Errorf("db not configured")
+	}
+	d, err := scanDelivery(pool.QueryRow(ctx, `
+UPDATE webhook_deliveries d
+SET status = 'pending', attempts = 0, next_attempt_at = now()
+FROM webhook_endpoints e
+WHERE d.id = $1 AND d.endpoint_id = $2 AND e.id = d.endpoint_id AND e.owner_user_id = $3
+  AND d.status <> 'pending'
+RETURNING `+deliveryColumns, deliveryID, endpointID, ownerID))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return d, err
+	}
+	var pending bool
+	err = pool.QueryRow(ctx, `
+SELECT d.status = 'pending'
+FROM webhook_deliveries d
+JOIN webhook_endpoints e ON e.id = d.endpoint_id
+WHERE d.id = $1 AND d.endpoint_id = $2 AND e.owner_user_id = $3
+`, deliveryID, endpointID, ownerID).Scan(&pending)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return Delivery{}, ErrDeliveryNotFound
+	case err != nil:
+		return Delivery{}, err
+	default:
+		return Delivery{}, ErrDeliveryNotClosed
+	}
+}
@@ -0,0 +1,125 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/openapi"
+	"github.com/jagadeesh/grainlify/backend/webhookevents"
+)
+
+// bountyEvent is the data sent for a bounty.* event.
+func bountyEvent(b bounties.Bounty) webhookevents.BountyEvent {
+	out := webhookevents.Bounty{
+		ID:        b.ID,
+		ProjectID: b.ProjectID,
+		CreatedBy: b.CreatedBy,
+		Issue: webhookevents.Issue{
+			Provider:   b.Issue.Provider,
+			ExternalID: b.Issue.ExternalID,
+			Key:        b.Issue.Key,
+			Title:      b.Issue.Title,
+			URL:        b.Issue.URL,
+			State:      b.Issue.State,
+			Closed:     b.Issue.Closed,
+		},
+		PathPrefix:          b.PathPrefix,
+		Chain:               b.Chain,
+		Asset:               b.Asset,
+		Amount:              b.Amount,
+		Status:              b.Status,
+		SkillTags:           b.SkillTags,
+		SkillTagsOverridden: b.SkillTagsOverridden,
+		Funding:             b.Funding,
+		Metadata:            b.Metadata,
+		Deadline:            b.Deadline,
+		Visibility:          b.Visibility,
+		Kind:                b.Kind,
+		ApprovedBy:          b.ApprovedBy,
+		ApprovedAt:          b.ApprovedAt,
+		PayoutID:            b.PayoutID,
+		CreatedAt:           b.CreatedAt,
+		UpdatedAt:           b.UpdatedAt,
+	}
+	if e := b.Escrow; e != nil {
+		out.Escrow = &webhookevents.Escrow{Contract: e.Contract, Ref: e.Ref, Status: e.Status, Deadline: e.Deadline, LockTx: e.LockTx}
+	}
+	if c := b.Claim; c != nil {
+		out.Claim = &webhookevents.Claimant{
+			UserID:      c.UserID,
+			ClaimedAt:   c.ClaimedAt,
+			Repo:        c.Repo,
+			PRNumber:    c.PRNumber,
+			PRURL:       c.PRURL,
+			SubmittedAt: c.SubmittedAt,
+		}
+	}
+	return webhookevents.BountyEvent{Bounty: out}
+}
+
+// Catalog describes every event type endpoints receive, in the current
+// version, for integrators generating clients.
+type Catalog struct {
+	Version int `json:"version"`
+	// Headers are sent with every delivery.
+	Headers map[string]string `json:"headers"`
+	// Envelope is the schema of every delivery body.
+	Envelope *openapi.Schema `json:"envelope"`
+	Events   []CatalogEvent  `json:"events"`
+	// Defs are the objects the schemas refer to.
+	Defs map[string]*openapi.Schema `json:"$defs"`
+}
+
+// CatalogEvent is one event type.
+type CatalogEvent struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	// Schema is the schema of the envelope's data.
+	Schema *openapi.Schema `json:"schema"`
+	// Example is a delivery body, rendered as the dispatcher renders one.
+	Example json.RawMessage `json:"example"`
+}
+
+// exampleEventID and exampleEventTime stand in for a real event's in
+// catalog examples.
+var (
+	exampleEventID   = uuid.MustParse("7c1e5d3a-9b2f-4e6c-8a4d-0f3b2c1d5e6f").String()
+	exampleEventTime = time.Date(2026, 10, 3, 12, 0, 0, 0, time.UTC)
+)
+
+// BuildCatalog describes webhookevents.Types.
+func BuildCatalog() (Catalog, error) {
+	defs := openapi.NewDefinitions()
+	cat := Catalog{
+		Version: Versions.Current(),
+		Headers: map[string]string{
+			HeaderEvent:        "the event type",
+			HeaderDelivery:     "the delivery's ID, the same on every attempt",
+			HeaderEventVersion: "the payload version",
+			HeaderSignature:    "t=<unix seconds>,v1=<hex HMAC-SHA256 of \"<t>.<body>\" keyed with the endpoint secret>",
+		},
+		Envelope: defs.Of(webhookevents.Envelope{}),
+	}
+	for _, t := range webhookevents.Types {
+		data, err := json.Marshal(t.Example)
+		if err != nil {
+			return Catalog{}, err
+		}
+		body, _, err := Versions.Render(Event{ID: exampleEventID, Type: t.Name, CreatedAt: exampleEventTime, Data: data}, 0)
+		if err != nil {
+			return Catalog{}, fmt.Errorf("%s example: %w", t.Name, err)
+		}
+		cat.Events = append(cat.Events, CatalogEvent{
+			Type:        t.Name,
+			Description: t.Description,
+			Schema:      defs.Of(t.Example),
+			Example:     body,
+		})
+	}
+	cat.Defs = defs.Defs()
+	return cat, nil
+}
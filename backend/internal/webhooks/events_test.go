@@ -0,0 +1,71 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/issues"
+	"github.com/jagadeesh/grainlify/backend/webhookevents"
+)
+
+// TestBountyEventMatchesBounty keeps the published Bounty in step with
+// bounties.Bounty, which deliveries carried before the catalog existed.
+func TestBountyEventMatchesBounty(t *testing.T) {
+	now := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	id, repo, pr, url, tx := uuid.New(), "acme/widgets", 43, "https://github.com/acme/widgets/pull/43", "0xfeed"
+	b := bounties.Bounty{
+		ID: uuid.New(), ProjectID: uuid.New(), CreatedBy: uuid.New(),
+		Issue:      issues.Issue{Provider: "github", ExternalID: "1", Key: "#42", Title: "Fix", URL: "https://github.com/acme/widgets/issues/42", State: "open", Closed: true},
+		PathPrefix: "packages/api",
+		Chain:      "stellar", Asset: "USDC", Amount: "150", Status: "completed",
+		SkillTags: []string{"go"}, SkillTagsOverridden: true, Funding: bounties.FundingEscrow,
+		Escrow:     &bounties.Escrow{Contract: "C1", Ref: 7, Status: "released", Deadline: now, LockTx: &tx},
+		Metadata:   map[string]any{"team": "core"},
+		Deadline:   &now,
+		Visibility: "public", Kind: "standard",
+		Claim:      &bounties.Claimant{UserID: id, ClaimedAt: now, Repo: &repo, PRNumber: &pr, PRURL: &url, SubmittedAt: &now},
+		ApprovedBy: &id, ApprovedAt: &now, PayoutID: &id,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	want, err := json.Marshal(map[string]any{"bounty": b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(bountyEvent(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("webhook bounty differs from bounties.Bounty:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestBuildCatalog(t *testing.T) {
+	cat, err := BuildCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cat.Events) != len(bounties.EventTypes) {
+		t.Fatalf("catalog has %d events, want %d", len(cat.Events), len(bounties.EventTypes))
+	}
+	if cat.Defs["Bounty"] == nil || cat.Defs["BountyEvent"] == nil {
+		t.Errorf("defs = %v", cat.Defs)
+	}
+	for i, e := range cat.Events {
+		if e.Type != bounties.EventTypes[i] || e.Schema.Ref != "#/$defs/BountyEvent" {
+			t.Errorf("event %d = %s with schema %+v", i, e.Type, e.Schema)
+		}
+		env, err := webhookevents.Decode(e.Example)
+		if err != nil || env.Type != e.Type || env.Version != CurrentVersion {
+			t.Errorf("%s example = %+v, %v", e.Type, env, err)
+			continue
+		}
+		if _, err := env.BountyEvent(); err != nil {
+			t.Errorf("%s example data: %v", e.Type, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/webhookevents"
+)
+
+// HeaderEventVersion tells receivers which payload schema a delivery uses.
+const HeaderEventVersion = "X-Grainlify-Event-Version"
+
+// CurrentVersion is the schema version new events are produced in.
+// Bump webhookevents.Version when an event shape changes, and register a
+// downgrade for every event type whose shape changed so pinned endpoints keep
+// receiving the old one.
+const CurrentVersion = webhookevents.Version
+
+var ErrUnsupportedVersion = errors.New("unsupported event version")
+
+// Event is an outbound platform event in the current schema version.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Envelope is the JSON body POSTed to endpoints.
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Transformer rewrites event data from version N into version N-1.
+type Transformer func(data map[string]any) (map[string]any, error)
+
+type downgradeKey struct {
+	eventType string
+	from      int
+}
+
+// Registry holds the downgrade chain for every event type.
+type Registry struct {
+	current int
+
+	mu         sync.RWMutex
+	downgrades map[downgradeKey]Transformer
+}
+
+func NewRegistry(current int) *Registry {
+	if current < 1 {
+		current = 1
+	}
+	return &Registry{current: current, downgrades: map[downgradeKey]Transformer{}}
+}
+
+// Versions is the registry used by the dispatcher.
+var Versions = NewRegistry(CurrentVersion)
+
+func (r *Registry) Current() int { return r.current }
+
+// RegisterDowngrade registers fn to turn eventType data from version `from` into `from-1`.
+// Types without a downgrade for a given step are passed through unchanged.
+func (r *Registry) RegisterDowngrade(eventType string, from int, fn Transformer) {
+	if fn == nil || from < 2 || from > r.current {
+		panic(fmt.Sprintf("webhooks: invalid downgrade %s v%d", eventType, from))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downgrades[downgradeKey{eventType: eventType, from: from}] = fn
+}
+
+// Resolve maps an endpoint's requested version to the version it will receive.
+// Zero means "latest".
+func (r *Registry) Resolve(requested int) (int, error) {
+	if requested == 0 {
+		return r.current, nil
+	}
+	if requested < 1 || requested > r.current {
+		return 0, ErrUnsupportedVersion
+	}
+	return requested, nil
+}
+
+// Render encodes e for an endpoint pinned to the requested version, walking the
+// downgrade chain from the current version. It returns the body and the version used.
+func (r *Registry) Render(e Event, requested int) ([]byte, int, error) {
+	version, err := r.Resolve(requested)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data := e.Data
+	for v := r.current; v > version; v-- {
+		r.mu.RLock()
+		fn := r.downgrades[downgradeKey{eventType: e.Type, from: v}]
+		r.mu.RUnlock()
+		if fn == nil {
+			continue
+		}
+
+		var m map[string]any
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, 0, fmt.Errorf("decode %s v%d: %w", e.Type, v, err)
+		}
+		out, err := fn(m)
+		if err != nil {
+			return nil, 0, fmt.Errorf("downgrade %s v%d: %w", e.Type, v, err)
+		}
+		if data, err = json.Marshal(out); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	body, err := json.Marshal(Envelope{
+		ID:        e.ID,
+		Type:      e.Type,
+		Version:   version,
+		CreatedAt: e.CreatedAt,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, version, nil
+}
+
+// ParseVersion parses a version as sent by integrators ("2" or "v2"). Empty means latest.
+func ParseVersion(s string) (int, error) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "v")
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, ErrUnsupportedVersion
+	}
+	return n, nil
+}
+
+// SetVersionHeader stamps the delivery version on an outbound request.
+func SetVersionHeader(h http.Header, version int) {
+	h.Set(HeaderEventVersion, strconv.Itoa(version))
+}
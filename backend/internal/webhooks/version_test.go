@@ -0,0 +1,73 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRenderDowngradesThroughChain(t *testing.T) {
+	r := NewRegistry(3)
+	// v3 renamed amount -> amount_minor; v2 added currency.
+	r.RegisterDowngrade("payout.sent", 3, func(d map[string]any) (map[string]any, error) {
+		d["amount"] = d["amount_minor"]
+		delete(d, "amount_minor")
+		return d, nil
+	})
+	r.RegisterDowngrade("payout.sent", 2, func(d map[string]any) (map[string]any, error) {
+		delete(d, "currency")
+		return d, nil
+	})
+
+	e := Event{
+		ID:        "evt_1",
+		Type:      "payout.sent",
+		CreatedAt: time.Unix(0, 0).UTC(),
+		Data:      json.RawMessage(`{"amount_minor":100,"currency":"USDC"}`),
+	}
+
+	body, v, err := r.Render(e, 1)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if v != 1 {
+		t.Fatalf("expected version 1, got %d", v)
+	}
+	var env struct {
+		Version int            `json:"version"`
+		Data    map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatalf("bad envelope: %v", err)
+	}
+	if env.Version != 1 {
+		t.Errorf("envelope version = %d", env.Version)
+	}
+	if _, ok := env.Data["currency"]; ok {
+		t.Errorf("currency should be removed in v1: %v", env.Data)
+	}
+	if env.Data["amount"] != float64(100) {
+		t.Errorf("expected amount=100, got %v", env.Data)
+	}
+
+	body, v, err = r.Render(e, 0)
+	if err != nil || v != 3 {
+		t.Fatalf("latest render: v=%d err=%v", v, err)
+	}
+	if err := json.Unmarshal(body, &env); err != nil || env.Data["amount_minor"] != float64(100) {
+		t.Errorf("latest render should be untouched: %s", body)
+	}
+}
+
+func TestResolveRejectsUnknownVersions(t *testing.T) {
+	r := NewRegistry(2)
+	if _, err := r.Resolve(3); err != ErrUnsupportedVersion {
+		t.Errorf("expected ErrUnsupportedVersion, got %v", err)
+	}
+	if _, err := ParseVersion("v0"); err != ErrUnsupportedVersion {
+		t.Errorf("expected ErrUnsupportedVersion, got %v", err)
+	}
+	if n, err := ParseVersion(" V2 "); err != nil || n != 2 {
+		t.Errorf("ParseVersion(V2) = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,16 @@
+-- Outbound webhook endpoints registered by integrators.
+-- event_version pins the payload schema an endpoint receives; deliveries are
+-- downgraded through the registered transformers when it lags the current version.
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  owner_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  secret BYTEA NOT NULL,
+  events TEXT[] NOT NULL DEFAULT '{}',
+  event_version INT NOT NULL DEFAULT 1 CHECK (event_version >= 1),
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_owner ON webhook_endpoints(owner_user_id);
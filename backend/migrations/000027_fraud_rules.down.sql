@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fraud_reviews;
+DROP TABLE IF EXISTS fraud_signals;
+DROP TABLE IF EXISTS fraud_rules;
@@ -0,0 +1,45 @@
+-- Admin-defined fraud rules (see internal/fraud for the expression language).
+CREATE TABLE IF NOT EXISTS fraud_rules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL UNIQUE,
+  description TEXT,
+  expression TEXT NOT NULL,
+  events TEXT[] NOT NULL DEFAULT '{claim,payout}',
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Raw signals used to compute velocity and IP-reuse facts.
+CREATE TABLE IF NOT EXISTS fraud_signals (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event TEXT NOT NULL CHECK (event IN ('claim', 'payout')),
+  subject_id TEXT,
+  ip TEXT,
+  amount NUMERIC,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fraud_signals_user_event ON fraud_signals(user_id, event, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_fraud_signals_ip ON fraud_signals(ip, created_at DESC) WHERE ip IS NOT NULL;
+
+-- Review queue: one row per rule match.
+CREATE TABLE IF NOT EXISTS fraud_reviews (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  rule_id UUID REFERENCES fraud_rules(id) ON DELETE SET NULL,
+  rule_name TEXT NOT NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event TEXT NOT NULL,
+  subject_id TEXT,
+  facts JSONB NOT NULL DEFAULT '{}'::jsonb,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'cleared', 'confirmed')),
+  resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  resolved_at TIMESTAMPTZ,
+  note TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fraud_reviews_status ON fraud_reviews(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_fraud_reviews_user ON fraud_reviews(user_id, created_at DESC);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS shadowed_content;
+DROP TABLE IF EXISTS moderation_actions;
+DROP INDEX IF EXISTS idx_users_shadow_banned;
+ALTER TABLE users
+  DROP COLUMN IF EXISTS shadow_ban_reason,
+  DROP COLUMN IF EXISTS shadow_banned_at;
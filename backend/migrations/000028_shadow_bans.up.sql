@@ -0,0 +1,32 @@
+-- Shadow-banned users can keep acting, but their content is hidden from everyone else.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS shadow_banned_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS shadow_ban_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_users_shadow_banned ON users(shadow_banned_at) WHERE shadow_banned_at IS NOT NULL;
+
+-- Append-only trail of moderator actions.
+CREATE TABLE IF NOT EXISTS moderation_actions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  actor_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  target_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  action TEXT NOT NULL,
+  reason TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_target ON moderation_actions(target_user_id, created_at DESC);
+
+-- Content accepted from shadow-banned users. It is never forwarded to GitHub
+-- and only shown back to its author.
+CREATE TABLE IF NOT EXISTS shadowed_content (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL,
+  project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+  issue_number INT,
+  body TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shadowed_content_user ON shadowed_content(user_id, created_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE auth_nonces
+  DROP COLUMN IF EXISTS pow_difficulty;
@@ -0,0 +1,3 @@
+-- Proof-of-work difficulty (leading zero bits) attached to a nonce at issuance; 0 = no puzzle.
+ALTER TABLE auth_nonces
+  ADD COLUMN IF NOT EXISTS pow_difficulty INT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ledger_anchors;
+DROP TABLE IF EXISTS ledger_entries;
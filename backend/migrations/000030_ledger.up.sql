@@ -0,0 +1,29 @@
+-- Append-only money ledger. Amounts are signed: credits > 0, debits < 0.
+-- seq gives a total order used for Merkle anchoring and hash-chained backups.
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  seq BIGSERIAL NOT NULL UNIQUE,
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  account TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  amount NUMERIC(38, 7) NOT NULL,
+  reference TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_user ON ledger_entries(user_id, seq DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON ledger_entries(account, asset);
+
+-- Signed Merkle roots over ledger_entries[1..to_seq].
+CREATE TABLE IF NOT EXISTS ledger_anchors (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  to_seq BIGINT NOT NULL,
+  leaf_count BIGINT NOT NULL,
+  root TEXT NOT NULL,
+  signature TEXT NOT NULL,
+  public_key TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_anchors_to_seq ON ledger_anchors(to_seq DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS chain_transfers;
+DROP TABLE IF EXISTS watched_addresses;
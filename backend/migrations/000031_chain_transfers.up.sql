@@ -0,0 +1,34 @@
+-- On-chain addresses we care about (deposit addresses, hot/cold wallets).
+CREATE TABLE IF NOT EXISTS watched_addresses (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  chain TEXT NOT NULL,
+  address TEXT NOT NULL,
+  purpose TEXT NOT NULL CHECK (purpose IN ('deposit', 'hot', 'cold')),
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  label TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (chain, address)
+);
+
+-- Transfers observed by pollers or indexer webhooks. Ingestion is idempotent on
+-- (chain, tx_hash, log_index) so providers may redeliver freely.
+CREATE TABLE IF NOT EXISTS chain_transfers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  chain TEXT NOT NULL,
+  tx_hash TEXT NOT NULL,
+  log_index INT NOT NULL DEFAULT 0,
+  from_address TEXT NOT NULL,
+  to_address TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  amount NUMERIC(78, 18) NOT NULL,
+  block_number BIGINT,
+  provider TEXT NOT NULL,
+  direction TEXT NOT NULL CHECK (direction IN ('in', 'out', 'internal', 'unrelated')),
+  watched_address_id UUID REFERENCES watched_addresses(id) ON DELETE SET NULL,
+  status TEXT NOT NULL DEFAULT 'seen' CHECK (status IN ('seen', 'confirmed', 'credited', 'ignored')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (chain, tx_hash, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chain_transfers_status ON chain_transfers(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_chain_transfers_tx ON chain_transfers(tx_hash);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS deposit_intents;
+DROP TABLE IF EXISTS deposit_addresses;
+DROP TABLE IF EXISTS hd_derivation_cursors;
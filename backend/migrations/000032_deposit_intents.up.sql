@@ -0,0 +1,36 @@
+-- Next unused HD derivation index per family (evm, stellar, solana).
+CREATE TABLE IF NOT EXISTS hd_derivation_cursors (
+  family TEXT PRIMARY KEY,
+  next_index BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Every address ever handed out, so the gap limit and sweeps can be computed.
+CREATE TABLE IF NOT EXISTS deposit_addresses (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  family TEXT NOT NULL,
+  derivation_index BIGINT NOT NULL,
+  chain TEXT NOT NULL,
+  address TEXT NOT NULL,
+  first_funded_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (family, derivation_index),
+  UNIQUE (chain, address)
+);
+
+CREATE TABLE IF NOT EXISTS deposit_intents (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  chain TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  expected_amount NUMERIC(78, 18),
+  deposit_address_id UUID NOT NULL REFERENCES deposit_addresses(id),
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'funded', 'expired', 'cancelled')),
+  funded_transfer_id UUID REFERENCES chain_transfers(id) ON DELETE SET NULL,
+  funded_at TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_deposit_intents_user ON deposit_intents(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_deposit_intents_address ON deposit_intents(deposit_address_id, status);
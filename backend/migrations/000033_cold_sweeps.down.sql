@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS sweeps;
+DROP TABLE IF EXISTS sweep_policies;
+DROP TABLE IF EXISTS sweep_destinations;
+ALTER TABLE ledger_entries ALTER COLUMN amount TYPE NUMERIC(38, 7);
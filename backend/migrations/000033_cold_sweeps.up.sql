@@ -0,0 +1,49 @@
+-- Amounts on EVM chains carry up to 18 decimals. Dropping the fixed scale keeps
+-- the text form (and so the Merkle leaf encoding) of existing rows unchanged.
+ALTER TABLE ledger_entries ALTER COLUMN amount TYPE NUMERIC;
+
+-- Cold destinations must be approved by an admin before the first sweep.
+CREATE TABLE IF NOT EXISTS sweep_destinations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  chain TEXT NOT NULL,
+  address TEXT NOT NULL,
+  label TEXT,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  approved_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (chain, address)
+);
+
+-- Sweep hot balance above threshold down to retain.
+CREATE TABLE IF NOT EXISTS sweep_policies (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  chain TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  destination_id UUID NOT NULL REFERENCES sweep_destinations(id),
+  threshold NUMERIC NOT NULL CHECK (threshold >= 0),
+  retain NUMERIC NOT NULL DEFAULT 0 CHECK (retain >= 0),
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (chain, asset),
+  CHECK (retain <= threshold)
+);
+
+CREATE TABLE IF NOT EXISTS sweeps (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  policy_id UUID REFERENCES sweep_policies(id) ON DELETE SET NULL,
+  chain TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  from_address TEXT NOT NULL,
+  to_address TEXT NOT NULL,
+  amount NUMERIC NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('awaiting_approval', 'pending', 'submitted', 'failed', 'cancelled')),
+  tx_hash TEXT,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sweeps_policy_status ON sweeps(policy_id, status);
+CREATE INDEX IF NOT EXISTS idx_sweeps_created ON sweeps(created_at DESC);
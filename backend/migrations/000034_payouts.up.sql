@@ -0,0 +1,35 @@
+-- Payouts owed to users. Pending payouts on the same chain/asset are grouped
+-- into one payout_batches row and sent in a single multi-recipient transaction.
+CREATE TABLE IF NOT EXISTS payout_batches (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  chain TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  payout_count INT NOT NULL,
+  total_amount NUMERIC NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('sending', 'submitted', 'failed')),
+  tx_hash TEXT,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS payouts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+  chain TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  to_address TEXT NOT NULL,
+  amount NUMERIC NOT NULL CHECK (amount > 0),
+  reference TEXT,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'batched', 'submitted', 'failed', 'cancelled')),
+  batch_id UUID REFERENCES payout_batches(id) ON DELETE SET NULL,
+  tx_hash TEXT,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payouts_pending ON payouts(chain, asset, created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_payouts_user ON payouts(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_payouts_batch ON payouts(batch_id);
+CREATE INDEX IF NOT EXISTS idx_payout_batches_created ON payout_batches(created_at DESC);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS relayed_transfers;
@@ -0,0 +1,18 @@
+-- Gasless claims: transfers relayed on a recipient's behalf from an EIP-2612
+-- permit signature. The relayer fee is taken in the token.
+CREATE TABLE IF NOT EXISTS relayed_transfers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+  chain TEXT NOT NULL,
+  token TEXT NOT NULL,
+  owner_address TEXT NOT NULL,
+  to_address TEXT NOT NULL,
+  amount NUMERIC NOT NULL CHECK (amount > 0),
+  relayer_fee NUMERIC NOT NULL CHECK (relayer_fee >= 0),
+  status TEXT NOT NULL CHECK (status IN ('submitted', 'failed')),
+  tx_hash TEXT,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_relayed_transfers_user ON relayed_transfers(user_id, created_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_badges;
+DROP TABLE IF EXISTS badges;
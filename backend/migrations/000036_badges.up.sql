@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS badges (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  slug TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  kind TEXT NOT NULL CHECK (kind IN ('milestone', 'campaign')),
+  image_url TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- token_seq doubles as the NFT token id so ids are stable before minting.
+CREATE TABLE IF NOT EXISTS user_badges (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  token_seq BIGSERIAL UNIQUE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  badge_id UUID NOT NULL REFERENCES badges(id) ON DELETE CASCADE,
+  reason TEXT,
+  awarded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  awarded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  nft_status TEXT NOT NULL DEFAULT 'none' CHECK (nft_status IN ('none', 'pending', 'minted', 'failed')),
+  nft_chain TEXT,
+  nft_contract TEXT,
+  nft_token_id TEXT,
+  nft_recipient TEXT,
+  nft_tx_hash TEXT,
+  nft_error TEXT,
+  UNIQUE (user_id, badge_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_badges_user ON user_badges(user_id, awarded_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_badges_nft_pending ON user_badges(token_seq) WHERE nft_status = 'pending';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS attestations;
+ALTER TABLE payouts DROP COLUMN IF EXISTS pr_url;
+ALTER TABLE payouts DROP COLUMN IF EXISTS pr_number;
+ALTER TABLE payouts DROP COLUMN IF EXISTS repo_full_name;
@@ -0,0 +1,27 @@
+-- Work a payout pays for; when set, the payout is attested on-chain once sent.
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS repo_full_name TEXT;
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS pr_number INT;
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS pr_url TEXT;
+
+-- Ethereum Attestation Service records linking contributor address, repo and
+-- merged PR for a paid bounty.
+CREATE TABLE IF NOT EXISTS attestations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  payout_id UUID NOT NULL UNIQUE REFERENCES payouts(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  recipient TEXT NOT NULL,
+  repo_full_name TEXT NOT NULL,
+  pr_number INT NOT NULL,
+  pr_url TEXT NOT NULL DEFAULT '',
+  chain TEXT,
+  schema_uid TEXT,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'submitted', 'attested', 'failed')),
+  tx_hash TEXT,
+  uid TEXT UNIQUE,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attestations_user ON attestations(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_attestations_open ON attestations(status) WHERE status IN ('pending', 'submitted');
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS sponsorship_events;
+DROP TABLE IF EXISTS sponsorships;
+DROP TABLE IF EXISTS sponsor_tiers;
+DROP TABLE IF EXISTS sponsor_accounts;
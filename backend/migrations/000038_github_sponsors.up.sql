@@ -0,0 +1,56 @@
+-- GitHub Sponsors listings maintainers have connected. login is the
+-- sponsorable account (the user or an org they administer). The webhook
+-- secret is per account because maintainers paste it into the Sponsors
+-- dashboard themselves; it is AES-GCM encrypted with TOKEN_ENC_KEY_B64.
+CREATE TABLE IF NOT EXISTS sponsor_accounts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  login TEXT NOT NULL,
+  webhook_secret BYTEA NOT NULL,
+  last_synced_at TIMESTAMPTZ,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, login)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sponsor_accounts_login ON sponsor_accounts(lower(login));
+
+CREATE TABLE IF NOT EXISTS sponsor_tiers (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id UUID NOT NULL REFERENCES sponsor_accounts(id) ON DELETE CASCADE,
+  github_id TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  monthly_price_cents INT NOT NULL,
+  is_one_time BOOLEAN NOT NULL DEFAULT false,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS sponsorships (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  account_id UUID NOT NULL REFERENCES sponsor_accounts(id) ON DELETE CASCADE,
+  github_id TEXT NOT NULL UNIQUE,
+  sponsor_login TEXT,
+  tier_github_id TEXT,
+  monthly_price_cents INT NOT NULL DEFAULT 0,
+  is_one_time BOOLEAN NOT NULL DEFAULT false,
+  privacy_level TEXT NOT NULL DEFAULT 'public',
+  status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+  started_at TIMESTAMPTZ NOT NULL,
+  cancelled_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sponsorships_account ON sponsorships(account_id, status);
+
+-- Raw sponsorship webhook deliveries, idempotent on delivery_id.
+CREATE TABLE IF NOT EXISTS sponsorship_events (
+  delivery_id TEXT PRIMARY KEY,
+  account_id UUID NOT NULL REFERENCES sponsor_accounts(id) ON DELETE CASCADE,
+  action TEXT NOT NULL,
+  sponsor_login TEXT,
+  monthly_price_cents INT,
+  payload JSONB NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sponsorship_events_account ON sponsorship_events(account_id, received_at DESC);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS bounties;
+DROP TABLE IF EXISTS issue_provider_accounts;
+
+DELETE FROM oauth_states WHERE kind IN ('jira_link', 'linear_link');
+
+ALTER TABLE oauth_states
+  DROP CONSTRAINT IF EXISTS oauth_states_kind_check;
+
+ALTER TABLE oauth_states
+  ADD CONSTRAINT oauth_states_kind_check CHECK (kind IN ('github_link', 'github_login', 'github_app_install'));
@@ -0,0 +1,49 @@
+ALTER TABLE oauth_states
+  DROP CONSTRAINT IF EXISTS oauth_states_kind_check;
+
+ALTER TABLE oauth_states
+  ADD CONSTRAINT oauth_states_kind_check CHECK (kind IN ('github_link', 'github_login', 'github_app_install', 'jira_link', 'linear_link'));
+
+-- OAuth links to external issue trackers. site_id is the Jira cloud id or
+-- Linear organization id. Tokens and the webhook secret are AES-GCM
+-- encrypted with TOKEN_ENC_KEY_B64.
+CREATE TABLE IF NOT EXISTS issue_provider_accounts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider TEXT NOT NULL CHECK (provider IN ('jira', 'linear')),
+  site_id TEXT NOT NULL,
+  site_url TEXT NOT NULL,
+  access_token BYTEA NOT NULL,
+  refresh_token BYTEA,
+  token_expires_at TIMESTAMPTZ,
+  webhook_secret BYTEA,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, provider)
+);
+
+-- Bounties attach to an issue from any provider. issue_external_id is the
+-- provider's stable id (GitHub issue id, Jira issue id, Linear issue uuid);
+-- issue_key is the human reference (#12, ABC-123, ENG-42).
+CREATE TABLE IF NOT EXISTS bounties (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  created_by UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+  issue_provider TEXT NOT NULL DEFAULT 'github' CHECK (issue_provider IN ('github', 'jira', 'linear')),
+  issue_provider_account_id UUID REFERENCES issue_provider_accounts(id) ON DELETE SET NULL,
+  issue_external_id TEXT NOT NULL,
+  issue_key TEXT NOT NULL,
+  issue_title TEXT,
+  issue_url TEXT,
+  issue_state TEXT,
+  issue_closed BOOLEAN NOT NULL DEFAULT false,
+  chain TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  amount NUMERIC(38, 7) NOT NULL CHECK (amount > 0),
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed', 'cancelled')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounties_project ON bounties(project_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounties_open_issue ON bounties(issue_provider, issue_external_id) WHERE status = 'open';
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS bounties_record_event ON bounties;
+DROP FUNCTION IF EXISTS record_bounty_event();
+DROP TABLE IF EXISTS bounty_events;
+DROP TABLE IF EXISTS api_keys;
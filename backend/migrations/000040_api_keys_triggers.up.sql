@@ -0,0 +1,51 @@
+-- Personal API keys for no-code integrations (Zapier, Make). Only a SHA-256
+-- of the key is stored; prefix is shown in listings to tell keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  prefix TEXT NOT NULL,
+  key_hash BYTEA NOT NULL UNIQUE,
+  scopes TEXT[] NOT NULL,
+  last_used_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys(user_id, created_at DESC);
+
+-- Append-only feed of bounty changes for polling triggers. seq is the
+-- cursor clients pass back as `since`; id is stable for deduplication.
+CREATE TABLE IF NOT EXISTS bounty_events (
+  seq BIGSERIAL PRIMARY KEY,
+  id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  type TEXT NOT NULL CHECK (type IN ('bounty.created', 'bounty.completed', 'bounty.cancelled', 'bounty.issue_closed')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounty_events_project ON bounty_events(project_id, seq);
+
+-- Bounties are also updated by issue webhooks, so events are recorded by
+-- trigger rather than by each writer.
+CREATE OR REPLACE FUNCTION record_bounty_event() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.created');
+    RETURN NEW;
+  END IF;
+  IF NEW.status IS DISTINCT FROM OLD.status AND NEW.status IN ('completed', 'cancelled') THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.' || NEW.status);
+  END IF;
+  IF NEW.issue_closed AND NOT OLD.issue_closed THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.issue_closed');
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bounties_record_event ON bounties;
+CREATE TRIGGER bounties_record_event
+  AFTER INSERT OR UPDATE ON bounties
+  FOR EACH ROW EXECUTE FUNCTION record_bounty_event();
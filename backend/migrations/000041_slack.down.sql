@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS slack_subscriptions;
+DROP TABLE IF EXISTS slack_installations;
+
+DELETE FROM oauth_states WHERE kind = 'slack_install';
+
+ALTER TABLE oauth_states
+  DROP CONSTRAINT IF EXISTS oauth_states_kind_check;
+
+ALTER TABLE oauth_states
+  ADD CONSTRAINT oauth_states_kind_check CHECK (kind IN ('github_link', 'github_login', 'github_app_install', 'jira_link', 'linear_link'));
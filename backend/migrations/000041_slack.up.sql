@@ -0,0 +1,37 @@
+ALTER TABLE oauth_states
+  DROP CONSTRAINT IF EXISTS oauth_states_kind_check;
+
+ALTER TABLE oauth_states
+  ADD CONSTRAINT oauth_states_kind_check CHECK (kind IN ('github_link', 'github_login', 'github_app_install', 'jira_link', 'linear_link', 'slack_install'));
+
+-- One Slack app install per workspace (team). Slash commands act as
+-- installed_by; only installer_slack_user_id may run commands that change
+-- bounties. bot_token is AES-GCM encrypted with TOKEN_ENC_KEY_B64.
+CREATE TABLE IF NOT EXISTS slack_installations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  team_id TEXT NOT NULL UNIQUE,
+  team_name TEXT NOT NULL DEFAULT '',
+  bot_user_id TEXT NOT NULL DEFAULT '',
+  bot_token BYTEA NOT NULL,
+  installed_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  installer_slack_user_id TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_slack_installations_user ON slack_installations(installed_by);
+
+-- Channels receiving bounty events. A NULL project_id follows every project
+-- the installer owns. cursor is the last bounty_events.seq delivered.
+CREATE TABLE IF NOT EXISTS slack_subscriptions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  installation_id UUID NOT NULL REFERENCES slack_installations(id) ON DELETE CASCADE,
+  channel_id TEXT NOT NULL,
+  project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+  event_types TEXT[] NOT NULL DEFAULT '{}',
+  cursor BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_slack_subscriptions_target
+  ON slack_subscriptions(installation_id, channel_id, COALESCE(project_id, '00000000-0000-0000-0000-000000000000'::uuid));
@@ -0,0 +1,22 @@
+-- Outbound notification channels delivered by the notify dispatcher, one
+-- transport per row (Matrix for now). A NULL project_id follows every
+-- project the owner owns. config holds non-secret transport settings
+-- (e.g. Matrix homeserver and room); secret is the AES-GCM encrypted
+-- credential. cursor is the last bounty_events.seq delivered.
+CREATE TABLE IF NOT EXISTS notification_channels (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  owner_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+  transport TEXT NOT NULL CHECK (transport IN ('matrix')),
+  name TEXT NOT NULL,
+  config JSONB NOT NULL DEFAULT '{}'::jsonb,
+  secret BYTEA,
+  event_types TEXT[] NOT NULL DEFAULT '{}',
+  cursor BIGINT NOT NULL DEFAULT 0,
+  last_delivered_at TIMESTAMPTZ,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_channels_owner ON notification_channels(owner_user_id, created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS status_incident_updates;
+DROP TABLE IF EXISTS status_incidents;
+DROP TABLE IF EXISTS status_components;
@@ -0,0 +1,47 @@
+-- Public status page. Components are updated by automated checks unless an
+-- admin has set a manual status (source = 'manual'), which sticks until the
+-- admin hands the component back to the checks.
+CREATE TABLE IF NOT EXISTS status_components (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  position INT NOT NULL DEFAULT 0,
+  status TEXT NOT NULL DEFAULT 'operational' CHECK (status IN ('operational', 'degraded', 'partial_outage', 'major_outage', 'maintenance')),
+  source TEXT NOT NULL DEFAULT 'automated' CHECK (source IN ('automated', 'manual')),
+  message TEXT,
+  checked_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO status_components (id, name, description, position) VALUES
+  ('api', 'API', 'Public and authenticated REST API', 1),
+  ('database', 'Database', 'Primary Postgres database', 2),
+  ('github_sync', 'GitHub sync', 'Issue and pull request synchronisation', 3),
+  ('payouts', 'Payouts', 'Bounty payout batches', 4)
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS status_incidents (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  title TEXT NOT NULL,
+  impact TEXT NOT NULL DEFAULT 'minor' CHECK (impact IN ('none', 'minor', 'major', 'critical')),
+  status TEXT NOT NULL DEFAULT 'investigating' CHECK (status IN ('investigating', 'identified', 'monitoring', 'resolved')),
+  component_ids TEXT[] NOT NULL DEFAULT '{}',
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  resolved_at TIMESTAMPTZ,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_incidents_started ON status_incidents(started_at DESC);
+
+CREATE TABLE IF NOT EXISTS status_incident_updates (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  incident_id UUID NOT NULL REFERENCES status_incidents(id) ON DELETE CASCADE,
+  status TEXT NOT NULL CHECK (status IN ('investigating', 'identified', 'monitoring', 'resolved')),
+  message TEXT NOT NULL,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_incident_updates_incident ON status_incident_updates(incident_id, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_wallets_one_primary;
+ALTER TABLE wallets DROP COLUMN IF EXISTS is_primary;
@@ -0,0 +1,10 @@
+-- Users may link several wallets; one of them is the primary payout wallet.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE wallets w
+SET is_primary = true
+WHERE w.id = (
+  SELECT w2.id FROM wallets w2 WHERE w2.user_id = w.user_id ORDER BY w2.created_at, w2.id LIMIT 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_one_primary ON wallets(user_id) WHERE is_primary;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS github_stars;
+DROP TABLE IF EXISTS github_commits;
+DROP TABLE IF EXISTS github_pushes;
@@ -0,0 +1,43 @@
+-- Push and star webhooks for registered projects, for contribution tracking.
+CREATE TABLE IF NOT EXISTS github_pushes (
+  delivery_id TEXT PRIMARY KEY,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  ref TEXT NOT NULL,
+  before_sha TEXT,
+  after_sha TEXT,
+  forced BOOLEAN NOT NULL DEFAULT false,
+  deleted BOOLEAN NOT NULL DEFAULT false,
+  pusher_login TEXT,
+  sender_github_id BIGINT,
+  commit_count INT NOT NULL DEFAULT 0,
+  pushed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_pushes_project ON github_pushes(project_id, pushed_at DESC);
+
+-- Distinct commits seen in pushes (first push wins).
+CREATE TABLE IF NOT EXISTS github_commits (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  sha TEXT NOT NULL,
+  ref TEXT NOT NULL,
+  message TEXT NOT NULL DEFAULT '',
+  url TEXT,
+  author_name TEXT,
+  author_email TEXT,
+  author_login TEXT,
+  committed_at TIMESTAMPTZ,
+  delivery_id TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, sha)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_commits_author ON github_commits(lower(author_login), committed_at DESC);
+
+-- Current stargazers per project; removed stars are deleted.
+CREATE TABLE IF NOT EXISTS github_stars (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  github_user_id BIGINT NOT NULL,
+  login TEXT NOT NULL,
+  starred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, github_user_id)
+);
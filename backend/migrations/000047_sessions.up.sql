@@ -0,0 +1,17 @@
+-- Signed-in devices. The id is carried as the `sid` JWT claim and doubles as
+-- the refresh token family id; revoking a session rejects its access tokens
+-- immediately and revokes its refresh tokens.
+CREATE TABLE IF NOT EXISTS sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  wallet_type TEXT,
+  address TEXT,
+  ip TEXT,
+  user_agent TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_active ON sessions(user_id, last_seen_at DESC) WHERE revoked_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE sweep_destinations
+  DROP COLUMN IF EXISTS revoked_by,
+  DROP COLUMN IF EXISTS revoked_at;
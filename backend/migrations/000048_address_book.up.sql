@@ -0,0 +1,10 @@
+-- sweep_destinations is the platform's payout address book. Entries can be
+-- revoked, and an entry must be approved by someone other than its creator.
+ALTER TABLE sweep_destinations
+  ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS revoked_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Self-approved entries never had a second pair of eyes; send them back for review.
+UPDATE sweep_destinations
+SET approved_by = NULL, approved_at = NULL
+WHERE approved_by IS NOT NULL AND approved_by = created_by;
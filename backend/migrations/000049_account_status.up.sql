@@ -0,0 +1,8 @@
+-- Account restrictions set by admins. Suspended users can't sign in until
+-- suspended_until; banned users can't sign in at all. Changes are recorded in
+-- moderation_actions.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS suspension_reason TEXT,
+  ADD COLUMN IF NOT EXISTS banned_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS ban_reason TEXT;
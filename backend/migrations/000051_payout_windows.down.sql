@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payout_batches_window_run;
+ALTER TABLE payout_batches DROP COLUMN IF EXISTS window_run_id;
+DROP TABLE IF EXISTS payout_window_runs;
+DROP TABLE IF EXISTS payout_windows;
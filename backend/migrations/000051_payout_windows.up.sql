@@ -0,0 +1,31 @@
+-- Weekly payout windows (UTC). While any window exists, pending payouts wait
+-- for the next window and are sent together; each window occurrence gets one
+-- payout_window_runs row summarising what was sent.
+CREATE TABLE IF NOT EXISTS payout_windows (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+  hour SMALLINT NOT NULL CHECK (hour BETWEEN 0 AND 23),
+  minute SMALLINT NOT NULL CHECK (minute BETWEEN 0 AND 59),
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (weekday, hour, minute)
+);
+
+CREATE TABLE IF NOT EXISTS payout_window_runs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  window_id UUID REFERENCES payout_windows(id) ON DELETE SET NULL,
+  scheduled_for TIMESTAMPTZ NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  batches INT NOT NULL DEFAULT 0,
+  payouts INT NOT NULL DEFAULT 0,
+  failed INT NOT NULL DEFAULT 0,
+  UNIQUE (window_id, scheduled_for)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_window_runs_started ON payout_window_runs(started_at DESC);
+
+ALTER TABLE payout_batches
+  ADD COLUMN IF NOT EXISTS window_run_id UUID REFERENCES payout_window_runs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payout_batches_window_run ON payout_batches(window_run_id) WHERE window_run_id IS NOT NULL;
@@ -0,0 +1,16 @@
+-- Pending GitHub OAuth device flow links. The CLI polls by id; the device
+-- code itself never leaves the server and is stored encrypted.
+CREATE TABLE IF NOT EXISTS github_device_links (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  device_code BYTEA NOT NULL,
+  user_code TEXT NOT NULL,
+  verification_uri TEXT NOT NULL,
+  interval_seconds INT NOT NULL,
+  next_poll_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_device_links_user ON github_device_links(user_id);
+CREATE INDEX IF NOT EXISTS idx_github_device_links_expires ON github_device_links(expires_at);
@@ -0,0 +1,13 @@
+-- Leases for singleton background jobs. A replica runs a job only while it
+-- holds the job's unexpired lease, and only once per interval
+-- (last_started_at). Holders renew while running; a crashed holder's lease
+-- expires and another replica takes over.
+CREATE TABLE IF NOT EXISTS job_leases (
+  name TEXT PRIMARY KEY,
+  holder TEXT,
+  acquired_at TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_started_at TIMESTAMPTZ,
+  last_finished_at TIMESTAMPTZ,
+  takeovers BIGINT NOT NULL DEFAULT 0
+);
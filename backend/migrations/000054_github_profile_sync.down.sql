@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS github_contribution_stats;
+DROP TABLE IF EXISTS github_user_repos;
+DROP TABLE IF EXISTS github_profiles;
+DROP TABLE IF EXISTS job_queue;
@@ -0,0 +1,66 @@
+-- Postgres-backed job queue. Workers claim due rows with SKIP LOCKED; failed
+-- jobs are retried with backoff until max_attempts, then kept as 'failed'.
+-- At most one pending job per (kind, dedupe_key).
+CREATE TABLE IF NOT EXISTS job_queue (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  kind TEXT NOT NULL,
+  dedupe_key TEXT NOT NULL DEFAULT '',
+  payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'failed')),
+  attempts INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL DEFAULT 5,
+  run_after TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_by TEXT,
+  locked_at TIMESTAMPTZ,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_job_queue_pending_dedupe ON job_queue(kind, dedupe_key) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_job_queue_due ON job_queue(run_after) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_job_queue_running ON job_queue(locked_at) WHERE status = 'running';
+
+-- Local copies of linked users' GitHub data, refreshed by the queue so that
+-- profile reads never call GitHub.
+CREATE TABLE IF NOT EXISTS github_profiles (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  github_user_id BIGINT NOT NULL,
+  login TEXT NOT NULL,
+  name TEXT,
+  email TEXT,
+  avatar_url TEXT,
+  location TEXT,
+  bio TEXT,
+  blog TEXT,
+  synced_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS github_user_repos (
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  github_repo_id BIGINT NOT NULL,
+  full_name TEXT NOT NULL,
+  html_url TEXT NOT NULL,
+  description TEXT,
+  language TEXT,
+  private BOOLEAN NOT NULL DEFAULT false,
+  fork BOOLEAN NOT NULL DEFAULT false,
+  stars INT NOT NULL DEFAULT 0,
+  forks INT NOT NULL DEFAULT 0,
+  pushed_at TIMESTAMPTZ,
+  synced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, github_repo_id)
+);
+
+CREATE TABLE IF NOT EXISTS github_contribution_stats (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  period_start TIMESTAMPTZ NOT NULL,
+  period_end TIMESTAMPTZ NOT NULL,
+  total INT NOT NULL DEFAULT 0,
+  commits INT NOT NULL DEFAULT 0,
+  pull_requests INT NOT NULL DEFAULT 0,
+  pull_request_reviews INT NOT NULL DEFAULT 0,
+  issues INT NOT NULL DEFAULT 0,
+  restricted INT NOT NULL DEFAULT 0,
+  synced_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
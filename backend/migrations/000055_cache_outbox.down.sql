@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS cache_outbox_bounties ON bounties;
+DROP FUNCTION IF EXISTS cache_outbox_bounties();
+DROP TRIGGER IF EXISTS cache_outbox_github_accounts ON github_accounts;
+DROP FUNCTION IF EXISTS cache_outbox_github_accounts();
+DROP TRIGGER IF EXISTS cache_outbox_users ON users;
+DROP FUNCTION IF EXISTS cache_outbox_users();
+DROP TRIGGER IF EXISTS cache_outbox_notify ON cache_outbox;
+DROP FUNCTION IF EXISTS cache_outbox_notify();
+DROP TABLE IF EXISTS cache_outbox;
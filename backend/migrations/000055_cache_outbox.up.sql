@@ -0,0 +1,86 @@
+-- Outbox of cache invalidations. Triggers append a row whenever data behind a
+-- cached API response changes, in the same transaction as the change; each
+-- insert is announced on the cache_invalidation channel once it commits, so
+-- every API instance drops its copies. Rows are pruned by a background job.
+CREATE TABLE IF NOT EXISTS cache_outbox (
+  id BIGSERIAL PRIMARY KEY,
+  topic TEXT NOT NULL,
+  keys TEXT[] NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cache_outbox_created ON cache_outbox(created_at);
+
+CREATE OR REPLACE FUNCTION cache_outbox_notify()
+RETURNS TRIGGER AS $$
+BEGIN
+  PERFORM pg_notify('cache_invalidation', json_build_object('topic', NEW.topic, 'keys', NEW.keys)::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS cache_outbox_notify ON cache_outbox;
+CREATE TRIGGER cache_outbox_notify
+  AFTER INSERT ON cache_outbox
+  FOR EACH ROW EXECUTE FUNCTION cache_outbox_notify();
+
+-- Public profiles are cached by user id and by lower-cased GitHub login.
+CREATE OR REPLACE FUNCTION cache_outbox_users()
+RETURNS TRIGGER AS $$
+BEGIN
+  INSERT INTO cache_outbox (topic, keys)
+  SELECT 'profile', ARRAY[NEW.id::text] || COALESCE(array_agg('login:' || lower(ga.login)), '{}')
+  FROM github_accounts ga
+  WHERE ga.user_id = NEW.id;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS cache_outbox_users ON users;
+CREATE TRIGGER cache_outbox_users
+  AFTER UPDATE ON users
+  FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+  EXECUTE FUNCTION cache_outbox_users();
+
+CREATE OR REPLACE FUNCTION cache_outbox_github_accounts()
+RETURNS TRIGGER AS $$
+DECLARE
+  k TEXT[] := '{}';
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    k := k || ARRAY[OLD.user_id::text, 'login:' || lower(OLD.login)];
+  END IF;
+  IF TG_OP <> 'DELETE' THEN
+    k := k || ARRAY[NEW.user_id::text, 'login:' || lower(NEW.login)];
+  END IF;
+  INSERT INTO cache_outbox (topic, keys) VALUES ('profile', k);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS cache_outbox_github_accounts ON github_accounts;
+CREATE TRIGGER cache_outbox_github_accounts
+  AFTER INSERT OR UPDATE OR DELETE ON github_accounts
+  FOR EACH ROW EXECUTE FUNCTION cache_outbox_github_accounts();
+
+-- Bounty lists are cached per project.
+CREATE OR REPLACE FUNCTION cache_outbox_bounties()
+RETURNS TRIGGER AS $$
+DECLARE
+  k TEXT[] := '{}';
+BEGIN
+  IF TG_OP <> 'INSERT' THEN
+    k := k || OLD.project_id::text;
+  END IF;
+  IF TG_OP <> 'DELETE' AND NOT (NEW.project_id::text = ANY(k)) THEN
+    k := k || NEW.project_id::text;
+  END IF;
+  INSERT INTO cache_outbox (topic, keys) VALUES ('bounty', k);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS cache_outbox_bounties ON bounties;
+CREATE TRIGGER cache_outbox_bounties
+  AFTER INSERT OR UPDATE OR DELETE ON bounties
+  FOR EACH ROW EXECUTE FUNCTION cache_outbox_bounties();
@@ -0,0 +1 @@
+DROP TRIGGER IF EXISTS cache_outbox_github_profiles ON github_profiles;
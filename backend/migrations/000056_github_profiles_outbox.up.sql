@@ -0,0 +1,7 @@
+-- /auth/me caches each user's synced GitHub profile; drop it cluster-wide
+-- whenever a sync rewrites the row. github_profiles has the same user_id and
+-- login columns as github_accounts, so the same keys are emitted.
+DROP TRIGGER IF EXISTS cache_outbox_github_profiles ON github_profiles;
+CREATE TRIGGER cache_outbox_github_profiles
+  AFTER INSERT OR UPDATE OR DELETE ON github_profiles
+  FOR EACH ROW EXECUTE FUNCTION cache_outbox_github_accounts();
@@ -0,0 +1,3 @@
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS last_error_code;
+ALTER TABLE payout_batches DROP COLUMN IF EXISTS error_code;
+ALTER TABLE payouts DROP COLUMN IF EXISTS error_code;
@@ -0,0 +1,6 @@
+-- Stable failure codes (see internal/failures) stored beside the raw error
+-- text, so API responses and notifications can carry a typed code and a
+-- remediation hint. Existing failed rows keep NULL and read as "unknown".
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS error_code TEXT;
+ALTER TABLE payout_batches ADD COLUMN IF NOT EXISTS error_code TEXT;
+ALTER TABLE sync_jobs ADD COLUMN IF NOT EXISTS last_error_code TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS abuse_report_attachments;
+DROP TABLE IF EXISTS abuse_reports;
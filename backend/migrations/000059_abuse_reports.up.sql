@@ -0,0 +1,33 @@
+-- User-submitted abuse reports; open reports form the moderation queue.
+CREATE TABLE IF NOT EXISTS abuse_reports (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  reporter_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  -- target_id is a bounty or user id, or a GitHub comment id.
+  target_type TEXT NOT NULL CHECK (target_type IN ('bounty', 'user', 'comment')),
+  target_id TEXT NOT NULL,
+  category TEXT NOT NULL CHECK (category IN ('spam', 'harassment', 'scam', 'plagiarism', 'inappropriate', 'other')),
+  details TEXT,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+  resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  resolution_note TEXT,
+  resolved_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_abuse_reports_queue ON abuse_reports(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_abuse_reports_target ON abuse_reports(target_type, target_id);
+-- One open report per reporter and target.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_abuse_reports_open_reporter ON abuse_reports(reporter_user_id, target_type, target_id) WHERE status = 'open';
+
+-- Evidence (screenshots, logs) stored inline; reports carry a few small files.
+CREATE TABLE IF NOT EXISTS abuse_report_attachments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  report_id UUID NOT NULL REFERENCES abuse_reports(id) ON DELETE CASCADE,
+  filename TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size_bytes INT NOT NULL,
+  data BYTEA NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_abuse_report_attachments_report ON abuse_report_attachments(report_id);
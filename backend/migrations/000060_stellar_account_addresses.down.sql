@@ -0,0 +1,7 @@
+UPDATE wallets
+SET address = lower(address)
+WHERE wallet_type = 'stellar_ed25519' AND address ~ '^G[A-Z2-7]{55}$';
+
+UPDATE auth_nonces
+SET address = lower(address)
+WHERE wallet_type = 'stellar_ed25519' AND address ~ '^G[A-Z2-7]{55}$';
@@ -0,0 +1,9 @@
+-- Stellar G... addresses are now kept in their canonical upper case (they
+-- were lowercased like opaque identifiers before). Hex keys never match.
+UPDATE wallets
+SET address = upper(address)
+WHERE wallet_type = 'stellar_ed25519' AND address ~ '^g[a-z2-7]{55}$';
+
+UPDATE auth_nonces
+SET address = upper(address)
+WHERE wallet_type = 'stellar_ed25519' AND address ~ '^g[a-z2-7]{55}$';
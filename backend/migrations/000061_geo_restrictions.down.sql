@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS geo_policies;
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS last_ip_country_at,
+  DROP COLUMN IF EXISTS last_ip_country,
+  DROP COLUMN IF EXISTS country;
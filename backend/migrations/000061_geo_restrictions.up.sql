@@ -0,0 +1,19 @@
+-- Country restrictions on money movement. A user's country is taken from
+-- their self-declared country and/or the country of their IP, as reported by
+-- the edge (e.g. CF-IPCountry) when they last signed in.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS country TEXT,
+  ADD COLUMN IF NOT EXISTS last_ip_country TEXT,
+  ADD COLUMN IF NOT EXISTS last_ip_country_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS geo_policies (
+  action TEXT PRIMARY KEY CHECK (action IN ('bounty_funding', 'payout')),
+  -- ISO 3166-1 alpha-2 codes, upper case.
+  blocked_countries TEXT[] NOT NULL DEFAULT '{}',
+  check_ip BOOLEAN NOT NULL DEFAULT true,
+  check_declared BOOLEAN NOT NULL DEFAULT true,
+  -- Block when no checked source yields a country.
+  block_unknown BOOLEAN NOT NULL DEFAULT false,
+  updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS account_recoveries;
+DROP TABLE IF EXISTS email_challenges;
+DROP INDEX IF EXISTS idx_users_verified_email;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+ALTER TABLE users DROP COLUMN IF EXISTS email;
@@ -0,0 +1,41 @@
+-- A verified email lets a user recover an account whose wallets they lost.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_verified_email ON users(lower(email)) WHERE email_verified_at IS NOT NULL;
+
+-- One-time codes mailed to verify an address or to start a recovery. Only
+-- the latest unconsumed code per (user, purpose) is accepted.
+CREATE TABLE IF NOT EXISTS email_challenges (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  purpose TEXT NOT NULL CHECK (purpose IN ('verify_email', 'recovery')),
+  email TEXT NOT NULL,
+  code_hash BYTEA NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  expires_at TIMESTAMPTZ NOT NULL,
+  consumed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_challenges_user ON email_challenges(user_id, purpose, created_at DESC);
+
+-- A new wallet waiting to be bound to an account. It can be completed with
+-- the token once available_at passes, and cancelled from any signed-in
+-- session until then.
+CREATE TABLE IF NOT EXISTS account_recoveries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'cancelled')),
+  wallet_type TEXT NOT NULL,
+  address TEXT NOT NULL,
+  public_key TEXT,
+  token_hash BYTEA NOT NULL UNIQUE,
+  requested_ip TEXT,
+  available_at TIMESTAMPTZ NOT NULL,
+  completed_at TIMESTAMPTZ,
+  cancelled_at TIMESTAMPTZ,
+  cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_recoveries_pending ON account_recoveries(user_id) WHERE status = 'pending';
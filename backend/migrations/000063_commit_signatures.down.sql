@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_github_commits_unchecked;
+ALTER TABLE github_commits DROP COLUMN IF EXISTS signature_checked_at;
+ALTER TABLE github_commits DROP COLUMN IF EXISTS signature_reason;
+ALTER TABLE github_commits DROP COLUMN IF EXISTS signature_status;
@@ -0,0 +1,8 @@
+-- Signature checks of commits credited to linked contributors. NULL
+-- signature_status means not checked yet.
+ALTER TABLE github_commits ADD COLUMN IF NOT EXISTS signature_status TEXT
+  CHECK (signature_status IN ('verified', 'other_signer', 'unsigned', 'invalid', 'unavailable'));
+ALTER TABLE github_commits ADD COLUMN IF NOT EXISTS signature_reason TEXT;
+ALTER TABLE github_commits ADD COLUMN IF NOT EXISTS signature_checked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_github_commits_unchecked ON github_commits(created_at) WHERE signature_checked_at IS NULL;
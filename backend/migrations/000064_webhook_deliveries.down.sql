@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS cursor;
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS description;
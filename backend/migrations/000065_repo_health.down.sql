@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS project_health;
+DROP TABLE IF EXISTS github_check_suites;
+DROP TABLE IF EXISTS github_issue_comment_events;
+DROP TABLE IF EXISTS github_pr_reviews;
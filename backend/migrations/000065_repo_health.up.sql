@@ -0,0 +1,56 @@
+-- Review, comment and CI activity mirrored from GitHub webhooks, used to
+-- compute repo health for funders.
+CREATE TABLE IF NOT EXISTS github_pr_reviews (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  github_review_id BIGINT NOT NULL,
+  pr_number INT NOT NULL,
+  reviewer_login TEXT,
+  author_association TEXT,
+  state TEXT NOT NULL,
+  submitted_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (project_id, github_review_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_pr_reviews_pr ON github_pr_reviews(project_id, pr_number, submitted_at);
+
+-- Comments on issues and pull requests (GitHub numbers both alike).
+CREATE TABLE IF NOT EXISTS github_issue_comment_events (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  github_comment_id BIGINT NOT NULL,
+  issue_number INT NOT NULL,
+  author_login TEXT,
+  author_association TEXT,
+  created_at_github TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (project_id, github_comment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_issue_comment_events_issue ON github_issue_comment_events(project_id, issue_number, created_at_github);
+
+CREATE TABLE IF NOT EXISTS github_check_suites (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  github_suite_id BIGINT NOT NULL,
+  head_sha TEXT,
+  head_branch TEXT,
+  app_slug TEXT,
+  conclusion TEXT,
+  completed_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (project_id, github_suite_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_check_suites_completed ON github_check_suites(project_id, completed_at DESC);
+
+-- Latest computed health per project. NULL metrics mean no data.
+CREATE TABLE IF NOT EXISTS project_health (
+  project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+  window_days INT NOT NULL,
+  prs_opened INT NOT NULL DEFAULT 0,
+  prs_merged INT NOT NULL DEFAULT 0,
+  median_review_hours DOUBLE PRECISION,
+  median_merge_hours DOUBLE PRECISION,
+  ci_runs INT NOT NULL DEFAULT 0,
+  ci_pass_rate DOUBLE PRECISION,
+  median_response_hours DOUBLE PRECISION,
+  response_rate DOUBLE PRECISION,
+  rating TEXT NOT NULL CHECK (rating IN ('good', 'fair', 'poor', 'insufficient_data')),
+  computed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_bounties_skill_tags;
+ALTER TABLE bounties DROP COLUMN IF EXISTS skill_tags_overridden;
+ALTER TABLE bounties DROP COLUMN IF EXISTS skill_tags;
+ALTER TABLE projects DROP COLUMN IF EXISTS skills_detected_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS detected_skills;
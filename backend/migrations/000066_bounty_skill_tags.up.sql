@@ -0,0 +1,10 @@
+-- Languages and frameworks detected from a project's repo, cached between
+-- bounties, and the skill tags applied to each bounty. Overridden tags were
+-- set by a maintainer and are never replaced by detection.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS detected_skills TEXT[];
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS skills_detected_at TIMESTAMPTZ;
+
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS skill_tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS skill_tags_overridden BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_bounties_skill_tags ON bounties USING GIN (skill_tags);
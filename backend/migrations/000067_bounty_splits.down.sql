@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bounty_split_shares;
+DROP TABLE IF EXISTS bounty_splits;
@@ -0,0 +1,32 @@
+-- Suggested splits of a bounty between the authors of a team pull request,
+-- weighted by commits and lines changed. Claimants accept or adjust the
+-- shares before payout; a new suggestion supersedes the previous one.
+CREATE TABLE IF NOT EXISTS bounty_splits (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  repo_full_name TEXT NOT NULL,
+  pr_number INT NOT NULL,
+  -- proposed, accepted (every claimant agreed) or superseded.
+  status TEXT NOT NULL DEFAULT 'proposed',
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounty_splits_current ON bounty_splits(bounty_id) WHERE status <> 'superseded';
+
+CREATE TABLE IF NOT EXISTS bounty_split_shares (
+  split_id UUID NOT NULL REFERENCES bounty_splits(id) ON DELETE CASCADE,
+  github_login TEXT NOT NULL,
+  -- The Grainlify user linked to github_login, if any.
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  commits INT NOT NULL,
+  lines_changed INT NOT NULL,
+  suggested_bps INT NOT NULL,
+  share_bps INT NOT NULL,
+  amount NUMERIC NOT NULL,
+  accepted_at TIMESTAMPTZ,
+  PRIMARY KEY (split_id, github_login)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounty_split_shares_user ON bounty_split_shares(user_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_payouts_escrow_bounty;
+ALTER TABLE payouts DROP COLUMN IF EXISTS escrow_bounty_id;
+ALTER TABLE bounties DROP COLUMN IF EXISTS escrow_lock_tx;
+ALTER TABLE bounties DROP COLUMN IF EXISTS escrow_deadline;
+ALTER TABLE bounties DROP COLUMN IF EXISTS escrow_status;
+ALTER TABLE bounties DROP COLUMN IF EXISTS escrow_ref;
+ALTER TABLE bounties DROP COLUMN IF EXISTS escrow_contract;
+ALTER TABLE bounties DROP COLUMN IF EXISTS funding;
+DROP SEQUENCE IF EXISTS bounty_escrow_ref_seq;
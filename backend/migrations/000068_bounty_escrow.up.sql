@@ -0,0 +1,18 @@
+-- Bounties funded into an on-chain escrow contract by the maintainer rather
+-- than paid from the platform hot wallet. escrow_ref is the bounty's id in
+-- the contract. Releases are payouts tied to the bounty, sent as contract
+-- release calls once the maintainer has signed off on them.
+CREATE SEQUENCE IF NOT EXISTS bounty_escrow_ref_seq;
+
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS funding TEXT NOT NULL DEFAULT 'hot_wallet';
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS escrow_contract TEXT;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS escrow_ref BIGINT UNIQUE;
+-- awaiting_lock, locked, releasing, released.
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS escrow_status TEXT;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS escrow_deadline TIMESTAMPTZ;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS escrow_lock_tx TEXT;
+
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS escrow_bounty_id UUID REFERENCES bounties(id);
+-- A bounty's escrow is released once; a failed release is retried, not
+-- created again.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payouts_escrow_bounty ON payouts(escrow_bounty_id) WHERE escrow_bounty_id IS NOT NULL AND status <> 'cancelled';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payment_proofs;
+DROP TABLE IF EXISTS accounting_endpoints;
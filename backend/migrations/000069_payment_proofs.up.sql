@@ -0,0 +1,37 @@
+-- Proof-of-payment deliveries to a funding project's accounting system (an
+-- ERP or bookkeeping endpoint). Once a payout for work on the project has
+-- been sent, the proofs worker numbers it as an invoice and POSTs a signed
+-- proof document to the project's endpoint, retrying failures with
+-- exponential backoff. payment_proofs rows double as the delivery log.
+CREATE TABLE IF NOT EXISTS accounting_endpoints (
+  project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  secret BYTEA NOT NULL,
+  invoice_prefix TEXT NOT NULL DEFAULT 'GRL',
+  next_invoice BIGINT NOT NULL DEFAULT 1,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS payment_proofs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  payout_id UUID NOT NULL UNIQUE REFERENCES payouts(id) ON DELETE CASCADE,
+  invoice_number TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_attempt_at TIMESTAMPTZ,
+  response_status INT,
+  response_body TEXT,
+  error TEXT,
+  duration_ms INT,
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (project_id, invoice_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_proofs_due ON payment_proofs(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_payment_proofs_project ON payment_proofs(project_id, created_at DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE bounties DROP COLUMN IF EXISTS metadata;
+ALTER TABLE projects DROP COLUMN IF EXISTS metadata;
+DROP TABLE IF EXISTS metadata_fields;
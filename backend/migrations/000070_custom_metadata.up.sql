@@ -0,0 +1,21 @@
+-- Custom metadata integrators attach to projects and bounties (their own
+-- ticket ids, cost centers, ...). Each project defines the fields it
+-- accepts; values are validated against the definitions on write.
+CREATE TABLE IF NOT EXISTS metadata_fields (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  -- project or bounty.
+  entity TEXT NOT NULL,
+  key TEXT NOT NULL,
+  -- string, number, boolean or enum.
+  type TEXT NOT NULL,
+  required BOOLEAN NOT NULL DEFAULT false,
+  -- Allowed values of an enum field.
+  options TEXT[] NOT NULL DEFAULT '{}',
+  description TEXT,
+  position INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, entity, key)
+);
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
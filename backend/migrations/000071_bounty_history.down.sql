@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS payouts_record_bounty_history ON payouts;
+DROP FUNCTION IF EXISTS record_bounty_payout_history();
+DROP TRIGGER IF EXISTS bounty_split_shares_record_history ON bounty_split_shares;
+DROP FUNCTION IF EXISTS record_bounty_split_share_history();
+DROP TRIGGER IF EXISTS bounty_splits_record_history ON bounty_splits;
+DROP FUNCTION IF EXISTS record_bounty_split_history();
+DROP TRIGGER IF EXISTS bounties_record_history ON bounties;
+DROP FUNCTION IF EXISTS record_bounty_history();
+DROP FUNCTION IF EXISTS history_change(TEXT, JSONB, JSONB);
+DROP FUNCTION IF EXISTS history_actor();
+DROP TABLE IF EXISTS bounty_history;
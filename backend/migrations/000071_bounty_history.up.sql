@@ -0,0 +1,160 @@
+-- Full timeline of a bounty for disputes and audits: state transitions,
+-- edits with their before/after values, split and escrow payout changes.
+-- Unlike bounty_events, which feeds integrations a few coarse event types,
+-- every change is kept here. Entries are recorded by trigger; writers acting
+-- for a user set grainlify.actor_user_id for the transaction, and entries
+-- without it are the system's (webhooks, jobs).
+CREATE TABLE IF NOT EXISTS bounty_history (
+  seq BIGSERIAL PRIMARY KEY,
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  actor_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  -- {"field": {"from": ..., "to": ...}} for edits and transitions, plus ids
+  -- of the split or payout concerned.
+  changes JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounty_history_bounty ON bounty_history(bounty_id, seq);
+
+CREATE OR REPLACE FUNCTION history_actor() RETURNS UUID AS $$
+  SELECT NULLIF(current_setting('grainlify.actor_user_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION history_change(field TEXT, old_value JSONB, new_value JSONB) RETURNS JSONB AS $$
+  SELECT CASE WHEN old_value IS DISTINCT FROM new_value
+    THEN jsonb_build_object(field, jsonb_build_object('from', old_value, 'to', new_value))
+    ELSE '{}'::jsonb END
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION record_bounty_history() RETURNS trigger AS $$
+DECLARE
+  edits JSONB;
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.id, 'bounty.created', COALESCE(history_actor(), NEW.created_by), jsonb_build_object(
+      'issue_provider', NEW.issue_provider, 'issue_key', NEW.issue_key,
+      'chain', NEW.chain, 'asset', NEW.asset, 'amount', NEW.amount::text));
+    RETURN NEW;
+  END IF;
+  IF NEW.status IS DISTINCT FROM OLD.status THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.id, 'bounty.status_changed', history_actor(), history_change('status', to_jsonb(OLD.status), to_jsonb(NEW.status)));
+  END IF;
+  IF NEW.issue_closed IS DISTINCT FROM OLD.issue_closed THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.id, CASE WHEN NEW.issue_closed THEN 'bounty.issue_closed' ELSE 'bounty.issue_reopened' END, history_actor(), '{}');
+  END IF;
+  IF NEW.escrow_status IS DISTINCT FROM OLD.escrow_status THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.id, 'bounty.escrow_status_changed', history_actor(),
+      history_change('escrow_status', to_jsonb(OLD.escrow_status), to_jsonb(NEW.escrow_status))
+      || history_change('escrow_lock_tx', to_jsonb(OLD.escrow_lock_tx), to_jsonb(NEW.escrow_lock_tx)));
+  END IF;
+  edits := history_change('amount', to_jsonb(OLD.amount::text), to_jsonb(NEW.amount::text))
+    || history_change('asset', to_jsonb(OLD.asset), to_jsonb(NEW.asset))
+    || history_change('chain', to_jsonb(OLD.chain), to_jsonb(NEW.chain))
+    || history_change('funding', to_jsonb(OLD.funding), to_jsonb(NEW.funding))
+    || history_change('issue_title', to_jsonb(OLD.issue_title), to_jsonb(NEW.issue_title))
+    || history_change('skill_tags', to_jsonb(OLD.skill_tags), to_jsonb(NEW.skill_tags))
+    || history_change('metadata', OLD.metadata, NEW.metadata);
+  IF edits <> '{}'::jsonb THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.id, 'bounty.edited', history_actor(), edits);
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bounties_record_history ON bounties;
+CREATE TRIGGER bounties_record_history
+  AFTER INSERT OR UPDATE ON bounties
+  FOR EACH ROW EXECUTE FUNCTION record_bounty_history();
+
+CREATE OR REPLACE FUNCTION record_bounty_split_history() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.bounty_id, 'split.' || NEW.status, COALESCE(history_actor(), NEW.created_by),
+      jsonb_build_object('split_id', NEW.id, 'repo_full_name', NEW.repo_full_name, 'pr_number', NEW.pr_number));
+  ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.bounty_id, 'split.' || NEW.status, history_actor(),
+      jsonb_build_object('split_id', NEW.id) || history_change('status', to_jsonb(OLD.status), to_jsonb(NEW.status)));
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bounty_splits_record_history ON bounty_splits;
+CREATE TRIGGER bounty_splits_record_history
+  AFTER INSERT OR UPDATE ON bounty_splits
+  FOR EACH ROW EXECUTE FUNCTION record_bounty_split_history();
+
+CREATE OR REPLACE FUNCTION record_bounty_split_share_history() RETURNS trigger AS $$
+DECLARE
+  bounty UUID;
+BEGIN
+  SELECT bounty_id INTO bounty FROM bounty_splits WHERE id = NEW.split_id;
+  IF NEW.share_bps IS DISTINCT FROM OLD.share_bps THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (bounty, 'split.share_changed', history_actor(),
+      jsonb_build_object('split_id', NEW.split_id, 'github_login', NEW.github_login)
+      || history_change('share_bps', to_jsonb(OLD.share_bps), to_jsonb(NEW.share_bps))
+      || history_change('amount', to_jsonb(OLD.amount::text), to_jsonb(NEW.amount::text)));
+  END IF;
+  IF NEW.accepted_at IS NOT NULL AND OLD.accepted_at IS NULL THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (bounty, 'split.share_accepted', COALESCE(history_actor(), NEW.user_id),
+      jsonb_build_object('split_id', NEW.split_id, 'github_login', NEW.github_login));
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bounty_split_shares_record_history ON bounty_split_shares;
+CREATE TRIGGER bounty_split_shares_record_history
+  AFTER UPDATE ON bounty_split_shares
+  FOR EACH ROW EXECUTE FUNCTION record_bounty_split_share_history();
+
+CREATE OR REPLACE FUNCTION record_bounty_payout_history() RETURNS trigger AS $$
+BEGIN
+  IF NEW.escrow_bounty_id IS NULL THEN
+    RETURN NEW;
+  END IF;
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.escrow_bounty_id, 'payout.created', history_actor(),
+      jsonb_build_object('payout_id', NEW.id, 'recipient_user_id', NEW.user_id, 'amount', NEW.amount::text, 'status', NEW.status));
+  ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.escrow_bounty_id, 'payout.status_changed', history_actor(),
+      jsonb_build_object('payout_id', NEW.id)
+      || history_change('status', to_jsonb(OLD.status), to_jsonb(NEW.status))
+      || history_change('tx_hash', to_jsonb(OLD.tx_hash), to_jsonb(NEW.tx_hash)));
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS payouts_record_bounty_history ON payouts;
+CREATE TRIGGER payouts_record_bounty_history
+  AFTER INSERT OR UPDATE ON payouts
+  FOR EACH ROW EXECUTE FUNCTION record_bounty_payout_history();
+
+-- Earlier bounties get their history from the event log.
+INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes, created_at)
+SELECT e.bounty_id,
+       CASE e.type WHEN 'bounty.created' THEN 'bounty.created'
+                   WHEN 'bounty.issue_closed' THEN 'bounty.issue_closed'
+                   ELSE 'bounty.status_changed' END,
+       CASE WHEN e.type = 'bounty.created' THEN b.created_by END,
+       CASE e.type WHEN 'bounty.completed' THEN '{"status": {"from": "open", "to": "completed"}}'::jsonb
+                   WHEN 'bounty.cancelled' THEN '{"status": {"from": "open", "to": "cancelled"}}'::jsonb
+                   ELSE '{}'::jsonb END,
+       e.created_at
+FROM bounty_events e
+JOIN bounties b ON b.id = e.bounty_id
+WHERE NOT EXISTS (SELECT 1 FROM bounty_history h WHERE h.bounty_id = e.bounty_id)
+ORDER BY e.seq;
@@ -0,0 +1,3 @@
+-- Plan tier of an account, picking the token bucket its API keys draw from
+-- (see API_KEY_RATE_LIMIT_TIERS).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS plan_tier TEXT NOT NULL DEFAULT 'free';
@@ -0,0 +1,5 @@
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_funding_address_check;
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS funding_address,
+  DROP COLUMN IF EXISTS funding_wallet_type,
+  DROP COLUMN IF EXISTS description;
@@ -0,0 +1,10 @@
+-- Maintainer-provided project details: a description shown instead of the
+-- GitHub one, and the address the project funds bounties from.
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS description TEXT,
+  ADD COLUMN IF NOT EXISTS funding_wallet_type TEXT,
+  ADD COLUMN IF NOT EXISTS funding_address TEXT;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_funding_address_check;
+ALTER TABLE projects ADD CONSTRAINT projects_funding_address_check
+  CHECK ((funding_wallet_type IS NULL) = (funding_address IS NULL));
@@ -0,0 +1,36 @@
+DROP TRIGGER IF EXISTS bounties_record_claim_history ON bounties;
+DROP FUNCTION IF EXISTS record_bounty_claim_history();
+
+CREATE OR REPLACE FUNCTION record_bounty_event() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.created');
+    RETURN NEW;
+  END IF;
+  IF NEW.status IS DISTINCT FROM OLD.status AND NEW.status IN ('completed', 'cancelled') THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.' || NEW.status);
+  END IF;
+  IF NEW.issue_closed AND NOT OLD.issue_closed THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.issue_closed');
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_bounties_claimed_by;
+ALTER TABLE bounties DROP COLUMN IF EXISTS payout_id;
+ALTER TABLE bounties DROP COLUMN IF EXISTS approved_at;
+ALTER TABLE bounties DROP COLUMN IF EXISTS approved_by;
+ALTER TABLE bounties DROP COLUMN IF EXISTS submitted_at;
+ALTER TABLE bounties DROP COLUMN IF EXISTS pr_url;
+ALTER TABLE bounties DROP COLUMN IF EXISTS pr_number;
+ALTER TABLE bounties DROP COLUMN IF EXISTS pr_repo_full_name;
+ALTER TABLE bounties DROP COLUMN IF EXISTS claimed_at;
+ALTER TABLE bounties DROP COLUMN IF EXISTS claimed_by;
+ALTER TABLE bounties DROP COLUMN IF EXISTS deadline;
+
+UPDATE bounties SET status = 'open' WHERE status IN ('claimed', 'submitted', 'approved');
+DROP INDEX IF EXISTS idx_bounties_open_issue;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounties_open_issue ON bounties(issue_provider, issue_external_id) WHERE status = 'open';
+ALTER TABLE bounties DROP CONSTRAINT IF EXISTS bounties_status_check;
+ALTER TABLE bounties ADD CONSTRAINT bounties_status_check CHECK (status IN ('open', 'completed', 'cancelled'));
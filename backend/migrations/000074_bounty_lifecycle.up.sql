@@ -0,0 +1,64 @@
+-- Bounties move through claim, submission and approval before they are paid:
+-- open -> claimed -> submitted -> approved -> completed, and may be cancelled
+-- until approved. A claim can be dropped, returning the bounty to open.
+ALTER TABLE bounties DROP CONSTRAINT IF EXISTS bounties_status_check;
+ALTER TABLE bounties ADD CONSTRAINT bounties_status_check
+  CHECK (status IN ('open', 'claimed', 'submitted', 'approved', 'completed', 'cancelled'));
+
+-- An issue carries one bounty until it is completed or cancelled.
+DROP INDEX IF EXISTS idx_bounties_open_issue;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounties_open_issue ON bounties(issue_provider, issue_external_id)
+  WHERE status IN ('open', 'claimed', 'submitted', 'approved');
+
+-- Claims and submissions are refused after the deadline.
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS deadline TIMESTAMPTZ;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS claimed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;
+-- The pull request the claimant submitted.
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS pr_repo_full_name TEXT;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS pr_number INT;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS pr_url TEXT;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS approved_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS approved_at TIMESTAMPTZ;
+-- The hot wallet payout or escrow release that paid the bounty.
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS payout_id UUID REFERENCES payouts(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bounties_claimed_by ON bounties(claimed_by, updated_at DESC) WHERE claimed_by IS NOT NULL;
+
+-- Integrations see every transition out of open.
+CREATE OR REPLACE FUNCTION record_bounty_event() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.created');
+    RETURN NEW;
+  END IF;
+  IF NEW.status IS DISTINCT FROM OLD.status AND NEW.status <> 'open' THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.' || NEW.status);
+  END IF;
+  IF NEW.issue_closed AND NOT OLD.issue_closed THEN
+    INSERT INTO bounty_events (bounty_id, project_id, type) VALUES (NEW.id, NEW.project_id, 'bounty.issue_closed');
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_bounty_claim_history() RETURNS trigger AS $$
+DECLARE
+  changes JSONB;
+BEGIN
+  changes := history_change('claimed_by', to_jsonb(OLD.claimed_by), to_jsonb(NEW.claimed_by))
+    || history_change('pr_url', to_jsonb(OLD.pr_url), to_jsonb(NEW.pr_url))
+    || history_change('deadline', to_jsonb(OLD.deadline), to_jsonb(NEW.deadline));
+  IF changes <> '{}'::jsonb THEN
+    INSERT INTO bounty_history (bounty_id, type, actor_user_id, changes)
+    VALUES (NEW.id, 'bounty.claim_changed', history_actor(), changes);
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bounties_record_claim_history ON bounties;
+CREATE TRIGGER bounties_record_claim_history
+  AFTER UPDATE ON bounties
+  FOR EACH ROW EXECUTE FUNCTION record_bounty_claim_history();
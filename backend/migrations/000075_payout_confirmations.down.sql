@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_payouts_unconfirmed;
+
+ALTER TABLE payout_batches DROP COLUMN IF EXISTS confirmed_at;
+ALTER TABLE payout_batches DROP COLUMN IF EXISTS block_number;
+ALTER TABLE payouts DROP COLUMN IF EXISTS confirmed_at;
+ALTER TABLE payouts DROP COLUMN IF EXISTS block_number;
+
+UPDATE payout_batches SET status = 'submitted' WHERE status = 'confirmed';
+UPDATE payouts SET status = 'submitted' WHERE status = 'confirmed';
+ALTER TABLE payout_batches DROP CONSTRAINT IF EXISTS payout_batches_status_check;
+ALTER TABLE payout_batches ADD CONSTRAINT payout_batches_status_check
+  CHECK (status IN ('sending', 'submitted', 'failed'));
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending', 'batched', 'submitted', 'failed', 'cancelled'));
@@ -0,0 +1,16 @@
+-- Submitted payouts are watched on chain until their transaction is final:
+-- submitted -> confirmed, or failed if it reverted.
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending', 'batched', 'submitted', 'confirmed', 'failed', 'cancelled'));
+ALTER TABLE payout_batches DROP CONSTRAINT IF EXISTS payout_batches_status_check;
+ALTER TABLE payout_batches ADD CONSTRAINT payout_batches_status_check
+  CHECK (status IN ('sending', 'submitted', 'confirmed', 'failed'));
+
+-- The block (ledger on Stellar) that included the transaction.
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS block_number BIGINT;
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMPTZ;
+ALTER TABLE payout_batches ADD COLUMN IF NOT EXISTS block_number BIGINT;
+ALTER TABLE payout_batches ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_payouts_unconfirmed ON payouts(chain, updated_at) WHERE status = 'submitted';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_escrow_deposits;
+DROP TABLE IF EXISTS project_escrow_accounts;
@@ -0,0 +1,28 @@
+-- Projects fund bounties up front into escrow: one HD-derived deposit address
+-- per project and chain, never recycled for deposit intents.
+CREATE TABLE IF NOT EXISTS project_escrow_accounts (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE RESTRICT,
+  chain TEXT NOT NULL,
+  deposit_address_id UUID NOT NULL UNIQUE REFERENCES deposit_addresses(id),
+  -- Where the chain watcher resumes polling, for chains it polls itself
+  -- (Horizon's paging token on Stellar).
+  watch_cursor TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, chain)
+);
+
+-- Inbound transfers credited to a project's escrow, each posted once to the
+-- ledger's escrow account.
+CREATE TABLE IF NOT EXISTS project_escrow_deposits (
+  transfer_id UUID PRIMARY KEY REFERENCES chain_transfers(id) ON DELETE RESTRICT,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE RESTRICT,
+  chain TEXT NOT NULL,
+  asset TEXT NOT NULL,
+  amount NUMERIC(78, 18) NOT NULL,
+  tx_hash TEXT NOT NULL,
+  from_address TEXT NOT NULL,
+  ledger_entry_id UUID NOT NULL REFERENCES ledger_entries(id),
+  credited_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_escrow_deposits_project ON project_escrow_deposits(project_id, credited_at DESC);
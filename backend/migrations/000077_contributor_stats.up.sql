@@ -0,0 +1,20 @@
+-- Contributor leaderboard, recomputed by a background job per time window
+-- ('week' and 'month' are the trailing 7 and 30 days). login_key is the
+-- lowercased GitHub login; earnings map "chain/asset" to the amount paid.
+CREATE TABLE IF NOT EXISTS contributor_stats (
+  time_window TEXT NOT NULL CHECK (time_window IN ('week', 'month', 'all')),
+  login_key TEXT NOT NULL,
+  login TEXT NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  merged_prs INT NOT NULL DEFAULT 0,
+  completed_bounties INT NOT NULL DEFAULT 0,
+  projects INT NOT NULL DEFAULT 0,
+  earnings JSONB NOT NULL DEFAULT '{}',
+  score INT NOT NULL DEFAULT 0,
+  rank INT NOT NULL,
+  computed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (time_window, login_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contributor_stats_rank ON contributor_stats(time_window, rank);
+CREATE INDEX IF NOT EXISTS idx_contributor_stats_user ON contributor_stats(user_id) WHERE user_id IS NOT NULL;
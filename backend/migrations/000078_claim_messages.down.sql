@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS claim_messages;
+DROP TABLE IF EXISTS messaging_keys;
@@ -0,0 +1,33 @@
+-- End-to-end encrypted messages between a bounty's maintainer and its
+-- claimant. Each user publishes an X25519 public key, signed by one of their
+-- wallets so the other side can check it wasn't swapped; clients encrypt to
+-- it and the server only ever stores ciphertext.
+CREATE TABLE IF NOT EXISTS messaging_keys (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  -- Unlinking the wallet that vouches for the key withdraws the key.
+  wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+  public_key TEXT NOT NULL,
+  signature TEXT NOT NULL,
+  -- The wallet's own public key, for wallet types whose address isn't one.
+  wallet_public_key TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- A thread is one claim: the bounty and who claimed it, so a later claimant
+-- doesn't see an earlier one's messages. Each message records both keys it
+-- was encrypted with, so it stays readable after either side rotates.
+CREATE TABLE IF NOT EXISTS claim_messages (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  claimant_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  recipient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  sender_key TEXT NOT NULL,
+  recipient_key TEXT NOT NULL,
+  nonce TEXT NOT NULL,
+  ciphertext TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_messages_thread ON claim_messages(bounty_id, claimant_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS github_org_members;
+DROP TABLE IF EXISTS github_orgs;
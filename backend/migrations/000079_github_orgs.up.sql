@@ -0,0 +1,29 @@
+-- GitHub organizations linked through a GitHub App installation. Admins of a
+-- linked organization manage every project under it, alongside each
+-- project's owner. Memberships are mirrored from GitHub by a background job.
+CREATE TABLE IF NOT EXISTS github_orgs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  github_org_id BIGINT NOT NULL UNIQUE,
+  login TEXT NOT NULL,
+  installation_id TEXT NOT NULL UNIQUE,
+  linked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  members_synced_at TIMESTAMPTZ,
+  sync_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Projects are matched to their organization by the owner in their
+-- repository's full name.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_github_orgs_login ON github_orgs(lower(login));
+
+CREATE TABLE IF NOT EXISTS github_org_members (
+  org_id UUID NOT NULL REFERENCES github_orgs(id) ON DELETE CASCADE,
+  github_user_id BIGINT NOT NULL,
+  login TEXT NOT NULL,
+  role TEXT NOT NULL CHECK (role IN ('admin', 'member')),
+  synced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (org_id, github_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_org_members_user ON github_org_members(github_user_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS claim_time_entries;
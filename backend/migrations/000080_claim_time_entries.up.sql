@@ -0,0 +1,18 @@
+-- Time a claimant logs against their claim, with a running timer (ended_at
+-- NULL) or as manual entries. Maintainers see the totals on the claim, and
+-- completed bounties' totals feed the amount suggestions for new ones.
+CREATE TABLE IF NOT EXISTS claim_time_entries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  source TEXT NOT NULL CHECK (source IN ('timer', 'manual')),
+  started_at TIMESTAMPTZ NOT NULL,
+  ended_at TIMESTAMPTZ,
+  note TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ended_at IS NULL OR ended_at > started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_time_entries_claim ON claim_time_entries(bounty_id, user_id, started_at);
+-- One running timer per claim.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_claim_time_entries_running ON claim_time_entries(bounty_id, user_id) WHERE ended_at IS NULL;
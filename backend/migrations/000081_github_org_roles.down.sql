@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS github_org_role_assignments;
+DROP TABLE IF EXISTS github_org_roles;
@@ -0,0 +1,28 @@
+-- Custom roles defined by a linked organization's admins. Each grants a set
+-- of permissions over the organization's projects to the members assigned
+-- it, on top of GitHub's admin and member roles.
+CREATE TABLE IF NOT EXISTS github_org_roles (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id UUID NOT NULL REFERENCES github_orgs(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  permissions TEXT[] NOT NULL DEFAULT '{}',
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_github_org_roles_name ON github_org_roles(org_id, lower(name));
+
+-- Assignments are keyed by GitHub user so they survive member syncs, which
+-- replace github_org_members wholesale; they only take effect while the
+-- user is still a mirrored member.
+CREATE TABLE IF NOT EXISTS github_org_role_assignments (
+  role_id UUID NOT NULL REFERENCES github_org_roles(id) ON DELETE CASCADE,
+  github_user_id BIGINT NOT NULL,
+  assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (role_id, github_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_org_role_assignments_user ON github_org_role_assignments(github_user_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_github_accounts_key_version;
+ALTER TABLE github_accounts DROP COLUMN IF EXISTS access_token_key_version;
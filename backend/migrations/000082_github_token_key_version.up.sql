@@ -0,0 +1,6 @@
+-- Key version each GitHub access token is sealed under (0: the legacy
+-- TOKEN_ENC_KEY_B64), so re-keying can find the tokens still to move.
+ALTER TABLE github_accounts
+  ADD COLUMN IF NOT EXISTS access_token_key_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_github_accounts_key_version ON github_accounts(access_token_key_version);
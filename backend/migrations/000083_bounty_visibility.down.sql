@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS bounty_invites;
+DROP INDEX IF EXISTS idx_bounties_hidden;
+ALTER TABLE bounties DROP COLUMN IF EXISTS link_version;
+ALTER TABLE bounties DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,21 @@
+-- Who can see a bounty. Public bounties are listed in feeds; private ones
+-- (e.g. security fixes) only to members of the project's GitHub
+-- organization and invited users; unlisted ones to whoever holds their
+-- signed link. Bumping link_version revokes every link handed out so far.
+ALTER TABLE bounties
+  ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'private', 'unlisted')),
+  ADD COLUMN IF NOT EXISTS link_version INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_bounties_hidden ON bounties(project_id) WHERE visibility <> 'public';
+
+-- Users invited to a private or unlisted bounty.
+CREATE TABLE IF NOT EXISTS bounty_invites (
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  invited_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (bounty_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounty_invites_user ON bounty_invites(user_id);
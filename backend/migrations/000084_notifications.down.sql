@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS payouts_record_notification ON payouts;
+DROP FUNCTION IF EXISTS record_payout_notification();
+DROP TRIGGER IF EXISTS bounties_record_notification ON bounties;
+DROP FUNCTION IF EXISTS record_bounty_notification();
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS notification_outbox;
@@ -0,0 +1,88 @@
+-- Notifications to users. Triggers record each event for its recipient in
+-- notification_outbox; the dispatcher renders it and hands it to every
+-- channel the recipient has enabled, recording in delivered which ones took
+-- it so retries never repeat a channel.
+CREATE TABLE IF NOT EXISTS notification_outbox (
+  seq BIGSERIAL PRIMARY KEY,
+  id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  data JSONB NOT NULL DEFAULT '{}',
+  delivered TEXT[] NOT NULL DEFAULT '{}',
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  done_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_pending ON notification_outbox(seq) WHERE done_at IS NULL;
+
+-- The in-app channel.
+CREATE TABLE IF NOT EXISTS notifications (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  title TEXT NOT NULL,
+  body TEXT NOT NULL,
+  link TEXT,
+  read_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Channels a user turned off or on per event type; missing rows take the
+-- defaults in package notifications.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  channel TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, type, channel)
+);
+
+-- A claim tells the project owner someone started on the bounty; a
+-- submission asks them to review the pull request.
+CREATE OR REPLACE FUNCTION record_bounty_notification() RETURNS trigger AS $$
+BEGIN
+  IF NEW.status IS DISTINCT FROM OLD.status AND NEW.status IN ('claimed', 'submitted') THEN
+    INSERT INTO notification_outbox (user_id, type, data)
+    SELECT p.owner_user_id,
+           CASE NEW.status WHEN 'claimed' THEN 'bounty.claimed' ELSE 'review.requested' END,
+           jsonb_build_object(
+             'bounty_id', NEW.id, 'project_id', NEW.project_id, 'project', p.github_full_name,
+             'issue_key', NEW.issue_key, 'issue_title', COALESCE(NEW.issue_title, ''), 'issue_url', COALESCE(NEW.issue_url, ''),
+             'amount', NEW.amount::text, 'asset', NEW.asset, 'chain', NEW.chain,
+             'claimant', COALESCE((SELECT ga.login FROM github_accounts ga WHERE ga.user_id = NEW.claimed_by), ''),
+             'pr_url', COALESCE(NEW.pr_url, ''))
+    FROM projects p
+    WHERE p.id = NEW.project_id AND p.owner_user_id IS DISTINCT FROM NEW.claimed_by;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bounties_record_notification ON bounties;
+CREATE TRIGGER bounties_record_notification
+  AFTER UPDATE ON bounties
+  FOR EACH ROW EXECUTE FUNCTION record_bounty_notification();
+
+-- A payout is sent once its transaction is submitted.
+CREATE OR REPLACE FUNCTION record_payout_notification() RETURNS trigger AS $$
+BEGIN
+  IF NEW.status = 'submitted' AND OLD.status IS DISTINCT FROM 'submitted' THEN
+    INSERT INTO notification_outbox (user_id, type, data)
+    VALUES (NEW.user_id, 'payout.sent', jsonb_build_object(
+      'payout_id', NEW.id, 'amount', NEW.amount::text, 'asset', NEW.asset, 'chain', NEW.chain,
+      'to_address', NEW.to_address, 'tx_hash', COALESCE(NEW.tx_hash, ''), 'reference', COALESCE(NEW.reference, '')));
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS payouts_record_notification ON payouts;
+CREATE TRIGGER payouts_record_notification
+  AFTER UPDATE ON payouts
+  FOR EACH ROW EXECUTE FUNCTION record_payout_notification();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bounty_advisories;
+ALTER TABLE bounties DROP COLUMN IF EXISTS kind;
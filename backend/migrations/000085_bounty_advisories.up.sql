@@ -0,0 +1,42 @@
+-- Security-advisory bounties pay for vulnerability fixes. Until their
+-- coordinated disclosure date the bounty is private, its write-up is only
+-- shown to the project's maintainers, the invited researchers and the
+-- claimant, and only invited users may claim it. At disclosure the bounty
+-- turns public with the redacted write-up, and the linked GitHub Security
+-- Advisory is published.
+ALTER TABLE bounties
+  ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'standard'
+    CHECK (kind IN ('standard', 'security_advisory'));
+
+CREATE TABLE IF NOT EXISTS bounty_advisories (
+  bounty_id UUID PRIMARY KEY REFERENCES bounties(id) ON DELETE CASCADE,
+  severity TEXT NOT NULL CHECK (severity IN ('low', 'medium', 'high', 'critical')),
+  summary TEXT NOT NULL,
+  -- details stay embargoed; redacted_details are published at disclosure.
+  details TEXT NOT NULL,
+  redacted_details TEXT NOT NULL,
+  cve_id TEXT,
+  cwe_ids TEXT[] NOT NULL DEFAULT '{}',
+  package_ecosystem TEXT,
+  package_name TEXT,
+  vulnerable_versions TEXT,
+  patched_versions TEXT,
+  disclosure_at TIMESTAMPTZ NOT NULL,
+  published_at TIMESTAMPTZ,
+  -- The repository advisory on GitHub, once drafted, and when it was made
+  -- public there. github_synced_at is the updated_at last pushed to GitHub;
+  -- failed pushes are retried from github_retry_at.
+  ghsa_id TEXT,
+  ghsa_url TEXT,
+  ghsa_published_at TIMESTAMPTZ,
+  github_synced_at TIMESTAMPTZ,
+  github_retry_at TIMESTAMPTZ,
+  github_error TEXT,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounty_advisories_embargoed ON bounty_advisories(disclosure_at) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_bounty_advisories_github_pending ON bounty_advisories(updated_at)
+  WHERE github_synced_at IS NULL OR github_synced_at < updated_at;
@@ -0,0 +1,8 @@
+ALTER TABLE payouts
+  DROP COLUMN IF EXISTS routed_at,
+  DROP COLUMN IF EXISTS routing_notes,
+  DROP COLUMN IF EXISTS original_to_address,
+  DROP COLUMN IF EXISTS original_asset,
+  DROP COLUMN IF EXISTS original_chain,
+  DROP COLUMN IF EXISTS method_rank;
+DROP TABLE IF EXISTS payout_methods;
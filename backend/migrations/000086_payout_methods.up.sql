@@ -0,0 +1,23 @@
+-- Payout methods a user ranks, most preferred first. Pending payouts are
+-- routed to the first method whose chain is up, not congested and whose
+-- minimum the amount meets; the original destination is the last resort.
+CREATE TABLE IF NOT EXISTS payout_methods (
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  rank SMALLINT NOT NULL CHECK (rank >= 1),
+  chain TEXT NOT NULL,
+  address TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, rank),
+  UNIQUE (user_id, chain)
+);
+
+-- routed_at marks payouts the router has looked at; method_rank is the
+-- method they went out with (NULL for the original destination), and the
+-- original_* columns keep where they were first headed when rerouted.
+ALTER TABLE payouts
+  ADD COLUMN IF NOT EXISTS method_rank SMALLINT,
+  ADD COLUMN IF NOT EXISTS original_chain TEXT,
+  ADD COLUMN IF NOT EXISTS original_asset TEXT,
+  ADD COLUMN IF NOT EXISTS original_to_address TEXT,
+  ADD COLUMN IF NOT EXISTS routing_notes TEXT[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS routed_at TIMESTAMPTZ;
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS payouts_realtime_status ON payouts;
+DROP FUNCTION IF EXISTS realtime_payout_status();
+DROP TRIGGER IF EXISTS github_issue_comment_events_realtime ON github_issue_comment_events;
+DROP FUNCTION IF EXISTS realtime_issue_comment();
+DROP TRIGGER IF EXISTS bounties_realtime_status ON bounties;
+DROP FUNCTION IF EXISTS realtime_bounty_status();
+DROP FUNCTION IF EXISTS realtime_bounty_audience(bounties);
+DROP TABLE IF EXISTS realtime_events;
+DROP FUNCTION IF EXISTS realtime_events_notify();
@@ -0,0 +1,103 @@
+-- Events pushed to clients connected to GET /ws. Triggers append them in the
+-- same transaction as the change, and each insert is announced on the
+-- realtime channel once it commits so every API instance hands it to its
+-- sockets. Public events go to every connected client, others only to
+-- user_ids. Reconnecting clients replay what they missed by seq; rows are
+-- pruned by a background job.
+CREATE TABLE IF NOT EXISTS realtime_events (
+  seq BIGSERIAL PRIMARY KEY,
+  type TEXT NOT NULL,
+  public BOOLEAN NOT NULL DEFAULT false,
+  user_ids UUID[] NOT NULL DEFAULT '{}',
+  data JSONB NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_realtime_events_created ON realtime_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_realtime_events_users ON realtime_events USING GIN (user_ids);
+
+-- NOTIFY payloads are capped at 8000 bytes; a larger event is announced by
+-- seq alone and read back by the listener.
+CREATE OR REPLACE FUNCTION realtime_events_notify()
+RETURNS TRIGGER AS $$
+DECLARE
+  payload TEXT := json_build_object('seq', NEW.seq, 'type', NEW.type, 'public', NEW.public,
+    'user_ids', NEW.user_ids, 'data', NEW.data, 'created_at', NEW.created_at)::text;
+BEGIN
+  IF octet_length(payload) > 7900 THEN
+    payload := json_build_object('seq', NEW.seq)::text;
+  END IF;
+  PERFORM pg_notify('realtime', payload);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS realtime_events_notify ON realtime_events;
+CREATE TRIGGER realtime_events_notify
+  AFTER INSERT ON realtime_events
+  FOR EACH ROW EXECUTE FUNCTION realtime_events_notify();
+
+-- Who hears about a bounty that is not public: its creator, claimant,
+-- project owner and invited users.
+CREATE OR REPLACE FUNCTION realtime_bounty_audience(b bounties) RETURNS UUID[] AS $$
+  SELECT COALESCE(array_agg(DISTINCT u) FILTER (WHERE u IS NOT NULL), '{}')
+  FROM (
+    SELECT b.created_by AS u
+    UNION ALL SELECT b.claimed_by
+    UNION ALL SELECT p.owner_user_id FROM projects p WHERE p.id = b.project_id
+    UNION ALL SELECT i.user_id FROM bounty_invites i WHERE i.bounty_id = b.id
+  ) audience
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION realtime_bounty_status() RETURNS trigger AS $$
+BEGIN
+  IF NEW.status IS DISTINCT FROM OLD.status THEN
+    INSERT INTO realtime_events (type, public, user_ids, data)
+    VALUES ('bounty.status_changed', NEW.visibility = 'public', realtime_bounty_audience(NEW), jsonb_build_object(
+      'bounty_id', NEW.id, 'project_id', NEW.project_id, 'issue_key', NEW.issue_key,
+      'from', OLD.status, 'to', NEW.status));
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bounties_realtime_status ON bounties;
+CREATE TRIGGER bounties_realtime_status
+  AFTER UPDATE ON bounties
+  FOR EACH ROW EXECUTE FUNCTION realtime_bounty_status();
+
+-- A new comment on a bounty's GitHub issue.
+CREATE OR REPLACE FUNCTION realtime_issue_comment() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO realtime_events (type, public, user_ids, data)
+  SELECT 'bounty.comment_created', b.visibility = 'public', realtime_bounty_audience(b), jsonb_build_object(
+    'bounty_id', b.id, 'project_id', b.project_id, 'issue_key', b.issue_key,
+    'comment_id', NEW.github_comment_id, 'author_login', NEW.author_login)
+  FROM bounties b
+  WHERE b.project_id = NEW.project_id AND b.issue_provider = 'github' AND b.issue_key = '#' || NEW.issue_number;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS github_issue_comment_events_realtime ON github_issue_comment_events;
+CREATE TRIGGER github_issue_comment_events_realtime
+  AFTER INSERT ON github_issue_comment_events
+  FOR EACH ROW EXECUTE FUNCTION realtime_issue_comment();
+
+-- Payout progress, through to confirmation on chain, for its recipient.
+CREATE OR REPLACE FUNCTION realtime_payout_status() RETURNS trigger AS $$
+BEGIN
+  IF NEW.status IS DISTINCT FROM OLD.status THEN
+    INSERT INTO realtime_events (type, user_ids, data)
+    VALUES ('payout.status_changed', ARRAY[NEW.user_id], jsonb_build_object(
+      'payout_id', NEW.id, 'from', OLD.status, 'to', NEW.status,
+      'chain', NEW.chain, 'tx_hash', NEW.tx_hash, 'block_number', NEW.block_number));
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS payouts_realtime_status ON payouts;
+CREATE TRIGGER payouts_realtime_status
+  AFTER UPDATE ON payouts
+  FOR EACH ROW EXECUTE FUNCTION realtime_payout_status();
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_payouts_accruing;
+ALTER TABLE payouts
+  DROP COLUMN IF EXISTS settles_accrual,
+  DROP COLUMN IF EXISTS merged_into;
+
+-- Accrued balances are paid out as they stood.
+UPDATE payouts SET status = 'pending', updated_at = now() WHERE status = 'accruing';
+UPDATE payouts SET status = 'cancelled', updated_at = now() WHERE status = 'merged';
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending', 'batched', 'submitted', 'confirmed', 'failed', 'cancelled'));
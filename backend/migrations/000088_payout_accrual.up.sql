@@ -0,0 +1,14 @@
+-- Payouts below their asset's threshold accrue instead of being sent: they
+-- wait as accruing, credited to the user's ledger account, until the total
+-- crosses the threshold or a new month starts. They are then merged into one
+-- payout of the whole balance, which settles_accrual keeps from being held
+-- again.
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending', 'accruing', 'merged', 'batched', 'submitted', 'confirmed', 'failed', 'cancelled'));
+
+ALTER TABLE payouts
+  ADD COLUMN IF NOT EXISTS merged_into UUID REFERENCES payouts(id),
+  ADD COLUMN IF NOT EXISTS settles_accrual BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_payouts_accruing ON payouts(user_id, chain, created_at) WHERE status = 'accruing';
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_bounties_search;
+DROP INDEX IF EXISTS idx_projects_search;
+DROP TRIGGER IF EXISTS bounties_search_vector ON bounties;
+DROP TRIGGER IF EXISTS projects_search_vector ON projects;
+DROP FUNCTION IF EXISTS bounties_search_vector_refresh();
+DROP FUNCTION IF EXISTS projects_search_vector_refresh();
+ALTER TABLE bounties DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE projects DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS bounty_search_vector(bounties);
+DROP FUNCTION IF EXISTS project_search_vector(projects);
+DROP FUNCTION IF EXISTS search_words(TEXT);
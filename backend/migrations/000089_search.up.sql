@@ -0,0 +1,57 @@
+-- Full-text search over projects and bounties. Each row keeps a weighted
+-- tsvector, refreshed by trigger when the text it is built from changes:
+-- project names rank above tags, tags above descriptions; bounty titles
+-- above issue keys and skill tags.
+
+-- search_words splits owner/repo-name and ABC-123 style identifiers into
+-- words the parser indexes on their own.
+CREATE OR REPLACE FUNCTION search_words(s TEXT) RETURNS TEXT AS $$
+  SELECT regexp_replace(COALESCE(s, ''), '[/_.-]+', ' ', 'g')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION project_search_vector(p projects) RETURNS TSVECTOR AS $$
+  SELECT setweight(to_tsvector('english', search_words(p.github_full_name)), 'A')
+    || setweight(to_tsvector('english', CASE WHEN jsonb_typeof(p.tags) = 'array'
+         THEN (SELECT COALESCE(string_agg(t, ' '), '') FROM jsonb_array_elements_text(p.tags) t) ELSE '' END), 'B')
+    || setweight(to_tsvector('english', COALESCE(p.description, '')), 'C')
+    || setweight(to_tsvector('english', concat_ws(' ', p.language, p.category)), 'D')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION bounty_search_vector(b bounties) RETURNS TSVECTOR AS $$
+  SELECT setweight(to_tsvector('english', COALESCE(b.issue_title, '')), 'A')
+    || setweight(to_tsvector('english', search_words(b.issue_key)), 'B')
+    || setweight(to_tsvector('english', array_to_string(b.skill_tags, ' ')), 'C')
+$$ LANGUAGE sql IMMUTABLE;
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION projects_search_vector_refresh() RETURNS trigger AS $$
+BEGIN
+  NEW.search_vector := project_search_vector(NEW);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION bounties_search_vector_refresh() RETURNS trigger AS $$
+BEGIN
+  NEW.search_vector := bounty_search_vector(NEW);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS projects_search_vector ON projects;
+CREATE TRIGGER projects_search_vector
+  BEFORE INSERT OR UPDATE OF github_full_name, tags, description, language, category ON projects
+  FOR EACH ROW EXECUTE FUNCTION projects_search_vector_refresh();
+
+DROP TRIGGER IF EXISTS bounties_search_vector ON bounties;
+CREATE TRIGGER bounties_search_vector
+  BEFORE INSERT OR UPDATE OF issue_title, issue_key, skill_tags ON bounties
+  FOR EACH ROW EXECUTE FUNCTION bounties_search_vector_refresh();
+
+UPDATE projects p SET search_vector = project_search_vector(p);
+UPDATE bounties b SET search_vector = bounty_search_vector(b);
+
+CREATE INDEX IF NOT EXISTS idx_projects_search ON projects USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_bounties_search ON bounties USING GIN (search_vector);
@@ -0,0 +1,20 @@
+-- Responses to requests sent with an Idempotency-Key header, replayed when
+-- the same caller retries the key with the same request. status is NULL
+-- while the first request is still running. Rows are pruned by a background
+-- job once they expire.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  -- The user the key belongs to, or "ip:<address>" for anonymous callers.
+  scope TEXT NOT NULL,
+  key TEXT NOT NULL,
+  -- SHA-256 of the method, path and body, to reject a key reused for a
+  -- different request.
+  request_hash TEXT NOT NULL,
+  status INT,
+  content_type TEXT,
+  body BYTEA,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys(expires_at);
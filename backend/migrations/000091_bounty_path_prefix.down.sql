@@ -0,0 +1 @@
+ALTER TABLE bounties DROP COLUMN IF EXISTS path_prefix;
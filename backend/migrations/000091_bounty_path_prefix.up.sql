@@ -0,0 +1,4 @@
+-- Monorepo bounties can be scoped to a directory of the project's repo,
+-- e.g. packages/api: merged pull requests only settle them when they change
+-- files under it, and their skill tags come from that subtree.
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS path_prefix TEXT;
@@ -0,0 +1,18 @@
+CREATE OR REPLACE FUNCTION record_bounty_notification() RETURNS trigger AS $$
+BEGIN
+  IF NEW.status IS DISTINCT FROM OLD.status AND NEW.status IN ('claimed', 'submitted') THEN
+    INSERT INTO notification_outbox (user_id, type, data)
+    SELECT p.owner_user_id,
+           CASE NEW.status WHEN 'claimed' THEN 'bounty.claimed' ELSE 'review.requested' END,
+           jsonb_build_object(
+             'bounty_id', NEW.id, 'project_id', NEW.project_id, 'project', p.github_full_name,
+             'issue_key', NEW.issue_key, 'issue_title', COALESCE(NEW.issue_title, ''), 'issue_url', COALESCE(NEW.issue_url, ''),
+             'amount', NEW.amount::text, 'asset', NEW.asset, 'chain', NEW.chain,
+             'claimant', COALESCE((SELECT ga.login FROM github_accounts ga WHERE ga.user_id = NEW.claimed_by), ''),
+             'pr_url', COALESCE(NEW.pr_url, ''))
+    FROM projects p
+    WHERE p.id = NEW.project_id AND p.owner_user_id IS DISTINCT FROM NEW.claimed_by;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
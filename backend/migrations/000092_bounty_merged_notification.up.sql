@@ -0,0 +1,21 @@
+-- A merged pull request that settles a bounty approves it with no approver;
+-- tell the project owner it awaits payment.
+CREATE OR REPLACE FUNCTION record_bounty_notification() RETURNS trigger AS $$
+BEGIN
+  IF NEW.status IS DISTINCT FROM OLD.status
+     AND (NEW.status IN ('claimed', 'submitted') OR (NEW.status = 'approved' AND NEW.approved_by IS NULL)) THEN
+    INSERT INTO notification_outbox (user_id, type, data)
+    SELECT p.owner_user_id,
+           CASE NEW.status WHEN 'claimed' THEN 'bounty.claimed' WHEN 'submitted' THEN 'review.requested' ELSE 'bounty.merged' END,
+           jsonb_build_object(
+             'bounty_id', NEW.id, 'project_id', NEW.project_id, 'project', p.github_full_name,
+             'issue_key', NEW.issue_key, 'issue_title', COALESCE(NEW.issue_title, ''), 'issue_url', COALESCE(NEW.issue_url, ''),
+             'amount', NEW.amount::text, 'asset', NEW.asset, 'chain', NEW.chain,
+             'claimant', COALESCE((SELECT ga.login FROM github_accounts ga WHERE ga.user_id = NEW.claimed_by), ''),
+             'pr_url', COALESCE(NEW.pr_url, ''))
+    FROM projects p
+    WHERE p.id = NEW.project_id AND p.owner_user_id IS DISTINCT FROM NEW.claimed_by;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
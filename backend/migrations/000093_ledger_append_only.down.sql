@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS ledger_entries_no_rewrite ON ledger_entries;
+DROP FUNCTION IF EXISTS ledger_entries_append_only();
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS ledger_entries_user_id_fkey;
+ALTER TABLE ledger_entries
+  ADD CONSTRAINT ledger_entries_user_id_fkey
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+-- Anchored rows hash user_id, so deleting a user must not rewrite them.
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS ledger_entries_user_id_fkey;
+ALTER TABLE ledger_entries
+  ADD CONSTRAINT ledger_entries_user_id_fkey
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+CREATE OR REPLACE FUNCTION ledger_entries_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'ledger_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_entries_no_rewrite ON ledger_entries;
+CREATE TRIGGER ledger_entries_no_rewrite
+  BEFORE UPDATE OR DELETE ON ledger_entries
+  FOR EACH ROW EXECUTE FUNCTION ledger_entries_append_only();
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_payout_batches_sending;
+
+-- Held payouts go back to the queue.
+UPDATE payouts SET status = 'pending', updated_at = now() WHERE status = 'held';
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending', 'accruing', 'merged', 'batched', 'submitted', 'confirmed', 'failed', 'cancelled'));
+
+UPDATE sweeps SET status = 'pending', updated_at = now() WHERE status = 'sending';
+ALTER TABLE sweeps DROP CONSTRAINT IF EXISTS sweeps_status_check;
+ALTER TABLE sweeps ADD CONSTRAINT sweeps_status_check
+  CHECK (status IN ('awaiting_approval', 'pending', 'submitted', 'failed', 'cancelled'));
+ALTER TABLE sweeps DROP COLUMN IF EXISTS signed_tx;
+ALTER TABLE payout_batches DROP COLUMN IF EXISTS signed_tx;
@@ -0,0 +1,15 @@
+-- Hot wallet sends are signed and recorded before they are broadcast, so a
+-- send interrupted after signing can be resumed from the stored envelope.
+ALTER TABLE payout_batches ADD COLUMN IF NOT EXISTS signed_tx TEXT;
+ALTER TABLE sweeps ADD COLUMN IF NOT EXISTS signed_tx TEXT;
+ALTER TABLE sweeps DROP CONSTRAINT IF EXISTS sweeps_status_check;
+ALTER TABLE sweeps ADD CONSTRAINT sweeps_status_check
+  CHECK (status IN ('awaiting_approval', 'pending', 'sending', 'submitted', 'failed', 'cancelled'));
+
+-- Payouts flagged by a fraud review are held out of batches until the
+-- review is resolved.
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending', 'held', 'accruing', 'merged', 'batched', 'submitted', 'confirmed', 'failed', 'cancelled'));
+
+CREATE INDEX IF NOT EXISTS idx_payout_batches_sending ON payout_batches(updated_at) WHERE status = 'sending';
@@ -0,0 +1,21 @@
+CREATE OR REPLACE FUNCTION realtime_issue_comment() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO realtime_events (type, public, user_ids, data)
+  SELECT 'bounty.comment_created', b.visibility = 'public', realtime_bounty_audience(b), jsonb_build_object(
+    'bounty_id', b.id, 'project_id', b.project_id, 'issue_key', b.issue_key,
+    'comment_id', NEW.github_comment_id, 'author_login', NEW.author_login)
+  FROM bounties b
+  WHERE b.project_id = NEW.project_id AND b.issue_provider = 'github' AND b.issue_key = '#' || NEW.issue_number;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION realtime_bounty_audience(b bounties) RETURNS UUID[] AS $$
+  SELECT COALESCE(array_agg(DISTINCT u) FILTER (WHERE u IS NOT NULL), '{}')
+  FROM (
+    SELECT b.created_by AS u
+    UNION ALL SELECT b.claimed_by
+    UNION ALL SELECT p.owner_user_id FROM projects p WHERE p.id = b.project_id
+    UNION ALL SELECT i.user_id FROM bounty_invites i WHERE i.bounty_id = b.id
+  ) audience
+$$ LANGUAGE sql STABLE;
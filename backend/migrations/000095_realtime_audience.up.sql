@@ -0,0 +1,48 @@
+-- Private bounties are also seen by members of the project's linked GitHub
+-- organization, and every bounty by its admins and the holders of roles that
+-- edit bounties (see bounties.VisibleTo), so they hear about them too.
+CREATE OR REPLACE FUNCTION realtime_bounty_audience(b bounties) RETURNS UUID[] AS $$
+  SELECT COALESCE(array_agg(DISTINCT u) FILTER (WHERE u IS NOT NULL), '{}')
+  FROM (
+    SELECT b.created_by AS u
+    UNION ALL SELECT b.claimed_by
+    UNION ALL SELECT p.owner_user_id FROM projects p WHERE p.id = b.project_id
+    UNION ALL SELECT i.user_id FROM bounty_invites i WHERE i.bounty_id = b.id
+    UNION ALL
+    SELECT ga.user_id
+    FROM projects p
+    JOIN github_orgs o ON lower(o.login) = lower(split_part(p.github_full_name, '/', 1))
+    JOIN github_org_members m ON m.org_id = o.id
+    JOIN github_accounts ga ON ga.github_user_id = m.github_user_id
+    WHERE p.id = b.project_id
+      AND (b.visibility = 'private' OR m.role = 'admin' OR EXISTS (
+        SELECT 1 FROM github_org_roles r
+        JOIN github_org_role_assignments a ON a.role_id = r.id
+        WHERE r.org_id = o.id AND a.github_user_id = m.github_user_id
+          AND 'bounties:edit' = ANY(r.permissions)))
+  ) audience
+$$ LANGUAGE sql STABLE;
+
+-- Comments by shadow-banned authors are hidden from everyone else, so only
+-- the author hears about them.
+CREATE OR REPLACE FUNCTION realtime_issue_comment() RETURNS trigger AS $$
+DECLARE
+  banned UUID;
+BEGIN
+  SELECT u.id INTO banned
+  FROM github_accounts ga JOIN users u ON u.id = ga.user_id
+  WHERE lower(ga.login) = lower(NEW.author_login) AND u.shadow_banned_at IS NOT NULL
+  LIMIT 1;
+
+  INSERT INTO realtime_events (type, public, user_ids, data)
+  SELECT 'bounty.comment_created',
+    banned IS NULL AND b.visibility = 'public',
+    CASE WHEN banned IS NULL THEN realtime_bounty_audience(b) ELSE ARRAY[banned] END,
+    jsonb_build_object(
+      'bounty_id', b.id, 'project_id', b.project_id, 'issue_key', b.issue_key,
+      'comment_id', NEW.github_comment_id, 'author_login', NEW.author_login)
+  FROM bounties b
+  WHERE b.project_id = NEW.project_id AND b.issue_provider = 'github' AND b.issue_key = '#' || NEW.issue_number;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
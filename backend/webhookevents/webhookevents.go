@@ -0,0 +1,221 @@
+// Package webhookevents is the catalog of events Grainlify POSTs to webhook
+// endpoints, as typed Go values. The dispatcher builds every payload from
+// these types and GET /meta/events publishes their schemas, so integrators
+// importing this package decode exactly what is sent:
+//
+//	env, err := webhookevents.Decode(body)
+//	if err != nil { ... }
+//	switch env.Type {
+//	case webhookevents.BountyClaimed:
+//		e, err := env.BountyEvent()
+//		...
+//	}
+//
+// Check the X-Grainlify-Signature header before trusting a body.
+package webhookevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Version is the payload schema version these types describe. Endpoints
+// pinned to an older version receive older shapes.
+const Version = 1
+
+// Event types. Every type carries a BountyEvent.
+const (
+	BountyCreated     = "bounty.created"
+	BountyClaimed     = "bounty.claimed"
+	BountySubmitted   = "bounty.submitted"
+	BountyApproved    = "bounty.approved"
+	BountyCompleted   = "bounty.completed"
+	BountyCancelled   = "bounty.cancelled"
+	BountyIssueClosed = "bounty.issue_closed"
+)
+
+// Type describes one event type.
+type Type struct {
+	Name        string
+	Description string
+	// Example is an example of the type's data.
+	Example any
+}
+
+// Types lists every event type, in the order the bounty lifecycle reaches
+// them.
+var Types = []Type{
+	{BountyCreated, "A bounty was posted on an issue.", exampleBounty("open")},
+	{BountyClaimed, "A contributor claimed the bounty.", exampleBounty("claimed")},
+	{BountySubmitted, "The claimant submitted a pull request for review.", exampleBounty("submitted")},
+	{BountyApproved, "A maintainer approved the submission; payment follows.", exampleBounty("approved")},
+	{BountyCompleted, "The reward was paid.", exampleBounty("completed")},
+	{BountyCancelled, "The bounty was withdrawn before approval.", exampleBounty("cancelled")},
+	{BountyIssueClosed, "The bounty's issue was closed on its tracker.", exampleBounty("open")},
+}
+
+// Names are the names of Types.
+func Names() []string {
+	out := make([]string, len(Types))
+	for i, t := range Types {
+		out[i] = t.Name
+	}
+	return out
+}
+
+// Envelope is the body of every delivery.
+type Envelope struct {
+	// ID is the event's ID, the same for every endpoint it is sent to.
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Decode parses a delivery body.
+func Decode(body []byte) (Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal(body, &e); err != nil {
+		return Envelope{}, err
+	}
+	return e, nil
+}
+
+// BountyEvent decodes the data of a bounty.* event.
+func (e Envelope) BountyEvent() (BountyEvent, error) {
+	var out BountyEvent
+	if err := json.Unmarshal(e.Data, &out); err != nil {
+		return BountyEvent{}, fmt.Errorf("%s data: %w", e.Type, err)
+	}
+	return out, nil
+}
+
+// BountyEvent is the data of bounty.* events: the bounty as it is when the
+// event is sent, which may be later than the change that caused it.
+type BountyEvent struct {
+	Bounty Bounty `json:"bounty"`
+}
+
+// Bounty is a reward offered on an issue.
+type Bounty struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	Issue     Issue     `json:"issue"`
+	// PathPrefix, when set, is the directory of the project's repo the
+	// bounty is scoped to: only pull requests changing files under it
+	// settle it.
+	PathPrefix string `json:"path_prefix,omitempty"`
+	Chain      string `json:"chain"`
+	Asset      string `json:"asset"`
+	// Amount is a decimal string.
+	Amount string `json:"amount"`
+	// Status is open, claimed, submitted, approved, completed or cancelled.
+	Status              string   `json:"status"`
+	SkillTags           []string `json:"skill_tags"`
+	SkillTagsOverridden bool     `json:"skill_tags_overridden"`
+	// Funding is how the reward is paid, e.g. hot_wallet or escrow.
+	Funding string `json:"funding"`
+	// Escrow is set for escrow-funded bounties.
+	Escrow *Escrow `json:"escrow,omitempty"`
+	// Metadata holds the custom fields the project defines for bounties.
+	Metadata map[string]any `json:"metadata"`
+	Deadline *time.Time     `json:"deadline,omitempty"`
+	// Visibility is public, private or unlisted.
+	Visibility string `json:"visibility"`
+	// Kind is standard or security_advisory.
+	Kind string `json:"kind"`
+	// Claim is set once a contributor claims the bounty.
+	Claim      *Claimant  `json:"claim,omitempty"`
+	ApprovedBy *uuid.UUID `json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+	PayoutID   *uuid.UUID `json:"payout_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Issue is the tracker issue a bounty is on.
+type Issue struct {
+	// Provider is github, jira or linear.
+	Provider   string `json:"provider"`
+	ExternalID string `json:"external_id"`
+	// Key is how the tracker names the issue, e.g. "#42" or "ENG-7".
+	Key    string `json:"key"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	State  string `json:"state"`
+	Closed bool   `json:"closed"`
+}
+
+// Claimant is the contributor working on a bounty and, once submitted,
+// their pull request.
+type Claimant struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	ClaimedAt   time.Time  `json:"claimed_at"`
+	Repo        *string    `json:"repo_full_name,omitempty"`
+	PRNumber    *int       `json:"pr_number,omitempty"`
+	PRURL       *string    `json:"pr_url,omitempty"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+}
+
+// Escrow is where an escrow-funded bounty's reward is locked.
+type Escrow struct {
+	Contract string    `json:"contract"`
+	Ref      int64     `json:"ref"`
+	Status   string    `json:"status"`
+	Deadline time.Time `json:"deadline"`
+	LockTx   *string   `json:"lock_tx_hash,omitempty"`
+}
+
+// exampleBounty is an example BountyEvent with the bounty in status.
+func exampleBounty(status string) BountyEvent {
+	created := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	b := Bounty{
+		ID:        uuid.MustParse("5b1f0a52-3c4e-4f0d-9a3b-2d6c8e1f7a90"),
+		ProjectID: uuid.MustParse("0c7d2e94-8f1a-4b6e-a5d3-9e2f4b8c1a07"),
+		CreatedBy: uuid.MustParse("e4a9b3c1-2d5f-4e8a-b7c6-1f0d9e8a2b3c"),
+		Issue: Issue{
+			Provider: "github", ExternalID: "2215430187", Key: "#42",
+			Title: "Fix the widget", URL: "https://github.com/acme/widgets/issues/42", State: "open",
+		},
+		Chain:      "stellar",
+		Asset:      "USDC",
+		Amount:     "150",
+		Status:     status,
+		SkillTags:  []string{"go", "postgres"},
+		Funding:    "hot_wallet",
+		Metadata:   map[string]any{},
+		Visibility: "public",
+		Kind:       "standard",
+		CreatedAt:  created,
+		UpdatedAt:  created,
+	}
+	if status == "open" || status == "cancelled" {
+		return BountyEvent{Bounty: b}
+	}
+	claimed := created.Add(2 * time.Hour)
+	b.Claim = &Claimant{UserID: uuid.MustParse("9d8c7b6a-5e4f-4a3b-8c2d-1e0f9a8b7c6d"), ClaimedAt: claimed}
+	b.UpdatedAt = claimed
+	if status == "claimed" {
+		return BountyEvent{Bounty: b}
+	}
+	submitted := claimed.Add(24 * time.Hour)
+	repo, pr, prURL := "acme/widgets", 43, "https://github.com/acme/widgets/pull/43"
+	b.Claim.Repo, b.Claim.PRNumber, b.Claim.PRURL, b.Claim.SubmittedAt = &repo, &pr, &prURL, &submitted
+	b.UpdatedAt = submitted
+	if status == "submitted" {
+		return BountyEvent{Bounty: b}
+	}
+	approved := submitted.Add(3 * time.Hour)
+	b.ApprovedBy, b.ApprovedAt, b.UpdatedAt = &b.CreatedBy, &approved, approved
+	if status == "completed" {
+		payout := uuid.MustParse("3a2b1c0d-9e8f-4a7b-9c6d-5e4f3a2b1c0d")
+		b.PayoutID = &payout
+		b.Issue.State, b.Issue.Closed = "closed", true
+	}
+	return BountyEvent{Bounty: b}
+}